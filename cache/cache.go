@@ -0,0 +1,68 @@
+// Package cache keeps copies of frequently read records behind a single
+// interface, so whether and where they are cached is a deployment choice
+// rather than a code change. Values are opaque bytes; callers encode them.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Cache is implemented by every cache backend. A backend that cannot be
+// reached reports an error; callers treat that as a miss and read the
+// database instead.
+type Cache interface {
+	// Get returns the value stored at key, with ok false if there is none
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value at key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes the values stored at keys
+	Delete(ctx context.Context, keys ...string) error
+	// Ping reports whether the backend can be reached
+	Ping(ctx context.Context) error
+	// Name identifies the backend in logs, e.g. "redis"
+	Name() string
+}
+
+// Open returns the cache described by rawURL:
+//
+//	none://                               no caching (the default)
+//	redis://[:password@]host:port/db      a Redis server shared by every API instance
+func Open(rawURL string) (Cache, error) {
+	if rawURL == "" {
+		return None{}, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("cache: invalid URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "none":
+		return None{}, nil
+	case "redis", "rediss":
+		return NewRedis(rawURL)
+	default:
+		return nil, fmt.Errorf("cache: unsupported scheme %q", u.Scheme)
+	}
+}
+
+// None is the cache used when caching is not configured: it holds nothing
+type None struct{}
+
+// Get implements Cache; it always misses
+func (None) Get(context.Context, string) ([]byte, bool, error) { return nil, false, nil }
+
+// Set implements Cache; it discards the value
+func (None) Set(context.Context, string, []byte, time.Duration) error { return nil }
+
+// Delete implements Cache
+func (None) Delete(context.Context, ...string) error { return nil }
+
+// Ping implements Cache
+func (None) Ping(context.Context) error { return nil }
+
+// Name implements Cache
+func (None) Name() string { return "none" }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"farm4u/service/activity"
+	"net/http"
+)
+
+// ActivityResponse represents the farm activity feed response
+type ActivityResponse struct {
+	Success  bool             `json:"success"`
+	Message  string           `json:"message"`
+	Activity []*activity.Item `json:"activity"`
+}
+
+// GetFarmActivityHandler handles a farm's activity feed: the crops,
+// livestock, employees and transactions added, changed or deleted, newest
+// first. It covers the last seven days unless ?from=/?to= (YYYY-MM-DD) are
+// given, and ?entityType= narrows it to one kind of record.
+func (app *Config) GetFarmActivityHandler(w http.ResponseWriter, r *http.Request) {
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := app.Services.Activity.Feed(r.Context(), user, farmID, r.URL.Query().Get("entityType"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ActivityResponse{
+		Success:  true,
+		Message:  "Farm activity retrieved successfully",
+		Activity: items,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// HistoryResponse represents a record's change history response
+type HistoryResponse struct {
+	Success  bool                `json:"success"`
+	Message  string              `json:"message"`
+	Versions []*activity.Version `json:"versions"`
+}
+
+// GetCropHistoryHandler handles a crop's change history
+func (app *Config) GetCropHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	app.recordHistory(w, r, activity.EntityCrop)
+}
+
+// GetLivestockHistoryHandler handles a livestock group's change history
+func (app *Config) GetLivestockHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	app.recordHistory(w, r, activity.EntityLivestock)
+}
+
+// GetEmployeeHistoryHandler handles an employee's change history
+func (app *Config) GetEmployeeHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	app.recordHistory(w, r, activity.EntityEmployee)
+}
+
+// GetTransactionHistoryHandler handles a transaction's change history
+func (app *Config) GetTransactionHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	app.recordHistory(w, r, activity.EntityTransaction)
+}
+
+// recordHistory writes every change made to the entityType record named by
+// the route, oldest first, with the fields each change set
+func (app *Config) recordHistory(w http.ResponseWriter, r *http.Request, entityType string) {
+	entityID := resourceID(r)
+	if entityID == "" {
+		app.errorJSON(w, errors.New(entityType+" ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := app.Services.Activity.History(r.Context(), user, entityType, entityID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := HistoryResponse{
+		Success:  true,
+		Message:  "History retrieved successfully",
+		Versions: versions,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"farm4u/data"
+	"farm4u/notify"
+	"farm4u/service/auth"
+	"net/http"
+	"strconv"
+)
+
+// adminRoles are the roles an admin can assign to a user
+var adminRoles = []string{auth.DefaultRole, auth.BuyerRole, "Admin"}
+
+// AdminUserRoleRequest represents the role change request body
+type AdminUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// AdminResetPasswordRequest represents the admin password reset request body.
+// When NewPassword is empty a temporary password is generated.
+type AdminResetPasswordRequest struct {
+	NewPassword string `json:"newPassword"`
+}
+
+// AdminResponse represents the admin API response
+type AdminResponse struct {
+	Success           bool                    `json:"success"`
+	Message           string                  `json:"message"`
+	User              *data.User              `json:"user,omitempty"`
+	Users             []*data.User            `json:"users,omitempty"`
+	Total             int64                   `json:"total,omitempty"`
+	Page              int                     `json:"page,omitempty"`
+	PageSize          int                     `json:"pageSize,omitempty"`
+	TemporaryPassword string                  `json:"temporaryPassword,omitempty"`
+	Token             string                  `json:"token,omitempty"`
+	Counts            map[string]int64        `json:"counts,omitempty"`
+	UsersByRole       map[string]int64        `json:"usersByRole,omitempty"`
+	Farms             []*data.Farm            `json:"farms,omitempty"`
+	Providers         []notify.ProviderStatus `json:"providers,omitempty"`
+}
+
+// Validate checks the role change request fields
+func (req *AdminUserRoleRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("role", req.Role)
+	v.OneOf("role", req.Role, adminRoles...)
+	return v.Errors()
+}
+
+// Validate checks the admin password reset request fields, a given password
+// against policy
+func (req *AdminResetPasswordRequest) Validate(policy auth.PasswordPolicy) ValidationErrors {
+	v := newValidator()
+	v.Password("newPassword", req.NewPassword, policy)
+	return v.Errors()
+}
+
+// AdminMiddleware protects a route for active users with the Admin role. The
+// role is read from the database, so revoking it takes effect immediately.
+func (app *Config) AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return app.JWTMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		// Support sessions must not be able to reach the admin API
+		if r.Header.Get("X-Impersonator-ID") != "" {
+			app.errorJSON(w, errors.New("admin access required"), http.StatusForbidden)
+			return
+		}
+
+		user, ok := app.currentUser(w, r)
+		if !ok {
+			return
+		}
+
+		if user.Role != "Admin" {
+			app.errorJSON(w, errors.New("admin access required"), http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+// AdminListUsersHandler lists and searches users. Query parameters: q (name or
+// email), role, active (true/false), page and pageSize.
+func (app *Config) AdminListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	page, pageSize := 1, 50
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			app.errorJSON(w, errors.New("page must be a positive integer"), http.StatusBadRequest)
+			return
+		}
+		page = n
+	}
+	if v := q.Get("pageSize"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 200 {
+			app.errorJSON(w, errors.New("pageSize must be between 1 and 200"), http.StatusBadRequest)
+			return
+		}
+		pageSize = n
+	}
+
+	filter := data.UserFilter{
+		Query:  q.Get("q"),
+		Role:   q.Get("role"),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+	if v := q.Get("active"); v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			app.errorJSON(w, errors.New("active must be true or false"), http.StatusBadRequest)
+			return
+		}
+		filter.Active = &active
+	}
+
+	users, total, err := app.Models.User.Search(r.Context(), filter)
+	if err != nil {
+		app.ErrorLog.Printf("Error searching users: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	response := AdminResponse{
+		Success:  true,
+		Message:  "Users retrieved successfully",
+		Users:    users,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminGetUserHandler retrieves a single user with their farms
+func (app *Config) AdminGetUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	farms, err := app.Models.Farm.GetByUserID(r.Context(), user.UserID)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting farms for user: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	response := AdminResponse{
+		Success: true,
+		Message: "User retrieved successfully",
+		User:    user,
+		Farms:   farms,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminDeactivateUserHandler deactivates an account. The user can no longer
+// log in and existing tokens stop working.
+func (app *Config) AdminDeactivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setUserActive(w, r, false)
+}
+
+// AdminReactivateUserHandler reactivates a deactivated account
+func (app *Config) AdminReactivateUserHandler(w http.ResponseWriter, r *http.Request) {
+	app.setUserActive(w, r, true)
+}
+
+// setUserActive updates a user's active flag for the (de)activate handlers
+func (app *Config) setUserActive(w http.ResponseWriter, r *http.Request, active bool) {
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !active && user.Email == r.Header.Get("X-User-Email") {
+		app.errorJSON(w, errors.New("you cannot deactivate your own account"), http.StatusConflict)
+		return
+	}
+
+	user.Active = active
+	if err := app.Models.User.Update(r.Context(), user); err != nil {
+		app.ErrorLog.Printf("Error updating user: %v", err)
+		app.errorJSON(w, errors.New("failed to update user"), http.StatusInternalServerError)
+		return
+	}
+
+	message := "User reactivated successfully"
+	if !active {
+		message = "User deactivated successfully"
+	}
+	app.InfoLog.Printf("Admin %s set active=%t for user %s", r.Header.Get("X-User-Email"), active, user.Email)
+
+	response := AdminResponse{
+		Success: true,
+		Message: message,
+		User:    user,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminSetUserRoleHandler changes a user's role
+func (app *Config) AdminSetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	var req AdminUserRoleRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Email == r.Header.Get("X-User-Email") && req.Role != "Admin" {
+		app.errorJSON(w, errors.New("you cannot remove your own admin role"), http.StatusConflict)
+		return
+	}
+
+	user.Role = req.Role
+	if err := app.Models.User.Update(r.Context(), user); err != nil {
+		app.ErrorLog.Printf("Error updating user role: %v", err)
+		app.errorJSON(w, errors.New("failed to update user"), http.StatusInternalServerError)
+		return
+	}
+	app.InfoLog.Printf("Admin %s set role %s for user %s", r.Header.Get("X-User-Email"), req.Role, user.Email)
+
+	response := AdminResponse{
+		Success: true,
+		Message: "User role updated successfully",
+		User:    user,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminResetPasswordHandler sets a new password for a user. If none is given a
+// temporary password is generated and returned once in the response.
+func (app *Config) AdminResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req AdminResetPasswordRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(app.Settings.PasswordPolicy); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	password, temporary := req.NewPassword, ""
+	if password == "" {
+		b := make([]byte, 12)
+		rand.Read(b)
+		password = base64.RawURLEncoding.EncodeToString(b)
+		temporary = password
+	}
+
+	if err := app.Models.User.ResetPassword(r.Context(), password, *user); err != nil {
+		app.ErrorLog.Printf("Error resetting password: %v", err)
+		app.errorJSON(w, errors.New("failed to reset password"), http.StatusInternalServerError)
+		return
+	}
+	app.InfoLog.Printf("Admin %s reset the password of user %s", r.Header.Get("X-User-Email"), user.Email)
+
+	response := AdminResponse{
+		Success:           true,
+		Message:           "Password reset successfully",
+		User:              user,
+		TemporaryPassword: temporary,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminImpersonateUserHandler issues a short-lived token to act as a user for
+// support. The token records the admin, and admins cannot be impersonated.
+func (app *Config) AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.adminTargetUser(w, r)
+	if !ok {
+		return
+	}
+
+	if user.Role == "Admin" {
+		app.errorJSON(w, errors.New("admins cannot be impersonated"), http.StatusForbidden)
+		return
+	}
+
+	if !user.Active {
+		app.errorJSON(w, errors.New("account is deactivated"), http.StatusConflict)
+		return
+	}
+
+	adminID, _ := strconv.Atoi(r.Header.Get("X-User-ID"))
+	token, err := app.GenerateImpersonationJWT(user, adminID)
+	if err != nil {
+		app.ErrorLog.Printf("Error generating impersonation token: %v", err)
+		app.errorJSON(w, errors.New("failed to generate authentication token"), http.StatusInternalServerError)
+		return
+	}
+	app.InfoLog.Printf("Admin %s started a support session as user %s", r.Header.Get("X-User-Email"), user.Email)
+
+	response := AdminResponse{
+		Success: true,
+		Message: "Impersonation token issued, valid for " + impersonationTTL.String(),
+		User:    user,
+		Token:   token,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminStatsHandler reports system-wide record counts
+func (app *Config) AdminStatsHandler(w http.ResponseWriter, r *http.Request) {
+	counts, err := app.Models.SystemStats.Counts(r.Context())
+	if err != nil {
+		app.ErrorLog.Printf("Error getting system counts: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	byRole, err := app.Models.User.CountByRole(r.Context())
+	if err != nil {
+		app.ErrorLog.Printf("Error counting users by role: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	response := AdminResponse{
+		Success:     true,
+		Message:     "System statistics retrieved successfully",
+		Counts:      counts,
+		UsersByRole: byRole,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminNotificationHealthHandler reports the state of each notification
+// provider. With ?check=true the providers are health checked first.
+func (app *Config) AdminNotificationHealthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("check") == "true" {
+		app.Notifier.CheckHealth(r.Context())
+	}
+
+	response := AdminResponse{
+		Success:   true,
+		Message:   "Notification provider status retrieved successfully",
+		Providers: app.Notifier.Status(),
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// adminTargetUser loads the user named by the {id} URL parameter (UUID)
+func (app *Config) adminTargetUser(w http.ResponseWriter, r *http.Request) (*data.User, bool) {
+	userID := resourceID(r)
+	if userID == "" {
+		app.errorJSON(w, errors.New("user ID is required"), http.StatusBadRequest)
+		return nil, false
+	}
+
+	user, err := app.Models.User.GetByUserID(r.Context(), userID)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting user: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	if user == nil {
+		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+		return nil, false
+	}
+
+	return user, true
+}
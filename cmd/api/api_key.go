@@ -0,0 +1,172 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/farm"
+	"farm4u/service/integration"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scopeResources are the top-level API paths each API key scope may read.
+// Paths no scope lists, such as /admin or /api-keys, are closed to keys.
+var scopeResources = map[string][]string{
+	integration.ScopeFarms:          {"farms"},
+	integration.ScopeFields:         {"fields", "rainfall", "irrigation", "paddocks", "grazing"},
+	integration.ScopeCrops:          {"crops", "crop-plans", "plan-scenarios", "crop-incidents", "sprays"},
+	integration.ScopeLivestock:      {"livestock", "breeding", "births", "production", "feeding", "mortality", "weights"},
+	integration.ScopeEmployees:      {"employees", "payroll", "attendance"},
+	integration.ScopeFinance:        {"transactions", "finance", "tax-rates", "assets", "utilities"},
+	integration.ScopeInventory:      {"inventory", "chemicals", "equipment", "purchases", "water-sources"},
+	integration.ScopeSustainability: {"sustainability"},
+}
+
+// APIKeyRequest represents the API key issuance request body
+type APIKeyRequest struct {
+	Name      string     `json:"name"`   // Who the key is for, e.g. Acme Insurance
+	FarmID    string     `json:"farmId"` // Limits the key to one farm; all the user's farms if empty
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt"` // Never expires if omitted
+}
+
+// APIKeyResponse represents the API key response
+type APIKeyResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Key     *data.APIKey   `json:"key,omitempty"`
+	Keys    []*data.APIKey `json:"keys,omitempty"`
+	// APIKey is only returned when a key is issued
+	APIKey string `json:"apiKey,omitempty"`
+}
+
+// APIKeyMiddleware authenticates a partner by the API key in the X-API-Key
+// header, in place of a JWT. The request acts as the farmer who issued the
+// key, but may only read the paths the key's scopes cover and, for a key
+// issued for one farm, only that farm's records.
+func (app *Config) APIKeyMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			app.errorJSON(w, errors.New("API keys are read-only"), http.StatusForbidden)
+			return
+		}
+
+		key, user, err := app.Services.Integration.AuthenticateKey(r.Context(), r.Header.Get(apiKeyHeader))
+		if err != nil {
+			app.serviceError(w, err)
+			return
+		}
+
+		resource := apiResource(r.URL.Path)
+		allowed := slices.ContainsFunc(key.Scopes, func(scope string) bool {
+			return slices.Contains(scopeResources[scope], resource)
+		})
+		if !allowed {
+			app.errorJSON(w, errors.New("API key scopes do not cover this resource"), http.StatusForbidden)
+			return
+		}
+
+		if key.FarmID != nil {
+			r = r.WithContext(farm.Restrict(r.Context(), *key.FarmID))
+		}
+		r.Header.Set("X-User-ID", strconv.Itoa(int(user.ID)))
+		r.Header.Set("X-User-Email", user.Email)
+		r.Header.Set("X-User-Role", user.Role)
+
+		app.FarmAccess(next)(w, r)
+	}
+}
+
+// apiResource returns the top-level path a request is for, such as crops for
+// /api/v1/crops/{id}
+func apiResource(path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	path = strings.TrimPrefix(path, "/v1")
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	return resource
+}
+
+// CreateAPIKeyHandler handles issuing a read-only API key for a partner to
+// pull the authenticated user's farm data with. The key is only returned
+// here.
+func (app *Config) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	key, value, err := app.Services.Integration.CreateAPIKey(r.Context(), user, integration.APIKeyInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := APIKeyResponse{
+		Success: true,
+		Message: "API key created successfully",
+		Key:     key,
+		APIKey:  value,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetAPIKeysHandler handles listing the API keys the authenticated user has
+// issued and not revoked
+func (app *Config) GetAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := app.Services.Integration.ListAPIKeys(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := APIKeyResponse{
+		Success: true,
+		Message: "API keys retrieved successfully",
+		Keys:    keys,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RevokeAPIKeyHandler handles revoking an API key, withdrawing the partner's
+// access at once
+func (app *Config) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeyID := resourceID(r)
+	if apiKeyID == "" {
+		app.errorJSON(w, errors.New("API key ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Integration.RevokeAPIKey(r.Context(), user, apiKeyID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := APIKeyResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"farm4u/notify"
+	"farm4u/service/auth"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// insecureJWTSecret is the secret the API used to fall back to. Anyone can
+// sign tokens with it, so it is refused.
+const insecureJWTSecret = "your-super-secret-jwt-key"
+
+// minProductionSecretLength is the shortest JWT secret accepted in production
+const minProductionSecretLength = 32
+
+// Environments, selected with APP_ENV
+const (
+	envDevelopment = "development"
+	envProduction  = "production"
+)
+
+// Defaults for the settings that are not kept with the code using them
+const (
+	defaultPort           = 9005
+	defaultJWTExpiration  = 24 * time.Hour
+	defaultStorageURL     = "file://uploads"
+	defaultRedisURL       = "redis://localhost:6379/0"
+	defaultJWTKeyID       = "primary"
+	dotEnvFile            = ".env"
+	defaultRateLimitStore = "memory"
+	defaultDBHost         = "localhost"
+	defaultDBPort         = "5433"
+	defaultDBUser         = "postgres"
+	defaultDBPassword     = "postgres"
+	defaultDBName         = "farm_manager_4u"
+	defaultDBSSLMode      = "disable"
+)
+
+// AppConfig is the API's configuration, read from the environment once at
+// startup by loadAppConfig. The rest of the API reads its settings from here
+// rather than from the environment.
+type AppConfig struct {
+	Env  string // APP_ENV: development (default) or production
+	Port int    // PORT
+
+	// JWTSecret signs tokens with HS256, unless JWTPrivateKeyFile is set, and
+	// verifies tokens issued before keys had IDs. One of the two is required.
+	JWTSecret         string        // JWT_SECRET
+	JWTKeyID          string        // JWT_KEY_ID, the kid of the signing key
+	JWTPrivateKeyFile string        // JWT_PRIVATE_KEY_FILE, a PEM RSA key to sign with RS256
+	JWTExpiration     time.Duration // JWT_EXPIRATION_HOURS
+	// Keys no longer used for signing but still accepted, by kid, while
+	// tokens signed with them are valid
+	JWTPreviousSecrets map[string]string // JWT_PREVIOUS_SECRETS: kid=secret,...
+	JWTPublicKeyFiles  map[string]string // JWT_PUBLIC_KEY_FILES: kid=path,... to PEM RSA public keys
+
+	DB DBConfig
+
+	StorageURL      string                    // STORAGE_URL
+	CacheURL        string                    // CACHE_URL
+	CacheTTL        time.Duration             // CACHE_TTL
+	WeatherURL      string                    // WEATHER_URL
+	MarketPricesURL string                    // MARKET_PRICES_URL
+	NotifyProviders map[notify.Channel]string // NOTIFY_EMAIL_PROVIDERS, NOTIFY_SMS_PROVIDERS, NOTIFY_PUSH_PROVIDERS
+
+	// PasswordPolicy is the rules for new passwords: PASSWORD_MIN_LENGTH,
+	// PASSWORD_REQUIRE_COMPLEXITY and PASSWORD_BANNED_FILE, a file of
+	// passwords to refuse besides the common ones, one per line
+	PasswordPolicy auth.PasswordPolicy
+
+	RateLimitStore string        // RATE_LIMIT_STORE: memory or redis
+	RedisURL       string        // REDIS_URL, for the redis rate limit store
+	APIRateLimit   int           // API_RATE_LIMIT
+	AuthRateLimits authRateRules // AUTH_RATE_LIMIT_IP, AUTH_RATE_LIMIT_EMAIL, AUTH_RATE_WINDOW
+}
+
+// DBConfig holds the database connection settings
+type DBConfig struct {
+	// DSN is DSN if set, else built from DB_HOST, DB_PORT, DB_USER,
+	// DB_PASSWORD and DB_NAME
+	DSN             string
+	MigrateOnStart  bool          // MIGRATE_ON_START
+	QueryTimeout    time.Duration // DB_QUERY_TIMEOUT
+	MaxOpenConns    int           // DB_MAX_OPEN_CONNS
+	MaxIdleConns    int           // DB_MAX_IDLE_CONNS
+	ConnMaxLifetime time.Duration // DB_CONN_MAX_LIFETIME
+	ConnMaxIdleTime time.Duration // DB_CONN_MAX_IDLE_TIME
+	ConnectAttempts int           // DB_CONNECT_ATTEMPTS
+	ConnectBackoff  time.Duration // DB_CONNECT_BACKOFF
+}
+
+// Production reports whether the API runs in production
+func (c *AppConfig) Production() bool {
+	return c.Env == envProduction
+}
+
+// loadAppConfig reads and validates the configuration. Outside production,
+// variables in a .env file in the working directory are loaded first, without
+// overriding those already set. Every invalid setting is reported at once.
+func loadAppConfig() (*AppConfig, error) {
+	if os.Getenv("APP_ENV") != envProduction {
+		if err := loadDotEnv(dotEnvFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var env envReader
+	cfg := &AppConfig{
+		Env:                env.oneOf("APP_ENV", envDevelopment, envDevelopment, envProduction),
+		Port:               env.int("PORT", defaultPort),
+		JWTSecret:          env.string("JWT_SECRET", ""),
+		JWTKeyID:           env.string("JWT_KEY_ID", defaultJWTKeyID),
+		JWTPrivateKeyFile:  env.string("JWT_PRIVATE_KEY_FILE", ""),
+		JWTExpiration:      time.Duration(env.int("JWT_EXPIRATION_HOURS", int(defaultJWTExpiration/time.Hour))) * time.Hour,
+		JWTPreviousSecrets: env.pairs("JWT_PREVIOUS_SECRETS"),
+		JWTPublicKeyFiles:  env.pairs("JWT_PUBLIC_KEY_FILES"),
+
+		DB: DBConfig{
+			DSN:             env.string("DSN", ""),
+			MigrateOnStart:  env.bool("MIGRATE_ON_START", true),
+			QueryTimeout:    env.duration("DB_QUERY_TIMEOUT", defaultQueryTimeout),
+			MaxOpenConns:    env.int("DB_MAX_OPEN_CONNS", defaultMaxOpenConns),
+			MaxIdleConns:    env.int("DB_MAX_IDLE_CONNS", defaultMaxIdleConns),
+			ConnMaxLifetime: env.duration("DB_CONN_MAX_LIFETIME", defaultConnMaxLifetime),
+			ConnMaxIdleTime: env.duration("DB_CONN_MAX_IDLE_TIME", defaultConnMaxIdleTime),
+			ConnectAttempts: env.int("DB_CONNECT_ATTEMPTS", defaultConnectAttempts),
+			ConnectBackoff:  env.duration("DB_CONNECT_BACKOFF", defaultConnectBackoff),
+		},
+
+		StorageURL:      env.string("STORAGE_URL", defaultStorageURL),
+		CacheURL:        env.string("CACHE_URL", ""),
+		CacheTTL:        env.duration("CACHE_TTL", defaultCacheTTL),
+		WeatherURL:      env.string("WEATHER_URL", ""),
+		MarketPricesURL: env.string("MARKET_PRICES_URL", ""),
+		NotifyProviders: map[notify.Channel]string{
+			notify.Email: env.string("NOTIFY_EMAIL_PROVIDERS", ""),
+			notify.SMS:   env.string("NOTIFY_SMS_PROVIDERS", ""),
+			notify.Push:  env.string("NOTIFY_PUSH_PROVIDERS", ""),
+		},
+
+		RateLimitStore: env.oneOf("RATE_LIMIT_STORE", defaultRateLimitStore, "memory", "redis"),
+		RedisURL:       env.string("REDIS_URL", defaultRedisURL),
+		APIRateLimit:   env.int("API_RATE_LIMIT", defaultAPIRateLimit),
+	}
+
+	window := env.duration("AUTH_RATE_WINDOW", defaultAuthRateWindow)
+	cfg.AuthRateLimits = authRateRules{
+		PerIP:    rateRule{Limit: env.int("AUTH_RATE_LIMIT_IP", defaultAuthIPLimit), Window: window},
+		PerEmail: rateRule{Limit: env.int("AUTH_RATE_LIMIT_EMAIL", defaultAuthEmailLimit), Window: window},
+	}
+
+	var banned []string
+	if path := env.string("PASSWORD_BANNED_FILE", ""); path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			env.fail("PASSWORD_BANNED_FILE", fmt.Sprintf("cannot be read: %v", err))
+		}
+		banned = strings.Split(string(contents), "\n")
+	}
+	cfg.PasswordPolicy = auth.NewPasswordPolicy(
+		env.int("PASSWORD_MIN_LENGTH", auth.DefaultMinPasswordLength),
+		env.bool("PASSWORD_REQUIRE_COMPLEXITY", false),
+		banned,
+	)
+
+	if cfg.DB.DSN == "" {
+		cfg.DB.DSN = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			env.string("DB_HOST", defaultDBHost), env.string("DB_PORT", defaultDBPort),
+			env.string("DB_USER", defaultDBUser), env.string("DB_PASSWORD", defaultDBPassword),
+			env.string("DB_NAME", defaultDBName), defaultDBSSLMode)
+	}
+
+	if cfg.Port > 65535 {
+		env.fail("PORT", "must be at most 65535")
+	}
+	if cfg.JWTSecret == "" && cfg.JWTPrivateKeyFile == "" {
+		env.fail("JWT_SECRET", "must be set, or JWT_PRIVATE_KEY_FILE to sign with RS256")
+	}
+	checkSecret := func(name, secret string) {
+		switch {
+		case secret == insecureJWTSecret:
+			env.fail(name, "is the well-known default secret; choose a random one")
+		case secret != "" && cfg.Production() && len(secret) < minProductionSecretLength:
+			env.fail(name, fmt.Sprintf("must be at least %d characters in production", minProductionSecretLength))
+		}
+	}
+	checkSecret("JWT_SECRET", cfg.JWTSecret)
+	for _, kid := range slices.Sorted(maps.Keys(cfg.JWTPreviousSecrets)) {
+		checkSecret("JWT_PREVIOUS_SECRETS key "+kid, cfg.JWTPreviousSecrets[kid])
+	}
+
+	if err := errors.Join(env.errs...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// envReader reads typed settings from the environment, collecting an error
+// for each malformed one so they can all be reported together
+type envReader struct {
+	errs []error
+}
+
+// fail records that the variable name is invalid
+func (e *envReader) fail(name, problem string) {
+	e.errs = append(e.errs, fmt.Errorf("%s %s", name, problem))
+}
+
+// string returns the variable name, or def if it is unset or empty
+func (e *envReader) string(name, def string) string {
+	if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+		return v
+	}
+	return def
+}
+
+// oneOf returns the variable name, which must be one of allowed, or def
+func (e *envReader) oneOf(name, def string, allowed ...string) string {
+	v := e.string(name, def)
+	for _, a := range allowed {
+		if v == a {
+			return v
+		}
+	}
+	e.fail(name, fmt.Sprintf("must be one of %s, got %q", strings.Join(allowed, ", "), v))
+	return def
+}
+
+// int returns the variable name as a positive integer, or def
+func (e *envReader) int(name string, def int) int {
+	v := e.string(name, "")
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		e.fail(name, fmt.Sprintf("must be a positive integer, got %q", v))
+		return def
+	}
+	return n
+}
+
+// duration returns the variable name as a positive duration (e.g. "15m"), or def
+func (e *envReader) duration(name string, def time.Duration) time.Duration {
+	v := e.string(name, "")
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		e.fail(name, fmt.Sprintf("must be a positive duration such as 30s or 15m, got %q", v))
+		return def
+	}
+	return d
+}
+
+// pairs returns the variable name, a comma-separated list of key=value
+// pairs, as a map
+func (e *envReader) pairs(name string) map[string]string {
+	pairs := map[string]string{}
+	v := e.string(name, "")
+	if v == "" {
+		return pairs
+	}
+	for _, pair := range strings.Split(v, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" || value == "" {
+			e.fail(name, "must be a comma-separated list of key=value pairs")
+			return map[string]string{}
+		}
+		pairs[key] = value
+	}
+	return pairs
+}
+
+// bool returns the variable name as a boolean, or def
+func (e *envReader) bool(name string, def bool) bool {
+	v := e.string(name, "")
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		e.fail(name, fmt.Sprintf("must be true or false, got %q", v))
+		return def
+	}
+	return b
+}
+
+// loadDotEnv sets the variables in a .env file of KEY=value lines that are
+// not already set. Blank lines and lines starting with # are skipped, and a
+// value may be quoted. A missing file is not an error.
+func loadDotEnv(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: expected KEY=value", path, line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return nil
+}
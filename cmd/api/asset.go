@@ -0,0 +1,374 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/asset"
+	"net/http"
+	"time"
+)
+
+// assetCategories are the categories an asset may be registered under
+var assetCategories = []string{data.AssetEquipment, data.AssetStructure, data.AssetBreedingStock, data.AssetLand, data.AssetOther}
+
+// AssetRequest represents the asset creation/update request body
+type AssetRequest struct {
+	Name               string     `json:"name"`
+	Category           string     `json:"category"`
+	EquipmentID        *string    `json:"equipmentId"`
+	LivestockID        *string    `json:"livestockId"`
+	AcquisitionDate    *time.Time `json:"acquisitionDate"`
+	AcquisitionCost    *float64   `json:"acquisitionCost"`
+	DepreciationMethod *string    `json:"depreciationMethod"` // Equipment and structures: Straight-line or Declining-balance; empty clears the depreciation terms
+	UsefulLifeYears    int        `json:"usefulLifeYears"`
+	SalvageValue       *float64   `json:"salvageValue"`
+	DepreciationRate   *float64   `json:"depreciationRate"` // Declining-balance annual percentage
+	Notes              string     `json:"notes"`
+}
+
+// AssetRevaluationRequest represents the asset revaluation request body
+type AssetRevaluationRequest struct {
+	Date  *time.Time `json:"date"`
+	Value float64    `json:"value"`
+	Notes string     `json:"notes"`
+}
+
+// AssetDisposalRequest represents the asset disposal request body
+type AssetDisposalRequest struct {
+	Date     *time.Time `json:"date"`
+	Method   string     `json:"method"`
+	Proceeds float64    `json:"proceeds"`
+	Notes    string     `json:"notes"`
+}
+
+// AssetResponse represents the asset response
+type AssetResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Asset   *data.Asset   `json:"asset,omitempty"`
+	Assets  []*data.Asset `json:"assets,omitempty"`
+}
+
+// BalanceSheetResponse represents the balance sheet response
+type BalanceSheetResponse struct {
+	Success      bool                `json:"success"`
+	Message      string              `json:"message"`
+	BalanceSheet *asset.BalanceSheet `json:"balanceSheet"`
+}
+
+// Validate checks the asset request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *AssetRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	linked := (req.EquipmentID != nil && *req.EquipmentID != "") || (req.LivestockID != nil && *req.LivestockID != "")
+	if !partial && !linked {
+		v.Required("name", req.Name)
+		v.Required("category", req.Category)
+		v.Check(req.AcquisitionDate != nil, "acquisitionDate", "is required")
+	}
+	v.OneOf("category", req.Category, assetCategories...)
+	v.Check(req.EquipmentID == nil || req.LivestockID == nil || *req.EquipmentID == "" || *req.LivestockID == "",
+		"livestockId", "an asset is registered from equipment or livestock, not both")
+	v.Check(req.AcquisitionCost == nil || *req.AcquisitionCost >= 0, "acquisitionCost", "must be >= 0")
+	if req.DepreciationMethod != nil && *req.DepreciationMethod != "" {
+		v.OneOf("depreciationMethod", *req.DepreciationMethod, data.DepreciationStraightLine, data.DepreciationDecliningBalance)
+	}
+	v.Check(req.UsefulLifeYears >= 0, "usefulLifeYears", "must be 0 or more")
+	v.Check(req.SalvageValue == nil || *req.SalvageValue >= 0, "salvageValue", "must be >= 0")
+	v.Check(req.DepreciationRate == nil || (*req.DepreciationRate > 0 && *req.DepreciationRate <= 100), "depreciationRate", "must be between 0 and 100")
+	return v.Errors()
+}
+
+// Validate checks the asset revaluation request fields
+func (req *AssetRevaluationRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Value >= 0, "value", "must be >= 0")
+	return v.Errors()
+}
+
+// Validate checks the asset disposal request fields
+func (req *AssetDisposalRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("method", req.Method)
+	v.OneOf("method", req.Method, data.DisposalSale, data.DisposalWriteOff)
+	v.Check(req.Proceeds >= 0, "proceeds", "must be >= 0")
+	v.Check(req.Method != data.DisposalSale || req.Proceeds > 0, "proceeds", "must be greater than 0 for a sale")
+	return v.Errors()
+}
+
+// CreateAssetHandler handles registering an asset, either directly or from an
+// equipment or livestock record
+func (app *Config) CreateAssetHandler(w http.ResponseWriter, r *http.Request) {
+	var req AssetRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	registered, err := app.Services.Asset.Create(r.Context(), user, farmID, asset.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Asset registered successfully",
+		Asset:   registered,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetAssetsHandler handles retrieving a farm's asset register by ?status=
+// (default Active; "all" for every status)
+func (app *Config) GetAssetsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = asset.StatusActive
+	case "all":
+		status = ""
+	case asset.StatusActive, asset.StatusDisposed:
+	default:
+		app.errorJSON(w, errors.New("status must be Active, Disposed or all"), http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	assets, err := app.Services.Asset.List(r.Context(), user, farmID, status)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Assets retrieved successfully",
+		Assets:  assets,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAssetHandler handles retrieving a single asset with its events
+func (app *Config) GetAssetHandler(w http.ResponseWriter, r *http.Request) {
+	assetID := resourceID(r)
+	if assetID == "" {
+		app.errorJSON(w, errors.New("asset ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := app.Services.Asset.Get(r.Context(), user, assetID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Asset retrieved successfully",
+		Asset:   found,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateAssetHandler handles changes to an asset's name, category or notes
+func (app *Config) UpdateAssetHandler(w http.ResponseWriter, r *http.Request) {
+	var req AssetRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	assetID := resourceID(r)
+	if assetID == "" {
+		app.errorJSON(w, errors.New("asset ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := app.Services.Asset.Update(r.Context(), user, assetID, asset.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Asset updated successfully",
+		Asset:   updated,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteAssetHandler handles removing an asset registered in error
+func (app *Config) DeleteAssetHandler(w http.ResponseWriter, r *http.Request) {
+	assetID := resourceID(r)
+	if assetID == "" {
+		app.errorJSON(w, errors.New("asset ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Asset.Delete(r.Context(), user, assetID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Asset deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RevalueAssetHandler handles recording a new book value for an asset
+func (app *Config) RevalueAssetHandler(w http.ResponseWriter, r *http.Request) {
+	var req AssetRevaluationRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	assetID := resourceID(r)
+	if assetID == "" {
+		app.errorJSON(w, errors.New("asset ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	revalued, err := app.Services.Asset.Revalue(r.Context(), user, assetID, asset.RevaluationInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Asset revalued successfully",
+		Asset:   revalued,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DisposeAssetHandler handles selling or writing off an asset
+func (app *Config) DisposeAssetHandler(w http.ResponseWriter, r *http.Request) {
+	var req AssetDisposalRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	assetID := resourceID(r)
+	if assetID == "" {
+		app.errorJSON(w, errors.New("asset ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	disposed, err := app.Services.Asset.Dispose(r.Context(), user, assetID, asset.DisposalInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AssetResponse{
+		Success: true,
+		Message: "Asset disposed of successfully",
+		Asset:   disposed,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBalanceSheetHandler values a farm's assets at the end of ?asOf=
+// (YYYY-MM-DD, default today) for lenders
+func (app *Config) GetBalanceSheetHandler(w http.ResponseWriter, r *http.Request) {
+	asOf := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := r.URL.Query().Get("asOf"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			app.errorJSON(w, errors.New("asOf must be in YYYY-MM-DD format"), http.StatusBadRequest)
+			return
+		}
+		asOf = t
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	sheet, err := app.Services.Asset.BalanceSheet(r.Context(), user, farmID, asOf)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BalanceSheetResponse{
+		Success:      true,
+		Message:      "Balance sheet generated successfully",
+		BalanceSheet: sheet,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/attachment"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// AttachmentRequest represents the attachment creation request body. The
+// file itself is sent afterwards to the upload URL in the response.
+type AttachmentRequest struct {
+	RecordType  string `json:"recordType"` // crop, livestock, equipment, maintenance, transaction, document, incident
+	RecordID    string `json:"recordId"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"` // image/jpeg, image/png, image/webp, image/heic or application/pdf
+	Size        int64  `json:"size"`        // Bytes
+	Caption     string `json:"caption"`
+}
+
+// AttachmentResponse represents the attachment response
+type AttachmentResponse struct {
+	Success     bool               `json:"success"`
+	Message     string             `json:"message"`
+	Attachment  *data.Attachment   `json:"attachment,omitempty"`
+	Attachments []*data.Attachment `json:"attachments,omitempty"`
+	// Upload is where to send a pending attachment's file
+	Upload *attachment.Transfer `json:"upload,omitempty"`
+	// Download is where to fetch an uploaded attachment's file
+	Download *attachment.Transfer `json:"download,omitempty"`
+}
+
+// Validate checks the attachment request fields
+func (req *AttachmentRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.OneOf("recordType", req.RecordType, attachment.RecordTypes...)
+	v.Required("recordId", req.RecordID)
+	v.Required("fileName", req.FileName)
+	v.Check(len(req.FileName) <= 255, "fileName", "must be at most 255 characters")
+	v.OneOf("contentType", req.ContentType, attachment.ContentTypes...)
+	v.Check(req.Size > 0, "size", "must be greater than 0")
+	v.Check(req.Size <= attachment.MaxSize, "size", fmt.Sprintf("must be at most %d MB", attachment.MaxSize>>20))
+	return v.Errors()
+}
+
+// attachmentContentURL is the API endpoint that sends or receives an
+// attachment's file
+func attachmentContentURL(a *data.Attachment) string {
+	return "/api/v1/attachments/" + a.AttachmentID + "/content"
+}
+
+// attachmentUpload returns upload, or the API's content endpoint when the
+// storage backend gave no presigned URL
+func attachmentUpload(a *data.Attachment, upload *attachment.Transfer) *attachment.Transfer {
+	if upload != nil {
+		return upload
+	}
+	return &attachment.Transfer{
+		URL:     attachmentContentURL(a),
+		Method:  http.MethodPut,
+		Headers: map[string]string{"Content-Type": a.ContentType},
+	}
+}
+
+// CreateAttachmentHandler handles attaching a file to a record. The response
+// says where to upload the file: a presigned URL when the storage backend
+// supports it, the API's content endpoint otherwise.
+func (app *Config) CreateAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req AttachmentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	a, upload, err := app.Services.Attachment.Create(r.Context(), user, attachment.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	response := AttachmentResponse{
+		Success:    true,
+		Message:    "Attachment created; upload the file to complete it",
+		Attachment: a,
+		Upload:     attachmentUpload(a, upload),
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// UploadAttachmentHandler handles receiving an attachment's file as the raw
+// request body, for storage backends without presigned URLs
+func (app *Config) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID := resourceID(r)
+	if attachmentID == "" {
+		app.errorJSON(w, errors.New("attachment ID is required"), http.StatusBadRequest)
+		return
+	}
+	if r.ContentLength > attachment.MaxSize {
+		app.errorJSON(w, fmt.Errorf("files can be at most %d MB", attachment.MaxSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, attachment.MaxSize)
+	a, err := app.Services.Attachment.Upload(r.Context(), user, attachmentID, body, r.ContentLength)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		app.errorJSON(w, fmt.Errorf("files can be at most %d MB", attachment.MaxSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttachmentResponse{
+		Success:    true,
+		Message:    "File uploaded successfully",
+		Attachment: a,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CompleteAttachmentHandler handles marking an attachment uploaded once its
+// file has been sent to the presigned upload URL
+func (app *Config) CompleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID := resourceID(r)
+	if attachmentID == "" {
+		app.errorJSON(w, errors.New("attachment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	a, err := app.Services.Attachment.Complete(r.Context(), user, attachmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttachmentResponse{
+		Success:    true,
+		Message:    "Attachment uploaded successfully",
+		Attachment: a,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAttachmentsHandler handles retrieving the attachments of a record
+// (?recordType=&recordId=) or of a whole farm (?farmId=)
+func (app *Config) GetAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	recordType := r.URL.Query().Get("recordType")
+	recordID := r.URL.Query().Get("recordId")
+	farmID := r.URL.Query().Get("farmId")
+	if farmID == "" && (recordType == "" || recordID == "") {
+		app.errorJSON(w, errors.New("farm ID, or record type and record ID, are required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var attachments []*data.Attachment
+	var err error
+	if recordType != "" && recordID != "" {
+		attachments, err = app.Services.Attachment.List(r.Context(), user, recordType, recordID)
+	} else {
+		attachments, err = app.Services.Attachment.ListFarm(r.Context(), user, farmID)
+	}
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttachmentResponse{
+		Success:     true,
+		Message:     "Attachments retrieved successfully",
+		Attachments: attachments,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAttachmentHandler handles retrieving an attachment and where to
+// download its file
+func (app *Config) GetAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID := resourceID(r)
+	if attachmentID == "" {
+		app.errorJSON(w, errors.New("attachment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	a, download, err := app.Services.Attachment.Get(r.Context(), user, attachmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	if download == nil && a.Status == attachment.StatusUploaded {
+		download = &attachment.Transfer{URL: attachmentContentURL(a), Method: http.MethodGet}
+	}
+
+	response := AttachmentResponse{
+		Success:    true,
+		Message:    "Attachment retrieved successfully",
+		Attachment: a,
+		Download:   download,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DownloadAttachmentHandler handles sending an uploaded attachment's file
+func (app *Config) DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID := resourceID(r)
+	if attachmentID == "" {
+		app.errorJSON(w, errors.New("attachment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	app.sendAttachment(w, r, user, attachmentID)
+}
+
+// sendAttachment writes an uploaded attachment's file as the response
+func (app *Config) sendAttachment(w http.ResponseWriter, r *http.Request, user *data.User, attachmentID string) {
+	file, a, err := app.Services.Attachment.Open(r.Context(), user, attachmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", a.FileName))
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	if _, err := io.Copy(w, file); err != nil {
+		app.ErrorLog.Printf("Error sending attachment %s: %v", a.AttachmentID, err)
+	}
+}
+
+// DeleteAttachmentHandler handles deleting an attachment and its file
+func (app *Config) DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachmentID := resourceID(r)
+	if attachmentID == "" {
+		app.errorJSON(w, errors.New("attachment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Attachment.Delete(r.Context(), user, attachmentID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttachmentResponse{
+		Success: true,
+		Message: "Attachment deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
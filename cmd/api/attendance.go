@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/workforce"
+	"net/http"
+	"time"
+)
+
+// AttendanceRequest represents the clock-in/clock-out request body
+type AttendanceRequest struct {
+	Action string     `json:"action"` // clock-in or clock-out
+	Time   *time.Time `json:"time"`   // Defaults to now
+	Notes  string     `json:"notes"`
+}
+
+// AttendanceResponse represents the attendance response
+type AttendanceResponse struct {
+	Success     bool                 `json:"success"`
+	Message     string               `json:"message"`
+	Attendance  *data.Attendance     `json:"attendance,omitempty"`
+	Records     []*data.Attendance   `json:"records,omitempty"`
+	WeeklyHours []data.WeeklyHours   `json:"weeklyHours,omitempty"`
+	Absentees   []workforce.Absentee `json:"absentees,omitempty"`
+}
+
+// Validate checks the clock-in/clock-out request fields
+func (req *AttendanceRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("action", req.Action)
+	v.OneOf("action", req.Action, workforce.ClockIn, workforce.ClockOut)
+	if req.Time != nil {
+		v.Check(!req.Time.After(time.Now()), "time", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// ClockAttendanceHandler handles an employee clocking in or out
+func (app *Config) ClockAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	var req AttendanceRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	attendance, err := app.Services.Workforce.Clock(r.Context(), user, employeeID, workforce.ClockInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	status, message := http.StatusOK, "Clocked out successfully"
+	if req.Action == workforce.ClockIn {
+		status, message = http.StatusCreated, "Clocked in successfully"
+	}
+
+	response := AttendanceResponse{
+		Success:    true,
+		Message:    message,
+		Attendance: attendance,
+	}
+
+	app.writeJSON(w, status, response)
+}
+
+// GetEmployeeAttendanceHandler handles retrieving an employee's attendance,
+// optionally limited by ?from=/?to=
+func (app *Config) GetEmployeeAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Workforce.ListAttendance(r.Context(), user, employeeID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttendanceResponse{
+		Success: true,
+		Message: "Attendance retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteAttendanceHandler handles deleting an attendance record
+func (app *Config) DeleteAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	attendanceID := resourceID(r)
+	if attendanceID == "" {
+		app.errorJSON(w, errors.New("attendance ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Workforce.DeleteAttendance(r.Context(), user, attendanceID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttendanceResponse{
+		Success: true,
+		Message: "Attendance record deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWeeklyHoursHandler reports hours worked per employee and week on a
+// farm, optionally limited by ?employeeId= and ?from=/?to= (default the last
+// four weeks)
+func (app *Config) GetWeeklyHoursHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	hours, err := app.Services.Workforce.WeeklyHours(r.Context(), user, farmID, r.URL.Query().Get("employeeId"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttendanceResponse{
+		Success:     true,
+		Message:     "Weekly hours retrieved successfully",
+		WeeklyHours: hours,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAbsenteeReportHandler reports the working days each active employee on
+// a farm did not clock in, over ?from=/?to= (default the last four weeks)
+func (app *Config) GetAbsenteeReportHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	absentees, err := app.Services.Workforce.AbsenteeReport(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AttendanceResponse{
+		Success:   true,
+		Message:   "Absentee report generated successfully",
+		Absentees: absentees,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,1055 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"farm4u/data"
+	"farm4u/service/asset"
+	"farm4u/service/attachment"
+	"farm4u/service/auth"
+	"farm4u/service/breeding"
+	"farm4u/service/chemical"
+	"farm4u/service/coop"
+	"farm4u/service/crop"
+	"farm4u/service/dairy"
+	"farm4u/service/dispute"
+	"farm4u/service/document"
+	"farm4u/service/equipment"
+	"farm4u/service/escrow"
+	"farm4u/service/farm"
+	"farm4u/service/feeding"
+	"farm4u/service/field"
+	"farm4u/service/finance"
+	"farm4u/service/grazing"
+	"farm4u/service/growth"
+	"farm4u/service/importer"
+	"farm4u/service/integration"
+	"farm4u/service/inventory"
+	"farm4u/service/irrigation"
+	"farm4u/service/livestock"
+	"farm4u/service/loan"
+	"farm4u/service/lock"
+	"farm4u/service/mortality"
+	"farm4u/service/note"
+	"farm4u/service/production"
+	"farm4u/service/provider"
+	"farm4u/service/purchase"
+	"farm4u/service/rainfall"
+	"farm4u/service/report"
+	"farm4u/service/season"
+	"farm4u/service/spray"
+	"farm4u/service/sustainability"
+	"farm4u/service/tag"
+	"farm4u/service/utility"
+	"farm4u/service/view"
+	"farm4u/service/water"
+	"farm4u/service/workforce"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// matrixOut names a file to write the authorization matrix to as Markdown:
+//
+//	go test ./cmd/api -run TestAuthzMatrix -authz-matrix authz-matrix.md
+var matrixOut = flag.String("authz-matrix", "", "write the authorization matrix as Markdown to this file")
+
+// Callers the matrix makes requests as, besides one member per farm role
+const (
+	callerAnonymous = "anonymous" // No token
+	callerStranger  = "stranger"  // Signed in, owns a different farm
+	callerOwner     = "owner"     // Owns the farm and every record requests target
+)
+
+// missingID fills the parameters of routes that need no seeded record, such
+// as the admin routes every non-admin is turned away from
+const missingID = "00000000-0000-0000-0000-000000000000"
+
+// publicRoutes need no token
+var publicRoutes = []string{
+	"POST /auth/signup",
+	"POST /auth/login",
+	"POST /auth/phone/request",
+	"POST /auth/phone/verify",
+	"POST /auth/forgot-password",
+	"POST /auth/reset-password",
+}
+
+// sharedRoutes return data open to every signed-in user, such as market
+// prices, buyer profiles and the lists of webhook events and import fields,
+// so any caller may use them. Patterns ending in "*" match every route
+// under them.
+var sharedRoutes = []string{
+	"* /market/*",
+	"GET /buyers/",
+	"GET /buyers/{id}",
+	"GET /ratings/",
+	"GET /imports/fields",
+	"GET /webhooks/events",
+}
+
+// personalRoutes act on the caller's own account, or on records it holds
+// outside any farm: API keys, exports, notifications, the event stream,
+// co-operatives, collection centers and marketplace sales. Any signed-in
+// caller may use those without a record ID; the records that fill an ID are
+// the owner's, so only the owner may reach them.
+var personalRoutes = []string{
+	"POST /auth/refresh-token",
+	"POST /auth/logout",
+	"* /users/me/*",
+	"GET /me",
+	"* /me/*",
+	"GET /events",
+	"* /api-keys/*",
+	"* /notifications/*",
+	"GET /farms/",
+	"POST /farms/",
+	"GET /farms/trash",
+	"GET /farms/archived",
+	"GET /farms/shared",
+	"* /organizations/*",
+	"* /organization-members/*",
+	"* /procurement-windows/*",
+	"* /procurement-allocations/*",
+	"* /collection-centers/*",
+	"* /buyers/me/*",
+	"POST /ratings/",
+	"* /disputes/*",
+	"* /escrows/*",
+	"GET /reports/portfolio",
+	"GET /crops/all",
+	"GET /livestock/all",
+	"GET /transactions/all",
+}
+
+// centerRoutes are opened by a collection center's API key rather than a
+// user's token, so every caller is turned away from them
+var centerRoutes = []string{
+	"* /collection/*",
+}
+
+// buyerRoutes are for buyer accounts, or for the buyer in a sale, so the
+// owner, a farmer and the seller in the seeded sale, is turned away from them
+var buyerRoutes = []string{
+	"PUT /buyers/me/verification",
+	"POST /escrows/",
+	"POST /escrows/{id}/confirm",
+}
+
+// lastRoutes are called after every other route, in this order, as each
+// changes the farm or the caller's session for the calls that follow
+var lastRoutes = []string{
+	"POST /farms/{id}/archive",
+	"POST /farms/{id}/unarchive",
+	"DELETE /farms/{id}",
+	"POST /farms/{id}/restore",
+	"POST /auth/logout",
+}
+
+// routeModules maps route prefixes to the module of the permissions matrix
+// their records belong to; other farm routes are for the owner only
+var routeModules = map[string]farm.Module{
+	"/transactions/":        farm.ModuleFinance,
+	"/finance/":             farm.ModuleFinance,
+	"/tax-rates/":           farm.ModuleFinance,
+	"/exchange-rates/":      farm.ModuleFinance,
+	"/budgets/":             farm.ModuleFinance,
+	"/loans/":               farm.ModuleFinance,
+	"/payroll/":             farm.ModulePayroll,
+	"/reports/":             farm.ModuleReports,
+	"/assets/balance-sheet": farm.ModuleReports,
+}
+
+// paramValues fill route parameters other than {id}
+var paramValues = map[string]string{
+	"{type}": report.TypeFarmSummary,
+}
+
+// paramRecords fill route parameters other than {id} with the record seeded
+// under a route prefix
+var paramRecords = map[string]string{
+	"{webhookID}": "/webhooks/",
+}
+
+// routeParam matches a route parameter such as {id}
+var routeParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// matrixRoute is one registered route and the calls made to it
+type matrixRoute struct {
+	Method  string
+	Pattern string
+	Seeded  bool // The route's {id} was filled with a seeded record
+	Cells   map[string]*matrixCell
+}
+
+// matrixCell is one caller's call to a route
+type matrixCell struct {
+	Status int
+	Failed string // Why the status is wrong; empty when it is allowed
+}
+
+// matrixSeed holds the callers and the records their requests target
+type matrixSeed struct {
+	tokens  map[string]string
+	roles   map[string]string // Caller to farm role
+	farmID  string
+	records map[string]string // Route prefix to a record ID
+	order   []string          // Route prefixes in the order their records were made
+}
+
+// add records id as the record that routes under prefix target
+func (seed *matrixSeed) add(prefix, id string) {
+	seed.records[prefix] = id
+	seed.order = append(seed.order, prefix)
+}
+
+// TestAuthzMatrix calls every API route as each kind of caller and checks
+// the status codes against who may use the route, so a handler that forgets
+// its ownership check fails the test. Every route's {id} is filled with a
+// real record of the owner's, and the owner calls every route after the
+// others, with every method.
+func TestAuthzMatrix(t *testing.T) {
+	app := newTestApp(t)
+	// The matrix only checks who may open the event stream; a closed hub
+	// ends each stream as soon as it starts
+	app.Live.Close()
+
+	seed, err := app.seedMatrix(context.Background())
+	if err != nil {
+		t.Fatalf("seeding: %v", err)
+	}
+
+	api := app.apiRoutes()
+	srv := httptest.NewServer(middleware.Recoverer(api))
+	defer srv.Close()
+
+	routes, err := runMatrix(srv, api, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callers := matrixCallers(seed)
+	for _, route := range routes {
+		if !route.Seeded {
+			t.Errorf("%s %s: no record is seeded for its {id}", route.Method, route.Pattern)
+		}
+		for _, caller := range callers {
+			if cell := route.Cells[caller]; cell != nil && cell.Failed != "" {
+				t.Errorf("%s %s as %s: %d, %s", route.Method, route.Pattern, caller, cell.Status, cell.Failed)
+			}
+		}
+	}
+
+	if *matrixOut != "" {
+		f, err := os.Create(*matrixOut)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		writeMatrix(f, routes, callers)
+	}
+}
+
+// TestAuthzRoutes checks the matrix's route lists and its route-to-module
+// mapping against the router, without a database: every entry must still
+// name a registered route, and a farm member's success on a route must be
+// judged by the permissions matrix for the route's module.
+func TestAuthzRoutes(t *testing.T) {
+	routes, err := walkRoutes((&Config{}).apiRoutes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	find := func(method, pattern string) *matrixRoute {
+		for _, route := range routes {
+			if route.Method == method && route.Pattern == pattern {
+				return route
+			}
+		}
+		return nil
+	}
+
+	lists := map[string][]string{
+		"publicRoutes": publicRoutes, "sharedRoutes": sharedRoutes, "personalRoutes": personalRoutes,
+		"centerRoutes": centerRoutes, "buyerRoutes": buyerRoutes, "lastRoutes": lastRoutes,
+	}
+	for name, patterns := range lists {
+		for _, pattern := range patterns {
+			if !slices.ContainsFunc(routes, func(route *matrixRoute) bool { return route.matches([]string{pattern}) }) {
+				t.Errorf("%s: %q matches no route", name, pattern)
+			}
+		}
+	}
+	for prefix := range routeModules {
+		if !slices.ContainsFunc(routes, func(route *matrixRoute) bool { return strings.HasPrefix(route.Pattern, prefix) }) {
+			t.Errorf("routeModules: %q matches no route", prefix)
+		}
+	}
+
+	tests := []struct {
+		method  string
+		pattern string
+		role    string
+		allowed bool
+	}{
+		{method: "GET", pattern: "/transactions/", role: farm.RoleAccountant, allowed: true},
+		{method: "GET", pattern: "/transactions/{id}", role: farm.RoleAccountant, allowed: true},
+		{method: "POST", pattern: "/transactions/", role: farm.RoleAccountant},
+		{method: "DELETE", pattern: "/transactions/{id}", role: farm.RoleAccountant},
+		{method: "GET", pattern: "/tax-rates/", role: farm.RoleAccountant, allowed: true},
+		{method: "GET", pattern: "/loans/outstanding", role: farm.RoleAccountant, allowed: true},
+		{method: "POST", pattern: "/loans/{id}/repayments", role: farm.RoleAccountant},
+		{method: "GET", pattern: "/payroll/summary", role: farm.RoleAccountant, allowed: true},
+		{method: "GET", pattern: "/finance/profitability", role: farm.RoleAccountant, allowed: true},
+		{method: "GET", pattern: "/assets/balance-sheet", role: farm.RoleAccountant, allowed: true},
+		{method: "GET", pattern: "/assets/", role: farm.RoleAccountant},
+		{method: "GET", pattern: "/crops/", role: farm.RoleAccountant},
+		{method: "GET", pattern: "/employees/", role: farm.RoleAccountant},
+	}
+	for _, tt := range tests {
+		route := find(tt.method, tt.pattern)
+		if route == nil {
+			t.Errorf("%s %s is not registered", tt.method, tt.pattern)
+			continue
+		}
+		failed := route.check(tt.role, tt.role, http.StatusOK)
+		if tt.allowed && failed != "" {
+			t.Errorf("%s %s as %s: %s", tt.method, tt.pattern, tt.role, failed)
+		}
+		if !tt.allowed && failed == "" {
+			t.Errorf("%s %s as %s: success was not flagged", tt.method, tt.pattern, tt.role)
+		}
+		if failed := route.check(tt.role, tt.role, http.StatusForbidden); failed != "" {
+			t.Errorf("%s %s as %s: denial was flagged: %s", tt.method, tt.pattern, tt.role, failed)
+		}
+	}
+}
+
+// seedMatrix creates the owner's farm with a record behind every route's
+// {id}, the owner's records outside the farm, a member for each farm role,
+// a stranger with a farm of their own, and tokens for them all
+func (app *Config) seedMatrix(ctx context.Context) (*matrixSeed, error) {
+	run := time.Now().UnixNano()
+	seed := &matrixSeed{tokens: map[string]string{}, roles: map[string]string{}, records: map[string]string{}}
+
+	newUser := func(name, role string) (*data.User, error) {
+		user := &data.User{
+			FirstName:    "Matrix",
+			LastName:     name,
+			Email:        fmt.Sprintf("authz-matrix+%s-%d@example.com", name, run),
+			TempPassword: fmt.Sprintf("Matrix-%d", run),
+			Role:         role,
+			Active:       true,
+		}
+		if err := app.Models.User.Insert(ctx, user); err != nil {
+			return nil, fmt.Errorf("creating %s user: %w", name, err)
+		}
+		return user, nil
+	}
+
+	users := map[string]*data.User{}
+	callers := []string{callerOwner, callerStranger}
+	for _, role := range farm.Roles() {
+		caller := strings.ToLower(role)
+		callers = append(callers, caller)
+		seed.roles[caller] = role
+	}
+	for _, caller := range callers {
+		user, err := newUser(caller, auth.DefaultRole)
+		if err != nil {
+			return nil, err
+		}
+		token, err := app.GenerateJWT(user)
+		if err != nil {
+			return nil, fmt.Errorf("signing %s token: %w", caller, err)
+		}
+		users[caller], seed.tokens[caller] = user, token
+	}
+	// Neither calls a route: the colleague is the member the owner removes
+	// from the farm and the co-operative, the buyer the other party to a sale
+	colleague, err := newUser("colleague", auth.DefaultRole)
+	if err != nil {
+		return nil, err
+	}
+	buyer, err := newUser("buyer", auth.BuyerRole)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := users[callerOwner]
+	f, err := app.Services.Farm.Create(ctx, owner, farm.Input{Name: "Authorization matrix farm", Location: "Matrix", Size: 10})
+	if err != nil {
+		return nil, fmt.Errorf("creating farm: %w", err)
+	}
+	farmID := f.FarmID
+	seed.farmID = farmID
+	seed.add("/farms/", farmID)
+	if _, err := app.Services.Farm.Create(ctx, users[callerStranger], farm.Input{Name: "Stranger's farm", Location: "Elsewhere", Size: 5}); err != nil {
+		return nil, fmt.Errorf("creating stranger's farm: %w", err)
+	}
+	for caller, role := range seed.roles {
+		if _, err := app.Services.Farm.AddMember(ctx, owner, farmID, farm.MemberInput{Email: users[caller].Email, Role: role}); err != nil {
+			return nil, fmt.Errorf("adding %s member: %w", role, err)
+		}
+	}
+	member, err := app.Services.Farm.AddMember(ctx, owner, farmID, farm.MemberInput{Email: colleague.Email, Role: farm.RoleAccountant})
+	if err != nil {
+		return nil, fmt.Errorf("adding colleague: %w", err)
+	}
+	seed.add("/farm-members/", member.FarmMemberID)
+
+	now := time.Now()
+	lastMonth, nextMonth, nextWeek := now.AddDate(0, -1, 0), now.AddDate(0, 1, 0), now.AddDate(0, 0, 7)
+	harvest := now.AddDate(0, 5, 0)
+	shiftStart := now.AddDate(0, 0, 1).Truncate(time.Hour)
+	shiftEnd := shiftStart.Add(8 * time.Hour)
+	lockStart := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	lockEnd := lockStart.AddDate(0, 1, -1)
+	cost, mm := 5000.0, 12.5
+
+	// Fields, seasons and crops
+	fl, err := app.Services.Field.Create(ctx, owner, farmID, field.Input{Name: "North field", Area: 2})
+	if err != nil {
+		return nil, fmt.Errorf("creating field: %w", err)
+	}
+	seed.add("/fields/", fl.FieldID)
+	s, err := app.Services.Season.Create(ctx, owner, farmID, season.Input{Name: "Matrix season", StartDate: &lastMonth, EndDate: &harvest})
+	if err != nil {
+		return nil, fmt.Errorf("creating season: %w", err)
+	}
+	seed.add("/seasons/", s.SeasonID)
+	c, err := app.Services.Crop.Create(ctx, owner, farmID, crop.Input{Name: "Maize", Quantity: 100, FieldID: &fl.FieldID})
+	if err != nil {
+		return nil, fmt.Errorf("creating crop: %w", err)
+	}
+	seed.add("/crops/", c.CropID)
+	plan, _, err := app.Services.Crop.CreatePlan(ctx, owner, farmID, crop.PlanInput{
+		FieldID: fl.FieldID, CropName: "Beans", PlantingStart: &nextWeek, PlantingEnd: &nextMonth, ExpectedHarvest: &harvest, ExpectedYield: 500,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating crop plan: %w", err)
+	}
+	seed.add("/crop-plans/", plan.CropPlanID)
+	sc, err := app.Services.Crop.CreateScenario(ctx, owner, plan.CropPlanID, crop.ScenarioInput{Name: "Good year", PricePerKg: 2})
+	if err != nil {
+		return nil, fmt.Errorf("creating plan scenario: %w", err)
+	}
+	seed.add("/plan-scenarios/", sc.PlanScenarioID)
+	incident, err := app.Services.Crop.CreateIncident(ctx, owner, farmID, crop.IncidentInput{
+		CropID: c.CropID, Type: crop.IncidentPest, Name: "Fall armyworm", Severity: crop.SeverityLow,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating crop incident: %w", err)
+	}
+	seed.add("/crop-incidents/", incident.CropIncidentID)
+	rain, err := app.Services.Rainfall.Create(ctx, owner, farmID, rainfall.Input{FieldID: &fl.FieldID, Date: &lastMonth, MM: &mm})
+	if err != nil {
+		return nil, fmt.Errorf("creating rainfall record: %w", err)
+	}
+	seed.add("/rainfall/", rain.RainfallRecordID)
+	ws, err := app.Services.Water.Create(ctx, owner, farmID, water.Input{Name: "Borehole", SourceType: "Borehole"})
+	if err != nil {
+		return nil, fmt.Errorf("creating water source: %w", err)
+	}
+	seed.add("/water-sources/", ws.WaterSourceID)
+	irr, err := app.Services.Irrigation.Create(ctx, owner, farmID, irrigation.Input{
+		FieldID: &fl.FieldID, CropID: &c.CropID, WaterSourceID: &ws.WaterSourceID, Method: "Drip", StartDate: &nextWeek, IntervalDays: 3, Volume: 1000,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating irrigation schedule: %w", err)
+	}
+	seed.add("/irrigation/", irr.IrrigationScheduleID)
+
+	// Livestock
+	l, err := app.Services.Livestock.Create(ctx, owner, farmID, livestock.Input{Type: "Cattle", Count: 3})
+	if err != nil {
+		return nil, fmt.Errorf("creating livestock: %w", err)
+	}
+	seed.add("/livestock/", l.LivestockID)
+	event, err := app.Services.Breeding.CreateEvent(ctx, owner, farmID, breeding.EventInput{
+		LivestockID: l.LivestockID, DamTag: "C-1", ServiceDate: &lastMonth, ExpectedDueDate: &harvest,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating breeding event: %w", err)
+	}
+	seed.add("/breeding/", event.BreedingEventID)
+	birth, err := app.Services.Breeding.RecordBirth(ctx, owner, farmID, breeding.BirthInput{
+		BreedingEventID: event.BreedingEventID, LivestockID: l.LivestockID, DamTag: "C-1", LiveBorn: 1, Female: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recording birth: %w", err)
+	}
+	seed.add("/births/", birth.BirthRecordID)
+	prod, err := app.Services.Production.Create(ctx, owner, farmID, production.Input{
+		LivestockID: l.LivestockID, ProductType: production.ProductMilk, Quantity: 10, Unit: "litres",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating production record: %w", err)
+	}
+	seed.add("/production/", prod.ProductionRecordID)
+	weight, err := app.Services.Growth.Create(ctx, owner, farmID, growth.Input{LivestockID: l.LivestockID, Weight: 300, HeadCount: 1})
+	if err != nil {
+		return nil, fmt.Errorf("creating weight record: %w", err)
+	}
+	seed.add("/weights/", weight.WeightRecordID)
+	loss, err := app.Services.Mortality.Record(ctx, owner, farmID, mortality.Input{
+		LivestockID: l.LivestockID, Kind: mortality.KindDeath, Count: 1, Cause: "Disease",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recording mortality: %w", err)
+	}
+	seed.add("/mortality/", loss.MortalityRecordID)
+	paddock, err := app.Services.Grazing.CreatePaddock(ctx, owner, farmID, grazing.PaddockInput{FieldID: &fl.FieldID, Name: "Paddock A", Area: 1, RestDays: 30})
+	if err != nil {
+		return nil, fmt.Errorf("creating paddock: %w", err)
+	}
+	seed.add("/paddocks/", paddock.PaddockID)
+	move, _, err := app.Services.Grazing.RecordMove(ctx, owner, farmID, grazing.MoveInput{LivestockID: l.LivestockID, PaddockID: paddock.PaddockID})
+	if err != nil {
+		return nil, fmt.Errorf("recording grazing move: %w", err)
+	}
+	seed.add("/grazing/moves/", move.GrazingMoveID)
+
+	// Stores, equipment and suppliers
+	feed, err := app.Services.Inventory.Create(ctx, owner, farmID, inventory.Input{Name: "Dairy meal", Category: "Feed", Unit: "kg"})
+	if err != nil {
+		return nil, fmt.Errorf("creating inventory item: %w", err)
+	}
+	seed.add("/inventory/", feed.InventoryItemID)
+	if _, err := app.Services.Inventory.Receive(ctx, owner, feed.InventoryItemID, inventory.BatchInput{BatchNumber: "B-1", Quantity: 50, UnitCost: 2}); err != nil {
+		return nil, fmt.Errorf("receiving inventory: %w", err)
+	}
+	fed, _, err := app.Services.Feeding.Record(ctx, owner, farmID, feeding.Input{LivestockID: l.LivestockID, InventoryItemID: feed.InventoryItemID, Quantity: 1})
+	if err != nil {
+		return nil, fmt.Errorf("recording feeding: %w", err)
+	}
+	seed.add("/feeding/", fed.FeedingRecordID)
+	chem, err := app.Services.Chemical.Create(ctx, owner, farmID, chemical.Input{Name: "Cypermethrin", Category: "Insecticide", Quantity: 10, Unit: "L"})
+	if err != nil {
+		return nil, fmt.Errorf("creating chemical: %w", err)
+	}
+	seed.add("/chemicals/", chem.ChemicalProductID)
+	sprayed, err := app.Services.Spray.Record(ctx, owner, farmID, spray.Input{
+		CropID: c.CropID, ChemicalProductID: chem.ChemicalProductID, Rate: 1, RateUnit: "L/ha", QuantityUsed: 1, Applicator: "Matrix", PPEConfirmed: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recording spray: %w", err)
+	}
+	seed.add("/sprays/", sprayed.SprayRecordID)
+	eq, err := app.Services.Equipment.Create(ctx, owner, farmID, equipment.Input{Name: "Tractor", Type: "Tractor"})
+	if err != nil {
+		return nil, fmt.Errorf("creating equipment: %w", err)
+	}
+	seed.add("/equipment/", eq.EquipmentID)
+	maintenance, err := app.Services.Equipment.LogMaintenance(ctx, owner, eq.EquipmentID, equipment.MaintenanceInput{Date: &lastMonth, Type: "Service", Description: "Oil change"})
+	if err != nil {
+		return nil, fmt.Errorf("logging maintenance: %w", err)
+	}
+	seed.add("/equipment/maintenance/", maintenance.MaintenanceRecordID)
+	a, err := app.Services.Asset.Create(ctx, owner, farmID, asset.Input{Name: "Farm land", Category: "Land", AcquisitionDate: &lastMonth, AcquisitionCost: &cost})
+	if err != nil {
+		return nil, fmt.Errorf("creating asset: %w", err)
+	}
+	seed.add("/assets/", a.AssetID)
+	supplier, err := app.Services.Purchase.CreateSupplier(ctx, owner, farmID, purchase.SupplierInput{Name: "Agro dealer"})
+	if err != nil {
+		return nil, fmt.Errorf("creating supplier: %w", err)
+	}
+	seed.add("/purchases/suppliers/", supplier.SupplierID)
+	order, err := app.Services.Purchase.CreateOrder(ctx, owner, farmID, purchase.OrderInput{
+		SupplierID: supplier.SupplierID, Lines: []purchase.LineInput{{InventoryItemID: feed.InventoryItemID, Quantity: 5, UnitCost: 2}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating purchase order: %w", err)
+	}
+	seed.add("/purchases/orders/", order.PurchaseOrderID)
+	sp, err := app.Services.Provider.Create(ctx, owner, farmID, provider.Input{Name: "Vet services", Category: provider.CategoryVeterinarian})
+	if err != nil {
+		return nil, fmt.Errorf("creating service provider: %w", err)
+	}
+	seed.add("/service-providers/", sp.ServiceProviderID)
+	utilityRecord, err := app.Services.Utility.Create(ctx, owner, farmID, utility.Input{UtilityType: "Electricity", RecordType: "Bill", Date: &lastMonth, Quantity: 100, Unit: "kWh", Cost: 50})
+	if err != nil {
+		return nil, fmt.Errorf("creating utility record: %w", err)
+	}
+	seed.add("/utilities/", utilityRecord.UtilityRecordID)
+
+	// Workforce. The employee is the owner's own record of employment, so
+	// it also fills the self-service routes.
+	e, err := app.Services.Workforce.CreateEmployee(ctx, owner, farmID, workforce.EmployeeInput{
+		UserID: &owner.Email, FirstName: "Matrix", LastName: "Worker", Position: "Herder", Salary: 100,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating employee: %w", err)
+	}
+	seed.add("/employees/", e.EmployeeID)
+	seed.add("/me/employment/", e.EmployeeID)
+	attendance, err := app.Services.Workforce.Clock(ctx, owner, e.EmployeeID, workforce.ClockInput{Action: workforce.ClockIn})
+	if err != nil {
+		return nil, fmt.Errorf("clocking in: %w", err)
+	}
+	seed.add("/attendance/", attendance.AttendanceID)
+	payment, err := app.Services.Workforce.RecordPayment(ctx, owner, e.EmployeeID, workforce.PaymentInput{
+		PeriodStart: &lastMonth, PeriodEnd: &now, GrossPay: 100, PaymentDate: &now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("recording payment: %w", err)
+	}
+	seed.add("/payroll/", payment.PayrollPaymentID)
+	shift, err := app.Services.Workforce.CreateShift(ctx, owner, farmID, workforce.ShiftInput{
+		EmployeeID: e.EmployeeID, FieldID: &fl.FieldID, StartsAt: &shiftStart, EndsAt: &shiftEnd, Role: "Herding",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating shift: %w", err)
+	}
+	seed.add("/shifts/", shift.ShiftID)
+	doc, err := app.Services.Document.Create(ctx, owner, farmID, document.Input{Type: "Licence", Title: "Driving licence", EmployeeID: e.EmployeeID})
+	if err != nil {
+		return nil, fmt.Errorf("creating document: %w", err)
+	}
+	seed.add("/documents/", doc.DocumentID)
+
+	// Finance
+	tx, err := app.Services.Finance.CreateTransaction(ctx, owner, farmID, finance.TransactionInput{Type: "Income", Category: "Sales", Amount: 100, Date: &now})
+	if err != nil {
+		return nil, fmt.Errorf("creating transaction: %w", err)
+	}
+	seed.add("/transactions/", tx.TransactionID)
+	rate := 18.0
+	taxRate, err := app.Services.Finance.CreateTaxRate(ctx, owner, farmID, finance.TaxRateInput{Name: "VAT", Rate: &rate})
+	if err != nil {
+		return nil, fmt.Errorf("creating tax rate: %w", err)
+	}
+	seed.add("/tax-rates/", taxRate.TaxRateID)
+	budget, err := app.Services.Finance.CreateBudgetLine(ctx, owner, farmID, finance.BudgetLineInput{SeasonID: s.SeasonID, Type: "Expense", Category: "Seeds", PlannedAmount: 100})
+	if err != nil {
+		return nil, fmt.Errorf("creating budget line: %w", err)
+	}
+	seed.add("/budgets/", budget.BudgetLineID)
+	exchange, err := app.Services.Finance.CreateExchangeRate(ctx, owner, farmID, finance.ExchangeRateInput{Currency: "USD", Rate: 3700, Date: &now})
+	if err != nil {
+		return nil, fmt.Errorf("creating exchange rate: %w", err)
+	}
+	seed.add("/exchange-rates/", exchange.ExchangeRateID)
+	ln, err := app.Services.Loan.Create(ctx, owner, farmID, loan.Input{Lender: "Matrix bank", Principal: 1000, DisbursementDate: &lastMonth, TermMonths: 12})
+	if err != nil {
+		return nil, fmt.Errorf("creating loan: %w", err)
+	}
+	seed.add("/loans/", ln.LoanID)
+	repaid, err := app.Services.Loan.Repay(ctx, owner, ln.LoanID, loan.RepaymentInput{Amount: 100, Date: &now})
+	if err != nil {
+		return nil, fmt.Errorf("repaying loan: %w", err)
+	}
+	seed.add("/loans/repayments/", repaid.Repayments[len(repaid.Repayments)-1].LoanRepaymentID)
+	// A locked period far in the past leaves the records above writable
+	locked, err := app.Services.Lock.Lock(ctx, owner, farmID, lock.Input{PeriodStart: &lockStart, PeriodEnd: &lockEnd, Reason: "Audited"})
+	if err != nil {
+		return nil, fmt.Errorf("locking period: %w", err)
+	}
+	seed.add("/period-locks/", locked.PeriodLockID)
+
+	// Sustainability
+	practice, err := app.Services.Sustainability.CreatePractice(ctx, owner, farmID, sustainability.PracticeInput{Category: "Soil", Name: "Cover crops"})
+	if err != nil {
+		return nil, fmt.Errorf("creating practice: %w", err)
+	}
+	seed.add("/sustainability/practices/", practice.SustainabilityPracticeID)
+	assessment, err := app.Services.Sustainability.CreateAssessment(ctx, owner, farmID, sustainability.AssessmentInput{Season: "Matrix season"})
+	if err != nil {
+		return nil, fmt.Errorf("creating assessment: %w", err)
+	}
+	seed.add("/sustainability/assessments/", assessment.SustainabilityAssessmentID)
+
+	// Records about records: attachments, notes, tags, views, imports,
+	// reports and webhooks
+	att, _, err := app.Services.Attachment.Create(ctx, owner, attachment.Input{
+		RecordType: attachment.RecordCrop, RecordID: c.CropID, FileName: "maize.jpg", ContentType: "image/jpeg", Size: 3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating attachment: %w", err)
+	}
+	seed.add("/attachments/", att.AttachmentID)
+	n, err := app.Services.Note.Create(ctx, owner, note.Input{RecordType: note.RecordCrop, RecordID: c.CropID, Body: "Looking healthy"})
+	if err != nil {
+		return nil, fmt.Errorf("creating note: %w", err)
+	}
+	seed.add("/notes/", n.NoteID)
+	tg, err := app.Services.Tag.Create(ctx, owner, farmID, tag.Input{Name: "Matrix"})
+	if err != nil {
+		return nil, fmt.Errorf("creating tag: %w", err)
+	}
+	seed.add("/tags/", tg.TagID)
+	v, err := app.Services.View.Create(ctx, owner, farmID, view.Input{Name: "Maize", Entity: "crops", Params: map[string]string{"q": "maize"}})
+	if err != nil {
+		return nil, fmt.Errorf("creating view: %w", err)
+	}
+	seed.add("/views/", v.SavedViewID)
+	imp, err := app.Services.Import.Upload(ctx, owner, farmID, importer.UploadInput{
+		Target: importer.TargetCrops, FileName: "crops.csv", Content: []byte("name,quantity\nBeans,10\n"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("uploading import: %w", err)
+	}
+	seed.add("/imports/", imp.ImportJobID)
+	job, err := app.Services.Report.Request(ctx, owner, farmID, report.TypeFarmSummary, fmt.Sprint(now.Year()))
+	if err != nil {
+		return nil, fmt.Errorf("requesting report: %w", err)
+	}
+	seed.add("/reports/jobs/", job.ReportJobID)
+	// Stored directly: creating a webhook through its service resolves the
+	// address, which needs the network
+	hook := &data.Webhook{FarmID: farmID, URL: "https://example.com/hooks/farm4u", Secret: "matrix", EventTypes: []string{"*"}, Active: true, CreatedBy: owner.UserID}
+	if err := app.Models.Webhook.Insert(ctx, hook); err != nil {
+		return nil, fmt.Errorf("creating webhook: %w", err)
+	}
+	seed.add("/webhooks/", hook.WebhookID)
+
+	// The owner's records outside the farm
+	key, _, err := app.Services.Integration.CreateAPIKey(ctx, owner, integration.APIKeyInput{Name: "Matrix partner", Scopes: []string{integration.ScopeCrops}})
+	if err != nil {
+		return nil, fmt.Errorf("creating API key: %w", err)
+	}
+	seed.add("/api-keys/", key.APIKeyID)
+	export, err := app.Services.Export.Request(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("requesting export: %w", err)
+	}
+	seed.add("/me/export/", export.ExportJobID)
+	notification := &data.Notification{UserID: owner.UserID, FarmID: &farmID, Type: "authz_matrix", Title: "Matrix notification"}
+	if err := app.Models.Notification.Insert(ctx, notification); err != nil {
+		return nil, fmt.Errorf("creating notification: %w", err)
+	}
+	seed.add("/notifications/", notification.NotificationID)
+
+	org, err := app.Services.Coop.CreateOrganization(ctx, owner, coop.OrganizationInput{Name: "Matrix co-operative"})
+	if err != nil {
+		return nil, fmt.Errorf("creating organization: %w", err)
+	}
+	seed.add("/organizations/", org.OrganizationID)
+	orgMember, err := app.Services.Coop.AddMember(ctx, owner, org.OrganizationID, coop.MemberInput{Email: colleague.Email, Role: coop.RoleMember})
+	if err != nil {
+		return nil, fmt.Errorf("adding organization member: %w", err)
+	}
+	seed.add("/organization-members/", orgMember.OrganizationMemberID)
+	window, err := app.Services.Coop.OpenWindow(ctx, owner, org.OrganizationID, coop.WindowInput{
+		Title: "Fertiliser", ClosesAt: nextWeek, Items: []coop.ItemInput{{Name: "NPK", Unit: "bags", UnitPrice: 10}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("opening procurement window: %w", err)
+	}
+	seed.add("/procurement-windows/", window.ProcurementWindowID)
+	demand, err := app.Services.Coop.SubmitDemand(ctx, owner, window.ProcurementWindowID, coop.DemandInput{
+		FarmID: farmID, Lines: []coop.DemandLine{{ItemID: window.Items[0].ProcurementItemID, Quantity: 2}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("submitting procurement demand: %w", err)
+	}
+	seed.add("/procurement-allocations/", demand[0].ProcurementRequestID)
+
+	center, _, err := app.Services.Dairy.CreateCenter(ctx, owner, dairy.CenterInput{Name: "Matrix dairy", Location: "Matrix", PricePerLitre: 1})
+	if err != nil {
+		return nil, fmt.Errorf("creating collection center: %w", err)
+	}
+	seed.add("/collection-centers/", center.CollectionCenterID)
+	milkSupplier, err := app.Services.Dairy.LinkFarm(ctx, owner, center.CollectionCenterID, farmID, "S-1")
+	if err != nil {
+		return nil, fmt.Errorf("linking milk supplier: %w", err)
+	}
+	seed.add("/milk-suppliers/", milkSupplier.MilkSupplierID)
+
+	sale := fmt.Sprintf("MATRIX-%d", run)
+	held, err := app.Services.Escrow.Hold(ctx, buyer, escrow.Input{FarmerID: owner.UserID, SaleReference: sale, Amount: 100, PaymentReference: "PAY-1"})
+	if err != nil {
+		return nil, fmt.Errorf("holding escrow: %w", err)
+	}
+	seed.add("/escrows/", held.EscrowID)
+	disputed, err := app.Services.Dispute.Open(ctx, owner, dispute.Input{RespondentID: buyer.UserID, SaleReference: sale, Reason: "Late payment"})
+	if err != nil {
+		return nil, fmt.Errorf("opening dispute: %w", err)
+	}
+	seed.add("/disputes/", disputed.DisputeID)
+	seed.add("/buyers/", buyer.UserID)
+	return seed, nil
+}
+
+// matrixCallers lists the callers in column order: anonymous, the farm
+// roles, the stranger and the owner
+func matrixCallers(seed *matrixSeed) []string {
+	callers := []string{callerAnonymous}
+	for caller := range seed.roles {
+		callers = append(callers, caller)
+	}
+	slices.Sort(callers[1:])
+	return append(callers, callerStranger, callerOwner)
+}
+
+// runMatrix calls every route of api on srv as every caller, stage by stage.
+// Within a stage the owner goes after everyone else, so the owner's writes
+// and deletes leave the records in place for the other callers.
+func runMatrix(srv *httptest.Server, api http.Handler, seed *matrixSeed) ([]*matrixRoute, error) {
+	routes, err := walkRoutes(api)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(routes, func(a, b *matrixRoute) int {
+		if c := cmp.Compare(a.stage(), b.stage()); c != 0 {
+			return c
+		}
+		// Deletes go newest record first, so a record goes before those it
+		// was made from
+		if a.Method == http.MethodDelete && b.Method == http.MethodDelete {
+			if c := cmp.Compare(seed.rank(b.Pattern), seed.rank(a.Pattern)); c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.Pattern+a.Method, b.Pattern+b.Method)
+	})
+
+	callers := matrixCallers(seed)
+	others, owner := callers[:len(callers)-1], callers[len(callers)-1:]
+	for start := 0; start < len(routes); {
+		end := start + 1
+		for end < len(routes) && routes[end].stage() == routes[start].stage() {
+			end++
+		}
+		for _, group := range [][]string{others, owner} {
+			for _, route := range routes[start:end] {
+				for _, caller := range group {
+					status, err := call(srv, route, seed, caller)
+					if err != nil {
+						return nil, fmt.Errorf("%s %s as %s: %w", route.Method, route.Pattern, caller, err)
+					}
+					route.Cells[caller] = &matrixCell{Status: status, Failed: route.check(caller, seed.roles[caller], status)}
+				}
+			}
+		}
+		start = end
+	}
+	return routes, nil
+}
+
+// walkRoutes lists the routes registered on api
+func walkRoutes(api http.Handler) ([]*matrixRoute, error) {
+	mux, ok := api.(chi.Routes)
+	if !ok {
+		return nil, fmt.Errorf("API router cannot be walked")
+	}
+
+	var routes []*matrixRoute
+	err := chi.Walk(mux, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, &matrixRoute{Method: method, Pattern: pattern, Cells: map[string]*matrixCell{}})
+		return nil
+	})
+	return routes, err
+}
+
+// call makes caller's request to the route and returns the status
+func call(srv *httptest.Server, route *matrixRoute, seed *matrixSeed, caller string) (int, error) {
+	var body io.Reader
+	if route.Method != http.MethodGet && route.Method != http.MethodDelete {
+		body = bytes.NewBufferString(fmt.Sprintf(`{"farmId":%q}`, seed.farmID))
+	}
+	r, err := http.NewRequest(route.Method, srv.URL+route.path(seed), body)
+	if err != nil {
+		return 0, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if token := seed.tokens[caller]; token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := srv.Client().Do(r)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// stage orders the routes for calling: reads, then writes, then deletes,
+// then lastRoutes one at a time
+func (route *matrixRoute) stage() int {
+	if i := slices.Index(lastRoutes, route.Method+" "+route.Pattern); i >= 0 {
+		return 3 + i
+	}
+	switch route.Method {
+	case http.MethodGet:
+		return 0
+	case http.MethodDelete:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// record returns the seeded record the route's {id} names, by the longest
+// prefix of the route it was seeded under
+func (seed *matrixSeed) record(pattern string) (string, string, bool) {
+	best := ""
+	for prefix := range seed.records {
+		if strings.HasPrefix(pattern, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	id, ok := seed.records[best]
+	return best, id, ok
+}
+
+// rank returns when the record behind pattern was seeded, -1 for none
+func (seed *matrixSeed) rank(pattern string) int {
+	prefix, _, ok := seed.record(pattern)
+	if !ok {
+		return -1
+	}
+	return slices.Index(seed.order, prefix)
+}
+
+// path fills the route's parameters, {id} with the seeded record its prefix
+// names, and targets the owner's farm. Routes that need no seeded record
+// have their {id} filled with missingID.
+func (route *matrixRoute) path(seed *matrixSeed) string {
+	_, id, seeded := seed.record(route.Pattern)
+	needed := strings.Contains(route.Pattern, "{id}") && !route.matches(publicRoutes) && !route.matches(sharedRoutes) &&
+		!strings.HasPrefix(route.Pattern, "/admin/")
+	route.Seeded = seeded || !needed
+	if !seeded {
+		id = missingID
+	}
+	path := routeParam.ReplaceAllStringFunc(route.Pattern, func(param string) string {
+		if value, ok := paramValues[param]; ok {
+			return value
+		}
+		if record, ok := seed.records[paramRecords[param]]; ok {
+			return record
+		}
+		return id
+	})
+	return path + "?farmId=" + seed.farmID + "&id=" + id
+}
+
+// matches reports whether the route is one of patterns
+func (route *matrixRoute) matches(patterns []string) bool {
+	for _, p := range patterns {
+		method, pattern, _ := strings.Cut(p, " ")
+		if method != "*" && method != route.Method {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(route.Pattern, prefix) {
+			return true
+		}
+		if pattern == route.Pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns why status is wrong for caller, with farm role role, on the
+// route, or "" when it is allowed. Only a success where access should be
+// denied, or a denial of the owner, fails: other errors come from the
+// placeholder request body.
+func (route *matrixRoute) check(caller, role string, status int) string {
+	ok := status >= 200 && status < 300
+	denied := status == http.StatusUnauthorized || status == http.StatusForbidden
+	switch {
+	case route.matches(publicRoutes):
+		return ""
+	case caller == callerAnonymous:
+		if status != http.StatusUnauthorized {
+			return "expected 401 without a token"
+		}
+		return ""
+	case strings.HasPrefix(route.Pattern, "/admin/"):
+		if ok {
+			return "admin route allowed a non-admin"
+		}
+		return ""
+	case route.matches(sharedRoutes):
+		return ""
+	case route.matches(centerRoutes):
+		if ok {
+			return "a user's token opened a collection center route"
+		}
+		return ""
+	case caller == callerOwner:
+		if denied && !route.matches(buyerRoutes) {
+			return "owner denied"
+		}
+		return ""
+	case route.matches(personalRoutes):
+		if ok && routeParam.MatchString(route.Pattern) {
+			return caller + " reached the owner's record"
+		}
+		return ""
+	case caller == callerStranger:
+		if ok {
+			return "stranger reached the farm"
+		}
+		return ""
+	}
+
+	// A farm member may only do what their role allows on the route's module
+	if ok {
+		action := farm.Write
+		if route.Method == http.MethodGet {
+			action = farm.Read
+		}
+		for prefix, module := range routeModules {
+			if strings.HasPrefix(route.Pattern, prefix) && farm.Allowed(role, module, action) {
+				return ""
+			}
+		}
+		return role + " exceeded their role"
+	}
+	return ""
+}
+
+// writeMatrix writes the routes as a Markdown table
+func writeMatrix(w io.Writer, routes []*matrixRoute, callers []string) {
+	failed := 0
+	var failures []string
+	for _, route := range routes {
+		for _, caller := range callers {
+			if cell := route.Cells[caller]; cell != nil && cell.Failed != "" {
+				failed++
+				failures = append(failures, fmt.Sprintf("- `%s %s` as %s: %d, %s", route.Method, route.Pattern, caller, cell.Status, cell.Failed))
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "# Authorization matrix\n\n")
+	fmt.Fprintf(w, "%d routes, %d failed calls. Every {id} is a record of the owner's; the owner calls each\n", len(routes), failed)
+	fmt.Fprintf(w, "route after the other callers.\n\n")
+	if len(failures) > 0 {
+		fmt.Fprintf(w, "## Failures\n\n%s\n\n", strings.Join(failures, "\n"))
+	}
+
+	fmt.Fprintf(w, "| Method | Route | %s |\n", strings.Join(callers, " | "))
+	fmt.Fprintf(w, "|---|---|%s\n", strings.Repeat("---|", len(callers)))
+	for _, route := range routes {
+		cells := make([]string, len(callers))
+		for i, caller := range callers {
+			cell := route.Cells[caller]
+			switch {
+			case cell == nil:
+				cells[i] = "·"
+			case cell.Failed != "":
+				cells[i] = fmt.Sprintf("**%d ✗**", cell.Status)
+			default:
+				cells[i] = fmt.Sprint(cell.Status)
+			}
+		}
+		fmt.Fprintf(w, "| %s | `%s` | %s |\n", route.Method, route.Pattern, strings.Join(cells, " | "))
+	}
+}
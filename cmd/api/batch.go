@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// maxBatchItems caps the records a single batch request may create
+const maxBatchItems = 500
+
+// BatchResult is the outcome of one item of a batch request, by its position
+// in the request body
+type BatchResult struct {
+	Index   int              `json:"index"`
+	Success bool             `json:"success"`
+	Record  any              `json:"record,omitempty"`
+	Message string           `json:"message,omitempty"`
+	Errors  ValidationErrors `json:"errors,omitempty"`
+}
+
+// BatchResponse represents the response to a batch request. Success is true
+// only when every item was created.
+type BatchResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Created int           `json:"created"`
+	Failed  int           `json:"failed"`
+	Results []BatchResult `json:"results"`
+}
+
+// batchCreate handles a request whose body is a JSON array of items to create
+// on the farm in the farmId query parameter. Items that fail validation are
+// reported and left out; create makes the rest in a single transaction and
+// reports, in the slot of each item it was given, any item it rejected. The
+// response is 201 when every item was created, 207 when only some were and
+// 422 when none were.
+func batchCreate[T, R any](app *Config, w http.ResponseWriter, r *http.Request, what string,
+	validate func(*T) ValidationErrors, create func(*data.User, string, []T) ([]R, []error, error)) {
+	var items []T
+
+	if err := app.ReadJSON(w, r, &items); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(items) == 0 {
+		app.errorJSON(w, errors.New("at least one item is required"), http.StatusBadRequest)
+		return
+	}
+	if len(items) > maxBatchItems {
+		app.errorJSON(w, fmt.Errorf("a batch can hold at most %d items", maxBatchItems), http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	results := make([]BatchResult, len(items))
+	var valid []T
+	var positions []int
+	for i := range items {
+		results[i].Index = i
+		if errs := validate(&items[i]); errs != nil {
+			results[i].Message, results[i].Errors = errs.Error(), errs
+			continue
+		}
+		valid = append(valid, items[i])
+		positions = append(positions, i)
+	}
+
+	if len(valid) > 0 {
+		records, errs, err := create(user, farmID, valid)
+		if err != nil {
+			app.serviceError(w, err)
+			return
+		}
+		for j, i := range positions {
+			if errs != nil && errs[j] != nil {
+				results[i].Message = errs[j].Error()
+				continue
+			}
+			results[i].Success, results[i].Record = true, records[j]
+		}
+	}
+
+	response := BatchResponse{Results: results}
+	for _, result := range results {
+		if result.Success {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+	response.Success = response.Failed == 0
+	response.Message = fmt.Sprintf("%d of %d %s created", response.Created, len(items), what)
+
+	status := http.StatusCreated
+	switch {
+	case response.Created == 0:
+		status = http.StatusUnprocessableEntity
+	case response.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	app.writeJSON(w, status, response)
+}
+
+// BulkRequest represents the body of a request acting on several existing
+// records of the farm in the farmId query parameter
+type BulkRequest struct {
+	IDs    []string `json:"ids"`
+	Status string   `json:"status"` // The status to move the records to, for status updates
+}
+
+// BulkResponse represents the response to a bulk request. Success is true
+// only when every record was changed.
+type BulkResponse struct {
+	Success   bool          `json:"success"`
+	Message   string        `json:"message"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// Validate checks the bulk request fields. When statuses are given the
+// request is a status update and its status must be one of them.
+func (req *BulkRequest) Validate(statuses ...string) ValidationErrors {
+	v := newValidator()
+	v.Check(len(req.IDs) > 0, "ids", "at least one ID is required")
+	v.Check(len(req.IDs) <= maxBatchItems, "ids", fmt.Sprintf("at most %d IDs are allowed", maxBatchItems))
+	v.Check(!slices.Contains(req.IDs, ""), "ids", "must not contain blank IDs")
+	if len(statuses) > 0 {
+		v.Required("status", req.Status)
+		v.OneOf("status", req.Status, statuses...)
+	}
+	return v.Errors()
+}
+
+// bulkApply handles a request changing several records of the farm in the
+// farmId query parameter at once. apply changes them in a single transaction
+// and reports, in the slot of each ID, any record it left out. done describes
+// the change in the message, e.g. "updated". The response is 200 when every
+// record was changed, 207 when only some were and 422 when none were.
+func bulkApply[R any](app *Config, w http.ResponseWriter, r *http.Request, ids []string, what, done string,
+	apply func(*data.User, string) ([]R, []error, error)) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	records, errs, err := apply(user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BulkResponse{Results: make([]BatchResult, len(ids))}
+	for i := range ids {
+		response.Results[i].Index = i
+		if errs != nil && errs[i] != nil {
+			response.Results[i].Message = errs[i].Error()
+			response.Failed++
+			continue
+		}
+		response.Results[i].Success, response.Results[i].Record = true, records[i]
+		response.Succeeded++
+	}
+	response.Success = response.Failed == 0
+	response.Message = fmt.Sprintf("%d of %d %s %s", response.Succeeded, len(ids), what, done)
+
+	status := http.StatusOK
+	switch {
+	case response.Succeeded == 0:
+		status = http.StatusUnprocessableEntity
+	case response.Failed > 0:
+		status = http.StatusMultiStatus
+	}
+	app.writeJSON(w, status, response)
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/breeding"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultBreedingWindowDays is how far ahead the due list looks by default
+const defaultBreedingWindowDays = 30
+
+// BreedingEventRequest represents the breeding event creation/update request
+// body
+type BreedingEventRequest struct {
+	LivestockID     string     `json:"livestockId"` // Herd the dam belongs to
+	DamTag          string     `json:"damTag"`
+	SireTag         string     `json:"sireTag"`
+	ServiceDate     *time.Time `json:"serviceDate"`
+	Method          string     `json:"method"`          // Natural, Artificial Insemination, Embryo Transfer; defaults to Natural
+	ExpectedDueDate *time.Time `json:"expectedDueDate"` // Defaults to the service date plus the species' gestation
+	Outcome         string     `json:"outcome"`         // Pending, Pregnant, Open, Aborted; defaults to Pending
+	Notes           string     `json:"notes"`
+}
+
+// BirthRequest represents the request body for recording a calving, kidding
+// or lambing
+type BirthRequest struct {
+	BreedingEventID string     `json:"breedingEventId"` // Service the birth came from, if recorded
+	LivestockID     string     `json:"livestockId"`     // Required without breedingEventId
+	DamTag          string     `json:"damTag"`          // Required without breedingEventId
+	BirthDate       *time.Time `json:"birthDate"`       // Defaults to now
+	LiveBorn        int        `json:"liveBorn"`
+	Stillborn       int        `json:"stillborn"`
+	Male            int        `json:"male"`
+	Female          int        `json:"female"`
+	Assisted        bool       `json:"assisted"`
+	Notes           string     `json:"notes"`
+}
+
+// BreedingResponse represents the breeding event and birth response
+type BreedingResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message"`
+	Event   *data.BreedingEvent   `json:"event,omitempty"`
+	Events  []*data.BreedingEvent `json:"events,omitempty"`
+	Birth   *data.BirthRecord     `json:"birth,omitempty"`
+	Births  []*data.BirthRecord   `json:"births,omitempty"`
+}
+
+// Validate checks the breeding event request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *BreedingEventRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("livestockId", req.LivestockID)
+		v.Required("damTag", req.DamTag)
+		v.Check(req.ServiceDate != nil, "serviceDate", "is required")
+	}
+	v.OneOf("method", req.Method, breeding.Methods()...)
+	v.OneOf("outcome", req.Outcome, breeding.Outcomes()...)
+	if req.ServiceDate != nil && req.ExpectedDueDate != nil {
+		v.Check(req.ExpectedDueDate.After(*req.ServiceDate), "expectedDueDate", "must be after serviceDate")
+	}
+	return v.Errors()
+}
+
+// Validate checks the birth request fields
+func (req *BirthRequest) Validate() ValidationErrors {
+	v := newValidator()
+	if req.BreedingEventID == "" {
+		v.Required("livestockId", req.LivestockID)
+		v.Required("damTag", req.DamTag)
+	}
+	v.Check(req.LiveBorn >= 0, "liveBorn", "must not be negative")
+	v.Check(req.Stillborn >= 0, "stillborn", "must not be negative")
+	v.Check(req.LiveBorn+req.Stillborn > 0, "liveBorn", "liveBorn and stillborn must add up to at least 1")
+	v.Check(req.Male >= 0, "male", "must not be negative")
+	v.Check(req.Female >= 0, "female", "must not be negative")
+	v.Check(req.Male+req.Female <= req.LiveBorn, "male", "male and female must not add up to more than liveBorn")
+	return v.Errors()
+}
+
+// CreateBreedingEventHandler handles recording a dam being served
+func (app *Config) CreateBreedingEventHandler(w http.ResponseWriter, r *http.Request) {
+	var req BreedingEventRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := app.Services.Breeding.CreateEvent(r.Context(), user, farmID, breeding.EventInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Breeding event created successfully",
+		Event:   event,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetBreedingEventsHandler handles retrieving a farm's breeding events,
+// optionally filtered by the livestockId, damTag and outcome query
+// parameters
+func (app *Config) GetBreedingEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	filter := data.BreedingEventFilter{
+		LivestockID: r.URL.Query().Get("livestockId"),
+		DamTag:      r.URL.Query().Get("damTag"),
+		Outcome:     r.URL.Query().Get("outcome"),
+	}
+	events, err := app.Services.Breeding.ListEvents(r.Context(), user, farmID, filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Breeding events retrieved successfully",
+		Events:  events,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDueBreedingEventsHandler lists a farm's dams due to give birth within
+// ?days= days (default 30), including overdue ones
+func (app *Config) GetDueBreedingEventsHandler(w http.ResponseWriter, r *http.Request) {
+	days := defaultBreedingWindowDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 365 {
+			app.errorJSON(w, errors.New("days must be between 0 and 365"), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := app.Services.Breeding.Due(r.Context(), user, farmID, days)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Due breeding events retrieved successfully",
+		Events:  events,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBreedingEventHandler handles retrieving a single breeding event by ID
+func (app *Config) GetBreedingEventHandler(w http.ResponseWriter, r *http.Request) {
+	breedingEventID := resourceID(r)
+	if breedingEventID == "" {
+		app.errorJSON(w, errors.New("breeding event ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := app.Services.Breeding.GetEvent(r.Context(), user, breedingEventID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Breeding event retrieved successfully",
+		Event:   event,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateBreedingEventHandler handles updating a breeding event, as when a
+// pregnancy check confirms or rules out the service
+func (app *Config) UpdateBreedingEventHandler(w http.ResponseWriter, r *http.Request) {
+	var req BreedingEventRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	breedingEventID := resourceID(r)
+	if breedingEventID == "" {
+		app.errorJSON(w, errors.New("breeding event ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	event, err := app.Services.Breeding.UpdateEvent(r.Context(), user, breedingEventID, breeding.EventInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Breeding event updated successfully",
+		Event:   event,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteBreedingEventHandler handles deleting a breeding event
+func (app *Config) DeleteBreedingEventHandler(w http.ResponseWriter, r *http.Request) {
+	breedingEventID := resourceID(r)
+	if breedingEventID == "" {
+		app.errorJSON(w, errors.New("breeding event ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Breeding.DeleteEvent(r.Context(), user, breedingEventID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Breeding event deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CreateBirthHandler handles recording a calving, kidding or lambing
+func (app *Config) CreateBirthHandler(w http.ResponseWriter, r *http.Request) {
+	var req BirthRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	birth, err := app.Services.Breeding.RecordBirth(r.Context(), user, farmID, breeding.BirthInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Birth recorded successfully",
+		Birth:   birth,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetBirthsHandler handles retrieving a farm's births, optionally of the
+// herd in the livestockId query parameter
+func (app *Config) GetBirthsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	births, err := app.Services.Breeding.ListBirths(r.Context(), user, farmID, r.URL.Query().Get("livestockId"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Births retrieved successfully",
+		Births:  births,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBirthHandler handles retrieving a single birth by ID
+func (app *Config) GetBirthHandler(w http.ResponseWriter, r *http.Request) {
+	birthRecordID := resourceID(r)
+	if birthRecordID == "" {
+		app.errorJSON(w, errors.New("birth ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	birth, err := app.Services.Breeding.GetBirth(r.Context(), user, birthRecordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BreedingResponse{
+		Success: true,
+		Message: "Birth retrieved successfully",
+		Birth:   birth,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
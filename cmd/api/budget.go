@@ -0,0 +1,201 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/finance"
+	"net/http"
+)
+
+// BudgetLineRequest represents the budget line creation/update request body
+type BudgetLineRequest struct {
+	SeasonID      string  `json:"seasonId"` // Season the amount is planned for; ignored on update
+	Type          string  `json:"type"`     // Income or Expense (the default)
+	Category      string  `json:"category"` // Transaction category, e.g. Feed
+	PlannedAmount float64 `json:"plannedAmount"`
+	Notes         string  `json:"notes"`
+}
+
+// BudgetResponse represents the budget line and variance response
+type BudgetResponse struct {
+	Success  bool                    `json:"success"`
+	Message  string                  `json:"message"`
+	Line     *data.BudgetLine        `json:"line,omitempty"`
+	Lines    []*data.BudgetLine      `json:"lines,omitempty"`
+	Variance *finance.BudgetVariance `json:"variance,omitempty"`
+}
+
+// Validate checks the budget line request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *BudgetLineRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("seasonId", req.SeasonID)
+		v.Required("category", req.Category)
+		v.Check(req.PlannedAmount > 0, "plannedAmount", "must be greater than 0")
+	}
+	v.Check(req.PlannedAmount >= 0, "plannedAmount", "must be 0 or more")
+	v.OneOf("type", req.Type, "Income", "Expense")
+	return v.Errors()
+}
+
+// CreateBudgetLineHandler handles planning an amount for a transaction
+// category over one of a farm's seasons
+func (app *Config) CreateBudgetLineHandler(w http.ResponseWriter, r *http.Request) {
+	var req BudgetLineRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	line, err := app.Services.Finance.CreateBudgetLine(r.Context(), user, farmID, finance.BudgetLineInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BudgetResponse{
+		Success: true,
+		Message: "Budget line created successfully",
+		Line:    line,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetBudgetLinesHandler handles listing a season's budget
+// (/api/budgets?farmId=&seasonId=)
+func (app *Config) GetBudgetLinesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	seasonID := r.URL.Query().Get("seasonId")
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	lines, err := app.Services.Finance.ListBudgetLines(r.Context(), user, farmID, seasonID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BudgetResponse{
+		Success: true,
+		Message: "Budget lines retrieved successfully",
+		Lines:   lines,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateBudgetLineHandler handles changing a budget line of an open season
+func (app *Config) UpdateBudgetLineHandler(w http.ResponseWriter, r *http.Request) {
+	var req BudgetLineRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	budgetLineID := resourceID(r)
+	if budgetLineID == "" {
+		app.errorJSON(w, errors.New("budget line ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	line, err := app.Services.Finance.UpdateBudgetLine(r.Context(), user, budgetLineID, finance.BudgetLineInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BudgetResponse{
+		Success: true,
+		Message: "Budget line updated successfully",
+		Line:    line,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteBudgetLineHandler handles removing a budget line of an open season
+func (app *Config) DeleteBudgetLineHandler(w http.ResponseWriter, r *http.Request) {
+	budgetLineID := resourceID(r)
+	if budgetLineID == "" {
+		app.errorJSON(w, errors.New("budget line ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Finance.DeleteBudgetLine(r.Context(), user, budgetLineID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BudgetResponse{
+		Success: true,
+		Message: "Budget line deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBudgetVarianceHandler handles comparing a season's budget with the
+// income and spending recorded so far (/api/budgets/variance?farmId=&seasonId=).
+// Categories over budget, or using their budget faster than the season is
+// passing, are flagged.
+func (app *Config) GetBudgetVarianceHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	seasonID := r.URL.Query().Get("seasonId")
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	variance, err := app.Services.Finance.BudgetVariance(r.Context(), user, farmID, seasonID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BudgetResponse{
+		Success:  true,
+		Message:  "Budget variance retrieved successfully",
+		Variance: variance,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/buyer"
+	"net/http"
+)
+
+// BuyerVerificationRequest represents the buyer verification request body
+type BuyerVerificationRequest struct {
+	BusinessName       string `json:"businessName"`
+	RegistrationNumber string `json:"registrationNumber"`
+	Location           string `json:"location"`
+	DocumentURL        string `json:"documentUrl"`
+}
+
+// BuyerReviewRequest represents the admin verification decision request body
+type BuyerReviewRequest struct {
+	Notes string `json:"notes"`
+}
+
+// RatingRequest represents the rating request body
+type RatingRequest struct {
+	RateeID       string `json:"rateeId"`
+	SaleReference string `json:"saleReference"`
+	Score         int    `json:"score"`
+	Review        string `json:"review"`
+}
+
+// BuyerResponse represents the buyer and rating response
+type BuyerResponse struct {
+	Success       bool                 `json:"success"`
+	Message       string               `json:"message"`
+	Verification  *data.BuyerProfile   `json:"verification,omitempty"`
+	Verifications []*data.BuyerProfile `json:"verifications,omitempty"`
+	Buyer         *buyer.Profile       `json:"buyer,omitempty"`
+	Buyers        []*buyer.Profile     `json:"buyers,omitempty"`
+	Rating        *data.Rating         `json:"rating,omitempty"`
+	Summary       *data.RatingSummary  `json:"summary,omitempty"`
+	Ratings       []*data.Rating       `json:"ratings,omitempty"`
+}
+
+// Validate checks the buyer verification request fields
+func (req *BuyerVerificationRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("businessName", req.BusinessName)
+	v.Required("registrationNumber", req.RegistrationNumber)
+	v.Required("location", req.Location)
+	return v.Errors()
+}
+
+// Validate checks the rating request fields
+func (req *RatingRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("rateeId", req.RateeID)
+	v.Required("saleReference", req.SaleReference)
+	v.Check(req.Score >= 1 && req.Score <= 5, "score", "must be between 1 and 5")
+	v.Check(len(req.Review) <= 2000, "review", "must be at most 2000 characters")
+	return v.Errors()
+}
+
+// SubmitBuyerVerificationHandler handles a buyer submitting or updating their
+// business details for verification
+func (app *Config) SubmitBuyerVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	var req BuyerVerificationRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := app.Services.Buyer.SubmitVerification(r.Context(), user, buyer.ProfileInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success:      true,
+		Message:      "Verification submitted successfully",
+		Verification: profile,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBuyerVerificationHandler handles a buyer checking their verification status
+func (app *Config) GetBuyerVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := app.Services.Buyer.Verification(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success:      true,
+		Message:      "Verification retrieved successfully",
+		Verification: profile,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBuyersHandler lists buyers with their rating summaries; ?verified=true
+// limits the list to verified buyers
+func (app *Config) GetBuyersHandler(w http.ResponseWriter, r *http.Request) {
+	buyers, err := app.Services.Buyer.ListBuyers(r.Context(), r.URL.Query().Get("verified") == "true")
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success: true,
+		Message: "Buyers retrieved successfully",
+		Buyers:  buyers,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetBuyerHandler returns a buyer's public profile, verification badge and
+// recent reviews
+func (app *Config) GetBuyerHandler(w http.ResponseWriter, r *http.Request) {
+	userID := resourceID(r)
+	if userID == "" {
+		app.errorJSON(w, errors.New("buyer ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	profile, err := app.Services.Buyer.GetBuyer(r.Context(), userID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success: true,
+		Message: "Buyer retrieved successfully",
+		Buyer:   profile,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CreateRatingHandler handles a farmer or buyer rating the other party to a sale
+func (app *Config) CreateRatingHandler(w http.ResponseWriter, r *http.Request) {
+	var req RatingRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	rating, err := app.Services.Buyer.Rate(r.Context(), user, buyer.RatingInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success: true,
+		Message: "Rating submitted successfully",
+		Rating:  rating,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetRatingsHandler returns the rating summary and recent reviews of ?userId=
+func (app *Config) GetRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("userId")
+	if userID == "" {
+		app.errorJSON(w, errors.New("user ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	summary, ratings, err := app.Services.Buyer.Ratings(r.Context(), userID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success: true,
+		Message: "Ratings retrieved successfully",
+		Summary: &summary,
+		Ratings: ratings,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminListBuyerVerificationsHandler lists buyer verifications by ?status=
+// (default Pending; "all" for every status)
+func (app *Config) AdminListBuyerVerificationsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = buyer.StatusPending
+	case "all":
+		status = ""
+	case buyer.StatusPending, buyer.StatusVerified, buyer.StatusRejected:
+	default:
+		app.errorJSON(w, errors.New("status must be Pending, Verified, Rejected or all"), http.StatusBadRequest)
+		return
+	}
+
+	profiles, err := app.Services.Buyer.ListVerifications(r.Context(), status)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := BuyerResponse{
+		Success:       true,
+		Message:       "Verifications retrieved successfully",
+		Verifications: profiles,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminApproveBuyerHandler marks a pending buyer as verified
+func (app *Config) AdminApproveBuyerHandler(w http.ResponseWriter, r *http.Request) {
+	app.reviewBuyer(w, r, true)
+}
+
+// AdminRejectBuyerHandler rejects a pending buyer verification with a reason
+func (app *Config) AdminRejectBuyerHandler(w http.ResponseWriter, r *http.Request) {
+	app.reviewBuyer(w, r, false)
+}
+
+// reviewBuyer records an admin's verification decision for the approve and
+// reject handlers
+func (app *Config) reviewBuyer(w http.ResponseWriter, r *http.Request, approve bool) {
+	var req BuyerReviewRequest
+
+	if r.ContentLength != 0 {
+		if err := app.ReadJSON(w, r, &req); err != nil {
+			app.errorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	profileID := resourceID(r)
+	if profileID == "" {
+		app.errorJSON(w, errors.New("buyer profile ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	admin, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := app.Services.Buyer.ReviewVerification(r.Context(), admin, profileID, approve, req.Notes)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	message := "Buyer verified successfully"
+	if !approve {
+		message = "Buyer verification rejected"
+	}
+
+	response := BuyerResponse{
+		Success:      true,
+		Message:      message,
+		Verification: profile,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"farm4u/service/carbon"
+	"net/http"
+	"strconv"
+)
+
+// CarbonReportResponse represents the carbon report response
+type CarbonReportResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Report  *carbon.Report `json:"report"`
+}
+
+// GetCarbonReportHandler estimates a farm's emissions from livestock numbers,
+// fertilizer use, fuel and energy records over ?from=/?to= (default: the last
+// 12 months)
+func (app *Config) GetCarbonReportHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	gridFactor := carbon.DefaultGridFactor
+	if v := r.URL.Query().Get("gridFactor"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			app.errorJSON(w, errors.New("gridFactor must be a non-negative number"), http.StatusBadRequest)
+			return
+		}
+		gridFactor = f
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.Services.Carbon.Report(r.Context(), user, farmID, from, to, gridFactor)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CarbonReportResponse{
+		Success: true,
+		Message: "Carbon report generated successfully",
+		Report:  report,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
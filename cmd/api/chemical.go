@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/chemical"
+	"net/http"
+	"time"
+)
+
+// ChemicalProductRequest represents the chemical product creation/update request body
+type ChemicalProductRequest struct {
+	Name             string     `json:"name"`
+	ActiveIngredient string     `json:"activeIngredient"`
+	Category         string     `json:"category"`
+	WHOClass         string     `json:"whoClass"`
+	Restricted       *bool      `json:"restricted"`
+	BatchNumber      string     `json:"batchNumber"`
+	ExpiryDate       *time.Time `json:"expiryDate"`
+	Quantity         float64    `json:"quantity"`
+	Unit             string     `json:"unit"`
+	StorageLocation  string     `json:"storageLocation"`
+	Supplier         string     `json:"supplier"`
+	Notes            string     `json:"notes"`
+}
+
+// ChemicalUsageRequest represents the chemical usage logging request body
+type ChemicalUsageRequest struct {
+	Date                 *time.Time `json:"date"`
+	Quantity             float64    `json:"quantity"`
+	Applicator           string     `json:"applicator"`
+	ApplicatorEmployeeID *string    `json:"applicatorEmployeeId,omitempty"`
+	PPEConfirmed         bool       `json:"ppeConfirmed"`
+	Target               string     `json:"target"`
+	Purpose              string     `json:"purpose"`
+	Notes                string     `json:"notes"`
+}
+
+// ChemicalResponse represents the chemical register response
+type ChemicalResponse struct {
+	Success  bool                    `json:"success"`
+	Message  string                  `json:"message"`
+	Product  *data.ChemicalProduct   `json:"product,omitempty"`
+	Products []*data.ChemicalProduct `json:"products,omitempty"`
+	Usage    *data.ChemicalUsage     `json:"usage,omitempty"`
+	Usages   []*data.ChemicalUsage   `json:"usages,omitempty"`
+}
+
+// Validate checks the chemical product request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *ChemicalProductRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("activeIngredient", req.ActiveIngredient)
+		v.Required("whoClass", req.WHOClass)
+		v.Required("batchNumber", req.BatchNumber)
+		v.Required("unit", req.Unit)
+	}
+	v.OneOf("whoClass", req.WHOClass, "Ia", "Ib", "II", "III", "U")
+	v.Check(req.Quantity >= 0, "quantity", "must be >= 0")
+	return v.Errors()
+}
+
+// Validate checks the chemical usage request fields. The applicator and PPE
+// confirmation are mandatory for every entry.
+func (req *ChemicalUsageRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Quantity > 0, "quantity", "must be greater than 0")
+	v.Required("applicator", req.Applicator)
+	v.Check(req.PPEConfirmed, "ppeConfirmed", "PPE use must be confirmed before logging chemical usage")
+	return v.Errors()
+}
+
+// CreateChemicalProductHandler handles adding a product batch to the chemical store
+func (app *Config) CreateChemicalProductHandler(w http.ResponseWriter, r *http.Request) {
+	var req ChemicalProductRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := app.Services.Chemical.Create(r.Context(), user, farmID, chemical.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical product created successfully",
+		Product: product,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetChemicalProductsHandler handles retrieving the chemical store of a farm
+func (app *Config) GetChemicalProductsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	products, err := app.Services.Chemical.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success:  true,
+		Message:  "Chemical products retrieved successfully",
+		Products: products,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetChemicalProductHandler handles retrieving a single chemical product by ID
+func (app *Config) GetChemicalProductHandler(w http.ResponseWriter, r *http.Request) {
+	chemicalProductID := resourceID(r)
+	if chemicalProductID == "" {
+		app.errorJSON(w, errors.New("chemical product ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := app.Services.Chemical.Get(r.Context(), user, chemicalProductID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical product retrieved successfully",
+		Product: product,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateChemicalProductHandler handles chemical product updates
+func (app *Config) UpdateChemicalProductHandler(w http.ResponseWriter, r *http.Request) {
+	var req ChemicalProductRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	chemicalProductID := resourceID(r)
+	if chemicalProductID == "" {
+		app.errorJSON(w, errors.New("chemical product ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := app.Services.Chemical.Update(r.Context(), user, chemicalProductID, chemical.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical product updated successfully",
+		Product: product,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteChemicalProductHandler handles chemical product deletion
+func (app *Config) DeleteChemicalProductHandler(w http.ResponseWriter, r *http.Request) {
+	chemicalProductID := resourceID(r)
+	if chemicalProductID == "" {
+		app.errorJSON(w, errors.New("chemical product ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Chemical.Delete(r.Context(), user, chemicalProductID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical product deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// LogChemicalUsageHandler records a product being taken from the store. The
+// applicator and PPE confirmation are mandatory for every entry.
+func (app *Config) LogChemicalUsageHandler(w http.ResponseWriter, r *http.Request) {
+	var req ChemicalUsageRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	chemicalProductID := resourceID(r)
+	if chemicalProductID == "" {
+		app.errorJSON(w, errors.New("chemical product ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	usage, err := app.Services.Chemical.LogUsage(r.Context(), user, chemicalProductID, chemical.UsageInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical usage logged successfully",
+		Usage:   usage,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetChemicalUsageHandler handles retrieving the usage log of a chemical product
+func (app *Config) GetChemicalUsageHandler(w http.ResponseWriter, r *http.Request) {
+	chemicalProductID := resourceID(r)
+	if chemicalProductID == "" {
+		app.errorJSON(w, errors.New("chemical product ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	product, usages, err := app.Services.Chemical.Usage(r.Context(), user, chemicalProductID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical usage retrieved successfully",
+		Product: product,
+		Usages:  usages,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetChemicalRegisterHandler returns the full register of a farm's chemical
+// store with usage history, as presented during regulatory inspections
+func (app *Config) GetChemicalRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	restrictedOnly := r.URL.Query().Get("restricted") == "true"
+	products, err := app.Services.Chemical.Register(r.Context(), user, farmID, from, to, restrictedOnly)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success:  true,
+		Message:  "Chemical register retrieved successfully",
+		Products: products,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDeletedChemicalProductsHandler handles listing soft-deleted chemical products of a farm
+func (app *Config) GetDeletedChemicalProductsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	chemicalProducts, err := app.Services.Chemical.ListDeleted(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success:  true,
+		Message:  "Deleted chemical products retrieved successfully",
+		Products: chemicalProducts,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreChemicalProductHandler handles restoring a soft-deleted chemical product
+func (app *Config) RestoreChemicalProductHandler(w http.ResponseWriter, r *http.Request) {
+	chemicalProductID := resourceID(r)
+	if chemicalProductID == "" {
+		app.errorJSON(w, errors.New("chemical product ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	chemicalProduct, err := app.Services.Chemical.Restore(r.Context(), user, chemicalProductID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ChemicalResponse{
+		Success: true,
+		Message: "Chemical product restored successfully",
+		Product: chemicalProduct,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
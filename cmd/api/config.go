@@ -1,19 +1,227 @@
 package main
 
 import (
+	"farm4u/cache"
 	"farm4u/data"
+	"farm4u/live"
+	"farm4u/notify"
+	"farm4u/pricefeed"
+	"farm4u/service/activity"
+	"farm4u/service/asset"
+	"farm4u/service/attachment"
+	"farm4u/service/auth"
+	"farm4u/service/breeding"
+	"farm4u/service/buyer"
+	"farm4u/service/carbon"
+	"farm4u/service/chemical"
+	"farm4u/service/coop"
+	"farm4u/service/crop"
+	"farm4u/service/dairy"
+	"farm4u/service/dashboard"
+	"farm4u/service/dispute"
+	"farm4u/service/document"
+	"farm4u/service/equipment"
+	"farm4u/service/escrow"
+	"farm4u/service/export"
+	"farm4u/service/farm"
+	"farm4u/service/feeding"
+	"farm4u/service/field"
+	"farm4u/service/finance"
+	"farm4u/service/grazing"
+	"farm4u/service/growth"
+	"farm4u/service/importer"
+	"farm4u/service/integration"
+	"farm4u/service/inventory"
+	"farm4u/service/irrigation"
+	"farm4u/service/livestock"
+	"farm4u/service/loan"
+	"farm4u/service/lock"
+	"farm4u/service/market"
+	"farm4u/service/mortality"
+	"farm4u/service/note"
+	"farm4u/service/offline"
+	"farm4u/service/production"
+	"farm4u/service/provider"
+	"farm4u/service/purchase"
+	"farm4u/service/rainfall"
+	"farm4u/service/report"
+	"farm4u/service/search"
+	"farm4u/service/season"
+	"farm4u/service/spray"
+	"farm4u/service/sustainability"
+	"farm4u/service/tag"
+	"farm4u/service/utility"
+	"farm4u/service/view"
+	"farm4u/service/water"
+	"farm4u/service/workforce"
+	"farm4u/storage"
+	"farm4u/weather"
 	"log"
+	"log/slog"
 	"sync"
 
 	"gorm.io/gorm"
 )
 
+// Services are the domain services called by the HTTP handlers
+type Services struct {
+	Auth           auth.Service
+	Farm           farm.Service
+	Field          field.Service
+	Crop           crop.Service
+	Season         season.Service
+	Livestock      livestock.Service
+	Workforce      workforce.Service
+	Equipment      equipment.Service
+	Asset          asset.Service
+	Finance        finance.Service
+	Loan           loan.Service
+	Purchase       purchase.Service
+	Provider       provider.Service
+	Lock           lock.Service
+	Activity       activity.Service
+	Integration    integration.Service
+	Buyer          buyer.Service
+	Dispute        dispute.Service
+	Escrow         escrow.Service
+	Irrigation     irrigation.Service
+	Rainfall       rainfall.Service
+	Grazing        grazing.Service
+	Breeding       breeding.Service
+	Production     production.Service
+	Feeding        feeding.Service
+	Growth         growth.Service
+	Mortality      mortality.Service
+	Spray          spray.Service
+	Market         market.Service
+	Import         importer.Service
+	Attachment     attachment.Service
+	Document       document.Service
+	Tag            tag.Service
+	View           view.Service
+	Note           note.Service
+	Report         report.Service
+	Export         export.Service
+	Dashboard      dashboard.Service
+	Coop           coop.Service
+	Dairy          dairy.Service
+	Offline        offline.Service
+	Search         search.Service
+	Inventory      inventory.Service
+	Water          water.Service
+	Chemical       chemical.Service
+	Utility        utility.Service
+	Sustainability sustainability.Service
+	Carbon         carbon.Service
+}
+
+// newServices wires the domain services to the repositories, object storage,
+// the weather forecast provider, the market price feed and the live event hub
+func newServices(models data.Models, files storage.Storage, forecasts weather.Forecaster, prices pricefeed.Feed, hub *live.Hub) Services {
+	farms := farm.New(models)
+	locks := lock.New(models, farms)
+	services := Services{
+		Auth:        auth.New(models.User, models.RevokedToken, models.PhoneLogin),
+		Farm:        farms,
+		Field:       field.New(models.Field, models.Crop, farms),
+		Crop:        crop.New(models.Crop, models.CropPlan, models.PlanScenario, models.CropIncident, models.Field, models.Season, models.Tag, models.ServiceProvider, locks, farms),
+		Season:      season.New(models.Season, farms),
+		Livestock:   livestock.New(models.Livestock, models.Tag, farms),
+		Workforce:   workforce.New(models.Employee, models.PayrollPayment, models.Attendance, models.Shift, models.Field, locks, models.User, farms),
+		Equipment:   equipment.New(models.Equipment, models.MaintenanceRecord, models.Employee, models.ServiceProvider, locks, farms),
+		Asset:       asset.New(models.Asset, models.Equipment, models.Livestock, models.Transaction, locks, farms),
+		Finance:     finance.New(models.Transaction, models.TaxRate, models.ExchangeRate, models.PayrollPayment, models.Season, models.BudgetLine, models.Asset, locks, farms),
+		Loan:        loan.New(models.Loan, locks, farms),
+		Purchase:    purchase.New(models.Supplier, models.PurchaseOrder, models.InventoryItem, locks, farms),
+		Provider:    provider.New(models.ServiceProvider, models.MaintenanceRecord, models.CropIncident, farms),
+		Lock:        locks,
+		Activity:    activity.New(models.AuditLog, models.User, farms),
+		Integration: integration.New(models.Webhook, models.WebhookDelivery, models.APIKey, models.User, farms),
+		Buyer:       buyer.New(models.BuyerProfile, models.Rating, models.User, models.Notification),
+		Dispute:     dispute.New(models.Dispute, models.User, models.Notification),
+		Escrow:      escrow.New(models.Escrow, models.Dispute, models.User, models.Notification),
+		Irrigation:  irrigation.New(models.IrrigationSchedule, models.Field, models.Crop, models.WaterSource, forecasts, farms),
+		Rainfall:    rainfall.New(models.RainfallRecord, models.Field, forecasts, farms),
+		Grazing:     grazing.New(models.Paddock, models.GrazingMove, models.Field, models.Livestock, farms),
+		Breeding:    breeding.New(models, farms),
+		Production:  production.New(models.ProductionRecord, models.Livestock, locks, farms),
+		Feeding:     feeding.New(models, locks, farms),
+		Growth:      growth.New(models.WeightRecord, models.Livestock, farms),
+		Mortality:   mortality.New(models, locks, farms),
+		Spray:       spray.New(models, locks, farms),
+		Market:      market.New(models.MarketPrice, prices),
+		Import:      importer.New(models.ImportJob, files, models.Field, locks, farms),
+		Attachment: attachment.New(models.Attachment, files, models.Crop, models.Livestock, models.Equipment,
+			models.MaintenanceRecord, models.Transaction, models.Document, models.CropIncident, farms),
+		Report: report.New(models.ReportJob, files, models.Field, models.Crop, models.Livestock, models.Employee,
+			models.PayrollPayment, models.Transaction, models.Season, models.Farm, farms),
+		Export:    export.New(models.ExportJob, models.AccountExport, files, models.User, models.Farm, models.Notification),
+		Dashboard: dashboard.New(models.DashboardLayout),
+		Coop: coop.New(models.Organization, models.ProcurementWindow, models.ProcurementRequest, models.ProcurementOrder,
+			models.User, farms),
+		Dairy:          dairy.New(models.CollectionCenter, models.MilkDelivery, locks, farms),
+		Search:         search.New(models.Search, farms),
+		Tag:            tag.New(models.Tag, models.Crop, models.Livestock, models.Document, farms),
+		View:           view.New(models.SavedView, farms),
+		Note:           note.New(models, farms),
+		Inventory:      inventory.New(models.InventoryItem, models.InventoryBatch, farms),
+		Water:          water.New(models.WaterSource, models.WaterUsage, locks, farms),
+		Chemical:       chemical.New(models.ChemicalProduct, models.ChemicalUsage, models.Employee, locks, farms),
+		Utility:        utility.New(models.UtilityRecord, locks, farms),
+		Sustainability: sustainability.New(models.SustainabilityPractice, models.SustainabilityAssessment, farms),
+		Carbon:         carbon.New(models.Livestock, models.InventoryMovement, models.UtilityRecord, farms),
+	}
+	// Changes to crops, livestock, employees and transactions go to the
+	// activity feed, the farm's webhooks and the live event streams,
+	// including those made through offline sync
+	events := &farmEvents{next: services.Integration, hub: hub, farms: models.Farm, members: models.FarmMember}
+	services.Crop = activity.Crops(services.Crop, models.AuditLog, events)
+	services.Livestock = activity.Livestock(services.Livestock, models.AuditLog, events)
+	services.Workforce = activity.Workforce(services.Workforce, models.AuditLog, events)
+	services.Finance = activity.Finance(services.Finance, models.AuditLog, events)
+	services.Document = document.New(models.Document, models.Employee, services.Attachment, models.Tag, farms)
+	services.Offline = offline.New(models.Sync, services.Field, services.Crop, services.Livestock, services.Workforce, farms)
+	return services
+}
+
 type Config struct {
+	// Settings is the configuration read from the environment at startup
+	Settings *AppConfig
+	// JWTKeys sign and verify access tokens
+	JWTKeys  *jwtKeys
 	DB       *gorm.DB
 	InfoLog  *log.Logger
 	ErrorLog *log.Logger
-	Wait     *sync.WaitGroup
-	Models   data.Models
+	// AccessLog writes one structured JSON line per HTTP request
+	AccessLog *slog.Logger
+	Wait      *sync.WaitGroup
+	Models    data.Models
+	// Services hold the domain logic behind the handlers
+	Services Services
+	// Storage holds attachments, exports and backups (see STORAGE_URL)
+	Storage storage.Storage
+	// Cache keeps hot lookups (see CACHE_URL)
+	Cache cache.Cache
+	// Notifier delivers email, SMS and push messages with provider failover
+	Notifier *notify.Dispatcher
+	// Weather supplies rain forecasts and recorded rainfall (see WEATHER_URL)
+	Weather weather.Forecaster
+	// PriceFeed supplies daily commodity prices (see MARKET_PRICES_URL)
+	PriceFeed pricefeed.Feed
+	// Live pushes record changes and notifications to connected clients
+	Live *live.Hub
+
+	// RateLimiter counts requests per client; APIRateLimit is the hourly
+	// allowance, AuthRateLimits the stricter login/reset limits and APIUsage
+	// buffers per-endpoint call counts
+	RateLimiter    RateLimitStore
+	APIRateLimit   int
+	AuthRateLimits authRateRules
+	APIUsage       *usageRecorder
+
+	// Done is closed when the server starts shutting down; background
+	// workers registered on Wait return once it is closed.
+	Done chan struct{}
 
 	ErrorChan     chan error
 	ErrorChanDone chan bool
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/crop"
 	"net/http"
 	"time"
 )
@@ -15,6 +17,9 @@ type CropRequest struct {
 	Quantity     float64    `json:"quantity"`
 	Status       string     `json:"status"`
 	Notes        string     `json:"notes"`
+	FieldID      *string    `json:"fieldId"`  // Empty string takes the crop off its field
+	SeasonID     *string    `json:"seasonId"` // Empty string unlinks the crop from its season
+	Version      int        `json:"version"`  // Required on update: the version being changed; a stale one gets 409 with the current record
 }
 
 // CropResponse represents the crop response
@@ -23,6 +28,34 @@ type CropResponse struct {
 	Message string       `json:"message"`
 	Crop    *data.Crop   `json:"crop,omitempty"`
 	Crops   []*data.Crop `json:"crops,omitempty"`
+	Stats   *crop.Stats  `json:"stats,omitempty"`
+}
+
+// Validate checks the crop request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *CropRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Check(req.Quantity > 0, "quantity", "must be greater than 0")
+	}
+	v.Check(req.Quantity >= 0, "quantity", "must be 0 or more")
+	v.OneOf("status", req.Status, "Growing", "Harvested", "Failed")
+	if req.PlantingDate != nil && req.HarvestDate != nil {
+		v.Check(!req.HarvestDate.Before(*req.PlantingDate), "harvestDate", "must not be before plantingDate")
+	}
+	return v.Errors()
+}
+
+// ValidatePatch checks a crop PATCH body, whose set fields may be cleared
+// except for those a crop cannot do without
+func (req *CropRequest) ValidatePatch(set service.Fields) ValidationErrors {
+	v := newValidator()
+	v.Check(!set["name"] || req.Name != "", "name", "cannot be cleared")
+	v.Check(!set["quantity"] || req.Quantity > 0, "quantity", "must be greater than 0")
+	v.Check(!set["status"] || req.Status != "", "status", "cannot be cleared")
+	v.Merge(req.Validate(true))
+	return v.Errors()
 }
 
 // CreateCropHandler handles crop creation
@@ -34,202 +67,169 @@ func (app *Config) CreateCropHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.Name == "" || req.Quantity <= 0 {
-		app.errorJSON(w, errors.New("name and quantity are required"), http.StatusBadRequest)
-		return
-	}
-
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("farmId")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	c, err := app.Services.Crop.Create(r.Context(), user, farmID, crop.Input(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
-	}
-
-	// Verify farm exists and belongs to user
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
+	response := CropResponse{
+		Success: true,
+		Message: "Crop created successfully",
+		Crop:    c,
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("farm not found or access denied"), http.StatusForbidden)
-		return
-	}
+	app.writeJSON(w, http.StatusCreated, response)
+}
 
-	// Set default status if not provided
-	if req.Status == "" {
-		req.Status = "Growing"
-	}
+// CreateCropsBatchHandler handles adding several crops to a farm at once, as
+// when a farm is first set up or an offline client syncs
+func (app *Config) CreateCropsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	batchCreate(app, w, r, "crops", func(req *CropRequest) ValidationErrors { return req.Validate(false) },
+		func(user *data.User, farmID string, reqs []CropRequest) ([]*data.Crop, []error, error) {
+			ins := make([]crop.Input, len(reqs))
+			for i, req := range reqs {
+				ins[i] = crop.Input(req)
+			}
+			return app.Services.Crop.CreateBatch(r.Context(), user, farmID, ins)
+		})
+}
 
-	// Create new crop
-	crop := &data.Crop{
-		FarmID:       farmID,
-		Name:         req.Name,
-		PlantingDate: req.PlantingDate,
-		HarvestDate:  req.HarvestDate,
-		Quantity:     req.Quantity,
-		Status:       req.Status,
-		Notes:        req.Notes,
-	}
+// UpdateCropsStatusHandler handles moving several of a farm's crops to one
+// status at once, e.g. marking a field's crops Harvested
+func (app *Config) UpdateCropsStatusHandler(w http.ResponseWriter, r *http.Request) {
+	var req BulkRequest
 
-	// Insert crop
-	if err := app.Models.Crop.Insert(crop); err != nil {
-		app.ErrorLog.Printf("Error creating crop: %v", err)
-		app.errorJSON(w, errors.New("failed to create crop"), http.StatusInternalServerError)
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	response := CropResponse{
-		Success: true,
-		Message: "Crop created successfully",
-		Crop:    crop,
+	if errs := req.Validate("Growing", "Harvested", "Failed"); errs != nil {
+		app.failedValidation(w, errs)
+		return
 	}
 
-	app.writeJSON(w, http.StatusCreated, response)
+	bulkApply(app, w, r, req.IDs, "crops", "updated", func(user *data.User, farmID string) ([]*data.Crop, []error, error) {
+		changes, errs, err := app.Services.Crop.UpdateStatus(r.Context(), user, farmID, req.IDs, req.Status)
+		crops := make([]*data.Crop, len(changes))
+		for i, change := range changes {
+			if change != nil {
+				crops[i] = change.Crop
+			}
+		}
+		return crops, errs, err
+	})
 }
 
 // GetCropHandler handles retrieving a single crop by ID
 func (app *Config) GetCropHandler(w http.ResponseWriter, r *http.Request) {
-	// Get crop ID from URL parameters
-	cropID := r.URL.Query().Get("id")
+	cropID := resourceID(r)
 	if cropID == "" {
 		app.errorJSON(w, errors.New("crop ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get crop by ID
-	crop, err := app.Models.Crop.GetByCropID(cropID)
+	c, err := app.Services.Crop.Get(r.Context(), user, cropID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting crop: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if crop == nil {
-		app.errorJSON(w, errors.New("crop not found"), http.StatusNotFound)
-		return
+	response := CropResponse{
+		Success: true,
+		Message: "Crop retrieved successfully",
+		Crop:    c,
 	}
 
-	// Verify that the crop belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetCropsHandler handles retrieving all crops for a farm, optionally only
+// those planted ?from= ?to= and those with every ?tag=
+func (app *Config) GetCropsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(crop.FarmID)
+	crops, err := app.Services.Crop.List(r.Context(), user, farmID, from, to, queryTags(r))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: crop does not belong to user's farm"), http.StatusForbidden)
+		app.serviceError(w, err)
 		return
 	}
 
 	response := CropResponse{
 		Success: true,
-		Message: "Crop retrieved successfully",
-		Crop:    crop,
+		Message: "Crops retrieved successfully",
+		Crops:   crops,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
 }
 
-// GetCropsHandler handles retrieving all crops for a farm
-func (app *Config) GetCropsHandler(w http.ResponseWriter, r *http.Request) {
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("farmId")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+// GetAllCropsHandler handles retrieving the crops of every farm the user
+// owns in one list, each with its farm
+func (app *Config) GetAllCropsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
-		return
-	}
-
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	crops, err := app.Services.Crop.ListAll(r.Context(), user)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
+	response := CropResponse{
+		Success: true,
+		Message: "Crops retrieved successfully",
+		Crops:   crops,
 	}
 
-	// Verify farm exists and belongs to user
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
+	app.writeJSON(w, http.StatusOK, response)
+}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("farm not found or access denied"), http.StatusForbidden)
+// GetCropStatsHandler handles counting a farm's crops per name and status
+// (/api/crops/stats?farmId=)
+func (app *Config) GetCropStatsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Get crops by farm ID
-	crops, err := app.Models.Crop.GetByFarmID(farmID)
+	stats, err := app.Services.Crop.Stats(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting crops: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
 	response := CropResponse{
 		Success: true,
-		Message: "Crops retrieved successfully",
-		Crops:   crops,
+		Message: "Crop statistics retrieved successfully",
+		Stats:   stats,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -244,90 +244,74 @@ func (app *Config) UpdateCropHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get crop ID from URL parameters
-	cropID := r.URL.Query().Get("id")
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	cropID := resourceID(r)
 	if cropID == "" {
 		app.errorJSON(w, errors.New("crop ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get existing crop
-	existingCrop, err := app.Models.Crop.GetByCropID(cropID)
+	c, err := app.Services.Crop.Update(r.Context(), user, cropID, crop.Input(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting crop: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if existingCrop == nil {
-		app.errorJSON(w, errors.New("crop not found"), http.StatusNotFound)
-		return
+	response := CropResponse{
+		Success: true,
+		Message: "Crop updated successfully",
+		Crop:    c,
 	}
 
-	// Verify that the crop belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// PatchCropHandler handles partial crop updates. Only the fields in the body
+// change; one sent as null, blank or zero is cleared.
+func (app *Config) PatchCropHandler(w http.ResponseWriter, r *http.Request) {
+	var req CropRequest
+
+	set, err := app.readPatch(w, r, &req)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+	if errs := req.ValidatePatch(set); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(existingCrop.FarmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	cropID := resourceID(r)
+	if cropID == "" {
+		app.errorJSON(w, errors.New("crop ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: crop does not belong to user's farm"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Update crop fields if provided
-	if req.Name != "" {
-		existingCrop.Name = req.Name
-	}
-	if req.PlantingDate != nil {
-		existingCrop.PlantingDate = req.PlantingDate
-	}
-	if req.HarvestDate != nil {
-		existingCrop.HarvestDate = req.HarvestDate
-	}
-	if req.Quantity > 0 {
-		existingCrop.Quantity = req.Quantity
-	}
-	if req.Status != "" {
-		existingCrop.Status = req.Status
-	}
-	if req.Notes != "" {
-		existingCrop.Notes = req.Notes
-	}
-
-	// Update crop
-	if err := app.Models.Crop.Update(existingCrop); err != nil {
-		app.ErrorLog.Printf("Error updating crop: %v", err)
-		app.errorJSON(w, errors.New("failed to update crop"), http.StatusInternalServerError)
+	c, err := app.Services.Crop.Patch(r.Context(), user, cropID, crop.Input(req), set)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := CropResponse{
 		Success: true,
 		Message: "Crop updated successfully",
-		Crop:    existingCrop,
+		Crop:    c,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -335,69 +319,75 @@ func (app *Config) UpdateCropHandler(w http.ResponseWriter, r *http.Request) {
 
 // DeleteCropHandler handles crop deletion
 func (app *Config) DeleteCropHandler(w http.ResponseWriter, r *http.Request) {
-	// Get crop ID from URL parameters
-	cropID := r.URL.Query().Get("id")
+	cropID := resourceID(r)
 	if cropID == "" {
 		app.errorJSON(w, errors.New("crop ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get crop to verify it exists
-	crop, err := app.Models.Crop.GetByCropID(cropID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting crop: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if err := app.Services.Crop.Delete(r.Context(), user, cropID); err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
-	if crop == nil {
-		app.errorJSON(w, errors.New("crop not found"), http.StatusNotFound)
+	response := CropResponse{
+		Success: true,
+		Message: "Crop deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDeletedCropsHandler handles listing soft-deleted crops of a farm
+func (app *Config) GetDeletedCropsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Verify that the crop belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	crops, err := app.Services.Crop.ListDeleted(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
+	response := CropResponse{
+		Success: true,
+		Message: "Deleted crops retrieved successfully",
+		Crops:   crops,
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(crop.FarmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreCropHandler handles restoring a soft-deleted crop
+func (app *Config) RestoreCropHandler(w http.ResponseWriter, r *http.Request) {
+	cropID := resourceID(r)
+	if cropID == "" {
+		app.errorJSON(w, errors.New("crop ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: crop does not belong to user's farm"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Delete crop (soft delete)
-	if err := app.Models.Crop.DeleteByID(int(crop.ID)); err != nil {
-		app.ErrorLog.Printf("Error deleting crop: %v", err)
-		app.errorJSON(w, errors.New("failed to delete crop"), http.StatusInternalServerError)
+	c, err := app.Services.Crop.Restore(r.Context(), user, cropID)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := CropResponse{
 		Success: true,
-		Message: "Crop deleted successfully",
+		Message: "Crop restored successfully",
+		Crop:    c,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/attachment"
+	"farm4u/service/crop"
+	"net/http"
+	"time"
+)
+
+// CropIncidentRequest represents the crop incident creation/update request
+// body. Photos are attached afterwards through /attachments with the
+// recordType incident.
+type CropIncidentRequest struct {
+	CropID            string     `json:"cropId"`  // Crop affected; a crop or a field is required
+	FieldID           string     `json:"fieldId"` // Field affected; defaults to the crop's field
+	Type              string     `json:"type"`    // Pest, Disease
+	Name              string     `json:"name"`    // e.g. Fall Armyworm
+	Severity          string     `json:"severity"`
+	ObservedAt        *time.Time `json:"observedAt"`   // Defaults to now
+	AffectedArea      float64    `json:"affectedArea"` // Hectares
+	Treatment         string     `json:"treatment"`
+	Cost              float64    `json:"cost"` // Booked as a Crop Protection expense
+	Status            string     `json:"status"`
+	Notes             string     `json:"notes"`
+	ServiceProviderID string     `json:"serviceProviderId"` // Contractor who treated it, from the farm's directory
+}
+
+// CropIncidentResponse represents the crop incident response
+type CropIncidentResponse struct {
+	Success   bool                   `json:"success"`
+	Message   string                 `json:"message"`
+	Incident  *data.CropIncident     `json:"incident,omitempty"`
+	Incidents []*data.CropIncident   `json:"incidents,omitempty"`
+	Photos    []*data.Attachment     `json:"photos,omitempty"`
+	History   []data.IncidentHistory `json:"history,omitempty"`
+}
+
+// Validate checks the crop incident request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *CropIncidentRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Check(req.CropID != "" || req.FieldID != "", "cropId", "a crop or a field is required")
+		v.Required("type", req.Type)
+		v.Required("name", req.Name)
+		v.Required("severity", req.Severity)
+	}
+	v.OneOf("type", req.Type, crop.IncidentTypes()...)
+	v.OneOf("severity", req.Severity, crop.Severities()...)
+	v.OneOf("status", req.Status, crop.IncidentStatuses()...)
+	v.Check(req.AffectedArea >= 0, "affectedArea", "must be >= 0")
+	v.Check(req.Cost >= 0, "cost", "must be >= 0")
+	if req.ObservedAt != nil {
+		v.Check(!req.ObservedAt.After(time.Now()), "observedAt", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateCropIncidentHandler handles reporting a pest or disease on a crop or
+// field
+func (app *Config) CreateCropIncidentHandler(w http.ResponseWriter, r *http.Request) {
+	var req CropIncidentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	incident, err := app.Services.Crop.CreateIncident(r.Context(), user, farmID, crop.IncidentInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropIncidentResponse{
+		Success:  true,
+		Message:  "Crop incident reported successfully",
+		Incident: incident,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetCropIncidentsHandler handles retrieving a farm's crop incidents,
+// optionally filtered by the cropId, fieldId, type, status, from and to
+// (YYYY-MM-DD) query parameters
+func (app *Config) GetCropIncidentsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := app.cropIncidentFilter(w, r)
+	if !ok {
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	incidents, err := app.Services.Crop.ListIncidents(r.Context(), user, farmID, filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropIncidentResponse{
+		Success:   true,
+		Message:   "Crop incidents retrieved successfully",
+		Incidents: incidents,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetCropIncidentHistoryHandler handles a farm's incident history: each pest
+// or disease with how often, where and how badly it struck, the most
+// frequent first. It takes the same filters as the incident list.
+func (app *Config) GetCropIncidentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := app.cropIncidentFilter(w, r)
+	if !ok {
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := app.Services.Crop.IncidentHistory(r.Context(), user, farmID, filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropIncidentResponse{
+		Success: true,
+		Message: "Crop incident history retrieved successfully",
+		History: history,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetCropIncidentHandler handles retrieving a single crop incident by ID,
+// with its photos
+func (app *Config) GetCropIncidentHandler(w http.ResponseWriter, r *http.Request) {
+	incidentID := resourceID(r)
+	if incidentID == "" {
+		app.errorJSON(w, errors.New("crop incident ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	incident, err := app.Services.Crop.GetIncident(r.Context(), user, incidentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	photos, err := app.Services.Attachment.List(r.Context(), user, attachment.RecordIncident, incident.CropIncidentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropIncidentResponse{
+		Success:  true,
+		Message:  "Crop incident retrieved successfully",
+		Incident: incident,
+		Photos:   photos,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateCropIncidentHandler handles updating a crop incident, such as
+// recording its treatment or resolving it
+func (app *Config) UpdateCropIncidentHandler(w http.ResponseWriter, r *http.Request) {
+	var req CropIncidentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	incidentID := resourceID(r)
+	if incidentID == "" {
+		app.errorJSON(w, errors.New("crop incident ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	incident, err := app.Services.Crop.UpdateIncident(r.Context(), user, incidentID, crop.IncidentInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropIncidentResponse{
+		Success:  true,
+		Message:  "Crop incident updated successfully",
+		Incident: incident,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteCropIncidentHandler handles deleting a crop incident and its expense
+func (app *Config) DeleteCropIncidentHandler(w http.ResponseWriter, r *http.Request) {
+	incidentID := resourceID(r)
+	if incidentID == "" {
+		app.errorJSON(w, errors.New("crop incident ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Crop.DeleteIncident(r.Context(), user, incidentID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropIncidentResponse{
+		Success: true,
+		Message: "Crop incident deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// cropIncidentFilter reads the crop incident filter from the query
+// parameters. On failure the error response has already been written and ok
+// is false.
+func (app *Config) cropIncidentFilter(w http.ResponseWriter, r *http.Request) (data.CropIncidentFilter, bool) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return data.CropIncidentFilter{}, false
+	}
+	query := r.URL.Query()
+	return data.CropIncidentFilter{
+		CropID:            query.Get("cropId"),
+		FieldID:           query.Get("fieldId"),
+		ServiceProviderID: query.Get("serviceProviderId"),
+		Type:              query.Get("type"),
+		Status:            query.Get("status"),
+		From:              from,
+		To:                to,
+	}, true
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/crop"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CropPlanRequest represents the crop plan creation/update request body
+type CropPlanRequest struct {
+	FieldID         string              `json:"fieldId"`
+	CropName        string              `json:"cropName"`
+	Variety         string              `json:"variety"`
+	PlantingStart   *time.Time          `json:"plantingStart"`
+	PlantingEnd     *time.Time          `json:"plantingEnd"`
+	ExpectedHarvest *time.Time          `json:"expectedHarvest"`
+	ExpectedYield   float64             `json:"expectedYield"`
+	Status          string              `json:"status"`
+	Notes           string              `json:"notes"`
+	Inputs          []BudgetItemRequest `json:"inputs"` // Replaces the budgeted inputs when present
+}
+
+// BudgetItemRequest represents one budgeted input of a crop plan
+type BudgetItemRequest struct {
+	Item     string  `json:"item"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Cost     float64 `json:"cost"`
+}
+
+// CropPlanResponse represents the crop plan response. Conflicts lists the
+// plans a saved plan clashes with on its field.
+type CropPlanResponse struct {
+	Success   bool             `json:"success"`
+	Message   string           `json:"message"`
+	Plan      *data.CropPlan   `json:"plan,omitempty"`
+	Plans     []*data.CropPlan `json:"plans,omitempty"`
+	Conflicts []crop.Conflict  `json:"conflicts,omitempty"`
+	Calendar  *crop.Calendar   `json:"calendar,omitempty"`
+}
+
+// Validate checks the crop plan request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *CropPlanRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("fieldId", req.FieldID)
+		v.Required("cropName", req.CropName)
+		v.Check(req.PlantingStart != nil, "plantingStart", "is required")
+		v.Check(req.PlantingEnd != nil, "plantingEnd", "is required")
+		v.Check(req.ExpectedHarvest != nil, "expectedHarvest", "is required")
+	}
+	if req.PlantingStart != nil && req.PlantingEnd != nil {
+		v.Check(!req.PlantingEnd.Before(*req.PlantingStart), "plantingEnd", "must not be before plantingStart")
+	}
+	if req.PlantingEnd != nil && req.ExpectedHarvest != nil {
+		v.Check(!req.ExpectedHarvest.Before(*req.PlantingEnd), "expectedHarvest", "must not be before plantingEnd")
+	}
+	v.Check(req.ExpectedYield >= 0, "expectedYield", "must be >= 0")
+	v.OneOf("status", req.Status, "Planned", "Planted", "Cancelled")
+	for i, input := range req.Inputs {
+		field := fmt.Sprintf("inputs[%d]", i)
+		v.Required(field+".item", input.Item)
+		v.Check(input.Quantity >= 0, field+".quantity", "must be >= 0")
+		v.Check(input.Cost >= 0, field+".cost", "must be >= 0")
+	}
+	return v.Errors()
+}
+
+// planInput converts the request to the crop service input
+func (req *CropPlanRequest) planInput() crop.PlanInput {
+	in := crop.PlanInput{
+		FieldID:         req.FieldID,
+		CropName:        req.CropName,
+		Variety:         req.Variety,
+		PlantingStart:   req.PlantingStart,
+		PlantingEnd:     req.PlantingEnd,
+		ExpectedHarvest: req.ExpectedHarvest,
+		ExpectedYield:   req.ExpectedYield,
+		Status:          req.Status,
+		Notes:           req.Notes,
+	}
+	if req.Inputs != nil {
+		in.Inputs = make([]crop.BudgetItem, 0, len(req.Inputs))
+		for _, input := range req.Inputs {
+			in.Inputs = append(in.Inputs, crop.BudgetItem(input))
+		}
+	}
+	return in
+}
+
+// CreateCropPlanHandler handles planning a crop on a field
+func (app *Config) CreateCropPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var req CropPlanRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	plan, conflicts, err := app.Services.Crop.CreatePlan(r.Context(), user, farmID, req.planInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	message := "Crop plan created successfully"
+	if len(conflicts) > 0 {
+		message = "Crop plan created; it overlaps other plans on the same field"
+	}
+
+	response := CropPlanResponse{
+		Success:   true,
+		Message:   message,
+		Plan:      plan,
+		Conflicts: conflicts,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetCropPlansHandler handles retrieving a farm's crop plans, optionally
+// limited to those holding their field within ?from=/?to=
+func (app *Config) GetCropPlansHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	plans, err := app.Services.Crop.ListPlans(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropPlanResponse{
+		Success: true,
+		Message: "Crop plans retrieved successfully",
+		Plans:   plans,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetCropPlanHandler handles retrieving a single crop plan by ID
+func (app *Config) GetCropPlanHandler(w http.ResponseWriter, r *http.Request) {
+	planID := resourceID(r)
+	if planID == "" {
+		app.errorJSON(w, errors.New("crop plan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := app.Services.Crop.GetPlan(r.Context(), user, planID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropPlanResponse{
+		Success: true,
+		Message: "Crop plan retrieved successfully",
+		Plan:    plan,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateCropPlanHandler handles crop plan updates
+func (app *Config) UpdateCropPlanHandler(w http.ResponseWriter, r *http.Request) {
+	var req CropPlanRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	planID := resourceID(r)
+	if planID == "" {
+		app.errorJSON(w, errors.New("crop plan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	plan, conflicts, err := app.Services.Crop.UpdatePlan(r.Context(), user, planID, req.planInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	message := "Crop plan updated successfully"
+	if len(conflicts) > 0 {
+		message = "Crop plan updated; it overlaps other plans on the same field"
+	}
+
+	response := CropPlanResponse{
+		Success:   true,
+		Message:   message,
+		Plan:      plan,
+		Conflicts: conflicts,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteCropPlanHandler handles crop plan deletion
+func (app *Config) DeleteCropPlanHandler(w http.ResponseWriter, r *http.Request) {
+	planID := resourceID(r)
+	if planID == "" {
+		app.errorJSON(w, errors.New("crop plan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Crop.DeletePlan(r.Context(), user, planID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropPlanResponse{
+		Success: true,
+		Message: "Crop plan deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSeasonCalendarHandler lays out a farm's crop plans for ?year= (default
+// this year) by field, with the plans that clash on a field
+func (app *Config) GetSeasonCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1900 || y > 9999 {
+			app.errorJSON(w, errors.New("year must be a valid year"), http.StatusBadRequest)
+			return
+		}
+		year = y
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	calendar, err := app.Services.Crop.SeasonCalendar(r.Context(), user, farmID, year)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CropPlanResponse{
+		Success:  true,
+		Message:  "Season calendar generated successfully",
+		Calendar: calendar,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,549 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/dairy"
+	"net/http"
+	"time"
+)
+
+// apiKeyHeader carries a collection center's or a partner's API key
+const apiKeyHeader = "X-API-Key"
+
+// collectionCenterKey is the context key holding the collection center
+// authenticated by CollectionCenterMiddleware
+type collectionCenterKey struct{}
+
+// CollectionCenterRequest represents the collection center creation/update
+// request body
+type CollectionCenterRequest struct {
+	Name          string  `json:"name"`
+	Location      string  `json:"location"`
+	PricePerLitre float64 `json:"pricePerLitre"`
+	Active        *bool   `json:"active"`
+}
+
+// MilkSupplierRequest represents the request body for registering a farm with
+// a collection center
+type MilkSupplierRequest struct {
+	SupplierNumber string `json:"supplierNumber"` // The number the center knows the farmer by
+}
+
+// MilkDeliveryRequest represents a delivery posted by a collection center
+type MilkDeliveryRequest struct {
+	SupplierNumber  string    `json:"supplierNumber"`
+	Reference       string    `json:"reference"` // Receipt number; posting it again corrects the delivery
+	Date            time.Time `json:"date"`
+	Session         string    `json:"session"` // Morning, Evening
+	Volume          float64   `json:"volume"`  // Litres
+	Fat             float64   `json:"fat"`
+	SNF             float64   `json:"snf"`
+	Density         float64   `json:"density"`
+	Accepted        *bool     `json:"accepted"` // True if omitted
+	RejectionReason string    `json:"rejectionReason"`
+	PricePerLitre   float64   `json:"pricePerLitre"` // The center's price if omitted
+}
+
+// CollectionCenterResponse represents the collection center response
+type CollectionCenterResponse struct {
+	Success bool                     `json:"success"`
+	Message string                   `json:"message"`
+	Center  *data.CollectionCenter   `json:"center,omitempty"`
+	Centers []*data.CollectionCenter `json:"centers,omitempty"`
+	// APIKey is only returned when a center is created or its key rotated
+	APIKey string `json:"apiKey,omitempty"`
+}
+
+// MilkSupplierResponse represents the milk supplier response
+type MilkSupplierResponse struct {
+	Success   bool                 `json:"success"`
+	Message   string               `json:"message"`
+	Supplier  *data.MilkSupplier   `json:"supplier,omitempty"`
+	Suppliers []*data.MilkSupplier `json:"suppliers,omitempty"`
+}
+
+// MilkDeliveryResponse represents the milk delivery response
+type MilkDeliveryResponse struct {
+	Success    bool                 `json:"success"`
+	Message    string               `json:"message"`
+	Delivery   *data.MilkDelivery   `json:"delivery,omitempty"`
+	Deliveries []*data.MilkDelivery `json:"deliveries,omitempty"`
+}
+
+// MilkStatementResponse represents the monthly milk payment statement response
+type MilkStatementResponse struct {
+	Success    bool               `json:"success"`
+	Message    string             `json:"message"`
+	Statements []*dairy.Statement `json:"statements"`
+}
+
+// Validate checks the collection center request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *CollectionCenterRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+	}
+	v.Check(req.PricePerLitre >= 0, "pricePerLitre", "must be >= 0")
+	return v.Errors()
+}
+
+// Validate checks the milk supplier request fields
+func (req *MilkSupplierRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("supplierNumber", req.SupplierNumber)
+	return v.Errors()
+}
+
+// Validate checks the milk delivery request fields
+func (req *MilkDeliveryRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("supplierNumber", req.SupplierNumber)
+	v.Required("reference", req.Reference)
+	v.Check(!req.Date.IsZero(), "date", "is required")
+	v.Required("session", req.Session)
+	v.OneOf("session", req.Session, dairy.SessionMorning, dairy.SessionEvening)
+	v.Check(req.Volume > 0, "volume", "must be greater than 0")
+	v.Check(req.Fat >= 0, "fat", "must be >= 0")
+	v.Check(req.SNF >= 0, "snf", "must be >= 0")
+	v.Check(req.Density >= 0, "density", "must be >= 0")
+	v.Check(req.PricePerLitre >= 0, "pricePerLitre", "must be >= 0")
+	return v.Errors()
+}
+
+// CollectionCenterMiddleware authenticates a collection center's system by
+// the API key in the X-API-Key header
+func (app *Config) CollectionCenterMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.Header.Get(apiKeyHeader)
+		if apiKey == "" {
+			app.errorJSON(w, errors.New("API key required"), http.StatusUnauthorized)
+			return
+		}
+
+		center, err := app.Services.Dairy.Authenticate(r.Context(), apiKey)
+		if err != nil {
+			app.serviceError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), collectionCenterKey{}, center)))
+	}
+}
+
+// currentCollectionCenter returns the center authenticated by
+// CollectionCenterMiddleware
+func currentCollectionCenter(r *http.Request) *data.CollectionCenter {
+	center, _ := r.Context().Value(collectionCenterKey{}).(*data.CollectionCenter)
+	return center
+}
+
+// CreateCollectionCenterHandler handles registering a milk collection center
+// run by the authenticated user. The response carries the center's API key,
+// which is not shown again.
+func (app *Config) CreateCollectionCenterHandler(w http.ResponseWriter, r *http.Request) {
+	var req CollectionCenterRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	center, apiKey, err := app.Services.Dairy.CreateCenter(r.Context(), user, dairy.CenterInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CollectionCenterResponse{
+		Success: true,
+		Message: "Collection center created successfully",
+		Center:  center,
+		APIKey:  apiKey,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetCollectionCentersHandler handles listing the collection centers the
+// authenticated user runs
+func (app *Config) GetCollectionCentersHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	centers, err := app.Services.Dairy.ListCenters(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CollectionCenterResponse{
+		Success: true,
+		Message: "Collection centers retrieved successfully",
+		Centers: centers,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetCollectionCenterHandler handles retrieving a collection center
+func (app *Config) GetCollectionCenterHandler(w http.ResponseWriter, r *http.Request) {
+	centerID := resourceID(r)
+	if centerID == "" {
+		app.errorJSON(w, errors.New("collection center ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	center, err := app.Services.Dairy.GetCenter(r.Context(), user, centerID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CollectionCenterResponse{
+		Success: true,
+		Message: "Collection center retrieved successfully",
+		Center:  center,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateCollectionCenterHandler handles updating a collection center
+func (app *Config) UpdateCollectionCenterHandler(w http.ResponseWriter, r *http.Request) {
+	var req CollectionCenterRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	centerID := resourceID(r)
+	if centerID == "" {
+		app.errorJSON(w, errors.New("collection center ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	center, err := app.Services.Dairy.UpdateCenter(r.Context(), user, centerID, dairy.CenterInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CollectionCenterResponse{
+		Success: true,
+		Message: "Collection center updated successfully",
+		Center:  center,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RotateCollectionCenterKeyHandler handles replacing a collection center's
+// API key; the old key stops working at once
+func (app *Config) RotateCollectionCenterKeyHandler(w http.ResponseWriter, r *http.Request) {
+	centerID := resourceID(r)
+	if centerID == "" {
+		app.errorJSON(w, errors.New("collection center ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	center, apiKey, err := app.Services.Dairy.RotateKey(r.Context(), user, centerID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := CollectionCenterResponse{
+		Success: true,
+		Message: "API key rotated successfully",
+		Center:  center,
+		APIKey:  apiKey,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// LinkMilkSupplierHandler handles a farm owner registering their farm
+// (?farmId=) with a collection center so the center can post its deliveries
+func (app *Config) LinkMilkSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	var req MilkSupplierRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	centerID := resourceID(r)
+	if centerID == "" {
+		app.errorJSON(w, errors.New("collection center ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	supplier, err := app.Services.Dairy.LinkFarm(r.Context(), user, centerID, farmID, req.SupplierNumber)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkSupplierResponse{
+		Success:  true,
+		Message:  "Farm registered with collection center successfully",
+		Supplier: supplier,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetMilkSuppliersHandler handles listing the farms registered with a
+// collection center
+func (app *Config) GetMilkSuppliersHandler(w http.ResponseWriter, r *http.Request) {
+	centerID := resourceID(r)
+	if centerID == "" {
+		app.errorJSON(w, errors.New("collection center ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	suppliers, err := app.Services.Dairy.ListSuppliers(r.Context(), user, centerID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkSupplierResponse{
+		Success:   true,
+		Message:   "Milk suppliers retrieved successfully",
+		Suppliers: suppliers,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFarmCollectionCentersHandler handles listing the collection centers a
+// farm (?farmId=) is registered with
+func (app *Config) GetFarmCollectionCentersHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	suppliers, err := app.Services.Dairy.ListFarmCenters(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkSupplierResponse{
+		Success:   true,
+		Message:   "Collection centers retrieved successfully",
+		Suppliers: suppliers,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UnlinkMilkSupplierHandler handles removing a farm's registration with a
+// collection center
+func (app *Config) UnlinkMilkSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	supplierID := resourceID(r)
+	if supplierID == "" {
+		app.errorJSON(w, errors.New("milk supplier ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Dairy.UnlinkFarm(r.Context(), user, supplierID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkSupplierResponse{
+		Success: true,
+		Message: "Farm unregistered from collection center successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMilkDeliveriesHandler handles listing a farm's (?farmId=) milk
+// deliveries, optionally between ?from= and ?to=
+func (app *Config) GetMilkDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := app.Services.Dairy.FarmDeliveries(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkDeliveryResponse{
+		Success:    true,
+		Message:    "Milk deliveries retrieved successfully",
+		Deliveries: deliveries,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMilkStatementsHandler handles a farm's (?farmId=) payment statements
+// for a month (?month=YYYY-MM, the current month if omitted), one per
+// collection center
+func (app *Config) GetMilkStatementsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	statements, err := app.Services.Dairy.FarmStatements(r.Context(), user, farmID, r.URL.Query().Get("month"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkStatementResponse{
+		Success:    true,
+		Message:    "Milk statements retrieved successfully",
+		Statements: statements,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// PostCollectionDeliveryHandler handles a collection center's system posting
+// a farmer's delivery. Posting a receipt number again corrects the delivery.
+func (app *Config) PostCollectionDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	var req MilkDeliveryRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	delivery, created, err := app.Services.Dairy.RecordDelivery(r.Context(), currentCollectionCenter(r), dairy.DeliveryInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkDeliveryResponse{
+		Success:  true,
+		Message:  "Milk delivery updated successfully",
+		Delivery: delivery,
+	}
+	status := http.StatusOK
+	if created {
+		response.Message = "Milk delivery recorded successfully"
+		status = http.StatusCreated
+	}
+
+	app.writeJSON(w, status, response)
+}
+
+// GetCollectionDeliveriesHandler handles a collection center's system listing
+// its deliveries, optionally between ?from= and ?to=
+func (app *Config) GetCollectionDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := app.Services.Dairy.CenterDeliveries(r.Context(), currentCollectionCenter(r), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkDeliveryResponse{
+		Success:    true,
+		Message:    "Milk deliveries retrieved successfully",
+		Deliveries: deliveries,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetCollectionStatementsHandler handles a collection center's system
+// fetching a month's (?month=YYYY-MM) payment statements for its suppliers
+func (app *Config) GetCollectionStatementsHandler(w http.ResponseWriter, r *http.Request) {
+	statements, err := app.Services.Dairy.CenterStatements(r.Context(), currentCollectionCenter(r), r.URL.Query().Get("month"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MilkStatementResponse{
+		Success:    true,
+		Message:    "Milk statements retrieved successfully",
+		Statements: statements,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
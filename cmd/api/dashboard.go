@@ -0,0 +1,107 @@
+package main
+
+import (
+	"farm4u/data"
+	"farm4u/service/dashboard"
+	"net/http"
+)
+
+// DashboardLayoutRequest represents the dashboard layout request body
+type DashboardLayoutRequest struct {
+	Widgets []data.DashboardWidget `json:"widgets"` // In display order
+}
+
+// DashboardLayoutResponse represents the dashboard layout response
+type DashboardLayoutResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message"`
+	Layout  *data.DashboardLayout `json:"layout"`
+	// Saved is false while the user is shown the default layout
+	Saved bool `json:"saved"`
+	// Available lists every widget that can be placed on the dashboard
+	Available []dashboard.Widget `json:"available"`
+	Sizes     []string           `json:"sizes"`
+}
+
+// Validate checks the dashboard layout request fields
+func (req *DashboardLayoutRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Widgets != nil, "widgets", "is required")
+	v.Check(len(req.Widgets) <= len(dashboard.Widgets), "widgets", "has more widgets than there are to place")
+	return v.Errors()
+}
+
+// dashboardLayoutResponse builds the response for a layout
+func dashboardLayoutResponse(message string, layout *data.DashboardLayout) DashboardLayoutResponse {
+	return DashboardLayoutResponse{
+		Success:   true,
+		Message:   message,
+		Layout:    layout,
+		Saved:     layout.DashboardLayoutID != "",
+		Available: dashboard.Widgets,
+		Sizes:     dashboard.Sizes,
+	}
+}
+
+// GetDashboardLayoutHandler handles retrieving the authenticated user's
+// dashboard layout, or the default layout if they have not arranged one
+func (app *Config) GetDashboardLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	layout, err := app.Services.Dashboard.Get(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, dashboardLayoutResponse("Dashboard layout retrieved successfully", layout))
+}
+
+// SaveDashboardLayoutHandler handles replacing the authenticated user's
+// dashboard layout with the widgets given, in display order
+func (app *Config) SaveDashboardLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req DashboardLayoutRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	layout, err := app.Services.Dashboard.Save(r.Context(), user, req.Widgets)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, dashboardLayoutResponse("Dashboard layout saved successfully", layout))
+}
+
+// ResetDashboardLayoutHandler handles discarding the authenticated user's
+// dashboard layout so the default is shown again
+func (app *Config) ResetDashboardLayoutHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	layout, err := app.Services.Dashboard.Reset(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, dashboardLayoutResponse("Dashboard layout reset successfully", layout))
+}
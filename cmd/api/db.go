@@ -1,79 +1,61 @@
 package main
 
 import (
-	"farm4u/data"
-	"fmt"
 	"log"
-	"os"
+	"strconv"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
+// Connection defaults, each overridable from the environment
+const (
+	defaultMaxOpenConns    = 100              // DB_MAX_OPEN_CONNS
+	defaultMaxIdleConns    = 10               // DB_MAX_IDLE_CONNS
+	defaultConnMaxLifetime = time.Hour        // DB_CONN_MAX_LIFETIME
+	defaultConnMaxIdleTime = 30 * time.Minute // DB_CONN_MAX_IDLE_TIME
+	// defaultConnectAttempts and defaultConnectBackoff bound the wait for
+	// the database at startup (DB_CONNECT_ATTEMPTS, DB_CONNECT_BACKOFF). The
+	// backoff doubles after each failed attempt, up to maxConnectBackoff.
+	defaultConnectAttempts = 10
+	defaultConnectBackoff  = time.Second
+	maxConnectBackoff      = 30 * time.Second
+)
+
+// defaultQueryTimeout bounds how long a single statement may run unless
+// DB_QUERY_TIMEOUT says otherwise. Requests cancelled by the client stop
+// their queries sooner, through the request context.
+const defaultQueryTimeout = 30 * time.Second
+
 func (app *Config) initDB() *gorm.DB {
-	conn := connectToDB()
+	conn := connectToDB(app.Settings.DB)
 	if conn == nil {
 		log.Panic("can't connect to database")
 	}
 
-	// Auto-migrate the schema using actual model structs, not interfaces
-	log.Println("Starting database migration...")
-	if err := conn.AutoMigrate(
-		&data.User{},
-		&data.Farm{},
-		&data.Crop{},
-		&data.Livestock{},
-		&data.Employee{},
-	); err != nil {
-		log.Panic("failed to migrate database:", err)
+	if app.Settings.DB.MigrateOnStart {
+		if err := migrate(conn); err != nil {
+			log.Panic("failed to migrate database: ", err)
+		}
 	}
-	log.Println("✅ Database migration completed successfully")
 
 	return conn
 }
 
-func connectToDB() *gorm.DB {
-	counts := 0
-
-	// Get database connection details from environment variables or use defaults
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost"
-	}
-
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "5433"
-	}
-
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "postgres"
-	}
-
-	dbPassword := os.Getenv("DB_PASSWORD")
-	if dbPassword == "" {
-		dbPassword = "postgres"
-	}
-
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "farm_manager_4u"
-	}
-
-	// Construct the DSN string
-	dsn := os.Getenv("DSN")
-	if dsn == "" {
-		dsn = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-			dbHost, dbPort, dbUser, dbPassword, dbName)
-	}
+// connectToDB connects to the database, retrying with exponential backoff
+// while it is not yet up, as when Postgres starts alongside the API in
+// Docker. It returns nil once the attempts run out.
+func connectToDB(cfg DBConfig) *gorm.DB {
+	backoff := cfg.ConnectBackoff
 
-	log.Printf("Attempting to connect to database with DSN: %s", dsn)
+	log.Printf("Attempting to connect to database with DSN: %s", cfg.DSN)
 
-	for {
-		connection, err := openDB(dsn)
+	for attempt := 1; ; attempt++ {
+		connection, err := openDB(cfg)
 		if err != nil {
 			log.Println("postgres not yet ready...")
 			log.Printf("Connection error: %v", err)
@@ -82,23 +64,31 @@ func connectToDB() *gorm.DB {
 			return connection
 		}
 
-		if counts > 10 {
+		if attempt >= cfg.ConnectAttempts {
+			log.Printf("Giving up after %d attempts", cfg.ConnectAttempts)
 			return nil
 		}
 
-		log.Print("Backing off for 1 second")
-		time.Sleep(1 * time.Second)
-		counts++
+		log.Printf("Backing off for %s", backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
 	}
 }
 
-func openDB(dsn string) (*gorm.DB, error) {
+func openDB(cfg DBConfig) (*gorm.DB, error) {
 	config := &gorm.Config{
 		DisableForeignKeyConstraintWhenMigrating: true,
 		Logger:                                   logger.Default.LogMode(logger.Info),
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), config)
+	pgxConfig, err := pgx.ParseConfig(cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	// PostgreSQL cancels any statement running longer than this
+	pgxConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.QueryTimeout.Milliseconds(), 10)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: stdlib.OpenDB(*pgxConfig)}), config)
 	if err != nil {
 		return nil, err
 	}
@@ -110,13 +100,15 @@ func openDB(dsn string) (*gorm.DB, error) {
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	// Test the connection
 	err = sqlDB.Ping()
 	if err != nil {
+		sqlDB.Close()
 		return nil, err
 	}
 
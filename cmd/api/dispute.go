@@ -0,0 +1,328 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/dispute"
+	"fmt"
+	"net/http"
+)
+
+// DisputeRequest represents the dispute creation request body
+type DisputeRequest struct {
+	RespondentID  string                   `json:"respondentId"`
+	SaleReference string                   `json:"saleReference"`
+	Reason        string                   `json:"reason"`
+	Description   string                   `json:"description"`
+	Evidence      []DisputeEvidenceRequest `json:"evidence"`
+}
+
+// DisputeEvidenceRequest represents a piece of dispute evidence
+type DisputeEvidenceRequest struct {
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// DisputeResolveRequest represents the admin arbitration request body
+type DisputeResolveRequest struct {
+	Outcome    string `json:"outcome"`
+	Resolution string `json:"resolution"`
+}
+
+// DisputeResponse represents the dispute response
+type DisputeResponse struct {
+	Success  bool                  `json:"success"`
+	Message  string                `json:"message"`
+	Dispute  *data.Dispute         `json:"dispute,omitempty"`
+	Disputes []*data.Dispute       `json:"disputes,omitempty"`
+	Evidence *data.DisputeEvidence `json:"evidence,omitempty"`
+}
+
+// Validate checks the dispute request fields
+func (req *DisputeRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("respondentId", req.RespondentID)
+	v.Required("saleReference", req.SaleReference)
+	v.Required("reason", req.Reason)
+	v.OneOf("reason", req.Reason, "Not Delivered", "Quality", "Quantity", "Payment", "Other")
+	v.Required("description", req.Description)
+	for i, evidence := range req.Evidence {
+		v.Required(fmt.Sprintf("evidence[%d].url", i), evidence.URL)
+	}
+	return v.Errors()
+}
+
+// Validate checks the dispute evidence request fields
+func (req *DisputeEvidenceRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("url", req.URL)
+	return v.Errors()
+}
+
+// Validate checks the arbitration request fields
+func (req *DisputeResolveRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("outcome", req.Outcome)
+	v.OneOf("outcome", req.Outcome, dispute.OutcomeUpheld, dispute.OutcomeRejected)
+	v.Required("resolution", req.Resolution)
+	return v.Errors()
+}
+
+// disputeInput converts the request to the dispute service input
+func (req *DisputeRequest) disputeInput() dispute.Input {
+	in := dispute.Input{
+		RespondentID:  req.RespondentID,
+		SaleReference: req.SaleReference,
+		Reason:        req.Reason,
+		Description:   req.Description,
+	}
+	for _, evidence := range req.Evidence {
+		in.Evidence = append(in.Evidence, dispute.EvidenceInput(evidence))
+	}
+	return in
+}
+
+// CreateDisputeHandler handles a farmer or buyer raising a dispute about a sale
+func (app *Config) CreateDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	var req DisputeRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Dispute.Open(r.Context(), user, req.disputeInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success: true,
+		Message: "Dispute opened successfully",
+		Dispute: d,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetDisputesHandler handles retrieving the disputes the user raised or responds to
+func (app *Config) GetDisputesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	disputes, err := app.Services.Dispute.List(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success:  true,
+		Message:  "Disputes retrieved successfully",
+		Disputes: disputes,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDisputeHandler handles retrieving a single dispute with its evidence
+func (app *Config) GetDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	disputeID := resourceID(r)
+	if disputeID == "" {
+		app.errorJSON(w, errors.New("dispute ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Dispute.Get(r.Context(), user, disputeID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success: true,
+		Message: "Dispute retrieved successfully",
+		Dispute: d,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AddDisputeEvidenceHandler handles either party attaching evidence to a dispute
+func (app *Config) AddDisputeEvidenceHandler(w http.ResponseWriter, r *http.Request) {
+	var req DisputeEvidenceRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	disputeID := resourceID(r)
+	if disputeID == "" {
+		app.errorJSON(w, errors.New("dispute ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	evidence, err := app.Services.Dispute.AddEvidence(r.Context(), user, disputeID, dispute.EvidenceInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success:  true,
+		Message:  "Evidence added successfully",
+		Evidence: evidence,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// WithdrawDisputeHandler handles the claimant withdrawing a dispute
+func (app *Config) WithdrawDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	disputeID := resourceID(r)
+	if disputeID == "" {
+		app.errorJSON(w, errors.New("dispute ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Dispute.Withdraw(r.Context(), user, disputeID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success: true,
+		Message: "Dispute withdrawn successfully",
+		Dispute: d,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminListDisputesHandler lists disputes by ?status= (default Open; "all"
+// for every status), oldest first
+func (app *Config) AdminListDisputesHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = dispute.StatusOpen
+	case "all":
+		status = ""
+	case dispute.StatusOpen, dispute.StatusUnderReview, dispute.StatusResolved, dispute.StatusWithdrawn:
+	default:
+		app.errorJSON(w, errors.New("status must be Open, Under Review, Resolved, Withdrawn or all"), http.StatusBadRequest)
+		return
+	}
+
+	disputes, err := app.Services.Dispute.ListByStatus(r.Context(), status)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success:  true,
+		Message:  "Disputes retrieved successfully",
+		Disputes: disputes,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminReviewDisputeHandler takes an open dispute into review
+func (app *Config) AdminReviewDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	disputeID := resourceID(r)
+	if disputeID == "" {
+		app.errorJSON(w, errors.New("dispute ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	d, err := app.Services.Dispute.Review(r.Context(), disputeID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success: true,
+		Message: "Dispute taken into review",
+		Dispute: d,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminResolveDisputeHandler records the arbitration decision on a dispute
+func (app *Config) AdminResolveDisputeHandler(w http.ResponseWriter, r *http.Request) {
+	var req DisputeResolveRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	disputeID := resourceID(r)
+	if disputeID == "" {
+		app.errorJSON(w, errors.New("dispute ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	admin, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Dispute.Resolve(r.Context(), admin, disputeID, req.Outcome, req.Resolution)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DisputeResponse{
+		Success: true,
+		Message: "Dispute resolved successfully",
+		Dispute: d,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
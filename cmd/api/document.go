@@ -0,0 +1,357 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/attachment"
+	"farm4u/service/document"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultDocumentWindowDays is how far ahead the expiring documents list
+// looks by default
+const defaultDocumentWindowDays = 60
+
+// DocumentRequest represents the document creation/update request body
+type DocumentRequest struct {
+	Type         string     `json:"type"` // Land Title, Certification, Insurance Policy, Permit, License, Contract, ID Copy, Other
+	Title        string     `json:"title"`
+	Number       string     `json:"number"`
+	Issuer       string     `json:"issuer"`
+	IssuedAt     *time.Time `json:"issuedAt"`
+	ExpiresAt    *time.Time `json:"expiresAt"`
+	ReminderDays int        `json:"reminderDays"` // Days before expiry to send a reminder, default 30
+	Notes        string     `json:"notes"`
+	EmployeeID   string     `json:"employeeId"` // Employee the paper is for, e.g. a contract or certification
+}
+
+// DocumentFileRequest represents the request body for adding a scan of a
+// document. The file itself is sent afterwards to the upload URL in the
+// response, as for attachments.
+type DocumentFileRequest struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"` // image/jpeg, image/png, image/webp, image/heic or application/pdf
+	Size        int64  `json:"size"`        // Bytes
+	Caption     string `json:"caption"`
+}
+
+// DocumentResponse represents the document response
+type DocumentResponse struct {
+	Success   bool             `json:"success"`
+	Message   string           `json:"message"`
+	Document  *data.Document   `json:"document,omitempty"`
+	Documents []*data.Document `json:"documents,omitempty"`
+	File      *data.Attachment `json:"file,omitempty"`
+	// Upload is where to send a new file's content
+	Upload *attachment.Transfer `json:"upload,omitempty"`
+}
+
+// Validate checks the document request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *DocumentRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("type", req.Type)
+		v.Required("title", req.Title)
+	}
+	v.OneOf("type", req.Type, document.Types...)
+	v.Check(req.ReminderDays >= 0 && req.ReminderDays <= 365, "reminderDays", "must be between 0 and 365")
+	if req.IssuedAt != nil {
+		v.Check(!req.IssuedAt.After(time.Now()), "issuedAt", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateDocumentHandler handles adding a document to a farm
+func (app *Config) CreateDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	var req DocumentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Document.Create(r.Context(), user, farmID, document.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success:  true,
+		Message:  "Document created successfully",
+		Document: d,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetDocumentsHandler handles retrieving a farm's documents, optionally of
+// one ?type= and only those with every ?tag=
+func (app *Config) GetDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	documents, err := app.Services.Document.List(r.Context(), user, farmID, r.URL.Query().Get("type"), queryTags(r))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success:   true,
+		Message:   "Documents retrieved successfully",
+		Documents: documents,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetEmployeeDocumentsHandler handles retrieving the documents held for an
+// employee, such as their contract and certifications
+func (app *Config) GetEmployeeDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	documents, err := app.Services.Document.ListForEmployee(r.Context(), user, employeeID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success:   true,
+		Message:   "Documents retrieved successfully",
+		Documents: documents,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetExpiringDocumentsHandler lists a farm's documents that expire within
+// ?days= days (default 60), including expired ones
+func (app *Config) GetExpiringDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	days := defaultDocumentWindowDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 365 {
+			app.errorJSON(w, errors.New("days must be between 0 and 365"), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	documents, err := app.Services.Document.Expiring(r.Context(), user, farmID, days)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success:   true,
+		Message:   "Expiring documents retrieved successfully",
+		Documents: documents,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDocumentHandler handles retrieving a document with its files
+func (app *Config) GetDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	documentID := resourceID(r)
+	if documentID == "" {
+		app.errorJSON(w, errors.New("document ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Document.Get(r.Context(), user, documentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success:  true,
+		Message:  "Document retrieved successfully",
+		Document: d,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateDocumentHandler handles document updates, such as a renewed policy's
+// new expiry date
+func (app *Config) UpdateDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	var req DocumentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	documentID := resourceID(r)
+	if documentID == "" {
+		app.errorJSON(w, errors.New("document ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := app.Services.Document.Update(r.Context(), user, documentID, document.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success:  true,
+		Message:  "Document updated successfully",
+		Document: d,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteDocumentHandler handles document deletion
+func (app *Config) DeleteDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	documentID := resourceID(r)
+	if documentID == "" {
+		app.errorJSON(w, errors.New("document ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Document.Delete(r.Context(), user, documentID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success: true,
+		Message: "Document deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AddDocumentFileHandler handles adding a scan of a document. The response
+// says where to upload the file, as when creating an attachment.
+func (app *Config) AddDocumentFileHandler(w http.ResponseWriter, r *http.Request) {
+	var req DocumentFileRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	documentID := resourceID(r)
+	if documentID == "" {
+		app.errorJSON(w, errors.New("document ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	in := AttachmentRequest{
+		RecordType:  attachment.RecordDocument,
+		RecordID:    documentID,
+		FileName:    req.FileName,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		Caption:     req.Caption,
+	}
+	if errs := in.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	a, upload, err := app.Services.Attachment.Create(r.Context(), user, attachment.Input(in))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DocumentResponse{
+		Success: true,
+		Message: "File created; upload it to complete it",
+		File:    a,
+		Upload:  attachmentUpload(a, upload),
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// DownloadDocumentFileHandler handles fetching a document's most recently
+// uploaded file: a redirect to a presigned URL when the storage backend
+// supports it, the file itself otherwise
+func (app *Config) DownloadDocumentFileHandler(w http.ResponseWriter, r *http.Request) {
+	documentID := resourceID(r)
+	if documentID == "" {
+		app.errorJSON(w, errors.New("document ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	a, download, err := app.Services.Document.File(r.Context(), user, documentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	if download != nil {
+		http.Redirect(w, r, download.URL, http.StatusFound)
+		return
+	}
+
+	app.sendAttachment(w, r, user, a.AttachmentID)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/workforce"
 	"net/http"
 	"time"
 )
@@ -17,6 +19,7 @@ type EmployeeRequest struct {
 	HireDate    *time.Time `json:"hireDate"`
 	ContactInfo string     `json:"contactInfo"`
 	Status      string     `json:"status"`
+	Version     int        `json:"version"` // Required on update: the version being changed; a stale one gets 409 with the current record
 }
 
 // EmployeeResponse represents the employee response
@@ -27,6 +30,32 @@ type EmployeeResponse struct {
 	Employees []*data.Employee `json:"employees,omitempty"`
 }
 
+// Validate checks the employee request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *EmployeeRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("firstName", req.FirstName)
+		v.Required("lastName", req.LastName)
+		v.Required("position", req.Position)
+	}
+	v.Check(req.Salary >= 0, "salary", "must be >= 0")
+	v.OneOf("status", req.Status, "Active", "Inactive", "Terminated")
+	return v.Errors()
+}
+
+// ValidatePatch checks an employee PATCH body, whose set fields may be
+// cleared, salary to 0, except for those an employee cannot do without
+func (req *EmployeeRequest) ValidatePatch(set service.Fields) ValidationErrors {
+	v := newValidator()
+	v.Check(!set["firstName"] || req.FirstName != "", "firstName", "cannot be cleared")
+	v.Check(!set["lastName"] || req.LastName != "", "lastName", "cannot be cleared")
+	v.Check(!set["position"] || req.Position != "", "position", "cannot be cleared")
+	v.Check(!set["status"] || req.Status != "", "status", "cannot be cleared")
+	v.Merge(req.Validate(true))
+	return v.Errors()
+}
+
 // CreateEmployeeHandler handles employee creation
 func (app *Config) CreateEmployeeHandler(w http.ResponseWriter, r *http.Request) {
 	var req EmployeeRequest
@@ -36,89 +65,19 @@ func (app *Config) CreateEmployeeHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Validate required fields
-	if req.FirstName == "" || req.LastName == "" || req.Position == "" {
-		app.errorJSON(w, errors.New("firstName, lastName, and position are required"), http.StatusBadRequest)
-		return
-	}
-
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("farmId")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
-		return
-	}
-
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
-		return
-	}
-
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Verify farm exists and belongs to user
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
+	employee, err := app.Services.Workforce.CreateEmployee(r.Context(), user, farmID, workforce.EmployeeInput(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("farm not found or access denied"), http.StatusForbidden)
-		return
-	}
-
-	// If UserID is provided, verify the user exists
-	if req.UserID != nil && *req.UserID != "" {
-		linkedUser, err := app.Models.User.GetByEmail(*req.UserID) // Assuming UserID is email for now
-		if err != nil {
-			app.ErrorLog.Printf("Error getting linked user: %v", err)
-			app.errorJSON(w, errors.New("linked user not found"), http.StatusBadRequest)
-			return
-		}
-		if linkedUser == nil {
-			app.errorJSON(w, errors.New("linked user not found"), http.StatusBadRequest)
-			return
-		}
-		req.UserID = &linkedUser.UserID // Use the actual UserID
-	}
-
-	// Set default status if not provided
-	if req.Status == "" {
-		req.Status = "Active"
-	}
-
-	// Create new employee
-	employee := &data.Employee{
-		UserID:      req.UserID,
-		FarmID:      farmID,
-		FirstName:   req.FirstName,
-		LastName:    req.LastName,
-		Position:    req.Position,
-		Salary:      req.Salary,
-		HireDate:    req.HireDate,
-		ContactInfo: req.ContactInfo,
-		Status:      req.Status,
-	}
-
-	// Insert employee
-	if err := app.Models.Employee.Insert(employee); err != nil {
-		app.ErrorLog.Printf("Error creating employee: %v", err)
-		app.errorJSON(w, errors.New("failed to create employee"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
@@ -131,58 +90,35 @@ func (app *Config) CreateEmployeeHandler(w http.ResponseWriter, r *http.Request)
 	app.writeJSON(w, http.StatusCreated, response)
 }
 
+// CreateEmployeesBatchHandler handles adding several employees to a farm at
+// once, as when a farm is first set up or an offline client syncs
+func (app *Config) CreateEmployeesBatchHandler(w http.ResponseWriter, r *http.Request) {
+	batchCreate(app, w, r, "employees", func(req *EmployeeRequest) ValidationErrors { return req.Validate(false) },
+		func(user *data.User, farmID string, reqs []EmployeeRequest) ([]*data.Employee, []error, error) {
+			ins := make([]workforce.EmployeeInput, len(reqs))
+			for i, req := range reqs {
+				ins[i] = workforce.EmployeeInput(req)
+			}
+			return app.Services.Workforce.CreateEmployees(r.Context(), user, farmID, ins)
+		})
+}
+
 // GetEmployeeHandler handles retrieving a single employee by ID
 func (app *Config) GetEmployeeHandler(w http.ResponseWriter, r *http.Request) {
-	// Get employee ID from URL parameters
-	employeeID := r.URL.Query().Get("id")
+	employeeID := resourceID(r)
 	if employeeID == "" {
 		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get employee by ID
-	employee, err := app.Models.Employee.GetByEmployeeID(employeeID)
+	employee, err := app.Services.Workforce.GetEmployee(r.Context(), user, employeeID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting employee: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if employee == nil {
-		app.errorJSON(w, errors.New("employee not found"), http.StatusNotFound)
-		return
-	}
-
-	// Verify that the employee belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
-	}
-
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(employee.FarmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: employee does not belong to user's farm"), http.StatusForbidden)
+		app.serviceError(w, err)
 		return
 	}
 
@@ -197,51 +133,14 @@ func (app *Config) GetEmployeeHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetEmployeesHandler handles retrieving all employees for a farm
 func (app *Config) GetEmployeesHandler(w http.ResponseWriter, r *http.Request) {
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("farmId")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
-		return
-	}
-
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
-		return
-	}
-
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
-	}
-
-	// Verify farm exists and belongs to user
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("farm not found or access denied"), http.StatusForbidden)
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Get employees by farm ID
-	employees, err := app.Models.Employee.GetByFarmID(farmID)
+	employees, err := app.Services.Workforce.ListEmployees(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting employees: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
@@ -263,111 +162,74 @@ func (app *Config) UpdateEmployeeHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Get employee ID from URL parameters
-	employeeID := r.URL.Query().Get("id")
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	employeeID := resourceID(r)
 	if employeeID == "" {
 		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get existing employee
-	existingEmployee, err := app.Models.Employee.GetByEmployeeID(employeeID)
+	employee, err := app.Services.Workforce.UpdateEmployee(r.Context(), user, employeeID, workforce.EmployeeInput(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting employee: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if existingEmployee == nil {
-		app.errorJSON(w, errors.New("employee not found"), http.StatusNotFound)
-		return
+	response := EmployeeResponse{
+		Success:  true,
+		Message:  "Employee updated successfully",
+		Employee: employee,
 	}
 
-	// Verify that the employee belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
+	app.writeJSON(w, http.StatusOK, response)
+}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
-	}
+// PatchEmployeeHandler handles partial employee updates. Only the fields in the body
+// change; one sent as null, blank or zero is cleared.
+func (app *Config) PatchEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	var req EmployeeRequest
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(existingEmployee.FarmID)
+	set, err := app.readPatch(w, r, &req)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: employee does not belong to user's farm"), http.StatusForbidden)
+	if errs := req.ValidatePatch(set); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// If UserID is provided, verify the user exists
-	if req.UserID != nil && *req.UserID != "" {
-		linkedUser, err := app.Models.User.GetByEmail(*req.UserID) // Assuming UserID is email for now
-		if err != nil {
-			app.ErrorLog.Printf("Error getting linked user: %v", err)
-			app.errorJSON(w, errors.New("linked user not found"), http.StatusBadRequest)
-			return
-		}
-		if linkedUser == nil {
-			app.errorJSON(w, errors.New("linked user not found"), http.StatusBadRequest)
-			return
-		}
-		req.UserID = &linkedUser.UserID // Use the actual UserID
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
 	}
 
-	// Update employee fields if provided
-	if req.FirstName != "" {
-		existingEmployee.FirstName = req.FirstName
-	}
-	if req.LastName != "" {
-		existingEmployee.LastName = req.LastName
-	}
-	if req.Position != "" {
-		existingEmployee.Position = req.Position
-	}
-	if req.Salary > 0 {
-		existingEmployee.Salary = req.Salary
-	}
-	if req.HireDate != nil {
-		existingEmployee.HireDate = req.HireDate
-	}
-	if req.ContactInfo != "" {
-		existingEmployee.ContactInfo = req.ContactInfo
-	}
-	if req.Status != "" {
-		existingEmployee.Status = req.Status
-	}
-	if req.UserID != nil {
-		existingEmployee.UserID = req.UserID
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
 	}
 
-	// Update employee
-	if err := app.Models.Employee.Update(existingEmployee); err != nil {
-		app.ErrorLog.Printf("Error updating employee: %v", err)
-		app.errorJSON(w, errors.New("failed to update employee"), http.StatusInternalServerError)
+	employee, err := app.Services.Workforce.PatchEmployee(r.Context(), user, employeeID, workforce.EmployeeInput(req), set)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := EmployeeResponse{
 		Success:  true,
 		Message:  "Employee updated successfully",
-		Employee: existingEmployee,
+		Employee: employee,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -375,69 +237,75 @@ func (app *Config) UpdateEmployeeHandler(w http.ResponseWriter, r *http.Request)
 
 // DeleteEmployeeHandler handles employee deletion
 func (app *Config) DeleteEmployeeHandler(w http.ResponseWriter, r *http.Request) {
-	// Get employee ID from URL parameters
-	employeeID := r.URL.Query().Get("id")
+	employeeID := resourceID(r)
 	if employeeID == "" {
 		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get employee to verify it exists
-	employee, err := app.Models.Employee.GetByEmployeeID(employeeID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting employee: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if err := app.Services.Workforce.DeleteEmployee(r.Context(), user, employeeID); err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
-	if employee == nil {
-		app.errorJSON(w, errors.New("employee not found"), http.StatusNotFound)
+	response := EmployeeResponse{
+		Success: true,
+		Message: "Employee deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDeletedEmployeesHandler handles listing soft-deleted employees of a farm
+func (app *Config) GetDeletedEmployeesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Verify that the employee belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	employees, err := app.Services.Workforce.ListDeletedEmployees(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
+	response := EmployeeResponse{
+		Success:   true,
+		Message:   "Deleted employees retrieved successfully",
+		Employees: employees,
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(employee.FarmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreEmployeeHandler handles restoring a soft-deleted employee
+func (app *Config) RestoreEmployeeHandler(w http.ResponseWriter, r *http.Request) {
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: employee does not belong to user's farm"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Delete employee (soft delete)
-	if err := app.Models.Employee.DeleteByID(int(employee.ID)); err != nil {
-		app.ErrorLog.Printf("Error deleting employee: %v", err)
-		app.errorJSON(w, errors.New("failed to delete employee"), http.StatusInternalServerError)
+	employee, err := app.Services.Workforce.RestoreEmployee(r.Context(), user, employeeID)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := EmployeeResponse{
-		Success: true,
-		Message: "Employee deleted successfully",
+		Success:  true,
+		Message:  "Employee restored successfully",
+		Employee: employee,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
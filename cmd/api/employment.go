@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"net/http"
+)
+
+// EmploymentResponse represents the employee self-service response
+type EmploymentResponse struct {
+	Success     bool                   `json:"success"`
+	Message     string                 `json:"message"`
+	Employments []*data.Employee       `json:"employments,omitempty"`
+	Attendance  []*data.Attendance     `json:"attendance,omitempty"`
+	Payslips    []*data.PayrollPayment `json:"payslips,omitempty"`
+}
+
+// GetMyEmploymentHandler lists the employee records linked to the
+// authenticated user, with the farms they work on
+func (app *Config) GetMyEmploymentHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	employments, err := app.Services.Workforce.Employments(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EmploymentResponse{
+		Success:     true,
+		Message:     "Employment retrieved successfully",
+		Employments: employments,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMyAttendanceHandler lists the authenticated user's own attendance for
+// one of their employments, optionally limited by ?from=/?to=
+func (app *Config) GetMyAttendanceHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Workforce.OwnAttendance(r.Context(), user, employeeID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EmploymentResponse{
+		Success:    true,
+		Message:    "Attendance retrieved successfully",
+		Attendance: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMyPayslipsHandler lists the authenticated user's own payslips for one
+// of their employments, optionally limited by ?from=/?to=
+func (app *Config) GetMyPayslipsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	payslips, err := app.Services.Workforce.OwnPayments(r.Context(), user, employeeID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EmploymentResponse{
+		Success:  true,
+		Message:  "Payslips retrieved successfully",
+		Payslips: payslips,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
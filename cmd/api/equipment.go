@@ -0,0 +1,408 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/equipment"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultMaintenanceWindowDays is how far ahead the maintenance-due list looks by default
+const defaultMaintenanceWindowDays = 14
+
+// EquipmentRequest represents the equipment creation/update request body
+type EquipmentRequest struct {
+	Name                    string     `json:"name"`
+	Type                    string     `json:"type"`
+	Make                    string     `json:"make"`
+	Model                   string     `json:"model"`
+	SerialNumber            string     `json:"serialNumber"`
+	PurchaseDate            *time.Time `json:"purchaseDate"`
+	PurchaseCost            float64    `json:"purchaseCost"`
+	Condition               string     `json:"condition"`
+	MaintenanceIntervalDays int        `json:"maintenanceIntervalDays"`
+	LastMaintenanceDate     *time.Time `json:"lastMaintenanceDate"`
+	NextMaintenanceDate     *time.Time `json:"nextMaintenanceDate"`
+	AssignedEmployeeID      *string    `json:"assignedEmployeeId"` // Empty string unassigns
+	Notes                   string     `json:"notes"`
+}
+
+// EquipmentResponse represents the equipment response
+type EquipmentResponse struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message"`
+	Equipment *data.Equipment   `json:"equipment,omitempty"`
+	Items     []*data.Equipment `json:"items,omitempty"`
+}
+
+// MaintenanceRecordRequest represents the maintenance log entry request body
+type MaintenanceRecordRequest struct {
+	Date              *time.Time `json:"date"`
+	Type              string     `json:"type"`
+	Description       string     `json:"description"`
+	Cost              float64    `json:"cost"`
+	DowntimeHours     float64    `json:"downtimeHours"`
+	PartsUsed         string     `json:"partsUsed"`
+	PerformedBy       string     `json:"performedBy"`
+	ServiceProviderID string     `json:"serviceProviderId"` // Contractor from the farm's directory
+	Notes             string     `json:"notes"`
+}
+
+// MaintenanceResponse represents the maintenance log response
+type MaintenanceResponse struct {
+	Success bool                         `json:"success"`
+	Message string                       `json:"message"`
+	Record  *data.MaintenanceRecord      `json:"record,omitempty"`
+	Records []*data.MaintenanceRecord    `json:"records,omitempty"`
+	Costs   []data.YearlyMaintenanceCost `json:"costs,omitempty"`
+}
+
+// Validate checks the equipment request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *EquipmentRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("type", req.Type)
+	}
+	v.OneOf("type", req.Type, "Tractor", "Irrigation Pump", "Harvester", "Vehicle", "Generator", "Implement", "Other")
+	v.OneOf("condition", req.Condition, "New", "Good", "Fair", "Poor", "Out of Service")
+	v.Check(req.PurchaseCost >= 0, "purchaseCost", "must be >= 0")
+	v.Check(req.MaintenanceIntervalDays >= 0, "maintenanceIntervalDays", "must be >= 0")
+	if req.PurchaseDate != nil {
+		v.Check(!req.PurchaseDate.After(time.Now()), "purchaseDate", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// Validate checks the maintenance log entry fields
+func (req *MaintenanceRecordRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("type", req.Type)
+	v.OneOf("type", req.Type, equipment.ScheduledService, "Repair", "Inspection", "Breakdown")
+	v.Check(req.Cost >= 0, "cost", "must be >= 0")
+	v.Check(req.DowntimeHours >= 0, "downtimeHours", "must be >= 0")
+	if req.Date != nil {
+		v.Check(!req.Date.After(time.Now()), "date", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateEquipmentHandler handles adding equipment to a farm's register
+func (app *Config) CreateEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req EquipmentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Equipment.Create(r.Context(), user, farmID, equipment.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EquipmentResponse{
+		Success:   true,
+		Message:   "Equipment created successfully",
+		Equipment: e,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetEquipmentHandler handles retrieving a single piece of equipment by ID
+func (app *Config) GetEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID := resourceID(r)
+	if equipmentID == "" {
+		app.errorJSON(w, errors.New("equipment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Equipment.Get(r.Context(), user, equipmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EquipmentResponse{
+		Success:   true,
+		Message:   "Equipment retrieved successfully",
+		Equipment: e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetEquipmentListHandler handles retrieving all equipment for a farm
+func (app *Config) GetEquipmentListHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := app.Services.Equipment.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EquipmentResponse{
+		Success: true,
+		Message: "Equipment retrieved successfully",
+		Items:   items,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateEquipmentHandler handles equipment updates
+func (app *Config) UpdateEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req EquipmentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	equipmentID := resourceID(r)
+	if equipmentID == "" {
+		app.errorJSON(w, errors.New("equipment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Equipment.Update(r.Context(), user, equipmentID, equipment.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EquipmentResponse{
+		Success:   true,
+		Message:   "Equipment updated successfully",
+		Equipment: e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteEquipmentHandler handles equipment deletion
+func (app *Config) DeleteEquipmentHandler(w http.ResponseWriter, r *http.Request) {
+	equipmentID := resourceID(r)
+	if equipmentID == "" {
+		app.errorJSON(w, errors.New("equipment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Equipment.Delete(r.Context(), user, equipmentID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EquipmentResponse{
+		Success: true,
+		Message: "Equipment deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMaintenanceDueHandler lists a farm's equipment due for service within
+// ?days= days (default 14), including overdue equipment
+func (app *Config) GetMaintenanceDueHandler(w http.ResponseWriter, r *http.Request) {
+	days := defaultMaintenanceWindowDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 365 {
+			app.errorJSON(w, errors.New("days must be between 0 and 365"), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := app.Services.Equipment.MaintenanceDue(r.Context(), user, farmID, days)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EquipmentResponse{
+		Success: true,
+		Message: "Equipment due for maintenance retrieved successfully",
+		Items:   items,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// LogMaintenanceHandler handles recording a service event on equipment
+func (app *Config) LogMaintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRecordRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	equipmentID := resourceID(r)
+	if equipmentID == "" {
+		app.errorJSON(w, errors.New("equipment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Equipment.LogMaintenance(r.Context(), user, equipmentID, equipment.MaintenanceInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MaintenanceResponse{
+		Success: true,
+		Message: "Maintenance logged successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetMaintenanceRecordsHandler handles retrieving the maintenance log of
+// equipment, optionally limited by ?from=/?to=
+func (app *Config) GetMaintenanceRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	equipmentID := resourceID(r)
+	if equipmentID == "" {
+		app.errorJSON(w, errors.New("equipment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Equipment.ListMaintenance(r.Context(), user, equipmentID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MaintenanceResponse{
+		Success: true,
+		Message: "Maintenance records retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteMaintenanceRecordHandler handles deleting a maintenance log entry
+func (app *Config) DeleteMaintenanceRecordHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("maintenance record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Equipment.DeleteMaintenance(r.Context(), user, recordID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MaintenanceResponse{
+		Success: true,
+		Message: "Maintenance record deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMaintenanceCostsHandler reports a farm's maintenance cost and downtime
+// per machine per year, optionally limited by ?equipmentId= and ?year=
+func (app *Config) GetMaintenanceCostsHandler(w http.ResponseWriter, r *http.Request) {
+	year := 0
+	if v := r.URL.Query().Get("year"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1900 || n > 9999 {
+			app.errorJSON(w, errors.New("year must be a four-digit year"), http.StatusBadRequest)
+			return
+		}
+		year = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	costs, err := app.Services.Equipment.MaintenanceCosts(r.Context(), user, farmID, r.URL.Query().Get("equipmentId"), year)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MaintenanceResponse{
+		Success: true,
+		Message: "Maintenance costs retrieved successfully",
+		Costs:   costs,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/escrow"
+	"net/http"
+)
+
+// EscrowRequest represents the escrow payment request body
+type EscrowRequest struct {
+	FarmerID         string  `json:"farmerId"`
+	SaleReference    string  `json:"saleReference"`
+	Amount           float64 `json:"amount"`
+	PaymentReference string  `json:"paymentReference"`
+	HoldDays         int     `json:"holdDays"` // Days until automatic release (default 7)
+	Notes            string  `json:"notes"`
+}
+
+// EscrowRedeemRequest represents the gate pass scan request body
+type EscrowRedeemRequest struct {
+	Code string `json:"code"`
+}
+
+// EscrowRefundRequest represents the refund request body
+type EscrowRefundRequest struct {
+	Reason string `json:"reason"`
+}
+
+// EscrowResponse represents the escrow response
+type EscrowResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Escrow  *data.Escrow   `json:"escrow,omitempty"`
+	Escrows []*data.Escrow `json:"escrows,omitempty"`
+}
+
+// Validate checks the escrow request fields
+func (req *EscrowRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("farmerId", req.FarmerID)
+	v.Required("saleReference", req.SaleReference)
+	v.Check(req.Amount > 0, "amount", "must be greater than 0")
+	v.Required("paymentReference", req.PaymentReference)
+	v.Check(req.HoldDays >= 0 && req.HoldDays <= 90, "holdDays", "must be between 0 and 90")
+	return v.Errors()
+}
+
+// Validate checks the gate pass scan request fields
+func (req *EscrowRedeemRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("code", req.Code)
+	return v.Errors()
+}
+
+// Validate checks the refund request fields
+func (req *EscrowRefundRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("reason", req.Reason)
+	return v.Errors()
+}
+
+// CreateEscrowHandler handles a buyer paying into escrow for a sale
+func (app *Config) CreateEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	var req EscrowRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Escrow.Hold(r.Context(), user, escrow.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Payment held in escrow",
+		Escrow:  e,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetEscrowsHandler handles retrieving the escrows the user pays into or is paid from
+func (app *Config) GetEscrowsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	escrows, err := app.Services.Escrow.List(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Escrows retrieved successfully",
+		Escrows: escrows,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetEscrowHandler handles retrieving a single escrow by ID
+func (app *Config) GetEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	escrowID := resourceID(r)
+	if escrowID == "" {
+		app.errorJSON(w, errors.New("escrow ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Escrow.Get(r.Context(), user, escrowID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Escrow retrieved successfully",
+		Escrow:  e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ConfirmEscrowHandler handles the buyer confirming delivery, releasing the funds
+func (app *Config) ConfirmEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	escrowID := resourceID(r)
+	if escrowID == "" {
+		app.errorJSON(w, errors.New("escrow ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Escrow.Confirm(r.Context(), user, escrowID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Delivery confirmed; funds released to the farmer",
+		Escrow:  e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RedeemEscrowHandler handles the farmer scanning the buyer's gate pass on
+// delivery, releasing the funds
+func (app *Config) RedeemEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	var req EscrowRedeemRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	escrowID := resourceID(r)
+	if escrowID == "" {
+		app.errorJSON(w, errors.New("escrow ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Escrow.Redeem(r.Context(), user, escrowID, req.Code)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Gate pass accepted; funds released",
+		Escrow:  e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RefundEscrowHandler handles returning held funds to the buyer. The farmer
+// may refund their own sale; admins may refund any.
+func (app *Config) RefundEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	var req EscrowRefundRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	escrowID := resourceID(r)
+	if escrowID == "" {
+		app.errorJSON(w, errors.New("escrow ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Escrow.Refund(r.Context(), user, escrowID, req.Reason)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Escrow refunded to the buyer",
+		Escrow:  e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminListEscrowsHandler lists escrows by ?status= (default Held; "all" for
+// every status)
+func (app *Config) AdminListEscrowsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = escrow.StatusHeld
+	case "all":
+		status = ""
+	case escrow.StatusHeld, escrow.StatusReleased, escrow.StatusRefunded:
+	default:
+		app.errorJSON(w, errors.New("status must be Held, Released, Refunded or all"), http.StatusBadRequest)
+		return
+	}
+
+	escrows, err := app.Services.Escrow.ListByStatus(r.Context(), status)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Escrows retrieved successfully",
+		Escrows: escrows,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminReleaseEscrowHandler releases held funds to the farmer after arbitration
+func (app *Config) AdminReleaseEscrowHandler(w http.ResponseWriter, r *http.Request) {
+	escrowID := resourceID(r)
+	if escrowID == "" {
+		app.errorJSON(w, errors.New("escrow ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	admin, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	e, err := app.Services.Escrow.Release(r.Context(), admin, escrowID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := EscrowResponse{
+		Success: true,
+		Message: "Escrow released to the farmer",
+		Escrow:  e,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/finance"
+	"net/http"
+	"time"
+)
+
+// ExchangeRateRequest represents the exchange rate creation/update request body
+type ExchangeRateRequest struct {
+	Currency string     `json:"currency"` // ISO 4217 code converted from, e.g. KES
+	Rate     float64    `json:"rate"`     // Farm currency per unit of currency
+	Date     *time.Time `json:"date"`     // Day the rate applies from; defaults to today
+	Notes    string     `json:"notes"`
+}
+
+// ExchangeRateResponse represents the exchange rate response
+type ExchangeRateResponse struct {
+	Success       bool                 `json:"success"`
+	Message       string               `json:"message"`
+	ExchangeRate  *data.ExchangeRate   `json:"exchangeRate,omitempty"`
+	ExchangeRates []*data.ExchangeRate `json:"exchangeRates,omitempty"`
+}
+
+// Validate checks the exchange rate request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *ExchangeRateRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("currency", req.Currency)
+		v.Check(req.Rate > 0, "rate", "must be greater than 0")
+	}
+	v.Check(req.Currency == "" || len(req.Currency) == 3, "currency", "must be a 3-letter code")
+	v.Check(req.Rate >= 0, "rate", "must be 0 or more")
+	return v.Errors()
+}
+
+// CreateExchangeRateHandler handles storing the rate a currency converts to
+// the farm currency at
+func (app *Config) CreateExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	var req ExchangeRateRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := app.Services.Finance.CreateExchangeRate(r.Context(), user, farmID, finance.ExchangeRateInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ExchangeRateResponse{
+		Success:      true,
+		Message:      "Exchange rate created successfully",
+		ExchangeRate: rate,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetExchangeRatesHandler handles listing a farm's exchange rates
+// (/api/exchange-rates?farmId=&currency=)
+func (app *Config) GetExchangeRatesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	rates, err := app.Services.Finance.ListExchangeRates(r.Context(), user, farmID, r.URL.Query().Get("currency"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ExchangeRateResponse{
+		Success:       true,
+		Message:       "Exchange rates retrieved successfully",
+		ExchangeRates: rates,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateExchangeRateHandler handles correcting an exchange rate. Transactions
+// already converted keep the rate they were converted at.
+func (app *Config) UpdateExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	var req ExchangeRateRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	exchangeRateID := resourceID(r)
+	if exchangeRateID == "" {
+		app.errorJSON(w, errors.New("exchange rate ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := app.Services.Finance.UpdateExchangeRate(r.Context(), user, exchangeRateID, finance.ExchangeRateInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ExchangeRateResponse{
+		Success:      true,
+		Message:      "Exchange rate updated successfully",
+		ExchangeRate: rate,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteExchangeRateHandler handles deleting an exchange rate
+func (app *Config) DeleteExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	exchangeRateID := resourceID(r)
+	if exchangeRateID == "" {
+		app.errorJSON(w, errors.New("exchange rate ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Finance.DeleteExchangeRate(r.Context(), user, exchangeRateID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ExchangeRateResponse{
+		Success: true,
+		Message: "Exchange rate deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
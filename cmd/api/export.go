@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/export"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// ExportJobResponse represents the account export response
+type ExportJobResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Export  *data.ExportJob   `json:"export,omitempty"`
+	Exports []*data.ExportJob `json:"exports,omitempty"`
+	// StatusURL is where to poll a queued export until it is Ready
+	StatusURL string `json:"statusUrl,omitempty"`
+	// DownloadURL is where to fetch a Ready export's ZIP
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
+
+// exportJobResponse builds the response for one export job, pointing at
+// where to poll or download it
+func exportJobResponse(message string, job *data.ExportJob) ExportJobResponse {
+	response := ExportJobResponse{
+		Success:   true,
+		Message:   message,
+		Export:    job,
+		StatusURL: "/api/v1/me/export/" + job.ExportJobID,
+	}
+	if job.Status == export.StatusReady {
+		response.DownloadURL = response.StatusURL + "/download"
+	}
+	return response
+}
+
+// RequestExportHandler handles requesting a copy of all of the authenticated
+// user's data: their profile, the farms they own with every record and
+// attached file, and their notifications, as a ZIP of JSON and CSV files. The
+// export is bundled in the background and the user notified when it is
+// Ready; poll the status URL, then download it.
+func (app *Config) RequestExportHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Export.Request(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	if job.Status == export.StatusPending {
+		app.background(func() { app.generateExport(job.ExportJobID) })
+	}
+
+	response := exportJobResponse("Export is being generated", job)
+	w.Header().Set("Location", response.StatusURL)
+	app.writeJSON(w, http.StatusAccepted, response)
+}
+
+// GetExportJobsHandler handles listing the authenticated user's exports
+func (app *Config) GetExportJobsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := app.Services.Export.List(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ExportJobResponse{
+		Success: true,
+		Message: "Exports retrieved successfully",
+		Exports: jobs,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetExportJobHandler handles polling an export's status
+func (app *Config) GetExportJobHandler(w http.ResponseWriter, r *http.Request) {
+	exportID := resourceID(r)
+	if exportID == "" {
+		app.errorJSON(w, errors.New("export ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Export.Get(r.Context(), user, exportID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, exportJobResponse("Export retrieved successfully", job))
+}
+
+// DownloadExportHandler handles downloading a Ready export's ZIP
+func (app *Config) DownloadExportHandler(w http.ResponseWriter, r *http.Request) {
+	exportID := resourceID(r)
+	if exportID == "" {
+		app.errorJSON(w, errors.New("export ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	file, job, err := app.Services.Export.Open(r.Context(), user, exportID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("farm4u-export-%s.zip", job.CreatedAt.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.FormatInt(job.Size, 10))
+	if _, err := io.Copy(w, file); err != nil {
+		app.ErrorLog.Printf("Error sending export %s: %v", job.ExportJobID, err)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"farm4u/data"
+	"farm4u/service/farm"
 	"net/http"
 )
 
@@ -13,7 +14,38 @@ type FarmRequest struct {
 	Location    string  `json:"location"`
 	Size        float64 `json:"size"`
 	FarmType    string  `json:"farmType"`
+	Currency    string  `json:"currency"` // ISO 4217 code, UGX by default; fixed once transactions are recorded
 	Status      string  `json:"status"`
+	Version     int     `json:"version"` // Required on update: the version being changed; a stale one gets 409 with the current record
+}
+
+// FarmTransferRequest represents the farm transfer request body
+type FarmTransferRequest struct {
+	Email   string `json:"email"`   // Account the farm is handed to
+	Reason  string `json:"reason"`  // e.g. Sale, Inheritance; kept in the audit log
+	Version int    `json:"version"` // Required: the farm version being transferred; a stale one gets 409
+}
+
+// Validate checks the farm transfer request fields
+func (req *FarmTransferRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("email", req.Email)
+	v.Email("email", req.Email)
+	return v.Errors()
+}
+
+// FarmCloneRequest represents the farm clone request body. Both fields are
+// optional.
+type FarmCloneRequest struct {
+	Name     string `json:"name"`     // Defaults to the cloned farm's name with " (copy)"
+	Location string `json:"location"` // Defaults to the cloned farm's location
+}
+
+// FarmCloneResponse represents the farm clone response
+type FarmCloneResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Clone   *farm.Cloned `json:"clone,omitempty"`
 }
 
 // FarmResponse represents the farm response
@@ -24,6 +56,21 @@ type FarmResponse struct {
 	Farms   []*data.Farm `json:"farms,omitempty"`
 }
 
+// Validate checks the farm request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *FarmRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("location", req.Location)
+		v.Check(req.Size > 0, "size", "must be greater than 0")
+	}
+	v.Check(req.Size >= 0, "size", "must be 0 or more")
+	v.OneOf("status", req.Status, "Active", "Inactive", "Suspended")
+	v.Check(req.Currency == "" || len(req.Currency) == 3, "currency", "must be a 3-letter code")
+	return v.Errors()
+}
+
 // CreateFarmHandler handles farm creation
 func (app *Config) CreateFarmHandler(w http.ResponseWriter, r *http.Request) {
 	var req FarmRequest
@@ -33,309 +80,340 @@ func (app *Config) CreateFarmHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.Name == "" || req.Location == "" {
-		app.errorJSON(w, errors.New("name and location are required"), http.StatusBadRequest)
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	f, err := app.Services.Farm.Create(r.Context(), user, farm.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
-	// Validate farm type
-	if req.FarmType == "" {
-		req.FarmType = "Mixed" // Default farm type
+	response := FarmResponse{
+		Success: true,
+		Message: "Farm created successfully",
+		Farm:    f,
 	}
 
-	// Validate status
-	if req.Status == "" {
-		req.Status = "Active" // Default status
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetFarmHandler handles retrieving a single farm by ID
+func (app *Config) GetFarmHandler(w http.ResponseWriter, r *http.Request) {
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+		return
 	}
 
-	// Validate size
-	if req.Size <= 0 {
-		app.errorJSON(w, errors.New("farm size must be greater than 0"), http.StatusBadRequest)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get user from database using email from JWT claims
-	user, err := app.Models.User.GetByEmail(userEmail)
+	f, err := app.Services.Farm.Get(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
+	response := FarmResponse{
+		Success: true,
+		Message: "Farm retrieved successfully",
+		Farm:    f,
 	}
 
-	// Create new farm
-	farm := &data.Farm{
-		Name:        req.Name,
-		Description: req.Description,
-		Location:    req.Location,
-		Size:        req.Size,
-		FarmType:    req.FarmType,
-		Status:      req.Status,
-		UserID:      user.UserID, // Use the actual UserID from the user record
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFarmsHandler handles retrieving all farms for a user
+func (app *Config) GetFarmsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
 	}
 
-	// Insert farm
-	if err := app.Models.Farm.Insert(farm); err != nil {
-		app.ErrorLog.Printf("Error creating farm: %v", err)
-		app.errorJSON(w, errors.New("failed to create farm"), http.StatusInternalServerError)
+	farms, err := app.Services.Farm.List(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := FarmResponse{
 		Success: true,
-		Message: "Farm created successfully",
-		Farm:    farm,
+		Message: "Farms retrieved successfully",
+		Farms:   farms,
 	}
 
-	app.writeJSON(w, http.StatusCreated, response)
+	app.writeJSON(w, http.StatusOK, response)
 }
 
-// GetFarmHandler handles retrieving a single farm by ID
-func (app *Config) GetFarmHandler(w http.ResponseWriter, r *http.Request) {
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("id")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
-		return
-	}
+// UpdateFarmHandler handles farm updates
+func (app *Config) UpdateFarmHandler(w http.ResponseWriter, r *http.Request) {
+	var req FarmRequest
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	// Get farm by ID
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	if farm == nil {
-		app.errorJSON(w, errors.New("farm not found"), http.StatusNotFound)
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	if user == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: farm does not belong to user"), http.StatusForbidden)
+	f, err := app.Services.Farm.Update(r.Context(), user, farmID, farm.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := FarmResponse{
 		Success: true,
-		Message: "Farm retrieved successfully",
-		Farm:    farm,
+		Message: "Farm updated successfully",
+		Farm:    f,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
 }
 
-// GetFarmsHandler handles retrieving all farms for a user
-func (app *Config) GetFarmsHandler(w http.ResponseWriter, r *http.Request) {
-	// Get user ID from JWT claims (set by JWT middleware)
-	userID := r.Header.Get("X-User-ID")
-	if userID == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+// DeleteFarmHandler handles farm deletion
+func (app *Config) DeleteFarmHandler(w http.ResponseWriter, r *http.Request) {
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims to get the actual UserID
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get user from database to get the actual UserID
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if err := app.Services.Farm.Delete(r.Context(), user, farmID); err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+	response := FarmResponse{
+		Success: true,
+		Message: "Farm deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDeletedFarmsHandler handles listing the authenticated user's soft-deleted farms
+func (app *Config) GetDeletedFarmsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get farms by user ID
-	farms, err := app.Models.Farm.GetByUserID(user.UserID)
+	farms, err := app.Services.Farm.ListDeleted(r.Context(), user)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farms: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
 	response := FarmResponse{
 		Success: true,
-		Message: "Farms retrieved successfully",
+		Message: "Deleted farms retrieved successfully",
 		Farms:   farms,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
 }
 
-// UpdateFarmHandler handles farm updates
-func (app *Config) UpdateFarmHandler(w http.ResponseWriter, r *http.Request) {
-	var req FarmRequest
+// GetArchivedFarmsHandler handles listing the authenticated user's archived
+// farms, which the farm list leaves out
+func (app *Config) GetArchivedFarmsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
 
-	if err := app.ReadJSON(w, r, &req); err != nil {
-		app.errorJSON(w, err, http.StatusBadRequest)
+	farms, err := app.Services.Farm.ListArchived(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("id")
+	response := FarmResponse{
+		Success: true,
+		Message: "Archived farms retrieved successfully",
+		Farms:   farms,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreFarmHandler handles restoring a soft-deleted farm
+func (app *Config) RestoreFarmHandler(w http.ResponseWriter, r *http.Request) {
+	farmID := resourceID(r)
 	if farmID == "" {
 		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get existing farm
-	existingFarm, err := app.Models.Farm.GetByFarmID(farmID)
+	f, err := app.Services.Farm.Restore(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if existingFarm == nil {
-		app.errorJSON(w, errors.New("farm not found"), http.StatusNotFound)
-		return
+	response := FarmResponse{
+		Success: true,
+		Message: "Farm restored successfully",
+		Farm:    f,
 	}
 
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ArchiveFarmHandler handles archiving a farm the user no longer runs. It
+// leaves their farm list and its records can still be read, as for reports
+// on past seasons, but not changed until it is unarchived.
+func (app *Config) ArchiveFarmHandler(w http.ResponseWriter, r *http.Request) {
+	app.setFarmArchived(w, r, true)
+}
+
+// UnarchiveFarmHandler handles returning an archived farm to use
+func (app *Config) UnarchiveFarmHandler(w http.ResponseWriter, r *http.Request) {
+	app.setFarmArchived(w, r, false)
+}
+
+// setFarmArchived archives or unarchives the farm named in the URL
+func (app *Config) setFarmArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if user == nil || existingFarm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: farm does not belong to user"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Update farm fields if provided
-	if req.Name != "" {
-		existingFarm.Name = req.Name
-	}
-	if req.Description != "" {
-		existingFarm.Description = req.Description
-	}
-	if req.Location != "" {
-		existingFarm.Location = req.Location
-	}
-	if req.Size > 0 {
-		existingFarm.Size = req.Size
-	}
-	if req.FarmType != "" {
-		existingFarm.FarmType = req.FarmType
-	}
-	if req.Status != "" {
-		existingFarm.Status = req.Status
+	archive, message := app.Services.Farm.Unarchive, "Farm unarchived successfully"
+	if archived {
+		archive, message = app.Services.Farm.Archive, "Farm archived successfully"
 	}
-
-	// Update farm
-	if err := app.Models.Farm.Update(existingFarm); err != nil {
-		app.ErrorLog.Printf("Error updating farm: %v", err)
-		app.errorJSON(w, errors.New("failed to update farm"), http.StatusInternalServerError)
+	f, err := archive(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := FarmResponse{
 		Success: true,
-		Message: "Farm updated successfully",
-		Farm:    existingFarm,
+		Message: message,
+		Farm:    f,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
 }
 
-// DeleteFarmHandler handles farm deletion
-func (app *Config) DeleteFarmHandler(w http.ResponseWriter, r *http.Request) {
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("id")
+// CloneFarmHandler handles creating a farm with the structure of one of the
+// user's farms: its fields, growing crops, irrigation schedules and inventory
+// items, so similar sites need not be entered again
+func (app *Config) CloneFarmHandler(w http.ResponseWriter, r *http.Request) {
+	var req FarmCloneRequest
+
+	if r.ContentLength != 0 {
+		if err := app.ReadJSON(w, r, &req); err != nil {
+			app.errorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	farmID := resourceID(r)
 	if farmID == "" {
 		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get farm to verify it exists
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
+	cloned, err := app.Services.Farm.Clone(r.Context(), user, farmID, farm.CloneInput(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if farm == nil {
-		app.errorJSON(w, errors.New("farm not found"), http.StatusNotFound)
+	response := FarmCloneResponse{
+		Success: true,
+		Message: "Farm cloned successfully",
+		Clone:   cloned,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// TransferFarmHandler handles handing a farm, with all its records, over to
+// another user, as on a sale or inheritance. The previous owner loses access,
+// members they added are removed and the farm's webhooks are paused.
+func (app *Config) TransferFarmHandler(w http.ResponseWriter, r *http.Request) {
+	var req FarmTransferRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if user == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: farm does not belong to user"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Delete farm (soft delete)
-	if err := app.Models.Farm.DeleteByID(int(farm.ID)); err != nil {
-		app.ErrorLog.Printf("Error deleting farm: %v", err)
-		app.errorJSON(w, errors.New("failed to delete farm"), http.StatusInternalServerError)
+	f, err := app.Services.Farm.Transfer(r.Context(), user, farmID, farm.TransferInput(req))
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := FarmResponse{
 		Success: true,
-		Message: "Farm deleted successfully",
+		Message: "Farm transferred successfully",
+		Farm:    f,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/farm"
+	"net/http"
+)
+
+// farmAccessKey is the context key holding a request's farmAccess
+type farmAccessKey struct{}
+
+// farmAccess is who a request is from and which farm it is for, resolved
+// once per request
+type farmAccess struct {
+	user   *data.User
+	farmID string // Farm named by ?farmId=, if any
+}
+
+// FarmAccess resolves the user and, when the request names a farm with
+// ?farmId=, checks the user owns the farm or has a role on it, refusing the
+// request with 403 before the handler runs if not. Finer checks, such as
+// whether a member's role allows the action, are left to the services. The
+// request also gets a farm cache, so the services' own checks of the same
+// farm do not read it again. Requests other than reads are marked as writes,
+// which the checks refuse on archived farms. JWTMiddleware runs every request
+// through it.
+func (app *Config) FarmAccess(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := farm.WithCache(r.Context())
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			ctx = farm.Writing(ctx)
+		}
+		access := &farmAccess{}
+		r = r.WithContext(context.WithValue(ctx, farmAccessKey{}, access))
+
+		if farmID := r.URL.Query().Get("farmId"); farmID != "" {
+			user, ok := app.currentUser(w, r)
+			if !ok {
+				return
+			}
+			if _, err := app.Services.Farm.Access(r.Context(), user, farmID); err != nil {
+				app.serviceError(w, err)
+				return
+			}
+			access.farmID = farmID
+		}
+
+		next(w, r)
+	}
+}
+
+// requestAccess returns the request's farmAccess, or nil outside FarmAccess
+func requestAccess(r *http.Request) *farmAccess {
+	access, _ := r.Context().Value(farmAccessKey{}).(*farmAccess)
+	return access
+}
+
+// currentFarm returns the authenticated user and the farm named by ?farmId=,
+// which FarmAccess has checked they may access. On failure the error response
+// has already been written and ok is false.
+func (app *Config) currentFarm(w http.ResponseWriter, r *http.Request) (*data.User, string, bool) {
+	access := requestAccess(r)
+	if access == nil || access.farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+		return nil, "", false
+	}
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return nil, "", false
+	}
+	return user, access.farmID, true
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/farm"
+	"net/http"
+)
+
+// FarmMemberRequest represents the request body for giving a user a role on a farm
+type FarmMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// FarmMemberResponse represents the farm member response
+type FarmMemberResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Member  *data.FarmMember   `json:"member,omitempty"`
+	Members []*data.FarmMember `json:"members,omitempty"`
+}
+
+// Validate checks the farm member request fields
+func (req *FarmMemberRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("email", req.Email)
+	v.Email("email", req.Email)
+	v.Required("role", req.Role)
+	v.OneOf("role", req.Role, farm.Roles()...)
+	return v.Errors()
+}
+
+// AddFarmMemberHandler handles a farm owner giving another user a role, such
+// as Accountant, on the farm
+func (app *Config) AddFarmMemberHandler(w http.ResponseWriter, r *http.Request) {
+	var req FarmMemberRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	member, err := app.Services.Farm.AddMember(r.Context(), user, farmID, farm.MemberInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FarmMemberResponse{
+		Success: true,
+		Message: "Farm member added successfully",
+		Member:  member,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetFarmMembersHandler handles listing the members of a farm
+func (app *Config) GetFarmMembersHandler(w http.ResponseWriter, r *http.Request) {
+	farmID := resourceID(r)
+	if farmID == "" {
+		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := app.Services.Farm.ListMembers(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FarmMemberResponse{
+		Success: true,
+		Message: "Farm members retrieved successfully",
+		Members: members,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSharedFarmsHandler handles listing the farms other users have given the
+// authenticated user a role on
+func (app *Config) GetSharedFarmsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := app.Services.Farm.Memberships(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FarmMemberResponse{
+		Success: true,
+		Message: "Shared farms retrieved successfully",
+		Members: members,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RemoveFarmMemberHandler handles a farm owner taking a member's access away
+func (app *Config) RemoveFarmMemberHandler(w http.ResponseWriter, r *http.Request) {
+	memberID := resourceID(r)
+	if memberID == "" {
+		app.errorJSON(w, errors.New("member ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Farm.RemoveMember(r.Context(), user, memberID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FarmMemberResponse{
+		Success: true,
+		Message: "Farm member removed successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
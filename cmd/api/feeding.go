@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/feeding"
+	"net/http"
+	"time"
+)
+
+// FeedingRequest represents the request body for recording a feeding
+type FeedingRequest struct {
+	LivestockID     string     `json:"livestockId"`     // Herd or flock fed
+	InventoryItemID string     `json:"inventoryItemId"` // Feed item, taken out of stock
+	Date            *time.Time `json:"date"`            // Defaults to now
+	Quantity        float64    `json:"quantity"`        // In the item's unit
+	Notes           string     `json:"notes"`
+}
+
+// FeedingResponse represents the feeding response
+type FeedingResponse struct {
+	Success   bool                      `json:"success"`
+	Message   string                    `json:"message"`
+	Record    *data.FeedingRecord       `json:"record,omitempty"`
+	Records   []*data.FeedingRecord     `json:"records,omitempty"`
+	Movements []*data.InventoryMovement `json:"movements,omitempty"`
+	Report    *feeding.CostReport       `json:"report,omitempty"`
+}
+
+// Validate checks the feeding request fields
+func (req *FeedingRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("livestockId", req.LivestockID)
+	v.Required("inventoryItemId", req.InventoryItemID)
+	v.Check(req.Quantity > 0, "quantity", "must be greater than 0")
+	return v.Errors()
+}
+
+// CreateFeedingHandler handles recording feed given to a herd, taking it out
+// of inventory first-expired-first-out
+func (app *Config) CreateFeedingHandler(w http.ResponseWriter, r *http.Request) {
+	var req FeedingRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, movements, err := app.Services.Feeding.Record(r.Context(), user, farmID, feeding.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FeedingResponse{
+		Success:   true,
+		Message:   "Feeding recorded successfully",
+		Record:    record,
+		Movements: movements,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetFeedingsHandler handles retrieving a farm's feedings, optionally of the
+// herd in the livestockId query parameter and between from and to
+// (YYYY-MM-DD)
+func (app *Config) GetFeedingsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Feeding.List(r.Context(), user, farmID, r.URL.Query().Get("livestockId"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FeedingResponse{
+		Success: true,
+		Message: "Feedings retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFeedCostsHandler reports a farm's feed cost per herd and per animal
+// between from and to (YYYY-MM-DD), the last 30 days by default
+func (app *Config) GetFeedCostsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.Services.Feeding.CostReport(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FeedingResponse{
+		Success: true,
+		Message: "Feed costs retrieved successfully",
+		Report:  report,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFeedingHandler handles retrieving a single feeding by ID
+func (app *Config) GetFeedingHandler(w http.ResponseWriter, r *http.Request) {
+	feedingRecordID := resourceID(r)
+	if feedingRecordID == "" {
+		app.errorJSON(w, errors.New("feeding record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Feeding.Get(r.Context(), user, feedingRecordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FeedingResponse{
+		Success: true,
+		Message: "Feeding retrieved successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/field"
+	"net/http"
+)
+
+// soilTypes are the accepted field soil types
+var soilTypes = []string{"Clay", "Sandy", "Loam", "Silt", "Peat", "Chalk", "Other"}
+
+// FieldRequest represents the field creation/update request body
+type FieldRequest struct {
+	Name     string  `json:"name"`
+	Area     float64 `json:"area"`
+	SoilType string  `json:"soilType"`
+	Notes    string  `json:"notes"`
+	Version  int     `json:"version"` // Required on update: the version being changed; a stale one gets 409 with the current record
+}
+
+// FieldResponse represents the field response
+type FieldResponse struct {
+	Success  bool          `json:"success"`
+	Message  string        `json:"message"`
+	Field    *data.Field   `json:"field,omitempty"`
+	Fields   []*data.Field `json:"fields,omitempty"`
+	Rotation []*data.Crop  `json:"rotation,omitempty"`
+}
+
+// Validate checks the field request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *FieldRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Check(req.Area > 0, "area", "must be greater than 0")
+	}
+	v.Check(req.Area >= 0, "area", "must be 0 or more")
+	v.OneOf("soilType", req.SoilType, soilTypes...)
+	return v.Errors()
+}
+
+// CreateFieldHandler handles adding a field to a farm
+func (app *Config) CreateFieldHandler(w http.ResponseWriter, r *http.Request) {
+	var req FieldRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	f, err := app.Services.Field.Create(r.Context(), user, farmID, field.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FieldResponse{
+		Success: true,
+		Message: "Field created successfully",
+		Field:   f,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetFieldHandler handles retrieving a single field by ID
+func (app *Config) GetFieldHandler(w http.ResponseWriter, r *http.Request) {
+	fieldID := resourceID(r)
+	if fieldID == "" {
+		app.errorJSON(w, errors.New("field ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	f, err := app.Services.Field.Get(r.Context(), user, fieldID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FieldResponse{
+		Success: true,
+		Message: "Field retrieved successfully",
+		Field:   f,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFieldsHandler handles retrieving all fields for a farm
+func (app *Config) GetFieldsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	fields, err := app.Services.Field.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FieldResponse{
+		Success: true,
+		Message: "Fields retrieved successfully",
+		Fields:  fields,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateFieldHandler handles field updates
+func (app *Config) UpdateFieldHandler(w http.ResponseWriter, r *http.Request) {
+	var req FieldRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	fieldID := resourceID(r)
+	if fieldID == "" {
+		app.errorJSON(w, errors.New("field ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	f, err := app.Services.Field.Update(r.Context(), user, fieldID, field.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FieldResponse{
+		Success: true,
+		Message: "Field updated successfully",
+		Field:   f,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteFieldHandler handles field deletion
+func (app *Config) DeleteFieldHandler(w http.ResponseWriter, r *http.Request) {
+	fieldID := resourceID(r)
+	if fieldID == "" {
+		app.errorJSON(w, errors.New("field ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Field.Delete(r.Context(), user, fieldID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FieldResponse{
+		Success: true,
+		Message: "Field deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFieldRotationHandler returns a field with the crops planted on it,
+// oldest planting first
+func (app *Config) GetFieldRotationHandler(w http.ResponseWriter, r *http.Request) {
+	fieldID := resourceID(r)
+	if fieldID == "" {
+		app.errorJSON(w, errors.New("field ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	f, crops, err := app.Services.Field.Rotation(r.Context(), user, fieldID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := FieldResponse{
+		Success:  true,
+		Message:  "Rotation history retrieved successfully",
+		Field:    f,
+		Rotation: crops,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/finance"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TransactionRequest represents the transaction creation/update request body
+type TransactionRequest struct {
+	Type         string     `json:"type"`
+	Category     string     `json:"category"`
+	Amount       float64    `json:"amount"`
+	Date         *time.Time `json:"date"`
+	Description  string     `json:"description"`
+	Notes        string     `json:"notes"`
+	SeasonID     *string    `json:"seasonId"`     // Empty string unlinks the transaction from its season
+	Currency     string     `json:"currency"`     // ISO 4217 code of the amount when not the farm currency
+	ExchangeRate *float64   `json:"exchangeRate"` // Farm currency per unit; the farm's stored rate for the date when not given
+}
+
+// TransactionResponse represents the transaction response
+type TransactionResponse struct {
+	Success      bool                `json:"success"`
+	Message      string              `json:"message"`
+	Transaction  *data.Transaction   `json:"transaction,omitempty"`
+	Transactions []*data.Transaction `json:"transactions,omitempty"`
+}
+
+// DepreciationResponse represents the asset depreciation report response
+type DepreciationResponse struct {
+	Success bool                        `json:"success"`
+	Message string                      `json:"message"`
+	Report  *finance.DepreciationReport `json:"report"`
+}
+
+// ProfitabilityResponse represents the profitability report response
+type ProfitabilityResponse struct {
+	Success bool                         `json:"success"`
+	Message string                       `json:"message"`
+	Report  *finance.ProfitabilityReport `json:"report"`
+}
+
+// Validate checks the transaction request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *TransactionRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("type", req.Type)
+		v.Required("category", req.Category)
+		v.Check(req.Amount > 0, "amount", "must be greater than 0")
+	}
+	v.OneOf("type", req.Type, "Income", "Expense")
+	v.Check(req.Amount >= 0, "amount", "must be >= 0")
+	v.Check(req.Currency == "" || len(req.Currency) == 3, "currency", "must be a 3-letter code")
+	v.Check(req.ExchangeRate == nil || *req.ExchangeRate > 0, "exchangeRate", "must be greater than 0")
+	return v.Errors()
+}
+
+// CreateTransactionHandler handles recording an income or expense
+func (app *Config) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	var req TransactionRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := app.Services.Finance.CreateTransaction(r.Context(), user, farmID, finance.TransactionInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TransactionResponse{
+		Success:     true,
+		Message:     "Transaction created successfully",
+		Transaction: transaction,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetTransactionsHandler handles retrieving a farm's transactions, optionally
+// limited by ?from=/?to=
+func (app *Config) GetTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := app.Services.Finance.ListTransactions(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TransactionResponse{
+		Success:      true,
+		Message:      "Transactions retrieved successfully",
+		Transactions: transactions,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAllTransactionsHandler handles retrieving the transactions of every
+// farm whose finances the user may see in one list, each with its farm,
+// optionally dated ?from= and ?to=
+func (app *Config) GetAllTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	transactions, err := app.Services.Finance.ListAllTransactions(r.Context(), user, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TransactionResponse{
+		Success:      true,
+		Message:      "Transactions retrieved successfully",
+		Transactions: transactions,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetTransactionHandler handles retrieving a single transaction by ID
+func (app *Config) GetTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	transactionID := resourceID(r)
+	if transactionID == "" {
+		app.errorJSON(w, errors.New("transaction ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := app.Services.Finance.GetTransaction(r.Context(), user, transactionID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TransactionResponse{
+		Success:     true,
+		Message:     "Transaction retrieved successfully",
+		Transaction: transaction,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateTransactionHandler handles transaction updates. Entries generated from
+// another record (e.g. a utility bill) are changed through that record.
+func (app *Config) UpdateTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	var req TransactionRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	transactionID := resourceID(r)
+	if transactionID == "" {
+		app.errorJSON(w, errors.New("transaction ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	transaction, err := app.Services.Finance.UpdateTransaction(r.Context(), user, transactionID, finance.TransactionInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TransactionResponse{
+		Success:     true,
+		Message:     "Transaction updated successfully",
+		Transaction: transaction,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteTransactionHandler handles transaction deletion
+func (app *Config) DeleteTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	transactionID := resourceID(r)
+	if transactionID == "" {
+		app.errorJSON(w, errors.New("transaction ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Finance.DeleteTransaction(r.Context(), user, transactionID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TransactionResponse{
+		Success: true,
+		Message: "Transaction deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetProfitabilityHandler reports income, direct costs and overheads for a
+// farm over an optional ?from=/?to= period
+func (app *Config) GetProfitabilityHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.Services.Finance.Profitability(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProfitabilityResponse{
+		Success: true,
+		Message: "Profitability report generated successfully",
+		Report:  report,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAssetDepreciationHandler reports the depreciation of a farm's equipment
+// and structures for ?year= (the current year by default), with each asset's
+// full schedule
+func (app *Config) GetAssetDepreciationHandler(w http.ResponseWriter, r *http.Request) {
+	year := 0
+	if v := r.URL.Query().Get("year"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1900 || n > 9999 {
+			app.errorJSON(w, errors.New("year must be a four-digit year"), http.StatusBadRequest)
+			return
+		}
+		year = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.Services.Finance.Depreciation(r.Context(), user, farmID, year)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := DepreciationResponse{
+		Success: true,
+		Message: "Depreciation report generated successfully",
+		Report:  report,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
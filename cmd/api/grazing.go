@@ -0,0 +1,492 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/grazing"
+	"io"
+	"net/http"
+	"time"
+)
+
+// PaddockRequest represents the paddock creation/update request body
+type PaddockRequest struct {
+	FieldID      *string `json:"fieldId"` // Empty string clears
+	Name         string  `json:"name"`
+	Area         float64 `json:"area"`
+	RestDays     int     `json:"restDays"`     // Defaults to 30
+	MaxGrazeDays int     `json:"maxGrazeDays"` // Defaults to 7
+	Notes        string  `json:"notes"`
+}
+
+// GrazingPlanRequest represents the request body for planning a herd's
+// rotation through paddocks
+type GrazingPlanRequest struct {
+	LivestockID string    `json:"livestockId"`
+	PaddockIDs  []string  `json:"paddockIds"` // In rotation order
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`       // Exclusive
+	GrazeDays   int       `json:"grazeDays"` // Each paddock's maxGrazeDays if zero
+}
+
+// GrazingMoveRequest represents the request body for recording a move that
+// was not planned
+type GrazingMoveRequest struct {
+	LivestockID string     `json:"livestockId"`
+	PaddockID   string     `json:"paddockId"`
+	MovedInAt   *time.Time `json:"movedInAt"`
+	MovedOutAt  *time.Time `json:"movedOutAt"`
+	Notes       string     `json:"notes"`
+}
+
+// GrazingMoveTimeRequest represents the optional request body for a herd
+// moving onto or off a paddock; the move is recorded now without one
+type GrazingMoveTimeRequest struct {
+	At *time.Time `json:"at"`
+}
+
+// PaddockResponse represents the paddock response
+type PaddockResponse struct {
+	Success  bool                   `json:"success"`
+	Message  string                 `json:"message"`
+	Paddock  *data.Paddock          `json:"paddock,omitempty"`
+	Paddocks []*data.Paddock        `json:"paddocks,omitempty"`
+	Rest     []*grazing.PaddockRest `json:"rest,omitempty"`
+}
+
+// GrazingResponse represents the grazing plan and move response
+type GrazingResponse struct {
+	Success  bool                `json:"success"`
+	Message  string              `json:"message"`
+	Plan     *grazing.Plan       `json:"plan,omitempty"`
+	Move     *data.GrazingMove   `json:"move,omitempty"`
+	Moves    []*data.GrazingMove `json:"moves,omitempty"`
+	Warnings []grazing.Warning   `json:"warnings,omitempty"`
+}
+
+// Validate checks the paddock request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *PaddockRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Check(req.Area > 0, "area", "must be greater than 0")
+	}
+	v.Check(req.Area >= 0, "area", "must be 0 or more")
+	v.Check(req.RestDays >= 0 && req.RestDays <= 365, "restDays", "must be between 0 and 365")
+	v.Check(req.MaxGrazeDays >= 0 && req.MaxGrazeDays <= 365, "maxGrazeDays", "must be between 0 and 365")
+	return v.Errors()
+}
+
+// Validate checks the grazing plan request fields
+func (req *GrazingPlanRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("livestockId", req.LivestockID)
+	v.Check(len(req.PaddockIDs) > 0, "paddockIds", "must list at least one paddock")
+	v.Check(!req.Start.IsZero(), "start", "is required")
+	v.Check(req.End.After(req.Start), "end", "must be after start")
+	v.Check(req.GrazeDays >= 0 && req.GrazeDays <= 365, "grazeDays", "must be between 0 and 365")
+	return v.Errors()
+}
+
+// Validate checks the grazing move request fields
+func (req *GrazingMoveRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("livestockId", req.LivestockID)
+	v.Required("paddockId", req.PaddockID)
+	if req.MovedInAt != nil && req.MovedOutAt != nil {
+		v.Check(!req.MovedOutAt.Before(*req.MovedInAt), "movedOutAt", "must not be before movedInAt")
+	}
+	return v.Errors()
+}
+
+// CreatePaddockHandler handles adding a paddock to a farm
+func (app *Config) CreatePaddockHandler(w http.ResponseWriter, r *http.Request) {
+	var req PaddockRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	paddock, err := app.Services.Grazing.CreatePaddock(r.Context(), user, farmID, grazing.PaddockInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PaddockResponse{
+		Success: true,
+		Message: "Paddock created successfully",
+		Paddock: paddock,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetPaddocksHandler handles retrieving all paddocks for a farm
+func (app *Config) GetPaddocksHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	paddocks, err := app.Services.Grazing.ListPaddocks(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PaddockResponse{
+		Success:  true,
+		Message:  "Paddocks retrieved successfully",
+		Paddocks: paddocks,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetPaddockRestHandler handles reporting how rested each of a farm's
+// paddocks is, today or on the date query parameter (YYYY-MM-DD)
+func (app *Config) GetPaddockRestHandler(w http.ResponseWriter, r *http.Request) {
+	on := time.Now().UTC()
+	if v := r.URL.Query().Get("date"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			app.errorJSON(w, errors.New("date must be in YYYY-MM-DD format"), http.StatusBadRequest)
+			return
+		}
+		on = t
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	rest, err := app.Services.Grazing.Rest(r.Context(), user, farmID, on)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PaddockResponse{
+		Success: true,
+		Message: "Paddock rest retrieved successfully",
+		Rest:    rest,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetPaddockHandler handles retrieving a single paddock by ID
+func (app *Config) GetPaddockHandler(w http.ResponseWriter, r *http.Request) {
+	paddockID := resourceID(r)
+	if paddockID == "" {
+		app.errorJSON(w, errors.New("paddock ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	paddock, err := app.Services.Grazing.GetPaddock(r.Context(), user, paddockID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PaddockResponse{
+		Success: true,
+		Message: "Paddock retrieved successfully",
+		Paddock: paddock,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdatePaddockHandler handles updating a paddock
+func (app *Config) UpdatePaddockHandler(w http.ResponseWriter, r *http.Request) {
+	var req PaddockRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	paddockID := resourceID(r)
+	if paddockID == "" {
+		app.errorJSON(w, errors.New("paddock ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	paddock, err := app.Services.Grazing.UpdatePaddock(r.Context(), user, paddockID, grazing.PaddockInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PaddockResponse{
+		Success: true,
+		Message: "Paddock updated successfully",
+		Paddock: paddock,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeletePaddockHandler handles deleting a paddock
+func (app *Config) DeletePaddockHandler(w http.ResponseWriter, r *http.Request) {
+	paddockID := resourceID(r)
+	if paddockID == "" {
+		app.errorJSON(w, errors.New("paddock ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Grazing.DeletePaddock(r.Context(), user, paddockID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PaddockResponse{
+		Success: true,
+		Message: "Paddock deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// PreviewGrazingPlanHandler handles planning a herd's rotation without
+// saving it, so its warnings can be looked at first
+func (app *Config) PreviewGrazingPlanHandler(w http.ResponseWriter, r *http.Request) {
+	app.grazingPlan(w, r, false)
+}
+
+// CreateGrazingPlanHandler handles planning a herd's rotation and saving its
+// moves
+func (app *Config) CreateGrazingPlanHandler(w http.ResponseWriter, r *http.Request) {
+	app.grazingPlan(w, r, true)
+}
+
+// grazingPlan plans the rotation in the request body, saving it when save is
+// true
+func (app *Config) grazingPlan(w http.ResponseWriter, r *http.Request, save bool) {
+	var req GrazingPlanRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	plan, err := app.Services.Grazing.Plan(r.Context(), user, farmID, grazing.PlanInput(req), save)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	status, message := http.StatusOK, "Grazing plan previewed successfully"
+	if save {
+		status, message = http.StatusCreated, "Grazing plan created successfully"
+	}
+	response := GrazingResponse{
+		Success: true,
+		Message: message,
+		Plan:    plan,
+	}
+
+	app.writeJSON(w, status, response)
+}
+
+// RecordGrazingMoveHandler handles recording a move a herd made without a
+// plan
+func (app *Config) RecordGrazingMoveHandler(w http.ResponseWriter, r *http.Request) {
+	var req GrazingMoveRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	move, warnings, err := app.Services.Grazing.RecordMove(r.Context(), user, farmID, grazing.MoveInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := GrazingResponse{
+		Success:  true,
+		Message:  "Grazing move recorded successfully",
+		Move:     move,
+		Warnings: warnings,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetGrazingMovesHandler handles retrieving a farm's grazing moves, optionally
+// filtered by the paddockId, livestockId and status query parameters
+func (app *Config) GetGrazingMovesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	filter := grazing.MoveFilter{
+		PaddockID:   r.URL.Query().Get("paddockId"),
+		LivestockID: r.URL.Query().Get("livestockId"),
+		Status:      r.URL.Query().Get("status"),
+	}
+	moves, err := app.Services.Grazing.ListMoves(r.Context(), user, farmID, filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := GrazingResponse{
+		Success: true,
+		Message: "Grazing moves retrieved successfully",
+		Moves:   moves,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetGrazingMoveHandler handles retrieving a single grazing move by ID
+func (app *Config) GetGrazingMoveHandler(w http.ResponseWriter, r *http.Request) {
+	app.grazingMoveAction(w, r, "Grazing move retrieved successfully", app.Services.Grazing.GetMove)
+}
+
+// CancelGrazingMoveHandler handles dropping a planned move
+func (app *Config) CancelGrazingMoveHandler(w http.ResponseWriter, r *http.Request) {
+	app.grazingMoveAction(w, r, "Grazing move cancelled successfully", app.Services.Grazing.CancelMove)
+}
+
+// MoveInGrazingMoveHandler handles a herd moving onto the paddock of a
+// planned move
+func (app *Config) MoveInGrazingMoveHandler(w http.ResponseWriter, r *http.Request) {
+	app.grazingMoveTime(w, r, "Herd moved in successfully", app.Services.Grazing.MoveIn)
+}
+
+// MoveOutGrazingMoveHandler handles a herd moving off a paddock
+func (app *Config) MoveOutGrazingMoveHandler(w http.ResponseWriter, r *http.Request) {
+	app.grazingMoveTime(w, r, "Herd moved out successfully", func(ctx context.Context, user *data.User, moveID string, at *time.Time) (*data.GrazingMove, []grazing.Warning, error) {
+		move, err := app.Services.Grazing.MoveOut(ctx, user, moveID, at)
+		return move, nil, err
+	})
+}
+
+// grazingMoveAction runs a grazing service call on the move in the URL and
+// writes the move back
+func (app *Config) grazingMoveAction(w http.ResponseWriter, r *http.Request, message string,
+	action func(context.Context, *data.User, string) (*data.GrazingMove, error)) {
+	moveID := resourceID(r)
+	if moveID == "" {
+		app.errorJSON(w, errors.New("grazing move ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	move, err := action(r.Context(), user, moveID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := GrazingResponse{
+		Success: true,
+		Message: message,
+		Move:    move,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// grazingMoveTime records a herd moving onto or off the paddock of the move
+// in the URL, at the time in the optional request body
+func (app *Config) grazingMoveTime(w http.ResponseWriter, r *http.Request, message string,
+	action func(context.Context, *data.User, string, *time.Time) (*data.GrazingMove, []grazing.Warning, error)) {
+	var req GrazingMoveTimeRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil && !errors.Is(err, io.EOF) {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	moveID := resourceID(r)
+	if moveID == "" {
+		app.errorJSON(w, errors.New("grazing move ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	move, warnings, err := action(r.Context(), user, moveID, req.At)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := GrazingResponse{
+		Success:  true,
+		Message:  message,
+		Move:     move,
+		Warnings: warnings,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"farm4u/data"
+	"farm4u/service/auth"
+	"fmt"
 	"net/http"
 	"strconv"
 )
 
+// selfServiceRoles are the roles a user may pick at signup. Other roles, such
+// as Admin, are granted through the admin API.
+var selfServiceRoles = []string{auth.DefaultRole, auth.BuyerRole}
+
 // SignupRequest represents the signup request body
 type SignupRequest struct {
 	FirstName   string `json:"firstName"`
@@ -24,6 +31,35 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// ForgotPasswordRequest represents the forgot-password request body
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest represents the reset-password request body
+type ResetPasswordRequest struct {
+	Email       string `json:"email"`
+	OTP         string `json:"otp"`
+	NewPassword string `json:"newPassword"`
+}
+
+// PhoneLoginRequest represents the phone login request body
+type PhoneLoginRequest struct {
+	PhoneNumber string `json:"phoneNumber"`
+}
+
+// PhoneVerifyRequest represents the phone login verification request body
+type PhoneVerifyRequest struct {
+	PhoneNumber string `json:"phoneNumber"`
+	OTP         string `json:"otp"`
+}
+
+// ChangePasswordRequest represents the change-password request body
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
 // AuthResponse represents the authentication response
 type AuthResponse struct {
 	Success bool       `json:"success"`
@@ -32,6 +68,75 @@ type AuthResponse struct {
 	Token   string     `json:"token,omitempty"`
 }
 
+// Validate checks the signup request fields, the password against policy
+func (req *SignupRequest) Validate(policy auth.PasswordPolicy) ValidationErrors {
+	v := newValidator()
+	v.Required("firstName", req.FirstName)
+	v.Required("lastName", req.LastName)
+	v.Required("email", req.Email)
+	v.Email("email", req.Email)
+	v.Required("password", req.Password)
+	v.Password("password", req.Password, policy, req.Email, req.FirstName, req.LastName)
+	v.OneOf("role", req.Role, selfServiceRoles...)
+	return v.Errors()
+}
+
+// Validate checks the login request fields
+func (req *LoginRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("email", req.Email)
+	v.Required("password", req.Password)
+	return v.Errors()
+}
+
+// Validate checks the forgot-password request fields
+func (req *ForgotPasswordRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("email", req.Email)
+	v.Email("email", req.Email)
+	return v.Errors()
+}
+
+// Validate checks the reset-password request fields, the new password
+// against policy
+func (req *ResetPasswordRequest) Validate(policy auth.PasswordPolicy) ValidationErrors {
+	v := newValidator()
+	v.Required("email", req.Email)
+	v.Required("otp", req.OTP)
+	v.Check(req.OTP == "" || len(req.OTP) == 6, "otp", "must be 6 digits")
+	v.Required("newPassword", req.NewPassword)
+	v.Password("newPassword", req.NewPassword, policy, req.Email)
+	return v.Errors()
+}
+
+// Validate checks the phone login request fields
+func (req *PhoneLoginRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("phoneNumber", req.PhoneNumber)
+	v.Phone("phoneNumber", req.PhoneNumber)
+	return v.Errors()
+}
+
+// Validate checks the phone login verification request fields
+func (req *PhoneVerifyRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("phoneNumber", req.PhoneNumber)
+	v.Phone("phoneNumber", req.PhoneNumber)
+	v.Required("otp", req.OTP)
+	v.Check(req.OTP == "" || len(req.OTP) == 6, "otp", "must be 6 digits")
+	return v.Errors()
+}
+
+// Validate checks the change-password request fields, the new password
+// against policy for user
+func (req *ChangePasswordRequest) Validate(policy auth.PasswordPolicy, user *data.User) ValidationErrors {
+	v := newValidator()
+	v.Required("currentPassword", req.CurrentPassword)
+	v.Required("newPassword", req.NewPassword)
+	v.Password("newPassword", req.NewPassword, policy, user.Email, user.FirstName, user.LastName)
+	return v.Errors()
+}
+
 // SignupHandler handles user registration
 func (app *Config) SignupHandler(w http.ResponseWriter, r *http.Request) {
 	var req SignupRequest
@@ -41,48 +146,17 @@ func (app *Config) SignupHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.FirstName == "" || req.LastName == "" || req.Email == "" || req.Password == "" {
-		app.errorJSON(w, errors.New("missing required fields"), http.StatusBadRequest)
+	if errs := req.Validate(app.Settings.PasswordPolicy); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Check if user already exists
-	existingUser, err := app.Models.User.GetByEmail(req.Email)
+	user, err := app.Services.Auth.Signup(r.Context(), auth.SignupInput(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error checking existing user: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if existingUser != nil {
-		app.errorJSON(w, errors.New("user with this email already exists"), http.StatusConflict)
-		return
-	}
-
-	// Create new user
-	user := &data.User{
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		Email:        req.Email,
-		TempPassword: req.Password,
-		Role:         req.Role,
-		PhoneNumber:  req.PhoneNumber,
-		Address:      req.Address,
-		Active:       true,
-	}
-
-	// Insert user (password will be hashed automatically)
-	if err := app.Models.User.Insert(user); err != nil {
-		app.ErrorLog.Printf("Error creating user: %v", err)
-		app.errorJSON(w, errors.New("failed to create user"), http.StatusInternalServerError)
-		return
-	}
-
-	// Clear sensitive data before sending response
-	user.Password = ""
-	user.TempPassword = ""
-
 	response := AuthResponse{
 		Success: true,
 		Message: "User created successfully",
@@ -101,41 +175,14 @@ func (app *Config) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.Email == "" || req.Password == "" {
-		app.errorJSON(w, errors.New("email and password are required"), http.StatusBadRequest)
-		return
-	}
-
-	// Get user by email
-	user, err := app.Models.User.GetByEmail(req.Email)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if user == nil {
-		app.errorJSON(w, errors.New("invalid email or password"), http.StatusUnauthorized)
-		return
-	}
-
-	// Check if user is active
-	if !user.Active {
-		app.errorJSON(w, errors.New("account is deactivated"), http.StatusUnauthorized)
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Verify password
-	matches, err := app.Models.User.PasswordMatches(user, req.Password)
+	user, err := app.Services.Auth.Authenticate(r.Context(), req.Email, req.Password)
 	if err != nil {
-		app.ErrorLog.Printf("Error checking password: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if !matches {
-		app.errorJSON(w, errors.New("invalid email or password"), http.StatusUnauthorized)
+		app.serviceError(w, err)
 		return
 	}
 
@@ -147,10 +194,6 @@ func (app *Config) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Clear sensitive data before sending response
-	user.Password = ""
-	user.TempPassword = ""
-
 	response := AuthResponse{
 		Success: true,
 		Message: "Login successful",
@@ -163,25 +206,21 @@ func (app *Config) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 // ForgotPasswordHandler handles password reset requests
 func (app *Config) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Email string `json:"email"`
-	}
+	var req ForgotPasswordRequest
 
 	if err := app.ReadJSON(w, r, &req); err != nil {
 		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if req.Email == "" {
-		app.errorJSON(w, errors.New("email is required"), http.StatusBadRequest)
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Check if user exists
-	user, err := app.Models.User.GetByEmail(req.Email)
+	user, otp, err := app.Services.Auth.RequestPasswordReset(r.Context(), req.Email)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
@@ -195,17 +234,12 @@ func (app *Config) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Generate OTP
-	otp, err := app.Models.User.GenerateAndSaveOTP(req.Email)
-	if err != nil {
-		app.ErrorLog.Printf("Error generating OTP: %v", err)
-		app.errorJSON(w, errors.New("failed to generate reset code"), http.StatusInternalServerError)
+	if err := app.sendPasswordResetCode(r.Context(), user, otp); err != nil {
+		app.ErrorLog.Printf("Error sending reset code: %v", err)
+		app.errorJSON(w, errors.New("failed to send reset code"), http.StatusServiceUnavailable)
 		return
 	}
 
-	// TODO: Send OTP via email/SMS
-	app.InfoLog.Printf("OTP for %s: %s", req.Email, otp)
-
 	response := AuthResponse{
 		Success: true,
 		Message: "Password reset code has been sent to your email",
@@ -216,26 +250,20 @@ func (app *Config) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request)
 
 // ResetPasswordHandler handles password reset with OTP
 func (app *Config) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Email       string `json:"email"`
-		OTP         string `json:"otp"`
-		NewPassword string `json:"newPassword"`
-	}
+	var req ResetPasswordRequest
 
 	if err := app.ReadJSON(w, r, &req); err != nil {
 		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if req.Email == "" || req.OTP == "" || req.NewPassword == "" {
-		app.errorJSON(w, errors.New("email, OTP, and new password are required"), http.StatusBadRequest)
+	if errs := req.Validate(app.Settings.PasswordPolicy); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Reset password with OTP
-	if err := app.Models.User.ResetPasswordWithOTP(req.Email, req.OTP, req.NewPassword); err != nil {
-		app.ErrorLog.Printf("Error resetting password: %v", err)
-		app.errorJSON(w, errors.New("invalid or expired reset code"), http.StatusBadRequest)
+	if err := app.Services.Auth.ResetPassword(r.Context(), req.Email, req.OTP, req.NewPassword); err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
@@ -247,32 +275,142 @@ func (app *Config) ResetPasswordHandler(w http.ResponseWriter, r *http.Request)
 	app.writeJSON(w, http.StatusOK, response)
 }
 
-// RefreshTokenHandler generates a new JWT token for authenticated users
-func (app *Config) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
-	// Get current user from token (assumes JWT middleware was used)
-	userID := r.Header.Get("X-User-ID")
-	if userID == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+// PhoneLoginHandler starts a passwordless login by texting a code to the
+// phone number of an account
+func (app *Config) PhoneLoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req PhoneLoginRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	// Convert userID to int
-	id, err := strconv.Atoi(userID)
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, otp, err := app.Services.Auth.RequestPhoneLogin(r.Context(), req.PhoneNumber)
 	if err != nil {
-		app.errorJSON(w, errors.New("invalid user ID"), http.StatusBadRequest)
+		app.serviceError(w, err)
 		return
 	}
 
-	// Get user from database
-	user, err := app.Models.User.GetOne(id)
+	// Don't reveal if an account has the number
+	response := AuthResponse{
+		Success: true,
+		Message: "If the phone number belongs to an account, a login code has been sent",
+	}
+
+	if user != nil {
+		body := fmt.Sprintf("Your Farm Manager 4U login code is %s. It expires in %d minutes.", otp, int(data.OTPTTL.Minutes()))
+		if err := app.Notifier.SendSMS(r.Context(), user.PhoneNumber, body); err != nil {
+			app.ErrorLog.Printf("Error sending login code: %v", err)
+			app.errorJSON(w, errors.New("failed to send login code"), http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// PhoneVerifyHandler completes a passwordless login, exchanging the texted
+// code for a token
+func (app *Config) PhoneVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	var req PhoneVerifyRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, err := app.Services.Auth.VerifyPhoneLogin(r.Context(), req.PhoneNumber, req.OTP)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by ID: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
+		return
+	}
+
+	token, err := app.GenerateJWT(user)
+	if err != nil {
+		app.ErrorLog.Printf("Error generating JWT token: %v", err)
+		app.errorJSON(w, errors.New("failed to generate authentication token"), http.StatusInternalServerError)
+		return
+	}
+
+	response := AuthResponse{
+		Success: true,
+		Message: "Login successful",
+		User:    user,
+		Token:   token,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ChangePasswordHandler sets a new password for the authenticated user, who
+// must give their current one
+func (app *Config) ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ChangePasswordRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if errs := req.Validate(app.Settings.PasswordPolicy, user); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	if user == nil || !user.Active {
-		app.errorJSON(w, errors.New("user not found or inactive"), http.StatusUnauthorized)
+	if err := app.Services.Auth.ChangePassword(r.Context(), user, req.CurrentPassword, req.NewPassword); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := AuthResponse{
+		Success: true,
+		Message: "Password changed successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// sendPasswordResetCode emails the reset code to the user, falling back to
+// SMS when email delivery fails on every provider and a phone number is known
+func (app *Config) sendPasswordResetCode(ctx context.Context, user *data.User, otp string) error {
+	body := fmt.Sprintf("Your Farm Manager 4U password reset code is %s. It expires in %d minutes.", otp, int(data.OTPTTL.Minutes()))
+
+	err := app.Notifier.SendEmail(ctx, user.Email, "Password reset code", body)
+	if err == nil || user.PhoneNumber == "" {
+		return err
+	}
+	app.ErrorLog.Printf("Error emailing reset code, trying SMS: %v", err)
+
+	return app.Notifier.SendSMS(ctx, user.PhoneNumber, body)
+}
+
+// RefreshTokenHandler generates a new JWT token for authenticated users
+func (app *Config) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	// Get current user from token (assumes JWT middleware was used)
+	id, err := strconv.Atoi(r.Header.Get("X-User-ID"))
+	if err != nil {
+		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := app.Services.Auth.Refresh(r.Context(), id)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
@@ -292,3 +430,28 @@ func (app *Config) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
 
 	app.writeJSON(w, http.StatusOK, response)
 }
+
+// LogoutHandler revokes the token the request was made with, so it is
+// refused from then on even if it was copied elsewhere
+func (app *Config) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	claims := app.tokenClaims(r)
+	if claims == nil {
+		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Auth.Revoke(r.Context(), user, claims.ID, claims.ExpiresAt.Time); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, AuthResponse{
+		Success: true,
+		Message: "Logged out successfully",
+	})
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"farm4u/migrations"
+	"farm4u/notify"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// readinessTimeout bounds how long /readyz waits for each dependency
+const readinessTimeout = 2 * time.Second
+
+// Health statuses
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded" // Serving, with a non-critical dependency down
+	healthDown     = "down"
+)
+
+// ComponentHealth is the state of one dependency in a readiness report
+type ComponentHealth struct {
+	Status string `json:"status"`
+	// Critical components must be up for the API to be ready
+	Critical bool   `json:"critical"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// HealthResponse represents the liveness and readiness responses
+type HealthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+// LivenessHandler reports that the process is up and serving HTTP. It checks
+// no dependencies, so an outage elsewhere does not get the API restarted.
+func (app *Config) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	app.writeJSON(w, http.StatusOK, HealthResponse{Status: healthOK})
+}
+
+// ReadinessHandler reports whether the API can serve requests: 200 while
+// every critical component (the database and its migrations) is up, 503
+// otherwise. The cache and the email providers are reported but not
+// critical, as the API works without them: cache misses go to the database
+// and emails are retried through the failover providers.
+func (app *Config) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	components := map[string]ComponentHealth{
+		"database":   app.databaseHealth(r.Context()),
+		"migrations": app.migrationsHealth(r.Context()),
+		"cache":      app.cacheHealth(r.Context()),
+		"email":      app.emailHealth(),
+	}
+
+	status, code := healthOK, http.StatusOK
+	for _, component := range components {
+		if component.Status == healthOK {
+			continue
+		}
+		if component.Critical {
+			status, code = healthDown, http.StatusServiceUnavailable
+			break
+		}
+		status = healthDegraded
+	}
+
+	app.writeJSON(w, code, HealthResponse{Status: status, Components: components})
+}
+
+// databaseHealth pings the database
+func (app *Config) databaseHealth(ctx context.Context) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	sqlDB, err := app.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		app.ErrorLog.Printf("Readiness: database unreachable: %v", err)
+		return ComponentHealth{Status: healthDown, Critical: true, Detail: "unreachable"}
+	}
+	return ComponentHealth{Status: healthOK, Critical: true}
+}
+
+// migrationsHealth checks that every migration has been applied
+func (app *Config) migrationsHealth(ctx context.Context) ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	pending, err := migrations.Pending(app.DB.WithContext(ctx))
+	if err != nil {
+		app.ErrorLog.Printf("Readiness: checking migrations: %v", err)
+		return ComponentHealth{Status: healthDown, Critical: true, Detail: "cannot read applied migrations"}
+	}
+	if len(pending) > 0 {
+		return ComponentHealth{Status: healthDown, Critical: true, Detail: fmt.Sprintf("%d pending, run migrate up", len(pending))}
+	}
+	return ComponentHealth{Status: healthOK, Critical: true}
+}
+
+// cacheHealth pings the cache
+func (app *Config) cacheHealth(ctx context.Context) ComponentHealth {
+	if app.Cache == nil {
+		return ComponentHealth{Status: healthOK, Detail: "none"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := app.Cache.Ping(ctx); err != nil {
+		app.ErrorLog.Printf("Readiness: cache unreachable: %v", err)
+		return ComponentHealth{Status: healthDown, Detail: app.Cache.Name() + " unreachable"}
+	}
+	return ComponentHealth{Status: healthOK, Detail: app.Cache.Name()}
+}
+
+// emailHealth reports whether an email provider is in rotation. It uses the
+// state kept by monitorNotifiers rather than dialling the providers on every
+// probe.
+func (app *Config) emailHealth() ComponentHealth {
+	if app.Notifier == nil {
+		return ComponentHealth{Status: healthDown, Detail: "no providers"}
+	}
+
+	down := 0
+	for _, provider := range app.Notifier.Status() {
+		if provider.Channel != notify.Email {
+			continue
+		}
+		if provider.Healthy {
+			return ComponentHealth{Status: healthOK}
+		}
+		down++
+	}
+	if down == 0 {
+		return ComponentHealth{Status: healthDown, Detail: "no providers"}
+	}
+	return ComponentHealth{Status: healthDown, Detail: fmt.Sprintf("all %d providers failing", down)}
+}
@@ -1,9 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
 type jsonResponse struct {
@@ -32,6 +41,56 @@ func (app *Config) ReadJSON(w http.ResponseWriter, r *http.Request, data any) er
 	return nil
 }
 
+// readPatch reads a PATCH body into data, a pointer to a request struct, and
+// returns the fields it names, so a field sent as null, blank or zero, which
+// clears it, is told apart from one left out, which is left unchanged. A
+// field data does not have, under its exact JSON name, is rejected rather
+// than ignored.
+func (app *Config) readPatch(w http.ResponseWriter, r *http.Request, data any) (service.Fields, error) {
+	var raw json.RawMessage
+	if err := app.ReadJSON(w, r, &raw); err != nil {
+		return nil, err
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &body); err != nil || body == nil {
+		return nil, errors.New("body must be a JSON object")
+	}
+
+	// The decoder matches names regardless of case; the fields set must be
+	// named exactly, as services look them up by name
+	known := jsonNames(data)
+	set := service.Fields{}
+	for field := range body {
+		if !known[field] {
+			return nil, fmt.Errorf("body contains unknown field %q", field)
+		}
+		set[field] = true
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(data); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// jsonNames returns the JSON names of the fields of the struct v points to
+func jsonNames(v any) map[string]bool {
+	names := map[string]bool{}
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		if name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
 func (app *Config) writeJSON(w http.ResponseWriter, status int, data any, headers ...http.Header) error {
 	out, err := json.Marshal(data)
 	if err != nil {
@@ -65,3 +124,102 @@ func (app *Config) errorJSON(w http.ResponseWriter, err error, status ...int) er
 
 	return app.writeJSON(w, statusCode, payload)
 }
+
+// resourceID returns the {id} URL parameter, falling back to the id query
+// parameter used by the original endpoints.
+func resourceID(r *http.Request) string {
+	if id := chi.URLParam(r, "id"); id != "" {
+		return id
+	}
+	return r.URL.Query().Get("id")
+}
+
+// parseDateRange reads the optional from/to query parameters (YYYY-MM-DD).
+// The returned "to" is exclusive and already moved to the following day.
+func parseDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, nil, errors.New("from must be in YYYY-MM-DD format")
+		}
+		from = &t
+	}
+
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, nil, errors.New("to must be in YYYY-MM-DD format")
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must be before to")
+	}
+
+	return from, to, nil
+}
+
+// queryTags reads the optional tag query parameter, given as ?tag=a,b or
+// repeated, dropping empty names
+func queryTags(r *http.Request) []string {
+	var tags []string
+	for _, v := range r.URL.Query()["tag"] {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				tags = append(tags, name)
+			}
+		}
+	}
+	return tags
+}
+
+// serviceError writes the response for an error returned by a domain
+// service. Unexpected errors are logged and reported as internal errors; a
+// stale update also carries the record as it is now in data.
+func (app *Config) serviceError(w http.ResponseWriter, err error) {
+	var status int
+	switch service.KindOf(err) {
+	case service.KindInvalid:
+		status = http.StatusBadRequest
+	case service.KindUnauthorized:
+		status = http.StatusUnauthorized
+	case service.KindForbidden:
+		status = http.StatusForbidden
+	case service.KindNotFound:
+		status = http.StatusNotFound
+	case service.KindConflict:
+		status = http.StatusConflict
+	default:
+		app.ErrorLog.Printf("Error %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+	if current := service.CurrentOf(err); current != nil {
+		app.writeJSON(w, status, jsonResponse{Error: true, Message: err.Error(), Data: current})
+		return
+	}
+	app.errorJSON(w, err, status)
+}
+
+// currentUser resolves the authenticated user from the JWT claims, once per
+// request under FarmAccess. On failure the error response has already been
+// written and ok is false.
+func (app *Config) currentUser(w http.ResponseWriter, r *http.Request) (*data.User, bool) {
+	access := requestAccess(r)
+	if access != nil && access.user != nil {
+		return access.user, true
+	}
+	user, err := app.Services.Auth.CurrentUser(r.Context(), r.Header.Get("X-User-Email"))
+	if err != nil {
+		app.serviceError(w, err)
+		return nil, false
+	}
+	if access != nil {
+		access.user = user
+	}
+	return user, true
+}
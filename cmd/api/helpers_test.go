@@ -0,0 +1,65 @@
+package main
+
+import (
+	"maps"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReadPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		set     []string
+		wantErr string
+	}{
+		{name: "sets named fields", body: `{"name":"Maize","quantity":40}`, set: []string{"name", "quantity"}},
+		{name: "null clears a field", body: `{"notes":null}`, set: []string{"notes"}},
+		{name: "zero and blank are set", body: `{"quantity":0,"notes":""}`, set: []string{"quantity", "notes"}},
+		{name: "empty object sets nothing", body: `{}`, set: []string{}},
+		{name: "unknown field", body: `{"colour":"red"}`, wantErr: `unknown field "colour"`},
+		{name: "miscased field", body: `{"Name":"Maize"}`, wantErr: `unknown field "Name"`},
+		{name: "array", body: `[{"name":"Maize"}]`, wantErr: "must be a JSON object"},
+		{name: "null body", body: `null`, wantErr: "must be a JSON object"},
+		{name: "two values", body: `{"name":"a"}{"name":"b"}`, wantErr: "single JSON value"},
+		{name: "wrong type", body: `{"quantity":"forty"}`, wantErr: "cannot unmarshal"},
+	}
+
+	app := &Config{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/crops/1", strings.NewReader(tt.body))
+			var req CropRequest
+			set, err := app.readPatch(httptest.NewRecorder(), r, &req)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]bool{}
+			for _, field := range tt.set {
+				want[field] = true
+			}
+			if !maps.Equal(set, want) {
+				t.Errorf("set = %v, want %v", set, want)
+			}
+		})
+	}
+}
+
+func TestReadPatchDecodesValues(t *testing.T) {
+	r := httptest.NewRequest("PATCH", "/crops/1", strings.NewReader(`{"name":"Maize","quantity":40,"version":3}`))
+	var req CropRequest
+	if _, err := (&Config{}).readPatch(httptest.NewRecorder(), r, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Name != "Maize" || req.Quantity != 40 || req.Version != 3 {
+		t.Errorf("decoded %+v", req)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/importer"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// maxImportUploadBytes caps the size of a file uploaded for import
+const maxImportUploadBytes = 10 << 20
+
+// ImportMappingRequest represents the import column mapping request body
+type ImportMappingRequest struct {
+	Mapping    map[string]string `json:"mapping"` // Record field to column header
+	DateFormat string            `json:"dateFormat"`
+}
+
+// ImportCommitRequest represents the optional import commit request body
+type ImportCommitRequest struct {
+	SkipInvalid bool `json:"skipInvalid"`
+}
+
+// ImportResponse represents the import response
+type ImportResponse struct {
+	Success bool                        `json:"success"`
+	Message string                      `json:"message"`
+	Import  *data.ImportJob             `json:"import,omitempty"`
+	Imports []*data.ImportJob           `json:"imports,omitempty"`
+	Fields  map[string][]importer.Field `json:"fields,omitempty"`
+	Sources []string                    `json:"sources,omitempty"`
+}
+
+// Validate checks the mapping request fields
+func (req *ImportMappingRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(len(req.Mapping) > 0, "mapping", "must map at least one field")
+	v.OneOf("dateFormat", req.DateFormat, importer.DateISO, importer.DateDMY, importer.DateMDY)
+	return v.Errors()
+}
+
+// readImportUpload reads the uploaded file, either sent as the "file" part of
+// a multipart form or as the raw request body, with its name
+func readImportUpload(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportUploadBytes)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		content, err := io.ReadAll(r.Body)
+		return content, r.URL.Query().Get("fileName"), err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", errors.New("the form must include a file")
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	return content, header.Filename, err
+}
+
+// GetImportFieldsHandler handles listing the fields each import target can
+// map columns to and the known source layouts
+func (app *Config) GetImportFieldsHandler(w http.ResponseWriter, r *http.Request) {
+	response := ImportResponse{
+		Success: true,
+		Message: "Import fields retrieved successfully",
+		Fields:  importer.Targets,
+		Sources: importer.Sources(),
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UploadImportHandler handles uploading a CSV file exported from another app
+// or spreadsheet (?target=crops|livestock|transactions|employees, optional
+// ?source=) and responds with the proposed column mapping
+func (app *Config) UploadImportHandler(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		app.errorJSON(w, errors.New("target is required"), http.StatusBadRequest)
+		return
+	}
+
+	content, fileName, err := readImportUpload(w, r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(content) == 0 {
+		app.errorJSON(w, errors.New("the file is empty"), http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Import.Upload(r.Context(), user, farmID, importer.UploadInput{
+		Target:   target,
+		Source:   r.URL.Query().Get("source"),
+		FileName: fileName,
+		Content:  content,
+	})
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ImportResponse{
+		Success: true,
+		Message: "File uploaded successfully",
+		Import:  job,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetImportsHandler handles retrieving a farm's imports
+func (app *Config) GetImportsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := app.Services.Import.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ImportResponse{
+		Success: true,
+		Message: "Imports retrieved successfully",
+		Imports: jobs,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetImportHandler handles retrieving an import with a sample of its rows
+func (app *Config) GetImportHandler(w http.ResponseWriter, r *http.Request) {
+	importID := resourceID(r)
+	if importID == "" {
+		app.errorJSON(w, errors.New("import ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Import.Get(r.Context(), user, importID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ImportResponse{
+		Success: true,
+		Message: "Import retrieved successfully",
+		Import:  job,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// SetImportMappingHandler handles replacing an import's column mapping
+func (app *Config) SetImportMappingHandler(w http.ResponseWriter, r *http.Request) {
+	var req ImportMappingRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	importID := resourceID(r)
+	if importID == "" {
+		app.errorJSON(w, errors.New("import ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Import.SetMapping(r.Context(), user, importID, importer.MappingInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ImportResponse{
+		Success: true,
+		Message: "Import mapping updated successfully",
+		Import:  job,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ValidateImportHandler handles checking every row of an import against its
+// mapping. Problems are listed on the import rather than failing the request.
+func (app *Config) ValidateImportHandler(w http.ResponseWriter, r *http.Request) {
+	importID := resourceID(r)
+	if importID == "" {
+		app.errorJSON(w, errors.New("import ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Import.Validate(r.Context(), user, importID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	message := "Import validated successfully"
+	if job.ErrorCount > 0 {
+		message = "Import validated with errors"
+	}
+	response := ImportResponse{
+		Success: true,
+		Message: message,
+		Import:  job,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CommitImportHandler handles adding a validated import's rows to the farm
+func (app *Config) CommitImportHandler(w http.ResponseWriter, r *http.Request) {
+	var req ImportCommitRequest
+
+	if r.ContentLength != 0 {
+		if err := app.ReadJSON(w, r, &req); err != nil {
+			app.errorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	importID := resourceID(r)
+	if importID == "" {
+		app.errorJSON(w, errors.New("import ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Import.Commit(r.Context(), user, importID, req.SkipInvalid)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ImportResponse{
+		Success: true,
+		Message: "Import committed successfully",
+		Import:  job,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteImportHandler handles deleting an import and its uploaded file.
+// Records already committed are kept.
+func (app *Config) DeleteImportHandler(w http.ResponseWriter, r *http.Request) {
+	importID := resourceID(r)
+	if importID == "" {
+		app.errorJSON(w, errors.New("import ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Import.Delete(r.Context(), user, importID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ImportResponse{
+		Success: true,
+		Message: "Import deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/inventory"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// InventoryItemRequest represents the inventory item creation/update request body
+type InventoryItemRequest struct {
+	Name            string   `json:"name"`
+	Category        string   `json:"category"`
+	Unit            string   `json:"unit"`
+	NitrogenPercent float64  `json:"nitrogenPercent"`
+	ReorderLevel    *float64 `json:"reorderLevel"`
+	Notes           string   `json:"notes"`
+}
+
+// InventoryBatchRequest represents the batch receipt request body
+type InventoryBatchRequest struct {
+	BatchNumber  string     `json:"batchNumber"`
+	Quantity     float64    `json:"quantity"`
+	UnitCost     float64    `json:"unitCost"`
+	ReceivedDate *time.Time `json:"receivedDate"`
+	ExpiryDate   *time.Time `json:"expiryDate"`
+	Notes        string     `json:"notes"`
+}
+
+// ConsumeInventoryRequest represents the stock consumption request body
+type ConsumeInventoryRequest struct {
+	Quantity         float64    `json:"quantity"`
+	InventoryBatchID string     `json:"inventoryBatchId"` // Optional; FEFO allocation is used when empty
+	Date             *time.Time `json:"date"`
+	Purpose          string     `json:"purpose"`
+	Notes            string     `json:"notes"`
+}
+
+// InventoryResponse represents the inventory response
+type InventoryResponse struct {
+	Success    bool                      `json:"success"`
+	Message    string                    `json:"message"`
+	Item       *data.InventoryItem       `json:"item,omitempty"`
+	Items      []*data.InventoryItem     `json:"items,omitempty"`
+	Batch      *data.InventoryBatch      `json:"batch,omitempty"`
+	Batches    []*data.InventoryBatch    `json:"batches,omitempty"`
+	Movements  []*data.InventoryMovement `json:"movements,omitempty"`
+	Suggestion []data.BatchAllocation    `json:"suggestion,omitempty"`
+	Warning    string                    `json:"warning,omitempty"`
+}
+
+// Validate checks the inventory item request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *InventoryItemRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("unit", req.Unit)
+	}
+	v.OneOf("category", req.Category, "Feed", "Seed", "Fertilizer", "Drug", "Fuel", "Other")
+	v.Check(req.NitrogenPercent >= 0 && req.NitrogenPercent <= 100, "nitrogenPercent", "must be between 0 and 100")
+	v.Check(req.ReorderLevel == nil || *req.ReorderLevel >= 0, "reorderLevel", "must be >= 0")
+	return v.Errors()
+}
+
+// Validate checks the batch receipt request fields
+func (req *InventoryBatchRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Quantity > 0, "quantity", "must be greater than 0")
+	v.Check(req.UnitCost >= 0, "unitCost", "must be >= 0")
+	if req.ReceivedDate != nil && req.ExpiryDate != nil {
+		v.Check(req.ExpiryDate.After(*req.ReceivedDate), "expiryDate", "must be after receivedDate")
+	}
+	return v.Errors()
+}
+
+// Validate checks the consumption request fields
+func (req *ConsumeInventoryRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Quantity > 0, "quantity", "must be greater than 0")
+	return v.Errors()
+}
+
+// CreateInventoryItemHandler handles inventory item creation
+func (app *Config) CreateInventoryItemHandler(w http.ResponseWriter, r *http.Request) {
+	var req InventoryItemRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := app.Services.Inventory.Create(r.Context(), user, farmID, inventory.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory item created successfully",
+		Item:    item,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetInventoryItemsHandler handles retrieving all inventory items for a farm
+func (app *Config) GetInventoryItemsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := app.Services.Inventory.List(r.Context(), user, farmID, r.URL.Query().Get("category"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory items retrieved successfully",
+		Items:   items,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetInventoryItemHandler handles retrieving a single inventory item with its batches
+func (app *Config) GetInventoryItemHandler(w http.ResponseWriter, r *http.Request) {
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	item, batches, err := app.Services.Inventory.Batches(r.Context(), user, itemID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory item retrieved successfully",
+		Item:    item,
+		Batches: batches,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateInventoryItemHandler handles inventory item updates
+func (app *Config) UpdateInventoryItemHandler(w http.ResponseWriter, r *http.Request) {
+	var req InventoryItemRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := app.Services.Inventory.Update(r.Context(), user, itemID, inventory.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory item updated successfully",
+		Item:    item,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteInventoryItemHandler handles inventory item deletion
+func (app *Config) DeleteInventoryItemHandler(w http.ResponseWriter, r *http.Request) {
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Inventory.Delete(r.Context(), user, itemID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory item deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ReceiveInventoryBatchHandler handles receiving a new batch of an item into stock
+func (app *Config) ReceiveInventoryBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req InventoryBatchRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	batch, err := app.Services.Inventory.Receive(r.Context(), user, itemID, inventory.BatchInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory batch received successfully",
+		Batch:   batch,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetInventoryBatchesHandler handles retrieving the batches of an item in FEFO order
+func (app *Config) GetInventoryBatchesHandler(w http.ResponseWriter, r *http.Request) {
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	_, batches, err := app.Services.Inventory.Batches(r.Context(), user, itemID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Inventory batches retrieved successfully",
+		Batches: batches,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetFEFOSuggestionHandler suggests which batches to draw a quantity from,
+// first-expired-first-out
+func (app *Config) GetFEFOSuggestionHandler(w http.ResponseWriter, r *http.Request) {
+	quantity, err := strconv.ParseFloat(r.URL.Query().Get("quantity"), 64)
+	if err != nil || quantity <= 0 {
+		app.errorJSON(w, errors.New("quantity must be a number greater than 0"), http.StatusBadRequest)
+		return
+	}
+
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	item, suggestion, shortfall, err := app.Services.Inventory.Suggest(r.Context(), user, itemID, quantity)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success:    true,
+		Message:    "FEFO suggestion generated successfully",
+		Item:       item,
+		Suggestion: suggestion,
+	}
+	if shortfall > 0 {
+		response.Warning = fmt.Sprintf("unexpired stock is short by %.2f %s", shortfall, item.Unit)
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ConsumeInventoryHandler takes stock out of inventory. Without a batch the
+// quantity is drawn first-expired-first-out; when a batch is chosen that
+// differs from the FEFO suggestion the response carries a warning.
+func (app *Config) ConsumeInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	var req ConsumeInventoryRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	itemID := resourceID(r)
+	if itemID == "" {
+		app.errorJSON(w, errors.New("inventory item ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	consumption, err := app.Services.Inventory.Consume(r.Context(), user, itemID, inventory.ConsumeInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success:    true,
+		Message:    "Inventory consumed successfully",
+		Movements:  consumption.Movements,
+		Suggestion: consumption.Suggestion,
+		Warning:    consumption.Warning,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetExpiringInventoryHandler lists batches that expire within the given
+// number of days (default 30), including those already expired
+func (app *Config) GetExpiringInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	days := expiryWarningDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 {
+			app.errorJSON(w, errors.New("days must be a non-negative number"), http.StatusBadRequest)
+			return
+		}
+		days = d
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	batches, err := app.Services.Inventory.Expiring(r.Context(), user, farmID, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Expiring inventory retrieved successfully",
+		Batches: batches,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetLowStockInventoryHandler lists a farm's items whose stock has fallen
+// below their reorder level
+func (app *Config) GetLowStockInventoryHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	items, err := app.Services.Inventory.LowStock(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := InventoryResponse{
+		Success: true,
+		Message: "Low-stock inventory retrieved successfully",
+		Items:   items,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/irrigation"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultIrrigationWindowDays is how far ahead the upcoming-irrigation view looks by default
+const defaultIrrigationWindowDays = 7
+
+// IrrigationRequest represents the irrigation schedule creation/update request body
+type IrrigationRequest struct {
+	FieldID         *string    `json:"fieldId"`       // Empty string clears
+	CropID          *string    `json:"cropId"`        // Empty string clears
+	WaterSourceID   *string    `json:"waterSourceId"` // Empty string clears
+	Method          string     `json:"method"`
+	StartDate       *time.Time `json:"startDate"`
+	EndDate         *time.Time `json:"endDate"`
+	IntervalDays    int        `json:"intervalDays"`
+	StartTime       string     `json:"startTime"`
+	DurationMinutes int        `json:"durationMinutes"`
+	Volume          float64    `json:"volume"`
+	Status          string     `json:"status"`
+	Notes           string     `json:"notes"`
+}
+
+// IrrigationResponse represents the irrigation schedule response
+type IrrigationResponse struct {
+	Success   bool                       `json:"success"`
+	Message   string                     `json:"message"`
+	Schedule  *data.IrrigationSchedule   `json:"schedule,omitempty"`
+	Schedules []*data.IrrigationSchedule `json:"schedules,omitempty"`
+	Upcoming  *irrigation.Upcoming       `json:"upcoming,omitempty"`
+}
+
+// Validate checks the irrigation request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *IrrigationRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("method", req.Method)
+		v.Check(req.StartDate != nil, "startDate", "is required")
+		v.Check(req.DurationMinutes > 0, "durationMinutes", "must be greater than 0")
+	}
+	v.OneOf("method", req.Method, "Drip", "Sprinkler", "Furrow", "Flood", "Centre Pivot", "Manual")
+	if req.StartDate != nil && req.EndDate != nil {
+		v.Check(!req.EndDate.Before(*req.StartDate), "endDate", "must not be before startDate")
+	}
+	v.Check(req.IntervalDays >= 0 && req.IntervalDays <= 365, "intervalDays", "must be between 0 and 365")
+	if req.StartTime != "" {
+		_, err := time.Parse("15:04", req.StartTime)
+		v.Check(err == nil, "startTime", "must be a time of day as HH:MM")
+	}
+	v.Check(req.DurationMinutes >= 0, "durationMinutes", "must be 0 or more")
+	v.Check(req.Volume >= 0, "volume", "must be >= 0")
+	v.OneOf("status", req.Status, "Active", "Paused", "Completed")
+	return v.Errors()
+}
+
+// CreateIrrigationHandler handles adding an irrigation schedule to a farm
+func (app *Config) CreateIrrigationHandler(w http.ResponseWriter, r *http.Request) {
+	var req IrrigationRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := app.Services.Irrigation.Create(r.Context(), user, farmID, irrigation.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := IrrigationResponse{
+		Success:  true,
+		Message:  "Irrigation schedule created successfully",
+		Schedule: schedule,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetIrrigationsHandler handles retrieving all irrigation schedules for a farm
+func (app *Config) GetIrrigationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	schedules, err := app.Services.Irrigation.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := IrrigationResponse{
+		Success:   true,
+		Message:   "Irrigation schedules retrieved successfully",
+		Schedules: schedules,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetIrrigationHandler handles retrieving a single irrigation schedule by ID
+func (app *Config) GetIrrigationHandler(w http.ResponseWriter, r *http.Request) {
+	irrigationID := resourceID(r)
+	if irrigationID == "" {
+		app.errorJSON(w, errors.New("irrigation ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := app.Services.Irrigation.Get(r.Context(), user, irrigationID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := IrrigationResponse{
+		Success:  true,
+		Message:  "Irrigation schedule retrieved successfully",
+		Schedule: schedule,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateIrrigationHandler handles irrigation schedule updates
+func (app *Config) UpdateIrrigationHandler(w http.ResponseWriter, r *http.Request) {
+	var req IrrigationRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	irrigationID := resourceID(r)
+	if irrigationID == "" {
+		app.errorJSON(w, errors.New("irrigation ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	schedule, err := app.Services.Irrigation.Update(r.Context(), user, irrigationID, irrigation.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := IrrigationResponse{
+		Success:  true,
+		Message:  "Irrigation schedule updated successfully",
+		Schedule: schedule,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteIrrigationHandler handles irrigation schedule deletion
+func (app *Config) DeleteIrrigationHandler(w http.ResponseWriter, r *http.Request) {
+	irrigationID := resourceID(r)
+	if irrigationID == "" {
+		app.errorJSON(w, errors.New("irrigation ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Irrigation.Delete(r.Context(), user, irrigationID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := IrrigationResponse{
+		Success: true,
+		Message: "Irrigation schedule deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetUpcomingIrrigationHandler lists a farm's irrigations over the next
+// ?days= days (default 7), suggesting skips where rain is forecast
+func (app *Config) GetUpcomingIrrigationHandler(w http.ResponseWriter, r *http.Request) {
+	days := defaultIrrigationWindowDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 16 {
+			app.errorJSON(w, errors.New("days must be between 1 and 16"), http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	upcoming, err := app.Services.Irrigation.Upcoming(r.Context(), user, farmID, days)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := IrrigationResponse{
+		Success:  true,
+		Message:  "Upcoming irrigation retrieved successfully",
+		Upcoming: upcoming,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
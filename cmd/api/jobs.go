@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"farm4u/pricefeed"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// expiryCheckInterval is how often inventory batches are scanned for expiry
+	expiryCheckInterval = 6 * time.Hour
+	// expiryWarningDays is how far ahead of expiry a batch is reported
+	expiryWarningDays = 30
+	// documentCheckInterval is how often documents are scanned for expiry
+	documentCheckInterval = 12 * time.Hour
+	// lowStockCheckInterval is how often inventory is checked against reorder levels
+	lowStockCheckInterval = time.Hour
+	// notifierCheckInterval is how often notification providers are health checked
+	notifierCheckInterval = time.Minute
+	// escrowReleaseInterval is how often held escrows are checked for automatic release
+	escrowReleaseInterval = 15 * time.Minute
+	// marketPriceInterval is how often the market price feed is fetched
+	marketPriceInterval = 6 * time.Hour
+	// reportQueueInterval is how often reports left queued are picked up
+	reportQueueInterval = time.Minute
+	// credentialPurgeInterval is how often expired tokens are dropped from
+	// the denylist, and expired phone logins with them
+	credentialPurgeInterval = 6 * time.Hour
+	// webhookDeliveryInterval is how often due webhook deliveries are posted
+	webhookDeliveryInterval = 15 * time.Second
+	// reportTimeout bounds how long a report may take to render before it is
+	// taken as abandoned
+	reportTimeout = 15 * time.Minute
+	// exportQueueInterval is how often account exports left queued are
+	// picked up
+	exportQueueInterval = time.Minute
+	// exportTimeout bounds how long an account export may take to bundle
+	// before it is taken as abandoned
+	exportTimeout = time.Hour
+)
+
+// background runs fn in a goroutine tracked by app.Wait so shutdown can wait
+// for it to finish
+func (app *Config) background(fn func()) {
+	app.Wait.Add(1)
+	go func() {
+		defer app.Wait.Done()
+		fn()
+	}()
+}
+
+// watchInventoryExpiry periodically notifies farm owners about inventory
+// batches that are about to expire or have expired with stock remaining. It
+// returns when app.Done is closed.
+func (app *Config) watchInventoryExpiry() {
+	ctx := context.Background()
+	app.notifyExpiringInventory(ctx)
+
+	ticker := time.NewTicker(expiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			app.notifyExpiringInventory(ctx)
+		}
+	}
+}
+
+// notifyExpiringInventory runs a single expiry scan across all farms
+func (app *Config) notifyExpiringInventory(ctx context.Context) {
+	farms, err := app.Models.Farm.GetAll(ctx)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting farms for expiry check: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, farm := range farms {
+		batches, err := app.Models.InventoryBatch.GetExpiring(ctx, farm.FarmID, now.AddDate(0, 0, expiryWarningDays))
+		if err != nil {
+			app.ErrorLog.Printf("Error getting expiring inventory for farm %s: %v", farm.FarmID, err)
+			continue
+		}
+
+		for _, batch := range batches {
+			name := "Inventory"
+			unit := ""
+			if batch.InventoryItem != nil {
+				name = batch.InventoryItem.Name
+				unit = batch.InventoryItem.Unit
+			}
+
+			kind, title := "inventory_expiring", fmt.Sprintf("%s expiring soon", name)
+			message := fmt.Sprintf("Batch %s of %s (%.2f %s left) expires on %s at %s",
+				batch.BatchNumber, name, batch.Quantity, unit, batch.ExpiryDate.Format("2006-01-02"), farm.Name)
+			if batch.IsExpired(now) {
+				kind, title = "inventory_expired", fmt.Sprintf("%s has expired", name)
+				message = fmt.Sprintf("Batch %s of %s (%.2f %s left) expired on %s at %s",
+					batch.BatchNumber, name, batch.Quantity, unit, batch.ExpiryDate.Format("2006-01-02"), farm.Name)
+			}
+
+			reference := fmt.Sprintf("%s:%s", kind, batch.InventoryBatchID)
+			if err := app.notify(ctx, farm.UserID, &farm.FarmID, kind, title, message, reference); err != nil {
+				app.ErrorLog.Printf("Error creating expiry notification: %v", err)
+			}
+		}
+	}
+}
+
+// watchDocumentExpiry periodically notifies farm owners about documents
+// whose reminder window has opened or that have expired. It returns when
+// app.Done is closed.
+func (app *Config) watchDocumentExpiry() {
+	ctx := context.Background()
+	app.notifyExpiringDocuments(ctx)
+
+	ticker := time.NewTicker(documentCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			app.notifyExpiringDocuments(ctx)
+		}
+	}
+}
+
+// notifyExpiringDocuments runs a single document expiry scan across all
+// farms. Reminders are keyed by expiry date, so a renewed document is
+// reminded about again before its new date.
+func (app *Config) notifyExpiringDocuments(ctx context.Context) {
+	farms, err := app.Models.Farm.GetAll(ctx)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting farms for document expiry check: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, farm := range farms {
+		documents, err := app.Models.Document.GetExpiring(ctx, farm.FarmID, now)
+		if err != nil {
+			app.ErrorLog.Printf("Error getting expiring documents for farm %s: %v", farm.FarmID, err)
+			continue
+		}
+
+		for _, document := range documents {
+			expires := document.ExpiresAt.Format("2006-01-02")
+			paper := document.Type + " " + document.Title
+			if document.Employee != nil {
+				paper += fmt.Sprintf(" for %s %s", document.Employee.FirstName, document.Employee.LastName)
+			}
+			kind, title := "document_expiring", fmt.Sprintf("%s expiring soon", document.Title)
+			message := fmt.Sprintf("%s at %s expires on %s", paper, farm.Name, expires)
+			if document.IsExpired(now) {
+				kind, title = "document_expired", fmt.Sprintf("%s has expired", document.Title)
+				message = fmt.Sprintf("%s at %s expired on %s", paper, farm.Name, expires)
+			}
+
+			reference := fmt.Sprintf("%s:%s:%s", kind, document.DocumentID, expires)
+			if err := app.notify(ctx, farm.UserID, &farm.FarmID, kind, title, message, reference); err != nil {
+				app.ErrorLog.Printf("Error creating document expiry notification: %v", err)
+			}
+		}
+	}
+}
+
+// watchLowStock periodically notifies farm owners about inventory items
+// whose stock has fallen below their reorder level. It returns when app.Done
+// is closed.
+func (app *Config) watchLowStock() {
+	ctx := context.Background()
+	app.notifyLowStock(ctx)
+
+	ticker := time.NewTicker(lowStockCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			app.notifyLowStock(ctx)
+		}
+	}
+}
+
+// notifyLowStock runs a single low-stock scan across all farms. An item is
+// reported once until it is restocked, so a later shortage is reported again.
+func (app *Config) notifyLowStock(ctx context.Context) {
+	farms, err := app.Models.Farm.GetAll(ctx)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting farms for low-stock check: %v", err)
+		return
+	}
+
+	for _, farm := range farms {
+		items, err := app.Models.InventoryItem.GetLowStock(ctx, farm.FarmID)
+		if err != nil {
+			app.ErrorLog.Printf("Error getting low-stock inventory for farm %s: %v", farm.FarmID, err)
+			continue
+		}
+
+		for _, item := range items {
+			restocked := "never"
+			if item.RestockedAt != nil {
+				restocked = strconv.FormatInt(item.RestockedAt.Unix(), 10)
+			}
+
+			title := fmt.Sprintf("%s is running low", item.Name)
+			message := fmt.Sprintf("%s at %s is down to %.2f %s, below the reorder level of %.2f %s",
+				item.Name, farm.Name, item.QuantityOnHand, item.Unit, item.ReorderLevel, item.Unit)
+			reference := fmt.Sprintf("inventory_low_stock:%s:%s", item.InventoryItemID, restocked)
+			if err := app.notify(ctx, farm.UserID, &farm.FarmID, "inventory_low_stock", title, message, reference); err != nil {
+				app.ErrorLog.Printf("Error creating low-stock notification: %v", err)
+			}
+		}
+	}
+}
+
+// releaseDueEscrows periodically releases held escrow payments whose release
+// time has passed. It returns when app.Done is closed.
+func (app *Config) releaseDueEscrows() {
+	ctx := context.Background()
+	release := func() {
+		n, err := app.Services.Escrow.ReleaseDue(ctx, time.Now())
+		if err != nil {
+			app.ErrorLog.Printf("Error releasing due escrows: %v", err)
+		}
+		if n > 0 {
+			app.InfoLog.Printf("Released %d escrow payments", n)
+		}
+	}
+	release()
+
+	ticker := time.NewTicker(escrowReleaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			release()
+		}
+	}
+}
+
+// generateReport renders a queued PDF report
+func (app *Config) generateReport(reportJobID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), reportTimeout)
+	defer cancel()
+	if err := app.Services.Report.Generate(ctx, reportJobID); err != nil {
+		app.ErrorLog.Printf("Error generating report: %v", err)
+	}
+}
+
+// generateQueuedReports periodically renders reports that are still queued,
+// such as those requested just before a restart, and fails reports whose
+// rendering was cut short. It returns when app.Done is closed.
+func (app *Config) generateQueuedReports() {
+	generate := func() {
+		now := time.Now()
+		n, err := app.Services.Report.GenerateQueued(context.Background(), now.Add(-reportQueueInterval), now.Add(-reportTimeout))
+		if err != nil {
+			app.ErrorLog.Printf("Error generating queued reports: %v", err)
+		}
+		if n > 0 {
+			app.InfoLog.Printf("Generated %d queued reports", n)
+		}
+	}
+	generate()
+
+	ticker := time.NewTicker(reportQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			generate()
+		}
+	}
+}
+
+// generateExport bundles a queued account export
+func (app *Config) generateExport(exportJobID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	if err := app.Services.Export.Generate(ctx, exportJobID); err != nil {
+		app.ErrorLog.Printf("Error generating export: %v", err)
+	}
+}
+
+// generateQueuedExports periodically bundles account exports that are still
+// queued and fails those whose bundling was cut short. It returns when
+// app.Done is closed.
+func (app *Config) generateQueuedExports() {
+	generate := func() {
+		now := time.Now()
+		n, err := app.Services.Export.GenerateQueued(context.Background(), now.Add(-exportQueueInterval), now.Add(-exportTimeout))
+		if err != nil {
+			app.ErrorLog.Printf("Error generating queued exports: %v", err)
+		}
+		if n > 0 {
+			app.InfoLog.Printf("Generated %d queued exports", n)
+		}
+	}
+	generate()
+
+	ticker := time.NewTicker(exportQueueInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			generate()
+		}
+	}
+}
+
+// refreshMarketPrices periodically saves the latest prices from the market
+// price feed. Feeds publish daily, so fetching a few times a day picks up a
+// new day's prices promptly. It returns when app.Done is closed.
+func (app *Config) refreshMarketPrices() {
+	if _, ok := app.PriceFeed.(pricefeed.None); ok {
+		return
+	}
+
+	refresh := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		n, err := app.Services.Market.Refresh(ctx)
+		if err != nil {
+			app.ErrorLog.Printf("Error refreshing market prices: %v", err)
+			return
+		}
+		app.InfoLog.Printf("Saved %d market prices from %s", n, app.PriceFeed.Name())
+	}
+	refresh()
+
+	ticker := time.NewTicker(marketPriceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
+
+// monitorNotifiers periodically health checks the notification providers so
+// a recovered provider is put back in rotation. It returns when app.Done is
+// closed.
+func (app *Config) monitorNotifiers() {
+	ticker := time.NewTicker(notifierCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), notifierCheckInterval/2)
+			app.Notifier.CheckHealth(ctx)
+			cancel()
+		}
+	}
+}
+
+// purgeExpiredCredentials periodically drops tokens that have expired from
+// the denylist, as they are refused anyway, and expired phone logins. It
+// returns when app.Done is closed.
+func (app *Config) purgeExpiredCredentials() {
+	ctx := context.Background()
+	ticker := time.NewTicker(credentialPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			n, err := app.Services.Auth.PurgeExpired(ctx)
+			if err != nil {
+				app.ErrorLog.Printf("Error purging expired credentials: %v", err)
+				continue
+			}
+			if n > 0 {
+				app.InfoLog.Printf("Purged %d expired revoked tokens and phone logins", n)
+			}
+		}
+	}
+}
+
+// deliverWebhooks periodically posts the webhook deliveries that are due,
+// first attempts and retries alike. It returns when app.Done is closed.
+func (app *Config) deliverWebhooks() {
+	ticker := time.NewTicker(webhookDeliveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			return
+		case <-ticker.C:
+			n, err := app.Services.Integration.DeliverDue(context.Background())
+			if err != nil {
+				app.ErrorLog.Printf("Error delivering webhooks: %v", err)
+			}
+			if n > 0 {
+				app.InfoLog.Printf("Delivered %d webhook events", n)
+			}
+		}
+	}
+}
@@ -1,16 +1,20 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"farm4u/data"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// impersonationTTL is how long an admin's support session token lasts
+const impersonationTTL = 30 * time.Minute
+
 // JWT Claims structure
 type Claims struct {
 	UserID    int    `json:"user_id"`
@@ -18,32 +22,33 @@ type Claims struct {
 	Role      string `json:"role"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+	// ImpersonatorID is the admin acting as this user, for support sessions
+	ImpersonatorID int `json:"impersonator_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // GenerateJWT creates a JWT token for the user
 func (app *Config) GenerateJWT(user *data.User) (string, error) {
-	// Get JWT secret from environment variable, fallback to default
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-super-secret-jwt-key" // Change this in production!
-	}
+	return app.signJWT(user, app.Settings.JWTExpiration, 0)
+}
 
-	// Get expiration time from environment variable, fallback to 24 hours
-	expirationHours := 24
-	if envExp := os.Getenv("JWT_EXPIRATION_HOURS"); envExp != "" {
-		if hours, err := strconv.Atoi(envExp); err == nil {
-			expirationHours = hours
-		}
-	}
+// GenerateImpersonationJWT creates a short-lived token that acts as user on
+// behalf of the admin with ID impersonatorID
+func (app *Config) GenerateImpersonationJWT(user *data.User, impersonatorID int) (string, error) {
+	return app.signJWT(user, impersonationTTL, impersonatorID)
+}
 
+// signJWT creates and signs a token for the user valid for ttl
+func (app *Config) signJWT(user *data.User, ttl time.Duration, impersonatorID int) (string, error) {
 	// Create claims
 	claims := Claims{
-		UserID: int(user.ID),
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:         int(user.ID),
+		Email:          user.Email,
+		Role:           user.Role,
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expirationHours))),
+			ID:        newTokenID(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "farm4u",
@@ -51,11 +56,8 @@ func (app *Config) GenerateJWT(user *data.User) (string, error) {
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	// Sign token with the current key
+	tokenString, err := app.JWTKeys.signedString(claims)
 	if err != nil {
 		return "", err
 	}
@@ -63,20 +65,17 @@ func (app *Config) GenerateJWT(user *data.User) (string, error) {
 	return tokenString, nil
 }
 
+// newTokenID generates a random 16-byte hex token ID (jti), by which the
+// token can be revoked
+func newTokenID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 // ValidateJWT validates a JWT token and returns the claims
 func (app *Config) ValidateJWT(tokenString string) (*Claims, error) {
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-super-secret-jwt-key"
-	}
-
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
-		}
-		return []byte(jwtSecret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, app.JWTKeys.keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -94,6 +93,11 @@ func (app *Config) JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get token from Authorization header
 		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" && r.Header.Get(apiKeyHeader) != "" {
+			// Partners read with an API key instead
+			app.APIKeyMiddleware(next)(w, r)
+			return
+		}
 		if authHeader == "" {
 			app.errorJSON(w, errors.New("authorization header required"), http.StatusUnauthorized)
 			return
@@ -115,12 +119,27 @@ func (app *Config) JWTMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Refuse tokens revoked before they expired, as on logout
+		revoked, err := app.Services.Auth.Revoked(r.Context(), claims.ID)
+		if err != nil {
+			app.ErrorLog.Printf("Error checking token revocation: %v", err)
+			app.errorJSON(w, errors.New("failed to verify token"), http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			app.errorJSON(w, errors.New("token has been revoked"), http.StatusUnauthorized)
+			return
+		}
+
 		// Add claims to request context for use in handlers
 		r = r.WithContext(r.Context())
 		r.Header.Set("X-User-ID", strconv.Itoa(claims.UserID))
 		r.Header.Set("X-User-Email", claims.Email)
 		r.Header.Set("X-User-Role", claims.Role)
+		if claims.ImpersonatorID != 0 {
+			r.Header.Set("X-Impersonator-ID", strconv.Itoa(claims.ImpersonatorID))
+		}
 
-		next.ServeHTTP(w, r)
+		app.FarmAccess(next)(w, r)
 	}
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtKey is a key that tokens are verified with and, for the current key,
+// signed with
+type jwtKey struct {
+	id     string
+	method jwt.SigningMethod
+	sign   any // []byte or *rsa.PrivateKey; nil for keys only accepted
+	verify any // []byte or *rsa.PublicKey
+}
+
+// jwtKeys holds the key new tokens are signed with and every key tokens are
+// still accepted with. Tokens name their key in the kid header, so a secret
+// can be rotated without logging every user out: the new key signs, and the
+// old one stays accepted until the tokens it signed have expired.
+type jwtKeys struct {
+	signing *jwtKey
+	byID    map[string]*jwtKey
+	// legacy verifies tokens without a kid, issued before keys had IDs
+	legacy *jwtKey
+}
+
+// loadJWTKeys builds the keys from the configuration, reading the RSA key
+// files if any
+func loadJWTKeys(cfg *AppConfig) (*jwtKeys, error) {
+	keys := &jwtKeys{byID: map[string]*jwtKey{}}
+	add := func(key *jwtKey) error {
+		if _, ok := keys.byID[key.id]; ok {
+			return fmt.Errorf("JWT key ID %q is used twice", key.id)
+		}
+		keys.byID[key.id] = key
+		return nil
+	}
+
+	if cfg.JWTSecret != "" {
+		keys.legacy = &jwtKey{id: cfg.JWTKeyID, method: jwt.SigningMethodHS256, verify: []byte(cfg.JWTSecret)}
+	}
+
+	if cfg.JWTPrivateKeyFile != "" {
+		pem, err := os.ReadFile(cfg.JWTPrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parsing JWT_PRIVATE_KEY_FILE: %w", err)
+		}
+		keys.signing = &jwtKey{id: cfg.JWTKeyID, method: jwt.SigningMethodRS256, sign: private, verify: &private.PublicKey}
+	} else {
+		keys.signing = &jwtKey{id: cfg.JWTKeyID, method: jwt.SigningMethodHS256, sign: []byte(cfg.JWTSecret), verify: []byte(cfg.JWTSecret)}
+	}
+	if err := add(keys.signing); err != nil {
+		return nil, err
+	}
+
+	for id, secret := range cfg.JWTPreviousSecrets {
+		if err := add(&jwtKey{id: id, method: jwt.SigningMethodHS256, verify: []byte(secret)}); err != nil {
+			return nil, err
+		}
+	}
+	for id, path := range cfg.JWTPublicKeyFiles {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT public key %q: %w", id, err)
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parsing JWT public key %q: %w", id, err)
+		}
+		if err := add(&jwtKey{id: id, method: jwt.SigningMethodRS256, verify: public}); err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// signedString signs claims with the signing key, naming it in the kid header
+func (k *jwtKeys) signedString(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	token.Header["kid"] = k.signing.id
+	return token.SignedString(k.signing.sign)
+}
+
+// keyFunc returns the key to verify token with, found by its kid header. The
+// token must use the key's own algorithm, so an RSA public key can never be
+// passed off as an HMAC secret.
+func (k *jwtKeys) keyFunc(token *jwt.Token) (any, error) {
+	key := k.legacy
+	if kid, ok := token.Header["kid"]; ok {
+		id, _ := kid.(string)
+		key = k.byID[id]
+	}
+	if key == nil {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("invalid signing method")
+	}
+	return key.verify, nil
+}
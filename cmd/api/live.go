@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"farm4u/data"
+	"farm4u/live"
+	"farm4u/service/activity"
+	"farm4u/service/farm"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// liveKeepAlive is how often an idle event stream gets a comment line, so
+// proxies and load balancers do not close it
+const liveKeepAlive = 25 * time.Second
+
+// StreamEventsHandler handles the authenticated user's live event stream, as
+// server-sent events. Each event is named by its type, such as crop.updated
+// or notification, and carries a live.Event as JSON:
+//
+//	event: crop.updated
+//	data: {"type":"crop.updated","farmId":"...","at":"...","data":{...}}
+//
+// Clients get the changes made to every farm they may read, by anyone, and
+// their new notifications, for as long as the connection stays open.
+func (app *Config) StreamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream outlives any write timeout the server sets for requests
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	sub := app.Live.Subscribe(user.UserID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Stops nginx buffering the stream
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if err := rc.Flush(); err != nil {
+		app.ErrorLog.Printf("Error starting event stream: %v", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(liveKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-sub.Events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				app.ErrorLog.Printf("Error encoding live event %s: %v", event.Type, err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, payload)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// farmEvents publishes the changes made to a farm's records to next, the
+// farm's webhooks, and pushes them live to the farm's owner and to the
+// members whose role may read them
+type farmEvents struct {
+	next    activity.Publisher
+	hub     *live.Hub
+	farms   data.FarmInterface
+	members data.FarmMemberInterface
+}
+
+// Publish implements activity.Publisher. Pushing is best-effort: a client
+// that misses an event still sees the change on its next fetch.
+func (p *farmEvents) Publish(ctx context.Context, farmID, event string, record any) error {
+	err := p.next.Publish(ctx, farmID, event, record)
+
+	owner, lookupErr := p.farms.GetByFarmID(ctx, farmID)
+	if lookupErr != nil || owner == nil {
+		return err
+	}
+	message := live.Event{Type: event, FarmID: farmID, Data: record}
+	p.hub.Send(owner.UserID, message)
+
+	module := eventModule(event)
+	if module == "" {
+		return err
+	}
+	members, lookupErr := p.members.GetByFarmID(ctx, farmID)
+	if lookupErr != nil {
+		return err
+	}
+	for _, member := range members {
+		if farm.Allowed(member.Role, module, farm.Read) {
+			p.hub.Send(member.UserID, message)
+		}
+	}
+	return err
+}
+
+// eventModule returns the permissions module covering the records an event
+// is about, or "" for records only the farm's owner may read
+func eventModule(event string) farm.Module {
+	if strings.HasPrefix(event, "transaction.") || event == "sale.recorded" {
+		return farm.ModuleFinance
+	}
+	return ""
+}
+
+// liveNotifications pushes each notification stored through it to its
+// recipient's event stream
+type liveNotifications struct {
+	data.NotificationInterface
+	hub *live.Hub
+	// afterCommit holds a push back until the notification's transaction,
+	// if any, has committed
+	afterCommit func(func())
+}
+
+// withLiveNotifications makes models push new notifications to hub, including
+// those stored in a transaction once it commits
+func withLiveNotifications(models data.Models, hub *live.Hub) data.Models {
+	return models.Wrap(func(m data.Models) data.Models {
+		m.Notification = liveNotifications{NotificationInterface: m.Notification, hub: hub, afterCommit: m.AfterCommit}
+		return m
+	})
+}
+
+// Insert stores a notification, then pushes it
+func (n liveNotifications) Insert(ctx context.Context, notification *data.Notification) error {
+	if err := n.NotificationInterface.Insert(ctx, notification); err != nil {
+		return err
+	}
+	event := live.Event{Type: "notification", Data: notification}
+	if notification.FarmID != nil {
+		event.FarmID = *notification.FarmID
+	}
+	n.afterCommit(func() { n.hub.Send(notification.UserID, event) })
+	return nil
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/livestock"
 	"net/http"
 	"time"
 )
@@ -14,6 +16,7 @@ type LivestockRequest struct {
 	AcquisitionDate *time.Time `json:"acquisitionDate"`
 	HealthStatus    string     `json:"healthStatus"`
 	Notes           string     `json:"notes"`
+	Version         int        `json:"version"` // Required on update: the version being changed; a stale one gets 409 with the current record
 }
 
 // LivestockResponse represents the livestock response
@@ -22,6 +25,31 @@ type LivestockResponse struct {
 	Message    string            `json:"message"`
 	Livestock  *data.Livestock   `json:"livestock,omitempty"`
 	Livestocks []*data.Livestock `json:"livestocks,omitempty"`
+	Stats      *livestock.Stats  `json:"stats,omitempty"`
+}
+
+// Validate checks the livestock request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *LivestockRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("type", req.Type)
+		v.Check(req.Count > 0, "count", "must be greater than 0")
+	}
+	v.Check(req.Count >= 0, "count", "must be 0 or more")
+	v.OneOf("healthStatus", req.HealthStatus, "Healthy", "Sick", "Under Treatment", "Deceased")
+	return v.Errors()
+}
+
+// ValidatePatch checks a livestock PATCH body, whose set fields may be
+// cleared except for those a livestock record cannot do without
+func (req *LivestockRequest) ValidatePatch(set service.Fields) ValidationErrors {
+	v := newValidator()
+	v.Check(!set["type"] || req.Type != "", "type", "cannot be cleared")
+	v.Check(!set["count"] || req.Count > 0, "count", "must be greater than 0")
+	v.Check(!set["healthStatus"] || req.HealthStatus != "", "healthStatus", "cannot be cleared")
+	v.Merge(req.Validate(true))
+	return v.Errors()
 }
 
 // CreateLivestockHandler handles livestock creation
@@ -33,201 +61,163 @@ func (app *Config) CreateLivestockHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Validate required fields
-	if req.Type == "" || req.Count <= 0 {
-		app.errorJSON(w, errors.New("type and count are required"), http.StatusBadRequest)
-		return
-	}
-
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("farmId")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	l, err := app.Services.Livestock.Create(r.Context(), user, farmID, livestock.Input(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
-	}
-
-	// Verify farm exists and belongs to user
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
+	response := LivestockResponse{
+		Success:   true,
+		Message:   "Livestock created successfully",
+		Livestock: l,
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("farm not found or access denied"), http.StatusForbidden)
-		return
-	}
+	app.writeJSON(w, http.StatusCreated, response)
+}
 
-	// Set default health status if not provided
-	if req.HealthStatus == "" {
-		req.HealthStatus = "Healthy"
-	}
+// CreateLivestockBatchHandler handles adding several livestock records to a
+// farm at once, as when a farm is first set up or an offline client syncs
+func (app *Config) CreateLivestockBatchHandler(w http.ResponseWriter, r *http.Request) {
+	batchCreate(app, w, r, "livestock records", func(req *LivestockRequest) ValidationErrors { return req.Validate(false) },
+		func(user *data.User, farmID string, reqs []LivestockRequest) ([]*data.Livestock, []error, error) {
+			ins := make([]livestock.Input, len(reqs))
+			for i, req := range reqs {
+				ins[i] = livestock.Input(req)
+			}
+			records, err := app.Services.Livestock.CreateBatch(r.Context(), user, farmID, ins)
+			return records, nil, err
+		})
+}
 
-	// Create new livestock
-	livestock := &data.Livestock{
-		FarmID:          farmID,
-		Type:            req.Type,
-		Count:           req.Count,
-		AcquisitionDate: req.AcquisitionDate,
-		HealthStatus:    req.HealthStatus,
-		Notes:           req.Notes,
-	}
+// DeleteLivestockBatchHandler handles deleting several of a farm's
+// livestock records at once
+func (app *Config) DeleteLivestockBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req BulkRequest
 
-	// Insert livestock
-	if err := app.Models.Livestock.Insert(livestock); err != nil {
-		app.ErrorLog.Printf("Error creating livestock: %v", err)
-		app.errorJSON(w, errors.New("failed to create livestock"), http.StatusInternalServerError)
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	response := LivestockResponse{
-		Success:   true,
-		Message:   "Livestock created successfully",
-		Livestock: livestock,
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
 	}
 
-	app.writeJSON(w, http.StatusCreated, response)
+	bulkApply(app, w, r, req.IDs, "livestock records", "deleted", func(user *data.User, farmID string) ([]*data.Livestock, []error, error) {
+		return app.Services.Livestock.DeleteBatch(r.Context(), user, farmID, req.IDs)
+	})
 }
 
 // GetLivestockHandler handles retrieving a single livestock by ID
 func (app *Config) GetLivestockHandler(w http.ResponseWriter, r *http.Request) {
-	// Get livestock ID from URL parameters
-	livestockID := r.URL.Query().Get("id")
+	livestockID := resourceID(r)
 	if livestockID == "" {
 		app.errorJSON(w, errors.New("livestock ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get livestock by ID
-	livestock, err := app.Models.Livestock.GetByLivestockID(livestockID)
+	l, err := app.Services.Livestock.Get(r.Context(), user, livestockID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting livestock: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if livestock == nil {
-		app.errorJSON(w, errors.New("livestock not found"), http.StatusNotFound)
-		return
+	response := LivestockResponse{
+		Success:   true,
+		Message:   "Livestock retrieved successfully",
+		Livestock: l,
 	}
 
-	// Verify that the livestock belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetLivestocksHandler handles retrieving all livestock for a farm,
+// optionally only those acquired ?from= ?to= and those with every ?tag=
+func (app *Config) GetLivestocksHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(livestock.FarmID)
+	livestocks, err := app.Services.Livestock.List(r.Context(), user, farmID, from, to, queryTags(r))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
-
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: livestock does not belong to user's farm"), http.StatusForbidden)
+		app.serviceError(w, err)
 		return
 	}
 
 	response := LivestockResponse{
-		Success:   true,
-		Message:   "Livestock retrieved successfully",
-		Livestock: livestock,
+		Success:    true,
+		Message:    "Livestock retrieved successfully",
+		Livestocks: livestocks,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
 }
 
-// GetLivestocksHandler handles retrieving all livestock for a farm
-func (app *Config) GetLivestocksHandler(w http.ResponseWriter, r *http.Request) {
-	// Get farm ID from URL parameters
-	farmID := r.URL.Query().Get("farmId")
-	if farmID == "" {
-		app.errorJSON(w, errors.New("farm ID is required"), http.StatusBadRequest)
+// GetAllLivestocksHandler handles retrieving the livestock of every farm the
+// user owns in one list, each with its farm
+func (app *Config) GetAllLivestocksHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
-		return
-	}
-
-	// Verify that the farm belongs to the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	livestocks, err := app.Services.Livestock.ListAll(r.Context(), user)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
+	response := LivestockResponse{
+		Success:    true,
+		Message:    "Livestock retrieved successfully",
+		Livestocks: livestocks,
 	}
 
-	// Verify farm exists and belongs to user
-	farm, err := app.Models.Farm.GetByFarmID(farmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
-		return
-	}
+	app.writeJSON(w, http.StatusOK, response)
+}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("farm not found or access denied"), http.StatusForbidden)
+// GetLivestockStatsHandler handles counting a farm's livestock per type and
+// health status (/api/livestock/stats?farmId=)
+func (app *Config) GetLivestockStatsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Get livestock by farm ID
-	livestocks, err := app.Models.Livestock.GetByFarmID(farmID)
+	stats, err := app.Services.Livestock.Stats(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting livestock: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
 	response := LivestockResponse{
-		Success:    true,
-		Message:    "Livestock retrieved successfully",
-		Livestocks: livestocks,
+		Success: true,
+		Message: "Livestock statistics retrieved successfully",
+		Stats:   stats,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -242,87 +232,74 @@ func (app *Config) UpdateLivestockHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Get livestock ID from URL parameters
-	livestockID := r.URL.Query().Get("id")
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	livestockID := resourceID(r)
 	if livestockID == "" {
 		app.errorJSON(w, errors.New("livestock ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get existing livestock
-	existingLivestock, err := app.Models.Livestock.GetByLivestockID(livestockID)
+	l, err := app.Services.Livestock.Update(r.Context(), user, livestockID, livestock.Input(req))
 	if err != nil {
-		app.ErrorLog.Printf("Error getting livestock: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if existingLivestock == nil {
-		app.errorJSON(w, errors.New("livestock not found"), http.StatusNotFound)
-		return
+	response := LivestockResponse{
+		Success:   true,
+		Message:   "Livestock updated successfully",
+		Livestock: l,
 	}
 
-	// Verify that the livestock belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// PatchLivestockHandler handles partial livestock updates. Only the fields in the body
+// change; one sent as null, blank or zero is cleared.
+func (app *Config) PatchLivestockHandler(w http.ResponseWriter, r *http.Request) {
+	var req LivestockRequest
+
+	set, err := app.readPatch(w, r, &req)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.errorJSON(w, err, http.StatusBadRequest)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
+	if errs := req.ValidatePatch(set); errs != nil {
+		app.failedValidation(w, errs)
 		return
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(existingLivestock.FarmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	livestockID := resourceID(r)
+	if livestockID == "" {
+		app.errorJSON(w, errors.New("livestock ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: livestock does not belong to user's farm"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Update livestock fields if provided
-	if req.Type != "" {
-		existingLivestock.Type = req.Type
-	}
-	if req.Count > 0 {
-		existingLivestock.Count = req.Count
-	}
-	if req.AcquisitionDate != nil {
-		existingLivestock.AcquisitionDate = req.AcquisitionDate
-	}
-	if req.HealthStatus != "" {
-		existingLivestock.HealthStatus = req.HealthStatus
-	}
-	if req.Notes != "" {
-		existingLivestock.Notes = req.Notes
-	}
-
-	// Update livestock
-	if err := app.Models.Livestock.Update(existingLivestock); err != nil {
-		app.ErrorLog.Printf("Error updating livestock: %v", err)
-		app.errorJSON(w, errors.New("failed to update livestock"), http.StatusInternalServerError)
+	l, err := app.Services.Livestock.Patch(r.Context(), user, livestockID, livestock.Input(req), set)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := LivestockResponse{
 		Success:   true,
 		Message:   "Livestock updated successfully",
-		Livestock: existingLivestock,
+		Livestock: l,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -330,69 +307,75 @@ func (app *Config) UpdateLivestockHandler(w http.ResponseWriter, r *http.Request
 
 // DeleteLivestockHandler handles livestock deletion
 func (app *Config) DeleteLivestockHandler(w http.ResponseWriter, r *http.Request) {
-	// Get livestock ID from URL parameters
-	livestockID := r.URL.Query().Get("id")
+	livestockID := resourceID(r)
 	if livestockID == "" {
 		app.errorJSON(w, errors.New("livestock ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	// Get user email from JWT claims (set by JWT middleware)
-	userEmail := r.Header.Get("X-User-Email")
-	if userEmail == "" {
-		app.errorJSON(w, errors.New("user not authenticated"), http.StatusUnauthorized)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get livestock to verify it exists
-	livestock, err := app.Models.Livestock.GetByLivestockID(livestockID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting livestock: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	if err := app.Services.Livestock.Delete(r.Context(), user, livestockID); err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
-	if livestock == nil {
-		app.errorJSON(w, errors.New("livestock not found"), http.StatusNotFound)
+	response := LivestockResponse{
+		Success: true,
+		Message: "Livestock deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDeletedLivestocksHandler handles listing soft-deleted livestock of a farm
+func (app *Config) GetDeletedLivestocksHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
 		return
 	}
 
-	// Verify that the livestock belongs to a farm owned by the authenticated user
-	user, err := app.Models.User.GetByEmail(userEmail)
+	livestocks, err := app.Services.Livestock.ListDeleted(r.Context(), user, farmID)
 	if err != nil {
-		app.ErrorLog.Printf("Error getting user by email: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		app.serviceError(w, err)
 		return
 	}
 
-	if user == nil {
-		app.errorJSON(w, errors.New("user not found"), http.StatusNotFound)
-		return
+	response := LivestockResponse{
+		Success:    true,
+		Message:    "Deleted livestock retrieved successfully",
+		Livestocks: livestocks,
 	}
 
-	// Get the farm to verify ownership
-	farm, err := app.Models.Farm.GetByFarmID(livestock.FarmID)
-	if err != nil {
-		app.ErrorLog.Printf("Error getting farm: %v", err)
-		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreLivestockHandler handles restoring a soft-deleted livestock
+func (app *Config) RestoreLivestockHandler(w http.ResponseWriter, r *http.Request) {
+	livestockID := resourceID(r)
+	if livestockID == "" {
+		app.errorJSON(w, errors.New("livestock ID is required"), http.StatusBadRequest)
 		return
 	}
 
-	if farm == nil || farm.UserID != user.UserID {
-		app.errorJSON(w, errors.New("access denied: livestock does not belong to user's farm"), http.StatusForbidden)
+	user, ok := app.currentUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Delete livestock (soft delete)
-	if err := app.Models.Livestock.DeleteByID(int(livestock.ID)); err != nil {
-		app.ErrorLog.Printf("Error deleting livestock: %v", err)
-		app.errorJSON(w, errors.New("failed to delete livestock"), http.StatusInternalServerError)
+	l, err := app.Services.Livestock.Restore(r.Context(), user, livestockID)
+	if err != nil {
+		app.serviceError(w, err)
 		return
 	}
 
 	response := LivestockResponse{
-		Success: true,
-		Message: "Livestock deleted successfully",
+		Success:   true,
+		Message:   "Livestock restored successfully",
+		Livestock: l,
 	}
 
 	app.writeJSON(w, http.StatusOK, response)
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/loan"
+	"net/http"
+	"time"
+)
+
+// LoanRequest represents the loan creation/update request body
+type LoanRequest struct {
+	Lender           string     `json:"lender"`
+	Principal        float64    `json:"principal"`
+	InterestRate     *float64   `json:"interestRate"`   // Annual percentage; 0 for an interest-free loan
+	InterestMethod   string     `json:"interestMethod"` // Flat (the default) or Reducing
+	DisbursementDate *time.Time `json:"disbursementDate"`
+	TermMonths       int        `json:"termMonths"`
+	Frequency        string     `json:"frequency"` // Monthly (the default), Quarterly or Bullet
+	Notes            string     `json:"notes"`
+}
+
+// LoanRepaymentRequest represents the loan repayment request body
+type LoanRepaymentRequest struct {
+	Amount float64    `json:"amount"`
+	Date   *time.Time `json:"date"` // Defaults to today
+	Notes  string     `json:"notes"`
+}
+
+// LoanResponse represents the loan response
+type LoanResponse struct {
+	Success     bool                    `json:"success"`
+	Message     string                  `json:"message"`
+	Loan        *data.Loan              `json:"loan,omitempty"`
+	Loans       []*data.Loan            `json:"loans,omitempty"`
+	Outstanding *loan.OutstandingReport `json:"outstanding,omitempty"`
+}
+
+// Validate checks the loan request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *LoanRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("lender", req.Lender)
+		v.Check(req.Principal > 0, "principal", "must be greater than 0")
+		v.Check(req.DisbursementDate != nil, "disbursementDate", "is required")
+		v.Check(req.TermMonths > 0, "termMonths", "must be greater than 0")
+	}
+	v.Check(req.Principal >= 0, "principal", "must be 0 or more")
+	v.Check(req.InterestRate == nil || *req.InterestRate >= 0, "interestRate", "must not be negative")
+	v.Check(req.TermMonths >= 0, "termMonths", "must be 0 or more")
+	v.OneOf("interestMethod", req.InterestMethod, loan.MethodFlat, loan.MethodReducing)
+	v.OneOf("frequency", req.Frequency, loan.FrequencyMonthly, loan.FrequencyQuarterly, loan.FrequencyBullet)
+	return v.Errors()
+}
+
+// Validate checks the loan repayment request fields
+func (req *LoanRepaymentRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Amount > 0, "amount", "must be greater than 0")
+	return v.Errors()
+}
+
+// CreateLoanHandler handles recording a loan taken by a farm, along with
+// its repayment schedule
+func (app *Config) CreateLoanHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoanRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	l, err := app.Services.Loan.Create(r.Context(), user, farmID, loan.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Loan created successfully",
+		Loan:    l,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetLoansHandler handles listing a farm's loans (/api/loans?farmId=&status=)
+func (app *Config) GetLoansHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	loans, err := app.Services.Loan.List(r.Context(), user, farmID, r.URL.Query().Get("status"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Loans retrieved successfully",
+		Loans:   loans,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetLoanHandler handles retrieving a single loan with its schedule and
+// repayments
+func (app *Config) GetLoanHandler(w http.ResponseWriter, r *http.Request) {
+	loanID := resourceID(r)
+	if loanID == "" {
+		app.errorJSON(w, errors.New("loan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	l, err := app.Services.Loan.Get(r.Context(), user, loanID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Loan retrieved successfully",
+		Loan:    l,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateLoanHandler handles updating a loan. Its terms can only change
+// before any repayment is logged.
+func (app *Config) UpdateLoanHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoanRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	loanID := resourceID(r)
+	if loanID == "" {
+		app.errorJSON(w, errors.New("loan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	l, err := app.Services.Loan.Update(r.Context(), user, loanID, loan.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Loan updated successfully",
+		Loan:    l,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteLoanHandler handles deleting a loan with no repayments
+func (app *Config) DeleteLoanHandler(w http.ResponseWriter, r *http.Request) {
+	loanID := resourceID(r)
+	if loanID == "" {
+		app.errorJSON(w, errors.New("loan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Loan.Delete(r.Context(), user, loanID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Loan deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RepayLoanHandler handles logging a repayment against a loan. The interest
+// it covers is recorded as an Interest expense.
+func (app *Config) RepayLoanHandler(w http.ResponseWriter, r *http.Request) {
+	var req LoanRepaymentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	loanID := resourceID(r)
+	if loanID == "" {
+		app.errorJSON(w, errors.New("loan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	l, err := app.Services.Loan.Repay(r.Context(), user, loanID, loan.RepaymentInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Repayment logged successfully",
+		Loan:    l,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// DeleteLoanRepaymentHandler handles removing a repayment logged in error
+func (app *Config) DeleteLoanRepaymentHandler(w http.ResponseWriter, r *http.Request) {
+	repaymentID := resourceID(r)
+	if repaymentID == "" {
+		app.errorJSON(w, errors.New("repayment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	l, err := app.Services.Loan.DeleteRepayment(r.Context(), user, repaymentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success: true,
+		Message: "Repayment deleted successfully",
+		Loan:    l,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetLoansOutstandingHandler handles reporting what a farm still owes on its
+// active loans, and how much of it is overdue (/api/loans/outstanding?farmId=)
+func (app *Config) GetLoansOutstandingHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	report, err := app.Services.Loan.Outstanding(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := LoanResponse{
+		Success:     true,
+		Message:     "Outstanding loans retrieved successfully",
+		Outstanding: report,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -1,26 +1,100 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"farm4u/cache"
 	"farm4u/data"
+	"farm4u/live"
+	"farm4u/notify"
+	"farm4u/pricefeed"
+	"farm4u/storage"
+	"farm4u/weather"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout bounds how long in-flight requests and background workers
+// are given to finish once a stop signal is received
+const shutdownTimeout = 30 * time.Second
+
+// defaultCacheTTL is how long cached users and farms are kept unless
+// CACHE_TTL says otherwise; changes made through the API drop them sooner
+const defaultCacheTTL = 10 * time.Minute
+
 func main() {
-	// Set default port
-	port := 9005
-	if envPort := os.Getenv("PORT"); envPort != "" {
-		if p, err := fmt.Sscanf(envPort, "%d", &port); err != nil || p != 1 {
-			log.Printf("Invalid PORT environment variable, using default port %d", port)
-		}
+	// "api migrate" applies or reverts schema migrations (see migrate.go)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
+	settings, err := loadAppConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	port := settings.Port
+	jwtKeys, err := loadJWTKeys(settings)
+	if err != nil {
+		log.Fatal(err)
 	}
 
 	app := Config{
-		InfoLog:  log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
-		ErrorLog: log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
+		Settings:       settings,
+		JWTKeys:        jwtKeys,
+		InfoLog:        log.New(os.Stdout, "INFO: ", log.Ldate|log.Ltime|log.Lshortfile),
+		ErrorLog:       log.New(os.Stderr, "ERROR: ", log.Ldate|log.Ltime|log.Lshortfile),
+		AccessLog:      slog.New(slog.NewJSONHandler(os.Stdout, nil)),
+		APIRateLimit:   settings.APIRateLimit,
+		AuthRateLimits: settings.AuthRateLimits,
+		APIUsage:       newUsageRecorder(),
+		Wait:           &sync.WaitGroup{},
+		Done:           make(chan struct{}),
+	}
+
+	rateLimiter, err := newRateLimitStore(settings)
+	if err != nil {
+		app.ErrorLog.Fatal("Failed to initialize rate limit store: ", err)
+	}
+	app.RateLimiter = rateLimiter
+
+	// Object storage: file://dir (default ./uploads), s3://bucket/prefix or gs://bucket/prefix
+	store, err := storage.Open(settings.StorageURL)
+	if err != nil {
+		app.ErrorLog.Fatal("Failed to initialize storage: ", err)
 	}
+	app.Storage = store
+	app.InfoLog.Printf("Using storage backend %s", store.Name())
+
+	// Notification providers, comma-separated in priority order (see notify.FromConfig)
+	notifier, err := notify.FromConfig(settings.NotifyProviders, app.InfoLog)
+	if err != nil {
+		app.ErrorLog.Fatal("Failed to initialize notification providers: ", err)
+	}
+	app.Notifier = notifier
+
+	// Rain forecasts: none:// (default) or open-meteo://
+	forecasts, err := weather.Open(settings.WeatherURL)
+	if err != nil {
+		app.ErrorLog.Fatal("Failed to initialize weather provider: ", err)
+	}
+	app.Weather = forecasts
+	app.InfoLog.Printf("Using weather provider %s", forecasts.Name())
+
+	// Commodity prices: none:// (default, admin uploads only) or an https:// JSON feed
+	priceFeed, err := pricefeed.Open(settings.MarketPricesURL)
+	if err != nil {
+		app.ErrorLog.Fatal("Failed to initialize market price feed: ", err)
+	}
+	app.PriceFeed = priceFeed
+	app.InfoLog.Printf("Using market price feed %s", priceFeed.Name())
 
 	db := app.initDB()
 	if db == nil {
@@ -30,20 +104,108 @@ func main() {
 	// Initialize models
 	models := data.New(db)
 
+	// Cache for hot lookups: none:// (default) or redis://host:port/db
+	lookups, err := cache.Open(settings.CacheURL)
+	if err != nil {
+		app.ErrorLog.Fatal("Failed to initialize cache: ", err)
+	}
+	if lookups.Name() != "none" {
+		models = models.Cached(lookups, settings.CacheTTL)
+	}
+	app.Cache = lookups
+	app.InfoLog.Printf("Using cache %s", lookups.Name())
+
+	// New notifications are pushed to their recipient's live event stream
+	app.Live = live.NewHub()
+	models = withLiveNotifications(models, app.Live)
+
 	app.DB = db
 	app.Models = models
+	app.Services = newServices(models, app.Storage, app.Weather, app.PriceFeed, app.Live)
+
+	// Start background jobs
+	app.background(app.watchInventoryExpiry)
+	app.background(app.watchLowStock)
+	app.background(app.watchDocumentExpiry)
+	app.background(app.flushAPIUsage)
+	app.background(app.monitorNotifiers)
+	app.background(app.releaseDueEscrows)
+	app.background(app.refreshMarketPrices)
+	app.background(app.generateQueuedReports)
+	app.background(app.generateQueuedExports)
+	app.background(app.purgeExpiredCredentials)
+	app.background(app.deliverWebhooks)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: app.routes(),
 	}
+	// Event streams stay open until the client leaves, so end them rather
+	// than wait for them on shutdown
+	srv.RegisterOnShutdown(app.Live.Close)
 
-	app.InfoLog.Printf("Starting Farm Manager 4U API server on port %d", port)
+	app.InfoLog.Printf("Starting Farm Manager 4U API server on port %d (%s)", port, settings.Env)
 	app.InfoLog.Printf("Database connected successfully")
 	app.InfoLog.Printf("API endpoints available at http://localhost:%d", port)
-	app.InfoLog.Printf("Health check: http://localhost:%d/health", port)
+	app.InfoLog.Printf("Health checks: http://localhost:%d/healthz (live), http://localhost:%d/readyz (ready)", port, port)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	if err := srv.ListenAndServe(); err != nil {
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
 		app.ErrorLog.Fatal("Failed to start server:", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	app.shutdown(srv)
+}
+
+// shutdown stops accepting requests, waits for in-flight requests and
+// background workers to finish, then closes the database pool
+func (app *Config) shutdown(srv *http.Server) {
+	app.InfoLog.Printf("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		app.ErrorLog.Printf("Error shutting down HTTP server: %v", err)
 	}
+
+	close(app.Done)
+
+	workersDone := make(chan struct{})
+	go func() {
+		app.Wait.Wait()
+		close(workersDone)
+	}()
+
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		app.ErrorLog.Printf("Timed out waiting for background workers")
+	}
+
+	if closer, ok := app.RateLimiter.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			app.ErrorLog.Printf("Error closing rate limit store: %v", err)
+		}
+	}
+
+	if sqlDB, err := app.DB.DB(); err != nil {
+		app.ErrorLog.Printf("Error getting database pool: %v", err)
+	} else if err := sqlDB.Close(); err != nil {
+		app.ErrorLog.Printf("Error closing database pool: %v", err)
+	}
+
+	app.InfoLog.Printf("Server stopped")
 }
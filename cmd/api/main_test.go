@@ -0,0 +1,90 @@
+package main
+
+import (
+	"farm4u/data"
+	"farm4u/live"
+	"farm4u/notify"
+	"farm4u/pricefeed"
+	"farm4u/storage"
+	"farm4u/weather"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+)
+
+// newTestApp sets up the application against the disposable database named
+// by TEST_DATABASE_URL, migrated to the latest version, with local storage
+// and no outside providers. The test is skipped when TEST_DATABASE_URL is
+// unset. Tests sign up their own users, so they can share the database.
+func newTestApp(t *testing.T) *Config {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	t.Setenv("DSN", dsn)
+	t.Setenv("DB_CONNECT_ATTEMPTS", "1")
+	t.Setenv("JWT_SECRET", "test-secret-for-the-api-tests-only")
+	t.Setenv("APP_ENV", envDevelopment)
+
+	settings, err := loadAppConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtKeys, err := loadJWTKeys(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := &Config{
+		Settings:       settings,
+		JWTKeys:        jwtKeys,
+		InfoLog:        log.New(io.Discard, "", 0),
+		ErrorLog:       log.New(io.Discard, "", 0),
+		AccessLog:      slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		RateLimiter:    newMemoryRateLimitStore(),
+		APIRateLimit:   defaultAPIRateLimit,
+		AuthRateLimits: settings.AuthRateLimits,
+		APIUsage:       newUsageRecorder(),
+		Wait:           &sync.WaitGroup{},
+		Done:           make(chan struct{}),
+		Weather:        weather.None{},
+		PriceFeed:      pricefeed.None{},
+		Live:           live.NewHub(),
+	}
+
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Storage = store
+	notifier, err := notify.FromConfig(nil, app.InfoLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.Notifier = notifier
+
+	db := connectToDB(settings.DB)
+	if db == nil {
+		t.Fatal("can't connect to TEST_DATABASE_URL")
+	}
+	if err := migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		close(app.Done)
+		app.Wait.Wait()
+		app.Live.Close()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	app.DB = db
+	app.Models = data.New(db)
+	app.Services = newServices(app.Models, app.Storage, app.Weather, app.PriceFeed, app.Live)
+	return app
+}
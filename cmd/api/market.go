@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"farm4u/service/market"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPriceUploadBytes caps the size of an admin price upload
+const maxPriceUploadBytes = 5 << 20
+
+// priceColumns are the columns of a CSV price upload, in order after the header
+var priceColumns = []string{"commodity", "region", "unit", "price", "currency", "date"}
+
+// MarketPriceRequest represents one price in an admin upload
+type MarketPriceRequest struct {
+	Commodity string     `json:"commodity"`
+	Region    string     `json:"region"`
+	Unit      string     `json:"unit"`
+	Price     float64    `json:"price"`
+	Currency  string     `json:"currency"`
+	Date      *time.Time `json:"date"`
+}
+
+// MarketPriceUploadRequest represents the admin price upload request body
+type MarketPriceUploadRequest struct {
+	Prices []MarketPriceRequest `json:"prices"`
+}
+
+// MarketPriceResponse represents the market price response
+type MarketPriceResponse struct {
+	Success     bool           `json:"success"`
+	Message     string         `json:"message"`
+	Prices      *market.Prices `json:"prices,omitempty"`
+	Commodities []string       `json:"commodities,omitempty"`
+	Imported    int            `json:"imported,omitempty"`
+}
+
+// Validate checks the price upload request fields
+func (req *MarketPriceUploadRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(len(req.Prices) > 0, "prices", "must include at least one price")
+	for i, price := range req.Prices {
+		field := fmt.Sprintf("prices[%d]", i)
+		v.Required(field+".commodity", price.Commodity)
+		v.Required(field+".region", price.Region)
+		v.Required(field+".unit", price.Unit)
+		v.Check(price.Price > 0, field+".price", "must be greater than 0")
+		v.Check(len(strings.TrimSpace(price.Currency)) == 3, field+".currency", "must be a 3-letter code")
+		v.Check(price.Date != nil, field+".date", "is required")
+	}
+	return v.Errors()
+}
+
+// inputs converts the request to the market service input
+func (req *MarketPriceUploadRequest) inputs() []market.Input {
+	in := make([]market.Input, len(req.Prices))
+	for i, price := range req.Prices {
+		in[i] = market.Input{
+			Commodity: price.Commodity,
+			Region:    price.Region,
+			Unit:      price.Unit,
+			Price:     price.Price,
+			Currency:  price.Currency,
+			Date:      *price.Date,
+		}
+	}
+	return in
+}
+
+// readPriceCSV reads a CSV price upload. The first row is a header naming
+// the priceColumns; dates are YYYY-MM-DD.
+func readPriceCSV(body io.Reader) (*MarketPriceUploadRequest, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = len(priceColumns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	for i, column := range priceColumns {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), column) {
+			return nil, fmt.Errorf("CSV columns must be %s", strings.Join(priceColumns, ","))
+		}
+	}
+
+	req := &MarketPriceUploadRequest{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: price must be a number", line)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(record[5]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: date must be in YYYY-MM-DD format", line)
+		}
+		req.Prices = append(req.Prices, MarketPriceRequest{
+			Commodity: record[0],
+			Region:    record[1],
+			Unit:      record[2],
+			Price:     price,
+			Currency:  record[4],
+			Date:      &date,
+		})
+	}
+	return req, nil
+}
+
+// GetMarketPricesHandler handles retrieving a commodity's prices by
+// ?commodity=, optionally limited by ?region= and ?from=/?to=
+func (app *Config) GetMarketPricesHandler(w http.ResponseWriter, r *http.Request) {
+	commodity := r.URL.Query().Get("commodity")
+	if commodity == "" {
+		app.errorJSON(w, errors.New("commodity is required"), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	prices, err := app.Services.Market.Prices(r.Context(), commodity, r.URL.Query().Get("region"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MarketPriceResponse{
+		Success: true,
+		Message: "Market prices retrieved successfully",
+		Prices:  prices,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMarketCommoditiesHandler handles listing the commodities that have prices
+func (app *Config) GetMarketCommoditiesHandler(w http.ResponseWriter, r *http.Request) {
+	commodities, err := app.Services.Market.Commodities(r.Context())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MarketPriceResponse{
+		Success:     true,
+		Message:     "Commodities retrieved successfully",
+		Commodities: commodities,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AdminUploadMarketPricesHandler handles an admin uploading prices, either as
+// JSON ({"prices": [...]}) or as CSV with a Content-Type of text/csv. Prices
+// already held for the same commodity, region, unit and day are replaced.
+func (app *Config) AdminUploadMarketPricesHandler(w http.ResponseWriter, r *http.Request) {
+	req := &MarketPriceUploadRequest{}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		var err error
+		req, err = readPriceCSV(http.MaxBytesReader(w, r.Body, maxPriceUploadBytes))
+		if err != nil {
+			app.errorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+	} else if err := app.ReadJSON(w, r, req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	imported, err := app.Services.Market.Import(r.Context(), req.inputs())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MarketPriceResponse{
+		Success:  true,
+		Message:  "Market prices imported successfully",
+		Imported: imported,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"farm4u/data"
+	"farm4u/service/farm"
+	"net/http"
+	"strconv"
+)
+
+// Membership is a role the user has on someone else's farm, with what the
+// role allows
+type Membership struct {
+	*data.FarmMember
+	Permissions map[farm.Module][]farm.Action `json:"permissions"`
+}
+
+// MeResponse represents the current user response
+type MeResponse struct {
+	Success     bool         `json:"success"`
+	Message     string       `json:"message"`
+	User        *data.User   `json:"user"`
+	OwnedFarms  []*data.Farm `json:"ownedFarms"`
+	Memberships []Membership `json:"memberships"`
+	// ImpersonatorID is the admin acting as the user, in a support session
+	ImpersonatorID int `json:"impersonatorId,omitempty"`
+}
+
+// GetMeHandler returns the authenticated user's profile and platform role,
+// the farms they own and the roles they have on other farms, so a client can
+// set itself up after login in one call
+func (app *Config) GetMeHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	owned, err := app.Services.Farm.List(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	members, err := app.Services.Farm.Memberships(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	memberships := make([]Membership, 0, len(members))
+	for _, member := range members {
+		permissions := farm.Permissions[member.Role]
+		if permissions == nil {
+			permissions = map[farm.Module][]farm.Action{}
+		}
+		memberships = append(memberships, Membership{FarmMember: member, Permissions: permissions})
+	}
+	if owned == nil {
+		owned = []*data.Farm{}
+	}
+
+	response := MeResponse{
+		Success:     true,
+		Message:     "Current user retrieved successfully",
+		User:        user,
+		OwnedFarms:  owned,
+		Memberships: memberships,
+	}
+	if id, err := strconv.Atoi(r.Header.Get("X-Impersonator-ID")); err == nil {
+		response.ImpersonatorID = id
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
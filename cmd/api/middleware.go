@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"farm4u/service"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// requestIDHeader carries the request ID in both directions
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key holding the request ID
+type requestIDKey struct{}
+
+// validRequestID limits client-supplied request IDs to safe, short values
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// requestID returns the ID assigned to the request by RequestLogger
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID generates a random 16-byte hex request ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger assigns each request an ID (reusing a well-formed incoming
+// X-Request-ID), echoes it in the response and logs the request as JSON once
+// it completes
+func (app *Config) RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		// Identity headers are only trusted when set by JWTMiddleware
+		r.Header.Del("X-User-ID")
+		r.Header.Del("X-User-Email")
+		r.Header.Del("X-User-Role")
+		r.Header.Del("X-Impersonator-ID")
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		r = r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			app.AccessLog.LogAttrs(r.Context(), level, "request",
+				slog.String("request_id", id),
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("user_id", r.Header.Get("X-User-ID")),
+				slog.String("user_email", r.Header.Get("X-User-Email")),
+				slog.String("impersonator_id", r.Header.Get("X-Impersonator-ID")),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		}()
+
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// Deprecated marks responses served under the legacy path prefix as
+// deprecated, with a Link header naming the same route under its successor
+// prefix, so old clients keep working while being told where to move. Those
+// clients predate record versions, so their updates may leave the version out.
+func (app *Config) Deprecated(legacy, successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successor, strings.TrimPrefix(r.URL.Path, legacy)))
+			next.ServeHTTP(w, r.WithContext(service.AllowUnversioned(r.Context())))
+		})
+	}
+}
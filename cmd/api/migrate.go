@@ -0,0 +1,154 @@
+package main
+
+import (
+	"farm4u/migrations"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+)
+
+// The schema is managed by the versioned migrations in the migrations
+// package. The server applies pending ones when it starts unless
+// MIGRATE_ON_START=false; with that set, apply them beforehand with
+//
+//	api migrate up         apply every pending migration
+//	api migrate down [n]   revert the last n migrations (default 1)
+//	api migrate status     list the migrations and whether each was applied
+//	api migrate force v    record version v as applied after fixing a
+//	                       failed (dirty) migration by hand
+
+// migrate applies the pending migrations to db
+func migrate(db *gorm.DB) error {
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	applied, err := m.Up()
+	for _, migration := range applied {
+		log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+// runMigrate runs the migrate command with its arguments and returns the exit
+// status
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: api migrate up | down [n] | status | force version")
+	}
+	flags.Parse(args)
+
+	command := flags.Arg(0)
+	steps, version := 1, 0
+	switch command {
+	case "up", "status":
+		if flags.NArg() > 1 {
+			flags.Usage()
+			return 2
+		}
+	case "down":
+		if flags.NArg() > 2 {
+			flags.Usage()
+			return 2
+		}
+		if v := flags.Arg(1); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				log.Printf("down takes a number of migrations of at least 1, not %q", v)
+				return 2
+			}
+			steps = n
+		}
+	case "force":
+		if flags.NArg() != 2 {
+			flags.Usage()
+			return 2
+		}
+		n, err := strconv.Atoi(flags.Arg(1))
+		if err != nil || n < 1 {
+			log.Printf("force takes a migration version, not %q", flags.Arg(1))
+			return 2
+		}
+		version = n
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	settings, err := loadAppConfig()
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	db := connectToDB(settings.DB)
+	if db == nil {
+		log.Print("can't connect to database")
+		return 1
+	}
+	m, err := migrations.New(db)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+	defer m.Close()
+
+	switch command {
+	case "up":
+		applied, err := m.Up()
+		for _, migration := range applied {
+			fmt.Printf("applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		reverted, err := m.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Println("no applied migrations")
+		}
+	case "status":
+		states, err := m.Status()
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+		for _, state := range states {
+			status := "pending"
+			switch {
+			case state.Dirty:
+				status = "dirty"
+			case state.Applied:
+				status = "applied"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", state.Version, state.Name, status)
+		}
+		w.Flush()
+	case "force":
+		if err := m.Force(version); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("forced version %d\n", version)
+	}
+	return 0
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/mortality"
+	"net/http"
+	"time"
+)
+
+// MortalityRequest represents the mortality record creation/update request
+// body
+type MortalityRequest struct {
+	LivestockID string     `json:"livestockId"` // Herd the animals were in
+	AnimalTag   string     `json:"animalTag"`   // The animal, when a single one was lost
+	Kind        string     `json:"kind"`        // Death, Cull
+	Count       int        `json:"count"`       // Defaults to 1
+	Date        *time.Time `json:"date"`        // Defaults to now
+	Cause       string     `json:"cause"`
+	ValueLost   float64    `json:"valueLost"` // Booked as a Livestock Losses expense
+	Notes       string     `json:"notes"`
+}
+
+// MortalityResponse represents the mortality record response
+type MortalityResponse struct {
+	Success bool                    `json:"success"`
+	Message string                  `json:"message"`
+	Record  *data.MortalityRecord   `json:"record,omitempty"`
+	Records []*data.MortalityRecord `json:"records,omitempty"`
+}
+
+// Validate checks the mortality request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *MortalityRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("livestockId", req.LivestockID)
+		v.Required("kind", req.Kind)
+		v.Required("cause", req.Cause)
+	}
+	v.OneOf("kind", req.Kind, mortality.Kinds()...)
+	v.Check(req.Count >= 0, "count", "must be 0 or more")
+	v.Check(req.ValueLost >= 0, "valueLost", "must not be negative")
+	if req.Date != nil {
+		v.Check(!req.Date.After(time.Now()), "date", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateMortalityHandler handles recording animals that died or were culled,
+// taking them off their herd's count
+func (app *Config) CreateMortalityHandler(w http.ResponseWriter, r *http.Request) {
+	var req MortalityRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Mortality.Record(r.Context(), user, farmID, mortality.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MortalityResponse{
+		Success: true,
+		Message: "Loss recorded successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetMortalitiesHandler handles retrieving a farm's losses, optionally
+// filtered by the livestockId, from and to (YYYY-MM-DD) query parameters
+func (app *Config) GetMortalitiesHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Mortality.List(r.Context(), user, farmID, r.URL.Query().Get("livestockId"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MortalityResponse{
+		Success: true,
+		Message: "Losses retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMortalityHandler handles retrieving a single loss by ID
+func (app *Config) GetMortalityHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("mortality record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Mortality.Get(r.Context(), user, recordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MortalityResponse{
+		Success: true,
+		Message: "Loss retrieved successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateMortalityHandler handles correcting a loss. A changed count moves
+// the herd's count by the difference.
+func (app *Config) UpdateMortalityHandler(w http.ResponseWriter, r *http.Request) {
+	var req MortalityRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("mortality record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Mortality.Update(r.Context(), user, recordID, mortality.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MortalityResponse{
+		Success: true,
+		Message: "Loss updated successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteMortalityHandler handles deleting a loss recorded in error, putting
+// the animals back on the herd's count
+func (app *Config) DeleteMortalityHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("mortality record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Mortality.Delete(r.Context(), user, recordID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := MortalityResponse{
+		Success: true,
+		Message: "Loss deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
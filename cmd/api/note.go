@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/note"
+	"net/http"
+	"time"
+)
+
+// NoteRequest represents the note creation/update request body
+type NoteRequest struct {
+	RecordType string     `json:"recordType"` // crop, livestock, equipment, field, employee, maintenance, incident
+	RecordID   string     `json:"recordId"`
+	Body       string     `json:"body"`
+	NotedAt    *time.Time `json:"notedAt"` // When the observation was made; defaults to now
+}
+
+// NoteResponse represents the note response
+type NoteResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Note    *data.Note   `json:"note,omitempty"`
+	Notes   []*data.Note `json:"notes,omitempty"`
+}
+
+// Validate checks the note request fields. When partial is true only the
+// fields that are present are checked, as used by updates, which cannot move
+// a note to another record.
+func (req *NoteRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("recordType", req.RecordType)
+		v.Required("recordId", req.RecordID)
+		v.Required("body", req.Body)
+		v.OneOf("recordType", req.RecordType, note.RecordTypes...)
+	} else {
+		v.Check(req.RecordType == "" && req.RecordID == "", "recordType", "cannot be changed")
+	}
+	v.Check(len(req.Body) <= note.MaxBody, "body", "must be at most 5000 characters")
+	if req.NotedAt != nil {
+		v.Check(!req.NotedAt.After(time.Now()), "notedAt", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateNoteHandler handles adding a note to a record
+func (app *Config) CreateNoteHandler(w http.ResponseWriter, r *http.Request) {
+	var req NoteRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	n, err := app.Services.Note.Create(r.Context(), user, note.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := NoteResponse{
+		Success: true,
+		Message: "Note created successfully",
+		Note:    n,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetNotesHandler handles retrieving the notes on a record
+// (/api/notes?recordType=&recordId=)
+func (app *Config) GetNotesHandler(w http.ResponseWriter, r *http.Request) {
+	recordType := r.URL.Query().Get("recordType")
+	recordID := r.URL.Query().Get("recordId")
+	if recordType == "" || recordID == "" {
+		app.errorJSON(w, errors.New("record type and record ID are required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	notes, err := app.Services.Note.List(r.Context(), user, recordType, recordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := NoteResponse{
+		Success: true,
+		Message: "Notes retrieved successfully",
+		Notes:   notes,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetNoteHandler handles retrieving a note
+func (app *Config) GetNoteHandler(w http.ResponseWriter, r *http.Request) {
+	noteID := resourceID(r)
+	if noteID == "" {
+		app.errorJSON(w, errors.New("note ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	n, err := app.Services.Note.Get(r.Context(), user, noteID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := NoteResponse{
+		Success: true,
+		Message: "Note retrieved successfully",
+		Note:    n,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateNoteHandler handles the author correcting a note
+func (app *Config) UpdateNoteHandler(w http.ResponseWriter, r *http.Request) {
+	var req NoteRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	noteID := resourceID(r)
+	if noteID == "" {
+		app.errorJSON(w, errors.New("note ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	n, err := app.Services.Note.Update(r.Context(), user, noteID, note.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := NoteResponse{
+		Success: true,
+		Message: "Note updated successfully",
+		Note:    n,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteNoteHandler handles deleting a note
+func (app *Config) DeleteNoteHandler(w http.ResponseWriter, r *http.Request) {
+	noteID := resourceID(r)
+	if noteID == "" {
+		app.errorJSON(w, errors.New("note ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Note.Delete(r.Context(), user, noteID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := NoteResponse{
+		Success: true,
+		Message: "Note deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
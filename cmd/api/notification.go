@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"net/http"
+)
+
+// NotificationResponse represents the notification response
+type NotificationResponse struct {
+	Success       bool                 `json:"success"`
+	Message       string               `json:"message"`
+	Notifications []*data.Notification `json:"notifications,omitempty"`
+}
+
+// GetNotificationsHandler handles retrieving the authenticated user's notifications
+func (app *Config) GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	notifications, err := app.Models.Notification.GetByUserID(r.Context(), user.UserID, unreadOnly)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting notifications: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	response := NotificationResponse{
+		Success:       true,
+		Message:       "Notifications retrieved successfully",
+		Notifications: notifications,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// MarkNotificationReadHandler handles marking a single notification as read
+func (app *Config) MarkNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	notificationID := resourceID(r)
+	if notificationID == "" {
+		app.errorJSON(w, errors.New("notification ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	notification, err := app.Models.Notification.GetByNotificationID(r.Context(), notificationID)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting notification: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	if notification == nil || notification.UserID != user.UserID {
+		app.errorJSON(w, errors.New("notification not found"), http.StatusNotFound)
+		return
+	}
+
+	if err := app.Models.Notification.MarkRead(r.Context(), notificationID); err != nil {
+		app.ErrorLog.Printf("Error marking notification read: %v", err)
+		app.errorJSON(w, errors.New("failed to update notification"), http.StatusInternalServerError)
+		return
+	}
+
+	response := NotificationResponse{
+		Success: true,
+		Message: "Notification marked as read",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// MarkAllNotificationsReadHandler handles marking all of the user's notifications as read
+func (app *Config) MarkAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Models.Notification.MarkAllRead(r.Context(), user.UserID); err != nil {
+		app.ErrorLog.Printf("Error marking notifications read: %v", err)
+		app.errorJSON(w, errors.New("failed to update notifications"), http.StatusInternalServerError)
+		return
+	}
+
+	response := NotificationResponse{
+		Success: true,
+		Message: "All notifications marked as read",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// notify stores an in-app notification for a user unless one with the same
+// reference already exists
+func (app *Config) notify(ctx context.Context, userID string, farmID *string, kind, title, message, reference string) error {
+	if reference != "" {
+		exists, err := app.Models.Notification.ExistsByReference(ctx, userID, reference)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+	}
+
+	return app.Models.Notification.Insert(ctx, &data.Notification{
+		UserID:    userID,
+		FarmID:    farmID,
+		Type:      kind,
+		Title:     title,
+		Message:   message,
+		Reference: reference,
+	})
+}
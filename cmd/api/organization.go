@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/coop"
+	"net/http"
+)
+
+// OrganizationRequest represents the organization creation request body
+type OrganizationRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// OrganizationMemberRequest represents the request body for adding a user to
+// an organization
+type OrganizationMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // Admin or Member; Member if empty
+}
+
+// OrganizationResponse represents the organization response
+type OrganizationResponse struct {
+	Success       bool                 `json:"success"`
+	Message       string               `json:"message"`
+	Organization  *data.Organization   `json:"organization,omitempty"`
+	Organizations []*data.Organization `json:"organizations,omitempty"`
+}
+
+// OrganizationMemberResponse represents the organization member response
+type OrganizationMemberResponse struct {
+	Success bool                     `json:"success"`
+	Message string                   `json:"message"`
+	Member  *data.OrganizationMember `json:"member,omitempty"`
+}
+
+// Validate checks the organization request fields
+func (req *OrganizationRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("name", req.Name)
+	return v.Errors()
+}
+
+// Validate checks the organization member request fields
+func (req *OrganizationMemberRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("email", req.Email)
+	v.Email("email", req.Email)
+	if req.Role != "" {
+		v.OneOf("role", req.Role, coop.RoleAdmin, coop.RoleMember)
+	}
+	return v.Errors()
+}
+
+// CreateOrganizationHandler handles setting up a co-operative or farmer group
+// with the authenticated user as its admin
+func (app *Config) CreateOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	var req OrganizationRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	organization, err := app.Services.Coop.CreateOrganization(r.Context(), user, coop.OrganizationInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := OrganizationResponse{
+		Success:      true,
+		Message:      "Organization created successfully",
+		Organization: organization,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetOrganizationsHandler handles listing the organizations the
+// authenticated user is a member of
+func (app *Config) GetOrganizationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	organizations, err := app.Services.Coop.ListOrganizations(r.Context(), user)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := OrganizationResponse{
+		Success:       true,
+		Message:       "Organizations retrieved successfully",
+		Organizations: organizations,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetOrganizationHandler handles retrieving an organization with its members
+func (app *Config) GetOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	organizationID := resourceID(r)
+	if organizationID == "" {
+		app.errorJSON(w, errors.New("organization ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	organization, err := app.Services.Coop.GetOrganization(r.Context(), user, organizationID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := OrganizationResponse{
+		Success:      true,
+		Message:      "Organization retrieved successfully",
+		Organization: organization,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// AddOrganizationMemberHandler handles an organization admin adding another
+// user to the organization
+func (app *Config) AddOrganizationMemberHandler(w http.ResponseWriter, r *http.Request) {
+	var req OrganizationMemberRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	organizationID := resourceID(r)
+	if organizationID == "" {
+		app.errorJSON(w, errors.New("organization ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	member, err := app.Services.Coop.AddMember(r.Context(), user, organizationID, coop.MemberInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := OrganizationMemberResponse{
+		Success: true,
+		Message: "Organization member added successfully",
+		Member:  member,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// RemoveOrganizationMemberHandler handles an organization admin taking a
+// member out of the organization
+func (app *Config) RemoveOrganizationMemberHandler(w http.ResponseWriter, r *http.Request) {
+	memberID := resourceID(r)
+	if memberID == "" {
+		app.errorJSON(w, errors.New("member ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Coop.RemoveMember(r.Context(), user, memberID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := OrganizationMemberResponse{
+		Success: true,
+		Message: "Organization member removed successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/workforce"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// PaymentRequest represents the payroll payment request body
+type PaymentRequest struct {
+	PeriodStart      *time.Time `json:"periodStart"`
+	PeriodEnd        *time.Time `json:"periodEnd"`
+	GrossPay         float64    `json:"grossPay"`
+	Deductions       float64    `json:"deductions"`
+	PaymentDate      *time.Time `json:"paymentDate"`
+	PaymentMethod    string     `json:"paymentMethod"`
+	PaymentReference string     `json:"paymentReference"`
+	Notes            string     `json:"notes"`
+}
+
+// PayrollResponse represents the payroll response
+type PayrollResponse struct {
+	Success  bool                       `json:"success"`
+	Message  string                     `json:"message"`
+	Payment  *data.PayrollPayment       `json:"payment,omitempty"`
+	Payments []*data.PayrollPayment     `json:"payments,omitempty"`
+	Monthly  []data.MonthlyPayrollTotal `json:"monthly,omitempty"`
+}
+
+// Validate checks the payroll payment request fields
+func (req *PaymentRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.PeriodStart != nil, "periodStart", "is required")
+	v.Check(req.PeriodEnd != nil, "periodEnd", "is required")
+	if req.PeriodStart != nil && req.PeriodEnd != nil {
+		v.Check(!req.PeriodEnd.Before(*req.PeriodStart), "periodEnd", "must not be before periodStart")
+	}
+	v.Check(req.GrossPay > 0, "grossPay", "must be greater than 0")
+	v.Check(req.Deductions >= 0, "deductions", "must be >= 0")
+	v.Check(req.Deductions <= req.GrossPay, "deductions", "must not exceed grossPay")
+	v.Required("paymentMethod", req.PaymentMethod)
+	v.OneOf("paymentMethod", req.PaymentMethod, "Cash", "Bank Transfer", "Mobile Money", "Cheque")
+	return v.Errors()
+}
+
+// RecordPaymentHandler handles recording a payroll payment to an employee
+func (app *Config) RecordPaymentHandler(w http.ResponseWriter, r *http.Request) {
+	var req PaymentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	payment, err := app.Services.Workforce.RecordPayment(r.Context(), user, employeeID, workforce.PaymentInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PayrollResponse{
+		Success: true,
+		Message: "Payment recorded successfully",
+		Payment: payment,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetEmployeePaymentsHandler handles retrieving an employee's payments,
+// optionally limited by ?from=/?to=
+func (app *Config) GetEmployeePaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	employeeID := resourceID(r)
+	if employeeID == "" {
+		app.errorJSON(w, errors.New("employee ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	payments, err := app.Services.Workforce.ListEmployeePayments(r.Context(), user, employeeID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PayrollResponse{
+		Success:  true,
+		Message:  "Payments retrieved successfully",
+		Payments: payments,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetPaymentsHandler handles retrieving a farm's payroll payments,
+// optionally limited by ?from=/?to=
+func (app *Config) GetPaymentsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	payments, err := app.Services.Workforce.ListPayments(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PayrollResponse{
+		Success:  true,
+		Message:  "Payments retrieved successfully",
+		Payments: payments,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeletePaymentHandler handles deleting a payroll payment
+func (app *Config) DeletePaymentHandler(w http.ResponseWriter, r *http.Request) {
+	paymentID := resourceID(r)
+	if paymentID == "" {
+		app.errorJSON(w, errors.New("payment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Workforce.DeletePayment(r.Context(), user, paymentID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PayrollResponse{
+		Success: true,
+		Message: "Payment deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetPayrollSummaryHandler reports a farm's payroll per month of ?year=
+// (default this year)
+func (app *Config) GetPayrollSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1900 || y > 9999 {
+			app.errorJSON(w, errors.New("year must be a valid year"), http.StatusBadRequest)
+			return
+		}
+		year = y
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	monthly, err := app.Services.Workforce.PayrollSummary(r.Context(), user, farmID, year)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PayrollResponse{
+		Success: true,
+		Message: "Payroll summary retrieved successfully",
+		Monthly: monthly,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/lock"
+	"net/http"
+	"time"
+)
+
+// PeriodLockRequest represents the period lock request body
+type PeriodLockRequest struct {
+	PeriodStart *time.Time `json:"periodStart"`
+	PeriodEnd   *time.Time `json:"periodEnd"`
+	Reason      string     `json:"reason"`
+}
+
+// UnlockPeriodRequest represents the period unlock request body
+type UnlockPeriodRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PeriodLockResponse represents the period lock response
+type PeriodLockResponse struct {
+	Success bool               `json:"success"`
+	Message string             `json:"message"`
+	Lock    *data.PeriodLock   `json:"lock,omitempty"`
+	Locks   []*data.PeriodLock `json:"locks,omitempty"`
+	Entries []*data.AuditLog   `json:"entries,omitempty"`
+}
+
+// Validate checks the period lock request fields
+func (req *PeriodLockRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.PeriodStart != nil, "periodStart", "is required")
+	v.Check(req.PeriodEnd != nil, "periodEnd", "is required")
+	if req.PeriodStart != nil && req.PeriodEnd != nil {
+		v.Check(!req.PeriodEnd.Before(*req.PeriodStart), "periodEnd", "must not be before periodStart")
+	}
+	v.Required("reason", req.Reason)
+	return v.Errors()
+}
+
+// Validate checks the period unlock request fields
+func (req *UnlockPeriodRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("reason", req.Reason)
+	return v.Errors()
+}
+
+// LockPeriodHandler handles locking a farm's records within a closed period
+func (app *Config) LockPeriodHandler(w http.ResponseWriter, r *http.Request) {
+	var req PeriodLockRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	periodLock, err := app.Services.Lock.Lock(r.Context(), user, farmID, lock.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PeriodLockResponse{
+		Success: true,
+		Message: "Period locked successfully",
+		Lock:    periodLock,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetPeriodLocksHandler handles retrieving a farm's active period locks, or
+// all of them with ?all=true
+func (app *Config) GetPeriodLocksHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	locks, err := app.Services.Lock.List(r.Context(), user, farmID, r.URL.Query().Get("all") == "true")
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PeriodLockResponse{
+		Success: true,
+		Message: "Period locks retrieved successfully",
+		Locks:   locks,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UnlockPeriodHandler handles reopening a locked period. The unlock and its
+// reason are written to the audit log.
+func (app *Config) UnlockPeriodHandler(w http.ResponseWriter, r *http.Request) {
+	var req UnlockPeriodRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	lockID := resourceID(r)
+	if lockID == "" {
+		app.errorJSON(w, errors.New("period lock ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	periodLock, err := app.Services.Lock.Unlock(r.Context(), user, lockID, req.Reason)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PeriodLockResponse{
+		Success: true,
+		Message: "Period unlocked successfully",
+		Lock:    periodLock,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetAuditLogHandler handles retrieving a farm's audit log, optionally
+// filtered by ?entityType= and limited by ?from=/?to=
+func (app *Config) GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := app.Services.Lock.AuditLog(r.Context(), user, farmID, r.URL.Query().Get("entityType"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PeriodLockResponse{
+		Success: true,
+		Message: "Audit log retrieved successfully",
+		Entries: entries,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/crop"
+	"net/http"
+)
+
+// PlanScenarioRequest represents the what-if scenario creation/update request body
+type PlanScenarioRequest struct {
+	Name            string   `json:"name"`
+	PricePerKg      float64  `json:"pricePerKg"`
+	ExpectedYield   *float64 `json:"expectedYield"`
+	InputCostChange *float64 `json:"inputCostChange"`
+	OtherCosts      *float64 `json:"otherCosts"`
+	Notes           string   `json:"notes"`
+}
+
+// PlanScenarioResponse represents the what-if scenario response
+type PlanScenarioResponse struct {
+	Success    bool                     `json:"success"`
+	Message    string                   `json:"message"`
+	Scenario   *data.PlanScenario       `json:"scenario,omitempty"`
+	Comparison *crop.ScenarioComparison `json:"comparison,omitempty"`
+}
+
+// Validate checks the scenario request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *PlanScenarioRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Check(req.PricePerKg > 0, "pricePerKg", "must be greater than 0")
+	}
+	v.Check(req.PricePerKg >= 0, "pricePerKg", "must be >= 0")
+	v.Check(req.ExpectedYield == nil || *req.ExpectedYield >= 0, "expectedYield", "must be >= 0")
+	v.Check(req.InputCostChange == nil || *req.InputCostChange >= -100, "inputCostChange", "must be >= -100")
+	v.Check(req.OtherCosts == nil || *req.OtherCosts >= 0, "otherCosts", "must be >= 0")
+	return v.Errors()
+}
+
+// CreatePlanScenarioHandler handles saving a what-if scenario for a crop plan
+func (app *Config) CreatePlanScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	var req PlanScenarioRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	planID := resourceID(r)
+	if planID == "" {
+		app.errorJSON(w, errors.New("plan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	scenario, err := app.Services.Crop.CreateScenario(r.Context(), user, planID, crop.ScenarioInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PlanScenarioResponse{
+		Success:  true,
+		Message:  "Scenario created successfully",
+		Scenario: scenario,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// ComparePlanScenariosHandler handles setting a crop plan's scenarios side by side
+func (app *Config) ComparePlanScenariosHandler(w http.ResponseWriter, r *http.Request) {
+	planID := resourceID(r)
+	if planID == "" {
+		app.errorJSON(w, errors.New("plan ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	comparison, err := app.Services.Crop.CompareScenarios(r.Context(), user, planID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PlanScenarioResponse{
+		Success:    true,
+		Message:    "Scenarios compared successfully",
+		Comparison: comparison,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetPlanScenarioHandler handles retrieving a single scenario with its projection
+func (app *Config) GetPlanScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	scenarioID := resourceID(r)
+	if scenarioID == "" {
+		app.errorJSON(w, errors.New("scenario ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	scenario, err := app.Services.Crop.GetScenario(r.Context(), user, scenarioID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PlanScenarioResponse{
+		Success:  true,
+		Message:  "Scenario retrieved successfully",
+		Scenario: scenario,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdatePlanScenarioHandler handles scenario updates
+func (app *Config) UpdatePlanScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	var req PlanScenarioRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	scenarioID := resourceID(r)
+	if scenarioID == "" {
+		app.errorJSON(w, errors.New("scenario ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	scenario, err := app.Services.Crop.UpdateScenario(r.Context(), user, scenarioID, crop.ScenarioInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PlanScenarioResponse{
+		Success:  true,
+		Message:  "Scenario updated successfully",
+		Scenario: scenario,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeletePlanScenarioHandler handles scenario deletion
+func (app *Config) DeletePlanScenarioHandler(w http.ResponseWriter, r *http.Request) {
+	scenarioID := resourceID(r)
+	if scenarioID == "" {
+		app.errorJSON(w, errors.New("scenario ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Crop.DeleteScenario(r.Context(), user, scenarioID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PlanScenarioResponse{
+		Success: true,
+		Message: "Scenario deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
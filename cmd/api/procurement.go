@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/coop"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ProcurementItemRequest is one input offered in a procurement window request
+type ProcurementItemRequest struct {
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	Supplier  string  `json:"supplier"`
+	UnitPrice float64 `json:"unitPrice"`
+}
+
+// ProcurementWindowRequest represents the request body for opening a
+// procurement window
+type ProcurementWindowRequest struct {
+	Title    string                   `json:"title"`
+	Notes    string                   `json:"notes"`
+	ClosesAt time.Time                `json:"closesAt"`
+	Items    []ProcurementItemRequest `json:"items"`
+}
+
+// ProcurementDemandLineRequest is the quantity of one item a member puts in for
+type ProcurementDemandLineRequest struct {
+	ItemID   string  `json:"itemId"`
+	Quantity float64 `json:"quantity"`
+}
+
+// ProcurementDemandRequest represents the request body for a member's demand
+// in a procurement window
+type ProcurementDemandRequest struct {
+	FarmID string                         `json:"farmId"`
+	Lines  []ProcurementDemandLineRequest `json:"lines"`
+}
+
+// ProcurementDeliveryRequest represents the request body for recording the
+// delivery of an allocation
+type ProcurementDeliveryRequest struct {
+	Quantity    float64    `json:"quantity"` // The full allocation if zero
+	DeliveredAt *time.Time `json:"deliveredAt"`
+}
+
+// ProcurementWindowResponse represents the procurement window response
+type ProcurementWindowResponse struct {
+	Success bool                      `json:"success"`
+	Message string                    `json:"message"`
+	Window  *data.ProcurementWindow   `json:"window,omitempty"`
+	Windows []*data.ProcurementWindow `json:"windows,omitempty"`
+}
+
+// ProcurementRequestResponse represents the response for member demand and
+// allocations
+type ProcurementRequestResponse struct {
+	Success  bool                       `json:"success"`
+	Message  string                     `json:"message"`
+	Request  *data.ProcurementRequest   `json:"request,omitempty"`
+	Requests []*data.ProcurementRequest `json:"requests,omitempty"`
+}
+
+// ProcurementOrderResponse represents the consolidated order response
+type ProcurementOrderResponse struct {
+	Success bool                     `json:"success"`
+	Message string                   `json:"message"`
+	Orders  []*data.ProcurementOrder `json:"orders"`
+}
+
+// Validate checks the procurement window request fields
+func (req *ProcurementWindowRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("title", req.Title)
+	v.Check(!req.ClosesAt.IsZero(), "closesAt", "is required")
+	v.Check(len(req.Items) > 0, "items", "must include at least one item")
+	for i, item := range req.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.Required(field+".name", item.Name)
+		v.Required(field+".unit", item.Unit)
+		v.Required(field+".supplier", item.Supplier)
+		v.Check(item.UnitPrice >= 0, field+".unitPrice", "must be >= 0")
+	}
+	return v.Errors()
+}
+
+// Validate checks the procurement demand request fields
+func (req *ProcurementDemandRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("farmId", req.FarmID)
+	for i, line := range req.Lines {
+		field := fmt.Sprintf("lines[%d]", i)
+		v.Required(field+".itemId", line.ItemID)
+		v.Check(line.Quantity > 0, field+".quantity", "must be greater than 0")
+	}
+	return v.Errors()
+}
+
+// Validate checks the procurement delivery request fields
+func (req *ProcurementDeliveryRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Quantity >= 0, "quantity", "must be >= 0")
+	return v.Errors()
+}
+
+// windowInput converts the request to the co-op service input
+func (req *ProcurementWindowRequest) windowInput() coop.WindowInput {
+	in := coop.WindowInput{Title: req.Title, Notes: req.Notes, ClosesAt: req.ClosesAt, Items: make([]coop.ItemInput, len(req.Items))}
+	for i, item := range req.Items {
+		in.Items[i] = coop.ItemInput(item)
+	}
+	return in
+}
+
+// demandInput converts the request to the co-op service input
+func (req *ProcurementDemandRequest) demandInput() coop.DemandInput {
+	in := coop.DemandInput{FarmID: req.FarmID, Lines: make([]coop.DemandLine, len(req.Lines))}
+	for i, line := range req.Lines {
+		in.Lines[i] = coop.DemandLine(line)
+	}
+	return in
+}
+
+// OpenProcurementWindowHandler handles an organization admin opening a window
+// for members to put in for inputs bought as a group
+func (app *Config) OpenProcurementWindowHandler(w http.ResponseWriter, r *http.Request) {
+	var req ProcurementWindowRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	organizationID := resourceID(r)
+	if organizationID == "" {
+		app.errorJSON(w, errors.New("organization ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	window, err := app.Services.Coop.OpenWindow(r.Context(), user, organizationID, req.windowInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementWindowResponse{
+		Success: true,
+		Message: "Procurement window opened successfully",
+		Window:  window,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetProcurementWindowsHandler handles listing an organization's procurement
+// windows, optionally filtered by ?status=
+func (app *Config) GetProcurementWindowsHandler(w http.ResponseWriter, r *http.Request) {
+	organizationID := resourceID(r)
+	if organizationID == "" {
+		app.errorJSON(w, errors.New("organization ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	windows, err := app.Services.Coop.ListWindows(r.Context(), user, organizationID, r.URL.Query().Get("status"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementWindowResponse{
+		Success: true,
+		Message: "Procurement windows retrieved successfully",
+		Windows: windows,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetProcurementWindowHandler handles retrieving a procurement window with
+// its items
+func (app *Config) GetProcurementWindowHandler(w http.ResponseWriter, r *http.Request) {
+	app.procurementWindowAction(w, r, "Procurement window retrieved successfully", app.Services.Coop.GetWindow)
+}
+
+// CloseProcurementWindowHandler handles an organization admin closing a
+// window to further demand ahead of consolidation
+func (app *Config) CloseProcurementWindowHandler(w http.ResponseWriter, r *http.Request) {
+	app.procurementWindowAction(w, r, "Procurement window closed successfully", app.Services.Coop.CloseWindow)
+}
+
+// CancelProcurementWindowHandler handles an organization admin calling off a
+// window that has not been consolidated
+func (app *Config) CancelProcurementWindowHandler(w http.ResponseWriter, r *http.Request) {
+	app.procurementWindowAction(w, r, "Procurement window cancelled successfully", app.Services.Coop.CancelWindow)
+}
+
+// procurementWindowAction runs a co-op service call on the window in the URL
+// and writes the window back
+func (app *Config) procurementWindowAction(w http.ResponseWriter, r *http.Request, message string,
+	action func(context.Context, *data.User, string) (*data.ProcurementWindow, error)) {
+	windowID := resourceID(r)
+	if windowID == "" {
+		app.errorJSON(w, errors.New("procurement window ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	window, err := action(r.Context(), user, windowID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementWindowResponse{
+		Success: true,
+		Message: message,
+		Window:  window,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// SubmitProcurementDemandHandler handles a member putting in, for one of
+// their farms, for items in an open window. The lines replace any demand the
+// member put in before; an empty list withdraws it.
+func (app *Config) SubmitProcurementDemandHandler(w http.ResponseWriter, r *http.Request) {
+	var req ProcurementDemandRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	windowID := resourceID(r)
+	if windowID == "" {
+		app.errorJSON(w, errors.New("procurement window ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := app.Services.Coop.SubmitDemand(r.Context(), user, windowID, req.demandInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementRequestResponse{
+		Success:  true,
+		Message:  "Procurement demand saved successfully",
+		Requests: requests,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetProcurementDemandHandler handles listing the demand in a window: every
+// member's for an admin, only their own for other members
+func (app *Config) GetProcurementDemandHandler(w http.ResponseWriter, r *http.Request) {
+	windowID := resourceID(r)
+	if windowID == "" {
+		app.errorJSON(w, errors.New("procurement window ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := app.Services.Coop.ListDemand(r.Context(), user, windowID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementRequestResponse{
+		Success:  true,
+		Message:  "Procurement demand retrieved successfully",
+		Requests: requests,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ConsolidateProcurementWindowHandler handles an organization admin turning a
+// window's demand into one order per supplier with member allocations
+func (app *Config) ConsolidateProcurementWindowHandler(w http.ResponseWriter, r *http.Request) {
+	app.procurementOrders(w, r, http.StatusCreated, "Procurement window consolidated successfully", app.Services.Coop.Consolidate)
+}
+
+// GetProcurementOrdersHandler handles listing a window's consolidated orders
+// with their member allocations
+func (app *Config) GetProcurementOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	app.procurementOrders(w, r, http.StatusOK, "Procurement orders retrieved successfully", app.Services.Coop.ListOrders)
+}
+
+// procurementOrders runs a co-op service call on the window in the URL and
+// writes its orders back
+func (app *Config) procurementOrders(w http.ResponseWriter, r *http.Request, status int, message string,
+	action func(context.Context, *data.User, string) ([]*data.ProcurementOrder, error)) {
+	windowID := resourceID(r)
+	if windowID == "" {
+		app.errorJSON(w, errors.New("procurement window ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	orders, err := action(r.Context(), user, windowID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementOrderResponse{
+		Success: true,
+		Message: message,
+		Orders:  orders,
+	}
+
+	app.writeJSON(w, status, response)
+}
+
+// DeliverProcurementAllocationHandler handles an organization admin recording
+// that a member's allocation was handed over
+func (app *Config) DeliverProcurementAllocationHandler(w http.ResponseWriter, r *http.Request) {
+	var req ProcurementDeliveryRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	allocationID := resourceID(r)
+	if allocationID == "" {
+		app.errorJSON(w, errors.New("allocation ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := app.Services.Coop.DeliverAllocation(r.Context(), user, allocationID, coop.DeliveryInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProcurementRequestResponse{
+		Success: true,
+		Message: "Allocation delivered successfully",
+		Request: request,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
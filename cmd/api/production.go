@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/production"
+	"net/http"
+	"time"
+)
+
+// ProductionRequest represents the production record creation/update
+// request body, and one entry of a daily sheet sent to the batch endpoint
+type ProductionRequest struct {
+	LivestockID string     `json:"livestockId"` // Herd or flock
+	AnimalTag   string     `json:"animalTag"`   // One animal of the herd, if recorded per animal
+	ProductType string     `json:"productType"` // Milk, Eggs, Wool, Honey, Other
+	Date        *time.Time `json:"date"`        // Defaults to today
+	Quantity    float64    `json:"quantity"`
+	Unit        string     `json:"unit"` // Defaults to Litres for milk, Pieces for eggs and Kg for wool and honey
+	Notes       string     `json:"notes"`
+}
+
+// ProductionResponse represents the production record response
+type ProductionResponse struct {
+	Success bool                     `json:"success"`
+	Message string                   `json:"message"`
+	Record  *data.ProductionRecord   `json:"record,omitempty"`
+	Records []*data.ProductionRecord `json:"records,omitempty"`
+	Trend   *production.Trend        `json:"trend,omitempty"`
+}
+
+// Validate checks the production request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *ProductionRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("livestockId", req.LivestockID)
+		v.Required("productType", req.ProductType)
+		v.Check(req.Quantity > 0, "quantity", "must be greater than 0")
+	}
+	v.OneOf("productType", req.ProductType, production.ProductTypes()...)
+	v.Check(req.Quantity >= 0, "quantity", "must be 0 or more")
+	if req.Date != nil {
+		v.Check(!req.Date.After(time.Now()), "date", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateProductionHandler handles logging a herd's or an animal's production
+func (app *Config) CreateProductionHandler(w http.ResponseWriter, r *http.Request) {
+	var req ProductionRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Production.Create(r.Context(), user, farmID, production.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProductionResponse{
+		Success: true,
+		Message: "Production recorded successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// CreateProductionBatchHandler handles a daily production sheet: every
+// milking or egg collection of the day at once. An entry already recorded
+// for the same herd, animal, product and day is replaced.
+func (app *Config) CreateProductionBatchHandler(w http.ResponseWriter, r *http.Request) {
+	batchCreate(app, w, r, "production records", func(req *ProductionRequest) ValidationErrors { return req.Validate(false) },
+		func(user *data.User, farmID string, reqs []ProductionRequest) ([]*data.ProductionRecord, []error, error) {
+			ins := make([]production.Input, len(reqs))
+			for i, req := range reqs {
+				ins[i] = production.Input(req)
+			}
+			return app.Services.Production.CreateBatch(r.Context(), user, farmID, ins)
+		})
+}
+
+// GetProductionRecordsHandler handles retrieving a farm's production
+// records, optionally filtered by the livestockId, productType, from and to
+// (YYYY-MM-DD) query parameters
+func (app *Config) GetProductionRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := app.productionFilter(w, r)
+	if !ok {
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Production.List(r.Context(), user, farmID, filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProductionResponse{
+		Success: true,
+		Message: "Production records retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetProductionTrendHandler handles totalling a farm's production by
+// ?interval=week (the default) or month, over the last 12 periods unless
+// from and to (YYYY-MM-DD) are given, optionally for one livestockId or
+// productType
+func (app *Config) GetProductionTrendHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := app.productionFilter(w, r)
+	if !ok {
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	trend, err := app.Services.Production.Trend(r.Context(), user, farmID, r.URL.Query().Get("interval"), filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProductionResponse{
+		Success: true,
+		Message: "Production trend retrieved successfully",
+		Trend:   trend,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetProductionRecordHandler handles retrieving a single production record
+// by ID
+func (app *Config) GetProductionRecordHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("production record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Production.Get(r.Context(), user, recordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProductionResponse{
+		Success: true,
+		Message: "Production record retrieved successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateProductionRecordHandler handles correcting a production record
+func (app *Config) UpdateProductionRecordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ProductionRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("production record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Production.Update(r.Context(), user, recordID, production.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProductionResponse{
+		Success: true,
+		Message: "Production record updated successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteProductionRecordHandler handles deleting a production record
+func (app *Config) DeleteProductionRecordHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("production record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Production.Delete(r.Context(), user, recordID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ProductionResponse{
+		Success: true,
+		Message: "Production record deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// productionFilter reads the production filter from the query parameters. On
+// failure the error response has already been written and ok is false.
+func (app *Config) productionFilter(w http.ResponseWriter, r *http.Request) (data.ProductionFilter, bool) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return data.ProductionFilter{}, false
+	}
+	return data.ProductionFilter{
+		LivestockID: r.URL.Query().Get("livestockId"),
+		ProductType: r.URL.Query().Get("productType"),
+		From:        from,
+		To:          to,
+	}, true
+}
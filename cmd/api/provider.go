@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/provider"
+	"net/http"
+)
+
+// ServiceProviderRequest represents the service provider creation/update
+// request body
+type ServiceProviderRequest struct {
+	Name        string `json:"name"`
+	Category    string `json:"category"` // Veterinarian, Mechanic, Transporter, Agronomist, Electrician, Other
+	ContactName string `json:"contactName"`
+	PhoneNumber string `json:"phoneNumber"`
+	Email       string `json:"email"`
+	Address     string `json:"address"`
+	Notes       string `json:"notes"`
+}
+
+// ServiceProviderResponse represents the service provider response
+type ServiceProviderResponse struct {
+	Success   bool                    `json:"success"`
+	Message   string                  `json:"message"`
+	Provider  *data.ServiceProvider   `json:"provider,omitempty"`
+	Providers []*data.ServiceProvider `json:"providers,omitempty"`
+	Jobs      *provider.Jobs          `json:"jobs,omitempty"`
+}
+
+// Validate checks the service provider request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *ServiceProviderRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("category", req.Category)
+	}
+	v.Check(len(req.Name) <= 100, "name", "must be at most 100 characters")
+	if req.Category != "" {
+		v.OneOf("category", req.Category, provider.Categories...)
+	}
+	return v.Errors()
+}
+
+// CreateServiceProviderHandler handles adding a contractor to a farm's
+// directory
+func (app *Config) CreateServiceProviderHandler(w http.ResponseWriter, r *http.Request) {
+	var req ServiceProviderRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := app.Services.Provider.Create(r.Context(), user, farmID, provider.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ServiceProviderResponse{
+		Success:  true,
+		Message:  "Service provider created successfully",
+		Provider: p,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetServiceProvidersHandler handles listing a farm's directory
+// (/api/service-providers?farmId=&category=)
+func (app *Config) GetServiceProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	providers, err := app.Services.Provider.List(r.Context(), user, farmID, r.URL.Query().Get("category"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ServiceProviderResponse{
+		Success:   true,
+		Message:   "Service providers retrieved successfully",
+		Providers: providers,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetServiceProviderHandler handles retrieving a service provider
+func (app *Config) GetServiceProviderHandler(w http.ResponseWriter, r *http.Request) {
+	providerID := resourceID(r)
+	if providerID == "" {
+		app.errorJSON(w, errors.New("service provider ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := app.Services.Provider.Get(r.Context(), user, providerID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ServiceProviderResponse{
+		Success:  true,
+		Message:  "Service provider retrieved successfully",
+		Provider: p,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateServiceProviderHandler handles changing a service provider's details
+func (app *Config) UpdateServiceProviderHandler(w http.ResponseWriter, r *http.Request) {
+	var req ServiceProviderRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	providerID := resourceID(r)
+	if providerID == "" {
+		app.errorJSON(w, errors.New("service provider ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := app.Services.Provider.Update(r.Context(), user, providerID, provider.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ServiceProviderResponse{
+		Success:  true,
+		Message:  "Service provider updated successfully",
+		Provider: p,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteServiceProviderHandler handles removing a service provider from the
+// directory
+func (app *Config) DeleteServiceProviderHandler(w http.ResponseWriter, r *http.Request) {
+	providerID := resourceID(r)
+	if providerID == "" {
+		app.errorJSON(w, errors.New("service provider ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Provider.Delete(r.Context(), user, providerID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ServiceProviderResponse{
+		Success: true,
+		Message: "Service provider deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetServiceProviderJobsHandler handles listing the maintenance and crop
+// incident work a service provider did
+func (app *Config) GetServiceProviderJobsHandler(w http.ResponseWriter, r *http.Request) {
+	providerID := resourceID(r)
+	if providerID == "" {
+		app.errorJSON(w, errors.New("service provider ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := app.Services.Provider.Jobs(r.Context(), user, providerID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ServiceProviderResponse{
+		Success: true,
+		Message: "Service provider jobs retrieved successfully",
+		Jobs:    jobs,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
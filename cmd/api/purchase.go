@@ -0,0 +1,520 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/purchase"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SupplierRequest represents the supplier creation/update request body
+type SupplierRequest struct {
+	Name        string `json:"name"`
+	ContactName string `json:"contactName"`
+	PhoneNumber string `json:"phoneNumber"`
+	Email       string `json:"email"`
+	Address     string `json:"address"`
+	Notes       string `json:"notes"`
+}
+
+// PurchaseOrderLineRequest is one item in a purchase order request
+type PurchaseOrderLineRequest struct {
+	InventoryItemID string  `json:"inventoryItemId"`
+	Quantity        float64 `json:"quantity"`
+	UnitCost        float64 `json:"unitCost"`
+}
+
+// PurchaseOrderRequest represents the purchase order creation/update request body
+type PurchaseOrderRequest struct {
+	SupplierID   string                     `json:"supplierId"`
+	Reference    string                     `json:"reference"`
+	ExpectedDate *time.Time                 `json:"expectedDate"`
+	Notes        string                     `json:"notes"`
+	Lines        []PurchaseOrderLineRequest `json:"lines"`
+}
+
+// PurchaseReceiptLineRequest gives the batch details of one received line
+type PurchaseReceiptLineRequest struct {
+	LineID      string     `json:"lineId"`
+	BatchNumber string     `json:"batchNumber"`
+	ExpiryDate  *time.Time `json:"expiryDate"`
+}
+
+// PurchaseReceiptRequest represents the purchase order receipt request body
+type PurchaseReceiptRequest struct {
+	ReceivedAt *time.Time                   `json:"receivedAt"`
+	Lines      []PurchaseReceiptLineRequest `json:"lines"`
+}
+
+// SupplierResponse represents the supplier response
+type SupplierResponse struct {
+	Success   bool             `json:"success"`
+	Message   string           `json:"message"`
+	Supplier  *data.Supplier   `json:"supplier,omitempty"`
+	Suppliers []*data.Supplier `json:"suppliers,omitempty"`
+}
+
+// PurchaseOrderResponse represents the purchase order response
+type PurchaseOrderResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message"`
+	Order   *data.PurchaseOrder   `json:"order,omitempty"`
+	Orders  []*data.PurchaseOrder `json:"orders,omitempty"`
+}
+
+// Validate checks the supplier request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *SupplierRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+	}
+	v.Email("email", req.Email)
+	return v.Errors()
+}
+
+// Validate checks the purchase order request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *PurchaseOrderRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("supplierId", req.SupplierID)
+		v.Check(len(req.Lines) > 0, "lines", "must include at least one line")
+	}
+	for i, line := range req.Lines {
+		field := fmt.Sprintf("lines[%d]", i)
+		v.Required(field+".inventoryItemId", line.InventoryItemID)
+		v.Check(line.Quantity > 0, field+".quantity", "must be greater than 0")
+		v.Check(line.UnitCost >= 0, field+".unitCost", "must be >= 0")
+	}
+	return v.Errors()
+}
+
+// Validate checks the purchase order receipt request fields
+func (req *PurchaseReceiptRequest) Validate() ValidationErrors {
+	v := newValidator()
+	for i, line := range req.Lines {
+		v.Required(fmt.Sprintf("lines[%d].lineId", i), line.LineID)
+	}
+	return v.Errors()
+}
+
+// orderInput converts the request to the purchase service input, keeping a
+// missing lines list nil so an update leaves the lines unchanged
+func (req *PurchaseOrderRequest) orderInput() purchase.OrderInput {
+	in := purchase.OrderInput{
+		SupplierID:   req.SupplierID,
+		Reference:    req.Reference,
+		ExpectedDate: req.ExpectedDate,
+		Notes:        req.Notes,
+	}
+	if req.Lines != nil {
+		in.Lines = make([]purchase.LineInput, len(req.Lines))
+		for i, line := range req.Lines {
+			in.Lines[i] = purchase.LineInput(line)
+		}
+	}
+	return in
+}
+
+// receiptInput converts the request to the purchase service input
+func (req *PurchaseReceiptRequest) receiptInput() purchase.ReceiptInput {
+	in := purchase.ReceiptInput{ReceivedAt: req.ReceivedAt, Lines: make([]purchase.ReceiptLineInput, len(req.Lines))}
+	for i, line := range req.Lines {
+		in.Lines[i] = purchase.ReceiptLineInput(line)
+	}
+	return in
+}
+
+// CreateSupplierHandler handles adding a supplier to a farm
+func (app *Config) CreateSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	var req SupplierRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	supplier, err := app.Services.Purchase.CreateSupplier(r.Context(), user, farmID, purchase.SupplierInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SupplierResponse{
+		Success:  true,
+		Message:  "Supplier created successfully",
+		Supplier: supplier,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetSuppliersHandler handles retrieving a farm's suppliers
+func (app *Config) GetSuppliersHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	suppliers, err := app.Services.Purchase.ListSuppliers(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SupplierResponse{
+		Success:   true,
+		Message:   "Suppliers retrieved successfully",
+		Suppliers: suppliers,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSupplierHandler handles retrieving a single supplier by ID
+func (app *Config) GetSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	supplierID := resourceID(r)
+	if supplierID == "" {
+		app.errorJSON(w, errors.New("supplier ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	supplier, err := app.Services.Purchase.GetSupplier(r.Context(), user, supplierID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SupplierResponse{
+		Success:  true,
+		Message:  "Supplier retrieved successfully",
+		Supplier: supplier,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateSupplierHandler handles supplier updates
+func (app *Config) UpdateSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	var req SupplierRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	supplierID := resourceID(r)
+	if supplierID == "" {
+		app.errorJSON(w, errors.New("supplier ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	supplier, err := app.Services.Purchase.UpdateSupplier(r.Context(), user, supplierID, purchase.SupplierInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SupplierResponse{
+		Success:  true,
+		Message:  "Supplier updated successfully",
+		Supplier: supplier,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteSupplierHandler handles supplier deletion
+func (app *Config) DeleteSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	supplierID := resourceID(r)
+	if supplierID == "" {
+		app.errorJSON(w, errors.New("supplier ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Purchase.DeleteSupplier(r.Context(), user, supplierID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SupplierResponse{
+		Success: true,
+		Message: "Supplier deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CreatePurchaseOrderHandler handles drafting a purchase order
+func (app *Config) CreatePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req PurchaseOrderRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := app.Services.Purchase.CreateOrder(r.Context(), user, farmID, req.orderInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: "Purchase order created successfully",
+		Order:   order,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetPurchaseOrdersHandler handles retrieving a farm's purchase orders,
+// optionally by ?status=
+func (app *Config) GetPurchaseOrdersHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", purchase.StatusDraft, purchase.StatusOrdered, purchase.StatusReceived, purchase.StatusCancelled:
+	default:
+		app.errorJSON(w, errors.New("status must be Draft, Ordered, Received or Cancelled"), http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	orders, err := app.Services.Purchase.ListOrders(r.Context(), user, farmID, status)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: "Purchase orders retrieved successfully",
+		Orders:  orders,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetPurchaseOrderHandler handles retrieving a single purchase order by ID
+func (app *Config) GetPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	orderID := resourceID(r)
+	if orderID == "" {
+		app.errorJSON(w, errors.New("purchase order ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := app.Services.Purchase.GetOrder(r.Context(), user, orderID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: "Purchase order retrieved successfully",
+		Order:   order,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdatePurchaseOrderHandler handles changes to a draft purchase order
+func (app *Config) UpdatePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req PurchaseOrderRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	orderID := resourceID(r)
+	if orderID == "" {
+		app.errorJSON(w, errors.New("purchase order ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := app.Services.Purchase.UpdateOrder(r.Context(), user, orderID, req.orderInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: "Purchase order updated successfully",
+		Order:   order,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeletePurchaseOrderHandler handles deleting a draft or cancelled purchase order
+func (app *Config) DeletePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	orderID := resourceID(r)
+	if orderID == "" {
+		app.errorJSON(w, errors.New("purchase order ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Purchase.DeleteOrder(r.Context(), user, orderID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: "Purchase order deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// PlacePurchaseOrderHandler handles marking a draft order as sent to the supplier
+func (app *Config) PlacePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	app.purchaseOrderAction(w, r, app.Services.Purchase.PlaceOrder, "Purchase order placed successfully")
+}
+
+// CancelPurchaseOrderHandler handles cancelling a draft or placed order
+func (app *Config) CancelPurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	app.purchaseOrderAction(w, r, app.Services.Purchase.CancelOrder, "Purchase order cancelled successfully")
+}
+
+// purchaseOrderAction runs a status change that takes no request body
+func (app *Config) purchaseOrderAction(w http.ResponseWriter, r *http.Request, action func(context.Context, *data.User, string) (*data.PurchaseOrder, error), message string) {
+	orderID := resourceID(r)
+	if orderID == "" {
+		app.errorJSON(w, errors.New("purchase order ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := action(r.Context(), user, orderID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: message,
+		Order:   order,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ReceivePurchaseOrderHandler handles taking delivery of a placed order,
+// which puts its lines into inventory. The body is optional.
+func (app *Config) ReceivePurchaseOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req PurchaseReceiptRequest
+
+	if r.ContentLength != 0 {
+		if err := app.ReadJSON(w, r, &req); err != nil {
+			app.errorJSON(w, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	orderID := resourceID(r)
+	if orderID == "" {
+		app.errorJSON(w, errors.New("purchase order ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	order, err := app.Services.Purchase.ReceiveOrder(r.Context(), user, orderID, req.receiptInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PurchaseOrderResponse{
+		Success: true,
+		Message: "Purchase order received into inventory successfully",
+		Order:   order,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
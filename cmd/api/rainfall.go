@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/rainfall"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RainfallRequest represents the rain gauge reading creation/update request body
+type RainfallRequest struct {
+	FieldID *string    `json:"fieldId"` // Empty string clears
+	Gauge   string     `json:"gauge"`   // Defaults to the field's name, or "Main"
+	Date    *time.Time `json:"date"`
+	MM      *float64   `json:"mm"`
+	Notes   string     `json:"notes"`
+}
+
+// RainfallResponse represents the rain gauge reading response
+type RainfallResponse struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Record  *data.RainfallRecord   `json:"record,omitempty"`
+	Records []*data.RainfallRecord `json:"records,omitempty"`
+	Summary *rainfall.Summary      `json:"summary,omitempty"`
+}
+
+// Validate checks the rainfall request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *RainfallRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Check(req.Date != nil, "date", "is required")
+		v.Check(req.MM != nil, "mm", "is required")
+	}
+	if req.Date != nil {
+		v.Check(!req.Date.After(time.Now()), "date", "cannot be in the future")
+	}
+	if req.MM != nil {
+		v.Check(*req.MM >= 0 && *req.MM <= 1000, "mm", "must be between 0 and 1000")
+	}
+	return v.Errors()
+}
+
+// CreateRainfallHandler handles recording a rain gauge reading on a farm
+func (app *Config) CreateRainfallHandler(w http.ResponseWriter, r *http.Request) {
+	var req RainfallRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Rainfall.Create(r.Context(), user, farmID, rainfall.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := RainfallResponse{
+		Success: true,
+		Message: "Rainfall recorded successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetRainfallRecordsHandler handles retrieving a farm's rain gauge readings,
+// optionally of one gauge and between the from and to query parameters
+func (app *Config) GetRainfallRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Rainfall.List(r.Context(), user, farmID, r.URL.Query().Get("gauge"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := RainfallResponse{
+		Success: true,
+		Message: "Rainfall records retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetRainfallSummaryHandler handles a farm's monthly and seasonal rainfall
+// totals for the year query parameter (default: this year), compared with the
+// weather provider's
+func (app *Config) GetRainfallSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1900 || y > 9999 {
+			app.errorJSON(w, errors.New("year must be a valid year"), http.StatusBadRequest)
+			return
+		}
+		year = y
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := app.Services.Rainfall.Summary(r.Context(), user, farmID, year)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := RainfallResponse{
+		Success: true,
+		Message: "Rainfall summary retrieved successfully",
+		Summary: summary,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetRainfallHandler handles retrieving a single rain gauge reading by ID
+func (app *Config) GetRainfallHandler(w http.ResponseWriter, r *http.Request) {
+	rainfallID := resourceID(r)
+	if rainfallID == "" {
+		app.errorJSON(w, errors.New("rainfall ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Rainfall.Get(r.Context(), user, rainfallID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := RainfallResponse{
+		Success: true,
+		Message: "Rainfall record retrieved successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateRainfallHandler handles correcting a rain gauge reading
+func (app *Config) UpdateRainfallHandler(w http.ResponseWriter, r *http.Request) {
+	var req RainfallRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	rainfallID := resourceID(r)
+	if rainfallID == "" {
+		app.errorJSON(w, errors.New("rainfall ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Rainfall.Update(r.Context(), user, rainfallID, rainfall.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := RainfallResponse{
+		Success: true,
+		Message: "Rainfall record updated successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteRainfallHandler handles deleting a rain gauge reading
+func (app *Config) DeleteRainfallHandler(w http.ResponseWriter, r *http.Request) {
+	rainfallID := resourceID(r)
+	if rainfallID == "" {
+		app.errorJSON(w, errors.New("rainfall ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Rainfall.Delete(r.Context(), user, rainfallID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := RainfallResponse{
+		Success: true,
+		Message: "Rainfall record deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
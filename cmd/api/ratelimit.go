@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"farm4u/service/auth"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultAPIRateLimit is the number of requests a client may make per
+// apiRateWindow unless API_RATE_LIMIT is set
+const (
+	defaultAPIRateLimit = 1000
+	apiRateWindow       = time.Hour
+)
+
+// Defaults for the stricter limits on login and password reset endpoints,
+// overridable with AUTH_RATE_LIMIT_IP, AUTH_RATE_LIMIT_EMAIL and AUTH_RATE_WINDOW
+const (
+	defaultAuthIPLimit    = 20
+	defaultAuthEmailLimit = 5
+	defaultAuthRateWindow = 15 * time.Minute
+)
+
+// rateRule is a number of requests allowed per window
+type rateRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// authRateRules holds the per-IP and per-email limits for auth endpoints
+type authRateRules struct {
+	PerIP    rateRule
+	PerEmail rateRule
+}
+
+// RateLimitStore counts hits per key in fixed windows
+type RateLimitStore interface {
+	// Increment records a hit for key and returns the hit count in the
+	// current window and when that window resets
+	Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+// rateWindow is one key's counter in the in-memory store
+type rateWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+// memoryRateLimitStore is a RateLimitStore for a single API instance
+type memoryRateLimitStore struct {
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+// newMemoryRateLimitStore creates an empty in-memory store
+func newMemoryRateLimitStore() *memoryRateLimitStore {
+	return &memoryRateLimitStore{windows: map[string]*rateWindow{}, lastSweep: time.Now()}
+}
+
+// Increment implements RateLimitStore
+func (s *memoryRateLimitStore) Increment(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	// Drop expired windows now and then so the map does not grow unbounded
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, w := range s.windows {
+			if !now.Before(w.resetAt) {
+				delete(s.windows, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &rateWindow{resetAt: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.count++
+
+	return w.count, w.resetAt, nil
+}
+
+// newRateLimitStore builds the store selected by RATE_LIMIT_STORE: "memory"
+// (default, per instance) or "redis" (shared, configured by REDIS_URL)
+func newRateLimitStore(cfg *AppConfig) (RateLimitStore, error) {
+	switch cfg.RateLimitStore {
+	case "memory":
+		return newMemoryRateLimitStore(), nil
+	case "redis":
+		return newRedisRateLimitStore(cfg.RedisURL)
+	default:
+		return nil, errors.New("RATE_LIMIT_STORE must be memory or redis, got " + cfg.RateLimitStore)
+	}
+}
+
+// clientIP returns the remote address of the request without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// tokenClaims returns the claims of a valid bearer token on the request, if any
+func (app *Config) tokenClaims(r *http.Request) *Claims {
+	tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || tokenString == "" {
+		return nil
+	}
+	claims, err := app.ValidateJWT(tokenString)
+	if err != nil {
+		return nil
+	}
+	return claims
+}
+
+// setRateLimitHeaders writes the X-RateLimit-* headers for a window
+func setRateLimitHeaders(w http.ResponseWriter, limit, count int, resetAt time.Time) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(max(limit-count, 0)))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+}
+
+// rejectRateLimited writes a 429 response telling the client when to retry
+func (app *Config) rejectRateLimited(w http.ResponseWriter, resetAt time.Time) {
+	retryAfter := int(time.Until(resetAt).Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	app.errorJSON(w, errors.New("rate limit exceeded, try again later"), http.StatusTooManyRequests)
+}
+
+// RateLimit limits each client, identified by user for authenticated requests
+// and by IP otherwise, to app.APIRateLimit requests per hour. Every response
+// carries X-RateLimit-* headers and calls by authenticated users are counted
+// for the usage report. The health probes are not limited, so an
+// orchestrator polling them is never turned away.
+func (app *Config) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims := app.tokenClaims(r)
+
+		key := "api:ip:" + clientIP(r)
+		if claims != nil {
+			key = "api:user:" + strconv.Itoa(claims.UserID)
+		}
+
+		count, resetAt, err := app.RateLimiter.Increment(r.Context(), key, apiRateWindow)
+		if err != nil {
+			// Fail open: a broken limiter store must not take the API down
+			app.ErrorLog.Printf("Error checking rate limit: %v", err)
+		} else {
+			setRateLimitHeaders(w, app.APIRateLimit, count, resetAt)
+			if count > app.APIRateLimit {
+				app.rejectRateLimited(w, resetAt)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+
+		if claims != nil {
+			app.APIUsage.record(claims.UserID, r)
+		}
+	})
+}
+
+// AuthRateLimit applies the stricter auth limits to a login or password reset
+// endpoint: one counter per client IP and one per email address or phone
+// number in the request body, so neither many accounts from one address nor
+// one account from many addresses can be hammered. Rejected requests get 429 with
+// Retry-After and the X-RateLimit-* headers of the limit that was hit.
+func (app *Config) AuthRateLimit(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpoint := r.URL.Path
+
+		type check struct {
+			key  string
+			rule rateRule
+		}
+		checks := []check{{"auth:ip:" + endpoint + ":" + clientIP(r), app.AuthRateLimits.PerIP}}
+		if account := requestAccount(r); account != "" {
+			checks = append(checks, check{"auth:" + account + ":" + endpoint, app.AuthRateLimits.PerEmail})
+		}
+
+		for _, check := range checks {
+			count, resetAt, err := app.RateLimiter.Increment(r.Context(), check.key, check.rule.Window)
+			if err != nil {
+				app.ErrorLog.Printf("Error checking auth rate limit: %v", err)
+				continue
+			}
+			if count > check.rule.Limit {
+				setRateLimitHeaders(w, check.rule.Limit, count, resetAt)
+				app.rejectRateLimited(w, resetAt)
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
+
+// requestAccount peeks at the email or phoneNumber field of a JSON request
+// body, leaving the body intact for the handler, and returns it as a key
+// such as "email:a@b.c". It returns "" if there is neither.
+func requestAccount(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var payload struct {
+		Email       string `json:"email"`
+		PhoneNumber string `json:"phoneNumber"`
+	}
+	if json.Unmarshal(body, &payload) != nil {
+		return ""
+	}
+	if email := strings.ToLower(strings.TrimSpace(payload.Email)); email != "" {
+		return "email:" + email
+	}
+	if phone, ok := auth.NormalizePhone(payload.PhoneNumber); ok {
+		return "phone:" + phone
+	}
+	return ""
+}
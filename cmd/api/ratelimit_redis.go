@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisIncrementScript increments a counter, starts its expiry on the first
+// hit of a window, and returns the count with the remaining TTL in ms
+var redisIncrementScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {count, redis.call('PTTL', KEYS[1])}
+`)
+
+// redisRateLimitStore is a RateLimitStore shared by every API instance
+type redisRateLimitStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// newRedisRateLimitStore connects to the Redis server at url
+// (redis://[:password@]host:port/db)
+func newRedisRateLimitStore(url string) (*redisRateLimitStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connecting to redis: %w", err)
+	}
+
+	return &redisRateLimitStore{client: client, prefix: "farm4u:ratelimit:"}, nil
+}
+
+// Increment implements RateLimitStore
+func (s *redisRateLimitStore) Increment(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	result, err := redisIncrementScript.Run(ctx, s.client, []string{s.prefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	count, ttl := result[0], result[1]
+	if ttl < 0 {
+		ttl = window.Milliseconds()
+	}
+
+	return int(count), time.Now().Add(time.Duration(ttl) * time.Millisecond), nil
+}
+
+// Close closes the Redis connection pool
+func (s *redisRateLimitStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/report"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ReportJobResponse represents the PDF report response
+type ReportJobResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Report  *data.ReportJob   `json:"report,omitempty"`
+	Reports []*data.ReportJob `json:"reports,omitempty"`
+	// StatusURL is where to poll a queued report until it is Ready
+	StatusURL string `json:"statusUrl,omitempty"`
+	// DownloadURL is where to fetch a Ready report's PDF
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
+
+// reportJobResponse builds the response for one report job, pointing at
+// where to poll or download it
+func reportJobResponse(message string, job *data.ReportJob) ReportJobResponse {
+	response := ReportJobResponse{
+		Success:   true,
+		Message:   message,
+		Report:    job,
+		StatusURL: "/api/v1/reports/jobs/" + job.ReportJobID,
+	}
+	if job.Status == report.StatusReady {
+		response.DownloadURL = response.StatusURL + "/download"
+	}
+	return response
+}
+
+// PortfolioResponse represents the multi-farm portfolio report response
+type PortfolioResponse struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message"`
+	Portfolio *report.Portfolio `json:"portfolio"`
+}
+
+// GetPortfolioReportHandler handles rolling up livestock, crop acreage,
+// revenue and expenses across every farm the authenticated user owns, with a
+// breakdown per farm. ?from=/?to= limit revenue and expenses.
+func (app *Config) GetPortfolioReportHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	portfolio, err := app.Services.Report.Portfolio(r.Context(), user, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := PortfolioResponse{
+		Success:   true,
+		Message:   "Portfolio report generated successfully",
+		Portfolio: portfolio,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RequestReportHandler handles requesting a PDF report of a farm
+// (/api/reports/{type}?farmId=&period=, where type is farm-summary, payroll,
+// livestock-health or harvest and period is YYYY, YYYY-Qn or YYYY-MM; give
+// seasonId= instead to report on one of the farm's seasons). The report is
+// generated in the background; poll the status URL until it is Ready, then
+// download it.
+func (app *Config) RequestReportHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	var job *data.ReportJob
+	var err error
+	if seasonID := r.URL.Query().Get("seasonId"); seasonID != "" {
+		job, err = app.Services.Report.RequestSeason(r.Context(), user, farmID, chi.URLParam(r, "type"), seasonID)
+	} else {
+		job, err = app.Services.Report.Request(r.Context(), user, farmID, chi.URLParam(r, "type"), r.URL.Query().Get("period"))
+	}
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	if job.Status == report.StatusPending {
+		app.background(func() { app.generateReport(job.ReportJobID) })
+	}
+
+	response := reportJobResponse("Report is being generated", job)
+	w.Header().Set("Location", response.StatusURL)
+	app.writeJSON(w, http.StatusAccepted, response)
+}
+
+// GetReportJobsHandler handles listing a farm's PDF reports
+func (app *Config) GetReportJobsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	jobs, err := app.Services.Report.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ReportJobResponse{
+		Success: true,
+		Message: "Reports retrieved successfully",
+		Reports: jobs,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetReportJobHandler handles polling a PDF report's status
+func (app *Config) GetReportJobHandler(w http.ResponseWriter, r *http.Request) {
+	reportID := resourceID(r)
+	if reportID == "" {
+		app.errorJSON(w, errors.New("report ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := app.Services.Report.Get(r.Context(), user, reportID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, reportJobResponse("Report retrieved successfully", job))
+}
+
+// DownloadReportHandler handles downloading a Ready report's PDF
+func (app *Config) DownloadReportHandler(w http.ResponseWriter, r *http.Request) {
+	reportID := resourceID(r)
+	if reportID == "" {
+		app.errorJSON(w, errors.New("report ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	file, job, err := app.Services.Report.Open(r.Context(), user, reportID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	defer file.Close()
+
+	filename := fmt.Sprintf("%s-%s.pdf", job.Type, job.Period)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.FormatInt(job.Size, 10))
+	if _, err := io.Copy(w, file); err != nil {
+		app.ErrorLog.Printf("Error sending report %s: %v", job.ReportJobID, err)
+	}
+}
+
+// DeleteReportJobHandler handles deleting a PDF report
+func (app *Config) DeleteReportJobHandler(w http.ResponseWriter, r *http.Request) {
+	reportID := resourceID(r)
+	if reportID == "" {
+		app.errorJSON(w, errors.New("report ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Report.Delete(r.Context(), user, reportID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ReportJobResponse{
+		Success: true,
+		Message: "Report deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -10,66 +10,769 @@ import (
 
 func (app *Config) routes() http.Handler {
 	mux := chi.NewRouter()
+	mux.Use(app.RequestLogger)
+	mux.Use(middleware.Recoverer)
 	//specify who is allowed to connect
 	mux.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Request-ID", "X-API-Key"},
+		ExposedHeaders:   []string{"Link", "Deprecation", "X-Request-ID", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 	mux.Use(middleware.Heartbeat("/ping"))
+	mux.Use(app.RateLimit)
 
-	// Health check endpoint
-	mux.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
-	})
+	// Kubernetes-style probes: /healthz says the process is up, /readyz that
+	// it can serve requests (see health.go)
+	mux.Get("/healthz", app.LivenessHandler)
+	mux.Get("/readyz", app.ReadinessHandler)
+
+	// Versioned API. /api/v1 is current; the unversioned /api paths serve the
+	// same routes so older clients keep working, with every response marked
+	// deprecated and pointing at its /api/v1 successor.
+	mux.Mount("/api/v1", app.apiRoutes())
+	mux.With(app.Deprecated("/api", "/api/v1")).Mount("/api", app.apiRoutes())
+
+	return mux
+}
+
+// apiRoutes builds the API's routes, relative to the prefix they are mounted
+// under
+func (app *Config) apiRoutes() http.Handler {
+	api := chi.NewRouter()
 
 	// Authentication routes
-	mux.Route("/api/auth", func(r chi.Router) {
+	api.Route("/auth", func(r chi.Router) {
 		r.Post("/signup", app.SignupHandler)
-		r.Post("/login", app.LoginHandler)
-		r.Post("/forgot-password", app.ForgotPasswordHandler)
-		r.Post("/reset-password", app.ResetPasswordHandler)
+		r.Post("/login", app.AuthRateLimit(app.LoginHandler))
+		r.Post("/phone/request", app.AuthRateLimit(app.PhoneLoginHandler))
+		r.Post("/phone/verify", app.AuthRateLimit(app.PhoneVerifyHandler))
+		r.Post("/forgot-password", app.AuthRateLimit(app.ForgotPasswordHandler))
+		r.Post("/reset-password", app.AuthRateLimit(app.ResetPasswordHandler))
 		r.Post("/refresh-token", app.JWTMiddleware(app.RefreshTokenHandler))
+		r.Post("/logout", app.JWTMiddleware(app.LogoutHandler))
+	})
+
+	// Current user routes
+	api.Get("/me", app.JWTMiddleware(app.GetMeHandler))
+	api.Route("/users/me", func(r chi.Router) {
+		r.Post("/password", app.JWTMiddleware(app.ChangePasswordHandler))
+		r.Get("/api-usage", app.JWTMiddleware(app.GetMyAPIUsageHandler))
+		r.Get("/dashboard", app.JWTMiddleware(app.GetDashboardLayoutHandler))
+		r.Put("/dashboard", app.JWTMiddleware(app.SaveDashboardLayoutHandler))
+		r.Delete("/dashboard", app.JWTMiddleware(app.ResetDashboardLayoutHandler))
+	})
+
+	// Account export routes (protected with JWT middleware): a ZIP of all of
+	// the user's data, bundled in the background
+	api.Route("/me/export", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.RequestExportHandler))
+		r.Get("/", app.JWTMiddleware(app.GetExportJobsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetExportJobHandler))
+		r.Get("/{id}/download", app.JWTMiddleware(app.DownloadExportHandler))
+	})
+
+	// Employee self-service routes, scoped to the employee records linked to
+	// the authenticated user rather than to farm ownership
+	api.Route("/me/employment", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetMyEmploymentHandler))
+		r.Get("/{id}/attendance", app.JWTMiddleware(app.GetMyAttendanceHandler))
+		r.Get("/{id}/payslips", app.JWTMiddleware(app.GetMyPayslipsHandler))
+	})
+
+	// Admin routes (protected with admin middleware)
+	api.Route("/admin", func(r chi.Router) {
+		r.Get("/stats", app.AdminMiddleware(app.AdminStatsHandler))
+		r.Get("/notifications/health", app.AdminMiddleware(app.AdminNotificationHealthHandler))
+		r.Get("/users", app.AdminMiddleware(app.AdminListUsersHandler))
+		r.Get("/users/{id}", app.AdminMiddleware(app.AdminGetUserHandler))
+		r.Put("/users/{id}/role", app.AdminMiddleware(app.AdminSetUserRoleHandler))
+		r.Post("/users/{id}/deactivate", app.AdminMiddleware(app.AdminDeactivateUserHandler))
+		r.Post("/users/{id}/reactivate", app.AdminMiddleware(app.AdminReactivateUserHandler))
+		r.Post("/users/{id}/reset-password", app.AdminMiddleware(app.AdminResetPasswordHandler))
+		r.Post("/users/{id}/impersonate", app.AdminMiddleware(app.AdminImpersonateUserHandler))
+		r.Get("/buyer-verifications", app.AdminMiddleware(app.AdminListBuyerVerificationsHandler))
+		r.Post("/buyer-verifications/{id}/approve", app.AdminMiddleware(app.AdminApproveBuyerHandler))
+		r.Post("/buyer-verifications/{id}/reject", app.AdminMiddleware(app.AdminRejectBuyerHandler))
+		r.Get("/disputes", app.AdminMiddleware(app.AdminListDisputesHandler))
+		r.Post("/disputes/{id}/review", app.AdminMiddleware(app.AdminReviewDisputeHandler))
+		r.Post("/disputes/{id}/resolve", app.AdminMiddleware(app.AdminResolveDisputeHandler))
+		r.Get("/escrows", app.AdminMiddleware(app.AdminListEscrowsHandler))
+		r.Post("/escrows/{id}/release", app.AdminMiddleware(app.AdminReleaseEscrowHandler))
+		r.Post("/escrows/{id}/refund", app.AdminMiddleware(app.RefundEscrowHandler))
+		r.Post("/market-prices", app.AdminMiddleware(app.AdminUploadMarketPricesHandler))
 	})
 
 	// Farm routes (protected with JWT middleware)
-	mux.Route("/api/farms", func(r chi.Router) {
+	api.Route("/farms", func(r chi.Router) {
 		r.Post("/", app.JWTMiddleware(app.CreateFarmHandler))
 		r.Get("/", app.JWTMiddleware(app.GetFarmsHandler))
+		r.Get("/trash", app.JWTMiddleware(app.GetDeletedFarmsHandler))
+		r.Get("/archived", app.JWTMiddleware(app.GetArchivedFarmsHandler))
+		r.Get("/shared", app.JWTMiddleware(app.GetSharedFarmsHandler))
 		r.Get("/{id}", app.JWTMiddleware(app.GetFarmHandler))
 		r.Put("/{id}", app.JWTMiddleware(app.UpdateFarmHandler))
 		r.Delete("/{id}", app.JWTMiddleware(app.DeleteFarmHandler))
+		r.Post("/{id}/restore", app.JWTMiddleware(app.RestoreFarmHandler))
+		r.Post("/{id}/archive", app.JWTMiddleware(app.ArchiveFarmHandler))
+		r.Post("/{id}/unarchive", app.JWTMiddleware(app.UnarchiveFarmHandler))
+		r.Post("/{id}/transfer", app.JWTMiddleware(app.TransferFarmHandler))
+		r.Post("/{id}/clone", app.JWTMiddleware(app.CloneFarmHandler))
+		r.Post("/{id}/members", app.JWTMiddleware(app.AddFarmMemberHandler))
+		r.Get("/{id}/members", app.JWTMiddleware(app.GetFarmMembersHandler))
+		r.Get("/{id}/activity", app.JWTMiddleware(app.GetFarmActivityHandler))
+		r.Post("/{id}/webhooks/{webhookID}/replay", app.JWTMiddleware(app.ReplayWebhookDeliveriesHandler))
+	})
+
+	// Farm member routes (protected with JWT middleware)
+	api.Route("/farm-members", func(r chi.Router) {
+		r.Delete("/{id}", app.JWTMiddleware(app.RemoveFarmMemberHandler))
+	})
+
+	// Co-operative routes (protected with JWT middleware)
+	api.Route("/organizations", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateOrganizationHandler))
+		r.Get("/", app.JWTMiddleware(app.GetOrganizationsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetOrganizationHandler))
+		r.Post("/{id}/members", app.JWTMiddleware(app.AddOrganizationMemberHandler))
+		r.Post("/{id}/procurement-windows", app.JWTMiddleware(app.OpenProcurementWindowHandler))
+		r.Get("/{id}/procurement-windows", app.JWTMiddleware(app.GetProcurementWindowsHandler))
+	})
+
+	api.Route("/organization-members", func(r chi.Router) {
+		r.Delete("/{id}", app.JWTMiddleware(app.RemoveOrganizationMemberHandler))
+	})
+
+	api.Route("/procurement-windows", func(r chi.Router) {
+		r.Get("/{id}", app.JWTMiddleware(app.GetProcurementWindowHandler))
+		r.Post("/{id}/close", app.JWTMiddleware(app.CloseProcurementWindowHandler))
+		r.Post("/{id}/cancel", app.JWTMiddleware(app.CancelProcurementWindowHandler))
+		r.Put("/{id}/demand", app.JWTMiddleware(app.SubmitProcurementDemandHandler))
+		r.Get("/{id}/demand", app.JWTMiddleware(app.GetProcurementDemandHandler))
+		r.Post("/{id}/consolidate", app.JWTMiddleware(app.ConsolidateProcurementWindowHandler))
+		r.Get("/{id}/orders", app.JWTMiddleware(app.GetProcurementOrdersHandler))
+	})
+
+	api.Route("/procurement-allocations", func(r chi.Router) {
+		r.Post("/{id}/deliver", app.JWTMiddleware(app.DeliverProcurementAllocationHandler))
+	})
+
+	// Milk collection center routes (protected with JWT middleware)
+	api.Route("/collection-centers", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateCollectionCenterHandler))
+		r.Get("/", app.JWTMiddleware(app.GetCollectionCentersHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetCollectionCenterHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateCollectionCenterHandler))
+		r.Post("/{id}/rotate-key", app.JWTMiddleware(app.RotateCollectionCenterKeyHandler))
+		r.Post("/{id}/suppliers", app.JWTMiddleware(app.LinkMilkSupplierHandler))
+		r.Get("/{id}/suppliers", app.JWTMiddleware(app.GetMilkSuppliersHandler))
+	})
+
+	api.Route("/milk-suppliers", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetFarmCollectionCentersHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.UnlinkMilkSupplierHandler))
+	})
+
+	api.Route("/milk-deliveries", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetMilkDeliveriesHandler))
+	})
+
+	api.Route("/milk-statements", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetMilkStatementsHandler))
+	})
+
+	// Collection center system routes (protected with the center's API key)
+	api.Route("/collection", func(r chi.Router) {
+		r.Post("/deliveries", app.CollectionCenterMiddleware(app.PostCollectionDeliveryHandler))
+		r.Get("/deliveries", app.CollectionCenterMiddleware(app.GetCollectionDeliveriesHandler))
+		r.Get("/statements", app.CollectionCenterMiddleware(app.GetCollectionStatementsHandler))
+	})
+
+	// Field routes (protected with JWT middleware)
+	api.Route("/fields", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateFieldHandler))
+		r.Get("/", app.JWTMiddleware(app.GetFieldsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetFieldHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateFieldHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteFieldHandler))
+		r.Get("/{id}/rotation", app.JWTMiddleware(app.GetFieldRotationHandler))
 	})
 
 	// Crop routes (protected with JWT middleware)
-	mux.Route("/api/crops", func(r chi.Router) {
+	api.Route("/crops", func(r chi.Router) {
 		r.Post("/", app.JWTMiddleware(app.CreateCropHandler))
+		r.Post("/batch", app.JWTMiddleware(app.CreateCropsBatchHandler))
+		r.Patch("/bulk-status", app.JWTMiddleware(app.UpdateCropsStatusHandler))
 		r.Get("/", app.JWTMiddleware(app.GetCropsHandler))
+		r.Get("/all", app.JWTMiddleware(app.GetAllCropsHandler))
+		r.Get("/stats", app.JWTMiddleware(app.GetCropStatsHandler))
+		r.Get("/trash", app.JWTMiddleware(app.GetDeletedCropsHandler))
 		r.Get("/{id}", app.JWTMiddleware(app.GetCropHandler))
 		r.Put("/{id}", app.JWTMiddleware(app.UpdateCropHandler))
+		r.Patch("/{id}", app.JWTMiddleware(app.PatchCropHandler))
 		r.Delete("/{id}", app.JWTMiddleware(app.DeleteCropHandler))
+		r.Get("/{id}/history", app.JWTMiddleware(app.GetCropHistoryHandler))
+		r.Post("/{id}/restore", app.JWTMiddleware(app.RestoreCropHandler))
+	})
+
+	// Crop plan routes (protected with JWT middleware)
+	api.Route("/crop-plans", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateCropPlanHandler))
+		r.Get("/", app.JWTMiddleware(app.GetCropPlansHandler))
+		r.Get("/calendar", app.JWTMiddleware(app.GetSeasonCalendarHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetCropPlanHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateCropPlanHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteCropPlanHandler))
+		r.Post("/{id}/scenarios", app.JWTMiddleware(app.CreatePlanScenarioHandler))
+		r.Get("/{id}/scenarios", app.JWTMiddleware(app.ComparePlanScenariosHandler))
+	})
+
+	// Season routes (protected with JWT middleware)
+	api.Route("/seasons", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateSeasonHandler))
+		r.Get("/", app.JWTMiddleware(app.GetSeasonsHandler))
+		r.Get("/compare", app.JWTMiddleware(app.CompareSeasonsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetSeasonHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateSeasonHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteSeasonHandler))
+		r.Post("/{id}/close", app.JWTMiddleware(app.CloseSeasonHandler))
+		r.Get("/{id}/summary", app.JWTMiddleware(app.GetSeasonSummaryHandler))
+	})
+
+	// What-if scenario routes (protected with JWT middleware)
+	api.Route("/plan-scenarios", func(r chi.Router) {
+		r.Get("/{id}", app.JWTMiddleware(app.GetPlanScenarioHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdatePlanScenarioHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeletePlanScenarioHandler))
+	})
+
+	// Pest and disease incident routes (protected with JWT middleware)
+	api.Route("/crop-incidents", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateCropIncidentHandler))
+		r.Get("/", app.JWTMiddleware(app.GetCropIncidentsHandler))
+		r.Get("/history", app.JWTMiddleware(app.GetCropIncidentHistoryHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetCropIncidentHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateCropIncidentHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteCropIncidentHandler))
+	})
+
+	// Spray record routes (protected with JWT middleware)
+	api.Route("/sprays", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateSprayRecordHandler))
+		r.Get("/", app.JWTMiddleware(app.GetSprayRecordsHandler))
+		r.Get("/withholding", app.JWTMiddleware(app.GetWithholdingHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetSprayRecordHandler))
+	})
+
+	// Irrigation routes (protected with JWT middleware)
+	api.Route("/irrigation", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateIrrigationHandler))
+		r.Get("/", app.JWTMiddleware(app.GetIrrigationsHandler))
+		r.Get("/upcoming", app.JWTMiddleware(app.GetUpcomingIrrigationHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetIrrigationHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateIrrigationHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteIrrigationHandler))
+	})
+
+	// Rain gauge routes (protected with JWT middleware)
+	api.Route("/rainfall", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateRainfallHandler))
+		r.Get("/", app.JWTMiddleware(app.GetRainfallRecordsHandler))
+		r.Get("/summary", app.JWTMiddleware(app.GetRainfallSummaryHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetRainfallHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateRainfallHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteRainfallHandler))
+	})
+
+	// Paddock routes (protected with JWT middleware)
+	api.Route("/paddocks", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreatePaddockHandler))
+		r.Get("/", app.JWTMiddleware(app.GetPaddocksHandler))
+		r.Get("/rest", app.JWTMiddleware(app.GetPaddockRestHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetPaddockHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdatePaddockHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeletePaddockHandler))
+	})
+
+	// Grazing rotation routes (protected with JWT middleware)
+	api.Route("/grazing", func(r chi.Router) {
+		r.Post("/plan", app.JWTMiddleware(app.CreateGrazingPlanHandler))
+		r.Post("/plan/preview", app.JWTMiddleware(app.PreviewGrazingPlanHandler))
+		r.Post("/moves", app.JWTMiddleware(app.RecordGrazingMoveHandler))
+		r.Get("/moves", app.JWTMiddleware(app.GetGrazingMovesHandler))
+		r.Get("/moves/{id}", app.JWTMiddleware(app.GetGrazingMoveHandler))
+		r.Post("/moves/{id}/in", app.JWTMiddleware(app.MoveInGrazingMoveHandler))
+		r.Post("/moves/{id}/out", app.JWTMiddleware(app.MoveOutGrazingMoveHandler))
+		r.Post("/moves/{id}/cancel", app.JWTMiddleware(app.CancelGrazingMoveHandler))
+	})
+
+	// Breeding routes (protected with JWT middleware)
+	api.Route("/breeding", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateBreedingEventHandler))
+		r.Get("/", app.JWTMiddleware(app.GetBreedingEventsHandler))
+		r.Get("/due", app.JWTMiddleware(app.GetDueBreedingEventsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetBreedingEventHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateBreedingEventHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteBreedingEventHandler))
+	})
+
+	// Milk, egg and other production routes (protected with JWT middleware)
+	api.Route("/production", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateProductionHandler))
+		r.Post("/batch", app.JWTMiddleware(app.CreateProductionBatchHandler))
+		r.Get("/", app.JWTMiddleware(app.GetProductionRecordsHandler))
+		r.Get("/trend", app.JWTMiddleware(app.GetProductionTrendHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetProductionRecordHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateProductionRecordHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteProductionRecordHandler))
+	})
+
+	// Feeding routes (protected with JWT middleware)
+	api.Route("/feeding", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateFeedingHandler))
+		r.Get("/", app.JWTMiddleware(app.GetFeedingsHandler))
+		r.Get("/costs", app.JWTMiddleware(app.GetFeedCostsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetFeedingHandler))
+	})
+
+	// Mortality and culling routes (protected with JWT middleware)
+	api.Route("/mortality", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateMortalityHandler))
+		r.Get("/", app.JWTMiddleware(app.GetMortalitiesHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetMortalityHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateMortalityHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteMortalityHandler))
+	})
+
+	// Weight and growth routes (protected with JWT middleware)
+	api.Route("/weights", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateWeightHandler))
+		r.Post("/batch", app.JWTMiddleware(app.CreateWeightsBatchHandler))
+		r.Get("/", app.JWTMiddleware(app.GetWeightsHandler))
+		r.Get("/growth", app.JWTMiddleware(app.GetGrowthHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetWeightHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateWeightHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteWeightHandler))
+	})
+
+	// Calving, kidding and lambing routes (protected with JWT middleware)
+	api.Route("/births", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateBirthHandler))
+		r.Get("/", app.JWTMiddleware(app.GetBirthsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetBirthHandler))
+	})
+
+	// Offline sync routes (protected with JWT middleware)
+	api.Route("/sync", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.PullSyncHandler))
+		r.Post("/", app.JWTMiddleware(app.PushSyncHandler))
+	})
+
+	// Search routes (protected with JWT middleware)
+	api.Route("/search", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.SearchHandler))
+	})
+
+	// Market price routes (protected with JWT middleware)
+	api.Route("/market", func(r chi.Router) {
+		r.Get("/prices", app.JWTMiddleware(app.GetMarketPricesHandler))
+		r.Get("/commodities", app.JWTMiddleware(app.GetMarketCommoditiesHandler))
 	})
 
 	// Livestock routes (protected with JWT middleware)
-	mux.Route("/api/livestock", func(r chi.Router) {
+	api.Route("/livestock", func(r chi.Router) {
 		r.Post("/", app.JWTMiddleware(app.CreateLivestockHandler))
+		r.Post("/batch", app.JWTMiddleware(app.CreateLivestockBatchHandler))
+		r.Delete("/bulk", app.JWTMiddleware(app.DeleteLivestockBatchHandler))
 		r.Get("/", app.JWTMiddleware(app.GetLivestocksHandler))
+		r.Get("/all", app.JWTMiddleware(app.GetAllLivestocksHandler))
+		r.Get("/stats", app.JWTMiddleware(app.GetLivestockStatsHandler))
 		r.Put("/", app.JWTMiddleware(app.UpdateLivestockHandler))
 		r.Delete("/", app.JWTMiddleware(app.DeleteLivestockHandler))
+		r.Get("/trash", app.JWTMiddleware(app.GetDeletedLivestocksHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetLivestockHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateLivestockHandler))
+		r.Patch("/{id}", app.JWTMiddleware(app.PatchLivestockHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteLivestockHandler))
+		r.Get("/{id}/history", app.JWTMiddleware(app.GetLivestockHistoryHandler))
+		r.Post("/{id}/restore", app.JWTMiddleware(app.RestoreLivestockHandler))
 	})
 
 	// Employee routes (protected with JWT middleware)
-	mux.Route("/api/employees", func(r chi.Router) {
+	api.Route("/employees", func(r chi.Router) {
 		r.Post("/", app.JWTMiddleware(app.CreateEmployeeHandler))
+		r.Post("/batch", app.JWTMiddleware(app.CreateEmployeesBatchHandler))
 		r.Get("/", app.JWTMiddleware(app.GetEmployeesHandler))
+		r.Get("/trash", app.JWTMiddleware(app.GetDeletedEmployeesHandler))
 		r.Get("/{id}", app.JWTMiddleware(app.GetEmployeeHandler))
 		r.Put("/{id}", app.JWTMiddleware(app.UpdateEmployeeHandler))
+		r.Patch("/{id}", app.JWTMiddleware(app.PatchEmployeeHandler))
 		r.Delete("/{id}", app.JWTMiddleware(app.DeleteEmployeeHandler))
+		r.Get("/{id}/history", app.JWTMiddleware(app.GetEmployeeHistoryHandler))
+		r.Post("/{id}/restore", app.JWTMiddleware(app.RestoreEmployeeHandler))
+		r.Post("/{id}/payments", app.JWTMiddleware(app.RecordPaymentHandler))
+		r.Get("/{id}/payments", app.JWTMiddleware(app.GetEmployeePaymentsHandler))
+		r.Post("/{id}/attendance", app.JWTMiddleware(app.ClockAttendanceHandler))
+		r.Get("/{id}/attendance", app.JWTMiddleware(app.GetEmployeeAttendanceHandler))
+		r.Get("/{id}/documents", app.JWTMiddleware(app.GetEmployeeDocumentsHandler))
 	})
 
-	return mux
+	// Payroll routes (protected with JWT middleware)
+	api.Route("/payroll", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetPaymentsHandler))
+		r.Get("/summary", app.JWTMiddleware(app.GetPayrollSummaryHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeletePaymentHandler))
+	})
+
+	// Attendance report routes (protected with JWT middleware)
+	api.Route("/attendance", func(r chi.Router) {
+		r.Get("/weekly", app.JWTMiddleware(app.GetWeeklyHoursHandler))
+		r.Get("/absentees", app.JWTMiddleware(app.GetAbsenteeReportHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteAttendanceHandler))
+	})
+
+	// Shift routes (protected with JWT middleware)
+	api.Route("/shifts", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateShiftHandler))
+		r.Get("/", app.JWTMiddleware(app.GetShiftsHandler))
+		r.Get("/roster", app.JWTMiddleware(app.GetRosterHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateShiftHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteShiftHandler))
+	})
+
+	// Equipment routes (protected with JWT middleware)
+	api.Route("/equipment", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateEquipmentHandler))
+		r.Get("/", app.JWTMiddleware(app.GetEquipmentListHandler))
+		r.Get("/maintenance-due", app.JWTMiddleware(app.GetMaintenanceDueHandler))
+		r.Get("/maintenance-costs", app.JWTMiddleware(app.GetMaintenanceCostsHandler))
+		r.Delete("/maintenance/{id}", app.JWTMiddleware(app.DeleteMaintenanceRecordHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetEquipmentHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateEquipmentHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteEquipmentHandler))
+		r.Post("/{id}/maintenance", app.JWTMiddleware(app.LogMaintenanceHandler))
+		r.Get("/{id}/maintenance", app.JWTMiddleware(app.GetMaintenanceRecordsHandler))
+	})
+
+	// Water source routes (protected with JWT middleware)
+	api.Route("/water-sources", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateWaterSourceHandler))
+		r.Get("/", app.JWTMiddleware(app.GetWaterSourcesHandler))
+		r.Get("/alerts", app.JWTMiddleware(app.GetWaterAlertsHandler))
+		r.Get("/trash", app.JWTMiddleware(app.GetDeletedWaterSourcesHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetWaterSourceHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateWaterSourceHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteWaterSourceHandler))
+		r.Post("/{id}/restore", app.JWTMiddleware(app.RestoreWaterSourceHandler))
+		r.Post("/{id}/usage", app.JWTMiddleware(app.LogWaterUsageHandler))
+		r.Get("/{id}/usage", app.JWTMiddleware(app.GetWaterUsageHandler))
+	})
+
+	// Chemical store routes (protected with JWT middleware)
+	api.Route("/chemicals", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateChemicalProductHandler))
+		r.Get("/", app.JWTMiddleware(app.GetChemicalProductsHandler))
+		r.Get("/register", app.JWTMiddleware(app.GetChemicalRegisterHandler))
+		r.Get("/trash", app.JWTMiddleware(app.GetDeletedChemicalProductsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetChemicalProductHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateChemicalProductHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteChemicalProductHandler))
+		r.Post("/{id}/restore", app.JWTMiddleware(app.RestoreChemicalProductHandler))
+		r.Post("/{id}/usage", app.JWTMiddleware(app.LogChemicalUsageHandler))
+		r.Get("/{id}/usage", app.JWTMiddleware(app.GetChemicalUsageHandler))
+	})
+
+	// Inventory routes (protected with JWT middleware)
+	api.Route("/inventory", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateInventoryItemHandler))
+		r.Get("/", app.JWTMiddleware(app.GetInventoryItemsHandler))
+		r.Get("/expiring", app.JWTMiddleware(app.GetExpiringInventoryHandler))
+		r.Get("/low-stock", app.JWTMiddleware(app.GetLowStockInventoryHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetInventoryItemHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateInventoryItemHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteInventoryItemHandler))
+		r.Post("/{id}/batches", app.JWTMiddleware(app.ReceiveInventoryBatchHandler))
+		r.Get("/{id}/batches", app.JWTMiddleware(app.GetInventoryBatchesHandler))
+		r.Get("/{id}/fefo", app.JWTMiddleware(app.GetFEFOSuggestionHandler))
+		r.Post("/{id}/consume", app.JWTMiddleware(app.ConsumeInventoryHandler))
+	})
+
+	// Notification routes (protected with JWT middleware)
+	api.Route("/notifications", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetNotificationsHandler))
+		r.Post("/read-all", app.JWTMiddleware(app.MarkAllNotificationsReadHandler))
+		r.Post("/{id}/read", app.JWTMiddleware(app.MarkNotificationReadHandler))
+	})
+
+	// Live event stream (protected with JWT middleware): server-sent events
+	// for record changes and new notifications (see live.go)
+	api.Get("/events", app.JWTMiddleware(app.StreamEventsHandler))
+
+	// Utility (energy and water consumption) routes (protected with JWT middleware)
+	api.Route("/utilities", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateUtilityRecordHandler))
+		r.Get("/", app.JWTMiddleware(app.GetUtilityRecordsHandler))
+		r.Get("/monthly", app.JWTMiddleware(app.GetMonthlyUtilityConsumptionHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetUtilityRecordHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateUtilityRecordHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteUtilityRecordHandler))
+	})
+
+	// Asset register routes (protected with JWT middleware)
+	api.Route("/assets", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateAssetHandler))
+		r.Get("/", app.JWTMiddleware(app.GetAssetsHandler))
+		r.Get("/balance-sheet", app.JWTMiddleware(app.GetBalanceSheetHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetAssetHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateAssetHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteAssetHandler))
+		r.Post("/{id}/revalue", app.JWTMiddleware(app.RevalueAssetHandler))
+		r.Post("/{id}/dispose", app.JWTMiddleware(app.DisposeAssetHandler))
+	})
+
+	// Purchasing routes (protected with JWT middleware)
+	api.Route("/purchases", func(r chi.Router) {
+		r.Post("/suppliers", app.JWTMiddleware(app.CreateSupplierHandler))
+		r.Get("/suppliers", app.JWTMiddleware(app.GetSuppliersHandler))
+		r.Get("/suppliers/{id}", app.JWTMiddleware(app.GetSupplierHandler))
+		r.Put("/suppliers/{id}", app.JWTMiddleware(app.UpdateSupplierHandler))
+		r.Delete("/suppliers/{id}", app.JWTMiddleware(app.DeleteSupplierHandler))
+		r.Post("/orders", app.JWTMiddleware(app.CreatePurchaseOrderHandler))
+		r.Get("/orders", app.JWTMiddleware(app.GetPurchaseOrdersHandler))
+		r.Get("/orders/{id}", app.JWTMiddleware(app.GetPurchaseOrderHandler))
+		r.Put("/orders/{id}", app.JWTMiddleware(app.UpdatePurchaseOrderHandler))
+		r.Delete("/orders/{id}", app.JWTMiddleware(app.DeletePurchaseOrderHandler))
+		r.Post("/orders/{id}/place", app.JWTMiddleware(app.PlacePurchaseOrderHandler))
+		r.Post("/orders/{id}/cancel", app.JWTMiddleware(app.CancelPurchaseOrderHandler))
+		r.Post("/orders/{id}/receive", app.JWTMiddleware(app.ReceivePurchaseOrderHandler))
+	})
+
+	// Service provider routes (protected with JWT middleware)
+	api.Route("/service-providers", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateServiceProviderHandler))
+		r.Get("/", app.JWTMiddleware(app.GetServiceProvidersHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetServiceProviderHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateServiceProviderHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteServiceProviderHandler))
+		r.Get("/{id}/jobs", app.JWTMiddleware(app.GetServiceProviderJobsHandler))
+	})
+
+	// Attachment routes (protected with JWT middleware)
+	api.Route("/attachments", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateAttachmentHandler))
+		r.Get("/", app.JWTMiddleware(app.GetAttachmentsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetAttachmentHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteAttachmentHandler))
+		r.Put("/{id}/content", app.JWTMiddleware(app.UploadAttachmentHandler))
+		r.Get("/{id}/content", app.JWTMiddleware(app.DownloadAttachmentHandler))
+		r.Post("/{id}/complete", app.JWTMiddleware(app.CompleteAttachmentHandler))
+	})
+
+	// Document routes (protected with JWT middleware)
+	api.Route("/documents", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateDocumentHandler))
+		r.Get("/", app.JWTMiddleware(app.GetDocumentsHandler))
+		r.Get("/expiring", app.JWTMiddleware(app.GetExpiringDocumentsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetDocumentHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateDocumentHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteDocumentHandler))
+		r.Post("/{id}/files", app.JWTMiddleware(app.AddDocumentFileHandler))
+		r.Get("/{id}/file", app.JWTMiddleware(app.DownloadDocumentFileHandler))
+	})
+
+	// Tag routes (protected with JWT middleware)
+	api.Route("/tags", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateTagHandler))
+		r.Get("/", app.JWTMiddleware(app.GetTagsHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateTagHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteTagHandler))
+		r.Post("/{id}/records", app.JWTMiddleware(app.TagRecordHandler))
+		r.Delete("/{id}/records", app.JWTMiddleware(app.UntagRecordHandler))
+	})
+
+	// Saved view routes (protected with JWT middleware)
+	api.Route("/views", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateSavedViewHandler))
+		r.Get("/", app.JWTMiddleware(app.GetSavedViewsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetSavedViewHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateSavedViewHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteSavedViewHandler))
+	})
+
+	// Note routes (protected with JWT middleware)
+	api.Route("/notes", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateNoteHandler))
+		r.Get("/", app.JWTMiddleware(app.GetNotesHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetNoteHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateNoteHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteNoteHandler))
+	})
+
+	// Import wizard routes (protected with JWT middleware)
+	api.Route("/imports", func(r chi.Router) {
+		r.Get("/fields", app.JWTMiddleware(app.GetImportFieldsHandler))
+		r.Post("/", app.JWTMiddleware(app.UploadImportHandler))
+		r.Get("/", app.JWTMiddleware(app.GetImportsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetImportHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteImportHandler))
+		r.Put("/{id}/mapping", app.JWTMiddleware(app.SetImportMappingHandler))
+		r.Post("/{id}/validate", app.JWTMiddleware(app.ValidateImportHandler))
+		r.Post("/{id}/commit", app.JWTMiddleware(app.CommitImportHandler))
+	})
+
+	// Transaction routes (protected with JWT middleware)
+	api.Route("/transactions", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateTransactionHandler))
+		r.Get("/", app.JWTMiddleware(app.GetTransactionsHandler))
+		r.Get("/all", app.JWTMiddleware(app.GetAllTransactionsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetTransactionHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateTransactionHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteTransactionHandler))
+		r.Get("/{id}/history", app.JWTMiddleware(app.GetTransactionHistoryHandler))
+	})
+
+	// Finance report routes (protected with JWT middleware)
+	api.Route("/finance", func(r chi.Router) {
+		r.Get("/profitability", app.JWTMiddleware(app.GetProfitabilityHandler))
+		r.Get("/tax-summary", app.JWTMiddleware(app.GetTaxSummaryHandler))
+		r.Get("/assets", app.JWTMiddleware(app.GetAssetDepreciationHandler))
+	})
+
+	// Tax rate routes (protected with JWT middleware)
+	api.Route("/tax-rates", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateTaxRateHandler))
+		r.Get("/", app.JWTMiddleware(app.GetTaxRatesHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetTaxRateHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateTaxRateHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteTaxRateHandler))
+	})
+
+	// Exchange rate routes (protected with JWT middleware)
+	api.Route("/exchange-rates", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateExchangeRateHandler))
+		r.Get("/", app.JWTMiddleware(app.GetExchangeRatesHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateExchangeRateHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteExchangeRateHandler))
+	})
+
+	// Season budget routes (protected with JWT middleware)
+	api.Route("/budgets", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateBudgetLineHandler))
+		r.Get("/", app.JWTMiddleware(app.GetBudgetLinesHandler))
+		r.Get("/variance", app.JWTMiddleware(app.GetBudgetVarianceHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateBudgetLineHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteBudgetLineHandler))
+	})
+
+	// Loan routes (protected with JWT middleware)
+	api.Route("/loans", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateLoanHandler))
+		r.Get("/", app.JWTMiddleware(app.GetLoansHandler))
+		r.Get("/outstanding", app.JWTMiddleware(app.GetLoansOutstandingHandler))
+		r.Delete("/repayments/{id}", app.JWTMiddleware(app.DeleteLoanRepaymentHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetLoanHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateLoanHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteLoanHandler))
+		r.Post("/{id}/repayments", app.JWTMiddleware(app.RepayLoanHandler))
+	})
+
+	// Buyer routes (protected with JWT middleware)
+	api.Route("/buyers", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetBuyersHandler))
+		r.Get("/me/verification", app.JWTMiddleware(app.GetBuyerVerificationHandler))
+		r.Put("/me/verification", app.JWTMiddleware(app.SubmitBuyerVerificationHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetBuyerHandler))
+	})
+
+	// Rating routes (protected with JWT middleware)
+	api.Route("/ratings", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateRatingHandler))
+		r.Get("/", app.JWTMiddleware(app.GetRatingsHandler))
+	})
+
+	// Dispute routes (protected with JWT middleware)
+	api.Route("/disputes", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateDisputeHandler))
+		r.Get("/", app.JWTMiddleware(app.GetDisputesHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetDisputeHandler))
+		r.Post("/{id}/evidence", app.JWTMiddleware(app.AddDisputeEvidenceHandler))
+		r.Post("/{id}/withdraw", app.JWTMiddleware(app.WithdrawDisputeHandler))
+	})
+
+	// Escrow routes (protected with JWT middleware)
+	api.Route("/escrows", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateEscrowHandler))
+		r.Get("/", app.JWTMiddleware(app.GetEscrowsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetEscrowHandler))
+		r.Post("/{id}/confirm", app.JWTMiddleware(app.ConfirmEscrowHandler))
+		r.Post("/{id}/redeem", app.JWTMiddleware(app.RedeemEscrowHandler))
+		r.Post("/{id}/refund", app.JWTMiddleware(app.RefundEscrowHandler))
+	})
+
+	// Period lock routes (protected with JWT middleware)
+	api.Route("/period-locks", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.LockPeriodHandler))
+		r.Get("/", app.JWTMiddleware(app.GetPeriodLocksHandler))
+		r.Post("/{id}/unlock", app.JWTMiddleware(app.UnlockPeriodHandler))
+	})
+
+	// Audit log routes (protected with JWT middleware)
+	api.Route("/audit-log", func(r chi.Router) {
+		r.Get("/", app.JWTMiddleware(app.GetAuditLogHandler))
+	})
+
+	// API key routes (protected with JWT middleware). Keys cannot manage keys,
+	// as no scope covers these routes.
+	api.Route("/api-keys", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateAPIKeyHandler))
+		r.Get("/", app.JWTMiddleware(app.GetAPIKeysHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.RevokeAPIKeyHandler))
+	})
+
+	// Webhook routes (protected with JWT middleware)
+	api.Route("/webhooks", func(r chi.Router) {
+		r.Post("/", app.JWTMiddleware(app.CreateWebhookHandler))
+		r.Get("/", app.JWTMiddleware(app.GetWebhooksHandler))
+		r.Get("/events", app.JWTMiddleware(app.GetWebhookEventsHandler))
+		r.Get("/{id}", app.JWTMiddleware(app.GetWebhookHandler))
+		r.Put("/{id}", app.JWTMiddleware(app.UpdateWebhookHandler))
+		r.Delete("/{id}", app.JWTMiddleware(app.DeleteWebhookHandler))
+		r.Get("/{id}/deliveries", app.JWTMiddleware(app.GetWebhookDeliveriesHandler))
+		r.Post("/{id}/rotate-secret", app.JWTMiddleware(app.RotateWebhookSecretHandler))
+	})
+
+	// Report routes (protected with JWT middleware)
+	api.Route("/reports", func(r chi.Router) {
+		r.Get("/carbon", app.JWTMiddleware(app.GetCarbonReportHandler))
+		r.Get("/portfolio", app.JWTMiddleware(app.GetPortfolioReportHandler))
+		r.Get("/jobs", app.JWTMiddleware(app.GetReportJobsHandler))
+		r.Get("/jobs/{id}", app.JWTMiddleware(app.GetReportJobHandler))
+		r.Get("/jobs/{id}/download", app.JWTMiddleware(app.DownloadReportHandler))
+		r.Delete("/jobs/{id}", app.JWTMiddleware(app.DeleteReportJobHandler))
+		r.Get("/{type}", app.JWTMiddleware(app.RequestReportHandler))
+	})
+
+	// Sustainability checklist routes (protected with JWT middleware)
+	api.Route("/sustainability", func(r chi.Router) {
+		r.Route("/practices", func(r chi.Router) {
+			r.Post("/", app.JWTMiddleware(app.CreateSustainabilityPracticeHandler))
+			r.Get("/", app.JWTMiddleware(app.GetSustainabilityPracticesHandler))
+			r.Post("/defaults", app.JWTMiddleware(app.CreateDefaultSustainabilityPracticesHandler))
+			r.Put("/{id}", app.JWTMiddleware(app.UpdateSustainabilityPracticeHandler))
+			r.Delete("/{id}", app.JWTMiddleware(app.DeleteSustainabilityPracticeHandler))
+		})
+		r.Route("/assessments", func(r chi.Router) {
+			r.Post("/", app.JWTMiddleware(app.CreateSustainabilityAssessmentHandler))
+			r.Get("/", app.JWTMiddleware(app.GetSustainabilityAssessmentsHandler))
+			r.Get("/{id}", app.JWTMiddleware(app.GetSustainabilityAssessmentHandler))
+			r.Put("/{id}", app.JWTMiddleware(app.UpdateSustainabilityAssessmentHandler))
+			r.Delete("/{id}", app.JWTMiddleware(app.DeleteSustainabilityAssessmentHandler))
+			r.Post("/{id}/submit", app.JWTMiddleware(app.SubmitSustainabilityAssessmentHandler))
+			r.Get("/{id}/export", app.JWTMiddleware(app.ExportEvidencePackHandler))
+		})
+	})
+
+	return api
 }
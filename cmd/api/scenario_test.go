@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"farm4u/data"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestScenario walks a new farmer through the core journey against the full
+// router, stopping at the first step that fails
+func TestScenario(t *testing.T) {
+	app := newTestApp(t)
+	srv := httptest.NewServer(app.routes())
+	defer srv.Close()
+
+	s := &scenario{api: &scenarioClient{baseURL: srv.URL, http: srv.Client()}}
+	for _, st := range s.steps() {
+		passed := t.Run(st.name, func(t *testing.T) {
+			if err := st.run(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if !passed {
+			return
+		}
+	}
+}
+
+// scenarioStep is one action in the journey
+type scenarioStep struct {
+	name string
+	run  func() error
+}
+
+// scenario carries the records created by earlier steps to later ones
+type scenario struct {
+	api *scenarioClient
+
+	email       string
+	password    string
+	farmID      string
+	fieldID     string
+	cropID      string
+	cropVersion int
+	assessment  string
+}
+
+const (
+	harvestIncome = 1500.0
+	seedExpense   = 300.0
+)
+
+// steps returns the farmer journey in order
+func (s *scenario) steps() []scenarioStep {
+	return []scenarioStep{
+		{"sign up", s.signup},
+		{"log in", s.login},
+		{"create farm", s.createFarm},
+		{"add field", s.addField},
+		{"add crop", s.addCrop},
+		{"add livestock", s.addLivestock},
+		{"add employee", s.addEmployee},
+		{"record harvest", s.recordHarvest},
+		{"record expense", s.recordExpense},
+		{"view dashboard", s.viewDashboard},
+		{"export report", s.exportReport},
+		{"delete farm", s.deleteFarm},
+	}
+}
+
+func (s *scenario) signup() error {
+	s.email = fmt.Sprintf("scenario+%d@example.com", time.Now().UnixNano())
+	s.password = "Scenario-Passw0rd"
+
+	return s.api.call(http.MethodPost, "/api/v1/auth/signup", map[string]any{
+		"firstName": "Scenario",
+		"lastName":  "Farmer",
+		"email":     s.email,
+		"password":  s.password,
+	}, http.StatusCreated, nil)
+}
+
+func (s *scenario) login() error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	err := s.api.call(http.MethodPost, "/api/v1/auth/login", map[string]any{
+		"email":    s.email,
+		"password": s.password,
+	}, http.StatusOK, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Token == "" {
+		return errors.New("login returned no token")
+	}
+	s.api.token = resp.Token
+	return nil
+}
+
+func (s *scenario) createFarm() error {
+	var resp struct {
+		Farm *data.Farm `json:"farm"`
+	}
+	err := s.api.call(http.MethodPost, "/api/v1/farms", map[string]any{
+		"name":     "Scenario Farm",
+		"location": "Mbarara",
+		"size":     12.5,
+		"farmType": "Mixed",
+	}, http.StatusCreated, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Farm == nil || resp.Farm.FarmID == "" {
+		return errors.New("farm was created without an ID")
+	}
+	s.farmID = resp.Farm.FarmID
+	return nil
+}
+
+func (s *scenario) addField() error {
+	var resp struct {
+		Field *data.Field `json:"field"`
+	}
+	err := s.api.call(http.MethodPost, "/api/v1/fields?farmId="+s.farmID, map[string]any{
+		"name":     "River plot",
+		"area":     4.5,
+		"soilType": "Loam",
+	}, http.StatusCreated, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Field == nil || resp.Field.FieldID == "" {
+		return errors.New("field was created without an ID")
+	}
+	s.fieldID = resp.Field.FieldID
+	return nil
+}
+
+func (s *scenario) addCrop() error {
+	var resp struct {
+		Crop *data.Crop `json:"crop"`
+	}
+	err := s.api.call(http.MethodPost, "/api/v1/crops?farmId="+s.farmID, map[string]any{
+		"name":         "Maize",
+		"plantingDate": time.Now().AddDate(0, -4, 0),
+		"status":       "Growing",
+		"fieldId":      s.fieldID,
+	}, http.StatusCreated, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Crop == nil || resp.Crop.CropID == "" {
+		return errors.New("crop was created without an ID")
+	}
+	if resp.Crop.FieldID == nil || *resp.Crop.FieldID != s.fieldID {
+		return errors.New("crop was not planted on the field")
+	}
+	s.cropID, s.cropVersion = resp.Crop.CropID, resp.Crop.Version
+	return nil
+}
+
+func (s *scenario) addLivestock() error {
+	return s.api.call(http.MethodPost, "/api/v1/livestock?farmId="+s.farmID, map[string]any{
+		"type":         "Goat",
+		"count":        20,
+		"healthStatus": "Healthy",
+	}, http.StatusCreated, nil)
+}
+
+func (s *scenario) addEmployee() error {
+	return s.api.call(http.MethodPost, "/api/v1/employees?farmId="+s.farmID, map[string]any{
+		"firstName": "Scenario",
+		"lastName":  "Worker",
+		"position":  "Farm Hand",
+		"salary":    250000,
+		"hireDate":  time.Now().AddDate(0, -1, 0),
+	}, http.StatusCreated, nil)
+}
+
+// recordHarvest marks the crop harvested and books the sale as income
+func (s *scenario) recordHarvest() error {
+	var resp struct {
+		Crop *data.Crop `json:"crop"`
+	}
+	err := s.api.call(http.MethodPut, "/api/v1/crops/"+s.cropID, map[string]any{
+		"harvestDate": time.Now(),
+		"quantity":    1200,
+		"status":      "Harvested",
+		"version":     s.cropVersion,
+	}, http.StatusOK, &resp)
+	if err != nil {
+		return err
+	}
+	if resp.Crop == nil || resp.Crop.Status != "Harvested" {
+		return errors.New("crop was not marked as harvested")
+	}
+
+	return s.api.call(http.MethodPost, "/api/v1/transactions?farmId="+s.farmID, map[string]any{
+		"type":        "Income",
+		"category":    "Crop Sales",
+		"amount":      harvestIncome,
+		"description": "Maize harvest",
+	}, http.StatusCreated, nil)
+}
+
+func (s *scenario) recordExpense() error {
+	return s.api.call(http.MethodPost, "/api/v1/transactions?farmId="+s.farmID, map[string]any{
+		"type":        "Expense",
+		"category":    "Seeds",
+		"amount":      seedExpense,
+		"description": "Maize seed",
+	}, http.StatusCreated, nil)
+}
+
+// viewDashboard checks the farm's records and profitability add up to what
+// the earlier steps entered
+func (s *scenario) viewDashboard() error {
+	counts := []struct {
+		path string
+		key  string
+	}{
+		{"/api/v1/fields", "fields"},
+		{"/api/v1/crops", "crops"},
+		{"/api/v1/livestock", "livestocks"},
+		{"/api/v1/employees", "employees"},
+		{"/api/v1/transactions", "transactions"},
+	}
+	want := map[string]int{"fields": 1, "crops": 1, "livestocks": 1, "employees": 1, "transactions": 2}
+	for _, c := range counts {
+		var resp map[string]any
+		if err := s.api.call(http.MethodGet, c.path+"?farmId="+s.farmID, nil, http.StatusOK, &resp); err != nil {
+			return err
+		}
+		records, _ := resp[c.key].([]any)
+		if got := len(records); got != want[c.key] {
+			return fmt.Errorf("expected %d %s, got %d", want[c.key], c.key, got)
+		}
+	}
+
+	var resp struct {
+		Report struct {
+			Income      float64 `json:"income"`
+			DirectCosts float64 `json:"directCosts"`
+			NetProfit   float64 `json:"netProfit"`
+		} `json:"report"`
+	}
+	if err := s.api.call(http.MethodGet, "/api/v1/finance/profitability?farmId="+s.farmID, nil, http.StatusOK, &resp); err != nil {
+		return err
+	}
+	report := resp.Report
+	if !near(report.Income, harvestIncome) || !near(report.DirectCosts, seedExpense) || !near(report.NetProfit, harvestIncome-seedExpense) {
+		return fmt.Errorf("unexpected profitability: income %.2f, direct costs %.2f, net profit %.2f", report.Income, report.DirectCosts, report.NetProfit)
+	}
+	return nil
+}
+
+// exportReport completes a sustainability assessment and downloads its
+// evidence pack as CSV
+func (s *scenario) exportReport() error {
+	var practices struct {
+		Practices []*data.SustainabilityPractice `json:"practices"`
+	}
+	if err := s.api.call(http.MethodPost, "/api/v1/sustainability/practices/defaults?farmId="+s.farmID, nil, http.StatusCreated, &practices); err != nil {
+		return err
+	}
+	if len(practices.Practices) == 0 {
+		return errors.New("no default sustainability practices were created")
+	}
+
+	responses := make([]map[string]any, 0, len(practices.Practices))
+	for _, practice := range practices.Practices {
+		responses = append(responses, map[string]any{
+			"practiceId": practice.SustainabilityPracticeID,
+			"status":     "Not Applicable",
+		})
+	}
+
+	var created struct {
+		Assessment *data.SustainabilityAssessment `json:"assessment"`
+	}
+	err := s.api.call(http.MethodPost, "/api/v1/sustainability/assessments?farmId="+s.farmID, map[string]any{
+		"season":     fmt.Sprintf("%d", time.Now().Year()),
+		"assessedBy": "Scenario Farmer",
+		"responses":  responses,
+	}, http.StatusCreated, &created)
+	if err != nil {
+		return err
+	}
+	if created.Assessment == nil {
+		return errors.New("assessment was not returned")
+	}
+	s.assessment = created.Assessment.SustainabilityAssessmentID
+
+	path := "/api/v1/sustainability/assessments/" + s.assessment
+	if err := s.api.call(http.MethodPost, path+"/submit", nil, http.StatusOK, nil); err != nil {
+		return err
+	}
+
+	body, contentType, err := s.api.download(path + "/export?format=csv")
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(contentType, "text/csv") {
+		return fmt.Errorf("expected a CSV export, got %q", contentType)
+	}
+	if lines := bytes.Count(body, []byte("\n")); lines < len(practices.Practices) {
+		return fmt.Errorf("export has %d lines for %d practices", lines, len(practices.Practices))
+	}
+	return nil
+}
+
+func (s *scenario) deleteFarm() error {
+	return s.api.call(http.MethodDelete, "/api/v1/farms/"+s.farmID, nil, http.StatusOK, nil)
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+// scenarioClient calls the API as a single signed-in user
+type scenarioClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// call sends body as JSON and fails unless the response has the wanted status.
+// When out is non-nil the response body is decoded into it.
+func (c *scenarioClient) call(method, path string, body any, want int, out any) error {
+	raw, header, err := c.do(method, path, body, want)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if ct := header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		return fmt.Errorf("%s %s: expected JSON, got %q", method, path, ct)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%s %s: decoding response: %w", method, path, err)
+	}
+	return nil
+}
+
+// download fetches path and returns the raw body and its content type
+func (c *scenarioClient) download(path string) ([]byte, string, error) {
+	raw, header, err := c.do(http.MethodGet, path, nil, http.StatusOK)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, header.Get("Content-Type"), nil
+}
+
+func (c *scenarioClient) do(method, path string, body any, want int) ([]byte, http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s %s: encoding request: %w", method, path, err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s %s: reading response: %w", method, path, err)
+	}
+	if resp.StatusCode != want {
+		return nil, nil, fmt.Errorf("%s %s: expected status %d, got %d: %s", method, path, want, resp.StatusCode, bytes.TrimSpace(raw))
+	}
+	return raw, resp.Header, nil
+}
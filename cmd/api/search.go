@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SearchResponse represents the search response
+type SearchResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Hits    []data.SearchHit `json:"hits"`
+}
+
+// SearchHandler handles searching a farm's records for ?q=, optionally only
+// the comma-separated ?types= (crop, livestock, employee, document), returning
+// at most ?limit= hits (default 20)
+func (app *Config) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	var types []string
+	if v := r.URL.Query().Get("types"); v != "" {
+		types = strings.Split(v, ",")
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			app.errorJSON(w, errors.New("limit must be a positive number"), http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	hits, err := app.Services.Search.Search(r.Context(), user, farmID, r.URL.Query().Get("q"), types, limit)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+	if hits == nil {
+		hits = []data.SearchHit{}
+	}
+
+	response := SearchResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d results", len(hits)),
+		Hits:    hits,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/season"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SeasonRequest represents the season creation/update request body
+type SeasonRequest struct {
+	Name      string     `json:"name"`
+	StartDate *time.Time `json:"startDate"`
+	EndDate   *time.Time `json:"endDate"` // Last day of the season
+	Notes     string     `json:"notes"`
+}
+
+// SeasonResponse represents the season response
+type SeasonResponse struct {
+	Success   bool              `json:"success"`
+	Message   string            `json:"message"`
+	Season    *data.Season      `json:"season,omitempty"`
+	Seasons   []*data.Season    `json:"seasons,omitempty"`
+	Summary   *season.Summary   `json:"summary,omitempty"`
+	Summaries []*season.Summary `json:"summaries,omitempty"`
+}
+
+// Validate checks the season request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *SeasonRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Check(req.StartDate != nil, "startDate", "is required")
+		v.Check(req.EndDate != nil, "endDate", "is required")
+	}
+	if req.StartDate != nil && req.EndDate != nil {
+		v.Check(!req.EndDate.Before(*req.StartDate), "endDate", "must not be before startDate")
+	}
+	return v.Errors()
+}
+
+// CreateSeasonHandler handles adding a season to a farm
+func (app *Config) CreateSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	var req SeasonRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := app.Services.Season.Create(r.Context(), user, farmID, season.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Season created successfully",
+		Season:  s,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetSeasonsHandler handles listing a farm's seasons, earliest first
+func (app *Config) GetSeasonsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	seasons, err := app.Services.Season.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Seasons retrieved successfully",
+		Seasons: seasons,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSeasonHandler handles retrieving a single season by ID
+func (app *Config) GetSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID := resourceID(r)
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := app.Services.Season.Get(r.Context(), user, seasonID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Season retrieved successfully",
+		Season:  s,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateSeasonHandler handles updating an open season
+func (app *Config) UpdateSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	var req SeasonRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	seasonID := resourceID(r)
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := app.Services.Season.Update(r.Context(), user, seasonID, season.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Season updated successfully",
+		Season:  s,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteSeasonHandler handles deleting an open season
+func (app *Config) DeleteSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID := resourceID(r)
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Season.Delete(r.Context(), user, seasonID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Season deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CloseSeasonHandler handles closing a season, after which no more crops or
+// transactions may be linked to it
+func (app *Config) CloseSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID := resourceID(r)
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := app.Services.Season.Close(r.Context(), user, seasonID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Season closed successfully",
+		Season:  s,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSeasonSummaryHandler handles totalling the crops and transactions
+// counted towards a season
+func (app *Config) GetSeasonSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	seasonID := resourceID(r)
+	if seasonID == "" {
+		app.errorJSON(w, errors.New("season ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := app.Services.Season.Summarize(r.Context(), user, seasonID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success: true,
+		Message: "Season summary retrieved successfully",
+		Summary: summary,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CompareSeasonsHandler handles comparing a farm's seasons
+// (/api/seasons/compare?farmId=&ids=, where ids optionally lists the seasons
+// to compare, comma separated; all of them otherwise). Each season comes with
+// its totals and how far they moved since the season before.
+func (app *Config) CompareSeasonsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	var seasonIDs []string
+	for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			seasonIDs = append(seasonIDs, id)
+		}
+	}
+
+	summaries, err := app.Services.Season.Compare(r.Context(), user, farmID, seasonIDs)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SeasonResponse{
+		Success:   true,
+		Message:   "Seasons compared successfully",
+		Summaries: summaries,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
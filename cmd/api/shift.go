@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/workforce"
+	"net/http"
+	"time"
+)
+
+// ShiftRequest represents the shift creation/update request body
+type ShiftRequest struct {
+	EmployeeID string     `json:"employeeId"`
+	FieldID    *string    `json:"fieldId"` // Optional; "" takes the shift off its field
+	StartsAt   *time.Time `json:"startsAt"`
+	EndsAt     *time.Time `json:"endsAt"`
+	Role       string     `json:"role"`     // e.g. Milking, Harvest, Feeding
+	Location   string     `json:"location"` // Where to report when not a field
+	Notes      string     `json:"notes"`
+}
+
+// ShiftResponse represents the shift response
+type ShiftResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Shift   *data.Shift       `json:"shift,omitempty"`
+	Shifts  []*data.Shift     `json:"shifts,omitempty"`
+	Roster  *workforce.Roster `json:"roster,omitempty"`
+}
+
+// Validate checks the shift request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *ShiftRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("employeeId", req.EmployeeID)
+		v.Required("role", req.Role)
+		v.Check(req.StartsAt != nil, "startsAt", "is required")
+		v.Check(req.EndsAt != nil, "endsAt", "is required")
+	}
+	v.Check(len(req.Role) <= 50, "role", "must be at most 50 characters")
+	if req.StartsAt != nil && req.EndsAt != nil {
+		v.Check(req.EndsAt.After(*req.StartsAt), "endsAt", "must be after startsAt")
+	}
+	return v.Errors()
+}
+
+// CreateShiftHandler handles putting an employee on the roster
+func (app *Config) CreateShiftHandler(w http.ResponseWriter, r *http.Request) {
+	var req ShiftRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	shift, err := app.Services.Workforce.CreateShift(r.Context(), user, farmID, workforce.ShiftInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ShiftResponse{
+		Success: true,
+		Message: "Shift created successfully",
+		Shift:   shift,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetShiftsHandler handles retrieving a farm's shifts, optionally limited by
+// ?from=/?to=
+func (app *Config) GetShiftsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	shifts, err := app.Services.Workforce.ListShifts(r.Context(), user, farmID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ShiftResponse{
+		Success: true,
+		Message: "Shifts retrieved successfully",
+		Shifts:  shifts,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetRosterHandler handles retrieving a farm's weekly roster
+// (/api/shifts/roster?farmId=&week=YYYY-MM-DD), the week being the one
+// containing the given day and defaulting to this week
+func (app *Config) GetRosterHandler(w http.ResponseWriter, r *http.Request) {
+	day := time.Now()
+	if v := r.URL.Query().Get("week"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			app.errorJSON(w, errors.New("week must be in YYYY-MM-DD format"), http.StatusBadRequest)
+			return
+		}
+		day = t
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	roster, err := app.Services.Workforce.Roster(r.Context(), user, farmID, day)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ShiftResponse{
+		Success: true,
+		Message: "Roster retrieved successfully",
+		Roster:  roster,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateShiftHandler handles moving a shift or changing who works it
+func (app *Config) UpdateShiftHandler(w http.ResponseWriter, r *http.Request) {
+	var req ShiftRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	shiftID := resourceID(r)
+	if shiftID == "" {
+		app.errorJSON(w, errors.New("shift ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	shift, err := app.Services.Workforce.UpdateShift(r.Context(), user, shiftID, workforce.ShiftInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ShiftResponse{
+		Success: true,
+		Message: "Shift updated successfully",
+		Shift:   shift,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteShiftHandler handles taking a shift off the roster
+func (app *Config) DeleteShiftHandler(w http.ResponseWriter, r *http.Request) {
+	shiftID := resourceID(r)
+	if shiftID == "" {
+		app.errorJSON(w, errors.New("shift ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Workforce.DeleteShift(r.Context(), user, shiftID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := ShiftResponse{
+		Success: true,
+		Message: "Shift deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
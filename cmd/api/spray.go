@@ -0,0 +1,199 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/spray"
+	"net/http"
+	"time"
+)
+
+// SprayRecordRequest represents the request body for logging a spray
+// application. The product is taken out of the chemical store.
+type SprayRecordRequest struct {
+	CropID                 string     `json:"cropId"`
+	ChemicalProductID      string     `json:"chemicalProductId"`
+	Date                   *time.Time `json:"date"` // Defaults to now
+	Rate                   float64    `json:"rate"`
+	RateUnit               string     `json:"rateUnit"`     // e.g. L/ha
+	AreaSprayed            float64    `json:"areaSprayed"`  // Hectares
+	QuantityUsed           float64    `json:"quantityUsed"` // In the product's unit
+	Applicator             string     `json:"applicator"`
+	ApplicatorEmployeeID   string     `json:"applicatorEmployeeId"`
+	PPEConfirmed           bool       `json:"ppeConfirmed"`
+	WindSpeed              *float64   `json:"windSpeed"`   // km/h
+	Temperature            *float64   `json:"temperature"` // °C
+	Humidity               *float64   `json:"humidity"`    // %
+	WeatherConditions      string     `json:"weatherConditions"`
+	PreHarvestIntervalDays int        `json:"preHarvestIntervalDays"` // From the product label
+	Target                 string     `json:"target"`                 // Pest, disease or weed sprayed against
+	Notes                  string     `json:"notes"`
+}
+
+// SprayRecordResponse represents the spray record response
+type SprayRecordResponse struct {
+	Success     bool                 `json:"success"`
+	Message     string               `json:"message"`
+	Record      *data.SprayRecord    `json:"record,omitempty"`
+	Records     []*data.SprayRecord  `json:"records,omitempty"`
+	Withholding []*spray.Withholding `json:"withholding,omitempty"`
+}
+
+// Validate checks the spray record request fields. The applicator and PPE
+// confirmation are mandatory for every entry, as for chemical usage.
+func (req *SprayRecordRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("cropId", req.CropID)
+	v.Required("chemicalProductId", req.ChemicalProductID)
+	v.Check(req.Rate > 0, "rate", "must be greater than 0")
+	v.Required("rateUnit", req.RateUnit)
+	v.Check(req.QuantityUsed > 0, "quantityUsed", "must be greater than 0")
+	v.Check(req.AreaSprayed >= 0, "areaSprayed", "must be >= 0")
+	v.Required("applicator", req.Applicator)
+	v.Check(req.PPEConfirmed, "ppeConfirmed", "PPE use must be confirmed before logging a spray")
+	v.Check(req.PreHarvestIntervalDays >= 0, "preHarvestIntervalDays", "must be >= 0")
+	if req.Humidity != nil {
+		v.Check(*req.Humidity >= 0 && *req.Humidity <= 100, "humidity", "must be between 0 and 100")
+	}
+	if req.WindSpeed != nil {
+		v.Check(*req.WindSpeed >= 0, "windSpeed", "must be >= 0")
+	}
+	if req.Date != nil {
+		v.Check(!req.Date.After(time.Now()), "date", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateSprayRecordHandler handles logging a spray application on a crop
+func (app *Config) CreateSprayRecordHandler(w http.ResponseWriter, r *http.Request) {
+	var req SprayRecordRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Spray.Record(r.Context(), user, farmID, spray.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SprayRecordResponse{
+		Success: true,
+		Message: "Spray recorded successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetSprayRecordsHandler handles retrieving a farm's spray records,
+// optionally filtered by the cropId, fieldId, chemicalProductId, from and to
+// (YYYY-MM-DD) query parameters
+func (app *Config) GetSprayRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := data.SprayRecordFilter{
+		CropID:            query.Get("cropId"),
+		FieldID:           query.Get("fieldId"),
+		ChemicalProductID: query.Get("chemicalProductId"),
+		From:              from,
+		To:                to,
+	}
+	records, err := app.Services.Spray.List(r.Context(), user, farmID, filter)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SprayRecordResponse{
+		Success: true,
+		Message: "Spray records retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWithholdingHandler handles flagging the crops still inside the
+// pre-harvest interval of a spray, now or on the date query parameter
+// (YYYY-MM-DD)
+func (app *Config) GetWithholdingHandler(w http.ResponseWriter, r *http.Request) {
+	var at *time.Time
+	if v := r.URL.Query().Get("date"); v != "" {
+		t, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			app.errorJSON(w, errors.New("date must be in YYYY-MM-DD format"), http.StatusBadRequest)
+			return
+		}
+		at = &t
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	crops, err := app.Services.Spray.Withholding(r.Context(), user, farmID, at)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SprayRecordResponse{
+		Success:     true,
+		Message:     "Crops in a withholding period retrieved successfully",
+		Withholding: crops,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSprayRecordHandler handles retrieving a single spray record by ID
+func (app *Config) GetSprayRecordHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("spray record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Spray.Get(r.Context(), user, recordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SprayRecordResponse{
+		Success: true,
+		Message: "Spray record retrieved successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,475 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/sustainability"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SustainabilityPracticeRequest represents the checklist practice creation/update request body
+type SustainabilityPracticeRequest struct {
+	Category         string  `json:"category"`
+	Name             string  `json:"name"`
+	Description      string  `json:"description"`
+	Weight           float64 `json:"weight"`
+	EvidenceRequired *bool   `json:"evidenceRequired"`
+	Active           *bool   `json:"active"`
+}
+
+// SustainabilityResponseRequest is the answer to one practice in an assessment request
+type SustainabilityResponseRequest struct {
+	PracticeID  string `json:"practiceId"`
+	Status      string `json:"status"`
+	Evidence    string `json:"evidence"`
+	EvidenceURL string `json:"evidenceUrl"`
+	Notes       string `json:"notes"`
+}
+
+// SustainabilityAssessmentRequest represents the assessment creation/update request body
+type SustainabilityAssessmentRequest struct {
+	Season     string                          `json:"season"`
+	AssessedAt *time.Time                      `json:"assessedAt"`
+	AssessedBy string                          `json:"assessedBy"`
+	Notes      string                          `json:"notes"`
+	Responses  []SustainabilityResponseRequest `json:"responses"`
+}
+
+// SustainabilityResponse represents the sustainability checklist response
+type SustainabilityResponse struct {
+	Success     bool                             `json:"success"`
+	Message     string                           `json:"message"`
+	Practice    *data.SustainabilityPractice     `json:"practice,omitempty"`
+	Practices   []*data.SustainabilityPractice   `json:"practices,omitempty"`
+	Assessment  *data.SustainabilityAssessment   `json:"assessment,omitempty"`
+	Assessments []*data.SustainabilityAssessment `json:"assessments,omitempty"`
+}
+
+// Validate checks the practice request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *SustainabilityPracticeRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("category", req.Category)
+		v.Required("name", req.Name)
+	}
+	v.Check(req.Weight >= 0, "weight", "must be >= 0")
+	return v.Errors()
+}
+
+// Validate checks the assessment request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *SustainabilityAssessmentRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("season", req.Season)
+	}
+	seen := map[string]bool{}
+	for i, response := range req.Responses {
+		field := fmt.Sprintf("responses[%d]", i)
+		v.Required(field+".practiceId", response.PracticeID)
+		v.Required(field+".status", response.Status)
+		v.OneOf(field+".status", response.Status, "Implemented", "Partial", "Not Implemented", "Not Applicable")
+		v.Check(!seen[response.PracticeID], field+".practiceId", "is duplicated")
+		seen[response.PracticeID] = true
+	}
+	return v.Errors()
+}
+
+// assessmentInput converts the request to the service's input
+func (req *SustainabilityAssessmentRequest) assessmentInput() sustainability.AssessmentInput {
+	in := sustainability.AssessmentInput{Season: req.Season, AssessedAt: req.AssessedAt, AssessedBy: req.AssessedBy, Notes: req.Notes}
+	if req.Responses != nil {
+		in.Responses = make([]sustainability.ResponseInput, len(req.Responses))
+		for i, response := range req.Responses {
+			in.Responses[i] = sustainability.ResponseInput(response)
+		}
+	}
+	return in
+}
+
+// CreateSustainabilityPracticeHandler handles adding a practice to a farm's checklist
+func (app *Config) CreateSustainabilityPracticeHandler(w http.ResponseWriter, r *http.Request) {
+	var req SustainabilityPracticeRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	practice, err := app.Services.Sustainability.CreatePractice(r.Context(), user, farmID, sustainability.PracticeInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:  true,
+		Message:  "Practice created successfully",
+		Practice: practice,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// CreateDefaultSustainabilityPracticesHandler seeds a farm's checklist with
+// the standard practices. It refuses if the farm already has a checklist.
+func (app *Config) CreateDefaultSustainabilityPracticesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	practices, err := app.Services.Sustainability.CreateDefaultPractices(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:   true,
+		Message:   "Default checklist created successfully",
+		Practices: practices,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetSustainabilityPracticesHandler handles retrieving a farm's checklist
+func (app *Config) GetSustainabilityPracticesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	practices, err := app.Services.Sustainability.ListPractices(r.Context(), user, farmID, r.URL.Query().Get("active") == "true")
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:   true,
+		Message:   "Practices retrieved successfully",
+		Practices: practices,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateSustainabilityPracticeHandler handles checklist practice updates
+func (app *Config) UpdateSustainabilityPracticeHandler(w http.ResponseWriter, r *http.Request) {
+	var req SustainabilityPracticeRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	practiceID := resourceID(r)
+	if practiceID == "" {
+		app.errorJSON(w, errors.New("practice ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	practice, err := app.Services.Sustainability.UpdatePractice(r.Context(), user, practiceID, sustainability.PracticeInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:  true,
+		Message:  "Practice updated successfully",
+		Practice: practice,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteSustainabilityPracticeHandler handles removing a practice from a checklist
+func (app *Config) DeleteSustainabilityPracticeHandler(w http.ResponseWriter, r *http.Request) {
+	practiceID := resourceID(r)
+	if practiceID == "" {
+		app.errorJSON(w, errors.New("practice ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Sustainability.DeletePractice(r.Context(), user, practiceID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success: true,
+		Message: "Practice deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// CreateSustainabilityAssessmentHandler handles starting a self-assessment for a season
+func (app *Config) CreateSustainabilityAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req SustainabilityAssessmentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	assessment, err := app.Services.Sustainability.CreateAssessment(r.Context(), user, farmID, req.assessmentInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:    true,
+		Message:    "Assessment created successfully",
+		Assessment: assessment,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetSustainabilityAssessmentsHandler handles retrieving a farm's assessments,
+// optionally for one ?season=
+func (app *Config) GetSustainabilityAssessmentsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	assessments, err := app.Services.Sustainability.ListAssessments(r.Context(), user, farmID, r.URL.Query().Get("season"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:     true,
+		Message:     "Assessments retrieved successfully",
+		Assessments: assessments,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSustainabilityAssessmentHandler handles retrieving a single assessment with its responses
+func (app *Config) GetSustainabilityAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	assessmentID := resourceID(r)
+	if assessmentID == "" {
+		app.errorJSON(w, errors.New("assessment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	assessment, err := app.Services.Sustainability.GetAssessment(r.Context(), user, assessmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:    true,
+		Message:    "Assessment retrieved successfully",
+		Assessment: assessment,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateSustainabilityAssessmentHandler handles updating a draft assessment.
+// When responses are given they replace the existing answers.
+func (app *Config) UpdateSustainabilityAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	var req SustainabilityAssessmentRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	assessmentID := resourceID(r)
+	if assessmentID == "" {
+		app.errorJSON(w, errors.New("assessment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	assessment, err := app.Services.Sustainability.UpdateAssessment(r.Context(), user, assessmentID, req.assessmentInput())
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:    true,
+		Message:    "Assessment updated successfully",
+		Assessment: assessment,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// SubmitSustainabilityAssessmentHandler finalises an assessment once every
+// active practice is answered and required evidence is attached
+func (app *Config) SubmitSustainabilityAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	assessmentID := resourceID(r)
+	if assessmentID == "" {
+		app.errorJSON(w, errors.New("assessment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	assessment, err := app.Services.Sustainability.SubmitAssessment(r.Context(), user, assessmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success:    true,
+		Message:    "Assessment submitted successfully",
+		Assessment: assessment,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteSustainabilityAssessmentHandler handles assessment deletion
+func (app *Config) DeleteSustainabilityAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	assessmentID := resourceID(r)
+	if assessmentID == "" {
+		app.errorJSON(w, errors.New("assessment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Sustainability.DeleteAssessment(r.Context(), user, assessmentID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SustainabilityResponse{
+		Success: true,
+		Message: "Assessment deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ExportEvidencePackHandler exports an assessment as an evidence pack for
+// certification programs, as JSON or, with ?format=csv, as a CSV download
+func (app *Config) ExportEvidencePackHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		app.errorJSON(w, errors.New("format must be json or csv"), http.StatusBadRequest)
+		return
+	}
+
+	assessmentID := resourceID(r)
+	if assessmentID == "" {
+		app.errorJSON(w, errors.New("assessment ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	assessment, pack, err := app.Services.Sustainability.EvidencePack(r.Context(), user, assessmentID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	if format != "csv" {
+		app.writeJSON(w, http.StatusOK, pack)
+		return
+	}
+
+	filename := fmt.Sprintf("evidence-pack-%s.csv", assessment.SustainabilityAssessmentID)
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Farm", pack.FarmName, "Season", pack.Season, "Assessed", pack.AssessedAt.Format("2006-01-02"), "Score", strconv.FormatFloat(pack.ScorePercent, 'f', 2, 64) + "%"})
+	cw.Write([]string{"Category", "Practice", "Weight", "Status", "Evidence Required", "Evidence", "Evidence URL", "Notes"})
+	for _, item := range pack.Items {
+		cw.Write([]string{
+			item.Category,
+			item.Practice,
+			strconv.FormatFloat(item.Weight, 'f', -1, 64),
+			item.Status,
+			strconv.FormatBool(item.EvidenceRequired),
+			item.Evidence,
+			item.EvidenceURL,
+			item.Notes,
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.ErrorLog.Printf("Error writing evidence pack: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/crop"
+	"farm4u/service/field"
+	"farm4u/service/livestock"
+	"farm4u/service/offline"
+	"farm4u/service/workforce"
+	"fmt"
+	"net/http"
+)
+
+// maxSyncChanges caps the changes a single push may carry
+const maxSyncChanges = 500
+
+// SyncChangeRequest is one change pushed by an offline client. Data holds
+// the record's fields as for the entity's own create and update endpoints.
+type SyncChangeRequest struct {
+	Entity   string          `json:"entity"`   // field, crop, livestock or employee
+	Op       string          `json:"op"`       // create, update or delete
+	ID       string          `json:"id"`       // Record changed, for update and delete
+	ClientID string          `json:"clientId"` // Client's own ID for a created record; a create is applied once per clientId
+	Version  int             `json:"version"`  // Version last pulled, required for update and delete
+	Data     json.RawMessage `json:"data"`
+}
+
+// SyncPushRequest represents the offline sync push request body
+type SyncPushRequest struct {
+	Changes []SyncChangeRequest `json:"changes"`
+}
+
+// SyncResponse represents the offline sync response. Success is true for a
+// push only when every change was applied.
+type SyncResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Cursor  string            `json:"cursor,omitempty"`
+	HasMore bool              `json:"hasMore,omitempty"`
+	Changes []data.SyncChange `json:"changes,omitempty"`
+	Results []offline.Result  `json:"results,omitempty"`
+}
+
+// PullSyncHandler handles fetching the changes to a farm since the cursor in
+// the since query parameter
+func (app *Config) PullSyncHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	pull, err := app.Services.Offline.Pull(r.Context(), user, farmID, r.URL.Query().Get("since"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, SyncResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d changes", len(pull.Changes)),
+		Cursor:  pull.Cursor,
+		HasMore: pull.HasMore,
+		Changes: pull.Changes,
+	})
+}
+
+// PushSyncHandler handles applying the changes an offline client made to a
+// farm. Each change gets its own result; conflicts and rejected changes do
+// not stop the others.
+func (app *Config) PushSyncHandler(w http.ResponseWriter, r *http.Request) {
+	var req SyncPushRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+	if len(req.Changes) == 0 {
+		app.errorJSON(w, errors.New("at least one change is required"), http.StatusBadRequest)
+		return
+	}
+	if len(req.Changes) > maxSyncChanges {
+		app.errorJSON(w, fmt.Errorf("a push can hold at most %d changes", maxSyncChanges), http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	// Changes whose data fails validation are rejected here; the rest go to
+	// the service in their original order
+	results := make([]offline.Result, len(req.Changes))
+	var changes []offline.Change
+	var positions []int
+	for i, c := range req.Changes {
+		change := offline.Change{Entity: c.Entity, Op: c.Op, ID: c.ID, ClientID: c.ClientID, Version: c.Version}
+		if c.Op != offline.OpDelete && len(c.Data) > 0 {
+			in, err := syncInput(c.Entity, c.Data, c.Op == offline.OpUpdate)
+			if err != nil {
+				results[i] = offline.Result{Index: i, Entity: c.Entity, Op: c.Op, ID: c.ID, ClientID: c.ClientID,
+					Status: offline.StatusRejected, Message: err.Error()}
+				continue
+			}
+			change.Input = in
+		}
+		changes = append(changes, change)
+		positions = append(positions, i)
+	}
+
+	if len(changes) > 0 {
+		applied, err := app.Services.Offline.Push(r.Context(), user, farmID, changes)
+		if err != nil {
+			app.serviceError(w, err)
+			return
+		}
+		for j, i := range positions {
+			results[i] = applied[j]
+			results[i].Index = i
+		}
+	}
+
+	count := 0
+	for _, result := range results {
+		if result.Status == offline.StatusApplied {
+			count++
+		}
+	}
+	app.writeJSON(w, http.StatusOK, SyncResponse{
+		Success: count == len(results),
+		Message: fmt.Sprintf("%d of %d changes applied", count, len(results)),
+		Results: results,
+	})
+}
+
+// syncInput decodes and validates the data of a pushed change into the
+// entity service's input. An unknown entity is left to the service to reject.
+func syncInput(entity string, raw json.RawMessage, partial bool) (any, error) {
+	switch entity {
+	case data.SyncField:
+		var req FieldRequest
+		if err := decodeSyncData(raw, &req, req.Validate, partial); err != nil {
+			return nil, err
+		}
+		return field.Input(req), nil
+	case data.SyncCrop:
+		var req CropRequest
+		if err := decodeSyncData(raw, &req, req.Validate, partial); err != nil {
+			return nil, err
+		}
+		return crop.Input(req), nil
+	case data.SyncLivestock:
+		var req LivestockRequest
+		if err := decodeSyncData(raw, &req, req.Validate, partial); err != nil {
+			return nil, err
+		}
+		return livestock.Input(req), nil
+	case data.SyncEmployee:
+		var req EmployeeRequest
+		if err := decodeSyncData(raw, &req, req.Validate, partial); err != nil {
+			return nil, err
+		}
+		return workforce.EmployeeInput(req), nil
+	}
+	return nil, nil
+}
+
+// decodeSyncData decodes raw into req and validates it
+func decodeSyncData(raw json.RawMessage, req any, validate func(bool) ValidationErrors, partial bool) error {
+	if err := json.Unmarshal(raw, req); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	if errs := validate(partial); errs != nil {
+		return errs
+	}
+	return nil
+}
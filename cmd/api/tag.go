@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/tag"
+	"net/http"
+)
+
+// TagRequest represents the tag creation/update request body
+type TagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"` // Display colour, e.g. #2e7d32
+}
+
+// TaggingRequest represents the body naming the record to put a tag on
+type TaggingRequest struct {
+	RecordType string `json:"recordType"` // crop, livestock or document
+	RecordID   string `json:"recordId"`
+}
+
+// TagResponse represents the tag response
+type TagResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Tag     *data.Tag     `json:"tag,omitempty"`
+	Tags    []*data.Tag   `json:"tags,omitempty"`
+	Tagging *data.Tagging `json:"tagging,omitempty"`
+}
+
+// Validate checks the tag request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *TagRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+	}
+	v.Check(len(req.Name) <= 50, "name", "must be at most 50 characters")
+	v.Check(req.Color == "" || (len(req.Color) == 7 && req.Color[0] == '#'), "color", "must be a hex colour such as #2e7d32")
+	return v.Errors()
+}
+
+// Validate checks the tagging request fields
+func (req *TaggingRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Required("recordType", req.RecordType)
+	v.Required("recordId", req.RecordID)
+	v.OneOf("recordType", req.RecordType, tag.RecordTypes...)
+	return v.Errors()
+}
+
+// CreateTagHandler handles adding a tag to a farm
+func (app *Config) CreateTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := app.Services.Tag.Create(r.Context(), user, farmID, tag.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TagResponse{
+		Success: true,
+		Message: "Tag created successfully",
+		Tag:     t,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetTagsHandler handles retrieving the tags of a farm (?farmId=) or on a
+// record (?recordType=&recordId=)
+func (app *Config) GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	recordType := r.URL.Query().Get("recordType")
+	recordID := r.URL.Query().Get("recordId")
+	farmID := r.URL.Query().Get("farmId")
+	if farmID == "" && (recordType == "" || recordID == "") {
+		app.errorJSON(w, errors.New("farm ID, or record type and record ID, are required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var tags []*data.Tag
+	var err error
+	if recordType != "" && recordID != "" {
+		tags, err = app.Services.Tag.ForRecord(r.Context(), user, recordType, recordID)
+	} else {
+		tags, err = app.Services.Tag.List(r.Context(), user, farmID)
+	}
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TagResponse{
+		Success: true,
+		Message: "Tags retrieved successfully",
+		Tags:    tags,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateTagHandler handles renaming or recolouring a tag
+func (app *Config) UpdateTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	tagID := resourceID(r)
+	if tagID == "" {
+		app.errorJSON(w, errors.New("tag ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	t, err := app.Services.Tag.Update(r.Context(), user, tagID, tag.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TagResponse{
+		Success: true,
+		Message: "Tag updated successfully",
+		Tag:     t,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteTagHandler handles deleting a tag and taking it off every record
+func (app *Config) DeleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	tagID := resourceID(r)
+	if tagID == "" {
+		app.errorJSON(w, errors.New("tag ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Tag.Delete(r.Context(), user, tagID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TagResponse{
+		Success: true,
+		Message: "Tag deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// TagRecordHandler handles putting a tag on a crop, livestock or document
+func (app *Config) TagRecordHandler(w http.ResponseWriter, r *http.Request) {
+	var req TaggingRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	tagID := resourceID(r)
+	if tagID == "" {
+		app.errorJSON(w, errors.New("tag ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	tagging, err := app.Services.Tag.Attach(r.Context(), user, tagID, req.RecordType, req.RecordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TagResponse{
+		Success: true,
+		Message: "Record tagged successfully",
+		Tagging: tagging,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UntagRecordHandler handles taking a tag off a record
+// (/api/tags/{id}/records?recordType=&recordId=)
+func (app *Config) UntagRecordHandler(w http.ResponseWriter, r *http.Request) {
+	tagID := resourceID(r)
+	if tagID == "" {
+		app.errorJSON(w, errors.New("tag ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	recordType := r.URL.Query().Get("recordType")
+	recordID := r.URL.Query().Get("recordId")
+	if recordType == "" || recordID == "" {
+		app.errorJSON(w, errors.New("record type and record ID are required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Tag.Detach(r.Context(), user, tagID, recordType, recordID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TagResponse{
+		Success: true,
+		Message: "Tag removed from record successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"farm4u/data"
+	"farm4u/service/finance"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// TaxRateRequest represents the tax rate creation/update request body
+type TaxRateRequest struct {
+	Name         string   `json:"name"`
+	Jurisdiction string   `json:"jurisdiction"`
+	Basis        string   `json:"basis"`
+	Category     string   `json:"category"`
+	Rate         *float64 `json:"rate"`
+	Inclusive    *bool    `json:"inclusive"`
+	Active       *bool    `json:"active"`
+	Notes        string   `json:"notes"`
+}
+
+// TaxRateResponse represents the tax rate response
+type TaxRateResponse struct {
+	Success  bool            `json:"success"`
+	Message  string          `json:"message"`
+	TaxRate  *data.TaxRate   `json:"taxRate,omitempty"`
+	TaxRates []*data.TaxRate `json:"taxRates,omitempty"`
+}
+
+// TaxSummaryResponse represents the tax summary response
+type TaxSummaryResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message"`
+	Summary *finance.TaxSummary `json:"summary"`
+}
+
+// Validate checks the tax rate request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *TaxRateRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("jurisdiction", req.Jurisdiction)
+		v.Required("basis", req.Basis)
+		v.Check(req.Rate != nil, "rate", "is required")
+	}
+	v.OneOf("basis", req.Basis, data.TaxBasisSales, data.TaxBasisPurchases, data.TaxBasisWages)
+	v.Check(req.Basis != data.TaxBasisWages || req.Category == "", "category", "does not apply to the Wages basis")
+	v.Check(req.Rate == nil || (*req.Rate >= 0 && *req.Rate <= 100), "rate", "must be between 0 and 100")
+	return v.Errors()
+}
+
+// CreateTaxRateHandler handles configuring a tax on a farm
+func (app *Config) CreateTaxRateHandler(w http.ResponseWriter, r *http.Request) {
+	var req TaxRateRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := app.Services.Finance.CreateTaxRate(r.Context(), user, farmID, finance.TaxRateInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TaxRateResponse{
+		Success: true,
+		Message: "Tax rate created successfully",
+		TaxRate: rate,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetTaxRatesHandler handles retrieving a farm's tax rates
+func (app *Config) GetTaxRatesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	rates, err := app.Services.Finance.ListTaxRates(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TaxRateResponse{
+		Success:  true,
+		Message:  "Tax rates retrieved successfully",
+		TaxRates: rates,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetTaxRateHandler handles retrieving a single tax rate by ID
+func (app *Config) GetTaxRateHandler(w http.ResponseWriter, r *http.Request) {
+	taxRateID := resourceID(r)
+	if taxRateID == "" {
+		app.errorJSON(w, errors.New("tax rate ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := app.Services.Finance.GetTaxRate(r.Context(), user, taxRateID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TaxRateResponse{
+		Success: true,
+		Message: "Tax rate retrieved successfully",
+		TaxRate: rate,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateTaxRateHandler handles tax rate updates
+func (app *Config) UpdateTaxRateHandler(w http.ResponseWriter, r *http.Request) {
+	var req TaxRateRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	taxRateID := resourceID(r)
+	if taxRateID == "" {
+		app.errorJSON(w, errors.New("tax rate ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	rate, err := app.Services.Finance.UpdateTaxRate(r.Context(), user, taxRateID, finance.TaxRateInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TaxRateResponse{
+		Success: true,
+		Message: "Tax rate updated successfully",
+		TaxRate: rate,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteTaxRateHandler handles tax rate deletion
+func (app *Config) DeleteTaxRateHandler(w http.ResponseWriter, r *http.Request) {
+	taxRateID := resourceID(r)
+	if taxRateID == "" {
+		app.errorJSON(w, errors.New("tax rate ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Finance.DeleteTaxRate(r.Context(), user, taxRateID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := TaxRateResponse{
+		Success: true,
+		Message: "Tax rate deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetTaxSummaryHandler reports the tax due for a farm over ?from=/?to= (the
+// current quarter by default), optionally for one ?jurisdiction=, as JSON or,
+// with ?format=csv, as a CSV download for submission
+func (app *Config) GetTaxSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		app.errorJSON(w, errors.New("format must be json or csv"), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := app.Services.Finance.TaxSummary(r.Context(), user, farmID, r.URL.Query().Get("jurisdiction"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	if format != "csv" {
+		response := TaxSummaryResponse{
+			Success: true,
+			Message: "Tax summary generated successfully",
+			Summary: summary,
+		}
+		app.writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	// The period end is exclusive; the export shows the last day covered
+	periodEnd := summary.To.AddDate(0, 0, -1).Format("2006-01-02")
+	filename := fmt.Sprintf("tax-summary-%s-%s.csv", summary.From.Format("2006-01-02"), periodEnd)
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Farm", summary.FarmID, "Period", summary.From.Format("2006-01-02"), periodEnd})
+	cw.Write([]string{"Jurisdiction", "Tax", "Basis", "Category", "Rate %", "Inclusive", "Entries", "Taxable Amount", "Tax"})
+	for _, line := range summary.Lines {
+		cw.Write([]string{
+			line.Jurisdiction,
+			line.Name,
+			line.Basis,
+			line.Category,
+			strconv.FormatFloat(line.Rate, 'f', -1, 64),
+			strconv.FormatBool(line.Inclusive),
+			strconv.Itoa(line.Entries),
+			money(line.Base),
+			money(line.Tax),
+		})
+	}
+	cw.Write([]string{})
+	cw.Write([]string{"Jurisdiction", "Due", "Credits", "Net Payable"})
+	for _, total := range summary.Jurisdictions {
+		cw.Write([]string{total.Jurisdiction, money(total.Due), money(total.Credits), money(total.NetPayable)})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		app.ErrorLog.Printf("Error writing tax summary: %v", err)
+	}
+}
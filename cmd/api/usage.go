@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// usageFlushInterval is how often buffered API usage counts are written to the database
+const usageFlushInterval = time.Minute
+
+// usageKey identifies one user/day/endpoint counter
+type usageKey struct {
+	userID   int
+	date     string
+	endpoint string
+}
+
+// usageRecorder buffers API call counts in memory between flushes so that
+// requests do not each cost a database write
+type usageRecorder struct {
+	mu     sync.Mutex
+	counts map[usageKey]int64
+}
+
+// newUsageRecorder creates an empty usage recorder
+func newUsageRecorder() *usageRecorder {
+	return &usageRecorder{counts: map[usageKey]int64{}}
+}
+
+// record counts one call by a user to the route that served r
+func (u *usageRecorder) record(userID int, r *http.Request) {
+	endpoint := r.URL.Path
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+		endpoint = rctx.RoutePattern()
+	}
+
+	key := usageKey{
+		userID:   userID,
+		date:     time.Now().UTC().Format("2006-01-02"),
+		endpoint: r.Method + " " + endpoint,
+	}
+
+	u.mu.Lock()
+	u.counts[key]++
+	u.mu.Unlock()
+}
+
+// take returns the buffered counts and resets the buffer
+func (u *usageRecorder) take() map[usageKey]int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	counts := u.counts
+	u.counts = map[usageKey]int64{}
+	return counts
+}
+
+// APIUsageDay is one user's total calls on a day
+type APIUsageDay struct {
+	Date  string `json:"date"`
+	Total int64  `json:"total"`
+}
+
+// APIUsageResponse represents the API usage report response
+type APIUsageResponse struct {
+	Success      bool             `json:"success"`
+	Message      string           `json:"message"`
+	RateLimit    int              `json:"rateLimit"`
+	RateWindow   string           `json:"rateWindow"`
+	Days         []APIUsageDay    `json:"days"`
+	ByEndpoint   []*data.APIUsage `json:"byEndpoint"`
+	TotalInRange int64            `json:"totalInRange"`
+}
+
+// GetMyAPIUsageHandler summarises the authenticated user's API calls per day
+// and endpoint over ?from=/?to= (default: the last 30 days). Counts are
+// written in batches, so the most recent minute may not be included yet.
+func (app *Config) GetMyAPIUsageHandler(w http.ResponseWriter, r *http.Request) {
+	fromParam, toParam, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	to := today.AddDate(0, 0, 1)
+	if toParam != nil {
+		to = *toParam
+	}
+	from := to.AddDate(0, 0, -30)
+	if fromParam != nil {
+		from = *fromParam
+	}
+
+	usages, err := app.Models.APIUsage.GetByUserID(r.Context(), user.UserID, from, to)
+	if err != nil {
+		app.ErrorLog.Printf("Error getting API usage: %v", err)
+		app.errorJSON(w, errors.New("internal server error"), http.StatusInternalServerError)
+		return
+	}
+
+	response := APIUsageResponse{
+		Success:    true,
+		Message:    "API usage retrieved successfully",
+		RateLimit:  app.APIRateLimit,
+		RateWindow: apiRateWindow.String(),
+		Days:       []APIUsageDay{},
+		ByEndpoint: usages,
+	}
+
+	for _, usage := range usages {
+		date := usage.Date.Format("2006-01-02")
+		if n := len(response.Days); n == 0 || response.Days[n-1].Date != date {
+			response.Days = append(response.Days, APIUsageDay{Date: date})
+		}
+		response.Days[len(response.Days)-1].Total += usage.Count
+		response.TotalInRange += usage.Count
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// flushAPIUsage periodically writes buffered usage counts to the database. It
+// flushes once more and returns when app.Done is closed.
+func (app *Config) flushAPIUsage() {
+	ctx := context.Background()
+	ticker := time.NewTicker(usageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-app.Done:
+			app.writeAPIUsage(ctx)
+			return
+		case <-ticker.C:
+			app.writeAPIUsage(ctx)
+		}
+	}
+}
+
+// writeAPIUsage saves the buffered counts, resolving numeric user IDs from
+// the JWT claims to user UUIDs
+func (app *Config) writeAPIUsage(ctx context.Context) {
+	counts := app.APIUsage.take()
+	if len(counts) == 0 {
+		return
+	}
+
+	userIDs := map[int]string{}
+	usages := make([]*data.APIUsage, 0, len(counts))
+	for key, count := range counts {
+		userID, ok := userIDs[key.userID]
+		if !ok {
+			user, err := app.Models.User.GetOne(ctx, key.userID)
+			if err != nil || user == nil {
+				app.ErrorLog.Printf("Error resolving user %d for API usage: %v", key.userID, err)
+				userIDs[key.userID] = ""
+				continue
+			}
+			userID = user.UserID
+			userIDs[key.userID] = userID
+		}
+		if userID == "" {
+			continue
+		}
+
+		date, _ := time.Parse("2006-01-02", key.date)
+		usages = append(usages, &data.APIUsage{
+			UserID:   userID,
+			Date:     date,
+			Endpoint: key.endpoint,
+			Count:    count,
+		})
+	}
+
+	if err := app.Models.APIUsage.Add(ctx, usages); err != nil {
+		app.ErrorLog.Printf("Error saving API usage: %v", err)
+	}
+}
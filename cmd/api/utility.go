@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/utility"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UtilityRecordRequest represents the utility record creation/update request body
+type UtilityRecordRequest struct {
+	UtilityType  string     `json:"utilityType"`
+	RecordType   string     `json:"recordType"`
+	Date         *time.Time `json:"date"`
+	MeterReading *float64   `json:"meterReading"`
+	Quantity     float64    `json:"quantity"`
+	Unit         string     `json:"unit"`
+	Cost         float64    `json:"cost"`
+	Provider     string     `json:"provider"`
+	Notes        string     `json:"notes"`
+}
+
+// UtilityRecordResponse represents the utility record response
+type UtilityRecordResponse struct {
+	Success        bool                       `json:"success"`
+	Message        string                     `json:"message"`
+	UtilityRecord  *data.UtilityRecord        `json:"utilityRecord,omitempty"`
+	UtilityRecords []*data.UtilityRecord      `json:"utilityRecords,omitempty"`
+	Monthly        []data.MonthlyUtilityTotal `json:"monthly,omitempty"`
+}
+
+// Validate checks the utility record request fields. When partial is true
+// only the fields that are present are checked, as used by updates.
+func (req *UtilityRecordRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("utilityType", req.UtilityType)
+		v.Required("recordType", req.RecordType)
+		v.Check(req.RecordType != "Reading" || req.MeterReading != nil, "meterReading", "is required for readings")
+	}
+	v.OneOf("utilityType", req.UtilityType, "Electricity", "Diesel", "Water")
+	v.OneOf("recordType", req.RecordType, "Reading", "Bill")
+	v.Check(req.MeterReading == nil || *req.MeterReading >= 0, "meterReading", "must be >= 0")
+	v.Check(req.Quantity >= 0, "quantity", "must be >= 0")
+	v.Check(req.Cost >= 0, "cost", "must be >= 0")
+	return v.Errors()
+}
+
+// CreateUtilityRecordHandler handles recording a meter reading or utility bill.
+// For readings the consumption is the difference from the previous reading.
+func (app *Config) CreateUtilityRecordHandler(w http.ResponseWriter, r *http.Request) {
+	var req UtilityRecordRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Utility.Create(r.Context(), user, farmID, utility.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := UtilityRecordResponse{
+		Success:       true,
+		Message:       "Utility record created successfully",
+		UtilityRecord: record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetUtilityRecordsHandler handles retrieving a farm's utility records,
+// optionally filtered by ?type= and ?from=/?to=
+func (app *Config) GetUtilityRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	records, err := app.Services.Utility.List(r.Context(), user, farmID, r.URL.Query().Get("type"), from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := UtilityRecordResponse{
+		Success:        true,
+		Message:        "Utility records retrieved successfully",
+		UtilityRecords: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetUtilityRecordHandler handles retrieving a single utility record by ID
+func (app *Config) GetUtilityRecordHandler(w http.ResponseWriter, r *http.Request) {
+	utilityRecordID := resourceID(r)
+	if utilityRecordID == "" {
+		app.errorJSON(w, errors.New("utility record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Utility.Get(r.Context(), user, utilityRecordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := UtilityRecordResponse{
+		Success:       true,
+		Message:       "Utility record retrieved successfully",
+		UtilityRecord: record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateUtilityRecordHandler handles utility record updates
+func (app *Config) UpdateUtilityRecordHandler(w http.ResponseWriter, r *http.Request) {
+	var req UtilityRecordRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	utilityRecordID := resourceID(r)
+	if utilityRecordID == "" {
+		app.errorJSON(w, errors.New("utility record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Utility.Update(r.Context(), user, utilityRecordID, utility.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := UtilityRecordResponse{
+		Success:       true,
+		Message:       "Utility record updated successfully",
+		UtilityRecord: record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteUtilityRecordHandler handles utility record deletion
+func (app *Config) DeleteUtilityRecordHandler(w http.ResponseWriter, r *http.Request) {
+	utilityRecordID := resourceID(r)
+	if utilityRecordID == "" {
+		app.errorJSON(w, errors.New("utility record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Utility.Delete(r.Context(), user, utilityRecordID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := UtilityRecordResponse{
+		Success: true,
+		Message: "Utility record deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetMonthlyUtilityConsumptionHandler reports consumption and cost per month
+// and utility for a calendar year (?year=, defaults to the current year)
+func (app *Config) GetMonthlyUtilityConsumptionHandler(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year()
+	if v := r.URL.Query().Get("year"); v != "" {
+		y, err := strconv.Atoi(v)
+		if err != nil || y < 1900 || y > 9999 {
+			app.errorJSON(w, errors.New("year must be a valid year"), http.StatusBadRequest)
+			return
+		}
+		year = y
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	totals, err := app.Services.Utility.Monthly(r.Context(), user, farmID, year)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := UtilityRecordResponse{
+		Success: true,
+		Message: "Monthly utility consumption retrieved successfully",
+		Monthly: totals,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
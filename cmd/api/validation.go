@@ -0,0 +1,117 @@
+package main
+
+import (
+	"farm4u/service/auth"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+)
+
+// ValidationErrors maps request field names to what is wrong with them
+type ValidationErrors map[string]string
+
+// Error implements the error interface
+func (v ValidationErrors) Error() string {
+	return "validation failed"
+}
+
+// validationResponse is the body returned for requests that fail validation
+type validationResponse struct {
+	Error   bool             `json:"error"`
+	Message string           `json:"message"`
+	Errors  ValidationErrors `json:"errors"`
+}
+
+// validator collects field-level errors for a request. Only the first error
+// recorded for a field is kept.
+type validator struct {
+	errors ValidationErrors
+}
+
+// newValidator creates an empty validator
+func newValidator() *validator {
+	return &validator{errors: ValidationErrors{}}
+}
+
+// Check records message against field unless ok is true
+func (v *validator) Check(ok bool, field, message string) {
+	if ok {
+		return
+	}
+	if _, exists := v.errors[field]; !exists {
+		v.errors[field] = message
+	}
+}
+
+// Required records an error if value is blank
+func (v *validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// Email records an error if value is not a valid email address
+func (v *validator) Email(field, value string) {
+	if value == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	v.Check(err == nil && addr.Address == value, field, "must be a valid email address")
+}
+
+// Password records an error if value is set and does not meet policy.
+// personal holds details of the account the password must not be.
+func (v *validator) Password(field, value string, policy auth.PasswordPolicy, personal ...string) {
+	if value == "" {
+		return
+	}
+	problems := policy.Problems(value, personal...)
+	v.Check(len(problems) == 0, field, strings.Join(problems, "; "))
+}
+
+// Phone records an error if value is set and is not a phone number
+func (v *validator) Phone(field, value string) {
+	if value == "" {
+		return
+	}
+	_, ok := auth.NormalizePhone(value)
+	v.Check(ok, field, "must be a phone number of 7 to 15 digits, e.g. +256772123456")
+}
+
+// OneOf records an error if value is set and not one of the allowed values
+func (v *validator) OneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.Check(false, field, fmt.Sprintf("must be one of %s", strings.Join(allowed, ", ")))
+}
+
+// Merge records errs, as found by another validation of the same request
+func (v *validator) Merge(errs ValidationErrors) {
+	for field, message := range errs {
+		v.Check(false, field, message)
+	}
+}
+
+// Errors returns the collected errors, or nil if the request is valid
+func (v *validator) Errors() ValidationErrors {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
+
+// failedValidation writes a 422 response listing the field errors
+func (app *Config) failedValidation(w http.ResponseWriter, errs ValidationErrors) error {
+	payload := validationResponse{
+		Error:   true,
+		Message: errs.Error(),
+		Errors:  errs,
+	}
+
+	return app.writeJSON(w, http.StatusUnprocessableEntity, payload)
+}
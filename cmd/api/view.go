@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/view"
+	"net/http"
+)
+
+// SavedViewRequest represents the saved view creation/update request body
+type SavedViewRequest struct {
+	Name   string            `json:"name"`
+	Entity string            `json:"entity"` // The list filtered, e.g. livestock
+	Params map[string]string `json:"params"` // Query parameters of the list, e.g. {"tag": "organic"}
+}
+
+// SavedViewResponse represents the saved view response
+type SavedViewResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	View    *data.SavedView   `json:"view,omitempty"`
+	Views   []*data.SavedView `json:"views,omitempty"`
+}
+
+// Validate checks the saved view request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *SavedViewRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("entity", req.Entity)
+	}
+	v.Check(len(req.Name) <= 100, "name", "must be at most 100 characters")
+	if req.Entity != "" {
+		v.OneOf("entity", req.Entity, view.Entities...)
+	}
+	return v.Errors()
+}
+
+// CreateSavedViewHandler handles saving a named filter combination for a farm
+func (app *Config) CreateSavedViewHandler(w http.ResponseWriter, r *http.Request) {
+	var req SavedViewRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := app.Services.View.Create(r.Context(), user, farmID, view.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SavedViewResponse{
+		Success: true,
+		Message: "View saved successfully",
+		View:    v,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetSavedViewsHandler handles listing the views the user saved on a farm
+// (/api/views?farmId=&entity=)
+func (app *Config) GetSavedViewsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	views, err := app.Services.View.List(r.Context(), user, farmID, r.URL.Query().Get("entity"))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SavedViewResponse{
+		Success: true,
+		Message: "Views retrieved successfully",
+		Views:   views,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetSavedViewHandler handles retrieving a saved view
+func (app *Config) GetSavedViewHandler(w http.ResponseWriter, r *http.Request) {
+	viewID := resourceID(r)
+	if viewID == "" {
+		app.errorJSON(w, errors.New("view ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := app.Services.View.Get(r.Context(), user, viewID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SavedViewResponse{
+		Success: true,
+		Message: "View retrieved successfully",
+		View:    v,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateSavedViewHandler handles renaming a saved view or changing its
+// filters
+func (app *Config) UpdateSavedViewHandler(w http.ResponseWriter, r *http.Request) {
+	var req SavedViewRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	viewID := resourceID(r)
+	if viewID == "" {
+		app.errorJSON(w, errors.New("view ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := app.Services.View.Update(r.Context(), user, viewID, view.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SavedViewResponse{
+		Success: true,
+		Message: "View updated successfully",
+		View:    v,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteSavedViewHandler handles deleting a saved view
+func (app *Config) DeleteSavedViewHandler(w http.ResponseWriter, r *http.Request) {
+	viewID := resourceID(r)
+	if viewID == "" {
+		app.errorJSON(w, errors.New("view ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.View.Delete(r.Context(), user, viewID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := SavedViewResponse{
+		Success: true,
+		Message: "View deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/water"
+	"net/http"
+	"time"
+)
+
+// WaterSourceRequest represents the water source creation/update request body
+type WaterSourceRequest struct {
+	Name           string     `json:"name"`
+	SourceType     string     `json:"sourceType"`
+	PermitNumber   string     `json:"permitNumber"`
+	PermitExpiry   *time.Time `json:"permitExpiry"`
+	DailyLimit     float64    `json:"dailyLimit"`
+	AnnualLimit    float64    `json:"annualLimit"`
+	AlertThreshold float64    `json:"alertThreshold"`
+	Status         string     `json:"status"`
+	Notes          string     `json:"notes"`
+}
+
+// WaterUsageRequest represents the water usage logging request body
+type WaterUsageRequest struct {
+	Date    *time.Time `json:"date"`
+	Volume  float64    `json:"volume"`
+	Purpose string     `json:"purpose"`
+	Notes   string     `json:"notes"`
+}
+
+// WaterSourceResponse represents the water source response
+type WaterSourceResponse struct {
+	Success      bool                `json:"success"`
+	Message      string              `json:"message"`
+	WaterSource  *data.WaterSource   `json:"waterSource,omitempty"`
+	WaterSources []*data.WaterSource `json:"waterSources,omitempty"`
+	Usage        *data.WaterUsage    `json:"usage,omitempty"`
+	Usages       []*data.WaterUsage  `json:"usages,omitempty"`
+	Alerts       []water.Alert       `json:"alerts,omitempty"`
+}
+
+// Validate checks the water source request fields. When partial is true only
+// the fields that are present are checked, as used by updates.
+func (req *WaterSourceRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("name", req.Name)
+		v.Required("sourceType", req.SourceType)
+	}
+	v.Check(req.DailyLimit >= 0, "dailyLimit", "must be >= 0")
+	v.Check(req.AnnualLimit >= 0, "annualLimit", "must be >= 0")
+	v.Check(req.AlertThreshold >= 0 && req.AlertThreshold <= 100, "alertThreshold", "must be between 0 and 100")
+	v.OneOf("status", req.Status, "Active", "Inactive", "Decommissioned")
+	return v.Errors()
+}
+
+// Validate checks the water usage request fields
+func (req *WaterUsageRequest) Validate() ValidationErrors {
+	v := newValidator()
+	v.Check(req.Volume > 0, "volume", "must be greater than 0")
+	return v.Errors()
+}
+
+// CreateWaterSourceHandler handles water source creation
+func (app *Config) CreateWaterSourceHandler(w http.ResponseWriter, r *http.Request) {
+	var req WaterSourceRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	source, err := app.Services.Water.Create(r.Context(), user, farmID, water.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:     true,
+		Message:     "Water source created successfully",
+		WaterSource: source,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetWaterSourcesHandler handles retrieving all water sources for a farm
+func (app *Config) GetWaterSourcesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	sources, err := app.Services.Water.List(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:      true,
+		Message:      "Water sources retrieved successfully",
+		WaterSources: sources,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWaterSourceHandler handles retrieving a single water source by ID
+func (app *Config) GetWaterSourceHandler(w http.ResponseWriter, r *http.Request) {
+	waterSourceID := resourceID(r)
+	if waterSourceID == "" {
+		app.errorJSON(w, errors.New("water source ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	source, err := app.Services.Water.Get(r.Context(), user, waterSourceID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:     true,
+		Message:     "Water source retrieved successfully",
+		WaterSource: source,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateWaterSourceHandler handles water source updates
+func (app *Config) UpdateWaterSourceHandler(w http.ResponseWriter, r *http.Request) {
+	var req WaterSourceRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	waterSourceID := resourceID(r)
+	if waterSourceID == "" {
+		app.errorJSON(w, errors.New("water source ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	source, err := app.Services.Water.Update(r.Context(), user, waterSourceID, water.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:     true,
+		Message:     "Water source updated successfully",
+		WaterSource: source,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteWaterSourceHandler handles water source deletion
+func (app *Config) DeleteWaterSourceHandler(w http.ResponseWriter, r *http.Request) {
+	waterSourceID := resourceID(r)
+	if waterSourceID == "" {
+		app.errorJSON(w, errors.New("water source ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Water.Delete(r.Context(), user, waterSourceID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success: true,
+		Message: "Water source deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// LogWaterUsageHandler records water abstracted from a source and reports any
+// permit alerts the new entry triggers
+func (app *Config) LogWaterUsageHandler(w http.ResponseWriter, r *http.Request) {
+	var req WaterUsageRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	waterSourceID := resourceID(r)
+	if waterSourceID == "" {
+		app.errorJSON(w, errors.New("water source ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	usage, alerts, err := app.Services.Water.LogUsage(r.Context(), user, waterSourceID, water.UsageInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success: true,
+		Message: "Water usage logged successfully",
+		Usage:   usage,
+		Alerts:  alerts,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetWaterUsageHandler handles retrieving the usage log for a water source
+func (app *Config) GetWaterUsageHandler(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	waterSourceID := resourceID(r)
+	if waterSourceID == "" {
+		app.errorJSON(w, errors.New("water source ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	source, usages, err := app.Services.Water.Usage(r.Context(), user, waterSourceID, from, to)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:     true,
+		Message:     "Water usage retrieved successfully",
+		WaterSource: source,
+		Usages:      usages,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWaterAlertsHandler lists permit alerts across all water sources of a farm
+func (app *Config) GetWaterAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	alerts, err := app.Services.Water.Alerts(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success: true,
+		Message: "Water alerts retrieved successfully",
+		Alerts:  alerts,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetDeletedWaterSourcesHandler handles listing soft-deleted water sources of a farm
+func (app *Config) GetDeletedWaterSourcesHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	waterSources, err := app.Services.Water.ListDeleted(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:      true,
+		Message:      "Deleted water sources retrieved successfully",
+		WaterSources: waterSources,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RestoreWaterSourceHandler handles restoring a soft-deleted water source
+func (app *Config) RestoreWaterSourceHandler(w http.ResponseWriter, r *http.Request) {
+	waterSourceID := resourceID(r)
+	if waterSourceID == "" {
+		app.errorJSON(w, errors.New("water source ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	waterSource, err := app.Services.Water.Restore(r.Context(), user, waterSourceID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WaterSourceResponse{
+		Success:     true,
+		Message:     "Water source restored successfully",
+		WaterSource: waterSource,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
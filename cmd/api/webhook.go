@@ -0,0 +1,321 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/integration"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WebhookRequest represents the webhook creation/update request body
+type WebhookRequest struct {
+	URL           string   `json:"url"` // Must use https
+	Description   string   `json:"description"`
+	EventTypes    []string `json:"eventTypes"`    // See GET /webhooks/events; * for all
+	DigestSize    *int     `json:"digestSize"`    // Most events per digest, 0 to 100; 0 sends each on its own
+	DigestMinutes *int     `json:"digestMinutes"` // Send held events together every this many minutes, 0 to 1440
+	Active        *bool    `json:"active"`        // True if omitted
+}
+
+// WebhookResponse represents the webhook response
+type WebhookResponse struct {
+	Success    bool                    `json:"success"`
+	Message    string                  `json:"message"`
+	Webhook    *data.Webhook           `json:"webhook,omitempty"`
+	Webhooks   []*data.Webhook         `json:"webhooks,omitempty"`
+	Deliveries []*data.WebhookDelivery `json:"deliveries,omitempty"`
+	Events     []string                `json:"events,omitempty"`
+	Queued     int                     `json:"queued,omitempty"` // Deliveries queued by a replay
+	// Secret is only returned when a webhook is created or its secret rotated
+	Secret string `json:"secret,omitempty"`
+}
+
+// Validate checks the webhook request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *WebhookRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("url", req.URL)
+		v.Check(len(req.EventTypes) > 0, "eventTypes", "at least one event type is required")
+	}
+	for _, event := range req.EventTypes {
+		v.OneOf("eventTypes", event, append(integration.Events(), "*")...)
+	}
+	v.Check(req.DigestSize == nil || (*req.DigestSize >= 0 && *req.DigestSize <= 100), "digestSize", "must be between 0 and 100")
+	v.Check(req.DigestMinutes == nil || (*req.DigestMinutes >= 0 && *req.DigestMinutes <= 1440), "digestMinutes", "must be between 0 and 1440")
+	return v.Errors()
+}
+
+// CreateWebhookHandler handles subscribing a webhook to a farm's events. The
+// signing secret is only returned here.
+func (app *Config) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	hook, secret, err := app.Services.Integration.CreateWebhook(r.Context(), user, farmID, integration.WebhookInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success: true,
+		Message: "Webhook created successfully",
+		Webhook: hook,
+		Secret:  secret,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// GetWebhooksHandler handles listing a farm's webhooks
+func (app *Config) GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	hooks, err := app.Services.Integration.ListWebhooks(r.Context(), user, farmID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success:  true,
+		Message:  "Webhooks retrieved successfully",
+		Webhooks: hooks,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWebhookEventsHandler handles listing the event types webhooks can
+// subscribe to
+func (app *Config) GetWebhookEventsHandler(w http.ResponseWriter, r *http.Request) {
+	response := WebhookResponse{
+		Success: true,
+		Message: "Webhook events retrieved successfully",
+		Events:  integration.Events(),
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWebhookHandler handles retrieving a single webhook by ID
+func (app *Config) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID := resourceID(r)
+	if webhookID == "" {
+		app.errorJSON(w, errors.New("webhook ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	hook, err := app.Services.Integration.GetWebhook(r.Context(), user, webhookID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success: true,
+		Message: "Webhook retrieved successfully",
+		Webhook: hook,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateWebhookHandler handles changing a webhook's URL, description, events
+// or digest, or pausing and resuming it with active
+func (app *Config) UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	webhookID := resourceID(r)
+	if webhookID == "" {
+		app.errorJSON(w, errors.New("webhook ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	hook, err := app.Services.Integration.UpdateWebhook(r.Context(), user, webhookID, integration.WebhookInput(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success: true,
+		Message: "Webhook updated successfully",
+		Webhook: hook,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteWebhookHandler handles deleting a webhook
+func (app *Config) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID := resourceID(r)
+	if webhookID == "" {
+		app.errorJSON(w, errors.New("webhook ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Integration.DeleteWebhook(r.Context(), user, webhookID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success: true,
+		Message: "Webhook deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWebhookDeliveriesHandler handles a webhook's delivery log: its most
+// recent events, with the status and error of their last attempt
+func (app *Config) GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID := resourceID(r)
+	if webhookID == "" {
+		app.errorJSON(w, errors.New("webhook ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := app.Services.Integration.ListDeliveries(r.Context(), user, webhookID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success:    true,
+		Message:    "Webhook deliveries retrieved successfully",
+		Deliveries: deliveries,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// RotateWebhookSecretHandler handles giving a webhook a new signing secret,
+// which is only returned here. The old secret keeps signing deliveries
+// alongside it for a day.
+func (app *Config) RotateWebhookSecretHandler(w http.ResponseWriter, r *http.Request) {
+	webhookID := resourceID(r)
+	if webhookID == "" {
+		app.errorJSON(w, errors.New("webhook ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	hook, secret, err := app.Services.Integration.RotateSecret(r.Context(), user, webhookID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success: true,
+		Message: "Webhook secret rotated successfully",
+		Webhook: hook,
+		Secret:  secret,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// ReplayWebhookDeliveriesHandler handles queuing again the events one of a
+// farm's webhooks was sent between the ?from= and ?to= dates (YYYY-MM-DD,
+// both inclusive; to defaults to now), for a receiver that missed them
+func (app *Config) ReplayWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	farmID := resourceID(r)
+	webhookID := chi.URLParam(r, "webhookID")
+	if farmID == "" || webhookID == "" {
+		app.errorJSON(w, errors.New("farm ID and webhook ID are required"), http.StatusBadRequest)
+		return
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+	if from == nil {
+		app.errorJSON(w, errors.New("from is required"), http.StatusBadRequest)
+		return
+	}
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	queued, err := app.Services.Integration.ReplayDeliveries(r.Context(), user, farmID, webhookID, *from, end)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WebhookResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d webhook deliveries queued for replay", queued),
+		Queued:  queued,
+	}
+
+	app.writeJSON(w, http.StatusAccepted, response)
+}
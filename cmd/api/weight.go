@@ -0,0 +1,259 @@
+package main
+
+import (
+	"errors"
+	"farm4u/data"
+	"farm4u/service/growth"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WeightRequest represents the weight record creation/update request body,
+// and one entry of a weigh day sent to the batch endpoint
+type WeightRequest struct {
+	LivestockID string     `json:"livestockId"` // Herd weighed, or the animal's herd
+	AnimalTag   string     `json:"animalTag"`   // One animal of the herd; empty for the herd average
+	Date        *time.Time `json:"date"`        // Defaults to now
+	Weight      float64    `json:"weight"`      // Kg, per head for a herd
+	HeadCount   int        `json:"headCount"`   // Animals weighed, defaults to 1
+	Notes       string     `json:"notes"`
+}
+
+// WeightResponse represents the weight record response
+type WeightResponse struct {
+	Success bool                 `json:"success"`
+	Message string               `json:"message"`
+	Record  *data.WeightRecord   `json:"record,omitempty"`
+	Records []*data.WeightRecord `json:"records,omitempty"`
+	Growth  []*growth.Series     `json:"growth,omitempty"`
+}
+
+// Validate checks the weight request fields. When partial is true only the
+// fields that are present are checked, as used by updates.
+func (req *WeightRequest) Validate(partial bool) ValidationErrors {
+	v := newValidator()
+	if !partial {
+		v.Required("livestockId", req.LivestockID)
+		v.Check(req.Weight > 0, "weight", "must be greater than 0")
+	}
+	v.Check(req.Weight >= 0, "weight", "must be 0 or more")
+	v.Check(req.HeadCount >= 0, "headCount", "must not be negative")
+	if req.Date != nil {
+		v.Check(!req.Date.After(time.Now()), "date", "must not be in the future")
+	}
+	return v.Errors()
+}
+
+// CreateWeightHandler handles recording an animal's or a herd's weight
+func (app *Config) CreateWeightHandler(w http.ResponseWriter, r *http.Request) {
+	var req WeightRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(false); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Growth.Create(r.Context(), user, farmID, growth.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WeightResponse{
+		Success: true,
+		Message: "Weight recorded successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusCreated, response)
+}
+
+// CreateWeightsBatchHandler handles a weigh day: every animal or herd put on
+// the scale at once
+func (app *Config) CreateWeightsBatchHandler(w http.ResponseWriter, r *http.Request) {
+	batchCreate(app, w, r, "weight records", func(req *WeightRequest) ValidationErrors { return req.Validate(false) },
+		func(user *data.User, farmID string, reqs []WeightRequest) ([]*data.WeightRecord, []error, error) {
+			ins := make([]growth.Input, len(reqs))
+			for i, req := range reqs {
+				ins[i] = growth.Input(req)
+			}
+			return app.Services.Growth.CreateBatch(r.Context(), user, farmID, ins)
+		})
+}
+
+// GetWeightsHandler handles retrieving a farm's weighings, optionally only
+// those of a ?livestockId herd or ?animalTag animal
+func (app *Config) GetWeightsHandler(w http.ResponseWriter, r *http.Request) {
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	livestockID, animalTag := weightFilter(r)
+	records, err := app.Services.Growth.List(r.Context(), user, farmID, livestockID, animalTag)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WeightResponse{
+		Success: true,
+		Message: "Weight records retrieved successfully",
+		Records: records,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetGrowthHandler handles the growth curves of a farm's animals and herds,
+// with the daily gain between weighings, optionally only for a ?livestockId
+// herd or ?animalTag animal. A ?targetGain in kg per day flags the curves
+// growing slower.
+func (app *Config) GetGrowthHandler(w http.ResponseWriter, r *http.Request) {
+	var target float64
+	if v := r.URL.Query().Get("targetGain"); v != "" {
+		var err error
+		target, err = strconv.ParseFloat(v, 64)
+		if err != nil || target <= 0 {
+			app.errorJSON(w, errors.New("targetGain must be a number greater than 0"), http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, farmID, ok := app.currentFarm(w, r)
+	if !ok {
+		return
+	}
+
+	livestockID, animalTag := weightFilter(r)
+	series, err := app.Services.Growth.Growth(r.Context(), user, farmID, livestockID, animalTag, target)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WeightResponse{
+		Success: true,
+		Message: "Growth curves retrieved successfully",
+		Growth:  series,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GetWeightHandler handles retrieving a single weighing by ID
+func (app *Config) GetWeightHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("weight record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Growth.Get(r.Context(), user, recordID)
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WeightResponse{
+		Success: true,
+		Message: "Weight record retrieved successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// UpdateWeightHandler handles correcting a weighing
+func (app *Config) UpdateWeightHandler(w http.ResponseWriter, r *http.Request) {
+	var req WeightRequest
+
+	if err := app.ReadJSON(w, r, &req); err != nil {
+		app.errorJSON(w, err, http.StatusBadRequest)
+		return
+	}
+
+	if errs := req.Validate(true); errs != nil {
+		app.failedValidation(w, errs)
+		return
+	}
+
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("weight record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := app.Services.Growth.Update(r.Context(), user, recordID, growth.Input(req))
+	if err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WeightResponse{
+		Success: true,
+		Message: "Weight record updated successfully",
+		Record:  record,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// DeleteWeightHandler handles deleting a weighing
+func (app *Config) DeleteWeightHandler(w http.ResponseWriter, r *http.Request) {
+	recordID := resourceID(r)
+	if recordID == "" {
+		app.errorJSON(w, errors.New("weight record ID is required"), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := app.currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.Services.Growth.Delete(r.Context(), user, recordID); err != nil {
+		app.serviceError(w, err)
+		return
+	}
+
+	response := WeightResponse{
+		Success: true,
+		Message: "Weight record deleted successfully",
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// weightFilter reads the herd and animal to filter weighings on from the
+// query parameters. The animal tag is nil when not given.
+func weightFilter(r *http.Request) (string, *string) {
+	query := r.URL.Query()
+	var animalTag *string
+	if query.Has("animalTag") {
+		tag := query.Get("animalTag")
+		animalTag = &tag
+	}
+	return query.Get("livestockId"), animalTag
+}
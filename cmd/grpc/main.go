@@ -0,0 +1,71 @@
+// Command grpc serves the core farm data to internal services, such as the
+// analytics service, over gRPC instead of HTTP/JSON:
+//
+//	grpc -addr :9006 -dsn "host=localhost user=postgres dbname=farm4u"
+//
+// The service, defined in proto/farmdata/v1, is read-only and sees every farm,
+// so it must only be reachable on the internal network. Callers send the
+// shared token given by -token as "authorization: Bearer <token>" metadata.
+package main
+
+import (
+	"context"
+	"farm4u/data"
+	farmdatav1 "farm4u/proto/farmdata/v1"
+	"flag"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	addr := flag.String("addr", envOr("GRPC_ADDR", ":9006"), "address to listen on")
+	dsn := flag.String("dsn", os.Getenv("DSN"), "PostgreSQL DSN")
+	token := flag.String("token", os.Getenv("GRPC_TOKEN"), "shared token callers must present")
+	flag.Parse()
+
+	if *dsn == "" || *token == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := gorm.Open(postgres.Open(*dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Warn)})
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("Error listening on %s: %v", *addr, err)
+	}
+
+	srv := grpc.NewServer(grpc.UnaryInterceptor(authenticate(*token)))
+	farmdatav1.RegisterFarmDataServer(srv, &server{models: data.New(db)})
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		log.Print("Shutting down gRPC server")
+		srv.GracefulStop()
+	}()
+
+	log.Printf("Serving farm data over gRPC on %s", *addr)
+	if err := srv.Serve(lis); err != nil {
+		log.Fatalf("Error serving gRPC: %v", err)
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
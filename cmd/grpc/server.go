@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"farm4u/data"
+	farmdatav1 "farm4u/proto/farmdata/v1"
+	"log"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// server implements farmdatav1.FarmDataServer over the data repositories
+type server struct {
+	farmdatav1.UnimplementedFarmDataServer
+	models data.Models
+}
+
+// ListFarms implements farmdatav1.FarmDataServer
+func (s *server) ListFarms(ctx context.Context, req *farmdatav1.ListFarmsRequest) (*farmdatav1.ListFarmsResponse, error) {
+	var farms []*data.Farm
+	var err error
+	if req.GetUserId() != "" {
+		farms, err = s.models.Farm.GetByUserID(ctx, req.GetUserId())
+	} else {
+		farms, err = s.models.Farm.GetAll(ctx)
+	}
+	if err != nil {
+		return nil, internal("getting farms", err)
+	}
+
+	resp := &farmdatav1.ListFarmsResponse{Farms: make([]*farmdatav1.Farm, 0, len(farms))}
+	for _, farm := range farms {
+		resp.Farms = append(resp.Farms, farmMessage(farm))
+	}
+	return resp, nil
+}
+
+// GetFarm implements farmdatav1.FarmDataServer
+func (s *server) GetFarm(ctx context.Context, req *farmdatav1.GetFarmRequest) (*farmdatav1.Farm, error) {
+	farm, err := s.farm(ctx, req.GetFarmId())
+	if err != nil {
+		return nil, err
+	}
+	return farmMessage(farm), nil
+}
+
+// ListFields implements farmdatav1.FarmDataServer
+func (s *server) ListFields(ctx context.Context, req *farmdatav1.FarmRequest) (*farmdatav1.ListFieldsResponse, error) {
+	if _, err := s.farm(ctx, req.GetFarmId()); err != nil {
+		return nil, err
+	}
+	fields, err := s.models.Field.GetByFarmID(ctx, req.GetFarmId())
+	if err != nil {
+		return nil, internal("getting fields", err)
+	}
+
+	resp := &farmdatav1.ListFieldsResponse{Fields: make([]*farmdatav1.Field, 0, len(fields))}
+	for _, field := range fields {
+		resp.Fields = append(resp.Fields, &farmdatav1.Field{
+			FieldId:   field.FieldID,
+			FarmId:    field.FarmID,
+			Name:      field.Name,
+			Area:      field.Area,
+			SoilType:  field.SoilType,
+			CreatedAt: timestamppb.New(field.CreatedAt),
+			UpdatedAt: timestamppb.New(field.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// ListCrops implements farmdatav1.FarmDataServer
+func (s *server) ListCrops(ctx context.Context, req *farmdatav1.FarmRequest) (*farmdatav1.ListCropsResponse, error) {
+	if _, err := s.farm(ctx, req.GetFarmId()); err != nil {
+		return nil, err
+	}
+	crops, err := s.models.Crop.GetByFarmID(ctx, req.GetFarmId())
+	if err != nil {
+		return nil, internal("getting crops", err)
+	}
+
+	resp := &farmdatav1.ListCropsResponse{Crops: make([]*farmdatav1.Crop, 0, len(crops))}
+	for _, crop := range crops {
+		msg := &farmdatav1.Crop{
+			CropId:       crop.CropID,
+			FarmId:       crop.FarmID,
+			Name:         crop.Name,
+			PlantingDate: timestamp(crop.PlantingDate),
+			HarvestDate:  timestamp(crop.HarvestDate),
+			Quantity:     crop.Quantity,
+			Status:       crop.Status,
+			CreatedAt:    timestamppb.New(crop.CreatedAt),
+			UpdatedAt:    timestamppb.New(crop.UpdatedAt),
+		}
+		if crop.FieldID != nil {
+			msg.FieldId = *crop.FieldID
+		}
+		resp.Crops = append(resp.Crops, msg)
+	}
+	return resp, nil
+}
+
+// ListLivestock implements farmdatav1.FarmDataServer
+func (s *server) ListLivestock(ctx context.Context, req *farmdatav1.FarmRequest) (*farmdatav1.ListLivestockResponse, error) {
+	if _, err := s.farm(ctx, req.GetFarmId()); err != nil {
+		return nil, err
+	}
+	livestock, err := s.models.Livestock.GetByFarmID(ctx, req.GetFarmId())
+	if err != nil {
+		return nil, internal("getting livestock", err)
+	}
+
+	resp := &farmdatav1.ListLivestockResponse{Livestock: make([]*farmdatav1.Livestock, 0, len(livestock))}
+	for _, animals := range livestock {
+		resp.Livestock = append(resp.Livestock, &farmdatav1.Livestock{
+			LivestockId:     animals.LivestockID,
+			FarmId:          animals.FarmID,
+			Type:            animals.Type,
+			Count:           int32(animals.Count),
+			AcquisitionDate: timestamp(animals.AcquisitionDate),
+			HealthStatus:    animals.HealthStatus,
+			CreatedAt:       timestamppb.New(animals.CreatedAt),
+			UpdatedAt:       timestamppb.New(animals.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// ListTransactions implements farmdatav1.FarmDataServer
+func (s *server) ListTransactions(ctx context.Context, req *farmdatav1.ListTransactionsRequest) (*farmdatav1.ListTransactionsResponse, error) {
+	if _, err := s.farm(ctx, req.GetFarmId()); err != nil {
+		return nil, err
+	}
+	var from, to *time.Time
+	if req.GetFrom() != nil {
+		t := req.GetFrom().AsTime()
+		from = &t
+	}
+	if req.GetTo() != nil {
+		t := req.GetTo().AsTime()
+		to = &t
+	}
+	transactions, err := s.models.Transaction.GetByFarmIDAndDateRange(ctx, req.GetFarmId(), from, to)
+	if err != nil {
+		return nil, internal("getting transactions", err)
+	}
+
+	resp := &farmdatav1.ListTransactionsResponse{Transactions: make([]*farmdatav1.Transaction, 0, len(transactions))}
+	for _, transaction := range transactions {
+		resp.Transactions = append(resp.Transactions, &farmdatav1.Transaction{
+			TransactionId: transaction.TransactionID,
+			FarmId:        transaction.FarmID,
+			Type:          transaction.Type,
+			Category:      transaction.Category,
+			Amount:        transaction.Amount,
+			Date:          timestamppb.New(transaction.Date),
+			Description:   transaction.Description,
+			Reference:     transaction.Reference,
+			CreatedAt:     timestamppb.New(transaction.CreatedAt),
+			UpdatedAt:     timestamppb.New(transaction.UpdatedAt),
+		})
+	}
+	return resp, nil
+}
+
+// farm returns the farm with the given ID, or a NotFound status if there is
+// none
+func (s *server) farm(ctx context.Context, farmID string) (*data.Farm, error) {
+	if farmID == "" {
+		return nil, status.Error(codes.InvalidArgument, "farm_id is required")
+	}
+	farm, err := s.models.Farm.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, internal("getting farm", err)
+	}
+	if farm == nil {
+		return nil, status.Error(codes.NotFound, "farm not found")
+	}
+	return farm, nil
+}
+
+func farmMessage(farm *data.Farm) *farmdatav1.Farm {
+	return &farmdatav1.Farm{
+		FarmId:      farm.FarmID,
+		UserId:      farm.UserID,
+		Name:        farm.Name,
+		Description: farm.Description,
+		Location:    farm.Location,
+		Size:        farm.Size,
+		FarmType:    farm.FarmType,
+		Status:      farm.Status,
+		CreatedAt:   timestamppb.New(farm.CreatedAt),
+		UpdatedAt:   timestamppb.New(farm.UpdatedAt),
+	}
+}
+
+// timestamp converts an optional time, leaving the field unset if nil
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// internal logs err and returns an Internal status, keeping database details
+// from the caller
+func internal(doing string, err error) error {
+	log.Printf("Error %s: %v", doing, err)
+	return status.Error(codes.Internal, "internal error")
+}
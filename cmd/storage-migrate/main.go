@@ -0,0 +1,99 @@
+// Command storage-migrate copies stored objects from one storage backend to
+// another, e.g. when moving from local disk to S3 or between clouds:
+//
+//	storage-migrate -from file:///var/lib/farm4u/files -to s3://farm4u-files?region=eu-west-1
+//
+// Objects already present at the destination with the same size are skipped,
+// so an interrupted migration can simply be re-run.
+package main
+
+import (
+	"context"
+	"errors"
+	"farm4u/storage"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	from := flag.String("from", "", "source storage URL (file://, s3://, gs://)")
+	to := flag.String("to", "", "destination storage URL (file://, s3://, gs://)")
+	prefix := flag.String("prefix", "", "only migrate keys starting with this prefix")
+	dryRun := flag.Bool("dry-run", false, "list what would be copied without copying")
+	deleteSource := flag.Bool("delete-source", false, "delete each object from the source after it is copied")
+	flag.Parse()
+
+	if *from == "" || *to == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	src, err := storage.Open(*from)
+	if err != nil {
+		log.Fatalf("Error opening source: %v", err)
+	}
+	dst, err := storage.Open(*to)
+	if err != nil {
+		log.Fatalf("Error opening destination: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Migrating objects from %s to %s", src.Name(), dst.Name())
+
+	var copied, skipped, failed int
+	err = src.List(ctx, *prefix, func(obj storage.ObjectInfo) error {
+		existing, err := dst.Stat(ctx, obj.Key)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("checking %s at destination: %w", obj.Key, err)
+		}
+		if existing != nil && existing.Size == obj.Size {
+			skipped++
+			return nil
+		}
+
+		if *dryRun {
+			log.Printf("Would copy %s (%d bytes)", obj.Key, obj.Size)
+			copied++
+			return nil
+		}
+
+		if err := copyObject(ctx, src, dst, obj); err != nil {
+			log.Printf("Error copying %s: %v", obj.Key, err)
+			failed++
+			return nil
+		}
+		copied++
+
+		if *deleteSource {
+			if err := src.Delete(ctx, obj.Key); err != nil {
+				log.Printf("Error deleting %s from source: %v", obj.Key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Error listing source objects: %v", err)
+	}
+
+	log.Printf("Done: %d copied, %d already present, %d failed", copied, skipped, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// copyObject streams one object from src to dst
+func copyObject(ctx context.Context, src, dst storage.Storage, obj storage.ObjectInfo) error {
+	r, info, err := src.Get(ctx, obj.Key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	return dst.Put(ctx, obj.Key, r, info.Size, info.ContentType)
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// ExportSet is every live record of one kind kept for a farm, as read for an
+// account export
+type ExportSet struct {
+	Name    string // e.g. crops
+	Records any    // Slice of the records, e.g. []*Crop
+	Count   int
+}
+
+// AccountExportInterface defines the contract for reading a farm's records
+// for an account export
+type AccountExportInterface interface {
+	// FarmRecords returns a farm's live records of each exported kind, in a
+	// fixed order, leaving out kinds the farm has none of
+	FarmRecords(ctx context.Context, farmID string) ([]ExportSet, error)
+}
+
+// AccountExportRepo implements AccountExportInterface using GORM.
+type AccountExportRepo struct {
+	DB *gorm.DB
+}
+
+// NewAccountExportRepo creates a new instance of AccountExportRepo.
+func NewAccountExportRepo(db *gorm.DB) AccountExportInterface {
+	return &AccountExportRepo{DB: db}
+}
+
+// exportedModel is a kind of farm record included in account exports, with
+// the child records kept alongside it
+type exportedModel struct {
+	name    string
+	model   any
+	preload []string
+}
+
+// exportedFarmModels are the farm records included in account exports. Job
+// bookkeeping, sync mappings and webhook deliveries are left out as they say
+// nothing about the farm itself.
+var exportedFarmModels = []exportedModel{
+	{name: "fields", model: &Field{}},
+	{name: "crops", model: &Crop{}},
+	{name: "cropPlans", model: &CropPlan{}},
+	{name: "seasons", model: &Season{}},
+	{name: "planScenarios", model: &PlanScenario{}},
+	{name: "cropIncidents", model: &CropIncident{}},
+	{name: "sprayRecords", model: &SprayRecord{}},
+	{name: "livestock", model: &Livestock{}},
+	{name: "breedingEvents", model: &BreedingEvent{}},
+	{name: "birthRecords", model: &BirthRecord{}},
+	{name: "productionRecords", model: &ProductionRecord{}},
+	{name: "feedingRecords", model: &FeedingRecord{}},
+	{name: "weightRecords", model: &WeightRecord{}},
+	{name: "mortalityRecords", model: &MortalityRecord{}},
+	{name: "paddocks", model: &Paddock{}},
+	{name: "grazingMoves", model: &GrazingMove{}},
+	{name: "employees", model: &Employee{}},
+	{name: "payrollPayments", model: &PayrollPayment{}},
+	{name: "attendance", model: &Attendance{}},
+	{name: "shifts", model: &Shift{}},
+	{name: "equipment", model: &Equipment{}},
+	{name: "maintenanceRecords", model: &MaintenanceRecord{}},
+	{name: "assets", model: &Asset{}, preload: []string{"Events"}},
+	{name: "waterSources", model: &WaterSource{}},
+	{name: "waterUsage", model: &WaterUsage{}},
+	{name: "irrigationSchedules", model: &IrrigationSchedule{}},
+	{name: "rainfallRecords", model: &RainfallRecord{}},
+	{name: "chemicals", model: &ChemicalProduct{}},
+	{name: "chemicalUsage", model: &ChemicalUsage{}},
+	{name: "inventoryItems", model: &InventoryItem{}},
+	{name: "inventoryBatches", model: &InventoryBatch{}},
+	{name: "inventoryMovements", model: &InventoryMovement{}},
+	{name: "suppliers", model: &Supplier{}},
+	{name: "serviceProviders", model: &ServiceProvider{}},
+	{name: "purchaseOrders", model: &PurchaseOrder{}, preload: []string{"Lines"}},
+	{name: "transactions", model: &Transaction{}},
+	{name: "utilityRecords", model: &UtilityRecord{}},
+	{name: "taxRates", model: &TaxRate{}},
+	{name: "exchangeRates", model: &ExchangeRate{}},
+	{name: "budgetLines", model: &BudgetLine{}},
+	{name: "loans", model: &Loan{}, preload: []string{"Installments", "Repayments"}},
+	{name: "periodLocks", model: &PeriodLock{}},
+	{name: "sustainabilityPractices", model: &SustainabilityPractice{}},
+	{name: "sustainabilityAssessments", model: &SustainabilityAssessment{}, preload: []string{"Responses"}},
+	{name: "milkSuppliers", model: &MilkSupplier{}},
+	{name: "milkDeliveries", model: &MilkDelivery{}},
+	{name: "procurementRequests", model: &ProcurementRequest{}},
+	{name: "documents", model: &Document{}},
+	{name: "attachments", model: &Attachment{}},
+	{name: "tags", model: &Tag{}},
+	{name: "taggings", model: &Tagging{}},
+	{name: "savedViews", model: &SavedView{}},
+	{name: "notes", model: &Note{}},
+	{name: "members", model: &FarmMember{}},
+	{name: "webhooks", model: &Webhook{}},
+	{name: "activity", model: &AuditLog{}},
+}
+
+// FarmRecords reads each exported kind of record of a farm, oldest first
+func (a *AccountExportRepo) FarmRecords(ctx context.Context, farmID string) ([]ExportSet, error) {
+	var sets []ExportSet
+	for _, m := range exportedFarmModels {
+		records := reflect.New(reflect.SliceOf(reflect.TypeOf(m.model)))
+		query := a.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("created_at")
+		for _, rel := range m.preload {
+			query = query.Preload(rel)
+		}
+		if err := query.Find(records.Interface()).Error; err != nil {
+			return nil, err
+		}
+		if n := records.Elem().Len(); n > 0 {
+			sets = append(sets, ExportSet{Name: m.name, Records: records.Elem().Interface(), Count: n})
+		}
+	}
+	return sets, nil
+}
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey represents the api_keys table in the database: a key a farmer gives
+// a partner, such as an insurer or lender, to read their farm data without
+// logging in. Requests made with it act as the farmer, limited to reading
+// what its scopes cover and, when FarmID is set, to that farm. Only a hash of
+// the key is kept.
+type APIKey struct {
+	ID         uint           `gorm:"primaryKey" json:"-"`
+	APIKeyID   string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"apiKeyId"`
+	UserID     string         `gorm:"not null;size:36;index" json:"userId"`  // Farmer who issued it; requests act as them
+	FarmID     *string        `gorm:"size:36;index" json:"farmId,omitempty"` // Only farm it may read; all the farmer's if nil
+	Name       string         `gorm:"not null" json:"name"`                  // Who it was given to, e.g. Acme Insurance
+	Prefix     string         `gorm:"not null" json:"prefix"`                // Start of the key, to tell keys apart
+	KeyHash    string         `gorm:"not null;uniqueIndex" json:"-"`         // Hex SHA-256 of the key
+	Scopes     []string       `gorm:"serializer:json" json:"scopes"`         // e.g. crops:read, finance:read
+	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`                   // Refused after this; never expires if nil
+	LastUsedAt *time.Time     `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"` // Set when the key is revoked
+}
+
+// APIKeyInterface defines the contract for API key operations
+type APIKeyInterface interface {
+	GetByAPIKeyID(ctx context.Context, apiKeyID string) (*APIKey, error)
+	// GetByUserID returns the keys a user has issued and not revoked, newest
+	// first
+	GetByUserID(ctx context.Context, userID string) ([]*APIKey, error)
+	// GetByHash returns the unrevoked key with the given hash
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	Insert(ctx context.Context, key *APIKey) error
+	// Touch records that a key was used at the given time
+	Touch(ctx context.Context, apiKeyID string, at time.Time) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// APIKeyRepo implements APIKeyInterface using GORM.
+type APIKeyRepo struct {
+	DB *gorm.DB
+}
+
+// NewAPIKeyRepo creates a new instance of APIKeyRepo.
+func NewAPIKeyRepo(db *gorm.DB) APIKeyInterface {
+	return &APIKeyRepo{DB: db}
+}
+
+// GetByAPIKeyID retrieves a key by its APIKeyID (UUID)
+func (a *APIKeyRepo) GetByAPIKeyID(ctx context.Context, apiKeyID string) (*APIKey, error) {
+	var key APIKey
+	result := a.DB.WithContext(ctx).Where("api_key_id = ?", apiKeyID).First(&key)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &key, result.Error
+}
+
+// GetByUserID retrieves the keys a user has issued, newest first
+func (a *APIKeyRepo) GetByUserID(ctx context.Context, userID string) ([]*APIKey, error) {
+	var keys []*APIKey
+	result := a.DB.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&keys)
+	return keys, result.Error
+}
+
+// GetByHash retrieves a key by the hash of its value
+func (a *APIKeyRepo) GetByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	var key APIKey
+	result := a.DB.WithContext(ctx).Where("key_hash = ?", keyHash).First(&key)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &key, result.Error
+}
+
+// Insert creates a new key
+func (a *APIKeyRepo) Insert(ctx context.Context, key *APIKey) error {
+	return a.DB.WithContext(ctx).Create(key).Error
+}
+
+// Touch sets a key's last used time
+func (a *APIKeyRepo) Touch(ctx context.Context, apiKeyID string, at time.Time) error {
+	return a.DB.WithContext(ctx).Model(&APIKey{}).Where("api_key_id = ?", apiKeyID).
+		UpdateColumn("last_used_at", at).Error
+}
+
+// DeleteByID revokes a key by its ID
+func (a *APIKeyRepo) DeleteByID(ctx context.Context, id int) error {
+	return a.DB.WithContext(ctx).Delete(&APIKey{}, id).Error
+}
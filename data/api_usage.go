@@ -0,0 +1,56 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APIUsage represents the api_usages table in the database: the number of
+// calls a user made to one endpoint on one day.
+type APIUsage struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	UserID    string    `gorm:"not null;size:36;uniqueIndex:idx_api_usage_user_day_endpoint" json:"userId"` // Foreign key to User
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex:idx_api_usage_user_day_endpoint" json:"date"`
+	Endpoint  string    `gorm:"not null;uniqueIndex:idx_api_usage_user_day_endpoint" json:"endpoint"` // Method and route pattern, e.g. "GET /api/farms/{id}"
+	Count     int64     `gorm:"not null" json:"count"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// APIUsageInterface defines the contract for API usage operations
+type APIUsageInterface interface {
+	GetByUserID(ctx context.Context, userID string, from, to time.Time) ([]*APIUsage, error)
+	Add(ctx context.Context, usages []*APIUsage) error
+}
+
+// APIUsageRepo implements APIUsageInterface using GORM.
+type APIUsageRepo struct {
+	DB *gorm.DB
+}
+
+// NewAPIUsageRepo creates a new instance of APIUsageRepo.
+func NewAPIUsageRepo(db *gorm.DB) APIUsageInterface {
+	return &APIUsageRepo{DB: db}
+}
+
+// GetByUserID retrieves a user's daily usage for dates in [from, to)
+func (a *APIUsageRepo) GetByUserID(ctx context.Context, userID string, from, to time.Time) ([]*APIUsage, error) {
+	var usages []*APIUsage
+	result := a.DB.WithContext(ctx).Where("user_id = ? AND date >= ? AND date < ?", userID, from, to).
+		Order("date desc, count desc").
+		Find(&usages)
+	return usages, result.Error
+}
+
+// Add adds the counts to the matching user/day/endpoint rows, creating them as needed
+func (a *APIUsageRepo) Add(ctx context.Context, usages []*APIUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	return a.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}, {Name: "endpoint"}},
+		DoUpdates: clause.Assignments(map[string]any{"count": gorm.Expr("api_usages.count + excluded.count"), "updated_at": gorm.Expr("excluded.updated_at")}),
+	}).Create(&usages).Error
+}
@@ -0,0 +1,247 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Asset categories
+const (
+	AssetEquipment     = "Equipment"
+	AssetStructure     = "Structure"
+	AssetBreedingStock = "Breeding Stock"
+	AssetLand          = "Land"
+	AssetOther         = "Other"
+)
+
+// Asset event types
+const (
+	AssetAcquired = "Acquisition"
+	AssetRevalued = "Revaluation"
+	AssetDisposed = "Disposal"
+)
+
+// Depreciation methods
+const (
+	DepreciationStraightLine     = "Straight-line"
+	DepreciationDecliningBalance = "Declining-balance"
+)
+
+// Disposal methods
+const (
+	DisposalSale     = "Sale"
+	DisposalWriteOff = "Write-off"
+)
+
+// Asset represents the assets table in the database. The register brings a
+// farm's equipment, structures and breeding stock together at their book
+// value; equipment and breeding stock link to the record they were
+// registered from. Equipment and structures may be depreciated over a useful
+// life, either straight-line or by declining balance.
+type Asset struct {
+	ID                 uint           `gorm:"primaryKey" json:"-"`
+	AssetID            string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"assetId"`
+	FarmID             string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name               string         `gorm:"not null" json:"name"`
+	Category           string         `gorm:"not null" json:"category"`                   // Equipment, Structure, Breeding Stock, Land, Other
+	EquipmentID        *string        `gorm:"size:36;index" json:"equipmentId,omitempty"` // Optional foreign key to Equipment
+	LivestockID        *string        `gorm:"size:36;index" json:"livestockId,omitempty"` // Optional foreign key to Livestock
+	AcquisitionDate    time.Time      `gorm:"not null" json:"acquisitionDate"`
+	AcquisitionCost    float64        `gorm:"not null" json:"acquisitionCost"`
+	BookValue          float64        `gorm:"not null" json:"bookValue"`               // Value after the latest revaluation; 0 once disposed
+	Status             string         `gorm:"not null;default:'Active'" json:"status"` // Active, Disposed
+	DisposedAt         *time.Time     `json:"disposedAt,omitempty"`
+	DepreciationMethod string         `json:"depreciationMethod,omitempty"` // Straight-line, Declining-balance; none when empty
+	UsefulLifeYears    int            `json:"usefulLifeYears,omitempty"`
+	SalvageValue       float64        `json:"salvageValue,omitempty"`     // Value left at the end of the useful life
+	DepreciationRate   float64        `json:"depreciationRate,omitempty"` // Declining-balance annual percentage; double the straight-line rate when 0
+	Notes              string         `json:"notes"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm      *Farm        `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Equipment *Equipment   `gorm:"foreignKey:EquipmentID;references:EquipmentID" json:"equipment,omitempty"`
+	Livestock *Livestock   `gorm:"foreignKey:LivestockID;references:LivestockID" json:"livestock,omitempty"`
+	Events    []AssetEvent `gorm:"foreignKey:AssetID;references:AssetID" json:"events,omitempty"`
+}
+
+// AssetEvent represents the asset_events table in the database: one change
+// to an asset's value over its life, oldest first
+type AssetEvent struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	AssetEventID string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"eventId"`
+	AssetID      string    `gorm:"not null;size:36;index" json:"assetId"` // Foreign key to Asset
+	Type         string    `gorm:"not null" json:"type"`                  // Acquisition, Revaluation, Disposal
+	Date         time.Time `gorm:"not null" json:"date"`
+	Value        float64   `gorm:"not null" json:"value"`     // Book value after the event
+	Method       string    `json:"method,omitempty"`          // Disposals: Sale, Write-off
+	Proceeds     float64   `json:"proceeds,omitempty"`        // Disposals: amount received
+	GainOrLoss   float64   `json:"gainOrLoss,omitempty"`      // Disposals: proceeds less the book value disposed of
+	RecordedBy   string    `gorm:"size:36" json:"recordedBy"` // UserID of who recorded the event
+	Notes        string    `json:"notes"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TransactionReference is the reference used on the income transaction that
+// carries a disposal's sale proceeds into the finance ledger
+func (a *Asset) TransactionReference() string {
+	return fmt.Sprintf("asset_disposal:%s", a.AssetID)
+}
+
+// ValueAt returns the asset's book value at asOf by replaying its events,
+// which must be in date order, and whether it was held then
+func (a *Asset) ValueAt(asOf time.Time) (float64, bool) {
+	value, held := 0.0, false
+	for _, event := range a.Events {
+		if event.Date.After(asOf) {
+			break
+		}
+		switch event.Type {
+		case AssetAcquired, AssetRevalued:
+			value, held = event.Value, true
+		case AssetDisposed:
+			value, held = 0, false
+		}
+	}
+	return value, held
+}
+
+// AssetInterface defines the contract for asset operations
+type AssetInterface interface {
+	GetByAssetID(ctx context.Context, assetID string) (*Asset, error)
+	// GetByFarmID returns a farm's assets with their events, optionally only
+	// those with the given status
+	GetByFarmID(ctx context.Context, farmID, status string) ([]*Asset, error)
+	GetByEquipmentID(ctx context.Context, equipmentID string) (*Asset, error)
+	GetByLivestockID(ctx context.Context, livestockID string) (*Asset, error)
+	// Insert creates an asset with its acquisition event
+	Insert(ctx context.Context, asset *Asset, recordedBy string) error
+	Update(ctx context.Context, asset *Asset) error
+	// AddEvent records a revaluation or disposal and saves the asset's new
+	// value and status, with any sale proceeds as income in the ledger
+	AddEvent(ctx context.Context, asset *Asset, event *AssetEvent) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// AssetRepo implements AssetInterface using GORM.
+type AssetRepo struct {
+	DB *gorm.DB
+}
+
+// NewAssetRepo creates a new instance of AssetRepo.
+func NewAssetRepo(db *gorm.DB) AssetInterface {
+	return &AssetRepo{DB: db}
+}
+
+// withEvents preloads an asset's events in the order they happened
+func withEvents(db *gorm.DB) *gorm.DB {
+	return db.Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date, created_at")
+	})
+}
+
+// GetByAssetID retrieves an asset with its events by its AssetID (UUID)
+func (a *AssetRepo) GetByAssetID(ctx context.Context, assetID string) (*Asset, error) {
+	var asset Asset
+	result := withEvents(a.DB.WithContext(ctx)).Where("asset_id = ?", assetID).First(&asset)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &asset, result.Error
+}
+
+// GetByFarmID retrieves a farm's assets with their events by category and
+// name, optionally only those with the given status
+func (a *AssetRepo) GetByFarmID(ctx context.Context, farmID, status string) ([]*Asset, error) {
+	var assets []*Asset
+	query := withEvents(a.DB.WithContext(ctx)).Where("farm_id = ?", farmID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("category, name").Find(&assets)
+	return assets, result.Error
+}
+
+// GetByEquipmentID retrieves the asset registered from an equipment record
+func (a *AssetRepo) GetByEquipmentID(ctx context.Context, equipmentID string) (*Asset, error) {
+	var asset Asset
+	result := a.DB.WithContext(ctx).Where("equipment_id = ?", equipmentID).First(&asset)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &asset, result.Error
+}
+
+// GetByLivestockID retrieves the asset registered from a livestock record
+func (a *AssetRepo) GetByLivestockID(ctx context.Context, livestockID string) (*Asset, error) {
+	var asset Asset
+	result := a.DB.WithContext(ctx).Where("livestock_id = ?", livestockID).First(&asset)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &asset, result.Error
+}
+
+// Insert creates a new asset and its acquisition event in a single transaction
+func (a *AssetRepo) Insert(ctx context.Context, asset *Asset, recordedBy string) error {
+	return a.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Equipment", "Livestock", "Events").Create(asset).Error; err != nil {
+			return err
+		}
+		event := AssetEvent{
+			AssetID:    asset.AssetID,
+			Type:       AssetAcquired,
+			Date:       asset.AcquisitionDate,
+			Value:      asset.AcquisitionCost,
+			RecordedBy: recordedBy,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		asset.Events = []AssetEvent{event}
+		return nil
+	})
+}
+
+// Update updates an existing asset's details in the database
+func (a *AssetRepo) Update(ctx context.Context, asset *Asset) error {
+	return a.DB.WithContext(ctx).Omit("Equipment", "Livestock", "Events").Save(asset).Error
+}
+
+// AddEvent records an event and saves the asset in a single transaction. A
+// disposal by sale also records its proceeds as Asset Sale income.
+func (a *AssetRepo) AddEvent(ctx context.Context, asset *Asset, event *AssetEvent) error {
+	return a.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		event.AssetID = asset.AssetID
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Equipment", "Livestock", "Events").Save(asset).Error; err != nil {
+			return err
+		}
+		asset.Events = append(asset.Events, *event)
+
+		if event.Type != AssetDisposed || event.Method != DisposalSale {
+			return nil
+		}
+		return syncTransaction(tx, asset.TransactionReference(), Transaction{
+			FarmID:      asset.FarmID,
+			Type:        "Income",
+			Category:    "Asset Sale",
+			Amount:      event.Proceeds,
+			Date:        event.Date,
+			Description: "Sale of " + asset.Name,
+		})
+	})
+}
+
+// DeleteByID soft deletes an asset by its ID
+func (a *AssetRepo) DeleteByID(ctx context.Context, id int) error {
+	return a.DB.WithContext(ctx).Delete(&Asset{}, id).Error
+}
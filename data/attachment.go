@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment represents the attachments table in the database: a photo or
+// document, such as a picture of disease symptoms or a receipt, attached to
+// one of a farm's records. The file itself lives in object storage under
+// FileKey.
+type Attachment struct {
+	ID           uint       `gorm:"primaryKey" json:"-"`
+	AttachmentID string     `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"attachmentId"`
+	FarmID       string     `gorm:"not null;size:36;index" json:"farmId"`                   // Foreign key to Farm
+	UserID       string     `gorm:"not null;size:36" json:"userId"`                         // User who attached the file
+	RecordType   string     `gorm:"not null;index:idx_attachment_record" json:"recordType"` // crop, livestock, equipment, maintenance, transaction, document, incident
+	RecordID     string     `gorm:"not null;size:36;index:idx_attachment_record" json:"recordId"`
+	FileName     string     `gorm:"not null" json:"fileName"`
+	ContentType  string     `gorm:"not null" json:"contentType"`
+	Size         int64      `json:"size"` // Bytes; the declared size until the file is uploaded
+	Caption      string     `json:"caption"`
+	Status       string     `gorm:"not null;default:'Pending'" json:"status"` // Pending until the file is uploaded, then Uploaded
+	UploadedAt   *time.Time `json:"uploadedAt,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// FileKey is the storage key of the attached file
+func (a *Attachment) FileKey() string {
+	return fmt.Sprintf("farms/%s/attachments/%s", a.FarmID, a.AttachmentID)
+}
+
+// AttachmentInterface defines the contract for attachment operations
+type AttachmentInterface interface {
+	GetByAttachmentID(ctx context.Context, attachmentID string) (*Attachment, error)
+	// GetByRecord returns the attachments of a record, oldest first
+	GetByRecord(ctx context.Context, recordType, recordID string) ([]*Attachment, error)
+	// GetByFarmID returns a farm's attachments, newest first
+	GetByFarmID(ctx context.Context, farmID string) ([]*Attachment, error)
+	Insert(ctx context.Context, attachment *Attachment) error
+	Update(ctx context.Context, attachment *Attachment) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// AttachmentRepo implements AttachmentInterface using GORM.
+type AttachmentRepo struct {
+	DB *gorm.DB
+}
+
+// NewAttachmentRepo creates a new instance of AttachmentRepo.
+func NewAttachmentRepo(db *gorm.DB) AttachmentInterface {
+	return &AttachmentRepo{DB: db}
+}
+
+// GetByAttachmentID retrieves an attachment by its AttachmentID (UUID)
+func (a *AttachmentRepo) GetByAttachmentID(ctx context.Context, attachmentID string) (*Attachment, error) {
+	var attachment Attachment
+	result := a.DB.WithContext(ctx).Where("attachment_id = ?", attachmentID).First(&attachment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &attachment, result.Error
+}
+
+// GetByRecord retrieves the attachments of a record
+func (a *AttachmentRepo) GetByRecord(ctx context.Context, recordType, recordID string) ([]*Attachment, error) {
+	var attachments []*Attachment
+	result := a.DB.WithContext(ctx).Where("record_type = ? AND record_id = ?", recordType, recordID).Order("created_at").Find(&attachments)
+	return attachments, result.Error
+}
+
+// GetByFarmID retrieves all attachments of a farm
+func (a *AttachmentRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Attachment, error) {
+	var attachments []*Attachment
+	result := a.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("created_at DESC").Find(&attachments)
+	return attachments, result.Error
+}
+
+// Insert adds a new attachment to the database
+func (a *AttachmentRepo) Insert(ctx context.Context, attachment *Attachment) error {
+	return a.DB.WithContext(ctx).Create(attachment).Error
+}
+
+// Update modifies an existing attachment
+func (a *AttachmentRepo) Update(ctx context.Context, attachment *Attachment) error {
+	return a.DB.WithContext(ctx).Save(attachment).Error
+}
+
+// DeleteByID deletes an attachment by its ID
+func (a *AttachmentRepo) DeleteByID(ctx context.Context, id int) error {
+	return a.DB.WithContext(ctx).Delete(&Attachment{}, id).Error
+}
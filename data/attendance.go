@@ -0,0 +1,138 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attendance represents the attendances table in the database. A record is
+// opened when an employee clocks in and closed when they clock out.
+type Attendance struct {
+	ID           uint           `gorm:"primaryKey" json:"-"`
+	AttendanceID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"attendanceId"`
+	EmployeeID   string         `gorm:"not null;size:36;index" json:"employeeId"` // Foreign key to Employee
+	FarmID       string         `gorm:"not null;size:36;index" json:"farmId"`     // Foreign key to Farm
+	ClockIn      time.Time      `gorm:"not null;index" json:"clockIn"`
+	ClockOut     *time.Time     `json:"clockOut"` // nil while the employee is clocked in
+	Hours        float64        `json:"hours"`    // Worked hours, set on clock-out
+	Notes        string         `json:"notes"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Employee *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+}
+
+// WeeklyHours is the time an employee worked in one week, counted from
+// closed attendance records
+type WeeklyHours struct {
+	EmployeeID string  `json:"employeeId"`
+	WeekStart  string  `json:"weekStart"` // Monday, YYYY-MM-DD
+	Days       int     `json:"days"`      // Distinct days with a clock-in
+	Hours      float64 `json:"hours"`
+}
+
+// AttendanceInterface defines the contract for attendance operations
+type AttendanceInterface interface {
+	GetByAttendanceID(ctx context.Context, attendanceID string) (*Attendance, error)
+	GetOpen(ctx context.Context, employeeID string) (*Attendance, error)
+	GetByEmployeeID(ctx context.Context, employeeID string, from, to *time.Time) ([]*Attendance, error)
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*Attendance, error)
+	WeeklyHours(ctx context.Context, farmID, employeeID string, from, to time.Time) ([]WeeklyHours, error)
+	Insert(ctx context.Context, attendance *Attendance) error
+	Update(ctx context.Context, attendance *Attendance) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// AttendanceRepo implements AttendanceInterface using GORM.
+type AttendanceRepo struct {
+	DB *gorm.DB
+}
+
+// NewAttendanceRepo creates a new instance of AttendanceRepo.
+func NewAttendanceRepo(db *gorm.DB) AttendanceInterface {
+	return &AttendanceRepo{DB: db}
+}
+
+// GetByAttendanceID retrieves an attendance record by its AttendanceID (UUID)
+func (a *AttendanceRepo) GetByAttendanceID(ctx context.Context, attendanceID string) (*Attendance, error) {
+	var attendance Attendance
+	result := a.DB.WithContext(ctx).Where("attendance_id = ?", attendanceID).First(&attendance)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &attendance, result.Error
+}
+
+// GetOpen retrieves the record of an employee who is clocked in, if any
+func (a *AttendanceRepo) GetOpen(ctx context.Context, employeeID string) (*Attendance, error) {
+	var attendance Attendance
+	result := a.DB.WithContext(ctx).Where("employee_id = ? AND clock_out IS NULL", employeeID).Order("clock_in desc").First(&attendance)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &attendance, result.Error
+}
+
+// GetByEmployeeID retrieves an employee's attendance, optionally limited to
+// clock-ins in [from, to)
+func (a *AttendanceRepo) GetByEmployeeID(ctx context.Context, employeeID string, from, to *time.Time) ([]*Attendance, error) {
+	var records []*Attendance
+	query := a.DB.WithContext(ctx).Where("employee_id = ?", employeeID)
+	if from != nil {
+		query = query.Where("clock_in >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("clock_in < ?", *to)
+	}
+	result := query.Order("clock_in desc").Find(&records)
+	return records, result.Error
+}
+
+// GetByFarmID retrieves a farm's attendance, optionally limited to clock-ins
+// in [from, to)
+func (a *AttendanceRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*Attendance, error) {
+	var records []*Attendance
+	query := a.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if from != nil {
+		query = query.Where("clock_in >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("clock_in < ?", *to)
+	}
+	result := query.Order("clock_in desc").Find(&records)
+	return records, result.Error
+}
+
+// WeeklyHours sums closed attendance per employee and week for clock-ins in
+// [from, to). An empty employeeID covers the whole farm.
+func (a *AttendanceRepo) WeeklyHours(ctx context.Context, farmID, employeeID string, from, to time.Time) ([]WeeklyHours, error) {
+	var totals []WeeklyHours
+	query := a.DB.WithContext(ctx).Model(&Attendance{}).
+		Select("employee_id, to_char(date_trunc('week', clock_in), 'YYYY-MM-DD') AS week_start, COUNT(DISTINCT CAST(clock_in AS DATE)) AS days, SUM(hours) AS hours").
+		Where("farm_id = ? AND clock_out IS NOT NULL AND clock_in >= ? AND clock_in < ?", farmID, from, to)
+	if employeeID != "" {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	result := query.Group("employee_id, week_start").Order("week_start, employee_id").Scan(&totals)
+	return totals, result.Error
+}
+
+// Insert creates a new attendance record in the database
+func (a *AttendanceRepo) Insert(ctx context.Context, attendance *Attendance) error {
+	return a.DB.WithContext(ctx).Omit("Employee").Create(attendance).Error
+}
+
+// Update updates an existing attendance record in the database
+func (a *AttendanceRepo) Update(ctx context.Context, attendance *Attendance) error {
+	return a.DB.WithContext(ctx).Omit("Employee").Save(attendance).Error
+}
+
+// DeleteByID soft deletes an attendance record by its ID
+func (a *AttendanceRepo) DeleteByID(ctx context.Context, id int) error {
+	return a.DB.WithContext(ctx).Delete(&Attendance{}, id).Error
+}
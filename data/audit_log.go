@@ -0,0 +1,81 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog represents the audit_logs table in the database. Entries are
+// append-only: there is no update or delete.
+type AuditLog struct {
+	ID         uint          `gorm:"primaryKey" json:"-"`
+	AuditLogID string        `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"auditLogId"`
+	FarmID     string        `gorm:"size:36;index" json:"farmId,omitempty"` // Farm the action concerns, if any
+	UserID     string        `gorm:"not null;size:36;index" json:"userId"`  // User who performed the action
+	Action     string        `gorm:"not null" json:"action"`                // e.g. period.lock, period.unlock
+	EntityType string        `gorm:"not null" json:"entityType"`
+	EntityID   string        `gorm:"size:36;index" json:"entityId"`
+	Details    string        `json:"details"`
+	Changes    []FieldChange `gorm:"serializer:json" json:"changes,omitempty"` // Fields set by a create or changed by an update
+	CreatedAt  time.Time     `gorm:"autoCreateTime;index" json:"createdAt"`
+}
+
+// FieldChange is one field of a record set or changed by an audited action.
+// Old is null for a created record.
+type FieldChange struct {
+	Field string `json:"field"` // As named in the record's JSON
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// AuditLogInterface defines the contract for audit log operations
+type AuditLogInterface interface {
+	GetByFarmID(ctx context.Context, farmID, entityType string, from, to *time.Time) ([]*AuditLog, error)
+	// GetByEntity retrieves the entries about one record, oldest first
+	GetByEntity(ctx context.Context, entityType, entityID string) ([]*AuditLog, error)
+	Insert(ctx context.Context, entry *AuditLog) error
+}
+
+// AuditLogRepo implements AuditLogInterface using GORM.
+type AuditLogRepo struct {
+	DB *gorm.DB
+}
+
+// NewAuditLogRepo creates a new instance of AuditLogRepo.
+func NewAuditLogRepo(db *gorm.DB) AuditLogInterface {
+	return &AuditLogRepo{DB: db}
+}
+
+// GetByFarmID retrieves a farm's audit entries, newest first, optionally
+// filtered by entity type and limited to entries made in [from, to)
+func (a *AuditLogRepo) GetByFarmID(ctx context.Context, farmID, entityType string, from, to *time.Time) ([]*AuditLog, error) {
+	var entries []*AuditLog
+	query := a.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+	result := query.Order("created_at desc").Find(&entries)
+	return entries, result.Error
+}
+
+// GetByEntity retrieves the audit entries about one record in the order
+// they were made
+func (a *AuditLogRepo) GetByEntity(ctx context.Context, entityType, entityID string) ([]*AuditLog, error) {
+	var entries []*AuditLog
+	result := a.DB.WithContext(ctx).Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("created_at, id").Find(&entries)
+	return entries, result.Error
+}
+
+// Insert appends an entry to the audit log
+func (a *AuditLogRepo) Insert(ctx context.Context, entry *AuditLog) error {
+	return a.DB.WithContext(ctx).Create(entry).Error
+}
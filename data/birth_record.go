@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BirthRecord represents the birth_records table in the database: a
+// calving, kidding or lambing. It closes the dam's breeding event when one
+// was recorded; births from unrecorded services have none. The live-born
+// young join the dam's herd.
+type BirthRecord struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	BirthRecordID   string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"birthRecordId"`
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"`                 // Foreign key to Farm
+	LivestockID     string         `gorm:"not null;size:36;index" json:"livestockId"`            // Herd the dam belongs to
+	BreedingEventID *string        `gorm:"size:36;uniqueIndex" json:"breedingEventId,omitempty"` // Service the birth came from, if recorded
+	DamTag          string         `gorm:"not null" json:"damTag"`                               // Ear tag or name of the dam
+	BirthDate       time.Time      `gorm:"not null" json:"birthDate"`
+	LiveBorn        int            `gorm:"not null;default:0" json:"liveBorn"`
+	Stillborn       int            `gorm:"not null;default:0" json:"stillborn"`
+	Male            int            `gorm:"not null;default:0" json:"male"`         // Of the live born
+	Female          int            `gorm:"not null;default:0" json:"female"`       // Of the live born
+	Assisted        bool           `gorm:"not null;default:false" json:"assisted"` // A difficult birth that needed help
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BirthRecordInterface defines the contract for birth record operations
+type BirthRecordInterface interface {
+	GetByBirthRecordID(ctx context.Context, birthRecordID string) (*BirthRecord, error)
+	// GetByFarmID returns a farm's births, optionally only those in a herd,
+	// most recent first
+	GetByFarmID(ctx context.Context, farmID, livestockID string) ([]*BirthRecord, error)
+	Insert(ctx context.Context, birth *BirthRecord) error
+}
+
+// BirthRecordRepo implements BirthRecordInterface using GORM.
+type BirthRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewBirthRecordRepo creates a new instance of BirthRecordRepo.
+func NewBirthRecordRepo(db *gorm.DB) BirthRecordInterface {
+	return &BirthRecordRepo{DB: db}
+}
+
+// GetByBirthRecordID retrieves a birth by its BirthRecordID (UUID)
+func (b *BirthRecordRepo) GetByBirthRecordID(ctx context.Context, birthRecordID string) (*BirthRecord, error) {
+	var birth BirthRecord
+	result := b.DB.WithContext(ctx).Where("birth_record_id = ?", birthRecordID).First(&birth)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &birth, result.Error
+}
+
+// GetByFarmID retrieves a farm's births, most recent first
+func (b *BirthRecordRepo) GetByFarmID(ctx context.Context, farmID, livestockID string) ([]*BirthRecord, error) {
+	var births []*BirthRecord
+	query := b.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if livestockID != "" {
+		query = query.Where("livestock_id = ?", livestockID)
+	}
+	result := query.Order("birth_date desc, id desc").Find(&births)
+	return births, result.Error
+}
+
+// Insert adds a new birth
+func (b *BirthRecordRepo) Insert(ctx context.Context, birth *BirthRecord) error {
+	return b.DB.WithContext(ctx).Create(birth).Error
+}
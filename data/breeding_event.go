@@ -0,0 +1,121 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BreedingEvent represents the breeding_events table in the database: a dam
+// served, naturally or by insemination, and what came of it. Livestock is
+// kept in groups, so the dam is a member of the LivestockID herd named by
+// her ear tag. The outcome stays Pending until a pregnancy check, and
+// becomes Birthed once her calving, kidding or lambing is recorded.
+type BreedingEvent struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	BreedingEventID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"breedingEventId"`
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	LivestockID     string         `gorm:"not null;size:36;index" json:"livestockId"` // Herd the dam belongs to
+	DamTag          string         `gorm:"not null" json:"damTag"`                    // Ear tag or name of the dam
+	SireTag         string         `json:"sireTag"`                                   // Bull, buck or ram, or the semen straw code
+	ServiceDate     time.Time      `gorm:"not null" json:"serviceDate"`
+	Method          string         `gorm:"not null;default:'Natural'" json:"method"` // Natural, Artificial Insemination, Embryo Transfer
+	ExpectedDueDate time.Time      `gorm:"not null;index" json:"expectedDueDate"`
+	Outcome         string         `gorm:"not null;default:'Pending'" json:"outcome"` // Pending, Pregnant, Open, Aborted, Birthed
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Livestock *Livestock   `gorm:"foreignKey:LivestockID;references:LivestockID" json:"livestock,omitempty"`
+	Birth     *BirthRecord `gorm:"foreignKey:BreedingEventID;references:BreedingEventID" json:"birth,omitempty"`
+}
+
+// BreedingEventFilter narrows the breeding events listed; empty fields match
+// every event
+type BreedingEventFilter struct {
+	LivestockID string
+	DamTag      string
+	Outcome     string
+}
+
+// BreedingEventInterface defines the contract for breeding event operations
+type BreedingEventInterface interface {
+	GetByBreedingEventID(ctx context.Context, breedingEventID string) (*BreedingEvent, error)
+	// GetByFarmID returns a farm's breeding events, most recently served first
+	GetByFarmID(ctx context.Context, farmID string, filter BreedingEventFilter) ([]*BreedingEvent, error)
+	// GetDue returns a farm's events still awaiting a birth, Pending or
+	// Pregnant, that are due before the given time, soonest first
+	GetDue(ctx context.Context, farmID string, before time.Time) ([]*BreedingEvent, error)
+	Insert(ctx context.Context, event *BreedingEvent) error
+	Update(ctx context.Context, event *BreedingEvent) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// BreedingEventRepo implements BreedingEventInterface using GORM.
+type BreedingEventRepo struct {
+	DB *gorm.DB
+}
+
+// NewBreedingEventRepo creates a new instance of BreedingEventRepo.
+func NewBreedingEventRepo(db *gorm.DB) BreedingEventInterface {
+	return &BreedingEventRepo{DB: db}
+}
+
+// GetByBreedingEventID retrieves a breeding event with its herd and birth by
+// its BreedingEventID (UUID)
+func (b *BreedingEventRepo) GetByBreedingEventID(ctx context.Context, breedingEventID string) (*BreedingEvent, error) {
+	var event BreedingEvent
+	result := b.DB.WithContext(ctx).Preload("Livestock").Preload("Birth").
+		Where("breeding_event_id = ?", breedingEventID).First(&event)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &event, result.Error
+}
+
+// GetByFarmID retrieves a farm's breeding events with their births, most
+// recently served first
+func (b *BreedingEventRepo) GetByFarmID(ctx context.Context, farmID string, filter BreedingEventFilter) ([]*BreedingEvent, error) {
+	var events []*BreedingEvent
+	query := b.DB.WithContext(ctx).Preload("Birth").Where("farm_id = ?", farmID)
+	if filter.LivestockID != "" {
+		query = query.Where("livestock_id = ?", filter.LivestockID)
+	}
+	if filter.DamTag != "" {
+		query = query.Where("dam_tag = ?", filter.DamTag)
+	}
+	if filter.Outcome != "" {
+		query = query.Where("outcome = ?", filter.Outcome)
+	}
+	result := query.Order("service_date desc, id desc").Find(&events)
+	return events, result.Error
+}
+
+// GetDue retrieves a farm's events awaiting a birth that are due before the
+// given time, with their herds, soonest first
+func (b *BreedingEventRepo) GetDue(ctx context.Context, farmID string, before time.Time) ([]*BreedingEvent, error) {
+	var events []*BreedingEvent
+	result := b.DB.WithContext(ctx).Preload("Livestock").
+		Where("farm_id = ? AND outcome IN ? AND expected_due_date < ?", farmID, []string{"Pending", "Pregnant"}, before).
+		Order("expected_due_date, id").Find(&events)
+	return events, result.Error
+}
+
+// Insert adds a new breeding event
+func (b *BreedingEventRepo) Insert(ctx context.Context, event *BreedingEvent) error {
+	return b.DB.WithContext(ctx).Omit("Livestock", "Birth").Create(event).Error
+}
+
+// Update saves a breeding event
+func (b *BreedingEventRepo) Update(ctx context.Context, event *BreedingEvent) error {
+	return b.DB.WithContext(ctx).Omit("Livestock", "Birth").Save(event).Error
+}
+
+// DeleteByID soft deletes a breeding event by its ID
+func (b *BreedingEventRepo) DeleteByID(ctx context.Context, id int) error {
+	return b.DB.WithContext(ctx).Delete(&BreedingEvent{}, id).Error
+}
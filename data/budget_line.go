@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BudgetLine represents the budget_lines table in the database: the amount a
+// farm plans to spend, or to earn, in one transaction category over a season.
+// The budget variance compares it with the transactions counted towards the
+// season.
+type BudgetLine struct {
+	ID            uint           `gorm:"primaryKey" json:"-"`
+	BudgetLineID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"budgetLineId"`
+	FarmID        string         `gorm:"not null;size:36;index" json:"farmId"`   // Foreign key to Farm
+	SeasonID      string         `gorm:"not null;size:36;index" json:"seasonId"` // Foreign key to Season
+	Type          string         `gorm:"not null;default:'Expense'" json:"type"` // Income, Expense
+	Category      string         `gorm:"not null" json:"category"`               // Transaction category, e.g. Feed, Fertilizer, Sales
+	PlannedAmount float64        `gorm:"not null" json:"plannedAmount"`
+	Notes         string         `json:"notes"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BudgetLineInterface defines the contract for budget line operations
+type BudgetLineInterface interface {
+	GetByBudgetLineID(ctx context.Context, budgetLineID string) (*BudgetLine, error)
+	// GetBySeasonID retrieves a season's budget lines by type and category
+	GetBySeasonID(ctx context.Context, seasonID string) ([]*BudgetLine, error)
+	Insert(ctx context.Context, line *BudgetLine) error
+	Update(ctx context.Context, line *BudgetLine) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// BudgetLineRepo implements BudgetLineInterface using GORM.
+type BudgetLineRepo struct {
+	DB *gorm.DB
+}
+
+// NewBudgetLineRepo creates a new instance of BudgetLineRepo.
+func NewBudgetLineRepo(db *gorm.DB) BudgetLineInterface {
+	return &BudgetLineRepo{DB: db}
+}
+
+// GetByBudgetLineID retrieves a budget line by its BudgetLineID (UUID)
+func (b *BudgetLineRepo) GetByBudgetLineID(ctx context.Context, budgetLineID string) (*BudgetLine, error) {
+	var line BudgetLine
+	result := b.DB.WithContext(ctx).Where("budget_line_id = ?", budgetLineID).First(&line)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &line, result.Error
+}
+
+// GetBySeasonID retrieves a season's budget lines ordered by type and category
+func (b *BudgetLineRepo) GetBySeasonID(ctx context.Context, seasonID string) ([]*BudgetLine, error) {
+	var lines []*BudgetLine
+	result := b.DB.WithContext(ctx).Where("season_id = ?", seasonID).Order("type, category").Find(&lines)
+	return lines, result.Error
+}
+
+// Insert creates a new budget line in the database
+func (b *BudgetLineRepo) Insert(ctx context.Context, line *BudgetLine) error {
+	return b.DB.WithContext(ctx).Create(line).Error
+}
+
+// Update updates an existing budget line in the database
+func (b *BudgetLineRepo) Update(ctx context.Context, line *BudgetLine) error {
+	return b.DB.WithContext(ctx).Save(line).Error
+}
+
+// DeleteByID soft deletes a budget line by its ID
+func (b *BudgetLineRepo) DeleteByID(ctx context.Context, id int) error {
+	return b.DB.WithContext(ctx).Delete(&BudgetLine{}, id).Error
+}
@@ -0,0 +1,98 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BuyerProfile represents the buyer_profiles table in the database. A buyer
+// account submits its business details, which an admin verifies.
+type BuyerProfile struct {
+	ID                 uint           `gorm:"primaryKey" json:"-"`
+	BuyerProfileID     string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"buyerProfileId"`
+	UserID             string         `gorm:"not null;size:36;uniqueIndex" json:"userId"` // Foreign key to User
+	BusinessName       string         `gorm:"not null" json:"businessName"`
+	RegistrationNumber string         `json:"registrationNumber"` // Business or trading licence number
+	Location           string         `json:"location"`
+	DocumentURL        string         `json:"documentUrl"`                              // Supporting registration document
+	Status             string         `gorm:"not null;default:'Pending'" json:"status"` // Pending, Verified, Rejected
+	ReviewedBy         *string        `gorm:"size:36" json:"reviewedBy,omitempty"`      // Admin UserID
+	ReviewedAt         *time.Time     `json:"reviewedAt,omitempty"`
+	ReviewNotes        string         `json:"reviewNotes,omitempty"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;references:UserID" json:"user,omitempty"`
+}
+
+// Verified reports whether an admin has verified the buyer
+func (b *BuyerProfile) Verified() bool {
+	return b.Status == "Verified"
+}
+
+// BuyerProfileInterface defines the contract for buyer profile operations
+type BuyerProfileInterface interface {
+	GetByBuyerProfileID(ctx context.Context, buyerProfileID string) (*BuyerProfile, error)
+	GetByUserID(ctx context.Context, userID string) (*BuyerProfile, error)
+	GetByStatus(ctx context.Context, status string) ([]*BuyerProfile, error)
+	Insert(ctx context.Context, profile *BuyerProfile) error
+	Update(ctx context.Context, profile *BuyerProfile) error
+}
+
+// BuyerProfileRepo implements BuyerProfileInterface using GORM.
+type BuyerProfileRepo struct {
+	DB *gorm.DB
+}
+
+// NewBuyerProfileRepo creates a new instance of BuyerProfileRepo.
+func NewBuyerProfileRepo(db *gorm.DB) BuyerProfileInterface {
+	return &BuyerProfileRepo{DB: db}
+}
+
+// GetByBuyerProfileID retrieves a buyer profile and its user by BuyerProfileID (UUID)
+func (b *BuyerProfileRepo) GetByBuyerProfileID(ctx context.Context, buyerProfileID string) (*BuyerProfile, error) {
+	var profile BuyerProfile
+	result := b.DB.WithContext(ctx).Preload("User").Where("buyer_profile_id = ?", buyerProfileID).First(&profile)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &profile, result.Error
+}
+
+// GetByUserID retrieves the buyer profile and user of a buyer account
+func (b *BuyerProfileRepo) GetByUserID(ctx context.Context, userID string) (*BuyerProfile, error) {
+	var profile BuyerProfile
+	result := b.DB.WithContext(ctx).Preload("User").Where("user_id = ?", userID).First(&profile)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &profile, result.Error
+}
+
+// GetByStatus retrieves buyer profiles and their users with the given
+// status, oldest first so reviews are worked in order. An empty status
+// returns every profile.
+func (b *BuyerProfileRepo) GetByStatus(ctx context.Context, status string) ([]*BuyerProfile, error) {
+	var profiles []*BuyerProfile
+	query := b.DB.WithContext(ctx).Preload("User")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("updated_at").Find(&profiles)
+	return profiles, result.Error
+}
+
+// Insert creates a new buyer profile in the database
+func (b *BuyerProfileRepo) Insert(ctx context.Context, profile *BuyerProfile) error {
+	return b.DB.WithContext(ctx).Omit("User").Create(profile).Error
+}
+
+// Update updates an existing buyer profile in the database
+func (b *BuyerProfileRepo) Update(ctx context.Context, profile *BuyerProfile) error {
+	return b.DB.WithContext(ctx).Omit("User").Save(profile).Error
+}
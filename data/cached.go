@@ -0,0 +1,254 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"farm4u/cache"
+	"time"
+)
+
+// statsTTL is how long system-wide aggregates are cached. They change with
+// almost every write, so they expire rather than being invalidated.
+const statsTTL = time.Minute
+
+// revocationTTL is how long a token is remembered as not revoked. Revoking
+// a token through the cache marks it at once; the short expiry bounds how
+// long a failed write could leave a revoked token accepted.
+const revocationTTL = 30 * time.Second
+
+// Cache keys
+const (
+	keyStatsCounts      = "stats:counts"
+	keyStatsUsersByRole = "stats:users-by-role"
+)
+
+func userEmailKey(email string) string { return "user:email:" + email }
+func farmKey(farmID string) string     { return "farm:" + farmID }
+func revokedKey(tokenID string) string { return "token:revoked:" + tokenID }
+
+// Cached returns a copy of m whose user-by-email and farm-by-ID lookups,
+// token denylist checks and system-wide counts are served from c. Cached users and farms are kept for
+// ttl and dropped as soon as they are changed or deleted through m, or
+// through a transaction started from it. Cache errors are treated as misses.
+func (m Models) Cached(c cache.Cache, ttl time.Duration) Models {
+	store := cacheStore{cache: c, ttl: ttl}
+	m.User = &cachedUserRepo{UserInterface: m.User, store: store}
+	m.Farm = &cachedFarmRepo{FarmInterface: m.Farm, store: store}
+	m.SystemStats = &cachedSystemStatsRepo{SystemStatsInterface: m.SystemStats, store: store}
+	m.RevokedToken = &cachedRevokedTokenRepo{RevokedTokenInterface: m.RevokedToken, store: store}
+	m.cache = &store
+	return m
+}
+
+// cacheStore encodes records into a cache with gob, which unlike the JSON
+// encoding keeps the fields hidden from API responses, such as password hashes
+type cacheStore struct {
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// get decodes the value at key into v and reports whether there was one
+func (s cacheStore) get(ctx context.Context, key string, v any) bool {
+	value, ok, err := s.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return false
+	}
+	return gob.NewDecoder(bytes.NewReader(value)).Decode(v) == nil
+}
+
+// set stores v at key for ttl
+func (s cacheStore) set(ctx context.Context, key string, v any, ttl time.Duration) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return
+	}
+	s.cache.Set(ctx, key, buf.Bytes(), ttl)
+}
+
+// forget removes the values at keys. A failure leaves them to expire.
+func (s cacheStore) forget(ctx context.Context, keys ...string) {
+	s.cache.Delete(ctx, keys...)
+}
+
+// cachedUserRepo caches GetByEmail and CountByRole, and forgets a user
+// whenever they are written
+type cachedUserRepo struct {
+	UserInterface
+	store cacheStore
+}
+
+// GetByEmail implements UserInterface
+func (r *cachedUserRepo) GetByEmail(ctx context.Context, email string) (*User, error) {
+	var user User
+	if r.store.get(ctx, userEmailKey(email), &user) {
+		return &user, nil
+	}
+	found, err := r.UserInterface.GetByEmail(ctx, email)
+	if err == nil && found != nil {
+		r.store.set(ctx, userEmailKey(email), found, r.store.ttl)
+	}
+	return found, err
+}
+
+// CountByRole implements UserInterface
+func (r *cachedUserRepo) CountByRole(ctx context.Context) (map[string]int64, error) {
+	var counts map[string]int64
+	if r.store.get(ctx, keyStatsUsersByRole, &counts) {
+		return counts, nil
+	}
+	counts, err := r.UserInterface.CountByRole(ctx)
+	if err == nil {
+		r.store.set(ctx, keyStatsUsersByRole, counts, statsTTL)
+	}
+	return counts, err
+}
+
+// Insert implements UserInterface
+func (r *cachedUserRepo) Insert(ctx context.Context, user *User) error {
+	err := r.UserInterface.Insert(ctx, user)
+	r.store.forget(ctx, userEmailKey(user.Email), keyStatsUsersByRole)
+	return err
+}
+
+// Update implements UserInterface. The user is forgotten under the email
+// stored before the update too, in case it changed.
+func (r *cachedUserRepo) Update(ctx context.Context, user *User) error {
+	keys := []string{userEmailKey(user.Email), keyStatsUsersByRole}
+	if before, err := r.UserInterface.GetOne(ctx, int(user.ID)); err == nil && before != nil && before.Email != user.Email {
+		keys = append(keys, userEmailKey(before.Email))
+	}
+	err := r.UserInterface.Update(ctx, user)
+	r.store.forget(ctx, keys...)
+	return err
+}
+
+// ResetPassword implements UserInterface
+func (r *cachedUserRepo) ResetPassword(ctx context.Context, password string, user User) error {
+	err := r.UserInterface.ResetPassword(ctx, password, user)
+	r.store.forget(ctx, userEmailKey(user.Email))
+	return err
+}
+
+// DeleteByID implements UserInterface
+func (r *cachedUserRepo) DeleteByID(ctx context.Context, id int) error {
+	keys := []string{keyStatsUsersByRole}
+	if user, err := r.UserInterface.GetOne(ctx, id); err == nil && user != nil {
+		keys = append(keys, userEmailKey(user.Email))
+	}
+	err := r.UserInterface.DeleteByID(ctx, id)
+	r.store.forget(ctx, keys...)
+	return err
+}
+
+// GenerateAndSaveOTP implements UserInterface
+func (r *cachedUserRepo) GenerateAndSaveOTP(ctx context.Context, email string) (string, error) {
+	otp, err := r.UserInterface.GenerateAndSaveOTP(ctx, email)
+	r.store.forget(ctx, userEmailKey(email))
+	return otp, err
+}
+
+// VerifyOTP implements UserInterface
+func (r *cachedUserRepo) VerifyOTP(ctx context.Context, email, otp string) (bool, error) {
+	ok, err := r.UserInterface.VerifyOTP(ctx, email, otp)
+	r.store.forget(ctx, userEmailKey(email))
+	return ok, err
+}
+
+// ResetPasswordWithOTP implements UserInterface
+func (r *cachedUserRepo) ResetPasswordWithOTP(ctx context.Context, email, otp, newPassword string) error {
+	err := r.UserInterface.ResetPasswordWithOTP(ctx, email, otp, newPassword)
+	r.store.forget(ctx, userEmailKey(email))
+	return err
+}
+
+// cachedFarmRepo caches GetByFarmID and forgets a farm whenever it is written
+type cachedFarmRepo struct {
+	FarmInterface
+	store cacheStore
+}
+
+// GetByFarmID implements FarmInterface
+func (r *cachedFarmRepo) GetByFarmID(ctx context.Context, farmID string) (*Farm, error) {
+	var farm Farm
+	if r.store.get(ctx, farmKey(farmID), &farm) {
+		return &farm, nil
+	}
+	found, err := r.FarmInterface.GetByFarmID(ctx, farmID)
+	if err == nil && found != nil {
+		r.store.set(ctx, farmKey(farmID), found, r.store.ttl)
+	}
+	return found, err
+}
+
+// Update implements FarmInterface
+func (r *cachedFarmRepo) Update(ctx context.Context, farm *Farm) error {
+	err := r.FarmInterface.Update(ctx, farm)
+	r.store.forget(ctx, farmKey(farm.FarmID))
+	return err
+}
+
+// DeleteByID implements FarmInterface
+func (r *cachedFarmRepo) DeleteByID(ctx context.Context, id int) error {
+	farm, err := r.FarmInterface.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = r.FarmInterface.DeleteByID(ctx, id)
+	if farm != nil {
+		r.store.forget(ctx, farmKey(farm.FarmID))
+	}
+	return err
+}
+
+// cachedSystemStatsRepo caches the system-wide counts for statsTTL
+type cachedSystemStatsRepo struct {
+	SystemStatsInterface
+	store cacheStore
+}
+
+// Counts implements SystemStatsInterface
+func (r *cachedSystemStatsRepo) Counts(ctx context.Context) (map[string]int64, error) {
+	var counts map[string]int64
+	if r.store.get(ctx, keyStatsCounts, &counts) {
+		return counts, nil
+	}
+	counts, err := r.SystemStatsInterface.Counts(ctx)
+	if err == nil {
+		r.store.set(ctx, keyStatsCounts, counts, statsTTL)
+	}
+	return counts, err
+}
+
+// cachedRevokedTokenRepo caches IsRevoked, which runs on every authenticated
+// request
+type cachedRevokedTokenRepo struct {
+	RevokedTokenInterface
+	store cacheStore
+}
+
+// IsRevoked implements RevokedTokenInterface
+func (r *cachedRevokedTokenRepo) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+	if r.store.get(ctx, revokedKey(tokenID), &revoked) {
+		return revoked, nil
+	}
+	revoked, err := r.RevokedTokenInterface.IsRevoked(ctx, tokenID)
+	if err == nil {
+		ttl := revocationTTL
+		if revoked {
+			ttl = r.store.ttl
+		}
+		r.store.set(ctx, revokedKey(tokenID), revoked, ttl)
+	}
+	return revoked, err
+}
+
+// Insert implements RevokedTokenInterface
+func (r *cachedRevokedTokenRepo) Insert(ctx context.Context, token *RevokedToken) error {
+	err := r.RevokedTokenInterface.Insert(ctx, token)
+	if err == nil {
+		r.store.set(ctx, revokedKey(token.TokenID), true, r.store.ttl)
+	}
+	return err
+}
@@ -0,0 +1,151 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChemicalProduct represents the chemical_products table in the database.
+// Each row is one batch of an agrochemical held in the farm's chemical store.
+type ChemicalProduct struct {
+	ID                uint           `gorm:"primaryKey" json:"-"`
+	ChemicalProductID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"chemicalProductId"`
+	FarmID            string         `gorm:"not null;size:36" json:"farmId"` // Foreign key to Farm
+	Name              string         `gorm:"not null" json:"name"`           // Trade name
+	ActiveIngredient  string         `gorm:"not null" json:"activeIngredient"`
+	Category          string         `json:"category"`                        // Herbicide, Insecticide, Fungicide, Acaricide, etc.
+	WHOClass          string         `gorm:"not null" json:"whoClass"`        // Ia, Ib, II, III, U
+	Restricted        bool           `gorm:"default:false" json:"restricted"` // Restricted-use product (always true for class Ia/Ib)
+	BatchNumber       string         `gorm:"not null" json:"batchNumber"`
+	ExpiryDate        *time.Time     `json:"expiryDate"`
+	Quantity          float64        `gorm:"not null" json:"quantity"` // Quantity currently in store
+	Unit              string         `gorm:"not null" json:"unit"`     // L, kg, ml, g
+	StorageLocation   string         `json:"storageLocation"`
+	Supplier          string         `json:"supplier"`
+	Notes             string         `json:"notes"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm   *Farm           `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Usages []ChemicalUsage `gorm:"foreignKey:ChemicalProductID;references:ChemicalProductID" json:"usages,omitempty"`
+}
+
+// ChemicalProductInterface defines the contract for chemical store operations
+type ChemicalProductInterface interface {
+	GetAll(ctx context.Context) ([]*ChemicalProduct, error)
+	GetByID(ctx context.Context, id int) (*ChemicalProduct, error)
+	GetByChemicalProductID(ctx context.Context, chemicalProductID string) (*ChemicalProduct, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*ChemicalProduct, error)
+	GetRegister(ctx context.Context, farmID string, from, to *time.Time) ([]*ChemicalProduct, error)
+	Insert(ctx context.Context, product *ChemicalProduct) error
+	Update(ctx context.Context, product *ChemicalProduct) error
+	DeleteByID(ctx context.Context, id int) error
+	GetDeletedByFarmID(ctx context.Context, farmID string) ([]*ChemicalProduct, error)
+	GetDeletedByChemicalProductID(ctx context.Context, chemicalProductID string) (*ChemicalProduct, error)
+	RestoreByID(ctx context.Context, id int) error
+}
+
+// ChemicalProductRepo implements ChemicalProductInterface using GORM.
+type ChemicalProductRepo struct {
+	DB *gorm.DB
+}
+
+// NewChemicalProductRepo creates a new instance of ChemicalProductRepo.
+func NewChemicalProductRepo(db *gorm.DB) ChemicalProductInterface {
+	return &ChemicalProductRepo{DB: db}
+}
+
+// GetAll retrieves all chemical products from the database
+func (c *ChemicalProductRepo) GetAll(ctx context.Context) ([]*ChemicalProduct, error) {
+	var products []*ChemicalProduct
+	result := c.DB.WithContext(ctx).Find(&products)
+	return products, result.Error
+}
+
+// GetByID retrieves a chemical product by its ID
+func (c *ChemicalProductRepo) GetByID(ctx context.Context, id int) (*ChemicalProduct, error) {
+	var product ChemicalProduct
+	result := c.DB.WithContext(ctx).Where("id = ?", id).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &product, result.Error
+}
+
+// GetByChemicalProductID retrieves a chemical product by its ChemicalProductID (UUID)
+func (c *ChemicalProductRepo) GetByChemicalProductID(ctx context.Context, chemicalProductID string) (*ChemicalProduct, error) {
+	var product ChemicalProduct
+	result := c.DB.WithContext(ctx).Where("chemical_product_id = ?", chemicalProductID).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &product, result.Error
+}
+
+// GetByFarmID retrieves all chemical products held by a specific farm
+func (c *ChemicalProductRepo) GetByFarmID(ctx context.Context, farmID string) ([]*ChemicalProduct, error) {
+	var products []*ChemicalProduct
+	result := c.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("name").Find(&products)
+	return products, result.Error
+}
+
+// GetRegister retrieves the farm's chemical register for inspection: every
+// product with its usage entries, optionally limited to dates in [from, to)
+func (c *ChemicalProductRepo) GetRegister(ctx context.Context, farmID string, from, to *time.Time) ([]*ChemicalProduct, error) {
+	var products []*ChemicalProduct
+	result := c.DB.WithContext(ctx).Where("farm_id = ?", farmID).
+		Preload("Usages", func(db *gorm.DB) *gorm.DB {
+			if from != nil {
+				db = db.Where("date >= ?", *from)
+			}
+			if to != nil {
+				db = db.Where("date < ?", *to)
+			}
+			return db.Order("date")
+		}).
+		Order("name").
+		Find(&products)
+	return products, result.Error
+}
+
+// Insert creates a new chemical product in the database
+func (c *ChemicalProductRepo) Insert(ctx context.Context, product *ChemicalProduct) error {
+	return c.DB.WithContext(ctx).Create(product).Error
+}
+
+// Update updates an existing chemical product in the database
+func (c *ChemicalProductRepo) Update(ctx context.Context, product *ChemicalProduct) error {
+	return c.DB.WithContext(ctx).Save(product).Error
+}
+
+// DeleteByID soft deletes a chemical product by its ID
+func (c *ChemicalProductRepo) DeleteByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Delete(&ChemicalProduct{}, id).Error
+}
+
+// GetDeletedByFarmID retrieves soft-deleted chemical products belonging to a specific farm
+func (c *ChemicalProductRepo) GetDeletedByFarmID(ctx context.Context, farmID string) ([]*ChemicalProduct, error) {
+	var products []*ChemicalProduct
+	result := c.DB.WithContext(ctx).Unscoped().Where("farm_id = ? AND deleted_at IS NOT NULL", farmID).Order("deleted_at desc").Find(&products)
+	return products, result.Error
+}
+
+// GetDeletedByChemicalProductID retrieves a soft-deleted chemical product by its ChemicalProductID (UUID)
+func (c *ChemicalProductRepo) GetDeletedByChemicalProductID(ctx context.Context, chemicalProductID string) (*ChemicalProduct, error) {
+	var product ChemicalProduct
+	result := c.DB.WithContext(ctx).Unscoped().Where("chemical_product_id = ? AND deleted_at IS NOT NULL", chemicalProductID).First(&product)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &product, result.Error
+}
+
+// RestoreByID restores a soft-deleted chemical product by its ID
+func (c *ChemicalProductRepo) RestoreByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Unscoped().Model(&ChemicalProduct{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientStock is returned when a usage would take more than is in store
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// ChemicalUsage represents the chemical_usages table in the database.
+type ChemicalUsage struct {
+	ID                   uint           `gorm:"primaryKey" json:"-"`
+	ChemicalUsageID      string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"chemicalUsageId"`
+	ChemicalProductID    string         `gorm:"not null;size:36;index" json:"chemicalProductId"` // Foreign key to ChemicalProduct
+	FarmID               string         `gorm:"not null;size:36" json:"farmId"`                  // Foreign key to Farm
+	Date                 time.Time      `gorm:"not null" json:"date"`
+	Quantity             float64        `gorm:"not null" json:"quantity"` // In the product's unit
+	Applicator           string         `gorm:"not null" json:"applicator"`
+	ApplicatorEmployeeID *string        `gorm:"size:36" json:"applicatorEmployeeId,omitempty"` // Optional link to Employee
+	PPEConfirmed         bool           `gorm:"not null" json:"ppeConfirmed"`
+	Target               string         `json:"target"` // Crop, field or livestock treated
+	Purpose              string         `json:"purpose"`
+	Notes                string         `json:"notes"`
+	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ChemicalUsageInterface defines the contract for chemical usage operations
+type ChemicalUsageInterface interface {
+	GetByChemicalProductID(ctx context.Context, chemicalProductID string) ([]*ChemicalUsage, error)
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*ChemicalUsage, error)
+	Insert(ctx context.Context, usage *ChemicalUsage) error
+}
+
+// ChemicalUsageRepo implements ChemicalUsageInterface using GORM.
+type ChemicalUsageRepo struct {
+	DB *gorm.DB
+}
+
+// NewChemicalUsageRepo creates a new instance of ChemicalUsageRepo.
+func NewChemicalUsageRepo(db *gorm.DB) ChemicalUsageInterface {
+	return &ChemicalUsageRepo{DB: db}
+}
+
+// GetByChemicalProductID retrieves all usage entries for a chemical product
+func (c *ChemicalUsageRepo) GetByChemicalProductID(ctx context.Context, chemicalProductID string) ([]*ChemicalUsage, error) {
+	var usages []*ChemicalUsage
+	result := c.DB.WithContext(ctx).Where("chemical_product_id = ?", chemicalProductID).Order("date desc").Find(&usages)
+	return usages, result.Error
+}
+
+// GetByFarmID retrieves usage entries for a farm, optionally limited to
+// dates in [from, to)
+func (c *ChemicalUsageRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*ChemicalUsage, error) {
+	var usages []*ChemicalUsage
+	query := c.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc").Find(&usages)
+	return usages, result.Error
+}
+
+// Insert records a usage entry and deducts the quantity from the product's
+// stock in a single transaction. It returns ErrInsufficientStock if the
+// product does not hold enough.
+func (c *ChemicalUsageRepo) Insert(ctx context.Context, usage *ChemicalUsage) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var product ChemicalProduct
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("chemical_product_id = ?", usage.ChemicalProductID).
+			First(&product).Error; err != nil {
+			return err
+		}
+
+		if product.Quantity < usage.Quantity {
+			return ErrInsufficientStock
+		}
+
+		if err := tx.Model(&product).Update("quantity", product.Quantity-usage.Quantity).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(usage).Error
+	})
+}
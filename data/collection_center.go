@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CollectionCenter represents the collection_centers table in the database:
+// a milk collection center whose systems post farmers' deliveries through
+// the API with an API key. Only a hash of the key is kept.
+type CollectionCenter struct {
+	ID                 uint           `gorm:"primaryKey" json:"-"`
+	CollectionCenterID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"centerId"`
+	Name               string         `gorm:"not null" json:"name"`
+	Location           string         `json:"location"`
+	ManagerID          string         `gorm:"not null;size:36;index" json:"managerId"` // User who runs the center
+	PricePerLitre      float64        `gorm:"not null" json:"pricePerLitre"`           // Paid for accepted milk unless a delivery says otherwise
+	APIKeyHash         string         `gorm:"not null;uniqueIndex" json:"-"`           // SHA-256 of the API key
+	APIKeyPrefix       string         `gorm:"not null" json:"apiKeyPrefix"`            // Start of the key, to tell keys apart
+	Active             bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// MilkSupplier represents the milk_suppliers table in the database: a farm
+// its owner has registered with a collection center, under the supplier
+// number the center knows the farmer by
+type MilkSupplier struct {
+	ID                 uint           `gorm:"primaryKey" json:"-"`
+	MilkSupplierID     string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"milkSupplierId"`
+	CollectionCenterID string         `gorm:"not null;size:36;uniqueIndex:idx_milk_supplier_number,where:deleted_at IS NULL;uniqueIndex:idx_milk_supplier_farm,where:deleted_at IS NULL" json:"centerId"`
+	SupplierNumber     string         `gorm:"not null;uniqueIndex:idx_milk_supplier_number,where:deleted_at IS NULL" json:"supplierNumber"`
+	FarmID             string         `gorm:"not null;size:36;uniqueIndex:idx_milk_supplier_farm,where:deleted_at IS NULL;index" json:"farmId"` // Foreign key to Farm
+	LinkedBy           string         `gorm:"not null;size:36" json:"linkedBy"`                                                                 // Farm owner who registered the farm
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Center *CollectionCenter `gorm:"foreignKey:CollectionCenterID;references:CollectionCenterID" json:"center,omitempty"`
+	Farm   *Farm             `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// CollectionCenterInterface defines the contract for collection center and
+// milk supplier operations
+type CollectionCenterInterface interface {
+	GetByCollectionCenterID(ctx context.Context, collectionCenterID string) (*CollectionCenter, error)
+	GetByAPIKeyHash(ctx context.Context, hash string) (*CollectionCenter, error)
+	GetByManagerID(ctx context.Context, managerID string) ([]*CollectionCenter, error)
+	Insert(ctx context.Context, center *CollectionCenter) error
+	Update(ctx context.Context, center *CollectionCenter) error
+
+	// GetSupplier returns the farm registered with a center under a
+	// supplier number, or nil
+	GetSupplier(ctx context.Context, collectionCenterID, supplierNumber string) (*MilkSupplier, error)
+	GetSupplierByID(ctx context.Context, milkSupplierID string) (*MilkSupplier, error)
+	// GetSuppliers returns the farms registered with a center
+	GetSuppliers(ctx context.Context, collectionCenterID string) ([]*MilkSupplier, error)
+	// GetSuppliersByFarmID returns the centers a farm is registered with
+	GetSuppliersByFarmID(ctx context.Context, farmID string) ([]*MilkSupplier, error)
+	InsertSupplier(ctx context.Context, supplier *MilkSupplier) error
+	DeleteSupplierByID(ctx context.Context, id int) error
+}
+
+// CollectionCenterRepo implements CollectionCenterInterface using GORM.
+type CollectionCenterRepo struct {
+	DB *gorm.DB
+}
+
+// NewCollectionCenterRepo creates a new instance of CollectionCenterRepo.
+func NewCollectionCenterRepo(db *gorm.DB) CollectionCenterInterface {
+	return &CollectionCenterRepo{DB: db}
+}
+
+// GetByCollectionCenterID retrieves a center by its CollectionCenterID (UUID)
+func (c *CollectionCenterRepo) GetByCollectionCenterID(ctx context.Context, collectionCenterID string) (*CollectionCenter, error) {
+	var center CollectionCenter
+	result := c.DB.WithContext(ctx).Where("collection_center_id = ?", collectionCenterID).First(&center)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &center, result.Error
+}
+
+// GetByAPIKeyHash retrieves the center whose API key hashes to hash
+func (c *CollectionCenterRepo) GetByAPIKeyHash(ctx context.Context, hash string) (*CollectionCenter, error) {
+	var center CollectionCenter
+	result := c.DB.WithContext(ctx).Where("api_key_hash = ?", hash).First(&center)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &center, result.Error
+}
+
+// GetByManagerID retrieves the centers a user runs, by name
+func (c *CollectionCenterRepo) GetByManagerID(ctx context.Context, managerID string) ([]*CollectionCenter, error) {
+	var centers []*CollectionCenter
+	result := c.DB.WithContext(ctx).Where("manager_id = ?", managerID).Order("name").Find(&centers)
+	return centers, result.Error
+}
+
+// Insert adds a new center
+func (c *CollectionCenterRepo) Insert(ctx context.Context, center *CollectionCenter) error {
+	return c.DB.WithContext(ctx).Create(center).Error
+}
+
+// Update saves a center
+func (c *CollectionCenterRepo) Update(ctx context.Context, center *CollectionCenter) error {
+	return c.DB.WithContext(ctx).Save(center).Error
+}
+
+// GetSupplier retrieves the farm registered with a center under a supplier
+// number
+func (c *CollectionCenterRepo) GetSupplier(ctx context.Context, collectionCenterID, supplierNumber string) (*MilkSupplier, error) {
+	var supplier MilkSupplier
+	result := c.DB.WithContext(ctx).Where("collection_center_id = ? AND supplier_number = ?", collectionCenterID, supplierNumber).First(&supplier)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &supplier, result.Error
+}
+
+// GetSupplierByID retrieves a registration by its MilkSupplierID (UUID)
+func (c *CollectionCenterRepo) GetSupplierByID(ctx context.Context, milkSupplierID string) (*MilkSupplier, error) {
+	var supplier MilkSupplier
+	result := c.DB.WithContext(ctx).Where("milk_supplier_id = ?", milkSupplierID).First(&supplier)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &supplier, result.Error
+}
+
+// GetSuppliers retrieves the farms registered with a center, by supplier
+// number
+func (c *CollectionCenterRepo) GetSuppliers(ctx context.Context, collectionCenterID string) ([]*MilkSupplier, error) {
+	var suppliers []*MilkSupplier
+	result := c.DB.WithContext(ctx).Preload("Farm").Where("collection_center_id = ?", collectionCenterID).Order("supplier_number").Find(&suppliers)
+	return suppliers, result.Error
+}
+
+// GetSuppliersByFarmID retrieves the centers a farm is registered with
+func (c *CollectionCenterRepo) GetSuppliersByFarmID(ctx context.Context, farmID string) ([]*MilkSupplier, error) {
+	var suppliers []*MilkSupplier
+	result := c.DB.WithContext(ctx).Preload("Center").Where("farm_id = ?", farmID).Order("created_at").Find(&suppliers)
+	return suppliers, result.Error
+}
+
+// InsertSupplier registers a farm with a center
+func (c *CollectionCenterRepo) InsertSupplier(ctx context.Context, supplier *MilkSupplier) error {
+	return c.DB.WithContext(ctx).Omit("Center", "Farm").Create(supplier).Error
+}
+
+// DeleteSupplierByID soft deletes a registration by its ID
+func (c *CollectionCenterRepo) DeleteSupplierByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Delete(&MilkSupplier{}, id).Error
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -11,31 +12,65 @@ import (
 type Crop struct {
 	ID           uint           `gorm:"primaryKey" json:"-"`
 	CropID       string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"cropId"`
-	FarmID       string         `gorm:"not null;size:36" json:"farmId"` // Foreign key to Farm
+	FarmID       string         `gorm:"not null;size:36" json:"farmId"`          // Foreign key to Farm
+	FieldID      *string        `gorm:"size:36;index" json:"fieldId,omitempty"`  // Optional foreign key to Field
+	SeasonID     *string        `gorm:"size:36;index" json:"seasonId,omitempty"` // Optional foreign key to Season; unset counts towards the season planted in
 	Name         string         `gorm:"not null" json:"name"`
 	PlantingDate *time.Time     `json:"plantingDate"`
 	HarvestDate  *time.Time     `json:"harvestDate"`
 	Quantity     float64        `gorm:"not null" json:"quantity"`                 // Amount planted (kg or number of plants)
 	Status       string         `gorm:"not null;default:'Growing'" json:"status"` // Growing, Harvested, Failed
 	Notes        string         `json:"notes"`
+	Version      int            `gorm:"not null;default:1" json:"version"` // Incremented by every update; send it back to update
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
-	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Farm  *Farm  `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Field *Field `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+}
+
+// CropGroup is the crops of a farm with one name and status, counted in the
+// database
+type CropGroup struct {
+	Name     string  `json:"name"`
+	Status   string  `json:"status"`
+	Records  int     `json:"records"`  // Crop records in the group
+	Quantity float64 `json:"quantity"` // Amount planted, the records' quantities added up
 }
 
 // CropInterface defines the contract for crop operations
 type CropInterface interface {
-	GetAll() ([]*Crop, error)
-	GetByID(id int) (*Crop, error)
-	GetByCropID(cropID string) (*Crop, error)
-	GetByFarmID(farmID string) ([]*Crop, error)
-	Insert(crop *Crop) error
-	Update(crop *Crop) error
-	DeleteByID(id int) error
-	GetByStatus(status string) ([]*Crop, error)
+	GetAll(ctx context.Context) ([]*Crop, error)
+	GetByID(ctx context.Context, id int) (*Crop, error)
+	GetByCropID(ctx context.Context, cropID string) (*Crop, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Crop, error)
+	// GetByFarmIDAndDateRange retrieves a farm's crops planted in [from, to);
+	// with a range, crops without a planting date are left out
+	GetByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Crop, error)
+	// GetHarvestedByFarmIDAndDateRange retrieves a farm's crops with a
+	// harvest date in [from, to), oldest harvest first
+	GetHarvestedByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Crop, error)
+	GetByFieldID(ctx context.Context, fieldID string) ([]*Crop, error)
+	// GetByCropIDs retrieves the crops with the given CropIDs in a single
+	// query, leaving out those that do not exist
+	GetByCropIDs(ctx context.Context, cropIDs []string) ([]*Crop, error)
+	Insert(ctx context.Context, crop *Crop) error
+	// InsertMany creates crops in a single transaction
+	InsertMany(ctx context.Context, crops []*Crop) error
+	Update(ctx context.Context, crop *Crop) error
+	// UpdateMany updates several crops in a single transaction, each as
+	// Update does. A crop changed since it was loaded is left as it is and
+	// gets ErrStale in its slot of the returned errors.
+	UpdateMany(ctx context.Context, crops []*Crop) ([]error, error)
+	DeleteByID(ctx context.Context, id int) error
+	GetDeletedByFarmID(ctx context.Context, farmID string) ([]*Crop, error)
+	GetDeletedByCropID(ctx context.Context, cropID string) (*Crop, error)
+	RestoreByID(ctx context.Context, id int) error
+	GetByStatus(ctx context.Context, status string) ([]*Crop, error)
+	// Stats counts a farm's crops per name and status
+	Stats(ctx context.Context, farmID string) ([]CropGroup, error)
 }
 
 // CropRepo implements CropInterface using GORM.
@@ -49,16 +84,16 @@ func NewCropRepo(db *gorm.DB) CropInterface {
 }
 
 // GetAll retrieves all crops from the database
-func (c *CropRepo) GetAll() ([]*Crop, error) {
+func (c *CropRepo) GetAll(ctx context.Context) ([]*Crop, error) {
 	var crops []*Crop
-	result := c.DB.Find(&crops)
+	result := c.DB.WithContext(ctx).Find(&crops)
 	return crops, result.Error
 }
 
 // GetByID retrieves a crop by its ID
-func (c *CropRepo) GetByID(id int) (*Crop, error) {
+func (c *CropRepo) GetByID(ctx context.Context, id int) (*Crop, error) {
 	var crop Crop
-	result := c.DB.Where("id = ?", id).First(&crop)
+	result := c.DB.WithContext(ctx).Where("id = ?", id).First(&crop)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -66,40 +101,128 @@ func (c *CropRepo) GetByID(id int) (*Crop, error) {
 }
 
 // GetByCropID retrieves a crop by its CropID (UUID)
-func (c *CropRepo) GetByCropID(cropID string) (*Crop, error) {
+func (c *CropRepo) GetByCropID(ctx context.Context, cropID string) (*Crop, error) {
 	var crop Crop
-	result := c.DB.Where("crop_id = ?", cropID).First(&crop)
+	result := c.DB.WithContext(ctx).Where("crop_id = ?", cropID).First(&crop)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &crop, result.Error
 }
 
+// GetByCropIDs retrieves the crops with the given CropIDs (UUIDs)
+func (c *CropRepo) GetByCropIDs(ctx context.Context, cropIDs []string) ([]*Crop, error) {
+	var crops []*Crop
+	result := c.DB.WithContext(ctx).Where("crop_id IN ?", cropIDs).Find(&crops)
+	return crops, result.Error
+}
+
 // GetByFarmID retrieves all crops belonging to a specific farm
-func (c *CropRepo) GetByFarmID(farmID string) ([]*Crop, error) {
+func (c *CropRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Crop, error) {
 	var crops []*Crop
-	result := c.DB.Where("farm_id = ?", farmID).Find(&crops)
+	result := c.DB.WithContext(ctx).Where("farm_id = ?", farmID).Find(&crops)
+	return crops, result.Error
+}
+
+// Stats groups a farm's crops by name and status
+func (c *CropRepo) Stats(ctx context.Context, farmID string) ([]CropGroup, error) {
+	var groups []CropGroup
+	result := c.DB.WithContext(ctx).Model(&Crop{}).
+		Select("name, status, COUNT(*) AS records, COALESCE(SUM(quantity), 0) AS quantity").
+		Where("farm_id = ?", farmID).
+		Group("name, status").Order("name, status").
+		Scan(&groups)
+	return groups, result.Error
+}
+
+// GetByFarmIDAndDateRange retrieves the crops of a farm by planting date,
+// latest first
+func (c *CropRepo) GetByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Crop, error) {
+	var crops []*Crop
+	query := dateRange(c.DB.WithContext(ctx).Where("farm_id = ?", farmID), "planting_date", from, to)
+	result := query.Order("planting_date desc NULLS LAST").Find(&crops)
+	return crops, result.Error
+}
+
+// GetHarvestedByFarmIDAndDateRange retrieves the crops of a farm harvested,
+// or given up as failed, in a period
+func (c *CropRepo) GetHarvestedByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Crop, error) {
+	var crops []*Crop
+	query := dateRange(c.DB.WithContext(ctx).Where("farm_id = ? AND harvest_date IS NOT NULL", farmID), "harvest_date", from, to)
+	result := query.Order("harvest_date").Find(&crops)
+	return crops, result.Error
+}
+
+// GetByFieldID retrieves the crops planted on a field, oldest planting first,
+// which is the field's rotation history
+func (c *CropRepo) GetByFieldID(ctx context.Context, fieldID string) ([]*Crop, error) {
+	var crops []*Crop
+	result := c.DB.WithContext(ctx).Where("field_id = ?", fieldID).Order("planting_date, created_at").Find(&crops)
 	return crops, result.Error
 }
 
 // GetByStatus retrieves all crops with a specific status
-func (c *CropRepo) GetByStatus(status string) ([]*Crop, error) {
+func (c *CropRepo) GetByStatus(ctx context.Context, status string) ([]*Crop, error) {
 	var crops []*Crop
-	result := c.DB.Where("status = ?", status).Find(&crops)
+	result := c.DB.WithContext(ctx).Where("status = ?", status).Find(&crops)
 	return crops, result.Error
 }
 
 // Insert creates a new crop in the database
-func (c *CropRepo) Insert(crop *Crop) error {
-	return c.DB.Create(crop).Error
+func (c *CropRepo) Insert(ctx context.Context, crop *Crop) error {
+	return c.DB.WithContext(ctx).Omit("Field").Create(crop).Error
+}
+
+// InsertMany creates several crops in a single statement
+func (c *CropRepo) InsertMany(ctx context.Context, crops []*Crop) error {
+	return c.DB.WithContext(ctx).Omit("Field").Create(&crops).Error
+}
+
+// Update updates an existing crop in the database, moving it to its next
+// version. It returns ErrStale if the crop was updated since it was loaded.
+func (c *CropRepo) Update(ctx context.Context, crop *Crop) error {
+	return updateVersioned(c.DB.WithContext(ctx), crop, &crop.Version)
 }
 
-// Update updates an existing crop in the database
-func (c *CropRepo) Update(crop *Crop) error {
-	return c.DB.Save(crop).Error
+// UpdateMany updates the given crops in a single transaction
+func (c *CropRepo) UpdateMany(ctx context.Context, crops []*Crop) ([]error, error) {
+	errs := make([]error, len(crops))
+	err := c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, crop := range crops {
+			if err := updateVersioned(tx, crop, &crop.Version); errors.Is(err, ErrStale) {
+				errs[i] = err
+			} else if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return errs, err
 }
 
 // DeleteByID soft deletes a crop by its ID
-func (c *CropRepo) DeleteByID(id int) error {
-	return c.DB.Delete(&Crop{}, id).Error
+func (c *CropRepo) DeleteByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Delete(&Crop{}, id).Error
+}
+
+// GetDeletedByFarmID retrieves soft-deleted crops belonging to a specific farm
+func (c *CropRepo) GetDeletedByFarmID(ctx context.Context, farmID string) ([]*Crop, error) {
+	var crops []*Crop
+	result := c.DB.WithContext(ctx).Unscoped().Where("farm_id = ? AND deleted_at IS NOT NULL", farmID).Order("deleted_at desc").Find(&crops)
+	return crops, result.Error
+}
+
+// GetDeletedByCropID retrieves a soft-deleted crop by its CropID (UUID)
+func (c *CropRepo) GetDeletedByCropID(ctx context.Context, cropID string) (*Crop, error) {
+	var crop Crop
+	result := c.DB.WithContext(ctx).Unscoped().Where("crop_id = ? AND deleted_at IS NOT NULL", cropID).First(&crop)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &crop, result.Error
+}
+
+// RestoreByID restores a soft-deleted crop by its ID
+func (c *CropRepo) RestoreByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Unscoped().Model(&Crop{}).Where("id = ?", id).Update("deleted_at", nil).Error
 }
@@ -0,0 +1,206 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CropProtectionCategory is the expense category the cost of treating pests
+// and diseases is booked under in the finance ledger
+const CropProtectionCategory = "Crop Protection"
+
+// CropIncident represents the crop_incidents table in the database: a pest
+// or disease found on a crop or field, how bad it was and how it was
+// treated. Photos of the symptoms are kept as attachments.
+type CropIncident struct {
+	ID                uint           `gorm:"primaryKey" json:"-"`
+	CropIncidentID    string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"cropIncidentId"`
+	FarmID            string         `gorm:"not null;size:36;index" json:"farmId"`   // Foreign key to Farm
+	CropID            *string        `gorm:"size:36;index" json:"cropId,omitempty"`  // Crop affected, if any
+	FieldID           *string        `gorm:"size:36;index" json:"fieldId,omitempty"` // Field affected; the crop's field by default
+	Type              string         `gorm:"not null" json:"type"`                   // Pest, Disease
+	Name              string         `gorm:"not null" json:"name"`                   // e.g. Fall Armyworm, Maize Lethal Necrosis
+	Severity          string         `gorm:"not null" json:"severity"`               // Low, Moderate, High, Severe
+	ObservedAt        time.Time      `gorm:"not null;index" json:"observedAt"`
+	AffectedArea      float64        `json:"affectedArea"`                          // Hectares
+	Treatment         string         `json:"treatment"`                             // What was applied or done
+	Cost              float64        `json:"cost"`                                  // Of the treatment
+	Status            string         `gorm:"not null;default:'Open'" json:"status"` // Open, Treated, Resolved
+	ResolvedAt        *time.Time     `json:"resolvedAt,omitempty"`
+	Notes             string         `json:"notes"`
+	ServiceProviderID *string        `gorm:"size:36;index" json:"serviceProviderId,omitempty"` // Contractor who treated it, if in the farm's directory
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Crop            *Crop            `gorm:"foreignKey:CropID;references:CropID" json:"crop,omitempty"`
+	Field           *Field           `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+	ServiceProvider *ServiceProvider `gorm:"foreignKey:ServiceProviderID;references:ServiceProviderID" json:"serviceProvider,omitempty"`
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries this incident's treatment cost into the finance ledger
+func (c *CropIncident) TransactionReference() string {
+	return fmt.Sprintf("crop_incident:%s", c.CropIncidentID)
+}
+
+// CropIncidentFilter narrows a farm's incidents. Empty fields match all.
+type CropIncidentFilter struct {
+	CropID            string
+	FieldID           string
+	ServiceProviderID string
+	Type              string
+	Status            string
+	From              *time.Time // Observed on or after
+	To                *time.Time // Observed before
+}
+
+// IncidentHistory sums up how often one pest or disease struck a farm
+type IncidentHistory struct {
+	Type          string    `json:"type"`
+	Name          string    `json:"name"`
+	Incidents     int       `json:"incidents"`
+	Fields        int       `json:"fields"`        // Distinct fields it was found on
+	Crops         int       `json:"crops"`         // Distinct crops it was found on
+	SevereCount   int       `json:"severeCount"`   // Incidents rated High or Severe
+	Cost          float64   `json:"cost"`          // Of the treatments
+	FirstObserved time.Time `json:"firstObserved"` // Earliest incident
+	LastObserved  time.Time `json:"lastObserved"`  // Latest incident
+}
+
+// CropIncidentInterface defines the contract for crop incident operations
+type CropIncidentInterface interface {
+	GetByCropIncidentID(ctx context.Context, cropIncidentID string) (*CropIncident, error)
+	// GetByFarmID returns a farm's incidents, most recent first
+	GetByFarmID(ctx context.Context, farmID string, filter CropIncidentFilter) ([]*CropIncident, error)
+	// History groups a farm's incidents by pest or disease, the most
+	// frequent first
+	History(ctx context.Context, farmID string, filter CropIncidentFilter) ([]IncidentHistory, error)
+	Insert(ctx context.Context, incident *CropIncident) error
+	Update(ctx context.Context, incident *CropIncident) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// CropIncidentRepo implements CropIncidentInterface using GORM.
+type CropIncidentRepo struct {
+	DB *gorm.DB
+}
+
+// NewCropIncidentRepo creates a new instance of CropIncidentRepo.
+func NewCropIncidentRepo(db *gorm.DB) CropIncidentInterface {
+	return &CropIncidentRepo{DB: db}
+}
+
+// GetByCropIncidentID retrieves an incident by its CropIncidentID (UUID)
+func (c *CropIncidentRepo) GetByCropIncidentID(ctx context.Context, cropIncidentID string) (*CropIncident, error) {
+	var incident CropIncident
+	result := c.DB.WithContext(ctx).Preload("Crop").Preload("Field").Preload("ServiceProvider").
+		Where("crop_incident_id = ?", cropIncidentID).First(&incident)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &incident, result.Error
+}
+
+// GetByFarmID retrieves a farm's incidents, most recent first
+func (c *CropIncidentRepo) GetByFarmID(ctx context.Context, farmID string, filter CropIncidentFilter) ([]*CropIncident, error) {
+	var incidents []*CropIncident
+	result := c.filter(ctx, farmID, filter).Preload("Crop").Preload("Field").Preload("ServiceProvider").
+		Order("observed_at desc, id desc").Find(&incidents)
+	return incidents, result.Error
+}
+
+// History groups a farm's incidents by pest or disease, matching names
+// case-insensitively
+func (c *CropIncidentRepo) History(ctx context.Context, farmID string, filter CropIncidentFilter) ([]IncidentHistory, error) {
+	var history []IncidentHistory
+	result := c.filter(ctx, farmID, filter).Model(&CropIncident{}).
+		Select(`type, MAX(name) AS name, COUNT(*) AS incidents,
+			COUNT(DISTINCT field_id) AS fields, COUNT(DISTINCT crop_id) AS crops,
+			COUNT(*) FILTER (WHERE severity IN ('High', 'Severe')) AS severe_count,
+			COALESCE(SUM(cost), 0) AS cost, MIN(observed_at) AS first_observed, MAX(observed_at) AS last_observed`).
+		Group("type, LOWER(name)").
+		Order("incidents desc, last_observed desc").
+		Scan(&history)
+	return history, result.Error
+}
+
+// filter starts a query on a farm's incidents narrowed by filter
+func (c *CropIncidentRepo) filter(ctx context.Context, farmID string, filter CropIncidentFilter) *gorm.DB {
+	query := c.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if filter.CropID != "" {
+		query = query.Where("crop_id = ?", filter.CropID)
+	}
+	if filter.FieldID != "" {
+		query = query.Where("field_id = ?", filter.FieldID)
+	}
+	if filter.ServiceProviderID != "" {
+		query = query.Where("service_provider_id = ?", filter.ServiceProviderID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.From != nil {
+		query = query.Where("observed_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("observed_at < ?", *filter.To)
+	}
+	return query
+}
+
+// Insert creates a new incident and, when its treatment has a cost, the
+// matching expense in the finance ledger, in a single transaction
+func (c *CropIncidentRepo) Insert(ctx context.Context, incident *CropIncident) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Crop", "Field", "ServiceProvider").Create(incident).Error; err != nil {
+			return err
+		}
+		return syncExpense(tx, incident.TransactionReference(), incident.expense())
+	})
+}
+
+// Update saves an incident and brings its expense in line with it, in a
+// single transaction
+func (c *CropIncidentRepo) Update(ctx context.Context, incident *CropIncident) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Crop", "Field", "ServiceProvider").Save(incident).Error; err != nil {
+			return err
+		}
+		return syncExpense(tx, incident.TransactionReference(), incident.expense())
+	})
+}
+
+// DeleteByID soft deletes an incident by its ID along with its expense
+// transaction
+func (c *CropIncidentRepo) DeleteByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var incident CropIncident
+		if err := tx.Where("id = ?", id).First(&incident).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reference = ?", incident.TransactionReference()).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&incident).Error
+	})
+}
+
+// expense is the ledger entry for the incident's treatment cost
+func (c *CropIncident) expense() Transaction {
+	return Transaction{
+		FarmID:      c.FarmID,
+		Category:    CropProtectionCategory,
+		Amount:      c.Cost,
+		Date:        c.ObservedAt,
+		Description: fmt.Sprintf("Treating %s: %s", c.Name, c.Treatment),
+	}
+}
@@ -0,0 +1,155 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CropPlan represents the crop_plans table in the database: a crop intended
+// for a field in a coming season, with its planting window, expected harvest
+// and budgeted inputs.
+type CropPlan struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	CropPlanID      string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"planId"`
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"`  // Foreign key to Farm
+	FieldID         string         `gorm:"not null;size:36;index" json:"fieldId"` // Foreign key to Field
+	CropName        string         `gorm:"not null" json:"cropName"`
+	Variety         string         `json:"variety"`
+	PlantingStart   time.Time      `gorm:"not null" json:"plantingStart"` // First day of the planting window
+	PlantingEnd     time.Time      `gorm:"not null" json:"plantingEnd"`   // Last day of the planting window
+	ExpectedHarvest time.Time      `gorm:"not null" json:"expectedHarvest"`
+	ExpectedYield   float64        `json:"expectedYield"`
+	Status          string         `gorm:"not null;default:'Planned'" json:"status"` // Planned, Planted, Cancelled
+	BudgetedCost    float64        `json:"budgetedCost"`                             // Sum of the budgeted inputs
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Field  *Field          `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+	Inputs []CropPlanInput `gorm:"foreignKey:CropPlanID;references:CropPlanID" json:"inputs,omitempty"`
+}
+
+// CropPlanInput represents the crop_plan_inputs table in the database: one
+// budgeted input, such as seed or fertiliser, of a crop plan.
+type CropPlanInput struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	CropPlanInputID string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"inputId"`
+	CropPlanID      string    `gorm:"not null;size:36;index" json:"planId"` // Foreign key to CropPlan
+	Item            string    `gorm:"not null" json:"item"`
+	Quantity        float64   `json:"quantity"`
+	Unit            string    `json:"unit"`
+	Cost            float64   `json:"cost"` // Budgeted cost of the whole quantity
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// CalculateBudget sets BudgetedCost from the inputs
+func (p *CropPlan) CalculateBudget() {
+	p.BudgetedCost = 0
+	for _, input := range p.Inputs {
+		p.BudgetedCost += input.Cost
+	}
+}
+
+// Overlaps reports whether two plans would hold the same field at the same
+// time. A plan holds its field from the start of its planting window until its
+// expected harvest, so the next crop may be planted on the harvest day.
+func (p *CropPlan) Overlaps(other *CropPlan) bool {
+	return p.FieldID == other.FieldID &&
+		p.PlantingStart.Before(other.ExpectedHarvest) &&
+		other.PlantingStart.Before(p.ExpectedHarvest)
+}
+
+// CropPlanInterface defines the contract for crop plan operations
+type CropPlanInterface interface {
+	GetByCropPlanID(ctx context.Context, cropPlanID string) (*CropPlan, error)
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*CropPlan, error)
+	GetOverlapping(ctx context.Context, plan *CropPlan) ([]*CropPlan, error)
+	Insert(ctx context.Context, plan *CropPlan) error
+	Update(ctx context.Context, plan *CropPlan) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// CropPlanRepo implements CropPlanInterface using GORM.
+type CropPlanRepo struct {
+	DB *gorm.DB
+}
+
+// NewCropPlanRepo creates a new instance of CropPlanRepo.
+func NewCropPlanRepo(db *gorm.DB) CropPlanInterface {
+	return &CropPlanRepo{DB: db}
+}
+
+// GetByCropPlanID retrieves a plan with its field and inputs by its CropPlanID (UUID)
+func (c *CropPlanRepo) GetByCropPlanID(ctx context.Context, cropPlanID string) (*CropPlan, error) {
+	var plan CropPlan
+	result := c.DB.WithContext(ctx).Preload("Field").Preload("Inputs").Where("crop_plan_id = ?", cropPlanID).First(&plan)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &plan, result.Error
+}
+
+// GetByFarmID retrieves a farm's plans, earliest planting first. When from or
+// to is set, only plans holding their field at some point in [from, to) are
+// returned.
+func (c *CropPlanRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*CropPlan, error) {
+	var plans []*CropPlan
+	query := c.DB.WithContext(ctx).Preload("Field").Preload("Inputs").Where("farm_id = ?", farmID)
+	if from != nil {
+		query = query.Where("expected_harvest >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("planting_start < ?", *to)
+	}
+	result := query.Order("planting_start, crop_name").Find(&plans)
+	return plans, result.Error
+}
+
+// GetOverlapping retrieves the other plans, cancelled ones excepted, that
+// would hold plan's field at the same time as plan
+func (c *CropPlanRepo) GetOverlapping(ctx context.Context, plan *CropPlan) ([]*CropPlan, error) {
+	var plans []*CropPlan
+	query := c.DB.WithContext(ctx).Where("field_id = ? AND status <> ?", plan.FieldID, "Cancelled").
+		Where("planting_start < ? AND expected_harvest > ?", plan.ExpectedHarvest, plan.PlantingStart)
+	if plan.CropPlanID != "" {
+		query = query.Where("crop_plan_id <> ?", plan.CropPlanID)
+	}
+	result := query.Order("planting_start").Find(&plans)
+	return plans, result.Error
+}
+
+// Insert creates a new plan together with its inputs
+func (c *CropPlanRepo) Insert(ctx context.Context, plan *CropPlan) error {
+	return c.DB.WithContext(ctx).Omit("Field").Create(plan).Error
+}
+
+// Update saves a plan and replaces its inputs in a single transaction
+func (c *CropPlanRepo) Update(ctx context.Context, plan *CropPlan) error {
+	return c.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("crop_plan_id = ?", plan.CropPlanID).Delete(&CropPlanInput{}).Error; err != nil {
+			return err
+		}
+		for i := range plan.Inputs {
+			plan.Inputs[i].ID = 0
+			plan.Inputs[i].CropPlanInputID = ""
+			plan.Inputs[i].CropPlanID = plan.CropPlanID
+		}
+		if len(plan.Inputs) > 0 {
+			if err := tx.Create(&plan.Inputs).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Field", "Inputs").Save(plan).Error
+	})
+}
+
+// DeleteByID soft deletes a plan by its ID
+func (c *CropPlanRepo) DeleteByID(ctx context.Context, id int) error {
+	return c.DB.WithContext(ctx).Delete(&CropPlan{}, id).Error
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DashboardLayout represents the dashboard_layouts table in the database: the
+// widgets a user has chosen for their dashboard, in display order. It is kept
+// server-side so the same layout follows the user across devices.
+type DashboardLayout struct {
+	ID                uint              `gorm:"primaryKey" json:"-"`
+	DashboardLayoutID string            `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"layoutId"`
+	UserID            string            `gorm:"not null;size:36;uniqueIndex:idx_dashboard_layout_user,where:deleted_at IS NULL" json:"userId"` // Foreign key to User
+	Widgets           []DashboardWidget `gorm:"serializer:json" json:"widgets"`                                                                // In display order
+	CreatedAt         time.Time         `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time         `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// DashboardWidget is one widget placed on a dashboard
+type DashboardWidget struct {
+	Type string `json:"type"` // weather, cash-position, herd-health, open-tasks
+	Size string `json:"size"` // small, medium, large
+}
+
+// DashboardLayoutInterface defines the contract for dashboard layout operations
+type DashboardLayoutInterface interface {
+	// GetByUserID returns the user's saved layout, or nil
+	GetByUserID(ctx context.Context, userID string) (*DashboardLayout, error)
+	Insert(ctx context.Context, layout *DashboardLayout) error
+	Update(ctx context.Context, layout *DashboardLayout) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// DashboardLayoutRepo implements DashboardLayoutInterface using GORM.
+type DashboardLayoutRepo struct {
+	DB *gorm.DB
+}
+
+// NewDashboardLayoutRepo creates a new instance of DashboardLayoutRepo.
+func NewDashboardLayoutRepo(db *gorm.DB) DashboardLayoutInterface {
+	return &DashboardLayoutRepo{DB: db}
+}
+
+// GetByUserID retrieves a user's saved dashboard layout
+func (d *DashboardLayoutRepo) GetByUserID(ctx context.Context, userID string) (*DashboardLayout, error) {
+	var layout DashboardLayout
+	result := d.DB.WithContext(ctx).Where("user_id = ?", userID).First(&layout)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &layout, result.Error
+}
+
+// Insert creates a new dashboard layout
+func (d *DashboardLayoutRepo) Insert(ctx context.Context, layout *DashboardLayout) error {
+	return d.DB.WithContext(ctx).Create(layout).Error
+}
+
+// Update saves a dashboard layout
+func (d *DashboardLayoutRepo) Update(ctx context.Context, layout *DashboardLayout) error {
+	return d.DB.WithContext(ctx).Save(layout).Error
+}
+
+// DeleteByID soft deletes a dashboard layout by its ID
+func (d *DashboardLayoutRepo) DeleteByID(ctx context.Context, id int) error {
+	return d.DB.WithContext(ctx).Delete(&DashboardLayout{}, id).Error
+}
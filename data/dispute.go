@@ -0,0 +1,132 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Dispute represents the disputes table in the database: a claim by one party
+// to a sale against the other, arbitrated by an admin.
+type Dispute struct {
+	ID            uint           `gorm:"primaryKey" json:"-"`
+	DisputeID     string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"disputeId"`
+	SaleReference string         `gorm:"not null;index" json:"saleReference"`         // Identifies the sale, e.g. an invoice or transaction ID
+	ClaimantID    string         `gorm:"not null;size:36;index" json:"claimantId"`    // UserID of the party raising the dispute
+	RespondentID  string         `gorm:"not null;size:36;index" json:"respondentId"`  // UserID of the other party to the sale
+	Reason        string         `gorm:"not null" json:"reason"`                      // Not Delivered, Quality, Quantity, Payment, Other
+	Description   string         `gorm:"not null" json:"description"`                 // The claimant's account of the problem
+	Status        string         `gorm:"not null;default:'Open';index" json:"status"` // Open, Under Review, Resolved, Withdrawn
+	Outcome       string         `json:"outcome,omitempty"`                           // Upheld, Rejected; set when resolved
+	Resolution    string         `json:"resolution,omitempty"`                        // The arbitrator's decision and any remedy
+	ResolvedBy    *string        `gorm:"size:36" json:"resolvedBy,omitempty"`         // UserID of the arbitrating admin
+	ResolvedAt    *time.Time     `json:"resolvedAt,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Evidence []DisputeEvidence `gorm:"foreignKey:DisputeID;references:DisputeID" json:"evidence,omitempty"`
+}
+
+// DisputeEvidence represents the dispute_evidences table in the database: a
+// document or photo either party attaches to a dispute.
+type DisputeEvidence struct {
+	ID                uint      `gorm:"primaryKey" json:"-"`
+	DisputeEvidenceID string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"evidenceId"`
+	DisputeID         string    `gorm:"not null;size:36;index" json:"disputeId"` // Foreign key to Dispute
+	SubmittedBy       string    `gorm:"not null;size:36" json:"submittedBy"`     // UserID of the party attaching it
+	URL               string    `gorm:"not null" json:"url"`
+	Description       string    `json:"description"`
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// Closed reports whether the dispute has been resolved or withdrawn
+func (d *Dispute) Closed() bool {
+	return d.Status == "Resolved" || d.Status == "Withdrawn"
+}
+
+// Party reports whether the user is the claimant or the respondent
+func (d *Dispute) Party(userID string) bool {
+	return userID == d.ClaimantID || userID == d.RespondentID
+}
+
+// DisputeInterface defines the contract for dispute operations
+type DisputeInterface interface {
+	GetByDisputeID(ctx context.Context, disputeID string) (*Dispute, error)
+	GetByUserID(ctx context.Context, userID string) ([]*Dispute, error)
+	GetByStatus(ctx context.Context, status string) ([]*Dispute, error)
+	ExistsActive(ctx context.Context, claimantID, respondentID, saleReference string) (bool, error)
+	Insert(ctx context.Context, dispute *Dispute) error
+	Update(ctx context.Context, dispute *Dispute) error
+	AddEvidence(ctx context.Context, evidence *DisputeEvidence) error
+}
+
+// DisputeRepo implements DisputeInterface using GORM.
+type DisputeRepo struct {
+	DB *gorm.DB
+}
+
+// NewDisputeRepo creates a new instance of DisputeRepo.
+func NewDisputeRepo(db *gorm.DB) DisputeInterface {
+	return &DisputeRepo{DB: db}
+}
+
+// GetByDisputeID retrieves a dispute with its evidence by its DisputeID (UUID)
+func (d *DisputeRepo) GetByDisputeID(ctx context.Context, disputeID string) (*Dispute, error) {
+	var dispute Dispute
+	result := d.DB.WithContext(ctx).Preload("Evidence", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Where("dispute_id = ?", disputeID).First(&dispute)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &dispute, result.Error
+}
+
+// GetByUserID retrieves the disputes a user raised or responds to, newest first
+func (d *DisputeRepo) GetByUserID(ctx context.Context, userID string) ([]*Dispute, error) {
+	var disputes []*Dispute
+	result := d.DB.WithContext(ctx).Where("claimant_id = ? OR respondent_id = ?", userID, userID).Order("created_at desc").Find(&disputes)
+	return disputes, result.Error
+}
+
+// GetByStatus retrieves disputes with a status, or all when status is empty,
+// oldest first so the arbitration queue is worked in order
+func (d *DisputeRepo) GetByStatus(ctx context.Context, status string) ([]*Dispute, error) {
+	var disputes []*Dispute
+	query := d.DB.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("created_at").Find(&disputes)
+	return disputes, result.Error
+}
+
+// ExistsActive reports whether the claimant already has an open or under
+// review dispute against the respondent for a sale
+func (d *DisputeRepo) ExistsActive(ctx context.Context, claimantID, respondentID, saleReference string) (bool, error) {
+	var count int64
+	result := d.DB.WithContext(ctx).Model(&Dispute{}).
+		Where("claimant_id = ? AND respondent_id = ? AND sale_reference = ?", claimantID, respondentID, saleReference).
+		Where("status IN ?", []string{"Open", "Under Review"}).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// Insert creates a new dispute together with its evidence
+func (d *DisputeRepo) Insert(ctx context.Context, dispute *Dispute) error {
+	return d.DB.WithContext(ctx).Create(dispute).Error
+}
+
+// Update updates an existing dispute in the database, leaving its evidence alone
+func (d *DisputeRepo) Update(ctx context.Context, dispute *Dispute) error {
+	return d.DB.WithContext(ctx).Omit("Evidence").Save(dispute).Error
+}
+
+// AddEvidence attaches evidence to a dispute
+func (d *DisputeRepo) AddEvidence(ctx context.Context, evidence *DisputeEvidence) error {
+	return d.DB.WithContext(ctx).Create(evidence).Error
+}
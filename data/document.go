@@ -0,0 +1,124 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Document represents the documents table in the database: a compliance
+// paper such as a land title, a certification or an insurance policy. Scans
+// of the paper are attachments of record type document. A paper held for an
+// employee, such as their contract, an ID copy or a sprayer operator's
+// certification, names the employee.
+type Document struct {
+	ID           uint           `gorm:"primaryKey" json:"-"`
+	DocumentID   string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"documentId"`
+	FarmID       string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	EmployeeID   *string        `gorm:"size:36;index" json:"employeeId,omitempty"` // Optional foreign key to the Employee the paper is for
+	Type         string         `gorm:"not null" json:"type"`                      // Land Title, Certification, Insurance Policy, Permit, License, Contract, ID Copy, Other
+	Title        string         `gorm:"not null" json:"title"`
+	Number       string         `json:"number"` // Title deed, certificate or policy number
+	Issuer       string         `json:"issuer"` // Land registry, certification body or insurer
+	IssuedAt     *time.Time     `json:"issuedAt"`
+	ExpiresAt    *time.Time     `gorm:"index" json:"expiresAt"` // Nil for papers that do not expire, such as most land titles
+	ReminderDays int            `gorm:"not null;default:30" json:"reminderDays"`
+	Notes        string         `json:"notes"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	Files []*Attachment `gorm:"-" json:"files,omitempty"` // Scans of the paper, filled in by the document service
+
+	// Relationships
+	Employee *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+}
+
+// IsExpired reports whether the document has expired at the given time
+func (d *Document) IsExpired(now time.Time) bool {
+	return d.ExpiresAt != nil && !d.ExpiresAt.After(now)
+}
+
+// DocumentInterface defines the contract for document operations
+type DocumentInterface interface {
+	GetByDocumentID(ctx context.Context, documentID string) (*Document, error)
+	// GetByFarmID returns a farm's documents, optionally of one type, in
+	// order of expiry with papers that do not expire last
+	GetByFarmID(ctx context.Context, farmID, docType string) ([]*Document, error)
+	// GetByEmployeeID returns the documents held for an employee, in order of
+	// expiry with papers that do not expire last
+	GetByEmployeeID(ctx context.Context, employeeID string) ([]*Document, error)
+	// GetExpiring returns a farm's documents whose reminder window has opened
+	// at the given time, including those already expired, with the employees
+	// they are for
+	GetExpiring(ctx context.Context, farmID string, at time.Time) ([]*Document, error)
+	Insert(ctx context.Context, document *Document) error
+	Update(ctx context.Context, document *Document) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// DocumentRepo implements DocumentInterface using GORM.
+type DocumentRepo struct {
+	DB *gorm.DB
+}
+
+// NewDocumentRepo creates a new instance of DocumentRepo.
+func NewDocumentRepo(db *gorm.DB) DocumentInterface {
+	return &DocumentRepo{DB: db}
+}
+
+// GetByDocumentID retrieves a document by its DocumentID (UUID)
+func (d *DocumentRepo) GetByDocumentID(ctx context.Context, documentID string) (*Document, error) {
+	var document Document
+	result := d.DB.WithContext(ctx).Where("document_id = ?", documentID).First(&document)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &document, result.Error
+}
+
+// GetByFarmID retrieves all documents of a farm
+func (d *DocumentRepo) GetByFarmID(ctx context.Context, farmID, docType string) ([]*Document, error) {
+	var documents []*Document
+	query := d.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if docType != "" {
+		query = query.Where("type = ?", docType)
+	}
+	result := query.Order("expires_at NULLS LAST, title").Find(&documents)
+	return documents, result.Error
+}
+
+// GetByEmployeeID retrieves the documents of an employee
+func (d *DocumentRepo) GetByEmployeeID(ctx context.Context, employeeID string) ([]*Document, error) {
+	var documents []*Document
+	result := d.DB.WithContext(ctx).Where("employee_id = ?", employeeID).Order("expires_at NULLS LAST, title").Find(&documents)
+	return documents, result.Error
+}
+
+// GetExpiring retrieves a farm's documents that expire within their reminder
+// days of the given time
+func (d *DocumentRepo) GetExpiring(ctx context.Context, farmID string, at time.Time) ([]*Document, error) {
+	var documents []*Document
+	result := d.DB.WithContext(ctx).Preload("Employee").
+		Where("farm_id = ? AND expires_at IS NOT NULL AND expires_at < CAST(? AS timestamptz) + reminder_days * INTERVAL '1 day'", farmID, at).
+		Order("expires_at").
+		Find(&documents)
+	return documents, result.Error
+}
+
+// Insert adds a new document to the database
+func (d *DocumentRepo) Insert(ctx context.Context, document *Document) error {
+	return d.DB.WithContext(ctx).Omit("Employee").Create(document).Error
+}
+
+// Update modifies an existing document
+func (d *DocumentRepo) Update(ctx context.Context, document *Document) error {
+	return d.DB.WithContext(ctx).Omit("Employee").Save(document).Error
+}
+
+// DeleteByID deletes a document by its ID
+func (d *DocumentRepo) DeleteByID(ctx context.Context, id int) error {
+	return d.DB.WithContext(ctx).Delete(&Document{}, id).Error
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -20,6 +21,7 @@ type Employee struct {
 	HireDate    *time.Time     `json:"hireDate"`
 	ContactInfo string         `json:"contactInfo"`                             // Phone or email for contact
 	Status      string         `gorm:"not null;default:'Active'" json:"status"` // Active, Inactive, Terminated
+	Version     int            `gorm:"not null;default:1" json:"version"`       // Incremented by every update; send it back to update
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -31,16 +33,21 @@ type Employee struct {
 
 // EmployeeInterface defines the contract for employee operations
 type EmployeeInterface interface {
-	GetAll() ([]*Employee, error)
-	GetByID(id int) (*Employee, error)
-	GetByEmployeeID(employeeID string) (*Employee, error)
-	GetByFarmID(farmID string) ([]*Employee, error)
-	GetByUserID(userID string) ([]*Employee, error)
-	Insert(employee *Employee) error
-	Update(employee *Employee) error
-	DeleteByID(id int) error
-	GetByPosition(position string) ([]*Employee, error)
-	GetByStatus(status string) ([]*Employee, error)
+	GetAll(ctx context.Context) ([]*Employee, error)
+	GetByID(ctx context.Context, id int) (*Employee, error)
+	GetByEmployeeID(ctx context.Context, employeeID string) (*Employee, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Employee, error)
+	GetByUserID(ctx context.Context, userID string) ([]*Employee, error)
+	Insert(ctx context.Context, employee *Employee) error
+	// InsertMany creates employees in a single transaction
+	InsertMany(ctx context.Context, employees []*Employee) error
+	Update(ctx context.Context, employee *Employee) error
+	DeleteByID(ctx context.Context, id int) error
+	GetDeletedByFarmID(ctx context.Context, farmID string) ([]*Employee, error)
+	GetDeletedByEmployeeID(ctx context.Context, employeeID string) (*Employee, error)
+	RestoreByID(ctx context.Context, id int) error
+	GetByPosition(ctx context.Context, position string) ([]*Employee, error)
+	GetByStatus(ctx context.Context, status string) ([]*Employee, error)
 }
 
 // EmployeeRepo implements EmployeeInterface using GORM.
@@ -54,16 +61,16 @@ func NewEmployeeRepo(db *gorm.DB) EmployeeInterface {
 }
 
 // GetAll retrieves all employees from the database
-func (e *EmployeeRepo) GetAll() ([]*Employee, error) {
+func (e *EmployeeRepo) GetAll(ctx context.Context) ([]*Employee, error) {
 	var employees []*Employee
-	result := e.DB.Find(&employees)
+	result := e.DB.WithContext(ctx).Find(&employees)
 	return employees, result.Error
 }
 
 // GetByID retrieves an employee by its ID
-func (e *EmployeeRepo) GetByID(id int) (*Employee, error) {
+func (e *EmployeeRepo) GetByID(ctx context.Context, id int) (*Employee, error) {
 	var employee Employee
-	result := e.DB.Where("id = ?", id).First(&employee)
+	result := e.DB.WithContext(ctx).Where("id = ?", id).First(&employee)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -71,9 +78,9 @@ func (e *EmployeeRepo) GetByID(id int) (*Employee, error) {
 }
 
 // GetByEmployeeID retrieves an employee by its EmployeeID (UUID)
-func (e *EmployeeRepo) GetByEmployeeID(employeeID string) (*Employee, error) {
+func (e *EmployeeRepo) GetByEmployeeID(ctx context.Context, employeeID string) (*Employee, error) {
 	var employee Employee
-	result := e.DB.Where("employee_id = ?", employeeID).First(&employee)
+	result := e.DB.WithContext(ctx).Where("employee_id = ?", employeeID).First(&employee)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -81,44 +88,72 @@ func (e *EmployeeRepo) GetByEmployeeID(employeeID string) (*Employee, error) {
 }
 
 // GetByFarmID retrieves all employees belonging to a specific farm
-func (e *EmployeeRepo) GetByFarmID(farmID string) ([]*Employee, error) {
+func (e *EmployeeRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Employee, error) {
 	var employees []*Employee
-	result := e.DB.Where("farm_id = ?", farmID).Find(&employees)
+	result := e.DB.WithContext(ctx).Where("farm_id = ?", farmID).Find(&employees)
 	return employees, result.Error
 }
 
-// GetByUserID retrieves all employees linked to a specific user
-func (e *EmployeeRepo) GetByUserID(userID string) ([]*Employee, error) {
+// GetByUserID retrieves all employees linked to a specific user, with their farms
+func (e *EmployeeRepo) GetByUserID(ctx context.Context, userID string) ([]*Employee, error) {
 	var employees []*Employee
-	result := e.DB.Where("user_id = ?", userID).Find(&employees)
+	result := e.DB.WithContext(ctx).Preload("Farm").Where("user_id = ?", userID).Find(&employees)
 	return employees, result.Error
 }
 
 // GetByPosition retrieves all employees with a specific position
-func (e *EmployeeRepo) GetByPosition(position string) ([]*Employee, error) {
+func (e *EmployeeRepo) GetByPosition(ctx context.Context, position string) ([]*Employee, error) {
 	var employees []*Employee
-	result := e.DB.Where("position = ?", position).Find(&employees)
+	result := e.DB.WithContext(ctx).Where("position = ?", position).Find(&employees)
 	return employees, result.Error
 }
 
 // GetByStatus retrieves all employees with a specific status
-func (e *EmployeeRepo) GetByStatus(status string) ([]*Employee, error) {
+func (e *EmployeeRepo) GetByStatus(ctx context.Context, status string) ([]*Employee, error) {
 	var employees []*Employee
-	result := e.DB.Where("status = ?", status).Find(&employees)
+	result := e.DB.WithContext(ctx).Where("status = ?", status).Find(&employees)
 	return employees, result.Error
 }
 
 // Insert creates a new employee in the database
-func (e *EmployeeRepo) Insert(employee *Employee) error {
-	return e.DB.Create(employee).Error
+func (e *EmployeeRepo) Insert(ctx context.Context, employee *Employee) error {
+	return e.DB.WithContext(ctx).Create(employee).Error
 }
 
-// Update updates an existing employee in the database
-func (e *EmployeeRepo) Update(employee *Employee) error {
-	return e.DB.Save(employee).Error
+// InsertMany creates several employees in a single statement
+func (e *EmployeeRepo) InsertMany(ctx context.Context, employees []*Employee) error {
+	return e.DB.WithContext(ctx).Create(&employees).Error
+}
+
+// Update updates an existing employee in the database, moving it to its next
+// version. It returns ErrStale if the employee was updated since it was loaded.
+func (e *EmployeeRepo) Update(ctx context.Context, employee *Employee) error {
+	return updateVersioned(e.DB.WithContext(ctx), employee, &employee.Version)
 }
 
 // DeleteByID soft deletes an employee by its ID
-func (e *EmployeeRepo) DeleteByID(id int) error {
-	return e.DB.Delete(&Employee{}, id).Error
+func (e *EmployeeRepo) DeleteByID(ctx context.Context, id int) error {
+	return e.DB.WithContext(ctx).Delete(&Employee{}, id).Error
+}
+
+// GetDeletedByFarmID retrieves soft-deleted employees belonging to a specific farm
+func (e *EmployeeRepo) GetDeletedByFarmID(ctx context.Context, farmID string) ([]*Employee, error) {
+	var employees []*Employee
+	result := e.DB.WithContext(ctx).Unscoped().Where("farm_id = ? AND deleted_at IS NOT NULL", farmID).Order("deleted_at desc").Find(&employees)
+	return employees, result.Error
+}
+
+// GetDeletedByEmployeeID retrieves a soft-deleted employee by its EmployeeID (UUID)
+func (e *EmployeeRepo) GetDeletedByEmployeeID(ctx context.Context, employeeID string) (*Employee, error) {
+	var employee Employee
+	result := e.DB.WithContext(ctx).Unscoped().Where("employee_id = ? AND deleted_at IS NOT NULL", employeeID).First(&employee)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &employee, result.Error
+}
+
+// RestoreByID restores a soft-deleted employee by its ID
+func (e *EmployeeRepo) RestoreByID(ctx context.Context, id int) error {
+	return e.DB.WithContext(ctx).Unscoped().Model(&Employee{}).Where("id = ?", id).Update("deleted_at", nil).Error
 }
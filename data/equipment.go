@@ -0,0 +1,120 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Equipment represents the equipment table in the database. It covers
+// machinery and other tracked assets such as tractors and irrigation pumps.
+type Equipment struct {
+	ID                      uint           `gorm:"primaryKey" json:"-"`
+	EquipmentID             string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"equipmentId"`
+	FarmID                  string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name                    string         `gorm:"not null" json:"name"`
+	Type                    string         `gorm:"not null" json:"type"` // Tractor, Irrigation Pump, Harvester, Vehicle, Generator, Implement, Other
+	Make                    string         `json:"make"`
+	Model                   string         `json:"model"`
+	SerialNumber            string         `json:"serialNumber"`
+	PurchaseDate            *time.Time     `json:"purchaseDate"`
+	PurchaseCost            float64        `json:"purchaseCost"`
+	Condition               string         `gorm:"not null;default:'Good'" json:"condition"` // New, Good, Fair, Poor, Out of Service
+	MaintenanceIntervalDays int            `json:"maintenanceIntervalDays"`                  // Days between scheduled services (0 = no schedule)
+	LastMaintenanceDate     *time.Time     `json:"lastMaintenanceDate"`
+	NextMaintenanceDate     *time.Time     `gorm:"index" json:"nextMaintenanceDate"`
+	AssignedEmployeeID      *string        `gorm:"size:36" json:"assignedEmployeeId,omitempty"` // Optional foreign key to Employee
+	Notes                   string         `json:"notes"`
+	CreatedAt               time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt               time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt               gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm             *Farm     `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	AssignedEmployee *Employee `gorm:"foreignKey:AssignedEmployeeID;references:EmployeeID" json:"assignedEmployee,omitempty"`
+}
+
+// ScheduleNextMaintenance sets NextMaintenanceDate from the interval, counting
+// from the last service or, if there has been none, the purchase date. It is
+// left unchanged when there is no interval or nothing to count from.
+func (e *Equipment) ScheduleNextMaintenance() {
+	if e.MaintenanceIntervalDays <= 0 {
+		return
+	}
+	base := e.LastMaintenanceDate
+	if base == nil {
+		base = e.PurchaseDate
+	}
+	if base == nil {
+		return
+	}
+	next := base.AddDate(0, 0, e.MaintenanceIntervalDays)
+	e.NextMaintenanceDate = &next
+}
+
+// EquipmentInterface defines the contract for equipment operations
+type EquipmentInterface interface {
+	GetByEquipmentID(ctx context.Context, equipmentID string) (*Equipment, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Equipment, error)
+	GetMaintenanceDue(ctx context.Context, farmID string, before time.Time) ([]*Equipment, error)
+	Insert(ctx context.Context, equipment *Equipment) error
+	Update(ctx context.Context, equipment *Equipment) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// EquipmentRepo implements EquipmentInterface using GORM.
+type EquipmentRepo struct {
+	DB *gorm.DB
+}
+
+// NewEquipmentRepo creates a new instance of EquipmentRepo.
+func NewEquipmentRepo(db *gorm.DB) EquipmentInterface {
+	return &EquipmentRepo{DB: db}
+}
+
+// GetByEquipmentID retrieves equipment by its EquipmentID (UUID)
+func (e *EquipmentRepo) GetByEquipmentID(ctx context.Context, equipmentID string) (*Equipment, error) {
+	var equipment Equipment
+	result := e.DB.WithContext(ctx).Preload("AssignedEmployee").Where("equipment_id = ?", equipmentID).First(&equipment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &equipment, result.Error
+}
+
+// GetByFarmID retrieves all equipment belonging to a specific farm
+func (e *EquipmentRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Equipment, error) {
+	var equipment []*Equipment
+	result := e.DB.WithContext(ctx).Preload("AssignedEmployee").Where("farm_id = ?", farmID).Order("name").Find(&equipment)
+	return equipment, result.Error
+}
+
+// GetMaintenanceDue retrieves a farm's equipment whose next service is due
+// before the given time, including overdue equipment, soonest first.
+// Equipment that is out of service is skipped.
+func (e *EquipmentRepo) GetMaintenanceDue(ctx context.Context, farmID string, before time.Time) ([]*Equipment, error) {
+	var equipment []*Equipment
+	result := e.DB.WithContext(ctx).Preload("AssignedEmployee").
+		Where("farm_id = ? AND next_maintenance_date IS NOT NULL AND next_maintenance_date < ?", farmID, before).
+		Where("condition <> ?", "Out of Service").
+		Order("next_maintenance_date").
+		Find(&equipment)
+	return equipment, result.Error
+}
+
+// Insert creates new equipment in the database
+func (e *EquipmentRepo) Insert(ctx context.Context, equipment *Equipment) error {
+	return e.DB.WithContext(ctx).Omit("AssignedEmployee").Create(equipment).Error
+}
+
+// Update updates existing equipment in the database
+func (e *EquipmentRepo) Update(ctx context.Context, equipment *Equipment) error {
+	return e.DB.WithContext(ctx).Omit("AssignedEmployee").Save(equipment).Error
+}
+
+// DeleteByID soft deletes equipment by its ID
+func (e *EquipmentRepo) DeleteByID(ctx context.Context, id int) error {
+	return e.DB.WithContext(ctx).Delete(&Equipment{}, id).Error
+}
@@ -0,0 +1,123 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Escrow represents the escrows table in the database: a buyer's payment for
+// a sale held by the platform until delivery is confirmed, then released to
+// the farmer or refunded to the buyer.
+type Escrow struct {
+	ID               uint           `gorm:"primaryKey" json:"-"`
+	EscrowID         string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"escrowId"`
+	SaleReference    string         `gorm:"not null;uniqueIndex" json:"saleReference"`   // Identifies the sale, e.g. an invoice or transaction ID
+	BuyerID          string         `gorm:"not null;size:36;index" json:"buyerId"`       // UserID of the paying buyer
+	FarmerID         string         `gorm:"not null;size:36;index" json:"farmerId"`      // UserID of the farmer being paid
+	Amount           float64        `gorm:"not null" json:"amount"`                      // Amount held
+	PaymentReference string         `json:"paymentReference"`                            // Payment provider's reference for the buyer's payment
+	Status           string         `gorm:"not null;default:'Held';index" json:"status"` // Held, Released, Refunded
+	ReleaseCode      string         `gorm:"size:8" json:"releaseCode,omitempty"`         // Gate pass code the buyer hands over on delivery; shown to the buyer only
+	ReleaseAfter     time.Time      `gorm:"not null;index" json:"releaseAfter"`          // When the funds are released automatically if nobody acts
+	ReleasedAt       *time.Time     `json:"releasedAt,omitempty"`
+	ReleaseMethod    string         `json:"releaseMethod,omitempty"` // Gate Pass, Buyer Confirmation, Automatic, Arbitration
+	RefundedAt       *time.Time     `json:"refundedAt,omitempty"`
+	RefundReason     string         `json:"refundReason,omitempty"`
+	ClosedBy         *string        `gorm:"size:36" json:"closedBy,omitempty"` // UserID that released or refunded; empty for automatic release
+	Notes            string         `json:"notes"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// EscrowInterface defines the contract for escrow operations
+type EscrowInterface interface {
+	GetByEscrowID(ctx context.Context, escrowID string) (*Escrow, error)
+	GetBySaleReference(ctx context.Context, saleReference string) (*Escrow, error)
+	GetByUserID(ctx context.Context, userID string) ([]*Escrow, error)
+	GetByStatus(ctx context.Context, status string) ([]*Escrow, error)
+	GetDueForRelease(ctx context.Context, now time.Time) ([]*Escrow, error)
+	Insert(ctx context.Context, escrow *Escrow) error
+	// Close moves a held escrow to its final status. It returns false without
+	// error when the escrow was no longer held, e.g. released concurrently.
+	Close(ctx context.Context, escrow *Escrow) (bool, error)
+}
+
+// EscrowRepo implements EscrowInterface using GORM.
+type EscrowRepo struct {
+	DB *gorm.DB
+}
+
+// NewEscrowRepo creates a new instance of EscrowRepo.
+func NewEscrowRepo(db *gorm.DB) EscrowInterface {
+	return &EscrowRepo{DB: db}
+}
+
+// GetByEscrowID retrieves an escrow by its EscrowID (UUID)
+func (e *EscrowRepo) GetByEscrowID(ctx context.Context, escrowID string) (*Escrow, error) {
+	var escrow Escrow
+	result := e.DB.WithContext(ctx).Where("escrow_id = ?", escrowID).First(&escrow)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &escrow, result.Error
+}
+
+// GetBySaleReference retrieves the escrow for a sale
+func (e *EscrowRepo) GetBySaleReference(ctx context.Context, saleReference string) (*Escrow, error) {
+	var escrow Escrow
+	result := e.DB.WithContext(ctx).Where("sale_reference = ?", saleReference).First(&escrow)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &escrow, result.Error
+}
+
+// GetByUserID retrieves the escrows a user pays into or is paid from, newest first
+func (e *EscrowRepo) GetByUserID(ctx context.Context, userID string) ([]*Escrow, error) {
+	var escrows []*Escrow
+	result := e.DB.WithContext(ctx).Where("buyer_id = ? OR farmer_id = ?", userID, userID).Order("created_at desc").Find(&escrows)
+	return escrows, result.Error
+}
+
+// GetByStatus retrieves escrows with a status, or all when status is empty, newest first
+func (e *EscrowRepo) GetByStatus(ctx context.Context, status string) ([]*Escrow, error) {
+	var escrows []*Escrow
+	query := e.DB.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("created_at desc").Find(&escrows)
+	return escrows, result.Error
+}
+
+// GetDueForRelease retrieves held escrows whose automatic release time has passed
+func (e *EscrowRepo) GetDueForRelease(ctx context.Context, now time.Time) ([]*Escrow, error) {
+	var escrows []*Escrow
+	result := e.DB.WithContext(ctx).Where("status = ? AND release_after <= ?", "Held", now).Order("release_after").Find(&escrows)
+	return escrows, result.Error
+}
+
+// Insert creates a new escrow in the database
+func (e *EscrowRepo) Insert(ctx context.Context, escrow *Escrow) error {
+	return e.DB.WithContext(ctx).Create(escrow).Error
+}
+
+// Close moves a held escrow to its final status, guarding on the held status
+// so an escrow is never both released and refunded
+func (e *EscrowRepo) Close(ctx context.Context, escrow *Escrow) (bool, error) {
+	result := e.DB.WithContext(ctx).Model(&Escrow{}).
+		Where("escrow_id = ? AND status = ?", escrow.EscrowID, "Held").
+		Updates(map[string]any{
+			"status":         escrow.Status,
+			"released_at":    escrow.ReleasedAt,
+			"release_method": escrow.ReleaseMethod,
+			"refunded_at":    escrow.RefundedAt,
+			"refund_reason":  escrow.RefundReason,
+			"closed_by":      escrow.ClosedBy,
+		})
+	return result.RowsAffected == 1, result.Error
+}
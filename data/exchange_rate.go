@@ -0,0 +1,99 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExchangeRate represents the exchange_rates table in the database: what one
+// unit of another currency was worth in the farm's currency from a date.
+// Transactions entered in that currency are converted at the latest rate on
+// or before their date.
+type ExchangeRate struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	ExchangeRateID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"exchangeRateId"`
+	FarmID         string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Currency       string         `gorm:"not null;size:3" json:"currency"`      // ISO 4217 code converted from, e.g. KES
+	Rate           float64        `gorm:"not null" json:"rate"`                 // Farm currency per unit of Currency
+	Date           time.Time      `gorm:"not null" json:"date"`                 // Day the rate applies from
+	Notes          string         `json:"notes"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ExchangeRateInterface defines the contract for exchange rate operations
+type ExchangeRateInterface interface {
+	GetByExchangeRateID(ctx context.Context, exchangeRateID string) (*ExchangeRate, error)
+	// GetByFarmID retrieves a farm's rates, optionally for one currency
+	GetByFarmID(ctx context.Context, farmID, currency string) ([]*ExchangeRate, error)
+	// GetEffective retrieves the latest rate for currency dated on or before
+	// date, or nil if there is none
+	GetEffective(ctx context.Context, farmID, currency string, date time.Time) (*ExchangeRate, error)
+	Insert(ctx context.Context, rate *ExchangeRate) error
+	Update(ctx context.Context, rate *ExchangeRate) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ExchangeRateRepo implements ExchangeRateInterface using GORM.
+type ExchangeRateRepo struct {
+	DB *gorm.DB
+}
+
+// NewExchangeRateRepo creates a new instance of ExchangeRateRepo.
+func NewExchangeRateRepo(db *gorm.DB) ExchangeRateInterface {
+	return &ExchangeRateRepo{DB: db}
+}
+
+// GetByExchangeRateID retrieves a rate by its ExchangeRateID (UUID)
+func (e *ExchangeRateRepo) GetByExchangeRateID(ctx context.Context, exchangeRateID string) (*ExchangeRate, error) {
+	var rate ExchangeRate
+	result := e.DB.WithContext(ctx).Where("exchange_rate_id = ?", exchangeRateID).First(&rate)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rate, result.Error
+}
+
+// GetByFarmID retrieves a farm's rates by currency, newest first
+func (e *ExchangeRateRepo) GetByFarmID(ctx context.Context, farmID, currency string) ([]*ExchangeRate, error) {
+	var rates []*ExchangeRate
+	query := e.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if currency != "" {
+		query = query.Where("currency = ?", currency)
+	}
+	result := query.Order("currency, date desc").Find(&rates)
+	return rates, result.Error
+}
+
+// GetEffective retrieves the rate a transaction in currency dated date is
+// converted at
+func (e *ExchangeRateRepo) GetEffective(ctx context.Context, farmID, currency string, date time.Time) (*ExchangeRate, error) {
+	var rate ExchangeRate
+	result := e.DB.WithContext(ctx).
+		Where("farm_id = ? AND currency = ? AND date <= ?", farmID, currency, date).
+		Order("date desc").
+		First(&rate)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rate, result.Error
+}
+
+// Insert creates a new rate in the database
+func (e *ExchangeRateRepo) Insert(ctx context.Context, rate *ExchangeRate) error {
+	return e.DB.WithContext(ctx).Create(rate).Error
+}
+
+// Update updates an existing rate in the database
+func (e *ExchangeRateRepo) Update(ctx context.Context, rate *ExchangeRate) error {
+	return e.DB.WithContext(ctx).Save(rate).Error
+}
+
+// DeleteByID soft deletes a rate by its ID
+func (e *ExchangeRateRepo) DeleteByID(ctx context.Context, id int) error {
+	return e.DB.WithContext(ctx).Delete(&ExchangeRate{}, id).Error
+}
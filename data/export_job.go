@@ -0,0 +1,154 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExportJob represents the export_jobs table in the database: a user's
+// request for a copy of everything they keep in farm4u. Exports are bundled
+// in the background; once Ready the ZIP lives in object storage under
+// FileKey.
+type ExportJob struct {
+	ID          uint           `gorm:"primaryKey" json:"-"`
+	ExportJobID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"exportId"`
+	UserID      string         `gorm:"not null;size:36;index" json:"userId"`           // User whose data is exported
+	Status      string         `gorm:"not null;default:'Pending';index" json:"status"` // Pending, Running, Ready, Failed
+	Error       string         `json:"error,omitempty"`
+	Farms       int            `json:"farms"` // Farms included, once Ready
+	Files       int            `json:"files"` // Attached files included, once Ready
+	Size        int64          `json:"size"`  // Bytes, once Ready
+	StartedAt   *time.Time     `json:"startedAt,omitempty"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// FileKey is the storage key of the bundled ZIP
+func (j *ExportJob) FileKey() string {
+	return fmt.Sprintf("users/%s/exports/%s.zip", j.UserID, j.ExportJobID)
+}
+
+// ExportJobInterface defines the contract for export job operations
+type ExportJobInterface interface {
+	GetByExportJobID(ctx context.Context, exportJobID string) (*ExportJob, error)
+	// GetByUserID returns a user's export jobs, newest first
+	GetByUserID(ctx context.Context, userID string) ([]*ExportJob, error)
+	// GetQueued returns a user's Pending or Running job, or nil
+	GetQueued(ctx context.Context, userID string) (*ExportJob, error)
+	// GetPending returns the IDs of jobs still Pending that were requested
+	// before the given time
+	GetPending(ctx context.Context, before time.Time) ([]string, error)
+	Insert(ctx context.Context, job *ExportJob) error
+	// Claim moves a Pending job to Running, reporting whether it was still
+	// Pending so a job is only bundled once
+	Claim(ctx context.Context, job *ExportJob) (bool, error)
+	// Finish records the outcome of a Running job
+	Finish(ctx context.Context, job *ExportJob) error
+	// FailStale marks jobs Running since before the given time Failed,
+	// returning how many there were
+	FailStale(ctx context.Context, before time.Time, reason string) (int64, error)
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ExportJobRepo implements ExportJobInterface using GORM.
+type ExportJobRepo struct {
+	DB *gorm.DB
+}
+
+// NewExportJobRepo creates a new instance of ExportJobRepo.
+func NewExportJobRepo(db *gorm.DB) ExportJobInterface {
+	return &ExportJobRepo{DB: db}
+}
+
+// GetByExportJobID retrieves an export job by its ExportJobID (UUID)
+func (e *ExportJobRepo) GetByExportJobID(ctx context.Context, exportJobID string) (*ExportJob, error) {
+	var job ExportJob
+	result := e.DB.WithContext(ctx).Where("export_job_id = ?", exportJobID).First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, result.Error
+}
+
+// GetByUserID retrieves a user's export jobs, newest first
+func (e *ExportJobRepo) GetByUserID(ctx context.Context, userID string) ([]*ExportJob, error) {
+	var jobs []*ExportJob
+	result := e.DB.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&jobs)
+	return jobs, result.Error
+}
+
+// GetQueued retrieves a user's Pending or Running job
+func (e *ExportJobRepo) GetQueued(ctx context.Context, userID string) (*ExportJob, error) {
+	var job ExportJob
+	result := e.DB.WithContext(ctx).Where("user_id = ? AND status IN ?", userID, []string{"Pending", "Running"}).
+		Order("created_at desc").First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, result.Error
+}
+
+// GetPending retrieves the IDs of jobs still Pending that were requested
+// before the given time, oldest first
+func (e *ExportJobRepo) GetPending(ctx context.Context, before time.Time) ([]string, error) {
+	var ids []string
+	result := e.DB.WithContext(ctx).Model(&ExportJob{}).
+		Where("status = ? AND created_at < ?", "Pending", before).
+		Order("created_at").Pluck("export_job_id", &ids)
+	return ids, result.Error
+}
+
+// Insert creates a new export job
+func (e *ExportJobRepo) Insert(ctx context.Context, job *ExportJob) error {
+	return e.DB.WithContext(ctx).Create(job).Error
+}
+
+// Claim moves a Pending job to Running, guarding on the Pending status so two
+// workers never bundle the same export
+func (e *ExportJobRepo) Claim(ctx context.Context, job *ExportJob) (bool, error) {
+	result := e.DB.WithContext(ctx).Model(&ExportJob{}).
+		Where("export_job_id = ? AND status = ?", job.ExportJobID, "Pending").
+		Updates(map[string]any{
+			"status":     "Running",
+			"started_at": job.StartedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish records the outcome of a Running job
+func (e *ExportJobRepo) Finish(ctx context.Context, job *ExportJob) error {
+	return e.DB.WithContext(ctx).Model(&ExportJob{}).
+		Where("export_job_id = ? AND status = ?", job.ExportJobID, "Running").
+		Updates(map[string]any{
+			"status":       job.Status,
+			"error":        job.Error,
+			"farms":        job.Farms,
+			"files":        job.Files,
+			"size":         job.Size,
+			"completed_at": job.CompletedAt,
+		}).Error
+}
+
+// FailStale marks jobs Running since before the given time Failed, such as
+// those left behind when the server stopped mid-export
+func (e *ExportJobRepo) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	result := e.DB.WithContext(ctx).Model(&ExportJob{}).
+		Where("status = ? AND started_at < ?", "Running", before).
+		Updates(map[string]any{
+			"status":       "Failed",
+			"error":        reason,
+			"completed_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByID soft deletes a export job by its ID
+func (e *ExportJobRepo) DeleteByID(ctx context.Context, id int) error {
+	return e.DB.WithContext(ctx).Delete(&ExportJob{}, id).Error
+}
@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -14,10 +15,12 @@ type Farm struct {
 	Name        string         `gorm:"not null" json:"name"`
 	Description string         `json:"description"`
 	Location    string         `gorm:"not null" json:"location"`
-	Size        float64        `gorm:"not null" json:"size"`                    // Size in acres/hectares
-	FarmType    string         `gorm:"not null" json:"farmType"`                // e.g., "Crop", "Livestock", "Mixed"
-	Status      string         `gorm:"not null;default:'Active'" json:"status"` // Active, Inactive, Suspended
-	UserID      string         `gorm:"not null;size:36" json:"userId"`          // Foreign key to User
+	Size        float64        `gorm:"not null" json:"size"`                          // Size in acres/hectares
+	FarmType    string         `gorm:"not null" json:"farmType"`                      // e.g., "Crop", "Livestock", "Mixed"
+	Currency    string         `gorm:"size:3;not null;default:'UGX'" json:"currency"` // ISO 4217 code the farm keeps its accounts in
+	Status      string         `gorm:"not null;default:'Active'" json:"status"`       // Active, Inactive, Suspended
+	UserID      string         `gorm:"not null;size:36" json:"userId"`                // Foreign key to User
+	Version     int            `gorm:"not null;default:1" json:"version"`             // Incremented by every update; send it back to update
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
@@ -37,16 +40,16 @@ func NewFarmRepo(db *gorm.DB) FarmInterface {
 }
 
 // GetAll retrieves all farms from the database
-func (f *FarmRepo) GetAll() ([]*Farm, error) {
+func (f *FarmRepo) GetAll(ctx context.Context) ([]*Farm, error) {
 	var farms []*Farm
-	result := f.DB.Find(&farms)
+	result := f.DB.WithContext(ctx).Find(&farms)
 	return farms, result.Error
 }
 
 // GetByID retrieves a farm by its ID
-func (f *FarmRepo) GetByID(id int) (*Farm, error) {
+func (f *FarmRepo) GetByID(ctx context.Context, id int) (*Farm, error) {
 	var farm Farm
-	result := f.DB.Where("id = ?", id).First(&farm)
+	result := f.DB.WithContext(ctx).Where("id = ?", id).First(&farm)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -54,9 +57,9 @@ func (f *FarmRepo) GetByID(id int) (*Farm, error) {
 }
 
 // GetByFarmID retrieves a farm by its FarmID (UUID)
-func (f *FarmRepo) GetByFarmID(farmID string) (*Farm, error) {
+func (f *FarmRepo) GetByFarmID(ctx context.Context, farmID string) (*Farm, error) {
 	var farm Farm
-	result := f.DB.Where("farm_id = ?", farmID).First(&farm)
+	result := f.DB.WithContext(ctx).Where("farm_id = ?", farmID).First(&farm)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -64,23 +67,46 @@ func (f *FarmRepo) GetByFarmID(farmID string) (*Farm, error) {
 }
 
 // GetByUserID retrieves all farms belonging to a specific user
-func (f *FarmRepo) GetByUserID(userID string) ([]*Farm, error) {
+func (f *FarmRepo) GetByUserID(ctx context.Context, userID string) ([]*Farm, error) {
 	var farms []*Farm
-	result := f.DB.Where("user_id = ?", userID).Find(&farms)
+	result := f.DB.WithContext(ctx).Where("user_id = ?", userID).Find(&farms)
 	return farms, result.Error
 }
 
 // Insert creates a new farm in the database
-func (f *FarmRepo) Insert(farm *Farm) error {
-	return f.DB.Create(farm).Error
+func (f *FarmRepo) Insert(ctx context.Context, farm *Farm) error {
+	return f.DB.WithContext(ctx).Create(farm).Error
 }
 
-// Update updates an existing farm in the database
-func (f *FarmRepo) Update(farm *Farm) error {
-	return f.DB.Save(farm).Error
+// Update updates an existing farm in the database, moving it to its next
+// version. It returns ErrStale if the farm was updated since it was loaded.
+func (f *FarmRepo) Update(ctx context.Context, farm *Farm) error {
+	return updateVersioned(f.DB.WithContext(ctx), farm, &farm.Version)
 }
 
 // DeleteByID soft deletes a farm by its ID
-func (f *FarmRepo) DeleteByID(id int) error {
-	return f.DB.Delete(&Farm{}, id).Error
+func (f *FarmRepo) DeleteByID(ctx context.Context, id int) error {
+	return f.DB.WithContext(ctx).Delete(&Farm{}, id).Error
+}
+
+// GetDeletedByUserID retrieves soft-deleted farms belonging to a specific user
+func (f *FarmRepo) GetDeletedByUserID(ctx context.Context, userID string) ([]*Farm, error) {
+	var farms []*Farm
+	result := f.DB.WithContext(ctx).Unscoped().Where("user_id = ? AND deleted_at IS NOT NULL", userID).Order("deleted_at desc").Find(&farms)
+	return farms, result.Error
+}
+
+// GetDeletedByFarmID retrieves a soft-deleted farm by its FarmID (UUID)
+func (f *FarmRepo) GetDeletedByFarmID(ctx context.Context, farmID string) (*Farm, error) {
+	var farm Farm
+	result := f.DB.WithContext(ctx).Unscoped().Where("farm_id = ? AND deleted_at IS NOT NULL", farmID).First(&farm)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &farm, result.Error
+}
+
+// RestoreByID restores a soft-deleted farm by its ID
+func (f *FarmRepo) RestoreByID(ctx context.Context, id int) error {
+	return f.DB.WithContext(ctx).Unscoped().Model(&Farm{}).Where("id = ?", id).Update("deleted_at", nil).Error
 }
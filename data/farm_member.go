@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FarmMember represents the farm_members table in the database: a user the
+// farm's owner has given a role on the farm, such as an accountant. What a
+// role may see is decided by the farm service's permissions matrix.
+type FarmMember struct {
+	ID           uint           `gorm:"primaryKey" json:"-"`
+	FarmMemberID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"memberId"`
+	FarmID       string         `gorm:"not null;size:36;uniqueIndex:idx_farm_member,where:deleted_at IS NULL" json:"farmId"` // Foreign key to Farm
+	UserID       string         `gorm:"not null;size:36;uniqueIndex:idx_farm_member,where:deleted_at IS NULL;index" json:"userId"`
+	Role         string         `gorm:"not null" json:"role"`            // Accountant
+	AddedBy      string         `gorm:"not null;size:36" json:"addedBy"` // Owner who gave the access
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	User *User `gorm:"foreignKey:UserID;references:UserID" json:"user,omitempty"`
+}
+
+// FarmMemberInterface defines the contract for farm member operations
+type FarmMemberInterface interface {
+	GetByFarmMemberID(ctx context.Context, farmMemberID string) (*FarmMember, error)
+	// GetByFarmAndUser returns the user's membership of a farm, or nil
+	GetByFarmAndUser(ctx context.Context, farmID, userID string) (*FarmMember, error)
+	// GetByFarmID returns a farm's members with their users
+	GetByFarmID(ctx context.Context, farmID string) ([]*FarmMember, error)
+	// GetByUserID returns the farms a user is a member of, with the farms
+	GetByUserID(ctx context.Context, userID string) ([]*FarmMember, error)
+	Insert(ctx context.Context, member *FarmMember) error
+	Update(ctx context.Context, member *FarmMember) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// FarmMemberRepo implements FarmMemberInterface using GORM.
+type FarmMemberRepo struct {
+	DB *gorm.DB
+}
+
+// NewFarmMemberRepo creates a new instance of FarmMemberRepo.
+func NewFarmMemberRepo(db *gorm.DB) FarmMemberInterface {
+	return &FarmMemberRepo{DB: db}
+}
+
+// GetByFarmMemberID retrieves a membership by its FarmMemberID (UUID)
+func (m *FarmMemberRepo) GetByFarmMemberID(ctx context.Context, farmMemberID string) (*FarmMember, error) {
+	var member FarmMember
+	result := m.DB.WithContext(ctx).Preload("User").Where("farm_member_id = ?", farmMemberID).First(&member)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &member, result.Error
+}
+
+// GetByFarmAndUser retrieves a user's membership of a farm
+func (m *FarmMemberRepo) GetByFarmAndUser(ctx context.Context, farmID, userID string) (*FarmMember, error) {
+	var member FarmMember
+	result := m.DB.WithContext(ctx).Where("farm_id = ? AND user_id = ?", farmID, userID).First(&member)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &member, result.Error
+}
+
+// GetByFarmID retrieves a farm's members with their users, oldest first
+func (m *FarmMemberRepo) GetByFarmID(ctx context.Context, farmID string) ([]*FarmMember, error) {
+	var members []*FarmMember
+	result := m.DB.WithContext(ctx).Preload("User").Where("farm_id = ?", farmID).Order("created_at").Find(&members)
+	return members, result.Error
+}
+
+// GetByUserID retrieves a user's memberships with their farms, skipping
+// farms that have been deleted
+func (m *FarmMemberRepo) GetByUserID(ctx context.Context, userID string) ([]*FarmMember, error) {
+	var members []*FarmMember
+	result := m.DB.WithContext(ctx).InnerJoins("Farm").Where("farm_members.user_id = ?", userID).
+		Order("farm_members.created_at").Find(&members)
+	return members, result.Error
+}
+
+// Insert creates a new membership
+func (m *FarmMemberRepo) Insert(ctx context.Context, member *FarmMember) error {
+	return m.DB.WithContext(ctx).Omit("Farm", "User").Create(member).Error
+}
+
+// Update saves a membership
+func (m *FarmMemberRepo) Update(ctx context.Context, member *FarmMember) error {
+	return m.DB.WithContext(ctx).Omit("Farm", "User").Save(member).Error
+}
+
+// DeleteByID soft deletes a membership by its ID
+func (m *FarmMemberRepo) DeleteByID(ctx context.Context, id int) error {
+	return m.DB.WithContext(ctx).Delete(&FarmMember{}, id).Error
+}
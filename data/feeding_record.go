@@ -0,0 +1,87 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FeedingRecord represents the feeding_records table in the database: feed
+// from the farm's inventory given to a herd or flock. Recording a feeding
+// takes the feed out of stock, first-expired-first-out, and the cost is what
+// the batches drawn on were bought at.
+type FeedingRecord struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	FeedingRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"feedingRecordId"`
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"`          // Foreign key to Farm
+	LivestockID     string         `gorm:"not null;size:36;index" json:"livestockId"`     // Herd or flock fed
+	InventoryItemID string         `gorm:"not null;size:36;index" json:"inventoryItemId"` // Feed given
+	Date            time.Time      `gorm:"not null;index" json:"date"`
+	Quantity        float64        `gorm:"not null" json:"quantity"` // In the item's unit
+	Cost            float64        `gorm:"not null;default:0" json:"cost"`
+	HeadCount       int            `gorm:"not null;default:0" json:"headCount"` // Animals in the herd when fed
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Livestock     *Livestock     `gorm:"foreignKey:LivestockID;references:LivestockID" json:"livestock,omitempty"`
+	InventoryItem *InventoryItem `gorm:"foreignKey:InventoryItemID;references:InventoryItemID" json:"inventoryItem,omitempty"`
+}
+
+// FeedingRecordInterface defines the contract for feeding record operations
+type FeedingRecordInterface interface {
+	GetByFeedingRecordID(ctx context.Context, feedingRecordID string) (*FeedingRecord, error)
+	// GetByFarmID returns a farm's feedings with their feed items, optionally
+	// only those of a herd and dated in [from, to), most recent first
+	GetByFarmID(ctx context.Context, farmID, livestockID string, from, to *time.Time) ([]*FeedingRecord, error)
+	Insert(ctx context.Context, record *FeedingRecord) error
+}
+
+// FeedingRecordRepo implements FeedingRecordInterface using GORM.
+type FeedingRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewFeedingRecordRepo creates a new instance of FeedingRecordRepo.
+func NewFeedingRecordRepo(db *gorm.DB) FeedingRecordInterface {
+	return &FeedingRecordRepo{DB: db}
+}
+
+// GetByFeedingRecordID retrieves a feeding with its herd and feed item by its
+// FeedingRecordID (UUID)
+func (f *FeedingRecordRepo) GetByFeedingRecordID(ctx context.Context, feedingRecordID string) (*FeedingRecord, error) {
+	var record FeedingRecord
+	result := f.DB.WithContext(ctx).Preload("Livestock").Preload("InventoryItem").
+		Where("feeding_record_id = ?", feedingRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's feedings with their feed items, most recent
+// first
+func (f *FeedingRecordRepo) GetByFarmID(ctx context.Context, farmID, livestockID string, from, to *time.Time) ([]*FeedingRecord, error) {
+	var records []*FeedingRecord
+	query := f.DB.WithContext(ctx).Preload("InventoryItem").Where("farm_id = ?", farmID)
+	if livestockID != "" {
+		query = query.Where("livestock_id = ?", livestockID)
+	}
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc, id desc").Find(&records)
+	return records, result.Error
+}
+
+// Insert adds a new feeding
+func (f *FeedingRecordRepo) Insert(ctx context.Context, record *FeedingRecord) error {
+	return f.DB.WithContext(ctx).Omit("Livestock", "InventoryItem").Create(record).Error
+}
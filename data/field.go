@@ -0,0 +1,80 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Field represents the fields table in the database. A field is a plot within
+// a farm; crops planted on it over the seasons make up its rotation history.
+type Field struct {
+	ID        uint           `gorm:"primaryKey" json:"-"`
+	FieldID   string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"fieldId"`
+	FarmID    string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name      string         `gorm:"not null" json:"name"`
+	Area      float64        `gorm:"not null" json:"area"` // Same unit as the farm size
+	SoilType  string         `json:"soilType"`             // Clay, Sandy, Loam, Silt, Peat, Chalk, Other
+	Notes     string         `json:"notes"`
+	Version   int            `gorm:"not null;default:1" json:"version"` // Incremented by every update; send it back to update
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// FieldInterface defines the contract for field operations
+type FieldInterface interface {
+	GetByFieldID(ctx context.Context, fieldID string) (*Field, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Field, error)
+	Insert(ctx context.Context, field *Field) error
+	Update(ctx context.Context, field *Field) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// FieldRepo implements FieldInterface using GORM.
+type FieldRepo struct {
+	DB *gorm.DB
+}
+
+// NewFieldRepo creates a new instance of FieldRepo.
+func NewFieldRepo(db *gorm.DB) FieldInterface {
+	return &FieldRepo{DB: db}
+}
+
+// GetByFieldID retrieves a field by its FieldID (UUID)
+func (f *FieldRepo) GetByFieldID(ctx context.Context, fieldID string) (*Field, error) {
+	var field Field
+	result := f.DB.WithContext(ctx).Where("field_id = ?", fieldID).First(&field)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &field, result.Error
+}
+
+// GetByFarmID retrieves a farm's fields ordered by name
+func (f *FieldRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Field, error) {
+	var fields []*Field
+	result := f.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("name").Find(&fields)
+	return fields, result.Error
+}
+
+// Insert creates a new field in the database
+func (f *FieldRepo) Insert(ctx context.Context, field *Field) error {
+	return f.DB.WithContext(ctx).Create(field).Error
+}
+
+// Update updates an existing field in the database, moving it to its next
+// version. It returns ErrStale if the field was updated since it was loaded.
+func (f *FieldRepo) Update(ctx context.Context, field *Field) error {
+	return updateVersioned(f.DB.WithContext(ctx), field, &field.Version)
+}
+
+// DeleteByID soft deletes a field by its ID
+func (f *FieldRepo) DeleteByID(ctx context.Context, id int) error {
+	return f.DB.WithContext(ctx).Delete(&Field{}, id).Error
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GrazingMove represents the grazing_moves table in the database: a herd's
+// stay on a paddock. A move made by the rotation planner starts out Planned
+// with planned dates; recording the herd moving in and out makes it Grazing
+// and then Completed. A move recorded without a plan has no planned dates.
+type GrazingMove struct {
+	ID            uint           `gorm:"primaryKey" json:"-"`
+	GrazingMoveID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"moveId"`
+	FarmID        string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	LivestockID   string         `gorm:"not null;size:36;index" json:"livestockId"` // Herd being moved
+	PaddockID     string         `gorm:"not null;size:36;index" json:"paddockId"`
+	PlannedStart  *time.Time     `json:"plannedStart,omitempty"`
+	PlannedEnd    *time.Time     `json:"plannedEnd,omitempty"` // Day the herd is due off, exclusive
+	MovedInAt     *time.Time     `json:"movedInAt,omitempty"`
+	MovedOutAt    *time.Time     `json:"movedOutAt,omitempty"`
+	Status        string         `gorm:"not null;default:'Planned'" json:"status"` // Planned, Grazing, Completed, Cancelled
+	Notes         string         `json:"notes"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Paddock   *Paddock   `gorm:"foreignKey:PaddockID;references:PaddockID" json:"paddock,omitempty"`
+	Livestock *Livestock `gorm:"foreignKey:LivestockID;references:LivestockID" json:"livestock,omitempty"`
+}
+
+// Start returns when the herd moved, or is planned to move, onto the paddock
+func (g *GrazingMove) Start() *time.Time {
+	if g.MovedInAt != nil {
+		return g.MovedInAt
+	}
+	return g.PlannedStart
+}
+
+// End returns when the herd moved, or is planned to move, off the paddock.
+// It is nil while a herd without a planned end is still grazing.
+func (g *GrazingMove) End() *time.Time {
+	if g.MovedOutAt != nil {
+		return g.MovedOutAt
+	}
+	return g.PlannedEnd
+}
+
+// GrazingMoveInterface defines the contract for grazing move operations
+type GrazingMoveInterface interface {
+	GetByGrazingMoveID(ctx context.Context, grazingMoveID string) (*GrazingMove, error)
+	// GetByFarmID returns a farm's moves, optionally only those on a paddock,
+	// of a herd or with a status
+	GetByFarmID(ctx context.Context, farmID, paddockID, livestockID, status string) ([]*GrazingMove, error)
+	// InsertMany creates moves in a single transaction
+	InsertMany(ctx context.Context, moves []*GrazingMove) error
+	Update(ctx context.Context, move *GrazingMove) error
+}
+
+// GrazingMoveRepo implements GrazingMoveInterface using GORM.
+type GrazingMoveRepo struct {
+	DB *gorm.DB
+}
+
+// NewGrazingMoveRepo creates a new instance of GrazingMoveRepo.
+func NewGrazingMoveRepo(db *gorm.DB) GrazingMoveInterface {
+	return &GrazingMoveRepo{DB: db}
+}
+
+// GetByGrazingMoveID retrieves a move with its paddock by its GrazingMoveID
+// (UUID)
+func (g *GrazingMoveRepo) GetByGrazingMoveID(ctx context.Context, grazingMoveID string) (*GrazingMove, error) {
+	var move GrazingMove
+	result := g.DB.WithContext(ctx).Preload("Paddock").Where("grazing_move_id = ?", grazingMoveID).First(&move)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &move, result.Error
+}
+
+// GetByFarmID retrieves a farm's moves with their paddocks, in the order the
+// herds move
+func (g *GrazingMoveRepo) GetByFarmID(ctx context.Context, farmID, paddockID, livestockID, status string) ([]*GrazingMove, error) {
+	var moves []*GrazingMove
+	query := g.DB.WithContext(ctx).Preload("Paddock").Where("farm_id = ?", farmID)
+	if paddockID != "" {
+		query = query.Where("paddock_id = ?", paddockID)
+	}
+	if livestockID != "" {
+		query = query.Where("livestock_id = ?", livestockID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("COALESCE(moved_in_at, planned_start), id").Find(&moves)
+	return moves, result.Error
+}
+
+// InsertMany creates moves in a single transaction
+func (g *GrazingMoveRepo) InsertMany(ctx context.Context, moves []*GrazingMove) error {
+	if len(moves) == 0 {
+		return nil
+	}
+	return g.DB.WithContext(ctx).Omit("Paddock", "Livestock").Create(&moves).Error
+}
+
+// Update saves a move
+func (g *GrazingMoveRepo) Update(ctx context.Context, move *GrazingMove) error {
+	return g.DB.WithContext(ctx).Omit("Paddock", "Livestock").Save(move).Error
+}
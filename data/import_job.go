@@ -0,0 +1,141 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImportJob represents the import_jobs table in the database: a spreadsheet
+// of records kept in another app, taken through the mapping wizard before
+// its rows are added to a farm. The file itself lives in object storage
+// under FileKey.
+type ImportJob struct {
+	ID            uint              `gorm:"primaryKey" json:"-"`
+	ImportJobID   string            `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"importId"`
+	FarmID        string            `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	UserID        string            `gorm:"not null;size:36" json:"userId"`       // User who uploaded the file
+	Target        string            `gorm:"not null" json:"target"`               // crops, livestock, transactions, employees
+	Source        string            `gorm:"not null" json:"source"`               // Layout the file came from, e.g. generic or quickbooks
+	FileName      string            `json:"fileName"`
+	Delimiter     string            `gorm:"size:1;not null" json:"delimiter"`
+	DateFormat    string            `gorm:"not null" json:"dateFormat"` // YYYY-MM-DD, DD/MM/YYYY or MM/DD/YYYY
+	Headers       []string          `gorm:"serializer:json" json:"headers"`
+	Mapping       map[string]string `gorm:"serializer:json" json:"mapping"` // Record field to column header
+	RowCount      int               `json:"rowCount"`
+	ValidRows     int               `json:"validRows"`
+	ErrorCount    int               `json:"errorCount"`
+	Errors        []ImportRowError  `gorm:"serializer:json" json:"errors"` // The first errors found by validation
+	ImportedCount int               `json:"importedCount"`
+	Status        string            `gorm:"not null;default:'Pending'" json:"status"` // Pending, Validated, Committed
+	CommittedAt   *time.Time        `json:"committedAt,omitempty"`
+	CreatedAt     time.Time         `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time         `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt    `gorm:"index" json:"-"`
+
+	// Sample holds the first rows of the file; filled in by the import service
+	Sample [][]string `gorm:"-" json:"sample,omitempty"`
+}
+
+// FileKey is the storage key of the uploaded file
+func (j *ImportJob) FileKey() string {
+	return fmt.Sprintf("farms/%s/imports/%s.csv", j.FarmID, j.ImportJobID)
+}
+
+// ImportRowError is a problem with one row of an import. Row counts from 2,
+// the first line after the header, so it matches the spreadsheet.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportJobInterface defines the contract for import job operations
+type ImportJobInterface interface {
+	GetByImportJobID(ctx context.Context, importJobID string) (*ImportJob, error)
+	// GetByFarmID returns a farm's import jobs, newest first
+	GetByFarmID(ctx context.Context, farmID string) ([]*ImportJob, error)
+	Insert(ctx context.Context, job *ImportJob) error
+	Update(ctx context.Context, job *ImportJob) error
+	// Commit marks a Validated job Committed and creates records, a slice
+	// of models, in the same transaction, reporting whether the job was
+	// still Validated
+	Commit(ctx context.Context, job *ImportJob, records any) (bool, error)
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ImportJobRepo implements ImportJobInterface using GORM.
+type ImportJobRepo struct {
+	DB *gorm.DB
+}
+
+// NewImportJobRepo creates a new instance of ImportJobRepo.
+func NewImportJobRepo(db *gorm.DB) ImportJobInterface {
+	return &ImportJobRepo{DB: db}
+}
+
+// GetByImportJobID retrieves an import job by its ImportJobID (UUID)
+func (i *ImportJobRepo) GetByImportJobID(ctx context.Context, importJobID string) (*ImportJob, error) {
+	var job ImportJob
+	result := i.DB.WithContext(ctx).Where("import_job_id = ?", importJobID).First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, result.Error
+}
+
+// GetByFarmID retrieves a farm's import jobs, newest first
+func (i *ImportJobRepo) GetByFarmID(ctx context.Context, farmID string) ([]*ImportJob, error) {
+	var jobs []*ImportJob
+	result := i.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("created_at desc").Find(&jobs)
+	return jobs, result.Error
+}
+
+// Insert creates a new import job
+func (i *ImportJobRepo) Insert(ctx context.Context, job *ImportJob) error {
+	return i.DB.WithContext(ctx).Create(job).Error
+}
+
+// Update saves an import job
+func (i *ImportJobRepo) Update(ctx context.Context, job *ImportJob) error {
+	return i.DB.WithContext(ctx).Save(job).Error
+}
+
+// Commit marks a Validated job Committed and, in the same transaction,
+// creates the imported records so a file is never half imported
+func (i *ImportJobRepo) Commit(ctx context.Context, job *ImportJob, records any) (bool, error) {
+	committed := false
+	err := i.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ImportJob{}).
+			Where("import_job_id = ? AND status = ?", job.ImportJobID, "Validated").
+			Updates(map[string]any{
+				"status":         "Committed",
+				"valid_rows":     job.ValidRows,
+				"error_count":    job.ErrorCount,
+				"imported_count": job.ImportedCount,
+				"committed_at":   job.CommittedAt,
+			})
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		committed = true
+
+		if job.ImportedCount == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(records, 200).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return committed, nil
+}
+
+// DeleteByID soft deletes an import job by its ID
+func (i *ImportJobRepo) DeleteByID(ctx context.Context, id int) error {
+	return i.DB.WithContext(ctx).Delete(&ImportJob{}, id).Error
+}
@@ -1,25 +1,37 @@
 package data
 
+import "context"
+
 type UserInterface interface {
-	GetAll() ([]*User, error)
-	GetByEmail(email string) (*User, error)
-	GetOne(id int) (*User, error)
-	Update(user *User) error
-	Insert(user *User) error
-	ResetPassword(password string, user User) error
-	DeleteByID(id int) error
-	PasswordMatches(user *User, plainText string) (bool, error)
-	GenerateAndSaveOTP(email string) (string, error)
-	VerifyOTP(email, otp string) (bool, error)
-	ResetPasswordWithOTP(email, otp, newPassword string) error
+	GetAll(ctx context.Context) ([]*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetOne(ctx context.Context, id int) (*User, error)
+	Update(ctx context.Context, user *User) error
+	Insert(ctx context.Context, user *User) error
+	ResetPassword(ctx context.Context, password string, user User) error
+	DeleteByID(ctx context.Context, id int) error
+	PasswordMatches(ctx context.Context, user *User, plainText string) (bool, error)
+	GenerateAndSaveOTP(ctx context.Context, email string) (string, error)
+	VerifyOTP(ctx context.Context, email, otp string) (bool, error)
+	ResetPasswordWithOTP(ctx context.Context, email, otp, newPassword string) error
+	GetByUserID(ctx context.Context, userID string) (*User, error)
+	// GetByPhoneNumber returns the users whose phone number is phoneNumber,
+	// a string of digits with an optional leading +, ignoring the spaces and
+	// punctuation the stored numbers are formatted with
+	GetByPhoneNumber(ctx context.Context, phoneNumber string) ([]*User, error)
+	Search(ctx context.Context, filter UserFilter) ([]*User, int64, error)
+	CountByRole(ctx context.Context) (map[string]int64, error)
 }
 
 type FarmInterface interface {
-	GetAll() ([]*Farm, error)
-	GetByID(id int) (*Farm, error)
-	GetByUserID(userID string) ([]*Farm, error)
-	Insert(farm *Farm) error
-	Update(farm *Farm) error
-	DeleteByID(id int) error
-	GetByFarmID(farmID string) (*Farm, error)
+	GetAll(ctx context.Context) ([]*Farm, error)
+	GetByID(ctx context.Context, id int) (*Farm, error)
+	GetByUserID(ctx context.Context, userID string) ([]*Farm, error)
+	Insert(ctx context.Context, farm *Farm) error
+	Update(ctx context.Context, farm *Farm) error
+	DeleteByID(ctx context.Context, id int) error
+	GetByFarmID(ctx context.Context, farmID string) (*Farm, error)
+	GetDeletedByUserID(ctx context.Context, userID string) ([]*Farm, error)
+	GetDeletedByFarmID(ctx context.Context, farmID string) (*Farm, error)
+	RestoreByID(ctx context.Context, id int) error
 }
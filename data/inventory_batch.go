@@ -0,0 +1,252 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// fefoOrder sorts batches first-expired-first-out, with batches that never
+// expire last and older receipts first among equals
+const fefoOrder = "expiry_date ASC NULLS LAST, received_date ASC, id ASC"
+
+// InventoryBatch represents the inventory_batches table in the database.
+type InventoryBatch struct {
+	ID               uint           `gorm:"primaryKey" json:"-"`
+	InventoryBatchID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"inventoryBatchId"`
+	InventoryItemID  string         `gorm:"not null;size:36;index" json:"inventoryItemId"` // Foreign key to InventoryItem
+	FarmID           string         `gorm:"not null;size:36" json:"farmId"`                // Foreign key to Farm
+	BatchNumber      string         `json:"batchNumber"`
+	Quantity         float64        `gorm:"not null" json:"quantity"`        // Quantity remaining
+	InitialQuantity  float64        `gorm:"not null" json:"initialQuantity"` // Quantity received
+	UnitCost         float64        `json:"unitCost"`
+	ReceivedDate     time.Time      `gorm:"not null" json:"receivedDate"`
+	ExpiryDate       *time.Time     `gorm:"index" json:"expiryDate"`
+	Notes            string         `json:"notes"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	InventoryItem *InventoryItem `gorm:"foreignKey:InventoryItemID;references:InventoryItemID" json:"inventoryItem,omitempty"`
+}
+
+// IsExpired reports whether the batch has passed its expiry date at the given time
+func (b *InventoryBatch) IsExpired(at time.Time) bool {
+	return b.ExpiryDate != nil && !b.ExpiryDate.After(at)
+}
+
+// BatchAllocation is the quantity to take from one batch to satisfy a request
+type BatchAllocation struct {
+	InventoryBatchID string     `json:"inventoryBatchId"`
+	BatchNumber      string     `json:"batchNumber"`
+	ExpiryDate       *time.Time `json:"expiryDate"`
+	Quantity         float64    `json:"quantity"`
+}
+
+// AllocateFEFO spreads quantity over batches (already in FEFO order), skipping
+// empty batches and those expired at the given time. It returns the
+// allocations and any quantity that could not be covered.
+func AllocateFEFO(batches []*InventoryBatch, quantity float64, at time.Time) ([]BatchAllocation, float64) {
+	var allocations []BatchAllocation
+	remaining := quantity
+
+	for _, b := range batches {
+		if remaining <= 0 {
+			break
+		}
+		if b.Quantity <= 0 || b.IsExpired(at) {
+			continue
+		}
+
+		take := b.Quantity
+		if take > remaining {
+			take = remaining
+		}
+
+		allocations = append(allocations, BatchAllocation{
+			InventoryBatchID: b.InventoryBatchID,
+			BatchNumber:      b.BatchNumber,
+			ExpiryDate:       b.ExpiryDate,
+			Quantity:         take,
+		})
+		remaining -= take
+	}
+
+	return allocations, remaining
+}
+
+// InventoryBatchInterface defines the contract for inventory batch operations
+type InventoryBatchInterface interface {
+	GetByInventoryBatchID(ctx context.Context, inventoryBatchID string) (*InventoryBatch, error)
+	GetByInventoryItemID(ctx context.Context, inventoryItemID string) ([]*InventoryBatch, error)
+	GetAvailable(ctx context.Context, inventoryItemID string) ([]*InventoryBatch, error)
+	GetExpiring(ctx context.Context, farmID string, before time.Time) ([]*InventoryBatch, error)
+	QuantityByItem(ctx context.Context, farmID string) (map[string]float64, error)
+	Receive(ctx context.Context, batch *InventoryBatch) error
+	Consume(ctx context.Context, inventoryItemID, inventoryBatchID string, quantity float64, at time.Time, purpose, notes string) ([]*InventoryMovement, error)
+	Update(ctx context.Context, batch *InventoryBatch) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// InventoryBatchRepo implements InventoryBatchInterface using GORM.
+type InventoryBatchRepo struct {
+	DB *gorm.DB
+}
+
+// NewInventoryBatchRepo creates a new instance of InventoryBatchRepo.
+func NewInventoryBatchRepo(db *gorm.DB) InventoryBatchInterface {
+	return &InventoryBatchRepo{DB: db}
+}
+
+// GetByInventoryBatchID retrieves a batch by its InventoryBatchID (UUID)
+func (b *InventoryBatchRepo) GetByInventoryBatchID(ctx context.Context, inventoryBatchID string) (*InventoryBatch, error) {
+	var batch InventoryBatch
+	result := b.DB.WithContext(ctx).Where("inventory_batch_id = ?", inventoryBatchID).First(&batch)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &batch, result.Error
+}
+
+// GetByInventoryItemID retrieves all batches of an item in FEFO order
+func (b *InventoryBatchRepo) GetByInventoryItemID(ctx context.Context, inventoryItemID string) ([]*InventoryBatch, error) {
+	var batches []*InventoryBatch
+	result := b.DB.WithContext(ctx).Where("inventory_item_id = ?", inventoryItemID).Order(fefoOrder).Find(&batches)
+	return batches, result.Error
+}
+
+// GetAvailable retrieves the batches of an item that still hold stock, in FEFO order
+func (b *InventoryBatchRepo) GetAvailable(ctx context.Context, inventoryItemID string) ([]*InventoryBatch, error) {
+	var batches []*InventoryBatch
+	result := b.DB.WithContext(ctx).Where("inventory_item_id = ? AND quantity > 0", inventoryItemID).Order(fefoOrder).Find(&batches)
+	return batches, result.Error
+}
+
+// GetExpiring retrieves a farm's batches that still hold stock and expire
+// before the given time, including those already expired
+func (b *InventoryBatchRepo) GetExpiring(ctx context.Context, farmID string, before time.Time) ([]*InventoryBatch, error) {
+	var batches []*InventoryBatch
+	result := b.DB.WithContext(ctx).Preload("InventoryItem").
+		Where("farm_id = ? AND quantity > 0 AND expiry_date IS NOT NULL AND expiry_date < ?", farmID, before).
+		Order(fefoOrder).
+		Find(&batches)
+	return batches, result.Error
+}
+
+// QuantityByItem sums the remaining stock of every item on a farm
+func (b *InventoryBatchRepo) QuantityByItem(ctx context.Context, farmID string) (map[string]float64, error) {
+	var rows []struct {
+		InventoryItemID string
+		Total           float64
+	}
+	result := b.DB.WithContext(ctx).Model(&InventoryBatch{}).
+		Select("inventory_item_id, COALESCE(SUM(quantity), 0) AS total").
+		Where("farm_id = ?", farmID).
+		Group("inventory_item_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	totals := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		totals[row.InventoryItemID] = row.Total
+	}
+	return totals, nil
+}
+
+// Receive stores a new batch and records the receipt movement in a single transaction
+func (b *InventoryBatchRepo) Receive(ctx context.Context, batch *InventoryBatch) error {
+	return b.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&InventoryMovement{
+			InventoryItemID:  batch.InventoryItemID,
+			InventoryBatchID: batch.InventoryBatchID,
+			FarmID:           batch.FarmID,
+			Type:             "Receipt",
+			Quantity:         batch.InitialQuantity,
+			Date:             batch.ReceivedDate,
+			Notes:            batch.Notes,
+		}).Error
+	})
+}
+
+// Consume takes quantity of an item out of stock in a single transaction. When
+// inventoryBatchID is empty the quantity is allocated first-expired-first-out
+// across unexpired batches; otherwise it is taken from that batch only. It
+// returns ErrInsufficientStock if the stock cannot cover the quantity.
+func (b *InventoryBatchRepo) Consume(ctx context.Context, inventoryItemID, inventoryBatchID string, quantity float64, at time.Time, purpose, notes string) ([]*InventoryMovement, error) {
+	var movements []*InventoryMovement
+
+	err := b.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("inventory_item_id = ? AND quantity > 0", inventoryItemID)
+		if inventoryBatchID != "" {
+			query = query.Where("inventory_batch_id = ?", inventoryBatchID)
+		}
+
+		var batches []*InventoryBatch
+		if err := query.Order(fefoOrder).Find(&batches).Error; err != nil {
+			return err
+		}
+
+		var allocations []BatchAllocation
+		var shortfall float64
+		if inventoryBatchID != "" {
+			// An explicitly chosen batch may be used even if expired (e.g. disposal)
+			if len(batches) == 0 || batches[0].Quantity < quantity {
+				return ErrInsufficientStock
+			}
+			allocations = []BatchAllocation{{InventoryBatchID: inventoryBatchID, Quantity: quantity}}
+		} else {
+			allocations, shortfall = AllocateFEFO(batches, quantity, at)
+			if shortfall > 0 {
+				return ErrInsufficientStock
+			}
+		}
+
+		for _, a := range allocations {
+			if err := tx.Model(&InventoryBatch{}).
+				Where("inventory_batch_id = ?", a.InventoryBatchID).
+				Update("quantity", gorm.Expr("quantity - ?", a.Quantity)).Error; err != nil {
+				return err
+			}
+
+			movement := &InventoryMovement{
+				InventoryItemID:  inventoryItemID,
+				InventoryBatchID: a.InventoryBatchID,
+				FarmID:           batches[0].FarmID,
+				Type:             "Consumption",
+				Quantity:         a.Quantity,
+				Date:             at,
+				Purpose:          purpose,
+				Notes:            notes,
+			}
+			if err := tx.Create(movement).Error; err != nil {
+				return err
+			}
+			movements = append(movements, movement)
+		}
+
+		return nil
+	})
+
+	return movements, err
+}
+
+// Update updates an existing batch in the database
+func (b *InventoryBatchRepo) Update(ctx context.Context, batch *InventoryBatch) error {
+	return b.DB.WithContext(ctx).Save(batch).Error
+}
+
+// DeleteByID soft deletes a batch by its ID
+func (b *InventoryBatchRepo) DeleteByID(ctx context.Context, id int) error {
+	return b.DB.WithContext(ctx).Delete(&InventoryBatch{}, id).Error
+}
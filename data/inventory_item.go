@@ -0,0 +1,155 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryItem represents the inventory_items table in the database.
+// Stock is held in InventoryBatch rows so that expiry can be tracked per batch.
+type InventoryItem struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	InventoryItemID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"inventoryItemId"`
+	FarmID          string         `gorm:"not null;size:36" json:"farmId"` // Foreign key to Farm
+	Name            string         `gorm:"not null" json:"name"`
+	Category        string         `gorm:"not null" json:"category"` // Feed, Seed, Fertilizer, Drug, Fuel, Other
+	Unit            string         `gorm:"not null" json:"unit"`     // kg, L, bags, doses, etc.
+	NitrogenPercent float64        `json:"nitrogenPercent"`          // N content of fertilizers, e.g. 46 for urea
+	ReorderLevel    float64        `json:"reorderLevel"`             // Stock below which the item is reported low; 0 turns the alert off
+	Notes           string         `json:"notes"`
+	QuantityOnHand  float64        `gorm:"-" json:"quantityOnHand"`        // Sum of batch quantities, filled in by handlers
+	RestockedAt     *time.Time     `gorm:"-" json:"restockedAt,omitempty"` // When a batch was last received, filled in by GetLowStock
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm    *Farm            `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Batches []InventoryBatch `gorm:"foreignKey:InventoryItemID;references:InventoryItemID" json:"batches,omitempty"`
+}
+
+// InventoryItemInterface defines the contract for inventory item operations
+type InventoryItemInterface interface {
+	GetAll(ctx context.Context) ([]*InventoryItem, error)
+	GetByID(ctx context.Context, id int) (*InventoryItem, error)
+	GetByInventoryItemID(ctx context.Context, inventoryItemID string) (*InventoryItem, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*InventoryItem, error)
+	GetByCategory(ctx context.Context, farmID, category string) ([]*InventoryItem, error)
+	// GetLowStock returns a farm's items whose stock is below their reorder
+	// level, with QuantityOnHand and RestockedAt filled in
+	GetLowStock(ctx context.Context, farmID string) ([]*InventoryItem, error)
+	Insert(ctx context.Context, item *InventoryItem) error
+	Update(ctx context.Context, item *InventoryItem) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// InventoryItemRepo implements InventoryItemInterface using GORM.
+type InventoryItemRepo struct {
+	DB *gorm.DB
+}
+
+// NewInventoryItemRepo creates a new instance of InventoryItemRepo.
+func NewInventoryItemRepo(db *gorm.DB) InventoryItemInterface {
+	return &InventoryItemRepo{DB: db}
+}
+
+// GetAll retrieves all inventory items from the database
+func (i *InventoryItemRepo) GetAll(ctx context.Context) ([]*InventoryItem, error) {
+	var items []*InventoryItem
+	result := i.DB.WithContext(ctx).Find(&items)
+	return items, result.Error
+}
+
+// GetByID retrieves an inventory item by its ID
+func (i *InventoryItemRepo) GetByID(ctx context.Context, id int) (*InventoryItem, error) {
+	var item InventoryItem
+	result := i.DB.WithContext(ctx).Where("id = ?", id).First(&item)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &item, result.Error
+}
+
+// GetByInventoryItemID retrieves an inventory item by its InventoryItemID (UUID)
+func (i *InventoryItemRepo) GetByInventoryItemID(ctx context.Context, inventoryItemID string) (*InventoryItem, error) {
+	var item InventoryItem
+	result := i.DB.WithContext(ctx).Where("inventory_item_id = ?", inventoryItemID).First(&item)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &item, result.Error
+}
+
+// GetByFarmID retrieves all inventory items belonging to a specific farm
+func (i *InventoryItemRepo) GetByFarmID(ctx context.Context, farmID string) ([]*InventoryItem, error) {
+	var items []*InventoryItem
+	result := i.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("name").Find(&items)
+	return items, result.Error
+}
+
+// GetByCategory retrieves a farm's inventory items in a specific category
+func (i *InventoryItemRepo) GetByCategory(ctx context.Context, farmID, category string) ([]*InventoryItem, error) {
+	var items []*InventoryItem
+	result := i.DB.WithContext(ctx).Where("farm_id = ? AND category = ?", farmID, category).Order("name").Find(&items)
+	return items, result.Error
+}
+
+// GetLowStock retrieves a farm's items with a reorder level whose remaining
+// stock has fallen below it, ordered by name
+func (i *InventoryItemRepo) GetLowStock(ctx context.Context, farmID string) ([]*InventoryItem, error) {
+	var items []*InventoryItem
+	result := i.DB.WithContext(ctx).Where("farm_id = ? AND reorder_level > 0", farmID).Order("name").Find(&items)
+	if result.Error != nil || len(items) == 0 {
+		return nil, result.Error
+	}
+
+	var rows []struct {
+		InventoryItemID string
+		Total           float64
+		RestockedAt     *time.Time
+	}
+	result = i.DB.WithContext(ctx).Model(&InventoryBatch{}).
+		Select("inventory_item_id, COALESCE(SUM(quantity), 0) AS total, MAX(created_at) AS restocked_at").
+		Where("farm_id = ?", farmID).
+		Group("inventory_item_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	byID := make(map[string]*InventoryItem, len(items))
+	for _, item := range items {
+		byID[item.InventoryItemID] = item
+	}
+	for _, row := range rows {
+		if item, ok := byID[row.InventoryItemID]; ok {
+			item.QuantityOnHand = row.Total
+			item.RestockedAt = row.RestockedAt
+		}
+	}
+
+	low := []*InventoryItem{}
+	for _, item := range items {
+		if item.QuantityOnHand < item.ReorderLevel {
+			low = append(low, item)
+		}
+	}
+	return low, nil
+}
+
+// Insert creates a new inventory item in the database
+func (i *InventoryItemRepo) Insert(ctx context.Context, item *InventoryItem) error {
+	return i.DB.WithContext(ctx).Create(item).Error
+}
+
+// Update updates an existing inventory item in the database
+func (i *InventoryItemRepo) Update(ctx context.Context, item *InventoryItem) error {
+	return i.DB.WithContext(ctx).Save(item).Error
+}
+
+// DeleteByID soft deletes an inventory item by its ID
+func (i *InventoryItemRepo) DeleteByID(ctx context.Context, id int) error {
+	return i.DB.WithContext(ctx).Delete(&InventoryItem{}, id).Error
+}
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryMovement represents the inventory_movements table in the database.
+// Every change to a batch quantity is recorded as a movement.
+type InventoryMovement struct {
+	ID                  uint           `gorm:"primaryKey" json:"-"`
+	InventoryMovementID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"inventoryMovementId"`
+	InventoryItemID     string         `gorm:"not null;size:36;index" json:"inventoryItemId"`  // Foreign key to InventoryItem
+	InventoryBatchID    string         `gorm:"not null;size:36;index" json:"inventoryBatchId"` // Foreign key to InventoryBatch
+	FarmID              string         `gorm:"not null;size:36" json:"farmId"`                 // Foreign key to Farm
+	Type                string         `gorm:"not null" json:"type"`                           // Receipt, Consumption, Adjustment, Disposal
+	Quantity            float64        `gorm:"not null" json:"quantity"`                       // Always positive; Type gives the direction
+	Date                time.Time      `gorm:"not null" json:"date"`
+	Purpose             string         `json:"purpose"`
+	Notes               string         `json:"notes"`
+	CreatedAt           time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt           time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ItemConsumption is the total quantity of an inventory item consumed in a period
+type ItemConsumption struct {
+	InventoryItemID string  `json:"inventoryItemId"`
+	Name            string  `json:"name"`
+	Unit            string  `json:"unit"`
+	NitrogenPercent float64 `json:"nitrogenPercent"`
+	Quantity        float64 `json:"quantity"`
+}
+
+// InventoryMovementInterface defines the contract for inventory movement operations
+type InventoryMovementInterface interface {
+	GetByInventoryItemID(ctx context.Context, inventoryItemID string, from, to *time.Time) ([]*InventoryMovement, error)
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*InventoryMovement, error)
+	ConsumptionByCategory(ctx context.Context, farmID, category string, from, to time.Time) ([]ItemConsumption, error)
+	Insert(ctx context.Context, movement *InventoryMovement) error
+}
+
+// InventoryMovementRepo implements InventoryMovementInterface using GORM.
+type InventoryMovementRepo struct {
+	DB *gorm.DB
+}
+
+// NewInventoryMovementRepo creates a new instance of InventoryMovementRepo.
+func NewInventoryMovementRepo(db *gorm.DB) InventoryMovementInterface {
+	return &InventoryMovementRepo{DB: db}
+}
+
+// GetByInventoryItemID retrieves movements of an inventory item, optionally
+// limited to dates in [from, to)
+func (m *InventoryMovementRepo) GetByInventoryItemID(ctx context.Context, inventoryItemID string, from, to *time.Time) ([]*InventoryMovement, error) {
+	var movements []*InventoryMovement
+	query := m.DB.WithContext(ctx).Where("inventory_item_id = ?", inventoryItemID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc").Find(&movements)
+	return movements, result.Error
+}
+
+// GetByFarmID retrieves movements across a farm's inventory, optionally
+// limited to dates in [from, to)
+func (m *InventoryMovementRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*InventoryMovement, error) {
+	var movements []*InventoryMovement
+	query := m.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc").Find(&movements)
+	return movements, result.Error
+}
+
+// ConsumptionByCategory sums the consumption of each of a farm's items in a
+// category for dates in [from, to)
+func (m *InventoryMovementRepo) ConsumptionByCategory(ctx context.Context, farmID, category string, from, to time.Time) ([]ItemConsumption, error) {
+	var totals []ItemConsumption
+	result := m.DB.WithContext(ctx).Model(&InventoryMovement{}).
+		Select("inventory_items.inventory_item_id, inventory_items.name, inventory_items.unit, inventory_items.nitrogen_percent, SUM(inventory_movements.quantity) AS quantity").
+		Joins("JOIN inventory_items ON inventory_items.inventory_item_id = inventory_movements.inventory_item_id").
+		Where("inventory_movements.farm_id = ? AND inventory_items.category = ? AND inventory_movements.type = ?", farmID, category, "Consumption").
+		Where("inventory_movements.date >= ? AND inventory_movements.date < ?", from, to).
+		Group("inventory_items.inventory_item_id, inventory_items.name, inventory_items.unit, inventory_items.nitrogen_percent").
+		Order("inventory_items.name").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// Insert creates a new movement in the database
+func (m *InventoryMovementRepo) Insert(ctx context.Context, movement *InventoryMovement) error {
+	return m.DB.WithContext(ctx).Create(movement).Error
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IrrigationSchedule represents the irrigation_schedules table in the
+// database: a recurring irrigation of a field or crop, every IntervalDays from
+// StartDate until EndDate.
+type IrrigationSchedule struct {
+	ID                   uint           `gorm:"primaryKey" json:"-"`
+	IrrigationScheduleID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"irrigationId"`
+	FarmID               string         `gorm:"not null;size:36;index" json:"farmId"`    // Foreign key to Farm
+	FieldID              *string        `gorm:"size:36;index" json:"fieldId,omitempty"`  // Optional foreign key to Field
+	CropID               *string        `gorm:"size:36;index" json:"cropId,omitempty"`   // Optional foreign key to Crop
+	WaterSourceID        *string        `gorm:"size:36" json:"waterSourceId,omitempty"`  // Optional foreign key to WaterSource
+	Method               string         `gorm:"not null" json:"method"`                  // Drip, Sprinkler, Furrow, Flood, Centre Pivot, Manual
+	StartDate            time.Time      `gorm:"not null" json:"startDate"`               // First irrigation day
+	EndDate              *time.Time     `json:"endDate"`                                 // Last possible irrigation day (nil = open-ended)
+	IntervalDays         int            `gorm:"not null;default:1" json:"intervalDays"`  // Days between irrigations
+	StartTime            string         `json:"startTime"`                               // Time of day as HH:MM, e.g. "06:00"
+	DurationMinutes      int            `gorm:"not null" json:"durationMinutes"`         // Length of each irrigation
+	Volume               float64        `json:"volume"`                                  // Cubic metres applied per irrigation
+	Status               string         `gorm:"not null;default:'Active'" json:"status"` // Active, Paused, Completed
+	Notes                string         `json:"notes"`
+	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Field *Field `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+	Crop  *Crop  `gorm:"foreignKey:CropID;references:CropID" json:"crop,omitempty"`
+}
+
+// Occurrences returns the days in [from, to) on which the schedule irrigates
+func (s *IrrigationSchedule) Occurrences(from, to time.Time) []time.Time {
+	if s.IntervalDays <= 0 {
+		return nil
+	}
+	day := s.StartDate
+	if day.Before(from) {
+		// Skip whole intervals up to the first occurrence on or after from
+		skip := int(from.Sub(day).Hours()/24) / s.IntervalDays
+		day = day.AddDate(0, 0, skip*s.IntervalDays)
+		for day.Before(from) {
+			day = day.AddDate(0, 0, s.IntervalDays)
+		}
+	}
+
+	var days []time.Time
+	for ; day.Before(to); day = day.AddDate(0, 0, s.IntervalDays) {
+		if s.EndDate != nil && day.After(*s.EndDate) {
+			break
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// IrrigationScheduleInterface defines the contract for irrigation schedule operations
+type IrrigationScheduleInterface interface {
+	GetByIrrigationScheduleID(ctx context.Context, scheduleID string) (*IrrigationSchedule, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*IrrigationSchedule, error)
+	GetActive(ctx context.Context, farmID string, from, to time.Time) ([]*IrrigationSchedule, error)
+	Insert(ctx context.Context, schedule *IrrigationSchedule) error
+	Update(ctx context.Context, schedule *IrrigationSchedule) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// IrrigationScheduleRepo implements IrrigationScheduleInterface using GORM.
+type IrrigationScheduleRepo struct {
+	DB *gorm.DB
+}
+
+// NewIrrigationScheduleRepo creates a new instance of IrrigationScheduleRepo.
+func NewIrrigationScheduleRepo(db *gorm.DB) IrrigationScheduleInterface {
+	return &IrrigationScheduleRepo{DB: db}
+}
+
+// GetByIrrigationScheduleID retrieves a schedule with its field and crop by
+// its IrrigationScheduleID (UUID)
+func (i *IrrigationScheduleRepo) GetByIrrigationScheduleID(ctx context.Context, scheduleID string) (*IrrigationSchedule, error) {
+	var schedule IrrigationSchedule
+	result := i.DB.WithContext(ctx).Preload("Field").Preload("Crop").Where("irrigation_schedule_id = ?", scheduleID).First(&schedule)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &schedule, result.Error
+}
+
+// GetByFarmID retrieves a farm's schedules with their fields and crops
+func (i *IrrigationScheduleRepo) GetByFarmID(ctx context.Context, farmID string) ([]*IrrigationSchedule, error) {
+	var schedules []*IrrigationSchedule
+	result := i.DB.WithContext(ctx).Preload("Field").Preload("Crop").Where("farm_id = ?", farmID).Order("start_date").Find(&schedules)
+	return schedules, result.Error
+}
+
+// GetActive retrieves a farm's active schedules that run at some point in [from, to)
+func (i *IrrigationScheduleRepo) GetActive(ctx context.Context, farmID string, from, to time.Time) ([]*IrrigationSchedule, error) {
+	var schedules []*IrrigationSchedule
+	result := i.DB.WithContext(ctx).Preload("Field").Preload("Crop").
+		Where("farm_id = ? AND status = ?", farmID, "Active").
+		Where("start_date < ? AND (end_date IS NULL OR end_date >= ?)", to, from).
+		Find(&schedules)
+	return schedules, result.Error
+}
+
+// Insert creates a new schedule in the database
+func (i *IrrigationScheduleRepo) Insert(ctx context.Context, schedule *IrrigationSchedule) error {
+	return i.DB.WithContext(ctx).Omit("Field", "Crop").Create(schedule).Error
+}
+
+// Update updates an existing schedule in the database
+func (i *IrrigationScheduleRepo) Update(ctx context.Context, schedule *IrrigationSchedule) error {
+	return i.DB.WithContext(ctx).Omit("Field", "Crop").Save(schedule).Error
+}
+
+// DeleteByID soft deletes a schedule by its ID
+func (i *IrrigationScheduleRepo) DeleteByID(ctx context.Context, id int) error {
+	return i.DB.WithContext(ctx).Delete(&IrrigationSchedule{}, id).Error
+}
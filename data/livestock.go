@@ -1,6 +1,7 @@
 package data
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -17,6 +18,7 @@ type Livestock struct {
 	AcquisitionDate *time.Time     `json:"acquisitionDate"`
 	HealthStatus    string         `gorm:"not null;default:'Healthy'" json:"healthStatus"` // Healthy, Sick, Under Treatment, Deceased
 	Notes           string         `json:"notes"`
+	Version         int            `gorm:"not null;default:1" json:"version"` // Incremented by every update; send it back to update
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
@@ -25,17 +27,46 @@ type Livestock struct {
 	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
 }
 
+// LivestockGroup is the livestock of a farm of one type and health status,
+// counted in the database
+type LivestockGroup struct {
+	Type         string `json:"type"`
+	HealthStatus string `json:"healthStatus"`
+	Records      int    `json:"records"` // Livestock records in the group
+	Head         int    `json:"head"`    // Animals, the records' counts added up
+}
+
 // LivestockInterface defines the contract for livestock operations
 type LivestockInterface interface {
-	GetAll() ([]*Livestock, error)
-	GetByID(id int) (*Livestock, error)
-	GetByLivestockID(livestockID string) (*Livestock, error)
-	GetByFarmID(farmID string) ([]*Livestock, error)
-	Insert(livestock *Livestock) error
-	Update(livestock *Livestock) error
-	DeleteByID(id int) error
-	GetByType(livestockType string) ([]*Livestock, error)
-	GetByHealthStatus(healthStatus string) ([]*Livestock, error)
+	GetAll(ctx context.Context) ([]*Livestock, error)
+	GetByID(ctx context.Context, id int) (*Livestock, error)
+	GetByLivestockID(ctx context.Context, livestockID string) (*Livestock, error)
+	// GetByLivestockIDs retrieves the livestock with the given LivestockIDs
+	// in a single query, leaving out those that do not exist
+	GetByLivestockIDs(ctx context.Context, livestockIDs []string) ([]*Livestock, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Livestock, error)
+	// GetByFarmIDAndDateRange retrieves a farm's livestock acquired in
+	// [from, to); with a range, livestock without an acquisition date is left
+	// out
+	GetByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Livestock, error)
+	Insert(ctx context.Context, livestock *Livestock) error
+	// InsertMany creates livestock in a single transaction
+	InsertMany(ctx context.Context, livestock []*Livestock) error
+	Update(ctx context.Context, livestock *Livestock) error
+	// AdjustCount adds delta, which may be negative, to a livestock's count
+	// and moves it to its next version. It returns ErrStale if the livestock
+	// is gone or the count would drop below zero.
+	AdjustCount(ctx context.Context, livestockID string, delta int) error
+	DeleteByID(ctx context.Context, id int) error
+	// DeleteMany soft deletes several livestock in a single statement
+	DeleteMany(ctx context.Context, ids []uint) error
+	GetDeletedByFarmID(ctx context.Context, farmID string) ([]*Livestock, error)
+	GetDeletedByLivestockID(ctx context.Context, livestockID string) (*Livestock, error)
+	RestoreByID(ctx context.Context, id int) error
+	GetByType(ctx context.Context, livestockType string) ([]*Livestock, error)
+	GetByHealthStatus(ctx context.Context, healthStatus string) ([]*Livestock, error)
+	// Stats counts a farm's livestock per type and health status
+	Stats(ctx context.Context, farmID string) ([]LivestockGroup, error)
 }
 
 // LivestockRepo implements LivestockInterface using GORM.
@@ -49,16 +80,16 @@ func NewLivestockRepo(db *gorm.DB) LivestockInterface {
 }
 
 // GetAll retrieves all livestock from the database
-func (l *LivestockRepo) GetAll() ([]*Livestock, error) {
+func (l *LivestockRepo) GetAll(ctx context.Context) ([]*Livestock, error) {
 	var livestock []*Livestock
-	result := l.DB.Find(&livestock)
+	result := l.DB.WithContext(ctx).Find(&livestock)
 	return livestock, result.Error
 }
 
 // GetByID retrieves a livestock by its ID
-func (l *LivestockRepo) GetByID(id int) (*Livestock, error) {
+func (l *LivestockRepo) GetByID(ctx context.Context, id int) (*Livestock, error) {
 	var livestock Livestock
-	result := l.DB.Where("id = ?", id).First(&livestock)
+	result := l.DB.WithContext(ctx).Where("id = ?", id).First(&livestock)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -66,47 +97,122 @@ func (l *LivestockRepo) GetByID(id int) (*Livestock, error) {
 }
 
 // GetByLivestockID retrieves a livestock by its LivestockID (UUID)
-func (l *LivestockRepo) GetByLivestockID(livestockID string) (*Livestock, error) {
+func (l *LivestockRepo) GetByLivestockID(ctx context.Context, livestockID string) (*Livestock, error) {
 	var livestock Livestock
-	result := l.DB.Where("livestock_id = ?", livestockID).First(&livestock)
+	result := l.DB.WithContext(ctx).Where("livestock_id = ?", livestockID).First(&livestock)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
 	return &livestock, result.Error
 }
 
+// GetByLivestockIDs retrieves the livestock with the given LivestockIDs (UUIDs)
+func (l *LivestockRepo) GetByLivestockIDs(ctx context.Context, livestockIDs []string) ([]*Livestock, error) {
+	var livestock []*Livestock
+	result := l.DB.WithContext(ctx).Where("livestock_id IN ?", livestockIDs).Find(&livestock)
+	return livestock, result.Error
+}
+
 // GetByFarmID retrieves all livestock belonging to a specific farm
-func (l *LivestockRepo) GetByFarmID(farmID string) ([]*Livestock, error) {
+func (l *LivestockRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Livestock, error) {
 	var livestock []*Livestock
-	result := l.DB.Where("farm_id = ?", farmID).Find(&livestock)
+	result := l.DB.WithContext(ctx).Where("farm_id = ?", farmID).Find(&livestock)
 	return livestock, result.Error
 }
 
+// GetByFarmIDAndDateRange retrieves the livestock of a farm by acquisition
+// date, latest first
+func (l *LivestockRepo) GetByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Livestock, error) {
+	var livestock []*Livestock
+	query := dateRange(l.DB.WithContext(ctx).Where("farm_id = ?", farmID), "acquisition_date", from, to)
+	result := query.Order("acquisition_date desc NULLS LAST").Find(&livestock)
+	return livestock, result.Error
+}
+
+// Stats groups a farm's livestock by type and health status
+func (l *LivestockRepo) Stats(ctx context.Context, farmID string) ([]LivestockGroup, error) {
+	var groups []LivestockGroup
+	result := l.DB.WithContext(ctx).Model(&Livestock{}).
+		Select("type, health_status, COUNT(*) AS records, COALESCE(SUM(count), 0) AS head").
+		Where("farm_id = ?", farmID).
+		Group("type, health_status").Order("type, health_status").
+		Scan(&groups)
+	return groups, result.Error
+}
+
 // GetByType retrieves all livestock of a specific type
-func (l *LivestockRepo) GetByType(livestockType string) ([]*Livestock, error) {
+func (l *LivestockRepo) GetByType(ctx context.Context, livestockType string) ([]*Livestock, error) {
 	var livestock []*Livestock
-	result := l.DB.Where("type = ?", livestockType).Find(&livestock)
+	result := l.DB.WithContext(ctx).Where("type = ?", livestockType).Find(&livestock)
 	return livestock, result.Error
 }
 
 // GetByHealthStatus retrieves all livestock with a specific health status
-func (l *LivestockRepo) GetByHealthStatus(healthStatus string) ([]*Livestock, error) {
+func (l *LivestockRepo) GetByHealthStatus(ctx context.Context, healthStatus string) ([]*Livestock, error) {
 	var livestock []*Livestock
-	result := l.DB.Where("health_status = ?", healthStatus).Find(&livestock)
+	result := l.DB.WithContext(ctx).Where("health_status = ?", healthStatus).Find(&livestock)
 	return livestock, result.Error
 }
 
 // Insert creates a new livestock in the database
-func (l *LivestockRepo) Insert(livestock *Livestock) error {
-	return l.DB.Create(livestock).Error
+func (l *LivestockRepo) Insert(ctx context.Context, livestock *Livestock) error {
+	return l.DB.WithContext(ctx).Create(livestock).Error
+}
+
+// InsertMany creates several livestock in a single statement
+func (l *LivestockRepo) InsertMany(ctx context.Context, livestock []*Livestock) error {
+	return l.DB.WithContext(ctx).Create(&livestock).Error
 }
 
-// Update updates an existing livestock in the database
-func (l *LivestockRepo) Update(livestock *Livestock) error {
-	return l.DB.Save(livestock).Error
+// Update updates an existing livestock in the database, moving it to its next
+// version. It returns ErrStale if the livestock was updated since it was loaded.
+func (l *LivestockRepo) Update(ctx context.Context, livestock *Livestock) error {
+	return updateVersioned(l.DB.WithContext(ctx), livestock, &livestock.Version)
+}
+
+// AdjustCount adds delta to a livestock's count in a single statement, so
+// concurrent births and losses are all counted
+func (l *LivestockRepo) AdjustCount(ctx context.Context, livestockID string, delta int) error {
+	result := l.DB.WithContext(ctx).Model(&Livestock{}).
+		Where("livestock_id = ? AND count + ? >= 0", livestockID, delta).
+		Updates(map[string]any{"count": gorm.Expr("count + ?", delta), "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStale
+	}
+	return nil
 }
 
 // DeleteByID soft deletes a livestock by its ID
-func (l *LivestockRepo) DeleteByID(id int) error {
-	return l.DB.Delete(&Livestock{}, id).Error
+func (l *LivestockRepo) DeleteByID(ctx context.Context, id int) error {
+	return l.DB.WithContext(ctx).Delete(&Livestock{}, id).Error
+}
+
+// DeleteMany soft deletes the livestock with the given IDs
+func (l *LivestockRepo) DeleteMany(ctx context.Context, ids []uint) error {
+	return l.DB.WithContext(ctx).Delete(&Livestock{}, ids).Error
+}
+
+// GetDeletedByFarmID retrieves soft-deleted livestock belonging to a specific farm
+func (l *LivestockRepo) GetDeletedByFarmID(ctx context.Context, farmID string) ([]*Livestock, error) {
+	var livestock []*Livestock
+	result := l.DB.WithContext(ctx).Unscoped().Where("farm_id = ? AND deleted_at IS NOT NULL", farmID).Order("deleted_at desc").Find(&livestock)
+	return livestock, result.Error
+}
+
+// GetDeletedByLivestockID retrieves a soft-deleted livestock by its LivestockID (UUID)
+func (l *LivestockRepo) GetDeletedByLivestockID(ctx context.Context, livestockID string) (*Livestock, error) {
+	var livestock Livestock
+	result := l.DB.WithContext(ctx).Unscoped().Where("livestock_id = ? AND deleted_at IS NOT NULL", livestockID).First(&livestock)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &livestock, result.Error
+}
+
+// RestoreByID restores a soft-deleted livestock by its ID
+func (l *LivestockRepo) RestoreByID(ctx context.Context, id int) error {
+	return l.DB.WithContext(ctx).Unscoped().Model(&Livestock{}).Where("id = ?", id).Update("deleted_at", nil).Error
 }
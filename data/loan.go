@@ -0,0 +1,235 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Loan represents the loans table in the database: credit a farm has taken,
+// such as a seasonal input loan. Installments is the repayment schedule
+// worked out from the terms when the loan is recorded; Repayments are what
+// has been paid back.
+type Loan struct {
+	ID               uint           `gorm:"primaryKey" json:"-"`
+	LoanID           string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"loanId"`
+	FarmID           string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Lender           string         `gorm:"not null" json:"lender"`               // Bank, SACCO, input dealer, etc.
+	Principal        float64        `gorm:"not null" json:"principal"`
+	InterestRate     float64        `gorm:"not null" json:"interestRate"`                  // Annual percentage
+	InterestMethod   string         `gorm:"not null;default:'Flat'" json:"interestMethod"` // Flat, Reducing
+	DisbursementDate time.Time      `gorm:"not null" json:"disbursementDate"`
+	TermMonths       int            `gorm:"not null" json:"termMonths"`
+	Frequency        string         `gorm:"not null;default:'Monthly'" json:"frequency"`   // Monthly, Quarterly, Bullet (all due at the end of the term)
+	Status           string         `gorm:"not null;default:'Active';index" json:"status"` // Active, Repaid
+	Notes            string         `json:"notes"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Installments []LoanInstallment `gorm:"foreignKey:LoanID;references:LoanID" json:"installments"`
+	Repayments   []LoanRepayment   `gorm:"foreignKey:LoanID;references:LoanID" json:"repayments"`
+}
+
+// TotalDue is the principal and interest over all installments
+func (l *Loan) TotalDue() float64 {
+	var total float64
+	for _, installment := range l.Installments {
+		total += installment.Amount
+	}
+	return total
+}
+
+// Repaid is the sum of the loan's repayments
+func (l *Loan) Repaid() float64 {
+	var total float64
+	for _, repayment := range l.Repayments {
+		total += repayment.Amount
+	}
+	return total
+}
+
+// LoanInstallment represents the loan_installments table in the database: one
+// scheduled repayment of a loan
+type LoanInstallment struct {
+	ID                uint      `gorm:"primaryKey" json:"-"`
+	LoanInstallmentID string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"installmentId"`
+	LoanID            string    `gorm:"not null;size:36;index" json:"loanId"` // Foreign key to Loan
+	Number            int       `gorm:"not null" json:"number"`               // 1 for the first installment
+	DueDate           time.Time `gorm:"not null" json:"dueDate"`
+	Principal         float64   `gorm:"not null" json:"principal"`
+	Interest          float64   `gorm:"not null" json:"interest"`
+	Amount            float64   `gorm:"not null" json:"amount"` // Principal plus interest
+	CreatedAt         time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// LoanRepayment represents the loan_repayments table in the database: money
+// paid back on a loan. The interest it covers is carried into the finance
+// ledger as an expense; the principal is not, as it repays borrowed money
+// rather than costing the farm.
+type LoanRepayment struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	LoanRepaymentID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"repaymentId"`
+	LoanID          string         `gorm:"not null;size:36;index" json:"loanId"` // Foreign key to Loan
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Date            time.Time      `gorm:"not null" json:"date"`
+	Amount          float64        `gorm:"not null" json:"amount"`
+	Interest        float64        `gorm:"not null" json:"interest"` // Share of the amount that paid interest
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries this repayment's interest into the finance ledger
+func (r *LoanRepayment) TransactionReference() string {
+	return fmt.Sprintf("loan_repayment:%s", r.LoanRepaymentID)
+}
+
+// LoanInterface defines the contract for loan operations
+type LoanInterface interface {
+	// GetByLoanID retrieves a loan with its schedule and repayments
+	GetByLoanID(ctx context.Context, loanID string) (*Loan, error)
+	// GetByFarmID retrieves a farm's loans with their schedules and
+	// repayments, optionally only those with the given status
+	GetByFarmID(ctx context.Context, farmID, status string) ([]*Loan, error)
+	Insert(ctx context.Context, loan *Loan) error
+	Update(ctx context.Context, loan *Loan) error
+	DeleteByID(ctx context.Context, id int) error
+	GetRepaymentByID(ctx context.Context, loanRepaymentID string) (*LoanRepayment, error)
+	// InsertRepayment records a repayment and saves the loan's status
+	InsertRepayment(ctx context.Context, loan *Loan, repayment *LoanRepayment) error
+	// DeleteRepayment removes a repayment and saves the loan's status
+	DeleteRepayment(ctx context.Context, loan *Loan, repayment *LoanRepayment) error
+}
+
+// LoanRepo implements LoanInterface using GORM.
+type LoanRepo struct {
+	DB *gorm.DB
+}
+
+// NewLoanRepo creates a new instance of LoanRepo.
+func NewLoanRepo(db *gorm.DB) LoanInterface {
+	return &LoanRepo{DB: db}
+}
+
+// withSchedule preloads a loan's installments and repayments in date order
+func withSchedule(db *gorm.DB) *gorm.DB {
+	return db.Preload("Installments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("number")
+	}).Preload("Repayments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("date, id")
+	})
+}
+
+// GetByLoanID retrieves a loan with its schedule and repayments by its LoanID
+// (UUID)
+func (l *LoanRepo) GetByLoanID(ctx context.Context, loanID string) (*Loan, error) {
+	var loan Loan
+	result := withSchedule(l.DB.WithContext(ctx)).Where("loan_id = ?", loanID).First(&loan)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &loan, result.Error
+}
+
+// GetByFarmID retrieves a farm's loans, most recently disbursed first
+func (l *LoanRepo) GetByFarmID(ctx context.Context, farmID, status string) ([]*Loan, error) {
+	var loans []*Loan
+	query := withSchedule(l.DB.WithContext(ctx)).Where("farm_id = ?", farmID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("disbursement_date desc").Find(&loans)
+	return loans, result.Error
+}
+
+// Insert creates a new loan and its installments in a single transaction
+func (l *LoanRepo) Insert(ctx context.Context, loan *Loan) error {
+	return l.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Installments", "Repayments").Create(loan).Error; err != nil {
+			return err
+		}
+		for i := range loan.Installments {
+			loan.Installments[i].LoanID = loan.LoanID
+		}
+		if len(loan.Installments) == 0 {
+			return nil
+		}
+		return tx.Create(&loan.Installments).Error
+	})
+}
+
+// Update saves a loan and replaces its installments in a single transaction
+func (l *LoanRepo) Update(ctx context.Context, loan *Loan) error {
+	return l.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("loan_id = ?", loan.LoanID).Delete(&LoanInstallment{}).Error; err != nil {
+			return err
+		}
+		for i := range loan.Installments {
+			loan.Installments[i].ID = 0
+			loan.Installments[i].LoanInstallmentID = ""
+			loan.Installments[i].LoanID = loan.LoanID
+		}
+		if len(loan.Installments) > 0 {
+			if err := tx.Create(&loan.Installments).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Installments", "Repayments").Save(loan).Error
+	})
+}
+
+// DeleteByID soft deletes a loan by its ID
+func (l *LoanRepo) DeleteByID(ctx context.Context, id int) error {
+	return l.DB.WithContext(ctx).Delete(&Loan{}, id).Error
+}
+
+// GetRepaymentByID retrieves a repayment by its LoanRepaymentID (UUID)
+func (l *LoanRepo) GetRepaymentByID(ctx context.Context, loanRepaymentID string) (*LoanRepayment, error) {
+	var repayment LoanRepayment
+	result := l.DB.WithContext(ctx).Where("loan_repayment_id = ?", loanRepaymentID).First(&repayment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &repayment, result.Error
+}
+
+// InsertRepayment creates a repayment, the Interest expense for the interest
+// it covers and the loan's new status in a single transaction
+func (l *LoanRepo) InsertRepayment(ctx context.Context, loan *Loan, repayment *LoanRepayment) error {
+	return l.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(repayment).Error; err != nil {
+			return err
+		}
+		if err := syncExpense(tx, repayment.TransactionReference(), Transaction{
+			FarmID:      repayment.FarmID,
+			Category:    "Interest",
+			Amount:      repayment.Interest,
+			Date:        repayment.Date,
+			Description: "Loan interest: " + loan.Lender,
+		}); err != nil {
+			return err
+		}
+		return tx.Model(loan).Update("status", loan.Status).Error
+	})
+}
+
+// DeleteRepayment soft deletes a repayment along with its expense transaction
+// and saves the loan's new status in a single transaction
+func (l *LoanRepo) DeleteRepayment(ctx context.Context, loan *Loan, repayment *LoanRepayment) error {
+	return l.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reference = ?", repayment.TransactionReference()).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(repayment).Error; err != nil {
+			return err
+		}
+		return tx.Model(loan).Update("status", loan.Status).Error
+	})
+}
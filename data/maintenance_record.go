@@ -0,0 +1,157 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaintenanceRecord represents the maintenance_records table in the
+// database. Each record is one service event on a piece of equipment.
+type MaintenanceRecord struct {
+	ID                  uint           `gorm:"primaryKey" json:"-"`
+	MaintenanceRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"maintenanceRecordId"`
+	EquipmentID         string         `gorm:"not null;size:36;index" json:"equipmentId"` // Foreign key to Equipment
+	FarmID              string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	Date                time.Time      `gorm:"not null;index" json:"date"`
+	Type                string         `gorm:"not null" json:"type"` // Scheduled Service, Repair, Inspection, Breakdown
+	Description         string         `json:"description"`
+	Cost                float64        `json:"cost"`
+	DowntimeHours       float64        `json:"downtimeHours"` // Hours the equipment was out of use
+	PartsUsed           string         `json:"partsUsed"`
+	PerformedBy         string         `json:"performedBy"`                            // Mechanic, dealer or employee who did the work
+	ServiceProviderID   *string        `gorm:"size:36;index" json:"serviceProviderId"` // Contractor who did the work, if in the farm's directory
+	Notes               string         `json:"notes"`
+	CreatedAt           time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt           time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Equipment       *Equipment       `gorm:"foreignKey:EquipmentID;references:EquipmentID" json:"equipment,omitempty"`
+	ServiceProvider *ServiceProvider `gorm:"foreignKey:ServiceProviderID;references:ServiceProviderID" json:"serviceProvider,omitempty"`
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries this record's cost into the finance ledger
+func (m *MaintenanceRecord) TransactionReference() string {
+	return fmt.Sprintf("maintenance_record:%s", m.MaintenanceRecordID)
+}
+
+// YearlyMaintenanceCost is the maintenance spend and downtime of one piece of
+// equipment in one calendar year
+type YearlyMaintenanceCost struct {
+	EquipmentID   string  `json:"equipmentId"`
+	EquipmentName string  `json:"equipmentName"`
+	Year          int     `json:"year"`
+	Records       int     `json:"records"`
+	Cost          float64 `json:"cost"`
+	DowntimeHours float64 `json:"downtimeHours"`
+}
+
+// MaintenanceRecordInterface defines the contract for maintenance record operations
+type MaintenanceRecordInterface interface {
+	GetByMaintenanceRecordID(ctx context.Context, maintenanceRecordID string) (*MaintenanceRecord, error)
+	GetByEquipmentID(ctx context.Context, equipmentID string, from, to *time.Time) ([]*MaintenanceRecord, error)
+	// GetByServiceProviderID retrieves the work a provider did, most recent
+	// first, with the equipment worked on
+	GetByServiceProviderID(ctx context.Context, serviceProviderID string) ([]*MaintenanceRecord, error)
+	YearlyCosts(ctx context.Context, farmID, equipmentID string, year int) ([]YearlyMaintenanceCost, error)
+	Insert(ctx context.Context, record *MaintenanceRecord) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// MaintenanceRecordRepo implements MaintenanceRecordInterface using GORM.
+type MaintenanceRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewMaintenanceRecordRepo creates a new instance of MaintenanceRecordRepo.
+func NewMaintenanceRecordRepo(db *gorm.DB) MaintenanceRecordInterface {
+	return &MaintenanceRecordRepo{DB: db}
+}
+
+// GetByMaintenanceRecordID retrieves a maintenance record by its MaintenanceRecordID (UUID)
+func (m *MaintenanceRecordRepo) GetByMaintenanceRecordID(ctx context.Context, maintenanceRecordID string) (*MaintenanceRecord, error) {
+	var record MaintenanceRecord
+	result := m.DB.WithContext(ctx).Where("maintenance_record_id = ?", maintenanceRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByEquipmentID retrieves the maintenance log of a piece of equipment,
+// optionally limited to dates in [from, to)
+func (m *MaintenanceRecordRepo) GetByEquipmentID(ctx context.Context, equipmentID string, from, to *time.Time) ([]*MaintenanceRecord, error) {
+	var records []*MaintenanceRecord
+	query := m.DB.WithContext(ctx).Where("equipment_id = ?", equipmentID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Preload("ServiceProvider").Order("date desc").Find(&records)
+	return records, result.Error
+}
+
+// GetByServiceProviderID retrieves a provider's maintenance records, most
+// recent first
+func (m *MaintenanceRecordRepo) GetByServiceProviderID(ctx context.Context, serviceProviderID string) ([]*MaintenanceRecord, error) {
+	var records []*MaintenanceRecord
+	result := m.DB.WithContext(ctx).Where("service_provider_id = ?", serviceProviderID).
+		Preload("Equipment").Order("date desc").Find(&records)
+	return records, result.Error
+}
+
+// YearlyCosts sums a farm's maintenance cost and downtime per piece of
+// equipment and calendar year. An empty equipmentID covers all equipment and
+// a zero year covers all years.
+func (m *MaintenanceRecordRepo) YearlyCosts(ctx context.Context, farmID, equipmentID string, year int) ([]YearlyMaintenanceCost, error) {
+	var totals []YearlyMaintenanceCost
+	query := m.DB.WithContext(ctx).Model(&MaintenanceRecord{}).
+		Select("equipment_id, CAST(EXTRACT(YEAR FROM date) AS INTEGER) AS year, COUNT(*) AS records, SUM(cost) AS cost, SUM(downtime_hours) AS downtime_hours").
+		Where("farm_id = ?", farmID)
+	if equipmentID != "" {
+		query = query.Where("equipment_id = ?", equipmentID)
+	}
+	if year != 0 {
+		query = query.Where("EXTRACT(YEAR FROM date) = ?", year)
+	}
+	result := query.Group("equipment_id, year").Order("year desc, cost desc").Scan(&totals)
+	return totals, result.Error
+}
+
+// Insert creates a new maintenance record and, when it has a cost, the
+// matching expense in the finance ledger, in a single transaction
+func (m *MaintenanceRecordRepo) Insert(ctx context.Context, record *MaintenanceRecord) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Equipment", "ServiceProvider").Create(record).Error; err != nil {
+			return err
+		}
+		return syncExpense(tx, record.TransactionReference(), Transaction{
+			FarmID:      record.FarmID,
+			Category:    "Maintenance",
+			Amount:      record.Cost,
+			Date:        record.Date,
+			Description: fmt.Sprintf("%s: %s", record.Type, record.Description),
+		})
+	})
+}
+
+// DeleteByID soft deletes a maintenance record by its ID along with its expense transaction
+func (m *MaintenanceRecordRepo) DeleteByID(ctx context.Context, id int) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record MaintenanceRecord
+		if err := tx.Where("id = ?", id).First(&record).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reference = ?", record.TransactionReference()).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+}
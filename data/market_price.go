@@ -0,0 +1,83 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MarketPrice represents the market_prices table in the database: the price
+// of a commodity in a region on a day, from a price feed or an admin upload.
+type MarketPrice struct {
+	ID            uint      `gorm:"primaryKey" json:"-"`
+	MarketPriceID string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"priceId"`
+	Commodity     string    `gorm:"not null;uniqueIndex:idx_market_price_day" json:"commodity"` // Lower case, e.g. maize, beans, cattle
+	Region        string    `gorm:"not null;uniqueIndex:idx_market_price_day" json:"region"`    // Market or region name, e.g. Kampala
+	Unit          string    `gorm:"not null;uniqueIndex:idx_market_price_day" json:"unit"`      // e.g. kg, bag (100kg), head
+	Date          time.Time `gorm:"not null;uniqueIndex:idx_market_price_day" json:"date"`
+	Price         float64   `gorm:"not null" json:"price"`
+	Currency      string    `gorm:"not null" json:"currency"` // ISO 4217 code, e.g. UGX
+	Source        string    `gorm:"not null" json:"source"`   // "manual" or the feed's name
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// MarketPriceInterface defines the contract for market price operations
+type MarketPriceInterface interface {
+	// GetPrices returns prices for a commodity, optionally in one region,
+	// with dates in [from, to), newest first
+	GetPrices(ctx context.Context, commodity, region string, from, to *time.Time) ([]*MarketPrice, error)
+	Commodities(ctx context.Context) ([]string, error)
+	// Upsert saves prices, replacing any already held for the same
+	// commodity, region, unit and day
+	Upsert(ctx context.Context, prices []*MarketPrice) error
+}
+
+// MarketPriceRepo implements MarketPriceInterface using GORM.
+type MarketPriceRepo struct {
+	DB *gorm.DB
+}
+
+// NewMarketPriceRepo creates a new instance of MarketPriceRepo.
+func NewMarketPriceRepo(db *gorm.DB) MarketPriceInterface {
+	return &MarketPriceRepo{DB: db}
+}
+
+// GetPrices retrieves prices for a commodity, optionally in one region and
+// limited to dates in [from, to), newest first
+func (m *MarketPriceRepo) GetPrices(ctx context.Context, commodity, region string, from, to *time.Time) ([]*MarketPrice, error) {
+	var prices []*MarketPrice
+	query := m.DB.WithContext(ctx).Where("commodity = ?", commodity)
+	if region != "" {
+		query = query.Where("LOWER(region) = LOWER(?)", region)
+	}
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc, region, unit").Find(&prices)
+	return prices, result.Error
+}
+
+// Commodities retrieves the commodities that have prices, alphabetically
+func (m *MarketPriceRepo) Commodities(ctx context.Context) ([]string, error) {
+	var commodities []string
+	result := m.DB.WithContext(ctx).Model(&MarketPrice{}).Distinct().Order("commodity").Pluck("commodity", &commodities)
+	return commodities, result.Error
+}
+
+// Upsert saves prices, replacing any already held for the same commodity,
+// region, unit and day
+func (m *MarketPriceRepo) Upsert(ctx context.Context, prices []*MarketPrice) error {
+	if len(prices) == 0 {
+		return nil
+	}
+	return m.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "commodity"}, {Name: "region"}, {Name: "unit"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"price", "currency", "source", "updated_at"}),
+	}).CreateInBatches(prices, 500).Error
+}
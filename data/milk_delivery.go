@@ -0,0 +1,117 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MilkDelivery represents the milk_deliveries table in the database: milk a
+// farm delivered to a collection center in one session, with the center's
+// quality tests. It is the farm's production record for the milk, and
+// accepted milk is carried into the finance ledger as a sale.
+type MilkDelivery struct {
+	ID                 uint           `gorm:"primaryKey" json:"-"`
+	MilkDeliveryID     string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"deliveryId"`
+	CollectionCenterID string         `gorm:"not null;size:36;uniqueIndex:idx_milk_delivery_reference,where:deleted_at IS NULL" json:"centerId"`
+	Reference          string         `gorm:"not null;uniqueIndex:idx_milk_delivery_reference,where:deleted_at IS NULL" json:"reference"` // Center's receipt number
+	FarmID             string         `gorm:"not null;size:36;index" json:"farmId"`                                                       // Foreign key to Farm
+	SupplierNumber     string         `gorm:"not null" json:"supplierNumber"`
+	Date               time.Time      `gorm:"not null;index" json:"date"`
+	Session            string         `gorm:"not null" json:"session"` // Morning, Evening
+	Volume             float64        `gorm:"not null" json:"volume"`  // Litres
+	Fat                float64        `json:"fat,omitempty"`           // Butterfat, %
+	SNF                float64        `json:"snf,omitempty"`           // Solids-not-fat, %
+	Density            float64        `json:"density,omitempty"`       // Lactometer reading, g/ml
+	Accepted           bool           `gorm:"not null" json:"accepted"`
+	RejectionReason    string         `json:"rejectionReason,omitempty"`
+	PricePerLitre      float64        `gorm:"not null" json:"pricePerLitre"`
+	Amount             float64        `gorm:"not null" json:"amount"` // Owed to the farmer; zero if rejected
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// TransactionReference is the reference used on the income transaction that
+// carries a delivery's sale into the finance ledger
+func (m *MilkDelivery) TransactionReference() string {
+	return fmt.Sprintf("milk_delivery:%s", m.MilkDeliveryID)
+}
+
+// MilkDeliveryInterface defines the contract for milk delivery operations
+type MilkDeliveryInterface interface {
+	// GetByReference returns a center's delivery by its receipt number, or nil
+	GetByReference(ctx context.Context, collectionCenterID, reference string) (*MilkDelivery, error)
+	// GetByFarmID returns a farm's deliveries, optionally only those between
+	// from (inclusive) and to (exclusive)
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*MilkDelivery, error)
+	// GetByCollectionCenterID returns a center's deliveries, optionally only
+	// those between from (inclusive) and to (exclusive)
+	GetByCollectionCenterID(ctx context.Context, collectionCenterID string, from, to *time.Time) ([]*MilkDelivery, error)
+	// Save creates or updates a delivery together with its sale transaction
+	Save(ctx context.Context, delivery *MilkDelivery) error
+}
+
+// MilkDeliveryRepo implements MilkDeliveryInterface using GORM.
+type MilkDeliveryRepo struct {
+	DB *gorm.DB
+}
+
+// NewMilkDeliveryRepo creates a new instance of MilkDeliveryRepo.
+func NewMilkDeliveryRepo(db *gorm.DB) MilkDeliveryInterface {
+	return &MilkDeliveryRepo{DB: db}
+}
+
+// GetByReference retrieves a center's delivery by its receipt number
+func (m *MilkDeliveryRepo) GetByReference(ctx context.Context, collectionCenterID, reference string) (*MilkDelivery, error) {
+	var delivery MilkDelivery
+	result := m.DB.WithContext(ctx).Where("collection_center_id = ? AND reference = ?", collectionCenterID, reference).First(&delivery)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &delivery, result.Error
+}
+
+// GetByFarmID retrieves a farm's deliveries, oldest first
+func (m *MilkDeliveryRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*MilkDelivery, error) {
+	return m.find(m.DB.WithContext(ctx).Where("farm_id = ?", farmID), from, to)
+}
+
+// GetByCollectionCenterID retrieves a center's deliveries, oldest first
+func (m *MilkDeliveryRepo) GetByCollectionCenterID(ctx context.Context, collectionCenterID string, from, to *time.Time) ([]*MilkDelivery, error) {
+	return m.find(m.DB.WithContext(ctx).Where("collection_center_id = ?", collectionCenterID), from, to)
+}
+
+// find runs a delivery query limited to the date range
+func (m *MilkDeliveryRepo) find(query *gorm.DB, from, to *time.Time) ([]*MilkDelivery, error) {
+	var deliveries []*MilkDelivery
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date, session desc, id").Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// Save creates or updates a delivery and, in the same transaction, keeps its
+// income transaction in line with the amount owed for it
+func (m *MilkDeliveryRepo) Save(ctx context.Context, delivery *MilkDelivery) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(delivery).Error; err != nil {
+			return err
+		}
+		return syncTransaction(tx, delivery.TransactionReference(), Transaction{
+			FarmID:      delivery.FarmID,
+			Type:        "Income",
+			Category:    "Milk Sales",
+			Amount:      delivery.Amount,
+			Date:        delivery.Date,
+			Description: fmt.Sprintf("%.1f L of milk, %s delivery %s", delivery.Volume, delivery.Session, delivery.Reference),
+		})
+	})
+}
@@ -1,6 +1,11 @@
 package data
 
-import "gorm.io/gorm"
+import (
+	"context"
+	"slices"
+
+	"gorm.io/gorm"
+)
 
 type Models struct {
 	User      UserInterface
@@ -8,6 +13,115 @@ type Models struct {
 	Crop      CropInterface
 	Livestock LivestockInterface
 	Employee  EmployeeInterface
+	Field     FieldInterface
+
+	FarmMember FarmMemberInterface
+
+	CropPlan     CropPlanInterface
+	PlanScenario PlanScenarioInterface
+	Season       SeasonInterface
+
+	PayrollPayment PayrollPaymentInterface
+	Attendance     AttendanceInterface
+	Shift          ShiftInterface
+
+	Equipment         EquipmentInterface
+	MaintenanceRecord MaintenanceRecordInterface
+
+	Asset AssetInterface
+
+	WaterSource WaterSourceInterface
+	WaterUsage  WaterUsageInterface
+
+	IrrigationSchedule IrrigationScheduleInterface
+	RainfallRecord     RainfallRecordInterface
+
+	Paddock     PaddockInterface
+	GrazingMove GrazingMoveInterface
+
+	BreedingEvent BreedingEventInterface
+	BirthRecord   BirthRecordInterface
+
+	ProductionRecord ProductionRecordInterface
+	FeedingRecord    FeedingRecordInterface
+	WeightRecord     WeightRecordInterface
+	MortalityRecord  MortalityRecordInterface
+	CropIncident     CropIncidentInterface
+	SprayRecord      SprayRecordInterface
+
+	ChemicalProduct ChemicalProductInterface
+	ChemicalUsage   ChemicalUsageInterface
+
+	InventoryItem     InventoryItemInterface
+	InventoryBatch    InventoryBatchInterface
+	InventoryMovement InventoryMovementInterface
+
+	Supplier        SupplierInterface
+	ServiceProvider ServiceProviderInterface
+	PurchaseOrder   PurchaseOrderInterface
+
+	Notification NotificationInterface
+
+	BuyerProfile BuyerProfileInterface
+	Rating       RatingInterface
+	Dispute      DisputeInterface
+	Escrow       EscrowInterface
+
+	MarketPrice MarketPriceInterface
+
+	Transaction   TransactionInterface
+	UtilityRecord UtilityRecordInterface
+	PeriodLock    PeriodLockInterface
+	TaxRate       TaxRateInterface
+	ExchangeRate  ExchangeRateInterface
+	BudgetLine    BudgetLineInterface
+	Loan          LoanInterface
+
+	SustainabilityPractice   SustainabilityPracticeInterface
+	SustainabilityAssessment SustainabilityAssessmentInterface
+
+	ImportJob  ImportJobInterface
+	ReportJob  ReportJobInterface
+	ExportJob  ExportJobInterface
+	Attachment AttachmentInterface
+	Document   DocumentInterface
+	Tag        TagInterface
+	SavedView  SavedViewInterface
+	Note       NoteInterface
+
+	DashboardLayout DashboardLayoutInterface
+
+	Organization       OrganizationInterface
+	ProcurementWindow  ProcurementWindowInterface
+	ProcurementRequest ProcurementRequestInterface
+	ProcurementOrder   ProcurementOrderInterface
+
+	CollectionCenter CollectionCenterInterface
+	MilkDelivery     MilkDeliveryInterface
+
+	Webhook         WebhookInterface
+	WebhookDelivery WebhookDeliveryInterface
+	APIKey          APIKeyInterface
+
+	Sync          SyncInterface
+	Search        SearchInterface
+	AccountExport AccountExportInterface
+
+	AuditLog     AuditLogInterface
+	APIUsage     APIUsageInterface
+	SystemStats  SystemStatsInterface
+	RevokedToken RevokedTokenInterface
+	PhoneLogin   PhoneLoginInterface
+
+	// db is the connection or transaction the repositories run on
+	db *gorm.DB
+	// cache is where cached lookups are kept, if any (see Cached)
+	cache *cacheStore
+	// wraps are applied again to the Models bound to a transaction (see Wrap)
+	wraps []func(Models) Models
+	// committed collects what to run once the transaction these Models are
+	// bound to commits, or is nil outside a transaction (see AfterCommit)
+	committed *[]func()
 }
 
 func New(gormDB *gorm.DB) Models {
@@ -17,5 +131,153 @@ func New(gormDB *gorm.DB) Models {
 		Crop:      NewCropRepo(gormDB),
 		Livestock: NewLivestockRepo(gormDB),
 		Employee:  NewEmployeeRepo(gormDB),
+		Field:     NewFieldRepo(gormDB),
+
+		FarmMember: NewFarmMemberRepo(gormDB),
+
+		CropPlan:     NewCropPlanRepo(gormDB),
+		PlanScenario: NewPlanScenarioRepo(gormDB),
+		Season:       NewSeasonRepo(gormDB),
+
+		PayrollPayment: NewPayrollPaymentRepo(gormDB),
+		Attendance:     NewAttendanceRepo(gormDB),
+		Shift:          NewShiftRepo(gormDB),
+
+		Equipment:         NewEquipmentRepo(gormDB),
+		MaintenanceRecord: NewMaintenanceRecordRepo(gormDB),
+
+		Asset: NewAssetRepo(gormDB),
+
+		WaterSource: NewWaterSourceRepo(gormDB),
+		WaterUsage:  NewWaterUsageRepo(gormDB),
+
+		IrrigationSchedule: NewIrrigationScheduleRepo(gormDB),
+		RainfallRecord:     NewRainfallRecordRepo(gormDB),
+
+		Paddock:     NewPaddockRepo(gormDB),
+		GrazingMove: NewGrazingMoveRepo(gormDB),
+
+		BreedingEvent: NewBreedingEventRepo(gormDB),
+		BirthRecord:   NewBirthRecordRepo(gormDB),
+
+		ProductionRecord: NewProductionRecordRepo(gormDB),
+		FeedingRecord:    NewFeedingRecordRepo(gormDB),
+		WeightRecord:     NewWeightRecordRepo(gormDB),
+		MortalityRecord:  NewMortalityRecordRepo(gormDB),
+		CropIncident:     NewCropIncidentRepo(gormDB),
+		SprayRecord:      NewSprayRecordRepo(gormDB),
+
+		ChemicalProduct: NewChemicalProductRepo(gormDB),
+		ChemicalUsage:   NewChemicalUsageRepo(gormDB),
+
+		InventoryItem:     NewInventoryItemRepo(gormDB),
+		InventoryBatch:    NewInventoryBatchRepo(gormDB),
+		InventoryMovement: NewInventoryMovementRepo(gormDB),
+
+		Supplier:        NewSupplierRepo(gormDB),
+		ServiceProvider: NewServiceProviderRepo(gormDB),
+		PurchaseOrder:   NewPurchaseOrderRepo(gormDB),
+
+		Notification: NewNotificationRepo(gormDB),
+
+		BuyerProfile: NewBuyerProfileRepo(gormDB),
+		Rating:       NewRatingRepo(gormDB),
+		Dispute:      NewDisputeRepo(gormDB),
+		Escrow:       NewEscrowRepo(gormDB),
+
+		MarketPrice: NewMarketPriceRepo(gormDB),
+
+		Transaction:   NewTransactionRepo(gormDB),
+		UtilityRecord: NewUtilityRecordRepo(gormDB),
+		PeriodLock:    NewPeriodLockRepo(gormDB),
+		TaxRate:       NewTaxRateRepo(gormDB),
+		ExchangeRate:  NewExchangeRateRepo(gormDB),
+		BudgetLine:    NewBudgetLineRepo(gormDB),
+		Loan:          NewLoanRepo(gormDB),
+
+		SustainabilityPractice:   NewSustainabilityPracticeRepo(gormDB),
+		SustainabilityAssessment: NewSustainabilityAssessmentRepo(gormDB),
+
+		ImportJob:  NewImportJobRepo(gormDB),
+		ReportJob:  NewReportJobRepo(gormDB),
+		ExportJob:  NewExportJobRepo(gormDB),
+		Attachment: NewAttachmentRepo(gormDB),
+		Document:   NewDocumentRepo(gormDB),
+		Tag:        NewTagRepo(gormDB),
+		SavedView:  NewSavedViewRepo(gormDB),
+		Note:       NewNoteRepo(gormDB),
+
+		DashboardLayout: NewDashboardLayoutRepo(gormDB),
+
+		Organization:       NewOrganizationRepo(gormDB),
+		ProcurementWindow:  NewProcurementWindowRepo(gormDB),
+		ProcurementRequest: NewProcurementRequestRepo(gormDB),
+		ProcurementOrder:   NewProcurementOrderRepo(gormDB),
+
+		CollectionCenter: NewCollectionCenterRepo(gormDB),
+		MilkDelivery:     NewMilkDeliveryRepo(gormDB),
+
+		Webhook:         NewWebhookRepo(gormDB),
+		WebhookDelivery: NewWebhookDeliveryRepo(gormDB),
+		APIKey:          NewAPIKeyRepo(gormDB),
+
+		Sync:          NewSyncRepo(gormDB),
+		Search:        NewSearchRepo(gormDB),
+		AccountExport: NewAccountExportRepo(gormDB),
+
+		AuditLog:     NewAuditLogRepo(gormDB),
+		APIUsage:     NewAPIUsageRepo(gormDB),
+		SystemStats:  NewSystemStatsRepo(gormDB),
+		RevokedToken: NewRevokedTokenRepo(gormDB),
+		PhoneLogin:   NewPhoneLoginRepo(gormDB),
+
+		db: gormDB,
+	}
+}
+
+// WithTransaction runs fn with every repository bound to one transaction,
+// committing it if fn returns nil and rolling it back otherwise. Calling
+// WithTransaction on the Models passed to fn nests a savepoint, whose
+// AfterCommit functions wait for the outermost transaction.
+func (m Models) WithTransaction(ctx context.Context, fn func(tx Models) error) error {
+	var committed []func()
+	err := m.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		tx := New(db)
+		if m.cache != nil {
+			tx = tx.Cached(m.cache.cache, m.cache.ttl)
+		}
+		tx.wraps = m.wraps
+		tx.committed = &committed
+		for _, wrap := range m.wraps {
+			tx = wrap(tx)
+		}
+		return fn(tx)
+	})
+	if err != nil {
+		return err
+	}
+	m.AfterCommit(func() {
+		for _, f := range committed {
+			f()
+		}
+	})
+	return nil
+}
+
+// Wrap returns wrap(m), e.g. m with a repository decorated. The Models passed
+// to fn by WithTransaction are wrapped the same way.
+func (m Models) Wrap(wrap func(Models) Models) Models {
+	m = wrap(m)
+	m.wraps = append(slices.Clip(m.wraps), wrap)
+	return m
+}
+
+// AfterCommit runs f once the transaction m is bound to has committed, or
+// straight away if m is not bound to one. f is dropped on a rollback.
+func (m Models) AfterCommit(f func()) {
+	if m.committed == nil {
+		f()
+		return
 	}
+	*m.committed = append(*m.committed, f)
 }
@@ -0,0 +1,139 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LivestockLossCategory is the expense category the value of dead and culled
+// animals is booked under in the finance ledger
+const LivestockLossCategory = "Livestock Losses"
+
+// MortalityRecord represents the mortality_records table in the database:
+// animals of a herd that died or were culled. The animals leave the herd's
+// count, and the value lost is carried into the finance ledger as an
+// expense.
+type MortalityRecord struct {
+	ID                uint           `gorm:"primaryKey" json:"-"`
+	MortalityRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"mortalityRecordId"`
+	FarmID            string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	LivestockID       string         `gorm:"not null;size:36;index" json:"livestockId"` // Herd the animals were in
+	AnimalTag         string         `json:"animalTag"`                                 // The animal, when a single one was lost
+	Kind              string         `gorm:"not null" json:"kind"`                      // Death, Cull
+	Count             int            `gorm:"not null;default:1" json:"count"`
+	Date              time.Time      `gorm:"not null;index" json:"date"`
+	Cause             string         `gorm:"not null" json:"cause"`               // e.g. Disease, Predator, Injury, Old Age, Poor Production
+	ValueLost         float64        `gorm:"not null;default:0" json:"valueLost"` // Worth of the animals lost, in total
+	Notes             string         `json:"notes"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Livestock *Livestock `gorm:"foreignKey:LivestockID;references:LivestockID" json:"livestock,omitempty"`
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries this record's value lost into the finance ledger
+func (m *MortalityRecord) TransactionReference() string {
+	return fmt.Sprintf("mortality_record:%s", m.MortalityRecordID)
+}
+
+// MortalityRecordInterface defines the contract for mortality record operations
+type MortalityRecordInterface interface {
+	GetByMortalityRecordID(ctx context.Context, mortalityRecordID string) (*MortalityRecord, error)
+	// GetByFarmID returns a farm's losses, optionally only those of a herd
+	// and dated in [from, to), most recent first
+	GetByFarmID(ctx context.Context, farmID, livestockID string, from, to *time.Time) ([]*MortalityRecord, error)
+	Insert(ctx context.Context, record *MortalityRecord) error
+	Update(ctx context.Context, record *MortalityRecord) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// MortalityRecordRepo implements MortalityRecordInterface using GORM.
+type MortalityRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewMortalityRecordRepo creates a new instance of MortalityRecordRepo.
+func NewMortalityRecordRepo(db *gorm.DB) MortalityRecordInterface {
+	return &MortalityRecordRepo{DB: db}
+}
+
+// GetByMortalityRecordID retrieves a loss by its MortalityRecordID (UUID)
+func (m *MortalityRecordRepo) GetByMortalityRecordID(ctx context.Context, mortalityRecordID string) (*MortalityRecord, error) {
+	var record MortalityRecord
+	result := m.DB.WithContext(ctx).Preload("Livestock").Where("mortality_record_id = ?", mortalityRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's losses, most recent first
+func (m *MortalityRecordRepo) GetByFarmID(ctx context.Context, farmID, livestockID string, from, to *time.Time) ([]*MortalityRecord, error) {
+	var records []*MortalityRecord
+	query := m.DB.WithContext(ctx).Preload("Livestock").Where("farm_id = ?", farmID)
+	if livestockID != "" {
+		query = query.Where("livestock_id = ?", livestockID)
+	}
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc, id desc").Find(&records)
+	return records, result.Error
+}
+
+// Insert creates a new loss and, when it has a value lost, the matching
+// expense in the finance ledger, in a single transaction
+func (m *MortalityRecordRepo) Insert(ctx context.Context, record *MortalityRecord) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Livestock").Create(record).Error; err != nil {
+			return err
+		}
+		return syncExpense(tx, record.TransactionReference(), record.expense())
+	})
+}
+
+// Update saves a loss and brings its expense in line with it, in a single
+// transaction
+func (m *MortalityRecordRepo) Update(ctx context.Context, record *MortalityRecord) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Livestock").Save(record).Error; err != nil {
+			return err
+		}
+		return syncExpense(tx, record.TransactionReference(), record.expense())
+	})
+}
+
+// DeleteByID soft deletes a loss by its ID along with its expense transaction
+func (m *MortalityRecordRepo) DeleteByID(ctx context.Context, id int) error {
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record MortalityRecord
+		if err := tx.Where("id = ?", id).First(&record).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reference = ?", record.TransactionReference()).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+}
+
+// expense is the ledger entry for the record's value lost
+func (m *MortalityRecord) expense() Transaction {
+	return Transaction{
+		FarmID:      m.FarmID,
+		Category:    LivestockLossCategory,
+		Amount:      m.ValueLost,
+		Date:        m.Date,
+		Description: fmt.Sprintf("%s of %d: %s", m.Kind, m.Count, m.Cause),
+	}
+}
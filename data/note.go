@@ -0,0 +1,80 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Note represents the notes table in the database: a dated observation a
+// user writes on one of a farm's records, such as "leaves yellowing on the
+// east side" on a crop. A record keeps any number of notes, unlike its single
+// Notes column.
+type Note struct {
+	ID         uint           `gorm:"primaryKey" json:"-"`
+	NoteID     string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"noteId"`
+	FarmID     string         `gorm:"not null;size:36;index" json:"farmId"`             // Foreign key to Farm
+	AuthorID   string         `gorm:"not null;size:36" json:"authorId"`                 // User who wrote the note
+	RecordType string         `gorm:"not null;index:idx_note_record" json:"recordType"` // crop, livestock, equipment, field, employee, maintenance, incident
+	RecordID   string         `gorm:"not null;size:36;index:idx_note_record" json:"recordId"`
+	Body       string         `gorm:"not null" json:"body"`
+	NotedAt    time.Time      `gorm:"not null" json:"notedAt"` // When the observation was made
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// NoteInterface defines the contract for note operations
+type NoteInterface interface {
+	GetByNoteID(ctx context.Context, noteID string) (*Note, error)
+	// GetByRecord retrieves the notes on a record, newest first
+	GetByRecord(ctx context.Context, recordType, recordID string) ([]*Note, error)
+	Insert(ctx context.Context, note *Note) error
+	Update(ctx context.Context, note *Note) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// NoteRepo implements NoteInterface using GORM.
+type NoteRepo struct {
+	DB *gorm.DB
+}
+
+// NewNoteRepo creates a new instance of NoteRepo.
+func NewNoteRepo(db *gorm.DB) NoteInterface {
+	return &NoteRepo{DB: db}
+}
+
+// GetByNoteID retrieves a note by its NoteID (UUID)
+func (n *NoteRepo) GetByNoteID(ctx context.Context, noteID string) (*Note, error) {
+	var note Note
+	result := n.DB.WithContext(ctx).Where("note_id = ?", noteID).First(&note)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &note, result.Error
+}
+
+// GetByRecord retrieves the notes on a record
+func (n *NoteRepo) GetByRecord(ctx context.Context, recordType, recordID string) ([]*Note, error) {
+	var notes []*Note
+	result := n.DB.WithContext(ctx).Where("record_type = ? AND record_id = ?", recordType, recordID).
+		Order("noted_at DESC, id DESC").Find(&notes)
+	return notes, result.Error
+}
+
+// Insert creates a new note in the database
+func (n *NoteRepo) Insert(ctx context.Context, note *Note) error {
+	return n.DB.WithContext(ctx).Create(note).Error
+}
+
+// Update updates an existing note in the database
+func (n *NoteRepo) Update(ctx context.Context, note *Note) error {
+	return n.DB.WithContext(ctx).Save(note).Error
+}
+
+// DeleteByID soft deletes a note by its ID
+func (n *NoteRepo) DeleteByID(ctx context.Context, id int) error {
+	return n.DB.WithContext(ctx).Delete(&Note{}, id).Error
+}
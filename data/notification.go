@@ -0,0 +1,98 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification represents the notifications table in the database.
+type Notification struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	NotificationID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"notificationId"`
+	UserID         string         `gorm:"not null;size:36;index" json:"userId"` // Recipient, foreign key to User
+	FarmID         *string        `gorm:"size:36" json:"farmId,omitempty"`      // Optional farm the notification relates to
+	Type           string         `gorm:"not null" json:"type"`                 // e.g. inventory_expiring, inventory_low_stock
+	Title          string         `gorm:"not null" json:"title"`
+	Message        string         `json:"message"`
+	Reference      string         `gorm:"index" json:"reference,omitempty"` // Identifies the source event, used to avoid duplicates
+	ReadAt         *time.Time     `json:"readAt"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// NotificationInterface defines the contract for notification operations
+type NotificationInterface interface {
+	GetByNotificationID(ctx context.Context, notificationID string) (*Notification, error)
+	GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*Notification, error)
+	ExistsByReference(ctx context.Context, userID, reference string) (bool, error)
+	Insert(ctx context.Context, notification *Notification) error
+	MarkRead(ctx context.Context, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// NotificationRepo implements NotificationInterface using GORM.
+type NotificationRepo struct {
+	DB *gorm.DB
+}
+
+// NewNotificationRepo creates a new instance of NotificationRepo.
+func NewNotificationRepo(db *gorm.DB) NotificationInterface {
+	return &NotificationRepo{DB: db}
+}
+
+// GetByNotificationID retrieves a notification by its NotificationID (UUID)
+func (n *NotificationRepo) GetByNotificationID(ctx context.Context, notificationID string) (*Notification, error) {
+	var notification Notification
+	result := n.DB.WithContext(ctx).Where("notification_id = ?", notificationID).First(&notification)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &notification, result.Error
+}
+
+// GetByUserID retrieves a user's notifications, newest first
+func (n *NotificationRepo) GetByUserID(ctx context.Context, userID string, unreadOnly bool) ([]*Notification, error) {
+	var notifications []*Notification
+	query := n.DB.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	result := query.Order("created_at desc").Find(&notifications)
+	return notifications, result.Error
+}
+
+// ExistsByReference reports whether the user already has a notification for the reference
+func (n *NotificationRepo) ExistsByReference(ctx context.Context, userID, reference string) (bool, error) {
+	var count int64
+	result := n.DB.WithContext(ctx).Model(&Notification{}).Where("user_id = ? AND reference = ?", userID, reference).Count(&count)
+	return count > 0, result.Error
+}
+
+// Insert creates a new notification in the database
+func (n *NotificationRepo) Insert(ctx context.Context, notification *Notification) error {
+	return n.DB.WithContext(ctx).Create(notification).Error
+}
+
+// MarkRead marks a single notification as read
+func (n *NotificationRepo) MarkRead(ctx context.Context, notificationID string) error {
+	return n.DB.WithContext(ctx).Model(&Notification{}).
+		Where("notification_id = ? AND read_at IS NULL", notificationID).
+		Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead marks all of a user's notifications as read
+func (n *NotificationRepo) MarkAllRead(ctx context.Context, userID string) error {
+	return n.DB.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// DeleteByID soft deletes a notification by its ID
+func (n *NotificationRepo) DeleteByID(ctx context.Context, id int) error {
+	return n.DB.WithContext(ctx).Delete(&Notification{}, id).Error
+}
@@ -0,0 +1,144 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization represents the organizations table in the database: a
+// cooperative or farmer group whose members buy inputs together.
+type Organization struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	OrganizationID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"organizationId"`
+	Name           string         `gorm:"not null" json:"name"`
+	Description    string         `json:"description"`
+	CreatedBy      string         `gorm:"not null;size:36" json:"createdBy"` // User who set the organization up
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Members []OrganizationMember `gorm:"foreignKey:OrganizationID;references:OrganizationID" json:"members,omitempty"`
+}
+
+// OrganizationMember represents the organization_members table in the
+// database: a user who belongs to an organization, as an Admin who runs it
+// or as a Member farmer
+type OrganizationMember struct {
+	ID                   uint           `gorm:"primaryKey" json:"-"`
+	OrganizationMemberID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"memberId"`
+	OrganizationID       string         `gorm:"not null;size:36;uniqueIndex:idx_organization_member,where:deleted_at IS NULL" json:"organizationId"` // Foreign key to Organization
+	UserID               string         `gorm:"not null;size:36;uniqueIndex:idx_organization_member,where:deleted_at IS NULL;index" json:"userId"`
+	Role                 string         `gorm:"not null;default:'Member'" json:"role"` // Admin, Member
+	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID;references:UserID" json:"user,omitempty"`
+}
+
+// OrganizationInterface defines the contract for organization and
+// organization member operations
+type OrganizationInterface interface {
+	GetByOrganizationID(ctx context.Context, organizationID string) (*Organization, error)
+	// GetByUserID returns the organizations a user is a member of
+	GetByUserID(ctx context.Context, userID string) ([]*Organization, error)
+	// Insert creates an organization with its founding admin
+	Insert(ctx context.Context, organization *Organization, admin *OrganizationMember) error
+	Update(ctx context.Context, organization *Organization) error
+	// GetMember returns a user's membership of an organization, or nil
+	GetMember(ctx context.Context, organizationID, userID string) (*OrganizationMember, error)
+	GetMemberByID(ctx context.Context, organizationMemberID string) (*OrganizationMember, error)
+	// GetMembers returns an organization's members with their users
+	GetMembers(ctx context.Context, organizationID string) ([]*OrganizationMember, error)
+	InsertMember(ctx context.Context, member *OrganizationMember) error
+	DeleteMemberByID(ctx context.Context, id int) error
+}
+
+// OrganizationRepo implements OrganizationInterface using GORM.
+type OrganizationRepo struct {
+	DB *gorm.DB
+}
+
+// NewOrganizationRepo creates a new instance of OrganizationRepo.
+func NewOrganizationRepo(db *gorm.DB) OrganizationInterface {
+	return &OrganizationRepo{DB: db}
+}
+
+// GetByOrganizationID retrieves an organization by its OrganizationID (UUID)
+func (o *OrganizationRepo) GetByOrganizationID(ctx context.Context, organizationID string) (*Organization, error) {
+	var organization Organization
+	result := o.DB.WithContext(ctx).Where("organization_id = ?", organizationID).First(&organization)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &organization, result.Error
+}
+
+// GetByUserID retrieves the organizations a user is a member of, by name
+func (o *OrganizationRepo) GetByUserID(ctx context.Context, userID string) ([]*Organization, error) {
+	var organizations []*Organization
+	result := o.DB.WithContext(ctx).Where("organization_id IN (?)",
+		o.DB.WithContext(ctx).Model(&OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)).
+		Order("name").Find(&organizations)
+	return organizations, result.Error
+}
+
+// Insert creates an organization and its founding admin in a single
+// transaction
+func (o *OrganizationRepo) Insert(ctx context.Context, organization *Organization, admin *OrganizationMember) error {
+	return o.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(organization).Error; err != nil {
+			return err
+		}
+		admin.OrganizationID = organization.OrganizationID
+		return tx.Omit("User").Create(admin).Error
+	})
+}
+
+// Update saves an organization
+func (o *OrganizationRepo) Update(ctx context.Context, organization *Organization) error {
+	return o.DB.WithContext(ctx).Omit("Members").Save(organization).Error
+}
+
+// GetMember retrieves a user's membership of an organization
+func (o *OrganizationRepo) GetMember(ctx context.Context, organizationID, userID string) (*OrganizationMember, error) {
+	var member OrganizationMember
+	result := o.DB.WithContext(ctx).Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&member)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &member, result.Error
+}
+
+// GetMemberByID retrieves a membership by its OrganizationMemberID (UUID)
+func (o *OrganizationRepo) GetMemberByID(ctx context.Context, organizationMemberID string) (*OrganizationMember, error) {
+	var member OrganizationMember
+	result := o.DB.WithContext(ctx).Where("organization_member_id = ?", organizationMemberID).First(&member)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &member, result.Error
+}
+
+// GetMembers retrieves an organization's members with their users, oldest
+// first
+func (o *OrganizationRepo) GetMembers(ctx context.Context, organizationID string) ([]*OrganizationMember, error) {
+	var members []*OrganizationMember
+	result := o.DB.WithContext(ctx).Preload("User").Where("organization_id = ?", organizationID).Order("created_at").Find(&members)
+	return members, result.Error
+}
+
+// InsertMember adds a member to an organization
+func (o *OrganizationRepo) InsertMember(ctx context.Context, member *OrganizationMember) error {
+	return o.DB.WithContext(ctx).Omit("User").Create(member).Error
+}
+
+// DeleteMemberByID soft deletes a membership by its ID
+func (o *OrganizationRepo) DeleteMemberByID(ctx context.Context, id int) error {
+	return o.DB.WithContext(ctx).Delete(&OrganizationMember{}, id).Error
+}
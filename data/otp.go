@@ -0,0 +1,38 @@
+package data
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// OTPTTL is how long a one-time code stays valid
+const OTPTTL = 15 * time.Minute
+
+// MaxOTPAttempts is how many wrong guesses a one-time code survives; it is
+// invalidated once they are used up
+const MaxOTPAttempts = 5
+
+// newOTP generates a random 6-digit one-time code and the hash of it to
+// store. Codes are only ever stored hashed.
+func newOTP() (code, hash string, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", "", err
+	}
+	code = fmt.Sprintf("%06d", n.Int64())
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", err
+	}
+	return code, string(hashed), nil
+}
+
+// otpMatches reports whether code is the one hash was made from
+func otpMatches(hash, code string) bool {
+	return hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(code)) == nil
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Paddock represents the paddocks table in the database: a grazing area that
+// herds are rotated through. RestDays is how long it must rest between
+// grazings to regrow; MaxGrazeDays how long a herd may stay on it at a time.
+type Paddock struct {
+	ID           uint           `gorm:"primaryKey" json:"-"`
+	PaddockID    string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"paddockId"`
+	FarmID       string         `gorm:"not null;size:36;index" json:"farmId"`   // Foreign key to Farm
+	FieldID      *string        `gorm:"size:36;index" json:"fieldId,omitempty"` // Optional foreign key to Field
+	Name         string         `gorm:"not null" json:"name"`
+	Area         float64        `gorm:"not null" json:"area"` // Same unit as the farm size
+	RestDays     int            `gorm:"not null" json:"restDays"`
+	MaxGrazeDays int            `gorm:"not null" json:"maxGrazeDays"`
+	Notes        string         `json:"notes"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm  *Farm  `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Field *Field `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+}
+
+// PaddockInterface defines the contract for paddock operations
+type PaddockInterface interface {
+	GetByPaddockID(ctx context.Context, paddockID string) (*Paddock, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Paddock, error)
+	Insert(ctx context.Context, paddock *Paddock) error
+	Update(ctx context.Context, paddock *Paddock) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// PaddockRepo implements PaddockInterface using GORM.
+type PaddockRepo struct {
+	DB *gorm.DB
+}
+
+// NewPaddockRepo creates a new instance of PaddockRepo.
+func NewPaddockRepo(db *gorm.DB) PaddockInterface {
+	return &PaddockRepo{DB: db}
+}
+
+// GetByPaddockID retrieves a paddock by its PaddockID (UUID)
+func (p *PaddockRepo) GetByPaddockID(ctx context.Context, paddockID string) (*Paddock, error) {
+	var paddock Paddock
+	result := p.DB.WithContext(ctx).Where("paddock_id = ?", paddockID).First(&paddock)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &paddock, result.Error
+}
+
+// GetByFarmID retrieves a farm's paddocks, by name
+func (p *PaddockRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Paddock, error) {
+	var paddocks []*Paddock
+	result := p.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("name").Find(&paddocks)
+	return paddocks, result.Error
+}
+
+// Insert adds a new paddock
+func (p *PaddockRepo) Insert(ctx context.Context, paddock *Paddock) error {
+	return p.DB.WithContext(ctx).Omit("Field").Create(paddock).Error
+}
+
+// Update saves a paddock
+func (p *PaddockRepo) Update(ctx context.Context, paddock *Paddock) error {
+	return p.DB.WithContext(ctx).Omit("Field").Save(paddock).Error
+}
+
+// DeleteByID soft deletes a paddock by its ID
+func (p *PaddockRepo) DeleteByID(ctx context.Context, id int) error {
+	return p.DB.WithContext(ctx).Delete(&Paddock{}, id).Error
+}
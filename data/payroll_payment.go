@@ -0,0 +1,158 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PayrollPayment represents the payroll_payments table in the database. Each
+// payment covers one employee for one pay period.
+type PayrollPayment struct {
+	ID               uint           `gorm:"primaryKey" json:"-"`
+	PayrollPaymentID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"paymentId"`
+	EmployeeID       string         `gorm:"not null;size:36;index" json:"employeeId"` // Foreign key to Employee
+	FarmID           string         `gorm:"not null;size:36;index" json:"farmId"`     // Foreign key to Farm
+	PeriodStart      time.Time      `gorm:"not null" json:"periodStart"`
+	PeriodEnd        time.Time      `gorm:"not null" json:"periodEnd"`
+	GrossPay         float64        `gorm:"not null" json:"grossPay"`
+	Deductions       float64        `json:"deductions"` // Tax, social security, advances, etc.
+	NetPay           float64        `gorm:"not null" json:"netPay"`
+	PaymentDate      time.Time      `gorm:"not null;index" json:"paymentDate"`
+	PaymentMethod    string         `gorm:"not null" json:"paymentMethod"` // Cash, Bank Transfer, Mobile Money, Cheque
+	PaymentReference string         `json:"paymentReference"`              // Bank or mobile money transaction reference
+	Notes            string         `json:"notes"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Employee *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries this payment into the finance ledger
+func (p *PayrollPayment) TransactionReference() string {
+	return fmt.Sprintf("payroll_payment:%s", p.PayrollPaymentID)
+}
+
+// MonthlyPayrollTotal is a farm's payroll for one month, by payment date
+type MonthlyPayrollTotal struct {
+	Month      string  `json:"month"` // YYYY-MM
+	Payments   int     `json:"payments"`
+	Employees  int     `json:"employees"`
+	GrossPay   float64 `json:"grossPay"`
+	Deductions float64 `json:"deductions"`
+	NetPay     float64 `json:"netPay"`
+}
+
+// PayrollPaymentInterface defines the contract for payroll payment operations
+type PayrollPaymentInterface interface {
+	GetByPayrollPaymentID(ctx context.Context, payrollPaymentID string) (*PayrollPayment, error)
+	GetByEmployeeID(ctx context.Context, employeeID string, from, to *time.Time) ([]*PayrollPayment, error)
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*PayrollPayment, error)
+	MonthlyTotals(ctx context.Context, farmID string, from, to time.Time) ([]MonthlyPayrollTotal, error)
+	Insert(ctx context.Context, payment *PayrollPayment) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// PayrollPaymentRepo implements PayrollPaymentInterface using GORM.
+type PayrollPaymentRepo struct {
+	DB *gorm.DB
+}
+
+// NewPayrollPaymentRepo creates a new instance of PayrollPaymentRepo.
+func NewPayrollPaymentRepo(db *gorm.DB) PayrollPaymentInterface {
+	return &PayrollPaymentRepo{DB: db}
+}
+
+// GetByPayrollPaymentID retrieves a payment by its PayrollPaymentID (UUID)
+func (p *PayrollPaymentRepo) GetByPayrollPaymentID(ctx context.Context, payrollPaymentID string) (*PayrollPayment, error) {
+	var payment PayrollPayment
+	result := p.DB.WithContext(ctx).Preload("Employee").Where("payroll_payment_id = ?", payrollPaymentID).First(&payment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &payment, result.Error
+}
+
+// GetByEmployeeID retrieves an employee's payments, optionally limited to
+// payment dates in [from, to)
+func (p *PayrollPaymentRepo) GetByEmployeeID(ctx context.Context, employeeID string, from, to *time.Time) ([]*PayrollPayment, error) {
+	var payments []*PayrollPayment
+	query := p.DB.WithContext(ctx).Where("employee_id = ?", employeeID)
+	if from != nil {
+		query = query.Where("payment_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("payment_date < ?", *to)
+	}
+	result := query.Order("payment_date desc").Find(&payments)
+	return payments, result.Error
+}
+
+// GetByFarmID retrieves a farm's payments with their employees, optionally
+// limited to payment dates in [from, to)
+func (p *PayrollPaymentRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*PayrollPayment, error) {
+	var payments []*PayrollPayment
+	query := p.DB.WithContext(ctx).Preload("Employee").Where("farm_id = ?", farmID)
+	if from != nil {
+		query = query.Where("payment_date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("payment_date < ?", *to)
+	}
+	result := query.Order("payment_date desc").Find(&payments)
+	return payments, result.Error
+}
+
+// MonthlyTotals sums a farm's payroll per month of payment date in [from, to)
+func (p *PayrollPaymentRepo) MonthlyTotals(ctx context.Context, farmID string, from, to time.Time) ([]MonthlyPayrollTotal, error) {
+	var totals []MonthlyPayrollTotal
+	result := p.DB.WithContext(ctx).Model(&PayrollPayment{}).
+		Select("to_char(payment_date, 'YYYY-MM') AS month, COUNT(*) AS payments, COUNT(DISTINCT employee_id) AS employees, SUM(gross_pay) AS gross_pay, SUM(deductions) AS deductions, SUM(net_pay) AS net_pay").
+		Where("farm_id = ? AND payment_date >= ? AND payment_date < ?", farmID, from, to).
+		Group("month").
+		Order("month").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// Insert creates a new payment and the matching Labour expense in the finance
+// ledger, in a single transaction. The expense is the gross pay, as
+// deductions are still a cost to the farm.
+func (p *PayrollPaymentRepo) Insert(ctx context.Context, payment *PayrollPayment) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Employee").Create(payment).Error; err != nil {
+			return err
+		}
+		description := "Payroll " + payment.PeriodStart.Format("2006-01-02") + " to " + payment.PeriodEnd.Format("2006-01-02")
+		if payment.Employee != nil {
+			description = fmt.Sprintf("%s: %s %s", description, payment.Employee.FirstName, payment.Employee.LastName)
+		}
+		return syncExpense(tx, payment.TransactionReference(), Transaction{
+			FarmID:      payment.FarmID,
+			Category:    "Labour",
+			Amount:      payment.GrossPay,
+			Date:        payment.PaymentDate,
+			Description: description,
+		})
+	})
+}
+
+// DeleteByID soft deletes a payment by its ID along with its expense transaction
+func (p *PayrollPaymentRepo) DeleteByID(ctx context.Context, id int) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var payment PayrollPayment
+		if err := tx.Where("id = ?", id).First(&payment).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reference = ?", payment.TransactionReference()).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&payment).Error
+	})
+}
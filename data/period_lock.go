@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PeriodLock represents the period_locks table in the database. While a lock
+// is active (not unlocked) the farm's records dated within the period cannot
+// be created, changed or deleted. PeriodStart and PeriodEnd are whole days,
+// both inclusive.
+type PeriodLock struct {
+	ID           uint       `gorm:"primaryKey" json:"-"`
+	PeriodLockID string     `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"periodLockId"`
+	FarmID       string     `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	PeriodStart  time.Time  `gorm:"not null" json:"periodStart"`
+	PeriodEnd    time.Time  `gorm:"not null" json:"periodEnd"`
+	Reason       string     `json:"reason"`                           // e.g. "Q1 report submitted to lender"
+	LockedBy     string     `gorm:"not null;size:36" json:"lockedBy"` // UserID
+	UnlockedBy   *string    `gorm:"size:36" json:"unlockedBy,omitempty"`
+	UnlockedAt   *time.Time `json:"unlockedAt,omitempty"`
+	UnlockReason string     `json:"unlockReason,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updatedAt"`
+}
+
+// Active reports whether the lock is still in force
+func (p *PeriodLock) Active() bool {
+	return p.UnlockedAt == nil
+}
+
+// PeriodLockInterface defines the contract for period lock operations
+type PeriodLockInterface interface {
+	GetByPeriodLockID(ctx context.Context, periodLockID string) (*PeriodLock, error)
+	GetByFarmID(ctx context.Context, farmID string, includeUnlocked bool) ([]*PeriodLock, error)
+	// GetActiveCovering returns the active lock covering day, if any
+	GetActiveCovering(ctx context.Context, farmID string, day time.Time) (*PeriodLock, error)
+	// GetActiveOverlapping returns an active lock overlapping [start, end], if any
+	GetActiveOverlapping(ctx context.Context, farmID string, start, end time.Time) (*PeriodLock, error)
+	Insert(ctx context.Context, lock *PeriodLock) error
+	Update(ctx context.Context, lock *PeriodLock) error
+}
+
+// PeriodLockRepo implements PeriodLockInterface using GORM.
+type PeriodLockRepo struct {
+	DB *gorm.DB
+}
+
+// NewPeriodLockRepo creates a new instance of PeriodLockRepo.
+func NewPeriodLockRepo(db *gorm.DB) PeriodLockInterface {
+	return &PeriodLockRepo{DB: db}
+}
+
+// GetByPeriodLockID retrieves a lock by its PeriodLockID (UUID)
+func (p *PeriodLockRepo) GetByPeriodLockID(ctx context.Context, periodLockID string) (*PeriodLock, error) {
+	var lock PeriodLock
+	result := p.DB.WithContext(ctx).Where("period_lock_id = ?", periodLockID).First(&lock)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &lock, result.Error
+}
+
+// GetByFarmID retrieves a farm's locks, latest period first
+func (p *PeriodLockRepo) GetByFarmID(ctx context.Context, farmID string, includeUnlocked bool) ([]*PeriodLock, error) {
+	var locks []*PeriodLock
+	query := p.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if !includeUnlocked {
+		query = query.Where("unlocked_at IS NULL")
+	}
+	result := query.Order("period_start desc").Find(&locks)
+	return locks, result.Error
+}
+
+// GetActiveCovering retrieves the active lock whose period includes day
+func (p *PeriodLockRepo) GetActiveCovering(ctx context.Context, farmID string, day time.Time) (*PeriodLock, error) {
+	return p.GetActiveOverlapping(ctx, farmID, day, day)
+}
+
+// GetActiveOverlapping retrieves an active lock whose period overlaps [start, end]
+func (p *PeriodLockRepo) GetActiveOverlapping(ctx context.Context, farmID string, start, end time.Time) (*PeriodLock, error) {
+	var lock PeriodLock
+	result := p.DB.WithContext(ctx).Where("farm_id = ? AND unlocked_at IS NULL AND period_start <= ? AND period_end >= ?", farmID, end, start).
+		Order("period_start").
+		First(&lock)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &lock, result.Error
+}
+
+// Insert creates a new lock in the database
+func (p *PeriodLockRepo) Insert(ctx context.Context, lock *PeriodLock) error {
+	return p.DB.WithContext(ctx).Create(lock).Error
+}
+
+// Update updates an existing lock in the database
+func (p *PeriodLockRepo) Update(ctx context.Context, lock *PeriodLock) error {
+	return p.DB.WithContext(ctx).Save(lock).Error
+}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PhoneLogin represents the phone_logins table in the database: a pending
+// passwordless login, for which a one-time code was sent by SMS. A new
+// request replaces the pending one for the same number.
+type PhoneLogin struct {
+	ID           uint       `gorm:"primaryKey" json:"-"`
+	PhoneLoginID string     `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"phoneLoginId"`
+	PhoneNumber  string     `gorm:"not null;index" json:"phoneNumber"` // Normalized, e.g. +256772123456
+	UserID       string     `gorm:"not null;size:36;index" json:"userId"`
+	CodeHash     string     `gorm:"not null" json:"-"`
+	Attempts     int        `gorm:"not null;default:0" json:"attempts"` // Wrong codes tried so far
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expiresAt"`
+	UsedAt       *time.Time `json:"usedAt,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// PhoneLoginInterface defines the contract for phone login operations
+type PhoneLoginInterface interface {
+	// Issue starts a login for the user by phone number, replacing any
+	// pending one, and returns its code. Only the code's hash is stored.
+	Issue(ctx context.Context, phoneNumber, userID string) (string, error)
+	// Verify checks a code against the pending login for the phone number
+	// and, if it matches, uses the login up and returns it. It returns nil
+	// when there is no pending login or the code is wrong; every wrong code
+	// counts against MaxOTPAttempts.
+	Verify(ctx context.Context, phoneNumber, code string) (*PhoneLogin, error)
+	// DeleteExpired removes logins that expired before the given time and
+	// returns how many there were
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PhoneLoginRepo implements PhoneLoginInterface using GORM.
+type PhoneLoginRepo struct {
+	DB *gorm.DB
+}
+
+// NewPhoneLoginRepo creates a new instance of PhoneLoginRepo.
+func NewPhoneLoginRepo(db *gorm.DB) PhoneLoginInterface {
+	return &PhoneLoginRepo{DB: db}
+}
+
+// Issue starts a login for the user by phone number
+func (p *PhoneLoginRepo) Issue(ctx context.Context, phoneNumber, userID string) (string, error) {
+	code, hash, err := newOTP()
+	if err != nil {
+		return "", err
+	}
+
+	err = p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("phone_number = ? AND used_at IS NULL", phoneNumber).Delete(&PhoneLogin{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&PhoneLogin{
+			PhoneNumber: phoneNumber,
+			UserID:      userID,
+			CodeHash:    hash,
+			ExpiresAt:   time.Now().Add(OTPTTL),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// Verify checks a code against the pending login for the phone number
+func (p *PhoneLoginRepo) Verify(ctx context.Context, phoneNumber, code string) (*PhoneLogin, error) {
+	db := p.DB.WithContext(ctx)
+	pending := db.Where("phone_number = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?", phoneNumber, time.Now(), MaxOTPAttempts)
+
+	var login PhoneLogin
+	if err := pending.First(&login).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// Count the attempt atomically, so concurrent guesses cannot get past
+	// the limit
+	result := db.Model(&PhoneLogin{}).Where("id = ? AND used_at IS NULL AND attempts < ?", login.ID, MaxOTPAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || !otpMatches(login.CodeHash, code) {
+		return nil, nil
+	}
+
+	now := time.Now()
+	result = db.Model(&PhoneLogin{}).Where("id = ? AND used_at IS NULL", login.ID).Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// Used by a concurrent request
+		return nil, nil
+	}
+	login.UsedAt = &now
+	return &login, nil
+}
+
+// DeleteExpired removes logins that expired before the given time
+func (p *PhoneLoginRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := p.DB.WithContext(ctx).Where("expires_at < ?", before).Delete(&PhoneLogin{})
+	return result.RowsAffected, result.Error
+}
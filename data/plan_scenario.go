@@ -0,0 +1,121 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanScenario represents the plan_scenarios table in the database: a named
+// set of what-if assumptions about a crop plan's price, yield and costs. The
+// projection is worked out from the plan as it stands whenever the scenario
+// is read.
+type PlanScenario struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	PlanScenarioID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"scenarioId"`
+	CropPlanID      string         `gorm:"not null;size:36;index" json:"planId"` // Foreign key to CropPlan
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name            string         `gorm:"not null" json:"name"`
+	PricePerKg      float64        `gorm:"not null" json:"pricePerKg"`
+	ExpectedYield   *float64       `json:"expectedYield,omitempty"` // Kg; the plan's expected yield when not set
+	InputCostChange float64        `json:"inputCostChange"`         // Percent change to the plan's budgeted inputs, e.g. 10 or -5
+	OtherCosts      float64        `json:"otherCosts"`              // Costs outside the input budget, e.g. labour or transport
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Projection is filled in by the crop service
+	Projection *ScenarioProjection `gorm:"-" json:"projection,omitempty"`
+}
+
+// ScenarioProjection is the projected outcome of a scenario
+type ScenarioProjection struct {
+	Yield          float64  `json:"yield"`
+	Revenue        float64  `json:"revenue"`
+	InputCosts     float64  `json:"inputCosts"`
+	TotalCosts     float64  `json:"totalCosts"`
+	Profit         float64  `json:"profit"`
+	MarginPercent  *float64 `json:"marginPercent,omitempty"`  // Profit as a share of revenue, when there is revenue
+	BreakEvenPrice *float64 `json:"breakEvenPrice,omitempty"` // Price per kg covering the costs, when there is a yield
+	BreakEvenYield *float64 `json:"breakEvenYield,omitempty"` // Kg covering the costs, when there is a price
+}
+
+// Project works out the scenario's outcome for plan
+func (s *PlanScenario) Project(plan *CropPlan) *ScenarioProjection {
+	p := &ScenarioProjection{Yield: plan.ExpectedYield}
+	if s.ExpectedYield != nil {
+		p.Yield = *s.ExpectedYield
+	}
+	p.Revenue = p.Yield * s.PricePerKg
+	p.InputCosts = plan.BudgetedCost * (1 + s.InputCostChange/100)
+	p.TotalCosts = p.InputCosts + s.OtherCosts
+	p.Profit = p.Revenue - p.TotalCosts
+
+	if p.Revenue > 0 {
+		margin := p.Profit / p.Revenue * 100
+		p.MarginPercent = &margin
+	}
+	if p.Yield > 0 {
+		price := p.TotalCosts / p.Yield
+		p.BreakEvenPrice = &price
+	}
+	if s.PricePerKg > 0 {
+		yield := p.TotalCosts / s.PricePerKg
+		p.BreakEvenYield = &yield
+	}
+	return p
+}
+
+// PlanScenarioInterface defines the contract for plan scenario operations
+type PlanScenarioInterface interface {
+	GetByPlanScenarioID(ctx context.Context, planScenarioID string) (*PlanScenario, error)
+	GetByCropPlanID(ctx context.Context, cropPlanID string) ([]*PlanScenario, error)
+	Insert(ctx context.Context, scenario *PlanScenario) error
+	Update(ctx context.Context, scenario *PlanScenario) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// PlanScenarioRepo implements PlanScenarioInterface using GORM.
+type PlanScenarioRepo struct {
+	DB *gorm.DB
+}
+
+// NewPlanScenarioRepo creates a new instance of PlanScenarioRepo.
+func NewPlanScenarioRepo(db *gorm.DB) PlanScenarioInterface {
+	return &PlanScenarioRepo{DB: db}
+}
+
+// GetByPlanScenarioID retrieves a scenario by its PlanScenarioID (UUID)
+func (p *PlanScenarioRepo) GetByPlanScenarioID(ctx context.Context, planScenarioID string) (*PlanScenario, error) {
+	var scenario PlanScenario
+	result := p.DB.WithContext(ctx).Where("plan_scenario_id = ?", planScenarioID).First(&scenario)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &scenario, result.Error
+}
+
+// GetByCropPlanID retrieves a plan's scenarios in the order they were created
+func (p *PlanScenarioRepo) GetByCropPlanID(ctx context.Context, cropPlanID string) ([]*PlanScenario, error) {
+	var scenarios []*PlanScenario
+	result := p.DB.WithContext(ctx).Where("crop_plan_id = ?", cropPlanID).Order("created_at").Find(&scenarios)
+	return scenarios, result.Error
+}
+
+// Insert creates a new scenario in the database
+func (p *PlanScenarioRepo) Insert(ctx context.Context, scenario *PlanScenario) error {
+	return p.DB.WithContext(ctx).Create(scenario).Error
+}
+
+// Update updates an existing scenario in the database
+func (p *PlanScenarioRepo) Update(ctx context.Context, scenario *PlanScenario) error {
+	return p.DB.WithContext(ctx).Save(scenario).Error
+}
+
+// DeleteByID soft deletes a scenario by its ID
+func (p *PlanScenarioRepo) DeleteByID(ctx context.Context, id int) error {
+	return p.DB.WithContext(ctx).Delete(&PlanScenario{}, id).Error
+}
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProcurementOrder represents the procurement_orders table in the database:
+// an organization's consolidated order with one supplier, made from its
+// members' requests in a window. Each request allocated to the order records
+// how much of it goes to which member.
+type ProcurementOrder struct {
+	ID                  uint                   `gorm:"primaryKey" json:"-"`
+	ProcurementOrderID  string                 `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"orderId"`
+	ProcurementWindowID string                 `gorm:"not null;size:36;index" json:"windowId"` // Foreign key to ProcurementWindow
+	OrganizationID      string                 `gorm:"not null;size:36;index" json:"organizationId"`
+	Supplier            string                 `gorm:"not null" json:"supplier"`
+	Lines               []ProcurementOrderLine `gorm:"serializer:json" json:"lines"`
+	Total               float64                `gorm:"not null" json:"total"` // Sum of line totals
+	CreatedAt           time.Time              `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt           time.Time              `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt         `gorm:"index" json:"-"`
+
+	// Relationships
+	Allocations []ProcurementRequest `gorm:"foreignKey:ProcurementOrderID;references:ProcurementOrderID" json:"allocations,omitempty"`
+}
+
+// ProcurementOrderLine is the total demand for one item on a consolidated
+// order
+type ProcurementOrderLine struct {
+	ItemID    string  `json:"itemId"`
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	Quantity  float64 `json:"quantity"`
+	UnitPrice float64 `json:"unitPrice"`
+	Total     float64 `json:"total"`   // Quantity times unit price
+	Members   int     `json:"members"` // Members who put in for the item
+}
+
+// ProcurementOrderInterface defines the contract for procurement order
+// operations
+type ProcurementOrderInterface interface {
+	GetByProcurementOrderID(ctx context.Context, procurementOrderID string) (*ProcurementOrder, error)
+	// GetByWindowID returns a window's orders with their allocations
+	GetByWindowID(ctx context.Context, procurementWindowID string) ([]*ProcurementOrder, error)
+}
+
+// ProcurementOrderRepo implements ProcurementOrderInterface using GORM.
+type ProcurementOrderRepo struct {
+	DB *gorm.DB
+}
+
+// NewProcurementOrderRepo creates a new instance of ProcurementOrderRepo.
+func NewProcurementOrderRepo(db *gorm.DB) ProcurementOrderInterface {
+	return &ProcurementOrderRepo{DB: db}
+}
+
+// GetByProcurementOrderID retrieves an order with its allocations by its
+// ProcurementOrderID (UUID)
+func (p *ProcurementOrderRepo) GetByProcurementOrderID(ctx context.Context, procurementOrderID string) (*ProcurementOrder, error) {
+	var order ProcurementOrder
+	result := p.DB.WithContext(ctx).Preload("Allocations.User").Where("procurement_order_id = ?", procurementOrderID).First(&order)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &order, result.Error
+}
+
+// GetByWindowID retrieves a window's orders with their allocations, by
+// supplier
+func (p *ProcurementOrderRepo) GetByWindowID(ctx context.Context, procurementWindowID string) ([]*ProcurementOrder, error) {
+	var orders []*ProcurementOrder
+	result := p.DB.WithContext(ctx).Preload("Allocations.User").Where("procurement_window_id = ?", procurementWindowID).
+		Order("supplier").Find(&orders)
+	return orders, result.Error
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProcurementRequest represents the procurement_requests table in the
+// database: the quantity of an item a member puts in for during a window,
+// for one of their farms. Once the window is consolidated the request is
+// the member's allocation on the supplier's order and tracks its delivery.
+type ProcurementRequest struct {
+	ID                   uint           `gorm:"primaryKey" json:"-"`
+	ProcurementRequestID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"requestId"`
+	ProcurementWindowID  string         `gorm:"not null;size:36;index" json:"windowId"`                                                      // Foreign key to ProcurementWindow
+	ProcurementItemID    string         `gorm:"not null;size:36;uniqueIndex:idx_procurement_request,where:deleted_at IS NULL" json:"itemId"` // Foreign key to ProcurementItem
+	UserID               string         `gorm:"not null;size:36;uniqueIndex:idx_procurement_request,where:deleted_at IS NULL" json:"userId"` // Member who put in
+	FarmID               string         `gorm:"not null;size:36" json:"farmId"`                                                              // Member's farm the inputs are for
+	Quantity             float64        `gorm:"not null" json:"quantity"`
+	ProcurementOrderID   *string        `gorm:"size:36;index" json:"orderId,omitempty"`     // Order the request was allocated to
+	Status               string         `gorm:"not null;default:'Requested'" json:"status"` // Requested, Ordered, Delivered
+	DeliveredQuantity    float64        `json:"deliveredQuantity"`
+	DeliveredAt          *time.Time     `json:"deliveredAt,omitempty"`
+	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt            gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Item *ProcurementItem `gorm:"foreignKey:ProcurementItemID;references:ProcurementItemID" json:"item,omitempty"`
+	User *User            `gorm:"foreignKey:UserID;references:UserID" json:"user,omitempty"`
+}
+
+// ProcurementRequestInterface defines the contract for procurement request
+// operations
+type ProcurementRequestInterface interface {
+	GetByProcurementRequestID(ctx context.Context, procurementRequestID string) (*ProcurementRequest, error)
+	// GetByWindowID returns a window's requests with their items and users,
+	// optionally only one member's
+	GetByWindowID(ctx context.Context, procurementWindowID, userID string) ([]*ProcurementRequest, error)
+	// Replace swaps a member's requests in a window for requests, reporting
+	// whether the window was still Open
+	Replace(ctx context.Context, procurementWindowID, userID string, requests []*ProcurementRequest) (bool, error)
+	// Deliver marks an Ordered request Delivered, reporting whether it was
+	// still Ordered
+	Deliver(ctx context.Context, request *ProcurementRequest) (bool, error)
+}
+
+// ProcurementRequestRepo implements ProcurementRequestInterface using GORM.
+type ProcurementRequestRepo struct {
+	DB *gorm.DB
+}
+
+// NewProcurementRequestRepo creates a new instance of ProcurementRequestRepo.
+func NewProcurementRequestRepo(db *gorm.DB) ProcurementRequestInterface {
+	return &ProcurementRequestRepo{DB: db}
+}
+
+// GetByProcurementRequestID retrieves a request with its item by its
+// ProcurementRequestID (UUID)
+func (p *ProcurementRequestRepo) GetByProcurementRequestID(ctx context.Context, procurementRequestID string) (*ProcurementRequest, error) {
+	var request ProcurementRequest
+	result := p.DB.WithContext(ctx).Preload("Item").Where("procurement_request_id = ?", procurementRequestID).First(&request)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &request, result.Error
+}
+
+// GetByWindowID retrieves a window's requests with their items and users,
+// oldest first, optionally only one member's
+func (p *ProcurementRequestRepo) GetByWindowID(ctx context.Context, procurementWindowID, userID string) ([]*ProcurementRequest, error) {
+	var requests []*ProcurementRequest
+	query := p.DB.WithContext(ctx).Preload("Item").Preload("User").Where("procurement_window_id = ?", procurementWindowID)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	result := query.Order("created_at, id").Find(&requests)
+	return requests, result.Error
+}
+
+// Replace swaps a member's requests in a window for requests in a single
+// transaction. The window row is updated first, guarding on the Open status,
+// so a change cannot slip in while the window is being consolidated.
+func (p *ProcurementRequestRepo) Replace(ctx context.Context, procurementWindowID, userID string, requests []*ProcurementRequest) (bool, error) {
+	replaced := false
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ProcurementWindow{}).
+			Where("procurement_window_id = ? AND status = ?", procurementWindowID, "Open").
+			Update("updated_at", time.Now())
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		replaced = true
+
+		if err := tx.Unscoped().
+			Where("procurement_window_id = ? AND user_id = ?", procurementWindowID, userID).
+			Delete(&ProcurementRequest{}).Error; err != nil {
+			return err
+		}
+		if len(requests) == 0 {
+			return nil
+		}
+		return tx.Omit("Item", "User").Create(&requests).Error
+	})
+	if err != nil {
+		return false, err
+	}
+	return replaced, nil
+}
+
+// Deliver marks an Ordered request Delivered, guarding on the Ordered status
+// so a delivery is only recorded once
+func (p *ProcurementRequestRepo) Deliver(ctx context.Context, request *ProcurementRequest) (bool, error) {
+	result := p.DB.WithContext(ctx).Model(&ProcurementRequest{}).
+		Where("procurement_request_id = ? AND status = ?", request.ProcurementRequestID, "Ordered").
+		Updates(map[string]any{
+			"status":             "Delivered",
+			"delivered_quantity": request.DeliveredQuantity,
+			"delivered_at":       request.DeliveredAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProcurementWindow represents the procurement_windows table in the
+// database: a period in which an organization's members put in for inputs
+// bought as a group. A window moves from Open to Closed to Consolidated, when
+// the demand is turned into one order per supplier, or is Cancelled.
+type ProcurementWindow struct {
+	ID                  uint           `gorm:"primaryKey" json:"-"`
+	ProcurementWindowID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"windowId"`
+	OrganizationID      string         `gorm:"not null;size:36;index" json:"organizationId"` // Foreign key to Organization
+	Title               string         `gorm:"not null" json:"title"`
+	Notes               string         `json:"notes"`
+	ClosesAt            time.Time      `gorm:"not null" json:"closesAt"`              // Members can put in until then
+	Status              string         `gorm:"not null;default:'Open'" json:"status"` // Open, Closed, Consolidated, Cancelled
+	CreatedBy           string         `gorm:"not null;size:36" json:"createdBy"`     // Admin who opened the window
+	ConsolidatedAt      *time.Time     `json:"consolidatedAt,omitempty"`
+	CreatedAt           time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt           time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Items []ProcurementItem `gorm:"foreignKey:ProcurementWindowID;references:ProcurementWindowID" json:"items"`
+}
+
+// ProcurementItem represents the procurement_items table in the database: an
+// input offered in a window, from the supplier the group buys it from
+type ProcurementItem struct {
+	ID                  uint      `gorm:"primaryKey" json:"-"`
+	ProcurementItemID   string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"itemId"`
+	ProcurementWindowID string    `gorm:"not null;size:36;index" json:"windowId"` // Foreign key to ProcurementWindow
+	Name                string    `gorm:"not null" json:"name"`
+	Unit                string    `gorm:"not null" json:"unit"` // kg, bags, litres, etc.
+	Supplier            string    `gorm:"not null" json:"supplier"`
+	UnitPrice           float64   `json:"unitPrice"` // Price agreed or expected per unit
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// ProcurementWindowInterface defines the contract for procurement window
+// operations
+type ProcurementWindowInterface interface {
+	GetByProcurementWindowID(ctx context.Context, procurementWindowID string) (*ProcurementWindow, error)
+	// GetByOrganizationID returns an organization's windows, newest first,
+	// optionally only those with the given status
+	GetByOrganizationID(ctx context.Context, organizationID, status string) ([]*ProcurementWindow, error)
+	// Insert creates a window with its items
+	Insert(ctx context.Context, window *ProcurementWindow) error
+	// Transition moves a window to window.Status if it is still in status
+	// from, reporting whether it was
+	Transition(ctx context.Context, window *ProcurementWindow, from string) (bool, error)
+	// Consolidate marks an Open or Closed window Consolidated and creates
+	// its orders, allocating each order's requests to it, reporting whether
+	// the window was still Open or Closed
+	Consolidate(ctx context.Context, window *ProcurementWindow, orders []*ProcurementOrder) (bool, error)
+}
+
+// ProcurementWindowRepo implements ProcurementWindowInterface using GORM.
+type ProcurementWindowRepo struct {
+	DB *gorm.DB
+}
+
+// NewProcurementWindowRepo creates a new instance of ProcurementWindowRepo.
+func NewProcurementWindowRepo(db *gorm.DB) ProcurementWindowInterface {
+	return &ProcurementWindowRepo{DB: db}
+}
+
+// GetByProcurementWindowID retrieves a window with its items by its
+// ProcurementWindowID (UUID)
+func (p *ProcurementWindowRepo) GetByProcurementWindowID(ctx context.Context, procurementWindowID string) (*ProcurementWindow, error) {
+	var window ProcurementWindow
+	result := p.DB.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("procurement_window_id = ?", procurementWindowID).First(&window)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &window, result.Error
+}
+
+// GetByOrganizationID retrieves an organization's windows with their items,
+// newest first, optionally only those with the given status
+func (p *ProcurementWindowRepo) GetByOrganizationID(ctx context.Context, organizationID, status string) ([]*ProcurementWindow, error) {
+	var windows []*ProcurementWindow
+	query := p.DB.WithContext(ctx).Preload("Items").Where("organization_id = ?", organizationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("created_at desc").Find(&windows)
+	return windows, result.Error
+}
+
+// Insert creates a window and its items in a single transaction
+func (p *ProcurementWindowRepo) Insert(ctx context.Context, window *ProcurementWindow) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Create(window).Error; err != nil {
+			return err
+		}
+		for i := range window.Items {
+			window.Items[i].ProcurementWindowID = window.ProcurementWindowID
+		}
+		if len(window.Items) == 0 {
+			return nil
+		}
+		return tx.Create(&window.Items).Error
+	})
+}
+
+// Transition moves a window to window.Status, guarding on the status it was
+// loaded in so concurrent changes cannot both apply
+func (p *ProcurementWindowRepo) Transition(ctx context.Context, window *ProcurementWindow, from string) (bool, error) {
+	result := p.DB.WithContext(ctx).Model(&ProcurementWindow{}).
+		Where("procurement_window_id = ? AND status = ?", window.ProcurementWindowID, from).
+		Update("status", window.Status)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Consolidate marks an Open or Closed window Consolidated and, in the same
+// transaction, creates its orders and allocates each order's requests to it,
+// so demand is never ordered twice or left out of an order
+func (p *ProcurementWindowRepo) Consolidate(ctx context.Context, window *ProcurementWindow, orders []*ProcurementOrder) (bool, error) {
+	consolidated := false
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&ProcurementWindow{}).
+			Where("procurement_window_id = ? AND status IN ?", window.ProcurementWindowID, []string{"Open", "Closed"}).
+			Updates(map[string]any{"status": "Consolidated", "consolidated_at": window.ConsolidatedAt})
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		consolidated = true
+
+		for _, order := range orders {
+			if err := tx.Omit("Allocations").Create(order).Error; err != nil {
+				return err
+			}
+			ids := make([]string, len(order.Allocations))
+			for i := range order.Allocations {
+				order.Allocations[i].ProcurementOrderID = &order.ProcurementOrderID
+				order.Allocations[i].Status = "Ordered"
+				ids[i] = order.Allocations[i].ProcurementRequestID
+			}
+			if err := tx.Model(&ProcurementRequest{}).
+				Where("procurement_request_id IN ?", ids).
+				Updates(map[string]any{"procurement_order_id": order.ProcurementOrderID, "status": "Ordered"}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return consolidated, nil
+}
@@ -0,0 +1,170 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductionRecord represents the production_records table in the database:
+// what a herd or flock, or one animal in it, produced on a day, such as the
+// morning and evening milk of a cow or a flock's eggs. Milk delivered to a
+// collection center is recorded separately, as a MilkDelivery.
+type ProductionRecord struct {
+	ID                 uint           `gorm:"primaryKey" json:"-"`
+	ProductionRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"productionRecordId"`
+	FarmID             string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	LivestockID        string         `gorm:"not null;size:36;index" json:"livestockId"` // Herd or flock that produced it
+	AnimalTag          string         `json:"animalTag"`                                 // One animal of the herd; empty for the whole herd
+	ProductType        string         `gorm:"not null" json:"productType"`               // Milk, Eggs, Wool, Honey, Other
+	Date               time.Time      `gorm:"not null;index" json:"date"`                // Day produced
+	Quantity           float64        `gorm:"not null" json:"quantity"`
+	Unit               string         `gorm:"not null" json:"unit"` // Litres, Trays, Pieces, Kg
+	Notes              string         `json:"notes"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Livestock *Livestock `gorm:"foreignKey:LivestockID;references:LivestockID" json:"livestock,omitempty"`
+}
+
+// ProductionFilter narrows the production records listed or totalled; empty
+// fields match every record. To is exclusive.
+type ProductionFilter struct {
+	LivestockID string
+	ProductType string
+	From        *time.Time
+	To          *time.Time
+}
+
+// ProductionTotal is what a herd produced of one product in one week or
+// month
+type ProductionTotal struct {
+	Period      string  `json:"period"` // Monday of the week, YYYY-MM-DD, or the month, YYYY-MM
+	LivestockID string  `json:"livestockId"`
+	ProductType string  `json:"productType"`
+	Unit        string  `json:"unit"`
+	Days        int     `json:"days"` // Distinct days with a record
+	Quantity    float64 `json:"quantity"`
+}
+
+// periodFormats maps the intervals production is totalled over to the label
+// of each period
+var periodFormats = map[string]string{
+	"week":  "YYYY-MM-DD",
+	"month": "YYYY-MM",
+}
+
+// ProductionRecordInterface defines the contract for production record operations
+type ProductionRecordInterface interface {
+	GetByProductionRecordID(ctx context.Context, productionRecordID string) (*ProductionRecord, error)
+	// GetByFarmID returns a farm's production records, most recent first
+	GetByFarmID(ctx context.Context, farmID string, filter ProductionFilter) ([]*ProductionRecord, error)
+	// GetOnDates returns a farm's records for any of the given days
+	GetOnDates(ctx context.Context, farmID string, dates []time.Time) ([]*ProductionRecord, error)
+	// Totals sums a farm's production per herd, product, unit and week or
+	// month; interval is "week" or "month"
+	Totals(ctx context.Context, farmID, interval string, filter ProductionFilter) ([]ProductionTotal, error)
+	// SaveMany creates new records and updates existing ones in a single
+	// transaction
+	SaveMany(ctx context.Context, records []*ProductionRecord) error
+	Update(ctx context.Context, record *ProductionRecord) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ProductionRecordRepo implements ProductionRecordInterface using GORM.
+type ProductionRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewProductionRecordRepo creates a new instance of ProductionRecordRepo.
+func NewProductionRecordRepo(db *gorm.DB) ProductionRecordInterface {
+	return &ProductionRecordRepo{DB: db}
+}
+
+// GetByProductionRecordID retrieves a production record by its
+// ProductionRecordID (UUID)
+func (p *ProductionRecordRepo) GetByProductionRecordID(ctx context.Context, productionRecordID string) (*ProductionRecord, error) {
+	var record ProductionRecord
+	result := p.DB.WithContext(ctx).Where("production_record_id = ?", productionRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's production records, most recent first
+func (p *ProductionRecordRepo) GetByFarmID(ctx context.Context, farmID string, filter ProductionFilter) ([]*ProductionRecord, error) {
+	var records []*ProductionRecord
+	result := p.filtered(ctx, farmID, filter).Order("date desc, id desc").Find(&records)
+	return records, result.Error
+}
+
+// GetOnDates retrieves a farm's records for any of the given days
+func (p *ProductionRecordRepo) GetOnDates(ctx context.Context, farmID string, dates []time.Time) ([]*ProductionRecord, error) {
+	var records []*ProductionRecord
+	if len(dates) == 0 {
+		return records, nil
+	}
+	result := p.DB.WithContext(ctx).Where("farm_id = ? AND date IN ?", farmID, dates).Find(&records)
+	return records, result.Error
+}
+
+// Totals sums a farm's production per herd, product, unit and period
+func (p *ProductionRecordRepo) Totals(ctx context.Context, farmID, interval string, filter ProductionFilter) ([]ProductionTotal, error) {
+	format, ok := periodFormats[interval]
+	if !ok {
+		return nil, errors.New("interval must be week or month")
+	}
+	var totals []ProductionTotal
+	result := p.filtered(ctx, farmID, filter).Model(&ProductionRecord{}).
+		Select("to_char(date_trunc(?, date), ?) AS period, livestock_id, product_type, unit, COUNT(DISTINCT CAST(date AS DATE)) AS days, SUM(quantity) AS quantity", interval, format).
+		Group("period, livestock_id, product_type, unit").
+		Order("period, livestock_id, product_type, unit").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// filtered starts a query for a farm's records matching filter
+func (p *ProductionRecordRepo) filtered(ctx context.Context, farmID string, filter ProductionFilter) *gorm.DB {
+	query := p.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if filter.LivestockID != "" {
+		query = query.Where("livestock_id = ?", filter.LivestockID)
+	}
+	if filter.ProductType != "" {
+		query = query.Where("product_type = ?", filter.ProductType)
+	}
+	if filter.From != nil {
+		query = query.Where("date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("date < ?", *filter.To)
+	}
+	return query
+}
+
+// SaveMany creates new records and updates existing ones in a single
+// transaction
+func (p *ProductionRecordRepo) SaveMany(ctx context.Context, records []*ProductionRecord) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			if err := tx.Omit("Livestock").Save(record).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update saves a production record
+func (p *ProductionRecordRepo) Update(ctx context.Context, record *ProductionRecord) error {
+	return p.DB.WithContext(ctx).Omit("Livestock").Save(record).Error
+}
+
+// DeleteByID soft deletes a production record by its ID
+func (p *ProductionRecordRepo) DeleteByID(ctx context.Context, id int) error {
+	return p.DB.WithContext(ctx).Delete(&ProductionRecord{}, id).Error
+}
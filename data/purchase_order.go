@@ -0,0 +1,264 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurchaseOrder represents the purchase_orders table in the database. An
+// order moves from Draft to Ordered to Received, or is Cancelled; receiving
+// it puts each line into stock as a new inventory batch.
+type PurchaseOrder struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	PurchaseOrderID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"purchaseOrderId"`
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"`     // Foreign key to Farm
+	SupplierID      string         `gorm:"not null;size:36;index" json:"supplierId"` // Foreign key to Supplier
+	Reference       string         `json:"reference"`                                // Order number shared with the supplier
+	Status          string         `gorm:"not null;default:'Draft'" json:"status"`   // Draft, Ordered, Received, Cancelled
+	OrderedAt       *time.Time     `json:"orderedAt,omitempty"`
+	ExpectedDate    *time.Time     `json:"expectedDate,omitempty"`
+	ReceivedAt      *time.Time     `json:"receivedAt,omitempty"`
+	Total           float64        `gorm:"not null" json:"total"` // Sum of line totals
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Supplier *Supplier           `gorm:"foreignKey:SupplierID;references:SupplierID" json:"supplier,omitempty"`
+	Lines    []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID;references:PurchaseOrderID" json:"lines"`
+}
+
+// PurchaseOrderLine represents the purchase_order_lines table in the
+// database: a quantity of one inventory item on an order
+type PurchaseOrderLine struct {
+	ID                  uint       `gorm:"primaryKey" json:"-"`
+	PurchaseOrderLineID string     `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"lineId"`
+	PurchaseOrderID     string     `gorm:"not null;size:36;index" json:"purchaseOrderId"` // Foreign key to PurchaseOrder
+	InventoryItemID     string     `gorm:"not null;size:36" json:"inventoryItemId"`       // Foreign key to InventoryItem
+	Quantity            float64    `gorm:"not null" json:"quantity"`
+	UnitCost            float64    `gorm:"not null" json:"unitCost"`
+	Total               float64    `gorm:"not null" json:"total"`                     // Quantity times unit cost
+	InventoryBatchID    *string    `gorm:"size:36" json:"inventoryBatchId,omitempty"` // Batch created on receipt
+	BatchNumber         string     `json:"batchNumber,omitempty"`                     // Set on receipt
+	ExpiryDate          *time.Time `json:"expiryDate,omitempty"`                      // Set on receipt
+	CreatedAt           time.Time  `gorm:"autoCreateTime" json:"createdAt"`
+
+	// Relationships
+	InventoryItem *InventoryItem `gorm:"foreignKey:InventoryItemID;references:InventoryItemID" json:"inventoryItem,omitempty"`
+}
+
+// CalculateTotal sets each line's total and the order total
+func (p *PurchaseOrder) CalculateTotal() {
+	p.Total = 0
+	for i := range p.Lines {
+		p.Lines[i].Total = p.Lines[i].Quantity * p.Lines[i].UnitCost
+		p.Total += p.Lines[i].Total
+	}
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries a received line's cost into the finance ledger
+func (l *PurchaseOrderLine) TransactionReference() string {
+	return fmt.Sprintf("purchase_order_line:%s", l.PurchaseOrderLineID)
+}
+
+// PurchaseOrderInterface defines the contract for purchase order operations
+type PurchaseOrderInterface interface {
+	GetByPurchaseOrderID(ctx context.Context, purchaseOrderID string) (*PurchaseOrder, error)
+	// GetByFarmID returns a farm's orders, newest first, optionally only
+	// those with the given status
+	GetByFarmID(ctx context.Context, farmID, status string) ([]*PurchaseOrder, error)
+	// ExistsOpen reports whether a supplier has a Draft or Ordered order
+	ExistsOpen(ctx context.Context, supplierID string) (bool, error)
+	Insert(ctx context.Context, order *PurchaseOrder) error
+	// Update saves a draft order and replaces its lines
+	Update(ctx context.Context, order *PurchaseOrder) error
+	// Transition moves an order to order.Status if it is still in status
+	// from, reporting whether it was
+	Transition(ctx context.Context, order *PurchaseOrder, from string) (bool, error)
+	// Receive marks an Ordered order Received and puts its lines into stock,
+	// reporting whether the order was still Ordered
+	Receive(ctx context.Context, order *PurchaseOrder) (bool, error)
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// PurchaseOrderRepo implements PurchaseOrderInterface using GORM.
+type PurchaseOrderRepo struct {
+	DB *gorm.DB
+}
+
+// NewPurchaseOrderRepo creates a new instance of PurchaseOrderRepo.
+func NewPurchaseOrderRepo(db *gorm.DB) PurchaseOrderInterface {
+	return &PurchaseOrderRepo{DB: db}
+}
+
+// GetByPurchaseOrderID retrieves an order with its supplier and lines by its
+// PurchaseOrderID (UUID)
+func (p *PurchaseOrderRepo) GetByPurchaseOrderID(ctx context.Context, purchaseOrderID string) (*PurchaseOrder, error) {
+	var order PurchaseOrder
+	result := p.DB.WithContext(ctx).Preload("Supplier").Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Preload("Lines.InventoryItem").Where("purchase_order_id = ?", purchaseOrderID).First(&order)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &order, result.Error
+}
+
+// GetByFarmID retrieves a farm's orders with their suppliers and lines,
+// newest first, optionally only those with the given status
+func (p *PurchaseOrderRepo) GetByFarmID(ctx context.Context, farmID, status string) ([]*PurchaseOrder, error) {
+	var orders []*PurchaseOrder
+	query := p.DB.WithContext(ctx).Preload("Supplier").Preload("Lines").Where("farm_id = ?", farmID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	result := query.Order("created_at desc").Find(&orders)
+	return orders, result.Error
+}
+
+// ExistsOpen reports whether a supplier has a Draft or Ordered order
+func (p *PurchaseOrderRepo) ExistsOpen(ctx context.Context, supplierID string) (bool, error) {
+	var count int64
+	result := p.DB.WithContext(ctx).Model(&PurchaseOrder{}).
+		Where("supplier_id = ? AND status IN ?", supplierID, []string{"Draft", "Ordered"}).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// Insert creates a new order and its lines in a single transaction
+func (p *PurchaseOrderRepo) Insert(ctx context.Context, order *PurchaseOrder) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Supplier", "Lines").Create(order).Error; err != nil {
+			return err
+		}
+		for i := range order.Lines {
+			order.Lines[i].PurchaseOrderID = order.PurchaseOrderID
+		}
+		if len(order.Lines) == 0 {
+			return nil
+		}
+		return tx.Omit("InventoryItem").Create(&order.Lines).Error
+	})
+}
+
+// Update saves an order and replaces its lines in a single transaction
+func (p *PurchaseOrderRepo) Update(ctx context.Context, order *PurchaseOrder) error {
+	return p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("purchase_order_id = ?", order.PurchaseOrderID).
+			Delete(&PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range order.Lines {
+			order.Lines[i].ID = 0
+			order.Lines[i].PurchaseOrderLineID = ""
+			order.Lines[i].PurchaseOrderID = order.PurchaseOrderID
+		}
+		if len(order.Lines) > 0 {
+			if err := tx.Omit("InventoryItem").Create(&order.Lines).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Supplier", "Lines").Save(order).Error
+	})
+}
+
+// Transition moves an order to order.Status, guarding on the status it was
+// loaded in so concurrent changes cannot both apply
+func (p *PurchaseOrderRepo) Transition(ctx context.Context, order *PurchaseOrder, from string) (bool, error) {
+	result := p.DB.WithContext(ctx).Model(&PurchaseOrder{}).
+		Where("purchase_order_id = ? AND status = ?", order.PurchaseOrderID, from).
+		Updates(map[string]any{
+			"status":     order.Status,
+			"ordered_at": order.OrderedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Receive marks an Ordered order Received and, in the same transaction,
+// receives each line as a new inventory batch and records its cost as an
+// expense in the item's category
+func (p *PurchaseOrderRepo) Receive(ctx context.Context, order *PurchaseOrder) (bool, error) {
+	received := false
+	err := p.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&PurchaseOrder{}).
+			Where("purchase_order_id = ? AND status = ?", order.PurchaseOrderID, "Ordered").
+			Updates(map[string]any{"status": "Received", "received_at": order.ReceivedAt})
+		if result.Error != nil || result.RowsAffected != 1 {
+			return result.Error
+		}
+		received = true
+
+		supplier := "supplier"
+		if order.Supplier != nil {
+			supplier = order.Supplier.Name
+		}
+		for i := range order.Lines {
+			line := &order.Lines[i]
+			batch := &InventoryBatch{
+				InventoryItemID: line.InventoryItemID,
+				FarmID:          order.FarmID,
+				BatchNumber:     line.BatchNumber,
+				Quantity:        line.Quantity,
+				InitialQuantity: line.Quantity,
+				UnitCost:        line.UnitCost,
+				ReceivedDate:    *order.ReceivedAt,
+				ExpiryDate:      line.ExpiryDate,
+				Notes:           fmt.Sprintf("Purchase order %s", order.Reference),
+			}
+			if err := tx.Omit("InventoryItem").Create(batch).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&InventoryMovement{
+				InventoryItemID:  batch.InventoryItemID,
+				InventoryBatchID: batch.InventoryBatchID,
+				FarmID:           batch.FarmID,
+				Type:             "Receipt",
+				Quantity:         batch.InitialQuantity,
+				Date:             batch.ReceivedDate,
+				Purpose:          "Purchase",
+				Notes:            batch.Notes,
+			}).Error; err != nil {
+				return err
+			}
+
+			line.InventoryBatchID = &batch.InventoryBatchID
+			if err := tx.Model(line).Updates(map[string]any{
+				"inventory_batch_id": line.InventoryBatchID,
+				"batch_number":       line.BatchNumber,
+				"expiry_date":        line.ExpiryDate,
+			}).Error; err != nil {
+				return err
+			}
+
+			category, name := "Supplies", "inputs"
+			if line.InventoryItem != nil {
+				category, name = line.InventoryItem.Category, line.InventoryItem.Name
+			}
+			if err := syncExpense(tx, line.TransactionReference(), Transaction{
+				FarmID:      order.FarmID,
+				Category:    category,
+				Amount:      line.Total,
+				Date:        *order.ReceivedAt,
+				Description: fmt.Sprintf("%s from %s", name, supplier),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return received, nil
+}
+
+// DeleteByID soft deletes an order by its ID
+func (p *PurchaseOrderRepo) DeleteByID(ctx context.Context, id int) error {
+	return p.DB.WithContext(ctx).Delete(&PurchaseOrder{}, id).Error
+}
@@ -0,0 +1,92 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RainfallRecord represents the rainfall_records table in the database: one
+// reading of a farm's own rain gauge
+type RainfallRecord struct {
+	ID               uint           `gorm:"primaryKey" json:"-"`
+	RainfallRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"rainfallId"`
+	FarmID           string         `gorm:"not null;size:36;index" json:"farmId"`   // Foreign key to Farm
+	FieldID          *string        `gorm:"size:36;index" json:"fieldId,omitempty"` // Optional foreign key to Field the gauge stands in
+	Gauge            string         `gorm:"not null" json:"gauge"`                  // Which of the farm's gauges was read
+	Date             time.Time      `gorm:"not null;index" json:"date"`
+	MM               float64        `gorm:"not null" json:"mm"` // Rain caught, in millimetres
+	Notes            string         `json:"notes"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Field *Field `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+}
+
+// RainfallRecordInterface defines the contract for rainfall record operations
+type RainfallRecordInterface interface {
+	GetByRainfallRecordID(ctx context.Context, rainfallRecordID string) (*RainfallRecord, error)
+	// GetByFarmID returns a farm's readings, optionally of one gauge and
+	// limited to dates in [from, to), oldest first
+	GetByFarmID(ctx context.Context, farmID, gauge string, from, to *time.Time) ([]*RainfallRecord, error)
+	Insert(ctx context.Context, record *RainfallRecord) error
+	Update(ctx context.Context, record *RainfallRecord) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// RainfallRecordRepo implements RainfallRecordInterface using GORM.
+type RainfallRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewRainfallRecordRepo creates a new instance of RainfallRecordRepo.
+func NewRainfallRecordRepo(db *gorm.DB) RainfallRecordInterface {
+	return &RainfallRecordRepo{DB: db}
+}
+
+// GetByRainfallRecordID retrieves a reading by its RainfallRecordID (UUID)
+func (rr *RainfallRecordRepo) GetByRainfallRecordID(ctx context.Context, rainfallRecordID string) (*RainfallRecord, error) {
+	var record RainfallRecord
+	result := rr.DB.WithContext(ctx).Where("rainfall_record_id = ?", rainfallRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's readings, optionally of one gauge and
+// limited to dates in [from, to), oldest first
+func (rr *RainfallRecordRepo) GetByFarmID(ctx context.Context, farmID, gauge string, from, to *time.Time) ([]*RainfallRecord, error) {
+	var records []*RainfallRecord
+	query := rr.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if gauge != "" {
+		query = query.Where("gauge = ?", gauge)
+	}
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date, id").Find(&records)
+	return records, result.Error
+}
+
+// Insert creates a new reading in the database
+func (rr *RainfallRecordRepo) Insert(ctx context.Context, record *RainfallRecord) error {
+	return rr.DB.WithContext(ctx).Omit("Field").Create(record).Error
+}
+
+// Update saves a reading
+func (rr *RainfallRecordRepo) Update(ctx context.Context, record *RainfallRecord) error {
+	return rr.DB.WithContext(ctx).Omit("Field").Save(record).Error
+}
+
+// DeleteByID soft deletes a reading by its ID
+func (rr *RainfallRecordRepo) DeleteByID(ctx context.Context, id int) error {
+	return rr.DB.WithContext(ctx).Delete(&RainfallRecord{}, id).Error
+}
@@ -0,0 +1,78 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rating represents the ratings table in the database. After a sale the
+// farmer and the buyer can each rate the other once.
+type Rating struct {
+	ID            uint           `gorm:"primaryKey" json:"-"`
+	RatingID      string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"ratingId"`
+	RaterID       string         `gorm:"not null;size:36;uniqueIndex:idx_rating_sale" json:"raterId"`       // UserID of the author
+	RateeID       string         `gorm:"not null;size:36;uniqueIndex:idx_rating_sale;index" json:"rateeId"` // UserID being rated
+	SaleReference string         `gorm:"not null;uniqueIndex:idx_rating_sale" json:"saleReference"`         // Identifies the sale, e.g. an invoice or transaction ID
+	Score         int            `gorm:"not null" json:"score"`                                             // 1 to 5
+	Review        string         `json:"review"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// RatingSummary is the number and average score of a user's ratings
+type RatingSummary struct {
+	Count   int64   `json:"count"`
+	Average float64 `json:"average"`
+}
+
+// RatingInterface defines the contract for rating operations
+type RatingInterface interface {
+	GetByRateeID(ctx context.Context, rateeID string, limit int) ([]*Rating, error)
+	Summary(ctx context.Context, rateeID string) (RatingSummary, error)
+	Exists(ctx context.Context, raterID, rateeID, saleReference string) (bool, error)
+	Insert(ctx context.Context, rating *Rating) error
+}
+
+// RatingRepo implements RatingInterface using GORM.
+type RatingRepo struct {
+	DB *gorm.DB
+}
+
+// NewRatingRepo creates a new instance of RatingRepo.
+func NewRatingRepo(db *gorm.DB) RatingInterface {
+	return &RatingRepo{DB: db}
+}
+
+// GetByRateeID retrieves the most recent ratings of a user, up to limit
+func (r *RatingRepo) GetByRateeID(ctx context.Context, rateeID string, limit int) ([]*Rating, error) {
+	var ratings []*Rating
+	result := r.DB.WithContext(ctx).Where("ratee_id = ?", rateeID).Order("created_at desc").Limit(limit).Find(&ratings)
+	return ratings, result.Error
+}
+
+// Summary counts and averages the ratings of a user
+func (r *RatingRepo) Summary(ctx context.Context, rateeID string) (RatingSummary, error) {
+	var summary RatingSummary
+	result := r.DB.WithContext(ctx).Model(&Rating{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(score), 0) AS average").
+		Where("ratee_id = ?", rateeID).
+		Scan(&summary)
+	return summary, result.Error
+}
+
+// Exists reports whether the rater has already rated the ratee for a sale
+func (r *RatingRepo) Exists(ctx context.Context, raterID, rateeID, saleReference string) (bool, error) {
+	var count int64
+	result := r.DB.WithContext(ctx).Model(&Rating{}).
+		Where("rater_id = ? AND ratee_id = ? AND sale_reference = ?", raterID, rateeID, saleReference).
+		Count(&count)
+	return count > 0, result.Error
+}
+
+// Insert creates a new rating in the database
+func (r *RatingRepo) Insert(ctx context.Context, rating *Rating) error {
+	return r.DB.WithContext(ctx).Create(rating).Error
+}
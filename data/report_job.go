@@ -0,0 +1,157 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportJob represents the report_jobs table in the database: a PDF report
+// requested for a farm and period. Reports are rendered in the background;
+// once Ready the file lives in object storage under FileKey.
+type ReportJob struct {
+	ID          uint           `gorm:"primaryKey" json:"-"`
+	ReportJobID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"reportId"`
+	FarmID      string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	UserID      string         `gorm:"not null;size:36" json:"userId"`       // User who requested the report
+	Type        string         `gorm:"not null" json:"type"`                 // farm-summary, payroll, livestock-health, harvest
+	Period      string         `gorm:"not null" json:"period"`               // YYYY, YYYY-Qn, YYYY-MM or a season's name
+	PeriodStart time.Time      `gorm:"not null" json:"periodStart"`
+	PeriodEnd   time.Time      `gorm:"not null" json:"periodEnd"`                      // Exclusive
+	Status      string         `gorm:"not null;default:'Pending';index" json:"status"` // Pending, Running, Ready, Failed
+	Error       string         `json:"error,omitempty"`
+	Size        int64          `json:"size"` // Bytes, once Ready
+	StartedAt   *time.Time     `json:"startedAt,omitempty"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// FileKey is the storage key of the rendered PDF
+func (j *ReportJob) FileKey() string {
+	return fmt.Sprintf("farms/%s/reports/%s.pdf", j.FarmID, j.ReportJobID)
+}
+
+// ReportJobInterface defines the contract for report job operations
+type ReportJobInterface interface {
+	GetByReportJobID(ctx context.Context, reportJobID string) (*ReportJob, error)
+	// GetByFarmID returns a farm's report jobs, newest first
+	GetByFarmID(ctx context.Context, farmID string) ([]*ReportJob, error)
+	// GetQueued returns a farm's Pending or Running job for a report type
+	// and period, or nil
+	GetQueued(ctx context.Context, farmID, reportType, period string) (*ReportJob, error)
+	// GetPending returns the IDs of jobs still Pending that were requested
+	// before the given time
+	GetPending(ctx context.Context, before time.Time) ([]string, error)
+	Insert(ctx context.Context, job *ReportJob) error
+	// Claim moves a Pending job to Running, reporting whether it was still
+	// Pending so a job is only rendered once
+	Claim(ctx context.Context, job *ReportJob) (bool, error)
+	// Finish records the outcome of a Running job
+	Finish(ctx context.Context, job *ReportJob) error
+	// FailStale marks jobs Running since before the given time Failed,
+	// returning how many there were
+	FailStale(ctx context.Context, before time.Time, reason string) (int64, error)
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ReportJobRepo implements ReportJobInterface using GORM.
+type ReportJobRepo struct {
+	DB *gorm.DB
+}
+
+// NewReportJobRepo creates a new instance of ReportJobRepo.
+func NewReportJobRepo(db *gorm.DB) ReportJobInterface {
+	return &ReportJobRepo{DB: db}
+}
+
+// GetByReportJobID retrieves a report job by its ReportJobID (UUID)
+func (r *ReportJobRepo) GetByReportJobID(ctx context.Context, reportJobID string) (*ReportJob, error) {
+	var job ReportJob
+	result := r.DB.WithContext(ctx).Where("report_job_id = ?", reportJobID).First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, result.Error
+}
+
+// GetByFarmID retrieves a farm's report jobs, newest first
+func (r *ReportJobRepo) GetByFarmID(ctx context.Context, farmID string) ([]*ReportJob, error) {
+	var jobs []*ReportJob
+	result := r.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("created_at desc").Find(&jobs)
+	return jobs, result.Error
+}
+
+// GetQueued retrieves a farm's Pending or Running job for a report type and
+// period
+func (r *ReportJobRepo) GetQueued(ctx context.Context, farmID, reportType, period string) (*ReportJob, error) {
+	var job ReportJob
+	result := r.DB.WithContext(ctx).Where("farm_id = ? AND type = ? AND period = ? AND status IN ?",
+		farmID, reportType, period, []string{"Pending", "Running"}).
+		Order("created_at desc").First(&job)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &job, result.Error
+}
+
+// GetPending retrieves the IDs of jobs still Pending that were requested
+// before the given time, oldest first
+func (r *ReportJobRepo) GetPending(ctx context.Context, before time.Time) ([]string, error) {
+	var ids []string
+	result := r.DB.WithContext(ctx).Model(&ReportJob{}).
+		Where("status = ? AND created_at < ?", "Pending", before).
+		Order("created_at").Pluck("report_job_id", &ids)
+	return ids, result.Error
+}
+
+// Insert creates a new report job
+func (r *ReportJobRepo) Insert(ctx context.Context, job *ReportJob) error {
+	return r.DB.WithContext(ctx).Create(job).Error
+}
+
+// Claim moves a Pending job to Running, guarding on the Pending status so two
+// workers never render the same report
+func (r *ReportJobRepo) Claim(ctx context.Context, job *ReportJob) (bool, error) {
+	result := r.DB.WithContext(ctx).Model(&ReportJob{}).
+		Where("report_job_id = ? AND status = ?", job.ReportJobID, "Pending").
+		Updates(map[string]any{
+			"status":     "Running",
+			"started_at": job.StartedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish records the outcome of a Running job
+func (r *ReportJobRepo) Finish(ctx context.Context, job *ReportJob) error {
+	return r.DB.WithContext(ctx).Model(&ReportJob{}).
+		Where("report_job_id = ? AND status = ?", job.ReportJobID, "Running").
+		Updates(map[string]any{
+			"status":       job.Status,
+			"error":        job.Error,
+			"size":         job.Size,
+			"completed_at": job.CompletedAt,
+		}).Error
+}
+
+// FailStale marks jobs Running since before the given time Failed, such as
+// those left behind when the server stopped mid-render
+func (r *ReportJobRepo) FailStale(ctx context.Context, before time.Time, reason string) (int64, error) {
+	result := r.DB.WithContext(ctx).Model(&ReportJob{}).
+		Where("status = ? AND started_at < ?", "Running", before).
+		Updates(map[string]any{
+			"status":       "Failed",
+			"error":        reason,
+			"completed_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteByID soft deletes a report job by its ID
+func (r *ReportJobRepo) DeleteByID(ctx context.Context, id int) error {
+	return r.DB.WithContext(ctx).Delete(&ReportJob{}, id).Error
+}
@@ -0,0 +1,61 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedToken represents the revoked_tokens table in the database: the
+// denylist of access tokens invalidated before they expire, as on logout.
+// Entries are only needed until the token would have expired anyway.
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	TokenID   string    `gorm:"not null;size:64;uniqueIndex" json:"tokenId"` // The token's jti claim
+	UserID    string    `gorm:"not null;size:36;index" json:"userId"`        // User the token was issued to
+	ExpiresAt time.Time `gorm:"not null;index" json:"expiresAt"`             // When the token expires
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// RevokedTokenInterface defines the contract for token denylist operations
+type RevokedTokenInterface interface {
+	// Insert adds a token to the denylist; revoking it again is a no-op
+	Insert(ctx context.Context, token *RevokedToken) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+	// DeleteExpired removes the entries of tokens that expired before the
+	// given time and returns how many there were
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RevokedTokenRepo implements RevokedTokenInterface using GORM.
+type RevokedTokenRepo struct {
+	DB *gorm.DB
+}
+
+// NewRevokedTokenRepo creates a new instance of RevokedTokenRepo.
+func NewRevokedTokenRepo(db *gorm.DB) RevokedTokenInterface {
+	return &RevokedTokenRepo{DB: db}
+}
+
+// Insert adds a token to the denylist
+func (r *RevokedTokenRepo) Insert(ctx context.Context, token *RevokedToken) error {
+	return r.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_id"}},
+		DoNothing: true,
+	}).Create(token).Error
+}
+
+// IsRevoked reports whether a token is on the denylist
+func (r *RevokedTokenRepo) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+	result := r.DB.WithContext(ctx).Model(&RevokedToken{}).Where("token_id = ?", tokenID).Count(&count)
+	return count > 0, result.Error
+}
+
+// DeleteExpired removes the entries of tokens that expired before the given time
+func (r *RevokedTokenRepo) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.DB.WithContext(ctx).Where("expires_at < ?", before).Delete(&RevokedToken{})
+	return result.RowsAffected, result.Error
+}
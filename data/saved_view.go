@@ -0,0 +1,97 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedView represents the saved_views table in the database: a named
+// combination of list filters a user keeps for a farm, such as "Sick cattle"
+// for the livestock list with tag=cattle,sick, so the app can offer it as a
+// one-tap view
+type SavedView struct {
+	ID          uint              `gorm:"primaryKey" json:"-"`
+	SavedViewID string            `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"viewId"`
+	FarmID      string            `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	UserID      string            `gorm:"not null;size:36;index" json:"userId"` // Foreign key to User who saved it
+	Name        string            `gorm:"not null" json:"name"`
+	Entity      string            `gorm:"not null" json:"entity"`        // The list filtered, e.g. livestock
+	Params      map[string]string `gorm:"serializer:json" json:"params"` // Query parameters of the list, e.g. tag=organic
+	CreatedAt   time.Time         `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time         `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+// SavedViewInterface defines the contract for saved view operations
+type SavedViewInterface interface {
+	GetBySavedViewID(ctx context.Context, savedViewID string) (*SavedView, error)
+	// GetByName retrieves the view a user saved on a farm under name
+	GetByName(ctx context.Context, farmID, userID, name string) (*SavedView, error)
+	// GetByFarmID retrieves the views a user saved on a farm by name,
+	// optionally for one entity
+	GetByFarmID(ctx context.Context, farmID, userID, entity string) ([]*SavedView, error)
+	Insert(ctx context.Context, view *SavedView) error
+	Update(ctx context.Context, view *SavedView) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// SavedViewRepo implements SavedViewInterface using GORM.
+type SavedViewRepo struct {
+	DB *gorm.DB
+}
+
+// NewSavedViewRepo creates a new instance of SavedViewRepo.
+func NewSavedViewRepo(db *gorm.DB) SavedViewInterface {
+	return &SavedViewRepo{DB: db}
+}
+
+// GetBySavedViewID retrieves a view by its SavedViewID (UUID)
+func (v *SavedViewRepo) GetBySavedViewID(ctx context.Context, savedViewID string) (*SavedView, error) {
+	var view SavedView
+	result := v.DB.WithContext(ctx).Where("saved_view_id = ?", savedViewID).First(&view)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &view, result.Error
+}
+
+// GetByName retrieves a user's view on a farm by name, ignoring case
+func (v *SavedViewRepo) GetByName(ctx context.Context, farmID, userID, name string) (*SavedView, error) {
+	var view SavedView
+	result := v.DB.WithContext(ctx).
+		Where("farm_id = ? AND user_id = ? AND lower(name) = lower(?)", farmID, userID, name).
+		First(&view)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &view, result.Error
+}
+
+// GetByFarmID retrieves a user's views on a farm by name
+func (v *SavedViewRepo) GetByFarmID(ctx context.Context, farmID, userID, entity string) ([]*SavedView, error) {
+	var views []*SavedView
+	query := v.DB.WithContext(ctx).Where("farm_id = ? AND user_id = ?", farmID, userID)
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	result := query.Order("lower(name)").Find(&views)
+	return views, result.Error
+}
+
+// Insert creates a new view in the database
+func (v *SavedViewRepo) Insert(ctx context.Context, view *SavedView) error {
+	return v.DB.WithContext(ctx).Create(view).Error
+}
+
+// Update updates an existing view in the database
+func (v *SavedViewRepo) Update(ctx context.Context, view *SavedView) error {
+	return v.DB.WithContext(ctx).Save(view).Error
+}
+
+// DeleteByID soft deletes a view by its ID
+func (v *SavedViewRepo) DeleteByID(ctx context.Context, id int) error {
+	return v.DB.WithContext(ctx).Delete(&SavedView{}, id).Error
+}
@@ -0,0 +1,136 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Searchable record types
+const (
+	SearchCrop      = "crop"
+	SearchLivestock = "livestock"
+	SearchEmployee  = "employee"
+	SearchDocument  = "document"
+)
+
+// searchConfig is the text search configuration records are indexed and
+// searched with. English stemming lets "sick cows" find a note about a sick cow.
+const searchConfig = "english"
+
+// SearchHit is one record matching a search
+type SearchHit struct {
+	Type      string    `json:"type"` // crop, livestock, employee or document
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"` // Matching text with the matched words in <b></b>
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// searchSource describes how one record type is searched. Text is the SQL
+// expression searched for the record; it must match the expression of the
+// table's search index (see migrations/0002_search_indexes.up.sql) exactly
+// for the index to be used.
+type searchSource struct {
+	table string
+	id    string
+	title string
+	text  string
+}
+
+// searchSources are the record types that can be searched
+var searchSources = map[string]searchSource{
+	SearchCrop: {
+		table: "crops", id: "crop_id", title: "name",
+		text: "coalesce(name, '') || ' ' || coalesce(status, '') || ' ' || coalesce(notes, '')",
+	},
+	SearchLivestock: {
+		table: "livestock", id: "livestock_id", title: "type",
+		text: "coalesce(type, '') || ' ' || coalesce(health_status, '') || ' ' || coalesce(notes, '')",
+	},
+	SearchEmployee: {
+		table: "employees", id: "employee_id", title: "first_name || ' ' || last_name",
+		text: "coalesce(first_name, '') || ' ' || coalesce(last_name, '') || ' ' || coalesce(position, '') || ' ' || coalesce(contact_info, '')",
+	},
+	SearchDocument: {
+		table: "documents", id: "document_id", title: "title",
+		text: "coalesce(type, '') || ' ' || coalesce(title, '') || ' ' || coalesce(number, '') || ' ' || coalesce(issuer, '') || ' ' || coalesce(notes, '')",
+	},
+}
+
+// SearchTypes lists the record types that can be searched, in order
+func SearchTypes() []string {
+	return []string{SearchCrop, SearchLivestock, SearchEmployee, SearchDocument}
+}
+
+// vector is the tsvector expression of a source's text
+func (s searchSource) vector() string {
+	return fmt.Sprintf("to_tsvector('%s', %s)", searchConfig, s.text)
+}
+
+// SearchInterface defines the contract for full-text search across a farm's
+// records
+type SearchInterface interface {
+	// Search returns up to limit of a farm's records of the given types
+	// matching query, best match first. Query is in web search syntax:
+	// words, "quoted phrases", or and -excluded words.
+	Search(ctx context.Context, farmID, query string, types []string, limit int) ([]SearchHit, error)
+}
+
+// SearchRepo implements SearchInterface using PostgreSQL full-text search.
+type SearchRepo struct {
+	DB *gorm.DB
+}
+
+// NewSearchRepo creates a new instance of SearchRepo.
+func NewSearchRepo(db *gorm.DB) SearchInterface {
+	return &SearchRepo{DB: db}
+}
+
+// Search searches each type in turn and merges the hits by rank
+func (s *SearchRepo) Search(ctx context.Context, farmID, query string, types []string, limit int) ([]SearchHit, error) {
+	tsquery := fmt.Sprintf("websearch_to_tsquery('%s', ?)", searchConfig)
+
+	var hits []SearchHit
+	for _, t := range types {
+		source, ok := searchSources[t]
+		if !ok {
+			return nil, fmt.Errorf("unknown search type %q", t)
+		}
+
+		var found []SearchHit
+		result := s.DB.WithContext(ctx).Table(source.table).
+			Select(fmt.Sprintf(`? AS type, %s AS id, %s AS title,
+				ts_headline('%s', %s, %s, 'StartSel=<b>, StopSel=</b>, MaxFragments=2, MaxWords=20, MinWords=5') AS snippet,
+				ts_rank(%s, %s) AS rank, updated_at`,
+				source.id, source.title, searchConfig, source.text, tsquery, source.vector(), tsquery),
+				t, query, query).
+			Where("farm_id = ? AND deleted_at IS NULL", farmID).
+			Where(fmt.Sprintf("%s @@ %s", source.vector(), tsquery), query).
+			Order("rank DESC").
+			Limit(limit).
+			Scan(&found)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		hits = append(hits, found...)
+	}
+
+	slices.SortStableFunc(hits, func(a, b SearchHit) int {
+		switch {
+		case a.Rank > b.Rank:
+			return -1
+		case a.Rank < b.Rank:
+			return 1
+		}
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
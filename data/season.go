@@ -0,0 +1,171 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Season represents the seasons table in the database: a named growing season
+// of a farm, such as "2026 Long Rains", that its crops and transactions are
+// counted towards. Records linked to no season count towards the season their
+// date falls in. Seasons of a farm do not overlap.
+type Season struct {
+	ID        uint           `gorm:"primaryKey" json:"-"`
+	SeasonID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"seasonId"`
+	FarmID    string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name      string         `gorm:"not null" json:"name"`
+	StartDate time.Time      `gorm:"not null" json:"startDate"`             // First day of the season
+	EndDate   time.Time      `gorm:"not null" json:"endDate"`               // Last day of the season
+	Status    string         `gorm:"not null;default:'Open'" json:"status"` // Open, Closed
+	ClosedAt  *time.Time     `json:"closedAt,omitempty"`
+	Notes     string         `json:"notes"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// End returns the day after the season's last day, so the season covers
+// dates in [StartDate, End())
+func (s *Season) End() time.Time {
+	return s.EndDate.AddDate(0, 0, 1)
+}
+
+// SeasonMetrics are the totals of the crops and transactions counted towards
+// a season
+type SeasonMetrics struct {
+	CropsPlanted      int     `json:"cropsPlanted"`
+	CropsHarvested    int     `json:"cropsHarvested"`
+	CropsFailed       int     `json:"cropsFailed"`
+	QuantityHarvested float64 `json:"quantityHarvested"` // Sum of the harvested crops' quantities
+	Income            float64 `json:"income"`
+	Expenses          float64 `json:"expenses"`
+	Net               float64 `json:"net"` // Income less expenses
+}
+
+// SeasonInterface defines the contract for season operations
+type SeasonInterface interface {
+	GetBySeasonID(ctx context.Context, seasonID string) (*Season, error)
+	// GetByFarmID retrieves a farm's seasons, earliest first
+	GetByFarmID(ctx context.Context, farmID string) ([]*Season, error)
+	// GetOverlapping retrieves the farm's other seasons sharing a day with season
+	GetOverlapping(ctx context.Context, season *Season) ([]*Season, error)
+	Insert(ctx context.Context, season *Season) error
+	Update(ctx context.Context, season *Season) error
+	DeleteByID(ctx context.Context, id int) error
+	// Metrics totals the crops planted and the transactions dated in the
+	// season, or linked to it
+	Metrics(ctx context.Context, season *Season) (*SeasonMetrics, error)
+	// TotalsByCategory sums the transactions counted towards the season per
+	// type and category
+	TotalsByCategory(ctx context.Context, season *Season) ([]CategoryTotal, error)
+}
+
+// SeasonRepo implements SeasonInterface using GORM.
+type SeasonRepo struct {
+	DB *gorm.DB
+}
+
+// NewSeasonRepo creates a new instance of SeasonRepo.
+func NewSeasonRepo(db *gorm.DB) SeasonInterface {
+	return &SeasonRepo{DB: db}
+}
+
+// GetBySeasonID retrieves a season by its SeasonID (UUID)
+func (s *SeasonRepo) GetBySeasonID(ctx context.Context, seasonID string) (*Season, error) {
+	var season Season
+	result := s.DB.WithContext(ctx).Where("season_id = ?", seasonID).First(&season)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &season, result.Error
+}
+
+// GetByFarmID retrieves a farm's seasons ordered by start date
+func (s *SeasonRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Season, error) {
+	var seasons []*Season
+	result := s.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("start_date").Find(&seasons)
+	return seasons, result.Error
+}
+
+// GetOverlapping retrieves the farm's other seasons sharing a day with season
+func (s *SeasonRepo) GetOverlapping(ctx context.Context, season *Season) ([]*Season, error) {
+	var seasons []*Season
+	query := s.DB.WithContext(ctx).Where("farm_id = ?", season.FarmID).
+		Where("start_date <= ? AND end_date >= ?", season.EndDate, season.StartDate)
+	if season.SeasonID != "" {
+		query = query.Where("season_id <> ?", season.SeasonID)
+	}
+	result := query.Order("start_date").Find(&seasons)
+	return seasons, result.Error
+}
+
+// Insert creates a new season in the database
+func (s *SeasonRepo) Insert(ctx context.Context, season *Season) error {
+	return s.DB.WithContext(ctx).Create(season).Error
+}
+
+// Update updates an existing season in the database
+func (s *SeasonRepo) Update(ctx context.Context, season *Season) error {
+	return s.DB.WithContext(ctx).Save(season).Error
+}
+
+// DeleteByID soft deletes a season by its ID
+func (s *SeasonRepo) DeleteByID(ctx context.Context, id int) error {
+	return s.DB.WithContext(ctx).Delete(&Season{}, id).Error
+}
+
+// Metrics totals the season's crops, by planting date, and transactions, by
+// date. A record linked to a season counts towards that season only.
+func (s *SeasonRepo) Metrics(ctx context.Context, season *Season) (*SeasonMetrics, error) {
+	var metrics, money SeasonMetrics
+	db := s.DB.WithContext(ctx)
+
+	err := db.Model(&Crop{}).
+		Select("COUNT(*) AS crops_planted, "+
+			"COUNT(*) FILTER (WHERE status = 'Harvested') AS crops_harvested, "+
+			"COUNT(*) FILTER (WHERE status = 'Failed') AS crops_failed, "+
+			"COALESCE(SUM(quantity) FILTER (WHERE status = 'Harvested'), 0) AS quantity_harvested").
+		Where("farm_id = ?", season.FarmID).
+		Where("season_id = ? OR (season_id IS NULL AND planting_date >= ? AND planting_date < ?)",
+			season.SeasonID, season.StartDate, season.End()).
+		Scan(&metrics).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = seasonTransactions(db, season).
+		Select("COALESCE(SUM(amount) FILTER (WHERE type = 'Income'), 0) AS income, " +
+			"COALESCE(SUM(amount) FILTER (WHERE type = 'Expense'), 0) AS expenses").
+		Scan(&money).Error
+	if err != nil {
+		return nil, err
+	}
+	metrics.Income, metrics.Expenses = money.Income, money.Expenses
+	metrics.Net = metrics.Income - metrics.Expenses
+	return &metrics, nil
+}
+
+// TotalsByCategory sums the season's transactions, linked to it or dated in
+// it, per type and category
+func (s *SeasonRepo) TotalsByCategory(ctx context.Context, season *Season) ([]CategoryTotal, error) {
+	var totals []CategoryTotal
+	result := seasonTransactions(s.DB.WithContext(ctx), season).
+		Select("type, category, SUM(amount) AS total").
+		Group("type, category").Order("type, category").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// seasonTransactions scopes a query to the transactions counted towards season
+func seasonTransactions(db *gorm.DB, season *Season) *gorm.DB {
+	return db.Model(&Transaction{}).
+		Where("farm_id = ?", season.FarmID).
+		Where("season_id = ? OR (season_id IS NULL AND date >= ? AND date < ?)",
+			season.SeasonID, season.StartDate, season.End())
+}
@@ -0,0 +1,86 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ServiceProvider represents the service_providers table in the database: a
+// contractor a farm calls on, such as its vet, mechanic or transporter.
+// Maintenance records and crop incidents name the provider who did the work,
+// so the farm can look up who fixed the pump last time.
+type ServiceProvider struct {
+	ID                uint           `gorm:"primaryKey" json:"-"`
+	ServiceProviderID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"serviceProviderId"`
+	FarmID            string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name              string         `gorm:"not null" json:"name"`
+	Category          string         `gorm:"not null;index" json:"category"` // Veterinarian, Mechanic, Transporter, Agronomist, Electrician, Other
+	ContactName       string         `json:"contactName"`
+	PhoneNumber       string         `json:"phoneNumber"`
+	Email             string         `json:"email"`
+	Address           string         `json:"address"`
+	Notes             string         `json:"notes"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// ServiceProviderInterface defines the contract for service provider operations
+type ServiceProviderInterface interface {
+	GetByServiceProviderID(ctx context.Context, serviceProviderID string) (*ServiceProvider, error)
+	// GetByFarmID retrieves a farm's providers by name, optionally of one
+	// category
+	GetByFarmID(ctx context.Context, farmID, category string) ([]*ServiceProvider, error)
+	Insert(ctx context.Context, provider *ServiceProvider) error
+	Update(ctx context.Context, provider *ServiceProvider) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ServiceProviderRepo implements ServiceProviderInterface using GORM.
+type ServiceProviderRepo struct {
+	DB *gorm.DB
+}
+
+// NewServiceProviderRepo creates a new instance of ServiceProviderRepo.
+func NewServiceProviderRepo(db *gorm.DB) ServiceProviderInterface {
+	return &ServiceProviderRepo{DB: db}
+}
+
+// GetByServiceProviderID retrieves a provider by its ServiceProviderID (UUID)
+func (s *ServiceProviderRepo) GetByServiceProviderID(ctx context.Context, serviceProviderID string) (*ServiceProvider, error) {
+	var provider ServiceProvider
+	result := s.DB.WithContext(ctx).Where("service_provider_id = ?", serviceProviderID).First(&provider)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &provider, result.Error
+}
+
+// GetByFarmID retrieves a farm's providers by name
+func (s *ServiceProviderRepo) GetByFarmID(ctx context.Context, farmID, category string) ([]*ServiceProvider, error) {
+	var providers []*ServiceProvider
+	query := s.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	result := query.Order("lower(name)").Find(&providers)
+	return providers, result.Error
+}
+
+// Insert creates a new provider in the database
+func (s *ServiceProviderRepo) Insert(ctx context.Context, provider *ServiceProvider) error {
+	return s.DB.WithContext(ctx).Create(provider).Error
+}
+
+// Update updates an existing provider in the database
+func (s *ServiceProviderRepo) Update(ctx context.Context, provider *ServiceProvider) error {
+	return s.DB.WithContext(ctx).Save(provider).Error
+}
+
+// DeleteByID soft deletes a provider by its ID
+func (s *ServiceProviderRepo) DeleteByID(ctx context.Context, id int) error {
+	return s.DB.WithContext(ctx).Delete(&ServiceProvider{}, id).Error
+}
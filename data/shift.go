@@ -0,0 +1,107 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Shift represents the shifts table in the database: a planned stretch of
+// work for one employee, such as the morning milking or a harvest crew on a
+// field. Shifts make up the farm's roster; attendance records what was
+// actually worked.
+type Shift struct {
+	ID         uint           `gorm:"primaryKey" json:"-"`
+	ShiftID    string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"shiftId"`
+	FarmID     string         `gorm:"not null;size:36;index" json:"farmId"`     // Foreign key to Farm
+	EmployeeID string         `gorm:"not null;size:36;index" json:"employeeId"` // Foreign key to Employee
+	FieldID    *string        `gorm:"size:36;index" json:"fieldId"`             // Optional field the shift works on
+	StartsAt   time.Time      `gorm:"not null;index" json:"startsAt"`
+	EndsAt     time.Time      `gorm:"not null" json:"endsAt"`
+	Role       string         `gorm:"not null" json:"role"` // e.g. Milking, Harvest, Feeding
+	Location   string         `json:"location"`             // Where to report when not a field, e.g. Milking parlour
+	Notes      string         `json:"notes"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Employee *Employee `gorm:"foreignKey:EmployeeID;references:EmployeeID" json:"employee,omitempty"`
+	Field    *Field    `gorm:"foreignKey:FieldID;references:FieldID" json:"field,omitempty"`
+}
+
+// Hours is the length of the shift in hours
+func (s *Shift) Hours() float64 {
+	return s.EndsAt.Sub(s.StartsAt).Hours()
+}
+
+// ShiftInterface defines the contract for shift operations
+type ShiftInterface interface {
+	GetByShiftID(ctx context.Context, shiftID string) (*Shift, error)
+	// GetByFarmID retrieves a farm's shifts starting in [from, to), with
+	// their employee and field
+	GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*Shift, error)
+	// GetOverlapping retrieves an employee's shifts that overlap [start, end),
+	// leaving out the shift excludeID
+	GetOverlapping(ctx context.Context, employeeID string, start, end time.Time, excludeID string) ([]*Shift, error)
+	Insert(ctx context.Context, shift *Shift) error
+	Update(ctx context.Context, shift *Shift) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// ShiftRepo implements ShiftInterface using GORM.
+type ShiftRepo struct {
+	DB *gorm.DB
+}
+
+// NewShiftRepo creates a new instance of ShiftRepo.
+func NewShiftRepo(db *gorm.DB) ShiftInterface {
+	return &ShiftRepo{DB: db}
+}
+
+// GetByShiftID retrieves a shift by its ShiftID (UUID)
+func (s *ShiftRepo) GetByShiftID(ctx context.Context, shiftID string) (*Shift, error) {
+	var shift Shift
+	result := s.DB.WithContext(ctx).Where("shift_id = ?", shiftID).First(&shift)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &shift, result.Error
+}
+
+// GetByFarmID retrieves a farm's shifts by start time
+func (s *ShiftRepo) GetByFarmID(ctx context.Context, farmID string, from, to *time.Time) ([]*Shift, error) {
+	var shifts []*Shift
+	query := dateRange(s.DB.WithContext(ctx).Where("farm_id = ?", farmID), "starts_at", from, to)
+	result := query.Preload("Employee").Preload("Field").Order("starts_at, employee_id").Find(&shifts)
+	return shifts, result.Error
+}
+
+// GetOverlapping retrieves an employee's shifts overlapping [start, end) by
+// start time
+func (s *ShiftRepo) GetOverlapping(ctx context.Context, employeeID string, start, end time.Time, excludeID string) ([]*Shift, error) {
+	var shifts []*Shift
+	query := s.DB.WithContext(ctx).Where("employee_id = ? AND starts_at < ? AND ends_at > ?", employeeID, end, start)
+	if excludeID != "" {
+		query = query.Where("shift_id <> ?", excludeID)
+	}
+	result := query.Order("starts_at").Find(&shifts)
+	return shifts, result.Error
+}
+
+// Insert creates a new shift in the database
+func (s *ShiftRepo) Insert(ctx context.Context, shift *Shift) error {
+	return s.DB.WithContext(ctx).Omit("Employee", "Field").Create(shift).Error
+}
+
+// Update updates an existing shift in the database
+func (s *ShiftRepo) Update(ctx context.Context, shift *Shift) error {
+	return s.DB.WithContext(ctx).Omit("Employee", "Field").Save(shift).Error
+}
+
+// DeleteByID soft deletes a shift by its ID
+func (s *ShiftRepo) DeleteByID(ctx context.Context, id int) error {
+	return s.DB.WithContext(ctx).Delete(&Shift{}, id).Error
+}
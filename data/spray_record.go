@@ -0,0 +1,130 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SprayRecord represents the spray_records table in the database: one
+// application of an agrochemical from the chemical store to a crop, with the
+// conditions it was applied in, as kept for GAP certification audits. The
+// crop must not be harvested before SafeHarvestDate, the end of the
+// product's pre-harvest interval.
+type SprayRecord struct {
+	ID                     uint           `gorm:"primaryKey" json:"-"`
+	SprayRecordID          string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"sprayRecordId"`
+	FarmID                 string         `gorm:"not null;size:36;index" json:"farmId"`                // Foreign key to Farm
+	CropID                 string         `gorm:"not null;size:36;index" json:"cropId"`                // Crop sprayed
+	FieldID                *string        `gorm:"size:36;index" json:"fieldId,omitempty"`              // The crop's field when sprayed
+	ChemicalProductID      string         `gorm:"not null;size:36;index" json:"chemicalProductId"`     // Product batch drawn from the store
+	ChemicalUsageID        string         `gorm:"not null;size:36;uniqueIndex" json:"chemicalUsageId"` // Store usage entry the product was drawn with
+	Date                   time.Time      `gorm:"not null;index" json:"date"`
+	Rate                   float64        `gorm:"not null" json:"rate"`         // Application rate
+	RateUnit               string         `gorm:"not null" json:"rateUnit"`     // e.g. L/ha, ml/20L
+	AreaSprayed            float64        `json:"areaSprayed"`                  // Hectares
+	QuantityUsed           float64        `gorm:"not null" json:"quantityUsed"` // In the product's unit
+	Applicator             string         `gorm:"not null" json:"applicator"`
+	ApplicatorEmployeeID   *string        `gorm:"size:36" json:"applicatorEmployeeId,omitempty"` // Optional link to Employee
+	PPEConfirmed           bool           `gorm:"not null" json:"ppeConfirmed"`
+	WindSpeed              *float64       `json:"windSpeed,omitempty"`   // km/h
+	Temperature            *float64       `json:"temperature,omitempty"` // °C
+	Humidity               *float64       `json:"humidity,omitempty"`    // Relative, %
+	WeatherConditions      string         `json:"weatherConditions"`     // e.g. Sunny, calm; Overcast
+	PreHarvestIntervalDays int            `gorm:"not null;default:0" json:"preHarvestIntervalDays"`
+	SafeHarvestDate        time.Time      `gorm:"not null;index" json:"safeHarvestDate"` // Date plus the pre-harvest interval
+	Target                 string         `json:"target"`                                // Pest, disease or weed sprayed against
+	Notes                  string         `json:"notes"`
+	CreatedAt              time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt              time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt              gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Crop            *Crop            `gorm:"foreignKey:CropID;references:CropID" json:"crop,omitempty"`
+	ChemicalProduct *ChemicalProduct `gorm:"foreignKey:ChemicalProductID;references:ChemicalProductID" json:"chemicalProduct,omitempty"`
+}
+
+// SprayRecordFilter narrows a farm's spray records. Empty fields match all.
+type SprayRecordFilter struct {
+	CropID            string
+	FieldID           string
+	ChemicalProductID string
+	From              *time.Time // Sprayed on or after
+	To                *time.Time // Sprayed before
+}
+
+// SprayRecordInterface defines the contract for spray record operations
+type SprayRecordInterface interface {
+	GetBySprayRecordID(ctx context.Context, sprayRecordID string) (*SprayRecord, error)
+	// GetByFarmID returns a farm's spray records, most recent first
+	GetByFarmID(ctx context.Context, farmID string, filter SprayRecordFilter) ([]*SprayRecord, error)
+	// GetWithholding returns a farm's spray records whose pre-harvest
+	// interval has not ended at the given time, on crops not yet harvested,
+	// latest safe harvest date first
+	GetWithholding(ctx context.Context, farmID string, at time.Time) ([]*SprayRecord, error)
+	Insert(ctx context.Context, record *SprayRecord) error
+}
+
+// SprayRecordRepo implements SprayRecordInterface using GORM.
+type SprayRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewSprayRecordRepo creates a new instance of SprayRecordRepo.
+func NewSprayRecordRepo(db *gorm.DB) SprayRecordInterface {
+	return &SprayRecordRepo{DB: db}
+}
+
+// GetBySprayRecordID retrieves a spray record by its SprayRecordID (UUID)
+func (s *SprayRecordRepo) GetBySprayRecordID(ctx context.Context, sprayRecordID string) (*SprayRecord, error) {
+	var record SprayRecord
+	result := s.DB.WithContext(ctx).Preload("Crop").Preload("ChemicalProduct").
+		Where("spray_record_id = ?", sprayRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's spray records, most recent first
+func (s *SprayRecordRepo) GetByFarmID(ctx context.Context, farmID string, filter SprayRecordFilter) ([]*SprayRecord, error) {
+	var records []*SprayRecord
+	query := s.DB.WithContext(ctx).Preload("Crop").Preload("ChemicalProduct").Where("farm_id = ?", farmID)
+	if filter.CropID != "" {
+		query = query.Where("crop_id = ?", filter.CropID)
+	}
+	if filter.FieldID != "" {
+		query = query.Where("field_id = ?", filter.FieldID)
+	}
+	if filter.ChemicalProductID != "" {
+		query = query.Where("chemical_product_id = ?", filter.ChemicalProductID)
+	}
+	if filter.From != nil {
+		query = query.Where("date >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("date < ?", *filter.To)
+	}
+	result := query.Order("date desc, id desc").Find(&records)
+	return records, result.Error
+}
+
+// GetWithholding retrieves the spray records still inside their pre-harvest
+// interval on a farm's unharvested crops
+func (s *SprayRecordRepo) GetWithholding(ctx context.Context, farmID string, at time.Time) ([]*SprayRecord, error) {
+	var records []*SprayRecord
+	result := s.DB.WithContext(ctx).Preload("Crop").Preload("ChemicalProduct").
+		Joins("JOIN crops ON crops.crop_id = spray_records.crop_id AND crops.deleted_at IS NULL").
+		Where("spray_records.farm_id = ? AND spray_records.safe_harvest_date > ?", farmID, at).
+		Where("crops.status <> ?", "Harvested").
+		Order("spray_records.safe_harvest_date desc, spray_records.id").
+		Find(&records)
+	return records, result.Error
+}
+
+// Insert adds a new spray record
+func (s *SprayRecordRepo) Insert(ctx context.Context, record *SprayRecord) error {
+	return s.DB.WithContext(ctx).Omit("Crop", "ChemicalProduct").Create(record).Error
+}
@@ -0,0 +1,80 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supplier represents the suppliers table in the database: a business a farm
+// buys inputs such as seed, feed and fertilizer from.
+type Supplier struct {
+	ID          uint           `gorm:"primaryKey" json:"-"`
+	SupplierID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"supplierId"`
+	FarmID      string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name        string         `gorm:"not null" json:"name"`
+	ContactName string         `json:"contactName"`
+	PhoneNumber string         `json:"phoneNumber"`
+	Email       string         `json:"email"`
+	Address     string         `json:"address"`
+	Notes       string         `json:"notes"`
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// SupplierInterface defines the contract for supplier operations
+type SupplierInterface interface {
+	GetBySupplierID(ctx context.Context, supplierID string) (*Supplier, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Supplier, error)
+	Insert(ctx context.Context, supplier *Supplier) error
+	Update(ctx context.Context, supplier *Supplier) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// SupplierRepo implements SupplierInterface using GORM.
+type SupplierRepo struct {
+	DB *gorm.DB
+}
+
+// NewSupplierRepo creates a new instance of SupplierRepo.
+func NewSupplierRepo(db *gorm.DB) SupplierInterface {
+	return &SupplierRepo{DB: db}
+}
+
+// GetBySupplierID retrieves a supplier by its SupplierID (UUID)
+func (s *SupplierRepo) GetBySupplierID(ctx context.Context, supplierID string) (*Supplier, error) {
+	var supplier Supplier
+	result := s.DB.WithContext(ctx).Where("supplier_id = ?", supplierID).First(&supplier)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &supplier, result.Error
+}
+
+// GetByFarmID retrieves a farm's suppliers ordered by name
+func (s *SupplierRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Supplier, error) {
+	var suppliers []*Supplier
+	result := s.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("name").Find(&suppliers)
+	return suppliers, result.Error
+}
+
+// Insert creates a new supplier in the database
+func (s *SupplierRepo) Insert(ctx context.Context, supplier *Supplier) error {
+	return s.DB.WithContext(ctx).Create(supplier).Error
+}
+
+// Update updates an existing supplier in the database
+func (s *SupplierRepo) Update(ctx context.Context, supplier *Supplier) error {
+	return s.DB.WithContext(ctx).Save(supplier).Error
+}
+
+// DeleteByID soft deletes a supplier by its ID
+func (s *SupplierRepo) DeleteByID(ctx context.Context, id int) error {
+	return s.DB.WithContext(ctx).Delete(&Supplier{}, id).Error
+}
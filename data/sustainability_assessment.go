@@ -0,0 +1,150 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SustainabilityAssessment represents the sustainability_assessments table in
+// the database: a farm's self-assessment against its checklist for a season.
+type SustainabilityAssessment struct {
+	ID                         uint           `gorm:"primaryKey" json:"-"`
+	SustainabilityAssessmentID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"assessmentId"`
+	FarmID                     string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Season                     string         `gorm:"not null" json:"season"`               // e.g. "2025 Long Rains"
+	AssessedAt                 time.Time      `gorm:"not null" json:"assessedAt"`
+	AssessedBy                 string         `json:"assessedBy"`
+	Status                     string         `gorm:"not null;default:'Draft'" json:"status"` // Draft, Submitted
+	Score                      float64        `json:"score"`                                  // Points achieved
+	MaxScore                   float64        `json:"maxScore"`                               // Points available, excluding not applicable practices
+	ScorePercent               float64        `json:"scorePercent"`
+	Notes                      string         `json:"notes"`
+	CreatedAt                  time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt                  time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt                  gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm      *Farm                    `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+	Responses []SustainabilityResponse `gorm:"foreignKey:SustainabilityAssessmentID;references:SustainabilityAssessmentID" json:"responses,omitempty"`
+}
+
+// SustainabilityResponse represents the sustainability_responses table in the
+// database: the answer to one checklist practice within an assessment.
+type SustainabilityResponse struct {
+	ID                         uint      `gorm:"primaryKey" json:"-"`
+	SustainabilityResponseID   string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"responseId"`
+	SustainabilityAssessmentID string    `gorm:"not null;size:36;index" json:"assessmentId"` // Foreign key to SustainabilityAssessment
+	SustainabilityPracticeID   string    `gorm:"not null;size:36" json:"practiceId"`         // Foreign key to SustainabilityPractice
+	Status                     string    `gorm:"not null" json:"status"`                     // Implemented, Partial, Not Implemented, Not Applicable
+	Evidence                   string    `json:"evidence"`                                   // Description of the supporting evidence
+	EvidenceURL                string    `json:"evidenceUrl"`
+	Notes                      string    `json:"notes"`
+	CreatedAt                  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt                  time.Time `gorm:"autoUpdateTime" json:"updatedAt"`
+
+	// Relationships
+	Practice *SustainabilityPractice `gorm:"foreignKey:SustainabilityPracticeID;references:SustainabilityPracticeID" json:"practice,omitempty"`
+}
+
+// CalculateScore sets Score, MaxScore and ScorePercent from the responses,
+// using practices (keyed by SustainabilityPracticeID) for the weights.
+// Implemented earns the full weight, Partial half, and Not Applicable is left
+// out of the maximum.
+func (a *SustainabilityAssessment) CalculateScore(practices map[string]*SustainabilityPractice) {
+	a.Score, a.MaxScore, a.ScorePercent = 0, 0, 0
+	for _, response := range a.Responses {
+		practice, ok := practices[response.SustainabilityPracticeID]
+		if !ok || response.Status == "Not Applicable" {
+			continue
+		}
+		a.MaxScore += practice.Weight
+		switch response.Status {
+		case "Implemented":
+			a.Score += practice.Weight
+		case "Partial":
+			a.Score += practice.Weight / 2
+		}
+	}
+	if a.MaxScore > 0 {
+		a.ScorePercent = a.Score / a.MaxScore * 100
+	}
+}
+
+// SustainabilityAssessmentInterface defines the contract for sustainability assessment operations
+type SustainabilityAssessmentInterface interface {
+	GetBySustainabilityAssessmentID(ctx context.Context, assessmentID string) (*SustainabilityAssessment, error)
+	GetByFarmID(ctx context.Context, farmID, season string) ([]*SustainabilityAssessment, error)
+	Insert(ctx context.Context, assessment *SustainabilityAssessment) error
+	Update(ctx context.Context, assessment *SustainabilityAssessment) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// SustainabilityAssessmentRepo implements SustainabilityAssessmentInterface using GORM.
+type SustainabilityAssessmentRepo struct {
+	DB *gorm.DB
+}
+
+// NewSustainabilityAssessmentRepo creates a new instance of SustainabilityAssessmentRepo.
+func NewSustainabilityAssessmentRepo(db *gorm.DB) SustainabilityAssessmentInterface {
+	return &SustainabilityAssessmentRepo{DB: db}
+}
+
+// GetBySustainabilityAssessmentID retrieves an assessment with its responses
+// and their practices by its SustainabilityAssessmentID (UUID)
+func (s *SustainabilityAssessmentRepo) GetBySustainabilityAssessmentID(ctx context.Context, assessmentID string) (*SustainabilityAssessment, error) {
+	var assessment SustainabilityAssessment
+	result := s.DB.WithContext(ctx).Where("sustainability_assessment_id = ?", assessmentID).
+		Preload("Responses.Practice", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		First(&assessment)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &assessment, result.Error
+}
+
+// GetByFarmID retrieves a farm's assessments, optionally for one season
+func (s *SustainabilityAssessmentRepo) GetByFarmID(ctx context.Context, farmID, season string) ([]*SustainabilityAssessment, error) {
+	var assessments []*SustainabilityAssessment
+	query := s.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if season != "" {
+		query = query.Where("season = ?", season)
+	}
+	result := query.Order("assessed_at desc").Find(&assessments)
+	return assessments, result.Error
+}
+
+// Insert creates a new assessment together with its responses
+func (s *SustainabilityAssessmentRepo) Insert(ctx context.Context, assessment *SustainabilityAssessment) error {
+	return s.DB.WithContext(ctx).Create(assessment).Error
+}
+
+// Update saves an assessment and replaces its responses in a single transaction
+func (s *SustainabilityAssessmentRepo) Update(ctx context.Context, assessment *SustainabilityAssessment) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sustainability_assessment_id = ?", assessment.SustainabilityAssessmentID).
+			Delete(&SustainabilityResponse{}).Error; err != nil {
+			return err
+		}
+		for i := range assessment.Responses {
+			assessment.Responses[i].ID = 0
+			assessment.Responses[i].SustainabilityResponseID = ""
+			assessment.Responses[i].SustainabilityAssessmentID = assessment.SustainabilityAssessmentID
+		}
+		if len(assessment.Responses) > 0 {
+			if err := tx.Omit("Practice").Create(&assessment.Responses).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Responses").Save(assessment).Error
+	})
+}
+
+// DeleteByID soft deletes an assessment by its ID
+func (s *SustainabilityAssessmentRepo) DeleteByID(ctx context.Context, id int) error {
+	return s.DB.WithContext(ctx).Delete(&SustainabilityAssessment{}, id).Error
+}
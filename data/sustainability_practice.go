@@ -0,0 +1,104 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SustainabilityPractice represents the sustainability_practices table in the
+// database. Each row is one checklist item a farm assesses itself against.
+type SustainabilityPractice struct {
+	ID                       uint           `gorm:"primaryKey" json:"-"`
+	SustainabilityPracticeID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"practiceId"`
+	FarmID                   string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Category                 string         `gorm:"not null" json:"category"`             // Cover Cropping, Manure Management, IPM, Soil, Water, Biodiversity
+	Name                     string         `gorm:"not null" json:"name"`
+	Description              string         `json:"description"`
+	Weight                   float64        `gorm:"not null;default:1" json:"weight"` // Points awarded when fully implemented
+	EvidenceRequired         bool           `gorm:"default:false" json:"evidenceRequired"`
+	Active                   bool           `gorm:"default:true" json:"active"`
+	CreatedAt                time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt                time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt                gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// DefaultSustainabilityPractices is the standard checklist a farm can start from
+var DefaultSustainabilityPractices = []SustainabilityPractice{
+	{Category: "Cover Cropping", Name: "Cover crops sown after main harvest", Description: "Fields are not left bare between seasons", Weight: 2, EvidenceRequired: true},
+	{Category: "Cover Cropping", Name: "Legumes included in rotation", Description: "Nitrogen-fixing crops are part of the rotation plan", Weight: 1},
+	{Category: "Manure Management", Name: "Manure stored covered and away from water", Description: "Manure heaps are covered and at least 30 m from water sources", Weight: 2, EvidenceRequired: true},
+	{Category: "Manure Management", Name: "Manure applied to a nutrient plan", Description: "Application rates follow a written nutrient management plan", Weight: 1},
+	{Category: "IPM", Name: "Pest scouting records kept", Description: "Crops are scouted regularly and findings recorded", Weight: 2, EvidenceRequired: true},
+	{Category: "IPM", Name: "Action thresholds used before spraying", Description: "Chemicals are applied only when pest levels exceed thresholds", Weight: 2},
+	{Category: "IPM", Name: "Biological or cultural controls used", Description: "Non-chemical controls are used where available", Weight: 1},
+	{Category: "Soil", Name: "Soil tested in the last three years", Description: "Soil analysis guides fertilizer decisions", Weight: 1, EvidenceRequired: true},
+	{Category: "Water", Name: "Water use metered", Description: "Abstraction and irrigation volumes are measured", Weight: 1},
+	{Category: "Biodiversity", Name: "Buffer strips along watercourses", Description: "Uncultivated margins protect streams and wildlife", Weight: 1},
+}
+
+// SustainabilityPracticeInterface defines the contract for sustainability practice operations
+type SustainabilityPracticeInterface interface {
+	GetBySustainabilityPracticeID(ctx context.Context, practiceID string) (*SustainabilityPractice, error)
+	GetByFarmID(ctx context.Context, farmID string, activeOnly bool) ([]*SustainabilityPractice, error)
+	Insert(ctx context.Context, practice *SustainabilityPractice) error
+	InsertMany(ctx context.Context, practices []*SustainabilityPractice) error
+	Update(ctx context.Context, practice *SustainabilityPractice) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// SustainabilityPracticeRepo implements SustainabilityPracticeInterface using GORM.
+type SustainabilityPracticeRepo struct {
+	DB *gorm.DB
+}
+
+// NewSustainabilityPracticeRepo creates a new instance of SustainabilityPracticeRepo.
+func NewSustainabilityPracticeRepo(db *gorm.DB) SustainabilityPracticeInterface {
+	return &SustainabilityPracticeRepo{DB: db}
+}
+
+// GetBySustainabilityPracticeID retrieves a practice by its SustainabilityPracticeID (UUID)
+func (s *SustainabilityPracticeRepo) GetBySustainabilityPracticeID(ctx context.Context, practiceID string) (*SustainabilityPractice, error) {
+	var practice SustainabilityPractice
+	result := s.DB.WithContext(ctx).Where("sustainability_practice_id = ?", practiceID).First(&practice)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &practice, result.Error
+}
+
+// GetByFarmID retrieves a farm's checklist, optionally only the active practices
+func (s *SustainabilityPracticeRepo) GetByFarmID(ctx context.Context, farmID string, activeOnly bool) ([]*SustainabilityPractice, error) {
+	var practices []*SustainabilityPractice
+	query := s.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	result := query.Order("category, name").Find(&practices)
+	return practices, result.Error
+}
+
+// Insert creates a new practice in the database
+func (s *SustainabilityPracticeRepo) Insert(ctx context.Context, practice *SustainabilityPractice) error {
+	return s.DB.WithContext(ctx).Create(practice).Error
+}
+
+// InsertMany creates several practices in a single statement
+func (s *SustainabilityPracticeRepo) InsertMany(ctx context.Context, practices []*SustainabilityPractice) error {
+	return s.DB.WithContext(ctx).Create(&practices).Error
+}
+
+// Update updates an existing practice in the database
+func (s *SustainabilityPracticeRepo) Update(ctx context.Context, practice *SustainabilityPractice) error {
+	return s.DB.WithContext(ctx).Save(practice).Error
+}
+
+// DeleteByID soft deletes a practice by its ID
+func (s *SustainabilityPracticeRepo) DeleteByID(ctx context.Context, id int) error {
+	return s.DB.WithContext(ctx).Delete(&SustainabilityPractice{}, id).Error
+}
@@ -0,0 +1,180 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Synced entities, as named in sync changes
+const (
+	SyncField     = "field"
+	SyncCrop      = "crop"
+	SyncLivestock = "livestock"
+	SyncEmployee  = "employee"
+)
+
+// SyncChange is a record of a farm that was created, updated or soft deleted.
+// A deleted record is sent as a tombstone, without the record itself.
+type SyncChange struct {
+	Entity    string    `json:"entity"`
+	ID        string    `json:"id"`
+	Version   int       `json:"version"`
+	ChangedAt time.Time `json:"changedAt"`
+	Deleted   bool      `json:"deleted"`
+	Record    any       `json:"record,omitempty"`
+}
+
+// SyncMapping represents the sync_mappings table in the database: the record
+// an offline client's create was turned into, so a create pushed again after
+// a lost response is not applied twice
+type SyncMapping struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	FarmID    string    `gorm:"not null;size:36;uniqueIndex:idx_sync_mapping" json:"farmId"`
+	Entity    string    `gorm:"not null;uniqueIndex:idx_sync_mapping" json:"entity"`
+	ClientID  string    `gorm:"not null;uniqueIndex:idx_sync_mapping" json:"clientId"` // The client's own ID for the record
+	RecordID  string    `gorm:"not null;size:36" json:"recordId"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// SyncInterface defines the contract for offline sync operations
+type SyncInterface interface {
+	// Changes returns the synced records of a farm changed at or after since,
+	// oldest change first. At most limit changes of each entity are read;
+	// when an entity has more, the changes are cut at the last one read and
+	// more is true.
+	Changes(ctx context.Context, farmID string, since time.Time, limit int) (changes []SyncChange, more bool, err error)
+	// Deleted reports whether a synced record has been soft deleted
+	Deleted(ctx context.Context, entity, id string) (bool, error)
+	GetMapping(ctx context.Context, farmID, entity, clientID string) (*SyncMapping, error)
+	InsertMapping(ctx context.Context, mapping *SyncMapping) error
+}
+
+// SyncRepo implements SyncInterface using GORM.
+type SyncRepo struct {
+	DB *gorm.DB
+}
+
+// NewSyncRepo creates a new instance of SyncRepo.
+func NewSyncRepo(db *gorm.DB) SyncInterface {
+	return &SyncRepo{DB: db}
+}
+
+// changedOrder orders records by when they last changed
+const changedOrder = "GREATEST(updated_at, COALESCE(deleted_at, updated_at)), id"
+
+// changesOf reads up to limit+1 records of one entity changed at or after
+// since, including soft-deleted ones, and turns them into changes
+func changesOf[T any](db *gorm.DB, entity, farmID string, since time.Time, limit int,
+	describe func(*T) (id string, version int, updated time.Time, deleted gorm.DeletedAt)) ([]SyncChange, error) {
+	var records []*T
+	result := db.Unscoped().
+		Where("farm_id = ? AND (updated_at >= ? OR deleted_at >= ?)", farmID, since, since).
+		Order(changedOrder).Limit(limit + 1).Find(&records)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	changes := make([]SyncChange, len(records))
+	for i, record := range records {
+		id, version, updated, deleted := describe(record)
+		changes[i] = SyncChange{Entity: entity, ID: id, Version: version, ChangedAt: updated, Record: record}
+		if deleted.Valid {
+			changes[i].Deleted, changes[i].Record = true, nil
+			if deleted.Time.After(updated) {
+				changes[i].ChangedAt = deleted.Time
+			}
+		}
+	}
+	return changes, nil
+}
+
+// Changes returns a farm's fields, crops, livestock and employees changed at
+// or after since
+func (s *SyncRepo) Changes(ctx context.Context, farmID string, since time.Time, limit int) ([]SyncChange, bool, error) {
+	var byEntity [4][]SyncChange
+	var err error
+	if byEntity[0], err = changesOf(s.DB.WithContext(ctx), SyncField, farmID, since, limit, func(f *Field) (string, int, time.Time, gorm.DeletedAt) {
+		return f.FieldID, f.Version, f.UpdatedAt, f.DeletedAt
+	}); err != nil {
+		return nil, false, err
+	}
+	if byEntity[1], err = changesOf(s.DB.WithContext(ctx), SyncCrop, farmID, since, limit, func(c *Crop) (string, int, time.Time, gorm.DeletedAt) {
+		return c.CropID, c.Version, c.UpdatedAt, c.DeletedAt
+	}); err != nil {
+		return nil, false, err
+	}
+	if byEntity[2], err = changesOf(s.DB.WithContext(ctx), SyncLivestock, farmID, since, limit, func(l *Livestock) (string, int, time.Time, gorm.DeletedAt) {
+		return l.LivestockID, l.Version, l.UpdatedAt, l.DeletedAt
+	}); err != nil {
+		return nil, false, err
+	}
+	if byEntity[3], err = changesOf(s.DB.WithContext(ctx), SyncEmployee, farmID, since, limit, func(e *Employee) (string, int, time.Time, gorm.DeletedAt) {
+		return e.EmployeeID, e.Version, e.UpdatedAt, e.DeletedAt
+	}); err != nil {
+		return nil, false, err
+	}
+
+	// An entity with more changes than were read cuts every entity's changes
+	// at its last one, so nothing is skipped by the next read
+	var cutoff *time.Time
+	for i, changes := range byEntity {
+		if len(changes) > limit {
+			byEntity[i] = changes[:limit]
+			last := changes[limit-1].ChangedAt
+			if cutoff == nil || last.Before(*cutoff) {
+				cutoff = &last
+			}
+		}
+	}
+
+	var all []SyncChange
+	for _, changes := range byEntity {
+		for _, change := range changes {
+			if cutoff == nil || !change.ChangedAt.After(*cutoff) {
+				all = append(all, change)
+			}
+		}
+	}
+	slices.SortStableFunc(all, func(a, b SyncChange) int { return a.ChangedAt.Compare(b.ChangedAt) })
+	return all, cutoff != nil, nil
+}
+
+// Deleted reports whether a synced record has been soft deleted
+func (s *SyncRepo) Deleted(ctx context.Context, entity, id string) (bool, error) {
+	var model any
+	var column string
+	switch entity {
+	case SyncField:
+		model, column = &Field{}, "field_id"
+	case SyncCrop:
+		model, column = &Crop{}, "crop_id"
+	case SyncLivestock:
+		model, column = &Livestock{}, "livestock_id"
+	case SyncEmployee:
+		model, column = &Employee{}, "employee_id"
+	default:
+		return false, nil
+	}
+	var count int64
+	result := s.DB.WithContext(ctx).Unscoped().Model(model).Where(column+" = ? AND deleted_at IS NOT NULL", id).Count(&count)
+	return count > 0, result.Error
+}
+
+// GetMapping retrieves the record a client's create was turned into
+func (s *SyncRepo) GetMapping(ctx context.Context, farmID, entity, clientID string) (*SyncMapping, error) {
+	var mapping SyncMapping
+	result := s.DB.WithContext(ctx).Where("farm_id = ? AND entity = ? AND client_id = ?", farmID, entity, clientID).First(&mapping)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &mapping, result.Error
+}
+
+// InsertMapping records the record a client's create was turned into
+func (s *SyncRepo) InsertMapping(ctx context.Context, mapping *SyncMapping) error {
+	return s.DB.WithContext(ctx).Create(mapping).Error
+}
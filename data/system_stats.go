@@ -0,0 +1,108 @@
+package data
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// SystemStatsInterface defines the contract for system-wide statistics
+type SystemStatsInterface interface {
+	Counts(ctx context.Context) (map[string]int64, error)
+}
+
+// SystemStatsRepo implements SystemStatsInterface using GORM.
+type SystemStatsRepo struct {
+	DB *gorm.DB
+}
+
+// NewSystemStatsRepo creates a new instance of SystemStatsRepo.
+func NewSystemStatsRepo(db *gorm.DB) SystemStatsInterface {
+	return &SystemStatsRepo{DB: db}
+}
+
+// countedModels are the records reported in system-wide counts, by name
+var countedModels = map[string]any{
+	"users":                     &User{},
+	"farms":                     &Farm{},
+	"farmMembers":               &FarmMember{},
+	"fields":                    &Field{},
+	"crops":                     &Crop{},
+	"cropPlans":                 &CropPlan{},
+	"seasons":                   &Season{},
+	"livestock":                 &Livestock{},
+	"employees":                 &Employee{},
+	"payrollPayments":           &PayrollPayment{},
+	"shifts":                    &Shift{},
+	"equipment":                 &Equipment{},
+	"assets":                    &Asset{},
+	"waterSources":              &WaterSource{},
+	"irrigationSchedules":       &IrrigationSchedule{},
+	"rainfallRecords":           &RainfallRecord{},
+	"paddocks":                  &Paddock{},
+	"grazingMoves":              &GrazingMove{},
+	"breedingEvents":            &BreedingEvent{},
+	"birthRecords":              &BirthRecord{},
+	"productionRecords":         &ProductionRecord{},
+	"feedingRecords":            &FeedingRecord{},
+	"weightRecords":             &WeightRecord{},
+	"mortalityRecords":          &MortalityRecord{},
+	"cropIncidents":             &CropIncident{},
+	"sprayRecords":              &SprayRecord{},
+	"chemicals":                 &ChemicalProduct{},
+	"inventoryItems":            &InventoryItem{},
+	"suppliers":                 &Supplier{},
+	"serviceProviders":          &ServiceProvider{},
+	"purchaseOrders":            &PurchaseOrder{},
+	"transactions":              &Transaction{},
+	"utilityRecords":            &UtilityRecord{},
+	"taxRates":                  &TaxRate{},
+	"exchangeRates":             &ExchangeRate{},
+	"budgetLines":               &BudgetLine{},
+	"loans":                     &Loan{},
+	"loanRepayments":            &LoanRepayment{},
+	"notifications":             &Notification{},
+	"buyerProfiles":             &BuyerProfile{},
+	"ratings":                   &Rating{},
+	"disputes":                  &Dispute{},
+	"escrows":                   &Escrow{},
+	"sustainabilityAssessments": &SustainabilityAssessment{},
+	"importJobs":                &ImportJob{},
+	"reportJobs":                &ReportJob{},
+	"exportJobs":                &ExportJob{},
+	"attachments":               &Attachment{},
+	"documents":                 &Document{},
+	"tags":                      &Tag{},
+	"savedViews":                &SavedView{},
+	"notes":                     &Note{},
+	"dashboardLayouts":          &DashboardLayout{},
+	"organizations":             &Organization{},
+	"procurementWindows":        &ProcurementWindow{},
+	"procurementRequests":       &ProcurementRequest{},
+	"procurementOrders":         &ProcurementOrder{},
+	"collectionCenters":         &CollectionCenter{},
+	"milkDeliveries":            &MilkDelivery{},
+	"webhooks":                  &Webhook{},
+	"apiKeys":                   &APIKey{},
+	"syncMappings":              &SyncMapping{},
+}
+
+// Counts returns the number of live (not soft-deleted) records of each kind
+func (s *SystemStatsRepo) Counts(ctx context.Context) (map[string]int64, error) {
+	counts := make(map[string]int64, len(countedModels))
+	for name, model := range countedModels {
+		var n int64
+		if err := s.DB.WithContext(ctx).Model(model).Count(&n).Error; err != nil {
+			return nil, err
+		}
+		counts[name] = n
+	}
+
+	var active int64
+	if err := s.DB.WithContext(ctx).Model(&User{}).Where("active = ?", true).Count(&active).Error; err != nil {
+		return nil, err
+	}
+	counts["activeUsers"] = active
+
+	return counts, nil
+}
@@ -0,0 +1,160 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Tag represents the tags table in the database: a label a farm puts on its
+// records, such as "organic" or "2024-season", to group and find them. Names
+// are unique on a farm regardless of case.
+type Tag struct {
+	ID        uint           `gorm:"primaryKey" json:"-"`
+	TagID     string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"tagId"`
+	FarmID    string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name      string         `gorm:"not null" json:"name"`
+	Color     string         `json:"color,omitempty"` // Display colour, e.g. #2e7d32
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Tagging represents the taggings table in the database: a tag put on one
+// record, named by its type and ID as attachments are
+type Tagging struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	TaggingID  string    `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"taggingId"`
+	TagID      string    `gorm:"not null;size:36;uniqueIndex:idx_tagging_record" json:"tagId"` // Foreign key to Tag
+	FarmID     string    `gorm:"not null;size:36;index" json:"farmId"`                         // Foreign key to Farm
+	RecordType string    `gorm:"not null;uniqueIndex:idx_tagging_record" json:"recordType"`    // crop, livestock, document
+	RecordID   string    `gorm:"not null;size:36;uniqueIndex:idx_tagging_record" json:"recordId"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"createdAt"`
+}
+
+// TagInterface defines the contract for tag operations
+type TagInterface interface {
+	GetByTagID(ctx context.Context, tagID string) (*Tag, error)
+	// GetByName retrieves a farm's tag by name, ignoring case
+	GetByName(ctx context.Context, farmID, name string) (*Tag, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Tag, error)
+	// GetByRecord retrieves the tags on a record by name
+	GetByRecord(ctx context.Context, recordType, recordID string) ([]*Tag, error)
+	Insert(ctx context.Context, tag *Tag) error
+	Update(ctx context.Context, tag *Tag) error
+	// DeleteByID removes a tag from every record and soft deletes it
+	DeleteByID(ctx context.Context, id int) error
+	// Attach puts a tag on a record; tagging a record twice is a no-op
+	Attach(ctx context.Context, tagging *Tagging) error
+	// Detach takes a tag off a record, reporting whether it was on it
+	Detach(ctx context.Context, tagID, recordType, recordID string) (bool, error)
+	// RecordIDs returns the IDs of a farm's records of recordType tagged with
+	// every one of names, ignoring case
+	RecordIDs(ctx context.Context, farmID, recordType string, names []string) ([]string, error)
+}
+
+// TagRepo implements TagInterface using GORM.
+type TagRepo struct {
+	DB *gorm.DB
+}
+
+// NewTagRepo creates a new instance of TagRepo.
+func NewTagRepo(db *gorm.DB) TagInterface {
+	return &TagRepo{DB: db}
+}
+
+// GetByTagID retrieves a tag by its TagID (UUID)
+func (t *TagRepo) GetByTagID(ctx context.Context, tagID string) (*Tag, error) {
+	var tag Tag
+	result := t.DB.WithContext(ctx).Where("tag_id = ?", tagID).First(&tag)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &tag, result.Error
+}
+
+// GetByName retrieves a farm's tag by name, ignoring case
+func (t *TagRepo) GetByName(ctx context.Context, farmID, name string) (*Tag, error) {
+	var tag Tag
+	result := t.DB.WithContext(ctx).Where("farm_id = ? AND lower(name) = ?", farmID, strings.ToLower(name)).First(&tag)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &tag, result.Error
+}
+
+// GetByFarmID retrieves a farm's tags by name
+func (t *TagRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Tag, error) {
+	var tags []*Tag
+	result := t.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("lower(name)").Find(&tags)
+	return tags, result.Error
+}
+
+// GetByRecord retrieves the tags on a record by name
+func (t *TagRepo) GetByRecord(ctx context.Context, recordType, recordID string) ([]*Tag, error) {
+	var tags []*Tag
+	result := t.DB.WithContext(ctx).
+		Joins("JOIN taggings ON taggings.tag_id = tags.tag_id").
+		Where("taggings.record_type = ? AND taggings.record_id = ?", recordType, recordID).
+		Order("lower(tags.name)").
+		Find(&tags)
+	return tags, result.Error
+}
+
+// Insert creates a new tag in the database
+func (t *TagRepo) Insert(ctx context.Context, tag *Tag) error {
+	return t.DB.WithContext(ctx).Create(tag).Error
+}
+
+// Update updates an existing tag in the database
+func (t *TagRepo) Update(ctx context.Context, tag *Tag) error {
+	return t.DB.WithContext(ctx).Save(tag).Error
+}
+
+// DeleteByID removes a tag's taggings and soft deletes it in a single
+// transaction
+func (t *TagRepo) DeleteByID(ctx context.Context, id int) error {
+	return t.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag Tag
+		if err := tx.Where("id = ?", id).First(&tag).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tag_id = ?", tag.TagID).Delete(&Tagging{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&tag).Error
+	})
+}
+
+// Attach creates a tagging unless the record already has the tag
+func (t *TagRepo) Attach(ctx context.Context, tagging *Tagging) error {
+	return t.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(tagging).Error
+}
+
+// Detach deletes the tagging of a tag on a record
+func (t *TagRepo) Detach(ctx context.Context, tagID, recordType, recordID string) (bool, error) {
+	result := t.DB.WithContext(ctx).
+		Where("tag_id = ? AND record_type = ? AND record_id = ?", tagID, recordType, recordID).
+		Delete(&Tagging{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordIDs retrieves the records tagged with all of names
+func (t *TagRepo) RecordIDs(ctx context.Context, farmID, recordType string, names []string) ([]string, error) {
+	lower := make([]string, len(names))
+	for i, name := range names {
+		lower[i] = strings.ToLower(name)
+	}
+	var ids []string
+	result := t.DB.WithContext(ctx).Model(&Tagging{}).
+		Joins("JOIN tags ON tags.tag_id = taggings.tag_id AND tags.deleted_at IS NULL").
+		Where("taggings.farm_id = ? AND taggings.record_type = ? AND lower(tags.name) IN ?", farmID, recordType, lower).
+		Group("taggings.record_id").
+		Having("COUNT(DISTINCT tags.tag_id) = ?", len(lower)).
+		Pluck("taggings.record_id", &ids)
+	return ids, result.Error
+}
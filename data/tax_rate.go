@@ -0,0 +1,103 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tax bases: what a tax rate is charged on
+const (
+	TaxBasisSales     = "Sales"     // Income transactions, e.g. output VAT
+	TaxBasisPurchases = "Purchases" // Expense transactions, e.g. reclaimable input VAT
+	TaxBasisWages     = "Wages"     // Gross payroll, e.g. PAYE or withholding
+)
+
+// TaxRate represents the tax_rates table in the database. A farm configures
+// the taxes of its jurisdiction; the tax summary applies them to the ledger
+// and payroll for a period.
+type TaxRate struct {
+	ID           uint           `gorm:"primaryKey" json:"-"`
+	TaxRateID    string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"taxRateId"`
+	FarmID       string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Name         string         `gorm:"not null" json:"name"`                 // e.g. VAT, Withholding Tax, PAYE
+	Jurisdiction string         `gorm:"not null" json:"jurisdiction"`         // Revenue authority or country, e.g. UG
+	Basis        string         `gorm:"not null" json:"basis"`                // Sales, Purchases, Wages
+	Category     string         `json:"category"`                             // Transaction category taxed; empty for every category of the basis
+	Rate         float64        `gorm:"not null" json:"rate"`                 // Percent
+	Inclusive    bool           `json:"inclusive"`                            // Amounts already include the tax
+	Active       bool           `gorm:"default:true" json:"active"`
+	Notes        string         `json:"notes"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// TaxOn returns the tax due on amount at this rate
+func (t *TaxRate) TaxOn(amount float64) float64 {
+	if t.Inclusive {
+		return amount * t.Rate / (100 + t.Rate)
+	}
+	return amount * t.Rate / 100
+}
+
+// TaxRateInterface defines the contract for tax rate operations
+type TaxRateInterface interface {
+	GetByTaxRateID(ctx context.Context, taxRateID string) (*TaxRate, error)
+	GetByFarmID(ctx context.Context, farmID string, activeOnly bool) ([]*TaxRate, error)
+	Insert(ctx context.Context, rate *TaxRate) error
+	Update(ctx context.Context, rate *TaxRate) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// TaxRateRepo implements TaxRateInterface using GORM.
+type TaxRateRepo struct {
+	DB *gorm.DB
+}
+
+// NewTaxRateRepo creates a new instance of TaxRateRepo.
+func NewTaxRateRepo(db *gorm.DB) TaxRateInterface {
+	return &TaxRateRepo{DB: db}
+}
+
+// GetByTaxRateID retrieves a tax rate by its TaxRateID (UUID)
+func (t *TaxRateRepo) GetByTaxRateID(ctx context.Context, taxRateID string) (*TaxRate, error) {
+	var rate TaxRate
+	result := t.DB.WithContext(ctx).Where("tax_rate_id = ?", taxRateID).First(&rate)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &rate, result.Error
+}
+
+// GetByFarmID retrieves a farm's tax rates by jurisdiction and name,
+// optionally only the active ones
+func (t *TaxRateRepo) GetByFarmID(ctx context.Context, farmID string, activeOnly bool) ([]*TaxRate, error) {
+	var rates []*TaxRate
+	query := t.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	result := query.Order("jurisdiction, name, basis, category").Find(&rates)
+	return rates, result.Error
+}
+
+// Insert creates a new tax rate in the database
+func (t *TaxRateRepo) Insert(ctx context.Context, rate *TaxRate) error {
+	return t.DB.WithContext(ctx).Create(rate).Error
+}
+
+// Update updates an existing tax rate in the database
+func (t *TaxRateRepo) Update(ctx context.Context, rate *TaxRate) error {
+	return t.DB.WithContext(ctx).Save(rate).Error
+}
+
+// DeleteByID soft deletes a tax rate by its ID
+func (t *TaxRateRepo) DeleteByID(ctx context.Context, id int) error {
+	return t.DB.WithContext(ctx).Delete(&TaxRate{}, id).Error
+}
@@ -0,0 +1,179 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OverheadCategories are expense categories reported as overheads rather than
+// direct production costs
+var OverheadCategories = []string{"Utilities", "Rent", "Insurance", "Administration", "Interest"}
+
+// Transaction represents the transactions table in the database. Amount is
+// always in the farm's currency, so totals and reports need no conversion;
+// an entry made in another currency keeps what was entered in Currency,
+// OriginalAmount and ExchangeRate.
+type Transaction struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	TransactionID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"transactionId"`
+	FarmID         string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	Type           string         `gorm:"not null" json:"type"`                 // Income, Expense
+	Category       string         `gorm:"not null" json:"category"`             // e.g. Sales, Feed, Fertilizer, Labour, Utilities
+	Amount         float64        `gorm:"not null" json:"amount"`               // Always positive; Type gives the direction
+	Currency       string         `gorm:"size:3" json:"currency,omitempty"`     // ISO 4217 code when entered in another currency than the farm's
+	OriginalAmount float64        `json:"originalAmount,omitempty"`             // Amount entered, in Currency
+	ExchangeRate   float64        `json:"exchangeRate,omitempty"`               // Farm currency per unit of Currency
+	Date           time.Time      `gorm:"not null;index" json:"date"`
+	Description    string         `json:"description"`
+	Reference      string         `gorm:"index" json:"reference,omitempty"`        // Source record for system-generated entries
+	SeasonID       *string        `gorm:"size:36;index" json:"seasonId,omitempty"` // Optional foreign key to Season; unset counts towards the season dated in
+	Notes          string         `json:"notes"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// CategoryTotal is the sum of transactions of one type and category
+type CategoryTotal struct {
+	Type     string  `json:"type"`
+	Category string  `json:"category"`
+	Total    float64 `json:"total"`
+}
+
+// TransactionInterface defines the contract for transaction operations
+type TransactionInterface interface {
+	GetByTransactionID(ctx context.Context, transactionID string) (*Transaction, error)
+	// GetByFarmIDAndDateRange retrieves a farm's transactions dated in
+	// [from, to), either end being open when nil
+	GetByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Transaction, error)
+	TotalsByCategory(ctx context.Context, farmID string, from, to *time.Time) ([]CategoryTotal, error)
+	Insert(ctx context.Context, transaction *Transaction) error
+	Update(ctx context.Context, transaction *Transaction) error
+	DeleteByID(ctx context.Context, id int) error
+	// Exists reports whether a farm has any transactions
+	Exists(ctx context.Context, farmID string) (bool, error)
+}
+
+// TransactionRepo implements TransactionInterface using GORM.
+type TransactionRepo struct {
+	DB *gorm.DB
+}
+
+// NewTransactionRepo creates a new instance of TransactionRepo.
+func NewTransactionRepo(db *gorm.DB) TransactionInterface {
+	return &TransactionRepo{DB: db}
+}
+
+// GetByTransactionID retrieves a transaction by its TransactionID (UUID)
+func (t *TransactionRepo) GetByTransactionID(ctx context.Context, transactionID string) (*Transaction, error) {
+	var transaction Transaction
+	result := t.DB.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&transaction)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &transaction, result.Error
+}
+
+// GetByFarmIDAndDateRange retrieves a farm's transactions, latest first
+func (t *TransactionRepo) GetByFarmIDAndDateRange(ctx context.Context, farmID string, from, to *time.Time) ([]*Transaction, error) {
+	var transactions []*Transaction
+	query := dateRange(t.DB.WithContext(ctx).Where("farm_id = ?", farmID), "date", from, to)
+	result := query.Order("date desc").Find(&transactions)
+	return transactions, result.Error
+}
+
+// dateRange limits a query to rows whose column is in [from, to), either end
+// being open when nil
+func dateRange(db *gorm.DB, column string, from, to *time.Time) *gorm.DB {
+	if from != nil {
+		db = db.Where(column+" >= ?", *from)
+	}
+	if to != nil {
+		db = db.Where(column+" < ?", *to)
+	}
+	return db
+}
+
+// TotalsByCategory sums a farm's transactions per type and category,
+// optionally limited to dates in [from, to)
+func (t *TransactionRepo) TotalsByCategory(ctx context.Context, farmID string, from, to *time.Time) ([]CategoryTotal, error) {
+	var totals []CategoryTotal
+	query := t.DB.WithContext(ctx).Model(&Transaction{}).
+		Select("type, category, SUM(amount) AS total").
+		Where("farm_id = ?", farmID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Group("type, category").Order("type, category").Scan(&totals)
+	return totals, result.Error
+}
+
+// Insert creates a new transaction in the database
+func (t *TransactionRepo) Insert(ctx context.Context, transaction *Transaction) error {
+	return t.DB.WithContext(ctx).Create(transaction).Error
+}
+
+// Update updates an existing transaction in the database
+func (t *TransactionRepo) Update(ctx context.Context, transaction *Transaction) error {
+	return t.DB.WithContext(ctx).Save(transaction).Error
+}
+
+// DeleteByID soft deletes a transaction by its ID
+func (t *TransactionRepo) DeleteByID(ctx context.Context, id int) error {
+	return t.DB.WithContext(ctx).Delete(&Transaction{}, id).Error
+}
+
+// Exists reports whether a farm has any transactions
+func (t *TransactionRepo) Exists(ctx context.Context, farmID string) (bool, error) {
+	var count int64
+	result := t.DB.WithContext(ctx).Model(&Transaction{}).Where("farm_id = ?", farmID).Limit(1).Count(&count)
+	return count > 0, result.Error
+}
+
+// syncExpense creates, updates or removes the system-generated expense with
+// the given reference so that it mirrors a source record such as a utility
+// bill. The expense is removed when want.Amount is not positive.
+func syncExpense(tx *gorm.DB, reference string, want Transaction) error {
+	want.Type = "Expense"
+	return syncTransaction(tx, reference, want)
+}
+
+// syncTransaction is syncExpense for an entry of either type, as given by
+// want.Type
+func syncTransaction(tx *gorm.DB, reference string, want Transaction) error {
+	var entry Transaction
+	err := tx.Where("reference = ?", reference).First(&entry).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	exists := err == nil
+
+	if want.Amount <= 0 {
+		if exists {
+			return tx.Delete(&entry).Error
+		}
+		return nil
+	}
+
+	entry.FarmID = want.FarmID
+	entry.Type = want.Type
+	entry.Category = want.Category
+	entry.Amount = want.Amount
+	entry.Date = want.Date
+	entry.Description = want.Description
+	entry.Reference = reference
+
+	if exists {
+		return tx.Save(&entry).Error
+	}
+	return tx.Create(&entry).Error
+}
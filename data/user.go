@@ -1,9 +1,9 @@
 package data
 
 import (
+	"context"
 	"errors"
-	"math/rand"
-	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -26,14 +26,25 @@ type User struct {
 	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"createdAt"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
-	// OTP fields
-	OTPCode      string    `gorm:"type:varchar(6)" json:"-"`
+	// One-time password reset code, stored hashed, and the wrong guesses
+	// made at it so far
+	OTPHash      string    `json:"-"`
 	OTPExpiresAt time.Time `json:"-"`
+	OTPAttempts  int       `gorm:"not null;default:0" json:"-"`
 
 	// Relationships
 	Farms []Farm `gorm:"foreignKey:UserID;references:UserID" json:"farms,omitempty"`
 }
 
+// UserFilter narrows down a user search. Empty fields are not filtered on.
+type UserFilter struct {
+	Query  string // Matched against name and email
+	Role   string
+	Active *bool
+	Limit  int
+	Offset int
+}
+
 // UserRepo implements UserInterface using GORM.
 type UserRepo struct {
 	DB *gorm.DB
@@ -54,16 +65,16 @@ func HashPassword(password string) (string, error) {
 }
 
 // GetAll retrieves all users from the database
-func (u *UserRepo) GetAll() ([]*User, error) {
+func (u *UserRepo) GetAll(ctx context.Context) ([]*User, error) {
 	var users []*User
-	result := u.DB.Find(&users)
+	result := u.DB.WithContext(ctx).Find(&users)
 	return users, result.Error
 }
 
 // GetByEmail retrieves a user by their email address
-func (u *UserRepo) GetByEmail(email string) (*User, error) {
+func (u *UserRepo) GetByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
-	result := u.DB.Where("email = ?", email).First(&user)
+	result := u.DB.WithContext(ctx).Where("email = ?", email).First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -71,9 +82,9 @@ func (u *UserRepo) GetByEmail(email string) (*User, error) {
 }
 
 // GetOne retrieves a user by their ID
-func (u *UserRepo) GetOne(id int) (*User, error) {
+func (u *UserRepo) GetOne(ctx context.Context, id int) (*User, error) {
 	var user User
-	result := u.DB.Where("id = ?", id).First(&user)
+	result := u.DB.WithContext(ctx).Where("id = ?", id).First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, nil
 	}
@@ -81,7 +92,7 @@ func (u *UserRepo) GetOne(id int) (*User, error) {
 }
 
 // Insert creates a new user in the database after hashing the password
-func (u *UserRepo) Insert(user *User) error {
+func (u *UserRepo) Insert(ctx context.Context, user *User) error {
 	// Hash the password before saving
 	hashedPassword, err := HashPassword(user.TempPassword)
 	if err != nil {
@@ -89,11 +100,11 @@ func (u *UserRepo) Insert(user *User) error {
 	}
 	user.Password = hashedPassword
 
-	return u.DB.Create(user).Error
+	return u.DB.WithContext(ctx).Create(user).Error
 }
 
 // Update updates an existing user in the database
-func (u *UserRepo) Update(user *User) error {
+func (u *UserRepo) Update(ctx context.Context, user *User) error {
 	// If the password is being updated, hash it
 	if user.TempPassword != "" {
 		hashedPassword, err := HashPassword(user.TempPassword)
@@ -104,32 +115,32 @@ func (u *UserRepo) Update(user *User) error {
 	} else {
 		// Prevent overwriting the password with an empty string
 		var existingUser User
-		if err := u.DB.First(&existingUser, user.ID).Error; err != nil {
+		if err := u.DB.WithContext(ctx).First(&existingUser, user.ID).Error; err != nil {
 			return err
 		}
 		user.Password = existingUser.Password
 	}
 
-	return u.DB.Save(user).Error
+	return u.DB.WithContext(ctx).Save(user).Error
 }
 
 // ResetPassword updates the password for a specific user
-func (u *UserRepo) ResetPassword(password string, user User) error {
+func (u *UserRepo) ResetPassword(ctx context.Context, password string, user User) error {
 	hashedPassword, err := HashPassword(password)
 	if err != nil {
 		return err
 	}
 
-	return u.DB.Model(&user).Update("password", hashedPassword).Error
+	return u.DB.WithContext(ctx).Model(&user).Update("password", hashedPassword).Error
 }
 
 // DeleteByID soft deletes a user by their ID
-func (u *UserRepo) DeleteByID(id int) error {
-	return u.DB.Delete(&User{}, id).Error
+func (u *UserRepo) DeleteByID(ctx context.Context, id int) error {
+	return u.DB.WithContext(ctx).Delete(&User{}, id).Error
 }
 
 // PasswordMatches checks if the provided plain text password matches the stored hashed password
-func (u *UserRepo) PasswordMatches(user *User, plainText string) (bool, error) {
+func (u *UserRepo) PasswordMatches(ctx context.Context, user *User, plainText string) (bool, error) {
 	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(plainText))
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
@@ -140,76 +151,139 @@ func (u *UserRepo) PasswordMatches(user *User, plainText string) (bool, error) {
 	return true, nil
 }
 
-// GenerateAndSaveOTP generates a new OTP code for the user and saves it to the database
-func (u *UserRepo) GenerateAndSaveOTP(email string) (string, error) {
-	var user User
-	result := u.DB.Where("email = ?", email).First(&user)
+// GenerateAndSaveOTP issues a new one-time code for the user, replacing any
+// earlier one, and returns it. Only its hash is stored.
+func (u *UserRepo) GenerateAndSaveOTP(ctx context.Context, email string) (string, error) {
+	code, hash, err := newOTP()
+	if err != nil {
+		return "", err
+	}
+
+	result := u.DB.WithContext(ctx).Model(&User{}).Where("email = ?", email).Updates(map[string]any{
+		"otp_hash":       hash,
+		"otp_expires_at": time.Now().Add(OTPTTL),
+		"otp_attempts":   0,
+	})
 	if result.Error != nil {
 		return "", result.Error
 	}
-
-	// Generate a random 6-digit OTP using crypto/rand for better security
-	otpNum := 100000 + rand.New(rand.NewSource(time.Now().UnixNano())).Intn(900000)
-	otp := strconv.Itoa(otpNum)
-
-	// Set OTP and expiration (15 minutes from now)
-	user.OTPCode = otp
-	user.OTPExpiresAt = time.Now().Add(15 * time.Minute)
-
-	// Save the user with the new OTP
-	if err := u.DB.Save(&user).Error; err != nil {
-		return "", err
+	if result.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
 	}
-
-	return otp, nil
+	return code, nil
 }
 
-// VerifyOTP checks if the provided OTP is valid for the user
-func (u *UserRepo) VerifyOTP(email, otp string) (bool, error) {
-	var user User
-	result := u.DB.Where("email = ?", email).First(&user)
+// VerifyOTP checks the user's one-time code and, if it matches, uses it up
+// so it cannot be used again. Every wrong guess counts against
+// MaxOTPAttempts, after which the code no longer verifies.
+func (u *UserRepo) VerifyOTP(ctx context.Context, email, otp string) (bool, error) {
+	db := u.DB.WithContext(ctx)
+
+	// Count the attempt first, atomically, so concurrent guesses cannot get
+	// past the limit
+	result := db.Model(&User{}).
+		Where("email = ? AND otp_hash <> '' AND otp_expires_at > ? AND otp_attempts < ?", email, time.Now(), MaxOTPAttempts).
+		Update("otp_attempts", gorm.Expr("otp_attempts + 1"))
 	if result.Error != nil {
 		return false, result.Error
 	}
-
-	// Check if OTP matches and has not expired
-	if user.OTPCode != otp {
+	if result.RowsAffected == 0 {
 		return false, nil
 	}
 
-	if time.Now().After(user.OTPExpiresAt) {
-		return false, errors.New("OTP has expired")
+	var user User
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
+		return false, err
+	}
+	if !otpMatches(user.OTPHash, otp) {
+		return false, nil
 	}
 
-	return true, nil
+	// Use the code up only if it is still the one compared, so that of two
+	// concurrent requests with the right code only one verifies
+	result = db.Model(&User{}).Where("id = ? AND otp_hash = ?", user.ID, user.OTPHash).
+		Updates(map[string]any{"otp_hash": "", "otp_attempts": 0})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
 }
 
-// ResetPasswordWithOTP resets a user's password after validating the OTP
-func (u *UserRepo) ResetPasswordWithOTP(email, otp, newPassword string) error {
-	// Verify OTP first
-	valid, err := u.VerifyOTP(email, otp)
+// ResetPasswordWithOTP resets a user's password after verifying, and using
+// up, their one-time code
+func (u *UserRepo) ResetPasswordWithOTP(ctx context.Context, email, otp, newPassword string) error {
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Not in a transaction: a wrong guess must be counted even though the
+	// reset fails
+	valid, err := u.VerifyOTP(ctx, email, otp)
 	if err != nil {
 		return err
 	}
 	if !valid {
 		return errors.New("invalid or expired OTP")
 	}
+	return u.DB.WithContext(ctx).Model(&User{}).Where("email = ?", email).Update("password", hashedPassword).Error
+}
 
+// GetByUserID retrieves a user by their UserID (UUID)
+func (u *UserRepo) GetByUserID(ctx context.Context, userID string) (*User, error) {
 	var user User
-	if err := u.DB.Where("email = ?", email).First(&user).Error; err != nil {
-		return err
+	result := u.DB.WithContext(ctx).Where("user_id = ?", userID).First(&user)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
 	}
+	return &user, result.Error
+}
 
-	// Hash the new password
-	hashedPassword, err := HashPassword(newPassword)
-	if err != nil {
-		return err
+// GetByPhoneNumber retrieves the users with a phone number
+func (u *UserRepo) GetByPhoneNumber(ctx context.Context, phoneNumber string) ([]*User, error) {
+	var users []*User
+	result := u.DB.WithContext(ctx).Where("regexp_replace(phone_number, '[^0-9+]', '', 'g') = ?", phoneNumber).Find(&users)
+	return users, result.Error
+}
+
+// Search retrieves a page of users matching the filter along with the total
+// number of matches
+func (u *UserRepo) Search(ctx context.Context, filter UserFilter) ([]*User, int64, error) {
+	query := u.DB.WithContext(ctx).Model(&User{})
+	if filter.Query != "" {
+		like := "%" + strings.ToLower(filter.Query) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(first_name || ' ' || last_name) LIKE ?", like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
 	}
 
-	// Update the password and clear the OTP
-	user.Password = hashedPassword
-	user.OTPCode = ""
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*User
+	result := query.Order("created_at desc").Limit(filter.Limit).Offset(filter.Offset).Find(&users)
+	return users, total, result.Error
+}
+
+// CountByRole counts users per role
+func (u *UserRepo) CountByRole(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Role  string
+		Count int64
+	}
+	if err := u.DB.WithContext(ctx).Model(&User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
 
-	// Save the changes
-	return u.DB.Save(&user).Error
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Role] = row.Count
+	}
+	return counts, nil
 }
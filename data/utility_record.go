@@ -0,0 +1,169 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UtilityRecord represents the utility_records table in the database. A
+// record is either a meter reading, whose consumption is the difference from
+// the previous reading, or a bill/purchase with the consumption stated.
+type UtilityRecord struct {
+	ID              uint           `gorm:"primaryKey" json:"-"`
+	UtilityRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"utilityRecordId"`
+	FarmID          string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	UtilityType     string         `gorm:"not null" json:"utilityType"`          // Electricity, Diesel, Water
+	RecordType      string         `gorm:"not null" json:"recordType"`           // Reading, Bill
+	Date            time.Time      `gorm:"not null;index" json:"date"`
+	MeterReading    *float64       `json:"meterReading,omitempty"`   // Cumulative meter value for readings
+	Quantity        float64        `gorm:"not null" json:"quantity"` // Consumption in Unit
+	Unit            string         `gorm:"not null" json:"unit"`     // kWh, L, m3
+	Cost            float64        `json:"cost"`
+	Provider        string         `json:"provider"`
+	Notes           string         `json:"notes"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// TransactionReference is the reference used on the expense transaction that
+// carries this record's cost into the finance ledger
+func (u *UtilityRecord) TransactionReference() string {
+	return fmt.Sprintf("utility_record:%s", u.UtilityRecordID)
+}
+
+// MonthlyUtilityTotal is the consumption and cost of one utility in one month
+type MonthlyUtilityTotal struct {
+	Month       string  `json:"month"` // YYYY-MM
+	UtilityType string  `json:"utilityType"`
+	Unit        string  `json:"unit"`
+	Quantity    float64 `json:"quantity"`
+	Cost        float64 `json:"cost"`
+}
+
+// UtilityRecordInterface defines the contract for utility record operations
+type UtilityRecordInterface interface {
+	GetByUtilityRecordID(ctx context.Context, utilityRecordID string) (*UtilityRecord, error)
+	GetByFarmID(ctx context.Context, farmID, utilityType string, from, to *time.Time) ([]*UtilityRecord, error)
+	GetLastReading(ctx context.Context, farmID, utilityType string, before time.Time) (*UtilityRecord, error)
+	MonthlyTotals(ctx context.Context, farmID string, from, to time.Time) ([]MonthlyUtilityTotal, error)
+	Insert(ctx context.Context, record *UtilityRecord) error
+	Update(ctx context.Context, record *UtilityRecord) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// UtilityRecordRepo implements UtilityRecordInterface using GORM.
+type UtilityRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewUtilityRecordRepo creates a new instance of UtilityRecordRepo.
+func NewUtilityRecordRepo(db *gorm.DB) UtilityRecordInterface {
+	return &UtilityRecordRepo{DB: db}
+}
+
+// GetByUtilityRecordID retrieves a utility record by its UtilityRecordID (UUID)
+func (u *UtilityRecordRepo) GetByUtilityRecordID(ctx context.Context, utilityRecordID string) (*UtilityRecord, error) {
+	var record UtilityRecord
+	result := u.DB.WithContext(ctx).Where("utility_record_id = ?", utilityRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's utility records, optionally filtered by
+// utility type and limited to dates in [from, to)
+func (u *UtilityRecordRepo) GetByFarmID(ctx context.Context, farmID, utilityType string, from, to *time.Time) ([]*UtilityRecord, error) {
+	var records []*UtilityRecord
+	query := u.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if utilityType != "" {
+		query = query.Where("utility_type = ?", utilityType)
+	}
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc").Find(&records)
+	return records, result.Error
+}
+
+// GetLastReading retrieves the most recent meter reading of a utility before the given time
+func (u *UtilityRecordRepo) GetLastReading(ctx context.Context, farmID, utilityType string, before time.Time) (*UtilityRecord, error) {
+	var record UtilityRecord
+	result := u.DB.WithContext(ctx).Where("farm_id = ? AND utility_type = ? AND record_type = ? AND date < ?", farmID, utilityType, "Reading", before).
+		Order("date desc").
+		First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// MonthlyTotals sums consumption and cost per month and utility for dates in [from, to)
+func (u *UtilityRecordRepo) MonthlyTotals(ctx context.Context, farmID string, from, to time.Time) ([]MonthlyUtilityTotal, error) {
+	var totals []MonthlyUtilityTotal
+	result := u.DB.WithContext(ctx).Model(&UtilityRecord{}).
+		Select("to_char(date, 'YYYY-MM') AS month, utility_type, unit, SUM(quantity) AS quantity, SUM(cost) AS cost").
+		Where("farm_id = ? AND date >= ? AND date < ?", farmID, from, to).
+		Group("month, utility_type, unit").
+		Order("month, utility_type").
+		Scan(&totals)
+	return totals, result.Error
+}
+
+// Insert creates a new utility record and, when it has a cost, the matching
+// overhead expense in the finance ledger, in a single transaction
+func (u *UtilityRecordRepo) Insert(ctx context.Context, record *UtilityRecord) error {
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+		return syncUtilityExpense(tx, record)
+	})
+}
+
+// Update updates an existing utility record and its expense transaction
+func (u *UtilityRecordRepo) Update(ctx context.Context, record *UtilityRecord) error {
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+		return syncUtilityExpense(tx, record)
+	})
+}
+
+// DeleteByID soft deletes a utility record by its ID along with its expense transaction
+func (u *UtilityRecordRepo) DeleteByID(ctx context.Context, id int) error {
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var record UtilityRecord
+		if err := tx.Where("id = ?", id).First(&record).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reference = ?", record.TransactionReference()).Delete(&Transaction{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&record).Error
+	})
+}
+
+// syncUtilityExpense creates, updates or removes the Utilities expense that
+// mirrors a utility record's cost
+func syncUtilityExpense(tx *gorm.DB, record *UtilityRecord) error {
+	return syncExpense(tx, record.TransactionReference(), Transaction{
+		FarmID:      record.FarmID,
+		Category:    "Utilities",
+		Amount:      record.Cost,
+		Date:        record.Date,
+		Description: fmt.Sprintf("%s %s (%.2f %s)", record.UtilityType, record.RecordType, record.Quantity, record.Unit),
+	})
+}
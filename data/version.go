@@ -0,0 +1,33 @@
+package data
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrStale is returned when updating a versioned record that another update
+// has saved since it was loaded
+var ErrStale = errors.New("record was changed by another update")
+
+// updateVersioned saves model only if its row is still at *version, and
+// moves it on to the next version. Two load-modify-save updates of the same
+// record therefore cannot silently overwrite each other: the later one gets
+// ErrStale.
+func updateVersioned(db *gorm.DB, model any, version *int) error {
+	loaded := *version
+	*version = loaded + 1
+	result := db.Model(model).Omit(clause.Associations).Select("*").
+		Where("version = ?", loaded).Updates(model)
+	if result.Error != nil || result.RowsAffected == 0 {
+		*version = loaded
+	}
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStale
+	}
+	return nil
+}
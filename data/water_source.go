@@ -0,0 +1,126 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WaterSource represents the water_sources table in the database.
+type WaterSource struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	WaterSourceID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"waterSourceId"`
+	FarmID         string         `gorm:"not null;size:36" json:"farmId"` // Foreign key to Farm
+	Name           string         `gorm:"not null" json:"name"`
+	SourceType     string         `gorm:"not null" json:"sourceType"` // Borehole, River Abstraction, Dam, Spring, Municipal
+	PermitNumber   string         `json:"permitNumber"`
+	PermitExpiry   *time.Time     `json:"permitExpiry"`
+	DailyLimit     float64        `json:"dailyLimit"`                                // Permitted abstraction per day in cubic metres (0 = no limit)
+	AnnualLimit    float64        `json:"annualLimit"`                               // Permitted abstraction per calendar year in cubic metres (0 = no limit)
+	AlertThreshold float64        `gorm:"not null;default:80" json:"alertThreshold"` // Percentage of a limit at which alerts are raised
+	Status         string         `gorm:"not null;default:'Active'" json:"status"`   // Active, Inactive, Decommissioned
+	Notes          string         `json:"notes"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Farm *Farm `gorm:"foreignKey:FarmID;references:FarmID" json:"farm,omitempty"`
+}
+
+// WaterSourceInterface defines the contract for water source operations
+type WaterSourceInterface interface {
+	GetAll(ctx context.Context) ([]*WaterSource, error)
+	GetByID(ctx context.Context, id int) (*WaterSource, error)
+	GetByWaterSourceID(ctx context.Context, waterSourceID string) (*WaterSource, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*WaterSource, error)
+	Insert(ctx context.Context, source *WaterSource) error
+	Update(ctx context.Context, source *WaterSource) error
+	DeleteByID(ctx context.Context, id int) error
+	GetDeletedByFarmID(ctx context.Context, farmID string) ([]*WaterSource, error)
+	GetDeletedByWaterSourceID(ctx context.Context, waterSourceID string) (*WaterSource, error)
+	RestoreByID(ctx context.Context, id int) error
+}
+
+// WaterSourceRepo implements WaterSourceInterface using GORM.
+type WaterSourceRepo struct {
+	DB *gorm.DB
+}
+
+// NewWaterSourceRepo creates a new instance of WaterSourceRepo.
+func NewWaterSourceRepo(db *gorm.DB) WaterSourceInterface {
+	return &WaterSourceRepo{DB: db}
+}
+
+// GetAll retrieves all water sources from the database
+func (ws *WaterSourceRepo) GetAll(ctx context.Context) ([]*WaterSource, error) {
+	var sources []*WaterSource
+	result := ws.DB.WithContext(ctx).Find(&sources)
+	return sources, result.Error
+}
+
+// GetByID retrieves a water source by its ID
+func (ws *WaterSourceRepo) GetByID(ctx context.Context, id int) (*WaterSource, error) {
+	var source WaterSource
+	result := ws.DB.WithContext(ctx).Where("id = ?", id).First(&source)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &source, result.Error
+}
+
+// GetByWaterSourceID retrieves a water source by its WaterSourceID (UUID)
+func (ws *WaterSourceRepo) GetByWaterSourceID(ctx context.Context, waterSourceID string) (*WaterSource, error) {
+	var source WaterSource
+	result := ws.DB.WithContext(ctx).Where("water_source_id = ?", waterSourceID).First(&source)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &source, result.Error
+}
+
+// GetByFarmID retrieves all water sources belonging to a specific farm
+func (ws *WaterSourceRepo) GetByFarmID(ctx context.Context, farmID string) ([]*WaterSource, error) {
+	var sources []*WaterSource
+	result := ws.DB.WithContext(ctx).Where("farm_id = ?", farmID).Find(&sources)
+	return sources, result.Error
+}
+
+// Insert creates a new water source in the database
+func (ws *WaterSourceRepo) Insert(ctx context.Context, source *WaterSource) error {
+	return ws.DB.WithContext(ctx).Create(source).Error
+}
+
+// Update updates an existing water source in the database
+func (ws *WaterSourceRepo) Update(ctx context.Context, source *WaterSource) error {
+	return ws.DB.WithContext(ctx).Save(source).Error
+}
+
+// DeleteByID soft deletes a water source by its ID
+func (ws *WaterSourceRepo) DeleteByID(ctx context.Context, id int) error {
+	return ws.DB.WithContext(ctx).Delete(&WaterSource{}, id).Error
+}
+
+// GetDeletedByFarmID retrieves soft-deleted water sources belonging to a specific farm
+func (ws *WaterSourceRepo) GetDeletedByFarmID(ctx context.Context, farmID string) ([]*WaterSource, error) {
+	var sources []*WaterSource
+	result := ws.DB.WithContext(ctx).Unscoped().Where("farm_id = ? AND deleted_at IS NOT NULL", farmID).Order("deleted_at desc").Find(&sources)
+	return sources, result.Error
+}
+
+// GetDeletedByWaterSourceID retrieves a soft-deleted water source by its WaterSourceID (UUID)
+func (ws *WaterSourceRepo) GetDeletedByWaterSourceID(ctx context.Context, waterSourceID string) (*WaterSource, error) {
+	var source WaterSource
+	result := ws.DB.WithContext(ctx).Unscoped().Where("water_source_id = ? AND deleted_at IS NOT NULL", waterSourceID).First(&source)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &source, result.Error
+}
+
+// RestoreByID restores a soft-deleted water source by its ID
+func (ws *WaterSourceRepo) RestoreByID(ctx context.Context, id int) error {
+	return ws.DB.WithContext(ctx).Unscoped().Model(&WaterSource{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
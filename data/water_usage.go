@@ -0,0 +1,91 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WaterUsage represents the water_usages table in the database.
+type WaterUsage struct {
+	ID            uint           `gorm:"primaryKey" json:"-"`
+	WaterUsageID  string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"waterUsageId"`
+	WaterSourceID string         `gorm:"not null;size:36;index" json:"waterSourceId"` // Foreign key to WaterSource
+	FarmID        string         `gorm:"not null;size:36" json:"farmId"`              // Foreign key to Farm
+	Date          time.Time      `gorm:"not null" json:"date"`
+	Volume        float64        `gorm:"not null" json:"volume"` // Cubic metres abstracted
+	Purpose       string         `json:"purpose"`                // Irrigation, Livestock, Domestic, etc.
+	Notes         string         `json:"notes"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	WaterSource *WaterSource `gorm:"foreignKey:WaterSourceID;references:WaterSourceID" json:"waterSource,omitempty"`
+}
+
+// WaterUsageInterface defines the contract for water usage operations
+type WaterUsageInterface interface {
+	GetByWaterUsageID(ctx context.Context, waterUsageID string) (*WaterUsage, error)
+	GetByWaterSourceID(ctx context.Context, waterSourceID string, from, to *time.Time) ([]*WaterUsage, error)
+	TotalVolume(ctx context.Context, waterSourceID string, from, to time.Time) (float64, error)
+	Insert(ctx context.Context, usage *WaterUsage) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// WaterUsageRepo implements WaterUsageInterface using GORM.
+type WaterUsageRepo struct {
+	DB *gorm.DB
+}
+
+// NewWaterUsageRepo creates a new instance of WaterUsageRepo.
+func NewWaterUsageRepo(db *gorm.DB) WaterUsageInterface {
+	return &WaterUsageRepo{DB: db}
+}
+
+// GetByWaterUsageID retrieves a usage entry by its WaterUsageID (UUID)
+func (wu *WaterUsageRepo) GetByWaterUsageID(ctx context.Context, waterUsageID string) (*WaterUsage, error) {
+	var usage WaterUsage
+	result := wu.DB.WithContext(ctx).Where("water_usage_id = ?", waterUsageID).First(&usage)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &usage, result.Error
+}
+
+// GetByWaterSourceID retrieves usage entries for a water source, optionally
+// limited to dates in [from, to)
+func (wu *WaterUsageRepo) GetByWaterSourceID(ctx context.Context, waterSourceID string, from, to *time.Time) ([]*WaterUsage, error) {
+	var usages []*WaterUsage
+	query := wu.DB.WithContext(ctx).Where("water_source_id = ?", waterSourceID)
+	if from != nil {
+		query = query.Where("date >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("date < ?", *to)
+	}
+	result := query.Order("date desc").Find(&usages)
+	return usages, result.Error
+}
+
+// TotalVolume sums the volume abstracted from a water source in [from, to)
+func (wu *WaterUsageRepo) TotalVolume(ctx context.Context, waterSourceID string, from, to time.Time) (float64, error) {
+	var total float64
+	result := wu.DB.WithContext(ctx).Model(&WaterUsage{}).
+		Where("water_source_id = ? AND date >= ? AND date < ?", waterSourceID, from, to).
+		Select("COALESCE(SUM(volume), 0)").
+		Scan(&total)
+	return total, result.Error
+}
+
+// Insert creates a new usage entry in the database
+func (wu *WaterUsageRepo) Insert(ctx context.Context, usage *WaterUsage) error {
+	return wu.DB.WithContext(ctx).Create(usage).Error
+}
+
+// DeleteByID soft deletes a usage entry by its ID
+func (wu *WaterUsageRepo) DeleteByID(ctx context.Context, id int) error {
+	return wu.DB.WithContext(ctx).Delete(&WaterUsage{}, id).Error
+}
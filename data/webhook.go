@@ -0,0 +1,105 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook represents the webhooks table in the database: a partner system,
+// such as a co-op's, subscribed to events on a farm. Each event it subscribes
+// to is posted to URL as JSON signed with Secret, on its own or, with a digest
+// size or interval, batched with others into a digest.
+type Webhook struct {
+	ID                      uint           `gorm:"primaryKey" json:"-"`
+	WebhookID               string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"webhookId"`
+	FarmID                  string         `gorm:"not null;size:36;index" json:"farmId"` // Foreign key to Farm
+	URL                     string         `gorm:"not null" json:"url"`                  // Deliveries are POSTed here
+	Description             string         `json:"description"`
+	Secret                  string         `gorm:"not null" json:"-"`                       // Signs deliveries; shown once, on creation or rotation
+	PreviousSecret          string         `json:"-"`                                       // Also signs deliveries until PreviousSecretExpiresAt
+	PreviousSecretExpiresAt *time.Time     `json:"previousSecretExpiresAt,omitempty"`       // When the secret rotated out stops signing
+	EventTypes              []string       `gorm:"serializer:json" json:"eventTypes"`       // e.g. crop.created, sale.recorded; * for all
+	DigestSize              int            `gorm:"not null;default:0" json:"digestSize"`    // Most events per digest; 0 sends each on its own
+	DigestMinutes           int            `gorm:"not null;default:0" json:"digestMinutes"` // Events are held and sent together every this many minutes
+	Active                  bool           `gorm:"not null;default:true" json:"active"`     // Inactive webhooks receive nothing
+	CreatedBy               string         `gorm:"not null;size:36" json:"createdBy"`       // UserID of the user who added it
+	CreatedAt               time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt               time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt               gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Subscribes reports whether the webhook is sent events of the given type
+func (w *Webhook) Subscribes(event string) bool {
+	return slices.Contains(w.EventTypes, "*") || slices.Contains(w.EventTypes, event)
+}
+
+// Digests reports whether the webhook's events are batched into digests
+func (w *Webhook) Digests() bool {
+	return w.DigestSize > 0 || w.DigestMinutes > 0
+}
+
+// WebhookInterface defines the contract for webhook operations
+type WebhookInterface interface {
+	GetByWebhookID(ctx context.Context, webhookID string) (*Webhook, error)
+	GetByFarmID(ctx context.Context, farmID string) ([]*Webhook, error)
+	// GetActiveByFarmID returns the webhooks on a farm that are to receive
+	// events
+	GetActiveByFarmID(ctx context.Context, farmID string) ([]*Webhook, error)
+	Insert(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// WebhookRepo implements WebhookInterface using GORM.
+type WebhookRepo struct {
+	DB *gorm.DB
+}
+
+// NewWebhookRepo creates a new instance of WebhookRepo.
+func NewWebhookRepo(db *gorm.DB) WebhookInterface {
+	return &WebhookRepo{DB: db}
+}
+
+// GetByWebhookID retrieves a webhook by its WebhookID (UUID)
+func (w *WebhookRepo) GetByWebhookID(ctx context.Context, webhookID string) (*Webhook, error) {
+	var webhook Webhook
+	result := w.DB.WithContext(ctx).Where("webhook_id = ?", webhookID).First(&webhook)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &webhook, result.Error
+}
+
+// GetByFarmID retrieves a farm's webhooks, oldest first
+func (w *WebhookRepo) GetByFarmID(ctx context.Context, farmID string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	result := w.DB.WithContext(ctx).Where("farm_id = ?", farmID).Order("id").Find(&webhooks)
+	return webhooks, result.Error
+}
+
+// GetActiveByFarmID retrieves a farm's active webhooks
+func (w *WebhookRepo) GetActiveByFarmID(ctx context.Context, farmID string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	result := w.DB.WithContext(ctx).Where("farm_id = ? AND active", farmID).Order("id").Find(&webhooks)
+	return webhooks, result.Error
+}
+
+// Insert creates a new webhook
+func (w *WebhookRepo) Insert(ctx context.Context, webhook *Webhook) error {
+	return w.DB.WithContext(ctx).Create(webhook).Error
+}
+
+// Update saves a webhook
+func (w *WebhookRepo) Update(ctx context.Context, webhook *Webhook) error {
+	return w.DB.WithContext(ctx).Save(webhook).Error
+}
+
+// DeleteByID soft deletes a webhook by its ID. Deliveries still pending are
+// dropped when they come due.
+func (w *WebhookRepo) DeleteByID(ctx context.Context, id int) error {
+	return w.DB.WithContext(ctx).Delete(&Webhook{}, id).Error
+}
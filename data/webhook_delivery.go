@@ -0,0 +1,122 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDelivery represents the webhook_deliveries table in the database:
+// one event queued for a webhook, and how posting it has gone. Deliveries
+// that fail are retried with a growing delay until they succeed or run out of
+// attempts.
+type WebhookDelivery struct {
+	ID                uint           `gorm:"primaryKey" json:"-"`
+	WebhookDeliveryID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"webhookDeliveryId"`
+	WebhookID         string         `gorm:"not null;size:36;index" json:"webhookId"`        // Foreign key to Webhook
+	FarmID            string         `gorm:"not null;size:36;index" json:"farmId"`           // Foreign key to Farm
+	Event             string         `gorm:"not null" json:"event"`                          // e.g. crop.created
+	Payload           string         `gorm:"type:text;not null" json:"payload"`              // JSON body posted
+	Status            string         `gorm:"not null;default:'Pending';index" json:"status"` // Pending, Delivered, Failed
+	Attempts          int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt     *time.Time     `gorm:"index" json:"nextAttemptAt,omitempty"` // When a Pending delivery is next tried
+	LastAttemptAt     *time.Time     `json:"lastAttemptAt,omitempty"`
+	ResponseStatus    int            `json:"responseStatus,omitempty"` // HTTP status of the last attempt
+	Error             string         `json:"error,omitempty"`          // Why the last attempt failed
+	DeliveredAt       *time.Time     `json:"deliveredAt,omitempty"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Webhook *Webhook `gorm:"foreignKey:WebhookID;references:WebhookID" json:"-"`
+}
+
+// WebhookDeliveryInterface defines the contract for webhook delivery
+// operations
+type WebhookDeliveryInterface interface {
+	// GetByWebhookID returns a webhook's deliveries, most recent first, at
+	// most limit of them
+	GetByWebhookID(ctx context.Context, webhookID string, limit int) ([]*WebhookDelivery, error)
+	// GetBetween returns up to limit of a webhook's deliveries queued from
+	// from up to but excluding to, oldest first
+	GetBetween(ctx context.Context, webhookID string, from, to time.Time, limit int) ([]*WebhookDelivery, error)
+	// GetDue returns up to limit Pending deliveries whose next attempt is due
+	// at the given time, with their webhooks, oldest first
+	GetDue(ctx context.Context, at time.Time, limit int) ([]*WebhookDelivery, error)
+	InsertMany(ctx context.Context, deliveries []*WebhookDelivery) error
+	// Claim moves a due delivery's next attempt to until, reporting whether
+	// it was still due, so two workers never post the same attempt
+	Claim(ctx context.Context, delivery *WebhookDelivery, until time.Time) (bool, error)
+	// Finish records the outcome of an attempt
+	Finish(ctx context.Context, delivery *WebhookDelivery) error
+}
+
+// WebhookDeliveryRepo implements WebhookDeliveryInterface using GORM.
+type WebhookDeliveryRepo struct {
+	DB *gorm.DB
+}
+
+// NewWebhookDeliveryRepo creates a new instance of WebhookDeliveryRepo.
+func NewWebhookDeliveryRepo(db *gorm.DB) WebhookDeliveryInterface {
+	return &WebhookDeliveryRepo{DB: db}
+}
+
+// GetByWebhookID retrieves a webhook's most recent deliveries
+func (w *WebhookDeliveryRepo) GetByWebhookID(ctx context.Context, webhookID string, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	result := w.DB.WithContext(ctx).Where("webhook_id = ?", webhookID).
+		Order("created_at desc, id desc").Limit(limit).Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// GetBetween retrieves a webhook's deliveries queued in a window
+func (w *WebhookDeliveryRepo) GetBetween(ctx context.Context, webhookID string, from, to time.Time, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	result := w.DB.WithContext(ctx).Where("webhook_id = ? AND created_at >= ? AND created_at < ?", webhookID, from, to).
+		Order("created_at, id").Limit(limit).Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// GetDue retrieves the Pending deliveries due at the given time
+func (w *WebhookDeliveryRepo) GetDue(ctx context.Context, at time.Time, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	result := w.DB.WithContext(ctx).Preload("Webhook").
+		Where("status = ? AND next_attempt_at <= ?", "Pending", at).
+		Order("next_attempt_at, id").Limit(limit).Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// InsertMany queues several deliveries at once
+func (w *WebhookDeliveryRepo) InsertMany(ctx context.Context, deliveries []*WebhookDelivery) error {
+	return w.DB.WithContext(ctx).Omit("Webhook").Create(deliveries).Error
+}
+
+// Claim moves a Pending delivery's next attempt, guarding on the attempt time
+// it was read with
+func (w *WebhookDeliveryRepo) Claim(ctx context.Context, delivery *WebhookDelivery, until time.Time) (bool, error) {
+	result := w.DB.WithContext(ctx).Model(&WebhookDelivery{}).
+		Where("webhook_delivery_id = ? AND status = ? AND next_attempt_at = ?",
+			delivery.WebhookDeliveryID, "Pending", delivery.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.RowsAffected == 1 {
+		delivery.NextAttemptAt = &until
+	}
+	return result.RowsAffected == 1, result.Error
+}
+
+// Finish records the outcome of a delivery attempt
+func (w *WebhookDeliveryRepo) Finish(ctx context.Context, delivery *WebhookDelivery) error {
+	return w.DB.WithContext(ctx).Model(&WebhookDelivery{}).
+		Where("webhook_delivery_id = ?", delivery.WebhookDeliveryID).
+		Updates(map[string]any{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_attempt_at": delivery.LastAttemptAt,
+			"response_status": delivery.ResponseStatus,
+			"error":           delivery.Error,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+}
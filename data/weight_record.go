@@ -0,0 +1,92 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WeightRecord represents the weight_records table in the database: one
+// animal weighed, or a herd weighed together. For a herd, Weight is the
+// average per head of the HeadCount animals put on the scale.
+type WeightRecord struct {
+	ID             uint           `gorm:"primaryKey" json:"-"`
+	WeightRecordID string         `gorm:"primaryKey;size:36;default:gen_random_uuid()" json:"weightRecordId"`
+	FarmID         string         `gorm:"not null;size:36;index" json:"farmId"`      // Foreign key to Farm
+	LivestockID    string         `gorm:"not null;size:36;index" json:"livestockId"` // Herd weighed, or the animal's herd
+	AnimalTag      string         `gorm:"index" json:"animalTag"`                    // One animal of the herd; empty for the herd average
+	Date           time.Time      `gorm:"not null" json:"date"`
+	Weight         float64        `gorm:"not null" json:"weight"`              // Kg, per head for a herd
+	HeadCount      int            `gorm:"not null;default:1" json:"headCount"` // Animals weighed
+	Notes          string         `json:"notes"`
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"createdAt"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// WeightRecordInterface defines the contract for weight record operations
+type WeightRecordInterface interface {
+	GetByWeightRecordID(ctx context.Context, weightRecordID string) (*WeightRecord, error)
+	// GetByFarmID returns a farm's weighings, optionally only those of a herd
+	// or of one animal, oldest first
+	GetByFarmID(ctx context.Context, farmID, livestockID string, animalTag *string) ([]*WeightRecord, error)
+	// InsertMany creates weighings in a single transaction
+	InsertMany(ctx context.Context, records []*WeightRecord) error
+	Update(ctx context.Context, record *WeightRecord) error
+	DeleteByID(ctx context.Context, id int) error
+}
+
+// WeightRecordRepo implements WeightRecordInterface using GORM.
+type WeightRecordRepo struct {
+	DB *gorm.DB
+}
+
+// NewWeightRecordRepo creates a new instance of WeightRecordRepo.
+func NewWeightRecordRepo(db *gorm.DB) WeightRecordInterface {
+	return &WeightRecordRepo{DB: db}
+}
+
+// GetByWeightRecordID retrieves a weighing by its WeightRecordID (UUID)
+func (w *WeightRecordRepo) GetByWeightRecordID(ctx context.Context, weightRecordID string) (*WeightRecord, error) {
+	var record WeightRecord
+	result := w.DB.WithContext(ctx).Where("weight_record_id = ?", weightRecordID).First(&record)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &record, result.Error
+}
+
+// GetByFarmID retrieves a farm's weighings, oldest first. A nil animalTag
+// matches every weighing, an empty one only those of whole herds.
+func (w *WeightRecordRepo) GetByFarmID(ctx context.Context, farmID, livestockID string, animalTag *string) ([]*WeightRecord, error) {
+	var records []*WeightRecord
+	query := w.DB.WithContext(ctx).Where("farm_id = ?", farmID)
+	if livestockID != "" {
+		query = query.Where("livestock_id = ?", livestockID)
+	}
+	if animalTag != nil {
+		query = query.Where("animal_tag = ?", *animalTag)
+	}
+	result := query.Order("date, id").Find(&records)
+	return records, result.Error
+}
+
+// InsertMany creates weighings in a single transaction
+func (w *WeightRecordRepo) InsertMany(ctx context.Context, records []*WeightRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return w.DB.WithContext(ctx).Create(&records).Error
+}
+
+// Update saves a weighing
+func (w *WeightRecordRepo) Update(ctx context.Context, record *WeightRecord) error {
+	return w.DB.WithContext(ctx).Save(record).Error
+}
+
+// DeleteByID soft deletes a weighing by its ID
+func (w *WeightRecordRepo) DeleteByID(ctx context.Context, id int) error {
+	return w.DB.WithContext(ctx).Delete(&WeightRecord{}, id).Error
+}
@@ -0,0 +1,132 @@
+// Package live pushes events to the users connected to the API's event
+// stream, so that two managers looking at the same farm see each other's
+// edits, and new notifications, without refreshing.
+//
+// A Hub only reaches the clients connected to the same API instance. Behind a
+// load balancer with several instances, clients see the changes made through
+// their own instance live and pick up the rest on their next fetch.
+package live
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many events may queue for a slow client before
+// further events to it are dropped
+const subscriberBuffer = 32
+
+// Event is one message pushed to a client
+type Event struct {
+	// Type is a record change such as crop.updated, as sent to webhooks, or
+	// notification for a new notification
+	Type   string    `json:"type"`
+	FarmID string    `json:"farmId,omitempty"`
+	At     time.Time `json:"at"`
+	Data   any       `json:"data,omitempty"` // The record or notification
+}
+
+// Subscription receives the events sent to one user until it is closed
+type Subscription struct {
+	// Events is closed when the subscription or its hub is closed
+	Events <-chan Event
+
+	hub    *Hub
+	userID string
+	events chan Event
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+// Hub routes events to the subscriptions of the users they are for. It is
+// safe for concurrent use.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[string]map[*Subscription]struct{}
+	closed bool
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{subs: make(map[string]map[*Subscription]struct{})}
+}
+
+// Subscribe starts receiving the events sent to a user. A user may hold
+// several subscriptions, one per open tab or device.
+func (h *Hub) Subscribe(userID string) *Subscription {
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscription{Events: events, hub: h, userID: userID, events: events}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(events)
+		return sub
+	}
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// Send queues an event for each of a user's subscriptions. A subscription
+// whose queue is full misses the event rather than holding up the sender.
+func (h *Hub) Send(userID string, event Event) {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs[userID] {
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// Connected reports whether a user has any open subscription
+func (h *Hub) Connected(userID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[userID]) > 0
+}
+
+// Close ends every subscription, as when the server shuts down, and refuses
+// new ones
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for _, subs := range h.subs {
+		for sub := range subs {
+			close(sub.events)
+		}
+	}
+	h.subs = nil
+}
+
+func (h *Hub) unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs, ok := h.subs[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subs, sub.userID)
+	}
+	close(sub.events)
+}
@@ -0,0 +1,62 @@
+-- Drops every table of the baseline schema
+DROP TABLE IF EXISTS "api_usages";
+DROP TABLE IF EXISTS "audit_logs";
+DROP TABLE IF EXISTS "sync_mappings";
+DROP TABLE IF EXISTS "milk_deliveries";
+DROP TABLE IF EXISTS "milk_suppliers";
+DROP TABLE IF EXISTS "collection_centers";
+DROP TABLE IF EXISTS "procurement_orders";
+DROP TABLE IF EXISTS "procurement_requests";
+DROP TABLE IF EXISTS "procurement_items";
+DROP TABLE IF EXISTS "procurement_windows";
+DROP TABLE IF EXISTS "organization_members";
+DROP TABLE IF EXISTS "organizations";
+DROP TABLE IF EXISTS "dashboard_layouts";
+DROP TABLE IF EXISTS "documents";
+DROP TABLE IF EXISTS "attachments";
+DROP TABLE IF EXISTS "report_jobs";
+DROP TABLE IF EXISTS "import_jobs";
+DROP TABLE IF EXISTS "sustainability_responses";
+DROP TABLE IF EXISTS "sustainability_assessments";
+DROP TABLE IF EXISTS "sustainability_practices";
+DROP TABLE IF EXISTS "tax_rates";
+DROP TABLE IF EXISTS "period_locks";
+DROP TABLE IF EXISTS "utility_records";
+DROP TABLE IF EXISTS "transactions";
+DROP TABLE IF EXISTS "market_prices";
+DROP TABLE IF EXISTS "escrows";
+DROP TABLE IF EXISTS "dispute_evidences";
+DROP TABLE IF EXISTS "disputes";
+DROP TABLE IF EXISTS "ratings";
+DROP TABLE IF EXISTS "buyer_profiles";
+DROP TABLE IF EXISTS "notifications";
+DROP TABLE IF EXISTS "purchase_order_lines";
+DROP TABLE IF EXISTS "purchase_orders";
+DROP TABLE IF EXISTS "suppliers";
+DROP TABLE IF EXISTS "inventory_movements";
+DROP TABLE IF EXISTS "inventory_batches";
+DROP TABLE IF EXISTS "inventory_items";
+DROP TABLE IF EXISTS "chemical_usages";
+DROP TABLE IF EXISTS "chemical_products";
+DROP TABLE IF EXISTS "grazing_moves";
+DROP TABLE IF EXISTS "paddocks";
+DROP TABLE IF EXISTS "rainfall_records";
+DROP TABLE IF EXISTS "irrigation_schedules";
+DROP TABLE IF EXISTS "water_usages";
+DROP TABLE IF EXISTS "water_sources";
+DROP TABLE IF EXISTS "asset_events";
+DROP TABLE IF EXISTS "assets";
+DROP TABLE IF EXISTS "maintenance_records";
+DROP TABLE IF EXISTS "equipment";
+DROP TABLE IF EXISTS "attendances";
+DROP TABLE IF EXISTS "payroll_payments";
+DROP TABLE IF EXISTS "employees";
+DROP TABLE IF EXISTS "livestocks";
+DROP TABLE IF EXISTS "plan_scenarios";
+DROP TABLE IF EXISTS "crop_plan_inputs";
+DROP TABLE IF EXISTS "crop_plans";
+DROP TABLE IF EXISTS "crops";
+DROP TABLE IF EXISTS "fields";
+DROP TABLE IF EXISTS "farm_members";
+DROP TABLE IF EXISTS "farms";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,1176 @@
+-- Baseline schema: every table as the models defined it before versioned
+-- migrations. Statements are IF NOT EXISTS so a database created by the old
+-- AutoMigrate startup adopts this migration without changes.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "user_id" varchar(36) DEFAULT gen_random_uuid(),
+    "first_name" text NOT NULL,
+    "last_name" text NOT NULL,
+    "email" text NOT NULL,
+    "password" text NOT NULL,
+    "role" text NOT NULL DEFAULT 'Farmer',
+    "phone_number" text,
+    "address" text,
+    "active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "otp_code" varchar(6),
+    "otp_expires_at" timestamptz,
+    PRIMARY KEY ("id","user_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "farms" (
+    "id" bigserial,
+    "farm_id" varchar(36) DEFAULT gen_random_uuid(),
+    "name" text NOT NULL,
+    "description" text,
+    "location" text NOT NULL,
+    "size" decimal NOT NULL,
+    "farm_type" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'Active',
+    "user_id" varchar(36) NOT NULL,
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","farm_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_farms_deleted_at" ON "farms" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "farm_members" (
+    "id" bigserial,
+    "farm_member_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "role" text NOT NULL,
+    "added_by" varchar(36) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","farm_member_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_farm_members_deleted_at" ON "farm_members" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_farm_members_user_id" ON "farm_members" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_farm_member" ON "farm_members" ("farm_id","user_id") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "fields" (
+    "id" bigserial,
+    "field_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "area" decimal NOT NULL,
+    "soil_type" text,
+    "notes" text,
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","field_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_fields_deleted_at" ON "fields" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_fields_farm_id" ON "fields" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "crops" (
+    "id" bigserial,
+    "crop_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "field_id" varchar(36),
+    "name" text NOT NULL,
+    "planting_date" timestamptz,
+    "harvest_date" timestamptz,
+    "quantity" decimal NOT NULL,
+    "status" text NOT NULL DEFAULT 'Growing',
+    "notes" text,
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","crop_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_crops_deleted_at" ON "crops" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_crops_field_id" ON "crops" ("field_id");
+
+CREATE TABLE IF NOT EXISTS "crop_plans" (
+    "id" bigserial,
+    "crop_plan_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "field_id" varchar(36) NOT NULL,
+    "crop_name" text NOT NULL,
+    "variety" text,
+    "planting_start" timestamptz NOT NULL,
+    "planting_end" timestamptz NOT NULL,
+    "expected_harvest" timestamptz NOT NULL,
+    "expected_yield" decimal,
+    "status" text NOT NULL DEFAULT 'Planned',
+    "budgeted_cost" decimal,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","crop_plan_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_crop_plans_deleted_at" ON "crop_plans" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_crop_plans_field_id" ON "crop_plans" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_crop_plans_farm_id" ON "crop_plans" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "crop_plan_inputs" (
+    "id" bigserial,
+    "crop_plan_input_id" varchar(36) DEFAULT gen_random_uuid(),
+    "crop_plan_id" varchar(36) NOT NULL,
+    "item" text NOT NULL,
+    "quantity" decimal,
+    "unit" text,
+    "cost" decimal,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id","crop_plan_input_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_crop_plan_inputs_crop_plan_id" ON "crop_plan_inputs" ("crop_plan_id");
+
+CREATE TABLE IF NOT EXISTS "plan_scenarios" (
+    "id" bigserial,
+    "plan_scenario_id" varchar(36) DEFAULT gen_random_uuid(),
+    "crop_plan_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "price_per_kg" decimal NOT NULL,
+    "expected_yield" decimal,
+    "input_cost_change" decimal,
+    "other_costs" decimal,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","plan_scenario_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_plan_scenarios_deleted_at" ON "plan_scenarios" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_plan_scenarios_farm_id" ON "plan_scenarios" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_plan_scenarios_crop_plan_id" ON "plan_scenarios" ("crop_plan_id");
+
+CREATE TABLE IF NOT EXISTS "livestocks" (
+    "id" bigserial,
+    "livestock_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "type" text NOT NULL,
+    "count" bigint NOT NULL,
+    "acquisition_date" timestamptz,
+    "health_status" text NOT NULL DEFAULT 'Healthy',
+    "notes" text,
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","livestock_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_livestocks_deleted_at" ON "livestocks" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "employees" (
+    "id" bigserial,
+    "employee_id" varchar(36) DEFAULT gen_random_uuid(),
+    "user_id" varchar(36),
+    "farm_id" varchar(36) NOT NULL,
+    "first_name" text NOT NULL,
+    "last_name" text NOT NULL,
+    "position" text NOT NULL,
+    "salary" decimal,
+    "hire_date" timestamptz,
+    "contact_info" text,
+    "status" text NOT NULL DEFAULT 'Active',
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","employee_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_employees_deleted_at" ON "employees" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "payroll_payments" (
+    "id" bigserial,
+    "payroll_payment_id" varchar(36) DEFAULT gen_random_uuid(),
+    "employee_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "period_start" timestamptz NOT NULL,
+    "period_end" timestamptz NOT NULL,
+    "gross_pay" decimal NOT NULL,
+    "deductions" decimal,
+    "net_pay" decimal NOT NULL,
+    "payment_date" timestamptz NOT NULL,
+    "payment_method" text NOT NULL,
+    "payment_reference" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","payroll_payment_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_payroll_payments_deleted_at" ON "payroll_payments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_payroll_payments_payment_date" ON "payroll_payments" ("payment_date");
+CREATE INDEX IF NOT EXISTS "idx_payroll_payments_farm_id" ON "payroll_payments" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_payroll_payments_employee_id" ON "payroll_payments" ("employee_id");
+
+CREATE TABLE IF NOT EXISTS "attendances" (
+    "id" bigserial,
+    "attendance_id" varchar(36) DEFAULT gen_random_uuid(),
+    "employee_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "clock_in" timestamptz NOT NULL,
+    "clock_out" timestamptz,
+    "hours" decimal,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","attendance_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_attendances_deleted_at" ON "attendances" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_attendances_clock_in" ON "attendances" ("clock_in");
+CREATE INDEX IF NOT EXISTS "idx_attendances_farm_id" ON "attendances" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_attendances_employee_id" ON "attendances" ("employee_id");
+
+CREATE TABLE IF NOT EXISTS "equipment" (
+    "id" bigserial,
+    "equipment_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "type" text NOT NULL,
+    "make" text,
+    "model" text,
+    "serial_number" text,
+    "purchase_date" timestamptz,
+    "purchase_cost" decimal,
+    "condition" text NOT NULL DEFAULT 'Good',
+    "maintenance_interval_days" bigint,
+    "last_maintenance_date" timestamptz,
+    "next_maintenance_date" timestamptz,
+    "assigned_employee_id" varchar(36),
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","equipment_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_equipment_deleted_at" ON "equipment" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_equipment_next_maintenance_date" ON "equipment" ("next_maintenance_date");
+CREATE INDEX IF NOT EXISTS "idx_equipment_farm_id" ON "equipment" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "maintenance_records" (
+    "id" bigserial,
+    "maintenance_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "equipment_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "date" timestamptz NOT NULL,
+    "type" text NOT NULL,
+    "description" text,
+    "cost" decimal,
+    "downtime_hours" decimal,
+    "parts_used" text,
+    "performed_by" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","maintenance_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_maintenance_records_deleted_at" ON "maintenance_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_records_date" ON "maintenance_records" ("date");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_records_farm_id" ON "maintenance_records" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_maintenance_records_equipment_id" ON "maintenance_records" ("equipment_id");
+
+CREATE TABLE IF NOT EXISTS "assets" (
+    "id" bigserial,
+    "asset_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "category" text NOT NULL,
+    "equipment_id" varchar(36),
+    "livestock_id" varchar(36),
+    "acquisition_date" timestamptz NOT NULL,
+    "acquisition_cost" decimal NOT NULL,
+    "book_value" decimal NOT NULL,
+    "status" text NOT NULL DEFAULT 'Active',
+    "disposed_at" timestamptz,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","asset_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_assets_deleted_at" ON "assets" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_assets_livestock_id" ON "assets" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_equipment_id" ON "assets" ("equipment_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_farm_id" ON "assets" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "asset_events" (
+    "id" bigserial,
+    "asset_event_id" varchar(36) DEFAULT gen_random_uuid(),
+    "asset_id" varchar(36) NOT NULL,
+    "type" text NOT NULL,
+    "date" timestamptz NOT NULL,
+    "value" decimal NOT NULL,
+    "method" text,
+    "proceeds" decimal,
+    "gain_or_loss" decimal,
+    "recorded_by" varchar(36),
+    "notes" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","asset_event_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_asset_events_asset_id" ON "asset_events" ("asset_id");
+
+CREATE TABLE IF NOT EXISTS "water_sources" (
+    "id" bigserial,
+    "water_source_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "source_type" text NOT NULL,
+    "permit_number" text,
+    "permit_expiry" timestamptz,
+    "daily_limit" decimal,
+    "annual_limit" decimal,
+    "alert_threshold" decimal NOT NULL DEFAULT 80,
+    "status" text NOT NULL DEFAULT 'Active',
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","water_source_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_water_sources_deleted_at" ON "water_sources" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "water_usages" (
+    "id" bigserial,
+    "water_usage_id" varchar(36) DEFAULT gen_random_uuid(),
+    "water_source_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "date" timestamptz NOT NULL,
+    "volume" decimal NOT NULL,
+    "purpose" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","water_usage_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_water_usages_deleted_at" ON "water_usages" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_water_usages_water_source_id" ON "water_usages" ("water_source_id");
+
+CREATE TABLE IF NOT EXISTS "irrigation_schedules" (
+    "id" bigserial,
+    "irrigation_schedule_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "field_id" varchar(36),
+    "crop_id" varchar(36),
+    "water_source_id" varchar(36),
+    "method" text NOT NULL,
+    "start_date" timestamptz NOT NULL,
+    "end_date" timestamptz,
+    "interval_days" bigint NOT NULL DEFAULT 1,
+    "start_time" text,
+    "duration_minutes" bigint NOT NULL,
+    "volume" decimal,
+    "status" text NOT NULL DEFAULT 'Active',
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","irrigation_schedule_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_irrigation_schedules_deleted_at" ON "irrigation_schedules" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_irrigation_schedules_crop_id" ON "irrigation_schedules" ("crop_id");
+CREATE INDEX IF NOT EXISTS "idx_irrigation_schedules_field_id" ON "irrigation_schedules" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_irrigation_schedules_farm_id" ON "irrigation_schedules" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "rainfall_records" (
+    "id" bigserial,
+    "rainfall_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "field_id" varchar(36),
+    "gauge" text NOT NULL,
+    "date" timestamptz NOT NULL,
+    "mm" decimal NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","rainfall_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_rainfall_records_deleted_at" ON "rainfall_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_rainfall_records_date" ON "rainfall_records" ("date");
+CREATE INDEX IF NOT EXISTS "idx_rainfall_records_field_id" ON "rainfall_records" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_rainfall_records_farm_id" ON "rainfall_records" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "paddocks" (
+    "id" bigserial,
+    "paddock_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "field_id" varchar(36),
+    "name" text NOT NULL,
+    "area" decimal NOT NULL,
+    "rest_days" bigint NOT NULL,
+    "max_graze_days" bigint NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","paddock_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_paddocks_deleted_at" ON "paddocks" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_paddocks_field_id" ON "paddocks" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_paddocks_farm_id" ON "paddocks" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "grazing_moves" (
+    "id" bigserial,
+    "grazing_move_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "paddock_id" varchar(36) NOT NULL,
+    "planned_start" timestamptz,
+    "planned_end" timestamptz,
+    "moved_in_at" timestamptz,
+    "moved_out_at" timestamptz,
+    "status" text NOT NULL DEFAULT 'Planned',
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","grazing_move_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_grazing_moves_deleted_at" ON "grazing_moves" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_grazing_moves_paddock_id" ON "grazing_moves" ("paddock_id");
+CREATE INDEX IF NOT EXISTS "idx_grazing_moves_livestock_id" ON "grazing_moves" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_grazing_moves_farm_id" ON "grazing_moves" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "chemical_products" (
+    "id" bigserial,
+    "chemical_product_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "active_ingredient" text NOT NULL,
+    "category" text,
+    "who_class" text NOT NULL,
+    "restricted" boolean DEFAULT false,
+    "batch_number" text NOT NULL,
+    "expiry_date" timestamptz,
+    "quantity" decimal NOT NULL,
+    "unit" text NOT NULL,
+    "storage_location" text,
+    "supplier" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","chemical_product_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_chemical_products_deleted_at" ON "chemical_products" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "chemical_usages" (
+    "id" bigserial,
+    "chemical_usage_id" varchar(36) DEFAULT gen_random_uuid(),
+    "chemical_product_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "date" timestamptz NOT NULL,
+    "quantity" decimal NOT NULL,
+    "applicator" text NOT NULL,
+    "applicator_employee_id" varchar(36),
+    "ppe_confirmed" boolean NOT NULL,
+    "target" text,
+    "purpose" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","chemical_usage_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_chemical_usages_deleted_at" ON "chemical_usages" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_chemical_usages_chemical_product_id" ON "chemical_usages" ("chemical_product_id");
+
+CREATE TABLE IF NOT EXISTS "inventory_items" (
+    "id" bigserial,
+    "inventory_item_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "category" text NOT NULL,
+    "unit" text NOT NULL,
+    "nitrogen_percent" decimal,
+    "reorder_level" decimal,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","inventory_item_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_inventory_items_deleted_at" ON "inventory_items" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "inventory_batches" (
+    "id" bigserial,
+    "inventory_batch_id" varchar(36) DEFAULT gen_random_uuid(),
+    "inventory_item_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "batch_number" text,
+    "quantity" decimal NOT NULL,
+    "initial_quantity" decimal NOT NULL,
+    "unit_cost" decimal,
+    "received_date" timestamptz NOT NULL,
+    "expiry_date" timestamptz,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","inventory_batch_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_deleted_at" ON "inventory_batches" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_expiry_date" ON "inventory_batches" ("expiry_date");
+CREATE INDEX IF NOT EXISTS "idx_inventory_batches_inventory_item_id" ON "inventory_batches" ("inventory_item_id");
+
+CREATE TABLE IF NOT EXISTS "inventory_movements" (
+    "id" bigserial,
+    "inventory_movement_id" varchar(36) DEFAULT gen_random_uuid(),
+    "inventory_item_id" varchar(36) NOT NULL,
+    "inventory_batch_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "type" text NOT NULL,
+    "quantity" decimal NOT NULL,
+    "date" timestamptz NOT NULL,
+    "purpose" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","inventory_movement_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_deleted_at" ON "inventory_movements" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_inventory_batch_id" ON "inventory_movements" ("inventory_batch_id");
+CREATE INDEX IF NOT EXISTS "idx_inventory_movements_inventory_item_id" ON "inventory_movements" ("inventory_item_id");
+
+CREATE TABLE IF NOT EXISTS "suppliers" (
+    "id" bigserial,
+    "supplier_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "contact_name" text,
+    "phone_number" text,
+    "email" text,
+    "address" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","supplier_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_suppliers_deleted_at" ON "suppliers" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_suppliers_farm_id" ON "suppliers" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "purchase_orders" (
+    "id" bigserial,
+    "purchase_order_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "supplier_id" varchar(36) NOT NULL,
+    "reference" text,
+    "status" text NOT NULL DEFAULT 'Draft',
+    "ordered_at" timestamptz,
+    "expected_date" timestamptz,
+    "received_at" timestamptz,
+    "total" decimal NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","purchase_order_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_deleted_at" ON "purchase_orders" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_supplier_id" ON "purchase_orders" ("supplier_id");
+CREATE INDEX IF NOT EXISTS "idx_purchase_orders_farm_id" ON "purchase_orders" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "purchase_order_lines" (
+    "id" bigserial,
+    "purchase_order_line_id" varchar(36) DEFAULT gen_random_uuid(),
+    "purchase_order_id" varchar(36) NOT NULL,
+    "inventory_item_id" varchar(36) NOT NULL,
+    "quantity" decimal NOT NULL,
+    "unit_cost" decimal NOT NULL,
+    "total" decimal NOT NULL,
+    "inventory_batch_id" varchar(36),
+    "batch_number" text,
+    "expiry_date" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","purchase_order_line_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_purchase_order_lines_purchase_order_id" ON "purchase_order_lines" ("purchase_order_id");
+
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id" bigserial,
+    "notification_id" varchar(36) DEFAULT gen_random_uuid(),
+    "user_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36),
+    "type" text NOT NULL,
+    "title" text NOT NULL,
+    "message" text,
+    "reference" text,
+    "read_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","notification_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notifications_deleted_at" ON "notifications" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_notifications_reference" ON "notifications" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_id" ON "notifications" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "buyer_profiles" (
+    "id" bigserial,
+    "buyer_profile_id" varchar(36) DEFAULT gen_random_uuid(),
+    "user_id" varchar(36) NOT NULL,
+    "business_name" text NOT NULL,
+    "registration_number" text,
+    "location" text,
+    "document_url" text,
+    "status" text NOT NULL DEFAULT 'Pending',
+    "reviewed_by" varchar(36),
+    "reviewed_at" timestamptz,
+    "review_notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","buyer_profile_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_buyer_profiles_deleted_at" ON "buyer_profiles" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_buyer_profiles_user_id" ON "buyer_profiles" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "ratings" (
+    "id" bigserial,
+    "rating_id" varchar(36) DEFAULT gen_random_uuid(),
+    "rater_id" varchar(36) NOT NULL,
+    "ratee_id" varchar(36) NOT NULL,
+    "sale_reference" text NOT NULL,
+    "score" bigint NOT NULL,
+    "review" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","rating_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_ratings_deleted_at" ON "ratings" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_ratings_ratee_id" ON "ratings" ("ratee_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_rating_sale" ON "ratings" ("rater_id","ratee_id","sale_reference");
+
+CREATE TABLE IF NOT EXISTS "disputes" (
+    "id" bigserial,
+    "dispute_id" varchar(36) DEFAULT gen_random_uuid(),
+    "sale_reference" text NOT NULL,
+    "claimant_id" varchar(36) NOT NULL,
+    "respondent_id" varchar(36) NOT NULL,
+    "reason" text NOT NULL,
+    "description" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'Open',
+    "outcome" text,
+    "resolution" text,
+    "resolved_by" varchar(36),
+    "resolved_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","dispute_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_disputes_deleted_at" ON "disputes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_disputes_status" ON "disputes" ("status");
+CREATE INDEX IF NOT EXISTS "idx_disputes_respondent_id" ON "disputes" ("respondent_id");
+CREATE INDEX IF NOT EXISTS "idx_disputes_claimant_id" ON "disputes" ("claimant_id");
+CREATE INDEX IF NOT EXISTS "idx_disputes_sale_reference" ON "disputes" ("sale_reference");
+
+CREATE TABLE IF NOT EXISTS "dispute_evidences" (
+    "id" bigserial,
+    "dispute_evidence_id" varchar(36) DEFAULT gen_random_uuid(),
+    "dispute_id" varchar(36) NOT NULL,
+    "submitted_by" varchar(36) NOT NULL,
+    "url" text NOT NULL,
+    "description" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","dispute_evidence_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dispute_evidences_dispute_id" ON "dispute_evidences" ("dispute_id");
+
+CREATE TABLE IF NOT EXISTS "escrows" (
+    "id" bigserial,
+    "escrow_id" varchar(36) DEFAULT gen_random_uuid(),
+    "sale_reference" text NOT NULL,
+    "buyer_id" varchar(36) NOT NULL,
+    "farmer_id" varchar(36) NOT NULL,
+    "amount" decimal NOT NULL,
+    "payment_reference" text,
+    "status" text NOT NULL DEFAULT 'Held',
+    "release_code" varchar(8),
+    "release_after" timestamptz NOT NULL,
+    "released_at" timestamptz,
+    "release_method" text,
+    "refunded_at" timestamptz,
+    "refund_reason" text,
+    "closed_by" varchar(36),
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","escrow_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_escrows_deleted_at" ON "escrows" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_escrows_release_after" ON "escrows" ("release_after");
+CREATE INDEX IF NOT EXISTS "idx_escrows_status" ON "escrows" ("status");
+CREATE INDEX IF NOT EXISTS "idx_escrows_farmer_id" ON "escrows" ("farmer_id");
+CREATE INDEX IF NOT EXISTS "idx_escrows_buyer_id" ON "escrows" ("buyer_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_escrows_sale_reference" ON "escrows" ("sale_reference");
+
+CREATE TABLE IF NOT EXISTS "market_prices" (
+    "id" bigserial,
+    "market_price_id" varchar(36) DEFAULT gen_random_uuid(),
+    "commodity" text NOT NULL,
+    "region" text NOT NULL,
+    "unit" text NOT NULL,
+    "date" timestamptz NOT NULL,
+    "price" decimal NOT NULL,
+    "currency" text NOT NULL,
+    "source" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id","market_price_id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_market_price_day" ON "market_prices" ("commodity","region","unit","date");
+
+CREATE TABLE IF NOT EXISTS "transactions" (
+    "id" bigserial,
+    "transaction_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "type" text NOT NULL,
+    "category" text NOT NULL,
+    "amount" decimal NOT NULL,
+    "date" timestamptz NOT NULL,
+    "description" text,
+    "reference" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","transaction_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_transactions_deleted_at" ON "transactions" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_transactions_reference" ON "transactions" ("reference");
+CREATE INDEX IF NOT EXISTS "idx_transactions_date" ON "transactions" ("date");
+CREATE INDEX IF NOT EXISTS "idx_transactions_farm_id" ON "transactions" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "utility_records" (
+    "id" bigserial,
+    "utility_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "utility_type" text NOT NULL,
+    "record_type" text NOT NULL,
+    "date" timestamptz NOT NULL,
+    "meter_reading" decimal,
+    "quantity" decimal NOT NULL,
+    "unit" text NOT NULL,
+    "cost" decimal,
+    "provider" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","utility_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_utility_records_deleted_at" ON "utility_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_utility_records_date" ON "utility_records" ("date");
+CREATE INDEX IF NOT EXISTS "idx_utility_records_farm_id" ON "utility_records" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "period_locks" (
+    "id" bigserial,
+    "period_lock_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "period_start" timestamptz NOT NULL,
+    "period_end" timestamptz NOT NULL,
+    "reason" text,
+    "locked_by" varchar(36) NOT NULL,
+    "unlocked_by" varchar(36),
+    "unlocked_at" timestamptz,
+    "unlock_reason" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id","period_lock_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_period_locks_farm_id" ON "period_locks" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "tax_rates" (
+    "id" bigserial,
+    "tax_rate_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "jurisdiction" text NOT NULL,
+    "basis" text NOT NULL,
+    "category" text,
+    "rate" decimal NOT NULL,
+    "inclusive" boolean,
+    "active" boolean DEFAULT true,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","tax_rate_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_tax_rates_deleted_at" ON "tax_rates" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_tax_rates_farm_id" ON "tax_rates" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "sustainability_practices" (
+    "id" bigserial,
+    "sustainability_practice_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "category" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text,
+    "weight" decimal NOT NULL DEFAULT 1,
+    "evidence_required" boolean DEFAULT false,
+    "active" boolean DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","sustainability_practice_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sustainability_practices_deleted_at" ON "sustainability_practices" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_sustainability_practices_farm_id" ON "sustainability_practices" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "sustainability_assessments" (
+    "id" bigserial,
+    "sustainability_assessment_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "season" text NOT NULL,
+    "assessed_at" timestamptz NOT NULL,
+    "assessed_by" text,
+    "status" text NOT NULL DEFAULT 'Draft',
+    "score" decimal,
+    "max_score" decimal,
+    "score_percent" decimal,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","sustainability_assessment_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sustainability_assessments_deleted_at" ON "sustainability_assessments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_sustainability_assessments_farm_id" ON "sustainability_assessments" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "sustainability_responses" (
+    "id" bigserial,
+    "sustainability_response_id" varchar(36) DEFAULT gen_random_uuid(),
+    "sustainability_assessment_id" varchar(36) NOT NULL,
+    "sustainability_practice_id" varchar(36) NOT NULL,
+    "status" text NOT NULL,
+    "evidence" text,
+    "evidence_url" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id","sustainability_response_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sustainability_responses_sustainability_assessment_id" ON "sustainability_responses" ("sustainability_assessment_id");
+
+CREATE TABLE IF NOT EXISTS "import_jobs" (
+    "id" bigserial,
+    "import_job_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "target" text NOT NULL,
+    "source" text NOT NULL,
+    "file_name" text,
+    "delimiter" varchar(1) NOT NULL,
+    "date_format" text NOT NULL,
+    "headers" text,
+    "mapping" text,
+    "row_count" bigint,
+    "valid_rows" bigint,
+    "error_count" bigint,
+    "errors" text,
+    "imported_count" bigint,
+    "status" text NOT NULL DEFAULT 'Pending',
+    "committed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","import_job_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_import_jobs_deleted_at" ON "import_jobs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_import_jobs_farm_id" ON "import_jobs" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "report_jobs" (
+    "id" bigserial,
+    "report_job_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "type" text NOT NULL,
+    "period" text NOT NULL,
+    "period_start" timestamptz NOT NULL,
+    "period_end" timestamptz NOT NULL,
+    "status" text NOT NULL DEFAULT 'Pending',
+    "error" text,
+    "size" bigint,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","report_job_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_report_jobs_deleted_at" ON "report_jobs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_report_jobs_status" ON "report_jobs" ("status");
+CREATE INDEX IF NOT EXISTS "idx_report_jobs_farm_id" ON "report_jobs" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "attachments" (
+    "id" bigserial,
+    "attachment_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "record_type" text NOT NULL,
+    "record_id" varchar(36) NOT NULL,
+    "file_name" text NOT NULL,
+    "content_type" text NOT NULL,
+    "size" bigint,
+    "caption" text,
+    "status" text NOT NULL DEFAULT 'Pending',
+    "uploaded_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id","attachment_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_attachment_record" ON "attachments" ("record_type","record_id");
+CREATE INDEX IF NOT EXISTS "idx_attachments_farm_id" ON "attachments" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "documents" (
+    "id" bigserial,
+    "document_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "type" text NOT NULL,
+    "title" text NOT NULL,
+    "number" text,
+    "issuer" text,
+    "issued_at" timestamptz,
+    "expires_at" timestamptz,
+    "reminder_days" bigint NOT NULL DEFAULT 30,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","document_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_documents_deleted_at" ON "documents" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_documents_expires_at" ON "documents" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_documents_farm_id" ON "documents" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "dashboard_layouts" (
+    "id" bigserial,
+    "dashboard_layout_id" varchar(36) DEFAULT gen_random_uuid(),
+    "user_id" varchar(36) NOT NULL,
+    "widgets" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","dashboard_layout_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dashboard_layouts_deleted_at" ON "dashboard_layouts" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_dashboard_layout_user" ON "dashboard_layouts" ("user_id") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "organizations" (
+    "id" bigserial,
+    "organization_id" varchar(36) DEFAULT gen_random_uuid(),
+    "name" text NOT NULL,
+    "description" text,
+    "created_by" varchar(36) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","organization_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_organizations_deleted_at" ON "organizations" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "organization_members" (
+    "id" bigserial,
+    "organization_member_id" varchar(36) DEFAULT gen_random_uuid(),
+    "organization_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "role" text NOT NULL DEFAULT 'Member',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","organization_member_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_organization_members_deleted_at" ON "organization_members" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_organization_members_user_id" ON "organization_members" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_organization_member" ON "organization_members" ("organization_id","user_id") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "procurement_windows" (
+    "id" bigserial,
+    "procurement_window_id" varchar(36) DEFAULT gen_random_uuid(),
+    "organization_id" varchar(36) NOT NULL,
+    "title" text NOT NULL,
+    "notes" text,
+    "closes_at" timestamptz NOT NULL,
+    "status" text NOT NULL DEFAULT 'Open',
+    "created_by" varchar(36) NOT NULL,
+    "consolidated_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","procurement_window_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_procurement_windows_deleted_at" ON "procurement_windows" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_procurement_windows_organization_id" ON "procurement_windows" ("organization_id");
+
+CREATE TABLE IF NOT EXISTS "procurement_items" (
+    "id" bigserial,
+    "procurement_item_id" varchar(36) DEFAULT gen_random_uuid(),
+    "procurement_window_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "unit" text NOT NULL,
+    "supplier" text NOT NULL,
+    "unit_price" decimal,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","procurement_item_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_procurement_items_procurement_window_id" ON "procurement_items" ("procurement_window_id");
+
+CREATE TABLE IF NOT EXISTS "procurement_requests" (
+    "id" bigserial,
+    "procurement_request_id" varchar(36) DEFAULT gen_random_uuid(),
+    "procurement_window_id" varchar(36) NOT NULL,
+    "procurement_item_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "quantity" decimal NOT NULL,
+    "procurement_order_id" varchar(36),
+    "status" text NOT NULL DEFAULT 'Requested',
+    "delivered_quantity" decimal,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","procurement_request_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_procurement_requests_deleted_at" ON "procurement_requests" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_procurement_requests_procurement_order_id" ON "procurement_requests" ("procurement_order_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_procurement_request" ON "procurement_requests" ("procurement_item_id","user_id") WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS "idx_procurement_requests_procurement_window_id" ON "procurement_requests" ("procurement_window_id");
+
+CREATE TABLE IF NOT EXISTS "procurement_orders" (
+    "id" bigserial,
+    "procurement_order_id" varchar(36) DEFAULT gen_random_uuid(),
+    "procurement_window_id" varchar(36) NOT NULL,
+    "organization_id" varchar(36) NOT NULL,
+    "supplier" text NOT NULL,
+    "lines" text,
+    "total" decimal NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","procurement_order_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_procurement_orders_deleted_at" ON "procurement_orders" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_procurement_orders_organization_id" ON "procurement_orders" ("organization_id");
+CREATE INDEX IF NOT EXISTS "idx_procurement_orders_procurement_window_id" ON "procurement_orders" ("procurement_window_id");
+
+CREATE TABLE IF NOT EXISTS "collection_centers" (
+    "id" bigserial,
+    "collection_center_id" varchar(36) DEFAULT gen_random_uuid(),
+    "name" text NOT NULL,
+    "location" text,
+    "manager_id" varchar(36) NOT NULL,
+    "price_per_litre" decimal NOT NULL,
+    "api_key_hash" text NOT NULL,
+    "api_key_prefix" text NOT NULL,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","collection_center_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_collection_centers_deleted_at" ON "collection_centers" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_collection_centers_api_key_hash" ON "collection_centers" ("api_key_hash");
+CREATE INDEX IF NOT EXISTS "idx_collection_centers_manager_id" ON "collection_centers" ("manager_id");
+
+CREATE TABLE IF NOT EXISTS "milk_suppliers" (
+    "id" bigserial,
+    "milk_supplier_id" varchar(36) DEFAULT gen_random_uuid(),
+    "collection_center_id" varchar(36) NOT NULL,
+    "supplier_number" text NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "linked_by" varchar(36) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","milk_supplier_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_milk_suppliers_deleted_at" ON "milk_suppliers" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_milk_suppliers_farm_id" ON "milk_suppliers" ("farm_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_milk_supplier_farm" ON "milk_suppliers" ("collection_center_id","farm_id") WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_milk_supplier_number" ON "milk_suppliers" ("collection_center_id","supplier_number") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "milk_deliveries" (
+    "id" bigserial,
+    "milk_delivery_id" varchar(36) DEFAULT gen_random_uuid(),
+    "collection_center_id" varchar(36) NOT NULL,
+    "reference" text NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "supplier_number" text NOT NULL,
+    "date" timestamptz NOT NULL,
+    "session" text NOT NULL,
+    "volume" decimal NOT NULL,
+    "fat" decimal,
+    "snf" decimal,
+    "density" decimal,
+    "accepted" boolean NOT NULL,
+    "rejection_reason" text,
+    "price_per_litre" decimal NOT NULL,
+    "amount" decimal NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","milk_delivery_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_milk_deliveries_deleted_at" ON "milk_deliveries" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_milk_deliveries_date" ON "milk_deliveries" ("date");
+CREATE INDEX IF NOT EXISTS "idx_milk_deliveries_farm_id" ON "milk_deliveries" ("farm_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_milk_delivery_reference" ON "milk_deliveries" ("collection_center_id","reference") WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS "sync_mappings" (
+    "id" bigserial,
+    "farm_id" varchar(36) NOT NULL,
+    "entity" text NOT NULL,
+    "client_id" text NOT NULL,
+    "record_id" varchar(36) NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sync_mapping" ON "sync_mappings" ("farm_id","entity","client_id");
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id" bigserial,
+    "audit_log_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36),
+    "user_id" varchar(36) NOT NULL,
+    "action" text NOT NULL,
+    "entity_type" text NOT NULL,
+    "entity_id" varchar(36),
+    "details" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","audit_log_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity_id" ON "audit_logs" ("entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_user_id" ON "audit_logs" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_farm_id" ON "audit_logs" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "api_usages" (
+    "id" bigserial,
+    "user_id" varchar(36) NOT NULL,
+    "date" date NOT NULL,
+    "endpoint" text NOT NULL,
+    "count" bigint NOT NULL,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_usage_user_day_endpoint" ON "api_usages" ("user_id","date","endpoint");
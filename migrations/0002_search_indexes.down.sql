@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_documents_search";
+DROP INDEX IF EXISTS "idx_employees_search";
+DROP INDEX IF EXISTS "idx_livestock_search";
+DROP INDEX IF EXISTS "idx_crops_search";
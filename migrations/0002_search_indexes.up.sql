@@ -0,0 +1,18 @@
+-- Full-text search indexes. Each expression must match the search text of
+-- its record type in data/search.go exactly, or searches will not use it.
+
+CREATE INDEX IF NOT EXISTS "idx_crops_search" ON "crops" USING GIN (
+    to_tsvector('english', coalesce(name, '') || ' ' || coalesce(status, '') || ' ' || coalesce(notes, ''))
+);
+
+CREATE INDEX IF NOT EXISTS "idx_livestock_search" ON "livestock" USING GIN (
+    to_tsvector('english', coalesce(type, '') || ' ' || coalesce(health_status, '') || ' ' || coalesce(notes, ''))
+);
+
+CREATE INDEX IF NOT EXISTS "idx_employees_search" ON "employees" USING GIN (
+    to_tsvector('english', coalesce(first_name, '') || ' ' || coalesce(last_name, '') || ' ' || coalesce(position, '') || ' ' || coalesce(contact_info, ''))
+);
+
+CREATE INDEX IF NOT EXISTS "idx_documents_search" ON "documents" USING GIN (
+    to_tsvector('english', coalesce(type, '') || ' ' || coalesce(title, '') || ' ' || coalesce(number, '') || ' ' || coalesce(issuer, '') || ' ' || coalesce(notes, ''))
+);
@@ -0,0 +1,2 @@
+-- Drops the token denylist
+DROP TABLE IF EXISTS "revoked_tokens";
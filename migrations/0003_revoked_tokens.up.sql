@@ -0,0 +1,13 @@
+-- Denylist of access tokens revoked before they expire, as on logout
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+    "id" bigserial,
+    "token_id" varchar(64) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_user_id" ON "revoked_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_revoked_tokens_token_id" ON "revoked_tokens" ("token_id");
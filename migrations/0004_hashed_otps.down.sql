@@ -0,0 +1,5 @@
+-- Restores the plaintext code column. Outstanding codes are not carried
+-- back and must be requested again.
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "otp_code" varchar(6);
+ALTER TABLE "users" DROP COLUMN IF EXISTS "otp_attempts";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "otp_hash";
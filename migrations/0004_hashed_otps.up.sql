@@ -0,0 +1,7 @@
+-- One-time codes are stored hashed, with a count of wrong guesses. Codes
+-- issued before were stored in plaintext and are dropped; users request a
+-- new one.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "otp_hash" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "otp_attempts" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" DROP COLUMN IF EXISTS "otp_code";
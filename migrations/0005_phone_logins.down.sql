@@ -0,0 +1,2 @@
+-- Drops the pending phone logins
+DROP TABLE IF EXISTS "phone_logins";
@@ -0,0 +1,17 @@
+-- Pending passwordless logins by phone number and SMS code
+
+CREATE TABLE IF NOT EXISTS "phone_logins" (
+    "id" bigserial,
+    "phone_login_id" varchar(36) DEFAULT gen_random_uuid(),
+    "phone_number" text NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "code_hash" text NOT NULL,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","phone_login_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_phone_logins_expires_at" ON "phone_logins" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_phone_logins_user_id" ON "phone_logins" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_phone_logins_phone_number" ON "phone_logins" ("phone_number");
@@ -0,0 +1,3 @@
+-- Drops the breeding events and birth records
+DROP TABLE IF EXISTS "birth_records";
+DROP TABLE IF EXISTS "breeding_events";
@@ -0,0 +1,47 @@
+-- Breeding events and the calving, kidding and lambing records that close them
+
+CREATE TABLE IF NOT EXISTS "breeding_events" (
+    "id" bigserial,
+    "breeding_event_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "dam_tag" text NOT NULL,
+    "sire_tag" text,
+    "service_date" timestamptz NOT NULL,
+    "method" text NOT NULL DEFAULT 'Natural',
+    "expected_due_date" timestamptz NOT NULL,
+    "outcome" text NOT NULL DEFAULT 'Pending',
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","breeding_event_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_breeding_events_deleted_at" ON "breeding_events" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_breeding_events_expected_due_date" ON "breeding_events" ("expected_due_date");
+CREATE INDEX IF NOT EXISTS "idx_breeding_events_livestock_id" ON "breeding_events" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_breeding_events_farm_id" ON "breeding_events" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "birth_records" (
+    "id" bigserial,
+    "birth_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "breeding_event_id" varchar(36),
+    "dam_tag" text NOT NULL,
+    "birth_date" timestamptz NOT NULL,
+    "live_born" bigint NOT NULL DEFAULT 0,
+    "stillborn" bigint NOT NULL DEFAULT 0,
+    "male" bigint NOT NULL DEFAULT 0,
+    "female" bigint NOT NULL DEFAULT 0,
+    "assisted" boolean NOT NULL DEFAULT false,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","birth_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_birth_records_deleted_at" ON "birth_records" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_birth_records_breeding_event_id" ON "birth_records" ("breeding_event_id");
+CREATE INDEX IF NOT EXISTS "idx_birth_records_livestock_id" ON "birth_records" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_birth_records_farm_id" ON "birth_records" ("farm_id");
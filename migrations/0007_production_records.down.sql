@@ -0,0 +1,2 @@
+-- Drops the production records
+DROP TABLE IF EXISTS "production_records";
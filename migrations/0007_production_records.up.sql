@@ -0,0 +1,22 @@
+-- Daily milk, egg and other production of herds and flocks
+
+CREATE TABLE IF NOT EXISTS "production_records" (
+    "id" bigserial,
+    "production_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "animal_tag" text,
+    "product_type" text NOT NULL,
+    "date" timestamptz NOT NULL,
+    "quantity" decimal NOT NULL,
+    "unit" text NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","production_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_production_records_deleted_at" ON "production_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_production_records_date" ON "production_records" ("date");
+CREATE INDEX IF NOT EXISTS "idx_production_records_livestock_id" ON "production_records" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_production_records_farm_id" ON "production_records" ("farm_id");
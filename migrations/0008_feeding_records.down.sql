@@ -0,0 +1,2 @@
+-- Drops the feeding records
+DROP TABLE IF EXISTS "feeding_records";
@@ -0,0 +1,23 @@
+-- Feed given to herds and flocks out of inventory
+
+CREATE TABLE IF NOT EXISTS "feeding_records" (
+    "id" bigserial,
+    "feeding_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "inventory_item_id" varchar(36) NOT NULL,
+    "date" timestamptz NOT NULL,
+    "quantity" decimal NOT NULL,
+    "cost" decimal NOT NULL DEFAULT 0,
+    "head_count" bigint NOT NULL DEFAULT 0,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","feeding_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_feeding_records_deleted_at" ON "feeding_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_feeding_records_date" ON "feeding_records" ("date");
+CREATE INDEX IF NOT EXISTS "idx_feeding_records_inventory_item_id" ON "feeding_records" ("inventory_item_id");
+CREATE INDEX IF NOT EXISTS "idx_feeding_records_livestock_id" ON "feeding_records" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_feeding_records_farm_id" ON "feeding_records" ("farm_id");
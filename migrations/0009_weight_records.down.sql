@@ -0,0 +1,2 @@
+-- Drops the weight records
+DROP TABLE IF EXISTS "weight_records";
@@ -0,0 +1,21 @@
+-- Weight records behind the growth curves
+
+CREATE TABLE IF NOT EXISTS "weight_records" (
+    "id" bigserial,
+    "weight_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "animal_tag" text,
+    "date" timestamptz NOT NULL,
+    "weight" decimal NOT NULL,
+    "head_count" bigint NOT NULL DEFAULT 1,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","weight_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_weight_records_deleted_at" ON "weight_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_weight_records_animal_tag" ON "weight_records" ("animal_tag");
+CREATE INDEX IF NOT EXISTS "idx_weight_records_livestock_id" ON "weight_records" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_weight_records_farm_id" ON "weight_records" ("farm_id");
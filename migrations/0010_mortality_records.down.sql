@@ -0,0 +1,2 @@
+-- Drops the mortality records
+DROP TABLE IF EXISTS "mortality_records";
@@ -0,0 +1,23 @@
+-- Animals lost to death or culling
+
+CREATE TABLE IF NOT EXISTS "mortality_records" (
+    "id" bigserial,
+    "mortality_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "livestock_id" varchar(36) NOT NULL,
+    "animal_tag" text,
+    "kind" text NOT NULL,
+    "count" bigint NOT NULL DEFAULT 1,
+    "date" timestamptz NOT NULL,
+    "cause" text NOT NULL,
+    "value_lost" decimal NOT NULL DEFAULT 0,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","mortality_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_mortality_records_deleted_at" ON "mortality_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_mortality_records_date" ON "mortality_records" ("date");
+CREATE INDEX IF NOT EXISTS "idx_mortality_records_livestock_id" ON "mortality_records" ("livestock_id");
+CREATE INDEX IF NOT EXISTS "idx_mortality_records_farm_id" ON "mortality_records" ("farm_id");
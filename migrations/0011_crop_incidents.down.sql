@@ -0,0 +1,2 @@
+-- Drops the crop incidents
+DROP TABLE IF EXISTS "crop_incidents";
@@ -0,0 +1,28 @@
+-- Pest and disease incidents on crops and fields
+
+CREATE TABLE IF NOT EXISTS "crop_incidents" (
+    "id" bigserial,
+    "crop_incident_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "crop_id" varchar(36),
+    "field_id" varchar(36),
+    "type" text NOT NULL,
+    "name" text NOT NULL,
+    "severity" text NOT NULL,
+    "observed_at" timestamptz NOT NULL,
+    "affected_area" decimal,
+    "treatment" text,
+    "cost" decimal,
+    "status" text NOT NULL DEFAULT 'Open',
+    "resolved_at" timestamptz,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","crop_incident_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_crop_incidents_deleted_at" ON "crop_incidents" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_crop_incidents_observed_at" ON "crop_incidents" ("observed_at");
+CREATE INDEX IF NOT EXISTS "idx_crop_incidents_field_id" ON "crop_incidents" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_crop_incidents_crop_id" ON "crop_incidents" ("crop_id");
+CREATE INDEX IF NOT EXISTS "idx_crop_incidents_farm_id" ON "crop_incidents" ("farm_id");
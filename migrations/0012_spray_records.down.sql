@@ -0,0 +1,2 @@
+-- Drops the spray records
+DROP TABLE IF EXISTS "spray_records";
@@ -0,0 +1,39 @@
+-- Spray records for the input application compliance log
+
+CREATE TABLE IF NOT EXISTS "spray_records" (
+    "id" bigserial,
+    "spray_record_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "crop_id" varchar(36) NOT NULL,
+    "field_id" varchar(36),
+    "chemical_product_id" varchar(36) NOT NULL,
+    "chemical_usage_id" varchar(36) NOT NULL,
+    "date" timestamptz NOT NULL,
+    "rate" decimal NOT NULL,
+    "rate_unit" text NOT NULL,
+    "area_sprayed" decimal,
+    "quantity_used" decimal NOT NULL,
+    "applicator" text NOT NULL,
+    "applicator_employee_id" varchar(36),
+    "ppe_confirmed" boolean NOT NULL,
+    "wind_speed" decimal,
+    "temperature" decimal,
+    "humidity" decimal,
+    "weather_conditions" text,
+    "pre_harvest_interval_days" bigint NOT NULL DEFAULT 0,
+    "safe_harvest_date" timestamptz NOT NULL,
+    "target" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","spray_record_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_spray_records_deleted_at" ON "spray_records" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_spray_records_safe_harvest_date" ON "spray_records" ("safe_harvest_date");
+CREATE INDEX IF NOT EXISTS "idx_spray_records_date" ON "spray_records" ("date");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_spray_records_chemical_usage_id" ON "spray_records" ("chemical_usage_id");
+CREATE INDEX IF NOT EXISTS "idx_spray_records_chemical_product_id" ON "spray_records" ("chemical_product_id");
+CREATE INDEX IF NOT EXISTS "idx_spray_records_field_id" ON "spray_records" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_spray_records_crop_id" ON "spray_records" ("crop_id");
+CREATE INDEX IF NOT EXISTS "idx_spray_records_farm_id" ON "spray_records" ("farm_id");
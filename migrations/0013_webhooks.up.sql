@@ -0,0 +1,44 @@
+-- Webhook subscriptions and their delivery log
+
+CREATE TABLE IF NOT EXISTS "webhooks" (
+    "id" bigserial,
+    "webhook_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "url" text NOT NULL,
+    "description" text,
+    "secret" text NOT NULL,
+    "event_types" text,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_by" varchar(36) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","webhook_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhooks_deleted_at" ON "webhooks" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_webhooks_farm_id" ON "webhooks" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" bigserial,
+    "webhook_delivery_id" varchar(36) DEFAULT gen_random_uuid(),
+    "webhook_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "event" text NOT NULL,
+    "payload" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'Pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz,
+    "last_attempt_at" timestamptz,
+    "response_status" bigint,
+    "error" text,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","webhook_delivery_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_deleted_at" ON "webhook_deliveries" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_next_attempt_at" ON "webhook_deliveries" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_status" ON "webhook_deliveries" ("status");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_farm_id" ON "webhook_deliveries" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_webhook_id" ON "webhook_deliveries" ("webhook_id");
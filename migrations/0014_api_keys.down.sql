@@ -0,0 +1,2 @@
+-- Drops the partner API keys
+DROP TABLE IF EXISTS "api_keys";
@@ -0,0 +1,22 @@
+-- Read-only API keys farmers issue to partners
+
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" bigserial,
+    "api_key_id" varchar(36) DEFAULT gen_random_uuid(),
+    "user_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36),
+    "name" text NOT NULL,
+    "prefix" text NOT NULL,
+    "key_hash" text NOT NULL,
+    "scopes" text,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","api_key_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_deleted_at" ON "api_keys" ("deleted_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+CREATE INDEX IF NOT EXISTS "idx_api_keys_farm_id" ON "api_keys" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_api_keys_user_id" ON "api_keys" ("user_id");
@@ -0,0 +1,2 @@
+-- Drops the account export jobs table
+DROP TABLE IF EXISTS "export_jobs";
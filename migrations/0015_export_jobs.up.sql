@@ -0,0 +1,21 @@
+-- Account exports of a user's farm records and attached files
+
+CREATE TABLE IF NOT EXISTS "export_jobs" (
+    "id" bigserial,
+    "export_job_id" varchar(36) DEFAULT gen_random_uuid(),
+    "user_id" varchar(36) NOT NULL,
+    "status" text NOT NULL DEFAULT 'Pending',
+    "error" text,
+    "farms" bigint,
+    "files" bigint,
+    "size" bigint,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","export_job_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_export_jobs_deleted_at" ON "export_jobs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_export_jobs_status" ON "export_jobs" ("status");
+CREATE INDEX IF NOT EXISTS "idx_export_jobs_user_id" ON "export_jobs" ("user_id");
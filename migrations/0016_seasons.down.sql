@@ -0,0 +1,4 @@
+-- Drops seasons and the links to them.
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "season_id";
+ALTER TABLE "crops" DROP COLUMN IF EXISTS "season_id";
+DROP TABLE IF EXISTS "seasons";
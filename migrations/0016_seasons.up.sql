@@ -0,0 +1,26 @@
+-- Seasons group a farm's crops and transactions for season-over-season
+-- comparison. Records already kept are linked by date, so season_id is left
+-- unset on them.
+
+CREATE TABLE IF NOT EXISTS "seasons" (
+    "id" bigserial,
+    "season_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "start_date" timestamptz NOT NULL,
+    "end_date" timestamptz NOT NULL,
+    "status" text NOT NULL DEFAULT 'Open',
+    "closed_at" timestamptz,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","season_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_seasons_deleted_at" ON "seasons" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_seasons_farm_id" ON "seasons" ("farm_id");
+
+ALTER TABLE "crops" ADD COLUMN IF NOT EXISTS "season_id" varchar(36);
+CREATE INDEX IF NOT EXISTS "idx_crops_season_id" ON "crops" ("season_id");
+ALTER TABLE "transactions" ADD COLUMN IF NOT EXISTS "season_id" varchar(36);
+CREATE INDEX IF NOT EXISTS "idx_transactions_season_id" ON "transactions" ("season_id");
@@ -0,0 +1,2 @@
+-- Drops the budget lines table
+DROP TABLE IF EXISTS "budget_lines";
@@ -0,0 +1,20 @@
+-- Budget lines plan a season's income and spending per transaction category,
+-- for comparison with what was recorded.
+
+CREATE TABLE IF NOT EXISTS "budget_lines" (
+    "id" bigserial,
+    "budget_line_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "season_id" varchar(36) NOT NULL,
+    "type" text NOT NULL DEFAULT 'Expense',
+    "category" text NOT NULL,
+    "planned_amount" decimal NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","budget_line_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_budget_lines_deleted_at" ON "budget_lines" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_budget_lines_season_id" ON "budget_lines" ("season_id");
+CREATE INDEX IF NOT EXISTS "idx_budget_lines_farm_id" ON "budget_lines" ("farm_id");
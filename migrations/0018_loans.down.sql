@@ -0,0 +1,4 @@
+-- Drops the loan tables
+DROP TABLE IF EXISTS "loan_repayments";
+DROP TABLE IF EXISTS "loan_installments";
+DROP TABLE IF EXISTS "loans";
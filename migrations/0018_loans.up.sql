@@ -0,0 +1,55 @@
+-- Loans with their repayment schedules and repayments
+
+CREATE TABLE IF NOT EXISTS "loans" (
+    "id" bigserial,
+    "loan_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "lender" text NOT NULL,
+    "principal" decimal NOT NULL,
+    "interest_rate" decimal NOT NULL,
+    "interest_method" text NOT NULL DEFAULT 'Flat',
+    "disbursement_date" timestamptz NOT NULL,
+    "term_months" bigint NOT NULL,
+    "frequency" text NOT NULL DEFAULT 'Monthly',
+    "status" text NOT NULL DEFAULT 'Active',
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","loan_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_loans_deleted_at" ON "loans" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_loans_status" ON "loans" ("status");
+CREATE INDEX IF NOT EXISTS "idx_loans_farm_id" ON "loans" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "loan_installments" (
+    "id" bigserial,
+    "loan_installment_id" varchar(36) DEFAULT gen_random_uuid(),
+    "loan_id" varchar(36) NOT NULL,
+    "number" bigint NOT NULL,
+    "due_date" timestamptz NOT NULL,
+    "principal" decimal NOT NULL,
+    "interest" decimal NOT NULL,
+    "amount" decimal NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","loan_installment_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_loan_installments_loan_id" ON "loan_installments" ("loan_id");
+
+CREATE TABLE IF NOT EXISTS "loan_repayments" (
+    "id" bigserial,
+    "loan_repayment_id" varchar(36) DEFAULT gen_random_uuid(),
+    "loan_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "date" timestamptz NOT NULL,
+    "amount" decimal NOT NULL,
+    "interest" decimal NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","loan_repayment_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_loan_repayments_deleted_at" ON "loan_repayments" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_loan_repayments_farm_id" ON "loan_repayments" ("farm_id");
+CREATE INDEX IF NOT EXISTS "idx_loan_repayments_loan_id" ON "loan_repayments" ("loan_id");
@@ -0,0 +1,5 @@
+-- Drops the asset depreciation terms
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "depreciation_rate";
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "salvage_value";
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "useful_life_years";
+ALTER TABLE "assets" DROP COLUMN IF EXISTS "depreciation_method";
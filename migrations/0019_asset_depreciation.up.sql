@@ -0,0 +1,6 @@
+-- Depreciation terms for equipment and structures in the asset register
+
+ALTER TABLE "assets" ADD COLUMN IF NOT EXISTS "depreciation_method" text;
+ALTER TABLE "assets" ADD COLUMN IF NOT EXISTS "useful_life_years" bigint;
+ALTER TABLE "assets" ADD COLUMN IF NOT EXISTS "salvage_value" decimal;
+ALTER TABLE "assets" ADD COLUMN IF NOT EXISTS "depreciation_rate" decimal;
@@ -0,0 +1,6 @@
+-- Drops exchange rates and the currency columns
+DROP TABLE IF EXISTS "exchange_rates";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "exchange_rate";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "original_amount";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "currency";
+ALTER TABLE "farms" DROP COLUMN IF EXISTS "currency";
@@ -0,0 +1,23 @@
+-- Farm currencies, the currency transactions were entered in and the
+-- exchange rates they are converted to the farm currency at
+
+ALTER TABLE "farms" ADD COLUMN IF NOT EXISTS "currency" varchar(3) NOT NULL DEFAULT 'UGX';
+ALTER TABLE "transactions" ADD COLUMN IF NOT EXISTS "currency" varchar(3);
+ALTER TABLE "transactions" ADD COLUMN IF NOT EXISTS "original_amount" decimal;
+ALTER TABLE "transactions" ADD COLUMN IF NOT EXISTS "exchange_rate" decimal;
+
+CREATE TABLE IF NOT EXISTS "exchange_rates" (
+    "id" bigserial,
+    "exchange_rate_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "currency" varchar(3) NOT NULL,
+    "rate" decimal NOT NULL,
+    "date" timestamptz NOT NULL,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","exchange_rate_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_exchange_rates_deleted_at" ON "exchange_rates" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_exchange_rates_farm_id" ON "exchange_rates" ("farm_id");
@@ -0,0 +1,3 @@
+-- Drops tags and taggings
+DROP TABLE IF EXISTS "taggings";
+DROP TABLE IF EXISTS "tags";
@@ -0,0 +1,28 @@
+-- Adds tags and the taggings that put them on crops, livestock and documents
+
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" bigserial,
+    "tag_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "color" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","tag_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_tags_deleted_at" ON "tags" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_tags_farm_id" ON "tags" ("farm_id");
+
+CREATE TABLE IF NOT EXISTS "taggings" (
+    "id" bigserial,
+    "tagging_id" varchar(36) DEFAULT gen_random_uuid(),
+    "tag_id" varchar(36) NOT NULL,
+    "farm_id" varchar(36) NOT NULL,
+    "record_type" text NOT NULL,
+    "record_id" varchar(36) NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id","tagging_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_taggings_farm_id" ON "taggings" ("farm_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tagging_record" ON "taggings" ("tag_id","record_type","record_id");
@@ -0,0 +1,2 @@
+-- Drops saved views
+DROP TABLE IF EXISTS "saved_views";
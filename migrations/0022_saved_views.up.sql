@@ -0,0 +1,18 @@
+-- Adds saved views: named list filters users keep for a farm
+
+CREATE TABLE IF NOT EXISTS "saved_views" (
+    "id" bigserial,
+    "saved_view_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "user_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "entity" text NOT NULL,
+    "params" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","saved_view_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_saved_views_deleted_at" ON "saved_views" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_saved_views_user_id" ON "saved_views" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_saved_views_farm_id" ON "saved_views" ("farm_id");
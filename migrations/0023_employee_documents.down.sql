@@ -0,0 +1,3 @@
+-- Drops the employee link from documents
+DROP INDEX IF EXISTS "idx_documents_employee_id";
+ALTER TABLE "documents" DROP COLUMN IF EXISTS "employee_id";
@@ -0,0 +1,4 @@
+-- Links documents to the employees they are held for
+
+ALTER TABLE "documents" ADD COLUMN IF NOT EXISTS "employee_id" varchar(36);
+CREATE INDEX IF NOT EXISTS "idx_documents_employee_id" ON "documents" ("employee_id");
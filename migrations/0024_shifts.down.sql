@@ -0,0 +1,2 @@
+-- Drops the shifts table
+DROP TABLE IF EXISTS "shifts";
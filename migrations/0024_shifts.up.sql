@@ -0,0 +1,23 @@
+-- Creates the shifts table for employee rosters
+
+CREATE TABLE IF NOT EXISTS "shifts" (
+    "id" bigserial,
+    "shift_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "employee_id" varchar(36) NOT NULL,
+    "field_id" varchar(36),
+    "starts_at" timestamptz NOT NULL,
+    "ends_at" timestamptz NOT NULL,
+    "role" text NOT NULL,
+    "location" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","shift_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_shifts_deleted_at" ON "shifts" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_shifts_starts_at" ON "shifts" ("starts_at");
+CREATE INDEX IF NOT EXISTS "idx_shifts_field_id" ON "shifts" ("field_id");
+CREATE INDEX IF NOT EXISTS "idx_shifts_employee_id" ON "shifts" ("employee_id");
+CREATE INDEX IF NOT EXISTS "idx_shifts_farm_id" ON "shifts" ("farm_id");
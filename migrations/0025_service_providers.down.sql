@@ -0,0 +1,6 @@
+-- Drops the service provider directory and its links
+DROP INDEX IF EXISTS "idx_crop_incidents_service_provider_id";
+ALTER TABLE "crop_incidents" DROP COLUMN IF EXISTS "service_provider_id";
+DROP INDEX IF EXISTS "idx_maintenance_records_service_provider_id";
+ALTER TABLE "maintenance_records" DROP COLUMN IF EXISTS "service_provider_id";
+DROP TABLE IF EXISTS "service_providers";
@@ -0,0 +1,28 @@
+-- Creates the service provider directory and links maintenance records and
+-- crop incidents to the provider who did the work
+
+CREATE TABLE IF NOT EXISTS "service_providers" (
+    "id" bigserial,
+    "service_provider_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "name" text NOT NULL,
+    "category" text NOT NULL,
+    "contact_name" text,
+    "phone_number" text,
+    "email" text,
+    "address" text,
+    "notes" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","service_provider_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_service_providers_deleted_at" ON "service_providers" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_service_providers_category" ON "service_providers" ("category");
+CREATE INDEX IF NOT EXISTS "idx_service_providers_farm_id" ON "service_providers" ("farm_id");
+
+ALTER TABLE "maintenance_records" ADD COLUMN IF NOT EXISTS "service_provider_id" varchar(36);
+CREATE INDEX IF NOT EXISTS "idx_maintenance_records_service_provider_id" ON "maintenance_records" ("service_provider_id");
+
+ALTER TABLE "crop_incidents" ADD COLUMN IF NOT EXISTS "service_provider_id" varchar(36);
+CREATE INDEX IF NOT EXISTS "idx_crop_incidents_service_provider_id" ON "crop_incidents" ("service_provider_id");
@@ -0,0 +1,2 @@
+-- Drops the notes table
+DROP TABLE IF EXISTS "notes";
@@ -0,0 +1,19 @@
+-- Creates the notes table for dated observations on farm records
+
+CREATE TABLE IF NOT EXISTS "notes" (
+    "id" bigserial,
+    "note_id" varchar(36) DEFAULT gen_random_uuid(),
+    "farm_id" varchar(36) NOT NULL,
+    "author_id" varchar(36) NOT NULL,
+    "record_type" text NOT NULL,
+    "record_id" varchar(36) NOT NULL,
+    "body" text NOT NULL,
+    "noted_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id","note_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notes_deleted_at" ON "notes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_note_record" ON "notes" ("record_type","record_id");
+CREATE INDEX IF NOT EXISTS "idx_notes_farm_id" ON "notes" ("farm_id");
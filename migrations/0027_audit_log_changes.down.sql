@@ -0,0 +1,2 @@
+-- Drops the fields stored with audit entries
+ALTER TABLE "audit_logs" DROP COLUMN IF EXISTS "changes";
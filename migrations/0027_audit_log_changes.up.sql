@@ -0,0 +1,3 @@
+-- Stores the fields each audited create or update set, for record histories
+
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "changes" text;
@@ -0,0 +1,5 @@
+-- Drops webhook digests and secret rotation
+ALTER TABLE "webhooks" DROP COLUMN IF EXISTS "previous_secret_expires_at";
+ALTER TABLE "webhooks" DROP COLUMN IF EXISTS "previous_secret";
+ALTER TABLE "webhooks" DROP COLUMN IF EXISTS "digest_minutes";
+ALTER TABLE "webhooks" DROP COLUMN IF EXISTS "digest_size";
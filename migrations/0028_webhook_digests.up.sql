@@ -0,0 +1,7 @@
+-- Batches a webhook's events into digests, and keeps a rotated-out signing
+-- secret signing deliveries for a while
+
+ALTER TABLE "webhooks" ADD COLUMN IF NOT EXISTS "digest_size" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "webhooks" ADD COLUMN IF NOT EXISTS "digest_minutes" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "webhooks" ADD COLUMN IF NOT EXISTS "previous_secret" text;
+ALTER TABLE "webhooks" ADD COLUMN IF NOT EXISTS "previous_secret_expires_at" timestamptz;
@@ -0,0 +1,249 @@
+// Package migrations holds the versioned database schema changes and applies
+// them with golang-migrate. Each change is a pair of SQL files,
+// NNNN_name.up.sql and NNNN_name.down.sql, embedded in the binary. The
+// current version is recorded in the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"gorm.io/gorm"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// versionTable is where golang-migrate records the current version
+const versionTable = "schema_migrations"
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+}
+
+// State is a migration and whether it has been applied. A dirty migration
+// failed part way and has to be fixed by hand, then forced.
+type State struct {
+	Migration
+	Applied bool
+	Dirty   bool
+}
+
+// All returns the embedded migrations, oldest first
+func All() ([]Migration, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	var migrations []Migration
+	version, err := source.First()
+	for err == nil {
+		up, name, readErr := source.ReadUp(version)
+		if readErr != nil {
+			return nil, fmt.Errorf("migration %d: %w", version, readErr)
+		}
+		up.Close()
+		migrations = append(migrations, Migration{Version: int(version), Name: name})
+		version, err = source.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return migrations, nil
+}
+
+// Migrator applies and reverts the embedded migrations on a database. It
+// holds one connection from the pool until it is closed.
+type Migrator struct {
+	migrate    *migrate.Migrate
+	migrations []Migration
+}
+
+// New creates a Migrator for db, creating the schema_migrations table if
+// needed
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+
+	// The driver is given its own connection rather than the pool, as
+	// closing a driver made from a pool closes the pool too
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("connecting for migrations: %w", err)
+	}
+	driver, err := postgres.WithConnection(context.Background(), conn, &postgres.Config{MigrationsTable: versionTable})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("creating %s: %w", versionTable, err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	return &Migrator{migrate: m, migrations: migrations}, nil
+}
+
+// Close returns the Migrator's connection to the pool
+func (m *Migrator) Close() error {
+	_, err := m.migrate.Close()
+	return err
+}
+
+// Status returns every migration with whether it has been applied, oldest
+// first
+func (m *Migrator) Status() ([]State, error) {
+	version, dirty, err := m.version()
+	if err != nil {
+		return nil, err
+	}
+	states := make([]State, len(m.migrations))
+	for i, migration := range m.migrations {
+		states[i] = State{
+			Migration: migration,
+			Applied:   migration.Version < version || (migration.Version == version && !dirty),
+			Dirty:     migration.Version == version && dirty,
+		}
+	}
+	return states, nil
+}
+
+// Pending returns the migrations not yet applied to db, oldest first,
+// counting a dirty one as not applied. Unlike New it only reads, so it suits
+// frequent checks such as readiness probes.
+func Pending(db *gorm.DB) ([]Migration, error) {
+	migrations, err := All()
+	if err != nil {
+		return nil, fmt.Errorf("loading migrations: %w", err)
+	}
+	if !db.Migrator().HasTable(versionTable) {
+		return migrations, nil
+	}
+
+	var current struct {
+		Version int
+		Dirty   bool
+	}
+	if err := db.Table(versionTable).Select("version, dirty").Limit(1).Scan(&current).Error; err != nil {
+		return nil, fmt.Errorf("getting schema version: %w", err)
+	}
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > current.Version || (migration.Version == current.Version && current.Dirty) {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies every pending migration, oldest first, and returns those it
+// applied. It stops at the first failure, leaving that migration dirty.
+func (m *Migrator) Up() ([]Migration, error) {
+	before, _, err := m.version()
+	if err != nil {
+		return nil, err
+	}
+	err = m.migrate.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		err = nil
+	}
+	after, dirty, verr := m.version()
+	if verr != nil {
+		return nil, errors.Join(err, verr)
+	}
+	applied := m.between(before, after)
+	if dirty && len(applied) > 0 {
+		applied = applied[:len(applied)-1]
+	}
+	if err != nil {
+		err = fmt.Errorf("applying migrations: %w", err)
+	}
+	return applied, err
+}
+
+// Down reverts the given number of most recently applied migrations, newest
+// first, and returns those it reverted
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be at least 1")
+	}
+	before, _, err := m.version()
+	if err != nil {
+		return nil, err
+	}
+	if before == 0 {
+		return nil, nil
+	}
+
+	// Asking for more steps than were applied reverts them all
+	var short migrate.ErrShortLimit
+	err = m.migrate.Steps(-steps)
+	if errors.Is(err, migrate.ErrNoChange) || errors.As(err, &short) {
+		err = nil
+	}
+	after, _, verr := m.version()
+	if verr != nil {
+		return nil, errors.Join(err, verr)
+	}
+	if err != nil {
+		err = fmt.Errorf("reverting migrations: %w", err)
+	}
+	reverted := m.between(after, before)
+	slices.Reverse(reverted)
+	return reverted, err
+}
+
+// Force records version as the current one without running any migration,
+// clearing the dirty flag once a failed migration has been fixed by hand
+func (m *Migrator) Force(version int) error {
+	if err := m.migrate.Force(version); err != nil {
+		return fmt.Errorf("forcing version %d: %w", version, err)
+	}
+	return nil
+}
+
+// version returns the current version, 0 when none has been applied
+func (m *Migrator) version() (int, bool, error) {
+	version, dirty, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("getting schema version: %w", err)
+	}
+	return int(version), dirty, nil
+}
+
+// between returns the migrations after from up to and including to, oldest
+// first
+func (m *Migrator) between(from, to int) []Migration {
+	var found []Migration
+	for _, migration := range m.migrations {
+		if migration.Version > from && migration.Version <= to {
+			found = append(found, migration)
+		}
+	}
+	return found
+}
@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultCooldown is how long a failed provider is skipped before it is tried again
+const DefaultCooldown = 5 * time.Minute
+
+// ProviderStatus describes a provider in a failover group
+type ProviderStatus struct {
+	Channel     Channel    `json:"channel"`
+	Name        string     `json:"name"`
+	Healthy     bool       `json:"healthy"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastFailure *time.Time `json:"lastFailure,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	DownUntil   *time.Time `json:"downUntil,omitempty"`
+}
+
+// provider is a sender plus its failover state
+type provider struct {
+	sender      Sender
+	lastErr     error
+	lastFailure time.Time
+	lastSuccess time.Time
+	downUntil   time.Time
+}
+
+// Failover sends through a list of providers in priority order. A provider
+// that fails is skipped until its cool-down expires or a health check passes.
+// If every provider is down they are all tried anyway.
+type Failover struct {
+	Channel  Channel
+	Cooldown time.Duration
+
+	mu        sync.Mutex
+	providers []*provider
+	now       func() time.Time
+}
+
+// NewFailover creates an empty failover group for channel
+func NewFailover(channel Channel) *Failover {
+	return &Failover{Channel: channel, Cooldown: DefaultCooldown, now: time.Now}
+}
+
+// Add appends a provider with the lowest priority so far
+func (f *Failover) Add(sender Sender) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.providers = append(f.providers, &provider{sender: sender})
+}
+
+// Send delivers msg with the first available provider that succeeds. The
+// returned error joins the errors of every provider that was tried.
+func (f *Failover) Send(ctx context.Context, msg Message) error {
+	candidates := f.candidates()
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w for %s", ErrNoProviders, f.Channel)
+	}
+
+	var errs []error
+	for _, p := range candidates {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		err := p.sender.Send(ctx, msg)
+		f.record(p, err)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.sender.Name(), err))
+	}
+	return fmt.Errorf("notify: %s delivery failed: %w", f.Channel, errors.Join(errs...))
+}
+
+// candidates returns the providers in rotation, or all of them when none are
+func (f *Failover) candidates() []*provider {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	var up []*provider
+	for _, p := range f.providers {
+		if !now.Before(p.downUntil) {
+			up = append(up, p)
+		}
+	}
+	if len(up) == 0 {
+		up = append(up, f.providers...)
+	}
+	return up
+}
+
+// record updates a provider's state after a send or health check
+func (f *Failover) record(p *provider, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if err != nil {
+		p.lastErr = err
+		p.lastFailure = now
+		p.downUntil = now.Add(f.Cooldown)
+		return
+	}
+	p.lastErr = nil
+	p.lastSuccess = now
+	p.downUntil = time.Time{}
+}
+
+// CheckHealth runs the health check of each provider that has one. A passing
+// check puts a provider back in rotation before its cool-down ends.
+func (f *Failover) CheckHealth(ctx context.Context) {
+	f.mu.Lock()
+	providers := append([]*provider(nil), f.providers...)
+	f.mu.Unlock()
+
+	for _, p := range providers {
+		checker, ok := p.sender.(HealthChecker)
+		if !ok {
+			continue
+		}
+		f.record(p, checker.Check(ctx))
+	}
+}
+
+// Status reports the state of each provider in priority order
+func (f *Failover) Status() []ProviderStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	statuses := make([]ProviderStatus, 0, len(f.providers))
+	for _, p := range f.providers {
+		s := ProviderStatus{
+			Channel: f.Channel,
+			Name:    p.sender.Name(),
+			Healthy: !now.Before(p.downUntil),
+		}
+		if p.lastErr != nil {
+			s.LastError = p.lastErr.Error()
+		}
+		if !p.lastFailure.IsZero() {
+			t := p.lastFailure
+			s.LastFailure = &t
+		}
+		if !p.lastSuccess.IsZero() {
+			t := p.lastSuccess
+			s.LastSuccess = &t
+		}
+		if !s.Healthy {
+			t := p.downUntil
+			s.DownUntil = &t
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
@@ -0,0 +1,409 @@
+// Package pdf writes plain reports — a title, headings, paragraphs and tables
+// — as PDF documents. It only uses the standard Helvetica fonts every PDF
+// reader has built in, so no fonts are embedded and no layout engine is
+// needed. Text outside the Windows-1252 character set is shown as "?".
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 page size and margins in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+	// bodyWidth is the usable width between the margins
+	bodyWidth = pageWidth - 2*margin
+	// footerY is the baseline of the page number footer
+	footerY = 30.0
+)
+
+// Font sizes and line spacing
+const (
+	titleSize   = 18.0
+	headingSize = 13.0
+	textSize    = 10.0
+	tableSize   = 9.0
+	leading     = 1.4
+	cellPadding = 4.0
+)
+
+// font is one of the two standard fonts a document uses
+type font string
+
+const (
+	regular font = "F1"
+	bold    font = "F2"
+)
+
+// Document is a report being laid out. Content flows down the page and onto
+// new pages as needed; call Bytes or WriteTo once it is complete.
+type Document struct {
+	title   string
+	created time.Time
+	pages   []*bytes.Buffer
+	y       float64 // Baseline of the next line on the current page
+}
+
+// New starts a document whose first page shows title and subtitle
+func New(title, subtitle string) *Document {
+	d := &Document{title: title, created: time.Now()}
+	d.newPage()
+	d.line(bold, titleSize, margin, title)
+	if subtitle != "" {
+		d.line(regular, textSize, margin, subtitle)
+	}
+	d.y -= textSize
+	return d
+}
+
+// Heading starts a new section. A heading is never left alone at the foot of
+// a page.
+func (d *Document) Heading(text string) {
+	d.ensure(headingSize*leading + 3*textSize*leading)
+	d.y -= headingSize * 0.5
+	d.line(bold, headingSize, margin, text)
+	d.y -= headingSize * 0.25
+}
+
+// Text adds a paragraph, wrapped to the page width
+func (d *Document) Text(text string) {
+	for _, l := range wrap(text, regular, textSize, bodyWidth) {
+		d.line(regular, textSize, margin, l)
+	}
+	d.y -= textSize * 0.5
+}
+
+// KeyValues adds a two-column list of labels and their values
+func (d *Document) KeyValues(pairs [][2]string) {
+	labelWidth := 0.0
+	for _, p := range pairs {
+		labelWidth = max(labelWidth, width(p[0], bold, textSize))
+	}
+	labelWidth = min(labelWidth+2*cellPadding, bodyWidth/2)
+
+	for _, p := range pairs {
+		d.ensure(textSize * leading)
+		d.text(bold, textSize, margin, d.y, truncate(p[0], bold, textSize, labelWidth-cellPadding))
+		d.text(regular, textSize, margin+labelWidth, d.y, truncate(p[1], regular, textSize, bodyWidth-labelWidth))
+		d.y -= textSize * leading
+	}
+	d.y -= textSize * 0.5
+}
+
+// Table adds a table with a header row, repeated at the top of every page
+// the table runs onto. Columns are sized to their content and cells too
+// wide for their column are cut short; columns holding only numbers are
+// right aligned.
+func (d *Document) Table(headers []string, rows [][]string) {
+	widths := columnWidths(headers, rows)
+	numeric := numericColumns(len(headers), rows)
+	rowHeight := tableSize * leading
+
+	header := func() {
+		d.fill(0.9, margin, d.y-tableSize*0.35, bodyWidth, rowHeight)
+		d.row(bold, headers, widths, numeric)
+	}
+
+	d.ensure(rowHeight * 3)
+	header()
+	if len(rows) == 0 {
+		d.line(regular, tableSize, margin+cellPadding, "No records")
+	}
+	for _, cells := range rows {
+		if d.y-rowHeight < margin {
+			d.newPage()
+			header()
+		}
+		d.row(regular, cells, widths, numeric)
+	}
+	d.y -= textSize * 0.5
+}
+
+// Bytes returns the finished document
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	d.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// WriteTo writes the finished document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-5 are the catalog, page tree, fonts and document info;
+	// each page is then a page object followed by its content stream.
+	firstPage := 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title %s /Producer (Farm Manager 4U) /CreationDate (D:%s) >>",
+		literal(d.title), d.created.UTC().Format("20060102150405Z")))
+
+	for i, page := range d.pages {
+		content := bytes.NewBuffer(page.Bytes())
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(d.pages))
+		writeText(content, regular, 8, pageWidth-margin-width(footer, regular, 8), footerY, footer)
+		writeText(content, regular, 8, margin, footerY, d.title)
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		zw.Write(content.Bytes())
+		zw.Close()
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// newPage starts a page and moves to its top
+func (d *Document) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+// ensure starts a new page unless height points are left on this one
+func (d *Document) ensure(height float64) {
+	if d.y-height < margin {
+		d.newPage()
+	}
+}
+
+// line writes one line of text at x and moves down a line
+func (d *Document) line(f font, size, x float64, text string) {
+	d.ensure(size * leading)
+	d.text(f, size, x, d.y, text)
+	d.y -= size * leading
+}
+
+// row writes one table row and moves down a row
+func (d *Document) row(f font, cells []string, widths []float64, numeric []bool) {
+	x := margin
+	for i, w := range widths {
+		cell := ""
+		if i < len(cells) {
+			cell = truncate(cells[i], f, tableSize, w-2*cellPadding)
+		}
+		cx := x + cellPadding
+		if numeric[i] {
+			cx = x + w - cellPadding - width(cell, f, tableSize)
+		}
+		d.text(f, tableSize, cx, d.y, cell)
+		x += w
+	}
+	d.y -= tableSize * leading
+}
+
+// text writes text with its baseline at (x, y) on the current page
+func (d *Document) text(f font, size, x, y float64, text string) {
+	writeText(d.pages[len(d.pages)-1], f, size, x, y, text)
+}
+
+// fill draws a grey rectangle on the current page
+func (d *Document) fill(grey, x, y, w, h float64) {
+	fmt.Fprintf(d.pages[len(d.pages)-1], "%s g %s %s %s %s re f 0 g\n", num(grey), num(x), num(y), num(w), num(h))
+}
+
+// writeText writes a text object to a content stream
+func writeText(w io.Writer, f font, size, x, y float64, text string) {
+	fmt.Fprintf(w, "BT /%s %s Tf %s %s Td %s Tj ET\n", f, num(size), num(x), num(y), literal(text))
+}
+
+// columnWidths shares the page width between a table's columns in
+// proportion to the width of their content
+func columnWidths(headers []string, rows [][]string) []float64 {
+	widths := make([]float64, len(headers))
+	for i, h := range headers {
+		widths[i] = width(h, bold, tableSize)
+	}
+	for _, cells := range rows {
+		for i := range min(len(cells), len(widths)) {
+			widths[i] = max(widths[i], width(cells[i], regular, tableSize))
+		}
+	}
+
+	total := 0.0
+	for i := range widths {
+		widths[i] += 2 * cellPadding
+		total += widths[i]
+	}
+	for i := range widths {
+		widths[i] *= bodyWidth / total
+	}
+	return widths
+}
+
+// numericColumns reports which columns hold only numbers, such as amounts
+// and counts
+func numericColumns(n int, rows [][]string) []bool {
+	numeric := make([]bool, n)
+	for i := range numeric {
+		numeric[i] = len(rows) > 0
+		for _, cells := range rows {
+			if i >= len(cells) || cells[i] == "" {
+				continue
+			}
+			v := strings.TrimSuffix(strings.ReplaceAll(cells[i], ",", ""), "%")
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				numeric[i] = false
+				break
+			}
+		}
+	}
+	return numeric
+}
+
+// wrap breaks text into lines no wider than maxWidth, keeping the line
+// breaks already in it
+func wrap(text string, f font, size, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if line != "" && width(candidate, f, size) > maxWidth {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, truncate(line, f, size, maxWidth))
+	}
+	return lines
+}
+
+// truncate cuts text short with an ellipsis so it is no wider than maxWidth
+func truncate(text string, f font, size, maxWidth float64) string {
+	if width(text, f, size) <= maxWidth {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if s := strings.TrimRight(string(runes), " ") + "..."; width(s, f, size) <= maxWidth {
+			return s
+		}
+	}
+	return ""
+}
+
+// width is how wide text is set in font f at size, in points
+func width(text string, f font, size float64) float64 {
+	units := 0
+	for _, r := range text {
+		if r >= ' ' && r <= '~' {
+			units += helveticaWidths[r-' ']
+		} else {
+			units += 556
+		}
+	}
+	w := float64(units) * size / 1000
+	if f == bold {
+		// Helvetica-Bold is a little wider; erring wide keeps text in its column
+		w *= 1.08
+	}
+	return w
+}
+
+// literal encodes text as a PDF string in the fonts' WinAnsi encoding
+func literal(text string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range text {
+		c, ok := winAnsi(r)
+		if !ok {
+			c = '?'
+		}
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// winAnsi maps a rune to its Windows-1252 byte
+func winAnsi(r rune) (byte, bool) {
+	switch {
+	case r == '\t':
+		return ' ', true
+	case r >= ' ' && r <= '~', r >= 0xA0 && r <= 0xFF:
+		return byte(r), true
+	}
+	switch r {
+	case '€':
+		return 0x80, true
+	case '‘':
+		return 0x91, true
+	case '’':
+		return 0x92, true
+	case '“':
+		return 0x93, true
+	case '”':
+		return 0x94, true
+	case '•':
+		return 0x95, true
+	case '–':
+		return 0x96, true
+	case '—':
+		return 0x97, true
+	}
+	return 0, false
+}
+
+// num formats a coordinate compactly
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// helveticaWidths are the advance widths of Helvetica's printable ASCII
+// characters, from space to tilde, in thousandths of the font size
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
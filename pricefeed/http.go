@@ -0,0 +1,90 @@
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// HTTPFeed reads prices from a URL serving a JSON document of the form
+//
+//	{"prices": [{"commodity": "maize", "region": "Kampala", "unit": "kg",
+//	             "price": 1100, "currency": "UGX", "date": "2026-10-16"}]}
+//
+// Credentials in the URL's user info are sent as a bearer token (the
+// password) and stripped from the request URL.
+type HTTPFeed struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+// NewHTTPFeed creates a feed reading from u
+func NewHTTPFeed(u *url.URL) *HTTPFeed {
+	feed := &HTTPFeed{client: &http.Client{Timeout: 30 * time.Second}}
+	if u.User != nil {
+		feed.token, _ = u.User.Password()
+		stripped := *u
+		stripped.User = nil
+		u = &stripped
+	}
+	feed.url = u.String()
+	return feed
+}
+
+// Name implements Feed
+func (f *HTTPFeed) Name() string {
+	u, err := url.Parse(f.url)
+	if err != nil {
+		return "http"
+	}
+	return u.Host
+}
+
+// Fetch implements Feed
+func (f *HTTPFeed) Fetch(ctx context.Context) ([]Price, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("pricefeed: %s: %w", f.Name(), err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if f.token != "" {
+		req.Header.Set("Authorization", "Bearer "+f.token)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("pricefeed: %s: %w", f.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		return nil, fmt.Errorf("pricefeed: %s returned %s", f.Name(), resp.Status)
+	}
+
+	var body struct {
+		Prices []struct {
+			Price
+			Date string `json:"date"`
+		} `json:"prices"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 8<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("pricefeed: %s: decoding prices: %w", f.Name(), err)
+	}
+
+	prices := make([]Price, 0, len(body.Prices))
+	for _, p := range body.Prices {
+		date, err := time.Parse("2006-01-02", p.Date)
+		if err != nil {
+			return nil, fmt.Errorf("pricefeed: %s: bad date %q", f.Name(), p.Date)
+		}
+		p.Price.Date = date
+		prices = append(prices, p.Price)
+	}
+	return prices, nil
+}
@@ -0,0 +1,67 @@
+// Package pricefeed fetches daily commodity prices from a price feed behind a
+// single interface, so the provider is a deployment choice rather than a code
+// change. Prices can also be uploaded by an admin, so a feed is optional.
+package pricefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrUnavailable is returned when no price feed is configured
+var ErrUnavailable = errors.New("pricefeed: no price feed configured")
+
+// Price is one commodity price reported by a feed
+type Price struct {
+	Commodity string    `json:"commodity"`
+	Region    string    `json:"region"`
+	Unit      string    `json:"unit"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
+	Date      time.Time `json:"date"`
+}
+
+// Feed is implemented by every price provider
+type Feed interface {
+	// Fetch returns the provider's latest prices
+	Fetch(ctx context.Context) ([]Price, error)
+	// Name identifies the provider in logs and as the source of its prices
+	Name() string
+}
+
+// Open returns the feed described by rawURL:
+//
+//	none://                      no feed; prices are uploaded by an admin (the default)
+//	https://host/path            a JSON document of prices (see HTTPFeed)
+func Open(rawURL string) (Feed, error) {
+	if rawURL == "" {
+		return None{}, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("pricefeed: invalid feed URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "none":
+		return None{}, nil
+	case "http", "https":
+		return NewHTTPFeed(u), nil
+	default:
+		return nil, fmt.Errorf("pricefeed: unsupported feed scheme %q", u.Scheme)
+	}
+}
+
+// None is the feed used when prices are only uploaded by an admin
+type None struct{}
+
+// Fetch implements Feed; it always returns ErrUnavailable
+func (None) Fetch(context.Context) ([]Price, error) {
+	return nil, ErrUnavailable
+}
+
+// Name implements Feed
+func (None) Name() string { return "none" }
@@ -0,0 +1,1177 @@
+// FarmData exposes the core farm records to internal services, such as
+// analytics, over gRPC. It is read-only and served by cmd/grpc; farmers and
+// partners use the HTTP API instead.
+//
+// Regenerate the Go code after changing this file, from the proto directory:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	  farmdata/v1/farmdata.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: farmdata/v1/farmdata.proto
+
+package farmdatav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListFarmsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFarmsRequest) Reset() {
+	*x = ListFarmsRequest{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFarmsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFarmsRequest) ProtoMessage() {}
+
+func (x *ListFarmsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFarmsRequest.ProtoReflect.Descriptor instead.
+func (*ListFarmsRequest) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListFarmsRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListFarmsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Farms         []*Farm                `protobuf:"bytes,1,rep,name=farms,proto3" json:"farms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFarmsResponse) Reset() {
+	*x = ListFarmsResponse{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFarmsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFarmsResponse) ProtoMessage() {}
+
+func (x *ListFarmsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFarmsResponse.ProtoReflect.Descriptor instead.
+func (*ListFarmsResponse) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListFarmsResponse) GetFarms() []*Farm {
+	if x != nil {
+		return x.Farms
+	}
+	return nil
+}
+
+type GetFarmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FarmId        string                 `protobuf:"bytes,1,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFarmRequest) Reset() {
+	*x = GetFarmRequest{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFarmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFarmRequest) ProtoMessage() {}
+
+func (x *GetFarmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFarmRequest.ProtoReflect.Descriptor instead.
+func (*GetFarmRequest) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetFarmRequest) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+type FarmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FarmId        string                 `protobuf:"bytes,1,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FarmRequest) Reset() {
+	*x = FarmRequest{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FarmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FarmRequest) ProtoMessage() {}
+
+func (x *FarmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FarmRequest.ProtoReflect.Descriptor instead.
+func (*FarmRequest) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FarmRequest) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+type ListFieldsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Fields        []*Field               `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFieldsResponse) Reset() {
+	*x = ListFieldsResponse{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFieldsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFieldsResponse) ProtoMessage() {}
+
+func (x *ListFieldsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFieldsResponse.ProtoReflect.Descriptor instead.
+func (*ListFieldsResponse) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListFieldsResponse) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+type ListCropsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Crops         []*Crop                `protobuf:"bytes,1,rep,name=crops,proto3" json:"crops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCropsResponse) Reset() {
+	*x = ListCropsResponse{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCropsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCropsResponse) ProtoMessage() {}
+
+func (x *ListCropsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCropsResponse.ProtoReflect.Descriptor instead.
+func (*ListCropsResponse) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListCropsResponse) GetCrops() []*Crop {
+	if x != nil {
+		return x.Crops
+	}
+	return nil
+}
+
+type ListLivestockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Livestock     []*Livestock           `protobuf:"bytes,1,rep,name=livestock,proto3" json:"livestock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLivestockResponse) Reset() {
+	*x = ListLivestockResponse{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLivestockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLivestockResponse) ProtoMessage() {}
+
+func (x *ListLivestockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLivestockResponse.ProtoReflect.Descriptor instead.
+func (*ListLivestockResponse) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListLivestockResponse) GetLivestock() []*Livestock {
+	if x != nil {
+		return x.Livestock
+	}
+	return nil
+}
+
+type ListTransactionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FarmId        string                 `protobuf:"bytes,1,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsRequest) Reset() {
+	*x = ListTransactionsRequest{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsRequest) ProtoMessage() {}
+
+func (x *ListTransactionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsRequest.ProtoReflect.Descriptor instead.
+func (*ListTransactionsRequest) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTransactionsRequest) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+func (x *ListTransactionsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListTransactionsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type ListTransactionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transactions  []*Transaction         `protobuf:"bytes,1,rep,name=transactions,proto3" json:"transactions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTransactionsResponse) Reset() {
+	*x = ListTransactionsResponse{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTransactionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTransactionsResponse) ProtoMessage() {}
+
+func (x *ListTransactionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTransactionsResponse.ProtoReflect.Descriptor instead.
+func (*ListTransactionsResponse) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListTransactionsResponse) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type Farm struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FarmId        string                 `protobuf:"bytes,1,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Location      string                 `protobuf:"bytes,5,opt,name=location,proto3" json:"location,omitempty"`
+	Size          float64                `protobuf:"fixed64,6,opt,name=size,proto3" json:"size,omitempty"`                       // In acres/hectares
+	FarmType      string                 `protobuf:"bytes,7,opt,name=farm_type,json=farmType,proto3" json:"farm_type,omitempty"` // Crop, Livestock, Mixed
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`                     // Active, Inactive, Suspended
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Farm) Reset() {
+	*x = Farm{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Farm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Farm) ProtoMessage() {}
+
+func (x *Farm) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Farm.ProtoReflect.Descriptor instead.
+func (*Farm) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Farm) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+func (x *Farm) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Farm) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Farm) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Farm) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Farm) GetSize() float64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Farm) GetFarmType() string {
+	if x != nil {
+		return x.FarmType
+	}
+	return ""
+}
+
+func (x *Farm) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Farm) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Farm) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Field struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FieldId       string                 `protobuf:"bytes,1,opt,name=field_id,json=fieldId,proto3" json:"field_id,omitempty"`
+	FarmId        string                 `protobuf:"bytes,2,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Area          float64                `protobuf:"fixed64,4,opt,name=area,proto3" json:"area,omitempty"` // Same unit as the farm size
+	SoilType      string                 `protobuf:"bytes,5,opt,name=soil_type,json=soilType,proto3" json:"soil_type,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Field) GetFieldId() string {
+	if x != nil {
+		return x.FieldId
+	}
+	return ""
+}
+
+func (x *Field) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+func (x *Field) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Field) GetArea() float64 {
+	if x != nil {
+		return x.Area
+	}
+	return 0
+}
+
+func (x *Field) GetSoilType() string {
+	if x != nil {
+		return x.SoilType
+	}
+	return ""
+}
+
+func (x *Field) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Field) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Crop struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CropId        string                 `protobuf:"bytes,1,opt,name=crop_id,json=cropId,proto3" json:"crop_id,omitempty"`
+	FarmId        string                 `protobuf:"bytes,2,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	FieldId       string                 `protobuf:"bytes,3,opt,name=field_id,json=fieldId,proto3" json:"field_id,omitempty"` // Empty if not planted on a field
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	PlantingDate  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=planting_date,json=plantingDate,proto3" json:"planting_date,omitempty"`
+	HarvestDate   *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=harvest_date,json=harvestDate,proto3" json:"harvest_date,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,7,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Status        string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"` // Growing, Harvested, Failed
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Crop) Reset() {
+	*x = Crop{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Crop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Crop) ProtoMessage() {}
+
+func (x *Crop) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Crop.ProtoReflect.Descriptor instead.
+func (*Crop) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Crop) GetCropId() string {
+	if x != nil {
+		return x.CropId
+	}
+	return ""
+}
+
+func (x *Crop) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+func (x *Crop) GetFieldId() string {
+	if x != nil {
+		return x.FieldId
+	}
+	return ""
+}
+
+func (x *Crop) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Crop) GetPlantingDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PlantingDate
+	}
+	return nil
+}
+
+func (x *Crop) GetHarvestDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.HarvestDate
+	}
+	return nil
+}
+
+func (x *Crop) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Crop) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Crop) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Crop) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Livestock struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LivestockId     string                 `protobuf:"bytes,1,opt,name=livestock_id,json=livestockId,proto3" json:"livestock_id,omitempty"`
+	FarmId          string                 `protobuf:"bytes,2,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	Type            string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // Cattle, Poultry, Sheep, Goat, etc.
+	Count           int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	AcquisitionDate *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=acquisition_date,json=acquisitionDate,proto3" json:"acquisition_date,omitempty"`
+	HealthStatus    string                 `protobuf:"bytes,6,opt,name=health_status,json=healthStatus,proto3" json:"health_status,omitempty"` // Healthy, Sick, Under Treatment, Deceased
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Livestock) Reset() {
+	*x = Livestock{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Livestock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Livestock) ProtoMessage() {}
+
+func (x *Livestock) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Livestock.ProtoReflect.Descriptor instead.
+func (*Livestock) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Livestock) GetLivestockId() string {
+	if x != nil {
+		return x.LivestockId
+	}
+	return ""
+}
+
+func (x *Livestock) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+func (x *Livestock) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Livestock) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Livestock) GetAcquisitionDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AcquisitionDate
+	}
+	return nil
+}
+
+func (x *Livestock) GetHealthStatus() string {
+	if x != nil {
+		return x.HealthStatus
+	}
+	return ""
+}
+
+func (x *Livestock) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Livestock) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Transaction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	FarmId        string                 `protobuf:"bytes,2,opt,name=farm_id,json=farmId,proto3" json:"farm_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // Income, Expense
+	Category      string                 `protobuf:"bytes,4,opt,name=category,proto3" json:"category,omitempty"`
+	Amount        float64                `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"` // Always positive; type gives the direction
+	Date          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=date,proto3" json:"date,omitempty"`
+	Description   string                 `protobuf:"bytes,7,opt,name=description,proto3" json:"description,omitempty"`
+	Reference     string                 `protobuf:"bytes,8,opt,name=reference,proto3" json:"reference,omitempty"` // Source record for system-generated entries
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_farmdata_v1_farmdata_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_farmdata_v1_farmdata_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Transaction) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *Transaction) GetFarmId() string {
+	if x != nil {
+		return x.FarmId
+	}
+	return ""
+}
+
+func (x *Transaction) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Transaction) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Transaction) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Transaction) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Transaction) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Transaction) GetReference() string {
+	if x != nil {
+		return x.Reference
+	}
+	return ""
+}
+
+func (x *Transaction) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Transaction) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_farmdata_v1_farmdata_proto protoreflect.FileDescriptor
+
+const file_farmdata_v1_farmdata_proto_rawDesc = "" +
+	"\n" +
+	"\x1afarmdata/v1/farmdata.proto\x12\x12farm4u.farmdata.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"+\n" +
+	"\x10ListFarmsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"C\n" +
+	"\x11ListFarmsResponse\x12.\n" +
+	"\x05farms\x18\x01 \x03(\v2\x18.farm4u.farmdata.v1.FarmR\x05farms\")\n" +
+	"\x0eGetFarmRequest\x12\x17\n" +
+	"\afarm_id\x18\x01 \x01(\tR\x06farmId\"&\n" +
+	"\vFarmRequest\x12\x17\n" +
+	"\afarm_id\x18\x01 \x01(\tR\x06farmId\"G\n" +
+	"\x12ListFieldsResponse\x121\n" +
+	"\x06fields\x18\x01 \x03(\v2\x19.farm4u.farmdata.v1.FieldR\x06fields\"C\n" +
+	"\x11ListCropsResponse\x12.\n" +
+	"\x05crops\x18\x01 \x03(\v2\x18.farm4u.farmdata.v1.CropR\x05crops\"T\n" +
+	"\x15ListLivestockResponse\x12;\n" +
+	"\tlivestock\x18\x01 \x03(\v2\x1d.farm4u.farmdata.v1.LivestockR\tlivestock\"\x8e\x01\n" +
+	"\x17ListTransactionsRequest\x12\x17\n" +
+	"\afarm_id\x18\x01 \x01(\tR\x06farmId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"_\n" +
+	"\x18ListTransactionsResponse\x12C\n" +
+	"\ftransactions\x18\x01 \x03(\v2\x1f.farm4u.farmdata.v1.TransactionR\ftransactions\"\xc9\x02\n" +
+	"\x04Farm\x12\x17\n" +
+	"\afarm_id\x18\x01 \x01(\tR\x06farmId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\blocation\x18\x05 \x01(\tR\blocation\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x01R\x04size\x12\x1b\n" +
+	"\tfarm_type\x18\a \x01(\tR\bfarmType\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xf6\x01\n" +
+	"\x05Field\x12\x19\n" +
+	"\bfield_id\x18\x01 \x01(\tR\afieldId\x12\x17\n" +
+	"\afarm_id\x18\x02 \x01(\tR\x06farmId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x12\n" +
+	"\x04area\x18\x04 \x01(\x01R\x04area\x12\x1b\n" +
+	"\tsoil_type\x18\x05 \x01(\tR\bsoilType\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x91\x03\n" +
+	"\x04Crop\x12\x17\n" +
+	"\acrop_id\x18\x01 \x01(\tR\x06cropId\x12\x17\n" +
+	"\afarm_id\x18\x02 \x01(\tR\x06farmId\x12\x19\n" +
+	"\bfield_id\x18\x03 \x01(\tR\afieldId\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12?\n" +
+	"\rplanting_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fplantingDate\x12=\n" +
+	"\fharvest_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\vharvestDate\x12\x1a\n" +
+	"\bquantity\x18\a \x01(\x01R\bquantity\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xd3\x02\n" +
+	"\tLivestock\x12!\n" +
+	"\flivestock_id\x18\x01 \x01(\tR\vlivestockId\x12\x17\n" +
+	"\afarm_id\x18\x02 \x01(\tR\x06farmId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05count\x12E\n" +
+	"\x10acquisition_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0facquisitionDate\x12#\n" +
+	"\rhealth_status\x18\x06 \x01(\tR\fhealthStatus\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xfb\x02\n" +
+	"\vTransaction\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x17\n" +
+	"\afarm_id\x18\x02 \x01(\tR\x06farmId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x1a\n" +
+	"\bcategory\x18\x04 \x01(\tR\bcategory\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x01R\x06amount\x12.\n" +
+	"\x04date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12 \n" +
+	"\vdescription\x18\a \x01(\tR\vdescription\x12\x1c\n" +
+	"\treference\x18\b \x01(\tR\treference\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2\xa5\x04\n" +
+	"\bFarmData\x12X\n" +
+	"\tListFarms\x12$.farm4u.farmdata.v1.ListFarmsRequest\x1a%.farm4u.farmdata.v1.ListFarmsResponse\x12G\n" +
+	"\aGetFarm\x12\".farm4u.farmdata.v1.GetFarmRequest\x1a\x18.farm4u.farmdata.v1.Farm\x12U\n" +
+	"\n" +
+	"ListFields\x12\x1f.farm4u.farmdata.v1.FarmRequest\x1a&.farm4u.farmdata.v1.ListFieldsResponse\x12S\n" +
+	"\tListCrops\x12\x1f.farm4u.farmdata.v1.FarmRequest\x1a%.farm4u.farmdata.v1.ListCropsResponse\x12[\n" +
+	"\rListLivestock\x12\x1f.farm4u.farmdata.v1.FarmRequest\x1a).farm4u.farmdata.v1.ListLivestockResponse\x12m\n" +
+	"\x10ListTransactions\x12+.farm4u.farmdata.v1.ListTransactionsRequest\x1a,.farm4u.farmdata.v1.ListTransactionsResponseB%Z#farm4u/proto/farmdata/v1;farmdatav1b\x06proto3"
+
+var (
+	file_farmdata_v1_farmdata_proto_rawDescOnce sync.Once
+	file_farmdata_v1_farmdata_proto_rawDescData []byte
+)
+
+func file_farmdata_v1_farmdata_proto_rawDescGZIP() []byte {
+	file_farmdata_v1_farmdata_proto_rawDescOnce.Do(func() {
+		file_farmdata_v1_farmdata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_farmdata_v1_farmdata_proto_rawDesc), len(file_farmdata_v1_farmdata_proto_rawDesc)))
+	})
+	return file_farmdata_v1_farmdata_proto_rawDescData
+}
+
+var file_farmdata_v1_farmdata_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_farmdata_v1_farmdata_proto_goTypes = []any{
+	(*ListFarmsRequest)(nil),         // 0: farm4u.farmdata.v1.ListFarmsRequest
+	(*ListFarmsResponse)(nil),        // 1: farm4u.farmdata.v1.ListFarmsResponse
+	(*GetFarmRequest)(nil),           // 2: farm4u.farmdata.v1.GetFarmRequest
+	(*FarmRequest)(nil),              // 3: farm4u.farmdata.v1.FarmRequest
+	(*ListFieldsResponse)(nil),       // 4: farm4u.farmdata.v1.ListFieldsResponse
+	(*ListCropsResponse)(nil),        // 5: farm4u.farmdata.v1.ListCropsResponse
+	(*ListLivestockResponse)(nil),    // 6: farm4u.farmdata.v1.ListLivestockResponse
+	(*ListTransactionsRequest)(nil),  // 7: farm4u.farmdata.v1.ListTransactionsRequest
+	(*ListTransactionsResponse)(nil), // 8: farm4u.farmdata.v1.ListTransactionsResponse
+	(*Farm)(nil),                     // 9: farm4u.farmdata.v1.Farm
+	(*Field)(nil),                    // 10: farm4u.farmdata.v1.Field
+	(*Crop)(nil),                     // 11: farm4u.farmdata.v1.Crop
+	(*Livestock)(nil),                // 12: farm4u.farmdata.v1.Livestock
+	(*Transaction)(nil),              // 13: farm4u.farmdata.v1.Transaction
+	(*timestamppb.Timestamp)(nil),    // 14: google.protobuf.Timestamp
+}
+var file_farmdata_v1_farmdata_proto_depIdxs = []int32{
+	9,  // 0: farm4u.farmdata.v1.ListFarmsResponse.farms:type_name -> farm4u.farmdata.v1.Farm
+	10, // 1: farm4u.farmdata.v1.ListFieldsResponse.fields:type_name -> farm4u.farmdata.v1.Field
+	11, // 2: farm4u.farmdata.v1.ListCropsResponse.crops:type_name -> farm4u.farmdata.v1.Crop
+	12, // 3: farm4u.farmdata.v1.ListLivestockResponse.livestock:type_name -> farm4u.farmdata.v1.Livestock
+	14, // 4: farm4u.farmdata.v1.ListTransactionsRequest.from:type_name -> google.protobuf.Timestamp
+	14, // 5: farm4u.farmdata.v1.ListTransactionsRequest.to:type_name -> google.protobuf.Timestamp
+	13, // 6: farm4u.farmdata.v1.ListTransactionsResponse.transactions:type_name -> farm4u.farmdata.v1.Transaction
+	14, // 7: farm4u.farmdata.v1.Farm.created_at:type_name -> google.protobuf.Timestamp
+	14, // 8: farm4u.farmdata.v1.Farm.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 9: farm4u.farmdata.v1.Field.created_at:type_name -> google.protobuf.Timestamp
+	14, // 10: farm4u.farmdata.v1.Field.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 11: farm4u.farmdata.v1.Crop.planting_date:type_name -> google.protobuf.Timestamp
+	14, // 12: farm4u.farmdata.v1.Crop.harvest_date:type_name -> google.protobuf.Timestamp
+	14, // 13: farm4u.farmdata.v1.Crop.created_at:type_name -> google.protobuf.Timestamp
+	14, // 14: farm4u.farmdata.v1.Crop.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 15: farm4u.farmdata.v1.Livestock.acquisition_date:type_name -> google.protobuf.Timestamp
+	14, // 16: farm4u.farmdata.v1.Livestock.created_at:type_name -> google.protobuf.Timestamp
+	14, // 17: farm4u.farmdata.v1.Livestock.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 18: farm4u.farmdata.v1.Transaction.date:type_name -> google.protobuf.Timestamp
+	14, // 19: farm4u.farmdata.v1.Transaction.created_at:type_name -> google.protobuf.Timestamp
+	14, // 20: farm4u.farmdata.v1.Transaction.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 21: farm4u.farmdata.v1.FarmData.ListFarms:input_type -> farm4u.farmdata.v1.ListFarmsRequest
+	2,  // 22: farm4u.farmdata.v1.FarmData.GetFarm:input_type -> farm4u.farmdata.v1.GetFarmRequest
+	3,  // 23: farm4u.farmdata.v1.FarmData.ListFields:input_type -> farm4u.farmdata.v1.FarmRequest
+	3,  // 24: farm4u.farmdata.v1.FarmData.ListCrops:input_type -> farm4u.farmdata.v1.FarmRequest
+	3,  // 25: farm4u.farmdata.v1.FarmData.ListLivestock:input_type -> farm4u.farmdata.v1.FarmRequest
+	7,  // 26: farm4u.farmdata.v1.FarmData.ListTransactions:input_type -> farm4u.farmdata.v1.ListTransactionsRequest
+	1,  // 27: farm4u.farmdata.v1.FarmData.ListFarms:output_type -> farm4u.farmdata.v1.ListFarmsResponse
+	9,  // 28: farm4u.farmdata.v1.FarmData.GetFarm:output_type -> farm4u.farmdata.v1.Farm
+	4,  // 29: farm4u.farmdata.v1.FarmData.ListFields:output_type -> farm4u.farmdata.v1.ListFieldsResponse
+	5,  // 30: farm4u.farmdata.v1.FarmData.ListCrops:output_type -> farm4u.farmdata.v1.ListCropsResponse
+	6,  // 31: farm4u.farmdata.v1.FarmData.ListLivestock:output_type -> farm4u.farmdata.v1.ListLivestockResponse
+	8,  // 32: farm4u.farmdata.v1.FarmData.ListTransactions:output_type -> farm4u.farmdata.v1.ListTransactionsResponse
+	27, // [27:33] is the sub-list for method output_type
+	21, // [21:27] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_farmdata_v1_farmdata_proto_init() }
+func file_farmdata_v1_farmdata_proto_init() {
+	if File_farmdata_v1_farmdata_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_farmdata_v1_farmdata_proto_rawDesc), len(file_farmdata_v1_farmdata_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_farmdata_v1_farmdata_proto_goTypes,
+		DependencyIndexes: file_farmdata_v1_farmdata_proto_depIdxs,
+		MessageInfos:      file_farmdata_v1_farmdata_proto_msgTypes,
+	}.Build()
+	File_farmdata_v1_farmdata_proto = out.File
+	file_farmdata_v1_farmdata_proto_goTypes = nil
+	file_farmdata_v1_farmdata_proto_depIdxs = nil
+}
@@ -0,0 +1,327 @@
+// FarmData exposes the core farm records to internal services, such as
+// analytics, over gRPC. It is read-only and served by cmd/grpc; farmers and
+// partners use the HTTP API instead.
+//
+// Regenerate the Go code after changing this file, from the proto directory:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	  farmdata/v1/farmdata.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: farmdata/v1/farmdata.proto
+
+package farmdatav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FarmData_ListFarms_FullMethodName        = "/farm4u.farmdata.v1.FarmData/ListFarms"
+	FarmData_GetFarm_FullMethodName          = "/farm4u.farmdata.v1.FarmData/GetFarm"
+	FarmData_ListFields_FullMethodName       = "/farm4u.farmdata.v1.FarmData/ListFields"
+	FarmData_ListCrops_FullMethodName        = "/farm4u.farmdata.v1.FarmData/ListCrops"
+	FarmData_ListLivestock_FullMethodName    = "/farm4u.farmdata.v1.FarmData/ListLivestock"
+	FarmData_ListTransactions_FullMethodName = "/farm4u.farmdata.v1.FarmData/ListTransactions"
+)
+
+// FarmDataClient is the client API for FarmData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FarmDataClient interface {
+	// ListFarms returns every farm, or only a user's farms when user_id is set
+	ListFarms(ctx context.Context, in *ListFarmsRequest, opts ...grpc.CallOption) (*ListFarmsResponse, error)
+	GetFarm(ctx context.Context, in *GetFarmRequest, opts ...grpc.CallOption) (*Farm, error)
+	ListFields(ctx context.Context, in *FarmRequest, opts ...grpc.CallOption) (*ListFieldsResponse, error)
+	ListCrops(ctx context.Context, in *FarmRequest, opts ...grpc.CallOption) (*ListCropsResponse, error)
+	ListLivestock(ctx context.Context, in *FarmRequest, opts ...grpc.CallOption) (*ListLivestockResponse, error)
+	// ListTransactions returns a farm's transactions, optionally only those
+	// dated within [from, to]
+	ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error)
+}
+
+type farmDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFarmDataClient(cc grpc.ClientConnInterface) FarmDataClient {
+	return &farmDataClient{cc}
+}
+
+func (c *farmDataClient) ListFarms(ctx context.Context, in *ListFarmsRequest, opts ...grpc.CallOption) (*ListFarmsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFarmsResponse)
+	err := c.cc.Invoke(ctx, FarmData_ListFarms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *farmDataClient) GetFarm(ctx context.Context, in *GetFarmRequest, opts ...grpc.CallOption) (*Farm, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Farm)
+	err := c.cc.Invoke(ctx, FarmData_GetFarm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *farmDataClient) ListFields(ctx context.Context, in *FarmRequest, opts ...grpc.CallOption) (*ListFieldsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListFieldsResponse)
+	err := c.cc.Invoke(ctx, FarmData_ListFields_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *farmDataClient) ListCrops(ctx context.Context, in *FarmRequest, opts ...grpc.CallOption) (*ListCropsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCropsResponse)
+	err := c.cc.Invoke(ctx, FarmData_ListCrops_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *farmDataClient) ListLivestock(ctx context.Context, in *FarmRequest, opts ...grpc.CallOption) (*ListLivestockResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLivestockResponse)
+	err := c.cc.Invoke(ctx, FarmData_ListLivestock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *farmDataClient) ListTransactions(ctx context.Context, in *ListTransactionsRequest, opts ...grpc.CallOption) (*ListTransactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTransactionsResponse)
+	err := c.cc.Invoke(ctx, FarmData_ListTransactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FarmDataServer is the server API for FarmData service.
+// All implementations must embed UnimplementedFarmDataServer
+// for forward compatibility.
+type FarmDataServer interface {
+	// ListFarms returns every farm, or only a user's farms when user_id is set
+	ListFarms(context.Context, *ListFarmsRequest) (*ListFarmsResponse, error)
+	GetFarm(context.Context, *GetFarmRequest) (*Farm, error)
+	ListFields(context.Context, *FarmRequest) (*ListFieldsResponse, error)
+	ListCrops(context.Context, *FarmRequest) (*ListCropsResponse, error)
+	ListLivestock(context.Context, *FarmRequest) (*ListLivestockResponse, error)
+	// ListTransactions returns a farm's transactions, optionally only those
+	// dated within [from, to]
+	ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error)
+	mustEmbedUnimplementedFarmDataServer()
+}
+
+// UnimplementedFarmDataServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFarmDataServer struct{}
+
+func (UnimplementedFarmDataServer) ListFarms(context.Context, *ListFarmsRequest) (*ListFarmsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFarms not implemented")
+}
+func (UnimplementedFarmDataServer) GetFarm(context.Context, *GetFarmRequest) (*Farm, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFarm not implemented")
+}
+func (UnimplementedFarmDataServer) ListFields(context.Context, *FarmRequest) (*ListFieldsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFields not implemented")
+}
+func (UnimplementedFarmDataServer) ListCrops(context.Context, *FarmRequest) (*ListCropsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCrops not implemented")
+}
+func (UnimplementedFarmDataServer) ListLivestock(context.Context, *FarmRequest) (*ListLivestockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLivestock not implemented")
+}
+func (UnimplementedFarmDataServer) ListTransactions(context.Context, *ListTransactionsRequest) (*ListTransactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTransactions not implemented")
+}
+func (UnimplementedFarmDataServer) mustEmbedUnimplementedFarmDataServer() {}
+func (UnimplementedFarmDataServer) testEmbeddedByValue()                  {}
+
+// UnsafeFarmDataServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FarmDataServer will
+// result in compilation errors.
+type UnsafeFarmDataServer interface {
+	mustEmbedUnimplementedFarmDataServer()
+}
+
+func RegisterFarmDataServer(s grpc.ServiceRegistrar, srv FarmDataServer) {
+	// If the following call pancis, it indicates UnimplementedFarmDataServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FarmData_ServiceDesc, srv)
+}
+
+func _FarmData_ListFarms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFarmsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FarmDataServer).ListFarms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FarmData_ListFarms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FarmDataServer).ListFarms(ctx, req.(*ListFarmsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FarmData_GetFarm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFarmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FarmDataServer).GetFarm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FarmData_GetFarm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FarmDataServer).GetFarm(ctx, req.(*GetFarmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FarmData_ListFields_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FarmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FarmDataServer).ListFields(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FarmData_ListFields_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FarmDataServer).ListFields(ctx, req.(*FarmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FarmData_ListCrops_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FarmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FarmDataServer).ListCrops(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FarmData_ListCrops_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FarmDataServer).ListCrops(ctx, req.(*FarmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FarmData_ListLivestock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FarmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FarmDataServer).ListLivestock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FarmData_ListLivestock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FarmDataServer).ListLivestock(ctx, req.(*FarmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FarmData_ListTransactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTransactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FarmDataServer).ListTransactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FarmData_ListTransactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FarmDataServer).ListTransactions(ctx, req.(*ListTransactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FarmData_ServiceDesc is the grpc.ServiceDesc for FarmData service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FarmData_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "farm4u.farmdata.v1.FarmData",
+	HandlerType: (*FarmDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListFarms",
+			Handler:    _FarmData_ListFarms_Handler,
+		},
+		{
+			MethodName: "GetFarm",
+			Handler:    _FarmData_GetFarm_Handler,
+		},
+		{
+			MethodName: "ListFields",
+			Handler:    _FarmData_ListFields_Handler,
+		},
+		{
+			MethodName: "ListCrops",
+			Handler:    _FarmData_ListCrops_Handler,
+		},
+		{
+			MethodName: "ListLivestock",
+			Handler:    _FarmData_ListLivestock_Handler,
+		},
+		{
+			MethodName: "ListTransactions",
+			Handler:    _FarmData_ListTransactions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "farmdata/v1/farmdata.proto",
+}
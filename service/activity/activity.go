@@ -0,0 +1,163 @@
+// Package activity keeps a farm's activity feed: what was added, changed,
+// deleted or restored across its crops, livestock, employees and finances,
+// and by whom. The feed is read from the audit log, which the domain services
+// wrapped with Crops, Livestock, Workforce and Finance write to as they make
+// each change. Each change is also published as an event, such as
+// crop.created, to the farm's webhooks.
+package activity
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service/farm"
+	"fmt"
+	"time"
+)
+
+// Entity types written to the audit log
+const (
+	EntityCrop        = "crop"
+	EntityLivestock   = "livestock"
+	EntityEmployee    = "employee"
+	EntityTransaction = "transaction"
+)
+
+// Changes, combined with the entity type into the audit log action, such as
+// crop.created
+const (
+	Created  = "created"
+	Updated  = "updated"
+	Deleted  = "deleted"
+	Restored = "restored"
+)
+
+// feedWindow is how far back the feed goes when no range is given
+const feedWindow = 7 * 24 * time.Hour
+
+// Item is one change in a farm's activity feed
+type Item struct {
+	At         time.Time `json:"at"`
+	Action     string    `json:"action"` // e.g. crop.created, period.lock
+	EntityType string    `json:"entityType"`
+	EntityID   string    `json:"entityId"`
+	Summary    string    `json:"summary"`
+	UserID     string    `json:"userId"`
+	UserName   string    `json:"userName"` // Empty when the user no longer exists
+}
+
+// Service is the activity feed service
+type Service interface {
+	// Feed returns the changes made on one of the user's farms in [from, to),
+	// newest first, optionally only those to one entity type. Without a range
+	// it covers the last seven days.
+	Feed(ctx context.Context, user *data.User, farmID, entityType string, from, to *time.Time) ([]*Item, error)
+	// History returns every change made to one record, oldest first, with
+	// the fields each change set. Members can read the history of records in
+	// the modules they can read; other histories are for the farm's owner.
+	History(ctx context.Context, user *data.User, entityType, entityID string) ([]*Version, error)
+}
+
+// activityService implements Service on top of the audit log and user
+// repositories
+type activityService struct {
+	audit data.AuditLogInterface
+	users data.UserInterface
+	farms farm.Service
+}
+
+// New creates the activity feed service
+func New(audit data.AuditLogInterface, users data.UserInterface, farms farm.Service) Service {
+	return &activityService{audit: audit, users: users, farms: farms}
+}
+
+// Feed implements Service
+func (s *activityService) Feed(ctx context.Context, user *data.User, farmID, entityType string, from, to *time.Time) ([]*Item, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if from == nil && to == nil {
+		since := time.Now().Add(-feedWindow)
+		from = &since
+	}
+
+	entries, err := s.audit.GetByFarmID(ctx, farmID, entityType, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting audit log: %w", err)
+	}
+
+	names := map[string]string{}
+	items := make([]*Item, len(entries))
+	for i, entry := range entries {
+		name, err := s.userName(ctx, names, entry.UserID)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = &Item{
+			At:         entry.CreatedAt,
+			Action:     entry.Action,
+			EntityType: entry.EntityType,
+			EntityID:   entry.EntityID,
+			Summary:    entry.Details,
+			UserID:     entry.UserID,
+			UserName:   name,
+		}
+	}
+	return items, nil
+}
+
+// userName returns the name of the user userID, remembering it in names. It
+// is empty when the user no longer exists.
+func (s *activityService) userName(ctx context.Context, names map[string]string, userID string) (string, error) {
+	if name, ok := names[userID]; ok {
+		return name, nil
+	}
+	author, err := s.users.GetByUserID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("getting user: %w", err)
+	}
+	var name string
+	if author != nil {
+		name = author.FirstName + " " + author.LastName
+	}
+	names[userID] = name
+	return name, nil
+}
+
+// Publisher passes a farm's events on to its webhooks
+type Publisher interface {
+	Publish(ctx context.Context, farmID, event string, record any) error
+}
+
+// recorder writes the changes the wrapped services make to the audit log and
+// publishes them
+type recorder struct {
+	audit  data.AuditLogInterface
+	events Publisher
+}
+
+// record writes a change to an entity on a farm and publishes it with the
+// entity as it now is. Creates and updates also store the fields they set,
+// compared with the entity as it was before, nil for a create. The change
+// has already been made by then, so a failed write leaves a gap in the feed,
+// or an event unsent, rather than failing the request.
+func (r recorder) record(ctx context.Context, user *data.User, farmID, entityType, change, entityID, summary string, before, entity any) {
+	entry := &data.AuditLog{
+		FarmID:     farmID,
+		UserID:     user.UserID,
+		Action:     entityType + "." + change,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    summary,
+	}
+	if change == Created || change == Updated {
+		entry.Changes = diff(before, entity)
+	}
+	_ = r.audit.Insert(ctx, entry)
+	r.publish(ctx, farmID, entry.Action, entity)
+}
+
+// publish sends an event that is not written to the feed, such as
+// sale.recorded
+func (r recorder) publish(ctx context.Context, farmID, event string, entity any) {
+	_ = r.events.Publish(ctx, farmID, event, entity)
+}
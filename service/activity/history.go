@@ -0,0 +1,121 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"time"
+)
+
+// untracked are the record fields left out of a change, as they move with
+// every write. Relations loaded alongside a record are left out too.
+var untracked = []string{"createdAt", "updatedAt", "version"}
+
+// entityModules are the modules of the permissions matrix whose members can
+// read an entity's history; the history of other entities is for the farm's
+// owner only
+var entityModules = map[string]farm.Module{
+	EntityTransaction: farm.ModuleFinance,
+}
+
+// EntityTypes lists the entity types whose history is kept
+var EntityTypes = []string{EntityCrop, EntityLivestock, EntityEmployee, EntityTransaction}
+
+// Version is one change in a record's history
+type Version struct {
+	Version  int                `json:"version"` // 1 for the change that created the record
+	At       time.Time          `json:"at"`
+	Action   string             `json:"action"` // e.g. crop.updated
+	UserID   string             `json:"userId"`
+	UserName string             `json:"userName"` // Empty when the user no longer exists
+	Changes  []data.FieldChange `json:"changes"`  // Empty for deletes and restores, and for changes made before fields were tracked
+}
+
+// History implements Service
+func (s *activityService) History(ctx context.Context, user *data.User, entityType, entityID string) ([]*Version, error) {
+	if !slices.Contains(EntityTypes, entityType) {
+		return nil, service.Invalid(fmt.Sprintf("no history is kept for %s records", entityType))
+	}
+
+	entries, err := s.audit.GetByEntity(ctx, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("getting audit log: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, service.NotFound(entityType + " history not found")
+	}
+
+	farmID := entries[0].FarmID
+	if module, ok := entityModules[entityType]; ok {
+		_, err = s.farms.Authorize(ctx, user, farmID, module, farm.Read)
+	} else {
+		err = farm.CheckRecord(ctx, s.farms, user, farmID, entityType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]string{}
+	versions := make([]*Version, len(entries))
+	for i, entry := range entries {
+		name, err := s.userName(ctx, names, entry.UserID)
+		if err != nil {
+			return nil, err
+		}
+		versions[i] = &Version{
+			Version:  i + 1,
+			At:       entry.CreatedAt,
+			Action:   entry.Action,
+			UserID:   entry.UserID,
+			UserName: name,
+			Changes:  entry.Changes,
+		}
+		if versions[i].Changes == nil {
+			versions[i].Changes = []data.FieldChange{}
+		}
+	}
+	return versions, nil
+}
+
+// diff lists the fields that differ between two versions of a record, by
+// name. A nil before lists every field of after, as set by a create.
+func diff(before, after any) []data.FieldChange {
+	old, new := fields(before), fields(after)
+	var changes []data.FieldChange
+	for name, value := range new {
+		previous, ok := old[name]
+		if ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		changes = append(changes, data.FieldChange{Field: name, Old: previous, New: value})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// fields reads a record's tracked fields as they are written to JSON
+func fields(record any) map[string]any {
+	values := map[string]any{}
+	if v := reflect.ValueOf(record); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return values
+	}
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return values
+	}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return map[string]any{}
+	}
+	for name, value := range values {
+		if _, nested := value.(map[string]any); nested || slices.Contains(untracked, name) {
+			delete(values, name)
+		}
+	}
+	return values
+}
@@ -0,0 +1,304 @@
+package activity
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/crop"
+	"farm4u/service/finance"
+	"farm4u/service/livestock"
+	"farm4u/service/workforce"
+	"fmt"
+)
+
+// Crops returns svc with the crops it adds, changes, deletes and restores
+// written to the activity feed
+func Crops(svc crop.Service, audit data.AuditLogInterface, events Publisher) crop.Service {
+	return &cropActivity{Service: svc, log: recorder{audit: audit, events: events}}
+}
+
+// Livestock returns svc with the livestock it adds, changes, deletes and
+// restores written to the activity feed
+func Livestock(svc livestock.Service, audit data.AuditLogInterface, events Publisher) livestock.Service {
+	return &livestockActivity{Service: svc, log: recorder{audit: audit, events: events}}
+}
+
+// Workforce returns svc with the employees it adds, changes, deletes and
+// restores written to the activity feed
+func Workforce(svc workforce.Service, audit data.AuditLogInterface, events Publisher) workforce.Service {
+	return &workforceActivity{Service: svc, log: recorder{audit: audit, events: events}}
+}
+
+// Finance returns svc with the transactions it records, changes and deletes
+// written to the activity feed. Income recorded is also published as
+// sale.recorded.
+func Finance(svc finance.Service, audit data.AuditLogInterface, events Publisher) finance.Service {
+	return &financeActivity{Service: svc, log: recorder{audit: audit, events: events}}
+}
+
+// cropActivity records the crop changes made through crop.Service
+type cropActivity struct {
+	crop.Service
+	log recorder
+}
+
+func (c *cropActivity) Create(ctx context.Context, user *data.User, farmID string, in crop.Input) (*data.Crop, error) {
+	created, err := c.Service.Create(ctx, user, farmID, in)
+	if err == nil {
+		c.record(ctx, user, nil, created, Created)
+	}
+	return created, err
+}
+
+func (c *cropActivity) CreateBatch(ctx context.Context, user *data.User, farmID string, ins []crop.Input) ([]*data.Crop, []error, error) {
+	crops, errs, err := c.Service.CreateBatch(ctx, user, farmID, ins)
+	for _, created := range crops {
+		if created != nil {
+			c.record(ctx, user, nil, created, Created)
+		}
+	}
+	return crops, errs, err
+}
+
+func (c *cropActivity) Update(ctx context.Context, user *data.User, cropID string, in crop.Input) (*data.Crop, error) {
+	before, _ := c.Service.Get(ctx, user, cropID)
+	updated, err := c.Service.Update(ctx, user, cropID, in)
+	if err == nil {
+		c.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (c *cropActivity) Patch(ctx context.Context, user *data.User, cropID string, in crop.Input, set service.Fields) (*data.Crop, error) {
+	before, _ := c.Service.Get(ctx, user, cropID)
+	updated, err := c.Service.Patch(ctx, user, cropID, in, set)
+	if err == nil {
+		c.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (c *cropActivity) UpdateStatus(ctx context.Context, user *data.User, farmID string, cropIDs []string, status string) ([]*crop.StatusChange, []error, error) {
+	changes, errs, err := c.Service.UpdateStatus(ctx, user, farmID, cropIDs, status)
+	for _, change := range changes {
+		if change != nil {
+			c.record(ctx, user, change.Before, change.Crop, Updated)
+		}
+	}
+	return changes, errs, err
+}
+
+func (c *cropActivity) Delete(ctx context.Context, user *data.User, cropID string) error {
+	deleted, err := c.Service.Get(ctx, user, cropID)
+	if err != nil {
+		return c.Service.Delete(ctx, user, cropID)
+	}
+	if err := c.Service.Delete(ctx, user, cropID); err != nil {
+		return err
+	}
+	c.record(ctx, user, nil, deleted, Deleted)
+	return nil
+}
+
+func (c *cropActivity) Restore(ctx context.Context, user *data.User, cropID string) (*data.Crop, error) {
+	restored, err := c.Service.Restore(ctx, user, cropID)
+	if err == nil {
+		c.record(ctx, user, nil, restored, Restored)
+	}
+	return restored, err
+}
+
+func (c *cropActivity) record(ctx context.Context, user *data.User, before, planting *data.Crop, change string) {
+	summary := fmt.Sprintf("%s (%s)", planting.Name, planting.Status)
+	c.log.record(ctx, user, planting.FarmID, EntityCrop, change, planting.CropID, summary, before, planting)
+}
+
+// livestockActivity records the livestock changes made through
+// livestock.Service
+type livestockActivity struct {
+	livestock.Service
+	log recorder
+}
+
+func (l *livestockActivity) Create(ctx context.Context, user *data.User, farmID string, in livestock.Input) (*data.Livestock, error) {
+	created, err := l.Service.Create(ctx, user, farmID, in)
+	if err == nil {
+		l.record(ctx, user, nil, created, Created)
+	}
+	return created, err
+}
+
+func (l *livestockActivity) CreateBatch(ctx context.Context, user *data.User, farmID string, ins []livestock.Input) ([]*data.Livestock, error) {
+	herds, err := l.Service.CreateBatch(ctx, user, farmID, ins)
+	if err == nil {
+		for _, created := range herds {
+			l.record(ctx, user, nil, created, Created)
+		}
+	}
+	return herds, err
+}
+
+func (l *livestockActivity) Update(ctx context.Context, user *data.User, livestockID string, in livestock.Input) (*data.Livestock, error) {
+	before, _ := l.Service.Get(ctx, user, livestockID)
+	updated, err := l.Service.Update(ctx, user, livestockID, in)
+	if err == nil {
+		l.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (l *livestockActivity) Patch(ctx context.Context, user *data.User, livestockID string, in livestock.Input, set service.Fields) (*data.Livestock, error) {
+	before, _ := l.Service.Get(ctx, user, livestockID)
+	updated, err := l.Service.Patch(ctx, user, livestockID, in, set)
+	if err == nil {
+		l.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (l *livestockActivity) Delete(ctx context.Context, user *data.User, livestockID string) error {
+	deleted, err := l.Service.Get(ctx, user, livestockID)
+	if err != nil {
+		return l.Service.Delete(ctx, user, livestockID)
+	}
+	if err := l.Service.Delete(ctx, user, livestockID); err != nil {
+		return err
+	}
+	l.record(ctx, user, nil, deleted, Deleted)
+	return nil
+}
+
+func (l *livestockActivity) DeleteBatch(ctx context.Context, user *data.User, farmID string, livestockIDs []string) ([]*data.Livestock, []error, error) {
+	herds, errs, err := l.Service.DeleteBatch(ctx, user, farmID, livestockIDs)
+	for _, deleted := range herds {
+		if deleted != nil {
+			l.record(ctx, user, nil, deleted, Deleted)
+		}
+	}
+	return herds, errs, err
+}
+
+func (l *livestockActivity) Restore(ctx context.Context, user *data.User, livestockID string) (*data.Livestock, error) {
+	restored, err := l.Service.Restore(ctx, user, livestockID)
+	if err == nil {
+		l.record(ctx, user, nil, restored, Restored)
+	}
+	return restored, err
+}
+
+func (l *livestockActivity) record(ctx context.Context, user *data.User, before, herd *data.Livestock, change string) {
+	summary := fmt.Sprintf("%d %s", herd.Count, herd.Type)
+	l.log.record(ctx, user, herd.FarmID, EntityLivestock, change, herd.LivestockID, summary, before, herd)
+}
+
+// workforceActivity records the employee changes made through
+// workforce.Service
+type workforceActivity struct {
+	workforce.Service
+	log recorder
+}
+
+func (w *workforceActivity) CreateEmployee(ctx context.Context, user *data.User, farmID string, in workforce.EmployeeInput) (*data.Employee, error) {
+	created, err := w.Service.CreateEmployee(ctx, user, farmID, in)
+	if err == nil {
+		w.record(ctx, user, nil, created, Created)
+	}
+	return created, err
+}
+
+func (w *workforceActivity) CreateEmployees(ctx context.Context, user *data.User, farmID string, ins []workforce.EmployeeInput) ([]*data.Employee, []error, error) {
+	employees, errs, err := w.Service.CreateEmployees(ctx, user, farmID, ins)
+	for _, created := range employees {
+		if created != nil {
+			w.record(ctx, user, nil, created, Created)
+		}
+	}
+	return employees, errs, err
+}
+
+func (w *workforceActivity) UpdateEmployee(ctx context.Context, user *data.User, employeeID string, in workforce.EmployeeInput) (*data.Employee, error) {
+	before, _ := w.Service.GetEmployee(ctx, user, employeeID)
+	updated, err := w.Service.UpdateEmployee(ctx, user, employeeID, in)
+	if err == nil {
+		w.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (w *workforceActivity) PatchEmployee(ctx context.Context, user *data.User, employeeID string, in workforce.EmployeeInput, set service.Fields) (*data.Employee, error) {
+	before, _ := w.Service.GetEmployee(ctx, user, employeeID)
+	updated, err := w.Service.PatchEmployee(ctx, user, employeeID, in, set)
+	if err == nil {
+		w.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (w *workforceActivity) DeleteEmployee(ctx context.Context, user *data.User, employeeID string) error {
+	deleted, err := w.Service.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return w.Service.DeleteEmployee(ctx, user, employeeID)
+	}
+	if err := w.Service.DeleteEmployee(ctx, user, employeeID); err != nil {
+		return err
+	}
+	w.record(ctx, user, nil, deleted, Deleted)
+	return nil
+}
+
+func (w *workforceActivity) RestoreEmployee(ctx context.Context, user *data.User, employeeID string) (*data.Employee, error) {
+	restored, err := w.Service.RestoreEmployee(ctx, user, employeeID)
+	if err == nil {
+		w.record(ctx, user, nil, restored, Restored)
+	}
+	return restored, err
+}
+
+func (w *workforceActivity) record(ctx context.Context, user *data.User, before, employee *data.Employee, change string) {
+	summary := fmt.Sprintf("%s %s (%s)", employee.FirstName, employee.LastName, employee.Position)
+	w.log.record(ctx, user, employee.FarmID, EntityEmployee, change, employee.EmployeeID, summary, before, employee)
+}
+
+// financeActivity records the transaction changes made through
+// finance.Service
+type financeActivity struct {
+	finance.Service
+	log recorder
+}
+
+func (f *financeActivity) CreateTransaction(ctx context.Context, user *data.User, farmID string, in finance.TransactionInput) (*data.Transaction, error) {
+	created, err := f.Service.CreateTransaction(ctx, user, farmID, in)
+	if err == nil {
+		f.record(ctx, user, nil, created, Created)
+		if created.Type == "Income" {
+			f.log.publish(ctx, created.FarmID, "sale.recorded", created)
+		}
+	}
+	return created, err
+}
+
+func (f *financeActivity) UpdateTransaction(ctx context.Context, user *data.User, transactionID string, in finance.TransactionInput) (*data.Transaction, error) {
+	before, _ := f.Service.GetTransaction(ctx, user, transactionID)
+	updated, err := f.Service.UpdateTransaction(ctx, user, transactionID, in)
+	if err == nil {
+		f.record(ctx, user, before, updated, Updated)
+	}
+	return updated, err
+}
+
+func (f *financeActivity) DeleteTransaction(ctx context.Context, user *data.User, transactionID string) error {
+	deleted, err := f.Service.GetTransaction(ctx, user, transactionID)
+	if err != nil {
+		return f.Service.DeleteTransaction(ctx, user, transactionID)
+	}
+	if err := f.Service.DeleteTransaction(ctx, user, transactionID); err != nil {
+		return err
+	}
+	f.record(ctx, user, nil, deleted, Deleted)
+	return nil
+}
+
+func (f *financeActivity) record(ctx context.Context, user *data.User, before, transaction *data.Transaction, change string) {
+	summary := fmt.Sprintf("%s %.2f, %s", transaction.Type, transaction.Amount, transaction.Category)
+	f.log.record(ctx, user, transaction.FarmID, EntityTransaction, change, transaction.TransactionID, summary, before, transaction)
+}
@@ -0,0 +1,445 @@
+// Package asset keeps a farm's asset register: equipment, structures and
+// breeding stock at their book value, with the acquisitions, revaluations
+// and disposals that change it, and the balance sheet lenders ask for.
+package asset
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Asset statuses
+const (
+	StatusActive   = "Active"
+	StatusDisposed = "Disposed"
+)
+
+// Input holds the editable asset fields. On create, an asset registered from
+// equipment or livestock takes its name, acquisition date and cost from that
+// record unless they are given. On update only Name, Category, Notes and the
+// depreciation terms change, and zero values are left unchanged; an empty
+// DepreciationMethod clears the depreciation terms.
+type Input struct {
+	Name               string
+	Category           string
+	EquipmentID        *string
+	LivestockID        *string
+	AcquisitionDate    *time.Time
+	AcquisitionCost    *float64
+	DepreciationMethod *string
+	UsefulLifeYears    int
+	SalvageValue       *float64
+	DepreciationRate   *float64
+	Notes              string
+}
+
+// RevaluationInput is a new book value for an asset
+type RevaluationInput struct {
+	Date  *time.Time
+	Value float64
+	Notes string
+}
+
+// DisposalInput is the sale or write-off of an asset
+type DisposalInput struct {
+	Date     *time.Time
+	Method   string
+	Proceeds float64
+	Notes    string
+}
+
+// CategoryValue totals the assets of one category on a balance sheet
+type CategoryValue struct {
+	Category  string  `json:"category"`
+	Count     int     `json:"count"`
+	Cost      float64 `json:"cost"`
+	BookValue float64 `json:"bookValue"`
+}
+
+// BalanceSheet values a farm's assets at the end of a day for lenders.
+// NetLedger is the income less expenses recorded up to that day.
+type BalanceSheet struct {
+	FarmID         string          `json:"farmId"`
+	FarmName       string          `json:"farmName"`
+	AsOf           time.Time       `json:"asOf"`
+	Assets         []CategoryValue `json:"assets"`
+	TotalCost      float64         `json:"totalCost"`
+	TotalBookValue float64         `json:"totalBookValue"`
+	NetLedger      float64         `json:"netLedger"`
+	GeneratedAt    time.Time       `json:"generatedAt"`
+}
+
+// Service is the asset register domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Asset, error)
+	Get(ctx context.Context, user *data.User, assetID string) (*data.Asset, error)
+	List(ctx context.Context, user *data.User, farmID, status string) ([]*data.Asset, error)
+	Update(ctx context.Context, user *data.User, assetID string, in Input) (*data.Asset, error)
+	Delete(ctx context.Context, user *data.User, assetID string) error
+	Revalue(ctx context.Context, user *data.User, assetID string, in RevaluationInput) (*data.Asset, error)
+	Dispose(ctx context.Context, user *data.User, assetID string, in DisposalInput) (*data.Asset, error)
+	// BalanceSheet values the farm's assets at the end of day asOf
+	BalanceSheet(ctx context.Context, user *data.User, farmID string, asOf time.Time) (*BalanceSheet, error)
+}
+
+// assetService implements Service on top of the asset repository
+type assetService struct {
+	assets       data.AssetInterface
+	equipment    data.EquipmentInterface
+	livestock    data.LivestockInterface
+	transactions data.TransactionInterface
+	locks        lock.Checker
+	farms        farm.Service
+}
+
+// New creates the asset service
+func New(assets data.AssetInterface, equipment data.EquipmentInterface, livestock data.LivestockInterface, transactions data.TransactionInterface, locks lock.Checker, farms farm.Service) Service {
+	return &assetService{assets: assets, equipment: equipment, livestock: livestock, transactions: transactions, locks: locks, farms: farms}
+}
+
+// Create registers an asset on one of the user's farms with its acquisition
+func (s *assetService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Asset, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	asset := &data.Asset{
+		FarmID:   farmID,
+		Name:     in.Name,
+		Category: in.Category,
+		Status:   StatusActive,
+		Notes:    in.Notes,
+	}
+	if in.EquipmentID != nil && *in.EquipmentID != "" {
+		if err := s.fromEquipment(ctx, asset, *in.EquipmentID); err != nil {
+			return nil, err
+		}
+	} else if in.LivestockID != nil && *in.LivestockID != "" {
+		if err := s.fromLivestock(ctx, asset, *in.LivestockID); err != nil {
+			return nil, err
+		}
+	}
+	if in.AcquisitionDate != nil {
+		asset.AcquisitionDate = *in.AcquisitionDate
+	}
+	if in.AcquisitionCost != nil {
+		asset.AcquisitionCost = *in.AcquisitionCost
+	}
+
+	switch {
+	case asset.Name == "":
+		return nil, service.Invalid("name is required")
+	case asset.Category == "":
+		return nil, service.Invalid("category is required")
+	case asset.AcquisitionDate.IsZero():
+		return nil, service.Invalid("acquisition date is required")
+	}
+	asset.BookValue = asset.AcquisitionCost
+	if err := depreciate(asset, in); err != nil {
+		return nil, err
+	}
+
+	if err := s.assets.Insert(ctx, asset, user.UserID); err != nil {
+		return nil, fmt.Errorf("creating asset: %w", err)
+	}
+	return asset, nil
+}
+
+// fromEquipment links an asset to an equipment record on the same farm that
+// is not yet registered, filling in what the record knows
+func (s *assetService) fromEquipment(ctx context.Context, asset *data.Asset, equipmentID string) error {
+	equipment, err := s.equipment.GetByEquipmentID(ctx, equipmentID)
+	if err != nil {
+		return fmt.Errorf("getting equipment: %w", err)
+	}
+	if equipment == nil || equipment.FarmID != asset.FarmID {
+		return service.Invalid("equipment not found on this farm")
+	}
+	existing, err := s.assets.GetByEquipmentID(ctx, equipmentID)
+	if err != nil {
+		return fmt.Errorf("getting equipment asset: %w", err)
+	}
+	if existing != nil {
+		return service.Conflict("equipment is already in the asset register")
+	}
+
+	asset.EquipmentID = &equipment.EquipmentID
+	if asset.Name == "" {
+		asset.Name = equipment.Name
+	}
+	if asset.Category == "" {
+		asset.Category = data.AssetEquipment
+	}
+	if equipment.PurchaseDate != nil {
+		asset.AcquisitionDate = *equipment.PurchaseDate
+	}
+	asset.AcquisitionCost = equipment.PurchaseCost
+	return nil
+}
+
+// fromLivestock links an asset to a livestock record on the same farm that
+// is not yet registered, filling in what the record knows
+func (s *assetService) fromLivestock(ctx context.Context, asset *data.Asset, livestockID string) error {
+	livestock, err := s.livestock.GetByLivestockID(ctx, livestockID)
+	if err != nil {
+		return fmt.Errorf("getting livestock: %w", err)
+	}
+	if livestock == nil || livestock.FarmID != asset.FarmID {
+		return service.Invalid("livestock not found on this farm")
+	}
+	existing, err := s.assets.GetByLivestockID(ctx, livestockID)
+	if err != nil {
+		return fmt.Errorf("getting livestock asset: %w", err)
+	}
+	if existing != nil {
+		return service.Conflict("livestock is already in the asset register")
+	}
+
+	asset.LivestockID = &livestock.LivestockID
+	if asset.Name == "" {
+		asset.Name = fmt.Sprintf("%s (%d head)", livestock.Type, livestock.Count)
+	}
+	if asset.Category == "" {
+		asset.Category = data.AssetBreedingStock
+	}
+	if livestock.AcquisitionDate != nil {
+		asset.AcquisitionDate = *livestock.AcquisitionDate
+	}
+	return nil
+}
+
+// Get returns an asset of one of the user's farms with its events
+func (s *assetService) Get(ctx context.Context, user *data.User, assetID string) (*data.Asset, error) {
+	asset, err := s.assets.GetByAssetID(ctx, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting asset: %w", err)
+	}
+	if asset == nil {
+		return nil, service.NotFound("asset not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, asset.FarmID, "asset"); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// List returns a farm's assets, optionally only those with the given status
+func (s *assetService) List(ctx context.Context, user *data.User, farmID, status string) ([]*data.Asset, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	assets, err := s.assets.GetByFarmID(ctx, farmID, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting assets: %w", err)
+	}
+	return assets, nil
+}
+
+// Update changes an asset's name, category or notes. Its value only changes
+// through revaluations and disposals.
+func (s *assetService) Update(ctx context.Context, user *data.User, assetID string, in Input) (*data.Asset, error) {
+	asset, err := s.Get(ctx, user, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		asset.Name = in.Name
+	}
+	if in.Category != "" {
+		asset.Category = in.Category
+	}
+	if in.Notes != "" {
+		asset.Notes = in.Notes
+	}
+	if err := depreciate(asset, in); err != nil {
+		return nil, err
+	}
+
+	if err := s.assets.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("updating asset: %w", err)
+	}
+	return asset, nil
+}
+
+// depreciate applies the depreciation terms of in to an asset, checking that
+// only equipment and structures are depreciated and that the terms are
+// complete
+func depreciate(asset *data.Asset, in Input) error {
+	if in.DepreciationMethod != nil {
+		asset.DepreciationMethod = *in.DepreciationMethod
+	}
+	if in.UsefulLifeYears > 0 {
+		asset.UsefulLifeYears = in.UsefulLifeYears
+	}
+	if in.SalvageValue != nil {
+		asset.SalvageValue = *in.SalvageValue
+	}
+	if in.DepreciationRate != nil {
+		asset.DepreciationRate = *in.DepreciationRate
+	}
+	if asset.DepreciationMethod == "" {
+		asset.UsefulLifeYears, asset.SalvageValue, asset.DepreciationRate = 0, 0, 0
+		return nil
+	}
+
+	switch {
+	case asset.Category != data.AssetEquipment && asset.Category != data.AssetStructure:
+		return service.Invalid("only equipment and structures are depreciated")
+	case asset.UsefulLifeYears <= 0:
+		return service.Invalid("useful life is required for depreciation")
+	case asset.SalvageValue > asset.AcquisitionCost:
+		return service.Invalid("salvage value must not exceed the acquisition cost")
+	}
+	return nil
+}
+
+// Delete removes an asset registered in error. Disposed assets stay in the
+// register as the record of their disposal.
+func (s *assetService) Delete(ctx context.Context, user *data.User, assetID string) error {
+	asset, err := s.Get(ctx, user, assetID)
+	if err != nil {
+		return err
+	}
+	if asset.Status == StatusDisposed {
+		return service.Conflict("a disposed asset cannot be deleted")
+	}
+	if err := s.assets.DeleteByID(ctx, int(asset.ID)); err != nil {
+		return fmt.Errorf("deleting asset: %w", err)
+	}
+	return nil
+}
+
+// Revalue sets a new book value for an active asset
+func (s *assetService) Revalue(ctx context.Context, user *data.User, assetID string, in RevaluationInput) (*data.Asset, error) {
+	asset, date, err := s.changeable(ctx, user, assetID, in.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	asset.BookValue = in.Value
+	event := &data.AssetEvent{
+		Type:       data.AssetRevalued,
+		Date:       date,
+		Value:      in.Value,
+		RecordedBy: user.UserID,
+		Notes:      in.Notes,
+	}
+	if err := s.assets.AddEvent(ctx, asset, event); err != nil {
+		return nil, fmt.Errorf("revaluing asset: %w", err)
+	}
+	return asset, nil
+}
+
+// Dispose sells or writes off an active asset. Sale proceeds are recorded as
+// income, so the date must not be in a locked period.
+func (s *assetService) Dispose(ctx context.Context, user *data.User, assetID string, in DisposalInput) (*data.Asset, error) {
+	asset, date, err := s.changeable(ctx, user, assetID, in.Date)
+	if err != nil {
+		return nil, err
+	}
+	if in.Method == data.DisposalWriteOff && in.Proceeds > 0 {
+		return nil, service.Invalid("a write-off has no proceeds; record a sale instead")
+	}
+	if err := s.locks.Check(ctx, asset.FarmID, date); err != nil {
+		return nil, err
+	}
+
+	event := &data.AssetEvent{
+		Type:       data.AssetDisposed,
+		Date:       date,
+		Method:     in.Method,
+		Proceeds:   in.Proceeds,
+		GainOrLoss: in.Proceeds - asset.BookValue,
+		RecordedBy: user.UserID,
+		Notes:      in.Notes,
+	}
+	asset.BookValue = 0
+	asset.Status = StatusDisposed
+	asset.DisposedAt = &date
+
+	if err := s.assets.AddEvent(ctx, asset, event); err != nil {
+		return nil, fmt.Errorf("disposing of asset: %w", err)
+	}
+	return asset, nil
+}
+
+// changeable loads an active asset for a new event dated date (today when
+// nil), which may not come before the asset's latest event
+func (s *assetService) changeable(ctx context.Context, user *data.User, assetID string, date *time.Time) (*data.Asset, time.Time, error) {
+	asset, err := s.Get(ctx, user, assetID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if asset.Status != StatusActive {
+		return nil, time.Time{}, service.Conflict("asset has been disposed of")
+	}
+
+	when := time.Now()
+	if date != nil {
+		when = *date
+	}
+	if n := len(asset.Events); n > 0 && when.Before(asset.Events[n-1].Date) {
+		return nil, time.Time{}, service.Invalid(fmt.Sprintf("date must not be before the asset's last %s on %s",
+			asset.Events[n-1].Type, asset.Events[n-1].Date.Format("2006-01-02")))
+	}
+	return asset, when, nil
+}
+
+// BalanceSheet values a farm's assets at the end of day asOf, replaying each
+// asset's events so later revaluations and disposals are left out
+func (s *assetService) BalanceSheet(ctx context.Context, user *data.User, farmID string, asOf time.Time) (*BalanceSheet, error) {
+	f, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleReports, farm.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := s.assets.GetByFarmID(ctx, farmID, "")
+	if err != nil {
+		return nil, fmt.Errorf("getting assets: %w", err)
+	}
+
+	end := asOf.AddDate(0, 0, 1)
+	sheet := &BalanceSheet{
+		FarmID:      farmID,
+		FarmName:    f.Name,
+		AsOf:        asOf,
+		Assets:      []CategoryValue{},
+		GeneratedAt: time.Now(),
+	}
+	for _, asset := range assets {
+		value, held := asset.ValueAt(end.Add(-time.Nanosecond))
+		if !held {
+			continue
+		}
+		i := slices.IndexFunc(sheet.Assets, func(c CategoryValue) bool { return c.Category == asset.Category })
+		if i < 0 {
+			sheet.Assets = append(sheet.Assets, CategoryValue{Category: asset.Category})
+			i = len(sheet.Assets) - 1
+		}
+		sheet.Assets[i].Count++
+		sheet.Assets[i].Cost += asset.AcquisitionCost
+		sheet.Assets[i].BookValue += value
+		sheet.TotalCost += asset.AcquisitionCost
+		sheet.TotalBookValue += value
+	}
+
+	totals, err := s.transactions.TotalsByCategory(ctx, farmID, nil, &end)
+	if err != nil {
+		return nil, fmt.Errorf("getting transaction totals: %w", err)
+	}
+	for _, t := range totals {
+		if t.Type == "Income" {
+			sheet.NetLedger += t.Total
+		} else {
+			sheet.NetLedger -= t.Total
+		}
+	}
+	return sheet, nil
+}
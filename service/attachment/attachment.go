@@ -0,0 +1,370 @@
+// Package attachment keeps the photos and documents attached to a farm's
+// records, such as pictures of disease symptoms on a crop or herd, the
+// receipt for a repair or the scan of a land title. Where the storage backend supports it, files are
+// uploaded and downloaded with presigned URLs straight to and from object
+// storage; otherwise they pass through the API.
+package attachment
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/storage"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Attachment statuses
+const (
+	StatusPending  = "Pending"
+	StatusUploaded = "Uploaded"
+)
+
+// Record types files can be attached to
+const (
+	RecordCrop        = "crop"
+	RecordLivestock   = "livestock"
+	RecordEquipment   = "equipment"
+	RecordMaintenance = "maintenance"
+	RecordTransaction = "transaction"
+	RecordDocument    = "document"
+	RecordIncident    = "incident"
+)
+
+// RecordTypes lists the record types files can be attached to
+var RecordTypes = []string{RecordCrop, RecordLivestock, RecordEquipment, RecordMaintenance, RecordTransaction, RecordDocument, RecordIncident}
+
+// ContentTypes lists the file types that can be attached
+var ContentTypes = []string{"image/jpeg", "image/png", "image/webp", "image/heic", "application/pdf"}
+
+// MaxSize is the largest file that can be attached, in bytes
+const MaxSize = 20 << 20
+
+// URLExpiry is how long a presigned upload or download URL is valid
+const URLExpiry = 15 * time.Minute
+
+// Input describes a file to attach to a record
+type Input struct {
+	RecordType  string
+	RecordID    string
+	FileName    string
+	ContentType string
+	Size        int64 // Bytes
+	Caption     string
+}
+
+// Transfer is where to upload or download an attachment's file: a presigned
+// URL straight to object storage, or the API's own endpoint, which needs the
+// usual Authorization header and does not expire
+type Transfer struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"` // To send with an upload
+	ExpiresAt *time.Time        `json:"expiresAt,omitempty"`
+}
+
+// Service is the attachment domain service
+type Service interface {
+	// Create records a file to attach to a record, pending its upload. The
+	// transfer is nil when the backend cannot presign URLs; the file is then
+	// sent with Upload.
+	Create(ctx context.Context, user *data.User, in Input) (*data.Attachment, *Transfer, error)
+	// Upload stores a pending attachment's file sent through the API
+	Upload(ctx context.Context, user *data.User, attachmentID string, r io.Reader, size int64) (*data.Attachment, error)
+	// Complete marks a pending attachment uploaded once its file has been
+	// sent to a presigned URL
+	Complete(ctx context.Context, user *data.User, attachmentID string) (*data.Attachment, error)
+	// Get returns an attachment with a presigned download URL, or a nil
+	// transfer when the backend cannot presign URLs or it is not uploaded
+	Get(ctx context.Context, user *data.User, attachmentID string) (*data.Attachment, *Transfer, error)
+	// List returns the attachments of a record
+	List(ctx context.Context, user *data.User, recordType, recordID string) ([]*data.Attachment, error)
+	// ListFarm returns the attachments of a farm, newest first
+	ListFarm(ctx context.Context, user *data.User, farmID string) ([]*data.Attachment, error)
+	// Open returns an uploaded attachment's file. The caller must close it.
+	Open(ctx context.Context, user *data.User, attachmentID string) (io.ReadCloser, *data.Attachment, error)
+	// Delete removes an attachment and its file
+	Delete(ctx context.Context, user *data.User, attachmentID string) error
+}
+
+// attachmentService implements Service on top of the attachment repository
+// and object storage
+type attachmentService struct {
+	attachments data.AttachmentInterface
+	files       storage.Storage
+	records     map[string]func(ctx context.Context, id string) (farmID string, err error)
+	farms       farm.Service
+}
+
+// New creates the attachment service
+func New(attachments data.AttachmentInterface, files storage.Storage, crops data.CropInterface, livestock data.LivestockInterface,
+	equipment data.EquipmentInterface, maintenance data.MaintenanceRecordInterface, transactions data.TransactionInterface,
+	documents data.DocumentInterface, incidents data.CropIncidentInterface, farms farm.Service) Service {
+	return &attachmentService{
+		attachments: attachments,
+		files:       files,
+		farms:       farms,
+		records: map[string]func(context.Context, string) (string, error){
+			RecordCrop: func(ctx context.Context, id string) (string, error) {
+				c, err := crops.GetByCropID(ctx, id)
+				if c == nil || err != nil {
+					return "", err
+				}
+				return c.FarmID, nil
+			},
+			RecordLivestock: func(ctx context.Context, id string) (string, error) {
+				l, err := livestock.GetByLivestockID(ctx, id)
+				if l == nil || err != nil {
+					return "", err
+				}
+				return l.FarmID, nil
+			},
+			RecordEquipment: func(ctx context.Context, id string) (string, error) {
+				e, err := equipment.GetByEquipmentID(ctx, id)
+				if e == nil || err != nil {
+					return "", err
+				}
+				return e.FarmID, nil
+			},
+			RecordMaintenance: func(ctx context.Context, id string) (string, error) {
+				m, err := maintenance.GetByMaintenanceRecordID(ctx, id)
+				if m == nil || err != nil {
+					return "", err
+				}
+				return m.FarmID, nil
+			},
+			RecordTransaction: func(ctx context.Context, id string) (string, error) {
+				t, err := transactions.GetByTransactionID(ctx, id)
+				if t == nil || err != nil {
+					return "", err
+				}
+				return t.FarmID, nil
+			},
+			RecordDocument: func(ctx context.Context, id string) (string, error) {
+				d, err := documents.GetByDocumentID(ctx, id)
+				if d == nil || err != nil {
+					return "", err
+				}
+				return d.FarmID, nil
+			},
+			RecordIncident: func(ctx context.Context, id string) (string, error) {
+				i, err := incidents.GetByCropIncidentID(ctx, id)
+				if i == nil || err != nil {
+					return "", err
+				}
+				return i.FarmID, nil
+			},
+		},
+	}
+}
+
+// record returns the farm of a record on one of the user's farms
+func (s *attachmentService) record(ctx context.Context, user *data.User, recordType, recordID string) (string, error) {
+	lookup, ok := s.records[recordType]
+	if !ok {
+		return "", service.Invalid("recordType must be one of " + strings.Join(RecordTypes, ", "))
+	}
+	farmID, err := lookup(ctx, recordID)
+	if err != nil {
+		return "", fmt.Errorf("getting %s: %w", recordType, err)
+	}
+	if farmID == "" {
+		return "", service.NotFound(recordType + " not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, farmID, recordType); err != nil {
+		return "", err
+	}
+	return farmID, nil
+}
+
+// Create implements Service
+func (s *attachmentService) Create(ctx context.Context, user *data.User, in Input) (*data.Attachment, *Transfer, error) {
+	farmID, err := s.record(ctx, user, in.RecordType, in.RecordID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !slices.Contains(ContentTypes, in.ContentType) {
+		return nil, nil, service.Invalid("contentType must be one of " + strings.Join(ContentTypes, ", "))
+	}
+	if in.Size > MaxSize {
+		return nil, nil, service.Invalid(fmt.Sprintf("files can be at most %d MB", MaxSize>>20))
+	}
+
+	attachment := &data.Attachment{
+		FarmID:      farmID,
+		UserID:      user.UserID,
+		RecordType:  in.RecordType,
+		RecordID:    in.RecordID,
+		FileName:    in.FileName,
+		ContentType: in.ContentType,
+		Size:        in.Size,
+		Caption:     in.Caption,
+		Status:      StatusPending,
+	}
+	if err := s.attachments.Insert(ctx, attachment); err != nil {
+		return nil, nil, fmt.Errorf("creating attachment: %w", err)
+	}
+
+	presigner, ok := s.files.(storage.Presigner)
+	if !ok {
+		return attachment, nil, nil
+	}
+	url, err := presigner.PresignPut(ctx, attachment.FileKey(), URLExpiry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("presigning upload: %w", err)
+	}
+	expires := time.Now().Add(URLExpiry)
+	return attachment, &Transfer{
+		URL:       url,
+		Method:    "PUT",
+		Headers:   map[string]string{"Content-Type": attachment.ContentType},
+		ExpiresAt: &expires,
+	}, nil
+}
+
+// get returns an attachment on one of the user's farms
+func (s *attachmentService) get(ctx context.Context, user *data.User, attachmentID string) (*data.Attachment, error) {
+	attachment, err := s.attachments.GetByAttachmentID(ctx, attachmentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting attachment: %w", err)
+	}
+	if attachment == nil {
+		return nil, service.NotFound("attachment not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, attachment.FarmID, "attachment"); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// Upload implements Service. Uploading again replaces the file.
+func (s *attachmentService) Upload(ctx context.Context, user *data.User, attachmentID string, r io.Reader, size int64) (*data.Attachment, error) {
+	attachment, err := s.get(ctx, user, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxSize {
+		return nil, service.Invalid(fmt.Sprintf("files can be at most %d MB", MaxSize>>20))
+	}
+
+	if err := s.files.Put(ctx, attachment.FileKey(), r, size, attachment.ContentType); err != nil {
+		return nil, fmt.Errorf("storing attachment file: %w", err)
+	}
+	return s.uploaded(ctx, attachment)
+}
+
+// Complete implements Service
+func (s *attachmentService) Complete(ctx context.Context, user *data.User, attachmentID string) (*data.Attachment, error) {
+	attachment, err := s.get(ctx, user, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.uploaded(ctx, attachment)
+}
+
+// uploaded marks an attachment uploaded with the size of its stored file.
+// A file over MaxSize, which a presigned URL cannot prevent, is removed.
+func (s *attachmentService) uploaded(ctx context.Context, attachment *data.Attachment) (*data.Attachment, error) {
+	info, err := s.files.Stat(ctx, attachment.FileKey())
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, service.Conflict("the file has not been uploaded")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("checking attachment file: %w", err)
+	}
+	if info.Size > MaxSize {
+		if err := s.files.Delete(ctx, attachment.FileKey()); err != nil {
+			return nil, fmt.Errorf("removing oversized attachment file: %w", err)
+		}
+		return nil, service.Invalid(fmt.Sprintf("files can be at most %d MB", MaxSize>>20))
+	}
+
+	now := time.Now()
+	attachment.Size = info.Size
+	attachment.Status = StatusUploaded
+	attachment.UploadedAt = &now
+	if err := s.attachments.Update(ctx, attachment); err != nil {
+		return nil, fmt.Errorf("updating attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// Get implements Service
+func (s *attachmentService) Get(ctx context.Context, user *data.User, attachmentID string) (*data.Attachment, *Transfer, error) {
+	attachment, err := s.get(ctx, user, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	presigner, ok := s.files.(storage.Presigner)
+	if !ok || attachment.Status != StatusUploaded {
+		return attachment, nil, nil
+	}
+	url, err := presigner.PresignGet(ctx, attachment.FileKey(), attachment.FileName, URLExpiry)
+	if err != nil {
+		return nil, nil, fmt.Errorf("presigning download: %w", err)
+	}
+	expires := time.Now().Add(URLExpiry)
+	return attachment, &Transfer{URL: url, Method: "GET", ExpiresAt: &expires}, nil
+}
+
+// List implements Service
+func (s *attachmentService) List(ctx context.Context, user *data.User, recordType, recordID string) ([]*data.Attachment, error) {
+	if _, err := s.record(ctx, user, recordType, recordID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.attachments.GetByRecord(ctx, recordType, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// ListFarm implements Service
+func (s *attachmentService) ListFarm(ctx context.Context, user *data.User, farmID string) ([]*data.Attachment, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	attachments, err := s.attachments.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// Open implements Service
+func (s *attachmentService) Open(ctx context.Context, user *data.User, attachmentID string) (io.ReadCloser, *data.Attachment, error) {
+	attachment, err := s.get(ctx, user, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attachment.Status != StatusUploaded {
+		return nil, nil, service.Conflict("the file has not been uploaded")
+	}
+
+	file, _, err := s.files.Get(ctx, attachment.FileKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening attachment file: %w", err)
+	}
+	return file, attachment, nil
+}
+
+// Delete implements Service. A pending attachment may have a file from an
+// unfinished upload, so its file is removed too.
+func (s *attachmentService) Delete(ctx context.Context, user *data.User, attachmentID string) error {
+	attachment, err := s.get(ctx, user, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.files.Delete(ctx, attachment.FileKey()); err != nil {
+		return fmt.Errorf("deleting attachment file: %w", err)
+	}
+	if err := s.attachments.DeleteByID(ctx, int(attachment.ID)); err != nil {
+		return fmt.Errorf("deleting attachment: %w", err)
+	}
+	return nil
+}
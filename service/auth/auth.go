@@ -0,0 +1,251 @@
+// Package auth manages user accounts, credentials and password resets. Token
+// issuing stays with the HTTP layer, which owns the signing keys.
+package auth
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"time"
+)
+
+// DefaultRole is given to users who sign up without choosing a role
+const DefaultRole = "Farmer"
+
+// BuyerRole is for accounts that buy produce from farmers rather than run farms
+const BuyerRole = "Buyer"
+
+// AdminRole is for platform staff; it is granted through the admin API only
+const AdminRole = "Admin"
+
+// SignupInput holds the fields of a new account
+type SignupInput struct {
+	FirstName   string
+	LastName    string
+	Email       string
+	Password    string
+	Role        string
+	PhoneNumber string
+	Address     string
+}
+
+// Service is the auth domain service
+type Service interface {
+	// Signup creates an active account
+	Signup(ctx context.Context, in SignupInput) (*data.User, error)
+	// Authenticate returns the active user with the given credentials
+	Authenticate(ctx context.Context, email, password string) (*data.User, error)
+	// CurrentUser returns the active user behind an authenticated request
+	CurrentUser(ctx context.Context, email string) (*data.User, error)
+	// Refresh returns the active user a token is being refreshed for
+	Refresh(ctx context.Context, id int) (*data.User, error)
+	// RequestPasswordReset issues a reset code. The user is nil, without an
+	// error, when no account has the email.
+	RequestPasswordReset(ctx context.Context, email string) (*data.User, string, error)
+	// ResetPassword sets a new password using a reset code
+	ResetPassword(ctx context.Context, email, otp, newPassword string) error
+	// RequestPhoneLogin issues a code to sign in with by SMS. The user is nil,
+	// without an error, when no single active account has the phone number.
+	RequestPhoneLogin(ctx context.Context, phone string) (*data.User, string, error)
+	// VerifyPhoneLogin returns the user a phone login code was issued to
+	VerifyPhoneLogin(ctx context.Context, phone, code string) (*data.User, error)
+	// ChangePassword sets a new password for a user who knows their current one
+	ChangePassword(ctx context.Context, user *data.User, currentPassword, newPassword string) error
+	// Revoke invalidates the user's token with the given ID before it
+	// expires, as on logout
+	Revoke(ctx context.Context, user *data.User, tokenID string, expiresAt time.Time) error
+	// Revoked reports whether the token with the given ID has been revoked
+	Revoked(ctx context.Context, tokenID string) (bool, error)
+	// PurgeExpired forgets revoked tokens and phone logins that have since
+	// expired and returns how many there were
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+// authService implements Service on top of the user repository
+type authService struct {
+	users       data.UserInterface
+	revoked     data.RevokedTokenInterface
+	phoneLogins data.PhoneLoginInterface
+}
+
+// New creates the auth service
+func New(users data.UserInterface, revoked data.RevokedTokenInterface, phoneLogins data.PhoneLoginInterface) Service {
+	return &authService{users: users, revoked: revoked, phoneLogins: phoneLogins}
+}
+
+// Signup creates an active account, defaulting the role to DefaultRole
+func (s *authService) Signup(ctx context.Context, in SignupInput) (*data.User, error) {
+	existing, err := s.users.GetByEmail(ctx, in.Email)
+	if err != nil {
+		return nil, fmt.Errorf("checking existing user: %w", err)
+	}
+	if existing != nil {
+		return nil, service.Conflict("user with this email already exists")
+	}
+
+	if in.Role == "" {
+		in.Role = DefaultRole
+	}
+
+	user := &data.User{
+		FirstName:    in.FirstName,
+		LastName:     in.LastName,
+		Email:        in.Email,
+		TempPassword: in.Password,
+		Role:         in.Role,
+		PhoneNumber:  in.PhoneNumber,
+		Address:      in.Address,
+		Active:       true,
+	}
+
+	// Insert hashes the password
+	if err := s.users.Insert(ctx, user); err != nil {
+		return nil, fmt.Errorf("creating user: %w", err)
+	}
+	user.Password = ""
+	user.TempPassword = ""
+	return user, nil
+}
+
+// Authenticate returns the active user with the given credentials
+func (s *authService) Authenticate(ctx context.Context, email, password string) (*data.User, error) {
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("getting user by email: %w", err)
+	}
+	if user == nil {
+		return nil, service.Unauthorized("invalid email or password")
+	}
+	if !user.Active {
+		return nil, service.Unauthorized("account is deactivated")
+	}
+
+	matches, err := s.users.PasswordMatches(ctx, user, password)
+	if err != nil {
+		return nil, fmt.Errorf("checking password: %w", err)
+	}
+	if !matches {
+		return nil, service.Unauthorized("invalid email or password")
+	}
+
+	user.Password = ""
+	user.TempPassword = ""
+	return user, nil
+}
+
+// CurrentUser returns the active user behind an authenticated request
+func (s *authService) CurrentUser(ctx context.Context, email string) (*data.User, error) {
+	if email == "" {
+		return nil, service.Unauthorized("user not authenticated")
+	}
+
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("getting user by email: %w", err)
+	}
+	if user == nil {
+		return nil, service.NotFound("user not found")
+	}
+	if !user.Active {
+		return nil, service.Forbidden("account is deactivated")
+	}
+	return user, nil
+}
+
+// Refresh returns the active user a token is being refreshed for
+func (s *authService) Refresh(ctx context.Context, id int) (*data.User, error) {
+	user, err := s.users.GetOne(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("getting user by ID: %w", err)
+	}
+	if user == nil || !user.Active {
+		return nil, service.Unauthorized("user not found or inactive")
+	}
+	return user, nil
+}
+
+// RequestPasswordReset issues a reset code for the account with email
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) (*data.User, string, error) {
+	user, err := s.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting user by email: %w", err)
+	}
+	if user == nil {
+		return nil, "", nil
+	}
+
+	otp, err := s.users.GenerateAndSaveOTP(ctx, email)
+	if err != nil {
+		return nil, "", fmt.Errorf("generating OTP: %w", err)
+	}
+	return user, otp, nil
+}
+
+// ResetPassword sets a new password using a reset code
+func (s *authService) ResetPassword(ctx context.Context, email, otp, newPassword string) error {
+	if err := s.users.ResetPasswordWithOTP(ctx, email, otp, newPassword); err != nil {
+		return service.Invalid("invalid or expired reset code")
+	}
+	return nil
+}
+
+// ChangePassword implements Service
+func (s *authService) ChangePassword(ctx context.Context, user *data.User, currentPassword, newPassword string) error {
+	matches, err := s.users.PasswordMatches(ctx, user, currentPassword)
+	if err != nil {
+		return fmt.Errorf("checking password: %w", err)
+	}
+	if !matches {
+		return service.Invalid("current password is incorrect")
+	}
+	if currentPassword == newPassword {
+		return service.Invalid("new password must differ from the current one")
+	}
+	if err := s.users.ResetPassword(ctx, newPassword, *user); err != nil {
+		return fmt.Errorf("changing password: %w", err)
+	}
+	return nil
+}
+
+// Revoke adds a token to the denylist until it expires. Tokens issued
+// before they carried an ID cannot be revoked and are left to expire.
+func (s *authService) Revoke(ctx context.Context, user *data.User, tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return service.Invalid("this token cannot be revoked; it expires at " + expiresAt.Format(time.RFC3339))
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil
+	}
+	err := s.revoked.Insert(ctx, &data.RevokedToken{TokenID: tokenID, UserID: user.UserID, ExpiresAt: expiresAt})
+	if err != nil {
+		return fmt.Errorf("revoking token: %w", err)
+	}
+	return nil
+}
+
+// Revoked implements Service
+func (s *authService) Revoked(ctx context.Context, tokenID string) (bool, error) {
+	if tokenID == "" {
+		return false, nil
+	}
+	revoked, err := s.revoked.IsRevoked(ctx, tokenID)
+	if err != nil {
+		return false, fmt.Errorf("checking token denylist: %w", err)
+	}
+	return revoked, nil
+}
+
+// PurgeExpired implements Service
+func (s *authService) PurgeExpired(ctx context.Context) (int64, error) {
+	now := time.Now()
+	tokens, err := s.revoked.DeleteExpired(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("purging revoked tokens: %w", err)
+	}
+	logins, err := s.phoneLogins.DeleteExpired(ctx, now)
+	if err != nil {
+		return tokens, fmt.Errorf("purging phone logins: %w", err)
+	}
+	return tokens + logins, nil
+}
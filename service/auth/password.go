@@ -0,0 +1,83 @@
+package auth
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultMinPasswordLength is the shortest password accepted unless the
+// policy says otherwise
+const DefaultMinPasswordLength = 8
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// PasswordPolicy is the rules new passwords must meet
+type PasswordPolicy struct {
+	MinLength int
+	// RequireComplexity asks for upper and lower case letters and a digit
+	RequireComplexity bool
+	// banned holds the refused passwords, lower-cased
+	banned map[string]bool
+}
+
+// NewPasswordPolicy creates a policy refusing the most common passwords and
+// any in extraBanned
+func NewPasswordPolicy(minLength int, requireComplexity bool, extraBanned []string) PasswordPolicy {
+	policy := PasswordPolicy{MinLength: minLength, RequireComplexity: requireComplexity, banned: map[string]bool{}}
+	for _, list := range [][]string{strings.Split(commonPasswords, "\n"), extraBanned} {
+		for _, password := range list {
+			password = strings.TrimSpace(password)
+			if password != "" && !strings.HasPrefix(password, "#") {
+				policy.banned[strings.ToLower(password)] = true
+			}
+		}
+	}
+	return policy
+}
+
+// Problems lists what is wrong with password, or nothing if it meets the
+// policy. personal holds details of the account, such as its email and
+// names, that the password must not be.
+func (p PasswordPolicy) Problems(password string, personal ...string) []string {
+	var problems []string
+	if len([]rune(password)) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	if p.RequireComplexity {
+		var upper, lower, digit bool
+		for _, r := range password {
+			switch {
+			case unicode.IsUpper(r):
+				upper = true
+			case unicode.IsLower(r):
+				lower = true
+			case unicode.IsDigit(r):
+				digit = true
+			}
+		}
+		if !upper || !lower {
+			problems = append(problems, "must contain upper and lower case letters")
+		}
+		if !digit {
+			problems = append(problems, "must contain a digit")
+		}
+	}
+
+	lower := strings.ToLower(password)
+	if p.banned[lower] {
+		problems = append(problems, "is too common")
+	}
+	for _, detail := range personal {
+		detail = strings.ToLower(strings.TrimSpace(detail))
+		local, _, _ := strings.Cut(detail, "@")
+		if detail != "" && (lower == detail || lower == local) {
+			problems = append(problems, "must not be your email or name")
+			break
+		}
+	}
+	return problems
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// phoneNumber matches a normalized phone number: up to 15 digits, as in
+// E.164, with an optional leading +
+var phoneNumber = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+// NormalizePhone strips the spaces and punctuation a phone number is written
+// with, e.g. "+256 (772) 123-456" to "+256772123456". ok is false when what
+// is left is not a phone number.
+func NormalizePhone(phone string) (normalized string, ok bool) {
+	normalized = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+	return normalized, phoneNumber.MatchString(normalized)
+}
+
+// RequestPhoneLogin starts a passwordless login for the account with the
+// phone number and returns the code to send it. The user is nil, without an
+// error, when no single active account has the number.
+func (s *authService) RequestPhoneLogin(ctx context.Context, phone string) (*data.User, string, error) {
+	normalized, ok := NormalizePhone(phone)
+	if !ok {
+		return nil, "", service.Invalid("invalid phone number")
+	}
+
+	users, err := s.users.GetByPhoneNumber(ctx, normalized)
+	if err != nil {
+		return nil, "", fmt.Errorf("getting users by phone number: %w", err)
+	}
+	// A number shared by several accounts cannot say which to sign in to
+	if len(users) != 1 || !users[0].Active {
+		return nil, "", nil
+	}
+
+	code, err := s.phoneLogins.Issue(ctx, normalized, users[0].UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("issuing phone login: %w", err)
+	}
+	return users[0], code, nil
+}
+
+// VerifyPhoneLogin returns the active user a phone login code was sent to,
+// using the code up
+func (s *authService) VerifyPhoneLogin(ctx context.Context, phone, code string) (*data.User, error) {
+	normalized, ok := NormalizePhone(phone)
+	if !ok {
+		return nil, service.Invalid("invalid phone number")
+	}
+
+	login, err := s.phoneLogins.Verify(ctx, normalized, code)
+	if err != nil {
+		return nil, fmt.Errorf("verifying phone login: %w", err)
+	}
+	if login == nil {
+		return nil, service.Unauthorized("invalid or expired code")
+	}
+
+	user, err := s.users.GetByUserID(ctx, login.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+	if user == nil || !user.Active {
+		return nil, service.Unauthorized("account not found or deactivated")
+	}
+	return user, nil
+}
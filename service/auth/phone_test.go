@@ -0,0 +1,30 @@
+package auth
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+		ok    bool
+	}{
+		{phone: "+256772123456", want: "+256772123456", ok: true},
+		{phone: "+256 (772) 123-456", want: "+256772123456", ok: true},
+		{phone: "  0772.123.456 ", want: "0772123456", ok: true},
+		{phone: "256772123456", want: "256772123456", ok: true},
+		{phone: "1234567", want: "1234567", ok: true},
+		{phone: "123456", want: "123456", ok: false},
+		{phone: "+1234567890123456", want: "+1234567890123456", ok: false},
+		{phone: "0772 12a 456", want: "077212a456", ok: false},
+		{phone: "++256772123456", want: "++256772123456", ok: false},
+		{phone: "256+772123456", want: "256+772123456", ok: false},
+		{phone: "", want: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizePhone(tt.phone)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizePhone(%q) = %q, %v; want %q, %v", tt.phone, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+// Package breeding records the reproduction cycle of a farm's livestock:
+// dams served, the outcome of each service and the births that follow.
+// Expected due dates default to the species' gestation period, so farmers
+// can see which dams are about to calve, kid or lamb.
+package breeding
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/livestock"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Breeding methods
+const (
+	MethodNatural = "Natural"
+	MethodAI      = "Artificial Insemination"
+	MethodEmbryo  = "Embryo Transfer"
+)
+
+// Breeding outcomes. Birthed is only set by recording the birth.
+const (
+	OutcomePending  = "Pending"
+	OutcomePregnant = "Pregnant"
+	OutcomeOpen     = "Open"
+	OutcomeAborted  = "Aborted"
+	OutcomeBirthed  = "Birthed"
+)
+
+// Methods returns the breeding methods
+func Methods() []string {
+	return []string{MethodNatural, MethodAI, MethodEmbryo}
+}
+
+// Outcomes returns the outcomes a breeding event can be given directly;
+// Birthed is not among them
+func Outcomes() []string {
+	return []string{OutcomePending, OutcomePregnant, OutcomeOpen, OutcomeAborted}
+}
+
+// gestationDays is the average gestation of each species, by the singular,
+// lower-case livestock type
+var gestationDays = map[string]int{
+	"cattle": 283,
+	"cow":    283,
+	"goat":   150,
+	"sheep":  147,
+	"pig":    114,
+	"rabbit": 31,
+	"horse":  340,
+}
+
+// GestationDays returns the average gestation of a livestock type, or zero
+// when it is not known
+func GestationDays(livestockType string) int {
+	key := strings.ToLower(strings.TrimSpace(livestockType))
+	if days, ok := gestationDays[key]; ok {
+		return days
+	}
+	return gestationDays[strings.TrimSuffix(key, "s")]
+}
+
+// EventInput holds the editable breeding event fields. On update, zero
+// values are left unchanged. ExpectedDueDate defaults to ServiceDate plus
+// the species' gestation period.
+type EventInput struct {
+	LivestockID     string
+	DamTag          string
+	SireTag         string
+	ServiceDate     *time.Time
+	Method          string
+	ExpectedDueDate *time.Time
+	Outcome         string
+	Notes           string
+}
+
+// BirthInput records a birth. BreedingEventID names the service the birth
+// came from, if it was recorded; the herd and dam are then taken from it.
+type BirthInput struct {
+	BreedingEventID string
+	LivestockID     string
+	DamTag          string
+	BirthDate       *time.Time // Defaults to now
+	LiveBorn        int
+	Stillborn       int
+	Male            int
+	Female          int
+	Assisted        bool
+	Notes           string
+}
+
+// Service is the breeding domain service
+type Service interface {
+	CreateEvent(ctx context.Context, user *data.User, farmID string, in EventInput) (*data.BreedingEvent, error)
+	GetEvent(ctx context.Context, user *data.User, breedingEventID string) (*data.BreedingEvent, error)
+	ListEvents(ctx context.Context, user *data.User, farmID string, filter data.BreedingEventFilter) ([]*data.BreedingEvent, error)
+	UpdateEvent(ctx context.Context, user *data.User, breedingEventID string, in EventInput) (*data.BreedingEvent, error)
+	// DeleteEvent soft deletes a breeding event no birth has been recorded for
+	DeleteEvent(ctx context.Context, user *data.User, breedingEventID string) error
+	// Due lists a farm's dams awaiting a birth that are due within the given
+	// number of days, including overdue ones
+	Due(ctx context.Context, user *data.User, farmID string, days int) ([]*data.BreedingEvent, error)
+
+	// RecordBirth records a birth, closing its breeding event and adding the
+	// live-born young to the dam's herd
+	RecordBirth(ctx context.Context, user *data.User, farmID string, in BirthInput) (*data.BirthRecord, error)
+	GetBirth(ctx context.Context, user *data.User, birthRecordID string) (*data.BirthRecord, error)
+	ListBirths(ctx context.Context, user *data.User, farmID, livestockID string) ([]*data.BirthRecord, error)
+}
+
+// breedingService implements Service on top of the breeding event, birth
+// record and livestock repositories
+type breedingService struct {
+	models    data.Models
+	events    data.BreedingEventInterface
+	births    data.BirthRecordInterface
+	livestock data.LivestockInterface
+	farms     farm.Service
+}
+
+// New creates the breeding service
+func New(models data.Models, farms farm.Service) Service {
+	return &breedingService{models: models, events: models.BreedingEvent, births: models.BirthRecord,
+		livestock: models.Livestock, farms: farms}
+}
+
+// inTransaction runs fn on a copy of the service whose repositories share one
+// transaction, so a birth, its event and the herd count change together
+func (s *breedingService) inTransaction(ctx context.Context, fn func(tx *breedingService) error) error {
+	return s.models.WithTransaction(ctx, func(models data.Models) error {
+		return fn(&breedingService{models: models, events: models.BreedingEvent, births: models.BirthRecord,
+			livestock: models.Livestock, farms: s.farms})
+	})
+}
+
+// CreateEvent records a dam being served on one of the user's farms
+func (s *breedingService) CreateEvent(ctx context.Context, user *data.User, farmID string, in EventInput) (*data.BreedingEvent, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	herd, err := livestock.OnFarm(ctx, s.livestock, farmID, in.LivestockID)
+	if err != nil {
+		return nil, err
+	}
+	if in.ServiceDate == nil {
+		return nil, service.Invalid("service date is required")
+	}
+	if in.Outcome == OutcomeBirthed {
+		return nil, service.Invalid("record the birth instead of setting the outcome to Birthed")
+	}
+
+	event := &data.BreedingEvent{
+		FarmID:      farmID,
+		LivestockID: herd.LivestockID,
+		DamTag:      strings.TrimSpace(in.DamTag),
+		SireTag:     strings.TrimSpace(in.SireTag),
+		ServiceDate: *in.ServiceDate,
+		Method:      in.Method,
+		Outcome:     in.Outcome,
+		Notes:       in.Notes,
+	}
+	if event.Method == "" {
+		event.Method = MethodNatural
+	}
+	if event.Outcome == "" {
+		event.Outcome = OutcomePending
+	}
+	if err := setDueDate(event, herd, in.ExpectedDueDate); err != nil {
+		return nil, err
+	}
+
+	if err := s.events.Insert(ctx, event); err != nil {
+		return nil, fmt.Errorf("creating breeding event: %w", err)
+	}
+	event.Livestock = herd
+	return event, nil
+}
+
+// GetEvent returns a breeding event on one of the user's farms
+func (s *breedingService) GetEvent(ctx context.Context, user *data.User, breedingEventID string) (*data.BreedingEvent, error) {
+	event, err := s.events.GetByBreedingEventID(ctx, breedingEventID)
+	if err != nil {
+		return nil, fmt.Errorf("getting breeding event: %w", err)
+	}
+	if event == nil {
+		return nil, service.NotFound("breeding event not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, event.FarmID, "breeding event"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// ListEvents returns the breeding events of one of the user's farms
+func (s *breedingService) ListEvents(ctx context.Context, user *data.User, farmID string, filter data.BreedingEventFilter) ([]*data.BreedingEvent, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	events, err := s.events.GetByFarmID(ctx, farmID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("getting breeding events: %w", err)
+	}
+	return events, nil
+}
+
+// UpdateEvent changes the non-zero fields of in on a breeding event, as when
+// a pregnancy check confirms or rules out the service. Moving the service
+// date moves a defaulted due date with it.
+func (s *breedingService) UpdateEvent(ctx context.Context, user *data.User, breedingEventID string, in EventInput) (*data.BreedingEvent, error) {
+	event, err := s.GetEvent(ctx, user, breedingEventID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Outcome == OutcomeBirthed {
+		return nil, service.Conflict("the birth has been recorded for this breeding event")
+	}
+	if in.Outcome == OutcomeBirthed {
+		return nil, service.Invalid("record the birth instead of setting the outcome to Birthed")
+	}
+
+	herd := event.Livestock
+	if in.LivestockID != "" && in.LivestockID != event.LivestockID {
+		if herd, err = livestock.OnFarm(ctx, s.livestock, event.FarmID, in.LivestockID); err != nil {
+			return nil, err
+		}
+		event.LivestockID = herd.LivestockID
+	}
+	if in.DamTag != "" {
+		event.DamTag = strings.TrimSpace(in.DamTag)
+	}
+	if in.SireTag != "" {
+		event.SireTag = strings.TrimSpace(in.SireTag)
+	}
+	if in.Method != "" {
+		event.Method = in.Method
+	}
+	if in.Outcome != "" {
+		event.Outcome = in.Outcome
+	}
+	if in.Notes != "" {
+		event.Notes = in.Notes
+	}
+	if in.ServiceDate != nil || in.ExpectedDueDate != nil {
+		defaulted := herd != nil && event.ExpectedDueDate.Equal(dueDate(event.ServiceDate, herd))
+		if in.ServiceDate != nil {
+			event.ServiceDate = *in.ServiceDate
+		}
+		due := in.ExpectedDueDate
+		if due == nil && !defaulted {
+			due = &event.ExpectedDueDate
+		}
+		if err := setDueDate(event, herd, due); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.events.Update(ctx, event); err != nil {
+		return nil, fmt.Errorf("updating breeding event: %w", err)
+	}
+	event.Livestock = herd
+	return event, nil
+}
+
+// DeleteEvent soft deletes a breeding event. Once its birth is recorded the
+// event is part of the herd's history and is kept.
+func (s *breedingService) DeleteEvent(ctx context.Context, user *data.User, breedingEventID string) error {
+	event, err := s.GetEvent(ctx, user, breedingEventID)
+	if err != nil {
+		return err
+	}
+	if event.Birth != nil {
+		return service.Conflict("the birth has been recorded for this breeding event")
+	}
+	if err := s.events.DeleteByID(ctx, int(event.ID)); err != nil {
+		return fmt.Errorf("deleting breeding event: %w", err)
+	}
+	return nil
+}
+
+// Due implements Service
+func (s *breedingService) Due(ctx context.Context, user *data.User, farmID string, days int) ([]*data.BreedingEvent, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	events, err := s.events.GetDue(ctx, farmID, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return nil, fmt.Errorf("getting due breeding events: %w", err)
+	}
+	return events, nil
+}
+
+// RecordBirth records a birth on one of the user's farms
+func (s *breedingService) RecordBirth(ctx context.Context, user *data.User, farmID string, in BirthInput) (*data.BirthRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if in.LiveBorn+in.Stillborn <= 0 {
+		return nil, service.Invalid("a birth must have at least one live-born or stillborn young")
+	}
+	if in.Male+in.Female > in.LiveBorn {
+		return nil, service.Invalid("male and female young cannot be more than the live born")
+	}
+
+	birth := &data.BirthRecord{
+		FarmID:    farmID,
+		DamTag:    strings.TrimSpace(in.DamTag),
+		LiveBorn:  in.LiveBorn,
+		Stillborn: in.Stillborn,
+		Male:      in.Male,
+		Female:    in.Female,
+		Assisted:  in.Assisted,
+		Notes:     in.Notes,
+		BirthDate: time.Now(),
+	}
+	if in.BirthDate != nil {
+		birth.BirthDate = *in.BirthDate
+	}
+
+	var event *data.BreedingEvent
+	if in.BreedingEventID != "" {
+		var err error
+		if event, err = s.events.GetByBreedingEventID(ctx, in.BreedingEventID); err != nil {
+			return nil, fmt.Errorf("getting breeding event: %w", err)
+		}
+		if event == nil || event.FarmID != farmID {
+			return nil, service.Invalid("breeding event not found on this farm")
+		}
+		if event.Birth != nil || event.Outcome == OutcomeBirthed {
+			return nil, service.Conflict("the birth has already been recorded for this breeding event")
+		}
+		if birth.BirthDate.Before(event.ServiceDate) {
+			return nil, service.Invalid("birth date cannot be before the service date")
+		}
+		birth.BreedingEventID = &event.BreedingEventID
+		birth.LivestockID = event.LivestockID
+		if birth.DamTag == "" {
+			birth.DamTag = event.DamTag
+		}
+	} else {
+		herd, err := livestock.OnFarm(ctx, s.livestock, farmID, in.LivestockID)
+		if err != nil {
+			return nil, err
+		}
+		birth.LivestockID = herd.LivestockID
+	}
+	if birth.DamTag == "" {
+		return nil, service.Invalid("dam tag is required")
+	}
+
+	err := s.inTransaction(ctx, func(tx *breedingService) error {
+		if err := tx.births.Insert(ctx, birth); err != nil {
+			return fmt.Errorf("recording birth: %w", err)
+		}
+		if event != nil {
+			event.Outcome = OutcomeBirthed
+			if err := tx.events.Update(ctx, event); err != nil {
+				return fmt.Errorf("updating breeding event: %w", err)
+			}
+		}
+		if birth.LiveBorn > 0 {
+			err := tx.livestock.AdjustCount(ctx, birth.LivestockID, birth.LiveBorn)
+			if errors.Is(err, data.ErrStale) {
+				return service.Conflict("the dam's herd has been deleted")
+			}
+			if err != nil {
+				return fmt.Errorf("updating livestock count: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return birth, nil
+}
+
+// GetBirth returns a birth on one of the user's farms
+func (s *breedingService) GetBirth(ctx context.Context, user *data.User, birthRecordID string) (*data.BirthRecord, error) {
+	birth, err := s.births.GetByBirthRecordID(ctx, birthRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting birth: %w", err)
+	}
+	if birth == nil {
+		return nil, service.NotFound("birth not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, birth.FarmID, "birth"); err != nil {
+		return nil, err
+	}
+	return birth, nil
+}
+
+// ListBirths returns the births on one of the user's farms, optionally only
+// those in a herd
+func (s *breedingService) ListBirths(ctx context.Context, user *data.User, farmID, livestockID string) ([]*data.BirthRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	births, err := s.births.GetByFarmID(ctx, farmID, livestockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting births: %w", err)
+	}
+	return births, nil
+}
+
+// dueDate returns when a dam of herd served on serviceDate is due, or the
+// zero time when the species' gestation is not known
+func dueDate(serviceDate time.Time, herd *data.Livestock) time.Time {
+	days := GestationDays(herd.Type)
+	if days == 0 {
+		return time.Time{}
+	}
+	return serviceDate.AddDate(0, 0, days)
+}
+
+// setDueDate sets an event's expected due date to due or, when due is nil,
+// to the default for the herd's species
+func setDueDate(event *data.BreedingEvent, herd *data.Livestock, due *time.Time) error {
+	if due == nil {
+		if herd == nil || GestationDays(herd.Type) == 0 {
+			return service.Invalid("expected due date is required; the gestation period of this livestock type is not known")
+		}
+		event.ExpectedDueDate = dueDate(event.ServiceDate, herd)
+		return nil
+	}
+	if !due.After(event.ServiceDate) {
+		return service.Invalid("expected due date must be after the service date")
+	}
+	event.ExpectedDueDate = *due
+	return nil
+}
@@ -0,0 +1,306 @@
+// Package buyer manages buyer accounts: the business details an admin
+// verifies, and the ratings farmers and buyers leave each other after a sale.
+package buyer
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/auth"
+	"fmt"
+	"time"
+)
+
+// Verification statuses
+const (
+	StatusPending  = "Pending"
+	StatusVerified = "Verified"
+	StatusRejected = "Rejected"
+)
+
+// recentReviews is how many reviews a buyer profile shows
+const recentReviews = 20
+
+// ProfileInput holds the business details a buyer submits for verification
+type ProfileInput struct {
+	BusinessName       string
+	RegistrationNumber string
+	Location           string
+	DocumentURL        string
+}
+
+// RatingInput is a rating of another user after a sale
+type RatingInput struct {
+	RateeID       string
+	SaleReference string
+	Score         int
+	Review        string
+}
+
+// Profile is what farmers see of a buyer. Registration details stay between
+// the buyer and the admins.
+type Profile struct {
+	UserID       string             `json:"userId"`
+	Name         string             `json:"name"`
+	BusinessName string             `json:"businessName"`
+	Location     string             `json:"location"`
+	Verified     bool               `json:"verified"`
+	VerifiedAt   *time.Time         `json:"verifiedAt,omitempty"`
+	Rating       data.RatingSummary `json:"rating"`
+	Reviews      []*data.Rating     `json:"reviews,omitempty"`
+}
+
+// Service is the buyer domain service
+type Service interface {
+	// SubmitVerification creates or updates the user's buyer profile and
+	// queues it for review
+	SubmitVerification(ctx context.Context, user *data.User, in ProfileInput) (*data.BuyerProfile, error)
+	Verification(ctx context.Context, user *data.User) (*data.BuyerProfile, error)
+	// ListVerifications and ReviewVerification are for admins; callers must
+	// have checked the role
+	ListVerifications(ctx context.Context, status string) ([]*data.BuyerProfile, error)
+	ReviewVerification(ctx context.Context, admin *data.User, buyerProfileID string, approve bool, notes string) (*data.BuyerProfile, error)
+	ListBuyers(ctx context.Context, verifiedOnly bool) ([]*Profile, error)
+	GetBuyer(ctx context.Context, userID string) (*Profile, error)
+	Rate(ctx context.Context, user *data.User, in RatingInput) (*data.Rating, error)
+	Ratings(ctx context.Context, userID string) (data.RatingSummary, []*data.Rating, error)
+}
+
+// buyerService implements Service on top of the buyer profile and rating repositories
+type buyerService struct {
+	profiles      data.BuyerProfileInterface
+	ratings       data.RatingInterface
+	users         data.UserInterface
+	notifications data.NotificationInterface
+}
+
+// New creates the buyer service
+func New(profiles data.BuyerProfileInterface, ratings data.RatingInterface, users data.UserInterface, notifications data.NotificationInterface) Service {
+	return &buyerService{profiles: profiles, ratings: ratings, users: users, notifications: notifications}
+}
+
+// SubmitVerification creates or updates the user's buyer profile. Any change
+// sends the profile back to Pending, so verified details are always reviewed.
+func (s *buyerService) SubmitVerification(ctx context.Context, user *data.User, in ProfileInput) (*data.BuyerProfile, error) {
+	if user.Role != auth.BuyerRole {
+		return nil, service.Forbidden("only buyer accounts can be verified")
+	}
+
+	profile, err := s.profiles.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer profile: %w", err)
+	}
+
+	if profile == nil {
+		profile = &data.BuyerProfile{
+			UserID:             user.UserID,
+			BusinessName:       in.BusinessName,
+			RegistrationNumber: in.RegistrationNumber,
+			Location:           in.Location,
+			DocumentURL:        in.DocumentURL,
+			Status:             StatusPending,
+		}
+		if err := s.profiles.Insert(ctx, profile); err != nil {
+			return nil, fmt.Errorf("creating buyer profile: %w", err)
+		}
+		return profile, nil
+	}
+
+	profile.BusinessName = in.BusinessName
+	profile.RegistrationNumber = in.RegistrationNumber
+	profile.Location = in.Location
+	profile.DocumentURL = in.DocumentURL
+	profile.Status = StatusPending
+	profile.ReviewedBy = nil
+	profile.ReviewedAt = nil
+	profile.ReviewNotes = ""
+	if err := s.profiles.Update(ctx, profile); err != nil {
+		return nil, fmt.Errorf("updating buyer profile: %w", err)
+	}
+	return profile, nil
+}
+
+// Verification returns the user's own buyer profile
+func (s *buyerService) Verification(ctx context.Context, user *data.User) (*data.BuyerProfile, error) {
+	profile, err := s.profiles.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer profile: %w", err)
+	}
+	if profile == nil {
+		return nil, service.NotFound("no verification has been submitted")
+	}
+	return profile, nil
+}
+
+// ListVerifications returns buyer profiles with the given status, or all of them
+func (s *buyerService) ListVerifications(ctx context.Context, status string) ([]*data.BuyerProfile, error) {
+	profiles, err := s.profiles.GetByStatus(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer profiles: %w", err)
+	}
+	return profiles, nil
+}
+
+// ReviewVerification verifies or rejects a pending buyer profile and tells
+// the buyer the outcome
+func (s *buyerService) ReviewVerification(ctx context.Context, admin *data.User, buyerProfileID string, approve bool, notes string) (*data.BuyerProfile, error) {
+	profile, err := s.profiles.GetByBuyerProfileID(ctx, buyerProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer profile: %w", err)
+	}
+	if profile == nil {
+		return nil, service.NotFound("buyer profile not found")
+	}
+	if profile.Status != StatusPending {
+		return nil, service.Conflict("buyer profile has already been reviewed")
+	}
+	if !approve && notes == "" {
+		return nil, service.Invalid("a reason is required to reject a verification")
+	}
+
+	now := time.Now()
+	profile.Status = StatusRejected
+	if approve {
+		profile.Status = StatusVerified
+	}
+	profile.ReviewedBy = &admin.UserID
+	profile.ReviewedAt = &now
+	profile.ReviewNotes = notes
+	if err := s.profiles.Update(ctx, profile); err != nil {
+		return nil, fmt.Errorf("reviewing buyer profile: %w", err)
+	}
+
+	message := "Your buyer account has been verified."
+	if !approve {
+		message = "Your buyer verification was rejected: " + notes
+	}
+	notification := &data.Notification{
+		UserID:    profile.UserID,
+		Type:      "buyer_verification",
+		Title:     "Buyer verification " + profile.Status,
+		Message:   message,
+		Reference: fmt.Sprintf("buyer_profile:%s:%d", profile.BuyerProfileID, now.Unix()),
+	}
+	if err := s.notifications.Insert(ctx, notification); err != nil {
+		return nil, fmt.Errorf("notifying buyer: %w", err)
+	}
+
+	return profile, nil
+}
+
+// ListBuyers returns the public profiles of buyers who have submitted their
+// details, optionally only verified ones
+func (s *buyerService) ListBuyers(ctx context.Context, verifiedOnly bool) ([]*Profile, error) {
+	status := ""
+	if verifiedOnly {
+		status = StatusVerified
+	}
+	profiles, err := s.profiles.GetByStatus(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer profiles: %w", err)
+	}
+
+	buyers := make([]*Profile, 0, len(profiles))
+	for _, p := range profiles {
+		if p.Status == StatusRejected || p.User == nil || !p.User.Active {
+			continue
+		}
+		buyer, err := s.profile(ctx, p, false)
+		if err != nil {
+			return nil, err
+		}
+		buyers = append(buyers, buyer)
+	}
+	return buyers, nil
+}
+
+// GetBuyer returns a buyer's public profile with their recent reviews
+func (s *buyerService) GetBuyer(ctx context.Context, userID string) (*Profile, error) {
+	p, err := s.profiles.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer profile: %w", err)
+	}
+	if p == nil || p.User == nil || !p.User.Active || p.Status == StatusRejected {
+		return nil, service.NotFound("buyer not found")
+	}
+	return s.profile(ctx, p, true)
+}
+
+// Rate records a rating between a farmer and a buyer after a sale. Each side
+// can rate the other once per sale.
+func (s *buyerService) Rate(ctx context.Context, user *data.User, in RatingInput) (*data.Rating, error) {
+	if in.RateeID == user.UserID {
+		return nil, service.Invalid("you cannot rate yourself")
+	}
+
+	ratee, err := s.users.GetByUserID(ctx, in.RateeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting rated user: %w", err)
+	}
+	if ratee == nil || !ratee.Active {
+		return nil, service.NotFound("user not found")
+	}
+	if (user.Role == auth.BuyerRole) == (ratee.Role == auth.BuyerRole) {
+		return nil, service.Invalid("ratings are between a farmer and a buyer")
+	}
+
+	exists, err := s.ratings.Exists(ctx, user.UserID, ratee.UserID, in.SaleReference)
+	if err != nil {
+		return nil, fmt.Errorf("checking existing rating: %w", err)
+	}
+	if exists {
+		return nil, service.Conflict("you have already rated this user for this sale")
+	}
+
+	rating := &data.Rating{
+		RaterID:       user.UserID,
+		RateeID:       ratee.UserID,
+		SaleReference: in.SaleReference,
+		Score:         in.Score,
+		Review:        in.Review,
+	}
+	if err := s.ratings.Insert(ctx, rating); err != nil {
+		return nil, fmt.Errorf("creating rating: %w", err)
+	}
+	return rating, nil
+}
+
+// Ratings returns a user's rating summary and recent reviews
+func (s *buyerService) Ratings(ctx context.Context, userID string) (data.RatingSummary, []*data.Rating, error) {
+	summary, err := s.ratings.Summary(ctx, userID)
+	if err != nil {
+		return summary, nil, fmt.Errorf("getting rating summary: %w", err)
+	}
+	reviews, err := s.ratings.GetByRateeID(ctx, userID, recentReviews)
+	if err != nil {
+		return summary, nil, fmt.Errorf("getting ratings: %w", err)
+	}
+	return summary, reviews, nil
+}
+
+// profile builds the public view of a buyer profile
+func (s *buyerService) profile(ctx context.Context, p *data.BuyerProfile, withReviews bool) (*Profile, error) {
+	buyer := &Profile{
+		UserID:       p.UserID,
+		BusinessName: p.BusinessName,
+		Location:     p.Location,
+		Verified:     p.Verified(),
+	}
+	if p.User != nil {
+		buyer.Name = p.User.FirstName + " " + p.User.LastName
+	}
+	if buyer.Verified {
+		buyer.VerifiedAt = p.ReviewedAt
+	}
+
+	var err error
+	if withReviews {
+		buyer.Rating, buyer.Reviews, err = s.Ratings(ctx, p.UserID)
+	} else {
+		buyer.Rating, err = s.ratings.Summary(ctx, p.UserID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting buyer ratings: %w", err)
+	}
+	return buyer, nil
+}
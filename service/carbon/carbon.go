@@ -0,0 +1,272 @@
+// Package carbon estimates a farm's greenhouse gas emissions from its
+// livestock numbers, fertilizer and fuel use and utility records
+package carbon
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service/farm"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Emission factors in kg CO2e, based on IPCC 2019 Tier 1 defaults (AR5 100-year
+// GWPs: CH4 = 28, N2O = 265) and common published fuel and grid factors. They
+// are estimates intended for screening reports, not certified inventories.
+const (
+	factorSource = "IPCC 2019 Tier 1 defaults, AR5 GWP100"
+
+	dieselFactor   = 2.68 // per litre burned
+	petrolFactor   = 2.31 // per litre burned
+	waterFactor    = 0.34 // per m3 of supplied and treated water
+	nitrogenFactor = 5.51 // per kg N applied: direct and indirect field N2O
+)
+
+// DefaultGridFactor is the kg CO2e per kWh of grid electricity used unless
+// the farm's supplier publishes its own
+const DefaultGridFactor = 0.50
+
+// livestockFactors are annual enteric and manure emissions per head
+var livestockFactors = map[string]float64{
+	"dairy":   2600,
+	"cattle":  1600,
+	"sheep":   145,
+	"goat":    145,
+	"pig":     110,
+	"poultry": 2,
+}
+
+// livestockAliases maps common livestock type names to a livestockFactors key
+var livestockAliases = map[string]string{
+	"dairy cattle": "dairy",
+	"dairy cows":   "dairy",
+	"cow":          "cattle",
+	"cows":         "cattle",
+	"beef":         "cattle",
+	"sheep":        "sheep",
+	"goats":        "goat",
+	"pigs":         "pig",
+	"swine":        "pig",
+	"chicken":      "poultry",
+	"chickens":     "poultry",
+	"broilers":     "poultry",
+	"layers":       "poultry",
+	"ducks":        "poultry",
+	"turkeys":      "poultry",
+}
+
+// EmissionSource is one line of the carbon report
+type EmissionSource struct {
+	Category   string  `json:"category"` // Livestock, Fertilizer, Fuel, Electricity, Water
+	Source     string  `json:"source"`
+	Activity   float64 `json:"activity"`
+	Unit       string  `json:"unit"`
+	Factor     float64 `json:"factor"`
+	FactorUnit string  `json:"factorUnit"`
+	CO2eKg     float64 `json:"co2eKg"`
+}
+
+// Report is a farm's estimated greenhouse gas emissions for a period
+type Report struct {
+	FarmID        string             `json:"farmId"`
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	FactorSource  string             `json:"factorSource"`
+	Sources       []EmissionSource   `json:"sources"`
+	ByCategory    map[string]float64 `json:"byCategory"`
+	TotalCO2eKg   float64            `json:"totalCo2eKg"`
+	TotalCO2eTons float64            `json:"totalCo2eTonnes"`
+	Warnings      []string           `json:"warnings,omitempty"`
+}
+
+// Service is the carbon reporting domain service
+type Service interface {
+	// Report estimates the emissions of one of the user's farms over
+	// [from, to), by default the last 12 months. gridFactor is the factor
+	// for grid electricity, normally DefaultGridFactor.
+	Report(ctx context.Context, user *data.User, farmID string, from, to *time.Time, gridFactor float64) (*Report, error)
+}
+
+// carbonService implements Service on top of the livestock, inventory and
+// utility repositories
+type carbonService struct {
+	livestock data.LivestockInterface
+	movements data.InventoryMovementInterface
+	utilities data.UtilityRecordInterface
+	farms     farm.Service
+}
+
+// New creates the carbon reporting service
+func New(livestock data.LivestockInterface, movements data.InventoryMovementInterface, utilities data.UtilityRecordInterface, farms farm.Service) Service {
+	return &carbonService{livestock: livestock, movements: movements, utilities: utilities, farms: farms}
+}
+
+// Report implements Service
+func (s *carbonService) Report(ctx context.Context, user *data.User, farmID string, from, to *time.Time, gridFactor float64) (*Report, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	end := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(-1, 0, 0)
+	if from != nil {
+		start = *from
+	}
+
+	report := &Report{
+		FarmID:       farmID,
+		From:         start,
+		To:           end,
+		FactorSource: factorSource,
+		Sources:      []EmissionSource{},
+		ByCategory:   map[string]float64{},
+	}
+
+	add := func(source EmissionSource) {
+		source.CO2eKg = round2(source.Activity * source.Factor)
+		report.Sources = append(report.Sources, source)
+		report.ByCategory[source.Category] += source.CO2eKg
+		report.TotalCO2eKg += source.CO2eKg
+	}
+
+	// Livestock: current headcount held for the length of the period
+	years := end.Sub(start).Hours() / (24 * 365)
+	livestock, err := s.livestock.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting livestock: %w", err)
+	}
+	for _, l := range livestock {
+		if l.HealthStatus == "Deceased" || l.Count <= 0 {
+			continue
+		}
+		factor, ok := livestockFactor(l.Type)
+		if !ok {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("no emission factor for livestock type %q", l.Type))
+			continue
+		}
+		add(EmissionSource{
+			Category:   "Livestock",
+			Source:     l.Type,
+			Activity:   round2(float64(l.Count) * years),
+			Unit:       "head-years",
+			Factor:     factor,
+			FactorUnit: "kg CO2e/head/year",
+		})
+	}
+
+	// Fertilizer: nitrogen applied from consumed fertilizer stock
+	fertilizers, err := s.movements.ConsumptionByCategory(ctx, farmID, "Fertilizer", start, end)
+	if err != nil {
+		return nil, fmt.Errorf("getting fertilizer use: %w", err)
+	}
+	for _, f := range fertilizers {
+		kg, ok := massInKg(f.Quantity, f.Unit)
+		if !ok {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("fertilizer %q is recorded in %q; use kg or t to include it", f.Name, f.Unit))
+			continue
+		}
+		if f.NitrogenPercent <= 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("fertilizer %q has no nitrogen content set", f.Name))
+			continue
+		}
+		add(EmissionSource{
+			Category:   "Fertilizer",
+			Source:     f.Name,
+			Activity:   round2(kg * f.NitrogenPercent / 100),
+			Unit:       "kg N",
+			Factor:     nitrogenFactor,
+			FactorUnit: "kg CO2e/kg N",
+		})
+	}
+
+	// Fuel: consumed fuel stock
+	fuels, err := s.movements.ConsumptionByCategory(ctx, farmID, "Fuel", start, end)
+	if err != nil {
+		return nil, fmt.Errorf("getting fuel use: %w", err)
+	}
+	for _, f := range fuels {
+		if !strings.EqualFold(f.Unit, "L") && !strings.EqualFold(f.Unit, "litres") && !strings.EqualFold(f.Unit, "liters") {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("fuel %q is recorded in %q; use L to include it", f.Name, f.Unit))
+			continue
+		}
+		factor := dieselFactor
+		name := strings.ToLower(f.Name)
+		if strings.Contains(name, "petrol") || strings.Contains(name, "gasoline") {
+			factor = petrolFactor
+		}
+		add(EmissionSource{
+			Category:   "Fuel",
+			Source:     f.Name,
+			Activity:   round2(f.Quantity),
+			Unit:       "L",
+			Factor:     factor,
+			FactorUnit: "kg CO2e/L",
+		})
+	}
+
+	// Energy: electricity, generator diesel and water from utility records
+	utilities, err := s.utilities.MonthlyTotals(ctx, farmID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("getting utility consumption: %w", err)
+	}
+	energy := map[string]float64{}
+	for _, u := range utilities {
+		energy[u.UtilityType+"|"+u.Unit] += u.Quantity
+	}
+	for _, key := range slices.Sorted(maps.Keys(energy)) {
+		quantity := energy[key]
+		utilityType, unit, _ := strings.Cut(key, "|")
+		source := EmissionSource{Source: utilityType, Activity: round2(quantity), Unit: unit}
+		switch {
+		case utilityType == "Electricity" && unit == "kWh":
+			source.Category, source.Factor, source.FactorUnit = "Electricity", gridFactor, "kg CO2e/kWh"
+		case utilityType == "Diesel" && unit == "L":
+			source.Category, source.Factor, source.FactorUnit = "Fuel", dieselFactor, "kg CO2e/L"
+		case utilityType == "Water" && unit == "m3":
+			source.Category, source.Factor, source.FactorUnit = "Water", waterFactor, "kg CO2e/m3"
+		default:
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s records in %q are not included", utilityType, unit))
+			continue
+		}
+		add(source)
+	}
+
+	for category, total := range report.ByCategory {
+		report.ByCategory[category] = round2(total)
+	}
+	report.TotalCO2eKg = round2(report.TotalCO2eKg)
+	report.TotalCO2eTons = round2(report.TotalCO2eKg / 1000)
+	return report, nil
+}
+
+// livestockFactor looks up the annual per-head factor for a livestock type
+func livestockFactor(livestockType string) (float64, bool) {
+	key := strings.ToLower(strings.TrimSpace(livestockType))
+	if alias, ok := livestockAliases[key]; ok {
+		key = alias
+	}
+	factor, ok := livestockFactors[key]
+	return factor, ok
+}
+
+// massInKg converts a quantity in kg or tonnes to kg
+func massInKg(quantity float64, unit string) (float64, bool) {
+	switch strings.ToLower(unit) {
+	case "kg":
+		return quantity, true
+	case "t", "tonne", "tonnes":
+		return quantity * 1000, true
+	}
+	return 0, false
+}
+
+// round2 rounds to two decimal places
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,328 @@
+// Package chemical keeps a farm's chemical store register: the product
+// batches held, by WHO hazard class, and the log of what was taken out, by
+// whom and with PPE confirmed, as presented during regulatory inspections
+package chemical
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Input holds the editable chemical product fields. On update, zero values
+// are left unchanged.
+type Input struct {
+	Name             string
+	ActiveIngredient string
+	Category         string
+	WHOClass         string
+	Restricted       *bool // Class Ia and Ib products are always restricted
+	BatchNumber      string
+	ExpiryDate       *time.Time
+	Quantity         float64
+	Unit             string
+	StorageLocation  string
+	Supplier         string
+	Notes            string
+}
+
+// UsageInput holds the fields of a product taken from the store
+type UsageInput struct {
+	Date                 *time.Time // Defaults to now
+	Quantity             float64
+	Applicator           string
+	ApplicatorEmployeeID *string // Must work on the product's farm; empty for none
+	PPEConfirmed         bool
+	Target               string
+	Purpose              string
+	Notes                string
+}
+
+// Service is the chemical store domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.ChemicalProduct, error)
+	Get(ctx context.Context, user *data.User, chemicalProductID string) (*data.ChemicalProduct, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.ChemicalProduct, error)
+	Update(ctx context.Context, user *data.User, chemicalProductID string, in Input) (*data.ChemicalProduct, error)
+	// Delete soft deletes a product; its usage history is kept for
+	// inspections
+	Delete(ctx context.Context, user *data.User, chemicalProductID string) error
+	ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.ChemicalProduct, error)
+	Restore(ctx context.Context, user *data.User, chemicalProductID string) (*data.ChemicalProduct, error)
+
+	// LogUsage records a product being taken from the store. An expired batch
+	// cannot be used, and the date may not be in a locked period.
+	LogUsage(ctx context.Context, user *data.User, chemicalProductID string, in UsageInput) (*data.ChemicalUsage, error)
+	// Usage returns a product with its usage log
+	Usage(ctx context.Context, user *data.User, chemicalProductID string) (*data.ChemicalProduct, []*data.ChemicalUsage, error)
+	// Register returns a farm's products with their usage in [from, to),
+	// optionally only the restricted ones
+	Register(ctx context.Context, user *data.User, farmID string, from, to *time.Time, restrictedOnly bool) ([]*data.ChemicalProduct, error)
+}
+
+// chemicalService implements Service on top of the chemical repositories
+type chemicalService struct {
+	products  data.ChemicalProductInterface
+	usages    data.ChemicalUsageInterface
+	employees data.EmployeeInterface
+	locks     lock.Checker
+	farms     farm.Service
+}
+
+// New creates the chemical store service
+func New(products data.ChemicalProductInterface, usages data.ChemicalUsageInterface, employees data.EmployeeInterface, locks lock.Checker, farms farm.Service) Service {
+	return &chemicalService{products: products, usages: usages, employees: employees, locks: locks, farms: farms}
+}
+
+// restrictedClass reports whether a WHO class is always treated as restricted
+func restrictedClass(whoClass string) bool {
+	return whoClass == "Ia" || whoClass == "Ib"
+}
+
+// Create adds a product batch to the chemical store of one of the user's
+// farms
+func (s *chemicalService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.ChemicalProduct, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	product := &data.ChemicalProduct{
+		FarmID:           farmID,
+		Name:             in.Name,
+		ActiveIngredient: in.ActiveIngredient,
+		Category:         in.Category,
+		WHOClass:         in.WHOClass,
+		Restricted:       restrictedClass(in.WHOClass) || (in.Restricted != nil && *in.Restricted),
+		BatchNumber:      in.BatchNumber,
+		ExpiryDate:       in.ExpiryDate,
+		Quantity:         in.Quantity,
+		Unit:             in.Unit,
+		StorageLocation:  in.StorageLocation,
+		Supplier:         in.Supplier,
+		Notes:            in.Notes,
+	}
+	if err := s.products.Insert(ctx, product); err != nil {
+		return nil, fmt.Errorf("creating chemical product: %w", err)
+	}
+	return product, nil
+}
+
+// Get returns a chemical product on one of the user's farms
+func (s *chemicalService) Get(ctx context.Context, user *data.User, chemicalProductID string) (*data.ChemicalProduct, error) {
+	product, err := s.products.GetByChemicalProductID(ctx, chemicalProductID)
+	if err != nil {
+		return nil, fmt.Errorf("getting chemical product: %w", err)
+	}
+	if product == nil {
+		return nil, service.NotFound("chemical product not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, product.FarmID, "chemical product"); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// List returns the chemical store of one of the user's farms
+func (s *chemicalService) List(ctx context.Context, user *data.User, farmID string) ([]*data.ChemicalProduct, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	products, err := s.products.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting chemical products: %w", err)
+	}
+	return products, nil
+}
+
+// Update changes the non-zero fields of in on a chemical product
+func (s *chemicalService) Update(ctx context.Context, user *data.User, chemicalProductID string, in Input) (*data.ChemicalProduct, error) {
+	product, err := s.Get(ctx, user, chemicalProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		product.Name = in.Name
+	}
+	if in.ActiveIngredient != "" {
+		product.ActiveIngredient = in.ActiveIngredient
+	}
+	if in.Category != "" {
+		product.Category = in.Category
+	}
+	if in.WHOClass != "" {
+		product.WHOClass = in.WHOClass
+	}
+	if in.Restricted != nil {
+		product.Restricted = *in.Restricted
+	}
+	if in.BatchNumber != "" {
+		product.BatchNumber = in.BatchNumber
+	}
+	if in.ExpiryDate != nil {
+		product.ExpiryDate = in.ExpiryDate
+	}
+	if in.Quantity > 0 {
+		product.Quantity = in.Quantity
+	}
+	if in.Unit != "" {
+		product.Unit = in.Unit
+	}
+	if in.StorageLocation != "" {
+		product.StorageLocation = in.StorageLocation
+	}
+	if in.Supplier != "" {
+		product.Supplier = in.Supplier
+	}
+	if in.Notes != "" {
+		product.Notes = in.Notes
+	}
+	if restrictedClass(product.WHOClass) {
+		product.Restricted = true
+	}
+
+	if err := s.products.Update(ctx, product); err != nil {
+		return nil, fmt.Errorf("updating chemical product: %w", err)
+	}
+	return product, nil
+}
+
+// Delete implements Service
+func (s *chemicalService) Delete(ctx context.Context, user *data.User, chemicalProductID string) error {
+	product, err := s.Get(ctx, user, chemicalProductID)
+	if err != nil {
+		return err
+	}
+	if err := s.products.DeleteByID(ctx, int(product.ID)); err != nil {
+		return fmt.Errorf("deleting chemical product: %w", err)
+	}
+	return nil
+}
+
+// ListDeleted returns the soft-deleted chemical products of one of the user's
+// farms
+func (s *chemicalService) ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.ChemicalProduct, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	products, err := s.products.GetDeletedByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted chemical products: %w", err)
+	}
+	return products, nil
+}
+
+// Restore undeletes a chemical product. Its farm must still exist and belong
+// to the user.
+func (s *chemicalService) Restore(ctx context.Context, user *data.User, chemicalProductID string) (*data.ChemicalProduct, error) {
+	product, err := s.products.GetDeletedByChemicalProductID(ctx, chemicalProductID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted chemical product: %w", err)
+	}
+	if product == nil {
+		return nil, service.NotFound("deleted chemical product not found")
+	}
+	if _, err := s.farms.Owned(ctx, user, product.FarmID); err != nil {
+		return nil, err
+	}
+
+	if err := s.products.RestoreByID(ctx, int(product.ID)); err != nil {
+		return nil, fmt.Errorf("restoring chemical product: %w", err)
+	}
+	product.DeletedAt = gorm.DeletedAt{}
+	return product, nil
+}
+
+// LogUsage implements Service
+func (s *chemicalService) LogUsage(ctx context.Context, user *data.User, chemicalProductID string, in UsageInput) (*data.ChemicalUsage, error) {
+	product, err := s.Get(ctx, user, chemicalProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if product.ExpiryDate != nil && product.ExpiryDate.Before(date) {
+		return nil, service.Invalid(fmt.Sprintf("batch %s expired on %s", product.BatchNumber, product.ExpiryDate.Format("2006-01-02")))
+	}
+	if err := s.locks.Check(ctx, product.FarmID, date); err != nil {
+		return nil, err
+	}
+
+	if in.ApplicatorEmployeeID != nil && *in.ApplicatorEmployeeID != "" {
+		employee, err := s.employees.GetByEmployeeID(ctx, *in.ApplicatorEmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("getting applicator employee: %w", err)
+		}
+		if employee == nil || employee.FarmID != product.FarmID {
+			return nil, service.Invalid("applicator employee not found on this farm")
+		}
+	} else {
+		in.ApplicatorEmployeeID = nil
+	}
+
+	usage := &data.ChemicalUsage{
+		ChemicalProductID:    product.ChemicalProductID,
+		FarmID:               product.FarmID,
+		Date:                 date,
+		Quantity:             in.Quantity,
+		Applicator:           in.Applicator,
+		ApplicatorEmployeeID: in.ApplicatorEmployeeID,
+		PPEConfirmed:         in.PPEConfirmed,
+		Target:               in.Target,
+		Purpose:              in.Purpose,
+		Notes:                in.Notes,
+	}
+	err = s.usages.Insert(ctx, usage)
+	if errors.Is(err, data.ErrInsufficientStock) {
+		return nil, service.Invalid(fmt.Sprintf("only %.2f %s of %s in store", product.Quantity, product.Unit, product.Name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("logging chemical usage: %w", err)
+	}
+	return usage, nil
+}
+
+// Usage implements Service
+func (s *chemicalService) Usage(ctx context.Context, user *data.User, chemicalProductID string) (*data.ChemicalProduct, []*data.ChemicalUsage, error) {
+	product, err := s.Get(ctx, user, chemicalProductID)
+	if err != nil {
+		return nil, nil, err
+	}
+	usages, err := s.usages.GetByChemicalProductID(ctx, product.ChemicalProductID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting chemical usage: %w", err)
+	}
+	return product, usages, nil
+}
+
+// Register implements Service
+func (s *chemicalService) Register(ctx context.Context, user *data.User, farmID string, from, to *time.Time, restrictedOnly bool) ([]*data.ChemicalProduct, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	products, err := s.products.GetRegister(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting chemical register: %w", err)
+	}
+	if !restrictedOnly {
+		return products, nil
+	}
+
+	restricted := []*data.ChemicalProduct{}
+	for _, product := range products {
+		if product.Restricted {
+			restricted = append(restricted, product)
+		}
+	}
+	return restricted, nil
+}
@@ -0,0 +1,215 @@
+// Package coop runs cooperatives and farmer groups: their members and the
+// procurement windows in which members put in for inputs that the group buys
+// in bulk. When a window is consolidated, demand is added up into one order
+// per supplier and each member's request becomes their allocation on it.
+package coop
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"strings"
+)
+
+// Organization roles
+const (
+	RoleAdmin  = "Admin"
+	RoleMember = "Member"
+)
+
+// OrganizationInput holds the editable organization fields
+type OrganizationInput struct {
+	Name        string
+	Description string
+}
+
+// MemberInput adds a user, by email, to an organization
+type MemberInput struct {
+	Email string
+	Role  string
+}
+
+// Service is the co-operative domain service
+type Service interface {
+	// CreateOrganization sets up an organization with user as its admin
+	CreateOrganization(ctx context.Context, user *data.User, in OrganizationInput) (*data.Organization, error)
+	// ListOrganizations returns the organizations user is a member of
+	ListOrganizations(ctx context.Context, user *data.User) ([]*data.Organization, error)
+	// GetOrganization returns an organization user is a member of, with its
+	// members
+	GetOrganization(ctx context.Context, user *data.User, organizationID string) (*data.Organization, error)
+	AddMember(ctx context.Context, user *data.User, organizationID string, in MemberInput) (*data.OrganizationMember, error)
+	RemoveMember(ctx context.Context, user *data.User, organizationMemberID string) error
+
+	OpenWindow(ctx context.Context, user *data.User, organizationID string, in WindowInput) (*data.ProcurementWindow, error)
+	ListWindows(ctx context.Context, user *data.User, organizationID, status string) ([]*data.ProcurementWindow, error)
+	GetWindow(ctx context.Context, user *data.User, procurementWindowID string) (*data.ProcurementWindow, error)
+	CloseWindow(ctx context.Context, user *data.User, procurementWindowID string) (*data.ProcurementWindow, error)
+	CancelWindow(ctx context.Context, user *data.User, procurementWindowID string) (*data.ProcurementWindow, error)
+	// SubmitDemand replaces the user's requests in an open window
+	SubmitDemand(ctx context.Context, user *data.User, procurementWindowID string, in DemandInput) ([]*data.ProcurementRequest, error)
+	// ListDemand returns every member's requests in a window to an admin,
+	// and only their own to other members
+	ListDemand(ctx context.Context, user *data.User, procurementWindowID string) ([]*data.ProcurementRequest, error)
+	// Consolidate turns a window's demand into one order per supplier
+	Consolidate(ctx context.Context, user *data.User, procurementWindowID string) ([]*data.ProcurementOrder, error)
+	ListOrders(ctx context.Context, user *data.User, procurementWindowID string) ([]*data.ProcurementOrder, error)
+	// DeliverAllocation records the delivery of a member's allocation
+	DeliverAllocation(ctx context.Context, user *data.User, procurementRequestID string, in DeliveryInput) (*data.ProcurementRequest, error)
+}
+
+// coopService implements Service on top of the organization and procurement
+// repositories
+type coopService struct {
+	organizations data.OrganizationInterface
+	windows       data.ProcurementWindowInterface
+	requests      data.ProcurementRequestInterface
+	orders        data.ProcurementOrderInterface
+	users         data.UserInterface
+	farms         farm.Service
+}
+
+// New creates the co-operative service
+func New(organizations data.OrganizationInterface, windows data.ProcurementWindowInterface, requests data.ProcurementRequestInterface,
+	orders data.ProcurementOrderInterface, users data.UserInterface, farms farm.Service) Service {
+	return &coopService{organizations: organizations, windows: windows, requests: requests, orders: orders, users: users, farms: farms}
+}
+
+// member returns user's membership of an organization, or Forbidden if they
+// are not a member
+func (s *coopService) member(ctx context.Context, user *data.User, organizationID string) (*data.OrganizationMember, error) {
+	member, err := s.organizations.GetMember(ctx, organizationID, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting organization member: %w", err)
+	}
+	if member == nil {
+		return nil, service.Forbidden("organization not found or access denied")
+	}
+	return member, nil
+}
+
+// admin returns user's membership of an organization, or Forbidden if they
+// are not one of its admins
+func (s *coopService) admin(ctx context.Context, user *data.User, organizationID string) (*data.OrganizationMember, error) {
+	member, err := s.member(ctx, user, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role != RoleAdmin {
+		return nil, service.Forbidden("only an organization admin can do this")
+	}
+	return member, nil
+}
+
+// CreateOrganization sets up an organization with user as its admin
+func (s *coopService) CreateOrganization(ctx context.Context, user *data.User, in OrganizationInput) (*data.Organization, error) {
+	organization := &data.Organization{
+		Name:        strings.TrimSpace(in.Name),
+		Description: in.Description,
+		CreatedBy:   user.UserID,
+	}
+	admin := &data.OrganizationMember{UserID: user.UserID, Role: RoleAdmin}
+	if err := s.organizations.Insert(ctx, organization, admin); err != nil {
+		return nil, fmt.Errorf("creating organization: %w", err)
+	}
+	admin.User = user
+	organization.Members = []data.OrganizationMember{*admin}
+	return organization, nil
+}
+
+// ListOrganizations returns the organizations user is a member of
+func (s *coopService) ListOrganizations(ctx context.Context, user *data.User) ([]*data.Organization, error) {
+	organizations, err := s.organizations.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting organizations: %w", err)
+	}
+	return organizations, nil
+}
+
+// GetOrganization returns an organization user is a member of, with its
+// members
+func (s *coopService) GetOrganization(ctx context.Context, user *data.User, organizationID string) (*data.Organization, error) {
+	if _, err := s.member(ctx, user, organizationID); err != nil {
+		return nil, err
+	}
+	organization, err := s.organizations.GetByOrganizationID(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("getting organization: %w", err)
+	}
+	if organization == nil {
+		return nil, service.NotFound("organization not found")
+	}
+	members, err := s.organizations.GetMembers(ctx, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("getting organization members: %w", err)
+	}
+	for _, member := range members {
+		organization.Members = append(organization.Members, *member)
+	}
+	return organization, nil
+}
+
+// AddMember lets an admin add another user to the organization
+func (s *coopService) AddMember(ctx context.Context, user *data.User, organizationID string, in MemberInput) (*data.OrganizationMember, error) {
+	if _, err := s.admin(ctx, user, organizationID); err != nil {
+		return nil, err
+	}
+	role := in.Role
+	if role == "" {
+		role = RoleMember
+	}
+	if role != RoleAdmin && role != RoleMember {
+		return nil, service.Invalid("role must be one of Admin, Member")
+	}
+
+	added, err := s.users.GetByEmail(ctx, strings.TrimSpace(in.Email))
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+	if added == nil || !added.Active {
+		return nil, service.NotFound("no active user with that email")
+	}
+
+	existing, err := s.organizations.GetMember(ctx, organizationID, added.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting organization member: %w", err)
+	}
+	if existing != nil {
+		return nil, service.Conflict("user is already a member of this organization")
+	}
+
+	member := &data.OrganizationMember{
+		OrganizationID: organizationID,
+		UserID:         added.UserID,
+		Role:           role,
+		User:           added,
+	}
+	if err := s.organizations.InsertMember(ctx, member); err != nil {
+		return nil, fmt.Errorf("adding organization member: %w", err)
+	}
+	return member, nil
+}
+
+// RemoveMember lets an admin take a member out of the organization. Admins
+// cannot remove themselves, so an organization always keeps one.
+func (s *coopService) RemoveMember(ctx context.Context, user *data.User, organizationMemberID string) error {
+	member, err := s.organizations.GetMemberByID(ctx, organizationMemberID)
+	if err != nil {
+		return fmt.Errorf("getting organization member: %w", err)
+	}
+	if member == nil {
+		return service.NotFound("organization member not found")
+	}
+	if _, err := s.admin(ctx, user, member.OrganizationID); err != nil {
+		return err
+	}
+	if member.UserID == user.UserID {
+		return service.Invalid("admins cannot remove themselves")
+	}
+	if err := s.organizations.DeleteMemberByID(ctx, int(member.ID)); err != nil {
+		return fmt.Errorf("removing organization member: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package coop
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Window statuses
+const (
+	WindowOpen         = "Open"
+	WindowClosed       = "Closed"
+	WindowConsolidated = "Consolidated"
+	WindowCancelled    = "Cancelled"
+)
+
+// Request statuses
+const (
+	RequestRequested = "Requested"
+	RequestOrdered   = "Ordered"
+	RequestDelivered = "Delivered"
+)
+
+// ItemInput is an input offered in a window
+type ItemInput struct {
+	Name      string
+	Unit      string
+	Supplier  string
+	UnitPrice float64
+}
+
+// WindowInput opens a procurement window
+type WindowInput struct {
+	Title    string
+	Notes    string
+	ClosesAt time.Time
+	Items    []ItemInput
+}
+
+// DemandLine is the quantity of one item a member puts in for
+type DemandLine struct {
+	ItemID   string
+	Quantity float64
+}
+
+// DemandInput is a member's demand in a window, for one of their farms. It
+// replaces any demand they put in before; no lines withdraws it.
+type DemandInput struct {
+	FarmID string
+	Lines  []DemandLine
+}
+
+// DeliveryInput records the delivery of an allocation. A zero Quantity means
+// the full allocation was delivered.
+type DeliveryInput struct {
+	Quantity    float64
+	DeliveredAt *time.Time
+}
+
+// window returns a procurement window, or NotFound
+func (s *coopService) window(ctx context.Context, procurementWindowID string) (*data.ProcurementWindow, error) {
+	window, err := s.windows.GetByProcurementWindowID(ctx, procurementWindowID)
+	if err != nil {
+		return nil, fmt.Errorf("getting procurement window: %w", err)
+	}
+	if window == nil {
+		return nil, service.NotFound("procurement window not found")
+	}
+	return window, nil
+}
+
+// OpenWindow lets an admin open a window for the organization's members to
+// put in for the given items
+func (s *coopService) OpenWindow(ctx context.Context, user *data.User, organizationID string, in WindowInput) (*data.ProcurementWindow, error) {
+	if _, err := s.admin(ctx, user, organizationID); err != nil {
+		return nil, err
+	}
+	if !in.ClosesAt.After(time.Now()) {
+		return nil, service.Invalid("closesAt must be in the future")
+	}
+	if len(in.Items) == 0 {
+		return nil, service.Invalid("a procurement window needs at least one item")
+	}
+
+	window := &data.ProcurementWindow{
+		OrganizationID: organizationID,
+		Title:          in.Title,
+		Notes:          in.Notes,
+		ClosesAt:       in.ClosesAt,
+		Status:         WindowOpen,
+		CreatedBy:      user.UserID,
+	}
+	for _, item := range in.Items {
+		if item.UnitPrice < 0 {
+			return nil, service.Invalid("unitPrice cannot be negative")
+		}
+		window.Items = append(window.Items, data.ProcurementItem{
+			Name:      item.Name,
+			Unit:      item.Unit,
+			Supplier:  strings.TrimSpace(item.Supplier),
+			UnitPrice: item.UnitPrice,
+		})
+	}
+	if err := s.windows.Insert(ctx, window); err != nil {
+		return nil, fmt.Errorf("opening procurement window: %w", err)
+	}
+	return window, nil
+}
+
+// ListWindows returns an organization's windows, optionally only those with
+// the given status
+func (s *coopService) ListWindows(ctx context.Context, user *data.User, organizationID, status string) ([]*data.ProcurementWindow, error) {
+	if _, err := s.member(ctx, user, organizationID); err != nil {
+		return nil, err
+	}
+	windows, err := s.windows.GetByOrganizationID(ctx, organizationID, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting procurement windows: %w", err)
+	}
+	return windows, nil
+}
+
+// GetWindow returns a window of an organization user is a member of
+func (s *coopService) GetWindow(ctx context.Context, user *data.User, procurementWindowID string) (*data.ProcurementWindow, error) {
+	window, err := s.window(ctx, procurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.member(ctx, user, window.OrganizationID); err != nil {
+		return nil, err
+	}
+	return window, nil
+}
+
+// CloseWindow lets an admin stop members putting in before the window's
+// closing time, ready for consolidation
+func (s *coopService) CloseWindow(ctx context.Context, user *data.User, procurementWindowID string) (*data.ProcurementWindow, error) {
+	return s.transition(ctx, user, procurementWindowID, WindowClosed, WindowOpen)
+}
+
+// CancelWindow lets an admin call off a window that has not been
+// consolidated
+func (s *coopService) CancelWindow(ctx context.Context, user *data.User, procurementWindowID string) (*data.ProcurementWindow, error) {
+	return s.transition(ctx, user, procurementWindowID, WindowCancelled, WindowOpen, WindowClosed)
+}
+
+// transition moves a window to status if an admin asks and it is in one of
+// the from statuses
+func (s *coopService) transition(ctx context.Context, user *data.User, procurementWindowID, status string, from ...string) (*data.ProcurementWindow, error) {
+	window, err := s.window(ctx, procurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admin(ctx, user, window.OrganizationID); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(from, window.Status) {
+		return nil, service.Conflict(fmt.Sprintf("a %s procurement window cannot be %s", strings.ToLower(window.Status), strings.ToLower(status)))
+	}
+
+	current := window.Status
+	window.Status = status
+	ok, err := s.windows.Transition(ctx, window, current)
+	if err != nil {
+		return nil, fmt.Errorf("updating procurement window: %w", err)
+	}
+	if !ok {
+		return nil, service.Conflict("procurement window was changed by another request")
+	}
+	return window, nil
+}
+
+// SubmitDemand replaces the user's requests in an open window with in, for
+// one of their farms
+func (s *coopService) SubmitDemand(ctx context.Context, user *data.User, procurementWindowID string, in DemandInput) ([]*data.ProcurementRequest, error) {
+	window, err := s.window(ctx, procurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.member(ctx, user, window.OrganizationID); err != nil {
+		return nil, err
+	}
+	if window.Status != WindowOpen || !time.Now().Before(window.ClosesAt) {
+		return nil, service.Conflict("procurement window is closed")
+	}
+	if _, err := s.farms.Owned(ctx, user, in.FarmID); err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]*data.ProcurementItem, len(window.Items))
+	for i := range window.Items {
+		items[window.Items[i].ProcurementItemID] = &window.Items[i]
+	}
+	requests := make([]*data.ProcurementRequest, 0, len(in.Lines))
+	seen := make(map[string]bool, len(in.Lines))
+	for _, line := range in.Lines {
+		item, ok := items[line.ItemID]
+		if !ok {
+			return nil, service.Invalid(fmt.Sprintf("item %s is not offered in this window", line.ItemID))
+		}
+		if seen[line.ItemID] {
+			return nil, service.Invalid(fmt.Sprintf("item %s is listed more than once", line.ItemID))
+		}
+		if line.Quantity <= 0 {
+			return nil, service.Invalid("quantity must be greater than zero")
+		}
+		seen[line.ItemID] = true
+		requests = append(requests, &data.ProcurementRequest{
+			ProcurementWindowID: window.ProcurementWindowID,
+			ProcurementItemID:   item.ProcurementItemID,
+			UserID:              user.UserID,
+			FarmID:              in.FarmID,
+			Quantity:            line.Quantity,
+			Status:              RequestRequested,
+			Item:                item,
+		})
+	}
+
+	ok, err := s.requests.Replace(ctx, window.ProcurementWindowID, user.UserID, requests)
+	if err != nil {
+		return nil, fmt.Errorf("saving procurement requests: %w", err)
+	}
+	if !ok {
+		return nil, service.Conflict("procurement window is closed")
+	}
+	return requests, nil
+}
+
+// ListDemand returns every member's requests in a window to an admin, and
+// only their own to other members
+func (s *coopService) ListDemand(ctx context.Context, user *data.User, procurementWindowID string) ([]*data.ProcurementRequest, error) {
+	window, err := s.window(ctx, procurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	member, err := s.member(ctx, user, window.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	userID := user.UserID
+	if member.Role == RoleAdmin {
+		userID = ""
+	}
+	requests, err := s.requests.GetByWindowID(ctx, window.ProcurementWindowID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting procurement requests: %w", err)
+	}
+	return requests, nil
+}
+
+// Consolidate lets an admin add up a window's demand into one order per
+// supplier. Each line totals an item across members and each member's
+// request is allocated to the order for its item's supplier.
+func (s *coopService) Consolidate(ctx context.Context, user *data.User, procurementWindowID string) ([]*data.ProcurementOrder, error) {
+	window, err := s.window(ctx, procurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admin(ctx, user, window.OrganizationID); err != nil {
+		return nil, err
+	}
+	if window.Status != WindowOpen && window.Status != WindowClosed {
+		return nil, service.Conflict(fmt.Sprintf("a %s procurement window cannot be consolidated", strings.ToLower(window.Status)))
+	}
+
+	requests, err := s.requests.GetByWindowID(ctx, window.ProcurementWindowID, "")
+	if err != nil {
+		return nil, fmt.Errorf("getting procurement requests: %w", err)
+	}
+	if len(requests) == 0 {
+		return nil, service.Invalid("no member has put in for this window")
+	}
+	byItem := make(map[string][]*data.ProcurementRequest)
+	for _, request := range requests {
+		byItem[request.ProcurementItemID] = append(byItem[request.ProcurementItemID], request)
+	}
+
+	// Items keep the order they were offered in on each supplier's order
+	var orders []*data.ProcurementOrder
+	bySupplier := make(map[string]*data.ProcurementOrder)
+	for _, item := range window.Items {
+		itemRequests := byItem[item.ProcurementItemID]
+		if len(itemRequests) == 0 {
+			continue
+		}
+		order, ok := bySupplier[item.Supplier]
+		if !ok {
+			order = &data.ProcurementOrder{
+				ProcurementWindowID: window.ProcurementWindowID,
+				OrganizationID:      window.OrganizationID,
+				Supplier:            item.Supplier,
+			}
+			bySupplier[item.Supplier] = order
+			orders = append(orders, order)
+		}
+
+		line := data.ProcurementOrderLine{
+			ItemID:    item.ProcurementItemID,
+			Name:      item.Name,
+			Unit:      item.Unit,
+			UnitPrice: item.UnitPrice,
+			Members:   len(itemRequests),
+		}
+		for _, request := range itemRequests {
+			line.Quantity += request.Quantity
+			order.Allocations = append(order.Allocations, *request)
+		}
+		line.Total = line.Quantity * line.UnitPrice
+		order.Lines = append(order.Lines, line)
+		order.Total += line.Total
+	}
+
+	now := time.Now()
+	window.ConsolidatedAt = &now
+	ok, err := s.windows.Consolidate(ctx, window, orders)
+	if err != nil {
+		return nil, fmt.Errorf("consolidating procurement window: %w", err)
+	}
+	if !ok {
+		return nil, service.Conflict("procurement window was changed by another request")
+	}
+	window.Status = WindowConsolidated
+	return orders, nil
+}
+
+// ListOrders returns a window's consolidated orders with their allocations
+// to an admin
+func (s *coopService) ListOrders(ctx context.Context, user *data.User, procurementWindowID string) ([]*data.ProcurementOrder, error) {
+	window, err := s.window(ctx, procurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admin(ctx, user, window.OrganizationID); err != nil {
+		return nil, err
+	}
+	orders, err := s.orders.GetByWindowID(ctx, window.ProcurementWindowID)
+	if err != nil {
+		return nil, fmt.Errorf("getting procurement orders: %w", err)
+	}
+	return orders, nil
+}
+
+// DeliverAllocation lets an admin record that a member's allocation was
+// handed over
+func (s *coopService) DeliverAllocation(ctx context.Context, user *data.User, procurementRequestID string, in DeliveryInput) (*data.ProcurementRequest, error) {
+	request, err := s.requests.GetByProcurementRequestID(ctx, procurementRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("getting procurement allocation: %w", err)
+	}
+	if request == nil {
+		return nil, service.NotFound("procurement allocation not found")
+	}
+	window, err := s.window(ctx, request.ProcurementWindowID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.admin(ctx, user, window.OrganizationID); err != nil {
+		return nil, err
+	}
+	if request.Status != RequestOrdered {
+		return nil, service.Conflict(fmt.Sprintf("a %s allocation cannot be delivered", strings.ToLower(request.Status)))
+	}
+	if in.Quantity < 0 {
+		return nil, service.Invalid("quantity cannot be negative")
+	}
+
+	request.DeliveredQuantity = in.Quantity
+	if request.DeliveredQuantity == 0 {
+		request.DeliveredQuantity = request.Quantity
+	}
+	deliveredAt := time.Now()
+	if in.DeliveredAt != nil {
+		deliveredAt = *in.DeliveredAt
+	}
+	request.DeliveredAt = &deliveredAt
+	ok, err := s.requests.Deliver(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("delivering procurement allocation: %w", err)
+	}
+	if !ok {
+		return nil, service.Conflict("procurement allocation was changed by another request")
+	}
+	request.Status = RequestDelivered
+	return request, nil
+}
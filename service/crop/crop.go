@@ -0,0 +1,544 @@
+// Package crop manages the crops grown on a farm, the plans for the seasons
+// ahead, the what-if scenarios weighed against them and the pests and
+// diseases found on them
+package crop
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"farm4u/service/season"
+	"farm4u/service/tag"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Input holds the editable crop fields. On update, zero values are left
+// unchanged; an empty FieldID string takes the crop off its field, and an
+// empty SeasonID unlinks it from its season.
+type Input struct {
+	Name         string
+	PlantingDate *time.Time
+	HarvestDate  *time.Time
+	Quantity     float64
+	Status       string
+	Notes        string
+	FieldID      *string
+	SeasonID     *string
+	Version      int // Required on update: must be the current version
+}
+
+// Stats is a farm's crops counted per name and status, with the totals
+// rolled up from those groups
+type Stats struct {
+	FarmID   string           `json:"farmId"`
+	Records  int              `json:"records"`
+	ByName   map[string]int   `json:"byName"`   // Crop records per name
+	ByStatus map[string]int   `json:"byStatus"` // Crop records per status
+	Groups   []data.CropGroup `json:"groups"`
+}
+
+// StatusChange is a crop moved by UpdateStatus, with the crop as it was
+// before
+type StatusChange struct {
+	Before *data.Crop
+	Crop   *data.Crop
+}
+
+// Service is the crop domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Crop, error)
+	// CreateBatch adds several crops in a single transaction. An item that
+	// fails its checks gets its error in the same slot of the returned errors
+	// and is left out; the others are still created.
+	CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.Crop, []error, error)
+	Get(ctx context.Context, user *data.User, cropID string) (*data.Crop, error)
+	// List returns the crops of a farm latest planted first, optionally only
+	// those planted in [from, to) and those tagged with every one of tags
+	List(ctx context.Context, user *data.User, farmID string, from, to *time.Time, tags []string) ([]*data.Crop, error)
+	// ListAll returns the crops of every farm the user owns, each with its
+	// farm, for one consolidated list
+	ListAll(ctx context.Context, user *data.User) ([]*data.Crop, error)
+	// Stats counts a farm's crops per name and status without loading the
+	// records
+	Stats(ctx context.Context, user *data.User, farmID string) (*Stats, error)
+	Update(ctx context.Context, user *data.User, cropID string, in Input) (*data.Crop, error)
+	// Patch changes only the fields in set, clearing those left blank
+	Patch(ctx context.Context, user *data.User, cropID string, in Input, set service.Fields) (*data.Crop, error)
+	// UpdateStatus moves several of a farm's crops to status in a single
+	// transaction, e.g. to mark a field's crops Harvested. A crop that is not
+	// on the farm gets its error in the same slot of the returned errors and
+	// is left out; the others are still updated.
+	// Each crop that becomes Harvested gets a harvest date, as in Patch.
+	UpdateStatus(ctx context.Context, user *data.User, farmID string, cropIDs []string, status string) ([]*StatusChange, []error, error)
+	Delete(ctx context.Context, user *data.User, cropID string) error
+	ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.Crop, error)
+	Restore(ctx context.Context, user *data.User, cropID string) (*data.Crop, error)
+
+	// CreatePlan and UpdatePlan return the other plans the saved plan clashes
+	// with on its field
+	CreatePlan(ctx context.Context, user *data.User, farmID string, in PlanInput) (*data.CropPlan, []Conflict, error)
+	GetPlan(ctx context.Context, user *data.User, planID string) (*data.CropPlan, error)
+	ListPlans(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.CropPlan, error)
+	UpdatePlan(ctx context.Context, user *data.User, planID string, in PlanInput) (*data.CropPlan, []Conflict, error)
+	DeletePlan(ctx context.Context, user *data.User, planID string) error
+	// SeasonCalendar lays out a farm's crop plans for a year by field
+	SeasonCalendar(ctx context.Context, user *data.User, farmID string, year int) (*Calendar, error)
+
+	CreateScenario(ctx context.Context, user *data.User, planID string, in ScenarioInput) (*data.PlanScenario, error)
+	GetScenario(ctx context.Context, user *data.User, scenarioID string) (*data.PlanScenario, error)
+	// CompareScenarios projects each of a plan's scenarios, most profitable first
+	CompareScenarios(ctx context.Context, user *data.User, planID string) (*ScenarioComparison, error)
+	UpdateScenario(ctx context.Context, user *data.User, scenarioID string, in ScenarioInput) (*data.PlanScenario, error)
+	DeleteScenario(ctx context.Context, user *data.User, scenarioID string) error
+
+	CreateIncident(ctx context.Context, user *data.User, farmID string, in IncidentInput) (*data.CropIncident, error)
+	GetIncident(ctx context.Context, user *data.User, cropIncidentID string) (*data.CropIncident, error)
+	ListIncidents(ctx context.Context, user *data.User, farmID string, filter data.CropIncidentFilter) ([]*data.CropIncident, error)
+	UpdateIncident(ctx context.Context, user *data.User, cropIncidentID string, in IncidentInput) (*data.CropIncident, error)
+	DeleteIncident(ctx context.Context, user *data.User, cropIncidentID string) error
+	// IncidentHistory groups a farm's incidents by pest or disease, the most
+	// frequent first
+	IncidentHistory(ctx context.Context, user *data.User, farmID string, filter data.CropIncidentFilter) ([]data.IncidentHistory, error)
+}
+
+// cropService implements Service on top of the crop repository
+type cropService struct {
+	crops     data.CropInterface
+	plans     data.CropPlanInterface
+	scenarios data.PlanScenarioInterface
+	incidents data.CropIncidentInterface
+	fields    data.FieldInterface
+	seasons   data.SeasonInterface
+	tags      data.TagInterface
+	providers data.ServiceProviderInterface
+	locks     lock.Checker
+	farms     farm.Service
+}
+
+// New creates the crop service
+func New(crops data.CropInterface, plans data.CropPlanInterface, scenarios data.PlanScenarioInterface, incidents data.CropIncidentInterface,
+	fields data.FieldInterface, seasons data.SeasonInterface, tags data.TagInterface, providers data.ServiceProviderInterface,
+	locks lock.Checker, farms farm.Service) Service {
+	return &cropService{crops: crops, plans: plans, scenarios: scenarios, incidents: incidents, fields: fields, seasons: seasons,
+		tags: tags, providers: providers, locks: locks, farms: farms}
+}
+
+// Create adds a crop to one of the user's farms, defaulting to Growing
+func (s *cropService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Crop, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	crop, err := s.newCrop(ctx, farmID, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.crops.Insert(ctx, crop); err != nil {
+		return nil, fmt.Errorf("creating crop: %w", err)
+	}
+	return crop, nil
+}
+
+// CreateBatch adds several crops to one of the user's farms in a single
+// transaction, leaving out those that fail their checks
+func (s *cropService) CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.Crop, []error, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	crops := make([]*data.Crop, len(ins))
+	errs := make([]error, len(ins))
+	var valid []*data.Crop
+	for i, in := range ins {
+		crops[i], errs[i] = s.newCrop(ctx, farmID, in)
+		if errs[i] != nil && service.KindOf(errs[i]) == service.KindInternal {
+			return nil, nil, errs[i]
+		}
+		if crops[i] != nil {
+			valid = append(valid, crops[i])
+		}
+	}
+	if len(valid) > 0 {
+		if err := s.crops.InsertMany(ctx, valid); err != nil {
+			return nil, nil, fmt.Errorf("creating crops: %w", err)
+		}
+	}
+	return crops, errs, nil
+}
+
+// newCrop builds a crop on farmID from in, defaulting to Growing. Its harvest
+// date may not be in a locked period.
+func (s *cropService) newCrop(ctx context.Context, farmID string, in Input) (*data.Crop, error) {
+	if in.Status == "" {
+		in.Status = "Growing"
+	}
+
+	if in.HarvestDate != nil {
+		if err := s.locks.Check(ctx, farmID, *in.HarvestDate); err != nil {
+			return nil, err
+		}
+	}
+
+	crop := &data.Crop{
+		FarmID:       farmID,
+		Name:         in.Name,
+		PlantingDate: in.PlantingDate,
+		HarvestDate:  in.HarvestDate,
+		Quantity:     in.Quantity,
+		Status:       in.Status,
+		Notes:        in.Notes,
+	}
+	if err := s.place(ctx, crop, in.FieldID); err != nil {
+		return nil, err
+	}
+	if err := s.linkSeason(ctx, crop, in.SeasonID); err != nil {
+		return nil, err
+	}
+	return crop, nil
+}
+
+// Get returns a crop on one of the user's farms
+func (s *cropService) Get(ctx context.Context, user *data.User, cropID string) (*data.Crop, error) {
+	crop, err := s.crops.GetByCropID(ctx, cropID)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop: %w", err)
+	}
+	if crop == nil {
+		return nil, service.NotFound("crop not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, crop.FarmID, "crop"); err != nil {
+		return nil, err
+	}
+	return crop, nil
+}
+
+// List returns the crops of one of the user's farms
+func (s *cropService) List(ctx context.Context, user *data.User, farmID string, from, to *time.Time, tags []string) ([]*data.Crop, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	crops, err := s.crops.GetByFarmIDAndDateRange(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting crops: %w", err)
+	}
+	return tag.Filter(ctx, s.tags, farmID, tag.RecordCrop, tags, crops, func(c *data.Crop) string { return c.CropID })
+}
+
+// ListAll implements Service
+func (s *cropService) ListAll(ctx context.Context, user *data.User) ([]*data.Crop, error) {
+	farms, err := s.farms.Reachable(ctx, user, "", farm.Read)
+	if err != nil {
+		return nil, err
+	}
+	all := []*data.Crop{}
+	for _, f := range farms {
+		crops, err := s.crops.GetByFarmID(ctx, f.FarmID)
+		if err != nil {
+			return nil, fmt.Errorf("getting crops: %w", err)
+		}
+		for _, crop := range crops {
+			crop.Farm = f
+		}
+		all = append(all, crops...)
+	}
+	return all, nil
+}
+
+// Stats implements Service
+func (s *cropService) Stats(ctx context.Context, user *data.User, farmID string) (*Stats, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	groups, err := s.crops.Stats(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("counting crops: %w", err)
+	}
+
+	stats := &Stats{FarmID: farmID, ByName: map[string]int{}, ByStatus: map[string]int{}, Groups: groups}
+	if stats.Groups == nil {
+		stats.Groups = []data.CropGroup{}
+	}
+	for _, group := range groups {
+		stats.Records += group.Records
+		stats.ByName[group.Name] += group.Records
+		stats.ByStatus[group.Status] += group.Records
+	}
+	return stats, nil
+}
+
+// Update changes the non-zero fields of in on a crop
+func (s *cropService) Update(ctx context.Context, user *data.User, cropID string, in Input) (*data.Crop, error) {
+	return s.Patch(ctx, user, cropID, in, nil)
+}
+
+// Patch implements Service. A nil set updates the fields with a non-zero
+// value, as Update does.
+func (s *cropService) Patch(ctx context.Context, user *data.User, cropID string, in Input, set service.Fields) (*data.Crop, error) {
+	crop, err := s.Get(ctx, user, cropID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.CheckVersion(ctx, "crop", in.Version, crop.Version, crop); err != nil {
+		return nil, err
+	}
+	before := *crop
+
+	if set.Sets("name", in.Name != "") {
+		crop.Name = in.Name
+	}
+	if set.Sets("plantingDate", in.PlantingDate != nil) {
+		crop.PlantingDate = in.PlantingDate
+	}
+	if set.Sets("harvestDate", in.HarvestDate != nil) {
+		crop.HarvestDate = in.HarvestDate
+	}
+	if set.Sets("quantity", in.Quantity > 0) {
+		crop.Quantity = in.Quantity
+	}
+	if set.Sets("status", in.Status != "") {
+		setStatus(crop, in.Status)
+	}
+	if set.Sets("notes", in.Notes != "") {
+		crop.Notes = in.Notes
+	}
+	// A null field or season takes the crop off it, as an empty ID does
+	none := ""
+	if set["fieldId"] && in.FieldID == nil {
+		in.FieldID = &none
+	}
+	if set["seasonId"] && in.SeasonID == nil {
+		in.SeasonID = &none
+	}
+
+	if err := checkDates(crop); err != nil {
+		return nil, err
+	}
+	if err := s.checkHarvestLock(ctx, &before, crop); err != nil {
+		return nil, err
+	}
+
+	if err := s.place(ctx, crop, in.FieldID); err != nil {
+		return nil, err
+	}
+	if err := s.linkSeason(ctx, crop, in.SeasonID); err != nil {
+		return nil, err
+	}
+
+	if err := s.crops.Update(ctx, crop); errors.Is(err, data.ErrStale) {
+		current, err := s.Get(ctx, user, cropID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Stale("crop", current)
+	} else if err != nil {
+		return nil, fmt.Errorf("updating crop: %w", err)
+	}
+	return crop, nil
+}
+
+// setStatus moves crop to status. A crop that becomes Harvested without a
+// harvest date is taken to have been harvested today.
+func setStatus(crop *data.Crop, status string) {
+	if status == "Harvested" && crop.Status != "Harvested" && crop.HarvestDate == nil {
+		today := service.Day(time.Now())
+		crop.HarvestDate = &today
+	}
+	crop.Status = status
+}
+
+// checkHarvestLock refuses a change to a crop's harvest, its date, quantity
+// or status, when the harvest date before or after the change is in a locked
+// period
+func (s *cropService) checkHarvestLock(ctx context.Context, before, after *data.Crop) error {
+	if sameDate(before.HarvestDate, after.HarvestDate) && before.Quantity == after.Quantity && before.Status == after.Status {
+		return nil
+	}
+	var dates []time.Time
+	for _, date := range []*time.Time{before.HarvestDate, after.HarvestDate} {
+		if date != nil {
+			dates = append(dates, *date)
+		}
+	}
+	return s.locks.Check(ctx, after.FarmID, dates...)
+}
+
+// sameDate reports whether a and b are both unset or the same time
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// checkDates rejects a crop harvested before it was planted
+func checkDates(crop *data.Crop) error {
+	if crop.PlantingDate != nil && crop.HarvestDate != nil && crop.HarvestDate.Before(*crop.PlantingDate) {
+		return service.Invalid("harvest date must not be before planting date")
+	}
+	return nil
+}
+
+// UpdateStatus moves several crops of one of the user's farms to status,
+// leaving out those that are not on the farm
+func (s *cropService) UpdateStatus(ctx context.Context, user *data.User, farmID string, cropIDs []string, status string) ([]*StatusChange, []error, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	found, err := s.crops.GetByCropIDs(ctx, cropIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting crops: %w", err)
+	}
+	byID := make(map[string]*data.Crop, len(found))
+	for _, crop := range found {
+		byID[crop.CropID] = crop
+	}
+
+	changes := make([]*StatusChange, len(cropIDs))
+	errs := make([]error, len(cropIDs))
+	var crops []*data.Crop
+	var positions []int
+	seen := map[string]bool{}
+	for i, cropID := range cropIDs {
+		if seen[cropID] {
+			errs[i] = service.Invalid("crop is listed more than once")
+			continue
+		}
+		seen[cropID] = true
+		crop := byID[cropID]
+		if crop == nil || crop.FarmID != farmID {
+			errs[i] = service.NotFound("crop not found on this farm")
+			continue
+		}
+		before := *crop
+		setStatus(crop, status)
+		if err := checkDates(crop); err != nil {
+			errs[i] = err
+			continue
+		}
+		if err := s.checkHarvestLock(ctx, &before, crop); err != nil {
+			if service.KindOf(err) == service.KindInternal {
+				return nil, nil, err
+			}
+			errs[i] = err
+			continue
+		}
+		changes[i] = &StatusChange{Before: &before, Crop: crop}
+		crops = append(crops, crop)
+		positions = append(positions, i)
+	}
+	if len(crops) == 0 {
+		return changes, errs, nil
+	}
+
+	stale, err := s.crops.UpdateMany(ctx, crops)
+	if err != nil {
+		return nil, nil, fmt.Errorf("updating crops: %w", err)
+	}
+	for j, err := range stale {
+		if err == nil {
+			continue
+		}
+		i := positions[j]
+		current, err := s.crops.GetByCropID(ctx, cropIDs[i])
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting crop: %w", err)
+		}
+		changes[i], errs[i] = nil, service.Stale("crop", current)
+	}
+	return changes, errs, nil
+}
+
+// Delete soft deletes a crop. One harvested in a locked period cannot be
+// deleted.
+func (s *cropService) Delete(ctx context.Context, user *data.User, cropID string) error {
+	crop, err := s.Get(ctx, user, cropID)
+	if err != nil {
+		return err
+	}
+	if crop.HarvestDate != nil {
+		if err := s.locks.Check(ctx, crop.FarmID, *crop.HarvestDate); err != nil {
+			return err
+		}
+	}
+	if err := s.crops.DeleteByID(ctx, int(crop.ID)); err != nil {
+		return fmt.Errorf("deleting crop: %w", err)
+	}
+	return nil
+}
+
+// ListDeleted returns the soft-deleted crops of one of the user's farms
+func (s *cropService) ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.Crop, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	crops, err := s.crops.GetDeletedByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted crops: %w", err)
+	}
+	return crops, nil
+}
+
+// Restore undeletes a crop. Its farm must still exist and belong to the user,
+// and its harvest must not be in a locked period.
+func (s *cropService) Restore(ctx context.Context, user *data.User, cropID string) (*data.Crop, error) {
+	crop, err := s.crops.GetDeletedByCropID(ctx, cropID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted crop: %w", err)
+	}
+	if crop == nil {
+		return nil, service.NotFound("deleted crop not found")
+	}
+	if _, err := s.farms.Owned(ctx, user, crop.FarmID); err != nil {
+		return nil, err
+	}
+	if crop.HarvestDate != nil {
+		if err := s.locks.Check(ctx, crop.FarmID, *crop.HarvestDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.crops.RestoreByID(ctx, int(crop.ID)); err != nil {
+		return nil, fmt.Errorf("restoring crop: %w", err)
+	}
+	crop.DeletedAt = gorm.DeletedAt{}
+	return crop, nil
+}
+
+// place sets the field a crop is planted on. The field must be on the crop's
+// farm. nil leaves the field unchanged and "" clears it.
+func (s *cropService) place(ctx context.Context, crop *data.Crop, fieldID *string) error {
+	if fieldID == nil {
+		return nil
+	}
+	if *fieldID == "" {
+		crop.FieldID = nil
+		return nil
+	}
+
+	field, err := s.fields.GetByFieldID(ctx, *fieldID)
+	if err != nil {
+		return fmt.Errorf("getting field: %w", err)
+	}
+	if field == nil || field.FarmID != crop.FarmID {
+		return service.Invalid("field not found on this farm")
+	}
+
+	crop.FieldID = &field.FieldID
+	return nil
+}
+
+// linkSeason links crop to the season named by seasonID; see season.Link
+func (s *cropService) linkSeason(ctx context.Context, crop *data.Crop, seasonID *string) error {
+	link, ok, err := season.Link(ctx, s.seasons, crop.FarmID, seasonID)
+	if ok {
+		crop.SeasonID = link
+	}
+	return err
+}
@@ -0,0 +1,290 @@
+package crop
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/provider"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Incident types, severities and statuses
+const (
+	IncidentPest    = "Pest"
+	IncidentDisease = "Disease"
+
+	SeverityLow      = "Low"
+	SeverityModerate = "Moderate"
+	SeverityHigh     = "High"
+	SeveritySevere   = "Severe"
+
+	IncidentOpen     = "Open"
+	IncidentTreated  = "Treated"
+	IncidentResolved = "Resolved"
+)
+
+// IncidentTypes returns the kinds of crop incident
+func IncidentTypes() []string {
+	return []string{IncidentPest, IncidentDisease}
+}
+
+// Severities returns the incident severities, mildest first
+func Severities() []string {
+	return []string{SeverityLow, SeverityModerate, SeverityHigh, SeveritySevere}
+}
+
+// IncidentStatuses returns the incident statuses
+func IncidentStatuses() []string {
+	return []string{IncidentOpen, IncidentTreated, IncidentResolved}
+}
+
+// IncidentInput holds the editable crop incident fields. On update, zero
+// values are left unchanged. An incident needs a crop or a field; a crop's
+// field is used when no field is given.
+type IncidentInput struct {
+	CropID            string
+	FieldID           string
+	Type              string
+	Name              string
+	Severity          string
+	ObservedAt        *time.Time // Defaults to now
+	AffectedArea      float64
+	Treatment         string
+	Cost              float64
+	Status            string // Defaults to Open
+	Notes             string
+	ServiceProviderID string // Contractor who treated it, from the farm's directory
+}
+
+// CreateIncident reports a pest or disease on one of the user's farms and
+// books its treatment cost as a Crop Protection expense
+func (s *cropService) CreateIncident(ctx context.Context, user *data.User, farmID string, in IncidentInput) (*data.CropIncident, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	incident := &data.CropIncident{
+		FarmID:       farmID,
+		Type:         in.Type,
+		Name:         strings.TrimSpace(in.Name),
+		Severity:     in.Severity,
+		ObservedAt:   time.Now(),
+		AffectedArea: in.AffectedArea,
+		Treatment:    in.Treatment,
+		Cost:         in.Cost,
+		Status:       in.Status,
+		Notes:        in.Notes,
+	}
+	if in.ObservedAt != nil {
+		incident.ObservedAt = *in.ObservedAt
+	}
+	if incident.Status == "" {
+		incident.Status = IncidentOpen
+	}
+	if err := s.placeIncident(ctx, incident, in.CropID, in.FieldID); err != nil {
+		return nil, err
+	}
+	if err := s.treatedBy(ctx, incident, in.ServiceProviderID); err != nil {
+		return nil, err
+	}
+	if incident.CropID == nil && incident.FieldID == nil {
+		return nil, service.Invalid("an incident needs a crop or a field")
+	}
+	if err := checkIncident(incident); err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, farmID, incident.ObservedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.incidents.Insert(ctx, incident); err != nil {
+		return nil, fmt.Errorf("creating crop incident: %w", err)
+	}
+	return incident, nil
+}
+
+// GetIncident returns an incident on one of the user's farms
+func (s *cropService) GetIncident(ctx context.Context, user *data.User, cropIncidentID string) (*data.CropIncident, error) {
+	incident, err := s.incidents.GetByCropIncidentID(ctx, cropIncidentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop incident: %w", err)
+	}
+	if incident == nil {
+		return nil, service.NotFound("crop incident not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, incident.FarmID, "crop incident"); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// ListIncidents returns the incidents on one of the user's farms, most
+// recent first
+func (s *cropService) ListIncidents(ctx context.Context, user *data.User, farmID string, filter data.CropIncidentFilter) ([]*data.CropIncident, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	incidents, err := s.incidents.GetByFarmID(ctx, farmID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// UpdateIncident changes the non-zero fields of in on an incident. Moving it
+// to Resolved records when.
+func (s *cropService) UpdateIncident(ctx context.Context, user *data.User, cropIncidentID string, in IncidentInput) (*data.CropIncident, error) {
+	incident, err := s.GetIncident(ctx, user, cropIncidentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, incident.FarmID, incident.ObservedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.placeIncident(ctx, incident, in.CropID, in.FieldID); err != nil {
+		return nil, err
+	}
+	if err := s.treatedBy(ctx, incident, in.ServiceProviderID); err != nil {
+		return nil, err
+	}
+	if in.Type != "" {
+		incident.Type = in.Type
+	}
+	if in.Name != "" {
+		incident.Name = strings.TrimSpace(in.Name)
+	}
+	if in.Severity != "" {
+		incident.Severity = in.Severity
+	}
+	if in.ObservedAt != nil {
+		incident.ObservedAt = *in.ObservedAt
+	}
+	if in.AffectedArea > 0 {
+		incident.AffectedArea = in.AffectedArea
+	}
+	if in.Treatment != "" {
+		incident.Treatment = in.Treatment
+	}
+	if in.Cost > 0 {
+		incident.Cost = in.Cost
+	}
+	if in.Status != "" {
+		incident.Status = in.Status
+	}
+	if in.Notes != "" {
+		incident.Notes = in.Notes
+	}
+	if err := checkIncident(incident); err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, incident.FarmID, incident.ObservedAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.incidents.Update(ctx, incident); err != nil {
+		return nil, fmt.Errorf("updating crop incident: %w", err)
+	}
+	return incident, nil
+}
+
+// DeleteIncident soft deletes an incident and its expense
+func (s *cropService) DeleteIncident(ctx context.Context, user *data.User, cropIncidentID string) error {
+	incident, err := s.GetIncident(ctx, user, cropIncidentID)
+	if err != nil {
+		return err
+	}
+	if err := s.locks.Check(ctx, incident.FarmID, incident.ObservedAt); err != nil {
+		return err
+	}
+	if err := s.incidents.DeleteByID(ctx, int(incident.ID)); err != nil {
+		return fmt.Errorf("deleting crop incident: %w", err)
+	}
+	return nil
+}
+
+// IncidentHistory groups the incidents on one of the user's farms by pest or
+// disease, the most frequent first, so recurring problems stand out
+func (s *cropService) IncidentHistory(ctx context.Context, user *data.User, farmID string, filter data.CropIncidentFilter) ([]data.IncidentHistory, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	history, err := s.incidents.History(ctx, farmID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop incident history: %w", err)
+	}
+	return history, nil
+}
+
+// treatedBy links an incident to the provider from the farm's directory who
+// treated it. An empty ID leaves the incident as it is.
+func (s *cropService) treatedBy(ctx context.Context, incident *data.CropIncident, serviceProviderID string) error {
+	contractor, err := provider.Lookup(ctx, s.providers, incident.FarmID, serviceProviderID)
+	if err != nil {
+		return err
+	}
+	if contractor != nil {
+		incident.ServiceProviderID = &contractor.ServiceProviderID
+		incident.ServiceProvider = contractor
+	}
+	return nil
+}
+
+// placeIncident sets the crop and field an incident was found on. Both must
+// be on the incident's farm; a crop brings its field unless one is given.
+// Empty IDs leave the incident as it is.
+func (s *cropService) placeIncident(ctx context.Context, incident *data.CropIncident, cropID, fieldID string) error {
+	if cropID != "" {
+		crop, err := s.crops.GetByCropID(ctx, cropID)
+		if err != nil {
+			return fmt.Errorf("getting crop: %w", err)
+		}
+		if crop == nil || crop.FarmID != incident.FarmID {
+			return service.Invalid("crop not found on this farm")
+		}
+		incident.CropID = &crop.CropID
+		if fieldID == "" && crop.FieldID != nil {
+			incident.FieldID = crop.FieldID
+		}
+	}
+	if fieldID != "" {
+		field, err := s.fields.GetByFieldID(ctx, fieldID)
+		if err != nil {
+			return fmt.Errorf("getting field: %w", err)
+		}
+		if field == nil || field.FarmID != incident.FarmID {
+			return service.Invalid("field not found on this farm")
+		}
+		incident.FieldID = &field.FieldID
+	}
+	return nil
+}
+
+// checkIncident checks an incident's fields and stamps when it was resolved
+func checkIncident(incident *data.CropIncident) error {
+	switch {
+	case incident.Type != IncidentPest && incident.Type != IncidentDisease:
+		return service.Invalid("type must be one of: " + strings.Join(IncidentTypes(), ", "))
+	case incident.Name == "":
+		return service.Invalid("name of the pest or disease is required")
+	case !slices.Contains(Severities(), incident.Severity):
+		return service.Invalid("severity must be one of: " + strings.Join(Severities(), ", "))
+	case !slices.Contains(IncidentStatuses(), incident.Status):
+		return service.Invalid("status must be one of: " + strings.Join(IncidentStatuses(), ", "))
+	case incident.Cost < 0 || incident.AffectedArea < 0:
+		return service.Invalid("cost and affected area must not be negative")
+	}
+
+	if incident.Status == IncidentResolved && incident.ResolvedAt == nil {
+		now := time.Now()
+		incident.ResolvedAt = &now
+	}
+	if incident.Status != IncidentResolved {
+		incident.ResolvedAt = nil
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package crop
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PlanInput holds the editable crop plan fields. On update, zero values are
+// left unchanged and a non-nil Inputs replaces the budgeted inputs.
+type PlanInput struct {
+	FieldID         string
+	CropName        string
+	Variety         string
+	PlantingStart   *time.Time
+	PlantingEnd     *time.Time
+	ExpectedHarvest *time.Time
+	ExpectedYield   float64
+	Status          string
+	Notes           string
+	Inputs          []BudgetItem
+}
+
+// BudgetItem is one budgeted input of a crop plan
+type BudgetItem struct {
+	Item     string
+	Quantity float64
+	Unit     string
+	Cost     float64
+}
+
+// Conflict is a pair of plans that would hold the same field at the same time
+type Conflict struct {
+	FieldID     string    `json:"fieldId"`
+	FieldName   string    `json:"fieldName"`
+	PlanID      string    `json:"planId"`
+	OtherPlanID string    `json:"otherPlanId"`
+	From        time.Time `json:"from"` // When both plans start holding the field
+	To          time.Time `json:"to"`   // When the first of them is harvested
+}
+
+// FieldSeason lists the plans for one field in a season calendar
+type FieldSeason struct {
+	FieldID   string           `json:"fieldId"`
+	FieldName string           `json:"fieldName"`
+	Plans     []*data.CropPlan `json:"plans"`
+}
+
+// Calendar is a farm's season calendar: its crop plans for a year by field,
+// the clashes between them and the total budget
+type Calendar struct {
+	FarmID       string        `json:"farmId"`
+	Year         int           `json:"year"`
+	Fields       []FieldSeason `json:"fields"`
+	Conflicts    []Conflict    `json:"conflicts"`
+	BudgetedCost float64       `json:"budgetedCost"`
+}
+
+// CreatePlan adds a crop plan for a field on one of the user's farms. The
+// plan is saved even when it clashes with another; the clashes are returned
+// so the farmer can resolve them.
+func (s *cropService) CreatePlan(ctx context.Context, user *data.User, farmID string, in PlanInput) (*data.CropPlan, []Conflict, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	if in.Status == "" {
+		in.Status = "Planned"
+	}
+
+	plan := &data.CropPlan{
+		FarmID:        farmID,
+		CropName:      in.CropName,
+		Variety:       in.Variety,
+		ExpectedYield: in.ExpectedYield,
+		Status:        in.Status,
+		Notes:         in.Notes,
+		Inputs:        budgetInputs(in.Inputs),
+	}
+	if in.PlantingStart != nil {
+		plan.PlantingStart = *in.PlantingStart
+	}
+	if in.PlantingEnd != nil {
+		plan.PlantingEnd = *in.PlantingEnd
+	}
+	if in.ExpectedHarvest != nil {
+		plan.ExpectedHarvest = *in.ExpectedHarvest
+	}
+	plan.CalculateBudget()
+
+	if err := s.planField(ctx, plan, in.FieldID); err != nil {
+		return nil, nil, err
+	}
+	if err := checkPlanDates(plan); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.plans.Insert(ctx, plan); err != nil {
+		return nil, nil, fmt.Errorf("creating crop plan: %w", err)
+	}
+
+	conflicts, err := s.planConflicts(ctx, plan)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, conflicts, nil
+}
+
+// GetPlan returns a crop plan on one of the user's farms
+func (s *cropService) GetPlan(ctx context.Context, user *data.User, planID string) (*data.CropPlan, error) {
+	plan, err := s.plans.GetByCropPlanID(ctx, planID)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop plan: %w", err)
+	}
+	if plan == nil {
+		return nil, service.NotFound("crop plan not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, plan.FarmID, "crop plan"); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ListPlans returns the crop plans of one of the user's farms that hold their
+// field at some point in the optional [from, to) period
+func (s *cropService) ListPlans(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.CropPlan, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	plans, err := s.plans.GetByFarmID(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop plans: %w", err)
+	}
+	return plans, nil
+}
+
+// UpdatePlan changes the non-zero fields of in on a crop plan and returns the
+// plans it now clashes with
+func (s *cropService) UpdatePlan(ctx context.Context, user *data.User, planID string, in PlanInput) (*data.CropPlan, []Conflict, error) {
+	plan, err := s.GetPlan(ctx, user, planID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if in.CropName != "" {
+		plan.CropName = in.CropName
+	}
+	if in.Variety != "" {
+		plan.Variety = in.Variety
+	}
+	if in.PlantingStart != nil {
+		plan.PlantingStart = *in.PlantingStart
+	}
+	if in.PlantingEnd != nil {
+		plan.PlantingEnd = *in.PlantingEnd
+	}
+	if in.ExpectedHarvest != nil {
+		plan.ExpectedHarvest = *in.ExpectedHarvest
+	}
+	if in.ExpectedYield > 0 {
+		plan.ExpectedYield = in.ExpectedYield
+	}
+	if in.Status != "" {
+		plan.Status = in.Status
+	}
+	if in.Notes != "" {
+		plan.Notes = in.Notes
+	}
+	if in.Inputs != nil {
+		plan.Inputs = budgetInputs(in.Inputs)
+		plan.CalculateBudget()
+	}
+
+	if in.FieldID != "" {
+		if err := s.planField(ctx, plan, in.FieldID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := checkPlanDates(plan); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.plans.Update(ctx, plan); err != nil {
+		return nil, nil, fmt.Errorf("updating crop plan: %w", err)
+	}
+
+	conflicts, err := s.planConflicts(ctx, plan)
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, conflicts, nil
+}
+
+// DeletePlan soft deletes a crop plan
+func (s *cropService) DeletePlan(ctx context.Context, user *data.User, planID string) error {
+	plan, err := s.GetPlan(ctx, user, planID)
+	if err != nil {
+		return err
+	}
+	if err := s.plans.DeleteByID(ctx, int(plan.ID)); err != nil {
+		return fmt.Errorf("deleting crop plan: %w", err)
+	}
+	return nil
+}
+
+// SeasonCalendar lays out a farm's crop plans for a year by field and lists
+// the pairs of plans, cancelled ones excepted, that clash on a field
+func (s *cropService) SeasonCalendar(ctx context.Context, user *data.User, farmID string, year int) (*Calendar, error) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+	plans, err := s.ListPlans(ctx, user, farmID, &from, &to)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &Calendar{FarmID: farmID, Year: year, Fields: []FieldSeason{}, Conflicts: []Conflict{}}
+	byField := map[string]int{}
+	for _, plan := range plans {
+		i, ok := byField[plan.FieldID]
+		if !ok {
+			i = len(calendar.Fields)
+			byField[plan.FieldID] = i
+			calendar.Fields = append(calendar.Fields, FieldSeason{FieldID: plan.FieldID, FieldName: fieldName(plan)})
+		}
+		calendar.Fields[i].Plans = append(calendar.Fields[i].Plans, plan)
+		if plan.Status != "Cancelled" {
+			calendar.BudgetedCost += plan.BudgetedCost
+		}
+	}
+	sort.Slice(calendar.Fields, func(i, j int) bool {
+		return calendar.Fields[i].FieldName < calendar.Fields[j].FieldName
+	})
+
+	// Plans within a field are in planting order, so each only needs
+	// comparing with the ones after it
+	for _, season := range calendar.Fields {
+		for i, plan := range season.Plans {
+			if plan.Status == "Cancelled" {
+				continue
+			}
+			for _, other := range season.Plans[i+1:] {
+				if other.Status != "Cancelled" && plan.Overlaps(other) {
+					calendar.Conflicts = append(calendar.Conflicts, conflict(plan, other))
+				}
+			}
+		}
+	}
+	return calendar, nil
+}
+
+// planField sets the field a plan is for. The field must be on the plan's farm.
+func (s *cropService) planField(ctx context.Context, plan *data.CropPlan, fieldID string) error {
+	field, err := s.fields.GetByFieldID(ctx, fieldID)
+	if err != nil {
+		return fmt.Errorf("getting field: %w", err)
+	}
+	if field == nil || field.FarmID != plan.FarmID {
+		return service.Invalid("field not found on this farm")
+	}
+	plan.FieldID = field.FieldID
+	plan.Field = field
+	return nil
+}
+
+// planConflicts lists the other plans that would hold plan's field at the
+// same time. A cancelled plan clashes with nothing.
+func (s *cropService) planConflicts(ctx context.Context, plan *data.CropPlan) ([]Conflict, error) {
+	if plan.Status == "Cancelled" {
+		return nil, nil
+	}
+	others, err := s.plans.GetOverlapping(ctx, plan)
+	if err != nil {
+		return nil, fmt.Errorf("checking crop plan conflicts: %w", err)
+	}
+	conflicts := make([]Conflict, 0, len(others))
+	for _, other := range others {
+		conflicts = append(conflicts, conflict(plan, other))
+	}
+	return conflicts, nil
+}
+
+// checkPlanDates requires the planting window to come in order and the
+// expected harvest not to precede it
+func checkPlanDates(plan *data.CropPlan) error {
+	if plan.PlantingEnd.Before(plan.PlantingStart) {
+		return service.Invalid("planting window must not end before it starts")
+	}
+	if plan.ExpectedHarvest.Before(plan.PlantingEnd) {
+		return service.Invalid("expected harvest must not be before the end of the planting window")
+	}
+	return nil
+}
+
+// conflict describes the clash between two overlapping plans on a field
+func conflict(plan, other *data.CropPlan) Conflict {
+	c := Conflict{
+		FieldID:     plan.FieldID,
+		FieldName:   fieldName(plan),
+		PlanID:      plan.CropPlanID,
+		OtherPlanID: other.CropPlanID,
+		From:        plan.PlantingStart,
+		To:          plan.ExpectedHarvest,
+	}
+	if other.PlantingStart.After(c.From) {
+		c.From = other.PlantingStart
+	}
+	if other.ExpectedHarvest.Before(c.To) {
+		c.To = other.ExpectedHarvest
+	}
+	return c
+}
+
+// fieldName returns the name of a plan's field, if loaded
+func fieldName(plan *data.CropPlan) string {
+	if plan.Field == nil {
+		return ""
+	}
+	return plan.Field.Name
+}
+
+// budgetInputs converts budget items to plan inputs
+func budgetInputs(items []BudgetItem) []data.CropPlanInput {
+	inputs := make([]data.CropPlanInput, 0, len(items))
+	for _, item := range items {
+		inputs = append(inputs, data.CropPlanInput{
+			Item:     item.Item,
+			Quantity: item.Quantity,
+			Unit:     item.Unit,
+			Cost:     item.Cost,
+		})
+	}
+	return inputs
+}
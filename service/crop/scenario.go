@@ -0,0 +1,189 @@
+package crop
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ScenarioInput holds the editable scenario fields. On update, zero values
+// are left unchanged; a nil ExpectedYield, InputCostChange or OtherCosts is
+// left unchanged too, so each can be set back to zero.
+type ScenarioInput struct {
+	Name            string
+	PricePerKg      float64
+	ExpectedYield   *float64
+	InputCostChange *float64
+	OtherCosts      *float64
+	Notes           string
+}
+
+// ScenarioComparison sets a plan's scenarios side by side, most profitable
+// first
+type ScenarioComparison struct {
+	PlanID        string               `json:"planId"`
+	CropName      string               `json:"cropName"`
+	ExpectedYield float64              `json:"expectedYield"`
+	BudgetedCost  float64              `json:"budgetedCost"`
+	Scenarios     []*data.PlanScenario `json:"scenarios"`
+	Best          string               `json:"best,omitempty"` // Name of the most profitable scenario
+}
+
+// CreateScenario saves a named what-if scenario for a crop plan
+func (s *cropService) CreateScenario(ctx context.Context, user *data.User, planID string, in ScenarioInput) (*data.PlanScenario, error) {
+	plan, err := s.GetPlan(ctx, user, planID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.uniqueScenarioName(ctx, plan.CropPlanID, in.Name, ""); err != nil {
+		return nil, err
+	}
+
+	scenario := &data.PlanScenario{
+		CropPlanID:    plan.CropPlanID,
+		FarmID:        plan.FarmID,
+		Name:          in.Name,
+		PricePerKg:    in.PricePerKg,
+		ExpectedYield: in.ExpectedYield,
+		Notes:         in.Notes,
+	}
+	if in.InputCostChange != nil {
+		scenario.InputCostChange = *in.InputCostChange
+	}
+	if in.OtherCosts != nil {
+		scenario.OtherCosts = *in.OtherCosts
+	}
+	if err := s.scenarios.Insert(ctx, scenario); err != nil {
+		return nil, fmt.Errorf("creating scenario: %w", err)
+	}
+	scenario.Projection = scenario.Project(plan)
+	return scenario, nil
+}
+
+// GetScenario returns a scenario of one of the user's plans with its projection
+func (s *cropService) GetScenario(ctx context.Context, user *data.User, scenarioID string) (*data.PlanScenario, error) {
+	scenario, plan, err := s.scenario(ctx, user, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	scenario.Projection = scenario.Project(plan)
+	return scenario, nil
+}
+
+// CompareScenarios projects every scenario of a plan against the plan as it
+// stands
+func (s *cropService) CompareScenarios(ctx context.Context, user *data.User, planID string) (*ScenarioComparison, error) {
+	plan, err := s.GetPlan(ctx, user, planID)
+	if err != nil {
+		return nil, err
+	}
+	scenarios, err := s.scenarios.GetByCropPlanID(ctx, plan.CropPlanID)
+	if err != nil {
+		return nil, fmt.Errorf("getting scenarios: %w", err)
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Projection = scenario.Project(plan)
+	}
+	sort.SliceStable(scenarios, func(i, j int) bool {
+		return scenarios[i].Projection.Profit > scenarios[j].Projection.Profit
+	})
+
+	comparison := &ScenarioComparison{
+		PlanID:        plan.CropPlanID,
+		CropName:      plan.CropName,
+		ExpectedYield: plan.ExpectedYield,
+		BudgetedCost:  plan.BudgetedCost,
+		Scenarios:     scenarios,
+	}
+	if len(scenarios) > 0 {
+		comparison.Best = scenarios[0].Name
+	}
+	return comparison, nil
+}
+
+// UpdateScenario changes the set fields of in on a scenario
+func (s *cropService) UpdateScenario(ctx context.Context, user *data.User, scenarioID string, in ScenarioInput) (*data.PlanScenario, error) {
+	scenario, plan, err := s.scenario(ctx, user, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" && in.Name != scenario.Name {
+		if err := s.uniqueScenarioName(ctx, plan.CropPlanID, in.Name, scenario.PlanScenarioID); err != nil {
+			return nil, err
+		}
+		scenario.Name = in.Name
+	}
+	if in.PricePerKg > 0 {
+		scenario.PricePerKg = in.PricePerKg
+	}
+	if in.ExpectedYield != nil {
+		scenario.ExpectedYield = in.ExpectedYield
+	}
+	if in.InputCostChange != nil {
+		scenario.InputCostChange = *in.InputCostChange
+	}
+	if in.OtherCosts != nil {
+		scenario.OtherCosts = *in.OtherCosts
+	}
+	if in.Notes != "" {
+		scenario.Notes = in.Notes
+	}
+
+	if err := s.scenarios.Update(ctx, scenario); err != nil {
+		return nil, fmt.Errorf("updating scenario: %w", err)
+	}
+	scenario.Projection = scenario.Project(plan)
+	return scenario, nil
+}
+
+// DeleteScenario soft deletes a scenario
+func (s *cropService) DeleteScenario(ctx context.Context, user *data.User, scenarioID string) error {
+	scenario, _, err := s.scenario(ctx, user, scenarioID)
+	if err != nil {
+		return err
+	}
+	if err := s.scenarios.DeleteByID(ctx, int(scenario.ID)); err != nil {
+		return fmt.Errorf("deleting scenario: %w", err)
+	}
+	return nil
+}
+
+// scenario loads a scenario and the plan it belongs to, checking the plan is
+// on one of the user's farms
+func (s *cropService) scenario(ctx context.Context, user *data.User, scenarioID string) (*data.PlanScenario, *data.CropPlan, error) {
+	scenario, err := s.scenarios.GetByPlanScenarioID(ctx, scenarioID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting scenario: %w", err)
+	}
+	if scenario == nil {
+		return nil, nil, service.NotFound("scenario not found")
+	}
+	plan, err := s.GetPlan(ctx, user, scenario.CropPlanID)
+	if service.KindOf(err) == service.KindNotFound {
+		return nil, nil, service.NotFound("scenario not found")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return scenario, plan, nil
+}
+
+// uniqueScenarioName rejects a name already used by another scenario of the
+// plan, so scenarios can be told apart when compared
+func (s *cropService) uniqueScenarioName(ctx context.Context, planID, name, exceptID string) error {
+	scenarios, err := s.scenarios.GetByCropPlanID(ctx, planID)
+	if err != nil {
+		return fmt.Errorf("getting scenarios: %w", err)
+	}
+	for _, other := range scenarios {
+		if other.PlanScenarioID != exceptID && strings.EqualFold(other.Name, name) {
+			return service.Conflict(fmt.Sprintf("the plan already has a scenario named %q", other.Name))
+		}
+	}
+	return nil
+}
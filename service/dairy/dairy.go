@@ -0,0 +1,302 @@
+// Package dairy connects farms to milk collection centers. A center's own
+// system posts each farmer's deliveries with an API key; every delivery is
+// the farm's production record, accepted milk becomes a sale in its ledger,
+// and the deliveries add up to monthly payment statements for both sides.
+package dairy
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// apiKeyPrefix starts every collection center API key
+const apiKeyPrefix = "mcc_"
+
+// CenterInput holds the editable collection center fields. On update, zero
+// values are left unchanged.
+type CenterInput struct {
+	Name          string
+	Location      string
+	PricePerLitre float64
+	Active        *bool
+}
+
+// Service is the dairy domain service
+type Service interface {
+	// CreateCenter registers a collection center run by user and returns
+	// its API key, which is not shown again
+	CreateCenter(ctx context.Context, user *data.User, in CenterInput) (*data.CollectionCenter, string, error)
+	ListCenters(ctx context.Context, user *data.User) ([]*data.CollectionCenter, error)
+	GetCenter(ctx context.Context, user *data.User, collectionCenterID string) (*data.CollectionCenter, error)
+	UpdateCenter(ctx context.Context, user *data.User, collectionCenterID string, in CenterInput) (*data.CollectionCenter, error)
+	// RotateKey replaces a center's API key, returning the new one
+	RotateKey(ctx context.Context, user *data.User, collectionCenterID string) (*data.CollectionCenter, string, error)
+	// Authenticate returns the active center an API key belongs to
+	Authenticate(ctx context.Context, apiKey string) (*data.CollectionCenter, error)
+
+	// LinkFarm registers one of user's farms with a center under the
+	// supplier number the center knows them by
+	LinkFarm(ctx context.Context, user *data.User, collectionCenterID, farmID, supplierNumber string) (*data.MilkSupplier, error)
+	// ListSuppliers returns the farms registered with a center user runs
+	ListSuppliers(ctx context.Context, user *data.User, collectionCenterID string) ([]*data.MilkSupplier, error)
+	// ListFarmCenters returns the centers one of user's farms is registered with
+	ListFarmCenters(ctx context.Context, user *data.User, farmID string) ([]*data.MilkSupplier, error)
+	// UnlinkFarm removes a registration, by the farm's owner or the center's manager
+	UnlinkFarm(ctx context.Context, user *data.User, milkSupplierID string) error
+
+	// RecordDelivery creates or, for a receipt number already posted,
+	// corrects a delivery to a center
+	RecordDelivery(ctx context.Context, center *data.CollectionCenter, in DeliveryInput) (*data.MilkDelivery, bool, error)
+	CenterDeliveries(ctx context.Context, center *data.CollectionCenter, from, to *time.Time) ([]*data.MilkDelivery, error)
+	// CenterStatements returns a month's payment statement for each of the
+	// center's suppliers
+	CenterStatements(ctx context.Context, center *data.CollectionCenter, month string) ([]*Statement, error)
+	FarmDeliveries(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.MilkDelivery, error)
+	// FarmStatements returns a month's payment statement from each center
+	// the farm delivered to
+	FarmStatements(ctx context.Context, user *data.User, farmID, month string) ([]*Statement, error)
+}
+
+// dairyService implements Service on top of the collection center and milk
+// delivery repositories
+type dairyService struct {
+	centers    data.CollectionCenterInterface
+	deliveries data.MilkDeliveryInterface
+	locks      lock.Checker
+	farms      farm.Service
+}
+
+// New creates the dairy service
+func New(centers data.CollectionCenterInterface, deliveries data.MilkDeliveryInterface, locks lock.Checker, farms farm.Service) Service {
+	return &dairyService{centers: centers, deliveries: deliveries, locks: locks, farms: farms}
+}
+
+// newAPIKey generates a random API key and returns it with its hash
+func newAPIKey() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(b)
+	return key, hashAPIKey(key), nil
+}
+
+// hashAPIKey returns the hex SHA-256 of an API key, as stored
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// managed returns a center if it exists and user runs it
+func (s *dairyService) managed(ctx context.Context, user *data.User, collectionCenterID string) (*data.CollectionCenter, error) {
+	center, err := s.centers.GetByCollectionCenterID(ctx, collectionCenterID)
+	if err != nil {
+		return nil, fmt.Errorf("getting collection center: %w", err)
+	}
+	if center == nil || center.ManagerID != user.UserID {
+		return nil, service.Forbidden("collection center not found or access denied")
+	}
+	return center, nil
+}
+
+// CreateCenter registers a collection center run by user
+func (s *dairyService) CreateCenter(ctx context.Context, user *data.User, in CenterInput) (*data.CollectionCenter, string, error) {
+	if in.PricePerLitre < 0 {
+		return nil, "", service.Invalid("pricePerLitre cannot be negative")
+	}
+	key, hash, err := newAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating API key: %w", err)
+	}
+
+	center := &data.CollectionCenter{
+		Name:          in.Name,
+		Location:      in.Location,
+		ManagerID:     user.UserID,
+		PricePerLitre: in.PricePerLitre,
+		APIKeyHash:    hash,
+		APIKeyPrefix:  key[:len(apiKeyPrefix)+6],
+		Active:        true,
+	}
+	if in.Active != nil {
+		center.Active = *in.Active
+	}
+	if err := s.centers.Insert(ctx, center); err != nil {
+		return nil, "", fmt.Errorf("creating collection center: %w", err)
+	}
+	return center, key, nil
+}
+
+// ListCenters returns the centers user runs
+func (s *dairyService) ListCenters(ctx context.Context, user *data.User) ([]*data.CollectionCenter, error) {
+	centers, err := s.centers.GetByManagerID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting collection centers: %w", err)
+	}
+	return centers, nil
+}
+
+// GetCenter returns a center user runs
+func (s *dairyService) GetCenter(ctx context.Context, user *data.User, collectionCenterID string) (*data.CollectionCenter, error) {
+	return s.managed(ctx, user, collectionCenterID)
+}
+
+// UpdateCenter updates a center user runs
+func (s *dairyService) UpdateCenter(ctx context.Context, user *data.User, collectionCenterID string, in CenterInput) (*data.CollectionCenter, error) {
+	center, err := s.managed(ctx, user, collectionCenterID)
+	if err != nil {
+		return nil, err
+	}
+	if in.PricePerLitre < 0 {
+		return nil, service.Invalid("pricePerLitre cannot be negative")
+	}
+
+	if in.Name != "" {
+		center.Name = in.Name
+	}
+	if in.Location != "" {
+		center.Location = in.Location
+	}
+	if in.PricePerLitre > 0 {
+		center.PricePerLitre = in.PricePerLitre
+	}
+	if in.Active != nil {
+		center.Active = *in.Active
+	}
+	if err := s.centers.Update(ctx, center); err != nil {
+		return nil, fmt.Errorf("updating collection center: %w", err)
+	}
+	return center, nil
+}
+
+// RotateKey replaces a center's API key; the old key stops working at once
+func (s *dairyService) RotateKey(ctx context.Context, user *data.User, collectionCenterID string) (*data.CollectionCenter, string, error) {
+	center, err := s.managed(ctx, user, collectionCenterID)
+	if err != nil {
+		return nil, "", err
+	}
+	key, hash, err := newAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating API key: %w", err)
+	}
+	center.APIKeyHash = hash
+	center.APIKeyPrefix = key[:len(apiKeyPrefix)+6]
+	if err := s.centers.Update(ctx, center); err != nil {
+		return nil, "", fmt.Errorf("updating collection center: %w", err)
+	}
+	return center, key, nil
+}
+
+// Authenticate returns the active center an API key belongs to
+func (s *dairyService) Authenticate(ctx context.Context, apiKey string) (*data.CollectionCenter, error) {
+	if !strings.HasPrefix(apiKey, apiKeyPrefix) {
+		return nil, service.Unauthorized("invalid API key")
+	}
+	center, err := s.centers.GetByAPIKeyHash(ctx, hashAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("getting collection center: %w", err)
+	}
+	if center == nil || !center.Active {
+		return nil, service.Unauthorized("invalid API key")
+	}
+	return center, nil
+}
+
+// LinkFarm registers one of user's farms with a center. Only a farm's owner
+// can do this, so a center can only post deliveries for farms that agreed.
+func (s *dairyService) LinkFarm(ctx context.Context, user *data.User, collectionCenterID, farmID, supplierNumber string) (*data.MilkSupplier, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	center, err := s.centers.GetByCollectionCenterID(ctx, collectionCenterID)
+	if err != nil {
+		return nil, fmt.Errorf("getting collection center: %w", err)
+	}
+	if center == nil || !center.Active {
+		return nil, service.NotFound("collection center not found")
+	}
+
+	supplierNumber = strings.TrimSpace(supplierNumber)
+	existing, err := s.centers.GetSupplier(ctx, collectionCenterID, supplierNumber)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk supplier: %w", err)
+	}
+	if existing != nil {
+		return nil, service.Conflict("supplier number is already registered with this center")
+	}
+	linked, err := s.centers.GetSuppliersByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk suppliers: %w", err)
+	}
+	for _, l := range linked {
+		if l.CollectionCenterID == collectionCenterID {
+			return nil, service.Conflict("farm is already registered with this center")
+		}
+	}
+
+	supplier := &data.MilkSupplier{
+		CollectionCenterID: collectionCenterID,
+		SupplierNumber:     supplierNumber,
+		FarmID:             farmID,
+		LinkedBy:           user.UserID,
+		Center:             center,
+	}
+	if err := s.centers.InsertSupplier(ctx, supplier); err != nil {
+		return nil, fmt.Errorf("registering milk supplier: %w", err)
+	}
+	return supplier, nil
+}
+
+// ListSuppliers returns the farms registered with a center user runs
+func (s *dairyService) ListSuppliers(ctx context.Context, user *data.User, collectionCenterID string) ([]*data.MilkSupplier, error) {
+	if _, err := s.managed(ctx, user, collectionCenterID); err != nil {
+		return nil, err
+	}
+	suppliers, err := s.centers.GetSuppliers(ctx, collectionCenterID)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk suppliers: %w", err)
+	}
+	return suppliers, nil
+}
+
+// ListFarmCenters returns the centers one of user's farms is registered with
+func (s *dairyService) ListFarmCenters(ctx context.Context, user *data.User, farmID string) ([]*data.MilkSupplier, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	suppliers, err := s.centers.GetSuppliersByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk suppliers: %w", err)
+	}
+	return suppliers, nil
+}
+
+// UnlinkFarm removes a registration. Either the farm's owner or the center's
+// manager may do it; deliveries already posted are kept.
+func (s *dairyService) UnlinkFarm(ctx context.Context, user *data.User, milkSupplierID string) error {
+	supplier, err := s.centers.GetSupplierByID(ctx, milkSupplierID)
+	if err != nil {
+		return fmt.Errorf("getting milk supplier: %w", err)
+	}
+	if supplier == nil {
+		return service.NotFound("milk supplier not found")
+	}
+	if _, err := s.managed(ctx, user, supplier.CollectionCenterID); err != nil {
+		if err := farm.CheckRecord(ctx, s.farms, user, supplier.FarmID, "milk supplier"); err != nil {
+			return err
+		}
+	}
+	if err := s.centers.DeleteSupplierByID(ctx, int(supplier.ID)); err != nil {
+		return fmt.Errorf("removing milk supplier: %w", err)
+	}
+	return nil
+}
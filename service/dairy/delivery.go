@@ -0,0 +1,270 @@
+package dairy
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Delivery sessions
+const (
+	SessionMorning = "Morning"
+	SessionEvening = "Evening"
+)
+
+// DeliveryInput is one delivery posted by a collection center. Accepted
+// defaults to true and PricePerLitre to the center's price.
+type DeliveryInput struct {
+	SupplierNumber  string
+	Reference       string
+	Date            time.Time
+	Session         string
+	Volume          float64
+	Fat             float64
+	SNF             float64
+	Density         float64
+	Accepted        *bool
+	RejectionReason string
+	PricePerLitre   float64
+}
+
+// StatementDay is one day's deliveries on a statement
+type StatementDay struct {
+	Date   time.Time `json:"date"`
+	Litres float64   `json:"litres"`
+	Amount float64   `json:"amount"`
+}
+
+// Statement is what a collection center owes a farm for a month's milk
+type Statement struct {
+	Month          string         `json:"month"` // YYYY-MM
+	CenterID       string         `json:"centerId"`
+	CenterName     string         `json:"centerName"`
+	FarmID         string         `json:"farmId"`
+	SupplierNumber string         `json:"supplierNumber"`
+	Deliveries     int            `json:"deliveries"`
+	Litres         float64        `json:"litres"`         // Delivered, accepted or not
+	AcceptedLitres float64        `json:"acceptedLitres"` // Paid for
+	RejectedLitres float64        `json:"rejectedLitres"`
+	AverageFat     float64        `json:"averageFat"` // Volume-weighted over tested accepted milk
+	AverageSNF     float64        `json:"averageSnf"`
+	Amount         float64        `json:"amount"`
+	Days           []StatementDay `json:"days"`
+}
+
+// parseMonth returns the first day of a YYYY-MM month and of the month
+// after it; an empty month is the current one
+func parseMonth(month string) (time.Time, time.Time, error) {
+	if month == "" {
+		now := time.Now().UTC()
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, time.Time{}, service.Invalid("month must be in YYYY-MM format")
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// RecordDelivery creates a delivery to a center for the farm registered under
+// the supplier number. Posting a receipt number again corrects that delivery
+// instead, so a center can safely retry.
+func (s *dairyService) RecordDelivery(ctx context.Context, center *data.CollectionCenter, in DeliveryInput) (*data.MilkDelivery, bool, error) {
+	supplier, err := s.centers.GetSupplier(ctx, center.CollectionCenterID, strings.TrimSpace(in.SupplierNumber))
+	if err != nil {
+		return nil, false, fmt.Errorf("getting milk supplier: %w", err)
+	}
+	if supplier == nil {
+		return nil, false, service.NotFound(fmt.Sprintf("no farm is registered under supplier number %s", in.SupplierNumber))
+	}
+	if in.Session != SessionMorning && in.Session != SessionEvening {
+		return nil, false, service.Invalid("session must be one of Morning, Evening")
+	}
+	if in.Volume <= 0 {
+		return nil, false, service.Invalid("volume must be greater than zero")
+	}
+	if in.PricePerLitre < 0 {
+		return nil, false, service.Invalid("pricePerLitre cannot be negative")
+	}
+
+	delivery, err := s.deliveries.GetByReference(ctx, center.CollectionCenterID, in.Reference)
+	if err != nil {
+		return nil, false, fmt.Errorf("getting milk delivery: %w", err)
+	}
+	created := delivery == nil
+	if created {
+		delivery = &data.MilkDelivery{CollectionCenterID: center.CollectionCenterID, Reference: in.Reference}
+	} else if err := s.locks.Check(ctx, delivery.FarmID, delivery.Date); err != nil {
+		return nil, false, err
+	}
+
+	date := time.Date(in.Date.Year(), in.Date.Month(), in.Date.Day(), 0, 0, 0, 0, time.UTC)
+	if err := s.locks.Check(ctx, supplier.FarmID, date); err != nil {
+		return nil, false, err
+	}
+
+	delivery.FarmID = supplier.FarmID
+	delivery.SupplierNumber = supplier.SupplierNumber
+	delivery.Date = date
+	delivery.Session = in.Session
+	delivery.Volume = in.Volume
+	delivery.Fat = in.Fat
+	delivery.SNF = in.SNF
+	delivery.Density = in.Density
+	delivery.Accepted = in.Accepted == nil || *in.Accepted
+	delivery.RejectionReason = in.RejectionReason
+	delivery.PricePerLitre = in.PricePerLitre
+	if delivery.PricePerLitre == 0 {
+		delivery.PricePerLitre = center.PricePerLitre
+	}
+	delivery.Amount = 0
+	if delivery.Accepted {
+		delivery.Amount = math.Round(delivery.Volume*delivery.PricePerLitre*100) / 100
+	}
+
+	if err := s.deliveries.Save(ctx, delivery); err != nil {
+		return nil, false, fmt.Errorf("saving milk delivery: %w", err)
+	}
+	return delivery, created, nil
+}
+
+// CenterDeliveries returns a center's deliveries
+func (s *dairyService) CenterDeliveries(ctx context.Context, center *data.CollectionCenter, from, to *time.Time) ([]*data.MilkDelivery, error) {
+	deliveries, err := s.deliveries.GetByCollectionCenterID(ctx, center.CollectionCenterID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// CenterStatements returns a month's payment statement for each farm that
+// delivered to the center, by supplier number
+func (s *dairyService) CenterStatements(ctx context.Context, center *data.CollectionCenter, month string) ([]*Statement, error) {
+	from, to, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := s.deliveries.GetByCollectionCenterID(ctx, center.CollectionCenterID, &from, &to)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk deliveries: %w", err)
+	}
+	names := map[string]string{center.CollectionCenterID: center.Name}
+	return statements(from, deliveries, names), nil
+}
+
+// FarmDeliveries returns the deliveries of one of user's farms
+func (s *dairyService) FarmDeliveries(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.MilkDelivery, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.deliveries.GetByFarmID(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// FarmStatements returns a month's payment statement from each center one of
+// user's farms delivered to
+func (s *dairyService) FarmStatements(ctx context.Context, user *data.User, farmID, month string) ([]*Statement, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	from, to, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := s.deliveries.GetByFarmID(ctx, farmID, &from, &to)
+	if err != nil {
+		return nil, fmt.Errorf("getting milk deliveries: %w", err)
+	}
+
+	names := map[string]string{}
+	for _, delivery := range deliveries {
+		if _, ok := names[delivery.CollectionCenterID]; ok {
+			continue
+		}
+		center, err := s.centers.GetByCollectionCenterID(ctx, delivery.CollectionCenterID)
+		if err != nil {
+			return nil, fmt.Errorf("getting collection center: %w", err)
+		}
+		if center != nil {
+			names[delivery.CollectionCenterID] = center.Name
+		}
+	}
+	return statements(from, deliveries, names), nil
+}
+
+// statements adds up a month's deliveries, given oldest first, into one
+// statement per center and farm, ordered by center name and supplier number
+func statements(month time.Time, deliveries []*data.MilkDelivery, centerNames map[string]string) []*Statement {
+	type key struct{ center, farm string }
+	byKey := map[key]*Statement{}
+	var out []*Statement
+	// Quality totals, weighted by volume, per statement
+	fat := map[*Statement][2]float64{}
+	snf := map[*Statement][2]float64{}
+
+	for _, delivery := range deliveries {
+		k := key{delivery.CollectionCenterID, delivery.FarmID}
+		statement, ok := byKey[k]
+		if !ok {
+			statement = &Statement{
+				Month:          month.Format("2006-01"),
+				CenterID:       delivery.CollectionCenterID,
+				CenterName:     centerNames[delivery.CollectionCenterID],
+				FarmID:         delivery.FarmID,
+				SupplierNumber: delivery.SupplierNumber,
+			}
+			byKey[k] = statement
+			out = append(out, statement)
+		}
+
+		statement.Deliveries++
+		statement.Litres += delivery.Volume
+		statement.Amount += delivery.Amount
+		if delivery.Accepted {
+			statement.AcceptedLitres += delivery.Volume
+			if delivery.Fat > 0 {
+				f := fat[statement]
+				fat[statement] = [2]float64{f[0] + delivery.Fat*delivery.Volume, f[1] + delivery.Volume}
+			}
+			if delivery.SNF > 0 {
+				n := snf[statement]
+				snf[statement] = [2]float64{n[0] + delivery.SNF*delivery.Volume, n[1] + delivery.Volume}
+			}
+		} else {
+			statement.RejectedLitres += delivery.Volume
+		}
+
+		if n := len(statement.Days); n > 0 && statement.Days[n-1].Date.Equal(delivery.Date) {
+			statement.Days[n-1].Litres += delivery.Volume
+			statement.Days[n-1].Amount += delivery.Amount
+		} else {
+			statement.Days = append(statement.Days, StatementDay{Date: delivery.Date, Litres: delivery.Volume, Amount: delivery.Amount})
+		}
+	}
+
+	for _, statement := range out {
+		if f := fat[statement]; f[1] > 0 {
+			statement.AverageFat = math.Round(f[0]/f[1]*100) / 100
+		}
+		if n := snf[statement]; n[1] > 0 {
+			statement.AverageSNF = math.Round(n[0]/n[1]*100) / 100
+		}
+		statement.Amount = math.Round(statement.Amount*100) / 100
+	}
+	slices.SortStableFunc(out, func(a, b *Statement) int {
+		if c := strings.Compare(a.CenterName, b.CenterName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SupplierNumber, b.SupplierNumber)
+	})
+	return out
+}
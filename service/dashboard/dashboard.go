@@ -0,0 +1,143 @@
+// Package dashboard keeps each user's choice of dashboard widgets and their
+// order, so the layout arranged on one device shows up on every other.
+package dashboard
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Widget types
+const (
+	WidgetWeather      = "weather"
+	WidgetCashPosition = "cash-position"
+	WidgetHerdHealth   = "herd-health"
+	WidgetOpenTasks    = "open-tasks"
+)
+
+// Widget sizes
+const (
+	SizeSmall  = "small"
+	SizeMedium = "medium"
+	SizeLarge  = "large"
+)
+
+// Widget describes a widget that can be placed on a dashboard
+type Widget struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Widgets are the widgets that can be placed on a dashboard, in the order of
+// the default layout
+var Widgets = []Widget{
+	{Type: WidgetWeather, Name: "Weather", Description: "Rain forecast for the farm's location"},
+	{Type: WidgetCashPosition, Name: "Cash position", Description: "Income, expenses and net for the month"},
+	{Type: WidgetHerdHealth, Name: "Herd health", Description: "Livestock head by health status"},
+	{Type: WidgetOpenTasks, Name: "Open tasks", Description: "Work still to be done on the farm"},
+}
+
+// Sizes are the sizes a widget can be shown at
+var Sizes = []string{SizeSmall, SizeMedium, SizeLarge}
+
+// Service is the dashboard domain service
+type Service interface {
+	// Get returns the user's layout, or the default layout, unsaved, if they
+	// have not arranged one
+	Get(ctx context.Context, user *data.User) (*data.DashboardLayout, error)
+	// Save replaces the user's layout with widgets, in display order. A
+	// widget without a size is shown at medium size.
+	Save(ctx context.Context, user *data.User, widgets []data.DashboardWidget) (*data.DashboardLayout, error)
+	// Reset discards the user's layout so the default is shown again
+	Reset(ctx context.Context, user *data.User) (*data.DashboardLayout, error)
+}
+
+// dashboardService implements Service on top of the dashboard layout repository
+type dashboardService struct {
+	layouts data.DashboardLayoutInterface
+}
+
+// New creates the dashboard service
+func New(layouts data.DashboardLayoutInterface) Service {
+	return &dashboardService{layouts: layouts}
+}
+
+// Get implements Service
+func (s *dashboardService) Get(ctx context.Context, user *data.User) (*data.DashboardLayout, error) {
+	layout, err := s.layouts.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting dashboard layout: %w", err)
+	}
+	if layout == nil {
+		return defaultLayout(user), nil
+	}
+	return layout, nil
+}
+
+// Save implements Service
+func (s *dashboardService) Save(ctx context.Context, user *data.User, widgets []data.DashboardWidget) (*data.DashboardLayout, error) {
+	placed := make([]data.DashboardWidget, 0, len(widgets))
+	for i, w := range widgets {
+		w.Type = strings.TrimSpace(w.Type)
+		if !slices.ContainsFunc(Widgets, func(known Widget) bool { return known.Type == w.Type }) {
+			return nil, service.Invalid(fmt.Sprintf("widget %d: unknown widget type %q", i+1, w.Type))
+		}
+		if slices.ContainsFunc(placed, func(p data.DashboardWidget) bool { return p.Type == w.Type }) {
+			return nil, service.Invalid(fmt.Sprintf("widget %d: %s is already on the dashboard", i+1, w.Type))
+		}
+		if w.Size == "" {
+			w.Size = SizeMedium
+		}
+		if !slices.Contains(Sizes, w.Size) {
+			return nil, service.Invalid(fmt.Sprintf("widget %d: size must be one of %s", i+1, strings.Join(Sizes, ", ")))
+		}
+		placed = append(placed, w)
+	}
+
+	layout, err := s.layouts.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting dashboard layout: %w", err)
+	}
+	if layout == nil {
+		layout = &data.DashboardLayout{UserID: user.UserID, Widgets: placed}
+		if err := s.layouts.Insert(ctx, layout); err != nil {
+			return nil, fmt.Errorf("saving dashboard layout: %w", err)
+		}
+		return layout, nil
+	}
+
+	layout.Widgets = placed
+	if err := s.layouts.Update(ctx, layout); err != nil {
+		return nil, fmt.Errorf("saving dashboard layout: %w", err)
+	}
+	return layout, nil
+}
+
+// Reset implements Service
+func (s *dashboardService) Reset(ctx context.Context, user *data.User) (*data.DashboardLayout, error) {
+	layout, err := s.layouts.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting dashboard layout: %w", err)
+	}
+	if layout != nil {
+		if err := s.layouts.DeleteByID(ctx, int(layout.ID)); err != nil {
+			return nil, fmt.Errorf("resetting dashboard layout: %w", err)
+		}
+	}
+	return defaultLayout(user), nil
+}
+
+// defaultLayout is the layout shown until a user arranges their own: every
+// widget at medium size
+func defaultLayout(user *data.User) *data.DashboardLayout {
+	widgets := make([]data.DashboardWidget, len(Widgets))
+	for i, w := range Widgets {
+		widgets[i] = data.DashboardWidget{Type: w.Type, Size: SizeMedium}
+	}
+	return &data.DashboardLayout{UserID: user.UserID, Widgets: widgets}
+}
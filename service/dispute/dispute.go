@@ -0,0 +1,304 @@
+// Package dispute manages claims raised by a farmer or buyer about a sale,
+// the evidence both sides attach and the admin's arbitration.
+package dispute
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/auth"
+	"fmt"
+	"time"
+)
+
+// Dispute statuses
+const (
+	StatusOpen        = "Open"
+	StatusUnderReview = "Under Review"
+	StatusResolved    = "Resolved"
+	StatusWithdrawn   = "Withdrawn"
+)
+
+// Arbitration outcomes
+const (
+	OutcomeUpheld   = "Upheld"
+	OutcomeRejected = "Rejected"
+)
+
+// Input is a new dispute against the other party to a sale
+type Input struct {
+	RespondentID  string
+	SaleReference string
+	Reason        string
+	Description   string
+	Evidence      []EvidenceInput
+}
+
+// EvidenceInput is a document or photo attached to a dispute
+type EvidenceInput struct {
+	URL         string
+	Description string
+}
+
+// Service is the dispute domain service
+type Service interface {
+	Open(ctx context.Context, user *data.User, in Input) (*data.Dispute, error)
+	// List returns the disputes the user raised or responds to
+	List(ctx context.Context, user *data.User) ([]*data.Dispute, error)
+	// Get returns a dispute to either party or an admin
+	Get(ctx context.Context, user *data.User, disputeID string) (*data.Dispute, error)
+	AddEvidence(ctx context.Context, user *data.User, disputeID string, in EvidenceInput) (*data.DisputeEvidence, error)
+	// Withdraw lets the claimant drop a dispute that is not yet resolved
+	Withdraw(ctx context.Context, user *data.User, disputeID string) (*data.Dispute, error)
+
+	// ListByStatus, Review and Resolve are for admins; callers must have
+	// checked the role
+	ListByStatus(ctx context.Context, status string) ([]*data.Dispute, error)
+	Review(ctx context.Context, disputeID string) (*data.Dispute, error)
+	Resolve(ctx context.Context, admin *data.User, disputeID, outcome, resolution string) (*data.Dispute, error)
+}
+
+// disputeService implements Service on top of the dispute repository
+type disputeService struct {
+	disputes      data.DisputeInterface
+	users         data.UserInterface
+	notifications data.NotificationInterface
+}
+
+// New creates the dispute service
+func New(disputes data.DisputeInterface, users data.UserInterface, notifications data.NotificationInterface) Service {
+	return &disputeService{disputes: disputes, users: users, notifications: notifications}
+}
+
+// Open raises a dispute between a farmer and a buyer over a sale and notifies
+// both of them. A claimant can have one active dispute per sale.
+func (s *disputeService) Open(ctx context.Context, user *data.User, in Input) (*data.Dispute, error) {
+	if in.RespondentID == user.UserID {
+		return nil, service.Invalid("you cannot raise a dispute against yourself")
+	}
+
+	respondent, err := s.users.GetByUserID(ctx, in.RespondentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting respondent: %w", err)
+	}
+	if respondent == nil {
+		return nil, service.NotFound("user not found")
+	}
+	if (user.Role == auth.BuyerRole) == (respondent.Role == auth.BuyerRole) {
+		return nil, service.Invalid("disputes are between a farmer and a buyer")
+	}
+
+	exists, err := s.disputes.ExistsActive(ctx, user.UserID, respondent.UserID, in.SaleReference)
+	if err != nil {
+		return nil, fmt.Errorf("checking existing disputes: %w", err)
+	}
+	if exists {
+		return nil, service.Conflict("you already have an active dispute for this sale")
+	}
+
+	dispute := &data.Dispute{
+		SaleReference: in.SaleReference,
+		ClaimantID:    user.UserID,
+		RespondentID:  respondent.UserID,
+		Reason:        in.Reason,
+		Description:   in.Description,
+		Status:        StatusOpen,
+	}
+	for _, e := range in.Evidence {
+		dispute.Evidence = append(dispute.Evidence, data.DisputeEvidence{
+			SubmittedBy: user.UserID,
+			URL:         e.URL,
+			Description: e.Description,
+		})
+	}
+	if err := s.disputes.Insert(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("creating dispute: %w", err)
+	}
+
+	if err := s.notify(ctx, dispute, "dispute_opened", "Dispute opened",
+		fmt.Sprintf("A dispute about sale %s was opened: %s.", dispute.SaleReference, dispute.Reason)); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// List returns the disputes the user raised or responds to
+func (s *disputeService) List(ctx context.Context, user *data.User) ([]*data.Dispute, error) {
+	disputes, err := s.disputes.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting disputes: %w", err)
+	}
+	return disputes, nil
+}
+
+// Get returns a dispute to either party or an admin
+func (s *disputeService) Get(ctx context.Context, user *data.User, disputeID string) (*data.Dispute, error) {
+	dispute, err := s.find(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if !dispute.Party(user.UserID) && user.Role != auth.AdminRole {
+		return nil, service.Forbidden("access denied: you are not a party to this dispute")
+	}
+	return dispute, nil
+}
+
+// AddEvidence attaches evidence from either party to a dispute that is still
+// open or under review, and tells the other party
+func (s *disputeService) AddEvidence(ctx context.Context, user *data.User, disputeID string, in EvidenceInput) (*data.DisputeEvidence, error) {
+	dispute, err := s.find(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if !dispute.Party(user.UserID) {
+		return nil, service.Forbidden("access denied: you are not a party to this dispute")
+	}
+	if dispute.Closed() {
+		return nil, service.Conflict("dispute is " + dispute.Status)
+	}
+
+	evidence := &data.DisputeEvidence{
+		DisputeID:   dispute.DisputeID,
+		SubmittedBy: user.UserID,
+		URL:         in.URL,
+		Description: in.Description,
+	}
+	if err := s.disputes.AddEvidence(ctx, evidence); err != nil {
+		return nil, fmt.Errorf("adding dispute evidence: %w", err)
+	}
+
+	other := dispute.RespondentID
+	if user.UserID == dispute.RespondentID {
+		other = dispute.ClaimantID
+	}
+	notification := &data.Notification{
+		UserID:    other,
+		Type:      "dispute_evidence",
+		Title:     "New dispute evidence",
+		Message:   fmt.Sprintf("The other party added evidence to the dispute about sale %s.", dispute.SaleReference),
+		Reference: "dispute_evidence:" + evidence.DisputeEvidenceID,
+	}
+	if err := s.notifications.Insert(ctx, notification); err != nil {
+		return nil, fmt.Errorf("notifying dispute party: %w", err)
+	}
+	return evidence, nil
+}
+
+// Withdraw lets the claimant drop a dispute that is not yet resolved
+func (s *disputeService) Withdraw(ctx context.Context, user *data.User, disputeID string) (*data.Dispute, error) {
+	dispute, err := s.find(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.ClaimantID != user.UserID {
+		return nil, service.Forbidden("only the claimant can withdraw a dispute")
+	}
+	if dispute.Closed() {
+		return nil, service.Conflict("dispute is " + dispute.Status)
+	}
+
+	dispute.Status = StatusWithdrawn
+	if err := s.disputes.Update(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("updating dispute: %w", err)
+	}
+
+	if err := s.notify(ctx, dispute, "dispute_withdrawn", "Dispute withdrawn",
+		fmt.Sprintf("The dispute about sale %s was withdrawn by the claimant.", dispute.SaleReference)); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// ListByStatus returns disputes with a status, or all when status is empty
+func (s *disputeService) ListByStatus(ctx context.Context, status string) ([]*data.Dispute, error) {
+	disputes, err := s.disputes.GetByStatus(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting disputes: %w", err)
+	}
+	return disputes, nil
+}
+
+// Review marks an open dispute as under review by an admin
+func (s *disputeService) Review(ctx context.Context, disputeID string) (*data.Dispute, error) {
+	dispute, err := s.find(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Status != StatusOpen {
+		return nil, service.Conflict("only open disputes can be taken into review")
+	}
+
+	dispute.Status = StatusUnderReview
+	if err := s.disputes.Update(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("updating dispute: %w", err)
+	}
+
+	if err := s.notify(ctx, dispute, "dispute_under_review", "Dispute under review",
+		fmt.Sprintf("An arbitrator is now reviewing the dispute about sale %s.", dispute.SaleReference)); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// Resolve records the admin's decision on a dispute and notifies both parties
+func (s *disputeService) Resolve(ctx context.Context, admin *data.User, disputeID, outcome, resolution string) (*data.Dispute, error) {
+	if outcome != OutcomeUpheld && outcome != OutcomeRejected {
+		return nil, service.Invalid("outcome must be Upheld or Rejected")
+	}
+	if resolution == "" {
+		return nil, service.Invalid("a resolution is required")
+	}
+
+	dispute, err := s.find(ctx, disputeID)
+	if err != nil {
+		return nil, err
+	}
+	if dispute.Closed() {
+		return nil, service.Conflict("dispute is " + dispute.Status)
+	}
+
+	now := time.Now()
+	dispute.Status = StatusResolved
+	dispute.Outcome = outcome
+	dispute.Resolution = resolution
+	dispute.ResolvedBy = &admin.UserID
+	dispute.ResolvedAt = &now
+	if err := s.disputes.Update(ctx, dispute); err != nil {
+		return nil, fmt.Errorf("updating dispute: %w", err)
+	}
+
+	if err := s.notify(ctx, dispute, "dispute_resolved", "Dispute "+outcome,
+		fmt.Sprintf("The dispute about sale %s was resolved: %s", dispute.SaleReference, resolution)); err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// find loads a dispute by ID
+func (s *disputeService) find(ctx context.Context, disputeID string) (*data.Dispute, error) {
+	dispute, err := s.disputes.GetByDisputeID(ctx, disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting dispute: %w", err)
+	}
+	if dispute == nil {
+		return nil, service.NotFound("dispute not found")
+	}
+	return dispute, nil
+}
+
+// notify tells both parties about a change to a dispute
+func (s *disputeService) notify(ctx context.Context, dispute *data.Dispute, kind, title, message string) error {
+	for _, userID := range []string{dispute.ClaimantID, dispute.RespondentID} {
+		notification := &data.Notification{
+			UserID:    userID,
+			Type:      kind,
+			Title:     title,
+			Message:   message,
+			Reference: fmt.Sprintf("dispute:%s:%s", dispute.DisputeID, dispute.Status),
+		}
+		if err := s.notifications.Insert(ctx, notification); err != nil {
+			return fmt.Errorf("notifying dispute party: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+// Package document keeps a farm's compliance papers, such as land titles,
+// certifications and insurance policies, and tracks when they expire. Papers
+// held for an employee, such as a contract or a sprayer operator's
+// certification, name the employee. Scans of a paper are kept by the
+// attachment service as attachments of record type document.
+package document
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/attachment"
+	"farm4u/service/farm"
+	"farm4u/service/tag"
+	"fmt"
+	"time"
+)
+
+// Document types
+const (
+	TypeLandTitle       = "Land Title"
+	TypeCertification   = "Certification"
+	TypeInsurancePolicy = "Insurance Policy"
+	TypePermit          = "Permit"
+	TypeLicense         = "License"
+	TypeContract        = "Contract"
+	TypeIDCopy          = "ID Copy"
+	TypeOther           = "Other"
+)
+
+// Types lists the document types
+var Types = []string{TypeLandTitle, TypeCertification, TypeInsurancePolicy, TypePermit, TypeLicense, TypeContract, TypeIDCopy, TypeOther}
+
+// DefaultReminderDays is how long before expiry a reminder is sent when the
+// document does not say
+const DefaultReminderDays = 30
+
+// Input holds the editable document fields. On update, zero values are left
+// unchanged.
+type Input struct {
+	Type         string
+	Title        string
+	Number       string
+	Issuer       string
+	IssuedAt     *time.Time
+	ExpiresAt    *time.Time
+	ReminderDays int
+	Notes        string
+	EmployeeID   string // Employee of the farm the paper is for, if any
+}
+
+// Service is the document domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Document, error)
+	// Get returns a document with its files
+	Get(ctx context.Context, user *data.User, documentID string) (*data.Document, error)
+	// ListForEmployee returns the documents held for an employee
+	ListForEmployee(ctx context.Context, user *data.User, employeeID string) ([]*data.Document, error)
+	// List returns a farm's documents, optionally of one type, only those
+	// tagged with every one of tags when any are given
+	List(ctx context.Context, user *data.User, farmID, docType string, tags []string) ([]*data.Document, error)
+	// Expiring lists a farm's documents that expire within the given number
+	// of days, including expired ones
+	Expiring(ctx context.Context, user *data.User, farmID string, days int) ([]*data.Document, error)
+	Update(ctx context.Context, user *data.User, documentID string, in Input) (*data.Document, error)
+	Delete(ctx context.Context, user *data.User, documentID string) error
+	// File returns a document's most recently uploaded file with a presigned
+	// download URL, or a nil transfer when the backend cannot presign URLs
+	File(ctx context.Context, user *data.User, documentID string) (*data.Attachment, *attachment.Transfer, error)
+}
+
+// documentService implements Service on top of the document repository and
+// the attachment service
+type documentService struct {
+	documents   data.DocumentInterface
+	employees   data.EmployeeInterface
+	attachments attachment.Service
+	tags        data.TagInterface
+	farms       farm.Service
+}
+
+// New creates the document service
+func New(documents data.DocumentInterface, employees data.EmployeeInterface, attachments attachment.Service, tags data.TagInterface,
+	farms farm.Service) Service {
+	return &documentService{documents: documents, employees: employees, attachments: attachments, tags: tags, farms: farms}
+}
+
+// employee returns an employee of the farm, as a document may only be held
+// for its own staff
+func (s *documentService) employee(ctx context.Context, farmID, employeeID string) (*data.Employee, error) {
+	employee, err := s.employees.GetByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employee: %w", err)
+	}
+	if employee == nil || employee.FarmID != farmID {
+		return nil, service.Invalid("employeeId must be an employee of the farm")
+	}
+	return employee, nil
+}
+
+// Create adds a document to one of the user's farms
+func (s *documentService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Document, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if in.IssuedAt != nil && in.ExpiresAt != nil && in.ExpiresAt.Before(*in.IssuedAt) {
+		return nil, service.Invalid("expiresAt must not be before issuedAt")
+	}
+	if in.ReminderDays == 0 {
+		in.ReminderDays = DefaultReminderDays
+	}
+	if in.EmployeeID != "" {
+		if _, err := s.employee(ctx, farmID, in.EmployeeID); err != nil {
+			return nil, err
+		}
+	}
+
+	document := &data.Document{
+		FarmID:       farmID,
+		Type:         in.Type,
+		Title:        in.Title,
+		Number:       in.Number,
+		Issuer:       in.Issuer,
+		IssuedAt:     in.IssuedAt,
+		ExpiresAt:    in.ExpiresAt,
+		ReminderDays: in.ReminderDays,
+		Notes:        in.Notes,
+	}
+	if in.EmployeeID != "" {
+		document.EmployeeID = &in.EmployeeID
+	}
+	if err := s.documents.Insert(ctx, document); err != nil {
+		return nil, fmt.Errorf("creating document: %w", err)
+	}
+	return document, nil
+}
+
+// get returns a document on one of the user's farms
+func (s *documentService) get(ctx context.Context, user *data.User, documentID string) (*data.Document, error) {
+	document, err := s.documents.GetByDocumentID(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting document: %w", err)
+	}
+	if document == nil {
+		return nil, service.NotFound("document not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, document.FarmID, "document"); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// Get implements Service
+func (s *documentService) Get(ctx context.Context, user *data.User, documentID string) (*data.Document, error) {
+	document, err := s.get(ctx, user, documentID)
+	if err != nil {
+		return nil, err
+	}
+	document.Files, err = s.attachments.List(ctx, user, attachment.RecordDocument, document.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// ListForEmployee implements Service
+func (s *documentService) ListForEmployee(ctx context.Context, user *data.User, employeeID string) ([]*data.Document, error) {
+	employee, err := s.employees.GetByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employee: %w", err)
+	}
+	if employee == nil {
+		return nil, service.NotFound("employee not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, employee.FarmID, "employee"); err != nil {
+		return nil, err
+	}
+	documents, err := s.documents.GetByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting documents: %w", err)
+	}
+	return documents, nil
+}
+
+// List implements Service
+func (s *documentService) List(ctx context.Context, user *data.User, farmID, docType string, tags []string) ([]*data.Document, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	documents, err := s.documents.GetByFarmID(ctx, farmID, docType)
+	if err != nil {
+		return nil, fmt.Errorf("getting documents: %w", err)
+	}
+	return tag.Filter(ctx, s.tags, farmID, tag.RecordDocument, tags, documents, func(d *data.Document) string { return d.DocumentID })
+}
+
+// Expiring implements Service
+func (s *documentService) Expiring(ctx context.Context, user *data.User, farmID string, days int) ([]*data.Document, error) {
+	documents, err := s.List(ctx, user, farmID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	before := time.Now().AddDate(0, 0, days)
+	var expiring []*data.Document
+	for _, document := range documents {
+		if document.ExpiresAt != nil && document.ExpiresAt.Before(before) {
+			expiring = append(expiring, document)
+		}
+	}
+	return expiring, nil
+}
+
+// Update changes the non-zero fields of in on a document. Moving the expiry
+// date, as when a policy is renewed, makes a new reminder due before it.
+func (s *documentService) Update(ctx context.Context, user *data.User, documentID string, in Input) (*data.Document, error) {
+	document, err := s.get(ctx, user, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Type != "" {
+		document.Type = in.Type
+	}
+	if in.Title != "" {
+		document.Title = in.Title
+	}
+	if in.Number != "" {
+		document.Number = in.Number
+	}
+	if in.Issuer != "" {
+		document.Issuer = in.Issuer
+	}
+	if in.IssuedAt != nil {
+		document.IssuedAt = in.IssuedAt
+	}
+	if in.ExpiresAt != nil {
+		document.ExpiresAt = in.ExpiresAt
+	}
+	if in.ReminderDays > 0 {
+		document.ReminderDays = in.ReminderDays
+	}
+	if in.Notes != "" {
+		document.Notes = in.Notes
+	}
+	if in.EmployeeID != "" {
+		if _, err := s.employee(ctx, document.FarmID, in.EmployeeID); err != nil {
+			return nil, err
+		}
+		document.EmployeeID = &in.EmployeeID
+	}
+	if document.IssuedAt != nil && document.ExpiresAt != nil && document.ExpiresAt.Before(*document.IssuedAt) {
+		return nil, service.Invalid("expiresAt must not be before issuedAt")
+	}
+
+	if err := s.documents.Update(ctx, document); err != nil {
+		return nil, fmt.Errorf("updating document: %w", err)
+	}
+	return document, nil
+}
+
+// Delete soft deletes a document. Its files are kept with it.
+func (s *documentService) Delete(ctx context.Context, user *data.User, documentID string) error {
+	document, err := s.get(ctx, user, documentID)
+	if err != nil {
+		return err
+	}
+	if err := s.documents.DeleteByID(ctx, int(document.ID)); err != nil {
+		return fmt.Errorf("deleting document: %w", err)
+	}
+	return nil
+}
+
+// File implements Service
+func (s *documentService) File(ctx context.Context, user *data.User, documentID string) (*data.Attachment, *attachment.Transfer, error) {
+	document, err := s.get(ctx, user, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := s.attachments.List(ctx, user, attachment.RecordDocument, document.DocumentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := len(files) - 1; i >= 0; i-- {
+		if files[i].Status == attachment.StatusUploaded {
+			return s.attachments.Get(ctx, user, files[i].AttachmentID)
+		}
+	}
+	return nil, nil, service.NotFound("the document has no uploaded file")
+}
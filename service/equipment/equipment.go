@@ -0,0 +1,242 @@
+// Package equipment manages a farm's machinery register, its maintenance
+// schedule and the log of service events
+package equipment
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+)
+
+// Input holds the editable equipment fields. On update, zero values are left
+// unchanged; an empty AssignedEmployeeID string unassigns the equipment.
+type Input struct {
+	Name                    string
+	Type                    string
+	Make                    string
+	Model                   string
+	SerialNumber            string
+	PurchaseDate            *time.Time
+	PurchaseCost            float64
+	Condition               string
+	MaintenanceIntervalDays int
+	LastMaintenanceDate     *time.Time
+	NextMaintenanceDate     *time.Time
+	AssignedEmployeeID      *string
+	Notes                   string
+}
+
+// Service is the equipment domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Equipment, error)
+	Get(ctx context.Context, user *data.User, equipmentID string) (*data.Equipment, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.Equipment, error)
+	Update(ctx context.Context, user *data.User, equipmentID string, in Input) (*data.Equipment, error)
+	Delete(ctx context.Context, user *data.User, equipmentID string) error
+	// MaintenanceDue lists equipment due for service within the given number
+	// of days, including overdue equipment
+	MaintenanceDue(ctx context.Context, user *data.User, farmID string, days int) ([]*data.Equipment, error)
+
+	LogMaintenance(ctx context.Context, user *data.User, equipmentID string, in MaintenanceInput) (*data.MaintenanceRecord, error)
+	ListMaintenance(ctx context.Context, user *data.User, equipmentID string, from, to *time.Time) ([]*data.MaintenanceRecord, error)
+	DeleteMaintenance(ctx context.Context, user *data.User, maintenanceRecordID string) error
+	// MaintenanceCosts totals maintenance cost and downtime per machine per year
+	MaintenanceCosts(ctx context.Context, user *data.User, farmID, equipmentID string, year int) ([]data.YearlyMaintenanceCost, error)
+}
+
+// equipmentService implements Service on top of the equipment repository
+type equipmentService struct {
+	equipment data.EquipmentInterface
+	records   data.MaintenanceRecordInterface
+	employees data.EmployeeInterface
+	providers data.ServiceProviderInterface
+	locks     lock.Checker
+	farms     farm.Service
+}
+
+// New creates the equipment service
+func New(equipment data.EquipmentInterface, records data.MaintenanceRecordInterface, employees data.EmployeeInterface,
+	providers data.ServiceProviderInterface, locks lock.Checker, farms farm.Service) Service {
+	return &equipmentService{equipment: equipment, records: records, employees: employees, providers: providers, locks: locks, farms: farms}
+}
+
+// Create adds equipment to one of the user's farms, defaulting to Good condition
+func (s *equipmentService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Equipment, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	if in.Condition == "" {
+		in.Condition = "Good"
+	}
+
+	equipment := &data.Equipment{
+		FarmID:                  farmID,
+		Name:                    in.Name,
+		Type:                    in.Type,
+		Make:                    in.Make,
+		Model:                   in.Model,
+		SerialNumber:            in.SerialNumber,
+		PurchaseDate:            in.PurchaseDate,
+		PurchaseCost:            in.PurchaseCost,
+		Condition:               in.Condition,
+		MaintenanceIntervalDays: in.MaintenanceIntervalDays,
+		LastMaintenanceDate:     in.LastMaintenanceDate,
+		Notes:                   in.Notes,
+	}
+
+	if err := s.assign(ctx, equipment, in.AssignedEmployeeID); err != nil {
+		return nil, err
+	}
+
+	equipment.NextMaintenanceDate = in.NextMaintenanceDate
+	if equipment.NextMaintenanceDate == nil {
+		equipment.ScheduleNextMaintenance()
+	}
+
+	if err := s.equipment.Insert(ctx, equipment); err != nil {
+		return nil, fmt.Errorf("creating equipment: %w", err)
+	}
+	return equipment, nil
+}
+
+// Get returns equipment on one of the user's farms
+func (s *equipmentService) Get(ctx context.Context, user *data.User, equipmentID string) (*data.Equipment, error) {
+	equipment, err := s.equipment.GetByEquipmentID(ctx, equipmentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting equipment: %w", err)
+	}
+	if equipment == nil {
+		return nil, service.NotFound("equipment not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, equipment.FarmID, "equipment"); err != nil {
+		return nil, err
+	}
+	return equipment, nil
+}
+
+// List returns the equipment of one of the user's farms
+func (s *equipmentService) List(ctx context.Context, user *data.User, farmID string) ([]*data.Equipment, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	equipment, err := s.equipment.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting equipment: %w", err)
+	}
+	return equipment, nil
+}
+
+// Update changes the non-zero fields of in on equipment. Changing the interval
+// or last service date reschedules the next service unless a date is given.
+func (s *equipmentService) Update(ctx context.Context, user *data.User, equipmentID string, in Input) (*data.Equipment, error) {
+	equipment, err := s.Get(ctx, user, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		equipment.Name = in.Name
+	}
+	if in.Type != "" {
+		equipment.Type = in.Type
+	}
+	if in.Make != "" {
+		equipment.Make = in.Make
+	}
+	if in.Model != "" {
+		equipment.Model = in.Model
+	}
+	if in.SerialNumber != "" {
+		equipment.SerialNumber = in.SerialNumber
+	}
+	if in.PurchaseDate != nil {
+		equipment.PurchaseDate = in.PurchaseDate
+	}
+	if in.PurchaseCost > 0 {
+		equipment.PurchaseCost = in.PurchaseCost
+	}
+	if in.Condition != "" {
+		equipment.Condition = in.Condition
+	}
+	if in.Notes != "" {
+		equipment.Notes = in.Notes
+	}
+
+	reschedule := false
+	if in.MaintenanceIntervalDays > 0 {
+		equipment.MaintenanceIntervalDays = in.MaintenanceIntervalDays
+		reschedule = true
+	}
+	if in.LastMaintenanceDate != nil {
+		equipment.LastMaintenanceDate = in.LastMaintenanceDate
+		reschedule = true
+	}
+	if in.NextMaintenanceDate != nil {
+		equipment.NextMaintenanceDate = in.NextMaintenanceDate
+	} else if reschedule {
+		equipment.ScheduleNextMaintenance()
+	}
+
+	if err := s.assign(ctx, equipment, in.AssignedEmployeeID); err != nil {
+		return nil, err
+	}
+
+	if err := s.equipment.Update(ctx, equipment); err != nil {
+		return nil, fmt.Errorf("updating equipment: %w", err)
+	}
+	return equipment, nil
+}
+
+// Delete soft deletes equipment
+func (s *equipmentService) Delete(ctx context.Context, user *data.User, equipmentID string) error {
+	equipment, err := s.Get(ctx, user, equipmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.equipment.DeleteByID(ctx, int(equipment.ID)); err != nil {
+		return fmt.Errorf("deleting equipment: %w", err)
+	}
+	return nil
+}
+
+// MaintenanceDue lists equipment due for service within days, including overdue equipment
+func (s *equipmentService) MaintenanceDue(ctx context.Context, user *data.User, farmID string, days int) ([]*data.Equipment, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	equipment, err := s.equipment.GetMaintenanceDue(ctx, farmID, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return nil, fmt.Errorf("getting equipment due for maintenance: %w", err)
+	}
+	return equipment, nil
+}
+
+// assign sets the employee responsible for equipment. The employee must work
+// on the same farm. nil leaves the assignment unchanged and "" clears it.
+func (s *equipmentService) assign(ctx context.Context, equipment *data.Equipment, employeeID *string) error {
+	if employeeID == nil {
+		return nil
+	}
+	if *employeeID == "" {
+		equipment.AssignedEmployeeID = nil
+		equipment.AssignedEmployee = nil
+		return nil
+	}
+
+	employee, err := s.employees.GetByEmployeeID(ctx, *employeeID)
+	if err != nil {
+		return fmt.Errorf("getting assigned employee: %w", err)
+	}
+	if employee == nil || employee.FarmID != equipment.FarmID {
+		return service.Invalid("assigned employee not found on this farm")
+	}
+
+	equipment.AssignedEmployeeID = &employee.EmployeeID
+	equipment.AssignedEmployee = employee
+	return nil
+}
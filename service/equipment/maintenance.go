@@ -0,0 +1,149 @@
+package equipment
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/provider"
+	"fmt"
+	"time"
+)
+
+// ScheduledService is the maintenance type that resets an equipment's
+// service schedule. Repairs, inspections and breakdowns do not.
+const ScheduledService = "Scheduled Service"
+
+// MaintenanceInput holds the fields of a logged service event. A nil Date
+// means today. ServiceProviderID optionally names the contractor who did
+// the work from the farm's directory; PerformedBy then defaults to its name.
+type MaintenanceInput struct {
+	Date              *time.Time
+	Type              string
+	Description       string
+	Cost              float64
+	DowntimeHours     float64
+	PartsUsed         string
+	PerformedBy       string
+	ServiceProviderID string
+	Notes             string
+}
+
+// LogMaintenance records a service event on equipment and books its cost as
+// a Maintenance expense. A scheduled service later than the last one moves
+// the equipment's service schedule on.
+func (s *equipmentService) LogMaintenance(ctx context.Context, user *data.User, equipmentID string, in MaintenanceInput) (*data.MaintenanceRecord, error) {
+	equipment, err := s.Get(ctx, user, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if err := s.locks.Check(ctx, equipment.FarmID, date); err != nil {
+		return nil, err
+	}
+
+	record := &data.MaintenanceRecord{
+		EquipmentID:   equipment.EquipmentID,
+		FarmID:        equipment.FarmID,
+		Date:          date,
+		Type:          in.Type,
+		Description:   in.Description,
+		Cost:          in.Cost,
+		DowntimeHours: in.DowntimeHours,
+		PartsUsed:     in.PartsUsed,
+		PerformedBy:   in.PerformedBy,
+		Notes:         in.Notes,
+	}
+	contractor, err := provider.Lookup(ctx, s.providers, equipment.FarmID, in.ServiceProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if contractor != nil {
+		record.ServiceProviderID = &contractor.ServiceProviderID
+		if record.PerformedBy == "" {
+			record.PerformedBy = contractor.Name
+		}
+	}
+
+	if err := s.records.Insert(ctx, record); err != nil {
+		return nil, fmt.Errorf("logging maintenance: %w", err)
+	}
+
+	if in.Type == ScheduledService && (equipment.LastMaintenanceDate == nil || date.After(*equipment.LastMaintenanceDate)) {
+		equipment.LastMaintenanceDate = &date
+		equipment.ScheduleNextMaintenance()
+		if err := s.equipment.Update(ctx, equipment); err != nil {
+			return nil, fmt.Errorf("rescheduling maintenance: %w", err)
+		}
+	}
+
+	return record, nil
+}
+
+// ListMaintenance returns the maintenance log of equipment, optionally
+// limited to dates in [from, to)
+func (s *equipmentService) ListMaintenance(ctx context.Context, user *data.User, equipmentID string, from, to *time.Time) ([]*data.MaintenanceRecord, error) {
+	equipment, err := s.Get(ctx, user, equipmentID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.records.GetByEquipmentID(ctx, equipment.EquipmentID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting maintenance records: %w", err)
+	}
+	return records, nil
+}
+
+// DeleteMaintenance soft deletes a maintenance record and its expense. The
+// equipment's service schedule is left as it is.
+func (s *equipmentService) DeleteMaintenance(ctx context.Context, user *data.User, maintenanceRecordID string) error {
+	record, err := s.records.GetByMaintenanceRecordID(ctx, maintenanceRecordID)
+	if err != nil {
+		return fmt.Errorf("getting maintenance record: %w", err)
+	}
+	if record == nil {
+		return service.NotFound("maintenance record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "maintenance record"); err != nil {
+		return err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return err
+	}
+	if err := s.records.DeleteByID(ctx, int(record.ID)); err != nil {
+		return fmt.Errorf("deleting maintenance record: %w", err)
+	}
+	return nil
+}
+
+// MaintenanceCosts totals maintenance cost and downtime per machine per year
+// on one of the user's farms. An empty equipmentID covers all equipment and a
+// zero year covers all years.
+func (s *equipmentService) MaintenanceCosts(ctx context.Context, user *data.User, farmID, equipmentID string, year int) ([]data.YearlyMaintenanceCost, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.records.YearlyCosts(ctx, farmID, equipmentID, year)
+	if err != nil {
+		return nil, fmt.Errorf("getting maintenance costs: %w", err)
+	}
+
+	equipment, err := s.equipment.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting equipment: %w", err)
+	}
+	names := make(map[string]string, len(equipment))
+	for _, e := range equipment {
+		names[e.EquipmentID] = e.Name
+	}
+	for i := range totals {
+		totals[i].EquipmentName = names[totals[i].EquipmentID]
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,375 @@
+// Package escrow holds a buyer's payment for a sale until delivery is
+// confirmed. The payment provider collects and pays out the money; this
+// service records the hold and decides when it ends: released to the farmer
+// on a gate pass scan, the buyer's confirmation or the automatic release
+// timer, or refunded to the buyer.
+package escrow
+
+import (
+	"context"
+	"crypto/rand"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/auth"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Escrow statuses
+const (
+	StatusHeld     = "Held"
+	StatusReleased = "Released"
+	StatusRefunded = "Refunded"
+)
+
+// Release methods
+const (
+	ReleaseGatePass     = "Gate Pass"
+	ReleaseConfirmation = "Buyer Confirmation"
+	ReleaseAutomatic    = "Automatic"
+	ReleaseArbitration  = "Arbitration"
+)
+
+// DefaultHoldDays is how long funds are held before automatic release when
+// the buyer does not choose
+const DefaultHoldDays = 7
+
+// Input is a buyer's payment into escrow for a sale
+type Input struct {
+	FarmerID         string
+	SaleReference    string
+	Amount           float64
+	PaymentReference string
+	HoldDays         int
+	Notes            string
+}
+
+// Service is the escrow domain service
+type Service interface {
+	// Hold records a buyer's payment into escrow and notifies the farmer
+	Hold(ctx context.Context, user *data.User, in Input) (*data.Escrow, error)
+	// List returns the escrows the user pays into or is paid from
+	List(ctx context.Context, user *data.User) ([]*data.Escrow, error)
+	// Get returns an escrow to either party or an admin
+	Get(ctx context.Context, user *data.User, escrowID string) (*data.Escrow, error)
+	// Confirm is the buyer confirming delivery, releasing the funds
+	Confirm(ctx context.Context, user *data.User, escrowID string) (*data.Escrow, error)
+	// Redeem is the farmer scanning the buyer's gate pass on delivery,
+	// releasing the funds
+	Redeem(ctx context.Context, user *data.User, escrowID, code string) (*data.Escrow, error)
+	// Refund returns the funds to the buyer. The farmer may refund their own
+	// sale; admins may refund any.
+	Refund(ctx context.Context, user *data.User, escrowID, reason string) (*data.Escrow, error)
+
+	// ListByStatus and Release are for admins; callers must have checked the role
+	ListByStatus(ctx context.Context, status string) ([]*data.Escrow, error)
+	Release(ctx context.Context, admin *data.User, escrowID string) (*data.Escrow, error)
+
+	// ReleaseDue releases held escrows past their release time, except those
+	// with an active dispute, and returns how many were released
+	ReleaseDue(ctx context.Context, now time.Time) (int, error)
+}
+
+// escrowService implements Service on top of the escrow repository
+type escrowService struct {
+	escrows       data.EscrowInterface
+	disputes      data.DisputeInterface
+	users         data.UserInterface
+	notifications data.NotificationInterface
+}
+
+// New creates the escrow service
+func New(escrows data.EscrowInterface, disputes data.DisputeInterface, users data.UserInterface, notifications data.NotificationInterface) Service {
+	return &escrowService{escrows: escrows, disputes: disputes, users: users, notifications: notifications}
+}
+
+// Hold records a buyer's payment into escrow for a sale with a farmer
+func (s *escrowService) Hold(ctx context.Context, user *data.User, in Input) (*data.Escrow, error) {
+	if user.Role != auth.BuyerRole {
+		return nil, service.Forbidden("only buyers can pay into escrow")
+	}
+
+	farmer, err := s.users.GetByUserID(ctx, in.FarmerID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farmer: %w", err)
+	}
+	if farmer == nil || !farmer.Active || farmer.Role != auth.DefaultRole {
+		return nil, service.NotFound("farmer not found")
+	}
+
+	existing, err := s.escrows.GetBySaleReference(ctx, in.SaleReference)
+	if err != nil {
+		return nil, fmt.Errorf("checking existing escrow: %w", err)
+	}
+	if existing != nil {
+		return nil, service.Conflict("this sale already has an escrow payment")
+	}
+
+	if in.HoldDays <= 0 {
+		in.HoldDays = DefaultHoldDays
+	}
+	code, err := releaseCode()
+	if err != nil {
+		return nil, fmt.Errorf("generating release code: %w", err)
+	}
+
+	escrow := &data.Escrow{
+		SaleReference:    in.SaleReference,
+		BuyerID:          user.UserID,
+		FarmerID:         farmer.UserID,
+		Amount:           in.Amount,
+		PaymentReference: in.PaymentReference,
+		Status:           StatusHeld,
+		ReleaseCode:      code,
+		ReleaseAfter:     time.Now().AddDate(0, 0, in.HoldDays),
+		Notes:            in.Notes,
+	}
+	if err := s.escrows.Insert(ctx, escrow); err != nil {
+		return nil, fmt.Errorf("creating escrow: %w", err)
+	}
+
+	if err := s.notify(ctx, escrow.FarmerID, escrow, "escrow_held", "Payment held in escrow",
+		fmt.Sprintf("%.2f for sale %s is held in escrow. It is released when the buyer's gate pass is scanned on delivery, or on %s.",
+			escrow.Amount, escrow.SaleReference, escrow.ReleaseAfter.Format("2006-01-02"))); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// List returns the escrows the user pays into or is paid from
+func (s *escrowService) List(ctx context.Context, user *data.User) ([]*data.Escrow, error) {
+	escrows, err := s.escrows.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting escrows: %w", err)
+	}
+	for _, escrow := range escrows {
+		hideCode(user, escrow)
+	}
+	return escrows, nil
+}
+
+// Get returns an escrow to either party or an admin
+func (s *escrowService) Get(ctx context.Context, user *data.User, escrowID string) (*data.Escrow, error) {
+	escrow, err := s.find(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserID != escrow.BuyerID && user.UserID != escrow.FarmerID && user.Role != auth.AdminRole {
+		return nil, service.Forbidden("access denied: you are not a party to this escrow")
+	}
+	hideCode(user, escrow)
+	return escrow, nil
+}
+
+// Confirm is the buyer confirming delivery, releasing the funds to the farmer
+func (s *escrowService) Confirm(ctx context.Context, user *data.User, escrowID string) (*data.Escrow, error) {
+	escrow, err := s.find(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow.BuyerID != user.UserID {
+		return nil, service.Forbidden("only the buyer can confirm delivery")
+	}
+	return s.release(ctx, escrow, ReleaseConfirmation, &user.UserID)
+}
+
+// Redeem is the farmer scanning the buyer's gate pass on delivery. The code
+// must match the escrow's release code.
+func (s *escrowService) Redeem(ctx context.Context, user *data.User, escrowID, code string) (*data.Escrow, error) {
+	escrow, err := s.find(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow.FarmerID != user.UserID {
+		return nil, service.Forbidden("only the farmer can redeem a gate pass")
+	}
+	if escrow.Status == StatusHeld && code != escrow.ReleaseCode {
+		return nil, service.Invalid("gate pass code does not match")
+	}
+	escrow, err = s.release(ctx, escrow, ReleaseGatePass, &user.UserID)
+	if err != nil {
+		return nil, err
+	}
+	escrow.ReleaseCode = ""
+	return escrow, nil
+}
+
+// Refund returns held funds to the buyer
+func (s *escrowService) Refund(ctx context.Context, user *data.User, escrowID, reason string) (*data.Escrow, error) {
+	if reason == "" {
+		return nil, service.Invalid("a refund reason is required")
+	}
+
+	escrow, err := s.find(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	if escrow.FarmerID != user.UserID && user.Role != auth.AdminRole {
+		return nil, service.Forbidden("only the farmer or an admin can refund an escrow")
+	}
+
+	now := time.Now()
+	escrow.Status = StatusRefunded
+	escrow.RefundedAt = &now
+	escrow.RefundReason = reason
+	escrow.ClosedBy = &user.UserID
+	if err := s.close(ctx, escrow); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("%.2f held for sale %s was refunded to the buyer: %s", escrow.Amount, escrow.SaleReference, reason)
+	if err := s.notifyBoth(ctx, escrow, "escrow_refunded", "Escrow refunded", message); err != nil {
+		return nil, err
+	}
+	hideCode(user, escrow)
+	return escrow, nil
+}
+
+// ListByStatus returns escrows with a status, or all when status is empty
+func (s *escrowService) ListByStatus(ctx context.Context, status string) ([]*data.Escrow, error) {
+	escrows, err := s.escrows.GetByStatus(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting escrows: %w", err)
+	}
+	for _, escrow := range escrows {
+		escrow.ReleaseCode = ""
+	}
+	return escrows, nil
+}
+
+// Release is an admin releasing held funds to the farmer after arbitration
+func (s *escrowService) Release(ctx context.Context, admin *data.User, escrowID string) (*data.Escrow, error) {
+	escrow, err := s.find(ctx, escrowID)
+	if err != nil {
+		return nil, err
+	}
+	escrow.ReleaseCode = ""
+	return s.release(ctx, escrow, ReleaseArbitration, &admin.UserID)
+}
+
+// ReleaseDue releases held escrows past their release time. An escrow with an
+// active dispute on its sale stays held until the dispute is settled.
+func (s *escrowService) ReleaseDue(ctx context.Context, now time.Time) (int, error) {
+	escrows, err := s.escrows.GetDueForRelease(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("getting escrows due for release: %w", err)
+	}
+
+	released := 0
+	for _, escrow := range escrows {
+		disputed, err := s.disputed(ctx, escrow)
+		if err != nil {
+			return released, err
+		}
+		if disputed {
+			continue
+		}
+		if _, err := s.release(ctx, escrow, ReleaseAutomatic, nil); err != nil {
+			if service.KindOf(err) == service.KindConflict {
+				continue
+			}
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// release pays held funds out to the farmer and notifies both parties
+func (s *escrowService) release(ctx context.Context, escrow *data.Escrow, method string, by *string) (*data.Escrow, error) {
+	now := time.Now()
+	escrow.Status = StatusReleased
+	escrow.ReleasedAt = &now
+	escrow.ReleaseMethod = method
+	escrow.ClosedBy = by
+	if err := s.close(ctx, escrow); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("%.2f held for sale %s was released to the farmer (%s).", escrow.Amount, escrow.SaleReference, method)
+	if err := s.notifyBoth(ctx, escrow, "escrow_released", "Escrow released", message); err != nil {
+		return nil, err
+	}
+	return escrow, nil
+}
+
+// close saves a released or refunded escrow, failing if it was no longer held
+func (s *escrowService) close(ctx context.Context, escrow *data.Escrow) error {
+	closed, err := s.escrows.Close(ctx, escrow)
+	if err != nil {
+		return fmt.Errorf("closing escrow: %w", err)
+	}
+	if !closed {
+		return service.Conflict("escrow has already been released or refunded")
+	}
+	return nil
+}
+
+// disputed reports whether either party has an active dispute about the sale
+func (s *escrowService) disputed(ctx context.Context, escrow *data.Escrow) (bool, error) {
+	for _, parties := range [][2]string{{escrow.BuyerID, escrow.FarmerID}, {escrow.FarmerID, escrow.BuyerID}} {
+		active, err := s.disputes.ExistsActive(ctx, parties[0], parties[1], escrow.SaleReference)
+		if err != nil {
+			return false, fmt.Errorf("checking disputes: %w", err)
+		}
+		if active {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// find loads an escrow by ID
+func (s *escrowService) find(ctx context.Context, escrowID string) (*data.Escrow, error) {
+	escrow, err := s.escrows.GetByEscrowID(ctx, escrowID)
+	if err != nil {
+		return nil, fmt.Errorf("getting escrow: %w", err)
+	}
+	if escrow == nil {
+		return nil, service.NotFound("escrow not found")
+	}
+	return escrow, nil
+}
+
+// notifyBoth tells the buyer and the farmer about an escrow
+func (s *escrowService) notifyBoth(ctx context.Context, escrow *data.Escrow, kind, title, message string) error {
+	for _, userID := range []string{escrow.BuyerID, escrow.FarmerID} {
+		if err := s.notify(ctx, userID, escrow, kind, title, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notify tells one party about an escrow
+func (s *escrowService) notify(ctx context.Context, userID string, escrow *data.Escrow, kind, title, message string) error {
+	notification := &data.Notification{
+		UserID:    userID,
+		Type:      kind,
+		Title:     title,
+		Message:   message,
+		Reference: fmt.Sprintf("escrow:%s:%s", escrow.EscrowID, escrow.Status),
+	}
+	if err := s.notifications.Insert(ctx, notification); err != nil {
+		return fmt.Errorf("notifying escrow party: %w", err)
+	}
+	return nil
+}
+
+// hideCode blanks the release code for anyone but the buyer, who hands it
+// over on delivery
+func hideCode(user *data.User, escrow *data.Escrow) {
+	if user.UserID != escrow.BuyerID {
+		escrow.ReleaseCode = ""
+	}
+}
+
+// releaseCode generates a random 8-digit gate pass code
+func releaseCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%08d", n.Int64()), nil
+}
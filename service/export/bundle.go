@@ -0,0 +1,252 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"farm4u/data"
+	"farm4u/storage"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// manifest describes an export's contents, written to its manifest.json
+type manifest struct {
+	ExportedAt time.Time      `json:"exportedAt"`
+	UserID     string         `json:"userId"`
+	Farms      []manifestFarm `json:"farms"`
+	Files      int            `json:"files"`
+}
+
+// manifestFarm lists how many records of each kind an export holds for a farm
+type manifestFarm struct {
+	FarmID  string         `json:"farmId"`
+	Name    string         `json:"name"`
+	Folder  string         `json:"folder"`
+	Records map[string]int `json:"records"`
+	Files   int            `json:"files"`
+}
+
+// bundle writes the user's data to a ZIP and stores it under the job's file
+// key, recording what it holds on the job. The ZIP is laid out as
+//
+//	manifest.json
+//	profile.json
+//	notifications.json, notifications.csv
+//	farms/<farmId>/farm.json
+//	farms/<farmId>/<records>.json, <records>.csv   e.g. crops.json, crops.csv
+//	farms/<farmId>/files/<attachmentId>-<fileName>
+//
+// It is built in a temporary file, as attached files can make it large.
+func (s *exportService) bundle(ctx context.Context, job *data.ExportJob) error {
+	user, err := s.users.GetByUserID(ctx, job.UserID)
+	if err != nil {
+		return fmt.Errorf("getting user: %w", err)
+	}
+	if user == nil {
+		return errors.New("user no longer exists")
+	}
+	farms, err := s.farms.GetByUserID(ctx, job.UserID)
+	if err != nil {
+		return fmt.Errorf("getting farms: %w", err)
+	}
+	notifications, err := s.notifications.GetByUserID(ctx, job.UserID, false)
+	if err != nil {
+		return fmt.Errorf("getting notifications: %w", err)
+	}
+
+	tmp, err := os.CreateTemp("", "farm4u-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("creating export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	contents := manifest{ExportedAt: time.Now(), UserID: user.UserID, Farms: []manifestFarm{}}
+
+	// The outer password field, empty on a stored user, hides the embedded
+	// one so it is left out rather than written blank
+	profile := struct {
+		*data.User
+		TempPassword string `json:"password,omitempty"`
+	}{User: user}
+	if err := writeJSON(zw, "profile.json", profile); err != nil {
+		return err
+	}
+	if err := writeRecords(zw, "notifications", notifications); err != nil {
+		return err
+	}
+
+	for _, f := range farms {
+		folder := "farms/" + f.FarmID
+		entry := manifestFarm{FarmID: f.FarmID, Name: f.Name, Folder: folder, Records: map[string]int{}}
+		if err := writeJSON(zw, folder+"/farm.json", f); err != nil {
+			return err
+		}
+
+		sets, err := s.records.FarmRecords(ctx, f.FarmID)
+		if err != nil {
+			return fmt.Errorf("getting records of farm %s: %w", f.FarmID, err)
+		}
+		for _, set := range sets {
+			if err := writeRecords(zw, folder+"/"+set.Name, set.Records); err != nil {
+				return err
+			}
+			entry.Records[set.Name] = set.Count
+
+			if attachments, ok := set.Records.([]*data.Attachment); ok {
+				n, err := s.writeFiles(ctx, zw, folder+"/files", attachments)
+				if err != nil {
+					return err
+				}
+				entry.Files = n
+			}
+		}
+		contents.Farms = append(contents.Farms, entry)
+		contents.Files += entry.Files
+	}
+
+	if err := writeJSON(zw, "manifest.json", contents); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("writing export file: %w", err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("sizing export file: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding export file: %w", err)
+	}
+	if err := s.files.Put(ctx, job.FileKey(), tmp, size, "application/zip"); err != nil {
+		return fmt.Errorf("storing export file: %w", err)
+	}
+
+	job.Farms, job.Files, job.Size = len(farms), contents.Files, size
+	return nil
+}
+
+// writeFiles copies the uploaded files of attachments into the ZIP under
+// dir, returning how many it copied. Files missing from storage are skipped;
+// their attachment records are still exported.
+func (s *exportService) writeFiles(ctx context.Context, zw *zip.Writer, dir string, attachments []*data.Attachment) (int, error) {
+	copied := 0
+	for _, a := range attachments {
+		if a.UploadedAt == nil {
+			continue
+		}
+		file, _, err := s.files.Get(ctx, a.FileKey())
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return copied, fmt.Errorf("opening attachment %s: %w", a.AttachmentID, err)
+		}
+
+		name := dir + "/" + a.AttachmentID + "-" + path.Base(a.FileName)
+		w, err := zw.Create(name)
+		if err == nil {
+			_, err = io.Copy(w, file)
+		}
+		file.Close()
+		if err != nil {
+			return copied, fmt.Errorf("writing attachment %s: %w", a.AttachmentID, err)
+		}
+		copied++
+	}
+	return copied, nil
+}
+
+// writeJSON writes v to the ZIP as indented JSON
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeRecords writes a slice of records to the ZIP twice: as name.json, and
+// as name.csv with a column per JSON field. Nested values, such as a purchase
+// order's lines, are kept as JSON in their cell.
+func writeRecords(zw *zip.Writer, name string, records any) error {
+	if err := writeJSON(zw, name+".json", records); err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(records)
+	if err != nil {
+		return fmt.Errorf("writing %s.csv: %w", name, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var rows []map[string]any
+	if err := dec.Decode(&rows); err != nil {
+		return fmt.Errorf("writing %s.csv: %w", name, err)
+	}
+
+	columns := map[string]bool{}
+	for _, row := range rows {
+		for column := range row {
+			columns[column] = true
+		}
+	}
+	header := slices.Sorted(maps.Keys(columns))
+
+	w, err := zw.Create(name + ".csv")
+	if err != nil {
+		return fmt.Errorf("writing %s.csv: %w", name, err)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("writing %s.csv: %w", name, err)
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, column := range header {
+			record[i] = cell(row[column])
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("writing %s.csv: %w", name, err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("writing %s.csv: %w", name, err)
+	}
+	return nil
+}
+
+// cell formats a decoded JSON value for a CSV cell
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		raw, _ := json.Marshal(v)
+		return string(raw)
+	}
+}
@@ -0,0 +1,217 @@
+// Package export bundles everything a user keeps in farm4u, their profile,
+// the farms they own with every record and attached file, and their
+// notifications, into one downloadable ZIP, so they can take their data with
+// them. Exports are bundled in the background and polled for until Ready,
+// and the user is notified when theirs is.
+package export
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/storage"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Export job statuses
+const (
+	StatusPending = "Pending"
+	StatusRunning = "Running"
+	StatusReady   = "Ready"
+	StatusFailed  = "Failed"
+)
+
+// Notification types sent when an export finishes
+const (
+	NotifyReady  = "export_ready"
+	NotifyFailed = "export_failed"
+)
+
+// Service is the account export domain service
+type Service interface {
+	// Request queues an export of all of the user's data. An export already
+	// queued is returned instead of queuing another.
+	Request(ctx context.Context, user *data.User) (*data.ExportJob, error)
+	Get(ctx context.Context, user *data.User, exportJobID string) (*data.ExportJob, error)
+	// List returns the user's exports, newest first
+	List(ctx context.Context, user *data.User) ([]*data.ExportJob, error)
+	// Open returns a Ready export's ZIP. The caller must close it.
+	Open(ctx context.Context, user *data.User, exportJobID string) (io.ReadCloser, *data.ExportJob, error)
+	// Generate bundles a queued export, stores its ZIP and notifies the
+	// user. An export already claimed by another worker is left alone.
+	Generate(ctx context.Context, exportJobID string) error
+	// GenerateQueued bundles exports still Pending that were requested
+	// before queuedBefore, and fails those Running since before
+	// runningBefore, returning how many were bundled
+	GenerateQueued(ctx context.Context, queuedBefore, runningBefore time.Time) (int, error)
+}
+
+// exportService implements Service on top of the export job repository,
+// object storage and the repositories the export draws on
+type exportService struct {
+	jobs          data.ExportJobInterface
+	records       data.AccountExportInterface
+	files         storage.Storage
+	users         data.UserInterface
+	farms         data.FarmInterface
+	notifications data.NotificationInterface
+}
+
+// New creates the account export service. Repositories are read directly as
+// exports are bundled in the background, away from the user who requested
+// them.
+func New(jobs data.ExportJobInterface, records data.AccountExportInterface, files storage.Storage,
+	users data.UserInterface, farms data.FarmInterface, notifications data.NotificationInterface) Service {
+	return &exportService{
+		jobs:          jobs,
+		records:       records,
+		files:         files,
+		users:         users,
+		farms:         farms,
+		notifications: notifications,
+	}
+}
+
+// Request implements Service
+func (s *exportService) Request(ctx context.Context, user *data.User) (*data.ExportJob, error) {
+	queued, err := s.jobs.GetQueued(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting queued export: %w", err)
+	}
+	if queued != nil {
+		return queued, nil
+	}
+
+	job := &data.ExportJob{UserID: user.UserID, Status: StatusPending}
+	if err := s.jobs.Insert(ctx, job); err != nil {
+		return nil, fmt.Errorf("queuing export: %w", err)
+	}
+	return job, nil
+}
+
+// Get implements Service
+func (s *exportService) Get(ctx context.Context, user *data.User, exportJobID string) (*data.ExportJob, error) {
+	job, err := s.jobs.GetByExportJobID(ctx, exportJobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting export: %w", err)
+	}
+	if job == nil || job.UserID != user.UserID {
+		return nil, service.NotFound("export not found")
+	}
+	return job, nil
+}
+
+// List implements Service
+func (s *exportService) List(ctx context.Context, user *data.User) ([]*data.ExportJob, error) {
+	jobs, err := s.jobs.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting exports: %w", err)
+	}
+	return jobs, nil
+}
+
+// Open implements Service
+func (s *exportService) Open(ctx context.Context, user *data.User, exportJobID string) (io.ReadCloser, *data.ExportJob, error) {
+	job, err := s.Get(ctx, user, exportJobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch job.Status {
+	case StatusFailed:
+		return nil, nil, service.Conflict("export could not be generated: " + job.Error)
+	case StatusPending, StatusRunning:
+		return nil, nil, service.Conflict("export is still being generated")
+	}
+
+	file, _, err := s.files.Get(ctx, job.FileKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening export file: %w", err)
+	}
+	return file, job, nil
+}
+
+// Generate implements Service. Bundling errors are recorded on the job;
+// only failures to record them are returned.
+func (s *exportService) Generate(ctx context.Context, exportJobID string) error {
+	job, err := s.jobs.GetByExportJobID(ctx, exportJobID)
+	if err != nil {
+		return fmt.Errorf("getting export: %w", err)
+	}
+	if job == nil || job.Status != StatusPending {
+		return nil
+	}
+
+	now := time.Now()
+	job.StartedAt = &now
+	claimed, err := s.jobs.Claim(ctx, job)
+	if err != nil {
+		return fmt.Errorf("claiming export: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	bundleErr := s.bundle(ctx, job)
+	completed := time.Now()
+	job.CompletedAt = &completed
+	if bundleErr != nil {
+		job.Status, job.Error = StatusFailed, bundleErr.Error()
+	} else {
+		job.Status = StatusReady
+	}
+	if err := s.jobs.Finish(ctx, job); err != nil {
+		return fmt.Errorf("finishing export: %w", err)
+	}
+	if err := s.notify(ctx, job); err != nil {
+		return err
+	}
+	if bundleErr != nil {
+		return fmt.Errorf("bundling export %s: %w", job.ExportJobID, bundleErr)
+	}
+	return nil
+}
+
+// GenerateQueued implements Service
+func (s *exportService) GenerateQueued(ctx context.Context, queuedBefore, runningBefore time.Time) (int, error) {
+	if _, err := s.jobs.FailStale(ctx, runningBefore, "export was interrupted; request it again"); err != nil {
+		return 0, fmt.Errorf("failing stale exports: %w", err)
+	}
+
+	ids, err := s.jobs.GetPending(ctx, queuedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("getting queued exports: %w", err)
+	}
+	generated := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.Generate(ctx, id); err != nil {
+			return generated, err
+		}
+		generated++
+	}
+	return generated, nil
+}
+
+// notify tells the user their export has finished
+func (s *exportService) notify(ctx context.Context, job *data.ExportJob) error {
+	notification := &data.Notification{
+		UserID:    job.UserID,
+		Type:      NotifyReady,
+		Title:     "Your data export is ready",
+		Message:   fmt.Sprintf("Your export of %d farms and %d files is ready to download.", job.Farms, job.Files),
+		Reference: "export:" + job.ExportJobID,
+	}
+	if job.Status == StatusFailed {
+		notification.Type = NotifyFailed
+		notification.Title = "Your data export failed"
+		notification.Message = "Your data export could not be generated: " + job.Error + ". Please request it again."
+	}
+	if err := s.notifications.Insert(ctx, notification); err != nil {
+		return fmt.Errorf("notifying export user: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package farm
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RoleAccountant is the farm role for a bookkeeper or accountant: read-only
+// access to the farm's finances and nothing else
+const RoleAccountant = "Accountant"
+
+// Module groups the records of a farm that access is granted to
+type Module string
+
+// Modules of the permissions matrix. Operational records (fields, crops,
+// livestock, employees, equipment, inventory and the rest) have no module:
+// only the farm's owner may use them.
+const (
+	// ModuleFinance covers the ledger's transactions and tax rates
+	ModuleFinance Module = "finance"
+	// ModulePayroll covers payroll payments and their totals
+	ModulePayroll Module = "payroll"
+	// ModuleReports covers financial reports such as profitability, the tax
+	// summary and the balance sheet
+	ModuleReports Module = "reports"
+)
+
+// Action is what a user wants to do with a module's records
+type Action string
+
+// Actions
+const (
+	Read  Action = "read"
+	Write Action = "write"
+)
+
+// Permissions is the permissions matrix: the actions each farm role may take
+// on each module. A farm's owner may take every action; anything a role is
+// not given here is denied.
+var Permissions = map[string]map[Module][]Action{
+	RoleAccountant: {
+		ModuleFinance: {Read},
+		ModulePayroll: {Read},
+		ModuleReports: {Read},
+	},
+}
+
+// Allowed reports whether role may take action on module
+func Allowed(role string, module Module, action Action) bool {
+	return slices.Contains(Permissions[role][module], action)
+}
+
+// MemberInput gives a user, by email, a role on a farm
+type MemberInput struct {
+	Email string
+	Role  string
+}
+
+// Authorize implements Service
+func (s *farmService) Authorize(ctx context.Context, user *data.User, farmID string, module Module, action Action) (*data.Farm, error) {
+	farm, err := s.farm(ctx, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if farm == nil {
+		return nil, service.Forbidden("farm not found or access denied")
+	}
+	if farm.UserID != user.UserID {
+		member, err := s.member(ctx, farmID, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil || !Allowed(member.Role, module, action) {
+			return nil, service.Forbidden("farm not found or access denied")
+		}
+	}
+	if err := writable(ctx, farm); err != nil {
+		return nil, err
+	}
+	return farm, nil
+}
+
+// Access implements Service
+func (s *farmService) Access(ctx context.Context, user *data.User, farmID string) (*data.Farm, error) {
+	farm, err := s.farm(ctx, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if farm == nil {
+		return nil, service.Forbidden("farm not found or access denied")
+	}
+	if farm.UserID != user.UserID {
+		member, err := s.member(ctx, farmID, user.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if member == nil {
+			return nil, service.Forbidden("farm not found or access denied")
+		}
+	}
+	if err := writable(ctx, farm); err != nil {
+		return nil, err
+	}
+	return farm, nil
+}
+
+// CheckAccess verifies that user may take action on module's records kept on
+// farmID. what names the record in the error, e.g. "transaction".
+func CheckAccess(ctx context.Context, farms Service, user *data.User, farmID, what string, module Module, action Action) error {
+	_, err := farms.Authorize(ctx, user, farmID, module, action)
+	if service.KindOf(err) == service.KindForbidden {
+		return service.Forbidden(fmt.Sprintf("access denied: %s does not belong to user's farm", what))
+	}
+	return err
+}
+
+// AddMember gives another user a role on one of the user's farms
+func (s *farmService) AddMember(ctx context.Context, user *data.User, farmID string, in MemberInput) (*data.FarmMember, error) {
+	if _, err := s.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if _, ok := Permissions[in.Role]; !ok {
+		return nil, service.Invalid("role must be one of " + strings.Join(Roles(), ", "))
+	}
+
+	member, err := s.users.GetByEmail(ctx, strings.TrimSpace(in.Email))
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+	if member == nil || !member.Active {
+		return nil, service.NotFound("no active user with that email")
+	}
+	if member.UserID == user.UserID {
+		return nil, service.Invalid("the farm's owner already has full access")
+	}
+
+	existing, err := s.members.GetByFarmAndUser(ctx, farmID, member.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farm member: %w", err)
+	}
+	if existing != nil {
+		return nil, service.Conflict("user is already a member of this farm")
+	}
+
+	farmMember := &data.FarmMember{
+		FarmID:  farmID,
+		UserID:  member.UserID,
+		Role:    in.Role,
+		AddedBy: user.UserID,
+		User:    member,
+	}
+	if err := s.members.Insert(ctx, farmMember); err != nil {
+		return nil, fmt.Errorf("adding farm member: %w", err)
+	}
+	forget(ctx, farmID)
+	return farmMember, nil
+}
+
+// ListMembers returns the members of one of the user's farms
+func (s *farmService) ListMembers(ctx context.Context, user *data.User, farmID string) ([]*data.FarmMember, error) {
+	if _, err := s.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	members, err := s.members.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farm members: %w", err)
+	}
+	return members, nil
+}
+
+// RemoveMember takes a member's access to one of the user's farms away
+func (s *farmService) RemoveMember(ctx context.Context, user *data.User, farmMemberID string) error {
+	member, err := s.members.GetByFarmMemberID(ctx, farmMemberID)
+	if err != nil {
+		return fmt.Errorf("getting farm member: %w", err)
+	}
+	if member == nil {
+		return service.NotFound("farm member not found")
+	}
+	if err := CheckRecord(ctx, s, user, member.FarmID, "farm member"); err != nil {
+		return err
+	}
+	if err := s.members.DeleteByID(ctx, int(member.ID)); err != nil {
+		return fmt.Errorf("removing farm member: %w", err)
+	}
+	forget(ctx, member.FarmID)
+	return nil
+}
+
+// Memberships returns the farms other users have given the user a role on
+func (s *farmService) Memberships(ctx context.Context, user *data.User) ([]*data.FarmMember, error) {
+	members, err := s.members.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farm memberships: %w", err)
+	}
+	return slices.DeleteFunc(members, func(m *data.FarmMember) bool { return restricted(ctx, m.FarmID) }), nil
+}
+
+// Reachable implements Service, owned farms first
+func (s *farmService) Reachable(ctx context.Context, user *data.User, module Module, action Action) ([]*data.Farm, error) {
+	farms, err := s.List(ctx, user)
+	if err != nil || module == "" {
+		return farms, err
+	}
+	members, err := s.Memberships(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		if member.Farm != nil && member.Farm.Status != StatusArchived && Allowed(member.Role, module, action) {
+			farms = append(farms, member.Farm)
+		}
+	}
+	return farms, nil
+}
+
+// Roles returns the farm roles an owner can give, in order
+func Roles() []string {
+	roles := make([]string, 0, len(Permissions))
+	for role := range Permissions {
+		roles = append(roles, role)
+	}
+	slices.Sort(roles)
+	return roles
+}
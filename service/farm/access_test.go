@@ -0,0 +1,97 @@
+package farm
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"testing"
+)
+
+// stubFarms serves one farm
+type stubFarms struct {
+	data.FarmInterface
+	farm *data.Farm
+}
+
+func (s stubFarms) GetByFarmID(ctx context.Context, farmID string) (*data.Farm, error) {
+	if s.farm.FarmID != farmID {
+		return nil, nil
+	}
+	return s.farm, nil
+}
+
+// stubMembers serves the members of one farm, by user ID
+type stubMembers struct {
+	data.FarmMemberInterface
+	members map[string]*data.FarmMember
+}
+
+func (s stubMembers) GetByFarmAndUser(ctx context.Context, farmID, userID string) (*data.FarmMember, error) {
+	return s.members[userID], nil
+}
+
+func TestPermissions(t *testing.T) {
+	tests := []struct {
+		role    string
+		module  Module
+		action  Action
+		allowed bool
+	}{
+		{role: RoleAccountant, module: ModuleFinance, action: Read, allowed: true},
+		{role: RoleAccountant, module: ModuleFinance, action: Write},
+		{role: RoleAccountant, module: ModulePayroll, action: Read, allowed: true},
+		{role: RoleAccountant, module: ModulePayroll, action: Write},
+		{role: RoleAccountant, module: ModuleReports, action: Read, allowed: true},
+		{role: RoleAccountant, module: ModuleReports, action: Write},
+		{role: RoleAccountant, module: "", action: Read},
+		{role: "Manager", module: ModuleFinance, action: Read},
+	}
+
+	for _, tt := range tests {
+		if got := Allowed(tt.role, tt.module, tt.action); got != tt.allowed {
+			t.Errorf("Allowed(%q, %q, %q) = %v, want %v", tt.role, tt.module, tt.action, got, tt.allowed)
+		}
+	}
+}
+
+// TestAuthorize checks every role of the permissions matrix, the owner and a
+// stranger against every module and action
+func TestAuthorize(t *testing.T) {
+	owner := &data.User{UserID: "owner"}
+	stranger := &data.User{UserID: "stranger"}
+	f := &data.Farm{FarmID: "farm", UserID: owner.UserID, Status: StatusActive}
+	members := map[string]*data.FarmMember{}
+	users := map[string]*data.User{}
+	for role := range Permissions {
+		members[role] = &data.FarmMember{FarmID: f.FarmID, UserID: role, Role: role}
+		users[role] = &data.User{UserID: role}
+	}
+	s := &farmService{farms: stubFarms{farm: f}, members: stubMembers{members: members}}
+
+	ctx := context.Background()
+	for _, module := range []Module{ModuleFinance, ModulePayroll, ModuleReports, ""} {
+		for _, action := range []Action{Read, Write} {
+			if _, err := s.Authorize(ctx, owner, f.FarmID, module, action); err != nil {
+				t.Errorf("owner %s %q: %v", action, module, err)
+			}
+			if _, err := s.Authorize(ctx, stranger, f.FarmID, module, action); service.KindOf(err) != service.KindForbidden {
+				t.Errorf("stranger %s %q: got %v, want forbidden", action, module, err)
+			}
+			for role, user := range users {
+				_, err := s.Authorize(ctx, user, f.FarmID, module, action)
+				if allowed := Allowed(role, module, action); allowed && err != nil {
+					t.Errorf("%s %s %q: %v", role, action, module, err)
+				} else if !allowed && service.KindOf(err) != service.KindForbidden {
+					t.Errorf("%s %s %q: got %v, want forbidden", role, action, module, err)
+				}
+			}
+		}
+	}
+
+	if _, err := s.Authorize(ctx, owner, "other", ModuleFinance, Read); service.KindOf(err) != service.KindForbidden {
+		t.Errorf("missing farm: got %v, want forbidden", err)
+	}
+	if _, err := s.Authorize(Restrict(ctx, "other"), owner, f.FarmID, ModuleFinance, Read); service.KindOf(err) != service.KindForbidden {
+		t.Errorf("restricted to another farm: got %v, want forbidden", err)
+	}
+}
@@ -0,0 +1,78 @@
+package farm
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"slices"
+)
+
+// Farm statuses. An archived farm is one the owner has stopped running but
+// keeps for its history: it is left out of their farm list and its records
+// can be read but not changed.
+const (
+	StatusActive   = "Active"
+	StatusArchived = "Archived"
+)
+
+// writingKey is the context key marking a request that changes records
+type writingKey struct{}
+
+// Writing returns a copy of ctx for a request that changes records. Access
+// checks made with it refuse archived farms, so every service's writes are
+// kept off them while reads go through.
+func Writing(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writingKey{}, true)
+}
+
+// writable refuses a request that changes records if farm is archived
+func writable(ctx context.Context, farm *data.Farm) error {
+	if writing, _ := ctx.Value(writingKey{}).(bool); writing && farm.Status == StatusArchived {
+		return service.Conflict("farm is archived; unarchive it to make changes")
+	}
+	return nil
+}
+
+// withoutArchived drops archived farms
+func withoutArchived(farms []*data.Farm) []*data.Farm {
+	return slices.DeleteFunc(farms, func(f *data.Farm) bool { return f.Status == StatusArchived })
+}
+
+// ListArchived implements Service
+func (s *farmService) ListArchived(ctx context.Context, user *data.User) ([]*data.Farm, error) {
+	farms, err := s.farms.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farms: %w", err)
+	}
+	return slices.DeleteFunc(onlyAllowed(ctx, farms), func(f *data.Farm) bool { return f.Status != StatusArchived }), nil
+}
+
+// Archive implements Service. Archiving an archived farm changes nothing.
+func (s *farmService) Archive(ctx context.Context, user *data.User, farmID string) (*data.Farm, error) {
+	return s.setStatus(ctx, user, farmID, StatusArchived)
+}
+
+// Unarchive implements Service, making the farm Active again
+func (s *farmService) Unarchive(ctx context.Context, user *data.User, farmID string) (*data.Farm, error) {
+	return s.setStatus(ctx, user, farmID, StatusActive)
+}
+
+// setStatus moves one of the user's farms between archived and active
+func (s *farmService) setStatus(ctx context.Context, user *data.User, farmID, status string) (*data.Farm, error) {
+	farm, err := s.Get(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if (farm.Status == StatusArchived) == (status == StatusArchived) {
+		return farm, nil
+	}
+
+	farm.Status = status
+	err = s.farms.Update(ctx, farm)
+	forget(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("updating farm: %w", err)
+	}
+	return farm, nil
+}
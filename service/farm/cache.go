@@ -0,0 +1,109 @@
+package farm
+
+import (
+	"context"
+	"farm4u/data"
+	"fmt"
+	"sync"
+)
+
+// cacheKey is the context key holding a request's cache
+type cacheKey struct{}
+
+// cache holds the farms and memberships read by access checks during one
+// request
+type cache struct {
+	mu      sync.Mutex
+	farms   map[string]*data.Farm
+	members map[[2]string]*data.FarmMember // By farm and user ID; nil if not a member
+}
+
+// WithCache returns a copy of ctx in which access checks remember the farms
+// and memberships they read. A request's access to a farm is checked by the
+// FarmAccess middleware and again by every service it calls; with the cache
+// only the first check reads the database. The cache lives as long as ctx, so
+// use it for a single request.
+func WithCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheKey{}, &cache{
+		farms:   map[string]*data.Farm{},
+		members: map[[2]string]*data.FarmMember{},
+	})
+}
+
+// cacheFrom returns ctx's cache, or nil if it has none
+func cacheFrom(ctx context.Context) *cache {
+	c, _ := ctx.Value(cacheKey{}).(*cache)
+	return c
+}
+
+// forget drops what the request's cache holds about a farm, after the farm or
+// its members change
+func forget(ctx context.Context, farmID string) {
+	c := cacheFrom(ctx)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.farms, farmID)
+	for key := range c.members {
+		if key[0] == farmID {
+			delete(c.members, key)
+		}
+	}
+}
+
+// farm returns the farm with farmID, or nil if there is none or the request
+// is restricted to another farm, reading it from the request's cache when it
+// is there
+func (s *farmService) farm(ctx context.Context, farmID string) (*data.Farm, error) {
+	if restricted(ctx, farmID) {
+		return nil, nil
+	}
+	c := cacheFrom(ctx)
+	if c != nil {
+		c.mu.Lock()
+		farm, ok := c.farms[farmID]
+		c.mu.Unlock()
+		if ok {
+			return farm, nil
+		}
+	}
+
+	farm, err := s.farms.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farm: %w", err)
+	}
+	if c != nil {
+		c.mu.Lock()
+		c.farms[farmID] = farm
+		c.mu.Unlock()
+	}
+	return farm, nil
+}
+
+// member returns user's membership of a farm, or nil if they have none,
+// reading it from the request's cache when it is there
+func (s *farmService) member(ctx context.Context, farmID, userID string) (*data.FarmMember, error) {
+	key := [2]string{farmID, userID}
+	c := cacheFrom(ctx)
+	if c != nil {
+		c.mu.Lock()
+		member, ok := c.members[key]
+		c.mu.Unlock()
+		if ok {
+			return member, nil
+		}
+	}
+
+	member, err := s.members.GetByFarmAndUser(ctx, farmID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farm member: %w", err)
+	}
+	if c != nil {
+		c.mu.Lock()
+		c.members[key] = member
+		c.mu.Unlock()
+	}
+	return member, nil
+}
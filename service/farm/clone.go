@@ -0,0 +1,165 @@
+package farm
+
+import (
+	"context"
+	"farm4u/data"
+	"fmt"
+	"time"
+)
+
+// CloneInput names the farm a clone creates. Empty fields are copied from
+// the farm being cloned, the name with " (copy)" added.
+type CloneInput struct {
+	Name     string
+	Location string
+}
+
+// Cloned is a farm created by Clone, with how many records of each kind were
+// copied into it
+type Cloned struct {
+	Farm           *data.Farm `json:"farm"`
+	Fields         int        `json:"fields"`
+	Crops          int        `json:"crops"`
+	Schedules      int        `json:"schedules"`
+	InventoryItems int        `json:"inventoryItems"`
+}
+
+// Clone implements Service. The structure of the farm is copied: its fields;
+// the crops growing on them, as the new farm's default crops, without dates;
+// its irrigation schedules, the recurring work of the farm, paused from today
+// until the new farm's water sources are set up; and its inventory items with
+// their categories, units and reorder levels but no stock. History, such as
+// harvests, transactions and livestock, stays with the original.
+func (s *farmService) Clone(ctx context.Context, user *data.User, farmID string, in CloneInput) (*Cloned, error) {
+	source, err := s.Get(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Name == "" {
+		in.Name = source.Name + " (copy)"
+	}
+	if in.Location == "" {
+		in.Location = source.Location
+	}
+
+	cloned := &Cloned{Farm: &data.Farm{
+		Name:        in.Name,
+		Description: source.Description,
+		Location:    in.Location,
+		Size:        source.Size,
+		FarmType:    source.FarmType,
+		Currency:    source.Currency,
+		Status:      StatusActive,
+		UserID:      user.UserID,
+	}}
+	err = s.models.WithTransaction(ctx, func(tx data.Models) error {
+		if err := tx.Farm.Insert(ctx, cloned.Farm); err != nil {
+			return fmt.Errorf("creating farm: %w", err)
+		}
+		newFarmID := cloned.Farm.FarmID
+
+		fields, err := tx.Field.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return fmt.Errorf("getting fields: %w", err)
+		}
+		fieldIDs := make(map[string]string, len(fields))
+		for _, f := range fields {
+			field := &data.Field{FarmID: newFarmID, Name: f.Name, Area: f.Area, SoilType: f.SoilType, Notes: f.Notes}
+			if err := tx.Field.Insert(ctx, field); err != nil {
+				return fmt.Errorf("copying field: %w", err)
+			}
+			fieldIDs[f.FieldID] = field.FieldID
+		}
+		cloned.Fields = len(fields)
+
+		crops, err := tx.Crop.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return fmt.Errorf("getting crops: %w", err)
+		}
+		cropIDs := make(map[string]string)
+		for _, c := range crops {
+			if c.Status != "Growing" {
+				continue
+			}
+			crop := &data.Crop{FarmID: newFarmID, FieldID: mapped(fieldIDs, c.FieldID), Name: c.Name, Quantity: c.Quantity, Status: "Growing"}
+			if err := tx.Crop.Insert(ctx, crop); err != nil {
+				return fmt.Errorf("copying crop: %w", err)
+			}
+			cropIDs[c.CropID] = crop.CropID
+		}
+		cloned.Crops = len(cropIDs)
+
+		schedules, err := tx.IrrigationSchedule.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return fmt.Errorf("getting irrigation schedules: %w", err)
+		}
+		today := time.Now().Truncate(24 * time.Hour)
+		for _, sc := range schedules {
+			if sc.Status == "Completed" {
+				continue
+			}
+			schedule := &data.IrrigationSchedule{
+				FarmID:          newFarmID,
+				FieldID:         mapped(fieldIDs, sc.FieldID),
+				CropID:          mapped(cropIDs, sc.CropID),
+				Method:          sc.Method,
+				StartDate:       today,
+				IntervalDays:    sc.IntervalDays,
+				StartTime:       sc.StartTime,
+				DurationMinutes: sc.DurationMinutes,
+				Volume:          sc.Volume,
+				Status:          "Paused",
+				Notes:           sc.Notes,
+			}
+			if err := tx.IrrigationSchedule.Insert(ctx, schedule); err != nil {
+				return fmt.Errorf("copying irrigation schedule: %w", err)
+			}
+			cloned.Schedules++
+		}
+
+		items, err := tx.InventoryItem.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return fmt.Errorf("getting inventory items: %w", err)
+		}
+		for _, it := range items {
+			item := &data.InventoryItem{
+				FarmID:          newFarmID,
+				Name:            it.Name,
+				Category:        it.Category,
+				Unit:            it.Unit,
+				NitrogenPercent: it.NitrogenPercent,
+				ReorderLevel:    it.ReorderLevel,
+				Notes:           it.Notes,
+			}
+			if err := tx.InventoryItem.Insert(ctx, item); err != nil {
+				return fmt.Errorf("copying inventory item: %w", err)
+			}
+		}
+		cloned.InventoryItems = len(items)
+
+		return tx.AuditLog.Insert(ctx, &data.AuditLog{
+			FarmID:     newFarmID,
+			UserID:     user.UserID,
+			Action:     "farm.clone",
+			EntityType: "farm",
+			EntityID:   newFarmID,
+			Details:    fmt.Sprintf("cloned from %s (%s)", source.Name, farmID),
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cloning farm: %w", err)
+	}
+	return cloned, nil
+}
+
+// mapped returns the copy of the record id names, or nil if id is nil or the
+// record was not copied
+func mapped(copies map[string]string, id *string) *string {
+	if id == nil {
+		return nil
+	}
+	if copied, ok := copies[*id]; ok {
+		return &copied
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Package farm manages farms and decides who may access them. The other
+// domain services use Owned to limit a farm's records to its owner, and
+// Authorize where the permissions matrix also lets farm members, such as an
+// accountant, in.
+package farm
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Input holds the editable farm fields. On update, zero values are left unchanged.
+type Input struct {
+	Name        string
+	Description string
+	Location    string
+	Size        float64
+	FarmType    string
+	Currency    string // ISO 4217 code; DefaultCurrency when not given on create
+	Status      string
+	Version     int // Required on update: must be the current version
+}
+
+// DefaultCurrency is the currency a farm keeps its accounts in unless another
+// is chosen
+const DefaultCurrency = "UGX"
+
+// Service is the farm domain service
+type Service interface {
+	// Owned returns the farm if it exists and belongs to user
+	Owned(ctx context.Context, user *data.User, farmID string) (*data.Farm, error)
+	// Authorize returns the farm if it exists and user owns it or has a role
+	// on it that the permissions matrix allows action on module for
+	Authorize(ctx context.Context, user *data.User, farmID string, module Module, action Action) (*data.Farm, error)
+	// Access returns the farm if it exists and user owns it or has any role
+	// on it
+	Access(ctx context.Context, user *data.User, farmID string) (*data.Farm, error)
+	Create(ctx context.Context, user *data.User, in Input) (*data.Farm, error)
+	Get(ctx context.Context, user *data.User, farmID string) (*data.Farm, error)
+	List(ctx context.Context, user *data.User) ([]*data.Farm, error)
+	Update(ctx context.Context, user *data.User, farmID string, in Input) (*data.Farm, error)
+	Delete(ctx context.Context, user *data.User, farmID string) error
+	ListDeleted(ctx context.Context, user *data.User) ([]*data.Farm, error)
+	Restore(ctx context.Context, user *data.User, farmID string) (*data.Farm, error)
+
+	AddMember(ctx context.Context, user *data.User, farmID string, in MemberInput) (*data.FarmMember, error)
+	ListMembers(ctx context.Context, user *data.User, farmID string) ([]*data.FarmMember, error)
+	RemoveMember(ctx context.Context, user *data.User, farmMemberID string) error
+	// Memberships returns the farms other users have given user a role on
+	Memberships(ctx context.Context, user *data.User) ([]*data.FarmMember, error)
+	// Reachable returns the farms, other than archived ones, whose records
+	// of module user may take action on: those they own and, for a module of
+	// the permissions matrix, those their role on allows it. With no module
+	// only owned farms are returned.
+	Reachable(ctx context.Context, user *data.User, module Module, action Action) ([]*data.Farm, error)
+	// Transfer hands one of the user's farms, with its records, to another
+	// user, as on a sale or inheritance. Both are notified.
+	Transfer(ctx context.Context, user *data.User, farmID string, in TransferInput) (*data.Farm, error)
+	// Archive keeps one of the user's farms for its history: it leaves their
+	// farm list and its records become read-only. Unarchive undoes it.
+	Archive(ctx context.Context, user *data.User, farmID string) (*data.Farm, error)
+	Unarchive(ctx context.Context, user *data.User, farmID string) (*data.Farm, error)
+	// ListArchived returns the user's archived farms
+	ListArchived(ctx context.Context, user *data.User) ([]*data.Farm, error)
+	// Clone creates a farm for user with the structure of one of their farms,
+	// for setting up similar sites
+	Clone(ctx context.Context, user *data.User, farmID string, in CloneInput) (*Cloned, error)
+}
+
+// farmService implements Service on top of the farm and farm member
+// repositories
+type farmService struct {
+	farms   data.FarmInterface
+	members data.FarmMemberInterface
+	users   data.UserInterface
+	// models runs transfers, which change the farm, its members and its
+	// webhooks together
+	models data.Models
+}
+
+// New creates the farm service
+func New(models data.Models) Service {
+	return &farmService{farms: models.Farm, members: models.FarmMember, users: models.User, models: models}
+}
+
+// Owned returns the farm if it exists and belongs to user
+func (s *farmService) Owned(ctx context.Context, user *data.User, farmID string) (*data.Farm, error) {
+	farm, err := s.farm(ctx, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if farm == nil || farm.UserID != user.UserID {
+		return nil, service.Forbidden("farm not found or access denied")
+	}
+	if err := writable(ctx, farm); err != nil {
+		return nil, err
+	}
+	return farm, nil
+}
+
+// CheckRecord verifies that a record kept on farmID belongs to one of user's
+// farms. what names the record in the error, e.g. "crop".
+func CheckRecord(ctx context.Context, farms Service, user *data.User, farmID, what string) error {
+	_, err := farms.Owned(ctx, user, farmID)
+	if service.KindOf(err) == service.KindForbidden {
+		return service.Forbidden(fmt.Sprintf("access denied: %s does not belong to user's farm", what))
+	}
+	return err
+}
+
+// Create adds a farm owned by user, defaulting to a mixed, active farm
+func (s *farmService) Create(ctx context.Context, user *data.User, in Input) (*data.Farm, error) {
+	if in.FarmType == "" {
+		in.FarmType = "Mixed"
+	}
+	if in.Status == "" {
+		in.Status = StatusActive
+	}
+	if in.Currency == "" {
+		in.Currency = DefaultCurrency
+	}
+
+	farm := &data.Farm{
+		Name:        in.Name,
+		Description: in.Description,
+		Location:    in.Location,
+		Size:        in.Size,
+		FarmType:    in.FarmType,
+		Currency:    strings.ToUpper(in.Currency),
+		Status:      in.Status,
+		UserID:      user.UserID,
+	}
+	if err := s.farms.Insert(ctx, farm); err != nil {
+		return nil, fmt.Errorf("creating farm: %w", err)
+	}
+	return farm, nil
+}
+
+// Get returns one of the user's farms
+func (s *farmService) Get(ctx context.Context, user *data.User, farmID string) (*data.Farm, error) {
+	farm, err := s.farm(ctx, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if farm == nil {
+		return nil, service.NotFound("farm not found")
+	}
+	if farm.UserID != user.UserID {
+		return nil, service.Forbidden("access denied: farm does not belong to user")
+	}
+	return farm, nil
+}
+
+// List returns the user's farms, leaving out archived ones
+func (s *farmService) List(ctx context.Context, user *data.User) ([]*data.Farm, error) {
+	farms, err := s.farms.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting farms: %w", err)
+	}
+	return withoutArchived(onlyAllowed(ctx, farms)), nil
+}
+
+// Update changes the non-zero fields of in on one of the user's farms. An
+// archived farm must be unarchived first.
+func (s *farmService) Update(ctx context.Context, user *data.User, farmID string, in Input) (*data.Farm, error) {
+	farm, err := s.Get(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if err := writable(Writing(ctx), farm); err != nil {
+		return nil, err
+	}
+	if in.Status == StatusArchived {
+		return nil, service.Invalid("use archive to archive a farm")
+	}
+	if err := service.CheckVersion(ctx, "farm", in.Version, farm.Version, farm); err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		farm.Name = in.Name
+	}
+	if in.Description != "" {
+		farm.Description = in.Description
+	}
+	if in.Location != "" {
+		farm.Location = in.Location
+	}
+	if in.Size > 0 {
+		farm.Size = in.Size
+	}
+	if in.FarmType != "" {
+		farm.FarmType = in.FarmType
+	}
+	if in.Status != "" {
+		farm.Status = in.Status
+	}
+	if currency := strings.ToUpper(in.Currency); currency != "" && currency != farm.Currency {
+		// Amounts are kept in the farm currency, so changing it would misstate
+		// every transaction already recorded
+		recorded, err := s.models.Transaction.Exists(ctx, farmID)
+		if err != nil {
+			return nil, fmt.Errorf("checking transactions: %w", err)
+		}
+		if recorded {
+			return nil, service.Conflict("the farm currency cannot change once transactions are recorded")
+		}
+		farm.Currency = currency
+	}
+
+	err = s.farms.Update(ctx, farm)
+	forget(ctx, farmID)
+	if errors.Is(err, data.ErrStale) {
+		current, err := s.Get(ctx, user, farmID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Stale("farm", current)
+	} else if err != nil {
+		return nil, fmt.Errorf("updating farm: %w", err)
+	}
+	return farm, nil
+}
+
+// Delete soft deletes one of the user's farms
+func (s *farmService) Delete(ctx context.Context, user *data.User, farmID string) error {
+	farm, err := s.Get(ctx, user, farmID)
+	if err != nil {
+		return err
+	}
+	if err := s.farms.DeleteByID(ctx, int(farm.ID)); err != nil {
+		return fmt.Errorf("deleting farm: %w", err)
+	}
+	forget(ctx, farmID)
+	return nil
+}
+
+// ListDeleted returns the user's soft-deleted farms
+func (s *farmService) ListDeleted(ctx context.Context, user *data.User) ([]*data.Farm, error) {
+	farms, err := s.farms.GetDeletedByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted farms: %w", err)
+	}
+	return onlyAllowed(ctx, farms), nil
+}
+
+// Restore undeletes one of the user's soft-deleted farms
+func (s *farmService) Restore(ctx context.Context, user *data.User, farmID string) (*data.Farm, error) {
+	farm, err := s.farms.GetDeletedByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted farm: %w", err)
+	}
+	if farm == nil {
+		return nil, service.NotFound("deleted farm not found")
+	}
+	if farm.UserID != user.UserID {
+		return nil, service.Forbidden("access denied: farm does not belong to user")
+	}
+
+	if err := s.farms.RestoreByID(ctx, int(farm.ID)); err != nil {
+		return nil, fmt.Errorf("restoring farm: %w", err)
+	}
+	forget(ctx, farmID)
+	farm.DeletedAt = gorm.DeletedAt{}
+	return farm, nil
+}
@@ -0,0 +1,28 @@
+package farm
+
+import (
+	"context"
+	"farm4u/data"
+	"slices"
+)
+
+// restrictKey is the context key holding the one farm a request may reach
+type restrictKey struct{}
+
+// Restrict returns a copy of ctx in which every farm but farmID is treated as
+// not found, whoever owns it, and farm lists hold only farmID. It keeps a
+// request made with an API key issued for one farm on that farm.
+func Restrict(ctx context.Context, farmID string) context.Context {
+	return context.WithValue(ctx, restrictKey{}, farmID)
+}
+
+// restricted reports whether ctx may not reach farmID
+func restricted(ctx context.Context, farmID string) bool {
+	only, ok := ctx.Value(restrictKey{}).(string)
+	return ok && only != farmID
+}
+
+// onlyAllowed drops the farms ctx may not reach
+func onlyAllowed(ctx context.Context, farms []*data.Farm) []*data.Farm {
+	return slices.DeleteFunc(farms, func(f *data.Farm) bool { return restricted(ctx, f.FarmID) })
+}
@@ -0,0 +1,121 @@
+package farm
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"strings"
+)
+
+// Notification type sent to both parties of a farm transfer
+const NotifyTransferred = "farm_transferred"
+
+// TransferInput hands a farm to another user, by email
+type TransferInput struct {
+	Email  string
+	Reason string // Why the farm changes hands, e.g. Sale, Inheritance
+	// Version is required and must be the farm's current version
+	Version int
+}
+
+// Transfer implements Service. The farm's records move with it. Members the
+// previous owner gave roles lose them, and the farm's webhooks are paused, as
+// they post to systems the previous owner chose; the new owner can add their
+// own or resume them.
+func (s *farmService) Transfer(ctx context.Context, user *data.User, farmID string, in TransferInput) (*data.Farm, error) {
+	farm, err := s.Get(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.CheckVersion(ctx, "farm", in.Version, farm.Version, farm); err != nil {
+		return nil, err
+	}
+
+	target, err := s.users.GetByEmail(ctx, strings.TrimSpace(in.Email))
+	if err != nil {
+		return nil, fmt.Errorf("getting user: %w", err)
+	}
+	if target == nil || !target.Active {
+		return nil, service.NotFound("no active user with that email")
+	}
+	if target.UserID == user.UserID {
+		return nil, service.Invalid("the farm already belongs to you")
+	}
+
+	details := fmt.Sprintf("transferred from %s to %s", user.Email, target.Email)
+	if reason := strings.TrimSpace(in.Reason); reason != "" {
+		details += ": " + reason
+	}
+
+	err = s.models.WithTransaction(ctx, func(tx data.Models) error {
+		farm.UserID = target.UserID
+		if err := tx.Farm.Update(ctx, farm); err != nil {
+			return err
+		}
+
+		members, err := tx.FarmMember.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return fmt.Errorf("getting farm members: %w", err)
+		}
+		for _, member := range members {
+			if err := tx.FarmMember.DeleteByID(ctx, int(member.ID)); err != nil {
+				return fmt.Errorf("removing farm member: %w", err)
+			}
+		}
+
+		hooks, err := tx.Webhook.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return fmt.Errorf("getting webhooks: %w", err)
+		}
+		for _, hook := range hooks {
+			if !hook.Active {
+				continue
+			}
+			hook.Active = false
+			if err := tx.Webhook.Update(ctx, hook); err != nil {
+				return fmt.Errorf("pausing webhook: %w", err)
+			}
+		}
+
+		if err := tx.AuditLog.Insert(ctx, &data.AuditLog{
+			FarmID:     farmID,
+			UserID:     user.UserID,
+			Action:     "farm.transfer",
+			EntityType: "farm",
+			EntityID:   farmID,
+			Details:    details,
+		}); err != nil {
+			return fmt.Errorf("recording transfer: %w", err)
+		}
+
+		for _, n := range []struct{ userID, title, message string }{
+			{user.UserID, "Farm transferred", fmt.Sprintf("%s now belongs to %s.", farm.Name, target.Email)},
+			{target.UserID, "Farm transferred to you", fmt.Sprintf("%s has transferred %s to you.", user.Email, farm.Name)},
+		} {
+			if err := tx.Notification.Insert(ctx, &data.Notification{
+				UserID:    n.userID,
+				FarmID:    &farmID,
+				Type:      NotifyTransferred,
+				Title:     n.title,
+				Message:   n.message,
+				Reference: fmt.Sprintf("farm-transfer:%s:%d", farmID, farm.Version),
+			}); err != nil {
+				return fmt.Errorf("notifying transfer: %w", err)
+			}
+		}
+		return nil
+	})
+	forget(ctx, farmID)
+	if errors.Is(err, data.ErrStale) {
+		current, err := s.farms.GetByFarmID(ctx, farmID)
+		if err != nil {
+			return nil, fmt.Errorf("getting farm: %w", err)
+		}
+		return nil, service.Stale("farm", current)
+	} else if err != nil {
+		return nil, fmt.Errorf("transferring farm: %w", err)
+	}
+	return farm, nil
+}
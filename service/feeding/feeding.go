@@ -0,0 +1,280 @@
+// Package feeding records the feed a farm's livestock eat. Feed comes out of
+// the farm's inventory as it is given, so stock levels follow the feeding
+// log, and the cost of the batches drawn on adds up to the feed cost of each
+// herd and of each animal in it.
+package feeding
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// feedCategory is the inventory category feed items are kept under
+const feedCategory = "Feed"
+
+// reportDays is how far back the feed cost report looks when no start is
+// given
+const reportDays = 30
+
+// Input records a feeding
+type Input struct {
+	LivestockID     string
+	InventoryItemID string
+	Date            *time.Time // Defaults to now
+	Quantity        float64    // In the item's unit
+	Notes           string
+}
+
+// FeedUse is how much of one feed a herd ate in a period and what it cost
+type FeedUse struct {
+	InventoryItemID string  `json:"inventoryItemId"`
+	Name            string  `json:"name"`
+	Unit            string  `json:"unit"`
+	Quantity        float64 `json:"quantity"`
+	Cost            float64 `json:"cost"`
+}
+
+// HerdFeedCost is what a herd's feed cost in a period. CostPerAnimal spreads
+// each feeding's cost over the animals in the herd when it was fed.
+type HerdFeedCost struct {
+	LivestockID   string    `json:"livestockId"`
+	Type          string    `json:"type"`
+	HeadCount     int       `json:"headCount"` // Animals in the herd now
+	Feedings      int       `json:"feedings"`
+	Cost          float64   `json:"cost"`
+	CostPerAnimal float64   `json:"costPerAnimal"`
+	Feeds         []FeedUse `json:"feeds"`
+}
+
+// CostReport is a farm's feed cost per herd and animal over a period
+type CostReport struct {
+	FarmID string          `json:"farmId"`
+	From   time.Time       `json:"from"`
+	To     time.Time       `json:"to"` // Exclusive
+	Cost   float64         `json:"cost"`
+	Herds  []*HerdFeedCost `json:"herds"`
+}
+
+// Service is the feeding domain service
+type Service interface {
+	// Record logs a feeding and takes the feed out of stock, in a single
+	// transaction
+	Record(ctx context.Context, user *data.User, farmID string, in Input) (*data.FeedingRecord, []*data.InventoryMovement, error)
+	Get(ctx context.Context, user *data.User, feedingRecordID string) (*data.FeedingRecord, error)
+	List(ctx context.Context, user *data.User, farmID, livestockID string, from, to *time.Time) ([]*data.FeedingRecord, error)
+	// CostReport totals a farm's feed cost per herd and per animal for
+	// feedings in [from, to). A nil from goes back 30 days and a nil to runs
+	// to the end of today.
+	CostReport(ctx context.Context, user *data.User, farmID string, from, to *time.Time) (*CostReport, error)
+}
+
+// feedingService implements Service on top of the feeding record, livestock
+// and inventory repositories
+type feedingService struct {
+	models data.Models
+	locks  lock.Checker
+	farms  farm.Service
+}
+
+// New creates the feeding service
+func New(models data.Models, locks lock.Checker, farms farm.Service) Service {
+	return &feedingService{models: models, locks: locks, farms: farms}
+}
+
+// Record logs a feeding on one of the user's farms
+func (s *feedingService) Record(ctx context.Context, user *data.User, farmID string, in Input) (*data.FeedingRecord, []*data.InventoryMovement, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+	if in.Quantity <= 0 {
+		return nil, nil, service.Invalid("quantity must be greater than 0")
+	}
+
+	herd, err := s.models.Livestock.GetByLivestockID(ctx, in.LivestockID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting livestock: %w", err)
+	}
+	if herd == nil || herd.FarmID != farmID {
+		return nil, nil, service.Invalid("livestock not found on this farm")
+	}
+	item, err := s.models.InventoryItem.GetByInventoryItemID(ctx, in.InventoryItemID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting inventory item: %w", err)
+	}
+	if item == nil || item.FarmID != farmID {
+		return nil, nil, service.Invalid("inventory item not found on this farm")
+	}
+	if item.Category != feedCategory {
+		return nil, nil, service.Invalid(fmt.Sprintf("%s is not a feed; only items in the Feed category can be fed", item.Name))
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if err := s.locks.Check(ctx, farmID, date); err != nil {
+		return nil, nil, err
+	}
+
+	record := &data.FeedingRecord{
+		FarmID:          farmID,
+		LivestockID:     herd.LivestockID,
+		InventoryItemID: item.InventoryItemID,
+		Date:            date,
+		Quantity:        in.Quantity,
+		HeadCount:       herd.Count,
+		Notes:           in.Notes,
+	}
+	var movements []*data.InventoryMovement
+	err = s.models.WithTransaction(ctx, func(tx data.Models) error {
+		var err error
+		purpose := "Feeding " + strings.TrimSpace(herd.Type)
+		movements, err = tx.InventoryBatch.Consume(ctx, item.InventoryItemID, "", in.Quantity, date, purpose, in.Notes)
+		if errors.Is(err, data.ErrInsufficientStock) {
+			return service.Invalid(fmt.Sprintf("not enough unexpired %s in stock", item.Name))
+		}
+		if err != nil {
+			return fmt.Errorf("consuming feed: %w", err)
+		}
+
+		for _, movement := range movements {
+			batch, err := tx.InventoryBatch.GetByInventoryBatchID(ctx, movement.InventoryBatchID)
+			if err != nil {
+				return fmt.Errorf("getting inventory batch: %w", err)
+			}
+			if batch != nil {
+				record.Cost += movement.Quantity * batch.UnitCost
+			}
+		}
+		record.Cost = round(record.Cost)
+
+		if err := tx.FeedingRecord.Insert(ctx, record); err != nil {
+			return fmt.Errorf("recording feeding: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	record.Livestock, record.InventoryItem = herd, item
+	return record, movements, nil
+}
+
+// Get returns a feeding on one of the user's farms
+func (s *feedingService) Get(ctx context.Context, user *data.User, feedingRecordID string) (*data.FeedingRecord, error) {
+	record, err := s.models.FeedingRecord.GetByFeedingRecordID(ctx, feedingRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting feeding record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("feeding record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "feeding record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the feedings on one of the user's farms, optionally only those
+// of a herd and dated in [from, to)
+func (s *feedingService) List(ctx context.Context, user *data.User, farmID, livestockID string, from, to *time.Time) ([]*data.FeedingRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.models.FeedingRecord.GetByFarmID(ctx, farmID, livestockID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting feeding records: %w", err)
+	}
+	return records, nil
+}
+
+// CostReport implements Service
+func (s *feedingService) CostReport(ctx context.Context, user *data.User, farmID string, from, to *time.Time) (*CostReport, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	end := service.Day(time.Now()).AddDate(0, 0, 1)
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -reportDays)
+	if from != nil {
+		start = *from
+	}
+
+	records, err := s.models.FeedingRecord.GetByFarmID(ctx, farmID, "", &start, &end)
+	if err != nil {
+		return nil, fmt.Errorf("getting feeding records: %w", err)
+	}
+	herds, err := s.models.Livestock.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting livestock: %w", err)
+	}
+
+	report := &CostReport{FarmID: farmID, From: start, To: end, Herds: []*HerdFeedCost{}}
+	byHerd := map[string]*HerdFeedCost{}
+	for _, herd := range herds {
+		byHerd[herd.LivestockID] = &HerdFeedCost{LivestockID: herd.LivestockID, Type: herd.Type, HeadCount: herd.Count}
+	}
+	for _, record := range records {
+		cost, ok := byHerd[record.LivestockID]
+		if !ok {
+			// A herd deleted since it was fed
+			cost = &HerdFeedCost{LivestockID: record.LivestockID}
+			byHerd[record.LivestockID] = cost
+		}
+		cost.Feedings++
+		cost.Cost += record.Cost
+		if record.HeadCount > 0 {
+			cost.CostPerAnimal += record.Cost / float64(record.HeadCount)
+		}
+		cost.use(record)
+		report.Cost += record.Cost
+	}
+
+	for _, cost := range byHerd {
+		if cost.Feedings == 0 {
+			continue
+		}
+		cost.Cost = round(cost.Cost)
+		cost.CostPerAnimal = round(cost.CostPerAnimal)
+		report.Herds = append(report.Herds, cost)
+	}
+	// Costliest herds first
+	slices.SortFunc(report.Herds, func(a, b *HerdFeedCost) int {
+		return cmp.Or(cmp.Compare(b.Cost, a.Cost), strings.Compare(a.LivestockID, b.LivestockID))
+	})
+	report.Cost = round(report.Cost)
+	return report, nil
+}
+
+// use adds a feeding to the herd's totals for its feed
+func (c *HerdFeedCost) use(record *data.FeedingRecord) {
+	i := slices.IndexFunc(c.Feeds, func(f FeedUse) bool { return f.InventoryItemID == record.InventoryItemID })
+	if i < 0 {
+		feed := FeedUse{InventoryItemID: record.InventoryItemID}
+		if record.InventoryItem != nil {
+			feed.Name, feed.Unit = record.InventoryItem.Name, record.InventoryItem.Unit
+		}
+		c.Feeds = append(c.Feeds, feed)
+		i = len(c.Feeds) - 1
+	}
+	c.Feeds[i].Quantity += record.Quantity
+	c.Feeds[i].Cost = round(c.Feeds[i].Cost + record.Cost)
+}
+
+// round rounds an amount to cents
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,162 @@
+// Package field manages the fields (plots) a farm is divided into and their
+// crop rotation history
+package field
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+)
+
+// Input holds the editable field fields. On update, zero values are left unchanged.
+type Input struct {
+	Name     string
+	Area     float64
+	SoilType string
+	Notes    string
+	Version  int // Required on update: must be the current version
+}
+
+// Service is the field domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Field, error)
+	Get(ctx context.Context, user *data.User, fieldID string) (*data.Field, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.Field, error)
+	Update(ctx context.Context, user *data.User, fieldID string, in Input) (*data.Field, error)
+	Delete(ctx context.Context, user *data.User, fieldID string) error
+	// Rotation returns the crops planted on a field, oldest planting first
+	Rotation(ctx context.Context, user *data.User, fieldID string) (*data.Field, []*data.Crop, error)
+}
+
+// fieldService implements Service on top of the field repository
+type fieldService struct {
+	fields data.FieldInterface
+	crops  data.CropInterface
+	farms  farm.Service
+}
+
+// New creates the field service
+func New(fields data.FieldInterface, crops data.CropInterface, farms farm.Service) Service {
+	return &fieldService{fields: fields, crops: crops, farms: farms}
+}
+
+// Create adds a field to one of the user's farms
+func (s *fieldService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Field, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	field := &data.Field{
+		FarmID:   farmID,
+		Name:     in.Name,
+		Area:     in.Area,
+		SoilType: in.SoilType,
+		Notes:    in.Notes,
+	}
+	if err := s.fields.Insert(ctx, field); err != nil {
+		return nil, fmt.Errorf("creating field: %w", err)
+	}
+	return field, nil
+}
+
+// Get returns a field on one of the user's farms
+func (s *fieldService) Get(ctx context.Context, user *data.User, fieldID string) (*data.Field, error) {
+	field, err := s.fields.GetByFieldID(ctx, fieldID)
+	if err != nil {
+		return nil, fmt.Errorf("getting field: %w", err)
+	}
+	if field == nil {
+		return nil, service.NotFound("field not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, field.FarmID, "field"); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// List returns the fields of one of the user's farms
+func (s *fieldService) List(ctx context.Context, user *data.User, farmID string) ([]*data.Field, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	fields, err := s.fields.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting fields: %w", err)
+	}
+	return fields, nil
+}
+
+// Update changes the non-zero fields of in on a field
+func (s *fieldService) Update(ctx context.Context, user *data.User, fieldID string, in Input) (*data.Field, error) {
+	field, err := s.Get(ctx, user, fieldID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.CheckVersion(ctx, "field", in.Version, field.Version, field); err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		field.Name = in.Name
+	}
+	if in.Area > 0 {
+		field.Area = in.Area
+	}
+	if in.SoilType != "" {
+		field.SoilType = in.SoilType
+	}
+	if in.Notes != "" {
+		field.Notes = in.Notes
+	}
+
+	if err := s.fields.Update(ctx, field); errors.Is(err, data.ErrStale) {
+		current, err := s.Get(ctx, user, fieldID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Stale("field", current)
+	} else if err != nil {
+		return nil, fmt.Errorf("updating field: %w", err)
+	}
+	return field, nil
+}
+
+// Delete soft deletes a field. A field with a crop still growing on it cannot
+// be deleted; past crops keep their reference so the history is not lost.
+func (s *fieldService) Delete(ctx context.Context, user *data.User, fieldID string) error {
+	field, err := s.Get(ctx, user, fieldID)
+	if err != nil {
+		return err
+	}
+
+	crops, err := s.crops.GetByFieldID(ctx, field.FieldID)
+	if err != nil {
+		return fmt.Errorf("getting field crops: %w", err)
+	}
+	for _, crop := range crops {
+		if crop.Status == "Growing" {
+			return service.Conflict("field has a growing crop; harvest or move it first")
+		}
+	}
+
+	if err := s.fields.DeleteByID(ctx, int(field.ID)); err != nil {
+		return fmt.Errorf("deleting field: %w", err)
+	}
+	return nil
+}
+
+// Rotation returns a field and the crops planted on it, oldest planting first
+func (s *fieldService) Rotation(ctx context.Context, user *data.User, fieldID string) (*data.Field, []*data.Crop, error) {
+	field, err := s.Get(ctx, user, fieldID)
+	if err != nil {
+		return nil, nil, err
+	}
+	crops, err := s.crops.GetByFieldID(ctx, field.FieldID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting field crops: %w", err)
+	}
+	return field, crops, nil
+}
@@ -0,0 +1,262 @@
+package finance
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/season"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BudgetLineInput holds the editable budget line fields. On update, zero
+// values are left unchanged and the season cannot change.
+type BudgetLineInput struct {
+	SeasonID      string
+	Type          string
+	Category      string
+	PlannedAmount float64
+	Notes         string
+}
+
+// VarianceLine compares the budget for one type and category of transaction
+// with what was recorded. Used is the percentage of the planned amount
+// recorded so far; it is left out for categories with nothing planned.
+type VarianceLine struct {
+	Type     string   `json:"type"`
+	Category string   `json:"category"`
+	Planned  float64  `json:"planned"`
+	Actual   float64  `json:"actual"`
+	Variance float64  `json:"variance"` // Actual less planned
+	Used     *float64 `json:"used,omitempty"`
+	// OverBudget marks spending above plan
+	OverBudget bool `json:"overBudget"`
+	// OverPace marks spending that has used more of its budget than the
+	// share of the season gone by, so is heading over plan
+	OverPace bool `json:"overPace"`
+	// Unbudgeted marks transactions in a category with no budget line
+	Unbudgeted bool `json:"unbudgeted"`
+}
+
+// BudgetVariance compares a season's budget with the transactions counted
+// towards it. Elapsed is the percentage of the season's days gone by.
+type BudgetVariance struct {
+	Season          *data.Season   `json:"season"`
+	Elapsed         float64        `json:"elapsed"`
+	Lines           []VarianceLine `json:"lines"`
+	PlannedIncome   float64        `json:"plannedIncome"`
+	ActualIncome    float64        `json:"actualIncome"`
+	PlannedExpenses float64        `json:"plannedExpenses"`
+	ActualExpenses  float64        `json:"actualExpenses"`
+}
+
+// CreateBudgetLine plans an amount for a category over an open season of
+// one of the user's farms. Each type and category is budgeted once a season.
+func (s *financeService) CreateBudgetLine(ctx context.Context, user *data.User, farmID string, in BudgetLineInput) (*data.BudgetLine, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if _, _, err := season.Link(ctx, s.seasons, farmID, &in.SeasonID); err != nil {
+		return nil, err
+	}
+	if in.Type == "" {
+		in.Type = "Expense"
+	}
+
+	line := &data.BudgetLine{
+		FarmID:        farmID,
+		SeasonID:      in.SeasonID,
+		Type:          in.Type,
+		Category:      in.Category,
+		PlannedAmount: in.PlannedAmount,
+		Notes:         in.Notes,
+	}
+	if err := s.checkBudgeted(ctx, line); err != nil {
+		return nil, err
+	}
+	if err := s.budgets.Insert(ctx, line); err != nil {
+		return nil, fmt.Errorf("creating budget line: %w", err)
+	}
+	return line, nil
+}
+
+// ListBudgetLines returns the budget of one of a farm's seasons
+func (s *financeService) ListBudgetLines(ctx context.Context, user *data.User, farmID, seasonID string) ([]*data.BudgetLine, error) {
+	if _, err := s.budgetSeason(ctx, user, farmID, seasonID); err != nil {
+		return nil, err
+	}
+	lines, err := s.budgets.GetBySeasonID(ctx, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("getting budget lines: %w", err)
+	}
+	return lines, nil
+}
+
+// UpdateBudgetLine changes the non-zero fields of in on a budget line of an
+// open season
+func (s *financeService) UpdateBudgetLine(ctx context.Context, user *data.User, budgetLineID string, in BudgetLineInput) (*data.BudgetLine, error) {
+	line, err := s.editableBudgetLine(ctx, user, budgetLineID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Type != "" {
+		line.Type = in.Type
+	}
+	if in.Category != "" {
+		line.Category = in.Category
+	}
+	if in.PlannedAmount > 0 {
+		line.PlannedAmount = in.PlannedAmount
+	}
+	if in.Notes != "" {
+		line.Notes = in.Notes
+	}
+	if in.Type != "" || in.Category != "" {
+		if err := s.checkBudgeted(ctx, line); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.budgets.Update(ctx, line); err != nil {
+		return nil, fmt.Errorf("updating budget line: %w", err)
+	}
+	return line, nil
+}
+
+// DeleteBudgetLine soft deletes a budget line of an open season
+func (s *financeService) DeleteBudgetLine(ctx context.Context, user *data.User, budgetLineID string) error {
+	line, err := s.editableBudgetLine(ctx, user, budgetLineID)
+	if err != nil {
+		return err
+	}
+	if err := s.budgets.DeleteByID(ctx, int(line.ID)); err != nil {
+		return fmt.Errorf("deleting budget line: %w", err)
+	}
+	return nil
+}
+
+// BudgetVariance compares the budget of one of a farm's seasons with the
+// transactions counted towards it, category by category
+func (s *financeService) BudgetVariance(ctx context.Context, user *data.User, farmID, seasonID string) (*BudgetVariance, error) {
+	ssn, err := s.budgetSeason(ctx, user, farmID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.budgets.GetBySeasonID(ctx, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("getting budget lines: %w", err)
+	}
+	totals, err := s.seasons.TotalsByCategory(ctx, ssn)
+	if err != nil {
+		return nil, fmt.Errorf("totalling season transactions: %w", err)
+	}
+
+	report := &BudgetVariance{Season: ssn, Elapsed: elapsed(ssn, time.Now())}
+	index := make(map[string]int)
+	key := func(kind, category string) string { return kind + "\x00" + strings.ToLower(category) }
+	for _, line := range lines {
+		k := key(line.Type, line.Category)
+		if i, ok := index[k]; ok {
+			report.Lines[i].Planned += line.PlannedAmount
+			continue
+		}
+		index[k] = len(report.Lines)
+		report.Lines = append(report.Lines, VarianceLine{Type: line.Type, Category: line.Category, Planned: line.PlannedAmount})
+	}
+	for _, total := range totals {
+		k := key(total.Type, total.Category)
+		i, ok := index[k]
+		if !ok {
+			i = len(report.Lines)
+			index[k] = i
+			report.Lines = append(report.Lines, VarianceLine{Type: total.Type, Category: total.Category, Unbudgeted: true})
+		}
+		report.Lines[i].Actual += total.Total
+	}
+
+	for i := range report.Lines {
+		line := &report.Lines[i]
+		line.Planned, line.Actual = round2(line.Planned), round2(line.Actual)
+		line.Variance = round2(line.Actual - line.Planned)
+		if line.Planned > 0 {
+			used := round2(line.Actual / line.Planned * 100)
+			line.Used = &used
+		}
+		if line.Type == "Income" {
+			report.PlannedIncome += line.Planned
+			report.ActualIncome += line.Actual
+			continue
+		}
+		report.PlannedExpenses += line.Planned
+		report.ActualExpenses += line.Actual
+		line.OverBudget = line.Actual > line.Planned
+		line.OverPace = line.Used != nil && !line.OverBudget && *line.Used > report.Elapsed
+	}
+	report.PlannedIncome, report.ActualIncome = round2(report.PlannedIncome), round2(report.ActualIncome)
+	report.PlannedExpenses, report.ActualExpenses = round2(report.PlannedExpenses), round2(report.ActualExpenses)
+	return report, nil
+}
+
+// budgetSeason returns a season of farmID, checking that the user may read
+// the farm's finances
+func (s *financeService) budgetSeason(ctx context.Context, user *data.User, farmID, seasonID string) (*data.Season, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Read); err != nil {
+		return nil, err
+	}
+	ssn, err := s.seasons.GetBySeasonID(ctx, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("getting season: %w", err)
+	}
+	if ssn == nil || ssn.FarmID != farmID {
+		return nil, service.NotFound("season not found on this farm")
+	}
+	return ssn, nil
+}
+
+// editableBudgetLine loads a budget line the user may change: one of a farm
+// they own, for a season that is still open
+func (s *financeService) editableBudgetLine(ctx context.Context, user *data.User, budgetLineID string) (*data.BudgetLine, error) {
+	line, err := s.budgets.GetByBudgetLineID(ctx, budgetLineID)
+	if err != nil {
+		return nil, fmt.Errorf("getting budget line: %w", err)
+	}
+	if line == nil {
+		return nil, service.NotFound("budget line not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, line.FarmID, "budget line"); err != nil {
+		return nil, err
+	}
+	if _, _, err := season.Link(ctx, s.seasons, line.FarmID, &line.SeasonID); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// checkBudgeted verifies no other line of the season budgets the same type
+// and category
+func (s *financeService) checkBudgeted(ctx context.Context, line *data.BudgetLine) error {
+	lines, err := s.budgets.GetBySeasonID(ctx, line.SeasonID)
+	if err != nil {
+		return fmt.Errorf("getting budget lines: %w", err)
+	}
+	for _, other := range lines {
+		if other.BudgetLineID != line.BudgetLineID && other.Type == line.Type && strings.EqualFold(other.Category, line.Category) {
+			return service.Conflict(fmt.Sprintf("%s is already budgeted for this season", other.Category))
+		}
+	}
+	return nil
+}
+
+// elapsed returns the percentage of the season's days gone by at now
+func elapsed(ssn *data.Season, now time.Time) float64 {
+	switch {
+	case !now.After(ssn.StartDate):
+		return 0
+	case !now.Before(ssn.End()):
+		return 100
+	}
+	return round2(float64(now.Sub(ssn.StartDate)) / float64(ssn.End().Sub(ssn.StartDate)) * 100)
+}
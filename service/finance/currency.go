@@ -0,0 +1,148 @@
+package finance
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExchangeRateInput holds the editable exchange rate fields. On update, zero
+// values are left unchanged; on create a nil Date means today.
+type ExchangeRateInput struct {
+	Currency string
+	Rate     float64
+	Date     *time.Time
+	Notes    string
+}
+
+// CreateExchangeRate stores what a unit of another currency is worth in the
+// farm's currency from a date. Transactions already converted keep the rate
+// they were converted at.
+func (s *financeService) CreateExchangeRate(ctx context.Context, user *data.User, farmID string, in ExchangeRateInput) (*data.ExchangeRate, error) {
+	f, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &data.ExchangeRate{
+		FarmID:   farmID,
+		Currency: strings.ToUpper(in.Currency),
+		Rate:     in.Rate,
+		Date:     time.Now(),
+		Notes:    in.Notes,
+	}
+	if in.Date != nil {
+		rate.Date = *in.Date
+	}
+	if rate.Currency == f.Currency {
+		return nil, service.Invalid(fmt.Sprintf("%s is the farm currency", f.Currency))
+	}
+	if err := s.exchangeRates.Insert(ctx, rate); err != nil {
+		return nil, fmt.Errorf("creating exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// ListExchangeRates returns a farm's exchange rates, newest first within each
+// currency, optionally for one currency
+func (s *financeService) ListExchangeRates(ctx context.Context, user *data.User, farmID, currency string) ([]*data.ExchangeRate, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Read); err != nil {
+		return nil, err
+	}
+	rates, err := s.exchangeRates.GetByFarmID(ctx, farmID, strings.ToUpper(currency))
+	if err != nil {
+		return nil, fmt.Errorf("getting exchange rates: %w", err)
+	}
+	return rates, nil
+}
+
+// UpdateExchangeRate changes the non-zero fields of in on an exchange rate
+func (s *financeService) UpdateExchangeRate(ctx context.Context, user *data.User, exchangeRateID string, in ExchangeRateInput) (*data.ExchangeRate, error) {
+	rate, f, err := s.exchangeRate(ctx, user, exchangeRateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Currency != "" {
+		rate.Currency = strings.ToUpper(in.Currency)
+		if rate.Currency == f.Currency {
+			return nil, service.Invalid(fmt.Sprintf("%s is the farm currency", f.Currency))
+		}
+	}
+	if in.Rate > 0 {
+		rate.Rate = in.Rate
+	}
+	if in.Date != nil {
+		rate.Date = *in.Date
+	}
+	if in.Notes != "" {
+		rate.Notes = in.Notes
+	}
+
+	if err := s.exchangeRates.Update(ctx, rate); err != nil {
+		return nil, fmt.Errorf("updating exchange rate: %w", err)
+	}
+	return rate, nil
+}
+
+// DeleteExchangeRate soft deletes an exchange rate
+func (s *financeService) DeleteExchangeRate(ctx context.Context, user *data.User, exchangeRateID string) error {
+	rate, _, err := s.exchangeRate(ctx, user, exchangeRateID)
+	if err != nil {
+		return err
+	}
+	if err := s.exchangeRates.DeleteByID(ctx, int(rate.ID)); err != nil {
+		return fmt.Errorf("deleting exchange rate: %w", err)
+	}
+	return nil
+}
+
+// exchangeRate loads an exchange rate and its farm, checking the user may
+// change the farm's finances
+func (s *financeService) exchangeRate(ctx context.Context, user *data.User, exchangeRateID string) (*data.ExchangeRate, *data.Farm, error) {
+	rate, err := s.exchangeRates.GetByExchangeRateID(ctx, exchangeRateID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting exchange rate: %w", err)
+	}
+	if rate == nil {
+		return nil, nil, service.NotFound("exchange rate not found")
+	}
+	f, err := s.farms.Authorize(ctx, user, rate.FarmID, farm.ModuleFinance, farm.Write)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rate, f, nil
+}
+
+// convert sets a transaction's amount in the farm currency from amount in
+// the transaction's currency. The rate is the one given, or else the farm's
+// latest stored rate on or before the transaction date. Entries in the farm
+// currency are stored as they are.
+func (s *financeService) convert(ctx context.Context, transaction *data.Transaction, f *data.Farm, amount float64, rate *float64) error {
+	transaction.Currency = strings.ToUpper(transaction.Currency)
+	if transaction.Currency == "" || transaction.Currency == f.Currency {
+		transaction.Currency, transaction.OriginalAmount, transaction.ExchangeRate = "", 0, 0
+		transaction.Amount = amount
+		return nil
+	}
+
+	if rate == nil {
+		stored, err := s.exchangeRates.GetEffective(ctx, transaction.FarmID, transaction.Currency, transaction.Date)
+		if err != nil {
+			return fmt.Errorf("getting exchange rate: %w", err)
+		}
+		if stored == nil {
+			return service.Invalid(fmt.Sprintf("no %s exchange rate on or before %s; add one or give the rate",
+				transaction.Currency, transaction.Date.Format("2006-01-02")))
+		}
+		rate = &stored.Rate
+	}
+	transaction.OriginalAmount = amount
+	transaction.ExchangeRate = *rate
+	transaction.Amount = round2(amount * *rate)
+	return nil
+}
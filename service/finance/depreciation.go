@@ -0,0 +1,166 @@
+package finance
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service/farm"
+	"fmt"
+	"time"
+)
+
+// DepreciationYear is one calendar year of an asset's depreciation
+type DepreciationYear struct {
+	Year    int     `json:"year"`
+	Opening float64 `json:"opening"` // Book value at the start of the year
+	Charge  float64 `json:"charge"`
+	Closing float64 `json:"closing"` // Book value at the end of the year
+}
+
+// AssetDepreciation is an asset's depreciation for the report year and the
+// whole schedule it is taken from. Rate is the annual percentage applied:
+// of the depreciable amount for straight-line, of the opening value for
+// declining balance.
+type AssetDepreciation struct {
+	AssetID         string             `json:"assetId"`
+	Name            string             `json:"name"`
+	Category        string             `json:"category"`
+	Status          string             `json:"status"`
+	Method          string             `json:"method"`
+	AcquisitionDate time.Time          `json:"acquisitionDate"`
+	Cost            float64            `json:"cost"`
+	SalvageValue    float64            `json:"salvageValue"`
+	UsefulLifeYears int                `json:"usefulLifeYears"`
+	Rate            float64            `json:"rate"`
+	Opening         float64            `json:"opening"`
+	Charge          float64            `json:"charge"`
+	Closing         float64            `json:"closing"`
+	Accumulated     float64            `json:"accumulated"` // Depreciation charged up to the end of the year
+	Schedule        []DepreciationYear `json:"schedule"`
+}
+
+// DepreciationReport is the depreciation of a farm's equipment and
+// structures for a calendar year, for the end-of-year accounts
+type DepreciationReport struct {
+	FarmID      string              `json:"farmId"`
+	Year        int                 `json:"year"`
+	Assets      []AssetDepreciation `json:"assets"`
+	Cost        float64             `json:"cost"`
+	Charge      float64             `json:"charge"`
+	Accumulated float64             `json:"accumulated"`
+	BookValue   float64             `json:"bookValue"` // Cost less accumulated depreciation at the end of the year
+}
+
+// Depreciation reports the depreciation of a farm's equipment and structures
+// for year, the current year when 0. Assets without depreciation terms, not
+// yet acquired or disposed of before the year are left out.
+func (s *financeService) Depreciation(ctx context.Context, user *data.User, farmID string, year int) (*DepreciationReport, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Read); err != nil {
+		return nil, err
+	}
+	if year == 0 {
+		year = time.Now().Year()
+	}
+	assets, err := s.assets.GetByFarmID(ctx, farmID, "")
+	if err != nil {
+		return nil, fmt.Errorf("getting assets: %w", err)
+	}
+
+	report := &DepreciationReport{FarmID: farmID, Year: year, Assets: []AssetDepreciation{}}
+	for _, asset := range assets {
+		if asset.DepreciationMethod == "" || asset.AcquisitionDate.Year() > year {
+			continue
+		}
+		if asset.DisposedAt != nil && asset.DisposedAt.Year() < year {
+			continue
+		}
+
+		row := AssetDepreciation{
+			AssetID:         asset.AssetID,
+			Name:            asset.Name,
+			Category:        asset.Category,
+			Status:          asset.Status,
+			Method:          asset.DepreciationMethod,
+			AcquisitionDate: asset.AcquisitionDate,
+			Cost:            asset.AcquisitionCost,
+			SalvageValue:    asset.SalvageValue,
+			UsefulLifeYears: asset.UsefulLifeYears,
+			Rate:            round2(depreciationRate(asset) * 100),
+			Schedule:        depreciationSchedule(asset),
+		}
+		// Past the end of its schedule an asset stays at its last closing value
+		for _, y := range row.Schedule {
+			switch {
+			case y.Year == year:
+				row.Opening, row.Charge, row.Closing = y.Opening, y.Charge, y.Closing
+			case y.Year < year:
+				row.Opening, row.Closing = y.Closing, y.Closing
+			}
+		}
+		row.Accumulated = round2(row.Cost - row.Closing)
+
+		report.Assets = append(report.Assets, row)
+		report.Cost += row.Cost
+		report.Charge += row.Charge
+		report.Accumulated += row.Accumulated
+	}
+	report.Cost, report.Charge, report.Accumulated = round2(report.Cost), round2(report.Charge), round2(report.Accumulated)
+	report.BookValue = round2(report.Cost - report.Accumulated)
+	return report, nil
+}
+
+// depreciationRate returns the annual rate, as a fraction, an asset is
+// depreciated at
+func depreciationRate(asset *data.Asset) float64 {
+	if asset.DepreciationMethod == data.DepreciationDecliningBalance {
+		if asset.DepreciationRate > 0 {
+			return asset.DepreciationRate / 100
+		}
+		return 2 / float64(asset.UsefulLifeYears)
+	}
+	return 1 / float64(asset.UsefulLifeYears)
+}
+
+// depreciationSchedule works out an asset's depreciation year by year from
+// its acquisition cost, counting the month it was acquired in and stopping
+// before the month it was disposed of. Straight-line charges the cost less
+// salvage value evenly over the useful life; declining balance charges the
+// rate on the value left each year. Either way the last year of the useful
+// life brings the value down to the salvage value.
+func depreciationSchedule(asset *data.Asset) []DepreciationYear {
+	rate := depreciationRate(asset)
+	depreciable := asset.AcquisitionCost - asset.SalvageValue
+	value := asset.AcquisitionCost
+	left := asset.UsefulLifeYears * 12
+
+	var schedule []DepreciationYear
+	for year := asset.AcquisitionDate.Year(); left > 0; year++ {
+		from, to := 0, 12
+		if year == asset.AcquisitionDate.Year() {
+			from = int(asset.AcquisitionDate.Month()) - 1
+		}
+		disposed := asset.DisposedAt != nil && asset.DisposedAt.Year() == year
+		if disposed {
+			to = int(asset.DisposedAt.Month()) - 1
+		}
+		months := max(min(to-from, left), 0)
+		left -= months
+
+		var charge float64
+		switch {
+		case left == 0 && !disposed:
+			charge = value - asset.SalvageValue
+		case asset.DepreciationMethod == data.DepreciationDecliningBalance:
+			charge = value * rate * float64(months) / 12
+		default:
+			charge = depreciable * rate * float64(months) / 12
+		}
+		charge = round2(max(min(charge, value-asset.SalvageValue), 0))
+
+		schedule = append(schedule, DepreciationYear{Year: year, Opening: round2(value), Charge: charge, Closing: round2(value - charge)})
+		value -= charge
+		if disposed {
+			break
+		}
+	}
+	return schedule
+}
@@ -0,0 +1,80 @@
+package finance
+
+import (
+	"farm4u/data"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDepreciationSchedule(t *testing.T) {
+	date := func(year int, month time.Month) time.Time {
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+	disposed := date(2021, time.March)
+
+	tests := []struct {
+		name  string
+		asset data.Asset
+		want  []DepreciationYear
+	}{
+		{
+			name: "straight-line to salvage value",
+			asset: data.Asset{AcquisitionDate: date(2020, time.January), AcquisitionCost: 1000, SalvageValue: 100,
+				DepreciationMethod: data.DepreciationStraightLine, UsefulLifeYears: 3},
+			want: []DepreciationYear{
+				{Year: 2020, Opening: 1000, Charge: 300, Closing: 700},
+				{Year: 2021, Opening: 700, Charge: 300, Closing: 400},
+				{Year: 2022, Opening: 400, Charge: 300, Closing: 100},
+			},
+		},
+		{
+			name: "straight-line acquired mid-year",
+			asset: data.Asset{AcquisitionDate: date(2020, time.July), AcquisitionCost: 1200,
+				DepreciationMethod: data.DepreciationStraightLine, UsefulLifeYears: 2},
+			want: []DepreciationYear{
+				{Year: 2020, Opening: 1200, Charge: 300, Closing: 900},
+				{Year: 2021, Opening: 900, Charge: 600, Closing: 300},
+				{Year: 2022, Opening: 300, Charge: 300, Closing: 0},
+			},
+		},
+		{
+			name: "declining balance at double the straight-line rate",
+			asset: data.Asset{AcquisitionDate: date(2020, time.January), AcquisitionCost: 1000,
+				DepreciationMethod: data.DepreciationDecliningBalance, UsefulLifeYears: 4},
+			want: []DepreciationYear{
+				{Year: 2020, Opening: 1000, Charge: 500, Closing: 500},
+				{Year: 2021, Opening: 500, Charge: 250, Closing: 250},
+				{Year: 2022, Opening: 250, Charge: 125, Closing: 125},
+				{Year: 2023, Opening: 125, Charge: 125, Closing: 0},
+			},
+		},
+		{
+			name: "declining balance at a set rate",
+			asset: data.Asset{AcquisitionDate: date(2020, time.January), AcquisitionCost: 1000, SalvageValue: 100,
+				DepreciationMethod: data.DepreciationDecliningBalance, UsefulLifeYears: 2, DepreciationRate: 20},
+			want: []DepreciationYear{
+				{Year: 2020, Opening: 1000, Charge: 200, Closing: 800},
+				{Year: 2021, Opening: 800, Charge: 700, Closing: 100},
+			},
+		},
+		{
+			name: "stops at disposal",
+			asset: data.Asset{AcquisitionDate: date(2020, time.January), AcquisitionCost: 1200, DisposedAt: &disposed,
+				DepreciationMethod: data.DepreciationStraightLine, UsefulLifeYears: 4},
+			want: []DepreciationYear{
+				{Year: 2020, Opening: 1200, Charge: 300, Closing: 900},
+				{Year: 2021, Opening: 900, Charge: 50, Closing: 850},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := depreciationSchedule(&tt.asset)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("schedule = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
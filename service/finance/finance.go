@@ -0,0 +1,343 @@
+// Package finance manages a farm's income and expense ledger, the taxes
+// configured for its jurisdiction, the budgets planned for its seasons, and
+// the reports built from them, including the depreciation of its equipment
+// and structures
+package finance
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"farm4u/service/season"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TransactionInput holds the editable transaction fields. On update, zero
+// values are left unchanged; on create a nil Date means today.
+type TransactionInput struct {
+	Type         string
+	Category     string
+	Amount       float64
+	Date         *time.Time
+	Description  string
+	Notes        string
+	SeasonID     *string  // An empty ID unlinks the transaction from its season
+	Currency     string   // Currency of Amount when not the farm's
+	ExchangeRate *float64 // Rate to convert Amount at instead of the farm's stored rate
+}
+
+// ProfitabilityReport summarises a farm's income and costs for a period.
+// Overheads are expenses in data.OverheadCategories, including utility bills.
+// Losses is the value of dead and culled animals, which is counted in the
+// direct costs.
+type ProfitabilityReport struct {
+	FarmID      string               `json:"farmId"`
+	Currency    string               `json:"currency"` // Farm currency all amounts are in
+	From        *time.Time           `json:"from,omitempty"`
+	To          *time.Time           `json:"to,omitempty"`
+	Income      float64              `json:"income"`
+	DirectCosts float64              `json:"directCosts"`
+	Overheads   float64              `json:"overheads"`
+	Losses      float64              `json:"losses"`
+	GrossProfit float64              `json:"grossProfit"` // Income less direct costs
+	NetProfit   float64              `json:"netProfit"`   // Gross profit less overheads
+	Breakdown   []data.CategoryTotal `json:"breakdown"`
+}
+
+// Service is the finance domain service
+type Service interface {
+	CreateTransaction(ctx context.Context, user *data.User, farmID string, in TransactionInput) (*data.Transaction, error)
+	GetTransaction(ctx context.Context, user *data.User, transactionID string) (*data.Transaction, error)
+	ListTransactions(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.Transaction, error)
+	// ListAllTransactions returns the transactions of every farm whose
+	// finances the user may see, each with its farm, for one consolidated
+	// list. Amounts are in each farm's own currency.
+	ListAllTransactions(ctx context.Context, user *data.User, from, to *time.Time) ([]*data.Transaction, error)
+	UpdateTransaction(ctx context.Context, user *data.User, transactionID string, in TransactionInput) (*data.Transaction, error)
+	DeleteTransaction(ctx context.Context, user *data.User, transactionID string) error
+	Profitability(ctx context.Context, user *data.User, farmID string, from, to *time.Time) (*ProfitabilityReport, error)
+
+	CreateTaxRate(ctx context.Context, user *data.User, farmID string, in TaxRateInput) (*data.TaxRate, error)
+	GetTaxRate(ctx context.Context, user *data.User, taxRateID string) (*data.TaxRate, error)
+	ListTaxRates(ctx context.Context, user *data.User, farmID string) ([]*data.TaxRate, error)
+	UpdateTaxRate(ctx context.Context, user *data.User, taxRateID string, in TaxRateInput) (*data.TaxRate, error)
+	DeleteTaxRate(ctx context.Context, user *data.User, taxRateID string) error
+	TaxSummary(ctx context.Context, user *data.User, farmID, jurisdiction string, from, to *time.Time) (*TaxSummary, error)
+
+	CreateBudgetLine(ctx context.Context, user *data.User, farmID string, in BudgetLineInput) (*data.BudgetLine, error)
+	ListBudgetLines(ctx context.Context, user *data.User, farmID, seasonID string) ([]*data.BudgetLine, error)
+	UpdateBudgetLine(ctx context.Context, user *data.User, budgetLineID string, in BudgetLineInput) (*data.BudgetLine, error)
+	DeleteBudgetLine(ctx context.Context, user *data.User, budgetLineID string) error
+	CreateExchangeRate(ctx context.Context, user *data.User, farmID string, in ExchangeRateInput) (*data.ExchangeRate, error)
+	ListExchangeRates(ctx context.Context, user *data.User, farmID, currency string) ([]*data.ExchangeRate, error)
+	UpdateExchangeRate(ctx context.Context, user *data.User, exchangeRateID string, in ExchangeRateInput) (*data.ExchangeRate, error)
+	DeleteExchangeRate(ctx context.Context, user *data.User, exchangeRateID string) error
+
+	// Depreciation reports a year's depreciation of the farm's equipment and
+	// structures
+	Depreciation(ctx context.Context, user *data.User, farmID string, year int) (*DepreciationReport, error)
+	// BudgetVariance compares a season's budget with its recorded income and
+	// spending, flagging categories over budget or spending ahead of the
+	// season
+	BudgetVariance(ctx context.Context, user *data.User, farmID, seasonID string) (*BudgetVariance, error)
+}
+
+// financeService implements Service on top of the transaction, tax rate,
+// exchange rate, payroll, season, budget and asset repositories
+type financeService struct {
+	transactions  data.TransactionInterface
+	taxRates      data.TaxRateInterface
+	exchangeRates data.ExchangeRateInterface
+	payroll       data.PayrollPaymentInterface
+	seasons       data.SeasonInterface
+	budgets       data.BudgetLineInterface
+	assets        data.AssetInterface
+	locks         lock.Checker
+	farms         farm.Service
+}
+
+// New creates the finance service
+func New(transactions data.TransactionInterface, taxRates data.TaxRateInterface, exchangeRates data.ExchangeRateInterface,
+	payroll data.PayrollPaymentInterface, seasons data.SeasonInterface, budgets data.BudgetLineInterface, assets data.AssetInterface,
+	locks lock.Checker, farms farm.Service) Service {
+	return &financeService{transactions: transactions, taxRates: taxRates, exchangeRates: exchangeRates, payroll: payroll,
+		seasons: seasons, budgets: budgets, assets: assets, locks: locks, farms: farms}
+}
+
+// CreateTransaction records an income or expense on one of the user's farms
+func (s *financeService) CreateTransaction(ctx context.Context, user *data.User, farmID string, in TransactionInput) (*data.Transaction, error) {
+	f, err := s.farms.Owned(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if err := s.locks.Check(ctx, farmID, date); err != nil {
+		return nil, err
+	}
+
+	transaction := &data.Transaction{
+		FarmID:      farmID,
+		Type:        in.Type,
+		Category:    in.Category,
+		Currency:    in.Currency,
+		Date:        date,
+		Description: in.Description,
+		Notes:       in.Notes,
+	}
+	if err := s.convert(ctx, transaction, f, in.Amount, in.ExchangeRate); err != nil {
+		return nil, err
+	}
+	if err := s.linkSeason(ctx, transaction, in.SeasonID); err != nil {
+		return nil, err
+	}
+	if err := s.transactions.Insert(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("creating transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// GetTransaction returns a transaction of a farm whose finances the user may
+// read
+func (s *financeService) GetTransaction(ctx context.Context, user *data.User, transactionID string) (*data.Transaction, error) {
+	return s.transaction(ctx, user, transactionID, farm.Read)
+}
+
+// ListTransactions returns a farm's transactions dated in [from, to)
+func (s *financeService) ListTransactions(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.Transaction, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Read); err != nil {
+		return nil, err
+	}
+	transactions, err := s.transactions.GetByFarmIDAndDateRange(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// ListAllTransactions implements Service
+func (s *financeService) ListAllTransactions(ctx context.Context, user *data.User, from, to *time.Time) ([]*data.Transaction, error) {
+	farms, err := s.farms.Reachable(ctx, user, farm.ModuleFinance, farm.Read)
+	if err != nil {
+		return nil, err
+	}
+	all := []*data.Transaction{}
+	for _, f := range farms {
+		transactions, err := s.transactions.GetByFarmIDAndDateRange(ctx, f.FarmID, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("getting transactions: %w", err)
+		}
+		for _, transaction := range transactions {
+			transaction.Farm = f
+		}
+		all = append(all, transactions...)
+	}
+	return all, nil
+}
+
+// UpdateTransaction changes the non-zero fields of in on a transaction.
+// Entries generated from another record (e.g. a utility bill) are changed
+// through that record.
+func (s *financeService) UpdateTransaction(ctx context.Context, user *data.User, transactionID string, in TransactionInput) (*data.Transaction, error) {
+	transaction, err := s.editableTransaction(ctx, user, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Type != "" {
+		transaction.Type = in.Type
+	}
+	if in.Category != "" {
+		transaction.Category = in.Category
+	}
+	if in.Date != nil {
+		if err := s.locks.Check(ctx, transaction.FarmID, *in.Date); err != nil {
+			return nil, err
+		}
+		transaction.Date = *in.Date
+	}
+	if in.Description != "" {
+		transaction.Description = in.Description
+	}
+	if in.Notes != "" {
+		transaction.Notes = in.Notes
+	}
+	if in.Amount > 0 || in.Currency != "" || in.ExchangeRate != nil || (in.Date != nil && transaction.Currency != "") {
+		if err := s.reconvert(ctx, user, transaction, in); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.linkSeason(ctx, transaction, in.SeasonID); err != nil {
+		return nil, err
+	}
+
+	if err := s.transactions.Update(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("updating transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// reconvert works out a changed transaction's amount in the farm currency
+// again. A foreign amount keeps its rate unless its currency or date changed
+// or a new rate is given.
+func (s *financeService) reconvert(ctx context.Context, user *data.User, transaction *data.Transaction, in TransactionInput) error {
+	f, err := s.farms.Authorize(ctx, user, transaction.FarmID, farm.ModuleFinance, farm.Write)
+	if err != nil {
+		return err
+	}
+
+	amount, rate := transaction.Amount, in.ExchangeRate
+	if transaction.Currency != "" {
+		amount = transaction.OriginalAmount
+		if rate == nil && in.Currency == "" && in.Date == nil {
+			rate = &transaction.ExchangeRate
+		}
+	}
+	if in.Amount > 0 {
+		amount = in.Amount
+	}
+	if in.Currency != "" {
+		transaction.Currency = in.Currency
+	}
+	return s.convert(ctx, transaction, f, amount, rate)
+}
+
+// linkSeason links transaction to the season named by seasonID; see
+// season.Link
+func (s *financeService) linkSeason(ctx context.Context, transaction *data.Transaction, seasonID *string) error {
+	link, ok, err := season.Link(ctx, s.seasons, transaction.FarmID, seasonID)
+	if ok {
+		transaction.SeasonID = link
+	}
+	return err
+}
+
+// DeleteTransaction soft deletes a transaction that is not managed by a source record
+func (s *financeService) DeleteTransaction(ctx context.Context, user *data.User, transactionID string) error {
+	transaction, err := s.editableTransaction(ctx, user, transactionID)
+	if err != nil {
+		return err
+	}
+	if err := s.transactions.DeleteByID(ctx, int(transaction.ID)); err != nil {
+		return fmt.Errorf("deleting transaction: %w", err)
+	}
+	return nil
+}
+
+// Profitability reports income, direct costs and overheads for a farm over [from, to)
+func (s *financeService) Profitability(ctx context.Context, user *data.User, farmID string, from, to *time.Time) (*ProfitabilityReport, error) {
+	f, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleReports, farm.Read)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.transactions.TotalsByCategory(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting transaction totals: %w", err)
+	}
+
+	report := &ProfitabilityReport{
+		FarmID:    farmID,
+		Currency:  f.Currency,
+		From:      from,
+		To:        to,
+		Breakdown: totals,
+	}
+
+	for _, t := range totals {
+		switch {
+		case t.Type == "Income":
+			report.Income += t.Total
+		case slices.Contains(data.OverheadCategories, t.Category):
+			report.Overheads += t.Total
+		default:
+			report.DirectCosts += t.Total
+		}
+		if t.Type == "Expense" && t.Category == data.LivestockLossCategory {
+			report.Losses += t.Total
+		}
+	}
+	report.GrossProfit = report.Income - report.DirectCosts
+	report.NetProfit = report.GrossProfit - report.Overheads
+
+	return report, nil
+}
+
+// transaction loads a transaction, checking that the user may take action on
+// the finances of its farm
+func (s *financeService) transaction(ctx context.Context, user *data.User, transactionID string, action farm.Action) (*data.Transaction, error) {
+	transaction, err := s.transactions.GetByTransactionID(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("getting transaction: %w", err)
+	}
+	if transaction == nil {
+		return nil, service.NotFound("transaction not found")
+	}
+	if _, err := s.farms.Authorize(ctx, user, transaction.FarmID, farm.ModuleFinance, action); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// editableTransaction loads a transaction the user may change directly: one
+// that is not managed by a source record and not in a locked period
+func (s *financeService) editableTransaction(ctx context.Context, user *data.User, transactionID string) (*data.Transaction, error) {
+	transaction, err := s.transaction(ctx, user, transactionID, farm.Write)
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Reference != "" {
+		return nil, service.Conflict("transaction is managed by its source record")
+	}
+	if err := s.locks.Check(ctx, transaction.FarmID, transaction.Date); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
@@ -0,0 +1,291 @@
+package finance
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// payrollReferencePrefix marks ledger entries generated from payroll, which
+// are taxed on the Wages basis rather than as purchases
+const payrollReferencePrefix = "payroll_payment:"
+
+// TaxRateInput holds the editable tax rate fields. On update, zero values
+// and nil pointers are left unchanged.
+type TaxRateInput struct {
+	Name         string
+	Jurisdiction string
+	Basis        string
+	Category     string
+	Rate         *float64
+	Inclusive    *bool
+	Active       *bool
+	Notes        string
+}
+
+// TaxLine is one tax rate applied over the summary period
+type TaxLine struct {
+	TaxRateID    string  `json:"taxRateId"`
+	Name         string  `json:"name"`
+	Jurisdiction string  `json:"jurisdiction"`
+	Basis        string  `json:"basis"`
+	Category     string  `json:"category,omitempty"`
+	Rate         float64 `json:"rate"`
+	Inclusive    bool    `json:"inclusive"`
+	Entries      int     `json:"entries"` // Transactions or payroll payments taxed
+	Base         float64 `json:"base"`    // Taxable amount as recorded
+	Tax          float64 `json:"tax"`
+}
+
+// JurisdictionTax totals the tax lines of one jurisdiction. Purchases tax is
+// a credit against what is due on sales and wages.
+type JurisdictionTax struct {
+	Jurisdiction string  `json:"jurisdiction"`
+	Due          float64 `json:"due"`
+	Credits      float64 `json:"credits"`
+	NetPayable   float64 `json:"netPayable"`
+}
+
+// TaxSummary applies a farm's active tax rates to its ledger and payroll over
+// [From, To)
+type TaxSummary struct {
+	FarmID        string            `json:"farmId"`
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	Lines         []TaxLine         `json:"lines"`
+	Jurisdictions []JurisdictionTax `json:"jurisdictions"`
+}
+
+// CreateTaxRate configures a tax on one of the user's farms
+func (s *financeService) CreateTaxRate(ctx context.Context, user *data.User, farmID string, in TaxRateInput) (*data.TaxRate, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	rate := &data.TaxRate{
+		FarmID:       farmID,
+		Name:         in.Name,
+		Jurisdiction: in.Jurisdiction,
+		Basis:        in.Basis,
+		Category:     in.Category,
+		Notes:        in.Notes,
+		Active:       true,
+	}
+	if in.Rate != nil {
+		rate.Rate = *in.Rate
+	}
+	if in.Inclusive != nil {
+		rate.Inclusive = *in.Inclusive
+	}
+	if err := s.taxRates.Insert(ctx, rate); err != nil {
+		return nil, fmt.Errorf("creating tax rate: %w", err)
+	}
+
+	// Active defaults to true in the database, so an inactive rate is saved
+	// after it is created
+	if in.Active != nil && !*in.Active {
+		rate.Active = false
+		if err := s.taxRates.Update(ctx, rate); err != nil {
+			return nil, fmt.Errorf("updating tax rate: %w", err)
+		}
+	}
+	return rate, nil
+}
+
+// GetTaxRate returns a tax rate of a farm whose finances the user may read
+func (s *financeService) GetTaxRate(ctx context.Context, user *data.User, taxRateID string) (*data.TaxRate, error) {
+	return s.taxRate(ctx, user, taxRateID, farm.Read)
+}
+
+// ListTaxRates returns every tax rate configured on a farm
+func (s *financeService) ListTaxRates(ctx context.Context, user *data.User, farmID string) ([]*data.TaxRate, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Read); err != nil {
+		return nil, err
+	}
+	rates, err := s.taxRates.GetByFarmID(ctx, farmID, false)
+	if err != nil {
+		return nil, fmt.Errorf("getting tax rates: %w", err)
+	}
+	return rates, nil
+}
+
+// UpdateTaxRate changes the set fields of in on a tax rate
+func (s *financeService) UpdateTaxRate(ctx context.Context, user *data.User, taxRateID string, in TaxRateInput) (*data.TaxRate, error) {
+	rate, err := s.taxRate(ctx, user, taxRateID, farm.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		rate.Name = in.Name
+	}
+	if in.Jurisdiction != "" {
+		rate.Jurisdiction = in.Jurisdiction
+	}
+	if in.Basis != "" {
+		rate.Basis = in.Basis
+	}
+	if in.Category != "" {
+		rate.Category = in.Category
+	}
+	if in.Rate != nil {
+		rate.Rate = *in.Rate
+	}
+	if in.Inclusive != nil {
+		rate.Inclusive = *in.Inclusive
+	}
+	if in.Active != nil {
+		rate.Active = *in.Active
+	}
+	if in.Notes != "" {
+		rate.Notes = in.Notes
+	}
+
+	if err := s.taxRates.Update(ctx, rate); err != nil {
+		return nil, fmt.Errorf("updating tax rate: %w", err)
+	}
+	return rate, nil
+}
+
+// DeleteTaxRate soft deletes a tax rate
+func (s *financeService) DeleteTaxRate(ctx context.Context, user *data.User, taxRateID string) error {
+	rate, err := s.taxRate(ctx, user, taxRateID, farm.Write)
+	if err != nil {
+		return err
+	}
+	if err := s.taxRates.DeleteByID(ctx, int(rate.ID)); err != nil {
+		return fmt.Errorf("deleting tax rate: %w", err)
+	}
+	return nil
+}
+
+// TaxSummary applies a farm's active tax rates, optionally only those of one
+// jurisdiction, to the transactions and payroll dated in [from, to). Without
+// a period the current calendar quarter is summarised.
+func (s *financeService) TaxSummary(ctx context.Context, user *data.User, farmID, jurisdiction string, from, to *time.Time) (*TaxSummary, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleReports, farm.Read); err != nil {
+		return nil, err
+	}
+
+	if from == nil && to == nil {
+		now := time.Now()
+		start := time.Date(now.Year(), now.Month()-(now.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(0, 3, 0)
+		from, to = &start, &end
+	}
+	if from == nil || to == nil {
+		return nil, service.Invalid("a tax period needs both from and to")
+	}
+
+	rates, err := s.taxRates.GetByFarmID(ctx, farmID, true)
+	if err != nil {
+		return nil, fmt.Errorf("getting tax rates: %w", err)
+	}
+	transactions, err := s.transactions.GetByFarmIDAndDateRange(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting transactions: %w", err)
+	}
+	payments, err := s.payroll.GetByFarmID(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting payroll payments: %w", err)
+	}
+
+	summary := &TaxSummary{
+		FarmID:        farmID,
+		From:          *from,
+		To:            *to,
+		Lines:         []TaxLine{},
+		Jurisdictions: []JurisdictionTax{},
+	}
+	totals := map[string]*JurisdictionTax{}
+
+	for _, rate := range rates {
+		if jurisdiction != "" && !strings.EqualFold(rate.Jurisdiction, jurisdiction) {
+			continue
+		}
+
+		line := TaxLine{
+			TaxRateID:    rate.TaxRateID,
+			Name:         rate.Name,
+			Jurisdiction: rate.Jurisdiction,
+			Basis:        rate.Basis,
+			Category:     rate.Category,
+			Rate:         rate.Rate,
+			Inclusive:    rate.Inclusive,
+		}
+		switch rate.Basis {
+		case data.TaxBasisWages:
+			for _, payment := range payments {
+				line.Entries++
+				line.Base += payment.GrossPay
+			}
+		case data.TaxBasisSales, data.TaxBasisPurchases:
+			for _, t := range transactions {
+				if taxable(rate, t) {
+					line.Entries++
+					line.Base += t.Amount
+				}
+			}
+		}
+		line.Tax = round2(rate.TaxOn(line.Base))
+		line.Base = round2(line.Base)
+		summary.Lines = append(summary.Lines, line)
+
+		total, ok := totals[rate.Jurisdiction]
+		if !ok {
+			summary.Jurisdictions = append(summary.Jurisdictions, JurisdictionTax{Jurisdiction: rate.Jurisdiction})
+			total = &summary.Jurisdictions[len(summary.Jurisdictions)-1]
+			totals[rate.Jurisdiction] = total
+		}
+		if rate.Basis == data.TaxBasisPurchases {
+			total.Credits += line.Tax
+		} else {
+			total.Due += line.Tax
+		}
+	}
+
+	for i := range summary.Jurisdictions {
+		total := &summary.Jurisdictions[i]
+		total.Due = round2(total.Due)
+		total.Credits = round2(total.Credits)
+		total.NetPayable = round2(total.Due - total.Credits)
+	}
+	return summary, nil
+}
+
+// taxRate loads a tax rate, checking that the user may take action on the
+// finances of its farm
+func (s *financeService) taxRate(ctx context.Context, user *data.User, taxRateID string, action farm.Action) (*data.TaxRate, error) {
+	rate, err := s.taxRates.GetByTaxRateID(ctx, taxRateID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tax rate: %w", err)
+	}
+	if rate == nil {
+		return nil, service.NotFound("tax rate not found")
+	}
+	if _, err := s.farms.Authorize(ctx, user, rate.FarmID, farm.ModuleFinance, action); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+// taxable reports whether a ledger entry falls under a Sales or Purchases rate
+func taxable(rate *data.TaxRate, t *data.Transaction) bool {
+	if rate.Category != "" && !strings.EqualFold(rate.Category, t.Category) {
+		return false
+	}
+	if rate.Basis == data.TaxBasisSales {
+		return t.Type == "Income"
+	}
+	return t.Type == "Expense" && !strings.HasPrefix(t.Reference, payrollReferencePrefix)
+}
+
+// round2 rounds an amount to cents
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
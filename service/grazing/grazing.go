@@ -0,0 +1,210 @@
+// Package grazing manages a farm's paddocks and the rotation of its herds
+// through them. The planner schedules a herd's moves from paddock to paddock
+// and warns where a paddock would be grazed again before it has rested long
+// enough to regrow; the moves actually made are then recorded against the
+// plan.
+package grazing
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Paddock defaults, used when a paddock does not set its own
+const (
+	DefaultRestDays     = 30
+	DefaultMaxGrazeDays = 7
+)
+
+// PaddockInput holds the editable paddock fields. On update, zero values are
+// left unchanged; an empty FieldID string clears it.
+type PaddockInput struct {
+	FieldID      *string
+	Name         string
+	Area         float64
+	RestDays     int
+	MaxGrazeDays int
+	Notes        string
+}
+
+// Service is the grazing domain service
+type Service interface {
+	CreatePaddock(ctx context.Context, user *data.User, farmID string, in PaddockInput) (*data.Paddock, error)
+	GetPaddock(ctx context.Context, user *data.User, paddockID string) (*data.Paddock, error)
+	ListPaddocks(ctx context.Context, user *data.User, farmID string) ([]*data.Paddock, error)
+	UpdatePaddock(ctx context.Context, user *data.User, paddockID string, in PaddockInput) (*data.Paddock, error)
+	// DeletePaddock soft deletes a paddock no herd is grazing or planned on
+	DeletePaddock(ctx context.Context, user *data.User, paddockID string) error
+	// Rest reports, for each of a farm's paddocks, how long it has rested and
+	// when it is ready to graze again
+	Rest(ctx context.Context, user *data.User, farmID string, on time.Time) ([]*PaddockRest, error)
+
+	// Plan rotates a herd through paddocks over a period. The plan is only
+	// saved when save is true, so it can be previewed first.
+	Plan(ctx context.Context, user *data.User, farmID string, in PlanInput, save bool) (*Plan, error)
+	ListMoves(ctx context.Context, user *data.User, farmID string, filter MoveFilter) ([]*data.GrazingMove, error)
+	GetMove(ctx context.Context, user *data.User, grazingMoveID string) (*data.GrazingMove, error)
+	// RecordMove records a move that was not planned
+	RecordMove(ctx context.Context, user *data.User, farmID string, in MoveInput) (*data.GrazingMove, []Warning, error)
+	// MoveIn records a herd moving onto the paddock of a planned move
+	MoveIn(ctx context.Context, user *data.User, grazingMoveID string, at *time.Time) (*data.GrazingMove, []Warning, error)
+	// MoveOut records a herd moving off a paddock
+	MoveOut(ctx context.Context, user *data.User, grazingMoveID string, at *time.Time) (*data.GrazingMove, error)
+	// CancelMove drops a planned move the herd has not made
+	CancelMove(ctx context.Context, user *data.User, grazingMoveID string) (*data.GrazingMove, error)
+}
+
+// grazingService implements Service on top of the paddock and grazing move
+// repositories
+type grazingService struct {
+	paddocks  data.PaddockInterface
+	moves     data.GrazingMoveInterface
+	fields    data.FieldInterface
+	livestock data.LivestockInterface
+	farms     farm.Service
+}
+
+// New creates the grazing service
+func New(paddocks data.PaddockInterface, moves data.GrazingMoveInterface, fields data.FieldInterface,
+	livestock data.LivestockInterface, farms farm.Service) Service {
+	return &grazingService{paddocks: paddocks, moves: moves, fields: fields, livestock: livestock, farms: farms}
+}
+
+// CreatePaddock adds a paddock to one of the user's farms
+func (s *grazingService) CreatePaddock(ctx context.Context, user *data.User, farmID string, in PaddockInput) (*data.Paddock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	if in.RestDays <= 0 {
+		in.RestDays = DefaultRestDays
+	}
+	if in.MaxGrazeDays <= 0 {
+		in.MaxGrazeDays = DefaultMaxGrazeDays
+	}
+	paddock := &data.Paddock{
+		FarmID:       farmID,
+		Name:         strings.TrimSpace(in.Name),
+		Area:         in.Area,
+		RestDays:     in.RestDays,
+		MaxGrazeDays: in.MaxGrazeDays,
+		Notes:        in.Notes,
+	}
+	if err := s.link(ctx, paddock, in.FieldID); err != nil {
+		return nil, err
+	}
+
+	if err := s.paddocks.Insert(ctx, paddock); err != nil {
+		return nil, fmt.Errorf("creating paddock: %w", err)
+	}
+	return paddock, nil
+}
+
+// GetPaddock returns a paddock on one of the user's farms
+func (s *grazingService) GetPaddock(ctx context.Context, user *data.User, paddockID string) (*data.Paddock, error) {
+	paddock, err := s.paddocks.GetByPaddockID(ctx, paddockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting paddock: %w", err)
+	}
+	if paddock == nil {
+		return nil, service.NotFound("paddock not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, paddock.FarmID, "paddock"); err != nil {
+		return nil, err
+	}
+	return paddock, nil
+}
+
+// ListPaddocks returns the paddocks of one of the user's farms
+func (s *grazingService) ListPaddocks(ctx context.Context, user *data.User, farmID string) ([]*data.Paddock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	paddocks, err := s.paddocks.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting paddocks: %w", err)
+	}
+	return paddocks, nil
+}
+
+// UpdatePaddock changes the non-zero fields of in on a paddock
+func (s *grazingService) UpdatePaddock(ctx context.Context, user *data.User, paddockID string, in PaddockInput) (*data.Paddock, error) {
+	paddock, err := s.GetPaddock(ctx, user, paddockID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		paddock.Name = strings.TrimSpace(in.Name)
+	}
+	if in.Area > 0 {
+		paddock.Area = in.Area
+	}
+	if in.RestDays > 0 {
+		paddock.RestDays = in.RestDays
+	}
+	if in.MaxGrazeDays > 0 {
+		paddock.MaxGrazeDays = in.MaxGrazeDays
+	}
+	if in.Notes != "" {
+		paddock.Notes = in.Notes
+	}
+	if err := s.link(ctx, paddock, in.FieldID); err != nil {
+		return nil, err
+	}
+
+	if err := s.paddocks.Update(ctx, paddock); err != nil {
+		return nil, fmt.Errorf("updating paddock: %w", err)
+	}
+	return paddock, nil
+}
+
+// DeletePaddock soft deletes a paddock. Its past moves are kept for the
+// grazing history; a paddock a herd is on or planned on cannot be deleted.
+func (s *grazingService) DeletePaddock(ctx context.Context, user *data.User, paddockID string) error {
+	paddock, err := s.GetPaddock(ctx, user, paddockID)
+	if err != nil {
+		return err
+	}
+
+	moves, err := s.moves.GetByFarmID(ctx, paddock.FarmID, paddock.PaddockID, "", "")
+	if err != nil {
+		return fmt.Errorf("getting grazing moves: %w", err)
+	}
+	for _, move := range moves {
+		if move.Status == MovePlanned || move.Status == MoveGrazing {
+			return service.Conflict("paddock has a herd on it or planned on it; move or cancel it first")
+		}
+	}
+
+	if err := s.paddocks.DeleteByID(ctx, int(paddock.ID)); err != nil {
+		return fmt.Errorf("deleting paddock: %w", err)
+	}
+	return nil
+}
+
+// link sets the field a paddock lies on. A nil fieldID leaves it unchanged
+// and an empty one clears it.
+func (s *grazingService) link(ctx context.Context, paddock *data.Paddock, fieldID *string) error {
+	if fieldID == nil {
+		return nil
+	}
+	paddock.FieldID, paddock.Field = nil, nil
+	if *fieldID == "" {
+		return nil
+	}
+	field, err := s.fields.GetByFieldID(ctx, *fieldID)
+	if err != nil {
+		return fmt.Errorf("getting field: %w", err)
+	}
+	if field == nil || field.FarmID != paddock.FarmID {
+		return service.Invalid("field not found on this farm")
+	}
+	paddock.FieldID, paddock.Field = &field.FieldID, field
+	return nil
+}
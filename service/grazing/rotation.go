@@ -0,0 +1,472 @@
+package grazing
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/livestock"
+	"fmt"
+	"time"
+)
+
+// Grazing move statuses
+const (
+	MovePlanned   = "Planned"
+	MoveGrazing   = "Grazing"
+	MoveCompleted = "Completed"
+	MoveCancelled = "Cancelled"
+)
+
+// Warning kinds
+const (
+	// WarnShortRest is a paddock grazed again before its rest days are up
+	WarnShortRest = "ShortRest"
+	// WarnLongStay is a herd kept on a paddock longer than its MaxGrazeDays
+	WarnLongStay = "LongStay"
+	// WarnOverlap is a paddock with two herds on it, or a herd on two
+	// paddocks, at the same time
+	WarnOverlap = "Overlap"
+)
+
+// MaxPlanDays bounds the period a single rotation plan may cover
+const MaxPlanDays = 366
+
+// Warning flags a move that would overgraze a paddock or clash with another
+// move. Warnings do not stop a move being planned or recorded.
+type Warning struct {
+	Kind        string    `json:"kind"`
+	PaddockID   string    `json:"paddockId"`
+	PaddockName string    `json:"paddockName"`
+	Date        time.Time `json:"date"` // Start of the move warned about
+	Message     string    `json:"message"`
+}
+
+// PlanInput asks for a herd to be rotated through paddocks, in the order
+// given and round again, from Start until End. Each stay lasts GrazeDays, or
+// the paddock's MaxGrazeDays when GrazeDays is zero.
+type PlanInput struct {
+	LivestockID string
+	PaddockIDs  []string
+	Start       time.Time
+	End         time.Time // Exclusive
+	GrazeDays   int
+}
+
+// Plan is a herd's planned rotation and what is wrong with it
+type Plan struct {
+	LivestockID string              `json:"livestockId"`
+	Start       time.Time           `json:"start"`
+	End         time.Time           `json:"end"`
+	Saved       bool                `json:"saved"`
+	Moves       []*data.GrazingMove `json:"moves"`
+	Warnings    []Warning           `json:"warnings"`
+}
+
+// MoveInput records a move a herd made without a plan. Without MovedOutAt the
+// herd is still on the paddock.
+type MoveInput struct {
+	LivestockID string
+	PaddockID   string
+	MovedInAt   *time.Time // Defaults to now
+	MovedOutAt  *time.Time
+	Notes       string
+}
+
+// MoveFilter narrows the moves listed; empty fields match every move
+type MoveFilter struct {
+	PaddockID   string
+	LivestockID string
+	Status      string
+}
+
+// PaddockRest is how rested a paddock is on a given day
+type PaddockRest struct {
+	Paddock     *data.Paddock `json:"paddock"`
+	Grazing     bool          `json:"grazing"`               // A herd is on it
+	LastGrazed  *time.Time    `json:"lastGrazed,omitempty"`  // When the last herd moved off
+	RestedDays  *int          `json:"restedDays,omitempty"`  // Days since LastGrazed
+	ReadyOn     *time.Time    `json:"readyOn,omitempty"`     // When its rest days are up
+	Ready       bool          `json:"ready"`                 // Not grazing and rested
+	NextPlanned *time.Time    `json:"nextPlanned,omitempty"` // Start of the next planned move onto it
+}
+
+// daysBetween returns the whole days from the date of a to the date of b
+func daysBetween(a, b time.Time) int {
+	return int(service.Day(b).Sub(service.Day(a)).Hours() / 24)
+}
+
+// Plan rotates a herd through the paddocks and checks each move against the
+// farm's other moves, including those made earlier in the same plan
+func (s *grazingService) Plan(ctx context.Context, user *data.User, farmID string, in PlanInput, save bool) (*Plan, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if _, err := livestock.OnFarm(ctx, s.livestock, farmID, in.LivestockID); err != nil {
+		return nil, err
+	}
+	if len(in.PaddockIDs) == 0 {
+		return nil, service.Invalid("at least one paddock is required")
+	}
+	if in.GrazeDays < 0 {
+		return nil, service.Invalid("grazeDays cannot be negative")
+	}
+	start, end := service.Day(in.Start), service.Day(in.End)
+	if !end.After(start) {
+		return nil, service.Invalid("end must be after start")
+	}
+	if daysBetween(start, end) > MaxPlanDays {
+		return nil, service.Invalid(fmt.Sprintf("a plan can cover at most %d days", MaxPlanDays))
+	}
+
+	paddocks := make([]*data.Paddock, len(in.PaddockIDs))
+	for i, paddockID := range in.PaddockIDs {
+		paddock, err := s.paddockOn(ctx, farmID, paddockID)
+		if err != nil {
+			return nil, err
+		}
+		paddocks[i] = paddock
+	}
+
+	existing, err := s.moves.GetByFarmID(ctx, farmID, "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("getting grazing moves: %w", err)
+	}
+
+	plan := &Plan{LivestockID: in.LivestockID, Start: start, End: end, Saved: save, Moves: []*data.GrazingMove{}, Warnings: []Warning{}}
+	for i, from := 0, start; from.Before(end); i++ {
+		paddock := paddocks[i%len(paddocks)]
+		stay := in.GrazeDays
+		if stay == 0 {
+			stay = paddock.MaxGrazeDays
+		}
+		to := from.AddDate(0, 0, stay)
+		if to.After(end) {
+			to = end
+		}
+
+		plannedStart, plannedEnd := from, to
+		move := &data.GrazingMove{
+			FarmID:       farmID,
+			LivestockID:  in.LivestockID,
+			PaddockID:    paddock.PaddockID,
+			PlannedStart: &plannedStart,
+			PlannedEnd:   &plannedEnd,
+			Status:       MovePlanned,
+			Paddock:      paddock,
+		}
+		plan.Warnings = append(plan.Warnings, check(move, paddock, existing)...)
+		plan.Moves = append(plan.Moves, move)
+		existing = append(existing, move)
+		from = to
+	}
+
+	if save {
+		if err := s.moves.InsertMany(ctx, plan.Moves); err != nil {
+			return nil, fmt.Errorf("saving grazing plan: %w", err)
+		}
+	}
+	return plan, nil
+}
+
+// ListMoves returns the grazing moves of one of the user's farms
+func (s *grazingService) ListMoves(ctx context.Context, user *data.User, farmID string, filter MoveFilter) ([]*data.GrazingMove, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	moves, err := s.moves.GetByFarmID(ctx, farmID, filter.PaddockID, filter.LivestockID, filter.Status)
+	if err != nil {
+		return nil, fmt.Errorf("getting grazing moves: %w", err)
+	}
+	return moves, nil
+}
+
+// GetMove returns a grazing move on one of the user's farms
+func (s *grazingService) GetMove(ctx context.Context, user *data.User, grazingMoveID string) (*data.GrazingMove, error) {
+	move, err := s.moves.GetByGrazingMoveID(ctx, grazingMoveID)
+	if err != nil {
+		return nil, fmt.Errorf("getting grazing move: %w", err)
+	}
+	if move == nil {
+		return nil, service.NotFound("grazing move not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, move.FarmID, "grazing move"); err != nil {
+		return nil, err
+	}
+	return move, nil
+}
+
+// RecordMove records a move a herd made without a plan. A herd still on a
+// paddock moves off it when it is recorded moving onto another.
+func (s *grazingService) RecordMove(ctx context.Context, user *data.User, farmID string, in MoveInput) (*data.GrazingMove, []Warning, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+	if _, err := livestock.OnFarm(ctx, s.livestock, farmID, in.LivestockID); err != nil {
+		return nil, nil, err
+	}
+	paddock, err := s.paddockOn(ctx, farmID, in.PaddockID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	movedIn := time.Now().UTC()
+	if in.MovedInAt != nil {
+		movedIn = in.MovedInAt.UTC()
+	}
+	move := &data.GrazingMove{
+		FarmID:      farmID,
+		LivestockID: in.LivestockID,
+		PaddockID:   paddock.PaddockID,
+		MovedInAt:   &movedIn,
+		Status:      MoveGrazing,
+		Notes:       in.Notes,
+		Paddock:     paddock,
+	}
+	if in.MovedOutAt != nil {
+		if in.MovedOutAt.Before(movedIn) {
+			return nil, nil, service.Invalid("movedOutAt must not be before movedInAt")
+		}
+		movedOut := in.MovedOutAt.UTC()
+		move.MovedOutAt, move.Status = &movedOut, MoveCompleted
+	}
+
+	existing, err := s.moves.GetByFarmID(ctx, farmID, "", "", "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting grazing moves: %w", err)
+	}
+	if move.Status == MoveGrazing {
+		if err := s.moveOff(ctx, existing, move, movedIn); err != nil {
+			return nil, nil, err
+		}
+	}
+	warnings := check(move, paddock, existing)
+
+	if err := s.moves.InsertMany(ctx, []*data.GrazingMove{move}); err != nil {
+		return nil, nil, fmt.Errorf("recording grazing move: %w", err)
+	}
+	return move, warnings, nil
+}
+
+// MoveIn records the herd of a planned move going onto its paddock, by
+// default now. The herd moves off the paddock it was on.
+func (s *grazingService) MoveIn(ctx context.Context, user *data.User, grazingMoveID string, at *time.Time) (*data.GrazingMove, []Warning, error) {
+	move, err := s.GetMove(ctx, user, grazingMoveID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if move.Status != MovePlanned {
+		return nil, nil, service.Conflict(fmt.Sprintf("grazing move is %s; only a planned move can be moved into", move.Status))
+	}
+	paddock, err := s.paddockOn(ctx, move.FarmID, move.PaddockID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	movedIn := time.Now().UTC()
+	if at != nil {
+		movedIn = at.UTC()
+	}
+	existing, err := s.moves.GetByFarmID(ctx, move.FarmID, "", "", "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting grazing moves: %w", err)
+	}
+	if err := s.moveOff(ctx, existing, move, movedIn); err != nil {
+		return nil, nil, err
+	}
+
+	move.MovedInAt, move.Status = &movedIn, MoveGrazing
+	warnings := check(move, paddock, existing)
+	if err := s.moves.Update(ctx, move); err != nil {
+		return nil, nil, fmt.Errorf("updating grazing move: %w", err)
+	}
+	return move, warnings, nil
+}
+
+// MoveOut records a herd going off the paddock of a move, by default now
+func (s *grazingService) MoveOut(ctx context.Context, user *data.User, grazingMoveID string, at *time.Time) (*data.GrazingMove, error) {
+	move, err := s.GetMove(ctx, user, grazingMoveID)
+	if err != nil {
+		return nil, err
+	}
+	if move.Status != MoveGrazing {
+		return nil, service.Conflict(fmt.Sprintf("grazing move is %s; only a herd that is grazing can move out", move.Status))
+	}
+
+	movedOut := time.Now().UTC()
+	if at != nil {
+		movedOut = at.UTC()
+	}
+	if movedOut.Before(*move.MovedInAt) {
+		return nil, service.Invalid("the herd cannot move out before it moved in")
+	}
+	move.MovedOutAt, move.Status = &movedOut, MoveCompleted
+	if err := s.moves.Update(ctx, move); err != nil {
+		return nil, fmt.Errorf("updating grazing move: %w", err)
+	}
+	return move, nil
+}
+
+// CancelMove drops a planned move the herd has not made
+func (s *grazingService) CancelMove(ctx context.Context, user *data.User, grazingMoveID string) (*data.GrazingMove, error) {
+	move, err := s.GetMove(ctx, user, grazingMoveID)
+	if err != nil {
+		return nil, err
+	}
+	if move.Status != MovePlanned {
+		return nil, service.Conflict(fmt.Sprintf("grazing move is %s; only a planned move can be cancelled", move.Status))
+	}
+	move.Status = MoveCancelled
+	if err := s.moves.Update(ctx, move); err != nil {
+		return nil, fmt.Errorf("updating grazing move: %w", err)
+	}
+	return move, nil
+}
+
+// Rest reports how rested each of a farm's paddocks is on a day
+func (s *grazingService) Rest(ctx context.Context, user *data.User, farmID string, on time.Time) ([]*PaddockRest, error) {
+	paddocks, err := s.ListPaddocks(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+	moves, err := s.moves.GetByFarmID(ctx, farmID, "", "", "")
+	if err != nil {
+		return nil, fmt.Errorf("getting grazing moves: %w", err)
+	}
+	on = service.Day(on)
+
+	rests := make([]*PaddockRest, 0, len(paddocks))
+	for _, paddock := range paddocks {
+		rest := &PaddockRest{Paddock: paddock}
+		for _, move := range moves {
+			if move.PaddockID != paddock.PaddockID {
+				continue
+			}
+			switch move.Status {
+			case MoveGrazing:
+				rest.Grazing = true
+			case MoveCompleted:
+				if rest.LastGrazed == nil || move.MovedOutAt.After(*rest.LastGrazed) {
+					rest.LastGrazed = move.MovedOutAt
+				}
+			case MovePlanned:
+				if !move.PlannedStart.Before(on) && (rest.NextPlanned == nil || move.PlannedStart.Before(*rest.NextPlanned)) {
+					rest.NextPlanned = move.PlannedStart
+				}
+			}
+		}
+
+		rest.Ready = !rest.Grazing
+		if rest.LastGrazed != nil {
+			rested := daysBetween(*rest.LastGrazed, on)
+			readyOn := service.Day(*rest.LastGrazed).AddDate(0, 0, paddock.RestDays)
+			rest.RestedDays, rest.ReadyOn = &rested, &readyOn
+			rest.Ready = rest.Ready && !on.Before(readyOn)
+		}
+		rests = append(rests, rest)
+	}
+	return rests, nil
+}
+
+// paddockOn returns a paddock on farmID
+func (s *grazingService) paddockOn(ctx context.Context, farmID, paddockID string) (*data.Paddock, error) {
+	paddock, err := s.paddocks.GetByPaddockID(ctx, paddockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting paddock: %w", err)
+	}
+	if paddock == nil || paddock.FarmID != farmID {
+		return nil, service.Invalid(fmt.Sprintf("paddock %s not found on this farm", paddockID))
+	}
+	return paddock, nil
+}
+
+// moveOff completes the moves of move's herd that are still grazing another
+// paddock, at the time it moves on
+func (s *grazingService) moveOff(ctx context.Context, existing []*data.GrazingMove, move *data.GrazingMove, at time.Time) error {
+	for _, other := range existing {
+		if other.LivestockID != move.LivestockID || other.Status != MoveGrazing || other.GrazingMoveID == move.GrazingMoveID {
+			continue
+		}
+		movedOut := at
+		if movedOut.Before(*other.MovedInAt) {
+			return service.Conflict(fmt.Sprintf("the herd moved onto %s after this; record that move out first", paddockName(other)))
+		}
+		other.MovedOutAt, other.Status = &movedOut, MoveCompleted
+		if err := s.moves.Update(ctx, other); err != nil {
+			return fmt.Errorf("updating grazing move: %w", err)
+		}
+	}
+	return nil
+}
+
+// check warns where move would graze its paddock before it has rested, keep
+// the herd on it too long, or clash with one of the other moves. A move
+// without an end runs on indefinitely.
+func check(move *data.GrazingMove, paddock *data.Paddock, others []*data.GrazingMove) []Warning {
+	var warnings []Warning
+	start := service.Day(*move.Start())
+	var end *time.Time
+	if e := move.End(); e != nil {
+		d := service.Day(*e)
+		end = &d
+	}
+	warn := func(kind, message string) {
+		warnings = append(warnings, Warning{Kind: kind, PaddockID: paddock.PaddockID, PaddockName: paddock.Name, Date: start, Message: message})
+	}
+
+	if end != nil && daysBetween(start, *end) > paddock.MaxGrazeDays {
+		warn(WarnLongStay, fmt.Sprintf("%d days on %s is more than its %d grazing days", daysBetween(start, *end), paddock.Name, paddock.MaxGrazeDays))
+	}
+
+	// The nearest grazing of the same paddock before and after the move
+	var before, after *time.Time
+	for _, other := range others {
+		if other == move || other.Status == MoveCancelled || (move.GrazingMoveID != "" && other.GrazingMoveID == move.GrazingMoveID) {
+			continue
+		}
+		otherStart := service.Day(*other.Start())
+		var otherEnd *time.Time
+		if e := other.End(); e != nil {
+			d := service.Day(*e)
+			otherEnd = &d
+		}
+		overlaps := (end == nil || otherStart.Before(*end)) && (otherEnd == nil || start.Before(*otherEnd))
+
+		switch {
+		case other.PaddockID == move.PaddockID && overlaps:
+			warn(WarnOverlap, fmt.Sprintf("%s is already grazed from %s", paddock.Name, otherStart.Format(time.DateOnly)))
+		case other.PaddockID == move.PaddockID && otherEnd != nil && !otherEnd.After(start):
+			if before == nil || otherEnd.After(*before) {
+				before = otherEnd
+			}
+		case other.PaddockID == move.PaddockID && end != nil && !end.After(otherStart):
+			if after == nil || otherStart.Before(*after) {
+				after = &otherStart
+			}
+		case other.LivestockID == move.LivestockID && overlaps:
+			warn(WarnOverlap, fmt.Sprintf("the herd is on %s from %s", paddockName(other), otherStart.Format(time.DateOnly)))
+		}
+	}
+
+	if before != nil {
+		if rested := daysBetween(*before, start); rested < paddock.RestDays {
+			warn(WarnShortRest, fmt.Sprintf("%s will have rested %d of its %d days since %s",
+				paddock.Name, rested, paddock.RestDays, before.Format(time.DateOnly)))
+		}
+	}
+	if after != nil {
+		if rested := daysBetween(*end, *after); rested < paddock.RestDays {
+			warn(WarnShortRest, fmt.Sprintf("%s is grazed again on %s, after only %d of its %d rest days",
+				paddock.Name, after.Format(time.DateOnly), rested, paddock.RestDays))
+		}
+	}
+	return warnings
+}
+
+// paddockName names the paddock of a move loaded with it
+func paddockName(move *data.GrazingMove) string {
+	if move.Paddock != nil {
+		return move.Paddock.Name
+	}
+	return "another paddock"
+}
@@ -0,0 +1,115 @@
+package grazing
+
+import (
+	"farm4u/data"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	day := func(month time.Month, d int) *time.Time {
+		at := time.Date(2024, month, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	paddock := &data.Paddock{PaddockID: "north", Name: "North", RestDays: 30, MaxGrazeDays: 7}
+	move := func(herd, paddockID string, start, end *time.Time) *data.GrazingMove {
+		return &data.GrazingMove{LivestockID: herd, PaddockID: paddockID, PlannedStart: start, PlannedEnd: end, Status: MovePlanned}
+	}
+	cancelled := move("heifers", "north", day(time.February, 15), day(time.February, 20))
+	cancelled.Status = MoveCancelled
+
+	tests := []struct {
+		name   string
+		move   *data.GrazingMove
+		others []*data.GrazingMove
+		want   []string
+	}{
+		{
+			name: "rested paddock",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("heifers", "north", day(time.January, 10), day(time.January, 15)),
+			},
+		},
+		{
+			name: "grazed again too soon",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("heifers", "north", day(time.February, 15), day(time.February, 20)),
+			},
+			want: []string{WarnShortRest},
+		},
+		{
+			name: "next grazing too soon",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("heifers", "north", day(time.March, 15), day(time.March, 20)),
+			},
+			want: []string{WarnShortRest},
+		},
+		{
+			name: "rest counted from the nearest grazing",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("heifers", "north", day(time.January, 1), day(time.January, 5)),
+				move("heifers", "north", day(time.February, 20), day(time.February, 25)),
+			},
+			want: []string{WarnShortRest},
+		},
+		{
+			name:   "cancelled moves ignored",
+			move:   move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{cancelled},
+		},
+		{
+			name: "stay too long",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 20)),
+			want: []string{WarnLongStay},
+		},
+		{
+			name: "paddock already grazed",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("heifers", "north", day(time.March, 3), day(time.March, 8)),
+			},
+			want: []string{WarnOverlap},
+		},
+		{
+			name: "herd elsewhere",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("cows", "south", day(time.March, 4), day(time.March, 9)),
+			},
+			want: []string{WarnOverlap},
+		},
+		{
+			name: "open-ended move overlaps later grazing",
+			move: move("cows", "north", day(time.March, 1), nil),
+			others: []*data.GrazingMove{
+				move("heifers", "north", day(time.June, 1), day(time.June, 5)),
+			},
+			want: []string{WarnOverlap},
+		},
+		{
+			name: "back to back on the same day",
+			move: move("cows", "north", day(time.March, 1), day(time.March, 5)),
+			others: []*data.GrazingMove{
+				move("cows", "south", day(time.February, 25), day(time.March, 1)),
+				move("cows", "east", day(time.March, 5), day(time.March, 10)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, warning := range check(tt.move, paddock, tt.others) {
+				got = append(got, warning.Kind)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("warnings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
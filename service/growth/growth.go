@@ -0,0 +1,300 @@
+// Package growth records the weights of a farm's livestock and turns them
+// into growth curves, so fatteners can follow each animal's or herd's
+// average daily gain against the gain they aim for.
+package growth
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/livestock"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Input holds the editable weight record fields. On update, zero values are
+// left unchanged. HeadCount defaults to 1; a herd weighed together records
+// its average Weight per head.
+type Input struct {
+	LivestockID string
+	AnimalTag   string
+	Date        *time.Time // Defaults to now
+	Weight      float64    // Kg
+	HeadCount   int
+	Notes       string
+}
+
+// Point is one weighing on a growth curve
+type Point struct {
+	Date   time.Time `json:"date"`
+	Weight float64   `json:"weight"`
+	Days   int       `json:"days"` // Since the first weighing
+	// DailyGain is the gain per day since the previous weighing; it is
+	// absent on the first
+	DailyGain *float64 `json:"dailyGain,omitempty"`
+}
+
+// Series is the growth curve of one animal, or of a herd's average weight
+// when AnimalTag is empty
+type Series struct {
+	LivestockID string  `json:"livestockId"`
+	AnimalTag   string  `json:"animalTag"`
+	Points      []Point `json:"points"`
+	// AverageDailyGain is the gain per day from the first weighing to the
+	// last
+	AverageDailyGain *float64 `json:"averageDailyGain,omitempty"`
+	TargetDailyGain  float64  `json:"targetDailyGain,omitempty"`
+	// BelowTarget is set when a target was given and the average gain falls
+	// short of it
+	BelowTarget bool `json:"belowTarget"`
+}
+
+// Service is the growth domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.WeightRecord, error)
+	// CreateBatch records a weigh day in a single transaction. An item that
+	// fails its checks gets its error in the same slot of the returned errors
+	// and is left out; the others are still created.
+	CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.WeightRecord, []error, error)
+	Get(ctx context.Context, user *data.User, weightRecordID string) (*data.WeightRecord, error)
+	// List returns a farm's weighings, optionally only those of a herd or,
+	// when animalTag is not nil, of one animal
+	List(ctx context.Context, user *data.User, farmID, livestockID string, animalTag *string) ([]*data.WeightRecord, error)
+	Update(ctx context.Context, user *data.User, weightRecordID string, in Input) (*data.WeightRecord, error)
+	Delete(ctx context.Context, user *data.User, weightRecordID string) error
+	// Growth returns a growth curve for each animal, and each herd weighed as
+	// a whole, that List would return weighings of. A targetDailyGain above
+	// zero flags the curves growing slower.
+	Growth(ctx context.Context, user *data.User, farmID, livestockID string, animalTag *string, targetDailyGain float64) ([]*Series, error)
+}
+
+// growthService implements Service on top of the weight record and
+// livestock repositories
+type growthService struct {
+	weights   data.WeightRecordInterface
+	livestock data.LivestockInterface
+	farms     farm.Service
+}
+
+// New creates the growth service
+func New(weights data.WeightRecordInterface, livestock data.LivestockInterface, farms farm.Service) Service {
+	return &growthService{weights: weights, livestock: livestock, farms: farms}
+}
+
+// Create records a weighing on one of the user's farms
+func (s *growthService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.WeightRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	record, err := s.newRecord(ctx, farmID, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.weights.InsertMany(ctx, []*data.WeightRecord{record}); err != nil {
+		return nil, fmt.Errorf("creating weight record: %w", err)
+	}
+	return record, nil
+}
+
+// CreateBatch records several weighings on one of the user's farms in a
+// single transaction, leaving out those that fail their checks
+func (s *growthService) CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.WeightRecord, []error, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]*data.WeightRecord, len(ins))
+	errs := make([]error, len(ins))
+	var valid []*data.WeightRecord
+	for i, in := range ins {
+		records[i], errs[i] = s.newRecord(ctx, farmID, in)
+		if errs[i] != nil && service.KindOf(errs[i]) == service.KindInternal {
+			return nil, nil, errs[i]
+		}
+		if records[i] != nil {
+			valid = append(valid, records[i])
+		}
+	}
+	if err := s.weights.InsertMany(ctx, valid); err != nil {
+		return nil, nil, fmt.Errorf("creating weight records: %w", err)
+	}
+	return records, errs, nil
+}
+
+// Get returns a weighing on one of the user's farms
+func (s *growthService) Get(ctx context.Context, user *data.User, weightRecordID string) (*data.WeightRecord, error) {
+	record, err := s.weights.GetByWeightRecordID(ctx, weightRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting weight record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("weight record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "weight record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the weighings on one of the user's farms
+func (s *growthService) List(ctx context.Context, user *data.User, farmID, livestockID string, animalTag *string) ([]*data.WeightRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.weights.GetByFarmID(ctx, farmID, livestockID, animalTag)
+	if err != nil {
+		return nil, fmt.Errorf("getting weight records: %w", err)
+	}
+	return records, nil
+}
+
+// Update changes the non-zero fields of in on a weighing
+func (s *growthService) Update(ctx context.Context, user *data.User, weightRecordID string, in Input) (*data.WeightRecord, error) {
+	record, err := s.Get(ctx, user, weightRecordID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.LivestockID != "" && in.LivestockID != record.LivestockID {
+		herd, err := livestock.OnFarm(ctx, s.livestock, record.FarmID, in.LivestockID)
+		if err != nil {
+			return nil, err
+		}
+		record.LivestockID = herd.LivestockID
+	}
+	if in.AnimalTag != "" {
+		record.AnimalTag = strings.TrimSpace(in.AnimalTag)
+	}
+	if in.Date != nil {
+		record.Date = *in.Date
+	}
+	if in.Weight > 0 {
+		record.Weight = in.Weight
+	}
+	if in.HeadCount > 0 {
+		record.HeadCount = in.HeadCount
+	}
+	if in.Notes != "" {
+		record.Notes = in.Notes
+	}
+	if record.AnimalTag != "" && record.HeadCount != 1 {
+		return nil, service.Invalid("an animal's weighing has a head count of 1")
+	}
+
+	if err := s.weights.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("updating weight record: %w", err)
+	}
+	return record, nil
+}
+
+// Delete soft deletes a weighing
+func (s *growthService) Delete(ctx context.Context, user *data.User, weightRecordID string) error {
+	record, err := s.Get(ctx, user, weightRecordID)
+	if err != nil {
+		return err
+	}
+	if err := s.weights.DeleteByID(ctx, int(record.ID)); err != nil {
+		return fmt.Errorf("deleting weight record: %w", err)
+	}
+	return nil
+}
+
+// Growth implements Service
+func (s *growthService) Growth(ctx context.Context, user *data.User, farmID, livestockID string, animalTag *string, targetDailyGain float64) ([]*Series, error) {
+	records, err := s.List(ctx, user, farmID, livestockID, animalTag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Records come oldest first, so each series' points do too
+	all := []*Series{}
+	byKey := map[string]*Series{}
+	for _, record := range records {
+		key := record.LivestockID + "|" + strings.ToLower(record.AnimalTag)
+		series, ok := byKey[key]
+		if !ok {
+			series = &Series{LivestockID: record.LivestockID, AnimalTag: record.AnimalTag, Points: []Point{}}
+			byKey[key] = series
+			all = append(all, series)
+		}
+		series.add(record)
+	}
+
+	for _, series := range all {
+		first, last := series.Points[0], series.Points[len(series.Points)-1]
+		if last.Days > 0 {
+			gain := round((last.Weight - first.Weight) / float64(last.Days))
+			series.AverageDailyGain = &gain
+		}
+		if targetDailyGain > 0 {
+			series.TargetDailyGain = targetDailyGain
+			series.BelowTarget = series.AverageDailyGain != nil && *series.AverageDailyGain < targetDailyGain
+		}
+	}
+	return all, nil
+}
+
+// add appends a weighing to the curve. Weighings on the same day as the
+// previous one replace it, as a reweigh.
+func (s *Series) add(record *data.WeightRecord) {
+	point := Point{Date: record.Date, Weight: record.Weight}
+	if n := len(s.Points); n > 0 && service.Day(s.Points[n-1].Date).Equal(service.Day(record.Date)) {
+		s.Points = s.Points[:n-1]
+	}
+	if n := len(s.Points); n > 0 {
+		first, previous := s.Points[0], s.Points[n-1]
+		point.Days = daysBetween(first.Date, record.Date)
+		if days := daysBetween(previous.Date, record.Date); days > 0 {
+			gain := round((record.Weight - previous.Weight) / float64(days))
+			point.DailyGain = &gain
+		}
+	}
+	s.Points = append(s.Points, point)
+}
+
+// newRecord builds a weighing on farmID from in
+func (s *growthService) newRecord(ctx context.Context, farmID string, in Input) (*data.WeightRecord, error) {
+	herd, err := livestock.OnFarm(ctx, s.livestock, farmID, in.LivestockID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Weight <= 0 {
+		return nil, service.Invalid("weight must be greater than 0")
+	}
+
+	record := &data.WeightRecord{
+		FarmID:      farmID,
+		LivestockID: herd.LivestockID,
+		AnimalTag:   strings.TrimSpace(in.AnimalTag),
+		Date:        time.Now(),
+		Weight:      in.Weight,
+		HeadCount:   in.HeadCount,
+		Notes:       in.Notes,
+	}
+	if in.Date != nil {
+		record.Date = *in.Date
+	}
+	if record.HeadCount <= 0 {
+		record.HeadCount = 1
+	}
+	if record.AnimalTag != "" && record.HeadCount != 1 {
+		return nil, service.Invalid("an animal's weighing has a head count of 1")
+	}
+	if record.HeadCount > herd.Count && herd.Count > 0 {
+		return nil, service.Invalid(fmt.Sprintf("head count cannot be more than the %d animals in the herd", herd.Count))
+	}
+	return record, nil
+}
+
+// round rounds a weight or gain to grams
+func round(kg float64) float64 {
+	return math.Round(kg*1000) / 1000
+}
+
+// daysBetween returns the whole days from the date of a to the date of b
+func daysBetween(a, b time.Time) int {
+	return int(service.Day(b).Sub(service.Day(a)).Hours() / 24)
+}
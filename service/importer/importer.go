@@ -0,0 +1,332 @@
+// Package importer brings records kept in other farm-record apps and
+// spreadsheets into a farm, so switching to Farm Manager 4U does not mean
+// retyping years of history. A file is uploaded, a column mapping proposed
+// from its headers, adjusted by the user, checked row by row and then
+// committed in one transaction.
+package importer
+
+import (
+	"bytes"
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"farm4u/storage"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Import statuses
+const (
+	StatusPending   = "Pending"
+	StatusValidated = "Validated"
+	StatusCommitted = "Committed"
+)
+
+// maxRows caps the rows in one file
+const maxRows = 10000
+
+// sampleRows is how many rows are shown while mapping columns
+const sampleRows = 5
+
+// UploadInput is an uploaded file. Source defaults to generic.
+type UploadInput struct {
+	Target   string
+	Source   string
+	FileName string
+	Content  []byte
+}
+
+// MappingInput sets which column fills each field, replacing the proposed
+// mapping. An empty DateFormat is left unchanged.
+type MappingInput struct {
+	Mapping    map[string]string
+	DateFormat string
+}
+
+// Service is the import domain service
+type Service interface {
+	// Upload stores a file and proposes a column mapping for it
+	Upload(ctx context.Context, user *data.User, farmID string, in UploadInput) (*data.ImportJob, error)
+	// Get returns an import with a sample of its rows
+	Get(ctx context.Context, user *data.User, importJobID string) (*data.ImportJob, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.ImportJob, error)
+	SetMapping(ctx context.Context, user *data.User, importJobID string, in MappingInput) (*data.ImportJob, error)
+	// Validate checks every row against the mapping and records the errors
+	Validate(ctx context.Context, user *data.User, importJobID string) (*data.ImportJob, error)
+	// Commit adds a validated import's rows to the farm. Rows with errors
+	// fail the commit unless skipInvalid is set, when they are left out.
+	Commit(ctx context.Context, user *data.User, importJobID string, skipInvalid bool) (*data.ImportJob, error)
+	// Delete removes an import and its file; committed records are kept
+	Delete(ctx context.Context, user *data.User, importJobID string) error
+}
+
+// importService implements Service on top of the import job repository and
+// object storage
+type importService struct {
+	jobs   data.ImportJobInterface
+	files  storage.Storage
+	fields data.FieldInterface
+	locks  lock.Checker
+	farms  farm.Service
+}
+
+// New creates the import service
+func New(jobs data.ImportJobInterface, files storage.Storage, fields data.FieldInterface, locks lock.Checker, farms farm.Service) Service {
+	return &importService{jobs: jobs, files: files, fields: fields, locks: locks, farms: farms}
+}
+
+// Upload implements Service
+func (s *importService) Upload(ctx context.Context, user *data.User, farmID string, in UploadInput) (*data.ImportJob, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if _, ok := Targets[in.Target]; !ok {
+		return nil, service.Invalid("target must be one of crops, livestock, transactions, employees")
+	}
+	if in.Source == "" {
+		in.Source = SourceGeneric
+	}
+	source, ok := layouts[in.Source]
+	if !ok {
+		return nil, service.Invalid("source must be one of " + strings.Join(Sources(), ", "))
+	}
+
+	delimiter := sniffDelimiter(in.Content)
+	sh, err := readSheet(in.Content, delimiter)
+	if err != nil {
+		return nil, service.Invalid(fmt.Sprintf("reading file: %v", err))
+	}
+	switch {
+	case len(sh.rows) == 0:
+		return nil, service.Invalid("the file has no rows after the header")
+	case len(sh.rows) > maxRows:
+		return nil, service.Invalid(fmt.Sprintf("the file has %d rows; split it into files of at most %d", len(sh.rows), maxRows))
+	}
+
+	job := &data.ImportJob{
+		FarmID:     farmID,
+		UserID:     user.UserID,
+		Target:     in.Target,
+		Source:     in.Source,
+		FileName:   in.FileName,
+		Delimiter:  delimiter,
+		DateFormat: source.dateFormat,
+		Headers:    sh.headers,
+		Mapping:    proposeMapping(in.Source, in.Target, sh.headers),
+		RowCount:   len(sh.rows),
+		Status:     StatusPending,
+	}
+	if err := s.jobs.Insert(ctx, job); err != nil {
+		return nil, fmt.Errorf("creating import: %w", err)
+	}
+	if err := s.files.Put(ctx, job.FileKey(), bytes.NewReader(in.Content), int64(len(in.Content)), "text/csv"); err != nil {
+		if derr := s.jobs.DeleteByID(ctx, int(job.ID)); derr != nil {
+			return nil, fmt.Errorf("storing import file: %w (and removing import: %v)", err, derr)
+		}
+		return nil, fmt.Errorf("storing import file: %w", err)
+	}
+
+	job.Sample = sh.sample(sampleRows)
+	return job, nil
+}
+
+// Get implements Service
+func (s *importService) Get(ctx context.Context, user *data.User, importJobID string) (*data.ImportJob, error) {
+	job, err := s.get(ctx, user, importJobID)
+	if err != nil {
+		return nil, err
+	}
+	sh, err := s.read(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	job.Sample = sh.sample(sampleRows)
+	return job, nil
+}
+
+// List implements Service
+func (s *importService) List(ctx context.Context, user *data.User, farmID string) ([]*data.ImportJob, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	jobs, err := s.jobs.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting imports: %w", err)
+	}
+	return jobs, nil
+}
+
+// SetMapping implements Service. Changing the mapping discards an earlier
+// validation.
+func (s *importService) SetMapping(ctx context.Context, user *data.User, importJobID string, in MappingInput) (*data.ImportJob, error) {
+	job, err := s.get(ctx, user, importJobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == StatusCommitted {
+		return nil, service.Conflict("import has already been committed")
+	}
+
+	mapping := map[string]string{}
+	for field, header := range in.Mapping {
+		if header == "" {
+			continue
+		}
+		if _, ok := fieldNamed(job.Target, field); !ok {
+			return nil, service.Invalid(fmt.Sprintf("%s records have no field %q", job.Target, field))
+		}
+		if !slices.Contains(job.Headers, header) {
+			return nil, service.Invalid(fmt.Sprintf("the file has no column %q", header))
+		}
+		mapping[field] = header
+	}
+	if in.DateFormat != "" {
+		if _, ok := dateLayouts[in.DateFormat]; !ok {
+			return nil, service.Invalid(fmt.Sprintf("dateFormat must be one of %s, %s, %s", DateISO, DateDMY, DateMDY))
+		}
+		job.DateFormat = in.DateFormat
+	}
+
+	job.Mapping = mapping
+	job.Status = StatusPending
+	job.ValidRows, job.ErrorCount, job.Errors = 0, 0, nil
+	if err := s.jobs.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("updating import: %w", err)
+	}
+	return s.Get(ctx, user, job.ImportJobID)
+}
+
+// Validate implements Service
+func (s *importService) Validate(ctx context.Context, user *data.User, importJobID string) (*data.ImportJob, error) {
+	job, err := s.get(ctx, user, importJobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == StatusCommitted {
+		return nil, service.Conflict("import has already been committed")
+	}
+	if _, err := s.check(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Commit implements Service. The rows are checked again, as the farm's
+// fields and locked periods may have changed since validation.
+func (s *importService) Commit(ctx context.Context, user *data.User, importJobID string, skipInvalid bool) (*data.ImportJob, error) {
+	job, err := s.get(ctx, user, importJobID)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case StatusCommitted:
+		return nil, service.Conflict("import has already been committed")
+	case StatusPending:
+		return nil, service.Conflict("validate the import before committing it")
+	}
+
+	sh, err := s.read(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.convert(ctx, job, sh)
+	if err != nil {
+		return nil, err
+	}
+	if c.errorCount > 0 && !skipInvalid {
+		return nil, service.Invalid(fmt.Sprintf("%d problems found in the file; fix them or commit with skipInvalid to leave those rows out", c.errorCount))
+	}
+	if c.valid == 0 {
+		return nil, service.Invalid("the file has no valid rows to import")
+	}
+
+	now := time.Now()
+	job.ValidRows, job.ErrorCount = c.valid, c.errorCount
+	job.ImportedCount = c.valid
+	job.CommittedAt = &now
+	committed, err := s.jobs.Commit(ctx, job, c.records)
+	if err != nil {
+		return nil, fmt.Errorf("committing import: %w", err)
+	}
+	if !committed {
+		return nil, service.Conflict("import has already been committed")
+	}
+	job.Status = StatusCommitted
+	return job, nil
+}
+
+// Delete implements Service
+func (s *importService) Delete(ctx context.Context, user *data.User, importJobID string) error {
+	job, err := s.get(ctx, user, importJobID)
+	if err != nil {
+		return err
+	}
+	if err := s.files.Delete(ctx, job.FileKey()); err != nil {
+		return fmt.Errorf("deleting import file: %w", err)
+	}
+	if err := s.jobs.DeleteByID(ctx, int(job.ID)); err != nil {
+		return fmt.Errorf("deleting import: %w", err)
+	}
+	return nil
+}
+
+// get returns an import of one of the user's farms
+func (s *importService) get(ctx context.Context, user *data.User, importJobID string) (*data.ImportJob, error) {
+	job, err := s.jobs.GetByImportJobID(ctx, importJobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting import: %w", err)
+	}
+	if job == nil {
+		return nil, service.NotFound("import not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, job.FarmID, "import"); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// read loads and parses an import's file
+func (s *importService) read(ctx context.Context, job *data.ImportJob) (*sheet, error) {
+	file, _, err := s.files.Get(ctx, job.FileKey())
+	if err != nil {
+		return nil, fmt.Errorf("opening import file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading import file: %w", err)
+	}
+	sh, err := readSheet(content, job.Delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("parsing import file: %w", err)
+	}
+	return sh, nil
+}
+
+// check converts an import's rows and saves the outcome on the job, marking
+// it Validated
+func (s *importService) check(ctx context.Context, job *data.ImportJob) (*conversion, error) {
+	sh, err := s.read(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.convert(ctx, job, sh)
+	if err != nil {
+		return nil, err
+	}
+
+	job.Status = StatusValidated
+	job.ValidRows = c.valid
+	job.ErrorCount = c.errorCount
+	job.Errors = c.errors
+	if err := s.jobs.Update(ctx, job); err != nil {
+		return nil, fmt.Errorf("updating import: %w", err)
+	}
+	return c, nil
+}
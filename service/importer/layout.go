@@ -0,0 +1,174 @@
+package importer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Import targets, the kinds of record a file can be imported as
+const (
+	TargetCrops        = "crops"
+	TargetLivestock    = "livestock"
+	TargetTransactions = "transactions"
+	TargetEmployees    = "employees"
+)
+
+// Sources are the layouts of files exported by other apps. Generic covers
+// any spreadsheet, with columns matched on common header names.
+const (
+	SourceGeneric    = "generic"
+	SourceQuickBooks = "quickbooks"
+)
+
+// Date formats a file's dates can be written in
+const (
+	DateISO = "YYYY-MM-DD"
+	DateDMY = "DD/MM/YYYY"
+	DateMDY = "MM/DD/YYYY"
+)
+
+// Value types of a field
+const (
+	TypeText    = "text"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeDate    = "date"
+)
+
+// Field is a record field a column can be mapped to
+type Field struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"` // Allowed values, matched ignoring case
+	Help     string   `json:"help,omitempty"`
+
+	// aliases are header names, normalised, proposed for this field
+	aliases []string
+}
+
+// Targets lists the fields of each import target
+var Targets = map[string][]Field{
+	TargetCrops: {
+		{Name: "name", Type: TypeText, Required: true, aliases: []string{"crop", "cropname", "variety", "cropvariety"}},
+		{Name: "field", Type: TypeText, Help: "Name of one of the farm's fields", aliases: []string{"fieldname", "plot", "block", "paddock"}},
+		{Name: "plantingDate", Type: TypeDate, aliases: []string{"planted", "planteddate", "plantedon", "sowingdate", "sowndate", "datesown", "dateplanted"}},
+		{Name: "harvestDate", Type: TypeDate, aliases: []string{"harvested", "harvesteddate", "dateharvested", "expectedharvest"}},
+		{Name: "quantity", Type: TypeNumber, aliases: []string{"qty", "amountplanted", "seedrate", "plants", "area"}},
+		{Name: "status", Type: TypeText, Options: []string{"Growing", "Harvested", "Failed"}, aliases: []string{"cropstatus", "stage"}},
+		{Name: "notes", Type: TypeText, aliases: []string{"note", "comments", "comment", "remarks"}},
+	},
+	TargetLivestock: {
+		{Name: "type", Type: TypeText, Required: true, aliases: []string{"animal", "animaltype", "species", "livestocktype", "kind"}},
+		{Name: "count", Type: TypeInteger, Required: true, aliases: []string{"number", "head", "headcount", "qty", "quantity", "animals"}},
+		{Name: "acquisitionDate", Type: TypeDate, aliases: []string{"acquired", "purchasedate", "datepurchased", "dateacquired", "birthdate", "dateofbirth"}},
+		{Name: "healthStatus", Type: TypeText, Options: []string{"Healthy", "Sick", "Under Treatment", "Deceased"}, aliases: []string{"health", "status", "condition"}},
+		{Name: "notes", Type: TypeText, aliases: []string{"note", "comments", "comment", "remarks"}},
+	},
+	TargetTransactions: {
+		{Name: "date", Type: TypeDate, Required: true, aliases: []string{"transactiondate", "txndate", "paymentdate", "posteddate"}},
+		{Name: "amount", Type: TypeNumber, Required: true, Help: "Negative amounts are expenses when no type column is mapped", aliases: []string{"total", "value", "amountugx", "amountusd"}},
+		{Name: "type", Type: TypeText, Options: []string{"Income", "Expense"}, aliases: []string{"transactiontype", "incomeexpense", "direction", "kind"}},
+		{Name: "category", Type: TypeText, Required: true, aliases: []string{"account", "accountname", "expensecategory", "incomecategory"}},
+		{Name: "description", Type: TypeText, aliases: []string{"details", "memo", "memodescription", "payee", "narration", "particulars"}},
+		{Name: "notes", Type: TypeText, aliases: []string{"note", "comments", "comment", "remarks", "reference", "ref", "num"}},
+	},
+	TargetEmployees: {
+		{Name: "firstName", Type: TypeText, Required: true, aliases: []string{"first", "firstname", "givenname", "forename"}},
+		{Name: "lastName", Type: TypeText, Required: true, aliases: []string{"last", "lastname", "surname", "familyname"}},
+		{Name: "position", Type: TypeText, Required: true, aliases: []string{"role", "jobtitle", "title", "job"}},
+		{Name: "salary", Type: TypeNumber, aliases: []string{"pay", "wage", "wages", "monthlysalary", "rate"}},
+		{Name: "hireDate", Type: TypeDate, aliases: []string{"hired", "startdate", "datehired", "joined", "datejoined"}},
+		{Name: "contactInfo", Type: TypeText, aliases: []string{"contact", "phone", "phonenumber", "mobile", "email", "telephone"}},
+		{Name: "status", Type: TypeText, Options: []string{"Active", "Inactive", "Terminated"}, aliases: []string{"employmentstatus"}},
+	},
+}
+
+// layout is what is known about files exported by one app
+type layout struct {
+	dateFormat string
+	// columns maps normalised header names to fields, per target, ahead of
+	// the generic aliases
+	columns map[string]map[string]string
+}
+
+// layouts are the known sources
+var layouts = map[string]layout{
+	SourceGeneric: {dateFormat: DateISO},
+	// QuickBooks "Transaction Detail" and journal reports, exported to CSV
+	SourceQuickBooks: {
+		dateFormat: DateMDY,
+		columns: map[string]map[string]string{
+			TargetTransactions: {
+				"date":            "date",
+				"amount":          "amount",
+				"account":         "category",
+				"split":           "category",
+				"memodescription": "description",
+				"name":            "description",
+				"num":             "notes",
+			},
+		},
+	},
+}
+
+// Sources returns the names of the known sources
+func Sources() []string {
+	return []string{SourceGeneric, SourceQuickBooks}
+}
+
+// fieldNamed returns target's field called name
+func fieldNamed(target, name string) (Field, bool) {
+	for _, f := range Targets[target] {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// proposeMapping matches headers to target's fields: first the source's
+// known columns, then each field's name and aliases. A column is proposed
+// for at most one field and a field takes the first matching column.
+func proposeMapping(source, target string, headers []string) map[string]string {
+	mapping := map[string]string{}
+	used := map[string]bool{}
+	assign := func(field, header string) {
+		if _, done := mapping[field]; done || used[header] {
+			return
+		}
+		mapping[field] = header
+		used[header] = true
+	}
+
+	known := layouts[source].columns[target]
+	for _, header := range headers {
+		if field, ok := known[normaliseHeader(header)]; ok {
+			assign(field, header)
+		}
+	}
+
+	for _, f := range Targets[target] {
+		names := append([]string{normaliseHeader(f.Name)}, f.aliases...)
+		for _, name := range names {
+			for _, header := range headers {
+				if normaliseHeader(header) == name {
+					assign(f.Name, header)
+				}
+			}
+		}
+	}
+	return mapping
+}
+
+// normaliseHeader reduces a header to lower-case letters and digits, e.g.
+// "Memo/Description" -> "memodescription"
+func normaliseHeader(header string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(header) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
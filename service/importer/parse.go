@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sheet is an uploaded file split into its header and rows
+type sheet struct {
+	headers []string
+	columns map[string]int // Position of each header
+	rows    [][]string
+	lines   []int // File line number of each row
+}
+
+// sniffDelimiter picks the comma, semicolon or tab separating the header's
+// columns, as spreadsheets exported in some locales use semicolons
+func sniffDelimiter(content []byte) string {
+	header, _, _ := bytes.Cut(content, []byte("\n"))
+	delimiter, best := ",", bytes.Count(header, []byte(","))
+	for _, d := range []string{";", "\t"} {
+		if n := bytes.Count(header, []byte(d)); n > best {
+			delimiter, best = d, n
+		}
+	}
+	return delimiter
+}
+
+// readSheet parses a delimited file. Blank headers are named after their
+// position and repeated ones numbered, so every column can be mapped.
+func readSheet(content []byte, delimiter string) (*sheet, error) {
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comma = rune(delimiter[0])
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sheet{columns: map[string]int{}}
+	seen := map[string]int{}
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			h = fmt.Sprintf("Column %d", i+1)
+		}
+		if seen[h]++; seen[h] > 1 {
+			h = fmt.Sprintf("%s (%d)", h, seen[h])
+		}
+		s.columns[h] = len(s.headers)
+		s.headers = append(s.headers, h)
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		s.rows = append(s.rows, record)
+		s.lines = append(s.lines, line)
+	}
+	return s, nil
+}
+
+// blank reports whether every cell of record is empty
+func blank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sample returns up to n rows
+func (s *sheet) sample(n int) [][]string {
+	if len(s.rows) < n {
+		n = len(s.rows)
+	}
+	return s.rows[:n]
+}
+
+// values returns row i's cells keyed by the field they are mapped to
+func (s *sheet) values(i int, mapping map[string]string) map[string]string {
+	values := make(map[string]string, len(mapping))
+	for field, header := range mapping {
+		if j, ok := s.columns[header]; ok && j < len(s.rows[i]) {
+			values[field] = strings.TrimSpace(s.rows[i][j])
+		}
+	}
+	return values
+}
+
+// parseNumber reads a spreadsheet number, allowing currency symbols,
+// thousands separators and accounting negatives such as "(1,200.00)". When
+// both "." and "," appear the last one is the decimal point; a lone comma is
+// a decimal comma unless three digits follow it.
+func parseNumber(raw string) (float64, error) {
+	negative := strings.HasPrefix(raw, "(") && strings.HasSuffix(raw, ")")
+	var b strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9', r == '.', r == ',':
+			b.WriteRune(r)
+		case r == '-':
+			negative = !negative
+		}
+	}
+
+	s := b.String()
+	comma, dot := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case comma >= 0 && dot > comma:
+		s = strings.ReplaceAll(s, ",", "")
+	case comma >= 0 && dot >= 0:
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.Replace(s, ",", ".", 1)
+	case strings.Count(s, ",") > 1, comma >= 0 && len(s)-comma-1 == 3:
+		s = strings.ReplaceAll(s, ",", "")
+	case comma >= 0:
+		s = strings.Replace(s, ",", ".", 1)
+	case strings.Count(s, ".") > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(n, 0) {
+		return 0, errors.New("must be a number")
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+// dateLayouts are the layouts tried for each date format after ISO dates,
+// which are unambiguous and always accepted
+var dateLayouts = map[string][]string{
+	DateISO: {"2006/1/2"},
+	DateDMY: {"2/1/2006", "2-1-2006", "2.1.2006", "2/1/06"},
+	DateMDY: {"1/2/2006", "1-2-2006", "1/2/06"},
+}
+
+// parseDate reads a date in format, ignoring any time of day after it
+func parseDate(raw, format string) (time.Time, error) {
+	raw, _, _ = strings.Cut(raw, " ")
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC().Truncate(24 * time.Hour), nil
+	}
+	raw, _, _ = strings.Cut(raw, "T")
+	for _, layout := range append([]string{"2006-01-02"}, dateLayouts[format]...) {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("must be a date in %s format", format)
+}
@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// maxErrors caps the row errors kept on a job
+const maxErrors = 100
+
+// row reads one line's mapped values, collecting what is wrong with them
+type row struct {
+	line       int
+	values     map[string]string
+	dateFormat string
+	errs       []data.ImportRowError
+}
+
+// fail records a problem with field
+func (r *row) fail(field, message string) {
+	r.errs = append(r.errs, data.ImportRowError{Row: r.line, Field: field, Message: message})
+}
+
+// text returns field's value
+func (r *row) text(field string) string {
+	return r.values[field]
+}
+
+// number returns field's value as a number, or 0 when it is empty
+func (r *row) number(field string) float64 {
+	raw := r.values[field]
+	if raw == "" {
+		return 0
+	}
+	n, err := parseNumber(raw)
+	if err != nil {
+		r.fail(field, err.Error())
+	}
+	return n
+}
+
+// integer returns field's value as a whole number, or 0 when it is empty
+func (r *row) integer(field string) int {
+	n := r.number(field)
+	if n != math.Trunc(n) {
+		r.fail(field, "must be a whole number")
+	}
+	return int(n)
+}
+
+// date returns field's value as a date, or nil when it is empty
+func (r *row) date(field string) *time.Time {
+	raw := r.values[field]
+	if raw == "" {
+		return nil
+	}
+	t, err := parseDate(raw, r.dateFormat)
+	if err != nil {
+		r.fail(field, err.Error())
+		return nil
+	}
+	return &t
+}
+
+// option returns field's value spelt as one of the field's options, or
+// fallback when it is empty
+func (r *row) option(target, field, fallback string) string {
+	raw := r.values[field]
+	if raw == "" {
+		return fallback
+	}
+	f, _ := fieldNamed(target, field)
+	for _, option := range f.Options {
+		if strings.EqualFold(raw, option) {
+			return option
+		}
+	}
+	r.fail(field, "must be one of "+strings.Join(f.Options, ", "))
+	return fallback
+}
+
+// conversion is the outcome of turning a file's rows into records
+type conversion struct {
+	records    any // Slice of the target's model
+	valid      int
+	errorCount int
+	errors     []data.ImportRowError
+}
+
+// addErrors counts a row's errors, keeping the first maxErrors
+func (c *conversion) addErrors(errs []data.ImportRowError) {
+	c.errorCount += len(errs)
+	if room := maxErrors - len(c.errors); room > 0 {
+		c.errors = append(c.errors, errs[:min(room, len(errs))]...)
+	}
+}
+
+// convert turns every row of s into a record of the job's target. Rows with
+// errors are left out and their errors counted.
+func (s *importService) convert(ctx context.Context, job *data.ImportJob, sh *sheet) (*conversion, error) {
+	for _, f := range Targets[job.Target] {
+		if _, ok := job.Mapping[f.Name]; f.Required && !ok {
+			return nil, service.Invalid(fmt.Sprintf("map a column to %s first", f.Name))
+		}
+	}
+
+	var build func(r *row) (any, error)
+	var records []any
+	switch job.Target {
+	case TargetCrops:
+		fields, err := s.fields.GetByFarmID(ctx, job.FarmID)
+		if err != nil {
+			return nil, fmt.Errorf("getting fields: %w", err)
+		}
+		fieldIDs := map[string]string{}
+		for _, f := range fields {
+			fieldIDs[strings.ToLower(f.Name)] = f.FieldID
+		}
+		build = func(r *row) (any, error) { return cropRecord(job, r, fieldIDs), nil }
+	case TargetLivestock:
+		build = func(r *row) (any, error) { return livestockRecord(job, r), nil }
+	case TargetTransactions:
+		build = func(r *row) (any, error) { return s.transactionRecord(ctx, job, r) }
+	case TargetEmployees:
+		build = func(r *row) (any, error) { return employeeRecord(job, r), nil }
+	default:
+		return nil, fmt.Errorf("unknown import target %q", job.Target)
+	}
+
+	c := &conversion{}
+	for i := range sh.rows {
+		r := &row{line: sh.lines[i], values: sh.values(i, job.Mapping), dateFormat: job.DateFormat}
+		for _, f := range Targets[job.Target] {
+			if f.Required && r.text(f.Name) == "" {
+				r.fail(f.Name, "is required")
+			}
+		}
+		record, err := build(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(r.errs) > 0 {
+			c.addErrors(r.errs)
+			continue
+		}
+		records = append(records, record)
+	}
+	c.valid = len(records)
+	c.records = typed(job.Target, records)
+	return c, nil
+}
+
+// typed turns records into a slice of the target's model, as GORM needs to
+// create them in batches
+func typed(target string, records []any) any {
+	switch target {
+	case TargetCrops:
+		return collect[*data.Crop](records)
+	case TargetLivestock:
+		return collect[*data.Livestock](records)
+	case TargetTransactions:
+		return collect[*data.Transaction](records)
+	default:
+		return collect[*data.Employee](records)
+	}
+}
+
+// collect converts records to a []T
+func collect[T any](records []any) []T {
+	out := make([]T, len(records))
+	for i, record := range records {
+		out[i] = record.(T)
+	}
+	return out
+}
+
+// cropRecord builds a crop, finding its field by name
+func cropRecord(job *data.ImportJob, r *row, fieldIDs map[string]string) *data.Crop {
+	crop := &data.Crop{
+		FarmID:       job.FarmID,
+		Name:         r.text("name"),
+		PlantingDate: r.date("plantingDate"),
+		HarvestDate:  r.date("harvestDate"),
+		Quantity:     r.number("quantity"),
+		Status:       r.option(job.Target, "status", "Growing"),
+		Notes:        r.text("notes"),
+	}
+	if name := r.text("field"); name != "" {
+		if id, ok := fieldIDs[strings.ToLower(name)]; ok {
+			crop.FieldID = &id
+		} else {
+			r.fail("field", fmt.Sprintf("the farm has no field named %q", name))
+		}
+	}
+	if crop.Quantity < 0 {
+		r.fail("quantity", "must be >= 0")
+	}
+	return crop
+}
+
+// livestockRecord builds a livestock group
+func livestockRecord(job *data.ImportJob, r *row) *data.Livestock {
+	livestock := &data.Livestock{
+		FarmID:          job.FarmID,
+		Type:            r.text("type"),
+		Count:           r.integer("count"),
+		AcquisitionDate: r.date("acquisitionDate"),
+		HealthStatus:    r.option(job.Target, "healthStatus", "Healthy"),
+		Notes:           r.text("notes"),
+	}
+	if livestock.Count <= 0 && r.text("count") != "" {
+		r.fail("count", "must be greater than 0")
+	}
+	return livestock
+}
+
+// transactionRecord builds a ledger entry. Without a type column the sign
+// of the amount gives the direction, as in bank and accounting exports.
+// Entries in a locked period are rejected.
+func (s *importService) transactionRecord(ctx context.Context, job *data.ImportJob, r *row) (*data.Transaction, error) {
+	amount := r.number("amount")
+	transaction := &data.Transaction{
+		FarmID:      job.FarmID,
+		Type:        r.option(job.Target, "type", ""),
+		Category:    r.text("category"),
+		Amount:      math.Abs(amount),
+		Description: r.text("description"),
+		Notes:       r.text("notes"),
+	}
+	if transaction.Type == "" {
+		transaction.Type = "Income"
+		if amount < 0 {
+			transaction.Type = "Expense"
+		}
+	}
+	if amount == 0 && r.text("amount") != "" {
+		r.fail("amount", "must not be zero")
+	}
+
+	if date := r.date("date"); date != nil {
+		transaction.Date = *date
+		if err := s.locks.Check(ctx, job.FarmID, *date); err != nil {
+			if service.KindOf(err) != service.KindConflict {
+				return nil, err
+			}
+			r.fail("date", err.Error())
+		}
+	}
+	return transaction, nil
+}
+
+// employeeRecord builds an employee
+func employeeRecord(job *data.ImportJob, r *row) *data.Employee {
+	employee := &data.Employee{
+		FarmID:      job.FarmID,
+		FirstName:   r.text("firstName"),
+		LastName:    r.text("lastName"),
+		Position:    r.text("position"),
+		Salary:      r.number("salary"),
+		HireDate:    r.date("hireDate"),
+		ContactInfo: r.text("contactInfo"),
+		Status:      r.option(job.Target, "status", "Active"),
+	}
+	if employee.Salary < 0 {
+		r.fail("salary", "must be >= 0")
+	}
+	return employee
+}
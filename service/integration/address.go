@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"farm4u/service"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+// errPrivateAddress is returned when a delivery would connect to an address
+// on the server's own network
+var errPrivateAddress = errors.New("webhook address is not public")
+
+// reservedPrefixes are ranges that are not reachable on the internet but are
+// not covered by the netip checks in publicAddress
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),  // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"), // Benchmarking
+	netip.MustParsePrefix("64:ff9b::/96"),  // NAT64, which can reach any IPv4 address
+}
+
+// publicAddress reports whether deliveries may connect to ip: it must not
+// be loopback, private, link-local, which covers cloud metadata services
+// such as 169.254.169.254, or otherwise reserved
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkURL rejects webhook URLs deliveries cannot safely be posted to: they
+// must use https and their host must only resolve to public addresses
+func checkURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return service.Invalid("url must be an absolute URL")
+	}
+	if u.Scheme != "https" {
+		return service.Invalid("url must use https")
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return service.Invalid("url host cannot be resolved")
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return service.Invalid("url must not point at a private, loopback or link-local address")
+		}
+	}
+	return nil
+}
+
+// newDeliveryClient returns the client deliveries are posted with. Its
+// dialer checks each address again as it connects, so a host that resolved
+// to a public address when the webhook was saved cannot be pointed at the
+// internal network later. It uses no proxy, whose address would be the one
+// checked, and does not follow redirects, which count as failed attempts.
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: deliveryTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddress(addrPort.Addr()) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   deliveryTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,166 @@
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefix starts every partner API key
+	apiKeyPrefix = "fm4u_"
+	// apiKeyShownLength is how much of a key is kept to tell keys apart
+	apiKeyShownLength = len(apiKeyPrefix) + 8
+	// touchInterval is how stale a key's last used time may get before a
+	// request updates it, so busy keys do not write on every request
+	touchInterval = time.Minute
+)
+
+// API key scopes. Each lets a key read one area of the farm data; keys
+// cannot change anything.
+const (
+	ScopeFarms          = "farms:read"
+	ScopeFields         = "fields:read"
+	ScopeCrops          = "crops:read"
+	ScopeLivestock      = "livestock:read"
+	ScopeEmployees      = "employees:read"
+	ScopeFinance        = "finance:read"
+	ScopeInventory      = "inventory:read"
+	ScopeSustainability = "sustainability:read"
+)
+
+// Scopes returns the scopes an API key can be given
+func Scopes() []string {
+	return []string{
+		ScopeFarms, ScopeFields, ScopeCrops, ScopeLivestock,
+		ScopeEmployees, ScopeFinance, ScopeInventory, ScopeSustainability,
+	}
+}
+
+// APIKeyInput issues an API key
+type APIKeyInput struct {
+	Name      string
+	FarmID    string // Restricts the key to one of the user's farms if set
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreateAPIKey implements Service
+func (s *integrationService) CreateAPIKey(ctx context.Context, user *data.User, in APIKeyInput) (*data.APIKey, string, error) {
+	switch {
+	case strings.TrimSpace(in.Name) == "":
+		return nil, "", service.Invalid("name is required")
+	case len(in.Scopes) == 0:
+		return nil, "", service.Invalid("at least one scope is required")
+	case in.ExpiresAt != nil && !in.ExpiresAt.After(time.Now()):
+		return nil, "", service.Invalid("expiry must be in the future")
+	}
+	for _, scope := range in.Scopes {
+		if !slices.Contains(Scopes(), scope) {
+			return nil, "", service.Invalid(fmt.Sprintf("unknown scope %q; must be one of %s", scope, strings.Join(Scopes(), ", ")))
+		}
+	}
+
+	var farmID *string
+	if in.FarmID != "" {
+		if _, err := s.farms.Owned(ctx, user, in.FarmID); err != nil {
+			return nil, "", err
+		}
+		farmID = &in.FarmID
+	}
+
+	value, hash, err := newAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating API key: %w", err)
+	}
+	key := &data.APIKey{
+		UserID:    user.UserID,
+		FarmID:    farmID,
+		Name:      strings.TrimSpace(in.Name),
+		Prefix:    value[:apiKeyShownLength],
+		KeyHash:   hash,
+		Scopes:    slices.Compact(slices.Sorted(slices.Values(in.Scopes))),
+		ExpiresAt: in.ExpiresAt,
+	}
+	if err := s.apiKeys.Insert(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("creating API key: %w", err)
+	}
+	return key, value, nil
+}
+
+// ListAPIKeys implements Service
+func (s *integrationService) ListAPIKeys(ctx context.Context, user *data.User) ([]*data.APIKey, error) {
+	keys, err := s.apiKeys.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey implements Service
+func (s *integrationService) RevokeAPIKey(ctx context.Context, user *data.User, apiKeyID string) error {
+	key, err := s.apiKeys.GetByAPIKeyID(ctx, apiKeyID)
+	if err != nil {
+		return fmt.Errorf("getting API key: %w", err)
+	}
+	if key == nil {
+		return service.NotFound("API key not found")
+	}
+	if key.UserID != user.UserID {
+		return service.Forbidden("access denied: API key was not issued by user")
+	}
+	if err := s.apiKeys.DeleteByID(ctx, int(key.ID)); err != nil {
+		return fmt.Errorf("revoking API key: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateKey implements Service
+func (s *integrationService) AuthenticateKey(ctx context.Context, value string) (*data.APIKey, *data.User, error) {
+	key, err := s.apiKeys.GetByHash(ctx, hashAPIKey(value))
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting API key: %w", err)
+	}
+	now := time.Now()
+	if key == nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+		return nil, nil, service.Unauthorized("invalid, revoked or expired API key")
+	}
+	user, err := s.users.GetByUserID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting API key user: %w", err)
+	}
+	if user == nil || !user.Active {
+		return nil, nil, service.Unauthorized("invalid, revoked or expired API key")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > touchInterval {
+		if err := s.apiKeys.Touch(ctx, key.APIKeyID, now); err != nil {
+			return nil, nil, fmt.Errorf("recording API key use: %w", err)
+		}
+		key.LastUsedAt = &now
+	}
+	return key, user, nil
+}
+
+// newAPIKey generates a random API key and returns it with its hash
+func newAPIKey() (string, string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(b)
+	return key, hashAPIKey(key), nil
+}
+
+// hashAPIKey returns the hex SHA-256 of an API key, as stored
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
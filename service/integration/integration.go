@@ -0,0 +1,593 @@
+// Package integration connects partner systems, such as a co-op's or an
+// insurer's, to a farm. Partners subscribe webhooks to the farm's events and
+// are sent each one as a signed JSON POST, or several at once as a digest,
+// which is retried until it is accepted or runs out of attempts, with every
+// attempt logged. Deliveries a receiver missed can be replayed. Farmers can
+// also issue partners read-only API keys to pull their farm data with.
+package integration
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/webhook"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// secretPrefix starts every webhook signing secret
+	secretPrefix = "whsec_"
+	// maxAttempts is how many times a delivery is posted before it is
+	// given up as Failed
+	maxAttempts = 6
+	// deliveryTimeout bounds each attempt, after which it counts as failed
+	deliveryTimeout = 10 * time.Second
+	// deliveryBatch is how many due deliveries are posted per run
+	deliveryBatch = 100
+	// deliveryLogSize is how many recent deliveries a webhook's log shows
+	deliveryLogSize = 100
+	// maxDigestSize is the most events a digest holds
+	maxDigestSize = deliveryBatch
+	// maxDigestMinutes is the longest a digest interval may be
+	maxDigestMinutes = 24 * 60
+	// maxReplay is the most deliveries one replay queues
+	maxReplay = 1000
+	// rotationWindow is how long a rotated-out secret keeps signing
+	// deliveries alongside the new one
+	rotationWindow = 24 * time.Hour
+)
+
+// retryDelays is how long to wait after each failed attempt before the next
+var retryDelays = []time.Duration{
+	time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 12 * time.Hour,
+}
+
+// events are the event types webhooks can subscribe to
+var events = []string{
+	"crop.created", "crop.updated", "crop.deleted", "crop.restored",
+	"livestock.created", "livestock.updated", "livestock.deleted", "livestock.restored",
+	"employee.created", "employee.updated", "employee.deleted", "employee.restored",
+	"transaction.created", "transaction.updated", "transaction.deleted",
+	"sale.recorded",
+}
+
+// Events returns the event types webhooks can subscribe to. A webhook may
+// also subscribe to * for all of them.
+func Events() []string {
+	return slices.Clone(events)
+}
+
+// WebhookInput holds the editable webhook fields. On update, zero values are
+// left unchanged.
+type WebhookInput struct {
+	URL           string
+	Description   string
+	EventTypes    []string
+	DigestSize    *int
+	DigestMinutes *int
+	Active        *bool
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	Event      string    `json:"event"`
+	FarmID     string    `json:"farmId"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Data       any       `json:"data"` // The record the event is about
+}
+
+// DigestEntry is one event in a digest, the JSON array of events posted at
+// once to a webhook with a digest size or interval
+type DigestEntry struct {
+	DeliveryID string          `json:"deliveryId"` // Kept on retries, so receivers can skip events already processed
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// Service is the integration domain service
+type Service interface {
+	// CreateWebhook subscribes a webhook to events on one of the user's
+	// farms and returns its signing secret, which is not shown again
+	CreateWebhook(ctx context.Context, user *data.User, farmID string, in WebhookInput) (*data.Webhook, string, error)
+	GetWebhook(ctx context.Context, user *data.User, webhookID string) (*data.Webhook, error)
+	ListWebhooks(ctx context.Context, user *data.User, farmID string) ([]*data.Webhook, error)
+	UpdateWebhook(ctx context.Context, user *data.User, webhookID string, in WebhookInput) (*data.Webhook, error)
+	DeleteWebhook(ctx context.Context, user *data.User, webhookID string) error
+	// RotateSecret gives a webhook a new signing secret and returns it, which
+	// is not shown again. The old secret signs deliveries alongside it for a
+	// day, so the receiver can switch over without rejecting any.
+	RotateSecret(ctx context.Context, user *data.User, webhookID string) (*data.Webhook, string, error)
+	// ListDeliveries returns a webhook's most recent deliveries
+	ListDeliveries(ctx context.Context, user *data.User, webhookID string) ([]*data.WebhookDelivery, error)
+	// ReplayDeliveries queues again the events a webhook on the farm was sent
+	// from from up to but excluding to, as new deliveries, and returns how
+	// many it queued
+	ReplayDeliveries(ctx context.Context, user *data.User, farmID, webhookID string, from, to time.Time) (int, error)
+
+	// Publish queues an event on a farm for each of its active webhooks
+	// subscribed to it. It does not post anything itself.
+	Publish(ctx context.Context, farmID, event string, record any) error
+	// DeliverDue posts the deliveries due now, those of a digest webhook
+	// batched into digests, and returns how many were accepted
+	DeliverDue(ctx context.Context) (int, error)
+
+	// CreateAPIKey issues a read-only API key acting as user and returns
+	// it, which is not shown again
+	CreateAPIKey(ctx context.Context, user *data.User, in APIKeyInput) (*data.APIKey, string, error)
+	// ListAPIKeys returns the keys user has issued and not revoked
+	ListAPIKeys(ctx context.Context, user *data.User) ([]*data.APIKey, error)
+	RevokeAPIKey(ctx context.Context, user *data.User, apiKeyID string) error
+	// AuthenticateKey returns the unexpired, unrevoked key with the given
+	// value and the active user it acts as
+	AuthenticateKey(ctx context.Context, value string) (*data.APIKey, *data.User, error)
+}
+
+// integrationService implements Service on top of the webhook, webhook
+// delivery, API key and user repositories
+type integrationService struct {
+	webhooks   data.WebhookInterface
+	deliveries data.WebhookDeliveryInterface
+	apiKeys    data.APIKeyInterface
+	users      data.UserInterface
+	farms      farm.Service
+	client     *http.Client
+}
+
+// New creates the integration service
+func New(webhooks data.WebhookInterface, deliveries data.WebhookDeliveryInterface, apiKeys data.APIKeyInterface,
+	users data.UserInterface, farms farm.Service) Service {
+	return &integrationService{
+		webhooks:   webhooks,
+		deliveries: deliveries,
+		apiKeys:    apiKeys,
+		users:      users,
+		farms:      farms,
+		client:     newDeliveryClient(),
+	}
+}
+
+// CreateWebhook implements Service
+func (s *integrationService) CreateWebhook(ctx context.Context, user *data.User, farmID string, in WebhookInput) (*data.Webhook, string, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, "", err
+	}
+	if err := checkURL(ctx, in.URL); err != nil {
+		return nil, "", err
+	}
+	if err := checkEvents(in.EventTypes); err != nil {
+		return nil, "", err
+	}
+	if len(in.EventTypes) == 0 {
+		return nil, "", service.Invalid("at least one event type is required")
+	}
+	if err := checkDigest(in); err != nil {
+		return nil, "", err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	hook := &data.Webhook{
+		FarmID:      farmID,
+		URL:         in.URL,
+		Description: in.Description,
+		Secret:      secret,
+		EventTypes:  in.EventTypes,
+		Active:      in.Active == nil || *in.Active,
+		CreatedBy:   user.UserID,
+	}
+	if in.DigestSize != nil {
+		hook.DigestSize = *in.DigestSize
+	}
+	if in.DigestMinutes != nil {
+		hook.DigestMinutes = *in.DigestMinutes
+	}
+	if err := s.webhooks.Insert(ctx, hook); err != nil {
+		return nil, "", fmt.Errorf("creating webhook: %w", err)
+	}
+	return hook, secret, nil
+}
+
+// GetWebhook returns a webhook on one of the user's farms
+func (s *integrationService) GetWebhook(ctx context.Context, user *data.User, webhookID string) (*data.Webhook, error) {
+	hook, err := s.webhooks.GetByWebhookID(ctx, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook: %w", err)
+	}
+	if hook == nil {
+		return nil, service.NotFound("webhook not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, hook.FarmID, "webhook"); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// ListWebhooks returns the webhooks on one of the user's farms
+func (s *integrationService) ListWebhooks(ctx context.Context, user *data.User, farmID string) ([]*data.Webhook, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	hooks, err := s.webhooks.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// UpdateWebhook changes a webhook's URL, description, events, digest or
+// whether it is active
+func (s *integrationService) UpdateWebhook(ctx context.Context, user *data.User, webhookID string, in WebhookInput) (*data.Webhook, error) {
+	hook, err := s.GetWebhook(ctx, user, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if in.URL != "" {
+		if err := checkURL(ctx, in.URL); err != nil {
+			return nil, err
+		}
+		hook.URL = in.URL
+	}
+	if in.Description != "" {
+		hook.Description = in.Description
+	}
+	if len(in.EventTypes) > 0 {
+		if err := checkEvents(in.EventTypes); err != nil {
+			return nil, err
+		}
+		hook.EventTypes = in.EventTypes
+	}
+	if err := checkDigest(in); err != nil {
+		return nil, err
+	}
+	if in.DigestSize != nil {
+		hook.DigestSize = *in.DigestSize
+	}
+	if in.DigestMinutes != nil {
+		hook.DigestMinutes = *in.DigestMinutes
+	}
+	if in.Active != nil {
+		hook.Active = *in.Active
+	}
+	if err := s.webhooks.Update(ctx, hook); err != nil {
+		return nil, fmt.Errorf("updating webhook: %w", err)
+	}
+	return hook, nil
+}
+
+// DeleteWebhook removes a webhook. Its pending deliveries are dropped.
+func (s *integrationService) DeleteWebhook(ctx context.Context, user *data.User, webhookID string) error {
+	hook, err := s.GetWebhook(ctx, user, webhookID)
+	if err != nil {
+		return err
+	}
+	if err := s.webhooks.DeleteByID(ctx, int(hook.ID)); err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+	return nil
+}
+
+// RotateSecret implements Service
+func (s *integrationService) RotateSecret(ctx context.Context, user *data.User, webhookID string) (*data.Webhook, string, error) {
+	hook, err := s.GetWebhook(ctx, user, webhookID)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	expires := time.Now().Add(rotationWindow)
+	hook.PreviousSecret, hook.PreviousSecretExpiresAt = hook.Secret, &expires
+	hook.Secret = secret
+	if err := s.webhooks.Update(ctx, hook); err != nil {
+		return nil, "", fmt.Errorf("updating webhook: %w", err)
+	}
+	return hook, secret, nil
+}
+
+// ListDeliveries implements Service
+func (s *integrationService) ListDeliveries(ctx context.Context, user *data.User, webhookID string) ([]*data.WebhookDelivery, error) {
+	hook, err := s.GetWebhook(ctx, user, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := s.deliveries.GetByWebhookID(ctx, hook.WebhookID, deliveryLogSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// ReplayDeliveries implements Service. Deliveries still pending are left
+// out, as they will be sent anyway.
+func (s *integrationService) ReplayDeliveries(ctx context.Context, user *data.User, farmID, webhookID string, from, to time.Time) (int, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return 0, err
+	}
+	hook, err := s.GetWebhook(ctx, user, webhookID)
+	if err != nil {
+		return 0, err
+	}
+	if hook.FarmID != farmID {
+		return 0, service.NotFound("webhook not found")
+	}
+	if !hook.Active {
+		return 0, service.Conflict("webhook is inactive; activate it to replay deliveries")
+	}
+
+	stored, err := s.deliveries.GetBetween(ctx, hook.WebhookID, from, to, maxReplay+1)
+	if err != nil {
+		return 0, fmt.Errorf("getting webhook deliveries: %w", err)
+	}
+	if len(stored) > maxReplay {
+		return 0, service.Invalid(fmt.Sprintf("more than %d deliveries to replay; choose a shorter window", maxReplay))
+	}
+
+	next := firstAttempt(hook, time.Now())
+	var replays []*data.WebhookDelivery
+	for _, delivery := range stored {
+		if delivery.Status == "Pending" {
+			continue
+		}
+		replays = append(replays, &data.WebhookDelivery{
+			WebhookID:     hook.WebhookID,
+			FarmID:        hook.FarmID,
+			Event:         delivery.Event,
+			Payload:       delivery.Payload,
+			Status:        "Pending",
+			NextAttemptAt: &next,
+		})
+	}
+	if len(replays) == 0 {
+		return 0, nil
+	}
+	if err := s.deliveries.InsertMany(ctx, replays); err != nil {
+		return 0, fmt.Errorf("queuing webhook deliveries: %w", err)
+	}
+	return len(replays), nil
+}
+
+// Publish implements Service
+func (s *integrationService) Publish(ctx context.Context, farmID, event string, record any) error {
+	hooks, err := s.webhooks.GetActiveByFarmID(ctx, farmID)
+	if err != nil {
+		return fmt.Errorf("getting webhooks: %w", err)
+	}
+	hooks = slices.DeleteFunc(hooks, func(h *data.Webhook) bool { return !h.Subscribes(event) })
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	body, err := json.Marshal(Payload{Event: event, FarmID: farmID, OccurredAt: now, Data: record})
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+	deliveries := make([]*data.WebhookDelivery, len(hooks))
+	for i, hook := range hooks {
+		next := firstAttempt(hook, now)
+		deliveries[i] = &data.WebhookDelivery{
+			WebhookID:     hook.WebhookID,
+			FarmID:        farmID,
+			Event:         event,
+			Payload:       string(body),
+			Status:        "Pending",
+			NextAttemptAt: &next,
+		}
+	}
+	if err := s.deliveries.InsertMany(ctx, deliveries); err != nil {
+		return fmt.Errorf("queuing webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// DeliverDue implements Service
+func (s *integrationService) DeliverDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := s.deliveries.GetDue(ctx, now, deliveryBatch)
+	if err != nil {
+		return 0, fmt.Errorf("getting due webhook deliveries: %w", err)
+	}
+
+	delivered := 0
+	for _, batch := range batches(due) {
+		if ctx.Err() != nil {
+			break
+		}
+		// Hold the deliveries for longer than an attempt can take, so no
+		// other worker picks them up meanwhile
+		var claimed []*data.WebhookDelivery
+		for _, delivery := range batch {
+			ok, err := s.deliveries.Claim(ctx, delivery, now.Add(2*deliveryTimeout))
+			if err != nil {
+				return delivered, fmt.Errorf("claiming webhook delivery: %w", err)
+			}
+			if ok {
+				claimed = append(claimed, delivery)
+			}
+		}
+		if len(claimed) == 0 {
+			continue
+		}
+		delivered += s.attempt(ctx, claimed)
+		for _, delivery := range claimed {
+			if err := s.deliveries.Finish(ctx, delivery); err != nil {
+				return delivered, fmt.Errorf("recording webhook delivery: %w", err)
+			}
+		}
+	}
+	return delivered, nil
+}
+
+// batches splits due deliveries into those posted together: a digest
+// webhook's in digests of up to its digest size, in order, and every other
+// delivery on its own
+func batches(due []*data.WebhookDelivery) [][]*data.WebhookDelivery {
+	var out [][]*data.WebhookDelivery
+	filling := map[string]int{} // Webhook ID to the index of its last digest
+	for _, delivery := range due {
+		hook := delivery.Webhook
+		if hook == nil || !hook.Digests() {
+			out = append(out, []*data.WebhookDelivery{delivery})
+			continue
+		}
+		if i, ok := filling[hook.WebhookID]; ok && len(out[i]) < digestSize(hook) {
+			out[i] = append(out[i], delivery)
+			continue
+		}
+		filling[hook.WebhookID] = len(out)
+		out = append(out, []*data.WebhookDelivery{delivery})
+	}
+	return out
+}
+
+// digestSize returns the most events a digest to hook holds
+func digestSize(hook *data.Webhook) int {
+	if hook.DigestSize > 0 {
+		return hook.DigestSize
+	}
+	return maxDigestSize
+}
+
+// firstAttempt returns when an event published at now is first posted to
+// hook: straight away, or with a digest interval at the end of the interval
+// now falls in, so that interval's events go out together
+func firstAttempt(hook *data.Webhook, now time.Time) time.Time {
+	if hook.DigestMinutes <= 0 {
+		return now
+	}
+	interval := time.Duration(hook.DigestMinutes) * time.Minute
+	return now.Truncate(interval).Add(interval)
+}
+
+// attempt posts a batch of deliveries to their webhook once and records the
+// outcome on each, scheduling the next attempt if it failed and attempts
+// remain. It returns how many were delivered.
+func (s *integrationService) attempt(ctx context.Context, batch []*data.WebhookDelivery) int {
+	now := time.Now()
+	hook := batch[0].Webhook
+	status, err := 0, errors.New("webhook was deleted or deactivated")
+	if hook != nil && hook.Active {
+		status, err = s.post(ctx, hook, batch, now)
+	}
+
+	for _, delivery := range batch {
+		delivery.Attempts++
+		delivery.LastAttemptAt = &now
+		delivery.ResponseStatus = status
+		if err == nil {
+			delivery.Status, delivery.NextAttemptAt, delivery.DeliveredAt = "Delivered", nil, &now
+			delivery.Error = ""
+			continue
+		}
+
+		delivery.Error = err.Error()
+		if delivery.Attempts >= maxAttempts || hook == nil || !hook.Active {
+			delivery.Status, delivery.NextAttemptAt = "Failed", nil
+			continue
+		}
+		next := now.Add(retryDelays[min(delivery.Attempts, len(retryDelays))-1])
+		delivery.NextAttemptAt = &next
+	}
+	if err != nil {
+		return 0
+	}
+	return len(batch)
+}
+
+// post sends a batch of deliveries to their webhook, a single delivery's
+// payload as it is and a digest webhook's as a JSON array of DigestEntry,
+// and returns the response status. The body is signed with the webhook's
+// secret, and while a secret is being rotated with the old one too. Any
+// status outside 2xx is an error.
+func (s *integrationService) post(ctx context.Context, hook *data.Webhook, batch []*data.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(batch[0].Payload)
+	if hook.Digests() {
+		entries := make([]DigestEntry, len(batch))
+		for i, delivery := range batch {
+			entries[i] = DigestEntry{DeliveryID: delivery.WebhookDeliveryID, Payload: json.RawMessage(delivery.Payload)}
+		}
+		var err error
+		if body, err = json.Marshal(entries); err != nil {
+			return 0, fmt.Errorf("encoding webhook digest: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "farm4u-webhooks")
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(body, now, signingSecrets(hook, now)...))
+	if hook.Digests() {
+		req.Header.Set(webhook.EventHeader, "digest")
+	} else {
+		req.Header.Set(webhook.EventHeader, batch[0].Event)
+		req.Header.Set(webhook.DeliveryHeader, batch[0].WebhookDeliveryID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// signingSecrets returns the secrets hook's deliveries are signed with at
+// now: its secret, after the one it replaced until that one's rotation
+// window ends
+func signingSecrets(hook *data.Webhook, now time.Time) []string {
+	if hook.PreviousSecret != "" && hook.PreviousSecretExpiresAt != nil && now.Before(*hook.PreviousSecretExpiresAt) {
+		return []string{hook.PreviousSecret, hook.Secret}
+	}
+	return []string{hook.Secret}
+}
+
+// checkEvents rejects event types webhooks cannot subscribe to
+func checkEvents(types []string) error {
+	for _, t := range types {
+		if t != "*" && !slices.Contains(events, t) {
+			return service.Invalid(fmt.Sprintf("unknown event type %q; must be * or one of %s", t, strings.Join(events, ", ")))
+		}
+	}
+	return nil
+}
+
+// checkDigest rejects a digest size or interval out of range
+func checkDigest(in WebhookInput) error {
+	if in.DigestSize != nil && (*in.DigestSize < 0 || *in.DigestSize > maxDigestSize) {
+		return service.Invalid(fmt.Sprintf("digest size must be between 0 and %d", maxDigestSize))
+	}
+	if in.DigestMinutes != nil && (*in.DigestMinutes < 0 || *in.DigestMinutes > maxDigestMinutes) {
+		return service.Invalid(fmt.Sprintf("digest interval must be between 0 and %d minutes", maxDigestMinutes))
+	}
+	return nil
+}
+
+// newSecret generates a random webhook signing secret
+func newSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"farm4u/data"
+	"farm4u/webhook"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBatches(t *testing.T) {
+	single := &data.Webhook{WebhookID: "single"}
+	pairs := &data.Webhook{WebhookID: "pairs", DigestSize: 2}
+	hourly := &data.Webhook{WebhookID: "hourly", DigestMinutes: 60}
+	delivery := func(id string, hook *data.Webhook) *data.WebhookDelivery {
+		return &data.WebhookDelivery{WebhookDeliveryID: id, Webhook: hook}
+	}
+
+	due := []*data.WebhookDelivery{
+		delivery("s1", single), delivery("p1", pairs), delivery("h1", hourly), delivery("s2", single),
+		delivery("p2", pairs), delivery("p3", pairs), delivery("h2", hourly), delivery("gone", nil),
+	}
+	var got [][]string
+	for _, batch := range batches(due) {
+		var ids []string
+		for _, d := range batch {
+			ids = append(ids, d.WebhookDeliveryID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]string{{"s1"}, {"p1", "p2"}, {"h1", "h2"}, {"s2"}, {"p3"}, {"gone"}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("batches = %v, want %v", got, want)
+	}
+}
+
+func TestFirstAttempt(t *testing.T) {
+	now := time.Date(2026, time.March, 1, 10, 7, 30, 0, time.UTC)
+	tests := []struct {
+		minutes int
+		want    time.Time
+	}{
+		{minutes: 0, want: now},
+		{minutes: 15, want: time.Date(2026, time.March, 1, 10, 15, 0, 0, time.UTC)},
+		{minutes: 60, want: time.Date(2026, time.March, 1, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := firstAttempt(&data.Webhook{DigestMinutes: tt.minutes}, now); !got.Equal(tt.want) {
+			t.Errorf("%d minutes: first attempt %s, want %s", tt.minutes, got, tt.want)
+		}
+	}
+}
+
+func TestSigningSecrets(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	tests := []struct {
+		name string
+		hook data.Webhook
+		want []string
+	}{
+		{name: "never rotated", hook: data.Webhook{Secret: "new"}, want: []string{"new"}},
+		{name: "rotating", hook: data.Webhook{Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: &later}, want: []string{"old", "new"}},
+		{name: "rotated", hook: data.Webhook{Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: &earlier}, want: []string{"new"}},
+	}
+
+	for _, tt := range tests {
+		if got := signingSecrets(&tt.hook, now); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: secrets %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestPostDigest posts a digest and checks the receiver can verify it with
+// either secret while one is rotated
+func TestPostDigest(t *testing.T) {
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	hook := &data.Webhook{URL: srv.URL, Secret: "new", PreviousSecret: "old", PreviousSecretExpiresAt: &expires, DigestSize: 10}
+	batch := []*data.WebhookDelivery{
+		{WebhookDeliveryID: "d1", Payload: `{"event":"crop.created"}`},
+		{WebhookDeliveryID: "d2", Payload: `{"event":"crop.updated"}`},
+	}
+	s := &integrationService{client: srv.Client()}
+	if _, err := s.post(context.Background(), hook, batch, now); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []DigestEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		t.Fatalf("digest %s: %v", body, err)
+	}
+	if len(entries) != 2 || entries[0].DeliveryID != "d1" || string(entries[1].Payload) != `{"event":"crop.updated"}` {
+		t.Errorf("digest %s", body)
+	}
+	if event := header.Get(webhook.EventHeader); event != "digest" {
+		t.Errorf("event header %q, want digest", event)
+	}
+	for _, secret := range []string{"old", "new"} {
+		if err := webhook.Verify(header.Get(webhook.SignatureHeader), body, secret, 0, now); err != nil {
+			t.Errorf("verifying with %s secret: %v", secret, err)
+		}
+	}
+}
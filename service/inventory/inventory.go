@@ -0,0 +1,339 @@
+// Package inventory keeps a farm's stock of feed, seed, fertilizer, drugs and
+// fuel. Stock is received in batches and drawn first-expired-first-out
+// (FEFO) unless a batch is chosen.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"time"
+)
+
+// Input holds the editable inventory item fields. On update, zero values are
+// left unchanged.
+type Input struct {
+	Name            string
+	Category        string
+	Unit            string
+	NitrogenPercent float64
+	ReorderLevel    *float64
+	Notes           string
+}
+
+// BatchInput holds the fields of a batch received into stock
+type BatchInput struct {
+	BatchNumber  string
+	Quantity     float64
+	UnitCost     float64
+	ReceivedDate *time.Time // Defaults to now
+	ExpiryDate   *time.Time
+	Notes        string
+}
+
+// ConsumeInput holds the fields of stock taken out of inventory
+type ConsumeInput struct {
+	Quantity         float64
+	InventoryBatchID string     // Optional; FEFO allocation is used when empty
+	Date             *time.Time // Defaults to now
+	Purpose          string
+	Notes            string
+}
+
+// Consumption is stock taken out by Consume. When the chosen batch had
+// expired, or another expires sooner, Warning says so and Suggestion holds the
+// FEFO allocation that should have been used.
+type Consumption struct {
+	Movements  []*data.InventoryMovement
+	Warning    string
+	Suggestion []data.BatchAllocation
+}
+
+// Service is the inventory domain service
+type Service interface {
+	// Create adds an item to one of the user's farms, defaulting to the Other
+	// category
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.InventoryItem, error)
+	Get(ctx context.Context, user *data.User, inventoryItemID string) (*data.InventoryItem, error)
+	// List returns a farm's items, optionally of one category, each with its
+	// quantity on hand
+	List(ctx context.Context, user *data.User, farmID, category string) ([]*data.InventoryItem, error)
+	Update(ctx context.Context, user *data.User, inventoryItemID string, in Input) (*data.InventoryItem, error)
+	Delete(ctx context.Context, user *data.User, inventoryItemID string) error
+	// LowStock lists a farm's items whose stock has fallen below their
+	// reorder level
+	LowStock(ctx context.Context, user *data.User, farmID string) ([]*data.InventoryItem, error)
+
+	Receive(ctx context.Context, user *data.User, inventoryItemID string, in BatchInput) (*data.InventoryBatch, error)
+	// Batches returns an item with its batches in FEFO order, its quantity on
+	// hand summed from them
+	Batches(ctx context.Context, user *data.User, inventoryItemID string) (*data.InventoryItem, []*data.InventoryBatch, error)
+	// Suggest allocates quantity of an item to its unexpired batches, FEFO.
+	// shortfall is what unexpired stock cannot cover.
+	Suggest(ctx context.Context, user *data.User, inventoryItemID string, quantity float64) (item *data.InventoryItem, suggestion []data.BatchAllocation, shortfall float64, err error)
+	Consume(ctx context.Context, user *data.User, inventoryItemID string, in ConsumeInput) (*Consumption, error)
+	// Expiring lists a farm's batches that expire before the given time,
+	// including those already expired
+	Expiring(ctx context.Context, user *data.User, farmID string, before time.Time) ([]*data.InventoryBatch, error)
+}
+
+// inventoryService implements Service on top of the inventory repositories
+type inventoryService struct {
+	items   data.InventoryItemInterface
+	batches data.InventoryBatchInterface
+	farms   farm.Service
+}
+
+// New creates the inventory service
+func New(items data.InventoryItemInterface, batches data.InventoryBatchInterface, farms farm.Service) Service {
+	return &inventoryService{items: items, batches: batches, farms: farms}
+}
+
+// Create implements Service
+func (s *inventoryService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.InventoryItem, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	if in.Category == "" {
+		in.Category = "Other"
+	}
+
+	item := &data.InventoryItem{
+		FarmID:          farmID,
+		Name:            in.Name,
+		Category:        in.Category,
+		Unit:            in.Unit,
+		NitrogenPercent: in.NitrogenPercent,
+		Notes:           in.Notes,
+	}
+	if in.ReorderLevel != nil {
+		item.ReorderLevel = *in.ReorderLevel
+	}
+
+	if err := s.items.Insert(ctx, item); err != nil {
+		return nil, fmt.Errorf("creating inventory item: %w", err)
+	}
+	return item, nil
+}
+
+// Get returns an inventory item on one of the user's farms
+func (s *inventoryService) Get(ctx context.Context, user *data.User, inventoryItemID string) (*data.InventoryItem, error) {
+	item, err := s.items.GetByInventoryItemID(ctx, inventoryItemID)
+	if err != nil {
+		return nil, fmt.Errorf("getting inventory item: %w", err)
+	}
+	if item == nil {
+		return nil, service.NotFound("inventory item not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, item.FarmID, "inventory item"); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// List implements Service
+func (s *inventoryService) List(ctx context.Context, user *data.User, farmID, category string) ([]*data.InventoryItem, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	var items []*data.InventoryItem
+	var err error
+	if category != "" {
+		items, err = s.items.GetByCategory(ctx, farmID, category)
+	} else {
+		items, err = s.items.GetByFarmID(ctx, farmID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting inventory items: %w", err)
+	}
+
+	totals, err := s.batches.QuantityByItem(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting inventory totals: %w", err)
+	}
+	for _, item := range items {
+		item.QuantityOnHand = totals[item.InventoryItemID]
+	}
+	return items, nil
+}
+
+// Update changes the non-zero fields of in on an inventory item
+func (s *inventoryService) Update(ctx context.Context, user *data.User, inventoryItemID string, in Input) (*data.InventoryItem, error) {
+	item, err := s.Get(ctx, user, inventoryItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		item.Name = in.Name
+	}
+	if in.Category != "" {
+		item.Category = in.Category
+	}
+	if in.Unit != "" {
+		item.Unit = in.Unit
+	}
+	if in.NitrogenPercent > 0 {
+		item.NitrogenPercent = in.NitrogenPercent
+	}
+	if in.ReorderLevel != nil {
+		item.ReorderLevel = *in.ReorderLevel
+	}
+	if in.Notes != "" {
+		item.Notes = in.Notes
+	}
+
+	if err := s.items.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("updating inventory item: %w", err)
+	}
+	return item, nil
+}
+
+// Delete soft deletes an inventory item
+func (s *inventoryService) Delete(ctx context.Context, user *data.User, inventoryItemID string) error {
+	item, err := s.Get(ctx, user, inventoryItemID)
+	if err != nil {
+		return err
+	}
+	if err := s.items.DeleteByID(ctx, int(item.ID)); err != nil {
+		return fmt.Errorf("deleting inventory item: %w", err)
+	}
+	return nil
+}
+
+// LowStock implements Service
+func (s *inventoryService) LowStock(ctx context.Context, user *data.User, farmID string) ([]*data.InventoryItem, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	items, err := s.items.GetLowStock(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting low-stock inventory: %w", err)
+	}
+	return items, nil
+}
+
+// Receive adds a batch of an item to stock
+func (s *inventoryService) Receive(ctx context.Context, user *data.User, inventoryItemID string, in BatchInput) (*data.InventoryBatch, error) {
+	item, err := s.Get(ctx, user, inventoryItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	receivedDate := time.Now()
+	if in.ReceivedDate != nil {
+		receivedDate = *in.ReceivedDate
+	}
+
+	batch := &data.InventoryBatch{
+		InventoryItemID: item.InventoryItemID,
+		FarmID:          item.FarmID,
+		BatchNumber:     in.BatchNumber,
+		Quantity:        in.Quantity,
+		InitialQuantity: in.Quantity,
+		UnitCost:        in.UnitCost,
+		ReceivedDate:    receivedDate,
+		ExpiryDate:      in.ExpiryDate,
+		Notes:           in.Notes,
+	}
+	if err := s.batches.Receive(ctx, batch); err != nil {
+		return nil, fmt.Errorf("receiving inventory batch: %w", err)
+	}
+	return batch, nil
+}
+
+// Batches implements Service
+func (s *inventoryService) Batches(ctx context.Context, user *data.User, inventoryItemID string) (*data.InventoryItem, []*data.InventoryBatch, error) {
+	item, err := s.Get(ctx, user, inventoryItemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	batches, err := s.batches.GetByInventoryItemID(ctx, item.InventoryItemID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting inventory batches: %w", err)
+	}
+	for _, batch := range batches {
+		item.QuantityOnHand += batch.Quantity
+	}
+	return item, batches, nil
+}
+
+// Suggest implements Service
+func (s *inventoryService) Suggest(ctx context.Context, user *data.User, inventoryItemID string, quantity float64) (*data.InventoryItem, []data.BatchAllocation, float64, error) {
+	item, err := s.Get(ctx, user, inventoryItemID)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	batches, err := s.batches.GetAvailable(ctx, item.InventoryItemID)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("getting inventory batches: %w", err)
+	}
+	suggestion, shortfall := data.AllocateFEFO(batches, quantity, time.Now())
+	return item, suggestion, shortfall, nil
+}
+
+// Consume takes stock of an item out of inventory, from the chosen batch or
+// FEFO
+func (s *inventoryService) Consume(ctx context.Context, user *data.User, inventoryItemID string, in ConsumeInput) (*Consumption, error) {
+	item, err := s.Get(ctx, user, inventoryItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+
+	batches, err := s.batches.GetAvailable(ctx, item.InventoryItemID)
+	if err != nil {
+		return nil, fmt.Errorf("getting inventory batches: %w", err)
+	}
+	suggestion, _ := data.AllocateFEFO(batches, in.Quantity, date)
+
+	consumption := &Consumption{}
+	if in.InventoryBatchID != "" {
+		batch, err := s.batches.GetByInventoryBatchID(ctx, in.InventoryBatchID)
+		if err != nil {
+			return nil, fmt.Errorf("getting inventory batch: %w", err)
+		}
+		if batch == nil || batch.InventoryItemID != item.InventoryItemID {
+			return nil, service.Invalid("batch not found for this item")
+		}
+
+		if batch.IsExpired(date) {
+			consumption.Warning = fmt.Sprintf("batch %s expired on %s", batch.BatchNumber, batch.ExpiryDate.Format("2006-01-02"))
+		} else if len(suggestion) > 0 && suggestion[0].InventoryBatchID != batch.InventoryBatchID {
+			consumption.Warning = fmt.Sprintf("batch %s expires sooner and should be used first", suggestion[0].BatchNumber)
+		}
+		if consumption.Warning != "" {
+			consumption.Suggestion = suggestion
+		}
+	}
+
+	consumption.Movements, err = s.batches.Consume(ctx, item.InventoryItemID, in.InventoryBatchID, in.Quantity, date, in.Purpose, in.Notes)
+	if errors.Is(err, data.ErrInsufficientStock) {
+		return nil, service.Invalid(fmt.Sprintf("not enough unexpired %s in stock", item.Name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("consuming inventory: %w", err)
+	}
+	return consumption, nil
+}
+
+// Expiring implements Service
+func (s *inventoryService) Expiring(ctx context.Context, user *data.User, farmID string, before time.Time) ([]*data.InventoryBatch, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	batches, err := s.batches.GetExpiring(ctx, farmID, before)
+	if err != nil {
+		return nil, fmt.Errorf("getting expiring inventory: %w", err)
+	}
+	return batches, nil
+}
@@ -0,0 +1,363 @@
+// Package irrigation manages a farm's recurring irrigation schedules and the
+// view of upcoming irrigations, which checks the rain forecast and suggests
+// skipping a day when enough rain is expected.
+package irrigation
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/weather"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// SkipRainMM is the forecast rainfall, in millimetres, from which skipping
+// an irrigation is suggested
+const SkipRainMM = 5.0
+
+// forecastTimeout bounds the forecast lookup of the upcoming view
+const forecastTimeout = 10 * time.Second
+
+// Input holds the editable schedule fields. On update, zero values are left
+// unchanged; an empty FieldID, CropID or WaterSourceID string clears it.
+type Input struct {
+	FieldID         *string
+	CropID          *string
+	WaterSourceID   *string
+	Method          string
+	StartDate       *time.Time
+	EndDate         *time.Time
+	IntervalDays    int
+	StartTime       string
+	DurationMinutes int
+	Volume          float64
+	Status          string
+	Notes           string
+}
+
+// Session is one upcoming irrigation
+type Session struct {
+	IrrigationID    string    `json:"irrigationId"`
+	Date            time.Time `json:"date"`
+	StartTime       string    `json:"startTime"`
+	FieldName       string    `json:"fieldName,omitempty"`
+	CropName        string    `json:"cropName,omitempty"`
+	Method          string    `json:"method"`
+	DurationMinutes int       `json:"durationMinutes"`
+	Volume          float64   `json:"volume"`
+	RainMM          *float64  `json:"rainMm,omitempty"`     // Forecast rain on the day, when known
+	RainChance      *int      `json:"rainChance,omitempty"` // Forecast chance of rain on the day, when known
+	SkipSuggested   bool      `json:"skipSuggested"`
+}
+
+// Upcoming lists a farm's irrigations over the next days. Forecast explains
+// why there are no skip suggestions when the forecast could not be had.
+type Upcoming struct {
+	FarmID   string    `json:"farmId"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Forecast string    `json:"forecast"` // Provider used, or why no forecast is shown
+	Sessions []Session `json:"sessions"`
+}
+
+// Service is the irrigation domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.IrrigationSchedule, error)
+	Get(ctx context.Context, user *data.User, irrigationID string) (*data.IrrigationSchedule, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.IrrigationSchedule, error)
+	Update(ctx context.Context, user *data.User, irrigationID string, in Input) (*data.IrrigationSchedule, error)
+	Delete(ctx context.Context, user *data.User, irrigationID string) error
+	// Upcoming lists the irrigations due over the next days, flagging those
+	// that could be skipped for forecast rain
+	Upcoming(ctx context.Context, user *data.User, farmID string, days int) (*Upcoming, error)
+}
+
+// irrigationService implements Service on top of the irrigation schedule repository
+type irrigationService struct {
+	schedules data.IrrigationScheduleInterface
+	fields    data.FieldInterface
+	crops     data.CropInterface
+	sources   data.WaterSourceInterface
+	forecasts weather.Forecaster
+	farms     farm.Service
+}
+
+// New creates the irrigation service
+func New(schedules data.IrrigationScheduleInterface, fields data.FieldInterface, crops data.CropInterface, sources data.WaterSourceInterface, forecasts weather.Forecaster, farms farm.Service) Service {
+	return &irrigationService{schedules: schedules, fields: fields, crops: crops, sources: sources, forecasts: forecasts, farms: farms}
+}
+
+// Create adds an irrigation schedule to one of the user's farms, defaulting to
+// a daily, active schedule
+func (s *irrigationService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.IrrigationSchedule, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	if in.IntervalDays <= 0 {
+		in.IntervalDays = 1
+	}
+	if in.Status == "" {
+		in.Status = "Active"
+	}
+
+	schedule := &data.IrrigationSchedule{
+		FarmID:          farmID,
+		Method:          in.Method,
+		EndDate:         in.EndDate,
+		IntervalDays:    in.IntervalDays,
+		StartTime:       in.StartTime,
+		DurationMinutes: in.DurationMinutes,
+		Volume:          in.Volume,
+		Status:          in.Status,
+		Notes:           in.Notes,
+	}
+	if in.StartDate != nil {
+		schedule.StartDate = *in.StartDate
+	}
+
+	if err := s.link(ctx, schedule, in); err != nil {
+		return nil, err
+	}
+	if err := checkDates(schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.schedules.Insert(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("creating irrigation schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// Get returns an irrigation schedule on one of the user's farms
+func (s *irrigationService) Get(ctx context.Context, user *data.User, irrigationID string) (*data.IrrigationSchedule, error) {
+	schedule, err := s.schedules.GetByIrrigationScheduleID(ctx, irrigationID)
+	if err != nil {
+		return nil, fmt.Errorf("getting irrigation schedule: %w", err)
+	}
+	if schedule == nil {
+		return nil, service.NotFound("irrigation schedule not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, schedule.FarmID, "irrigation schedule"); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// List returns the irrigation schedules of one of the user's farms
+func (s *irrigationService) List(ctx context.Context, user *data.User, farmID string) ([]*data.IrrigationSchedule, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	schedules, err := s.schedules.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting irrigation schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// Update changes the non-zero fields of in on an irrigation schedule
+func (s *irrigationService) Update(ctx context.Context, user *data.User, irrigationID string, in Input) (*data.IrrigationSchedule, error) {
+	schedule, err := s.Get(ctx, user, irrigationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Method != "" {
+		schedule.Method = in.Method
+	}
+	if in.StartDate != nil {
+		schedule.StartDate = *in.StartDate
+	}
+	if in.EndDate != nil {
+		schedule.EndDate = in.EndDate
+	}
+	if in.IntervalDays > 0 {
+		schedule.IntervalDays = in.IntervalDays
+	}
+	if in.StartTime != "" {
+		schedule.StartTime = in.StartTime
+	}
+	if in.DurationMinutes > 0 {
+		schedule.DurationMinutes = in.DurationMinutes
+	}
+	if in.Volume > 0 {
+		schedule.Volume = in.Volume
+	}
+	if in.Status != "" {
+		schedule.Status = in.Status
+	}
+	if in.Notes != "" {
+		schedule.Notes = in.Notes
+	}
+
+	if err := s.link(ctx, schedule, in); err != nil {
+		return nil, err
+	}
+	if err := checkDates(schedule); err != nil {
+		return nil, err
+	}
+
+	if err := s.schedules.Update(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("updating irrigation schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// Delete soft deletes an irrigation schedule
+func (s *irrigationService) Delete(ctx context.Context, user *data.User, irrigationID string) error {
+	schedule, err := s.Get(ctx, user, irrigationID)
+	if err != nil {
+		return err
+	}
+	if err := s.schedules.DeleteByID(ctx, int(schedule.ID)); err != nil {
+		return fmt.Errorf("deleting irrigation schedule: %w", err)
+	}
+	return nil
+}
+
+// Upcoming lists the irrigations of the farm's active schedules over the next
+// days, starting today. When the forecast for the farm's location expects at
+// least SkipRainMM of rain on a day, skipping that day's irrigation is
+// suggested. A missing forecast leaves the list without suggestions.
+func (s *irrigationService) Upcoming(ctx context.Context, user *data.User, farmID string, days int) (*Upcoming, error) {
+	f, err := s.farms.Owned(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := service.Day(time.Now())
+	to := from.AddDate(0, 0, days)
+
+	schedules, err := s.schedules.GetActive(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting irrigation schedules: %w", err)
+	}
+
+	upcoming := &Upcoming{FarmID: farmID, From: from, To: to, Sessions: []Session{}}
+	for _, schedule := range schedules {
+		for _, day := range schedule.Occurrences(from, to) {
+			session := Session{
+				IrrigationID:    schedule.IrrigationScheduleID,
+				Date:            day,
+				StartTime:       schedule.StartTime,
+				Method:          schedule.Method,
+				DurationMinutes: schedule.DurationMinutes,
+				Volume:          schedule.Volume,
+			}
+			if schedule.Field != nil {
+				session.FieldName = schedule.Field.Name
+			}
+			if schedule.Crop != nil {
+				session.CropName = schedule.Crop.Name
+			}
+			upcoming.Sessions = append(upcoming.Sessions, session)
+		}
+	}
+	sort.SliceStable(upcoming.Sessions, func(i, j int) bool {
+		a, b := upcoming.Sessions[i], upcoming.Sessions[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.StartTime < b.StartTime
+	})
+
+	if len(upcoming.Sessions) == 0 {
+		upcoming.Forecast = s.forecasts.Name()
+		return upcoming, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forecastTimeout)
+	defer cancel()
+	forecast, err := s.forecasts.Forecast(ctx, f.Location, days)
+	switch {
+	case errors.Is(err, weather.ErrUnavailable):
+		upcoming.Forecast = "not configured"
+		return upcoming, nil
+	case errors.Is(err, weather.ErrUnknownLocation):
+		upcoming.Forecast = "farm location not recognised"
+		return upcoming, nil
+	case err != nil:
+		upcoming.Forecast = "unavailable"
+		return upcoming, nil
+	}
+	upcoming.Forecast = s.forecasts.Name()
+
+	byDay := make(map[string]weather.Day, len(forecast))
+	for _, day := range forecast {
+		byDay[day.Date.Format("2006-01-02")] = day
+	}
+	for i := range upcoming.Sessions {
+		day, ok := byDay[upcoming.Sessions[i].Date.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		upcoming.Sessions[i].RainMM = &day.RainMM
+		upcoming.Sessions[i].RainChance = &day.RainChance
+		upcoming.Sessions[i].SkipSuggested = day.RainMM >= SkipRainMM
+	}
+	return upcoming, nil
+}
+
+// link sets the field, crop and water source of a schedule, each of which
+// must be on the schedule's farm. A crop planted on a field sets the field
+// when none is given. nil leaves a link unchanged and "" clears it.
+func (s *irrigationService) link(ctx context.Context, schedule *data.IrrigationSchedule, in Input) error {
+	if in.FieldID != nil {
+		schedule.FieldID, schedule.Field = nil, nil
+		if *in.FieldID != "" {
+			field, err := s.fields.GetByFieldID(ctx, *in.FieldID)
+			if err != nil {
+				return fmt.Errorf("getting field: %w", err)
+			}
+			if field == nil || field.FarmID != schedule.FarmID {
+				return service.Invalid("field not found on this farm")
+			}
+			schedule.FieldID, schedule.Field = &field.FieldID, field
+		}
+	}
+
+	if in.CropID != nil {
+		schedule.CropID, schedule.Crop = nil, nil
+		if *in.CropID != "" {
+			crop, err := s.crops.GetByCropID(ctx, *in.CropID)
+			if err != nil {
+				return fmt.Errorf("getting crop: %w", err)
+			}
+			if crop == nil || crop.FarmID != schedule.FarmID {
+				return service.Invalid("crop not found on this farm")
+			}
+			schedule.CropID, schedule.Crop = &crop.CropID, crop
+			if schedule.FieldID == nil && crop.FieldID != nil {
+				schedule.FieldID = crop.FieldID
+			}
+		}
+	}
+
+	if in.WaterSourceID != nil {
+		schedule.WaterSourceID = nil
+		if *in.WaterSourceID != "" {
+			source, err := s.sources.GetByWaterSourceID(ctx, *in.WaterSourceID)
+			if err != nil {
+				return fmt.Errorf("getting water source: %w", err)
+			}
+			if source == nil || source.FarmID != schedule.FarmID {
+				return service.Invalid("water source not found on this farm")
+			}
+			schedule.WaterSourceID = &source.WaterSourceID
+		}
+	}
+	return nil
+}
+
+// checkDates requires a schedule not to end before it starts
+func checkDates(schedule *data.IrrigationSchedule) error {
+	if schedule.EndDate != nil && schedule.EndDate.Before(schedule.StartDate) {
+		return service.Invalid("end date must not be before start date")
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+// Package livestock manages the animals kept on a farm
+package livestock
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/tag"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Input holds the editable livestock fields. On update, zero values are left unchanged.
+type Input struct {
+	Type            string
+	Count           int
+	AcquisitionDate *time.Time
+	HealthStatus    string
+	Notes           string
+	Version         int // Required on update: must be the current version
+}
+
+// Stats is a farm's livestock counted per type and health status, with the
+// totals rolled up from those groups
+type Stats struct {
+	FarmID         string                `json:"farmId"`
+	Records        int                   `json:"records"`
+	Head           int                   `json:"head"`
+	ByType         map[string]int        `json:"byType"`         // Head per type
+	ByHealthStatus map[string]int        `json:"byHealthStatus"` // Head per health status
+	Groups         []data.LivestockGroup `json:"groups"`
+}
+
+// Service is the livestock domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Livestock, error)
+	// CreateBatch adds several livestock records in a single transaction
+	CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.Livestock, error)
+	Get(ctx context.Context, user *data.User, livestockID string) (*data.Livestock, error)
+	// List returns the livestock of a farm latest acquired first, optionally
+	// only that acquired in [from, to) and that tagged with every one of tags
+	List(ctx context.Context, user *data.User, farmID string, from, to *time.Time, tags []string) ([]*data.Livestock, error)
+	// ListAll returns the livestock of every farm the user owns, each with
+	// its farm, for one consolidated list
+	ListAll(ctx context.Context, user *data.User) ([]*data.Livestock, error)
+	// Stats counts a farm's livestock per type and health status without
+	// loading the records
+	Stats(ctx context.Context, user *data.User, farmID string) (*Stats, error)
+	Update(ctx context.Context, user *data.User, livestockID string, in Input) (*data.Livestock, error)
+	// Patch changes only the fields in set, clearing those left blank
+	Patch(ctx context.Context, user *data.User, livestockID string, in Input, set service.Fields) (*data.Livestock, error)
+	Delete(ctx context.Context, user *data.User, livestockID string) error
+	// DeleteBatch soft deletes several of a farm's livestock records in a
+	// single transaction and returns them. A record that is not on the farm
+	// gets its error in the same slot of the returned errors and is left
+	// out; the others are still deleted.
+	DeleteBatch(ctx context.Context, user *data.User, farmID string, livestockIDs []string) ([]*data.Livestock, []error, error)
+	ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.Livestock, error)
+	Restore(ctx context.Context, user *data.User, livestockID string) (*data.Livestock, error)
+}
+
+// livestockService implements Service on top of the livestock repository
+type livestockService struct {
+	livestock data.LivestockInterface
+	tags      data.TagInterface
+	farms     farm.Service
+}
+
+// New creates the livestock service
+func New(livestock data.LivestockInterface, tags data.TagInterface, farms farm.Service) Service {
+	return &livestockService{livestock: livestock, tags: tags, farms: farms}
+}
+
+// Create adds livestock to one of the user's farms, defaulting to Healthy
+func (s *livestockService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Livestock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	livestock := newLivestock(farmID, in)
+	if err := s.livestock.Insert(ctx, livestock); err != nil {
+		return nil, fmt.Errorf("creating livestock: %w", err)
+	}
+	return livestock, nil
+}
+
+// CreateBatch adds several livestock records to one of the user's farms in a
+// single transaction
+func (s *livestockService) CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.Livestock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	livestock := make([]*data.Livestock, len(ins))
+	for i, in := range ins {
+		livestock[i] = newLivestock(farmID, in)
+	}
+	if len(livestock) > 0 {
+		if err := s.livestock.InsertMany(ctx, livestock); err != nil {
+			return nil, fmt.Errorf("creating livestock: %w", err)
+		}
+	}
+	return livestock, nil
+}
+
+// newLivestock builds livestock on farmID from in, defaulting to Healthy
+func newLivestock(farmID string, in Input) *data.Livestock {
+	if in.HealthStatus == "" {
+		in.HealthStatus = "Healthy"
+	}
+	return &data.Livestock{
+		FarmID:          farmID,
+		Type:            in.Type,
+		Count:           in.Count,
+		AcquisitionDate: in.AcquisitionDate,
+		HealthStatus:    in.HealthStatus,
+		Notes:           in.Notes,
+	}
+}
+
+// OnFarm returns the herd livestockID kept on farmID, for the services that
+// record things against a herd, such as weighings and births. A herd on
+// another farm is not found.
+func OnFarm(ctx context.Context, livestock data.LivestockInterface, farmID, livestockID string) (*data.Livestock, error) {
+	herd, err := livestock.GetByLivestockID(ctx, livestockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting livestock: %w", err)
+	}
+	if herd == nil || herd.FarmID != farmID {
+		return nil, service.Invalid("livestock not found on this farm")
+	}
+	return herd, nil
+}
+
+// Get returns livestock on one of the user's farms
+func (s *livestockService) Get(ctx context.Context, user *data.User, livestockID string) (*data.Livestock, error) {
+	livestock, err := s.livestock.GetByLivestockID(ctx, livestockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting livestock: %w", err)
+	}
+	if livestock == nil {
+		return nil, service.NotFound("livestock not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, livestock.FarmID, "livestock"); err != nil {
+		return nil, err
+	}
+	return livestock, nil
+}
+
+// List returns the livestock of one of the user's farms
+func (s *livestockService) List(ctx context.Context, user *data.User, farmID string, from, to *time.Time, tags []string) ([]*data.Livestock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	livestock, err := s.livestock.GetByFarmIDAndDateRange(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting livestock: %w", err)
+	}
+	return tag.Filter(ctx, s.tags, farmID, tag.RecordLivestock, tags, livestock, func(l *data.Livestock) string { return l.LivestockID })
+}
+
+// ListAll implements Service
+func (s *livestockService) ListAll(ctx context.Context, user *data.User) ([]*data.Livestock, error) {
+	farms, err := s.farms.Reachable(ctx, user, "", farm.Read)
+	if err != nil {
+		return nil, err
+	}
+	all := []*data.Livestock{}
+	for _, f := range farms {
+		livestock, err := s.livestock.GetByFarmID(ctx, f.FarmID)
+		if err != nil {
+			return nil, fmt.Errorf("getting livestock: %w", err)
+		}
+		for _, l := range livestock {
+			l.Farm = f
+		}
+		all = append(all, livestock...)
+	}
+	return all, nil
+}
+
+// Stats implements Service
+func (s *livestockService) Stats(ctx context.Context, user *data.User, farmID string) (*Stats, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	groups, err := s.livestock.Stats(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("counting livestock: %w", err)
+	}
+
+	stats := &Stats{FarmID: farmID, ByType: map[string]int{}, ByHealthStatus: map[string]int{}, Groups: groups}
+	if stats.Groups == nil {
+		stats.Groups = []data.LivestockGroup{}
+	}
+	for _, group := range groups {
+		stats.Records += group.Records
+		stats.Head += group.Head
+		stats.ByType[group.Type] += group.Head
+		stats.ByHealthStatus[group.HealthStatus] += group.Head
+	}
+	return stats, nil
+}
+
+// Update changes the non-zero fields of in on livestock
+func (s *livestockService) Update(ctx context.Context, user *data.User, livestockID string, in Input) (*data.Livestock, error) {
+	return s.Patch(ctx, user, livestockID, in, nil)
+}
+
+// Patch implements Service. A nil set updates the fields with a non-zero
+// value, as Update does.
+func (s *livestockService) Patch(ctx context.Context, user *data.User, livestockID string, in Input, set service.Fields) (*data.Livestock, error) {
+	livestock, err := s.Get(ctx, user, livestockID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.CheckVersion(ctx, "livestock", in.Version, livestock.Version, livestock); err != nil {
+		return nil, err
+	}
+
+	if set.Sets("type", in.Type != "") {
+		livestock.Type = in.Type
+	}
+	if set.Sets("count", in.Count > 0) {
+		livestock.Count = in.Count
+	}
+	if set.Sets("acquisitionDate", in.AcquisitionDate != nil) {
+		livestock.AcquisitionDate = in.AcquisitionDate
+	}
+	if set.Sets("healthStatus", in.HealthStatus != "") {
+		livestock.HealthStatus = in.HealthStatus
+	}
+	if set.Sets("notes", in.Notes != "") {
+		livestock.Notes = in.Notes
+	}
+
+	if err := s.livestock.Update(ctx, livestock); errors.Is(err, data.ErrStale) {
+		current, err := s.Get(ctx, user, livestockID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Stale("livestock", current)
+	} else if err != nil {
+		return nil, fmt.Errorf("updating livestock: %w", err)
+	}
+	return livestock, nil
+}
+
+// Delete soft deletes livestock
+func (s *livestockService) Delete(ctx context.Context, user *data.User, livestockID string) error {
+	livestock, err := s.Get(ctx, user, livestockID)
+	if err != nil {
+		return err
+	}
+	if err := s.livestock.DeleteByID(ctx, int(livestock.ID)); err != nil {
+		return fmt.Errorf("deleting livestock: %w", err)
+	}
+	return nil
+}
+
+// DeleteBatch soft deletes several livestock records of one of the user's
+// farms, leaving out those that are not on the farm
+func (s *livestockService) DeleteBatch(ctx context.Context, user *data.User, farmID string, livestockIDs []string) ([]*data.Livestock, []error, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	found, err := s.livestock.GetByLivestockIDs(ctx, livestockIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting livestock: %w", err)
+	}
+	byID := make(map[string]*data.Livestock, len(found))
+	for _, livestock := range found {
+		byID[livestock.LivestockID] = livestock
+	}
+
+	herds := make([]*data.Livestock, len(livestockIDs))
+	errs := make([]error, len(livestockIDs))
+	seen := map[string]bool{}
+	var ids []uint
+	for i, livestockID := range livestockIDs {
+		if seen[livestockID] {
+			errs[i] = service.Invalid("livestock is listed more than once")
+			continue
+		}
+		seen[livestockID] = true
+		livestock := byID[livestockID]
+		if livestock == nil || livestock.FarmID != farmID {
+			errs[i] = service.NotFound("livestock not found on this farm")
+			continue
+		}
+		herds[i] = livestock
+		ids = append(ids, livestock.ID)
+	}
+	if len(ids) > 0 {
+		if err := s.livestock.DeleteMany(ctx, ids); err != nil {
+			return nil, nil, fmt.Errorf("deleting livestock: %w", err)
+		}
+	}
+	return herds, errs, nil
+}
+
+// ListDeleted returns the soft-deleted livestock of one of the user's farms
+func (s *livestockService) ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.Livestock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	livestock, err := s.livestock.GetDeletedByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted livestock: %w", err)
+	}
+	return livestock, nil
+}
+
+// Restore undeletes livestock. Its farm must still exist and belong to the user.
+func (s *livestockService) Restore(ctx context.Context, user *data.User, livestockID string) (*data.Livestock, error) {
+	livestock, err := s.livestock.GetDeletedByLivestockID(ctx, livestockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted livestock: %w", err)
+	}
+	if livestock == nil {
+		return nil, service.NotFound("deleted livestock not found")
+	}
+	if _, err := s.farms.Owned(ctx, user, livestock.FarmID); err != nil {
+		return nil, err
+	}
+
+	if err := s.livestock.RestoreByID(ctx, int(livestock.ID)); err != nil {
+		return nil, fmt.Errorf("restoring livestock: %w", err)
+	}
+	livestock.DeletedAt = gorm.DeletedAt{}
+	return livestock, nil
+}
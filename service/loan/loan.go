@@ -0,0 +1,333 @@
+// Package loan manages the credit a farm has taken. Each loan's repayment
+// schedule is worked out from its terms; repayments are logged against it,
+// with the interest they cover carried into the finance ledger, and the
+// outstanding balance reports what is still owed and what is overdue.
+package loan
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+)
+
+// Loan statuses
+const (
+	StatusActive = "Active"
+	StatusRepaid = "Repaid"
+)
+
+// Interest methods
+const (
+	MethodFlat     = "Flat"
+	MethodReducing = "Reducing"
+)
+
+// Repayment frequencies
+const (
+	FrequencyMonthly   = "Monthly"
+	FrequencyQuarterly = "Quarterly"
+	FrequencyBullet    = "Bullet"
+)
+
+// Input holds the editable loan fields. On update, zero values are left
+// unchanged; a nil InterestRate too, as a loan may be interest free.
+type Input struct {
+	Lender           string
+	Principal        float64
+	InterestRate     *float64
+	InterestMethod   string
+	DisbursementDate *time.Time
+	TermMonths       int
+	Frequency        string
+	Notes            string
+}
+
+// RepaymentInput holds the fields of a repayment. A nil Date means today.
+type RepaymentInput struct {
+	Amount float64
+	Date   *time.Time
+	Notes  string
+}
+
+// Balance is where one loan stands: what was scheduled, what has been repaid
+// and what is still owed. Overdue is the part of the installments already
+// due that has not been repaid; NextDueAmount is what remains of the next
+// installment not fully repaid.
+type Balance struct {
+	LoanID        string     `json:"loanId"`
+	Lender        string     `json:"lender"`
+	Status        string     `json:"status"`
+	Principal     float64    `json:"principal"`
+	Interest      float64    `json:"interest"` // Scheduled interest over the term
+	TotalDue      float64    `json:"totalDue"`
+	Repaid        float64    `json:"repaid"`
+	Outstanding   float64    `json:"outstanding"`
+	Overdue       float64    `json:"overdue"`
+	NextDueDate   *time.Time `json:"nextDueDate,omitempty"`
+	NextDueAmount float64    `json:"nextDueAmount,omitempty"`
+}
+
+// OutstandingReport is the balance of each of a farm's loans and their totals
+type OutstandingReport struct {
+	FarmID      string    `json:"farmId"`
+	AsOf        time.Time `json:"asOf"`
+	Loans       []Balance `json:"loans"`
+	Outstanding float64   `json:"outstanding"`
+	Overdue     float64   `json:"overdue"`
+}
+
+// Service is the loan domain service
+type Service interface {
+	// Create records a loan and works out its repayment schedule
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Loan, error)
+	Get(ctx context.Context, user *data.User, loanID string) (*data.Loan, error)
+	List(ctx context.Context, user *data.User, farmID, status string) ([]*data.Loan, error)
+	// Update changes a loan's terms and works out its schedule again, which
+	// is only possible before anything has been repaid
+	Update(ctx context.Context, user *data.User, loanID string, in Input) (*data.Loan, error)
+	Delete(ctx context.Context, user *data.User, loanID string) error
+	// Repay logs a repayment against a loan and returns the loan, which is
+	// marked repaid once nothing is outstanding
+	Repay(ctx context.Context, user *data.User, loanID string, in RepaymentInput) (*data.Loan, error)
+	// DeleteRepayment removes a repayment logged in error and returns its loan
+	DeleteRepayment(ctx context.Context, user *data.User, repaymentID string) (*data.Loan, error)
+	// Outstanding reports the balance of a farm's loans
+	Outstanding(ctx context.Context, user *data.User, farmID string) (*OutstandingReport, error)
+}
+
+// loanService implements Service on top of the loan repository
+type loanService struct {
+	loans data.LoanInterface
+	locks lock.Checker
+	farms farm.Service
+}
+
+// New creates the loan service
+func New(loans data.LoanInterface, locks lock.Checker, farms farm.Service) Service {
+	return &loanService{loans: loans, locks: locks, farms: farms}
+}
+
+// Create implements Service. The interest method defaults to flat and
+// installments to monthly.
+func (s *loanService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Loan, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Write); err != nil {
+		return nil, err
+	}
+	if in.InterestMethod == "" {
+		in.InterestMethod = MethodFlat
+	}
+	if in.Frequency == "" {
+		in.Frequency = FrequencyMonthly
+	}
+
+	loan := &data.Loan{
+		FarmID:           farmID,
+		Lender:           in.Lender,
+		Principal:        in.Principal,
+		InterestMethod:   in.InterestMethod,
+		DisbursementDate: *in.DisbursementDate,
+		TermMonths:       in.TermMonths,
+		Frequency:        in.Frequency,
+		Status:           StatusActive,
+		Notes:            in.Notes,
+	}
+	if in.InterestRate != nil {
+		loan.InterestRate = *in.InterestRate
+	}
+	loan.Installments = schedule(loan)
+	if err := s.loans.Insert(ctx, loan); err != nil {
+		return nil, fmt.Errorf("creating loan: %w", err)
+	}
+	return loan, nil
+}
+
+// Get returns a loan of a farm whose finances the user may read
+func (s *loanService) Get(ctx context.Context, user *data.User, loanID string) (*data.Loan, error) {
+	return s.loan(ctx, user, loanID, farm.Read)
+}
+
+// List returns a farm's loans, most recently disbursed first, optionally
+// only those with the given status
+func (s *loanService) List(ctx context.Context, user *data.User, farmID, status string) ([]*data.Loan, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModuleFinance, farm.Read); err != nil {
+		return nil, err
+	}
+	loans, err := s.loans.GetByFarmID(ctx, farmID, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting loans: %w", err)
+	}
+	return loans, nil
+}
+
+// Update implements Service
+func (s *loanService) Update(ctx context.Context, user *data.User, loanID string, in Input) (*data.Loan, error) {
+	loan, err := s.loan(ctx, user, loanID, farm.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := in.Principal > 0 || in.InterestRate != nil || in.InterestMethod != "" ||
+		in.DisbursementDate != nil || in.TermMonths > 0 || in.Frequency != ""
+	if terms && len(loan.Repayments) > 0 {
+		return nil, service.Conflict("loan terms cannot change once repayments are logged")
+	}
+
+	if in.Lender != "" {
+		loan.Lender = in.Lender
+	}
+	if in.Principal > 0 {
+		loan.Principal = in.Principal
+	}
+	if in.InterestRate != nil {
+		loan.InterestRate = *in.InterestRate
+	}
+	if in.InterestMethod != "" {
+		loan.InterestMethod = in.InterestMethod
+	}
+	if in.DisbursementDate != nil {
+		loan.DisbursementDate = *in.DisbursementDate
+	}
+	if in.TermMonths > 0 {
+		loan.TermMonths = in.TermMonths
+	}
+	if in.Frequency != "" {
+		loan.Frequency = in.Frequency
+	}
+	if in.Notes != "" {
+		loan.Notes = in.Notes
+	}
+	if terms {
+		loan.Installments = schedule(loan)
+	}
+
+	if err := s.loans.Update(ctx, loan); err != nil {
+		return nil, fmt.Errorf("updating loan: %w", err)
+	}
+	return loan, nil
+}
+
+// Delete soft deletes a loan with no repayments logged
+func (s *loanService) Delete(ctx context.Context, user *data.User, loanID string) error {
+	loan, err := s.loan(ctx, user, loanID, farm.Write)
+	if err != nil {
+		return err
+	}
+	if len(loan.Repayments) > 0 {
+		return service.Conflict("loan has repayments; delete them first")
+	}
+	if err := s.loans.DeleteByID(ctx, int(loan.ID)); err != nil {
+		return fmt.Errorf("deleting loan: %w", err)
+	}
+	return nil
+}
+
+// Repay implements Service. A repayment may not exceed what is outstanding.
+func (s *loanService) Repay(ctx context.Context, user *data.User, loanID string, in RepaymentInput) (*data.Loan, error) {
+	loan, err := s.loan(ctx, user, loanID, farm.Write)
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if date.Before(loan.DisbursementDate) {
+		return nil, service.Invalid("repayment date must not be before the disbursement date")
+	}
+	if err := s.locks.Check(ctx, loan.FarmID, date); err != nil {
+		return nil, err
+	}
+
+	repaid := loan.Repaid()
+	outstanding := round2(loan.TotalDue() - repaid)
+	if round2(in.Amount) > outstanding {
+		return nil, service.Invalid(fmt.Sprintf("repayment exceeds the outstanding balance of %.2f", outstanding))
+	}
+
+	repayment := &data.LoanRepayment{
+		LoanID:   loan.LoanID,
+		FarmID:   loan.FarmID,
+		Date:     date,
+		Amount:   in.Amount,
+		Interest: round2(interestCovered(loan.Installments, repaid+in.Amount) - interestCovered(loan.Installments, repaid)),
+		Notes:    in.Notes,
+	}
+	if round2(in.Amount) == outstanding {
+		loan.Status = StatusRepaid
+	}
+	if err := s.loans.InsertRepayment(ctx, loan, repayment); err != nil {
+		return nil, fmt.Errorf("logging repayment: %w", err)
+	}
+	loan.Repayments = append(loan.Repayments, *repayment)
+	return loan, nil
+}
+
+// DeleteRepayment implements Service. The loan becomes active again if it
+// had been repaid.
+func (s *loanService) DeleteRepayment(ctx context.Context, user *data.User, repaymentID string) (*data.Loan, error) {
+	repayment, err := s.loans.GetRepaymentByID(ctx, repaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting repayment: %w", err)
+	}
+	if repayment == nil {
+		return nil, service.NotFound("repayment not found")
+	}
+	loan, err := s.loan(ctx, user, repayment.LoanID, farm.Write)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, repayment.FarmID, repayment.Date); err != nil {
+		return nil, err
+	}
+
+	loan.Status = StatusActive
+	if err := s.loans.DeleteRepayment(ctx, loan, repayment); err != nil {
+		return nil, fmt.Errorf("deleting repayment: %w", err)
+	}
+	for i := range loan.Repayments {
+		if loan.Repayments[i].LoanRepaymentID == repayment.LoanRepaymentID {
+			loan.Repayments = append(loan.Repayments[:i], loan.Repayments[i+1:]...)
+			break
+		}
+	}
+	return loan, nil
+}
+
+// Outstanding implements Service. Repaid loans are left out.
+func (s *loanService) Outstanding(ctx context.Context, user *data.User, farmID string) (*OutstandingReport, error) {
+	loans, err := s.List(ctx, user, farmID, StatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &OutstandingReport{FarmID: farmID, AsOf: time.Now(), Loans: make([]Balance, 0, len(loans))}
+	for _, loan := range loans {
+		b := balance(loan, report.AsOf)
+		report.Loans = append(report.Loans, b)
+		report.Outstanding += b.Outstanding
+		report.Overdue += b.Overdue
+	}
+	report.Outstanding, report.Overdue = round2(report.Outstanding), round2(report.Overdue)
+	return report, nil
+}
+
+// loan loads a loan, checking the user may take action on the farm's
+// finances
+func (s *loanService) loan(ctx context.Context, user *data.User, loanID string, action farm.Action) (*data.Loan, error) {
+	loan, err := s.loans.GetByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("getting loan: %w", err)
+	}
+	if loan == nil {
+		return nil, service.NotFound("loan not found")
+	}
+	if _, err := s.farms.Authorize(ctx, user, loan.FarmID, farm.ModuleFinance, action); err != nil {
+		return nil, err
+	}
+	return loan, nil
+}
@@ -0,0 +1,106 @@
+package loan
+
+import (
+	"farm4u/data"
+	"math"
+	"time"
+)
+
+// schedule works out a loan's installments from its terms. Flat interest is
+// charged on the full principal for the whole term and spread evenly over the
+// installments; reducing-balance interest is charged each period on the
+// principal still owed, with equal installments. Rounding is settled on the
+// last installment so the schedule adds up to the principal exactly.
+func schedule(loan *data.Loan) []data.LoanInstallment {
+	months, count := 1, loan.TermMonths
+	switch loan.Frequency {
+	case FrequencyQuarterly:
+		months, count = 3, (loan.TermMonths+2)/3
+	case FrequencyBullet:
+		months, count = loan.TermMonths, 1
+	}
+
+	rate := loan.InterestRate / 100
+	periodRate := rate / 12 * float64(months)
+	flatInterest := round2(loan.Principal * rate * float64(loan.TermMonths) / 12 / float64(count))
+	payment := loan.Principal / float64(count)
+	if loan.InterestMethod == MethodReducing && periodRate > 0 {
+		payment = loan.Principal * periodRate / (1 - math.Pow(1+periodRate, -float64(count)))
+	}
+
+	installments := make([]data.LoanInstallment, count)
+	balance := loan.Principal
+	for i := range installments {
+		interest := flatInterest
+		principal := round2(payment)
+		if loan.InterestMethod == MethodReducing {
+			interest = round2(balance * periodRate)
+			principal = round2(payment - interest)
+		}
+		if i == count-1 {
+			principal = round2(balance)
+		}
+		balance -= principal
+
+		installments[i] = data.LoanInstallment{
+			Number:    i + 1,
+			DueDate:   loan.DisbursementDate.AddDate(0, months*(i+1), 0),
+			Principal: principal,
+			Interest:  interest,
+			Amount:    round2(principal + interest),
+		}
+	}
+	return installments
+}
+
+// interestCovered returns how much of an amount paid against a schedule goes
+// to interest. Installments are settled in order, each one's interest before
+// its principal.
+func interestCovered(installments []data.LoanInstallment, paid float64) float64 {
+	var interest float64
+	for _, installment := range installments {
+		if paid <= 0 {
+			break
+		}
+		share := min(paid, installment.Interest)
+		interest += share
+		paid -= share + min(paid-share, installment.Principal)
+	}
+	return round2(interest)
+}
+
+// balance is where a loan stands at asOf
+func balance(loan *data.Loan, asOf time.Time) Balance {
+	b := Balance{
+		LoanID:    loan.LoanID,
+		Lender:    loan.Lender,
+		Status:    loan.Status,
+		Principal: loan.Principal,
+		TotalDue:  round2(loan.TotalDue()),
+		Repaid:    round2(loan.Repaid()),
+	}
+	b.Interest = round2(b.TotalDue - loan.Principal)
+	b.Outstanding = round2(max(b.TotalDue-b.Repaid, 0))
+
+	// Repayments settle installments in order, so the next one due is the
+	// first the repayments have not covered
+	var due, scheduled float64
+	for _, installment := range loan.Installments {
+		scheduled += installment.Amount
+		if !installment.DueDate.After(asOf) {
+			due = scheduled
+		}
+		if b.NextDueDate == nil && scheduled-b.Repaid > 0.005 {
+			date := installment.DueDate
+			b.NextDueDate = &date
+			b.NextDueAmount = round2(scheduled - b.Repaid)
+		}
+	}
+	b.Overdue = round2(max(due-b.Repaid, 0))
+	return b
+}
+
+// round2 rounds an amount to cents
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,209 @@
+// Package lock closes periods on a farm, e.g. once a report has gone to a
+// lender or co-op. Records dated inside an active lock cannot be created,
+// changed or deleted until the owner unlocks the period; both actions are
+// written to the audit log.
+package lock
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"time"
+)
+
+// Audit log actions and entity type for period locks
+const (
+	ActionLock   = "period.lock"
+	ActionUnlock = "period.unlock"
+	EntityType   = "period_lock"
+)
+
+// Input holds the period to lock. Both dates are whole days and inclusive.
+type Input struct {
+	PeriodStart *time.Time
+	PeriodEnd   *time.Time
+	Reason      string
+}
+
+// Checker is implemented by Service. Domain services that write dated
+// records depend on it rather than on the whole lock service.
+type Checker interface {
+	// Check returns a Conflict error if any of dates falls in an active lock on the farm
+	Check(ctx context.Context, farmID string, dates ...time.Time) error
+}
+
+// Service is the period lock domain service
+type Service interface {
+	Checker
+	Lock(ctx context.Context, user *data.User, farmID string, in Input) (*data.PeriodLock, error)
+	Unlock(ctx context.Context, user *data.User, periodLockID, reason string) (*data.PeriodLock, error)
+	List(ctx context.Context, user *data.User, farmID string, includeUnlocked bool) ([]*data.PeriodLock, error)
+	// AuditLog returns a farm's audit entries, optionally filtered by entity type
+	AuditLog(ctx context.Context, user *data.User, farmID, entityType string, from, to *time.Time) ([]*data.AuditLog, error)
+}
+
+// lockService implements Service on top of the period lock and audit log repositories
+type lockService struct {
+	models data.Models
+	locks  data.PeriodLockInterface
+	audit  data.AuditLogInterface
+	farms  farm.Service
+}
+
+// New creates the period lock service
+func New(models data.Models, farms farm.Service) Service {
+	return &lockService{models: models, locks: models.PeriodLock, audit: models.AuditLog, farms: farms}
+}
+
+// inTransaction runs fn on a copy of the service whose repositories share one
+// transaction, so a lock change and its audit entry are saved together or
+// not at all
+func (s *lockService) inTransaction(ctx context.Context, fn func(tx *lockService) error) error {
+	return s.models.WithTransaction(ctx, func(models data.Models) error {
+		return fn(&lockService{models: models, locks: models.PeriodLock, audit: models.AuditLog, farms: s.farms})
+	})
+}
+
+// Lock closes a period on one of the user's farms. It may not overlap a
+// period that is already locked.
+func (s *lockService) Lock(ctx context.Context, user *data.User, farmID string, in Input) (*data.PeriodLock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	if in.PeriodStart == nil || in.PeriodEnd == nil {
+		return nil, service.Invalid("period start and end are required")
+	}
+	start, end := service.Day(*in.PeriodStart), service.Day(*in.PeriodEnd)
+	if end.Before(start) {
+		return nil, service.Invalid("period end cannot be before its start")
+	}
+
+	existing, err := s.locks.GetActiveOverlapping(ctx, farmID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("checking period locks: %w", err)
+	}
+	if existing != nil {
+		return nil, service.Conflict(fmt.Sprintf("period overlaps the locked period %s", describe(existing)))
+	}
+
+	lock := &data.PeriodLock{
+		FarmID:      farmID,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Reason:      in.Reason,
+		LockedBy:    user.UserID,
+	}
+	err = s.inTransaction(ctx, func(tx *lockService) error {
+		if err := tx.locks.Insert(ctx, lock); err != nil {
+			return fmt.Errorf("locking period: %w", err)
+		}
+		return tx.record(ctx, user, lock, ActionLock, lock.Reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Unlock reopens a locked period. A reason is required for the audit log.
+func (s *lockService) Unlock(ctx context.Context, user *data.User, periodLockID, reason string) (*data.PeriodLock, error) {
+	lock, err := s.locks.GetByPeriodLockID(ctx, periodLockID)
+	if err != nil {
+		return nil, fmt.Errorf("getting period lock: %w", err)
+	}
+	if lock == nil {
+		return nil, service.NotFound("period lock not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, lock.FarmID, "period lock"); err != nil {
+		return nil, err
+	}
+	if !lock.Active() {
+		return nil, service.Conflict("period is already unlocked")
+	}
+	if reason == "" {
+		return nil, service.Invalid("a reason is required to unlock a period")
+	}
+
+	now := time.Now()
+	lock.UnlockedBy = &user.UserID
+	lock.UnlockedAt = &now
+	lock.UnlockReason = reason
+	err = s.inTransaction(ctx, func(tx *lockService) error {
+		if err := tx.locks.Update(ctx, lock); err != nil {
+			return fmt.Errorf("unlocking period: %w", err)
+		}
+		return tx.record(ctx, user, lock, ActionUnlock, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// List returns the locks of one of the user's farms, by default only active ones
+func (s *lockService) List(ctx context.Context, user *data.User, farmID string, includeUnlocked bool) ([]*data.PeriodLock, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	locks, err := s.locks.GetByFarmID(ctx, farmID, includeUnlocked)
+	if err != nil {
+		return nil, fmt.Errorf("getting period locks: %w", err)
+	}
+	return locks, nil
+}
+
+// AuditLog returns the audit entries of one of the user's farms made in [from, to)
+func (s *lockService) AuditLog(ctx context.Context, user *data.User, farmID, entityType string, from, to *time.Time) ([]*data.AuditLog, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	entries, err := s.audit.GetByFarmID(ctx, farmID, entityType, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Check returns a Conflict error if any of dates falls in an active lock on the farm
+func (s *lockService) Check(ctx context.Context, farmID string, dates ...time.Time) error {
+	for _, date := range dates {
+		lock, err := s.locks.GetActiveCovering(ctx, farmID, service.Day(date))
+		if err != nil {
+			return fmt.Errorf("checking period locks: %w", err)
+		}
+		if lock != nil {
+			return service.Conflict(fmt.Sprintf("records dated %s are in the locked period %s and cannot be changed", date.Format("2006-01-02"), describe(lock)))
+		}
+	}
+	return nil
+}
+
+// record writes a lock or unlock to the audit log
+func (s *lockService) record(ctx context.Context, user *data.User, lock *data.PeriodLock, action, details string) error {
+	if details != "" {
+		details = describe(lock) + ": " + details
+	} else {
+		details = describe(lock)
+	}
+
+	entry := &data.AuditLog{
+		FarmID:     lock.FarmID,
+		UserID:     user.UserID,
+		Action:     action,
+		EntityType: EntityType,
+		EntityID:   lock.PeriodLockID,
+		Details:    details,
+	}
+	if err := s.audit.Insert(ctx, entry); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// describe formats a lock's period for messages
+func describe(lock *data.PeriodLock) string {
+	return lock.PeriodStart.Format("2006-01-02") + " to " + lock.PeriodEnd.Format("2006-01-02")
+}
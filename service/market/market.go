@@ -0,0 +1,189 @@
+// Package market serves daily commodity prices so farmers can time their
+// sales. Prices come from the configured price feed, refreshed by a
+// background job, or from admin uploads.
+package market
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/pricefeed"
+	"farm4u/service"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SourceManual is the source recorded for admin uploads
+const SourceManual = "manual"
+
+// defaultDays is how far back prices are listed when no period is given
+const defaultDays = 30
+
+// Input is one price in an admin upload
+type Input struct {
+	Commodity string
+	Region    string
+	Unit      string
+	Price     float64
+	Currency  string
+	Date      time.Time
+}
+
+// Prices lists a commodity's prices. Latest holds the most recent price per
+// region and unit; History holds every price in the period, newest first.
+type Prices struct {
+	Commodity string              `json:"commodity"`
+	Region    string              `json:"region,omitempty"`
+	From      time.Time           `json:"from"`
+	To        *time.Time          `json:"to,omitempty"`
+	Latest    []*data.MarketPrice `json:"latest"`
+	History   []*data.MarketPrice `json:"history"`
+}
+
+// Service is the market price domain service
+type Service interface {
+	// Prices lists a commodity's prices, optionally in one region, over
+	// [from, to); from defaults to 30 days ago
+	Prices(ctx context.Context, commodity, region string, from, to *time.Time) (*Prices, error)
+	Commodities(ctx context.Context) ([]string, error)
+	// Import saves uploaded prices; callers must have checked the role
+	Import(ctx context.Context, prices []Input) (int, error)
+	// Refresh fetches the feed's latest prices and saves them
+	Refresh(ctx context.Context) (int, error)
+}
+
+// marketService implements Service on top of the market price repository
+type marketService struct {
+	prices data.MarketPriceInterface
+	feed   pricefeed.Feed
+}
+
+// New creates the market price service
+func New(prices data.MarketPriceInterface, feed pricefeed.Feed) Service {
+	return &marketService{prices: prices, feed: feed}
+}
+
+// Prices implements Service
+func (s *marketService) Prices(ctx context.Context, commodity, region string, from, to *time.Time) (*Prices, error) {
+	commodity = normalise(commodity)
+	if commodity == "" {
+		return nil, service.Invalid("commodity is required")
+	}
+	if from == nil {
+		start := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -defaultDays)
+		from = &start
+	}
+
+	history, err := s.prices.GetPrices(ctx, commodity, strings.TrimSpace(region), from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting market prices: %w", err)
+	}
+
+	// History is newest first, so the first price seen per region and unit
+	// is the latest
+	latest := []*data.MarketPrice{}
+	seen := map[string]bool{}
+	for _, price := range history {
+		key := strings.ToLower(price.Region) + "|" + price.Unit
+		if !seen[key] {
+			seen[key] = true
+			latest = append(latest, price)
+		}
+	}
+
+	return &Prices{
+		Commodity: commodity,
+		Region:    strings.TrimSpace(region),
+		From:      *from,
+		To:        to,
+		Latest:    latest,
+		History:   history,
+	}, nil
+}
+
+// Commodities implements Service
+func (s *marketService) Commodities(ctx context.Context) ([]string, error) {
+	commodities, err := s.prices.Commodities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting commodities: %w", err)
+	}
+	return commodities, nil
+}
+
+// Import implements Service
+func (s *marketService) Import(ctx context.Context, prices []Input) (int, error) {
+	return s.save(ctx, prices, SourceManual)
+}
+
+// Refresh implements Service
+func (s *marketService) Refresh(ctx context.Context) (int, error) {
+	fetched, err := s.feed.Fetch(ctx)
+	if errors.Is(err, pricefeed.ErrUnavailable) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("fetching market prices: %w", err)
+	}
+	if len(fetched) == 0 {
+		return 0, nil
+	}
+
+	prices := make([]Input, len(fetched))
+	for i, p := range fetched {
+		prices[i] = Input(p)
+	}
+	return s.save(ctx, prices, s.feed.Name())
+}
+
+// save validates and stores prices from source. A batch with any invalid
+// price is rejected whole so an upload is never half applied.
+func (s *marketService) save(ctx context.Context, in []Input, source string) (int, error) {
+	if len(in) == 0 {
+		return 0, service.Invalid("no prices to import")
+	}
+
+	prices := make([]*data.MarketPrice, 0, len(in))
+	index := map[string]int{}
+	for i, p := range in {
+		price := &data.MarketPrice{
+			Commodity: normalise(p.Commodity),
+			Region:    strings.TrimSpace(p.Region),
+			Unit:      strings.TrimSpace(p.Unit),
+			Price:     p.Price,
+			Currency:  strings.ToUpper(strings.TrimSpace(p.Currency)),
+			Date:      p.Date.UTC().Truncate(24 * time.Hour),
+			Source:    source,
+		}
+		switch {
+		case price.Commodity == "" || price.Region == "" || price.Unit == "":
+			return 0, service.Invalid(fmt.Sprintf("price %d: commodity, region and unit are required", i+1))
+		case price.Price <= 0:
+			return 0, service.Invalid(fmt.Sprintf("price %d: price must be greater than 0", i+1))
+		case len(price.Currency) != 3:
+			return 0, service.Invalid(fmt.Sprintf("price %d: currency must be a 3-letter code", i+1))
+		case p.Date.IsZero():
+			return 0, service.Invalid(fmt.Sprintf("price %d: date is required", i+1))
+		}
+
+		// A repeated price for the same day replaces the earlier one, as a
+		// single upsert cannot touch the same row twice
+		key := strings.Join([]string{price.Commodity, price.Region, price.Unit, price.Date.Format("2006-01-02")}, "|")
+		if j, ok := index[key]; ok {
+			prices[j] = price
+			continue
+		}
+		index[key] = len(prices)
+		prices = append(prices, price)
+	}
+
+	if err := s.prices.Upsert(ctx, prices); err != nil {
+		return 0, fmt.Errorf("saving market prices: %w", err)
+	}
+	return len(prices), nil
+}
+
+// normalise puts a commodity name in the stored form, e.g. " Maize " -> "maize"
+func normalise(commodity string) string {
+	return strings.ToLower(strings.TrimSpace(commodity))
+}
@@ -0,0 +1,255 @@
+// Package mortality records the animals a farm loses to death or culling.
+// Each loss takes the animals off their herd's count and books the value
+// lost as an expense, so it shows in the finance reports.
+package mortality
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/livestock"
+	"farm4u/service/lock"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kinds of loss
+const (
+	KindDeath = "Death"
+	KindCull  = "Cull"
+)
+
+// Kinds returns the kinds of loss
+func Kinds() []string {
+	return []string{KindDeath, KindCull}
+}
+
+// Input holds the editable mortality record fields. On update, zero values
+// are left unchanged. Count defaults to 1.
+type Input struct {
+	LivestockID string
+	AnimalTag   string
+	Kind        string
+	Count       int
+	Date        *time.Time // Defaults to now
+	Cause       string
+	ValueLost   float64
+	Notes       string
+}
+
+// Service is the mortality domain service
+type Service interface {
+	// Record logs a loss and takes the animals off the herd's count, in a
+	// single transaction
+	Record(ctx context.Context, user *data.User, farmID string, in Input) (*data.MortalityRecord, error)
+	Get(ctx context.Context, user *data.User, mortalityRecordID string) (*data.MortalityRecord, error)
+	List(ctx context.Context, user *data.User, farmID, livestockID string, from, to *time.Time) ([]*data.MortalityRecord, error)
+	// Update corrects a loss; a changed count moves the herd's count by the
+	// difference
+	Update(ctx context.Context, user *data.User, mortalityRecordID string, in Input) (*data.MortalityRecord, error)
+	// Delete removes a loss recorded in error and puts the animals back on
+	// the herd's count
+	Delete(ctx context.Context, user *data.User, mortalityRecordID string) error
+}
+
+// mortalityService implements Service on top of the mortality record and
+// livestock repositories
+type mortalityService struct {
+	models data.Models
+	locks  lock.Checker
+	farms  farm.Service
+}
+
+// New creates the mortality service
+func New(models data.Models, locks lock.Checker, farms farm.Service) Service {
+	return &mortalityService{models: models, locks: locks, farms: farms}
+}
+
+// Record logs a loss on one of the user's farms
+func (s *mortalityService) Record(ctx context.Context, user *data.User, farmID string, in Input) (*data.MortalityRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	herd, err := livestock.OnFarm(ctx, s.models.Livestock, farmID, in.LivestockID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &data.MortalityRecord{
+		FarmID:      farmID,
+		LivestockID: herd.LivestockID,
+		AnimalTag:   strings.TrimSpace(in.AnimalTag),
+		Kind:        in.Kind,
+		Count:       in.Count,
+		Date:        time.Now(),
+		Cause:       strings.TrimSpace(in.Cause),
+		ValueLost:   in.ValueLost,
+		Notes:       in.Notes,
+	}
+	if in.Date != nil {
+		record.Date = *in.Date
+	}
+	if record.Count <= 0 {
+		record.Count = 1
+	}
+	if err := validate(record); err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, farmID, record.Date); err != nil {
+		return nil, err
+	}
+
+	err = s.models.WithTransaction(ctx, func(tx data.Models) error {
+		if err := tx.MortalityRecord.Insert(ctx, record); err != nil {
+			return fmt.Errorf("recording loss: %w", err)
+		}
+		return adjustCount(ctx, tx, record.LivestockID, -record.Count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	herd.Count -= record.Count
+	record.Livestock = herd
+	return record, nil
+}
+
+// Get returns a loss on one of the user's farms
+func (s *mortalityService) Get(ctx context.Context, user *data.User, mortalityRecordID string) (*data.MortalityRecord, error) {
+	record, err := s.models.MortalityRecord.GetByMortalityRecordID(ctx, mortalityRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting mortality record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("mortality record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "mortality record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the losses on one of the user's farms, optionally only those
+// of a herd and dated in [from, to)
+func (s *mortalityService) List(ctx context.Context, user *data.User, farmID, livestockID string, from, to *time.Time) ([]*data.MortalityRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.models.MortalityRecord.GetByFarmID(ctx, farmID, livestockID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting mortality records: %w", err)
+	}
+	return records, nil
+}
+
+// Update changes the non-zero fields of in on a loss
+func (s *mortalityService) Update(ctx context.Context, user *data.User, mortalityRecordID string, in Input) (*data.MortalityRecord, error) {
+	record, err := s.Get(ctx, user, mortalityRecordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return nil, err
+	}
+	if in.LivestockID != "" && in.LivestockID != record.LivestockID {
+		return nil, service.Invalid("a loss cannot be moved to another herd; delete it and record it again")
+	}
+
+	previous := record.Count
+	if in.AnimalTag != "" {
+		record.AnimalTag = strings.TrimSpace(in.AnimalTag)
+	}
+	if in.Kind != "" {
+		record.Kind = in.Kind
+	}
+	if in.Count > 0 {
+		record.Count = in.Count
+	}
+	if in.Date != nil {
+		record.Date = *in.Date
+	}
+	if in.Cause != "" {
+		record.Cause = strings.TrimSpace(in.Cause)
+	}
+	if in.ValueLost > 0 {
+		record.ValueLost = in.ValueLost
+	}
+	if in.Notes != "" {
+		record.Notes = in.Notes
+	}
+	if err := validate(record); err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return nil, err
+	}
+
+	err = s.models.WithTransaction(ctx, func(tx data.Models) error {
+		if err := tx.MortalityRecord.Update(ctx, record); err != nil {
+			return fmt.Errorf("updating mortality record: %w", err)
+		}
+		if record.Count == previous {
+			return nil
+		}
+		return adjustCount(ctx, tx, record.LivestockID, previous-record.Count)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if record.Livestock != nil {
+		record.Livestock.Count += previous - record.Count
+	}
+	return record, nil
+}
+
+// Delete soft deletes a loss and returns its animals to the herd
+func (s *mortalityService) Delete(ctx context.Context, user *data.User, mortalityRecordID string) error {
+	record, err := s.Get(ctx, user, mortalityRecordID)
+	if err != nil {
+		return err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return err
+	}
+
+	return s.models.WithTransaction(ctx, func(tx data.Models) error {
+		if err := tx.MortalityRecord.DeleteByID(ctx, int(record.ID)); err != nil {
+			return fmt.Errorf("deleting mortality record: %w", err)
+		}
+		return adjustCount(ctx, tx, record.LivestockID, record.Count)
+	})
+}
+
+// adjustCount moves a herd's count by delta within tx
+func adjustCount(ctx context.Context, tx data.Models, livestockID string, delta int) error {
+	err := tx.Livestock.AdjustCount(ctx, livestockID, delta)
+	if errors.Is(err, data.ErrStale) {
+		if delta < 0 {
+			return service.Invalid(fmt.Sprintf("the herd has fewer than %d animals left", -delta))
+		}
+		return service.Conflict("the herd has been deleted")
+	}
+	if err != nil {
+		return fmt.Errorf("updating livestock count: %w", err)
+	}
+	return nil
+}
+
+// validate checks the fields of a loss
+func validate(record *data.MortalityRecord) error {
+	switch {
+	case record.Kind != KindDeath && record.Kind != KindCull:
+		return service.Invalid("kind must be one of: " + strings.Join(Kinds(), ", "))
+	case record.Cause == "":
+		return service.Invalid("cause is required")
+	case record.ValueLost < 0:
+		return service.Invalid("value lost must not be negative")
+	case record.AnimalTag != "" && record.Count != 1:
+		return service.Invalid("a loss of a single tagged animal has a count of 1")
+	case record.Date.After(time.Now()):
+		return service.Invalid("date must not be in the future")
+	}
+	return nil
+}
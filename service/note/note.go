@@ -0,0 +1,225 @@
+// Package note keeps the dated observations users append to a farm's
+// records, such as a crop, an animal group or a piece of equipment, as a
+// journal alongside the record's single notes field
+package note
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Record types notes can be written on
+const (
+	RecordCrop        = "crop"
+	RecordLivestock   = "livestock"
+	RecordEquipment   = "equipment"
+	RecordField       = "field"
+	RecordEmployee    = "employee"
+	RecordMaintenance = "maintenance"
+	RecordIncident    = "incident"
+)
+
+// RecordTypes lists the record types notes can be written on
+var RecordTypes = []string{RecordCrop, RecordLivestock, RecordEquipment, RecordField, RecordEmployee, RecordMaintenance, RecordIncident}
+
+// MaxBody is the longest a note can be, in characters
+const MaxBody = 5000
+
+// Input holds the editable note fields. On update, RecordType and RecordID
+// are ignored and zero values are left unchanged. A nil NotedAt means now.
+type Input struct {
+	RecordType string
+	RecordID   string
+	Body       string
+	NotedAt    *time.Time
+}
+
+// Service is the note domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, in Input) (*data.Note, error)
+	Get(ctx context.Context, user *data.User, noteID string) (*data.Note, error)
+	// List returns the notes on a record, newest first
+	List(ctx context.Context, user *data.User, recordType, recordID string) ([]*data.Note, error)
+	// Update corrects a note; only its author can
+	Update(ctx context.Context, user *data.User, noteID string, in Input) (*data.Note, error)
+	Delete(ctx context.Context, user *data.User, noteID string) error
+}
+
+// noteService implements Service on top of the note repository
+type noteService struct {
+	notes   data.NoteInterface
+	records map[string]func(ctx context.Context, id string) (farmID string, err error)
+	farms   farm.Service
+}
+
+// New creates the note service
+func New(models data.Models, farms farm.Service) Service {
+	return &noteService{
+		notes: models.Note,
+		farms: farms,
+		records: map[string]func(context.Context, string) (string, error){
+			RecordCrop: func(ctx context.Context, id string) (string, error) {
+				c, err := models.Crop.GetByCropID(ctx, id)
+				if c == nil || err != nil {
+					return "", err
+				}
+				return c.FarmID, nil
+			},
+			RecordLivestock: func(ctx context.Context, id string) (string, error) {
+				l, err := models.Livestock.GetByLivestockID(ctx, id)
+				if l == nil || err != nil {
+					return "", err
+				}
+				return l.FarmID, nil
+			},
+			RecordEquipment: func(ctx context.Context, id string) (string, error) {
+				e, err := models.Equipment.GetByEquipmentID(ctx, id)
+				if e == nil || err != nil {
+					return "", err
+				}
+				return e.FarmID, nil
+			},
+			RecordField: func(ctx context.Context, id string) (string, error) {
+				f, err := models.Field.GetByFieldID(ctx, id)
+				if f == nil || err != nil {
+					return "", err
+				}
+				return f.FarmID, nil
+			},
+			RecordEmployee: func(ctx context.Context, id string) (string, error) {
+				e, err := models.Employee.GetByEmployeeID(ctx, id)
+				if e == nil || err != nil {
+					return "", err
+				}
+				return e.FarmID, nil
+			},
+			RecordMaintenance: func(ctx context.Context, id string) (string, error) {
+				m, err := models.MaintenanceRecord.GetByMaintenanceRecordID(ctx, id)
+				if m == nil || err != nil {
+					return "", err
+				}
+				return m.FarmID, nil
+			},
+			RecordIncident: func(ctx context.Context, id string) (string, error) {
+				i, err := models.CropIncident.GetByCropIncidentID(ctx, id)
+				if i == nil || err != nil {
+					return "", err
+				}
+				return i.FarmID, nil
+			},
+		},
+	}
+}
+
+// record returns the farm of a record on one of the user's farms
+func (s *noteService) record(ctx context.Context, user *data.User, recordType, recordID string) (string, error) {
+	lookup, ok := s.records[recordType]
+	if !ok {
+		return "", service.Invalid("recordType must be one of " + strings.Join(RecordTypes, ", "))
+	}
+	farmID, err := lookup(ctx, recordID)
+	if err != nil {
+		return "", fmt.Errorf("getting %s: %w", recordType, err)
+	}
+	if farmID == "" {
+		return "", service.NotFound(recordType + " not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, farmID, recordType); err != nil {
+		return "", err
+	}
+	return farmID, nil
+}
+
+// Create implements Service
+func (s *noteService) Create(ctx context.Context, user *data.User, in Input) (*data.Note, error) {
+	farmID, err := s.record(ctx, user, in.RecordType, in.RecordID)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &data.Note{
+		FarmID:     farmID,
+		AuthorID:   user.UserID,
+		RecordType: in.RecordType,
+		RecordID:   in.RecordID,
+		Body:       strings.TrimSpace(in.Body),
+		NotedAt:    time.Now(),
+	}
+	if in.NotedAt != nil {
+		note.NotedAt = *in.NotedAt
+	}
+	if note.Body == "" {
+		return nil, service.Invalid("a note needs a body")
+	}
+	if err := s.notes.Insert(ctx, note); err != nil {
+		return nil, fmt.Errorf("creating note: %w", err)
+	}
+	return note, nil
+}
+
+// Get implements Service
+func (s *noteService) Get(ctx context.Context, user *data.User, noteID string) (*data.Note, error) {
+	note, err := s.notes.GetByNoteID(ctx, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("getting note: %w", err)
+	}
+	if note == nil {
+		return nil, service.NotFound("note not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, note.FarmID, "note"); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// List implements Service
+func (s *noteService) List(ctx context.Context, user *data.User, recordType, recordID string) ([]*data.Note, error) {
+	if _, err := s.record(ctx, user, recordType, recordID); err != nil {
+		return nil, err
+	}
+	notes, err := s.notes.GetByRecord(ctx, recordType, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting notes: %w", err)
+	}
+	return notes, nil
+}
+
+// Update implements Service
+func (s *noteService) Update(ctx context.Context, user *data.User, noteID string, in Input) (*data.Note, error) {
+	note, err := s.Get(ctx, user, noteID)
+	if err != nil {
+		return nil, err
+	}
+	if note.AuthorID != user.UserID {
+		return nil, service.Forbidden("only the author can edit a note")
+	}
+
+	if body := strings.TrimSpace(in.Body); body != "" {
+		note.Body = body
+	}
+	if in.NotedAt != nil {
+		note.NotedAt = *in.NotedAt
+	}
+
+	if err := s.notes.Update(ctx, note); err != nil {
+		return nil, fmt.Errorf("updating note: %w", err)
+	}
+	return note, nil
+}
+
+// Delete implements Service
+func (s *noteService) Delete(ctx context.Context, user *data.User, noteID string) error {
+	note, err := s.Get(ctx, user, noteID)
+	if err != nil {
+		return err
+	}
+	if err := s.notes.DeleteByID(ctx, int(note.ID)); err != nil {
+		return fmt.Errorf("deleting note: %w", err)
+	}
+	return nil
+}
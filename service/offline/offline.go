@@ -0,0 +1,423 @@
+// Package offline syncs a farm's fields, crops, livestock and employees with
+// clients that work without a connection. A client pulls the records changed
+// since its last cursor, tombstones included, and pushes the changes it made
+// while offline. Pushed changes are resolved as follows:
+//
+//   - a create carrying a clientId is applied once; pushing it again returns
+//     the record it made
+//   - an update or delete must carry the version the client last pulled; if
+//     the record has changed since, the server's record wins and is returned
+//     with a conflict for the client to reapply its change to
+//   - an update to a record deleted on the server is dropped; the deletion wins
+//   - deleting a record that is already deleted succeeds
+package offline
+
+import (
+	"context"
+	"encoding/base64"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/crop"
+	"farm4u/service/farm"
+	"farm4u/service/field"
+	"farm4u/service/livestock"
+	"farm4u/service/workforce"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Push operations
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+// Push result statuses
+const (
+	StatusApplied  = "applied"
+	StatusConflict = "conflict" // The server's record changed; Record is its current state
+	StatusDeleted  = "deleted"  // The record was deleted on the server
+	StatusRejected = "rejected" // The change failed its checks; Message says why
+)
+
+// PullLimit is the most changes of each entity a pull returns
+const PullLimit = 1000
+
+// cursorOverlap is how far before a pull started its cursor points, so a
+// write committed while the pull ran is read by the next one. Clients see
+// those records twice and apply them by version.
+const cursorOverlap = 5 * time.Second
+
+// Pull is the changes of a farm since a cursor
+type Pull struct {
+	Cursor  string            `json:"cursor"`  // Pass as since on the next pull
+	HasMore bool              `json:"hasMore"` // Pull again straight away for the rest
+	Changes []data.SyncChange `json:"changes"`
+}
+
+// Change is one change a client made offline
+type Change struct {
+	Entity   string
+	Op       string
+	ID       string // Record changed, for update and delete
+	ClientID string // Client's own ID for a created record
+	Version  int    // Version the client last pulled, for update and delete
+	// Input is a field.Input, crop.Input, livestock.Input or
+	// workforce.EmployeeInput, for create and update
+	Input any
+}
+
+// Result is the outcome of one pushed change
+type Result struct {
+	Index    int    `json:"index"`
+	Entity   string `json:"entity"`
+	Op       string `json:"op"`
+	ID       string `json:"id,omitempty"`
+	ClientID string `json:"clientId,omitempty"`
+	Status   string `json:"status"`
+	Message  string `json:"message,omitempty"`
+	Record   any    `json:"record,omitempty"`
+}
+
+// Service is the offline sync domain service
+type Service interface {
+	// Pull returns a farm's changes since cursor; an empty cursor pulls
+	// every record
+	Pull(ctx context.Context, user *data.User, farmID, cursor string) (*Pull, error)
+	// Push applies a client's changes in order, each with its own result
+	Push(ctx context.Context, user *data.User, farmID string, changes []Change) ([]Result, error)
+}
+
+// entity is how a synced entity is read and written through its own service
+type entity struct {
+	get    func(ctx context.Context, user *data.User, id string) (record any, farmID string, version int, err error)
+	create func(ctx context.Context, user *data.User, farmID string, in any) (record any, id string, err error)
+	update func(ctx context.Context, user *data.User, id string, version int, in any) (any, error)
+	delete func(ctx context.Context, user *data.User, id string) error
+}
+
+// offlineService implements Service on top of the entity services
+type offlineService struct {
+	syncs    data.SyncInterface
+	farms    farm.Service
+	entities map[string]entity
+}
+
+// New creates the offline sync service
+func New(syncs data.SyncInterface, fields field.Service, crops crop.Service, herds livestock.Service, workers workforce.Service, farms farm.Service) Service {
+	return &offlineService{
+		syncs: syncs,
+		farms: farms,
+		entities: map[string]entity{
+			data.SyncField: {
+				get: func(ctx context.Context, user *data.User, id string) (any, string, int, error) {
+					f, err := fields.Get(ctx, user, id)
+					if err != nil {
+						return nil, "", 0, err
+					}
+					return f, f.FarmID, f.Version, nil
+				},
+				create: func(ctx context.Context, user *data.User, farmID string, in any) (any, string, error) {
+					f, err := fields.Create(ctx, user, farmID, in.(field.Input))
+					if err != nil {
+						return nil, "", err
+					}
+					return f, f.FieldID, nil
+				},
+				update: func(ctx context.Context, user *data.User, id string, version int, in any) (any, error) {
+					input := in.(field.Input)
+					input.Version = version
+					return fields.Update(ctx, user, id, input)
+				},
+				delete: fields.Delete,
+			},
+			data.SyncCrop: {
+				get: func(ctx context.Context, user *data.User, id string) (any, string, int, error) {
+					c, err := crops.Get(ctx, user, id)
+					if err != nil {
+						return nil, "", 0, err
+					}
+					return c, c.FarmID, c.Version, nil
+				},
+				create: func(ctx context.Context, user *data.User, farmID string, in any) (any, string, error) {
+					c, err := crops.Create(ctx, user, farmID, in.(crop.Input))
+					if err != nil {
+						return nil, "", err
+					}
+					return c, c.CropID, nil
+				},
+				update: func(ctx context.Context, user *data.User, id string, version int, in any) (any, error) {
+					input := in.(crop.Input)
+					input.Version = version
+					return crops.Update(ctx, user, id, input)
+				},
+				delete: crops.Delete,
+			},
+			data.SyncLivestock: {
+				get: func(ctx context.Context, user *data.User, id string) (any, string, int, error) {
+					l, err := herds.Get(ctx, user, id)
+					if err != nil {
+						return nil, "", 0, err
+					}
+					return l, l.FarmID, l.Version, nil
+				},
+				create: func(ctx context.Context, user *data.User, farmID string, in any) (any, string, error) {
+					l, err := herds.Create(ctx, user, farmID, in.(livestock.Input))
+					if err != nil {
+						return nil, "", err
+					}
+					return l, l.LivestockID, nil
+				},
+				update: func(ctx context.Context, user *data.User, id string, version int, in any) (any, error) {
+					input := in.(livestock.Input)
+					input.Version = version
+					return herds.Update(ctx, user, id, input)
+				},
+				delete: herds.Delete,
+			},
+			data.SyncEmployee: {
+				get: func(ctx context.Context, user *data.User, id string) (any, string, int, error) {
+					e, err := workers.GetEmployee(ctx, user, id)
+					if err != nil {
+						return nil, "", 0, err
+					}
+					return e, e.FarmID, e.Version, nil
+				},
+				create: func(ctx context.Context, user *data.User, farmID string, in any) (any, string, error) {
+					e, err := workers.CreateEmployee(ctx, user, farmID, in.(workforce.EmployeeInput))
+					if err != nil {
+						return nil, "", err
+					}
+					return e, e.EmployeeID, nil
+				},
+				update: func(ctx context.Context, user *data.User, id string, version int, in any) (any, error) {
+					input := in.(workforce.EmployeeInput)
+					input.Version = version
+					return workers.UpdateEmployee(ctx, user, id, input)
+				},
+				delete: workers.DeleteEmployee,
+			},
+		},
+	}
+}
+
+// Pull returns the changes of one of the user's farms since cursor
+func (s *offlineService) Pull(ctx context.Context, user *data.User, farmID, cursor string) (*Pull, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	since, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	changes, more, err := s.syncs.Changes(ctx, farmID, since, PullLimit)
+	if err != nil {
+		return nil, fmt.Errorf("getting changes: %w", err)
+	}
+
+	// A partial pull resumes at its last change; a complete one at when it
+	// started, less the overlap
+	next := started.Add(-cursorOverlap)
+	if more {
+		next = changes[len(changes)-1].ChangedAt
+	}
+	if next.Before(since) {
+		next = since
+	}
+	if changes == nil {
+		changes = []data.SyncChange{}
+	}
+	return &Pull{Cursor: encodeCursor(next), HasMore: more, Changes: changes}, nil
+}
+
+// Push applies a client's changes to one of the user's farms in order. A
+// change that fails its checks is rejected and the rest still applied.
+func (s *offlineService) Push(ctx context.Context, user *data.User, farmID string, changes []Change) ([]Result, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(changes))
+	for i, change := range changes {
+		result, err := s.apply(ctx, user, farmID, change)
+		if err != nil {
+			return nil, err
+		}
+		result.Index = i
+		results[i] = result
+	}
+	return results, nil
+}
+
+// apply applies one change. Only unexpected errors are returned; the
+// others become the change's result.
+func (s *offlineService) apply(ctx context.Context, user *data.User, farmID string, change Change) (Result, error) {
+	result := Result{Entity: change.Entity, Op: change.Op, ID: change.ID, ClientID: change.ClientID}
+	e, ok := s.entities[change.Entity]
+	if !ok {
+		return rejected(result, fmt.Sprintf("unknown entity %q", change.Entity)), nil
+	}
+
+	var err error
+	switch change.Op {
+	case OpCreate:
+		err = s.create(ctx, e, user, farmID, change, &result)
+	case OpUpdate, OpDelete:
+		err = s.change(ctx, e, user, farmID, change, &result)
+	default:
+		return rejected(result, fmt.Sprintf("unknown op %q", change.Op)), nil
+	}
+
+	if err == nil {
+		return result, nil
+	}
+	if service.KindOf(err) == service.KindInternal {
+		return result, err
+	}
+	if service.CurrentOf(err) != nil {
+		result.Status, result.Message, result.Record = StatusConflict, err.Error(), service.CurrentOf(err)
+		return result, nil
+	}
+	return rejected(result, err.Error()), nil
+}
+
+// create applies a create, once per client ID
+func (s *offlineService) create(ctx context.Context, e entity, user *data.User, farmID string, change Change, result *Result) error {
+	if change.Input == nil {
+		return service.Invalid("data is required")
+	}
+
+	if change.ClientID != "" {
+		mapping, err := s.syncs.GetMapping(ctx, farmID, change.Entity, change.ClientID)
+		if err != nil {
+			return fmt.Errorf("getting sync mapping: %w", err)
+		}
+		if mapping != nil {
+			result.ID = mapping.RecordID
+			return s.current(ctx, e, user, farmID, change.Entity, result)
+		}
+	}
+
+	record, id, err := e.create(ctx, user, farmID, change.Input)
+	if err != nil {
+		return err
+	}
+	if change.ClientID != "" {
+		mapping := &data.SyncMapping{FarmID: farmID, Entity: change.Entity, ClientID: change.ClientID, RecordID: id}
+		if err := s.syncs.InsertMapping(ctx, mapping); err != nil {
+			return fmt.Errorf("creating sync mapping: %w", err)
+		}
+	}
+	result.ID, result.Status, result.Record = id, StatusApplied, record
+	return nil
+}
+
+// current sets result to the record a repeated create already made
+func (s *offlineService) current(ctx context.Context, e entity, user *data.User, farmID, what string, result *Result) error {
+	record, _, _, err := e.get(ctx, user, result.ID)
+	if service.KindOf(err) == service.KindNotFound {
+		return s.gone(ctx, what, result)
+	}
+	if err != nil {
+		return err
+	}
+	result.Status, result.Record = StatusApplied, record
+	return nil
+}
+
+// change applies an update or delete made against the version the client
+// last pulled
+func (s *offlineService) change(ctx context.Context, e entity, user *data.User, farmID string, change Change, result *Result) error {
+	if change.ID == "" {
+		return service.Invalid("id is required")
+	}
+	if change.Version < 1 {
+		return service.Invalid("version is required")
+	}
+	if change.Op == OpUpdate && change.Input == nil {
+		return service.Invalid("data is required")
+	}
+
+	record, recordFarm, version, err := e.get(ctx, user, change.ID)
+	if service.KindOf(err) == service.KindNotFound {
+		if change.Op == OpDelete {
+			deleted, err := s.syncs.Deleted(ctx, change.Entity, change.ID)
+			if err != nil {
+				return fmt.Errorf("checking deleted %s: %w", change.Entity, err)
+			}
+			if deleted {
+				result.Status, result.Message = StatusApplied, change.Entity+" was already deleted"
+				return nil
+			}
+		}
+		return s.gone(ctx, change.Entity, result)
+	}
+	if err != nil {
+		return err
+	}
+	if recordFarm != farmID {
+		return service.Invalid(change.Entity + " does not belong to this farm")
+	}
+	if version != change.Version {
+		return service.Stale(change.Entity, record)
+	}
+
+	if change.Op == OpDelete {
+		if err := e.delete(ctx, user, change.ID); err != nil {
+			return err
+		}
+		result.Status = StatusApplied
+		return nil
+	}
+	updated, err := e.update(ctx, user, change.ID, change.Version, change.Input)
+	if err != nil {
+		return err
+	}
+	result.Status, result.Record = StatusApplied, updated
+	return nil
+}
+
+// gone sets result for a record that was deleted on the server, or reports
+// it missing when it never existed
+func (s *offlineService) gone(ctx context.Context, what string, result *Result) error {
+	deleted, err := s.syncs.Deleted(ctx, what, result.ID)
+	if err != nil {
+		return fmt.Errorf("checking deleted %s: %w", what, err)
+	}
+	if !deleted {
+		return service.NotFound(what + " not found")
+	}
+	result.Status, result.Message = StatusDeleted, what+" was deleted on the server"
+	return nil
+}
+
+// rejected marks result as rejected for message
+func rejected(result Result, message string) Result {
+	result.Status, result.Message = StatusRejected, message
+	return result
+}
+
+// encodeCursor makes the opaque cursor for t
+func encodeCursor(t time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(t.UnixNano(), 10)))
+}
+
+// decodeCursor reads a cursor made by encodeCursor; empty is the zero time
+func decodeCursor(cursor string) (time.Time, error) {
+	if cursor == "" {
+		return time.Time{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, service.Invalid("invalid sync cursor")
+	}
+	nanos, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || nanos < 0 {
+		return time.Time{}, service.Invalid("invalid sync cursor")
+	}
+	return time.Unix(0, nanos), nil
+}
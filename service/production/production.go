@@ -0,0 +1,348 @@
+// Package production logs what a farm's livestock produce day by day, milk
+// from dairy cows and goats and eggs from layers above all, and totals it
+// into weekly and monthly trends.
+package production
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/livestock"
+	"farm4u/service/lock"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Product types
+const (
+	ProductMilk  = "Milk"
+	ProductEggs  = "Eggs"
+	ProductWool  = "Wool"
+	ProductHoney = "Honey"
+	ProductOther = "Other"
+)
+
+// Trend intervals
+const (
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// trendPeriods is how many weeks or months a trend covers when no start is
+// given
+const trendPeriods = 12
+
+// ProductTypes returns the product types
+func ProductTypes() []string {
+	return []string{ProductMilk, ProductEggs, ProductWool, ProductHoney, ProductOther}
+}
+
+// defaultUnits is the unit each product is counted in unless another is given
+var defaultUnits = map[string]string{
+	ProductMilk:  "Litres",
+	ProductEggs:  "Pieces",
+	ProductWool:  "Kg",
+	ProductHoney: "Kg",
+}
+
+// Input holds the editable production record fields. On update, zero values
+// are left unchanged. Unit defaults to the product's usual unit.
+type Input struct {
+	LivestockID string
+	AnimalTag   string
+	ProductType string
+	Date        *time.Time // Defaults to today
+	Quantity    float64
+	Unit        string
+	Notes       string
+}
+
+// Trend is a farm's production totalled by week or month
+type Trend struct {
+	FarmID   string                 `json:"farmId"`
+	Interval string                 `json:"interval"`
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"` // Exclusive
+	Totals   []data.ProductionTotal `json:"totals"`
+}
+
+// Service is the production domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.ProductionRecord, error)
+	// CreateBatch saves a day's or several days' entries in a single
+	// transaction. An entry for a herd, animal, product and day already
+	// recorded replaces its quantity, so a daily sheet can be sent again
+	// after corrections. An item that fails its checks gets its error in
+	// the same slot of the returned errors and is left out.
+	CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.ProductionRecord, []error, error)
+	Get(ctx context.Context, user *data.User, productionRecordID string) (*data.ProductionRecord, error)
+	List(ctx context.Context, user *data.User, farmID string, filter data.ProductionFilter) ([]*data.ProductionRecord, error)
+	Update(ctx context.Context, user *data.User, productionRecordID string, in Input) (*data.ProductionRecord, error)
+	Delete(ctx context.Context, user *data.User, productionRecordID string) error
+	// Trend totals a farm's production by week or month. A nil from goes
+	// back 12 periods and a nil to runs to the end of today.
+	Trend(ctx context.Context, user *data.User, farmID, interval string, filter data.ProductionFilter) (*Trend, error)
+}
+
+// productionService implements Service on top of the production record and
+// livestock repositories
+type productionService struct {
+	records   data.ProductionRecordInterface
+	livestock data.LivestockInterface
+	locks     lock.Checker
+	farms     farm.Service
+}
+
+// New creates the production service
+func New(records data.ProductionRecordInterface, livestock data.LivestockInterface, locks lock.Checker, farms farm.Service) Service {
+	return &productionService{records: records, livestock: livestock, locks: locks, farms: farms}
+}
+
+// Create logs production on one of the user's farms
+func (s *productionService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.ProductionRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	record, err := s.newRecord(ctx, farmID, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.records.SaveMany(ctx, []*data.ProductionRecord{record}); err != nil {
+		return nil, fmt.Errorf("creating production record: %w", err)
+	}
+	return record, nil
+}
+
+// CreateBatch saves several entries on one of the user's farms in a single
+// transaction, leaving out those that fail their checks
+func (s *productionService) CreateBatch(ctx context.Context, user *data.User, farmID string, ins []Input) ([]*data.ProductionRecord, []error, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	records := make([]*data.ProductionRecord, len(ins))
+	errs := make([]error, len(ins))
+	var dates []time.Time
+	for i, in := range ins {
+		records[i], errs[i] = s.newRecord(ctx, farmID, in)
+		if errs[i] != nil && service.KindOf(errs[i]) == service.KindInternal {
+			return nil, nil, errs[i]
+		}
+		if records[i] != nil {
+			dates = append(dates, records[i].Date)
+		}
+	}
+
+	existing, err := s.records.GetOnDates(ctx, farmID, dates)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting production records: %w", err)
+	}
+	saved := map[string]*data.ProductionRecord{}
+	for _, record := range existing {
+		saved[entryKey(record)] = record
+	}
+
+	// Entries repeated within the batch replace each other too, so each
+	// record is saved once
+	var valid []*data.ProductionRecord
+	queued := map[*data.ProductionRecord]bool{}
+	for i, record := range records {
+		if record == nil {
+			continue
+		}
+		key := entryKey(record)
+		if previous, ok := saved[key]; ok {
+			previous.Quantity, previous.Unit, previous.Notes = record.Quantity, record.Unit, record.Notes
+			records[i] = previous
+		} else {
+			saved[key] = record
+		}
+		if !queued[records[i]] {
+			queued[records[i]] = true
+			valid = append(valid, records[i])
+		}
+	}
+	if len(valid) > 0 {
+		if err := s.records.SaveMany(ctx, valid); err != nil {
+			return nil, nil, fmt.Errorf("saving production records: %w", err)
+		}
+	}
+	return records, errs, nil
+}
+
+// Get returns a production record on one of the user's farms
+func (s *productionService) Get(ctx context.Context, user *data.User, productionRecordID string) (*data.ProductionRecord, error) {
+	record, err := s.records.GetByProductionRecordID(ctx, productionRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting production record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("production record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "production record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the production records of one of the user's farms
+func (s *productionService) List(ctx context.Context, user *data.User, farmID string, filter data.ProductionFilter) ([]*data.ProductionRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.records.GetByFarmID(ctx, farmID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("getting production records: %w", err)
+	}
+	return records, nil
+}
+
+// Update changes the non-zero fields of in on a production record
+func (s *productionService) Update(ctx context.Context, user *data.User, productionRecordID string, in Input) (*data.ProductionRecord, error) {
+	record, err := s.Get(ctx, user, productionRecordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return nil, err
+	}
+
+	if in.LivestockID != "" && in.LivestockID != record.LivestockID {
+		herd, err := livestock.OnFarm(ctx, s.livestock, record.FarmID, in.LivestockID)
+		if err != nil {
+			return nil, err
+		}
+		record.LivestockID = herd.LivestockID
+	}
+	if in.AnimalTag != "" {
+		record.AnimalTag = strings.TrimSpace(in.AnimalTag)
+	}
+	if in.ProductType != "" {
+		record.ProductType = in.ProductType
+	}
+	if in.Date != nil {
+		record.Date = service.Day(*in.Date)
+		if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+			return nil, err
+		}
+	}
+	if in.Quantity > 0 {
+		record.Quantity = in.Quantity
+	}
+	if in.Unit != "" {
+		record.Unit = in.Unit
+	}
+	if in.Notes != "" {
+		record.Notes = in.Notes
+	}
+
+	if err := s.records.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("updating production record: %w", err)
+	}
+	return record, nil
+}
+
+// Delete soft deletes a production record
+func (s *productionService) Delete(ctx context.Context, user *data.User, productionRecordID string) error {
+	record, err := s.Get(ctx, user, productionRecordID)
+	if err != nil {
+		return err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return err
+	}
+	if err := s.records.DeleteByID(ctx, int(record.ID)); err != nil {
+		return fmt.Errorf("deleting production record: %w", err)
+	}
+	return nil
+}
+
+// Trend implements Service
+func (s *productionService) Trend(ctx context.Context, user *data.User, farmID, interval string, filter data.ProductionFilter) (*Trend, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if interval == "" {
+		interval = IntervalWeek
+	}
+	if interval != IntervalWeek && interval != IntervalMonth {
+		return nil, service.Invalid("interval must be week or month")
+	}
+
+	to := service.Day(time.Now()).AddDate(0, 0, 1)
+	if filter.To != nil {
+		to = service.Day(*filter.To)
+	}
+	from := periodStart(to.AddDate(0, 0, -1), interval)
+	if interval == IntervalWeek {
+		from = from.AddDate(0, 0, -7*(trendPeriods-1))
+	} else {
+		from = from.AddDate(0, -(trendPeriods - 1), 0)
+	}
+	if filter.From != nil {
+		from = service.Day(*filter.From)
+	}
+	if !to.After(from) {
+		return nil, service.Invalid("to must be after from")
+	}
+	filter.From, filter.To = &from, &to
+
+	totals, err := s.records.Totals(ctx, farmID, interval, filter)
+	if err != nil {
+		return nil, fmt.Errorf("totalling production: %w", err)
+	}
+	return &Trend{FarmID: farmID, Interval: interval, From: from, To: to, Totals: totals}, nil
+}
+
+// newRecord builds a production record on farmID from in
+func (s *productionService) newRecord(ctx context.Context, farmID string, in Input) (*data.ProductionRecord, error) {
+	herd, err := livestock.OnFarm(ctx, s.livestock, farmID, in.LivestockID)
+	if err != nil {
+		return nil, err
+	}
+	if in.Quantity <= 0 {
+		return nil, service.Invalid("quantity must be greater than 0")
+	}
+
+	record := &data.ProductionRecord{
+		FarmID:      farmID,
+		LivestockID: herd.LivestockID,
+		AnimalTag:   strings.TrimSpace(in.AnimalTag),
+		ProductType: in.ProductType,
+		Date:        service.Day(time.Now()),
+		Quantity:    in.Quantity,
+		Unit:        in.Unit,
+		Notes:       in.Notes,
+	}
+	if in.Date != nil {
+		record.Date = service.Day(*in.Date)
+	}
+	if record.Unit == "" {
+		record.Unit = defaultUnits[record.ProductType]
+	}
+	if record.Unit == "" {
+		return nil, service.Invalid(fmt.Sprintf("unit is required for %s", record.ProductType))
+	}
+	if err := s.locks.Check(ctx, farmID, record.Date); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// entryKey identifies the entry for a herd, animal, product and day, of
+// which a daily sheet holds at most one
+func entryKey(record *data.ProductionRecord) string {
+	return strings.Join([]string{record.LivestockID, strings.ToLower(record.AnimalTag), record.ProductType,
+		record.Date.Format("2006-01-02")}, "|")
+}
+
+// periodStart returns the first day of the week (Monday) or month t falls in
+func periodStart(t time.Time, interval string) time.Time {
+	t = service.Day(t)
+	if interval == IntervalMonth {
+		return t.AddDate(0, 0, 1-t.Day())
+	}
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
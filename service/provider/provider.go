@@ -0,0 +1,213 @@
+// Package provider keeps a farm's directory of contractors and service
+// providers, such as its vet, mechanic and transporter. Maintenance records
+// and crop incidents name the provider who did the work, so a provider's
+// jobs answer "who fixed the pump last time".
+package provider
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Provider categories
+const (
+	CategoryVeterinarian = "Veterinarian"
+	CategoryMechanic     = "Mechanic"
+	CategoryTransporter  = "Transporter"
+	CategoryAgronomist   = "Agronomist"
+	CategoryElectrician  = "Electrician"
+	CategoryOther        = "Other"
+)
+
+// Categories lists the provider categories
+var Categories = []string{CategoryVeterinarian, CategoryMechanic, CategoryTransporter, CategoryAgronomist, CategoryElectrician, CategoryOther}
+
+// Input holds the editable provider fields. On update, zero values are left
+// unchanged.
+type Input struct {
+	Name        string
+	Category    string
+	ContactName string
+	PhoneNumber string
+	Email       string
+	Address     string
+	Notes       string
+}
+
+// Jobs is the work a provider did for a farm, most recent first
+type Jobs struct {
+	Maintenance []*data.MaintenanceRecord `json:"maintenance"`
+	Incidents   []*data.CropIncident      `json:"incidents"`
+}
+
+// Service is the service provider domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.ServiceProvider, error)
+	Get(ctx context.Context, user *data.User, serviceProviderID string) (*data.ServiceProvider, error)
+	// List returns a farm's providers by name, optionally of one category
+	List(ctx context.Context, user *data.User, farmID, category string) ([]*data.ServiceProvider, error)
+	Update(ctx context.Context, user *data.User, serviceProviderID string, in Input) (*data.ServiceProvider, error)
+	// Delete removes a provider from the directory. The records of work it
+	// did keep its name in their performedBy.
+	Delete(ctx context.Context, user *data.User, serviceProviderID string) error
+	// Jobs returns the maintenance records and crop incidents naming a
+	// provider
+	Jobs(ctx context.Context, user *data.User, serviceProviderID string) (*Jobs, error)
+}
+
+// providerService implements Service on top of the service provider
+// repository
+type providerService struct {
+	providers   data.ServiceProviderInterface
+	maintenance data.MaintenanceRecordInterface
+	incidents   data.CropIncidentInterface
+	farms       farm.Service
+}
+
+// New creates the service provider service
+func New(providers data.ServiceProviderInterface, maintenance data.MaintenanceRecordInterface,
+	incidents data.CropIncidentInterface, farms farm.Service) Service {
+	return &providerService{providers: providers, maintenance: maintenance, incidents: incidents, farms: farms}
+}
+
+// Lookup returns the provider serviceProviderID names, which must be in the
+// directory of farmID, for a record of that farm to link to. An empty ID
+// returns nil.
+func Lookup(ctx context.Context, providers data.ServiceProviderInterface, farmID, serviceProviderID string) (*data.ServiceProvider, error) {
+	if serviceProviderID == "" {
+		return nil, nil
+	}
+	provider, err := providers.GetByServiceProviderID(ctx, serviceProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting service provider: %w", err)
+	}
+	if provider == nil || provider.FarmID != farmID {
+		return nil, service.Invalid("service provider not found on this farm")
+	}
+	return provider, nil
+}
+
+// Create implements Service
+func (s *providerService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.ServiceProvider, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	provider := &data.ServiceProvider{
+		FarmID:      farmID,
+		Name:        strings.TrimSpace(in.Name),
+		Category:    in.Category,
+		ContactName: in.ContactName,
+		PhoneNumber: in.PhoneNumber,
+		Email:       in.Email,
+		Address:     in.Address,
+		Notes:       in.Notes,
+	}
+	if !slices.Contains(Categories, provider.Category) {
+		return nil, service.Invalid("category must be one of " + strings.Join(Categories, ", "))
+	}
+	if err := s.providers.Insert(ctx, provider); err != nil {
+		return nil, fmt.Errorf("creating service provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Get implements Service
+func (s *providerService) Get(ctx context.Context, user *data.User, serviceProviderID string) (*data.ServiceProvider, error) {
+	provider, err := s.providers.GetByServiceProviderID(ctx, serviceProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting service provider: %w", err)
+	}
+	if provider == nil {
+		return nil, service.NotFound("service provider not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, provider.FarmID, "service provider"); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// List implements Service
+func (s *providerService) List(ctx context.Context, user *data.User, farmID, category string) ([]*data.ServiceProvider, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	providers, err := s.providers.GetByFarmID(ctx, farmID, category)
+	if err != nil {
+		return nil, fmt.Errorf("getting service providers: %w", err)
+	}
+	return providers, nil
+}
+
+// Update implements Service
+func (s *providerService) Update(ctx context.Context, user *data.User, serviceProviderID string, in Input) (*data.ServiceProvider, error) {
+	provider, err := s.Get(ctx, user, serviceProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(in.Name); name != "" {
+		provider.Name = name
+	}
+	if in.Category != "" {
+		if !slices.Contains(Categories, in.Category) {
+			return nil, service.Invalid("category must be one of " + strings.Join(Categories, ", "))
+		}
+		provider.Category = in.Category
+	}
+	if in.ContactName != "" {
+		provider.ContactName = in.ContactName
+	}
+	if in.PhoneNumber != "" {
+		provider.PhoneNumber = in.PhoneNumber
+	}
+	if in.Email != "" {
+		provider.Email = in.Email
+	}
+	if in.Address != "" {
+		provider.Address = in.Address
+	}
+	if in.Notes != "" {
+		provider.Notes = in.Notes
+	}
+
+	if err := s.providers.Update(ctx, provider); err != nil {
+		return nil, fmt.Errorf("updating service provider: %w", err)
+	}
+	return provider, nil
+}
+
+// Delete implements Service
+func (s *providerService) Delete(ctx context.Context, user *data.User, serviceProviderID string) error {
+	provider, err := s.Get(ctx, user, serviceProviderID)
+	if err != nil {
+		return err
+	}
+	if err := s.providers.DeleteByID(ctx, int(provider.ID)); err != nil {
+		return fmt.Errorf("deleting service provider: %w", err)
+	}
+	return nil
+}
+
+// Jobs implements Service
+func (s *providerService) Jobs(ctx context.Context, user *data.User, serviceProviderID string) (*Jobs, error) {
+	provider, err := s.Get(ctx, user, serviceProviderID)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenance, err := s.maintenance.GetByServiceProviderID(ctx, provider.ServiceProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting maintenance records: %w", err)
+	}
+	incidents, err := s.incidents.GetByFarmID(ctx, provider.FarmID, data.CropIncidentFilter{ServiceProviderID: provider.ServiceProviderID})
+	if err != nil {
+		return nil, fmt.Errorf("getting crop incidents: %w", err)
+	}
+	return &Jobs{Maintenance: maintenance, Incidents: incidents}, nil
+}
@@ -0,0 +1,437 @@
+// Package purchase manages a farm's suppliers and the purchase orders it
+// places with them. Receiving an order puts its lines into inventory and its
+// cost into the finance ledger.
+package purchase
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+)
+
+// Order statuses
+const (
+	StatusDraft     = "Draft"
+	StatusOrdered   = "Ordered"
+	StatusReceived  = "Received"
+	StatusCancelled = "Cancelled"
+)
+
+// SupplierInput holds the editable supplier fields. On update, zero values
+// are left unchanged.
+type SupplierInput struct {
+	Name        string
+	ContactName string
+	PhoneNumber string
+	Email       string
+	Address     string
+	Notes       string
+}
+
+// LineInput is one item on an order
+type LineInput struct {
+	InventoryItemID string
+	Quantity        float64
+	UnitCost        float64
+}
+
+// OrderInput holds the editable order fields. On update, zero values are left
+// unchanged and a non-nil Lines replaces the order's lines.
+type OrderInput struct {
+	SupplierID   string
+	Reference    string
+	ExpectedDate *time.Time
+	Notes        string
+	Lines        []LineInput
+}
+
+// ReceiptLineInput gives the batch details of one line as it is received
+type ReceiptLineInput struct {
+	LineID      string
+	BatchNumber string
+	ExpiryDate  *time.Time
+}
+
+// ReceiptInput records the delivery of an order. Lines without receipt
+// details are received without a batch number or expiry.
+type ReceiptInput struct {
+	ReceivedAt *time.Time
+	Lines      []ReceiptLineInput
+}
+
+// Service is the purchasing domain service
+type Service interface {
+	CreateSupplier(ctx context.Context, user *data.User, farmID string, in SupplierInput) (*data.Supplier, error)
+	GetSupplier(ctx context.Context, user *data.User, supplierID string) (*data.Supplier, error)
+	ListSuppliers(ctx context.Context, user *data.User, farmID string) ([]*data.Supplier, error)
+	UpdateSupplier(ctx context.Context, user *data.User, supplierID string, in SupplierInput) (*data.Supplier, error)
+	DeleteSupplier(ctx context.Context, user *data.User, supplierID string) error
+
+	CreateOrder(ctx context.Context, user *data.User, farmID string, in OrderInput) (*data.PurchaseOrder, error)
+	GetOrder(ctx context.Context, user *data.User, purchaseOrderID string) (*data.PurchaseOrder, error)
+	ListOrders(ctx context.Context, user *data.User, farmID, status string) ([]*data.PurchaseOrder, error)
+	UpdateOrder(ctx context.Context, user *data.User, purchaseOrderID string, in OrderInput) (*data.PurchaseOrder, error)
+	DeleteOrder(ctx context.Context, user *data.User, purchaseOrderID string) error
+	PlaceOrder(ctx context.Context, user *data.User, purchaseOrderID string) (*data.PurchaseOrder, error)
+	CancelOrder(ctx context.Context, user *data.User, purchaseOrderID string) (*data.PurchaseOrder, error)
+	ReceiveOrder(ctx context.Context, user *data.User, purchaseOrderID string, in ReceiptInput) (*data.PurchaseOrder, error)
+}
+
+// purchaseService implements Service on top of the supplier and purchase
+// order repositories
+type purchaseService struct {
+	suppliers data.SupplierInterface
+	orders    data.PurchaseOrderInterface
+	items     data.InventoryItemInterface
+	locks     lock.Checker
+	farms     farm.Service
+}
+
+// New creates the purchasing service
+func New(suppliers data.SupplierInterface, orders data.PurchaseOrderInterface, items data.InventoryItemInterface, locks lock.Checker, farms farm.Service) Service {
+	return &purchaseService{suppliers: suppliers, orders: orders, items: items, locks: locks, farms: farms}
+}
+
+// CreateSupplier adds a supplier to one of the user's farms
+func (s *purchaseService) CreateSupplier(ctx context.Context, user *data.User, farmID string, in SupplierInput) (*data.Supplier, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	supplier := &data.Supplier{
+		FarmID:      farmID,
+		Name:        in.Name,
+		ContactName: in.ContactName,
+		PhoneNumber: in.PhoneNumber,
+		Email:       in.Email,
+		Address:     in.Address,
+		Notes:       in.Notes,
+	}
+	if err := s.suppliers.Insert(ctx, supplier); err != nil {
+		return nil, fmt.Errorf("creating supplier: %w", err)
+	}
+	return supplier, nil
+}
+
+// GetSupplier returns a supplier of one of the user's farms
+func (s *purchaseService) GetSupplier(ctx context.Context, user *data.User, supplierID string) (*data.Supplier, error) {
+	supplier, err := s.suppliers.GetBySupplierID(ctx, supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("getting supplier: %w", err)
+	}
+	if supplier == nil {
+		return nil, service.NotFound("supplier not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, supplier.FarmID, "supplier"); err != nil {
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// ListSuppliers returns a farm's suppliers
+func (s *purchaseService) ListSuppliers(ctx context.Context, user *data.User, farmID string) ([]*data.Supplier, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	suppliers, err := s.suppliers.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting suppliers: %w", err)
+	}
+	return suppliers, nil
+}
+
+// UpdateSupplier changes the non-zero fields of in on a supplier
+func (s *purchaseService) UpdateSupplier(ctx context.Context, user *data.User, supplierID string, in SupplierInput) (*data.Supplier, error) {
+	supplier, err := s.GetSupplier(ctx, user, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		supplier.Name = in.Name
+	}
+	if in.ContactName != "" {
+		supplier.ContactName = in.ContactName
+	}
+	if in.PhoneNumber != "" {
+		supplier.PhoneNumber = in.PhoneNumber
+	}
+	if in.Email != "" {
+		supplier.Email = in.Email
+	}
+	if in.Address != "" {
+		supplier.Address = in.Address
+	}
+	if in.Notes != "" {
+		supplier.Notes = in.Notes
+	}
+
+	if err := s.suppliers.Update(ctx, supplier); err != nil {
+		return nil, fmt.Errorf("updating supplier: %w", err)
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier soft deletes a supplier with no draft or outstanding orders
+func (s *purchaseService) DeleteSupplier(ctx context.Context, user *data.User, supplierID string) error {
+	supplier, err := s.GetSupplier(ctx, user, supplierID)
+	if err != nil {
+		return err
+	}
+	open, err := s.orders.ExistsOpen(ctx, supplierID)
+	if err != nil {
+		return fmt.Errorf("checking supplier orders: %w", err)
+	}
+	if open {
+		return service.Conflict("supplier has draft or outstanding orders")
+	}
+	if err := s.suppliers.DeleteByID(ctx, int(supplier.ID)); err != nil {
+		return fmt.Errorf("deleting supplier: %w", err)
+	}
+	return nil
+}
+
+// CreateOrder drafts an order with a supplier of the same farm
+func (s *purchaseService) CreateOrder(ctx context.Context, user *data.User, farmID string, in OrderInput) (*data.PurchaseOrder, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	order := &data.PurchaseOrder{
+		FarmID:       farmID,
+		Reference:    in.Reference,
+		Status:       StatusDraft,
+		ExpectedDate: in.ExpectedDate,
+		Notes:        in.Notes,
+	}
+	if err := s.supply(ctx, order, in.SupplierID); err != nil {
+		return nil, err
+	}
+	if err := s.setLines(ctx, order, in.Lines); err != nil {
+		return nil, err
+	}
+	if order.Reference == "" {
+		order.Reference = fmt.Sprintf("PO-%s", time.Now().Format("20060102-150405"))
+	}
+
+	if err := s.orders.Insert(ctx, order); err != nil {
+		return nil, fmt.Errorf("creating purchase order: %w", err)
+	}
+	return order, nil
+}
+
+// GetOrder returns an order of one of the user's farms with its lines
+func (s *purchaseService) GetOrder(ctx context.Context, user *data.User, purchaseOrderID string) (*data.PurchaseOrder, error) {
+	order, err := s.orders.GetByPurchaseOrderID(ctx, purchaseOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("getting purchase order: %w", err)
+	}
+	if order == nil {
+		return nil, service.NotFound("purchase order not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, order.FarmID, "purchase order"); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ListOrders returns a farm's orders, optionally only those with the given status
+func (s *purchaseService) ListOrders(ctx context.Context, user *data.User, farmID, status string) ([]*data.PurchaseOrder, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	orders, err := s.orders.GetByFarmID(ctx, farmID, status)
+	if err != nil {
+		return nil, fmt.Errorf("getting purchase orders: %w", err)
+	}
+	return orders, nil
+}
+
+// UpdateOrder changes a draft order; once placed an order can only be
+// received or cancelled
+func (s *purchaseService) UpdateOrder(ctx context.Context, user *data.User, purchaseOrderID string, in OrderInput) (*data.PurchaseOrder, error) {
+	order, err := s.GetOrder(ctx, user, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != StatusDraft {
+		return nil, service.Conflict(fmt.Sprintf("only draft orders can be changed; this order is %s", order.Status))
+	}
+
+	if in.SupplierID != "" {
+		if err := s.supply(ctx, order, in.SupplierID); err != nil {
+			return nil, err
+		}
+	}
+	if in.Reference != "" {
+		order.Reference = in.Reference
+	}
+	if in.ExpectedDate != nil {
+		order.ExpectedDate = in.ExpectedDate
+	}
+	if in.Notes != "" {
+		order.Notes = in.Notes
+	}
+	if in.Lines != nil {
+		if err := s.setLines(ctx, order, in.Lines); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.orders.Update(ctx, order); err != nil {
+		return nil, fmt.Errorf("updating purchase order: %w", err)
+	}
+	return order, nil
+}
+
+// DeleteOrder soft deletes a draft or cancelled order. Received orders stay
+// as the record behind their stock and expenses.
+func (s *purchaseService) DeleteOrder(ctx context.Context, user *data.User, purchaseOrderID string) error {
+	order, err := s.GetOrder(ctx, user, purchaseOrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != StatusDraft && order.Status != StatusCancelled {
+		return service.Conflict(fmt.Sprintf("an order that is %s cannot be deleted", order.Status))
+	}
+	if err := s.orders.DeleteByID(ctx, int(order.ID)); err != nil {
+		return fmt.Errorf("deleting purchase order: %w", err)
+	}
+	return nil
+}
+
+// PlaceOrder marks a draft order as sent to the supplier
+func (s *purchaseService) PlaceOrder(ctx context.Context, user *data.User, purchaseOrderID string) (*data.PurchaseOrder, error) {
+	order, err := s.GetOrder(ctx, user, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(order.Lines) == 0 {
+		return nil, service.Invalid("an order needs at least one line before it is placed")
+	}
+
+	now := time.Now()
+	order.Status = StatusOrdered
+	order.OrderedAt = &now
+	if err := s.transition(ctx, order, StatusDraft); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// CancelOrder cancels a draft or placed order
+func (s *purchaseService) CancelOrder(ctx context.Context, user *data.User, purchaseOrderID string) (*data.PurchaseOrder, error) {
+	order, err := s.GetOrder(ctx, user, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := order.Status
+	if from != StatusDraft && from != StatusOrdered {
+		return nil, service.Conflict(fmt.Sprintf("an order that is %s cannot be cancelled", from))
+	}
+	order.Status = StatusCancelled
+	if err := s.transition(ctx, order, from); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// ReceiveOrder takes delivery of a placed order: each line becomes an
+// inventory batch and its cost an expense dated the day of receipt, which
+// must not be in a locked period
+func (s *purchaseService) ReceiveOrder(ctx context.Context, user *data.User, purchaseOrderID string, in ReceiptInput) (*data.PurchaseOrder, error) {
+	order, err := s.GetOrder(ctx, user, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != StatusOrdered {
+		return nil, service.Conflict(fmt.Sprintf("only placed orders can be received; this order is %s", order.Status))
+	}
+
+	receivedAt := time.Now()
+	if in.ReceivedAt != nil {
+		receivedAt = *in.ReceivedAt
+	}
+	if err := s.locks.Check(ctx, order.FarmID, receivedAt); err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]*data.PurchaseOrderLine, len(order.Lines))
+	for i := range order.Lines {
+		lines[order.Lines[i].PurchaseOrderLineID] = &order.Lines[i]
+	}
+	for _, receipt := range in.Lines {
+		line, ok := lines[receipt.LineID]
+		if !ok {
+			return nil, service.Invalid(fmt.Sprintf("line %s is not on this order", receipt.LineID))
+		}
+		line.BatchNumber = receipt.BatchNumber
+		line.ExpiryDate = receipt.ExpiryDate
+	}
+
+	order.ReceivedAt = &receivedAt
+	received, err := s.orders.Receive(ctx, order)
+	if err != nil {
+		return nil, fmt.Errorf("receiving purchase order: %w", err)
+	}
+	if !received {
+		return nil, service.Conflict("purchase order was changed by someone else; reload and try again")
+	}
+	order.Status = StatusReceived
+	return order, nil
+}
+
+// transition saves an order's new status if it is still in status from
+func (s *purchaseService) transition(ctx context.Context, order *data.PurchaseOrder, from string) error {
+	ok, err := s.orders.Transition(ctx, order, from)
+	if err != nil {
+		return fmt.Errorf("updating purchase order status: %w", err)
+	}
+	if !ok {
+		return service.Conflict("purchase order was changed by someone else; reload and try again")
+	}
+	return nil
+}
+
+// supply sets an order's supplier, which must be on the order's farm
+func (s *purchaseService) supply(ctx context.Context, order *data.PurchaseOrder, supplierID string) error {
+	supplier, err := s.suppliers.GetBySupplierID(ctx, supplierID)
+	if err != nil {
+		return fmt.Errorf("getting supplier: %w", err)
+	}
+	if supplier == nil || supplier.FarmID != order.FarmID {
+		return service.Invalid("supplier not found on this farm")
+	}
+	order.SupplierID = supplier.SupplierID
+	order.Supplier = supplier
+	return nil
+}
+
+// setLines replaces an order's lines, checking each item is kept on the
+// order's farm, and recalculates the total
+func (s *purchaseService) setLines(ctx context.Context, order *data.PurchaseOrder, in []LineInput) error {
+	lines := make([]data.PurchaseOrderLine, 0, len(in))
+	for i, line := range in {
+		item, err := s.items.GetByInventoryItemID(ctx, line.InventoryItemID)
+		if err != nil {
+			return fmt.Errorf("getting inventory item: %w", err)
+		}
+		if item == nil || item.FarmID != order.FarmID {
+			return service.Invalid(fmt.Sprintf("line %d: inventory item not found on this farm", i+1))
+		}
+		lines = append(lines, data.PurchaseOrderLine{
+			InventoryItemID: item.InventoryItemID,
+			Quantity:        line.Quantity,
+			UnitCost:        line.UnitCost,
+			InventoryItem:   item,
+		})
+	}
+	order.Lines = lines
+	order.CalculateTotal()
+	return nil
+}
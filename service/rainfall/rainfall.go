@@ -0,0 +1,364 @@
+// Package rainfall keeps the readings of a farm's own rain gauges and sums
+// them by month and season, next to what the weather provider recorded for
+// the farm's location, since many farmers trust their own gauges over it.
+package rainfall
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/weather"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultGauge names the gauge of a reading that names none and is not taken
+// in a field
+const DefaultGauge = "Main"
+
+// historyTimeout bounds the weather provider lookup of the summary
+const historyTimeout = 10 * time.Second
+
+// Input holds the editable reading fields. On update, zero values are left
+// unchanged; an empty FieldID string clears it.
+type Input struct {
+	FieldID *string
+	Gauge   string
+	Date    *time.Time
+	MM      *float64
+	Notes   string
+}
+
+// Period is the rain over a month or season. The provider's rain is only
+// compared with the gauges' once it has records for every day of the period
+// that has passed.
+type Period struct {
+	Name         string    `json:"name"` // YYYY-MM for a month; DJF, MAM, JJA or SON for a season
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`       // Exclusive
+	GaugeMM      float64   `json:"gaugeMm"`  // By the farm's own gauges
+	RainDays     int       `json:"rainDays"` // Days the gauges caught rain
+	ProviderMM   *float64  `json:"providerMm,omitempty"`
+	DifferenceMM *float64  `json:"differenceMm,omitempty"` // Gauges minus provider
+}
+
+// GaugeTotal is the rain one gauge caught over the year
+type GaugeTotal struct {
+	Gauge    string  `json:"gauge"`
+	MM       float64 `json:"mm"`
+	Readings int     `json:"readings"`
+}
+
+// Summary is a farm's rainfall over a year. Where several gauges were read
+// on a day, the farm's rain that day is their average.
+type Summary struct {
+	FarmID   string       `json:"farmId"`
+	Year     int          `json:"year"`
+	Provider string       `json:"provider"` // Provider compared with, or why there is no comparison
+	Total    Period       `json:"total"`
+	Months   []Period     `json:"months"`
+	Seasons  []Period     `json:"seasons"` // DJF runs from the December before the year
+	Gauges   []GaugeTotal `json:"gauges"`
+}
+
+// Service is the rainfall domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.RainfallRecord, error)
+	Get(ctx context.Context, user *data.User, rainfallID string) (*data.RainfallRecord, error)
+	List(ctx context.Context, user *data.User, farmID, gauge string, from, to *time.Time) ([]*data.RainfallRecord, error)
+	Update(ctx context.Context, user *data.User, rainfallID string, in Input) (*data.RainfallRecord, error)
+	Delete(ctx context.Context, user *data.User, rainfallID string) error
+	// Summary totals a farm's rainfall by month and season for a year and
+	// compares it with the weather provider's
+	Summary(ctx context.Context, user *data.User, farmID string, year int) (*Summary, error)
+}
+
+// rainfallService implements Service on top of the rainfall record repository
+type rainfallService struct {
+	records data.RainfallRecordInterface
+	fields  data.FieldInterface
+	history weather.Forecaster
+	farms   farm.Service
+}
+
+// New creates the rainfall service
+func New(records data.RainfallRecordInterface, fields data.FieldInterface, history weather.Forecaster, farms farm.Service) Service {
+	return &rainfallService{records: records, fields: fields, history: history, farms: farms}
+}
+
+// Create records a gauge reading on one of the user's farms. The gauge
+// defaults to the field's name, or DefaultGauge.
+func (s *rainfallService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.RainfallRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if in.Date == nil {
+		return nil, service.Invalid("date is required")
+	}
+	if in.MM == nil {
+		return nil, service.Invalid("mm is required")
+	}
+
+	record := &data.RainfallRecord{
+		FarmID: farmID,
+		Gauge:  strings.TrimSpace(in.Gauge),
+		Date:   service.Day(*in.Date),
+		MM:     *in.MM,
+		Notes:  in.Notes,
+	}
+	if err := s.link(ctx, record, in.FieldID); err != nil {
+		return nil, err
+	}
+	if err := check(record); err != nil {
+		return nil, err
+	}
+
+	if err := s.records.Insert(ctx, record); err != nil {
+		return nil, fmt.Errorf("creating rainfall record: %w", err)
+	}
+	return record, nil
+}
+
+// Get returns a gauge reading on one of the user's farms
+func (s *rainfallService) Get(ctx context.Context, user *data.User, rainfallID string) (*data.RainfallRecord, error) {
+	record, err := s.records.GetByRainfallRecordID(ctx, rainfallID)
+	if err != nil {
+		return nil, fmt.Errorf("getting rainfall record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("rainfall record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "rainfall record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the gauge readings of one of the user's farms
+func (s *rainfallService) List(ctx context.Context, user *data.User, farmID, gauge string, from, to *time.Time) ([]*data.RainfallRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.records.GetByFarmID(ctx, farmID, gauge, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting rainfall records: %w", err)
+	}
+	return records, nil
+}
+
+// Update changes the non-zero fields of in on a gauge reading
+func (s *rainfallService) Update(ctx context.Context, user *data.User, rainfallID string, in Input) (*data.RainfallRecord, error) {
+	record, err := s.Get(ctx, user, rainfallID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Gauge != "" {
+		record.Gauge = strings.TrimSpace(in.Gauge)
+	}
+	if in.Date != nil {
+		record.Date = service.Day(*in.Date)
+	}
+	if in.MM != nil {
+		record.MM = *in.MM
+	}
+	if in.Notes != "" {
+		record.Notes = in.Notes
+	}
+	if err := s.link(ctx, record, in.FieldID); err != nil {
+		return nil, err
+	}
+	if err := check(record); err != nil {
+		return nil, err
+	}
+
+	if err := s.records.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("updating rainfall record: %w", err)
+	}
+	return record, nil
+}
+
+// Delete soft deletes a gauge reading
+func (s *rainfallService) Delete(ctx context.Context, user *data.User, rainfallID string) error {
+	record, err := s.Get(ctx, user, rainfallID)
+	if err != nil {
+		return err
+	}
+	if err := s.records.DeleteByID(ctx, int(record.ID)); err != nil {
+		return fmt.Errorf("deleting rainfall record: %w", err)
+	}
+	return nil
+}
+
+// Summary totals a farm's gauge readings for a year by month and
+// meteorological season, and the rain the weather provider recorded for the
+// farm's location over the same periods. A provider that cannot be reached
+// leaves the summary without a comparison.
+func (s *rainfallService) Summary(ctx context.Context, user *data.User, farmID string, year int) (*Summary, error) {
+	f, err := s.farms.Owned(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	// The DJF season starts in the December before the year
+	from := start.AddDate(0, -1, 0)
+	records, err := s.records.GetByFarmID(ctx, farmID, "", &from, &end)
+	if err != nil {
+		return nil, fmt.Errorf("getting rainfall records: %w", err)
+	}
+
+	summary := &Summary{FarmID: farmID, Year: year, Total: Period{Name: fmt.Sprint(year), From: start, To: end}}
+	for month := start; month.Before(end); month = month.AddDate(0, 1, 0) {
+		summary.Months = append(summary.Months, Period{Name: month.Format("2006-01"), From: month, To: month.AddDate(0, 1, 0)})
+	}
+	for i, name := range []string{"DJF", "MAM", "JJA", "SON"} {
+		season := from.AddDate(0, 3*i, 0)
+		summary.Seasons = append(summary.Seasons, Period{Name: name, From: season, To: season.AddDate(0, 3, 0)})
+	}
+
+	// Each gauge's rain per day, then the farm's as their average
+	byGauge := map[string]*GaugeTotal{}
+	gaugeDays := map[time.Time]map[string]float64{}
+	for _, record := range records {
+		date := service.Day(record.Date)
+		if gaugeDays[date] == nil {
+			gaugeDays[date] = map[string]float64{}
+		}
+		gaugeDays[date][record.Gauge] += record.MM
+		if !date.Before(start) {
+			if byGauge[record.Gauge] == nil {
+				byGauge[record.Gauge] = &GaugeTotal{Gauge: record.Gauge}
+			}
+			byGauge[record.Gauge].MM += record.MM
+			byGauge[record.Gauge].Readings++
+		}
+	}
+	farmDays := map[time.Time]float64{}
+	for date, gauges := range gaugeDays {
+		var sum float64
+		for _, mm := range gauges {
+			sum += mm
+		}
+		farmDays[date] = sum / float64(len(gauges))
+	}
+
+	providerDays, provider := s.providerHistory(f.Location, from, end)
+	summary.Provider = provider
+	today := service.Day(time.Now())
+
+	periods := []*Period{&summary.Total}
+	for i := range summary.Months {
+		periods = append(periods, &summary.Months[i])
+	}
+	for i := range summary.Seasons {
+		periods = append(periods, &summary.Seasons[i])
+	}
+	for _, period := range periods {
+		var providerMM float64
+		var providerCount, passed int
+		for date := period.From; date.Before(period.To); date = date.AddDate(0, 0, 1) {
+			if mm, ok := farmDays[date]; ok {
+				period.GaugeMM += mm
+				if mm > 0 {
+					period.RainDays++
+				}
+			}
+			if date.Before(today) {
+				passed++
+			}
+			if mm, ok := providerDays[date]; ok {
+				providerMM += mm
+				providerCount++
+			}
+		}
+		period.GaugeMM = round(period.GaugeMM)
+		if providerCount > 0 && providerCount >= passed {
+			providerMM = round(providerMM)
+			difference := round(period.GaugeMM - providerMM)
+			period.ProviderMM, period.DifferenceMM = &providerMM, &difference
+		}
+	}
+
+	summary.Gauges = []GaugeTotal{}
+	for _, gauge := range byGauge {
+		gauge.MM = round(gauge.MM)
+		summary.Gauges = append(summary.Gauges, *gauge)
+	}
+	sort.Slice(summary.Gauges, func(i, j int) bool { return summary.Gauges[i].Gauge < summary.Gauges[j].Gauge })
+	return summary, nil
+}
+
+// providerHistory returns the provider's rain per day at location in
+// [from, to), up to today, and the provider's name or why it has none
+func (s *rainfallService) providerHistory(location string, from, to time.Time) (map[time.Time]float64, string) {
+	if today := service.Day(time.Now()); to.After(today) {
+		to = today
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	history, err := s.history.History(ctx, location, from, to)
+	switch {
+	case errors.Is(err, weather.ErrUnavailable):
+		return nil, "not configured"
+	case errors.Is(err, weather.ErrUnknownLocation):
+		return nil, "farm location not recognised"
+	case err != nil:
+		return nil, "unavailable"
+	}
+
+	days := make(map[time.Time]float64, len(history))
+	for _, d := range history {
+		days[service.Day(d.Date)] = d.RainMM
+	}
+	return days, s.history.Name()
+}
+
+// link sets the field a gauge stands in. A nil fieldID leaves it unchanged
+// and an empty one clears it.
+func (s *rainfallService) link(ctx context.Context, record *data.RainfallRecord, fieldID *string) error {
+	if fieldID == nil {
+		return nil
+	}
+	record.FieldID, record.Field = nil, nil
+	if *fieldID == "" {
+		return nil
+	}
+	field, err := s.fields.GetByFieldID(ctx, *fieldID)
+	if err != nil {
+		return fmt.Errorf("getting field: %w", err)
+	}
+	if field == nil || field.FarmID != record.FarmID {
+		return service.Invalid("field not found on this farm")
+	}
+	record.FieldID, record.Field = &field.FieldID, field
+	return nil
+}
+
+// check fills in a reading's gauge and rejects readings that cannot be right
+func check(record *data.RainfallRecord) error {
+	if record.Gauge == "" {
+		record.Gauge = DefaultGauge
+		if record.Field != nil {
+			record.Gauge = record.Field.Name
+		}
+	}
+	if record.MM < 0 {
+		return service.Invalid("mm cannot be negative")
+	}
+	if record.Date.After(time.Now()) {
+		return service.Invalid("date cannot be in the future")
+	}
+	return nil
+}
+
+// round rounds mm to one decimal place, as gauges are read
+func round(mm float64) float64 {
+	return math.Round(mm*10) / 10
+}
@@ -0,0 +1,137 @@
+package report
+
+import (
+	"context"
+	"farm4u/data"
+	"fmt"
+	"time"
+)
+
+// PortfolioFigures are the figures rolled up for a farm, or for all of an
+// owner's farms together
+type PortfolioFigures struct {
+	LivestockHead   int            `json:"livestockHead"`   // Living animals
+	LivestockByType map[string]int `json:"livestockByType"` // Living animals per type
+	FieldArea       float64        `json:"fieldArea"`
+	CropAcreage     float64        `json:"cropAcreage"` // Area of the fields with a growing crop
+	CropsGrowing    int            `json:"cropsGrowing"`
+	Revenue         float64        `json:"revenue"`
+	Expenses        float64        `json:"expenses"`
+	Net             float64        `json:"net"`
+}
+
+// PortfolioFarm is one farm's share of a portfolio
+type PortfolioFarm struct {
+	FarmID   string  `json:"farmId"`
+	Name     string  `json:"name"`
+	Location string  `json:"location"`
+	Size     float64 `json:"size"`
+	PortfolioFigures
+}
+
+// Portfolio rolls up every farm a user owns, with each farm's breakdown.
+// Revenue and expenses cover [From, To); livestock and crops are as they
+// stand today.
+type Portfolio struct {
+	From   *time.Time       `json:"from,omitempty"`
+	To     *time.Time       `json:"to,omitempty"`
+	Totals PortfolioFigures `json:"totals"`
+	Farms  []PortfolioFarm  `json:"farms"`
+}
+
+// Portfolio implements Service
+func (s *reportService) Portfolio(ctx context.Context, user *data.User, from, to *time.Time) (*Portfolio, error) {
+	farms, err := s.farms.List(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio := &Portfolio{
+		From:   from,
+		To:     to,
+		Totals: PortfolioFigures{LivestockByType: map[string]int{}},
+		Farms:  make([]PortfolioFarm, 0, len(farms)),
+	}
+	for _, f := range farms {
+		figures, err := s.farmFigures(ctx, f.FarmID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		portfolio.Farms = append(portfolio.Farms, PortfolioFarm{
+			FarmID:           f.FarmID,
+			Name:             f.Name,
+			Location:         f.Location,
+			Size:             f.Size,
+			PortfolioFigures: figures,
+		})
+
+		t := &portfolio.Totals
+		t.LivestockHead += figures.LivestockHead
+		for livestockType, head := range figures.LivestockByType {
+			t.LivestockByType[livestockType] += head
+		}
+		t.FieldArea += figures.FieldArea
+		t.CropAcreage += figures.CropAcreage
+		t.CropsGrowing += figures.CropsGrowing
+		t.Revenue += figures.Revenue
+		t.Expenses += figures.Expenses
+		t.Net += figures.Net
+	}
+	return portfolio, nil
+}
+
+// farmFigures works out one farm's portfolio figures
+func (s *reportService) farmFigures(ctx context.Context, farmID string, from, to *time.Time) (PortfolioFigures, error) {
+	figures := PortfolioFigures{LivestockByType: map[string]int{}}
+
+	herds, err := s.livestock.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return figures, fmt.Errorf("getting livestock: %w", err)
+	}
+	for _, h := range herds {
+		if h.HealthStatus == "Deceased" {
+			continue
+		}
+		figures.LivestockHead += h.Count
+		figures.LivestockByType[h.Type] += h.Count
+	}
+
+	fields, err := s.fields.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return figures, fmt.Errorf("getting fields: %w", err)
+	}
+	crops, err := s.crops.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return figures, fmt.Errorf("getting crops: %w", err)
+	}
+	planted := map[string]bool{}
+	for _, c := range crops {
+		if c.Status != "Growing" {
+			continue
+		}
+		figures.CropsGrowing++
+		if c.FieldID != nil {
+			planted[*c.FieldID] = true
+		}
+	}
+	for _, f := range fields {
+		figures.FieldArea += f.Area
+		if planted[f.FieldID] {
+			figures.CropAcreage += f.Area
+		}
+	}
+
+	totals, err := s.transactions.TotalsByCategory(ctx, farmID, from, to)
+	if err != nil {
+		return figures, fmt.Errorf("getting transaction totals: %w", err)
+	}
+	for _, t := range totals {
+		if t.Type == "Income" {
+			figures.Revenue += t.Total
+		} else {
+			figures.Expenses += t.Total
+		}
+	}
+	figures.Net = figures.Revenue - figures.Expenses
+	return figures, nil
+}
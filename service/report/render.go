@@ -0,0 +1,401 @@
+package report
+
+import (
+	"cmp"
+	"context"
+	"farm4u/data"
+	"farm4u/pdf"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is how dates are shown in reports
+const dateLayout = "2 Jan 2006"
+
+// document is a report being laid out for a job
+type document struct {
+	*pdf.Document
+	job  *data.ReportJob
+	farm *data.Farm
+}
+
+// newDocument starts a report titled with its kind and farm
+func newDocument(title string, job *data.ReportJob, f *data.Farm) *document {
+	subtitle := fmt.Sprintf("Period %s (%s to %s) · Generated %s",
+		job.Period,
+		job.PeriodStart.Format(dateLayout),
+		job.PeriodEnd.AddDate(0, 0, -1).Format(dateLayout),
+		time.Now().UTC().Format("2 Jan 2006 15:04 UTC"))
+	return &document{
+		Document: pdf.New(fmt.Sprintf("%s — %s", title, f.Name), subtitle),
+		job:      job,
+		farm:     f,
+	}
+}
+
+// inPeriod reports whether t falls in the report's period
+func (d *document) inPeriod(t *time.Time) bool {
+	return t != nil && !t.Before(d.job.PeriodStart) && t.Before(d.job.PeriodEnd)
+}
+
+// farmSummary lays out the farm's details, its finances for the period and
+// its current crops and livestock
+func (s *reportService) farmSummary(ctx context.Context, d *document) error {
+	fields, err := s.fields.GetByFarmID(ctx, d.farm.FarmID)
+	if err != nil {
+		return fmt.Errorf("getting fields: %w", err)
+	}
+	employees, err := s.employees.GetByFarmID(ctx, d.farm.FarmID)
+	if err != nil {
+		return fmt.Errorf("getting employees: %w", err)
+	}
+	from, to := d.job.PeriodStart, d.job.PeriodEnd
+	totals, err := s.transactions.TotalsByCategory(ctx, d.farm.FarmID, &from, &to)
+	if err != nil {
+		return fmt.Errorf("getting transaction totals: %w", err)
+	}
+	crops, err := s.crops.GetByFarmID(ctx, d.farm.FarmID)
+	if err != nil {
+		return fmt.Errorf("getting crops: %w", err)
+	}
+	herds, err := s.livestock.GetByFarmID(ctx, d.farm.FarmID)
+	if err != nil {
+		return fmt.Errorf("getting livestock: %w", err)
+	}
+
+	area := 0.0
+	for _, f := range fields {
+		area += f.Area
+	}
+	active := 0
+	for _, e := range employees {
+		if e.Status == "Active" {
+			active++
+		}
+	}
+
+	d.Heading("Farm")
+	d.KeyValues([][2]string{
+		{"Name", d.farm.Name},
+		{"Location", d.farm.Location},
+		{"Type", d.farm.FarmType},
+		{"Size", number(d.farm.Size)},
+		{"Status", d.farm.Status},
+		{"Fields", fmt.Sprintf("%d covering %s", len(fields), number(area))},
+		{"Active employees", strconv.Itoa(active)},
+	})
+
+	income, expenses := 0.0, 0.0
+	rows := make([][]string, 0, len(totals))
+	for _, t := range totals {
+		if t.Type == "Income" {
+			income += t.Total
+		} else {
+			expenses += t.Total
+		}
+		rows = append(rows, []string{t.Type, t.Category, money(t.Total)})
+	}
+	d.Heading("Finances")
+	d.KeyValues([][2]string{
+		{"Income", money(income)},
+		{"Expenses", money(expenses)},
+		{"Net", money(income - expenses)},
+	})
+	d.Table([]string{"Type", "Category", "Total"}, rows)
+
+	planted, harvested := 0, 0
+	byStatus := map[string]int{}
+	for _, c := range crops {
+		byStatus[c.Status]++
+		if d.inPeriod(c.PlantingDate) {
+			planted++
+		}
+		if d.inPeriod(c.HarvestDate) {
+			harvested++
+		}
+	}
+	d.Heading("Crops")
+	d.KeyValues([][2]string{
+		{"Planted in period", strconv.Itoa(planted)},
+		{"Harvested in period", strconv.Itoa(harvested)},
+	})
+	d.Table([]string{"Status", "Crops"}, counted(byStatus))
+
+	head, groups := map[string]int{}, map[string]int{}
+	for _, h := range herds {
+		if h.HealthStatus == "Deceased" {
+			continue
+		}
+		head[h.Type] += h.Count
+		groups[h.Type]++
+	}
+	rows = make([][]string, 0, len(head))
+	for _, t := range sortedKeys(head) {
+		rows = append(rows, []string{t, strconv.Itoa(groups[t]), strconv.Itoa(head[t])})
+	}
+	d.Heading("Livestock")
+	d.Table([]string{"Type", "Groups", "Head"}, rows)
+	return nil
+}
+
+// payroll lays out the payments made in the period and each employee's totals
+func (s *reportService) payroll(ctx context.Context, d *document) error {
+	from, to := d.job.PeriodStart, d.job.PeriodEnd
+	payments, err := s.payments.GetByFarmID(ctx, d.farm.FarmID, &from, &to)
+	if err != nil {
+		return fmt.Errorf("getting payments: %w", err)
+	}
+	slices.SortStableFunc(payments, func(a, b *data.PayrollPayment) int { return a.PaymentDate.Compare(b.PaymentDate) })
+
+	type totals struct {
+		payments               int
+		gross, deductions, net float64
+	}
+	var all totals
+	perEmployee := map[string]*totals{}
+	rows := make([][]string, 0, len(payments))
+	for _, p := range payments {
+		name := employeeName(p.Employee)
+		t := perEmployee[name]
+		if t == nil {
+			t = &totals{}
+			perEmployee[name] = t
+		}
+		for _, sum := range []*totals{t, &all} {
+			sum.payments++
+			sum.gross += p.GrossPay
+			sum.deductions += p.Deductions
+			sum.net += p.NetPay
+		}
+		rows = append(rows, []string{
+			p.PaymentDate.Format(dateLayout),
+			name,
+			p.PeriodStart.Format(dateLayout) + " - " + p.PeriodEnd.Format(dateLayout),
+			p.PaymentMethod,
+			money(p.GrossPay),
+			money(p.Deductions),
+			money(p.NetPay),
+		})
+	}
+
+	d.Heading("Summary")
+	d.KeyValues([][2]string{
+		{"Payments", strconv.Itoa(all.payments)},
+		{"Employees paid", strconv.Itoa(len(perEmployee))},
+		{"Gross pay", money(all.gross)},
+		{"Deductions", money(all.deductions)},
+		{"Net pay", money(all.net)},
+	})
+
+	d.Heading("By employee")
+	employeeRows := make([][]string, 0, len(perEmployee))
+	for _, name := range sortedKeys(perEmployee) {
+		t := perEmployee[name]
+		employeeRows = append(employeeRows, []string{name, strconv.Itoa(t.payments), money(t.gross), money(t.deductions), money(t.net)})
+	}
+	d.Table([]string{"Employee", "Payments", "Gross", "Deductions", "Net"}, employeeRows)
+
+	d.Heading("Payments")
+	d.Table([]string{"Paid", "Employee", "Pay period", "Method", "Gross", "Deductions", "Net"}, rows)
+	return nil
+}
+
+// healthStatuses are the livestock health statuses, in report column order
+var healthStatuses = []string{"Healthy", "Sick", "Under Treatment", "Deceased"}
+
+// livestockHealth lays out the health of the herds held by the end of the
+// period. Livestock records keep their current status only, so the report
+// shows that status rather than a history.
+func (s *reportService) livestockHealth(ctx context.Context, d *document) error {
+	herds, err := s.livestock.GetByFarmID(ctx, d.farm.FarmID)
+	if err != nil {
+		return fmt.Errorf("getting livestock: %w", err)
+	}
+	herds = slices.DeleteFunc(herds, func(h *data.Livestock) bool {
+		return h.AcquisitionDate != nil && !h.AcquisitionDate.Before(d.job.PeriodEnd)
+	})
+
+	head := map[string]map[string]int{}
+	var attention [][]string
+	for _, h := range herds {
+		if head[h.Type] == nil {
+			head[h.Type] = map[string]int{}
+		}
+		head[h.Type][h.HealthStatus] += h.Count
+		if h.HealthStatus != "Healthy" {
+			attention = append(attention, []string{h.Type, strconv.Itoa(h.Count), h.HealthStatus, date(h.AcquisitionDate), h.Notes})
+		}
+	}
+
+	d.Text("Health is shown as currently recorded, for the livestock held by the end of the period.")
+
+	d.Heading("Head by health status")
+	headers := append([]string{"Type"}, healthStatuses...)
+	headers = append(headers, "Total")
+	totals := make([]int, len(healthStatuses)+1)
+	rows := make([][]string, 0, len(head)+1)
+	for _, t := range sortedKeys(head) {
+		row := []string{t}
+		total := 0
+		for i, status := range healthStatuses {
+			row = append(row, strconv.Itoa(head[t][status]))
+			totals[i] += head[t][status]
+			total += head[t][status]
+		}
+		totals[len(healthStatuses)] += total
+		rows = append(rows, append(row, strconv.Itoa(total)))
+	}
+	if len(rows) > 0 {
+		row := []string{"All livestock"}
+		for _, n := range totals {
+			row = append(row, strconv.Itoa(n))
+		}
+		rows = append(rows, row)
+	}
+	d.Table(headers, rows)
+
+	d.Heading("Needing attention")
+	slices.SortStableFunc(attention, func(a, b []string) int {
+		return cmp.Or(cmp.Compare(a[2], b[2]), cmp.Compare(a[0], b[0]))
+	})
+	d.Table([]string{"Type", "Head", "Status", "Acquired", "Notes"}, attention)
+	return nil
+}
+
+// harvest lays out the crops harvested in the period, and those that failed
+func (s *reportService) harvest(ctx context.Context, d *document) error {
+	crops, err := s.crops.GetHarvestedByFarmIDAndDateRange(ctx, d.farm.FarmID, &d.job.PeriodStart, &d.job.PeriodEnd)
+	if err != nil {
+		return fmt.Errorf("getting crops: %w", err)
+	}
+	fields, err := s.fields.GetByFarmID(ctx, d.farm.FarmID)
+	if err != nil {
+		return fmt.Errorf("getting fields: %w", err)
+	}
+	fieldNames := map[string]string{}
+	for _, f := range fields {
+		fieldNames[f.FieldID] = f.Name
+	}
+
+	type totals struct {
+		harvests, failed int
+		quantity         float64
+	}
+	perCrop := map[string]*totals{}
+	harvested, failed := 0, 0
+	rows := make([][]string, 0, len(crops))
+	for _, c := range crops {
+		t := perCrop[c.Name]
+		if t == nil {
+			t = &totals{}
+			perCrop[c.Name] = t
+		}
+		if c.Status == "Failed" {
+			t.failed++
+			failed++
+		} else {
+			t.harvests++
+			harvested++
+		}
+		t.quantity += c.Quantity
+
+		field := ""
+		if c.FieldID != nil {
+			field = fieldNames[*c.FieldID]
+		}
+		days := ""
+		if c.PlantingDate != nil {
+			days = strconv.Itoa(int(c.HarvestDate.Sub(*c.PlantingDate).Hours() / 24))
+		}
+		rows = append(rows, []string{c.Name, field, date(c.PlantingDate), date(c.HarvestDate), days, number(c.Quantity), c.Status})
+	}
+
+	d.Heading("Summary")
+	d.KeyValues([][2]string{
+		{"Crops harvested", strconv.Itoa(harvested)},
+		{"Crops failed", strconv.Itoa(failed)},
+	})
+
+	d.Heading("By crop")
+	cropRows := make([][]string, 0, len(perCrop))
+	for _, name := range sortedKeys(perCrop) {
+		t := perCrop[name]
+		cropRows = append(cropRows, []string{name, strconv.Itoa(t.harvests), strconv.Itoa(t.failed), number(t.quantity)})
+	}
+	d.Table([]string{"Crop", "Harvested", "Failed", "Quantity planted"}, cropRows)
+
+	d.Heading("Harvests")
+	d.Table([]string{"Crop", "Field", "Planted", "Harvested", "Days", "Quantity planted", "Status"}, rows)
+	return nil
+}
+
+// employeeName is an employee's full name, or a placeholder for a payment
+// whose employee has been removed
+func employeeName(e *data.Employee) string {
+	if e == nil {
+		return "Former employee"
+	}
+	return strings.TrimSpace(e.FirstName + " " + e.LastName)
+}
+
+// counted turns counts into rows of name and count, sorted by name
+func counted(counts map[string]int) [][]string {
+	rows := make([][]string, 0, len(counts))
+	for _, k := range sortedKeys(counts) {
+		rows = append(rows, []string{k, strconv.Itoa(counts[k])})
+	}
+	return rows
+}
+
+// sortedKeys returns a map's keys in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// date formats an optional date, leaving it blank when unset
+func date(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+// money formats an amount with two decimals and thousands separators
+func money(v float64) string {
+	return group(strconv.FormatFloat(v, 'f', 2, 64))
+}
+
+// number formats a quantity with up to two decimals and thousands separators
+func number(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+	return group(s)
+}
+
+// group puts thousands separators into a formatted number
+func group(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString("." + frac)
+	}
+	return sign + b.String()
+}
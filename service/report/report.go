@@ -0,0 +1,399 @@
+// Package report renders a farm's records as downloadable PDF reports: a
+// farm summary, payroll, livestock health and harvest. Reports are requested
+// for a period, rendered in the background and polled for until Ready, so a
+// farm with years of records never holds a request open. It also rolls an
+// owner's farms up into a portfolio.
+package report
+
+import (
+	"bytes"
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/storage"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Report types
+const (
+	TypeFarmSummary     = "farm-summary"
+	TypePayroll         = "payroll"
+	TypeLivestockHealth = "livestock-health"
+	TypeHarvest         = "harvest"
+)
+
+// Report job statuses
+const (
+	StatusPending = "Pending"
+	StatusRunning = "Running"
+	StatusReady   = "Ready"
+	StatusFailed  = "Failed"
+)
+
+// kind describes a report type
+type kind struct {
+	title  string
+	render func(s *reportService, ctx context.Context, doc *document) error
+	// module is the permissions matrix module whose readers may see the
+	// report; reports on operational records have none and are owner-only
+	module farm.Module
+}
+
+// kinds are the report types that can be requested
+var kinds = map[string]kind{
+	TypeFarmSummary:     {title: "Farm Summary", render: (*reportService).farmSummary},
+	TypePayroll:         {title: "Payroll Report", render: (*reportService).payroll, module: farm.ModulePayroll},
+	TypeLivestockHealth: {title: "Livestock Health Report", render: (*reportService).livestockHealth},
+	TypeHarvest:         {title: "Harvest Report", render: (*reportService).harvest},
+}
+
+// Types returns the report types, in order
+func Types() []string {
+	types := make([]string, 0, len(kinds))
+	for t := range kinds {
+		types = append(types, t)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// Service is the report domain service
+type Service interface {
+	// Request queues a report of one of the user's farms for a period (YYYY,
+	// YYYY-Qn or YYYY-MM; this year if empty). A report of the same type and
+	// period already queued is returned instead of queuing another.
+	Request(ctx context.Context, user *data.User, farmID, reportType, period string) (*data.ReportJob, error)
+	// RequestSeason is Request for the dates of one of the farm's seasons
+	RequestSeason(ctx context.Context, user *data.User, farmID, reportType, seasonID string) (*data.ReportJob, error)
+	Get(ctx context.Context, user *data.User, reportJobID string) (*data.ReportJob, error)
+	// List returns the reports of a farm the user may see, newest first
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.ReportJob, error)
+	// Open returns a Ready report's PDF. The caller must close it.
+	Open(ctx context.Context, user *data.User, reportJobID string) (io.ReadCloser, *data.ReportJob, error)
+	// Delete removes a report and its PDF
+	Delete(ctx context.Context, user *data.User, reportJobID string) error
+	// Generate renders a queued report and stores its PDF. A report already
+	// claimed by another worker is left alone.
+	Generate(ctx context.Context, reportJobID string) error
+	// Portfolio rolls up every farm the user owns, optionally limiting
+	// revenue and expenses to dates in [from, to)
+	Portfolio(ctx context.Context, user *data.User, from, to *time.Time) (*Portfolio, error)
+	// GenerateQueued renders reports still Pending that were requested
+	// before queuedBefore, and fails those Running since before
+	// runningBefore, returning how many were rendered
+	GenerateQueued(ctx context.Context, queuedBefore, runningBefore time.Time) (int, error)
+}
+
+// reportService implements Service on top of the report job repository,
+// object storage and the repositories the reports draw on
+type reportService struct {
+	jobs         data.ReportJobInterface
+	files        storage.Storage
+	fields       data.FieldInterface
+	crops        data.CropInterface
+	livestock    data.LivestockInterface
+	employees    data.EmployeeInterface
+	payments     data.PayrollPaymentInterface
+	transactions data.TransactionInterface
+	seasons      data.SeasonInterface
+	farmRepo     data.FarmInterface
+	farms        farm.Service
+}
+
+// New creates the report service. farmRepo is read directly as reports are
+// rendered in the background, away from the user who requested them.
+func New(jobs data.ReportJobInterface, files storage.Storage, fields data.FieldInterface, crops data.CropInterface,
+	livestock data.LivestockInterface, employees data.EmployeeInterface, payments data.PayrollPaymentInterface,
+	transactions data.TransactionInterface, seasons data.SeasonInterface, farmRepo data.FarmInterface, farms farm.Service) Service {
+	return &reportService{
+		jobs:         jobs,
+		files:        files,
+		fields:       fields,
+		crops:        crops,
+		livestock:    livestock,
+		employees:    employees,
+		payments:     payments,
+		transactions: transactions,
+		seasons:      seasons,
+		farmRepo:     farmRepo,
+		farms:        farms,
+	}
+}
+
+// Request implements Service
+func (s *reportService) Request(ctx context.Context, user *data.User, farmID, reportType, period string) (*data.ReportJob, error) {
+	if _, ok := kinds[reportType]; !ok {
+		return nil, service.Invalid("report type must be one of " + strings.Join(Types(), ", "))
+	}
+	if err := s.authorize(ctx, user, farmID, reportType); err != nil {
+		return nil, err
+	}
+	period, from, to, err := ParsePeriod(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.queue(ctx, user, farmID, reportType, period, from, to)
+}
+
+// RequestSeason implements Service. The season's name stands for the period.
+func (s *reportService) RequestSeason(ctx context.Context, user *data.User, farmID, reportType, seasonID string) (*data.ReportJob, error) {
+	if _, ok := kinds[reportType]; !ok {
+		return nil, service.Invalid("report type must be one of " + strings.Join(Types(), ", "))
+	}
+	if err := s.authorize(ctx, user, farmID, reportType); err != nil {
+		return nil, err
+	}
+	season, err := s.seasons.GetBySeasonID(ctx, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("getting season: %w", err)
+	}
+	if season == nil || season.FarmID != farmID {
+		return nil, service.Invalid("season not found on this farm")
+	}
+	return s.queue(ctx, user, farmID, reportType, season.Name, season.StartDate, season.End())
+}
+
+// queue queues a report of farmID for period, covering [from, to), unless the
+// same report is already queued
+func (s *reportService) queue(ctx context.Context, user *data.User, farmID, reportType, period string, from, to time.Time) (*data.ReportJob, error) {
+	queued, err := s.jobs.GetQueued(ctx, farmID, reportType, period)
+	if err != nil {
+		return nil, fmt.Errorf("getting queued report: %w", err)
+	}
+	if queued != nil {
+		return queued, nil
+	}
+
+	job := &data.ReportJob{
+		FarmID:      farmID,
+		UserID:      user.UserID,
+		Type:        reportType,
+		Period:      period,
+		PeriodStart: from,
+		PeriodEnd:   to,
+		Status:      StatusPending,
+	}
+	if err := s.jobs.Insert(ctx, job); err != nil {
+		return nil, fmt.Errorf("queuing report: %w", err)
+	}
+	return job, nil
+}
+
+// Get implements Service
+func (s *reportService) Get(ctx context.Context, user *data.User, reportJobID string) (*data.ReportJob, error) {
+	job, err := s.jobs.GetByReportJobID(ctx, reportJobID)
+	if err != nil {
+		return nil, fmt.Errorf("getting report: %w", err)
+	}
+	if job == nil {
+		return nil, service.NotFound("report not found")
+	}
+	if err := s.authorize(ctx, user, job.FarmID, job.Type); err != nil {
+		if service.KindOf(err) == service.KindForbidden {
+			return nil, service.Forbidden("access denied: report does not belong to user's farm")
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// List implements Service
+func (s *reportService) List(ctx context.Context, user *data.User, farmID string) ([]*data.ReportJob, error) {
+	readable, visible := map[string]bool{}, false
+	for t := range kinds {
+		err := s.authorize(ctx, user, farmID, t)
+		if err != nil && service.KindOf(err) != service.KindForbidden {
+			return nil, err
+		}
+		readable[t] = err == nil
+		visible = visible || readable[t]
+	}
+	if !visible {
+		return nil, service.Forbidden("farm not found or access denied")
+	}
+
+	jobs, err := s.jobs.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting reports: %w", err)
+	}
+	return slices.DeleteFunc(jobs, func(j *data.ReportJob) bool { return !readable[j.Type] }), nil
+}
+
+// Open implements Service
+func (s *reportService) Open(ctx context.Context, user *data.User, reportJobID string) (io.ReadCloser, *data.ReportJob, error) {
+	job, err := s.Get(ctx, user, reportJobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch job.Status {
+	case StatusFailed:
+		return nil, nil, service.Conflict("report could not be generated: " + job.Error)
+	case StatusPending, StatusRunning:
+		return nil, nil, service.Conflict("report is still being generated")
+	}
+
+	file, _, err := s.files.Get(ctx, job.FileKey())
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening report file: %w", err)
+	}
+	return file, job, nil
+}
+
+// Delete implements Service. A report still being generated cannot be
+// deleted, so its file is never orphaned.
+func (s *reportService) Delete(ctx context.Context, user *data.User, reportJobID string) error {
+	job, err := s.Get(ctx, user, reportJobID)
+	if err != nil {
+		return err
+	}
+	if job.Status == StatusRunning {
+		return service.Conflict("report is still being generated")
+	}
+	if job.Status == StatusReady {
+		if err := s.files.Delete(ctx, job.FileKey()); err != nil {
+			return fmt.Errorf("deleting report file: %w", err)
+		}
+	}
+	if err := s.jobs.DeleteByID(ctx, int(job.ID)); err != nil {
+		return fmt.Errorf("deleting report: %w", err)
+	}
+	return nil
+}
+
+// Generate implements Service. Rendering errors are recorded on the job;
+// only failures to record them are returned.
+func (s *reportService) Generate(ctx context.Context, reportJobID string) error {
+	job, err := s.jobs.GetByReportJobID(ctx, reportJobID)
+	if err != nil {
+		return fmt.Errorf("getting report: %w", err)
+	}
+	if job == nil || job.Status != StatusPending {
+		return nil
+	}
+
+	now := time.Now()
+	job.StartedAt = &now
+	claimed, err := s.jobs.Claim(ctx, job)
+	if err != nil {
+		return fmt.Errorf("claiming report: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	size, renderErr := s.render(ctx, job)
+	completed := time.Now()
+	job.CompletedAt = &completed
+	if renderErr != nil {
+		job.Status, job.Error = StatusFailed, renderErr.Error()
+	} else {
+		job.Status, job.Size = StatusReady, size
+	}
+	if err := s.jobs.Finish(ctx, job); err != nil {
+		return fmt.Errorf("finishing report: %w", err)
+	}
+	if renderErr != nil {
+		return fmt.Errorf("rendering report %s: %w", job.ReportJobID, renderErr)
+	}
+	return nil
+}
+
+// GenerateQueued implements Service
+func (s *reportService) GenerateQueued(ctx context.Context, queuedBefore, runningBefore time.Time) (int, error) {
+	if _, err := s.jobs.FailStale(ctx, runningBefore, "report generation was interrupted; request it again"); err != nil {
+		return 0, fmt.Errorf("failing stale reports: %w", err)
+	}
+
+	ids, err := s.jobs.GetPending(ctx, queuedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("getting queued reports: %w", err)
+	}
+	generated := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := s.Generate(ctx, id); err != nil {
+			return generated, err
+		}
+		generated++
+	}
+	return generated, nil
+}
+
+// authorize checks that user may see reports of the given type on farmID
+func (s *reportService) authorize(ctx context.Context, user *data.User, farmID, reportType string) error {
+	if module := kinds[reportType].module; module != "" {
+		_, err := s.farms.Authorize(ctx, user, farmID, module, farm.Read)
+		return err
+	}
+	_, err := s.farms.Owned(ctx, user, farmID)
+	return err
+}
+
+// render lays out a report and stores its PDF, returning its size
+func (s *reportService) render(ctx context.Context, job *data.ReportJob) (int64, error) {
+	k, ok := kinds[job.Type]
+	if !ok {
+		return 0, fmt.Errorf("unknown report type %q", job.Type)
+	}
+	f, err := s.farmRepo.GetByFarmID(ctx, job.FarmID)
+	if err != nil {
+		return 0, fmt.Errorf("getting farm: %w", err)
+	}
+	if f == nil {
+		return 0, fmt.Errorf("farm no longer exists")
+	}
+
+	doc := newDocument(k.title, job, f)
+	if err := k.render(s, ctx, doc); err != nil {
+		return 0, err
+	}
+	content := doc.Bytes()
+	if err := s.files.Put(ctx, job.FileKey(), bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		return 0, fmt.Errorf("storing report file: %w", err)
+	}
+	return int64(len(content)), nil
+}
+
+// periodPattern matches YYYY, YYYY-Qn and YYYY-MM
+var periodPattern = regexp.MustCompile(`^(\d{4})(?:-(?:Q([1-4])|(\d{2})))?$`)
+
+// ParsePeriod resolves a report period, YYYY, YYYY-Qn or YYYY-MM, to its
+// canonical form and the dates it covers, [from, to). An empty period is the
+// year of now.
+func ParsePeriod(period string, now time.Time) (string, time.Time, time.Time, error) {
+	period = strings.ToUpper(strings.TrimSpace(period))
+	if period == "" {
+		period = strconv.Itoa(now.Year())
+	}
+	m := periodPattern.FindStringSubmatch(period)
+	if m == nil {
+		return "", time.Time{}, time.Time{}, service.Invalid("period must be YYYY, YYYY-Qn or YYYY-MM")
+	}
+
+	year, _ := strconv.Atoi(m[1])
+	switch {
+	case m[2] != "":
+		quarter, _ := strconv.Atoi(m[2])
+		from := time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, time.UTC)
+		return period, from, from.AddDate(0, 3, 0), nil
+	case m[3] != "":
+		month, _ := strconv.Atoi(m[3])
+		if month < 1 || month > 12 {
+			return "", time.Time{}, time.Time{}, service.Invalid("period month must be 01 to 12")
+		}
+		from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		return period, from, from.AddDate(0, 1, 0), nil
+	default:
+		from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		return period, from, from.AddDate(1, 0, 0), nil
+	}
+}
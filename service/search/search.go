@@ -0,0 +1,72 @@
+// Package search finds a farm's records by the words in them, such as the
+// note about a sick Friesian, across crops, livestock, employees and
+// documents
+package search
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Limits on a search
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+	// MaxQueryLength bounds the query text, in bytes
+	MaxQueryLength = 200
+)
+
+// Service is the search domain service
+type Service interface {
+	// Search returns up to limit of a farm's records matching query, best
+	// match first. No types searches every type; a limit of 0 is the default.
+	Search(ctx context.Context, user *data.User, farmID, query string, types []string, limit int) ([]data.SearchHit, error)
+}
+
+// searchService implements Service on top of the search repository
+type searchService struct {
+	search data.SearchInterface
+	farms  farm.Service
+}
+
+// New creates the search service
+func New(search data.SearchInterface, farms farm.Service) Service {
+	return &searchService{search: search, farms: farms}
+}
+
+// Search implements Service
+func (s *searchService) Search(ctx context.Context, user *data.User, farmID, query string, types []string, limit int) ([]data.SearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, service.Invalid("q is required")
+	}
+	if len(query) > MaxQueryLength {
+		return nil, service.Invalid(fmt.Sprintf("q must be at most %d characters", MaxQueryLength))
+	}
+	if len(types) == 0 {
+		types = data.SearchTypes()
+	}
+	for _, t := range types {
+		if !slices.Contains(data.SearchTypes(), t) {
+			return nil, service.Invalid("types must be among " + strings.Join(data.SearchTypes(), ", "))
+		}
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	hits, err := s.search.Search(ctx, farmID, query, types, limit)
+	if err != nil {
+		return nil, fmt.Errorf("searching records: %w", err)
+	}
+	return hits, nil
+}
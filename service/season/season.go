@@ -0,0 +1,289 @@
+// Package season manages a farm's growing seasons. Crops and transactions
+// count towards the season they are linked to, or else the one their date
+// falls in, so seasons can be totalled and compared season over season. A
+// closed season takes no more records.
+package season
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Season statuses
+const (
+	StatusOpen   = "Open"
+	StatusClosed = "Closed"
+)
+
+// Input holds the editable season fields. On update, zero values are left
+// unchanged.
+type Input struct {
+	Name      string
+	StartDate *time.Time
+	EndDate   *time.Time
+	Notes     string
+}
+
+// Summary is a season with the totals of the records counted towards it.
+// Change, in a comparison, is how far each total moved since the season
+// before.
+type Summary struct {
+	Season  *data.Season        `json:"season"`
+	Metrics *data.SeasonMetrics `json:"metrics"`
+	Change  *data.SeasonMetrics `json:"change,omitempty"`
+}
+
+// Service is the season domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Season, error)
+	Get(ctx context.Context, user *data.User, seasonID string) (*data.Season, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.Season, error)
+	// Update changes an open season
+	Update(ctx context.Context, user *data.User, seasonID string, in Input) (*data.Season, error)
+	// Delete removes an open season. Records linked to it are counted by
+	// their dates again.
+	Delete(ctx context.Context, user *data.User, seasonID string) error
+	// Close ends a season: no more records may be linked to it and it can no
+	// longer be changed
+	Close(ctx context.Context, user *data.User, seasonID string) (*data.Season, error)
+	// Summarize totals the crops and transactions counted towards a season
+	Summarize(ctx context.Context, user *data.User, seasonID string) (*Summary, error)
+	// Compare summarizes the given seasons of a farm, or all of them if none
+	// are given, earliest first, each with its change from the one before
+	Compare(ctx context.Context, user *data.User, farmID string, seasonIDs []string) ([]*Summary, error)
+}
+
+// seasonService implements Service on top of the season repository
+type seasonService struct {
+	seasons data.SeasonInterface
+	farms   farm.Service
+}
+
+// New creates the season service
+func New(seasons data.SeasonInterface, farms farm.Service) Service {
+	return &seasonService{seasons: seasons, farms: farms}
+}
+
+// Create adds an open season to one of the user's farms
+func (s *seasonService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Season, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	season := &data.Season{
+		FarmID:    farmID,
+		Name:      in.Name,
+		StartDate: *in.StartDate,
+		EndDate:   *in.EndDate,
+		Status:    StatusOpen,
+		Notes:     in.Notes,
+	}
+	if err := s.checkDates(ctx, season); err != nil {
+		return nil, err
+	}
+	if err := s.seasons.Insert(ctx, season); err != nil {
+		return nil, fmt.Errorf("creating season: %w", err)
+	}
+	return season, nil
+}
+
+// Get returns a season of one of the user's farms
+func (s *seasonService) Get(ctx context.Context, user *data.User, seasonID string) (*data.Season, error) {
+	season, err := s.seasons.GetBySeasonID(ctx, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("getting season: %w", err)
+	}
+	if season == nil {
+		return nil, service.NotFound("season not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, season.FarmID, "season"); err != nil {
+		return nil, err
+	}
+	return season, nil
+}
+
+// List returns the seasons of one of the user's farms, earliest first
+func (s *seasonService) List(ctx context.Context, user *data.User, farmID string) ([]*data.Season, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	seasons, err := s.seasons.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting seasons: %w", err)
+	}
+	return seasons, nil
+}
+
+// Update implements Service
+func (s *seasonService) Update(ctx context.Context, user *data.User, seasonID string, in Input) (*data.Season, error) {
+	season, err := s.open(ctx, user, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		season.Name = in.Name
+	}
+	if in.StartDate != nil {
+		season.StartDate = *in.StartDate
+	}
+	if in.EndDate != nil {
+		season.EndDate = *in.EndDate
+	}
+	if in.Notes != "" {
+		season.Notes = in.Notes
+	}
+	if in.StartDate != nil || in.EndDate != nil {
+		if err := s.checkDates(ctx, season); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.seasons.Update(ctx, season); err != nil {
+		return nil, fmt.Errorf("updating season: %w", err)
+	}
+	return season, nil
+}
+
+// Delete implements Service
+func (s *seasonService) Delete(ctx context.Context, user *data.User, seasonID string) error {
+	season, err := s.open(ctx, user, seasonID)
+	if err != nil {
+		return err
+	}
+	if err := s.seasons.DeleteByID(ctx, int(season.ID)); err != nil {
+		return fmt.Errorf("deleting season: %w", err)
+	}
+	return nil
+}
+
+// Close implements Service
+func (s *seasonService) Close(ctx context.Context, user *data.User, seasonID string) (*data.Season, error) {
+	season, err := s.open(ctx, user, seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	season.Status = StatusClosed
+	season.ClosedAt = &now
+	if err := s.seasons.Update(ctx, season); err != nil {
+		return nil, fmt.Errorf("closing season: %w", err)
+	}
+	return season, nil
+}
+
+// Summarize implements Service
+func (s *seasonService) Summarize(ctx context.Context, user *data.User, seasonID string) (*Summary, error) {
+	season, err := s.Get(ctx, user, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	metrics, err := s.seasons.Metrics(ctx, season)
+	if err != nil {
+		return nil, fmt.Errorf("totalling season: %w", err)
+	}
+	return &Summary{Season: season, Metrics: metrics}, nil
+}
+
+// Compare implements Service
+func (s *seasonService) Compare(ctx context.Context, user *data.User, farmID string, seasonIDs []string) ([]*Summary, error) {
+	seasons, err := s.List(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+	if len(seasonIDs) > 0 {
+		for _, id := range seasonIDs {
+			if !slices.ContainsFunc(seasons, func(season *data.Season) bool { return season.SeasonID == id }) {
+				return nil, service.Invalid(fmt.Sprintf("season %s not found on this farm", id))
+			}
+		}
+		seasons = slices.DeleteFunc(seasons, func(season *data.Season) bool {
+			return !slices.Contains(seasonIDs, season.SeasonID)
+		})
+	}
+
+	summaries := make([]*Summary, len(seasons))
+	for i, season := range seasons {
+		metrics, err := s.seasons.Metrics(ctx, season)
+		if err != nil {
+			return nil, fmt.Errorf("totalling season: %w", err)
+		}
+		summaries[i] = &Summary{Season: season, Metrics: metrics}
+		if i > 0 {
+			summaries[i].Change = change(summaries[i-1].Metrics, metrics)
+		}
+	}
+	return summaries, nil
+}
+
+// open returns one of the user's seasons that has not been closed
+func (s *seasonService) open(ctx context.Context, user *data.User, seasonID string) (*data.Season, error) {
+	season, err := s.Get(ctx, user, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if season.Status == StatusClosed {
+		return nil, service.Conflict("season is closed")
+	}
+	return season, nil
+}
+
+// checkDates verifies the season ends after it starts and shares no day with
+// another season of the farm
+func (s *seasonService) checkDates(ctx context.Context, season *data.Season) error {
+	if season.EndDate.Before(season.StartDate) {
+		return service.Invalid("end date must not be before start date")
+	}
+	overlapping, err := s.seasons.GetOverlapping(ctx, season)
+	if err != nil {
+		return fmt.Errorf("getting overlapping seasons: %w", err)
+	}
+	if len(overlapping) > 0 {
+		return service.Conflict(fmt.Sprintf("season overlaps %s", overlapping[0].Name))
+	}
+	return nil
+}
+
+// change returns how far each total moved from before to after
+func change(before, after *data.SeasonMetrics) *data.SeasonMetrics {
+	return &data.SeasonMetrics{
+		CropsPlanted:      after.CropsPlanted - before.CropsPlanted,
+		CropsHarvested:    after.CropsHarvested - before.CropsHarvested,
+		CropsFailed:       after.CropsFailed - before.CropsFailed,
+		QuantityHarvested: after.QuantityHarvested - before.QuantityHarvested,
+		Income:            after.Income - before.Income,
+		Expenses:          after.Expenses - before.Expenses,
+		Net:               after.Net - before.Net,
+	}
+}
+
+// Link returns the ID to link a record of farmID to for the season named by
+// seasonID, which must be an open season of that farm. As with other optional
+// links, nil leaves the record's link unchanged, which the caller tells by
+// the returned ok being false, and an empty ID unlinks it.
+func Link(ctx context.Context, seasons data.SeasonInterface, farmID string, seasonID *string) (link *string, ok bool, err error) {
+	if seasonID == nil {
+		return nil, false, nil
+	}
+	if *seasonID == "" {
+		return nil, true, nil
+	}
+
+	season, err := seasons.GetBySeasonID(ctx, *seasonID)
+	if err != nil {
+		return nil, false, fmt.Errorf("getting season: %w", err)
+	}
+	if season == nil || season.FarmID != farmID {
+		return nil, false, service.Invalid("season not found on this farm")
+	}
+	if season.Status == StatusClosed {
+		return nil, false, service.Conflict("season is closed")
+	}
+	return &season.SeasonID, true, nil
+}
@@ -0,0 +1,145 @@
+// Package service holds what the domain services share. Each domain (auth,
+// farm, field, crop, livestock, workforce, equipment, asset, finance,
+// purchase, lock, buyer, dispute, escrow, irrigation, market, importer,
+// attachment, document, report, dashboard, coop, dairy, grazing, rainfall,
+// offline, search, breeding, production, feeding, growth, mortality, spray,
+// activity, integration, export, season, loan, tag, view, provider, note,
+// inventory, water, chemical, utility, sustainability, carbon)
+// lives in its own sub-package and exposes a Service interface that the HTTP
+// handlers call; the services own the business rules and ownership checks,
+// the handlers only translate between HTTP and those calls.
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Kind classifies a service error so callers can map it to a response
+type Kind int
+
+// Error kinds
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+)
+
+// Error is an expected failure whose message is safe to show to the client
+type Error struct {
+	Kind    Kind
+	Message string
+	// Current is the record as it is now, for a Conflict caused by an
+	// update made against an out-of-date version of it
+	Current any
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Invalid reports a request the domain rules reject
+func Invalid(message string) error {
+	return &Error{Kind: KindInvalid, Message: message}
+}
+
+// Unauthorized reports missing or wrong credentials
+func Unauthorized(message string) error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+// Forbidden reports a record the user may not access
+func Forbidden(message string) error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+// NotFound reports a missing record
+func NotFound(message string) error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+// Conflict reports a change that clashes with the current state
+func Conflict(message string) error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+// Stale reports an update made against an out-of-date version of a record.
+// what names the record, e.g. "crop"; current is the record as it is now,
+// for the client to reapply its change to.
+func Stale(what string, current any) error {
+	return &Error{
+		Kind:    KindConflict,
+		Message: what + " was changed by another update; reapply your changes to the current version",
+		Current: current,
+	}
+}
+
+// unversionedKey is the context key marking a client that predates versions
+type unversionedKey struct{}
+
+// AllowUnversioned marks ctx as coming from a client that predates record
+// versions, whose changes without a version CheckVersion lets through
+// unchecked
+func AllowUnversioned(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unversionedKey{}, true)
+}
+
+// CheckVersion rejects a change that does not send the version of the record
+// it was made against, or sends one other than version, the record's current
+// version. current is the record, returned with a Stale error.
+func CheckVersion(ctx context.Context, what string, sent, version int, current any) error {
+	if sent == 0 {
+		if unversioned, _ := ctx.Value(unversionedKey{}).(bool); unversioned {
+			return nil
+		}
+		return Invalid("version is required; send the version of the " + what + " being changed")
+	}
+	if sent != version {
+		return Stale(what, current)
+	}
+	return nil
+}
+
+// KindOf returns the kind of err, or KindInternal for unexpected errors
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindInternal
+}
+
+// CurrentOf returns the current record carried by a Stale error, or nil
+func CurrentOf(err error) any {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Current
+	}
+	return nil
+}
+
+// Fields names the fields a partial update sets, by their JSON names. A
+// listed field is set even to its zero value, which clears it; any other
+// field is left unchanged.
+type Fields map[string]bool
+
+// Sets reports whether an update sets field. Without fields, as for a full
+// update, it sets those with a non-zero value.
+func (f Fields) Sets(field string, nonZero bool) bool {
+	if f == nil {
+		return nonZero
+	}
+	return f[field]
+}
+
+// Day returns the start of t's day in UTC, for grouping and comparing dated
+// records by their date
+func Day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,261 @@
+// Package spray keeps a farm's spray records: each application of an
+// agrochemical to a crop, drawn from the chemical store, with its rate,
+// applicator, weather and pre-harvest interval. Records are an audit trail
+// for GAP certification, so they are never edited or deleted, and they flag
+// the crops that may not be harvested yet.
+package spray
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Input records a spray application
+type Input struct {
+	CropID                 string
+	ChemicalProductID      string
+	Date                   *time.Time // Defaults to now
+	Rate                   float64
+	RateUnit               string
+	AreaSprayed            float64 // Hectares
+	QuantityUsed           float64 // In the product's unit, taken out of the store
+	Applicator             string
+	ApplicatorEmployeeID   string
+	PPEConfirmed           bool
+	WindSpeed              *float64 // km/h
+	Temperature            *float64 // °C
+	Humidity               *float64 // %
+	WeatherConditions      string
+	PreHarvestIntervalDays int
+	Target                 string
+	Notes                  string
+}
+
+// Withholding is a crop still inside the pre-harvest interval of one or more
+// sprays
+type Withholding struct {
+	CropID          string              `json:"cropId"`
+	CropName        string              `json:"cropName"`
+	FieldID         *string             `json:"fieldId,omitempty"`
+	SafeHarvestDate time.Time           `json:"safeHarvestDate"` // The latest of its sprays'
+	DaysRemaining   int                 `json:"daysRemaining"`
+	Sprays          []*data.SprayRecord `json:"sprays"`
+}
+
+// Service is the spray domain service
+type Service interface {
+	// Record logs a spray application and takes the product out of the
+	// chemical store, in a single transaction
+	Record(ctx context.Context, user *data.User, farmID string, in Input) (*data.SprayRecord, error)
+	Get(ctx context.Context, user *data.User, sprayRecordID string) (*data.SprayRecord, error)
+	List(ctx context.Context, user *data.User, farmID string, filter data.SprayRecordFilter) ([]*data.SprayRecord, error)
+	// Withholding returns the farm's unharvested crops still inside a
+	// pre-harvest interval at the given time, or now when it is nil, the
+	// longest wait first
+	Withholding(ctx context.Context, user *data.User, farmID string, at *time.Time) ([]*Withholding, error)
+}
+
+// sprayService implements Service on top of the spray record, crop, chemical
+// store and employee repositories
+type sprayService struct {
+	models data.Models
+	locks  lock.Checker
+	farms  farm.Service
+}
+
+// New creates the spray service
+func New(models data.Models, locks lock.Checker, farms farm.Service) Service {
+	return &sprayService{models: models, locks: locks, farms: farms}
+}
+
+// Record logs a spray application on one of the user's farms
+func (s *sprayService) Record(ctx context.Context, user *data.User, farmID string, in Input) (*data.SprayRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	switch {
+	case in.Rate <= 0 || strings.TrimSpace(in.RateUnit) == "":
+		return nil, service.Invalid("rate and rate unit are required")
+	case in.QuantityUsed <= 0:
+		return nil, service.Invalid("quantity used must be greater than 0")
+	case strings.TrimSpace(in.Applicator) == "":
+		return nil, service.Invalid("applicator is required")
+	case !in.PPEConfirmed:
+		return nil, service.Invalid("PPE use must be confirmed before logging a spray")
+	case in.PreHarvestIntervalDays < 0 || in.AreaSprayed < 0:
+		return nil, service.Invalid("pre-harvest interval and area sprayed must not be negative")
+	}
+
+	crop, err := s.models.Crop.GetByCropID(ctx, in.CropID)
+	if err != nil {
+		return nil, fmt.Errorf("getting crop: %w", err)
+	}
+	if crop == nil || crop.FarmID != farmID {
+		return nil, service.Invalid("crop not found on this farm")
+	}
+	product, err := s.models.ChemicalProduct.GetByChemicalProductID(ctx, in.ChemicalProductID)
+	if err != nil {
+		return nil, fmt.Errorf("getting chemical product: %w", err)
+	}
+	if product == nil || product.FarmID != farmID {
+		return nil, service.Invalid("chemical product not found on this farm")
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if product.ExpiryDate != nil && product.ExpiryDate.Before(date) {
+		return nil, service.Invalid(fmt.Sprintf("batch %s expired on %s", product.BatchNumber, product.ExpiryDate.Format("2006-01-02")))
+	}
+	if err := s.locks.Check(ctx, farmID, date); err != nil {
+		return nil, err
+	}
+
+	var employeeID *string
+	if in.ApplicatorEmployeeID != "" {
+		employee, err := s.models.Employee.GetByEmployeeID(ctx, in.ApplicatorEmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("getting applicator employee: %w", err)
+		}
+		if employee == nil || employee.FarmID != farmID {
+			return nil, service.Invalid("applicator employee not found on this farm")
+		}
+		employeeID = &employee.EmployeeID
+	}
+
+	usage := &data.ChemicalUsage{
+		ChemicalProductID:    product.ChemicalProductID,
+		FarmID:               farmID,
+		Date:                 date,
+		Quantity:             in.QuantityUsed,
+		Applicator:           in.Applicator,
+		ApplicatorEmployeeID: employeeID,
+		PPEConfirmed:         in.PPEConfirmed,
+		Target:               crop.Name,
+		Purpose:              in.Target,
+		Notes:                in.Notes,
+	}
+	record := &data.SprayRecord{
+		FarmID:                 farmID,
+		CropID:                 crop.CropID,
+		FieldID:                crop.FieldID,
+		ChemicalProductID:      product.ChemicalProductID,
+		Date:                   date,
+		Rate:                   in.Rate,
+		RateUnit:               strings.TrimSpace(in.RateUnit),
+		AreaSprayed:            in.AreaSprayed,
+		QuantityUsed:           in.QuantityUsed,
+		Applicator:             in.Applicator,
+		ApplicatorEmployeeID:   employeeID,
+		PPEConfirmed:           in.PPEConfirmed,
+		WindSpeed:              in.WindSpeed,
+		Temperature:            in.Temperature,
+		Humidity:               in.Humidity,
+		WeatherConditions:      in.WeatherConditions,
+		PreHarvestIntervalDays: in.PreHarvestIntervalDays,
+		SafeHarvestDate:        date.AddDate(0, 0, in.PreHarvestIntervalDays),
+		Target:                 in.Target,
+		Notes:                  in.Notes,
+	}
+
+	err = s.models.WithTransaction(ctx, func(tx data.Models) error {
+		err := tx.ChemicalUsage.Insert(ctx, usage)
+		if errors.Is(err, data.ErrInsufficientStock) {
+			return service.Invalid(fmt.Sprintf("only %.2f %s of %s in store", product.Quantity, product.Unit, product.Name))
+		}
+		if err != nil {
+			return fmt.Errorf("drawing chemical from store: %w", err)
+		}
+		record.ChemicalUsageID = usage.ChemicalUsageID
+		if err := tx.SprayRecord.Insert(ctx, record); err != nil {
+			return fmt.Errorf("recording spray: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	product.Quantity -= in.QuantityUsed
+	record.Crop, record.ChemicalProduct = crop, product
+	return record, nil
+}
+
+// Get returns a spray record on one of the user's farms
+func (s *sprayService) Get(ctx context.Context, user *data.User, sprayRecordID string) (*data.SprayRecord, error) {
+	record, err := s.models.SprayRecord.GetBySprayRecordID(ctx, sprayRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting spray record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("spray record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "spray record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List returns the spray records of one of the user's farms, most recent
+// first
+func (s *sprayService) List(ctx context.Context, user *data.User, farmID string, filter data.SprayRecordFilter) ([]*data.SprayRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.models.SprayRecord.GetByFarmID(ctx, farmID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("getting spray records: %w", err)
+	}
+	return records, nil
+}
+
+// Withholding implements Service
+func (s *sprayService) Withholding(ctx context.Context, user *data.User, farmID string, at *time.Time) ([]*Withholding, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if at != nil {
+		now = *at
+	}
+
+	records, err := s.models.SprayRecord.GetWithholding(ctx, farmID, now)
+	if err != nil {
+		return nil, fmt.Errorf("getting spray records: %w", err)
+	}
+
+	crops := []*Withholding{}
+	byCrop := map[string]*Withholding{}
+	for _, record := range records {
+		crop, ok := byCrop[record.CropID]
+		if !ok {
+			crop = &Withholding{CropID: record.CropID, FieldID: record.FieldID}
+			if record.Crop != nil {
+				crop.CropName, crop.FieldID = record.Crop.Name, record.Crop.FieldID
+			}
+			byCrop[record.CropID] = crop
+			crops = append(crops, crop)
+		}
+		crop.Sprays = append(crop.Sprays, record)
+		if record.SafeHarvestDate.After(crop.SafeHarvestDate) {
+			crop.SafeHarvestDate = record.SafeHarvestDate
+		}
+	}
+	for _, crop := range crops {
+		crop.DaysRemaining = int(math.Ceil(crop.SafeHarvestDate.Sub(now).Hours() / 24))
+	}
+	slices.SortFunc(crops, func(a, b *Withholding) int {
+		return cmp.Or(b.SafeHarvestDate.Compare(a.SafeHarvestDate), strings.Compare(a.CropName, b.CropName))
+	})
+	return crops, nil
+}
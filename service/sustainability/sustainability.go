@@ -0,0 +1,528 @@
+// Package sustainability keeps a farm's sustainability checklist and the
+// seasonal self-assessments scored against it. A submitted assessment can be
+// exported as an evidence pack for certification programs.
+package sustainability
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Assessment statuses
+const (
+	StatusDraft     = "Draft"
+	StatusSubmitted = "Submitted"
+)
+
+// PracticeInput holds the editable checklist practice fields. On update, zero
+// values are left unchanged.
+type PracticeInput struct {
+	Category         string
+	Name             string
+	Description      string
+	Weight           float64 // Defaults to 1
+	EvidenceRequired *bool
+	Active           *bool // Defaults to true
+}
+
+// ResponseInput is the answer to one practice in an assessment
+type ResponseInput struct {
+	PracticeID  string
+	Status      string
+	Evidence    string
+	EvidenceURL string
+	Notes       string
+}
+
+// AssessmentInput holds the editable assessment fields. On update, zero
+// values are left unchanged and non-nil Responses replace the existing
+// answers.
+type AssessmentInput struct {
+	Season     string
+	AssessedAt *time.Time // Defaults to now
+	AssessedBy string     // Defaults to the user's name
+	Notes      string
+	Responses  []ResponseInput
+}
+
+// EvidenceItem is one practice line in a certification evidence pack
+type EvidenceItem struct {
+	Category         string  `json:"category"`
+	Practice         string  `json:"practice"`
+	Description      string  `json:"description"`
+	Weight           float64 `json:"weight"`
+	Status           string  `json:"status"`
+	EvidenceRequired bool    `json:"evidenceRequired"`
+	Evidence         string  `json:"evidence"`
+	EvidenceURL      string  `json:"evidenceUrl"`
+	Notes            string  `json:"notes"`
+}
+
+// EvidencePack is an exportable summary of a submitted assessment for certification programs
+type EvidencePack struct {
+	FarmID          string             `json:"farmId"`
+	FarmName        string             `json:"farmName"`
+	Location        string             `json:"location"`
+	Season          string             `json:"season"`
+	AssessedAt      time.Time          `json:"assessedAt"`
+	AssessedBy      string             `json:"assessedBy"`
+	Status          string             `json:"status"`
+	Score           float64            `json:"score"`
+	MaxScore        float64            `json:"maxScore"`
+	ScorePercent    float64            `json:"scorePercent"`
+	CategoryPercent map[string]float64 `json:"categoryPercent"`
+	Items           []EvidenceItem     `json:"items"`
+	GeneratedAt     time.Time          `json:"generatedAt"`
+}
+
+// Service is the sustainability domain service
+type Service interface {
+	CreatePractice(ctx context.Context, user *data.User, farmID string, in PracticeInput) (*data.SustainabilityPractice, error)
+	// CreateDefaultPractices seeds a farm's checklist with the standard
+	// practices. It refuses if the farm already has a checklist.
+	CreateDefaultPractices(ctx context.Context, user *data.User, farmID string) ([]*data.SustainabilityPractice, error)
+	// ListPractices returns a farm's checklist, optionally only the active
+	// practices
+	ListPractices(ctx context.Context, user *data.User, farmID string, activeOnly bool) ([]*data.SustainabilityPractice, error)
+	UpdatePractice(ctx context.Context, user *data.User, practiceID string, in PracticeInput) (*data.SustainabilityPractice, error)
+	// DeletePractice soft deletes a practice, so past assessments keep their
+	// wording
+	DeletePractice(ctx context.Context, user *data.User, practiceID string) error
+
+	// CreateAssessment starts a draft self-assessment for a season. Every
+	// response must answer a practice on the farm's checklist.
+	CreateAssessment(ctx context.Context, user *data.User, farmID string, in AssessmentInput) (*data.SustainabilityAssessment, error)
+	// ListAssessments returns a farm's assessments, optionally for one season
+	ListAssessments(ctx context.Context, user *data.User, farmID, season string) ([]*data.SustainabilityAssessment, error)
+	GetAssessment(ctx context.Context, user *data.User, assessmentID string) (*data.SustainabilityAssessment, error)
+	// UpdateAssessment changes a draft assessment; submitted ones cannot be
+	// changed
+	UpdateAssessment(ctx context.Context, user *data.User, assessmentID string, in AssessmentInput) (*data.SustainabilityAssessment, error)
+	// SubmitAssessment finalises a draft once every active practice is
+	// answered and required evidence is attached
+	SubmitAssessment(ctx context.Context, user *data.User, assessmentID string) (*data.SustainabilityAssessment, error)
+	DeleteAssessment(ctx context.Context, user *data.User, assessmentID string) error
+	// EvidencePack summarises an assessment for certification programs
+	EvidencePack(ctx context.Context, user *data.User, assessmentID string) (*data.SustainabilityAssessment, *EvidencePack, error)
+}
+
+// sustainabilityService implements Service on top of the checklist and
+// assessment repositories
+type sustainabilityService struct {
+	practices   data.SustainabilityPracticeInterface
+	assessments data.SustainabilityAssessmentInterface
+	farms       farm.Service
+}
+
+// New creates the sustainability service
+func New(practices data.SustainabilityPracticeInterface, assessments data.SustainabilityAssessmentInterface, farms farm.Service) Service {
+	return &sustainabilityService{practices: practices, assessments: assessments, farms: farms}
+}
+
+// CreatePractice adds a practice to the checklist of one of the user's farms
+func (s *sustainabilityService) CreatePractice(ctx context.Context, user *data.User, farmID string, in PracticeInput) (*data.SustainabilityPractice, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	if in.Weight == 0 {
+		in.Weight = 1
+	}
+
+	practice := &data.SustainabilityPractice{
+		FarmID:      farmID,
+		Category:    in.Category,
+		Name:        in.Name,
+		Description: in.Description,
+		Weight:      in.Weight,
+		Active:      true,
+	}
+	if in.EvidenceRequired != nil {
+		practice.EvidenceRequired = *in.EvidenceRequired
+	}
+	if in.Active != nil {
+		practice.Active = *in.Active
+	}
+
+	if err := s.practices.Insert(ctx, practice); err != nil {
+		return nil, fmt.Errorf("creating sustainability practice: %w", err)
+	}
+	return practice, nil
+}
+
+// CreateDefaultPractices implements Service
+func (s *sustainabilityService) CreateDefaultPractices(ctx context.Context, user *data.User, farmID string) ([]*data.SustainabilityPractice, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	existing, err := s.practices.GetByFarmID(ctx, farmID, false)
+	if err != nil {
+		return nil, fmt.Errorf("getting sustainability practices: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, service.Conflict("farm already has a checklist")
+	}
+
+	practices := make([]*data.SustainabilityPractice, 0, len(data.DefaultSustainabilityPractices))
+	for _, p := range data.DefaultSustainabilityPractices {
+		practice := p
+		practice.FarmID = farmID
+		practice.Active = true
+		practices = append(practices, &practice)
+	}
+	if err := s.practices.InsertMany(ctx, practices); err != nil {
+		return nil, fmt.Errorf("creating default sustainability practices: %w", err)
+	}
+	return practices, nil
+}
+
+// ListPractices implements Service
+func (s *sustainabilityService) ListPractices(ctx context.Context, user *data.User, farmID string, activeOnly bool) ([]*data.SustainabilityPractice, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	practices, err := s.practices.GetByFarmID(ctx, farmID, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("getting sustainability practices: %w", err)
+	}
+	return practices, nil
+}
+
+// UpdatePractice changes the non-zero fields of in on a checklist practice
+func (s *sustainabilityService) UpdatePractice(ctx context.Context, user *data.User, practiceID string, in PracticeInput) (*data.SustainabilityPractice, error) {
+	practice, err := s.practice(ctx, user, practiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Category != "" {
+		practice.Category = in.Category
+	}
+	if in.Name != "" {
+		practice.Name = in.Name
+	}
+	if in.Description != "" {
+		practice.Description = in.Description
+	}
+	if in.Weight > 0 {
+		practice.Weight = in.Weight
+	}
+	if in.EvidenceRequired != nil {
+		practice.EvidenceRequired = *in.EvidenceRequired
+	}
+	if in.Active != nil {
+		practice.Active = *in.Active
+	}
+
+	if err := s.practices.Update(ctx, practice); err != nil {
+		return nil, fmt.Errorf("updating sustainability practice: %w", err)
+	}
+	return practice, nil
+}
+
+// DeletePractice implements Service
+func (s *sustainabilityService) DeletePractice(ctx context.Context, user *data.User, practiceID string) error {
+	practice, err := s.practice(ctx, user, practiceID)
+	if err != nil {
+		return err
+	}
+	if err := s.practices.DeleteByID(ctx, int(practice.ID)); err != nil {
+		return fmt.Errorf("deleting sustainability practice: %w", err)
+	}
+	return nil
+}
+
+// CreateAssessment implements Service
+func (s *sustainabilityService) CreateAssessment(ctx context.Context, user *data.User, farmID string, in AssessmentInput) (*data.SustainabilityAssessment, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	practices, err := s.checklist(ctx, farmID)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := toResponses(in.Responses, practices)
+	if err != nil {
+		return nil, err
+	}
+
+	assessedAt := time.Now()
+	if in.AssessedAt != nil {
+		assessedAt = *in.AssessedAt
+	}
+	if in.AssessedBy == "" {
+		in.AssessedBy = user.FirstName + " " + user.LastName
+	}
+
+	assessment := &data.SustainabilityAssessment{
+		FarmID:     farmID,
+		Season:     in.Season,
+		AssessedAt: assessedAt,
+		AssessedBy: in.AssessedBy,
+		Status:     StatusDraft,
+		Notes:      in.Notes,
+		Responses:  responses,
+	}
+	assessment.CalculateScore(practices)
+
+	if err := s.assessments.Insert(ctx, assessment); err != nil {
+		return nil, fmt.Errorf("creating sustainability assessment: %w", err)
+	}
+	return assessment, nil
+}
+
+// ListAssessments implements Service
+func (s *sustainabilityService) ListAssessments(ctx context.Context, user *data.User, farmID, season string) ([]*data.SustainabilityAssessment, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	assessments, err := s.assessments.GetByFarmID(ctx, farmID, season)
+	if err != nil {
+		return nil, fmt.Errorf("getting sustainability assessments: %w", err)
+	}
+	return assessments, nil
+}
+
+// GetAssessment returns an assessment, with its responses, on one of the
+// user's farms
+func (s *sustainabilityService) GetAssessment(ctx context.Context, user *data.User, assessmentID string) (*data.SustainabilityAssessment, error) {
+	assessment, err := s.assessments.GetBySustainabilityAssessmentID(ctx, assessmentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting sustainability assessment: %w", err)
+	}
+	if assessment == nil {
+		return nil, service.NotFound("assessment not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, assessment.FarmID, "assessment"); err != nil {
+		return nil, err
+	}
+	return assessment, nil
+}
+
+// UpdateAssessment implements Service
+func (s *sustainabilityService) UpdateAssessment(ctx context.Context, user *data.User, assessmentID string, in AssessmentInput) (*data.SustainabilityAssessment, error) {
+	assessment, err := s.GetAssessment(ctx, user, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Status != StatusDraft {
+		return nil, service.Conflict("submitted assessments cannot be changed")
+	}
+	practices, err := s.checklist(ctx, assessment.FarmID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Season != "" {
+		assessment.Season = in.Season
+	}
+	if in.AssessedAt != nil {
+		assessment.AssessedAt = *in.AssessedAt
+	}
+	if in.AssessedBy != "" {
+		assessment.AssessedBy = in.AssessedBy
+	}
+	if in.Notes != "" {
+		assessment.Notes = in.Notes
+	}
+	if in.Responses != nil {
+		responses, err := toResponses(in.Responses, practices)
+		if err != nil {
+			return nil, err
+		}
+		assessment.Responses = responses
+	}
+	assessment.CalculateScore(practices)
+
+	if err := s.assessments.Update(ctx, assessment); err != nil {
+		return nil, fmt.Errorf("updating sustainability assessment: %w", err)
+	}
+	return assessment, nil
+}
+
+// SubmitAssessment implements Service
+func (s *sustainabilityService) SubmitAssessment(ctx context.Context, user *data.User, assessmentID string) (*data.SustainabilityAssessment, error) {
+	assessment, err := s.GetAssessment(ctx, user, assessmentID)
+	if err != nil {
+		return nil, err
+	}
+	if assessment.Status != StatusDraft {
+		return nil, service.Conflict("assessment has already been submitted")
+	}
+	practices, err := s.checklist(ctx, assessment.FarmID)
+	if err != nil {
+		return nil, err
+	}
+
+	answered := map[string]data.SustainabilityResponse{}
+	for _, response := range assessment.Responses {
+		answered[response.SustainabilityPracticeID] = response
+	}
+
+	var problems []string
+	for id, practice := range practices {
+		if !practice.Active {
+			continue
+		}
+		response, ok := answered[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q has not been answered", practice.Name))
+			continue
+		}
+		if practice.EvidenceRequired && (response.Status == "Implemented" || response.Status == "Partial") &&
+			response.Evidence == "" && response.EvidenceURL == "" {
+			problems = append(problems, fmt.Sprintf("%q requires evidence", practice.Name))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, service.Invalid("assessment is incomplete: " + strings.Join(problems, "; "))
+	}
+
+	assessment.Status = StatusSubmitted
+	assessment.CalculateScore(practices)
+
+	if err := s.assessments.Update(ctx, assessment); err != nil {
+		return nil, fmt.Errorf("submitting sustainability assessment: %w", err)
+	}
+	return assessment, nil
+}
+
+// DeleteAssessment implements Service
+func (s *sustainabilityService) DeleteAssessment(ctx context.Context, user *data.User, assessmentID string) error {
+	assessment, err := s.GetAssessment(ctx, user, assessmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.assessments.DeleteByID(ctx, int(assessment.ID)); err != nil {
+		return fmt.Errorf("deleting sustainability assessment: %w", err)
+	}
+	return nil
+}
+
+// EvidencePack implements Service. The category percentages score Partial
+// as half and leave Not Applicable practices out.
+func (s *sustainabilityService) EvidencePack(ctx context.Context, user *data.User, assessmentID string) (*data.SustainabilityAssessment, *EvidencePack, error) {
+	assessment, err := s.assessments.GetBySustainabilityAssessmentID(ctx, assessmentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting sustainability assessment: %w", err)
+	}
+	if assessment == nil {
+		return nil, nil, service.NotFound("assessment not found")
+	}
+	f, err := s.farms.Owned(ctx, user, assessment.FarmID)
+	if service.KindOf(err) == service.KindForbidden {
+		return nil, nil, service.Forbidden("access denied: assessment does not belong to user's farm")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pack := &EvidencePack{
+		FarmID:          f.FarmID,
+		FarmName:        f.Name,
+		Location:        f.Location,
+		Season:          assessment.Season,
+		AssessedAt:      assessment.AssessedAt,
+		AssessedBy:      assessment.AssessedBy,
+		Status:          assessment.Status,
+		Score:           assessment.Score,
+		MaxScore:        assessment.MaxScore,
+		ScorePercent:    assessment.ScorePercent,
+		CategoryPercent: map[string]float64{},
+		Items:           []EvidenceItem{},
+		GeneratedAt:     time.Now(),
+	}
+
+	earned, available := map[string]float64{}, map[string]float64{}
+	for _, response := range assessment.Responses {
+		if response.Practice == nil {
+			continue
+		}
+		p := response.Practice
+		pack.Items = append(pack.Items, EvidenceItem{
+			Category:         p.Category,
+			Practice:         p.Name,
+			Description:      p.Description,
+			Weight:           p.Weight,
+			Status:           response.Status,
+			EvidenceRequired: p.EvidenceRequired,
+			Evidence:         response.Evidence,
+			EvidenceURL:      response.EvidenceURL,
+			Notes:            response.Notes,
+		})
+
+		if response.Status == "Not Applicable" {
+			continue
+		}
+		available[p.Category] += p.Weight
+		switch response.Status {
+		case "Implemented":
+			earned[p.Category] += p.Weight
+		case "Partial":
+			earned[p.Category] += p.Weight / 2
+		}
+	}
+	for category, max := range available {
+		if max > 0 {
+			pack.CategoryPercent[category] = round2(earned[category] / max * 100)
+		}
+	}
+	return assessment, pack, nil
+}
+
+// practice loads a checklist practice on one of the user's farms
+func (s *sustainabilityService) practice(ctx context.Context, user *data.User, practiceID string) (*data.SustainabilityPractice, error) {
+	practice, err := s.practices.GetBySustainabilityPracticeID(ctx, practiceID)
+	if err != nil {
+		return nil, fmt.Errorf("getting sustainability practice: %w", err)
+	}
+	if practice == nil {
+		return nil, service.NotFound("practice not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, practice.FarmID, "practice"); err != nil {
+		return nil, err
+	}
+	return practice, nil
+}
+
+// checklist loads a farm's practices keyed by SustainabilityPracticeID
+func (s *sustainabilityService) checklist(ctx context.Context, farmID string) (map[string]*data.SustainabilityPractice, error) {
+	practices, err := s.practices.GetByFarmID(ctx, farmID, false)
+	if err != nil {
+		return nil, fmt.Errorf("getting sustainability practices: %w", err)
+	}
+	byID := make(map[string]*data.SustainabilityPractice, len(practices))
+	for _, practice := range practices {
+		byID[practice.SustainabilityPracticeID] = practice
+	}
+	return byID, nil
+}
+
+// toResponses converts answers to responses, checking that each practice is
+// on the farm's checklist
+func toResponses(in []ResponseInput, practices map[string]*data.SustainabilityPractice) ([]data.SustainabilityResponse, error) {
+	responses := make([]data.SustainabilityResponse, 0, len(in))
+	for i, answer := range in {
+		if _, ok := practices[answer.PracticeID]; !ok {
+			return nil, service.Invalid(fmt.Sprintf("responses[%d].practiceId is not a practice on this farm's checklist", i))
+		}
+		responses = append(responses, data.SustainabilityResponse{
+			SustainabilityPracticeID: answer.PracticeID,
+			Status:                   answer.Status,
+			Evidence:                 answer.Evidence,
+			EvidenceURL:              answer.EvidenceURL,
+			Notes:                    answer.Notes,
+		})
+	}
+	return responses, nil
+}
+
+// round2 rounds a percentage to two decimal places
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,272 @@
+// Package tag lets a farm label its crops, livestock and documents with its
+// own tags, such as "organic" or "2024-season", and filter their lists by
+// them
+package tag
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"strings"
+)
+
+// Record types tags can be put on
+const (
+	RecordCrop      = "crop"
+	RecordLivestock = "livestock"
+	RecordDocument  = "document"
+)
+
+// RecordTypes lists the record types tags can be put on
+var RecordTypes = []string{RecordCrop, RecordLivestock, RecordDocument}
+
+// Input holds the editable tag fields. On update, zero values are left
+// unchanged.
+type Input struct {
+	Name  string
+	Color string
+}
+
+// Service is the tag domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Tag, error)
+	// List returns a farm's tags by name
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.Tag, error)
+	Update(ctx context.Context, user *data.User, tagID string, in Input) (*data.Tag, error)
+	// Delete removes a tag from every record it is on and deletes it
+	Delete(ctx context.Context, user *data.User, tagID string) error
+	// Attach puts a tag on a record of the same farm
+	Attach(ctx context.Context, user *data.User, tagID, recordType, recordID string) (*data.Tagging, error)
+	// Detach takes a tag off a record
+	Detach(ctx context.Context, user *data.User, tagID, recordType, recordID string) error
+	// ForRecord returns the tags on a record
+	ForRecord(ctx context.Context, user *data.User, recordType, recordID string) ([]*data.Tag, error)
+}
+
+// tagService implements Service on top of the tag repository
+type tagService struct {
+	tags    data.TagInterface
+	records map[string]func(ctx context.Context, id string) (farmID string, err error)
+	farms   farm.Service
+}
+
+// New creates the tag service
+func New(tags data.TagInterface, crops data.CropInterface, livestock data.LivestockInterface,
+	documents data.DocumentInterface, farms farm.Service) Service {
+	return &tagService{
+		tags:  tags,
+		farms: farms,
+		records: map[string]func(context.Context, string) (string, error){
+			RecordCrop: func(ctx context.Context, id string) (string, error) {
+				c, err := crops.GetByCropID(ctx, id)
+				if c == nil || err != nil {
+					return "", err
+				}
+				return c.FarmID, nil
+			},
+			RecordLivestock: func(ctx context.Context, id string) (string, error) {
+				l, err := livestock.GetByLivestockID(ctx, id)
+				if l == nil || err != nil {
+					return "", err
+				}
+				return l.FarmID, nil
+			},
+			RecordDocument: func(ctx context.Context, id string) (string, error) {
+				d, err := documents.GetByDocumentID(ctx, id)
+				if d == nil || err != nil {
+					return "", err
+				}
+				return d.FarmID, nil
+			},
+		},
+	}
+}
+
+// Filter keeps the records tagged with every one of names, ignoring case,
+// using id to read a record's ID. With no names the records are returned as
+// they are. The caller has already checked access to the farm.
+func Filter[T any](ctx context.Context, tags data.TagInterface, farmID, recordType string, names []string, records []T, id func(T) string) ([]T, error) {
+	if len(names) == 0 {
+		return records, nil
+	}
+	ids, err := tags.RecordIDs(ctx, farmID, recordType, names)
+	if err != nil {
+		return nil, fmt.Errorf("getting tagged records: %w", err)
+	}
+	tagged := make(map[string]bool, len(ids))
+	for _, recordID := range ids {
+		tagged[recordID] = true
+	}
+	filtered := []T{}
+	for _, record := range records {
+		if tagged[id(record)] {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+// record returns the farm of a record on one of the user's farms
+func (s *tagService) record(ctx context.Context, user *data.User, recordType, recordID string) (string, error) {
+	lookup, ok := s.records[recordType]
+	if !ok {
+		return "", service.Invalid("recordType must be one of " + strings.Join(RecordTypes, ", "))
+	}
+	farmID, err := lookup(ctx, recordID)
+	if err != nil {
+		return "", fmt.Errorf("getting %s: %w", recordType, err)
+	}
+	if farmID == "" {
+		return "", service.NotFound(recordType + " not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, farmID, recordType); err != nil {
+		return "", err
+	}
+	return farmID, nil
+}
+
+// get returns a tag on one of the user's farms
+func (s *tagService) get(ctx context.Context, user *data.User, tagID string) (*data.Tag, error) {
+	tag, err := s.tags.GetByTagID(ctx, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tag: %w", err)
+	}
+	if tag == nil {
+		return nil, service.NotFound("tag not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, tag.FarmID, "tag"); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// unique checks no other tag on the farm has the name
+func (s *tagService) unique(ctx context.Context, farmID, name, tagID string) error {
+	existing, err := s.tags.GetByName(ctx, farmID, name)
+	if err != nil {
+		return fmt.Errorf("getting tag: %w", err)
+	}
+	if existing != nil && existing.TagID != tagID {
+		return service.Conflict(fmt.Sprintf("the farm already has a tag named %q", existing.Name))
+	}
+	return nil
+}
+
+// Create implements Service
+func (s *tagService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.Tag, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(in.Name)
+	if err := s.unique(ctx, farmID, name, ""); err != nil {
+		return nil, err
+	}
+
+	tag := &data.Tag{FarmID: farmID, Name: name, Color: in.Color}
+	if err := s.tags.Insert(ctx, tag); err != nil {
+		return nil, fmt.Errorf("creating tag: %w", err)
+	}
+	return tag, nil
+}
+
+// List implements Service
+func (s *tagService) List(ctx context.Context, user *data.User, farmID string) ([]*data.Tag, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	tags, err := s.tags.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tags: %w", err)
+	}
+	return tags, nil
+}
+
+// Update implements Service
+func (s *tagService) Update(ctx context.Context, user *data.User, tagID string, in Input) (*data.Tag, error) {
+	tag, err := s.get(ctx, user, tagID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(in.Name); name != "" {
+		if err := s.unique(ctx, tag.FarmID, name, tag.TagID); err != nil {
+			return nil, err
+		}
+		tag.Name = name
+	}
+	if in.Color != "" {
+		tag.Color = in.Color
+	}
+
+	if err := s.tags.Update(ctx, tag); err != nil {
+		return nil, fmt.Errorf("updating tag: %w", err)
+	}
+	return tag, nil
+}
+
+// Delete implements Service
+func (s *tagService) Delete(ctx context.Context, user *data.User, tagID string) error {
+	tag, err := s.get(ctx, user, tagID)
+	if err != nil {
+		return err
+	}
+	if err := s.tags.DeleteByID(ctx, int(tag.ID)); err != nil {
+		return fmt.Errorf("deleting tag: %w", err)
+	}
+	return nil
+}
+
+// Attach implements Service. Tagging a record that already has the tag
+// changes nothing.
+func (s *tagService) Attach(ctx context.Context, user *data.User, tagID, recordType, recordID string) (*data.Tagging, error) {
+	tag, err := s.get(ctx, user, tagID)
+	if err != nil {
+		return nil, err
+	}
+	farmID, err := s.record(ctx, user, recordType, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if farmID != tag.FarmID {
+		return nil, service.Invalid(fmt.Sprintf("the tag and the %s belong to different farms", recordType))
+	}
+
+	tagging := &data.Tagging{TagID: tag.TagID, FarmID: farmID, RecordType: recordType, RecordID: recordID}
+	if err := s.tags.Attach(ctx, tagging); err != nil {
+		return nil, fmt.Errorf("tagging %s: %w", recordType, err)
+	}
+	return tagging, nil
+}
+
+// Detach implements Service
+func (s *tagService) Detach(ctx context.Context, user *data.User, tagID, recordType, recordID string) error {
+	tag, err := s.get(ctx, user, tagID)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.records[recordType]; !ok {
+		return service.Invalid("recordType must be one of " + strings.Join(RecordTypes, ", "))
+	}
+	removed, err := s.tags.Detach(ctx, tag.TagID, recordType, recordID)
+	if err != nil {
+		return fmt.Errorf("untagging %s: %w", recordType, err)
+	}
+	if !removed {
+		return service.NotFound(fmt.Sprintf("the %s does not have the tag", recordType))
+	}
+	return nil
+}
+
+// ForRecord implements Service
+func (s *tagService) ForRecord(ctx context.Context, user *data.User, recordType, recordID string) ([]*data.Tag, error) {
+	if _, err := s.record(ctx, user, recordType, recordID); err != nil {
+		return nil, err
+	}
+	tags, err := s.tags.GetByRecord(ctx, recordType, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,227 @@
+// Package utility keeps a farm's electricity, diesel and water meter readings
+// and bills. A reading's consumption is worked out from the reading before
+// it.
+package utility
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+)
+
+// defaultUnits is the unit assumed for each utility when none is given
+var defaultUnits = map[string]string{
+	"Electricity": "kWh",
+	"Diesel":      "L",
+	"Water":       "m3",
+}
+
+// Input holds the editable utility record fields. On update, zero values are
+// left unchanged, and the utility and record type cannot be changed.
+type Input struct {
+	UtilityType  string
+	RecordType   string // Reading or Bill
+	Date         *time.Time
+	MeterReading *float64 // Required for a reading
+	Quantity     float64  // Worked out for a reading
+	Unit         string
+	Cost         float64
+	Provider     string
+	Notes        string
+}
+
+// Service is the utility domain service
+type Service interface {
+	// Create records a meter reading or bill on one of the user's farms,
+	// dated now and in the utility's usual unit unless given
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.UtilityRecord, error)
+	Get(ctx context.Context, user *data.User, utilityRecordID string) (*data.UtilityRecord, error)
+	// List returns a farm's utility records, optionally of one utility and
+	// dated in [from, to)
+	List(ctx context.Context, user *data.User, farmID, utilityType string, from, to *time.Time) ([]*data.UtilityRecord, error)
+	Update(ctx context.Context, user *data.User, utilityRecordID string, in Input) (*data.UtilityRecord, error)
+	// Delete soft deletes a utility record and its expense entry
+	Delete(ctx context.Context, user *data.User, utilityRecordID string) error
+	// Monthly totals a farm's consumption and cost per month and utility for
+	// a calendar year
+	Monthly(ctx context.Context, user *data.User, farmID string, year int) ([]data.MonthlyUtilityTotal, error)
+}
+
+// utilityService implements Service on top of the utility record repository
+type utilityService struct {
+	records data.UtilityRecordInterface
+	locks   lock.Checker
+	farms   farm.Service
+}
+
+// New creates the utility service
+func New(records data.UtilityRecordInterface, locks lock.Checker, farms farm.Service) Service {
+	return &utilityService{records: records, locks: locks, farms: farms}
+}
+
+// Create implements Service
+func (s *utilityService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.UtilityRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if err := s.locks.Check(ctx, farmID, date); err != nil {
+		return nil, err
+	}
+	if in.Unit == "" {
+		in.Unit = defaultUnits[in.UtilityType]
+	}
+
+	record := &data.UtilityRecord{
+		FarmID:       farmID,
+		UtilityType:  in.UtilityType,
+		RecordType:   in.RecordType,
+		Date:         date,
+		MeterReading: in.MeterReading,
+		Quantity:     in.Quantity,
+		Unit:         in.Unit,
+		Cost:         in.Cost,
+		Provider:     in.Provider,
+		Notes:        in.Notes,
+	}
+	if record.RecordType == "Reading" {
+		if err := s.applyReading(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.records.Insert(ctx, record); err != nil {
+		return nil, fmt.Errorf("creating utility record: %w", err)
+	}
+	return record, nil
+}
+
+// Get returns a utility record on one of the user's farms
+func (s *utilityService) Get(ctx context.Context, user *data.User, utilityRecordID string) (*data.UtilityRecord, error) {
+	record, err := s.records.GetByUtilityRecordID(ctx, utilityRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("getting utility record: %w", err)
+	}
+	if record == nil {
+		return nil, service.NotFound("utility record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, record.FarmID, "utility record"); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// List implements Service
+func (s *utilityService) List(ctx context.Context, user *data.User, farmID, utilityType string, from, to *time.Time) ([]*data.UtilityRecord, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	records, err := s.records.GetByFarmID(ctx, farmID, utilityType, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting utility records: %w", err)
+	}
+	return records, nil
+}
+
+// Update changes the non-zero fields of in on a utility record. Neither its
+// old nor its new date may be in a locked period.
+func (s *utilityService) Update(ctx context.Context, user *data.User, utilityRecordID string, in Input) (*data.UtilityRecord, error) {
+	record, err := s.Get(ctx, user, utilityRecordID)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := []time.Time{record.Date}
+	if in.Date != nil {
+		dates = append(dates, *in.Date)
+	}
+	if err := s.locks.Check(ctx, record.FarmID, dates...); err != nil {
+		return nil, err
+	}
+
+	if in.Date != nil {
+		record.Date = *in.Date
+	}
+	if in.MeterReading != nil {
+		record.MeterReading = in.MeterReading
+	}
+	if in.Quantity > 0 {
+		record.Quantity = in.Quantity
+	}
+	if in.Unit != "" {
+		record.Unit = in.Unit
+	}
+	if in.Cost > 0 {
+		record.Cost = in.Cost
+	}
+	if in.Provider != "" {
+		record.Provider = in.Provider
+	}
+	if in.Notes != "" {
+		record.Notes = in.Notes
+	}
+	if record.RecordType == "Reading" && (in.MeterReading != nil || in.Date != nil) {
+		if err := s.applyReading(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.records.Update(ctx, record); err != nil {
+		return nil, fmt.Errorf("updating utility record: %w", err)
+	}
+	return record, nil
+}
+
+// Delete implements Service
+func (s *utilityService) Delete(ctx context.Context, user *data.User, utilityRecordID string) error {
+	record, err := s.Get(ctx, user, utilityRecordID)
+	if err != nil {
+		return err
+	}
+	if err := s.locks.Check(ctx, record.FarmID, record.Date); err != nil {
+		return err
+	}
+	if err := s.records.DeleteByID(ctx, int(record.ID)); err != nil {
+		return fmt.Errorf("deleting utility record: %w", err)
+	}
+	return nil
+}
+
+// Monthly implements Service
+func (s *utilityService) Monthly(ctx context.Context, user *data.User, farmID string, year int) ([]data.MonthlyUtilityTotal, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := s.records.MonthlyTotals(ctx, farmID, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("getting monthly utility consumption: %w", err)
+	}
+	return totals, nil
+}
+
+// applyReading sets a reading's consumption from the previous reading of the
+// same utility. The first reading only establishes the baseline.
+func (s *utilityService) applyReading(ctx context.Context, record *data.UtilityRecord) error {
+	previous, err := s.records.GetLastReading(ctx, record.FarmID, record.UtilityType, record.Date)
+	if err != nil {
+		return fmt.Errorf("getting previous meter reading: %w", err)
+	}
+	if previous == nil || previous.MeterReading == nil {
+		return nil
+	}
+
+	if *record.MeterReading < *previous.MeterReading {
+		return service.Invalid(fmt.Sprintf("meter reading must not be less than the previous reading (%.2f)", *previous.MeterReading))
+	}
+	record.Quantity = *record.MeterReading - *previous.MeterReading
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package view keeps the named filter combinations users save for a farm's
+// lists, such as "Sick cattle", so the app can offer them as one-tap views.
+// A view holds the query parameters of the list it filters; the app sends
+// them back to that list's endpoint with the farm ID.
+package view
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Entities are the lists a view can filter, named as their endpoints are
+var Entities = []string{"crops", "livestock", "documents", "transactions", "employees", "equipment", "assets", "loans"}
+
+// MaxParams is the most query parameters a view can hold
+const MaxParams = 20
+
+// Input holds the editable view fields. On update, zero values are left
+// unchanged; a non-nil Params replaces the view's parameters.
+type Input struct {
+	Name   string
+	Entity string
+	Params map[string]string
+}
+
+// Service is the saved view domain service
+type Service interface {
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.SavedView, error)
+	Get(ctx context.Context, user *data.User, viewID string) (*data.SavedView, error)
+	// List returns the views the user saved on a farm by name, optionally
+	// for one entity
+	List(ctx context.Context, user *data.User, farmID, entity string) ([]*data.SavedView, error)
+	Update(ctx context.Context, user *data.User, viewID string, in Input) (*data.SavedView, error)
+	Delete(ctx context.Context, user *data.User, viewID string) error
+}
+
+// viewService implements Service on top of the saved view repository
+type viewService struct {
+	views data.SavedViewInterface
+	farms farm.Service
+}
+
+// New creates the saved view service
+func New(views data.SavedViewInterface, farms farm.Service) Service {
+	return &viewService{views: views, farms: farms}
+}
+
+// check validates a view's entity and parameters and that no other view of
+// the user on the farm has its name
+func (s *viewService) check(ctx context.Context, view *data.SavedView) error {
+	if !slices.Contains(Entities, view.Entity) {
+		return service.Invalid("entity must be one of " + strings.Join(Entities, ", "))
+	}
+	if len(view.Params) > MaxParams {
+		return service.Invalid(fmt.Sprintf("a view can hold at most %d parameters", MaxParams))
+	}
+	for key := range view.Params {
+		if key == "" || key == "farmId" {
+			return service.Invalid(fmt.Sprintf("%q cannot be saved as a view parameter", key))
+		}
+	}
+
+	existing, err := s.views.GetByName(ctx, view.FarmID, view.UserID, view.Name)
+	if err != nil {
+		return fmt.Errorf("getting view: %w", err)
+	}
+	if existing != nil && existing.SavedViewID != view.SavedViewID {
+		return service.Conflict(fmt.Sprintf("you already have a view named %q on this farm", existing.Name))
+	}
+	return nil
+}
+
+// Create implements Service
+func (s *viewService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.SavedView, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	view := &data.SavedView{
+		FarmID: farmID,
+		UserID: user.UserID,
+		Name:   strings.TrimSpace(in.Name),
+		Entity: in.Entity,
+		Params: in.Params,
+	}
+	if view.Params == nil {
+		view.Params = map[string]string{}
+	}
+	if err := s.check(ctx, view); err != nil {
+		return nil, err
+	}
+	if err := s.views.Insert(ctx, view); err != nil {
+		return nil, fmt.Errorf("creating view: %w", err)
+	}
+	return view, nil
+}
+
+// Get implements Service. Views are private to the user who saved them.
+func (s *viewService) Get(ctx context.Context, user *data.User, viewID string) (*data.SavedView, error) {
+	view, err := s.views.GetBySavedViewID(ctx, viewID)
+	if err != nil {
+		return nil, fmt.Errorf("getting view: %w", err)
+	}
+	if view == nil || view.UserID != user.UserID {
+		return nil, service.NotFound("view not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, view.FarmID, "view"); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// List implements Service
+func (s *viewService) List(ctx context.Context, user *data.User, farmID, entity string) ([]*data.SavedView, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	views, err := s.views.GetByFarmID(ctx, farmID, user.UserID, entity)
+	if err != nil {
+		return nil, fmt.Errorf("getting views: %w", err)
+	}
+	return views, nil
+}
+
+// Update implements Service
+func (s *viewService) Update(ctx context.Context, user *data.User, viewID string, in Input) (*data.SavedView, error) {
+	view, err := s.Get(ctx, user, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := strings.TrimSpace(in.Name); name != "" {
+		view.Name = name
+	}
+	if in.Entity != "" {
+		view.Entity = in.Entity
+	}
+	if in.Params != nil {
+		view.Params = in.Params
+	}
+	if err := s.check(ctx, view); err != nil {
+		return nil, err
+	}
+
+	if err := s.views.Update(ctx, view); err != nil {
+		return nil, fmt.Errorf("updating view: %w", err)
+	}
+	return view, nil
+}
+
+// Delete implements Service
+func (s *viewService) Delete(ctx context.Context, user *data.User, viewID string) error {
+	view, err := s.Get(ctx, user, viewID)
+	if err != nil {
+		return err
+	}
+	if err := s.views.DeleteByID(ctx, int(view.ID)); err != nil {
+		return fmt.Errorf("deleting view: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+// Package water keeps a farm's water sources with their abstraction permits
+// and the log of water drawn from them, and raises alerts as usage nears a
+// permit's limits or the permit nears its expiry
+package water
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// permitExpiryWarning is how far ahead of a permit's expiry an alert is raised
+const permitExpiryWarning = 30 * 24 * time.Hour
+
+// Input holds the editable water source fields. On update, zero values are
+// left unchanged.
+type Input struct {
+	Name           string
+	SourceType     string
+	PermitNumber   string
+	PermitExpiry   *time.Time
+	DailyLimit     float64
+	AnnualLimit    float64
+	AlertThreshold float64 // Percent of a limit at which an alert is raised
+	Status         string
+	Notes          string
+}
+
+// UsageInput holds the fields of water drawn from a source
+type UsageInput struct {
+	Date    *time.Time // Defaults to now
+	Volume  float64
+	Purpose string
+	Notes   string
+}
+
+// Alert describes a permit condition that needs the farmer's attention
+type Alert struct {
+	WaterSourceID string  `json:"waterSourceId"`
+	Name          string  `json:"name"`
+	PermitNumber  string  `json:"permitNumber"`
+	Type          string  `json:"type"` // daily_limit, annual_limit, permit_expiry
+	Message       string  `json:"message"`
+	Used          float64 `json:"used,omitempty"`
+	Limit         float64 `json:"limit,omitempty"`
+	UsedPercent   float64 `json:"usedPercent,omitempty"`
+}
+
+// Service is the water domain service
+type Service interface {
+	// Create adds a water source to one of the user's farms, defaulting to an
+	// active source alerting at 80% of its limits
+	Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.WaterSource, error)
+	Get(ctx context.Context, user *data.User, waterSourceID string) (*data.WaterSource, error)
+	List(ctx context.Context, user *data.User, farmID string) ([]*data.WaterSource, error)
+	Update(ctx context.Context, user *data.User, waterSourceID string, in Input) (*data.WaterSource, error)
+	Delete(ctx context.Context, user *data.User, waterSourceID string) error
+	ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.WaterSource, error)
+	Restore(ctx context.Context, user *data.User, waterSourceID string) (*data.WaterSource, error)
+
+	// LogUsage records water drawn from a source and returns the alerts the
+	// source raises with it. The date may not be in a locked period.
+	LogUsage(ctx context.Context, user *data.User, waterSourceID string, in UsageInput) (*data.WaterUsage, []Alert, error)
+	// Usage returns a source with the water drawn from it in [from, to)
+	Usage(ctx context.Context, user *data.User, waterSourceID string, from, to *time.Time) (*data.WaterSource, []*data.WaterUsage, error)
+	// Alerts lists the alerts raised by all of a farm's water sources
+	Alerts(ctx context.Context, user *data.User, farmID string) ([]Alert, error)
+}
+
+// waterService implements Service on top of the water repositories
+type waterService struct {
+	sources data.WaterSourceInterface
+	usages  data.WaterUsageInterface
+	locks   lock.Checker
+	farms   farm.Service
+}
+
+// New creates the water service
+func New(sources data.WaterSourceInterface, usages data.WaterUsageInterface, locks lock.Checker, farms farm.Service) Service {
+	return &waterService{sources: sources, usages: usages, locks: locks, farms: farms}
+}
+
+// Create implements Service
+func (s *waterService) Create(ctx context.Context, user *data.User, farmID string, in Input) (*data.WaterSource, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	if in.AlertThreshold == 0 {
+		in.AlertThreshold = 80
+	}
+	if in.Status == "" {
+		in.Status = "Active"
+	}
+
+	source := &data.WaterSource{
+		FarmID:         farmID,
+		Name:           in.Name,
+		SourceType:     in.SourceType,
+		PermitNumber:   in.PermitNumber,
+		PermitExpiry:   in.PermitExpiry,
+		DailyLimit:     in.DailyLimit,
+		AnnualLimit:    in.AnnualLimit,
+		AlertThreshold: in.AlertThreshold,
+		Status:         in.Status,
+		Notes:          in.Notes,
+	}
+	if err := s.sources.Insert(ctx, source); err != nil {
+		return nil, fmt.Errorf("creating water source: %w", err)
+	}
+	return source, nil
+}
+
+// Get returns a water source on one of the user's farms
+func (s *waterService) Get(ctx context.Context, user *data.User, waterSourceID string) (*data.WaterSource, error) {
+	source, err := s.sources.GetByWaterSourceID(ctx, waterSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("getting water source: %w", err)
+	}
+	if source == nil {
+		return nil, service.NotFound("water source not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, source.FarmID, "water source"); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// List returns the water sources of one of the user's farms
+func (s *waterService) List(ctx context.Context, user *data.User, farmID string) ([]*data.WaterSource, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	sources, err := s.sources.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting water sources: %w", err)
+	}
+	return sources, nil
+}
+
+// Update changes the non-zero fields of in on a water source
+func (s *waterService) Update(ctx context.Context, user *data.User, waterSourceID string, in Input) (*data.WaterSource, error) {
+	source, err := s.Get(ctx, user, waterSourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Name != "" {
+		source.Name = in.Name
+	}
+	if in.SourceType != "" {
+		source.SourceType = in.SourceType
+	}
+	if in.PermitNumber != "" {
+		source.PermitNumber = in.PermitNumber
+	}
+	if in.PermitExpiry != nil {
+		source.PermitExpiry = in.PermitExpiry
+	}
+	if in.DailyLimit > 0 {
+		source.DailyLimit = in.DailyLimit
+	}
+	if in.AnnualLimit > 0 {
+		source.AnnualLimit = in.AnnualLimit
+	}
+	if in.AlertThreshold > 0 {
+		source.AlertThreshold = in.AlertThreshold
+	}
+	if in.Status != "" {
+		source.Status = in.Status
+	}
+	if in.Notes != "" {
+		source.Notes = in.Notes
+	}
+
+	if err := s.sources.Update(ctx, source); err != nil {
+		return nil, fmt.Errorf("updating water source: %w", err)
+	}
+	return source, nil
+}
+
+// Delete soft deletes a water source
+func (s *waterService) Delete(ctx context.Context, user *data.User, waterSourceID string) error {
+	source, err := s.Get(ctx, user, waterSourceID)
+	if err != nil {
+		return err
+	}
+	if err := s.sources.DeleteByID(ctx, int(source.ID)); err != nil {
+		return fmt.Errorf("deleting water source: %w", err)
+	}
+	return nil
+}
+
+// ListDeleted returns the soft-deleted water sources of one of the user's
+// farms
+func (s *waterService) ListDeleted(ctx context.Context, user *data.User, farmID string) ([]*data.WaterSource, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	sources, err := s.sources.GetDeletedByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted water sources: %w", err)
+	}
+	return sources, nil
+}
+
+// Restore undeletes a water source. Its farm must still exist and belong to
+// the user.
+func (s *waterService) Restore(ctx context.Context, user *data.User, waterSourceID string) (*data.WaterSource, error) {
+	source, err := s.sources.GetDeletedByWaterSourceID(ctx, waterSourceID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted water source: %w", err)
+	}
+	if source == nil {
+		return nil, service.NotFound("deleted water source not found")
+	}
+	if _, err := s.farms.Owned(ctx, user, source.FarmID); err != nil {
+		return nil, err
+	}
+
+	if err := s.sources.RestoreByID(ctx, int(source.ID)); err != nil {
+		return nil, fmt.Errorf("restoring water source: %w", err)
+	}
+	source.DeletedAt = gorm.DeletedAt{}
+	return source, nil
+}
+
+// LogUsage implements Service
+func (s *waterService) LogUsage(ctx context.Context, user *data.User, waterSourceID string, in UsageInput) (*data.WaterUsage, []Alert, error) {
+	source, err := s.Get(ctx, user, waterSourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	date := time.Now()
+	if in.Date != nil {
+		date = *in.Date
+	}
+	if err := s.locks.Check(ctx, source.FarmID, date); err != nil {
+		return nil, nil, err
+	}
+
+	usage := &data.WaterUsage{
+		WaterSourceID: source.WaterSourceID,
+		FarmID:        source.FarmID,
+		Date:          date,
+		Volume:        in.Volume,
+		Purpose:       in.Purpose,
+		Notes:         in.Notes,
+	}
+	if err := s.usages.Insert(ctx, usage); err != nil {
+		return nil, nil, fmt.Errorf("logging water usage: %w", err)
+	}
+
+	alerts, err := s.alerts(ctx, source, date)
+	if err != nil {
+		return nil, nil, err
+	}
+	return usage, alerts, nil
+}
+
+// Usage implements Service
+func (s *waterService) Usage(ctx context.Context, user *data.User, waterSourceID string, from, to *time.Time) (*data.WaterSource, []*data.WaterUsage, error) {
+	source, err := s.Get(ctx, user, waterSourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	usages, err := s.usages.GetByWaterSourceID(ctx, source.WaterSourceID, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting water usage: %w", err)
+	}
+	return source, usages, nil
+}
+
+// Alerts implements Service
+func (s *waterService) Alerts(ctx context.Context, user *data.User, farmID string) ([]Alert, error) {
+	sources, err := s.List(ctx, user, farmID)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := []Alert{}
+	now := time.Now()
+	for _, source := range sources {
+		sourceAlerts, err := s.alerts(ctx, source, now)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, sourceAlerts...)
+	}
+	return alerts, nil
+}
+
+// alerts checks a source's usage on the day and year containing "at" against
+// its permitted limits and permit expiry
+func (s *waterService) alerts(ctx context.Context, source *data.WaterSource, at time.Time) ([]Alert, error) {
+	var alerts []Alert
+
+	dayStart := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	yearStart := time.Date(at.Year(), time.January, 1, 0, 0, 0, 0, at.Location())
+
+	limits := []struct {
+		kind  string
+		label string
+		limit float64
+		from  time.Time
+		to    time.Time
+	}{
+		{"daily_limit", "daily", source.DailyLimit, dayStart, dayStart.AddDate(0, 0, 1)},
+		{"annual_limit", "annual", source.AnnualLimit, yearStart, yearStart.AddDate(1, 0, 0)},
+	}
+
+	for _, l := range limits {
+		if l.limit <= 0 {
+			continue
+		}
+
+		used, err := s.usages.TotalVolume(ctx, source.WaterSourceID, l.from, l.to)
+		if err != nil {
+			return nil, fmt.Errorf("totalling water usage: %w", err)
+		}
+
+		percent := used / l.limit * 100
+		if percent < source.AlertThreshold {
+			continue
+		}
+
+		message := fmt.Sprintf("%s has used %.0f%% of its %s abstraction limit", source.Name, percent, l.label)
+		if used > l.limit {
+			message = fmt.Sprintf("%s has exceeded its %s abstraction limit", source.Name, l.label)
+		}
+
+		alerts = append(alerts, Alert{
+			WaterSourceID: source.WaterSourceID,
+			Name:          source.Name,
+			PermitNumber:  source.PermitNumber,
+			Type:          l.kind,
+			Message:       message,
+			Used:          used,
+			Limit:         l.limit,
+			UsedPercent:   percent,
+		})
+	}
+
+	if source.PermitExpiry != nil && source.PermitExpiry.Before(time.Now().Add(permitExpiryWarning)) {
+		message := fmt.Sprintf("Permit for %s expires on %s", source.Name, source.PermitExpiry.Format("2006-01-02"))
+		if source.PermitExpiry.Before(time.Now()) {
+			message = fmt.Sprintf("Permit for %s expired on %s", source.Name, source.PermitExpiry.Format("2006-01-02"))
+		}
+
+		alerts = append(alerts, Alert{
+			WaterSourceID: source.WaterSourceID,
+			Name:          source.Name,
+			PermitNumber:  source.PermitNumber,
+			Type:          "permit_expiry",
+			Message:       message,
+		})
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,236 @@
+package workforce
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Clock actions accepted by Clock
+const (
+	ClockIn  = "clock-in"
+	ClockOut = "clock-out"
+)
+
+// defaultAttendanceDays is how far back attendance reports look when no start
+// date is given
+const defaultAttendanceDays = 28
+
+// WorkingDays are the weekdays on which an active employee without a
+// clock-in is reported absent
+var WorkingDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// ClockInput is a clock-in or clock-out. A nil Time means now.
+type ClockInput struct {
+	Action string
+	Time   *time.Time
+	Notes  string
+}
+
+// Absentee is one employee's attendance over the working days of a period
+type Absentee struct {
+	EmployeeID  string   `json:"employeeId"`
+	Name        string   `json:"name"`
+	WorkingDays int      `json:"workingDays"`
+	DaysPresent int      `json:"daysPresent"`
+	DaysAbsent  int      `json:"daysAbsent"`
+	AbsentDates []string `json:"absentDates"` // YYYY-MM-DD
+}
+
+// Clock opens an attendance record on clock-in and closes the open one on
+// clock-out, working out the hours in between. Neither may fall in a locked
+// period.
+func (s *workforceService) Clock(ctx context.Context, user *data.User, employeeID string, in ClockInput) (*data.Attendance, error) {
+	employee, err := s.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	at := time.Now()
+	if in.Time != nil {
+		at = *in.Time
+	}
+
+	open, err := s.attendance.GetOpen(ctx, employee.EmployeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting open attendance: %w", err)
+	}
+
+	switch in.Action {
+	case ClockIn:
+		if employee.Status != "Active" {
+			return nil, service.Conflict("only active employees can clock in")
+		}
+		if open != nil {
+			return nil, service.Conflict("employee is already clocked in")
+		}
+		if err := s.locks.Check(ctx, employee.FarmID, at); err != nil {
+			return nil, err
+		}
+		attendance := &data.Attendance{
+			EmployeeID: employee.EmployeeID,
+			FarmID:     employee.FarmID,
+			ClockIn:    at,
+			Notes:      in.Notes,
+		}
+		if err := s.attendance.Insert(ctx, attendance); err != nil {
+			return nil, fmt.Errorf("clocking in: %w", err)
+		}
+		return attendance, nil
+
+	case ClockOut:
+		if open == nil {
+			return nil, service.Conflict("employee is not clocked in")
+		}
+		if !at.After(open.ClockIn) {
+			return nil, service.Invalid("clock-out must be after clock-in")
+		}
+		if err := s.locks.Check(ctx, open.FarmID, open.ClockIn, at); err != nil {
+			return nil, err
+		}
+		open.ClockOut = &at
+		open.Hours = at.Sub(open.ClockIn).Hours()
+		if in.Notes != "" {
+			open.Notes = in.Notes
+		}
+		if err := s.attendance.Update(ctx, open); err != nil {
+			return nil, fmt.Errorf("clocking out: %w", err)
+		}
+		return open, nil
+	}
+
+	return nil, service.Invalid("action must be clock-in or clock-out")
+}
+
+// ListAttendance returns an employee's attendance, optionally limited to
+// clock-ins in [from, to)
+func (s *workforceService) ListAttendance(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.Attendance, error) {
+	employee, err := s.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.attendance.GetByEmployeeID(ctx, employee.EmployeeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting attendance: %w", err)
+	}
+	return records, nil
+}
+
+// DeleteAttendance soft deletes an attendance record entered by mistake,
+// unless it is in a locked period
+func (s *workforceService) DeleteAttendance(ctx context.Context, user *data.User, attendanceID string) error {
+	attendance, err := s.attendance.GetByAttendanceID(ctx, attendanceID)
+	if err != nil {
+		return fmt.Errorf("getting attendance: %w", err)
+	}
+	if attendance == nil {
+		return service.NotFound("attendance record not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, attendance.FarmID, "attendance record"); err != nil {
+		return err
+	}
+	dates := []time.Time{attendance.ClockIn}
+	if attendance.ClockOut != nil {
+		dates = append(dates, *attendance.ClockOut)
+	}
+	if err := s.locks.Check(ctx, attendance.FarmID, dates...); err != nil {
+		return err
+	}
+	if err := s.attendance.DeleteByID(ctx, int(attendance.ID)); err != nil {
+		return fmt.Errorf("deleting attendance: %w", err)
+	}
+	return nil
+}
+
+// WeeklyHours totals the hours worked per employee and week on one of the
+// user's farms for clock-ins in [from, to). An empty employeeID covers every
+// employee; nil dates default to the last four weeks.
+func (s *workforceService) WeeklyHours(ctx context.Context, user *data.User, farmID, employeeID string, from, to *time.Time) ([]data.WeeklyHours, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	start, end := attendancePeriod(from, to)
+	totals, err := s.attendance.WeeklyHours(ctx, farmID, employeeID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("getting weekly hours: %w", err)
+	}
+	return totals, nil
+}
+
+// AbsenteeReport lists, for each active employee on one of the user's farms,
+// the working days in [from, to) on which they did not clock in. Days before
+// an employee's hire date and days after today are not counted; nil dates
+// default to the last four weeks.
+func (s *workforceService) AbsenteeReport(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]Absentee, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	start, end := attendancePeriod(from, to)
+	if today := service.Day(time.Now()).AddDate(0, 0, 1); end.After(today) {
+		end = today
+	}
+
+	employees, err := s.employees.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employees: %w", err)
+	}
+	records, err := s.attendance.GetByFarmID(ctx, farmID, &start, &end)
+	if err != nil {
+		return nil, fmt.Errorf("getting attendance: %w", err)
+	}
+
+	present := map[string]bool{}
+	for _, record := range records {
+		present[record.EmployeeID+"|"+record.ClockIn.UTC().Format("2006-01-02")] = true
+	}
+
+	report := []Absentee{}
+	for _, employee := range employees {
+		if employee.Status != "Active" {
+			continue
+		}
+		absentee := Absentee{
+			EmployeeID:  employee.EmployeeID,
+			Name:        employee.FirstName + " " + employee.LastName,
+			AbsentDates: []string{},
+		}
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			if !slices.Contains(WorkingDays, day.Weekday()) {
+				continue
+			}
+			if employee.HireDate != nil && day.Before(service.Day(*employee.HireDate)) {
+				continue
+			}
+			absentee.WorkingDays++
+			date := day.Format("2006-01-02")
+			if present[employee.EmployeeID+"|"+date] {
+				absentee.DaysPresent++
+			} else {
+				absentee.DaysAbsent++
+				absentee.AbsentDates = append(absentee.AbsentDates, date)
+			}
+		}
+		report = append(report, absentee)
+	}
+
+	return report, nil
+}
+
+// attendancePeriod resolves optional report dates to whole UTC days,
+// defaulting to the last four weeks up to and including today
+func attendancePeriod(from, to *time.Time) (time.Time, time.Time) {
+	end := service.Day(time.Now()).AddDate(0, 0, 1)
+	if to != nil {
+		end = service.Day(*to)
+	}
+	start := end.AddDate(0, 0, -defaultAttendanceDays)
+	if from != nil {
+		start = service.Day(*from)
+	}
+	return start, end
+}
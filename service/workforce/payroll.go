@@ -0,0 +1,131 @@
+package workforce
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"time"
+)
+
+// PaymentInput holds the fields of a payroll payment. A nil PaymentDate means
+// today; net pay is worked out from gross pay and deductions.
+type PaymentInput struct {
+	PeriodStart      *time.Time
+	PeriodEnd        *time.Time
+	GrossPay         float64
+	Deductions       float64
+	PaymentDate      *time.Time
+	PaymentMethod    string
+	PaymentReference string
+	Notes            string
+}
+
+// RecordPayment records a payroll payment to an employee and books the gross
+// pay as a Labour expense
+func (s *workforceService) RecordPayment(ctx context.Context, user *data.User, employeeID string, in PaymentInput) (*data.PayrollPayment, error) {
+	employee, err := s.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.PeriodStart == nil || in.PeriodEnd == nil {
+		return nil, service.Invalid("pay period start and end are required")
+	}
+	if in.PeriodEnd.Before(*in.PeriodStart) {
+		return nil, service.Invalid("pay period end cannot be before its start")
+	}
+	if in.Deductions > in.GrossPay {
+		return nil, service.Invalid("deductions cannot exceed gross pay")
+	}
+
+	paymentDate := time.Now()
+	if in.PaymentDate != nil {
+		paymentDate = *in.PaymentDate
+	}
+	if err := s.locks.Check(ctx, employee.FarmID, paymentDate); err != nil {
+		return nil, err
+	}
+
+	payment := &data.PayrollPayment{
+		EmployeeID:       employee.EmployeeID,
+		FarmID:           employee.FarmID,
+		PeriodStart:      *in.PeriodStart,
+		PeriodEnd:        *in.PeriodEnd,
+		GrossPay:         in.GrossPay,
+		Deductions:       in.Deductions,
+		NetPay:           in.GrossPay - in.Deductions,
+		PaymentDate:      paymentDate,
+		PaymentMethod:    in.PaymentMethod,
+		PaymentReference: in.PaymentReference,
+		Notes:            in.Notes,
+		Employee:         employee,
+	}
+
+	if err := s.payments.Insert(ctx, payment); err != nil {
+		return nil, fmt.Errorf("recording payment: %w", err)
+	}
+	return payment, nil
+}
+
+// ListEmployeePayments returns an employee's payments, optionally limited to
+// payment dates in [from, to)
+func (s *workforceService) ListEmployeePayments(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.PayrollPayment, error) {
+	employee, err := s.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.payments.GetByEmployeeID(ctx, employee.EmployeeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting payments: %w", err)
+	}
+	return payments, nil
+}
+
+// ListPayments returns the payments made on a farm whose payroll the user
+// may read, optionally limited to payment dates in [from, to)
+func (s *workforceService) ListPayments(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.PayrollPayment, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModulePayroll, farm.Read); err != nil {
+		return nil, err
+	}
+	payments, err := s.payments.GetByFarmID(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting payments: %w", err)
+	}
+	return payments, nil
+}
+
+// DeletePayment soft deletes a payroll payment and its expense
+func (s *workforceService) DeletePayment(ctx context.Context, user *data.User, paymentID string) error {
+	payment, err := s.payments.GetByPayrollPaymentID(ctx, paymentID)
+	if err != nil {
+		return fmt.Errorf("getting payment: %w", err)
+	}
+	if payment == nil {
+		return service.NotFound("payment not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, payment.FarmID, "payment"); err != nil {
+		return err
+	}
+	if err := s.locks.Check(ctx, payment.FarmID, payment.PaymentDate); err != nil {
+		return err
+	}
+	if err := s.payments.DeleteByID(ctx, int(payment.ID)); err != nil {
+		return fmt.Errorf("deleting payment: %w", err)
+	}
+	return nil
+}
+
+// PayrollSummary totals one of the user's farms' payroll per month of the year
+func (s *workforceService) PayrollSummary(ctx context.Context, user *data.User, farmID string, year int) ([]data.MonthlyPayrollTotal, error) {
+	if _, err := s.farms.Authorize(ctx, user, farmID, farm.ModulePayroll, farm.Read); err != nil {
+		return nil, err
+	}
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	totals, err := s.payments.MonthlyTotals(ctx, farmID, from, from.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("getting payroll summary: %w", err)
+	}
+	return totals, nil
+}
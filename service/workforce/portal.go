@@ -0,0 +1,63 @@
+package workforce
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"fmt"
+	"time"
+)
+
+// Employments returns the employee records linked to the user's account,
+// with their farms. This is the employee's own view and needs no farm
+// ownership.
+func (s *workforceService) Employments(ctx context.Context, user *data.User) ([]*data.Employee, error) {
+	employees, err := s.employees.GetByUserID(ctx, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employments: %w", err)
+	}
+	return employees, nil
+}
+
+// OwnAttendance returns the attendance of one of the user's own employments,
+// optionally limited to clock-ins in [from, to)
+func (s *workforceService) OwnAttendance(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.Attendance, error) {
+	employee, err := s.ownEmployment(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.attendance.GetByEmployeeID(ctx, employee.EmployeeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting attendance: %w", err)
+	}
+	return records, nil
+}
+
+// OwnPayments returns the payslips of one of the user's own employments,
+// optionally limited to payment dates in [from, to)
+func (s *workforceService) OwnPayments(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.PayrollPayment, error) {
+	employee, err := s.ownEmployment(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.payments.GetByEmployeeID(ctx, employee.EmployeeID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting payments: %w", err)
+	}
+	return payments, nil
+}
+
+// ownEmployment loads an employee record that is linked to the user
+func (s *workforceService) ownEmployment(ctx context.Context, user *data.User, employeeID string) (*data.Employee, error) {
+	employee, err := s.employees.GetByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employee: %w", err)
+	}
+	if employee == nil {
+		return nil, service.NotFound("employment not found")
+	}
+	if employee.UserID == nil || *employee.UserID != user.UserID {
+		return nil, service.Forbidden("access denied: employment is not linked to this account")
+	}
+	return employee, nil
+}
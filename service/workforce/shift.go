@@ -0,0 +1,249 @@
+package workforce
+
+import (
+	"context"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxShiftHours is the longest a single shift can run
+const MaxShiftHours = 16
+
+// ShiftInput holds the editable shift fields. On update, zero values are
+// left unchanged; an empty FieldID string takes the shift off its field.
+type ShiftInput struct {
+	EmployeeID string
+	FieldID    *string
+	StartsAt   *time.Time
+	EndsAt     *time.Time
+	Role       string
+	Location   string
+	Notes      string
+}
+
+// Roster is a farm's shifts over one week, Monday to Sunday, by day and by
+// employee
+type Roster struct {
+	FarmID    string           `json:"farmId"`
+	WeekStart string           `json:"weekStart"` // Monday, YYYY-MM-DD
+	WeekEnd   string           `json:"weekEnd"`   // Sunday, YYYY-MM-DD
+	Days      []RosterDay      `json:"days"`
+	Employees []RosterEmployee `json:"employees"`
+}
+
+// RosterDay is the shifts starting on one day of a roster
+type RosterDay struct {
+	Date   string        `json:"date"` // YYYY-MM-DD
+	Shifts []*data.Shift `json:"shifts"`
+}
+
+// RosterEmployee is one employee's planned work over a roster's week
+type RosterEmployee struct {
+	EmployeeID string  `json:"employeeId"`
+	Name       string  `json:"name"`
+	Shifts     int     `json:"shifts"`
+	Hours      float64 `json:"hours"`
+}
+
+// CreateShift plans a shift for an employee of one of the user's farms
+func (s *workforceService) CreateShift(ctx context.Context, user *data.User, farmID string, in ShiftInput) (*data.Shift, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	shift := &data.Shift{
+		FarmID:     farmID,
+		EmployeeID: in.EmployeeID,
+		Role:       strings.TrimSpace(in.Role),
+		Location:   in.Location,
+		Notes:      in.Notes,
+	}
+	if in.StartsAt != nil {
+		shift.StartsAt = *in.StartsAt
+	}
+	if in.EndsAt != nil {
+		shift.EndsAt = *in.EndsAt
+	}
+	if err := s.checkShift(ctx, shift, in.FieldID); err != nil {
+		return nil, err
+	}
+
+	if err := s.shifts.Insert(ctx, shift); err != nil {
+		return nil, fmt.Errorf("creating shift: %w", err)
+	}
+	return shift, nil
+}
+
+// ListShifts returns the shifts of one of the user's farms starting in
+// [from, to)
+func (s *workforceService) ListShifts(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.Shift, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	shifts, err := s.shifts.GetByFarmID(ctx, farmID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting shifts: %w", err)
+	}
+	return shifts, nil
+}
+
+// Roster lays out the shifts of one of the user's farms over the week, in
+// UTC, that contains day
+func (s *workforceService) Roster(ctx context.Context, user *data.User, farmID string, day time.Time) (*Roster, error) {
+	start := service.Day(day)
+	start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	end := start.AddDate(0, 0, 7)
+
+	shifts, err := s.ListShifts(ctx, user, farmID, &start, &end)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := &Roster{
+		FarmID:    farmID,
+		WeekStart: start.Format("2006-01-02"),
+		WeekEnd:   end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days:      make([]RosterDay, 7),
+		Employees: []RosterEmployee{},
+	}
+	for i := range roster.Days {
+		roster.Days[i] = RosterDay{Date: start.AddDate(0, 0, i).Format("2006-01-02"), Shifts: []*data.Shift{}}
+	}
+
+	byEmployee := map[string]int{}
+	for _, shift := range shifts {
+		i := int(service.Day(shift.StartsAt).Sub(start).Hours() / 24)
+		roster.Days[i].Shifts = append(roster.Days[i].Shifts, shift)
+
+		j, ok := byEmployee[shift.EmployeeID]
+		if !ok {
+			j = len(roster.Employees)
+			byEmployee[shift.EmployeeID] = j
+			employee := RosterEmployee{EmployeeID: shift.EmployeeID}
+			if shift.Employee != nil {
+				employee.Name = shift.Employee.FirstName + " " + shift.Employee.LastName
+			}
+			roster.Employees = append(roster.Employees, employee)
+		}
+		roster.Employees[j].Shifts++
+		roster.Employees[j].Hours += shift.Hours()
+	}
+
+	return roster, nil
+}
+
+// UpdateShift changes a shift's times, employee or duties
+func (s *workforceService) UpdateShift(ctx context.Context, user *data.User, shiftID string, in ShiftInput) (*data.Shift, error) {
+	shift, err := s.getShift(ctx, user, shiftID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.EmployeeID != "" {
+		shift.EmployeeID = in.EmployeeID
+	}
+	if in.StartsAt != nil {
+		shift.StartsAt = *in.StartsAt
+	}
+	if in.EndsAt != nil {
+		shift.EndsAt = *in.EndsAt
+	}
+	if role := strings.TrimSpace(in.Role); role != "" {
+		shift.Role = role
+	}
+	if in.Location != "" {
+		shift.Location = in.Location
+	}
+	if in.Notes != "" {
+		shift.Notes = in.Notes
+	}
+	shift.Employee, shift.Field = nil, nil
+	if err := s.checkShift(ctx, shift, in.FieldID); err != nil {
+		return nil, err
+	}
+
+	if err := s.shifts.Update(ctx, shift); err != nil {
+		return nil, fmt.Errorf("updating shift: %w", err)
+	}
+	return shift, nil
+}
+
+// DeleteShift soft deletes a shift taken off the roster
+func (s *workforceService) DeleteShift(ctx context.Context, user *data.User, shiftID string) error {
+	shift, err := s.getShift(ctx, user, shiftID)
+	if err != nil {
+		return err
+	}
+	if err := s.shifts.DeleteByID(ctx, int(shift.ID)); err != nil {
+		return fmt.Errorf("deleting shift: %w", err)
+	}
+	return nil
+}
+
+// getShift returns a shift on one of the user's farms
+func (s *workforceService) getShift(ctx context.Context, user *data.User, shiftID string) (*data.Shift, error) {
+	shift, err := s.shifts.GetByShiftID(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("getting shift: %w", err)
+	}
+	if shift == nil {
+		return nil, service.NotFound("shift not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, shift.FarmID, "shift"); err != nil {
+		return nil, err
+	}
+	return shift, nil
+}
+
+// checkShift validates a shift's times, places it on fieldID (nil leaves the
+// field unchanged and "" clears it) and makes sure its employee is an active
+// employee of the farm with no other shift at the same time
+func (s *workforceService) checkShift(ctx context.Context, shift *data.Shift, fieldID *string) error {
+	if !shift.EndsAt.After(shift.StartsAt) {
+		return service.Invalid("endsAt must be after startsAt")
+	}
+	if shift.Hours() > MaxShiftHours {
+		return service.Invalid(fmt.Sprintf("a shift can run at most %d hours", MaxShiftHours))
+	}
+
+	employee, err := s.employees.GetByEmployeeID(ctx, shift.EmployeeID)
+	if err != nil {
+		return fmt.Errorf("getting employee: %w", err)
+	}
+	if employee == nil || employee.FarmID != shift.FarmID {
+		return service.Invalid("employeeId must be an employee of the farm")
+	}
+	if employee.Status != "Active" {
+		return service.Invalid(fmt.Sprintf("%s %s is not an active employee", employee.FirstName, employee.LastName))
+	}
+
+	if fieldID != nil {
+		shift.FieldID = nil
+		if *fieldID != "" {
+			field, err := s.fields.GetByFieldID(ctx, *fieldID)
+			if err != nil {
+				return fmt.Errorf("getting field: %w", err)
+			}
+			if field == nil || field.FarmID != shift.FarmID {
+				return service.Invalid("field not found on this farm")
+			}
+			shift.FieldID = &field.FieldID
+		}
+	}
+
+	overlapping, err := s.shifts.GetOverlapping(ctx, shift.EmployeeID, shift.StartsAt, shift.EndsAt, shift.ShiftID)
+	if err != nil {
+		return fmt.Errorf("getting shifts: %w", err)
+	}
+	if len(overlapping) > 0 {
+		other := overlapping[0]
+		return service.Conflict(fmt.Sprintf("%s %s already has a %s shift from %s to %s",
+			employee.FirstName, employee.LastName, other.Role,
+			other.StartsAt.Format(time.RFC3339), other.EndsAt.Format(time.RFC3339)))
+	}
+	return nil
+}
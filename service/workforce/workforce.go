@@ -0,0 +1,312 @@
+// Package workforce manages the people employed on a farm, their pay, their
+// attendance and the shifts they are rostered on
+package workforce
+
+import (
+	"context"
+	"errors"
+	"farm4u/data"
+	"farm4u/service"
+	"farm4u/service/farm"
+	"farm4u/service/lock"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EmployeeInput holds the editable employee fields. On update, zero values
+// are left unchanged. UserID optionally links a user account by email.
+type EmployeeInput struct {
+	UserID      *string
+	FirstName   string
+	LastName    string
+	Position    string
+	Salary      float64
+	HireDate    *time.Time
+	ContactInfo string
+	Status      string
+	Version     int // Required on update: must be the current version
+}
+
+// Service is the workforce domain service
+type Service interface {
+	CreateEmployee(ctx context.Context, user *data.User, farmID string, in EmployeeInput) (*data.Employee, error)
+	// CreateEmployees adds several employees in a single transaction. An
+	// item that fails its checks gets its error in the same slot of the
+	// returned errors and is left out; the others are still created.
+	CreateEmployees(ctx context.Context, user *data.User, farmID string, ins []EmployeeInput) ([]*data.Employee, []error, error)
+	GetEmployee(ctx context.Context, user *data.User, employeeID string) (*data.Employee, error)
+	ListEmployees(ctx context.Context, user *data.User, farmID string) ([]*data.Employee, error)
+	UpdateEmployee(ctx context.Context, user *data.User, employeeID string, in EmployeeInput) (*data.Employee, error)
+	// PatchEmployee changes only the fields in set, clearing those left
+	// blank; a null userId unlinks the employee's user account
+	PatchEmployee(ctx context.Context, user *data.User, employeeID string, in EmployeeInput, set service.Fields) (*data.Employee, error)
+	DeleteEmployee(ctx context.Context, user *data.User, employeeID string) error
+	ListDeletedEmployees(ctx context.Context, user *data.User, farmID string) ([]*data.Employee, error)
+	RestoreEmployee(ctx context.Context, user *data.User, employeeID string) (*data.Employee, error)
+
+	RecordPayment(ctx context.Context, user *data.User, employeeID string, in PaymentInput) (*data.PayrollPayment, error)
+	ListEmployeePayments(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.PayrollPayment, error)
+	ListPayments(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.PayrollPayment, error)
+	DeletePayment(ctx context.Context, user *data.User, paymentID string) error
+	// PayrollSummary totals a farm's payroll per month of the given year
+	PayrollSummary(ctx context.Context, user *data.User, farmID string, year int) ([]data.MonthlyPayrollTotal, error)
+
+	Clock(ctx context.Context, user *data.User, employeeID string, in ClockInput) (*data.Attendance, error)
+	ListAttendance(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.Attendance, error)
+	DeleteAttendance(ctx context.Context, user *data.User, attendanceID string) error
+	// WeeklyHours totals hours worked per employee and week
+	WeeklyHours(ctx context.Context, user *data.User, farmID, employeeID string, from, to *time.Time) ([]data.WeeklyHours, error)
+	// AbsenteeReport lists the working days each active employee did not clock in
+	AbsenteeReport(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]Absentee, error)
+
+	// CreateShift and UpdateShift reject a shift that overlaps another of
+	// the same employee
+	CreateShift(ctx context.Context, user *data.User, farmID string, in ShiftInput) (*data.Shift, error)
+	ListShifts(ctx context.Context, user *data.User, farmID string, from, to *time.Time) ([]*data.Shift, error)
+	// Roster lays out a farm's shifts over the week containing a day
+	Roster(ctx context.Context, user *data.User, farmID string, day time.Time) (*Roster, error)
+	UpdateShift(ctx context.Context, user *data.User, shiftID string, in ShiftInput) (*data.Shift, error)
+	DeleteShift(ctx context.Context, user *data.User, shiftID string) error
+
+	// Employments, OwnAttendance and OwnPayments are the self-service view of
+	// a user linked to employee records; they are scoped to those records
+	// rather than to farm ownership
+	Employments(ctx context.Context, user *data.User) ([]*data.Employee, error)
+	OwnAttendance(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.Attendance, error)
+	OwnPayments(ctx context.Context, user *data.User, employeeID string, from, to *time.Time) ([]*data.PayrollPayment, error)
+}
+
+// workforceService implements Service on top of the employee, payroll,
+// attendance and shift repositories
+type workforceService struct {
+	employees  data.EmployeeInterface
+	payments   data.PayrollPaymentInterface
+	attendance data.AttendanceInterface
+	shifts     data.ShiftInterface
+	fields     data.FieldInterface
+	locks      lock.Checker
+	users      data.UserInterface
+	farms      farm.Service
+}
+
+// New creates the workforce service
+func New(employees data.EmployeeInterface, payments data.PayrollPaymentInterface, attendance data.AttendanceInterface, shifts data.ShiftInterface, fields data.FieldInterface, locks lock.Checker, users data.UserInterface, farms farm.Service) Service {
+	return &workforceService{employees: employees, payments: payments, attendance: attendance, shifts: shifts, fields: fields, locks: locks, users: users, farms: farms}
+}
+
+// CreateEmployee adds an employee to one of the user's farms, defaulting to Active
+func (s *workforceService) CreateEmployee(ctx context.Context, user *data.User, farmID string, in EmployeeInput) (*data.Employee, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+
+	employee, err := s.newEmployee(ctx, farmID, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.employees.Insert(ctx, employee); err != nil {
+		return nil, fmt.Errorf("creating employee: %w", err)
+	}
+	return employee, nil
+}
+
+// CreateEmployees adds several employees to one of the user's farms in a
+// single transaction, leaving out those that fail their checks
+func (s *workforceService) CreateEmployees(ctx context.Context, user *data.User, farmID string, ins []EmployeeInput) ([]*data.Employee, []error, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, nil, err
+	}
+
+	employees := make([]*data.Employee, len(ins))
+	errs := make([]error, len(ins))
+	var valid []*data.Employee
+	for i, in := range ins {
+		employees[i], errs[i] = s.newEmployee(ctx, farmID, in)
+		if errs[i] != nil && service.KindOf(errs[i]) == service.KindInternal {
+			return nil, nil, errs[i]
+		}
+		if employees[i] != nil {
+			valid = append(valid, employees[i])
+		}
+	}
+	if len(valid) > 0 {
+		if err := s.employees.InsertMany(ctx, valid); err != nil {
+			return nil, nil, fmt.Errorf("creating employees: %w", err)
+		}
+	}
+	return employees, errs, nil
+}
+
+// newEmployee builds an employee of farmID from in, defaulting to Active
+func (s *workforceService) newEmployee(ctx context.Context, farmID string, in EmployeeInput) (*data.Employee, error) {
+	linkedUserID, err := s.linkedUserID(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Status == "" {
+		in.Status = "Active"
+	}
+
+	employee := &data.Employee{
+		UserID:      linkedUserID,
+		FarmID:      farmID,
+		FirstName:   in.FirstName,
+		LastName:    in.LastName,
+		Position:    in.Position,
+		Salary:      in.Salary,
+		HireDate:    in.HireDate,
+		ContactInfo: in.ContactInfo,
+		Status:      in.Status,
+	}
+	return employee, nil
+}
+
+// GetEmployee returns an employee of one of the user's farms
+func (s *workforceService) GetEmployee(ctx context.Context, user *data.User, employeeID string) (*data.Employee, error) {
+	employee, err := s.employees.GetByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employee: %w", err)
+	}
+	if employee == nil {
+		return nil, service.NotFound("employee not found")
+	}
+	if err := farm.CheckRecord(ctx, s.farms, user, employee.FarmID, "employee"); err != nil {
+		return nil, err
+	}
+	return employee, nil
+}
+
+// ListEmployees returns the employees of one of the user's farms
+func (s *workforceService) ListEmployees(ctx context.Context, user *data.User, farmID string) ([]*data.Employee, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	employees, err := s.employees.GetByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting employees: %w", err)
+	}
+	return employees, nil
+}
+
+// UpdateEmployee changes the non-zero fields of in on an employee
+func (s *workforceService) UpdateEmployee(ctx context.Context, user *data.User, employeeID string, in EmployeeInput) (*data.Employee, error) {
+	return s.PatchEmployee(ctx, user, employeeID, in, nil)
+}
+
+// PatchEmployee implements Service. A nil set updates the fields with a
+// non-zero value, as UpdateEmployee does.
+func (s *workforceService) PatchEmployee(ctx context.Context, user *data.User, employeeID string, in EmployeeInput, set service.Fields) (*data.Employee, error) {
+	employee, err := s.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.CheckVersion(ctx, "employee", in.Version, employee.Version, employee); err != nil {
+		return nil, err
+	}
+
+	linkedUserID, err := s.linkedUserID(ctx, in.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if set.Sets("firstName", in.FirstName != "") {
+		employee.FirstName = in.FirstName
+	}
+	if set.Sets("lastName", in.LastName != "") {
+		employee.LastName = in.LastName
+	}
+	if set.Sets("position", in.Position != "") {
+		employee.Position = in.Position
+	}
+	if set.Sets("salary", in.Salary > 0) {
+		employee.Salary = in.Salary
+	}
+	if set.Sets("hireDate", in.HireDate != nil) {
+		employee.HireDate = in.HireDate
+	}
+	if set.Sets("contactInfo", in.ContactInfo != "") {
+		employee.ContactInfo = in.ContactInfo
+	}
+	if set.Sets("status", in.Status != "") {
+		employee.Status = in.Status
+	}
+	if set.Sets("userId", linkedUserID != nil) {
+		employee.UserID = linkedUserID
+	}
+
+	if err := s.employees.Update(ctx, employee); errors.Is(err, data.ErrStale) {
+		current, err := s.GetEmployee(ctx, user, employeeID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, service.Stale("employee", current)
+	} else if err != nil {
+		return nil, fmt.Errorf("updating employee: %w", err)
+	}
+	return employee, nil
+}
+
+// DeleteEmployee soft deletes an employee
+func (s *workforceService) DeleteEmployee(ctx context.Context, user *data.User, employeeID string) error {
+	employee, err := s.GetEmployee(ctx, user, employeeID)
+	if err != nil {
+		return err
+	}
+	if err := s.employees.DeleteByID(ctx, int(employee.ID)); err != nil {
+		return fmt.Errorf("deleting employee: %w", err)
+	}
+	return nil
+}
+
+// ListDeletedEmployees returns the soft-deleted employees of one of the user's farms
+func (s *workforceService) ListDeletedEmployees(ctx context.Context, user *data.User, farmID string) ([]*data.Employee, error) {
+	if _, err := s.farms.Owned(ctx, user, farmID); err != nil {
+		return nil, err
+	}
+	employees, err := s.employees.GetDeletedByFarmID(ctx, farmID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted employees: %w", err)
+	}
+	return employees, nil
+}
+
+// RestoreEmployee undeletes an employee. Their farm must still exist and
+// belong to the user.
+func (s *workforceService) RestoreEmployee(ctx context.Context, user *data.User, employeeID string) (*data.Employee, error) {
+	employee, err := s.employees.GetDeletedByEmployeeID(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("getting deleted employee: %w", err)
+	}
+	if employee == nil {
+		return nil, service.NotFound("deleted employee not found")
+	}
+	if _, err := s.farms.Owned(ctx, user, employee.FarmID); err != nil {
+		return nil, err
+	}
+
+	if err := s.employees.RestoreByID(ctx, int(employee.ID)); err != nil {
+		return nil, fmt.Errorf("restoring employee: %w", err)
+	}
+	employee.DeletedAt = gorm.DeletedAt{}
+	return employee, nil
+}
+
+// linkedUserID resolves the email given as an employee's user link to the
+// account's UserID. It returns nil when no link was given.
+func (s *workforceService) linkedUserID(ctx context.Context, email *string) (*string, error) {
+	if email == nil || *email == "" {
+		return nil, nil
+	}
+
+	linkedUser, err := s.users.GetByEmail(ctx, *email)
+	if err != nil {
+		return nil, fmt.Errorf("getting linked user: %w", err)
+	}
+	if linkedUser == nil {
+		return nil, service.Invalid("linked user not found")
+	}
+	return &linkedUser.UserID, nil
+}
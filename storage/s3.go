@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// gcsEndpoint is Google Cloud Storage's S3-compatible (XML API) endpoint
+const gcsEndpoint = "storage.googleapis.com"
+
+// S3 stores objects in an S3-compatible bucket. It serves Amazon S3, other
+// S3-compatible services and Google Cloud Storage (with HMAC keys).
+type S3 struct {
+	client *minio.Client
+	bucket string
+	prefix string
+	scheme string
+}
+
+// S3Options configures an S3 backend
+type S3Options struct {
+	Endpoint  string // host[:port]; s3.amazonaws.com if empty
+	Region    string
+	Bucket    string
+	Prefix    string // prepended to every key
+	AccessKey string
+	SecretKey string
+	Insecure  bool // use http instead of https
+}
+
+// NewS3 creates an S3 backend
+func NewS3(opts S3Options) (*S3, error) {
+	return newS3(opts, "s3")
+}
+
+func newS3(opts S3Options, scheme string) (*S3, error) {
+	if opts.Bucket == "" {
+		return nil, errors.New("storage: bucket is required")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = "s3.amazonaws.com"
+	}
+
+	var creds *credentials.Credentials
+	if opts.AccessKey != "" {
+		creds = credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, "")
+	} else {
+		creds = credentials.NewIAM("")
+	}
+
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !opts.Insecure,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: creating %s client: %w", scheme, err)
+	}
+
+	return &S3{
+		client: client,
+		bucket: opts.Bucket,
+		prefix: strings.Trim(opts.Prefix, "/"),
+		scheme: scheme,
+	}, nil
+}
+
+// newS3FromURL builds an S3 backend from s3://bucket/prefix?region=&endpoint=&insecure=true
+func newS3FromURL(u *url.URL) (*S3, error) {
+	q := u.Query()
+	return NewS3(S3Options{
+		Endpoint:  q.Get("endpoint"),
+		Region:    q.Get("region"),
+		Bucket:    u.Host,
+		Prefix:    u.Path,
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		Insecure:  q.Get("insecure") == "true",
+	})
+}
+
+// newGCSFromURL builds a Google Cloud Storage backend from gs://bucket/prefix
+func newGCSFromURL(u *url.URL) (*S3, error) {
+	return newS3(S3Options{
+		Endpoint:  gcsEndpoint,
+		Region:    "auto",
+		Bucket:    u.Host,
+		Prefix:    u.Path,
+		AccessKey: os.Getenv("GCS_HMAC_ACCESS_KEY"),
+		SecretKey: os.Getenv("GCS_HMAC_SECRET"),
+	}, "gs")
+}
+
+// Name implements Storage
+func (s *S3) Name() string {
+	return fmt.Sprintf("%s://%s/%s", s.scheme, s.bucket, s.prefix)
+}
+
+// objectName maps a key to the object name inside the bucket
+func (s *S3) objectName(key string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(s.prefix, key), nil
+}
+
+// key maps an object name inside the bucket back to a key
+func (s *S3) key(objectName string) string {
+	if s.prefix == "" {
+		return objectName
+	}
+	return strings.TrimPrefix(objectName, s.prefix+"/")
+}
+
+// Put implements Storage
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	name, err := s.objectName(key)
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, name, r, size, minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get implements Storage
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	name, _ := s.objectName(key)
+	obj, err := s.client.GetObject(ctx, s.bucket, name, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, translateS3Error(err)
+	}
+	return obj, info, nil
+}
+
+// Stat implements Storage
+func (s *S3) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	name, err := s.objectName(key)
+	if err != nil {
+		return nil, err
+	}
+	oi, err := s.client.StatObject(ctx, s.bucket, name, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, translateS3Error(err)
+	}
+	return &ObjectInfo{
+		Key:          s.key(oi.Key),
+		Size:         oi.Size,
+		ContentType:  oi.ContentType,
+		LastModified: oi.LastModified,
+	}, nil
+}
+
+// Delete implements Storage
+func (s *S3) Delete(ctx context.Context, key string) error {
+	name, err := s.objectName(key)
+	if err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, name, minio.RemoveObjectOptions{})
+}
+
+// List implements Storage
+func (s *S3) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	listPrefix := prefix
+	if s.prefix != "" {
+		listPrefix = s.prefix + "/" + prefix
+	}
+
+	for oi := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: listPrefix, Recursive: true}) {
+		if oi.Err != nil {
+			return oi.Err
+		}
+		if err := fn(ObjectInfo{
+			Key:          s.key(oi.Key),
+			Size:         oi.Size,
+			ContentType:  oi.ContentType,
+			LastModified: oi.LastModified,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PresignPut implements Presigner
+func (s *S3) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	name, err := s.objectName(key)
+	if err != nil {
+		return "", err
+	}
+	u, err := s.client.PresignedPutObject(ctx, s.bucket, name, expiry)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// PresignGet implements Presigner
+func (s *S3) PresignGet(ctx context.Context, key, fileName string, expiry time.Duration) (string, error) {
+	name, err := s.objectName(key)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	if fileName != "" {
+		params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	}
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, name, expiry, params)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// translateS3Error maps a missing object to ErrNotFound
+func translateS3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
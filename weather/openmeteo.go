@@ -0,0 +1,198 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Open-Meteo API endpoints
+const (
+	openMeteoGeocodeURL  = "https://geocoding-api.open-meteo.com/v1/search"
+	openMeteoForecastURL = "https://api.open-meteo.com/v1/forecast"
+	openMeteoArchiveURL  = "https://archive-api.open-meteo.com/v1/archive"
+)
+
+// maxForecastDays is the longest forecast Open-Meteo serves
+const maxForecastDays = 16
+
+// OpenMeteo serves forecasts from Open-Meteo, which needs no API key. Place
+// names are geocoded once and the coordinates cached for the process lifetime.
+type OpenMeteo struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	places map[string]coordinates
+}
+
+// coordinates is a geocoded place
+type coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// NewOpenMeteo creates an Open-Meteo provider
+func NewOpenMeteo() *OpenMeteo {
+	return &OpenMeteo{client: &http.Client{Timeout: 10 * time.Second}, places: map[string]coordinates{}}
+}
+
+// Name implements Forecaster
+func (o *OpenMeteo) Name() string { return "open-meteo" }
+
+// Forecast implements Forecaster
+func (o *OpenMeteo) Forecast(ctx context.Context, location string, days int) ([]Day, error) {
+	if days < 1 {
+		days = 1
+	}
+	if days > maxForecastDays {
+		days = maxForecastDays
+	}
+
+	place, err := o.geocode(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(place.Latitude, 'f', 4, 64))
+	query.Set("longitude", strconv.FormatFloat(place.Longitude, 'f', 4, 64))
+	query.Set("daily", "precipitation_sum,precipitation_probability_max")
+	query.Set("timezone", "auto")
+	query.Set("forecast_days", strconv.Itoa(days))
+
+	var body struct {
+		Daily struct {
+			Time        []string   `json:"time"`
+			Rain        []*float64 `json:"precipitation_sum"`
+			Probability []*int     `json:"precipitation_probability_max"`
+		} `json:"daily"`
+	}
+	if err := o.get(ctx, openMeteoForecastURL+"?"+query.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("weather: open-meteo forecast: %w", err)
+	}
+
+	forecast := make([]Day, 0, len(body.Daily.Time))
+	for i, day := range body.Daily.Time {
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			return nil, fmt.Errorf("weather: open-meteo forecast: bad date %q", day)
+		}
+		d := Day{Date: date}
+		if i < len(body.Daily.Rain) && body.Daily.Rain[i] != nil {
+			d.RainMM = *body.Daily.Rain[i]
+		}
+		if i < len(body.Daily.Probability) && body.Daily.Probability[i] != nil {
+			d.RainChance = *body.Daily.Probability[i]
+		}
+		forecast = append(forecast, d)
+	}
+	return forecast, nil
+}
+
+// History implements Forecaster using Open-Meteo's historical weather
+// archive, which runs a few days behind
+func (o *OpenMeteo) History(ctx context.Context, location string, from, to time.Time) ([]Day, error) {
+	last := to.AddDate(0, 0, -1)
+	if last.Before(from) {
+		return nil, nil
+	}
+
+	place, err := o.geocode(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("latitude", strconv.FormatFloat(place.Latitude, 'f', 4, 64))
+	query.Set("longitude", strconv.FormatFloat(place.Longitude, 'f', 4, 64))
+	query.Set("daily", "precipitation_sum")
+	query.Set("timezone", "auto")
+	query.Set("start_date", from.Format("2006-01-02"))
+	query.Set("end_date", last.Format("2006-01-02"))
+
+	var body struct {
+		Daily struct {
+			Time []string   `json:"time"`
+			Rain []*float64 `json:"precipitation_sum"`
+		} `json:"daily"`
+	}
+	if err := o.get(ctx, openMeteoArchiveURL+"?"+query.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("weather: open-meteo history: %w", err)
+	}
+
+	history := make([]Day, 0, len(body.Daily.Time))
+	for i, day := range body.Daily.Time {
+		if i >= len(body.Daily.Rain) || body.Daily.Rain[i] == nil {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			return nil, fmt.Errorf("weather: open-meteo history: bad date %q", day)
+		}
+		history = append(history, Day{Date: date, RainMM: *body.Daily.Rain[i]})
+	}
+	return history, nil
+}
+
+// geocode resolves a place name to coordinates. Farm locations are often
+// "Village, District"; when the full name is not found the first part is tried.
+func (o *OpenMeteo) geocode(ctx context.Context, location string) (coordinates, error) {
+	key := strings.ToLower(strings.TrimSpace(location))
+	o.mu.Lock()
+	place, ok := o.places[key]
+	o.mu.Unlock()
+	if ok {
+		return place, nil
+	}
+
+	names := []string{strings.TrimSpace(location)}
+	if first, _, found := strings.Cut(location, ","); found {
+		names = append(names, strings.TrimSpace(first))
+	}
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		var body struct {
+			Results []coordinates `json:"results"`
+		}
+		query := url.Values{"name": {name}, "count": {"1"}}
+		if err := o.get(ctx, openMeteoGeocodeURL+"?"+query.Encode(), &body); err != nil {
+			return coordinates{}, fmt.Errorf("weather: open-meteo geocoding: %w", err)
+		}
+		if len(body.Results) > 0 {
+			place = body.Results[0]
+			o.mu.Lock()
+			o.places[key] = place
+			o.mu.Unlock()
+			return place, nil
+		}
+	}
+	return coordinates{}, fmt.Errorf("%w: %q", ErrUnknownLocation, location)
+}
+
+// get fetches rawURL and decodes the JSON response into out
+func (o *OpenMeteo) get(ctx context.Context, rawURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		return fmt.Errorf("provider returned %s", resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
@@ -0,0 +1,76 @@
+// Package weather looks up rain forecasts, and the rain that actually fell,
+// for a farm's location behind a single interface, so the provider is a deployment choice rather than a code
+// change. Farms only record a place name, so providers resolve it themselves.
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ErrUnavailable is returned when no forecast provider is configured
+var ErrUnavailable = errors.New("weather: no forecast provider configured")
+
+// ErrUnknownLocation is returned when a provider cannot place a location
+var ErrUnknownLocation = errors.New("weather: unknown location")
+
+// Day is the forecast for one calendar day at a location
+type Day struct {
+	Date       time.Time `json:"date"`       // Midnight UTC of the local calendar day
+	RainMM     float64   `json:"rainMm"`     // Expected precipitation in millimetres
+	RainChance int       `json:"rainChance"` // Highest chance of precipitation during the day, in percent
+}
+
+// Forecaster is implemented by every forecast provider
+type Forecaster interface {
+	// Forecast returns up to days daily forecasts for location, starting today
+	Forecast(ctx context.Context, location string, days int) ([]Day, error)
+	// History returns the rain recorded at location on each day from from
+	// until to (exclusive). Recent days the provider has no record of yet are
+	// left out.
+	History(ctx context.Context, location string, from, to time.Time) ([]Day, error)
+	// Name identifies the provider in logs, e.g. "open-meteo"
+	Name() string
+}
+
+// Open returns the provider described by rawURL:
+//
+//	none://                  no forecasts (the default)
+//	open-meteo://            Open-Meteo's free forecast and geocoding APIs
+func Open(rawURL string) (Forecaster, error) {
+	if rawURL == "" {
+		return None{}, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("weather: invalid provider URL %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "none":
+		return None{}, nil
+	case "open-meteo":
+		return NewOpenMeteo(), nil
+	default:
+		return nil, fmt.Errorf("weather: unsupported provider scheme %q", u.Scheme)
+	}
+}
+
+// None is the provider used when forecasts are not configured
+type None struct{}
+
+// Forecast implements Forecaster; it always returns ErrUnavailable
+func (None) Forecast(context.Context, string, int) ([]Day, error) {
+	return nil, ErrUnavailable
+}
+
+// History implements Forecaster; it always returns ErrUnavailable
+func (None) History(context.Context, string, time.Time, time.Time) ([]Day, error) {
+	return nil, ErrUnavailable
+}
+
+// Name implements Forecaster
+func (None) Name() string { return "none" }
@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestSignFormat(t *testing.T) {
+	at := time.Unix(1735689600, 0)
+	header := Sign([]byte(`{"event":"crop.created"}`), at, "old", "new")
+
+	format := regexp.MustCompile(`^t=1735689600,v1=[0-9a-f]{64},v1=[0-9a-f]{64}$`)
+	if !format.MatchString(header) {
+		t.Errorf("header %q does not match %s", header, format)
+	}
+	if again := Sign([]byte(`{"event":"crop.created"}`), at, "old", "new"); again != header {
+		t.Errorf("signing twice gave %q and %q", header, again)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"crop.created","data":{"cropId":"c1"}}`)
+	signed := time.Unix(1735689600, 0)
+
+	tests := []struct {
+		name      string
+		header    string
+		body      []byte
+		secret    string
+		tolerance time.Duration
+		now       time.Time
+		want      error
+	}{
+		{name: "valid", header: Sign(body, signed, "s1"), body: body, secret: "s1", now: signed},
+		{name: "within tolerance", header: Sign(body, signed, "s1"), body: body, secret: "s1", now: signed.Add(4 * time.Minute)},
+		{name: "rotating, old secret", header: Sign(body, signed, "old", "new"), body: body, secret: "old", now: signed},
+		{name: "rotating, new secret", header: Sign(body, signed, "old", "new"), body: body, secret: "new", now: signed},
+		{name: "wrong secret", header: Sign(body, signed, "s1"), body: body, secret: "s2", now: signed, want: ErrSignatureMismatch},
+		{name: "changed body", header: Sign(body, signed, "s1"), body: []byte(`{"event":"crop.deleted"}`), secret: "s1", now: signed, want: ErrSignatureMismatch},
+		{name: "too old", header: Sign(body, signed, "s1"), body: body, secret: "s1", now: signed.Add(6 * time.Minute), want: ErrSignatureExpired},
+		{name: "from the future", header: Sign(body, signed, "s1"), body: body, secret: "s1", now: signed.Add(-6 * time.Minute), want: ErrSignatureExpired},
+		{name: "custom tolerance", header: Sign(body, signed, "s1"), body: body, secret: "s1", tolerance: time.Hour, now: signed.Add(30 * time.Minute)},
+		{name: "empty", header: "", body: body, secret: "s1", now: signed, want: ErrMalformedSignature},
+		{name: "no signature", header: "t=1735689600", body: body, secret: "s1", now: signed, want: ErrMalformedSignature},
+		{name: "no time", header: "v1=00", body: body, secret: "s1", now: signed, want: ErrMalformedSignature},
+		{name: "bad time", header: "t=soon,v1=00", body: body, secret: "s1", now: signed, want: ErrMalformedSignature},
+		{name: "bad hex", header: "t=1735689600,v1=zz", body: body, secret: "s1", now: signed, want: ErrMalformedSignature},
+		{name: "no key", header: "t=1735689600,00", body: body, secret: "s1", now: signed, want: ErrMalformedSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.header, tt.body, tt.secret, tt.tolerance, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}